@@ -258,6 +258,69 @@ func (m *Empty) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Empty proto.InternalMessageInfo
 
+type DriveManagerInfo struct {
+	ApiVersion           string   `protobuf:"bytes,1,opt,name=apiVersion,proto3" json:"apiVersion,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version              string   `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	Capabilities         []string `protobuf:"bytes,4,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DriveManagerInfo) Reset()         { *m = DriveManagerInfo{} }
+func (m *DriveManagerInfo) String() string { return proto.CompactTextString(m) }
+func (*DriveManagerInfo) ProtoMessage()    {}
+func (*DriveManagerInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_65bf77650f5c7dcf, []int{6}
+}
+
+func (m *DriveManagerInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DriveManagerInfo.Unmarshal(m, b)
+}
+func (m *DriveManagerInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DriveManagerInfo.Marshal(b, m, deterministic)
+}
+func (m *DriveManagerInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DriveManagerInfo.Merge(m, src)
+}
+func (m *DriveManagerInfo) XXX_Size() int {
+	return xxx_messageInfo_DriveManagerInfo.Size(m)
+}
+func (m *DriveManagerInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_DriveManagerInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DriveManagerInfo proto.InternalMessageInfo
+
+func (m *DriveManagerInfo) GetApiVersion() string {
+	if m != nil {
+		return m.ApiVersion
+	}
+	return ""
+}
+
+func (m *DriveManagerInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DriveManagerInfo) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *DriveManagerInfo) GetCapabilities() []string {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*DrivesRequest)(nil), "v1api.DrivesRequest")
 	proto.RegisterType((*DrivesResponse)(nil), "v1api.DrivesResponse")
@@ -265,32 +328,38 @@ func init() {
 	proto.RegisterType((*DriveLocateResponse)(nil), "v1api.DriveLocateResponse")
 	proto.RegisterType((*NodeLocateRequest)(nil), "v1api.NodeLocateRequest")
 	proto.RegisterType((*Empty)(nil), "v1api.Empty")
+	proto.RegisterType((*DriveManagerInfo)(nil), "v1api.DriveManagerInfo")
 }
 
 func init() { proto.RegisterFile("drivemgrsvc.proto", fileDescriptor_65bf77650f5c7dcf) }
 
 var fileDescriptor_65bf77650f5c7dcf = []byte{
-	// 306 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0xc1, 0x4b, 0xc3, 0x30,
-	0x14, 0xc6, 0x17, 0xa5, 0x1d, 0xbe, 0x6d, 0xc2, 0xa2, 0x8e, 0xda, 0xd3, 0xc8, 0xc5, 0x82, 0x5a,
-	0x70, 0x8a, 0x47, 0x41, 0x99, 0x88, 0x30, 0x76, 0xa8, 0xb7, 0xdd, 0xba, 0x36, 0x48, 0xd0, 0x36,
-	0xb5, 0x2f, 0x2d, 0xec, 0x4f, 0xf4, 0xbf, 0x92, 0x26, 0xa9, 0xae, 0x9b, 0xc7, 0xef, 0xbd, 0x8f,
-	0x2f, 0xbf, 0x2f, 0x09, 0x8c, 0xd3, 0x52, 0xd4, 0x3c, 0x7b, 0x2f, 0xb1, 0x4e, 0xc2, 0xa2, 0x94,
-	0x4a, 0x52, 0xa7, 0xbe, 0x89, 0x0b, 0xe1, 0x0f, 0xd4, 0xa6, 0xe0, 0x68, 0x66, 0xec, 0x02, 0x46,
-	0xf3, 0xc6, 0x88, 0x11, 0xff, 0xaa, 0x38, 0x2a, 0x3a, 0x01, 0x37, 0x97, 0x29, 0x7f, 0x4d, 0x3d,
-	0x32, 0x25, 0xc1, 0x51, 0x64, 0x15, 0xbb, 0x83, 0xe3, 0xd6, 0x88, 0x85, 0xcc, 0x91, 0x53, 0x06,
-	0x4e, 0x2a, 0xf0, 0x03, 0x3d, 0x32, 0x3d, 0x0c, 0x06, 0xb3, 0x61, 0xa8, 0xe3, 0x43, 0xed, 0x8a,
-	0xcc, 0x8a, 0xad, 0x80, 0x6a, 0xbd, 0x90, 0x49, 0xac, 0x78, 0x7b, 0xc6, 0x95, 0xa5, 0x7b, 0xe3,
-	0xa5, 0x88, 0x3f, 0x97, 0x55, 0xb6, 0xe6, 0xa5, 0x3d, 0x6e, 0x7f, 0xd1, 0x10, 0xc5, 0x89, 0x12,
-	0x32, 0xf7, 0x0e, 0xa6, 0x24, 0x70, 0x22, 0xab, 0xd8, 0x35, 0x9c, 0x74, 0xb2, 0x2d, 0xd6, 0x04,
-	0x5c, 0x54, 0xb1, 0xaa, 0x50, 0x27, 0x3a, 0x91, 0x55, 0xec, 0x12, 0xc6, 0x4b, 0x99, 0xee, 0x90,
-	0xfc, 0x65, 0x93, 0x4e, 0x76, 0x1f, 0x9c, 0xe7, 0xac, 0x50, 0x9b, 0xd9, 0x37, 0x81, 0xe1, 0xdc,
-	0x22, 0xd5, 0x22, 0xe1, 0xf4, 0x01, 0x46, 0x2f, 0x5c, 0x99, 0xab, 0x58, 0x08, 0x54, 0xf4, 0x74,
-	0xbb, 0x77, 0x7b, 0x8d, 0xfe, 0xd9, 0xce, 0xd4, 0xc0, 0xb1, 0x1e, 0x7d, 0x04, 0xd7, 0x20, 0xd0,
-	0xf3, 0x6d, 0x4b, 0x07, 0xcb, 0xf7, 0xff, 0x5b, 0xfd, 0x46, 0xdc, 0x03, 0x98, 0x59, 0xd3, 0x87,
-	0x7a, 0xd6, 0xbb, 0x57, 0xce, 0x6f, 0x5f, 0x44, 0x37, 0x61, 0xbd, 0xa7, 0xfe, 0xca, 0xfc, 0x80,
-	0xb5, 0xab, 0xdf, 0xfe, 0xf6, 0x27, 0x00, 0x00, 0xff, 0xff, 0x9e, 0x95, 0x3b, 0xed, 0x24, 0x02,
-	0x00, 0x00,
+	// 387 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6d, 0x52, 0x5d, 0x4f, 0xc2, 0x30,
+	0x14, 0x65, 0xc2, 0x58, 0x76, 0x01, 0x23, 0xf5, 0x6b, 0xee, 0xc1, 0x98, 0xbe, 0x48, 0x22, 0x92,
+	0x88, 0xc6, 0x47, 0x13, 0x0d, 0xc6, 0x90, 0x20, 0x0f, 0x33, 0xf1, 0x81, 0xb7, 0xb2, 0x55, 0xd2,
+	0xc8, 0x3e, 0x5c, 0xcb, 0x12, 0xde, 0xfd, 0xdb, 0x26, 0x96, 0x76, 0xd3, 0x4d, 0x7c, 0xeb, 0x3d,
+	0xf7, 0xf4, 0xdc, 0x73, 0x4f, 0x0b, 0xdd, 0x20, 0x65, 0x19, 0x0d, 0x17, 0x29, 0xcf, 0xfc, 0x41,
+	0x92, 0xc6, 0x22, 0x46, 0x66, 0x76, 0x45, 0x12, 0xe6, 0xb6, 0xc4, 0x3a, 0xa1, 0x5c, 0x63, 0xf8,
+	0x1c, 0x3a, 0xa3, 0x0d, 0x91, 0x7b, 0xf4, 0x63, 0x45, 0xb9, 0x40, 0x47, 0xd0, 0x8c, 0xe2, 0x80,
+	0x8e, 0x03, 0xc7, 0x38, 0x33, 0x7a, 0xb6, 0x97, 0x57, 0xf8, 0x06, 0x76, 0x0b, 0x22, 0x4f, 0xe2,
+	0x88, 0x53, 0x84, 0xc1, 0x0c, 0x18, 0x7f, 0xe7, 0x92, 0x58, 0xef, 0xb5, 0x86, 0xed, 0x81, 0x92,
+	0x1f, 0x28, 0x96, 0xa7, 0x5b, 0x78, 0x06, 0x48, 0xd5, 0x93, 0xd8, 0x27, 0x82, 0x16, 0x33, 0xfa,
+	0xa0, 0xdd, 0xbd, 0xd0, 0x94, 0x91, 0xe5, 0x74, 0x15, 0xce, 0x69, 0x9a, 0x8f, 0xdb, 0x6e, 0x6c,
+	0x1c, 0x11, 0x5f, 0xb0, 0x38, 0x72, 0x76, 0x24, 0xc5, 0xf4, 0xf2, 0x0a, 0x5f, 0xc2, 0x7e, 0x45,
+	0x3b, 0xb7, 0x25, 0xe9, 0x5c, 0x10, 0xb1, 0xe2, 0x4a, 0x51, 0xd2, 0x75, 0x85, 0x2f, 0xa0, 0x3b,
+	0x95, 0xab, 0x54, 0x9d, 0xfc, 0x6a, 0x1b, 0x15, 0x6d, 0x0b, 0xcc, 0xc7, 0x30, 0x11, 0x6b, 0xfc,
+	0x69, 0xc0, 0x9e, 0x9a, 0xf2, 0x4c, 0x22, 0xb2, 0xa0, 0xe9, 0x38, 0x7a, 0x8b, 0xd1, 0x29, 0x80,
+	0xdc, 0xf4, 0x95, 0xa6, 0xbc, 0xb8, 0x69, 0x7b, 0x25, 0x04, 0x21, 0x68, 0x44, 0x24, 0xa4, 0xca,
+	0xaf, 0xed, 0xa9, 0x33, 0x72, 0xc0, 0xca, 0xf2, 0x0b, 0x75, 0x05, 0x17, 0xa5, 0xcc, 0xb1, 0xed,
+	0x93, 0x84, 0xcc, 0xd9, 0x92, 0x09, 0x46, 0xb9, 0xd3, 0x90, 0x71, 0xda, 0x5e, 0x05, 0x1b, 0x7e,
+	0x19, 0xd0, 0x1e, 0xe5, 0xc9, 0x64, 0xcc, 0xa7, 0xe8, 0x0e, 0x3a, 0x4f, 0x54, 0xe8, 0x17, 0x99,
+	0x30, 0xb9, 0xc9, 0x41, 0x39, 0xfe, 0xe2, 0x35, 0xdd, 0xc3, 0x3f, 0xa8, 0xce, 0x08, 0xd7, 0xd0,
+	0x3d, 0x34, 0x75, 0x12, 0xe8, 0xa4, 0x4c, 0xa9, 0xa4, 0xe3, 0xba, 0xff, 0xb5, 0x7e, 0x24, 0x6e,
+	0x01, 0x34, 0xb6, 0x89, 0x15, 0x39, 0x39, 0x77, 0x2b, 0x63, 0xb7, 0xf8, 0x18, 0x3a, 0xd0, 0x1a,
+	0x1a, 0x82, 0x25, 0xad, 0xab, 0x20, 0x2b, 0x2d, 0xf7, 0xb8, 0x3c, 0xae, 0x94, 0x37, 0xae, 0x3d,
+	0x58, 0x33, 0xfd, 0x79, 0xe7, 0x4d, 0xf5, 0x6d, 0xaf, 0xbf, 0x01, 0xe6, 0xbc, 0xb7, 0x0d, 0xdf,
+	0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -308,6 +377,7 @@ type DriveServiceClient interface {
 	GetDrivesList(ctx context.Context, in *DrivesRequest, opts ...grpc.CallOption) (*DrivesResponse, error)
 	Locate(ctx context.Context, in *DriveLocateRequest, opts ...grpc.CallOption) (*DriveLocateResponse, error)
 	LocateNode(ctx context.Context, in *NodeLocateRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DriveManagerInfo, error)
 }
 
 type driveServiceClient struct {
@@ -345,11 +415,21 @@ func (c *driveServiceClient) LocateNode(ctx context.Context, in *NodeLocateReque
 	return out, nil
 }
 
+func (c *driveServiceClient) GetInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DriveManagerInfo, error) {
+	out := new(DriveManagerInfo)
+	err := c.cc.Invoke(ctx, "/v1api.DriveService/GetInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DriveServiceServer is the server API for DriveService service.
 type DriveServiceServer interface {
 	GetDrivesList(context.Context, *DrivesRequest) (*DrivesResponse, error)
 	Locate(context.Context, *DriveLocateRequest) (*DriveLocateResponse, error)
 	LocateNode(context.Context, *NodeLocateRequest) (*Empty, error)
+	GetInfo(context.Context, *Empty) (*DriveManagerInfo, error)
 }
 
 // UnimplementedDriveServiceServer can be embedded to have forward compatible implementations.
@@ -365,6 +445,9 @@ func (*UnimplementedDriveServiceServer) Locate(ctx context.Context, req *DriveLo
 func (*UnimplementedDriveServiceServer) LocateNode(ctx context.Context, req *NodeLocateRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LocateNode not implemented")
 }
+func (*UnimplementedDriveServiceServer) GetInfo(ctx context.Context, req *Empty) (*DriveManagerInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInfo not implemented")
+}
 
 func RegisterDriveServiceServer(s *grpc.Server, srv DriveServiceServer) {
 	s.RegisterService(&_DriveService_serviceDesc, srv)
@@ -424,6 +507,24 @@ func _DriveService_LocateNode_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DriveService_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriveServiceServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1api.DriveService/GetInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriveServiceServer).GetInfo(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _DriveService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "v1api.DriveService",
 	HandlerType: (*DriveServiceServer)(nil),
@@ -440,6 +541,10 @@ var _DriveService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "LocateNode",
 			Handler:    _DriveService_LocateNode_Handler,
 		},
+		{
+			MethodName: "GetInfo",
+			Handler:    _DriveService_GetInfo_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "drivemgrsvc.proto",
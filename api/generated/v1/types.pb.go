@@ -33,15 +33,17 @@ type Drive struct {
 	Usage  string `protobuf:"bytes,9,opt,name=Usage,proto3" json:"Usage,omitempty"`
 	NodeId string `protobuf:"bytes,10,opt,name=NodeId,proto3" json:"NodeId,omitempty"`
 	// path to the device. may not be set by drivemgr.
-	Path                 string   `protobuf:"bytes,11,opt,name=Path,proto3" json:"Path,omitempty"`
-	Enclosure            string   `protobuf:"bytes,12,opt,name=Enclosure,proto3" json:"Enclosure,omitempty"`
-	Slot                 string   `protobuf:"bytes,13,opt,name=Slot,proto3" json:"Slot,omitempty"`
-	Bay                  string   `protobuf:"bytes,14,opt,name=Bay,proto3" json:"Bay,omitempty"`
-	Firmware             string   `protobuf:"bytes,15,opt,name=Firmware,proto3" json:"Firmware,omitempty"`
-	Endurance            int64    `protobuf:"varint,16,opt,name=Endurance,proto3" json:"Endurance,omitempty"`
-	LEDState             string   `protobuf:"bytes,17,opt,name=LEDState,proto3" json:"LEDState,omitempty"`
-	IsSystem             bool     `protobuf:"varint,18,opt,name=IsSystem,proto3" json:"IsSystem,omitempty"`
-	IsClean              bool     `protobuf:"varint,19,opt,name=IsClean,proto3" json:"IsClean,omitempty"`
+	Path      string `protobuf:"bytes,11,opt,name=Path,proto3" json:"Path,omitempty"`
+	Enclosure string `protobuf:"bytes,12,opt,name=Enclosure,proto3" json:"Enclosure,omitempty"`
+	Slot      string `protobuf:"bytes,13,opt,name=Slot,proto3" json:"Slot,omitempty"`
+	Bay       string `protobuf:"bytes,14,opt,name=Bay,proto3" json:"Bay,omitempty"`
+	Firmware  string `protobuf:"bytes,15,opt,name=Firmware,proto3" json:"Firmware,omitempty"`
+	Endurance int64  `protobuf:"varint,16,opt,name=Endurance,proto3" json:"Endurance,omitempty"`
+	LEDState  string `protobuf:"bytes,17,opt,name=LEDState,proto3" json:"LEDState,omitempty"`
+	IsSystem  bool   `protobuf:"varint,18,opt,name=IsSystem,proto3" json:"IsSystem,omitempty"`
+	IsClean   bool   `protobuf:"varint,19,opt,name=IsClean,proto3" json:"IsClean,omitempty"`
+	// drive temperature in Celsius, 0 if unknown
+	Temperature          int64    `protobuf:"varint,20,opt,name=Temperature,proto3" json:"Temperature,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -205,6 +207,13 @@ func (m *Drive) GetIsClean() bool {
 	return false
 }
 
+func (m *Drive) GetTemperature() int64 {
+	if m != nil {
+		return m.Temperature
+	}
+	return 0
+}
+
 type Volume struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=Id,proto3" json:"Id,omitempty"`
 	Location             string   `protobuf:"bytes,2,opt,name=Location,proto3" json:"Location,omitempty"`
@@ -220,6 +229,11 @@ type Volume struct {
 	CSIStatus            string   `protobuf:"bytes,12,opt,name=CSIStatus,proto3" json:"CSIStatus,omitempty"`
 	Usage                string   `protobuf:"bytes,13,opt,name=Usage,proto3" json:"Usage,omitempty"`
 	Ephemeral            bool     `protobuf:"varint,14,opt,name=Ephemeral,proto3" json:"Ephemeral,omitempty"`
+	Encryption           string   `protobuf:"bytes,15,opt,name=Encryption,proto3" json:"Encryption,omitempty"`
+	LazyFormat           bool     `protobuf:"varint,16,opt,name=LazyFormat,proto3" json:"LazyFormat,omitempty"`
+	CacheMode            string   `protobuf:"bytes,17,opt,name=CacheMode,proto3" json:"CacheMode,omitempty"`
+	CacheSize            int64    `protobuf:"varint,18,opt,name=CacheSize,proto3" json:"CacheSize,omitempty"`
+	Template             string   `protobuf:"bytes,19,opt,name=Template,proto3" json:"Template,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -348,6 +362,41 @@ func (m *Volume) GetEphemeral() bool {
 	return false
 }
 
+func (m *Volume) GetEncryption() string {
+	if m != nil {
+		return m.Encryption
+	}
+	return ""
+}
+
+func (m *Volume) GetLazyFormat() bool {
+	if m != nil {
+		return m.LazyFormat
+	}
+	return false
+}
+
+func (m *Volume) GetCacheMode() string {
+	if m != nil {
+		return m.CacheMode
+	}
+	return ""
+}
+
+func (m *Volume) GetCacheSize() int64 {
+	if m != nil {
+		return m.CacheSize
+	}
+	return 0
+}
+
+func (m *Volume) GetTemplate() string {
+	if m != nil {
+		return m.Template
+	}
+	return ""
+}
+
 type AvailableCapacity struct {
 	Location             string   `protobuf:"bytes,1,opt,name=Location,proto3" json:"Location,omitempty"`
 	NodeId               string   `protobuf:"bytes,2,opt,name=NodeId,proto3" json:"NodeId,omitempty"`
@@ -717,10 +766,15 @@ func (m *LogicalVolumeGroup) GetHealth() string {
 type Node struct {
 	UUID string `protobuf:"bytes,1,opt,name=UUID,proto3" json:"UUID,omitempty"`
 	// key - address type, value - address, align with NodeAddress struct from k8s.io/api/core/v1
-	Addresses            map[string]string `protobuf:"bytes,2,rep,name=Addresses,proto3" json:"Addresses,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Addresses map[string]string `protobuf:"bytes,2,rep,name=Addresses,proto3" json:"Addresses,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// key - system utility name, value - detected version, filled by node service on start
+	Utilities map[string]string `protobuf:"bytes,3,rep,name=Utilities,proto3" json:"Utilities,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// version of node to controller protocol and capabilities of node service, filled by node service on start
+	ProtocolVersion      string   `protobuf:"bytes,4,opt,name=ProtocolVersion,proto3" json:"ProtocolVersion,omitempty"`
+	Capabilities         []string `protobuf:"bytes,5,rep,name=Capabilities,proto3" json:"Capabilities,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Node) Reset()         { *m = Node{} }
@@ -762,6 +816,324 @@ func (m *Node) GetAddresses() map[string]string {
 	return nil
 }
 
+func (m *Node) GetUtilities() map[string]string {
+	if m != nil {
+		return m.Utilities
+	}
+	return nil
+}
+
+type Pool struct {
+	// UUIDs of drives in the pool
+	Locations []string `protobuf:"bytes,1,rep,name=Locations,proto3" json:"Locations,omitempty"`
+	// names of k8s StorageClasses which volumes may use drives of the pool, any if empty
+	StorageClasses []string `protobuf:"bytes,2,rep,name=StorageClasses,proto3" json:"StorageClasses,omitempty"`
+	// namespaces which volumes may use drives of the pool, any if empty
+	Namespaces           []string `protobuf:"bytes,3,rep,name=Namespaces,proto3" json:"Namespaces,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Pool) Reset()         { *m = Pool{} }
+func (m *Pool) String() string { return proto.CompactTextString(m) }
+func (*Pool) ProtoMessage()    {}
+func (*Pool) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d938547f84707355, []int{9}
+}
+
+func (m *Node) GetProtocolVersion() string {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return ""
+}
+
+func (m *Node) GetCapabilities() []string {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
+func (m *Pool) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Pool.Unmarshal(m, b)
+}
+func (m *Pool) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Pool.Marshal(b, m, deterministic)
+}
+func (m *Pool) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Pool.Merge(m, src)
+}
+func (m *Pool) XXX_Size() int {
+	return xxx_messageInfo_Pool.Size(m)
+}
+func (m *Pool) XXX_DiscardUnknown() {
+	xxx_messageInfo_Pool.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Pool proto.InternalMessageInfo
+
+func (m *Pool) GetLocations() []string {
+	if m != nil {
+		return m.Locations
+	}
+	return nil
+}
+
+func (m *Pool) GetStorageClasses() []string {
+	if m != nil {
+		return m.StorageClasses
+	}
+	return nil
+}
+
+func (m *Pool) GetNamespaces() []string {
+	if m != nil {
+		return m.Namespaces
+	}
+	return nil
+}
+
+type DriveBatch struct {
+	// label selector of k8s Nodes which drives are processed, all nodes if empty
+	NodeSelector string `protobuf:"bytes,1,opt,name=NodeSelector,proto3" json:"NodeSelector,omitempty"`
+	// model of processed drives, any if empty
+	Model string `protobuf:"bytes,2,opt,name=Model,proto3" json:"Model,omitempty"`
+	// type of processed drives: HDD, SSD or NVME, any if empty
+	Type string `protobuf:"bytes,3,opt,name=Type,proto3" json:"Type,omitempty"`
+	// operation which is applied to matched drives: annotate or remove-annotation
+	Operation       string `protobuf:"bytes,4,opt,name=Operation,proto3" json:"Operation,omitempty"`
+	AnnotationKey   string `protobuf:"bytes,5,opt,name=AnnotationKey,proto3" json:"AnnotationKey,omitempty"`
+	AnnotationValue string `protobuf:"bytes,6,opt,name=AnnotationValue,proto3" json:"AnnotationValue,omitempty"`
+	// status of the batch: IN_PROGRESS, DONE or FAILED, filled by controller
+	Status string `protobuf:"bytes,7,opt,name=Status,proto3" json:"Status,omitempty"`
+	// UUIDs of drives which were updated, filled by controller
+	Updated []string `protobuf:"bytes,8,rep,name=Updated,proto3" json:"Updated,omitempty"`
+	// UUIDs of drives which weren't updated because of errors, filled by controller
+	Failed               []string `protobuf:"bytes,9,rep,name=Failed,proto3" json:"Failed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DriveBatch) Reset()         { *m = DriveBatch{} }
+func (m *DriveBatch) String() string { return proto.CompactTextString(m) }
+func (*DriveBatch) ProtoMessage()    {}
+func (*DriveBatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d938547f84707355, []int{10}
+}
+
+func (m *DriveBatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DriveBatch.Unmarshal(m, b)
+}
+func (m *DriveBatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DriveBatch.Marshal(b, m, deterministic)
+}
+func (m *DriveBatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DriveBatch.Merge(m, src)
+}
+func (m *DriveBatch) XXX_Size() int {
+	return xxx_messageInfo_DriveBatch.Size(m)
+}
+func (m *DriveBatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_DriveBatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DriveBatch proto.InternalMessageInfo
+
+func (m *DriveBatch) GetNodeSelector() string {
+	if m != nil {
+		return m.NodeSelector
+	}
+	return ""
+}
+
+func (m *DriveBatch) GetModel() string {
+	if m != nil {
+		return m.Model
+	}
+	return ""
+}
+
+func (m *DriveBatch) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *DriveBatch) GetOperation() string {
+	if m != nil {
+		return m.Operation
+	}
+	return ""
+}
+
+func (m *DriveBatch) GetAnnotationKey() string {
+	if m != nil {
+		return m.AnnotationKey
+	}
+	return ""
+}
+
+func (m *DriveBatch) GetAnnotationValue() string {
+	if m != nil {
+		return m.AnnotationValue
+	}
+	return ""
+}
+
+func (m *DriveBatch) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *DriveBatch) GetUpdated() []string {
+	if m != nil {
+		return m.Updated
+	}
+	return nil
+}
+
+func (m *DriveBatch) GetFailed() []string {
+	if m != nil {
+		return m.Failed
+	}
+	return nil
+}
+
+type HistoryEntry struct {
+	// unix time of the event
+	Time                 int64    `protobuf:"varint,1,opt,name=Time,proto3" json:"Time,omitempty"`
+	Type                 string   `protobuf:"bytes,2,opt,name=Type,proto3" json:"Type,omitempty"`
+	Reason               string   `protobuf:"bytes,3,opt,name=Reason,proto3" json:"Reason,omitempty"`
+	Message              string   `protobuf:"bytes,4,opt,name=Message,proto3" json:"Message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HistoryEntry) Reset()         { *m = HistoryEntry{} }
+func (m *HistoryEntry) String() string { return proto.CompactTextString(m) }
+func (*HistoryEntry) ProtoMessage()    {}
+func (*HistoryEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d938547f84707355, []int{11}
+}
+
+func (m *HistoryEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryEntry.Unmarshal(m, b)
+}
+func (m *HistoryEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryEntry.Marshal(b, m, deterministic)
+}
+func (m *HistoryEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryEntry.Merge(m, src)
+}
+func (m *HistoryEntry) XXX_Size() int {
+	return xxx_messageInfo_HistoryEntry.Size(m)
+}
+func (m *HistoryEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryEntry proto.InternalMessageInfo
+
+func (m *HistoryEntry) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *HistoryEntry) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *HistoryEntry) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *HistoryEntry) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type History struct {
+	// kind, name and namespace of the object which history is kept
+	Kind      string `protobuf:"bytes,1,opt,name=Kind,proto3" json:"Kind,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	Namespace string `protobuf:"bytes,3,opt,name=Namespace,proto3" json:"Namespace,omitempty"`
+	// entries ordered by time, the oldest entries are dropped when limit is reached
+	Entries              []*HistoryEntry `protobuf:"bytes,4,rep,name=Entries,proto3" json:"Entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *History) Reset()         { *m = History{} }
+func (m *History) String() string { return proto.CompactTextString(m) }
+func (*History) ProtoMessage()    {}
+func (*History) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d938547f84707355, []int{12}
+}
+
+func (m *History) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_History.Unmarshal(m, b)
+}
+func (m *History) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_History.Marshal(b, m, deterministic)
+}
+func (m *History) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_History.Merge(m, src)
+}
+func (m *History) XXX_Size() int {
+	return xxx_messageInfo_History.Size(m)
+}
+func (m *History) XXX_DiscardUnknown() {
+	xxx_messageInfo_History.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_History proto.InternalMessageInfo
+
+func (m *History) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *History) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *History) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *History) GetEntries() []*HistoryEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Drive)(nil), "v1api.Drive")
 	proto.RegisterType((*Volume)(nil), "v1api.Volume")
@@ -773,60 +1145,85 @@ func init() {
 	proto.RegisterType((*LogicalVolumeGroup)(nil), "v1api.LogicalVolumeGroup")
 	proto.RegisterType((*Node)(nil), "v1api.Node")
 	proto.RegisterMapType((map[string]string)(nil), "v1api.Node.AddressesEntry")
+	proto.RegisterMapType((map[string]string)(nil), "v1api.Node.UtilitiesEntry")
+	proto.RegisterType((*Pool)(nil), "v1api.Pool")
+	proto.RegisterType((*DriveBatch)(nil), "v1api.DriveBatch")
+	proto.RegisterType((*HistoryEntry)(nil), "v1api.HistoryEntry")
+	proto.RegisterType((*History)(nil), "v1api.History")
 }
 
 func init() { proto.RegisterFile("types.proto", fileDescriptor_d938547f84707355) }
 
 var fileDescriptor_d938547f84707355 = []byte{
-	// 792 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x55, 0xcd, 0x6e, 0xd3, 0x4e,
-	0x10, 0x97, 0xed, 0x7c, 0x6e, 0xd2, 0xaf, 0xed, 0x5f, 0xd5, 0xfe, 0xab, 0x08, 0x45, 0x3e, 0xe5,
-	0x80, 0x22, 0x11, 0x0e, 0x54, 0x88, 0x03, 0x6d, 0x52, 0xa8, 0x45, 0x69, 0x2b, 0x87, 0xf6, 0x80,
-	0xc4, 0x61, 0x1b, 0x0f, 0xad, 0x85, 0x13, 0x9b, 0x5d, 0x3b, 0x95, 0x7b, 0x81, 0x67, 0xe0, 0x81,
-	0x78, 0x03, 0x5e, 0x80, 0xa7, 0x41, 0xb3, 0xeb, 0xf8, 0xa3, 0xc9, 0x6d, 0xe6, 0x37, 0x3b, 0x3b,
-	0xe3, 0xdf, 0xfc, 0x66, 0x4d, 0x3a, 0x71, 0x1a, 0x81, 0x1c, 0x46, 0x22, 0x8c, 0x43, 0x5a, 0x5f,
-	0xbe, 0xe0, 0x91, 0x6f, 0xff, 0xb1, 0x48, 0x7d, 0x22, 0xfc, 0x25, 0x50, 0x4a, 0x6a, 0xd7, 0xd7,
-	0xce, 0x84, 0x19, 0x7d, 0x63, 0xd0, 0x76, 0x95, 0x4d, 0x77, 0x89, 0x75, 0xe3, 0x4c, 0x98, 0xa9,
-	0x20, 0x34, 0x11, 0xb9, 0x72, 0x26, 0xcc, 0xd2, 0xc8, 0x95, 0x33, 0xa1, 0x36, 0xe9, 0x4e, 0x41,
-	0xf8, 0x3c, 0xb8, 0x48, 0xe6, 0xb7, 0x20, 0x58, 0x4d, 0x85, 0x2a, 0x18, 0x3d, 0x20, 0x8d, 0x33,
-	0xe0, 0x41, 0x7c, 0xcf, 0xea, 0x2a, 0x9a, 0x79, 0x58, 0xf3, 0x53, 0x1a, 0x01, 0x6b, 0xe8, 0x9a,
-	0x68, 0x23, 0x36, 0xf5, 0x1f, 0x81, 0x35, 0xfb, 0xc6, 0xc0, 0x72, 0x95, 0x8d, 0xf9, 0xd3, 0x98,
-	0xc7, 0x89, 0x64, 0x2d, 0x9d, 0xaf, 0x3d, 0xfa, 0x1f, 0xa9, 0x5f, 0x4b, 0x7e, 0x07, 0xac, 0xad,
-	0x60, 0xed, 0xe0, 0xe9, 0x8b, 0xd0, 0x03, 0xc7, 0x63, 0x44, 0x9f, 0xd6, 0x1e, 0xde, 0x7c, 0xc5,
-	0xe3, 0x7b, 0xd6, 0xd1, 0xd5, 0xd0, 0xa6, 0x3d, 0xd2, 0x3e, 0x5d, 0xcc, 0x82, 0x50, 0x26, 0x02,
-	0x58, 0x57, 0x05, 0x0a, 0x40, 0xf5, 0x12, 0x84, 0x31, 0xdb, 0xd2, 0x19, 0x68, 0x23, 0x03, 0x27,
-	0x3c, 0x65, 0xdb, 0x9a, 0x81, 0x13, 0x9e, 0xd2, 0x43, 0xd2, 0x7a, 0xe7, 0x8b, 0xf9, 0x03, 0x17,
-	0xc0, 0x76, 0x14, 0x9c, 0xfb, 0xfa, 0x7e, 0x2f, 0x11, 0x7c, 0x31, 0x03, 0xb6, 0xab, 0x3e, 0xa9,
-	0x00, 0x30, 0xf3, 0xfc, 0x74, 0x82, 0x1f, 0x03, 0x6c, 0x4f, 0x67, 0xae, 0x7c, 0x8c, 0x39, 0x72,
-	0x9a, 0xca, 0x18, 0xe6, 0x8c, 0xf6, 0x8d, 0x41, 0xcb, 0xcd, 0x7d, 0xca, 0x48, 0xd3, 0x91, 0xe3,
-	0x00, 0xf8, 0x82, 0xed, 0xab, 0xd0, 0xca, 0xb5, 0x7f, 0x5a, 0xa4, 0x71, 0x13, 0x06, 0xc9, 0x1c,
-	0xe8, 0x36, 0x31, 0x1d, 0x2f, 0x1b, 0xa7, 0xe9, 0x78, 0xaa, 0x58, 0x38, 0xe3, 0xb1, 0x1f, 0x2e,
-	0xb2, 0x89, 0xe6, 0x3e, 0x0e, 0x71, 0x65, 0xab, 0x81, 0xe8, 0xf9, 0x56, 0x30, 0x35, 0xe8, 0x38,
-	0x14, 0xfc, 0x0e, 0xc6, 0x01, 0x97, 0x32, 0x1f, 0x74, 0x09, 0x2b, 0x51, 0x5f, 0xaf, 0x50, 0x7f,
-	0x40, 0x1a, 0x97, 0x0f, 0x0b, 0x10, 0x92, 0x35, 0xfa, 0x16, 0xe2, 0xda, 0xdb, 0x38, 0x6c, 0x4a,
-	0x6a, 0x1f, 0x43, 0x0f, 0xb2, 0x51, 0x2b, 0x3b, 0x17, 0x4a, 0xbb, 0x24, 0x94, 0x42, 0x54, 0xa4,
-	0x22, 0xaa, 0xe7, 0x64, 0xef, 0x32, 0x02, 0xa1, 0x1a, 0xe7, 0x41, 0xa6, 0x1b, 0x3d, 0xf3, 0xf5,
-	0x00, 0x0e, 0x68, 0x3c, 0x75, 0xb2, 0x53, 0x99, 0x00, 0x72, 0xa0, 0x10, 0xd8, 0x56, 0x59, 0x60,
-	0x38, 0xd4, 0xe8, 0x1e, 0xe6, 0x20, 0x78, 0xa0, 0x84, 0xd0, 0x72, 0x0b, 0xc0, 0xfe, 0x41, 0xf6,
-	0x8e, 0x97, 0xdc, 0x0f, 0xf8, 0x6d, 0x00, 0x63, 0x1e, 0xf1, 0x99, 0x1f, 0xa7, 0x15, 0xf2, 0x8d,
-	0x27, 0xe4, 0x17, 0xa4, 0x99, 0x15, 0xd2, 0x6c, 0xd2, 0x95, 0x65, 0xc2, 0xb3, 0xa1, 0x94, 0xb1,
-	0x9c, 0xc0, 0x5a, 0x41, 0xa0, 0xfd, 0xd7, 0x20, 0xbd, 0xb5, 0x0e, 0x5c, 0x90, 0x20, 0x96, 0xba,
-	0x60, 0x8f, 0xb4, 0x2f, 0xf8, 0x1c, 0x64, 0xc4, 0x67, 0x90, 0x75, 0x53, 0x00, 0xa5, 0x65, 0x33,
-	0x2b, 0xcb, 0xf6, 0x8a, 0x74, 0xb1, 0x31, 0x17, 0xbe, 0x27, 0x20, 0x63, 0xdd, 0x4e, 0x67, 0xb4,
-	0x3f, 0x54, 0x0f, 0xc9, 0xb0, 0x1c, 0x72, 0x2b, 0x07, 0xe9, 0x07, 0xb2, 0x5f, 0xaa, 0x9e, 0xe7,
-	0xd7, 0xfa, 0xd6, 0xa0, 0x33, 0xfa, 0x3f, 0xcb, 0x5f, 0x3f, 0xe1, 0x6e, 0xca, 0xb2, 0xcf, 0xaa,
-	0x5d, 0xe0, 0xb7, 0x64, 0x36, 0xa0, 0xd8, 0x51, 0x5c, 0x05, 0x80, 0xb4, 0xeb, 0x4b, 0x00, 0xc9,
-	0xc5, 0x60, 0xee, 0xdb, 0x8f, 0x84, 0xae, 0x17, 0xa0, 0x6f, 0xc9, 0x4e, 0x41, 0x99, 0x82, 0x14,
-	0x43, 0x9d, 0xd1, 0x41, 0xd6, 0xe8, 0x93, 0xa8, 0xfb, 0xf4, 0x38, 0x8e, 0xad, 0x74, 0xaf, 0xcc,
-	0xea, 0x56, 0x30, 0xfb, 0xcb, 0x5a, 0x15, 0x9c, 0x24, 0xce, 0x60, 0xf5, 0xfe, 0xa2, 0xbd, 0xb6,
-	0x72, 0xe6, 0x86, 0x95, 0x5b, 0x29, 0xc0, 0x2a, 0x29, 0xe0, 0xb7, 0x41, 0xe8, 0x79, 0x78, 0xe7,
-	0xcf, 0x78, 0xa0, 0x1f, 0x83, 0xf7, 0x22, 0x4c, 0xa2, 0x8d, 0x25, 0x10, 0xc3, 0x6d, 0x33, 0x33,
-	0x0c, 0xb7, 0xad, 0x47, 0xda, 0x2b, 0x71, 0xe2, 0x98, 0x15, 0xa7, 0x39, 0xb0, 0x49, 0x72, 0xf4,
-	0x19, 0x21, 0xba, 0x90, 0x0b, 0x5f, 0x25, 0xab, 0xab, 0x94, 0x12, 0x52, 0xd2, 0x54, 0xa3, 0xa2,
-	0xa9, 0x62, 0x87, 0x9b, 0xe5, 0x1d, 0xb6, 0x7f, 0x19, 0xba, 0xad, 0x8d, 0x7f, 0xa5, 0x23, 0xd2,
-	0x3e, 0xf6, 0x3c, 0x01, 0x52, 0x82, 0x66, 0xb7, 0x33, 0x3a, 0x2c, 0xa9, 0x70, 0x98, 0x07, 0x4f,
-	0x17, 0xb1, 0x48, 0xdd, 0xe2, 0xf0, 0xe1, 0x1b, 0xb2, 0x5d, 0x0d, 0xe2, 0x6b, 0xfe, 0x0d, 0xd2,
-	0xec, 0x7a, 0x34, 0x71, 0xe5, 0x97, 0x3c, 0x48, 0x56, 0x8c, 0x68, 0xe7, 0xb5, 0x79, 0x64, 0x9c,
-	0x34, 0x3f, 0xeb, 0x9f, 0xe6, 0x6d, 0x43, 0xfd, 0x42, 0x5f, 0xfe, 0x0b, 0x00, 0x00, 0xff, 0xff,
-	0x4e, 0xad, 0x82, 0xa2, 0x51, 0x07, 0x00, 0x00,
+	// 1119 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x95, 0x56, 0xcd, 0x6e, 0xe3, 0x36,
+	0x10, 0x86, 0xe5, 0x7f, 0x3a, 0x3f, 0x1b, 0x66, 0xb1, 0x50, 0x83, 0x45, 0xb1, 0x10, 0x8a, 0x62,
+	0x0f, 0x6d, 0x80, 0xa6, 0x87, 0x2e, 0x8a, 0x1e, 0x9a, 0xdf, 0xc6, 0x48, 0x36, 0x1b, 0xc8, 0xeb,
+	0x1c, 0x0a, 0xf4, 0xc0, 0xc8, 0xec, 0x5a, 0x28, 0x2d, 0xb9, 0xa2, 0xec, 0x85, 0x73, 0x68, 0xdf,
+	0xa1, 0xa7, 0x3e, 0x4d, 0x9f, 0xa3, 0xe8, 0x4b, 0xf4, 0x15, 0xca, 0xe1, 0x50, 0x14, 0x69, 0xfb,
+	0xd2, 0xdb, 0xcc, 0x37, 0x1c, 0x72, 0x38, 0xdf, 0xa7, 0xa1, 0xc8, 0xa0, 0x5c, 0xcd, 0xb9, 0x3c,
+	0x9e, 0x17, 0x79, 0x99, 0xd3, 0xf6, 0xf2, 0x2b, 0x36, 0x4f, 0xa3, 0x7f, 0x9b, 0xa4, 0x7d, 0x51,
+	0xa4, 0x4b, 0x4e, 0x29, 0x69, 0x8d, 0xc7, 0xc3, 0x8b, 0xb0, 0xf1, 0xaa, 0xf1, 0xba, 0x1f, 0x6b,
+	0x9b, 0x3e, 0x23, 0xcd, 0x07, 0x05, 0x05, 0x1a, 0x02, 0x13, 0x90, 0x7b, 0x85, 0x34, 0x11, 0x51,
+	0x26, 0x8d, 0xc8, 0xce, 0x88, 0x17, 0x29, 0x13, 0x77, 0x8b, 0xd9, 0x23, 0x2f, 0xc2, 0x96, 0x0e,
+	0x79, 0x18, 0x7d, 0x41, 0x3a, 0xd7, 0x9c, 0x89, 0x72, 0x1a, 0xb6, 0x75, 0xd4, 0x78, 0x70, 0xe6,
+	0x7b, 0x55, 0x53, 0xd8, 0xc1, 0x33, 0xc1, 0x06, 0x6c, 0x94, 0x3e, 0xf1, 0xb0, 0xab, 0xb0, 0x66,
+	0xac, 0x6d, 0xc8, 0x1f, 0x95, 0xac, 0x5c, 0xc8, 0xb0, 0x87, 0xf9, 0xe8, 0xd1, 0xe7, 0xa4, 0x3d,
+	0x96, 0xec, 0x03, 0x0f, 0xfb, 0x1a, 0x46, 0x07, 0x56, 0xdf, 0xe5, 0x13, 0x3e, 0x9c, 0x84, 0x04,
+	0x57, 0xa3, 0x07, 0x3b, 0xdf, 0x33, 0x55, 0xc3, 0x00, 0x4f, 0x03, 0x9b, 0xbe, 0x24, 0xfd, 0xcb,
+	0x2c, 0x11, 0xb9, 0x5c, 0x14, 0x3c, 0xdc, 0xd1, 0x81, 0x1a, 0xd0, 0xb5, 0x88, 0xbc, 0x0c, 0x77,
+	0x31, 0x03, 0x6c, 0xe8, 0xc0, 0x19, 0x5b, 0x85, 0x7b, 0xd8, 0x01, 0x65, 0xd2, 0x23, 0xd2, 0xbb,
+	0x4a, 0x8b, 0xd9, 0x47, 0xa6, 0xb6, 0xd8, 0xd7, 0xb0, 0xf5, 0x71, 0xff, 0xc9, 0xa2, 0x60, 0x59,
+	0xc2, 0xc3, 0x67, 0xfa, 0x4a, 0x35, 0x00, 0x99, 0xb7, 0x97, 0x17, 0x70, 0x19, 0x1e, 0x1e, 0x60,
+	0x66, 0xe5, 0x43, 0x6c, 0x28, 0x47, 0x2b, 0x59, 0xf2, 0x59, 0x48, 0x55, 0xac, 0x17, 0x5b, 0x9f,
+	0x86, 0xa4, 0x3b, 0x94, 0xe7, 0x82, 0xb3, 0x2c, 0x3c, 0xd4, 0xa1, 0xca, 0xa5, 0xaf, 0xc8, 0xe0,
+	0x3d, 0x9f, 0xcd, 0x79, 0xa1, 0xfa, 0xa3, 0xca, 0x79, 0xae, 0x4f, 0x74, 0xa1, 0xe8, 0xcf, 0x16,
+	0xe9, 0x3c, 0xe4, 0x62, 0x31, 0xe3, 0x74, 0x8f, 0x04, 0xaa, 0x49, 0x48, 0xb8, 0xb2, 0x74, 0x39,
+	0x79, 0xc2, 0xca, 0x34, 0xcf, 0x0c, 0xe7, 0xd6, 0x07, 0x9a, 0x2b, 0x5b, 0x53, 0x86, 0x0a, 0xf0,
+	0x30, 0x2d, 0x85, 0x32, 0x2f, 0x14, 0x07, 0xe7, 0x82, 0x49, 0x69, 0xa5, 0xe0, 0x60, 0x0e, 0x39,
+	0x6d, 0x8f, 0x1c, 0x85, 0xbf, 0xfb, 0x98, 0xf1, 0x42, 0x2a, 0x31, 0x34, 0x01, 0x47, 0x6f, 0xab,
+	0x1c, 0x14, 0xf6, 0x56, 0x65, 0x19, 0x31, 0x68, 0xdb, 0x4a, 0xa9, 0xef, 0x48, 0xa9, 0x96, 0x1d,
+	0xf1, 0x64, 0xf7, 0x05, 0x39, 0x78, 0xa7, 0xfb, 0xa1, 0x0a, 0x67, 0xc2, 0x28, 0x0b, 0x55, 0xb1,
+	0x19, 0x00, 0x0a, 0xcf, 0x47, 0x43, 0xb3, 0xca, 0x48, 0xc4, 0x02, 0xb5, 0x04, 0x77, 0x5d, 0x09,
+	0x02, 0xed, 0xf3, 0x29, 0x9f, 0xa9, 0xbd, 0x84, 0x96, 0x4a, 0x2f, 0xae, 0x01, 0xfa, 0x29, 0x21,
+	0x4a, 0x63, 0xc5, 0x6a, 0xae, 0x3b, 0x8d, 0x92, 0x71, 0x10, 0x88, 0xdf, 0xb2, 0xa7, 0xd5, 0x55,
+	0x5e, 0xcc, 0x58, 0xa9, 0x55, 0xd3, 0x8b, 0x1d, 0x44, 0x57, 0xc4, 0x92, 0x29, 0xd7, 0x4d, 0x38,
+	0x30, 0x15, 0x55, 0x80, 0x8d, 0xea, 0xb6, 0x51, 0x94, 0x9c, 0x05, 0x80, 0x63, 0x50, 0x83, 0x00,
+	0xc9, 0x1d, 0x22, 0xc7, 0x95, 0x1f, 0xfd, 0x4e, 0x0e, 0x4e, 0x97, 0x2c, 0x15, 0xec, 0x51, 0xf0,
+	0x73, 0x36, 0x67, 0x49, 0x5a, 0xae, 0x3c, 0x51, 0x34, 0xd6, 0x44, 0x51, 0x93, 0x19, 0x78, 0x64,
+	0x2a, 0x21, 0x48, 0x57, 0x08, 0x46, 0x2c, 0x2e, 0x66, 0x89, 0x6d, 0xd5, 0xc4, 0x46, 0xff, 0x34,
+	0xc8, 0xcb, 0x8d, 0x0a, 0x62, 0x2e, 0x79, 0xb1, 0xc4, 0x03, 0xd5, 0xdd, 0xee, 0xd8, 0x8c, 0x4b,
+	0x15, 0xe1, 0xa6, 0x9a, 0x1a, 0x70, 0xc6, 0x44, 0xe0, 0x8d, 0x89, 0x6f, 0xc8, 0x0e, 0x14, 0x16,
+	0xf3, 0x5f, 0x17, 0x5c, 0x96, 0x58, 0xce, 0xe0, 0xe4, 0xf0, 0x58, 0x8f, 0xc0, 0x63, 0x37, 0x14,
+	0x7b, 0x0b, 0xe9, 0x0d, 0x39, 0x74, 0x4e, 0xb7, 0xf9, 0x2d, 0xa5, 0xd0, 0xc1, 0xc9, 0x27, 0x26,
+	0x7f, 0x73, 0x45, 0xbc, 0x2d, 0x2b, 0xba, 0xf6, 0xab, 0x80, 0xbb, 0x18, 0x9b, 0xc3, 0x47, 0x08,
+	0xa2, 0xaf, 0x01, 0x68, 0x3b, 0x6e, 0xc2, 0xa1, 0xb9, 0x10, 0xb4, 0x7e, 0xf4, 0x44, 0xe8, 0xe6,
+	0x01, 0xf4, 0x7b, 0xb2, 0x5f, 0xb7, 0x4c, 0x43, 0xba, 0x43, 0x83, 0x93, 0x17, 0xa6, 0xd0, 0xb5,
+	0x68, 0xbc, 0xbe, 0x1c, 0x68, 0x73, 0xf6, 0x95, 0xe6, 0x5c, 0x0f, 0x8b, 0x7e, 0xda, 0x38, 0x05,
+	0x98, 0x04, 0x0e, 0xaa, 0x97, 0x03, 0xec, 0x8d, 0x51, 0x10, 0x6c, 0x19, 0x05, 0x95, 0x02, 0x9a,
+	0x8e, 0x02, 0xfe, 0x6a, 0x10, 0x7a, 0x9b, 0x7f, 0x48, 0x13, 0x26, 0x70, 0x48, 0xfd, 0x50, 0xe4,
+	0x8b, 0xf9, 0xd6, 0x23, 0x00, 0x83, 0x0f, 0x20, 0x30, 0x98, 0xd1, 0x7e, 0x25, 0x4e, 0xa0, 0x59,
+	0xf7, 0xd4, 0x02, 0xdb, 0x24, 0x07, 0xdf, 0x1a, 0x1e, 0x14, 0xf3, 0x9f, 0xa5, 0x9a, 0x49, 0x90,
+	0xe2, 0x20, 0x8e, 0xa6, 0x3a, 0x9e, 0xa6, 0xea, 0xd9, 0xd2, 0x75, 0x67, 0x4b, 0xf4, 0x77, 0x80,
+	0x65, 0x6d, 0x7d, 0x4f, 0xdf, 0x90, 0xfe, 0xe9, 0x64, 0x52, 0x70, 0x29, 0x39, 0x76, 0x77, 0x70,
+	0x72, 0xe4, 0xa8, 0xf0, 0xd8, 0x06, 0x2f, 0xb3, 0xb2, 0x58, 0xc5, 0xf5, 0x62, 0xc8, 0x1c, 0x97,
+	0xa9, 0x48, 0xcb, 0x94, 0xe3, 0xc5, 0xd6, 0x32, 0x6d, 0xd0, 0x64, 0x5a, 0x9f, 0xbe, 0x26, 0xfb,
+	0xf7, 0xf0, 0xe2, 0x27, 0xb9, 0x78, 0x50, 0x03, 0x15, 0x3e, 0x63, 0x9c, 0xcb, 0xeb, 0x30, 0x70,
+	0x06, 0xd4, 0x3e, 0x56, 0xc7, 0x60, 0x33, 0x3c, 0xec, 0xe8, 0x3b, 0xb2, 0xe7, 0x17, 0x09, 0xef,
+	0xe1, 0x2f, 0x7c, 0x65, 0xae, 0x09, 0x26, 0x8c, 0xc4, 0x25, 0x13, 0x8b, 0x8a, 0x19, 0x74, 0xbe,
+	0x0d, 0xde, 0x34, 0x20, 0xdb, 0x2f, 0xf4, 0xff, 0x64, 0x47, 0x42, 0xbd, 0xdf, 0x79, 0x2e, 0x7c,
+	0x92, 0x1b, 0xeb, 0x24, 0x7f, 0x4e, 0xf6, 0x5c, 0x95, 0xf1, 0x4a, 0xc6, 0x6b, 0x28, 0x10, 0x6f,
+	0x27, 0x47, 0xa5, 0x15, 0x07, 0x89, 0xfe, 0x08, 0x08, 0xd1, 0x7f, 0x46, 0x67, 0xac, 0x4c, 0xa6,
+	0xd0, 0x1c, 0x68, 0xf4, 0x88, 0x0b, 0x9e, 0xa8, 0x8d, 0x4c, 0xc5, 0x1e, 0x06, 0xa5, 0xc3, 0x04,
+	0x16, 0x55, 0xe9, 0xda, 0xb1, 0x2f, 0x53, 0xd3, 0x79, 0x99, 0xd4, 0x15, 0xec, 0x43, 0x63, 0xe8,
+	0xa8, 0x01, 0xfa, 0x19, 0xd9, 0x3d, 0xcd, 0xb2, 0xbc, 0xd4, 0xde, 0x8d, 0x6a, 0x0f, 0x3e, 0x95,
+	0x3e, 0x08, 0xc4, 0xd6, 0xc0, 0x83, 0x6e, 0x19, 0x4a, 0x74, 0x1d, 0x76, 0x34, 0xdc, 0xf5, 0x34,
+	0xac, 0x7e, 0x23, 0xc6, 0xf3, 0x09, 0x83, 0xf9, 0xd3, 0xd3, 0xf7, 0xaf, 0x5c, 0xc8, 0xb8, 0x52,
+	0x63, 0x58, 0x05, 0xfa, 0xf8, 0x1a, 0xa3, 0x17, 0x4d, 0xc9, 0xce, 0x75, 0x0a, 0x63, 0x7c, 0x85,
+	0xf4, 0xc1, 0xdd, 0x52, 0xf3, 0x5d, 0xaa, 0x2f, 0x0a, 0x6c, 0x7b, 0xdf, 0xc0, 0x7f, 0x89, 0x63,
+	0xce, 0xa4, 0xba, 0x2c, 0x76, 0xc1, 0x78, 0x50, 0xc1, 0x5b, 0xa5, 0x25, 0x78, 0x3f, 0xb1, 0x0b,
+	0x95, 0x1b, 0xfd, 0x46, 0xba, 0xe6, 0x24, 0xd8, 0xf0, 0x26, 0xcd, 0xaa, 0x1f, 0x15, 0x6d, 0xdb,
+	0x81, 0x10, 0x38, 0x03, 0xc1, 0x7b, 0x1c, 0x9a, 0xeb, 0x8f, 0xc3, 0x97, 0xa4, 0x0b, 0x35, 0x83,
+	0xb0, 0x71, 0x7e, 0x57, 0xf3, 0xdf, 0xbd, 0x50, 0x5c, 0xad, 0x39, 0xeb, 0xfe, 0x88, 0x7f, 0xc8,
+	0x8f, 0x1d, 0xfd, 0xbf, 0xfc, 0xf5, 0x7f, 0x66, 0xb5, 0x11, 0x98, 0x3e, 0x0b, 0x00, 0x00,
 }
@@ -26,14 +26,25 @@ import (
 
 // AvailableCapacity is the Schema for the availablecapacities API
 // +kubebuilder:resource:scope=Cluster,shortName={ac,acs}
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="SIZE",type="string",JSONPath=".spec.Size",description="Size of AvailableCapacity"
 // +kubebuilder:printcolumn:name="STORAGE CLASS",type="string",JSONPath=".spec.storageClass",description="StorageClass of AvailableCapacity"
 // +kubebuilder:printcolumn:name="LOCATION",type="string",JSONPath=".spec.Location",description="Drive/LVG UUID used by AvailableCapacity"
 // +kubebuilder:printcolumn:name="NODE",type="string",JSONPath=".spec.NodeId",description="Node id of Available Capacity"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether available capacity is ready",priority=1
 type AvailableCapacity struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Spec              api.AvailableCapacity `json:"spec,omitempty"`
+	Spec              api.AvailableCapacity   `json:"spec,omitempty"`
+	Status            AvailableCapacityStatus `json:"status,omitempty"`
+}
+
+// AvailableCapacityStatus holds status conditions of available capacity, conditions are derived from spec on each update of CR
+type AvailableCapacityStatus struct {
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -55,4 +66,5 @@ func (in *AvailableCapacity) DeepCopyInto(out *AvailableCapacity) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
@@ -5,6 +5,7 @@
 package accrd
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -57,3 +58,25 @@ func (in *AvailableCapacityList) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AvailableCapacityStatus) DeepCopyInto(out *AvailableCapacityStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AvailableCapacityStatus.
+func (in *AvailableCapacityStatus) DeepCopy() *AvailableCapacityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AvailableCapacityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
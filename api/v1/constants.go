@@ -23,6 +23,9 @@ const (
 	LVGKind                          = "LogicalVolumeGroup"
 	DriveKind                        = "Drive"
 	CSIBMNodeKind                    = "Node"
+	PoolKind                         = "Pool"
+	DriveBatchKind                   = "DriveBatch"
+	HistoryKind                      = "History"
 
 	Version            = "v1"
 	CSICRsGroupVersion = "csi-baremetal.dell.com"
@@ -39,6 +42,9 @@ const (
 	Empty       = ""
 	Resizing    = "RESIZING"
 	Resized     = "RESIZED"
+	// Formatting is a pseudo status of Creating volume which partition is ready and filesystem is being created
+	// asynchronously, it isn't stored in Volume CR and is used in WaitStatus only
+	Formatting = "FORMATTING"
 
 	// Health statuses
 	HealthUnknown = "UNKNOWN"
@@ -64,10 +70,87 @@ const (
 	DriveTypeSSD  = "SSD"
 	DriveTypeNVMe = "NVME"
 
+	// DriveMgrAPIVersion is a version of DriveService gRPC API which is supported by node service
+	DriveMgrAPIVersion = "v1"
+	// Optional DriveService methods which drive manager reports in capabilities
+	DriveMgrCapabilityLocate     = "LOCATE"
+	DriveMgrCapabilityLocateNode = "LOCATE_NODE"
+
+	// NodeProtocolVersion is a version of protocol between controller and node services over Volume CRs,
+	// node service reports it in Node CR
+	NodeProtocolVersion = "v1"
+	// Optional Volume CR features which node service reports in capabilities
+	NodeCapabilityEncryption = "ENCRYPTION"
+	NodeCapabilityLazyFormat = "LAZY_FORMAT"
+	NodeCapabilityCache      = "CACHE"
+	NodeCapabilityTemplate   = "TEMPLATE"
+
 	// Drive annotations
 	DriveAnnotationRemoval            = "removal"
 	DriveAnnotationRemovalReady       = "ready"
 	DriveAnnotationVolumeStatusPrefix = "status"
+	// DriveAnnotationTemperature is set on Drive and AvailableCapacity CRs when drive temperature exceeds threshold
+	DriveAnnotationTemperature = "temperature"
+	DriveTemperatureHigh       = "high"
+	// DriveAnnotationCordon excludes drive from capacity planning until annotation is removed
+	DriveAnnotationCordon = "cordon"
+	// DriveAnnotationEvacuation is set on Drive CR when evacuation of volumes from BAD drive was performed
+	DriveAnnotationEvacuation           = "evacuation"
+	DriveAnnotationEvacuationDone       = "done"
+	DriveAnnotationEvacuationInProgress = "in-progress"
+	// DriveAnnotationEvacuationBlocked lists pods which eviction is postponed by PodDisruptionBudgets
+	DriveAnnotationEvacuationBlocked = "evacuation/blocked"
+	// DriveAnnotationEvacuationEvicted lists pods which were evicted while evacuation is in progress
+	DriveAnnotationEvacuationEvicted = "evacuation/evicted"
+	// DriveAnnotationStandby is set on free Drive CR with pre-created partition and filesystem, value is FS type
+	DriveAnnotationStandby = "standby"
+	// DriveAnnotationStandbyPartUUID holds UUID of pre-created partition of standby drive
+	DriveAnnotationStandbyPartUUID = "standby/partition-uuid"
+	// DriveAnnotationReservedFor is set on Drive and AvailableCapacity CRs of drive which is held for pods of
+	// DaemonSet drive reservation, value is name of the reservation
+	DriveAnnotationReservedFor = "reserved-for"
+	// DriveAnnotationReplacementOf is set on Drive CR of drive which was installed in slot of BAD or removed drive,
+	// value is serial number of replaced drive
+	DriveAnnotationReplacementOf = "replacement/of"
+	// DriveAnnotationBurnIn holds state of burn-in test of new drive, drive isn't schedulable until test is passed
+	DriveAnnotationBurnIn           = "burn-in"
+	DriveAnnotationBurnInPending    = "pending"
+	DriveAnnotationBurnInInProgress = "in-progress"
+	DriveAnnotationBurnInPassed     = "passed"
+	DriveAnnotationBurnInFailed     = "failed"
+	DriveAnnotationBurnInSkipped    = "skipped"
+	// DriveAnnotationBurnInProgress holds percent of elapsed duration of running burn-in test
+	DriveAnnotationBurnInProgress = "burn-in/progress"
+	// DriveAnnotationBurnInResult holds result of finished burn-in test
+	DriveAnnotationBurnInResult = "burn-in/result"
+	// DriveAnnotationForeignData holds comma separated signatures (filesystem, LVM PV, RAID, LUKS, partition table)
+	// found on new drive, drive isn't clean until DriveAnnotationClean is set
+	DriveAnnotationForeignData        = "foreign-data"
+	DriveAnnotationForeignDataPending = "pending"
+	// DriveAnnotationClean confirms that data on drive with foreign signatures can be destroyed, value is "true"
+	DriveAnnotationClean = "clean"
+	// DriveAnnotationWriteCache holds state of volatile write cache of drive which is controlled by write cache policy
+	DriveAnnotationWriteCache            = "write-cache"
+	DriveAnnotationWriteCacheEnabled     = "enabled"
+	DriveAnnotationWriteCacheDisabled    = "disabled"
+	DriveAnnotationWriteCacheUnsupported = "unsupported"
+	DriveAnnotationWriteCacheFailed      = "failed"
+	// DriveAnnotationGrownFrom holds size of drive in bytes before it became larger, e.g. after expansion of
+	// RAID virtual disk, while added space isn't used by partition, PV and AvailableCapacity of the drive
+	DriveAnnotationGrownFrom = "growth/from"
+	// DriveAnnotationCircuit is set on Drive CR while circuit of the drive is open, value is DriveAnnotationCircuitOpen.
+	// Unlike SUSPECT health it doesn't release the drive, annotation is removed when circuit is closed
+	DriveAnnotationCircuit     = "circuit"
+	DriveAnnotationCircuitOpen = "open"
+	// DriveAnnotationSelfTest holds UUID of test partition while storage self-test of node runs on the drive,
+	// drive isn't schedulable while annotation is set
+	DriveAnnotationSelfTest = "self-test"
+	// DriveAnnotationPartUUIDs holds comma separated GUIDs of partitions found on drive, it is reported by node service
+	// when check of duplicated partition GUIDs is enabled
+	DriveAnnotationPartUUIDs = "partition-uuids"
+	// DriveAnnotationDuplicatePartUUID holds comma separated GUIDs of drive partitions which are found on other drive
+	// too, e.g. after clone of the disk. Drive isn't schedulable until duplication is resolved
+	DriveAnnotationDuplicatePartUUID = "duplicate/partition-uuid"
 	// Deprecated annotations
 	DriveAnnotationReplacement = "replacement"
 
@@ -91,6 +174,71 @@ const (
 	VolumeAnnotationReleaseFailed = "failed"
 	VolumeAnnotationReleaseStatus = "status"
 
+	// DriveBatch operations and statuses
+	DriveBatchOperationAnnotate         = "annotate"
+	DriveBatchOperationRemoveAnnotation = "remove-annotation"
+	DriveBatchStatusInProgress          = "IN_PROGRESS"
+	DriveBatchStatusDone                = "DONE"
+	DriveBatchStatusFailed              = "FAILED"
+
+	// VolumeFinalizer is set on Volume CR by node service which owns the volume and removed after storage is released
+	VolumeFinalizer = "dell.emc.csi/volume-cleanup"
+	// VolumeForceCleanupAnnotation allows to remove finalizer of terminating Volume CR which is stuck in unexpected
+	// status or located on unreachable node, storage of the volume isn't released in this case
+	VolumeForceCleanupAnnotation = "csi-baremetal.dell.com/force-cleanup"
+	VolumeForceCleanupValue      = "true"
+
+	// VolumeAnnotationFormat reports progress of asynchronous filesystem creation of Creating volume
+	VolumeAnnotationFormat           = "format/status"
+	VolumeAnnotationFormatInProgress = "in-progress"
+	VolumeAnnotationFormatDone       = "done"
+	VolumeAnnotationFormatFailed     = "failed"
+	// VolumeAnnotationFormatError holds error of failed asynchronous filesystem creation
+	VolumeAnnotationFormatError = "format/error"
+	// VolumeAnnotationPartUUID holds GUID of volume partition recorded after volume creation
+	VolumeAnnotationPartUUID = "ownership/partition-uuid"
+	// VolumeAnnotationFSUUID holds UUID of volume filesystem recorded after volume creation
+	VolumeAnnotationFSUUID = "ownership/fs-uuid"
+	// VolumeAnnotationCacheState holds state of bcache device of cached volume: clean, dirty, inconsistent, no cache
+	VolumeAnnotationCacheState = "cache/state"
+	// VolumeAnnotationCacheHitRatio holds cache hit ratio of cached volume in percents
+	VolumeAnnotationCacheHitRatio = "cache/hit-ratio"
+	// VolumeAnnotationCacheDirtyData holds amount of cached data which isn't written to the drive yet
+	VolumeAnnotationCacheDirtyData = "cache/dirty-data"
+	// VolumeAnnotationPublishTargets holds JSON map of target paths of published volume to names of their pods,
+	// volume in block mode might be published to several targets at once, e.g. during KubeVirt disk hot-plug
+	VolumeAnnotationPublishTargets = "publish/targets"
+	// VolumeAnnotationLazyUnmount holds boot ID of the node when staging path of volume was lazily unmounted
+	// during NodeUnstageVolume, leaked file handles keep file system busy until the node is rebooted
+	VolumeAnnotationLazyUnmount = "unstage/lazy-unmount"
+	// VolumeAnnotationAlignment holds state of partition alignment audit of volume
+	VolumeAnnotationAlignment           = "alignment/status"
+	VolumeAnnotationAlignmentMisaligned = "misaligned"
+	VolumeAnnotationAlignmentInProgress = "in-progress"
+	VolumeAnnotationAlignmentDone       = "done"
+	VolumeAnnotationAlignmentFailed     = "failed"
+	// VolumeAnnotationAlignmentImpact holds impact of misalignment of volume partition on performance
+	VolumeAnnotationAlignmentImpact = "alignment/impact"
+	// VolumeAnnotationAlignmentError holds error of the last partition realignment of volume
+	VolumeAnnotationAlignmentError = "alignment/error"
+	// VolumeAnnotationNBDExport requests read-only export of volume snapshot over NBD for duration, e.g. 2h
+	VolumeAnnotationNBDExport = "nbd-export"
+	// VolumeAnnotationNBDExportStatus holds state of NBD export of volume snapshot
+	VolumeAnnotationNBDExportStatus  = "nbd-export/status"
+	VolumeAnnotationNBDExportActive  = "active"
+	VolumeAnnotationNBDExportExpired = "expired"
+	VolumeAnnotationNBDExportFailed  = "failed"
+	// VolumeAnnotationNBDExportExpires holds time in RFC3339 format when NBD export of volume snapshot is stopped
+	VolumeAnnotationNBDExportExpires = "nbd-export/expires"
+	// VolumeAnnotationNBDExportError holds error of failed NBD export of volume snapshot
+	VolumeAnnotationNBDExportError = "nbd-export/error"
+	// VolumeAnnotationNBDExportClients holds comma-separated names of client certificates which are allowed
+	// to read NBD export of volume snapshot, it is required to start the export
+	VolumeAnnotationNBDExportClients = "nbd-export/clients"
+	// VolumeAnnotationNBDExportReserved holds size in bytes which is reserved in AvailableCapacity of LVG
+	// for snapshot of NBD export
+	VolumeAnnotationNBDExportReserved = "nbd-export/reserved"
+
 	//Volume expansion annotations
 	VolumePreviousStatus   = "expansion/previous-status"
 	VolumePreviousCapacity = "expansion/previous-capacity"
@@ -100,8 +248,49 @@ const (
 	ModeRAWPART = "RAW_PART"
 	ModeFS      = "FS"
 
+	// EncryptionLUKS - volume is encrypted with LUKS, key is passed in node stage secret
+	EncryptionLUKS = "luks"
+	// EncryptionKeySecretKey is a key of node stage secret which contains volume encryption passphrase
+	EncryptionKeySecretKey = "encryptionKey"
+
+	// CacheModeBcache - HDD volume is fronted by bcache device with SSD cache
+	CacheModeBcache = "bcache"
+
+	// PVAnnotationDriveFailed is set on PersistentVolume which is located on BAD drive, value is drive UUID
+	PVAnnotationDriveFailed = "csi-baremetal.dell.com/drive-failed"
+	// PodAnnotationEvictOnDriveFailure opts pod in eviction when drive with its volumes becomes BAD
+	PodAnnotationEvictOnDriveFailure = "csi-baremetal.dell.com/evict-on-drive-failure"
+	// PVLabelRetainData protects data of PersistentVolume from deletion, DeleteVolume is rejected while
+	// PVAnnotationConfirmDataDeletion isn't set, value is "true"
+	PVLabelRetainData = "retain-data"
+	// PVAnnotationConfirmDataDeletion confirms deletion of data of PersistentVolume with PVLabelRetainData,
+	// value is "true"
+	PVAnnotationConfirmDataDeletion = "csi-baremetal.dell.com/confirm-data-deletion"
+	// PVCAnnotationPinnedDrive pins volume of PersistentVolumeClaim to the drive, value is UUID of Drive CR
+	PVCAnnotationPinnedDrive = "csi-baremetal.dell.com/pinned-drive"
+	// NodeConditionStorageSelfTestFailed is True on kubernetes Node until storage self-test of node service passes,
+	// scheduler extender skips such node for pods with volumes
+	NodeConditionStorageSelfTestFailed = "StorageSelfTestFailed"
+	// PodLabelDriveReservation is a label of DaemonSet pods which volumes use drives held for the reservation,
+	// value is name of the reservation
+	PodLabelDriveReservation = "csi-baremetal.dell.com/drive-reservation"
+
 	//LVG annotations
 	LVGFreeSpaceAnnotation = "lvg/free-space"
+	// LVGMissingPVsAnnotation holds count of PVs which are missing in VG, VG is activated in degraded mode
+	LVGMissingPVsAnnotation = "lvg/missing-pvs"
+	// LVGRepairAnnotation triggers repair action for VG with missing PVs or corrupted metadata
+	LVGRepairAnnotation = "lvg/repair"
+	// LVGRepairRemoveMissing removes missing PVs from VG (vgreduce --removemissing)
+	LVGRepairRemoveMissing = "remove-missing"
+	// LVGRepairRestoreMetadata restores VG metadata from backup which is archived by node service (vgcfgrestore)
+	LVGRepairRestoreMetadata = "restore-metadata"
+	LVGRepairFailed          = "failed"
+	// LVGRestoreMetadataSeqNoAnnotation selects metadata seqno of backup for LVGRepairRestoreMetadata,
+	// the latest backup is restored if it isn't set
+	LVGRestoreMetadataSeqNoAnnotation = "lvg/restore-metadata-seqno"
+	// LVGCircuitAnnotation is set on LVG CR while circuit of the volume group is open, value is DriveAnnotationCircuitOpen
+	LVGCircuitAnnotation = "lvg/circuit"
 
 	// Volume location type
 	LocationTypeDrive = "DRIVE"
@@ -134,4 +323,12 @@ const (
 	LocateStatusNotAvailable = int32(2)
 
 	DockerImageKernelVersion = "5.4"
+
+	// Types of status conditions of Drive, Volume, LogicalVolumeGroup and AvailableCapacity CRs
+	ConditionReady   = "Ready"
+	ConditionHealthy = "Healthy"
+	// Reasons of Ready condition of AvailableCapacity CR, reasons of other conditions are derived from spec statuses,
+	// e.g. VOLUME_READY status is VolumeReady reason
+	ConditionReasonCapacityAvailable = "CapacityAvailable"
+	ConditionReasonNoCapacity        = "NoCapacity"
 )
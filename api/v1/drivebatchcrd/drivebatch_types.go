@@ -0,0 +1,71 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivebatchcrd
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// DriveBatch is the Schema for the DriveBatches API, batch applies annotation operation to Drive CRs which match
+// node selector, model and type
+// +kubebuilder:resource:scope=Cluster,shortName={batch,batches}
+// +kubebuilder:printcolumn:name="OPERATION",type="string",JSONPath=".spec.Operation",description="Operation applied to drives"
+// +kubebuilder:printcolumn:name="ANNOTATION",type="string",JSONPath=".spec.AnnotationKey",description="Annotation key"
+// +kubebuilder:printcolumn:name="NODE SELECTOR",type="string",JSONPath=".spec.NodeSelector",description="Selector of nodes"
+// +kubebuilder:printcolumn:name="MODEL",type="string",JSONPath=".spec.Model",description="Drive model"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".spec.Status",description="Batch status"
+type DriveBatch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              api.DriveBatch `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DriveBatchList contains a list of DriveBatch
+// +kubebuilder:object:generate=true
+type DriveBatchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DriveBatch `json:"items"`
+}
+
+func init() {
+	SchemeBuilderDriveBatch.Register(&DriveBatch{}, &DriveBatchList{})
+}
+
+// DeepCopyInto needs to be declared because api.DriveBatch doesn't have DeepCopyInto
+func (in *DriveBatch) DeepCopyInto(out *DriveBatch) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = api.DriveBatch{
+		NodeSelector:    in.Spec.NodeSelector,
+		Model:           in.Spec.Model,
+		Type:            in.Spec.Type,
+		Operation:       in.Spec.Operation,
+		AnnotationKey:   in.Spec.AnnotationKey,
+		AnnotationValue: in.Spec.AnnotationValue,
+		Status:          in.Spec.Status,
+		Updated:         append([]string(nil), in.Spec.Updated...),
+		Failed:          append([]string(nil), in.Spec.Failed...),
+	}
+}
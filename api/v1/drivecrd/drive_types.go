@@ -22,6 +22,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -31,6 +32,7 @@ import (
 
 // Drive is the Schema for the drives API
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="SIZE",type="string",JSONPath=".spec.Size",description="Drive capacity"
 // +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".spec.Type",description="Drive type (HDD/LVG/NVME)"
 // +kubebuilder:printcolumn:name="HEALTH",type="string",JSONPath=".spec.Health",description="Drive health status"
@@ -41,11 +43,21 @@ import (
 // +kubebuilder:printcolumn:name="SERIAL NUMBER",type="string",JSONPath=".spec.SerialNumber",description="Drive serial number"
 // +kubebuilder:printcolumn:name="NODE",type="string",JSONPath=".spec.NodeId",description="Drive node location"
 // +kubebuilder:printcolumn:name="SLOT",type="string",JSONPath=".spec.Slot",description="Drive slot"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether drive is ready",priority=1
 type Drive struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec api.Drive `json:"spec,omitempty"`
+	Spec   api.Drive   `json:"spec,omitempty"`
+	Status DriveStatus `json:"status,omitempty"`
+}
+
+// DriveStatus holds status conditions of drive, conditions are derived from spec on each update of CR
+type DriveStatus struct {
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -64,6 +76,7 @@ func (in *Drive) DeepCopyInto(out *Drive) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 func init() {
@@ -79,7 +92,19 @@ func (in *Drive) Equals(drive *api.Drive) bool {
 		in.Spec.Health == drive.Health &&
 		in.Spec.Type == drive.Type &&
 		in.Spec.Size == drive.Size &&
-		in.Spec.Path == drive.Path
+		in.Spec.Path == drive.Path &&
+		in.Spec.Enclosure == drive.Enclosure &&
+		in.Spec.Slot == drive.Slot
+}
+
+// IsBurnInBlocking returns true if burn-in test of drive is pending, running or failed,
+// such drive isn't available for volumes
+func (in *Drive) IsBurnInBlocking() bool {
+	switch in.GetAnnotations()[apiV1.DriveAnnotationBurnIn] {
+	case apiV1.DriveAnnotationBurnInPending, apiV1.DriveAnnotationBurnInInProgress, apiV1.DriveAnnotationBurnInFailed:
+		return true
+	}
+	return false
 }
 
 func (in *Drive) GetDriveDescription() string {
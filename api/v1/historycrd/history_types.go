@@ -0,0 +1,75 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package historycrd
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// History is the Schema for the Histories API, history keeps lifecycle events of Drive or Volume CR
+// and isn't removed with the object
+// +kubebuilder:resource:scope=Cluster,shortName={hist,hists}
+// +kubebuilder:printcolumn:name="KIND",type="string",JSONPath=".spec.Kind",description="Kind of the object"
+// +kubebuilder:printcolumn:name="OBJECT",type="string",JSONPath=".spec.Name",description="Name of the object"
+// +kubebuilder:printcolumn:name="NAMESPACE",type="string",JSONPath=".spec.Namespace",description="Namespace of the object"
+// +kubebuilder:printcolumn:name="LAST REASON",type="string",JSONPath=".spec.Entries[-1:].Reason",description="Reason of the last event"
+type History struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              api.History `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HistoryList contains a list of History
+// +kubebuilder:object:generate=true
+type HistoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []History `json:"items"`
+}
+
+func init() {
+	SchemeBuilderHistory.Register(&History{}, &HistoryList{})
+}
+
+// DeepCopyInto needs to be declared because api.History doesn't have DeepCopyInto
+func (in *History) DeepCopyInto(out *History) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = api.History{
+		Kind:      in.Spec.Kind,
+		Name:      in.Spec.Name,
+		Namespace: in.Spec.Namespace,
+	}
+	if in.Spec.Entries != nil {
+		out.Spec.Entries = make([]*api.HistoryEntry, len(in.Spec.Entries))
+		for i, entry := range in.Spec.Entries {
+			out.Spec.Entries[i] = &api.HistoryEntry{
+				Time:    entry.Time,
+				Type:    entry.Type,
+				Reason:  entry.Reason,
+				Message: entry.Message,
+			}
+		}
+	}
+}
@@ -26,16 +26,27 @@ import (
 
 // LogicalVolumeGroup is the Schema for the LVGs API
 // +kubebuilder:resource:scope=Cluster,shortName={lvg,lvgs}
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="SIZE",type="string",JSONPath=".spec.Size",description="Size of Logical volume group"
 // +kubebuilder:printcolumn:name="HEALTH",type="string",JSONPath=".spec.Health",description="LVG health"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".spec.Status",description="LVG status",priority=1
 // +kubebuilder:printcolumn:name="LOCATIONS",type="string",JSONPath=".spec.Locations",description="LVG drives locations list"
 // +kubebuilder:printcolumn:name="NODE",type="string",JSONPath=".spec.Node",description="LVG node location"
 // +kubebuilder:printcolumn:name="VOLUMES",type="string",JSONPath=".spec.VolumeRefs",description="Volume references",priority=1
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether logical volume group is ready",priority=1
 type LogicalVolumeGroup struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Spec              api.LogicalVolumeGroup `json:"spec,omitempty"`
+	Spec              api.LogicalVolumeGroup   `json:"spec,omitempty"`
+	Status            LogicalVolumeGroupStatus `json:"status,omitempty"`
+}
+
+// LogicalVolumeGroupStatus holds status conditions of logical volume group, conditions are derived from spec on each update of CR
+type LogicalVolumeGroupStatus struct {
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -58,4 +69,5 @@ func (in *LogicalVolumeGroup) DeepCopyInto(out *LogicalVolumeGroup) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
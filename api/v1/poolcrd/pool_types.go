@@ -0,0 +1,62 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolcrd
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// Pool is the Schema for the Pools API, pool holds drives which volumes of bound StorageClasses and namespaces may use
+// +kubebuilder:resource:scope=Cluster,shortName={pool,pools}
+// +kubebuilder:printcolumn:name="LOCATIONS",type="string",JSONPath=".spec.Locations",description="Pool drives UUIDs"
+// +kubebuilder:printcolumn:name="STORAGE CLASSES",type="string",JSONPath=".spec.StorageClasses",description="Bound StorageClasses"
+// +kubebuilder:printcolumn:name="NAMESPACES",type="string",JSONPath=".spec.Namespaces",description="Bound namespaces"
+type Pool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              api.Pool `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PoolList contains a list of Pool
+// +kubebuilder:object:generate=true
+type PoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Pool `json:"items"`
+}
+
+func init() {
+	SchemeBuilderPool.Register(&Pool{}, &PoolList{})
+}
+
+// DeepCopyInto needs to be declared because api.Pool doesn't have DeepCopyInto
+func (in *Pool) DeepCopyInto(out *Pool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = api.Pool{
+		Locations:      append([]string(nil), in.Spec.Locations...),
+		StorageClasses: append([]string(nil), in.Spec.StorageClasses...),
+		Namespaces:     append([]string(nil), in.Spec.Namespaces...),
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 // Volume is the Schema for the volumes API
 // +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="SIZE",type="string",JSONPath=".spec.Size",description="Volume allocated size"
 // +kubebuilder:printcolumn:name="STORAGE CLASS",type="string",JSONPath=".spec.StorageClass",description="Volume storage class"
 // +kubebuilder:printcolumn:name="HEALTH",type="string",JSONPath=".spec.Health",description="Volume health status"
@@ -35,11 +36,21 @@ import (
 // +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".spec.Type",description="Volume fs type",priority=1
 // +kubebuilder:printcolumn:name="LOCATION",type="string",JSONPath=".spec.Location",description="Volume LVG or drive location"
 // +kubebuilder:printcolumn:name="NODE",type="string",JSONPath=".spec.NodeId",description="Volume node location"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether volume is ready",priority=1
 type Volume struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec api.Volume `json:"spec,omitempty"`
+	Spec   api.Volume   `json:"spec,omitempty"`
+	Status VolumeStatus `json:"status,omitempty"`
+}
+
+// VolumeStatus holds status conditions of volume, conditions are derived from spec on each update of CR
+type VolumeStatus struct {
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -58,6 +69,7 @@ func (in *Volume) DeepCopyInto(out *Volume) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 func init() {
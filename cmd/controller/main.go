@@ -23,14 +23,17 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -38,19 +41,33 @@ import (
 
 	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
 	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivebatchcrd"
 	"github.com/dell/csi-baremetal/api/v1/drivecrd"
 	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
 	"github.com/dell/csi-baremetal/api/v1/volumecrd"
 	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
+	"github.com/dell/csi-baremetal/pkg/base/config"
+	"github.com/dell/csi-baremetal/pkg/base/ctrlopts"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/logger"
 	"github.com/dell/csi-baremetal/pkg/base/logger/objects"
+	basenet "github.com/dell/csi-baremetal/pkg/base/net"
 	"github.com/dell/csi-baremetal/pkg/base/rpc"
+	"github.com/dell/csi-baremetal/pkg/base/sharding"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	"github.com/dell/csi-baremetal/pkg/controller"
+	"github.com/dell/csi-baremetal/pkg/controller/admission"
+	"github.com/dell/csi-baremetal/pkg/controller/alerting"
 	"github.com/dell/csi-baremetal/pkg/controller/capacitycontroller"
+	"github.com/dell/csi-baremetal/pkg/controller/capacitymonitor"
+	"github.com/dell/csi-baremetal/pkg/controller/inventory"
+	"github.com/dell/csi-baremetal/pkg/controller/populator"
+	"github.com/dell/csi-baremetal/pkg/crcontrollers/drivebatch"
 	"github.com/dell/csi-baremetal/pkg/crcontrollers/reservation"
+	"github.com/dell/csi-baremetal/pkg/events"
 	"github.com/dell/csi-baremetal/pkg/metrics"
 )
 
@@ -68,56 +85,279 @@ var (
 		"(example: :8080 which corresponds to port 8080 on local host). The default is empty string, which means metrics endpoint is disabled.")
 	metricspath              = flag.String("metrics-path", "/metrics", "The HTTP path where prometheus metrics will be exposed. Default is /metrics.")
 	sequentialLVGReservation = flag.Bool("sequential-lvg-reservation", false, "disable concurrent reservations for cases with LVG Volumes")
+	useNamespaceQuota        = flag.Bool("namespace-quota", false,
+		"Whether controller should check capacity quotas from namespace annotations during CreateVolume request or not")
+	lowCapacityThresholds = flag.String("low-capacity-thresholds", "",
+		"Minimal free capacity in percents per media type, for example SSD=10,HDD=5. Empty value disables capacity monitoring")
+	shards = flag.Int("shards", 1,
+		"Amount of controller replicas which share reconciliation of Drive, LVG and Volume CRs by node")
+	shardIndex = flag.Int("shard-index", -1,
+		"Index of controller replica, if it is negative, index is taken from ordinal of StatefulSet pod name")
+	volumeTakeoverTimeout = flag.Duration("volume-takeover-timeout", 0,
+		"Time after which controller removes finalizers of terminating volumes on node in PermanentDown state. "+
+			"Zero value disables cleanup of volumes on lost nodes")
+	driveReservations = flag.String("drive-reservations", "",
+		"DaemonSet drive reservations: one free drive of storage class is held on every node for pods with "+
+			"csi-baremetal.dell.com/drive-reservation label, for example logs=HDD. Empty value disables reservations")
+	checkDuplicatePartUUIDs = flag.Bool("check-duplicate-partition-uuids", false,
+		"Whether drives with partitions which GUIDs are found on other drives are excluded from provisioning. "+
+			"Node services must report GUIDs with --report-partition-uuids")
+	storageClassTiers = flag.String("storage-class-tiers", "",
+		"Tiers of k8s StorageClasses in format <storage class>=platinum|gold|bronze, for example fast=platinum,std=gold. "+
+			"Drives with low latency are kept for volumes of higher tiers. Empty value disables tiering")
+	tierPressureThreshold = flag.Int64("tier-pressure-threshold", 10,
+		"Minimal free capacity in percents of drives allowed for StorageClass tier on the node, "+
+			"drives with lower latency are used for the tier below it")
+	drivePinning = flag.Bool("drive-pinning", false,
+		"Allow PVCs to pin volumes to drives with csi-baremetal.dell.com/pinned-drive annotation, value is Drive UUID")
+	inventoryAddress = flag.String("inventory-address", "",
+		"The TCP network address of read-only inventory endpoint with drives, volumes and capacity per node. "+
+			"Empty value disables the endpoint")
+	inventoryTokenFile = flag.String("inventory-token-file", "",
+		"Path to file with bearer token which clients of inventory endpoint must provide")
+	podDeletionWebhookAddress = flag.String("pod-deletion-webhook-address", "",
+		"The TCP network address of validating webhook which rejects deletion of pods with volumes being formatted "+
+			"or resized. Empty value disables the webhook")
+	webhookCertDir = flag.String("webhook-cert-dir", "/etc/csi-baremetal/webhook",
+		"Directory with tls.crt and tls.key of pod deletion webhook")
+	provisioningFreezeConfig = flag.String("provisioning-freeze-config", "",
+		"Path to the file mounted from ConfigMap with provisioning freeze toggle, e.g. "+
+			controller.DefaultProvisioningFreezeConfig+". CreateVolume and DeleteVolume are rejected while freeze is enabled. "+
+			"Empty value disables the toggle")
+	featureGates = flag.String("feature-gates", "",
+		"Comma separated list of Feature=true|false pairs which enable or disable experimental features, "+
+			"e.g. "+featureconfig.FeatureVolumeEncryption+"=false")
+	configPath = flag.String("config", "",
+		"Path to the versioned configuration file mounted from ConfigMap, its parameters override options and "+
+			"environment variables. Log level, low capacity thresholds and profiling are reloaded on change. "+
+			"Empty value disables the file")
+	populators = flag.String("populators", "",
+		"Volume populators of custom data sources in format <group>/<kind>=<image>, for example "+
+			"example.com/Dataset=registry/dataset-populator:1.0. PVCs which dataSourceRef points to the kind are "+
+			"filled by pod with the image. Empty value disables populators")
+	alertingURL = flag.String("alerting-url", "",
+		"URL of webhook or Alertmanager API (/api/v2/alerts) which receives critical CSI events. Empty value disables alerting")
+	alertingFormat = flag.String("alerting-format", alerting.FormatWebhook,
+		"Format of alerts sent to alerting URL: "+alerting.FormatWebhook+" or "+alerting.FormatAlertmanager)
+	alertingReasons = flag.String("alerting-reasons", alerting.DefaultReasons,
+		"Comma separated reasons of CSI events which are sent to alerting URL")
+	shutdownGracePeriod = flag.Duration("shutdown-grace-period", 25*time.Second,
+		"Time during which in-flight CSI calls and volume operations are finished on SIGTERM. "+
+			"Must be less than terminationGracePeriodSeconds of the pod")
+	debugProfiling = flag.Bool("debug-profiling", false,
+		"Expose pprof endpoints on "+diagnostics.PprofPath+" of metrics endpoint and write snapshots of profiles. "+
+			"Enable only to investigate memory or goroutine growth")
+	profileSnapshotInterval = flag.Duration("profile-snapshot-interval", 0,
+		"Interval of heap and goroutine snapshots while profiling is enabled. Zero value disables snapshots")
+	profileSnapshotDir = flag.String("profile-snapshot-dir", "/tmp/profiles",
+		"Directory of snapshots of profiles, the latest snapshots of each profile are kept")
+	leakCheckInterval = flag.Duration("leak-check-interval", time.Minute,
+		"Interval of checks of goroutines, heap and objects in informer cache")
+	leakWarningGrowth = flag.Float64("leak-warning-growth", 0,
+		"Ratio of current to start count of goroutines or objects in informer cache which triggers warning event, "+
+			"e.g. 3. Zero value disables warnings")
+	goroutineRestartThreshold = flag.Int64("goroutine-restart-threshold", 0,
+		"Count of goroutines above which service is restarted gracefully. Zero value disables restart")
+	heapRestartThreshold = flag.String("heap-restart-threshold", "0",
+		"Heap in use above which service is restarted gracefully, e.g. 1Gi. Zero value disables restart")
 )
 
+const componentName = "csi-baremetal-controller"
+
 func main() {
 	flag.Parse()
 
 	featureConf := featureconfig.NewFeatureConfig()
 	featureConf.Update(featureconfig.FeatureACReservation, *useACRs)
+	featureConf.Update(featureconfig.FeatureNamespaceQuota, *useNamespaceQuota)
 
 	var enableMetrics bool
 	if *metricspath != "" {
 		enableMetrics = true
 	}
 
-	logger, err := logger.InitLogger(*logPath, *logLevel)
+	cfg, err := config.Init(*configPath)
+	if err != nil {
+		logrus.Fatalf("fail to load configuration file: %v", err)
+	}
+
+	logger, err := logger.InitLogger(*logPath, cfg.GetLogLevel(*logLevel))
 	if err != nil {
 		logger.Warnf("Can't set logger's output to %s. Using stdout instead.\n", *logPath)
 	}
 
 	logger.Info("Starting controller ...")
 
-	csiControllerServer := rpc.NewServerRunner(nil, *endpoint, enableMetrics, logger)
+	gates, err := featureconfig.ParseFeatureGates(*featureGates)
+	if err != nil {
+		logger.Fatalf("fail to parse feature gates: %v", err)
+	}
+	featureConf.SetFeatureGates(gates)
+	featureConf.SetFeatureGates(cfg.FeatureGates)
+	logger.Infof("Feature gates: %s", featureconfig.FeatureGatesString(featureConf))
+
+	creds, err := rpc.ServerCredentials(*endpoint)
+	if err != nil {
+		logger.Fatalf("fail to load server credentials: %v", err)
+	}
+	csiControllerServer := rpc.NewServerRunner(creds, *endpoint, enableMetrics, logger)
 
 	k8SClient, err := k8s.GetK8SClient()
 	if err != nil {
 		logger.Fatalf("fail to create kubernetes client, error: %v", err)
 	}
 	kubeClient := k8s.NewKubeClient(k8SClient, logger, objects.NewObjectLogger(), *namespace)
+	// CRs created by previous versions have no node and storage class labels
+	if err := kubeClient.LabelCRs(context.Background()); err != nil {
+		logger.Warnf("Unable to label CRs, server-side filtering is disabled: %v", err)
+	} else {
+		kubeClient.EnableLabelSelectors()
+	}
 	controllerService := controller.NewControllerService(kubeClient, logger, featureConf)
+	shard, err := createShard()
+	if err != nil {
+		logger.Fatalf("fail to configure sharding: %v", err)
+	}
+	if shard != nil {
+		logger.Infof("Sharding is enabled, shard %s", shard)
+		controllerService.SetShard(shard)
+	}
+	if *provisioningFreezeConfig != "" {
+		controllerService.SetProvisioningFreezeConfig(*provisioningFreezeConfig)
+	}
 	handler := util.NewSignalHandler(logger)
-	go handler.SetupSIGTERMHandler(csiControllerServer)
+	drained := make(chan struct{})
+	go func() {
+		handler.SetupGracefulSIGTERMHandler(csiControllerServer, controllerService, *shutdownGracePeriod)
+		close(drained)
+	}()
 
 	csi.RegisterIdentityServer(csiControllerServer.GRPCServer, controllerService)
 	csi.RegisterControllerServer(csiControllerServer.GRPCServer, controllerService)
 
+	profiler := diagnostics.NewProfiler(*profileSnapshotDir, diagnostics.DefaultSnapshotHistory, logger)
+	profiler.SetEnabled(cfg.ProfilingEnabled(*debugProfiling))
 	if enableMetrics {
 		grpc_prometheus.Register(csiControllerServer.GRPCServer)
 		grpc_prometheus.EnableHandlingTimeHistogram()
 		grpc_prometheus.EnableClientHandlingTimeHistogram()
 		prometheus.MustRegister(metrics.BuildInfo)
+		prometheus.MustRegister(diagnostics.DefaultRegistry)
 
 		go func() {
-			http.Handle(*metricspath, promhttp.Handler())
-			if err := http.ListenAndServe(*metricsAddress, nil); err != nil {
+			http.Handle(*metricspath, diagnostics.MetricsHandler())
+			http.Handle(diagnostics.StatusPath, diagnostics.Handler())
+			http.Handle(diagnostics.PprofPath, profiler)
+			if err := basenet.ServeHTTP(*metricsAddress, nil); err != nil {
 				logger.Warnf("metric http returned: %s ", err)
 			}
 		}()
 	}
 	stopCH := ctrl.SetupSignalHandler()
+	if interval := cfg.GetSnapshotInterval(*profileSnapshotInterval); interval > 0 {
+		go profiler.Run(stopCH, interval)
+	}
+	kubeCache, err := k8s.InitKubeCache(stopCH, logger,
+		&drivecrd.Drive{}, &accrd.AvailableCapacity{}, &volumecrd.Volume{}, &lvgcrd.LogicalVolumeGroup{})
+	if err != nil {
+		logger.Fatalf("fail to start kubeCache, error: %v", err)
+	}
+	if leakGuardEnabled() {
+		eventRecorder, err := prepareEventRecorder(logger)
+		if err != nil {
+			logger.Fatalf("fail to prepare event recorder: %v", err)
+		}
+		if err := runLeakGuard(stopCH, kubeClient, kubeCache, eventRecorder, logger); err != nil {
+			logger.Fatalf("fail to start leak guard: %v", err)
+		}
+	}
+	thresholds, err := capacitymonitor.ParseThresholds(*lowCapacityThresholds)
+	if err != nil {
+		logger.Fatalf("fail to parse low capacity thresholds: %v", err)
+	}
+	if len(cfg.LowCapacityThresholds) > 0 {
+		thresholds = cfg.LowCapacityThresholds
+	}
+	var capacityMonitor *capacitymonitor.Monitor
+	// capacity is calculated for the whole cluster, so monitor runs on the first shard only
+	if len(thresholds) > 0 && shard.IsLeader() {
+		eventRecorder, err := prepareEventRecorder(logger)
+		if err != nil {
+			logger.Fatalf("fail to prepare event recorder: %v", err)
+		}
+		capacityMonitor = capacitymonitor.NewMonitor(kubeClient, kubeCache, eventRecorder, thresholds, logger)
+		if pod, err := kubeClient.GetOwnPod(stopCH); err == nil {
+			capacityMonitor.SetClusterObject(pod)
+		} else {
+			logger.Warnf("Cluster low capacity events aren't recorded, unable to read pod: %v", err)
+		}
+		go capacityMonitor.Run(stopCH, capacitymonitor.DefaultCheckInterval)
+	}
+	// events are read for the whole cluster, so alerts are sent by the first shard only
+	if *alertingURL != "" && shard.IsLeader() {
+		sink, err := alerting.NewSink(kubeClient, *alertingURL, *alertingFormat, *alertingReasons, logger)
+		if err != nil {
+			logger.Fatalf("fail to create alerting sink: %v", err)
+		}
+		go sink.Run(stopCH, alerting.DefaultCheckInterval)
+	}
+	if *configPath != "" {
+		configWatcher := config.NewWatcher(*configPath, cfg, logger)
+		configWatcher.OnReload(func(reloaded *config.Config) {
+			logger.SetLevel(reloaded.LogrusLevel(*logLevel))
+			profiler.SetEnabled(reloaded.ProfilingEnabled(*debugProfiling))
+			if capacityMonitor != nil && len(reloaded.LowCapacityThresholds) > 0 {
+				capacityMonitor.SetThresholds(reloaded.LowCapacityThresholds)
+			}
+		})
+		go func() {
+			if err := configWatcher.Run(stopCH); err != nil {
+				logger.Errorf("Configuration watcher failed with error: %v", err)
+			}
+		}()
+	}
+	if *inventoryAddress != "" {
+		token, err := inventory.ReadToken(*inventoryTokenFile)
+		if err != nil {
+			logger.Fatalf("fail to read inventory token: %v", err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle(inventory.Path, inventory.NewServer(kubeClient, kubeCache, token, logger))
+		go func() {
+			logger.Infof("Starting inventory endpoint on %s", *inventoryAddress)
+			if err := basenet.ServeHTTP(*inventoryAddress, mux); err != nil {
+				logger.Errorf("Inventory endpoint failed with error: %v", err)
+			}
+		}()
+	}
+	if *podDeletionWebhookAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle(admission.Path, admission.NewPodDeletionWebhook(kubeClient, kubeCache, logger))
+		listenConfig, err := basenet.ListenConfigFromEnv()
+		if err != nil {
+			logger.Fatalf("wrong listen configuration: %v", err)
+		}
+		// webhook is always served with its own certificate and kube-apiserver doesn't present client certificate
+		listenConfig.TLS.CertFile = filepath.Join(*webhookCertDir, "tls.crt")
+		listenConfig.TLS.KeyFile = filepath.Join(*webhookCertDir, "tls.key")
+		listenConfig.TLS.ClientCAFile = ""
+		go func() {
+			logger.Infof("Starting pod deletion webhook on %s", *podDeletionWebhookAddress)
+			if err := listenConfig.ServeHTTP(*podDeletionWebhookAddress, mux); err != nil {
+				logger.Errorf("Pod deletion webhook failed with error: %v", err)
+			}
+		}()
+	}
+	if *volumeTakeoverTimeout > 0 {
+		eventRecorder, err := prepareEventRecorder(logger)
+		if err != nil {
+			logger.Fatalf("fail to prepare event recorder: %v", err)
+		}
+		go controllerService.RunStuckVolumesCleanup(stopCH, eventRecorder, controller.DefaultStuckVolumesCheckInterval,
+			*volumeTakeoverTimeout)
+	}
 	// todo make ACR feature mandatory and get rid of feature flag https://github.com/dell/csi-baremetal/issues/366
-	mgr, err := createManager(stopCH, kubeClient, logger, featureConf.IsEnabled(featureconfig.FeatureACReservation))
+	mgr, err := createManager(kubeClient, kubeCache, shard, logger, featureConf.IsEnabled(featureconfig.FeatureACReservation))
 	if err != nil {
 		logger.Fatal(err)
 	}
@@ -129,6 +369,20 @@ func main() {
 		}
 	}()
 
+	// PVCs are populated once for the whole cluster, so populator runs on the first shard only
+	if *populators != "" && shard.IsLeader() {
+		populatorMgr, err := createPopulatorManager(logger)
+		if err != nil {
+			logger.Fatalf("fail to create populator manager: %v", err)
+		}
+		go func() {
+			logger.Info("Starting Populator Controller")
+			if err := populatorMgr.Start(stopCH); err != nil {
+				logger.Fatalf("Populator Controller failed with error: %v", err)
+			}
+		}()
+	}
+
 	go func() {
 		logger.Info("Starting Controller Health server")
 		if err := util.SetupAndStartHealthCheckServer(
@@ -142,9 +396,11 @@ func main() {
 		logger.Fatalf("fail to serve, error: %v", err)
 	}
 	logger.Info("Got SIGTERM signal")
+	<-drained
 }
 
-func createManager(ctx context.Context, client *k8s.KubeClient, log *logrus.Logger, featureEnabled bool) (ctrl.Manager, error) {
+func createManager(client *k8s.KubeClient, kubeCache *k8s.KubeCache, shard *sharding.Shard,
+	log *logrus.Logger, featureEnabled bool) (ctrl.Manager, error) {
 	// create scheme
 	scheme := runtime.NewScheme()
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
@@ -168,33 +424,159 @@ func createManager(ctx context.Context, client *k8s.KubeClient, log *logrus.Logg
 		return nil, err
 	}
 
+	if err := drivebatchcrd.AddToSchemeDriveBatch(scheme); err != nil {
+		return nil, err
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:    scheme,
-		Namespace: *namespace,
+		Scheme:     scheme,
+		Namespace:  *namespace,
+		SyncPeriod: ctrlopts.ResyncInterval(log.WithField("component", "ControllerManager")),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if featureEnabled {
+	// ACRs aren't bound to node, so they are reconciled by the first shard only
+	if featureEnabled && shard.IsLeader() {
 		// controller
 		reservationController := reservation.NewController(client, log, *sequentialLVGReservation)
+		if *storageClassTiers != "" {
+			tiers, err := capacityplanner.ParseStorageClassTiers(*storageClassTiers)
+			if err != nil {
+				return nil, fmt.Errorf("fail to parse storage class tiers: %v", err)
+			}
+			reservationController.SetStorageClassTiers(&capacityplanner.StorageClassTiers{
+				Tiers: tiers, PressureThreshold: *tierPressureThreshold})
+		}
+		reservationController.SetDrivePinning(*drivePinning)
 		if err = reservationController.SetupWithManager(mgr); err != nil {
 			return nil, err
 		}
 	}
 	wrappedK8SClient := k8s.NewKubeClient(client, log, objects.NewObjectLogger(), *namespace)
 
-	kubeCache, err := k8s.InitKubeCache(ctx, log,
-		&drivecrd.Drive{}, &accrd.AvailableCapacity{}, &volumecrd.Volume{})
-	if err != nil {
-		return nil, err
+	// DriveBatch CRs aren't bound to node, so they are processed by the first shard only
+	if shard.IsLeader() {
+		if err = drivebatch.NewController(wrappedK8SClient, log).SetupWithManager(mgr); err != nil {
+			return nil, err
+		}
 	}
 
 	capacityController := capacitycontroller.NewCapacityController(wrappedK8SClient, kubeCache, log)
+	capacityController.SetShard(shard)
+	if *driveReservations != "" {
+		reservations, err := capacitycontroller.ParseDriveReservations(*driveReservations)
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse drive reservations: %v", err)
+		}
+		capacityController.SetDriveReservations(reservations)
+	}
+	if *checkDuplicatePartUUIDs {
+		eventRecorder, err := prepareEventRecorder(log)
+		if err != nil {
+			return nil, fmt.Errorf("fail to prepare event recorder: %v", err)
+		}
+		capacityController.SetDuplicatePartUUIDCheck(eventRecorder)
+	}
 	// bind CSINodeService's VolumeManager to K8s Controller Manager as a driveLvgController for Volume CR
 	if err = capacityController.SetupWithManager(mgr); err != nil {
 		return nil, err
 	}
 	return mgr, nil
 }
+
+// createPopulatorManager creates manager with volume populator controller
+// Manager isn't limited by namespace since PVCs being populated are located in namespaces of applications
+func createPopulatorManager(log *logrus.Logger) (ctrl.Manager, error) {
+	kinds, err := populator.ParsePopulators(*populators)
+	if err != nil {
+		return nil, err
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: "0",
+		SyncPeriod:         ctrlopts.ResyncInterval(log.WithField("component", "PopulatorManager")),
+	})
+	if err != nil {
+		return nil, err
+	}
+	populatorController := populator.NewController(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetRESTMapper(),
+		*namespace, kinds, log)
+	if err = populatorController.SetupWithManager(mgr); err != nil {
+		return nil, err
+	}
+	return mgr, nil
+}
+
+// createShard returns shard of the controller replica, nil if sharding is disabled
+func createShard() (*sharding.Shard, error) {
+	if *shards <= 1 {
+		return nil, nil
+	}
+	index := *shardIndex
+	if index < 0 {
+		podName, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		if index, err = sharding.IndexFromPodName(podName); err != nil {
+			return nil, err
+		}
+	}
+	return sharding.NewShard(index, *shards)
+}
+
+// prepareEventRecorder helper which makes all the work to get EventRecorder
+func prepareEventRecorder(logger *logrus.Logger) (*events.Recorder, error) {
+	k8SClientset, err := k8s.GetK8SClientset()
+	if err != nil {
+		return nil, fmt.Errorf("fail to create kubernetes client, error: %s", err)
+	}
+	eventInter := k8SClientset.CoreV1().Events("")
+
+	scheme, err := k8s.PrepareScheme()
+	if err != nil {
+		return nil, fmt.Errorf("fail to prepare kubernetes scheme, error: %s", err)
+	}
+
+	eventRecorder, err := events.New(componentName, "", eventInter, scheme, logger)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create events recorder, error: %s", err)
+	}
+	return eventRecorder, nil
+}
+
+// leakGuardEnabled returns whether any threshold of leak guard is set
+func leakGuardEnabled() bool {
+	return *leakWarningGrowth > 0 || *goroutineRestartThreshold > 0 || *heapRestartThreshold != "0"
+}
+
+// runLeakGuard starts checks of resource leaks if any threshold is set, events are recorded for the pod of the service
+func runLeakGuard(ctx context.Context, kubeClient *k8s.KubeClient, kubeCache *k8s.KubeCache,
+	eventRecorder *events.Recorder, logger *logrus.Logger) error {
+	if !leakGuardEnabled() {
+		return nil
+	}
+	heapThreshold, err := resource.ParseQuantity(*heapRestartThreshold)
+	if err != nil {
+		return fmt.Errorf("wrong heap restart threshold %s: %v", *heapRestartThreshold, err)
+	}
+	var object runtime.Object
+	if pod, err := kubeClient.GetOwnPod(ctx); err == nil {
+		object = pod
+	} else {
+		logger.Warnf("Events of leak guard aren't recorded, unable to read pod: %v", err)
+	}
+	guard := diagnostics.NewLeakGuard(diagnostics.LeakGuardConfig{
+		WarningGrowth:             *leakWarningGrowth,
+		GoroutineRestartThreshold: *goroutineRestartThreshold,
+		HeapRestartThreshold:      uint64(heapThreshold.Value()),
+	}, kubeCache.ObjectCounts, eventRecorder, object, logger)
+	go guard.Run(ctx, *leakCheckInterval)
+	return nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+// RBAC rules of csi-baremetal-controller service account.
+// Controller creates Volume CRs in PVC namespaces, so access to Volume CRs is cluster wide,
+// Drive and Node CRs are only read, they are owned by node service and node controller, except annotations
+// of Drive CRs which are updated by DriveBatch controller,
+// Pool CRs are only read, they are managed by administrator. Namespaces are read for capacity quotas.
+// Rules are generated with `make generate-rbac` and used by csi-baremetal-operator, don't edit generated files.
+// rbac_test.go checks rules against requests of client in main code paths.
+
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=volumes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=availablecapacities,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=availablecapacityreservations,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=logicalvolumegroups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=drives;nodes;pools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=drives,verbs=update;patch
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=drives/status;availablecapacities/status;logicalvolumegroups/status;volumes/status,verbs=update;patch
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=drivebatches,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=nodes;pods;persistentvolumes;persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",namespace=csi-baremetal,resources=configmaps,verbs=get;list;watch
+
+// Volume populators (--populators) update PVCs being populated and PVs, create prime PVCs and populator pods,
+// data source kinds are registered at runtime, so read access to them is granted by the operator
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=update;patch
+// +kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=update;patch
+// +kubebuilder:rbac:groups="",namespace=csi-baremetal,resources=persistentvolumeclaims;pods,verbs=get;list;watch;create;delete
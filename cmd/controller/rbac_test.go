@@ -0,0 +1,100 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/cache"
+	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/common"
+	"github.com/dell/csi-baremetal/pkg/testutils"
+)
+
+// TestRBAC runs volume provisioning of controller over client which records requests
+// and checks that RBAC markers allow each of them
+func TestRBAC(t *testing.T) {
+	var (
+		logger    = logrus.New()
+		ctx       = context.Background()
+		namespace = "app"
+		volumeID  = "pvc-1111"
+		nodeID    = "node-1"
+	)
+	rules, err := testutils.ParseRBACMarkers("rbac.go")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, rules)
+
+	kubeClient, recorder, err := testutils.NewRecordingKubeClient("csi-baremetal", logger)
+	assert.Nil(t, err)
+
+	ac := kubeClient.ConstructACCR("ac-1", api.AvailableCapacity{
+		Location: "drive-1", NodeId: nodeID, StorageClass: apiV1.StorageClassHDD, Size: int64(util.GBYTE)})
+	acr := kubeClient.ConstructACRCR("app-pod", api.AvailableCapacityReservation{
+		Namespace: namespace,
+		Status:    apiV1.ReservationConfirmed,
+		ReservationRequests: []*api.ReservationRequest{{
+			CapacityRequest: &api.CapacityRequest{Name: volumeID, StorageClass: apiV1.StorageClassHDD, Size: 1},
+			Reservations:    []string{ac.Name},
+		}},
+	})
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: volumeID, Namespace: namespace}}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace,
+		Annotations: map[string]string{common.QuotaAnnotationPrefix + "hdd": "1Ti"}}}
+	assert.Nil(t, kubeClient.CreateCR(ctx, ac.Name, ac))
+	assert.Nil(t, kubeClient.CreateCR(ctx, acr.Name, acr))
+	assert.Nil(t, kubeClient.Create(ctx, pvc))
+	assert.Nil(t, kubeClient.Create(ctx, ns))
+	recorder.Reset()
+
+	featureConf := featureconfig.NewFeatureConfig()
+	featureConf.Update(featureconfig.FeatureNamespaceQuota, true)
+	svc := common.NewVolumeOperationsImpl(kubeClient, logger, cache.NewMemCache(), featureConf)
+
+	volumeInfo, err := util.NewVolumeInfo(map[string]string{
+		util.ClaimNamespaceKey: namespace,
+		util.ClaimNameKey:      volumeID,
+	})
+	assert.Nil(t, err)
+	_, err = svc.CreateVolume(context.WithValue(ctx, util.VolumeInfoKey, volumeInfo), api.Volume{
+		Id: volumeID, StorageClass: apiV1.StorageClassHDD, NodeId: nodeID, Size: 1})
+	assert.Nil(t, err)
+	// node service sets Created status
+	volume := &volumecrd.Volume{}
+	assert.Nil(t, kubeClient.ReadCR(ctx, volumeID, namespace, volume))
+	volume.Spec.CSIStatus = apiV1.Created
+	assert.Nil(t, kubeClient.UpdateCR(ctx, volume))
+
+	assert.Nil(t, svc.DeleteVolume(ctx, volumeID))
+	svc.UpdateCRsAfterVolumeDeletion(ctx, volumeID)
+
+	calls := recorder.Calls()
+	assert.NotEmpty(t, calls)
+	for _, call := range calls {
+		assert.True(t, testutils.RBACAllows(rules, call), "RBAC rule is missing: %s", call)
+	}
+}
@@ -23,9 +23,12 @@ import (
 	dmsetup "github.com/dell/csi-baremetal/cmd/drivemgr"
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/sgses"
 	"github.com/dell/csi-baremetal/pkg/base/logger"
 	"github.com/dell/csi-baremetal/pkg/base/rpc"
 	"github.com/dell/csi-baremetal/pkg/drivemgr/basemgr"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/identitycache"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/smarttrend"
 )
 
 var (
@@ -33,6 +36,21 @@ var (
 	logPath  = flag.String("logpath", "", "log path for DriveManager")
 	logLevel = flag.String("loglevel", logger.InfoLevel,
 		fmt.Sprintf("Log level, support values are %s, %s, %s", logger.InfoLevel, logger.DebugLevel, logger.TraceLevel))
+	smartHistory     = flag.Bool("smart-history", false, "Whether DriveManager should predict drive failures based on SMART history or not")
+	smartHistoryPath = flag.String("smart-history-path", "",
+		"Path to the file where SMART history is persisted, empty value means that history is kept in memory only")
+	enclosureDiscovery = flag.Bool("enclosure-discovery", false,
+		"Whether DriveManager should discover enclosure and slot numbers of SAS drives with sg_ses or not")
+	identityCachePath = flag.String("identity-cache-path", "",
+		"Path to the file where identity and SMART baseline of drives are cached for fast cold start, "+
+			"empty value disables the cache")
+	identityCacheTTL = flag.Duration("identity-cache-ttl", identitycache.DefaultTTL,
+		"Max age of cached identity of drive which is used on cold start")
+	execMode = flag.String("exec-mode", command.ExecModeContainer,
+		fmt.Sprintf("How system utilities are run: %s (bundled in image), %s (host namespaces), %s (host root), %s",
+			command.ExecModeContainer, command.ExecModeNsenter, command.ExecModeChroot, command.ExecModeSudo))
+	utilityPaths = flag.String("utility-paths", "",
+		"Absolute paths of system utilities which override names in commands, for example sgdisk=/opt/bin/sgdisk,lvm=/usr/sbin/lvm")
 )
 
 func main() {
@@ -43,12 +61,38 @@ func main() {
 		logger.Warnf("Can't set logger's output to %s. Using stdout instead.\n", *logPath)
 	}
 
-	// Server is insecure for now because credentials are nil
-	serverRunner := rpc.NewServerRunner(nil, *endpoint, false, logger)
+	if err = command.SetExecMode(*execMode); err != nil {
+		logger.Fatalf("fail to set exec mode: %v", err)
+	}
+	paths, err := command.ParseUtilityPaths(*utilityPaths)
+	if err != nil {
+		logger.Fatalf("fail to parse utility paths: %v", err)
+	}
+	if err = command.SetUtilityPaths(paths); err != nil {
+		logger.Fatalf("fail to set utility paths: %v", err)
+	}
+
+	// server requires client certificates if mutual TLS is configured
+	creds, err := rpc.ServerCredentials(*endpoint)
+	if err != nil {
+		logger.Fatalf("fail to load server credentials: %v", err)
+	}
+	serverRunner := rpc.NewServerRunner(creds, *endpoint, false, logger)
 
 	e := command.NewExecutor(logger)
 
 	driveMgr := basemgr.New(e, logger)
+	if *smartHistory {
+		driveMgr.SetSMARTTrendStore(smarttrend.NewStore(*smartHistoryPath, smarttrend.DefaultConfig(), logger))
+	}
+
+	if *identityCachePath != "" {
+		driveMgr.SetIdentityCache(identitycache.NewCache(*identityCachePath, *identityCacheTTL, logger))
+	}
+
+	if *enclosureDiscovery {
+		driveMgr.SetEnclosureServices(sgses.NewSGSES(e, logger))
+	}
 
 	dmsetup.SetupAndRunDriveMgr(driveMgr, serverRunner, nil, logger)
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/ipmi"
 	"github.com/dell/csi-baremetal/pkg/base/logger"
 	"github.com/dell/csi-baremetal/pkg/base/rpc"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/credentials"
 	"github.com/dell/csi-baremetal/pkg/drivemgr/idracmgr"
 )
 
@@ -35,6 +36,10 @@ var (
 	logPath  = flag.String("logpath", "", "log path for DriveManager")
 	logLevel = flag.String("loglevel", logger.InfoLevel,
 		fmt.Sprintf("Log level, support values are %s, %s, %s", logger.InfoLevel, logger.DebugLevel, logger.TraceLevel))
+	credentialsDir = flag.String("credentials-dir", "",
+		"Directory where kubernetes Secret with iDRAC credentials (username, password, endpoint, endpoint.<nodegroup>) is mounted")
+	nodeGroup = flag.String("nodegroup", "",
+		"Node group of the node, is used to select iDRAC endpoint from Secret")
 )
 
 func main() {
@@ -45,18 +50,35 @@ func main() {
 		logger.Warnf("Can't set logger's output to %s. Using stdout instead.\n", *logPath)
 	}
 
-	// Server is insecure for now because credentials are nil
-	serverRunner := rpc.NewServerRunner(nil, *endpoint, false, logger)
+	// server requires client certificates if mutual TLS is configured
+	serverCreds, err := rpc.ServerCredentials(*endpoint)
+	if err != nil {
+		logger.Fatalf("fail to load server credentials: %v", err)
+	}
+	serverRunner := rpc.NewServerRunner(serverCreds, *endpoint, false, logger)
 
 	e := command.NewExecutor(logger)
 
-	ipmiTool := ipmi.NewIPMI(e)
-	ip := ipmiTool.GetBmcIP()
+	var provider credentials.Provider = credentials.StaticProvider{Username: "root", Password: "passwd"}
+	if *credentialsDir != "" {
+		provider = credentials.NewSecretProvider(*credentialsDir, *nodeGroup, logger)
+	}
+	creds, err := provider.GetCredentials()
+	if err != nil {
+		logger.Fatalf("Unable to get IDRAC credentials: %v", err)
+	}
+
+	ip := creds.Endpoint
+	if ip == "" {
+		ipmiTool := ipmi.NewIPMI(e)
+		ip = ipmiTool.GetBmcIP()
+	}
 	if ip == "" {
 		logger.Fatal("IDRAC IP is not found")
 	}
 
-	driveMgr := idracmgr.NewIDRACManager(logger, 10*time.Second, "root", "passwd", ip)
+	driveMgr := idracmgr.NewIDRACManager(logger, 10*time.Second, creds.Username, creds.Password, ip)
+	driveMgr.SetCredentialsProvider(provider)
 
 	dmsetup.SetupAndRunDriveMgr(driveMgr, serverRunner, nil, logger)
 }
@@ -71,8 +71,12 @@ func main() {
 		logger.Fatalf("fail to get nodeID, error: %v", err)
 	}
 
-	// Server is insecure for now because credentials are nil
-	serverRunner := rpc.NewServerRunner(nil, *endpoint, false, logger)
+	// server requires client certificates if mutual TLS is configured
+	creds, err := rpc.ServerCredentials(*endpoint)
+	if err != nil {
+		logger.Fatalf("fail to load server credentials: %v", err)
+	}
+	serverRunner := rpc.NewServerRunner(creds, *endpoint, false, logger)
 
 	e := command.NewExecutor(logger)
 
@@ -50,7 +50,7 @@ func main() {
 	flag.Parse()
 
 	// TODO: refactor this after https://github.com/dell/csi-baremetal/issues/83 will be closed
-	err := os.Setenv("LOG_FORMAT", *logFormat)
+	err := os.Setenv(logger.LogFormatEnv, *logFormat)
 	if err != nil {
 		fmt.Printf("Unable to set LOG_FORMAT env: %v\n", err)
 	}
@@ -0,0 +1,25 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+// RBAC rules of csi-baremetal-node-controller service account (operator part which manages Node CRs).
+// Node controller owns Node CRs and sets node ID annotations and labels on kubernetes nodes.
+// Rules are generated with `make generate-rbac` and used by csi-baremetal-operator, don't edit generated files.
+
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=nodes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
@@ -19,6 +19,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net"
@@ -29,6 +30,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -41,24 +43,45 @@ import (
 	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
 	"github.com/dell/csi-baremetal/api/v1/drivecrd"
 	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/nodecrd"
 	"github.com/dell/csi-baremetal/api/v1/volumecrd"
 	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/audit"
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/config"
+	"github.com/dell/csi-baremetal/pkg/base/ctrlopts"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
+	"github.com/dell/csi-baremetal/pkg/base/hooks"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/burnin"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/kernellog"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lvm"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/utilversion"
 	"github.com/dell/csi-baremetal/pkg/base/logger"
 	"github.com/dell/csi-baremetal/pkg/base/logger/objects"
+	"github.com/dell/csi-baremetal/pkg/base/lvmbackup"
+	"github.com/dell/csi-baremetal/pkg/base/nbd"
+	basenet "github.com/dell/csi-baremetal/pkg/base/net"
+	"github.com/dell/csi-baremetal/pkg/base/nodeprotocol"
 	"github.com/dell/csi-baremetal/pkg/base/rpc"
+	"github.com/dell/csi-baremetal/pkg/base/transfer"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	"github.com/dell/csi-baremetal/pkg/crcontrollers/drive"
 	"github.com/dell/csi-baremetal/pkg/crcontrollers/lvg"
 	annotations "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
+	"github.com/dell/csi-baremetal/pkg/drivemgr"
 	"github.com/dell/csi-baremetal/pkg/events"
 	"github.com/dell/csi-baremetal/pkg/metrics"
 	"github.com/dell/csi-baremetal/pkg/node"
+	"github.com/dell/csi-baremetal/pkg/node/provisioners"
 	"github.com/dell/csi-baremetal/pkg/node/wbt"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 )
 
@@ -88,7 +111,196 @@ var (
 		fmt.Sprintf("Log level, support values are %s, %s, %s", logger.InfoLevel, logger.DebugLevel, logger.TraceLevel))
 	metricsAddress = flag.String("metrics-address", "", "The TCP network address where the prometheus metrics endpoint will run"+
 		"(example: :8080 which corresponds to port 8080 on local host). The default is empty string, which means metrics endpoint is disabled.")
-	metricspath = flag.String("metrics-path", "/metrics", "The HTTP path where prometheus metrics will be exposed. Default is /metrics.")
+	metricspath                = flag.String("metrics-path", "/metrics", "The HTTP path where prometheus metrics will be exposed. Default is /metrics.")
+	driveTemperatureThresholds = flag.String("drive-temperature-thresholds", "",
+		"Max drive temperature in Celsius per drive type, for example HDD=55,SSD=70,NVME=75. Empty value disables temperature monitoring")
+	thermalAwareCapacity = flag.Bool("thermal-aware-capacity", false,
+		"Whether AvailableCapacity of drives with high temperature should be marked to be selected last or not")
+	driveEvacuation = flag.Bool("drive-evacuation", false,
+		"Whether drive with BAD health should be cordoned and pods which opted in should be evicted or not, "+
+			"evictions are staged to keep PodDisruptionBudgets")
+	// drive manager sidecar might be started later than node service
+	driveMgrNegotiationTimeout = flag.Duration("drivemgr-negotiation-timeout", 2*time.Minute,
+		"Max time to wait for drive manager during API version negotiation on start. "+
+			"0 disables waiting, legacy API v1 with all capabilities is assumed if drive manager isn't available")
+	ioErrorsThreshold = flag.Int("io-errors-threshold", 0,
+		"Count of I/O errors in kernel log after which drive health is set to SUSPECT. 0 disables kernel log scraping")
+	ioErrorsWindow = flag.Duration("io-errors-window", time.Hour,
+		"Period in which I/O errors of drive are counted, drive health isn't overridden after errors leave the window")
+	driveCircuitThreshold = flag.Int("drive-circuit-threshold", 0,
+		"Count of consecutive failed operations on drive after which commands aren't issued to the drive and "+
+			"Drive CR is annotated. 0 disables circuit breaker")
+	driveCircuitProbeInterval = flag.Duration("drive-circuit-probe-interval", time.Minute,
+		"Interval after which operation on drive with open circuit is probed, it is doubled after each failed probe")
+	driveCircuitMaxProbeInterval = flag.Duration("drive-circuit-max-probe-interval", time.Hour,
+		"Max interval between probes of drive with open circuit")
+	execMode = flag.String("exec-mode", command.ExecModeContainer,
+		fmt.Sprintf("How system utilities are run: %s (bundled in image), %s (host namespaces), %s (host root), %s",
+			command.ExecModeContainer, command.ExecModeNsenter, command.ExecModeChroot, command.ExecModeSudo))
+	utilityPaths = flag.String("utility-paths", "",
+		"Absolute paths of system utilities which override names in commands, for example sgdisk=/opt/bin/sgdisk,lvm=/usr/sbin/lvm")
+	operationBudget = flag.String("operation-budget", "",
+		"Max count of simultaneous heavy operations of the node in format total=4,mkfs=2,wipe=2,pvmove=1,benchmark=1. "+
+			"Background pvmove and benchmark operations can't take the last slot. Empty value disables the budget")
+	nodeConditions = flag.Bool("node-conditions", false,
+		"Whether node-problem-detector compatible conditions DriveFailure and DiskPressureOnDataDrives should be set for the node or not")
+	diskPressureThreshold = flag.Int64("disk-pressure-threshold", 10,
+		"Free capacity of data drives in percents below which DiskPressureOnDataDrives node condition is set")
+	auditEnabled = flag.Bool("audit", false,
+		"Whether destructive operations (mkfs, wipefs, partition delete, LV removal) should be recorded into audit trail or not")
+	auditLogPath = flag.String("audit-log-path", "",
+		"Path of append-only file for audit records, used when audit is enabled")
+	auditSyslog = flag.Bool("audit-syslog", false,
+		"Whether audit records should be sent to local syslog or not, used when audit is enabled")
+	standbyVolumes = flag.String("standby-volumes", "",
+		"Amount of free drives with pre-created partition and filesystem per storage class, for example HDD=2,SSD=1. "+
+			"Empty value disables standby drives")
+	standbyFSType = flag.String("standby-fs-type", string(fs.XFS),
+		"Filesystem type of standby drives, volumes with other filesystem don't benefit from standby drives")
+	asyncFormatting = flag.Bool("async-formatting", false,
+		"Whether filesystem of volume should be created in background or not. "+
+			"CreateVolume doesn't wait for mkfs, NodeStageVolume waits for its completion")
+	kubeletRootDir = flag.String("kubelet-root-dir", "",
+		"Root directory of kubelet (--root-dir of kubelet). Empty value means that it is discovered automatically")
+	verifyMountPropagation = flag.Bool("verify-mount-propagation", true,
+		"Whether node service should verify that kubelet root directory is mounted with shared propagation or not. "+
+			"Health check fails if propagation is wrong")
+	pvDriveLabels = flag.String("pv-drive-labels", "",
+		"Comma separated drive attributes which are set as labels of PersistentVolume: "+
+			"media-type, model, serial-hash, bay. Empty value disables labeling")
+	partitionScheme = flag.String("partition-scheme", "",
+		"Scheme of GUID, label and type of volume partitions in format guid-prefix=<hex>,label=<label>,"+
+			"namespace-hash=<bool>,type-guid=<guid>,type-guid.<storage class>=<guid>. "+
+			"Empty value means GUID is volume UUID, label is CSI and type is Linux filesystem")
+	verifyVolumeOwnership = flag.Bool("verify-volume-ownership", true,
+		"Whether node service should record partition GUID and filesystem UUID of volumes and verify them "+
+			"before volume release or not")
+	busyPartitionPolicy = flag.String("busy-partition-policy", provisioners.BusyPartitionPolicyReport,
+		"Policy of handling of volume partition which is held by leftover device mapper, bcache or md device "+
+			"or mount during volume release: report - fail release with holders chain, "+
+			"remove - remove holders and retry release")
+	unstageUnmountPolicy = flag.String("unstage-unmount-policy", node.UnstageUnmountPolicyFail,
+		"Policy of handling of staging path which can't be unmounted during NodeUnstageVolume: "+
+			"fail - fail request, retry - retry unmount and fail request, "+
+			"lazy - retry unmount, then detach busy file system with lazy unmount and report NeedsReboot node condition")
+	unstageUnmountRetries = flag.Int("unstage-unmount-retries", 3,
+		"Number of unmount retries during NodeUnstageVolume with retry and lazy unstage unmount policies")
+	unstageUnmountRetryInterval = flag.Duration("unstage-unmount-retry-interval", time.Second,
+		"Delay between unmount retries during NodeUnstageVolume")
+	duplicateMountsPolicy = flag.String("duplicate-mounts-policy", "",
+		"Policy of handling of volumes mounted at kubelet target paths which aren't known to node service: "+
+			"report - send event and count in metrics, unmount - report and unmount. Empty value disables detection")
+	writeCachePolicy = flag.String("write-cache-policy", "",
+		"Required state of volatile write cache per drive type, for example HDD=off,SSD=off,NVME=on. "+
+			"Drives of types which aren't listed are left as is. Empty value disables write cache control")
+	growExpandedDrives = flag.Bool("grow-expanded-drives", false,
+		"Whether node service should use space added to drives, e.g. after expansion of RAID virtual disk: "+
+			"resize PV of LogicalVolumeGroup and partition of drive-based volume and increase AvailableCapacity")
+	queueTuning = flag.String("queue-tuning", "",
+		"Required queue attributes per drive type in format \"HDD:scheduler=mq-deadline,read_ahead_kb=4096;"+
+			"NVME:scheduler=none\", supported attributes are scheduler, nr_requests, read_ahead_kb, max_sectors_kb, "+
+			"rq_affinity and nomerges. Empty value disables queue tuning")
+	queueTuningInterval = flag.Duration("queue-tuning-interval", time.Minute,
+		"Interval of verification of queue attributes of drives, changed attributes are reapplied")
+	lvmMetadataBackupHistory = flag.Int("lvm-metadata-backup-history", 0,
+		"Number of backups of LVM metadata which are kept per volume group in Secret after changes of VG. "+
+			"Zero value disables backup and restore of LVM metadata")
+	partitionAlignmentPolicy = flag.String("partition-alignment-policy", "",
+		"Policy of handling of misaligned partitions of volumes: report - send event, annotate volume and count "+
+			"in metrics, realign - report and move partitions of unused volumes to aligned location. "+
+			"Empty value disables audit")
+	deviceGraphChecks = flag.Bool("device-graph-checks", true,
+		"Whether node service should treat drive which is used by LVM, software RAID, dm-crypt, bcache, "+
+			"mounted in any mount namespace or active swap as not clean and refuse to create volumes on it or not")
+	cacheVolumeGroup = flag.String("cache-volume-group", "",
+		"LVM volume group on SSD where cache of HDD volumes with cacheMode StorageClass parameter is created. "+
+			"Empty value disables cache tier")
+	templateVolumeGroup = flag.String("template-volume-group", "",
+		"LVM volume group with thin pool where template LVs are located, volumes with template StorageClass "+
+			"parameter are created as thin snapshots of them. Empty value disables template volumes")
+	volumeIOStatsInterval = flag.Duration("volume-io-stats-interval", 30*time.Second,
+		"Interval of collection of per-volume I/O metrics from diskstats when metrics are enabled. "+
+			"Zero value disables collection")
+	noisyNeighborDetection = flag.String("noisy-neighbor-detection", "",
+		"Thresholds of detection of LVG volumes which saturate shared drives in format "+
+			"\"share=80,utilization=90,duration=5m\", works when per-volume I/O metrics are collected. "+
+			"Empty value disables detection")
+	volumeAutogrow = flag.String("volume-autogrow", "",
+		"Settings of automatic expansion of LVG volumes in mount mode which file systems are filled up in format "+
+			"\"threshold=80,step=10Gi,max=1Ti\", step is quantity or percent of volume size, e.g. \"step=20%\". "+
+			"Empty value disables autogrow")
+	volumeAutogrowInterval = flag.Duration("volume-autogrow-interval", time.Minute,
+		"Interval of checks of usage of file systems of volumes when autogrow is enabled")
+	replacementDrivePolicy = flag.String("replacement-drive-policy", "",
+		"Policy of acceptance of drive which is installed in slot of BAD or removed drive: "+
+			"auto - drive is added to the free pool, manual - drive is cordoned until cordon annotation is removed. "+
+			"Empty value disables detection of replacement drives")
+	burnIn = flag.String("burn-in", "",
+		"Settings of destructive burn-in test of clean drives which are installed after node service start in format "+
+			"\"tool=fio,duration=4h\", tool is fio or badblocks. Drive isn't schedulable until test is passed. "+
+			"Empty value disables burn-in")
+	selfTest = flag.Bool("self-test", false,
+		"Whether node service should create, format, mount and write test partition on one free drive on start. "+
+			"Node isn't schedulable for pods with volumes until the test passes")
+	selfTestFSType = flag.String("self-test-fs-type", string(fs.XFS),
+		"Filesystem which is created on test partition during storage self-test")
+	discoverInterval = flag.Duration("discover-interval", node.DefaultDiscoverInterval,
+		"Interval of drive discovery in steady state, interval is reduced to fast interval when drives are changed "+
+			"and grows back twice after each discovery without changes")
+	discoverFastInterval = flag.Duration("discover-fast-interval", node.DefaultDiscoverFastInterval,
+		"Interval of drive discovery on start, after drives are changed and after discovery failure")
+	nodeGroup = flag.String("nodegroup", "",
+		"Node group of the node, is used to select discovery intervals of node group from configuration file")
+	foreignDataScan = flag.Bool("foreign-data-scan", false,
+		"Whether new drives should be scanned for filesystems, LVM PVs, RAID members, LUKS and partition tables "+
+			"before they are added to the free pool. Drive with foreign data isn't clean until it is annotated "+
+			"with clean=true")
+	reportPartitionUUIDs = flag.Bool("report-partition-uuids", false,
+		"Whether GUIDs of partitions should be reported in Drive CRs, so controller with "+
+			"--check-duplicate-partition-uuids quarantines drives with the same partition GUID")
+	nbdExportAddress = flag.String("nbd-export-address", "",
+		"TCP address of NBD server which exports read-only snapshots of volumes annotated with nbd-export, "+
+			"e.g. 0.0.0.0:10809. Requires mutual TLS, clients upgrade connection with STARTTLS and are authorized "+
+			"by names of their certificates from nbd-export/clients annotation. Empty value disables the export")
+	transferAddress = flag.String("transfer-address", "",
+		"TCP address of data transfer service between node pods, e.g. :9998. Empty value disables the service")
+	transferCertDir = flag.String("transfer-cert-dir", "/etc/csi-baremetal/transfer",
+		"Directory with tls.crt, tls.key and ca.crt which are used for mutual TLS of data transfer service")
+	transferBandwidthLimit = flag.String("transfer-bandwidth-limit", "0",
+		"Max bandwidth of all data transfers of the node in bytes per second, e.g. 100Mi. 0 disables the limit")
+	hooksDir = flag.String("hooks-dir", "",
+		"Directory with executable hooks of provisioning pipeline, e.g. /etc/csi-baremetal/hooks. "+
+			"Hooks are named pre-partition, post-mkfs or pre-mount with optional .<suffix>. Empty value disables hooks")
+	hooksTimeout = flag.Duration("hooks-timeout", 30*time.Second, "Max duration of each provisioning hook")
+	featureGates = flag.String("feature-gates", "",
+		"Comma separated list of Feature=true|false pairs which enable or disable experimental features, "+
+			"e.g. "+featureconfig.FeatureVolumeEncryption+"=false")
+	configPath = flag.String("config", "",
+		"Path to the versioned configuration file mounted from ConfigMap, its parameters override options and "+
+			"environment variables. Log level, profiling and discovery intervals are reloaded on change. "+
+			"Empty value disables the file")
+	shutdownGracePeriod = flag.Duration("shutdown-grace-period", 25*time.Second,
+		"Time during which in-flight CSI calls and volume operations are finished on SIGTERM. "+
+			"Must be less than terminationGracePeriodSeconds of the pod")
+	historyMaxEntries = flag.Int("history-max-entries", 0,
+		"Max number of lifecycle events kept in History CR of each Drive and Volume CR, the oldest events are dropped. "+
+			"Zero value disables history")
+	debugProfiling = flag.Bool("debug-profiling", false,
+		"Expose pprof endpoints on "+diagnostics.PprofPath+" of metrics endpoint and write snapshots of profiles. "+
+			"Enable only to investigate memory or goroutine growth")
+	profileSnapshotInterval = flag.Duration("profile-snapshot-interval", 0,
+		"Interval of heap and goroutine snapshots while profiling is enabled. Zero value disables snapshots")
+	profileSnapshotDir = flag.String("profile-snapshot-dir", "/tmp/profiles",
+		"Directory of snapshots of profiles, the latest snapshots of each profile are kept")
+	leakCheckInterval = flag.Duration("leak-check-interval", time.Minute,
+		"Interval of checks of goroutines, heap and objects in informer cache")
+	leakWarningGrowth = flag.Float64("leak-warning-growth", 0,
+		"Ratio of current to start count of goroutines or objects in informer cache which triggers warning event, "+
+			"e.g. 3. Zero value disables warnings")
+	goroutineRestartThreshold = flag.Int64("goroutine-restart-threshold", 0,
+		"Count of goroutines above which service is restarted gracefully. Zero value disables restart")
+	heapRestartThreshold = flag.String("heap-restart-threshold", "0",
+		"Heap in use above which service is restarted gracefully, e.g. 1Gi. Zero value disables restart")
 )
 
 func main() {
@@ -98,19 +310,54 @@ func main() {
 	featureConf.Update(featureconfig.FeatureACReservation, *useACRs)
 	featureConf.Update(featureconfig.FeatureNodeIDFromAnnotation, *useNodeAnnotation)
 	featureConf.Update(featureconfig.FeatureExternalAnnotationForNode, *useExternalAnnotation)
+	featureConf.Update(featureconfig.FeatureDriveEvacuation, *driveEvacuation)
 
 	var enableMetrics bool
 	if *metricspath != "" {
 		enableMetrics = true
 	}
 
-	logger, err := logger.InitLogger(*logPath, *logLevel)
+	cfg, err := config.Init(*configPath)
+	if err != nil {
+		logrus.Fatalf("fail to load configuration file: %v", err)
+	}
+
+	logger, err := logger.InitLogger(*logPath, cfg.GetLogLevel(*logLevel))
 	if err != nil {
 		logger.Warnf("Can't set logger's output to %s. Using stdout instead.\n", *logPath)
 	}
 
 	logger.Info("Starting Node Service")
 
+	gates, err := featureconfig.ParseFeatureGates(*featureGates)
+	if err != nil {
+		logger.Fatalf("fail to parse feature gates: %v", err)
+	}
+	featureConf.SetFeatureGates(gates)
+	featureConf.SetFeatureGates(cfg.FeatureGates)
+	logger.Infof("Feature gates: %s", featureconfig.FeatureGatesString(featureConf))
+
+	if err = command.SetExecMode(*execMode); err != nil {
+		logger.Fatalf("fail to set exec mode: %v", err)
+	}
+	paths, err := command.ParseUtilityPaths(*utilityPaths)
+	if err != nil {
+		logger.Fatalf("fail to parse utility paths: %v", err)
+	}
+	if err = command.SetUtilityPaths(paths); err != nil {
+		logger.Fatalf("fail to set utility paths: %v", err)
+	}
+	if *operationBudget != "" {
+		budget, err := command.ParseOperationBudget(*operationBudget)
+		if err != nil {
+			logger.Fatalf("fail to parse operation budget: %v", err)
+		}
+		command.SetOperationBudget(budget)
+	}
+	if missing := command.CheckUtilities(command.NewExecutor(logger), command.RequiredUtilities); len(missing) > 0 {
+		logger.Errorf("System utilities %v are not found in exec mode %s", missing, *execMode)
+	}
+
 	stopCH := ctrl.SetupSignalHandler()
 
 	k8SClient, err := k8s.GetK8SClient()
@@ -123,18 +370,35 @@ func main() {
 		logger.Fatalf("Unable to obtain node ID: %v", err)
 	}
 
+	utilVersions := utilversion.NewDetector(command.NewExecutor(logger), logger).Detect()
+	if _, ok := utilVersions[utilversion.Lsblk]; ok {
+		lsblk.SetJSONSupported(utilversion.GetCapabilities(utilVersions).LsblkJSON)
+	}
+	if _, ok := utilVersions[utilversion.Parted]; ok {
+		partitionhelper.SetPartedJSONSupported(utilversion.GetCapabilities(utilVersions).PartedJSON)
+	}
+	// controller and extender treat node without protocol version as the legacy one without capabilities
+	if err = recordNodeInfoWithRetries(k8SClient, nodeID, utilVersions, logger); err != nil {
+		logger.Fatalf("Unable to record protocol version and versions of system utilities %v: %v", utilVersions, err)
+	}
+
 	// gRPC client for communication with DriveMgr via TCP socket
-	gRPCClient, err := rpc.NewClient(nil, *driveMgrEndpoint, enableMetrics, logger)
+	creds, err := rpc.ClientCredentials(*driveMgrEndpoint)
+	if err != nil {
+		logger.Fatalf("fail to load client credentials for endpoint %s, error: %v", *driveMgrEndpoint, err)
+	}
+	gRPCClient, err := rpc.NewClient(creds, *driveMgrEndpoint, enableMetrics, logger)
 	if err != nil {
 		logger.Fatalf("fail to create grpc client for endpoint %s, error: %v", *driveMgrEndpoint, err)
 	}
 	clientToDriveMgr := api.NewDriveServiceClient(gRPCClient.GRPCClient)
+	driveMgrInfo := negotiateDriveMgrAPIVersion(clientToDriveMgr, logger)
 
 	// gRPC server that will serve requests (node CSI) from k8s via unix socket
 	csiUDSServer := rpc.NewServerRunner(nil, *csiEndpoint, enableMetrics, logger)
 
 	kubeCache, err := k8s.InitKubeCache(stopCH, logger,
-		&drivecrd.Drive{}, &accrd.AvailableCapacity{}, &volumecrd.Volume{})
+		&drivecrd.Drive{}, &accrd.AvailableCapacity{}, &volumecrd.Volume{}, &lvgcrd.LogicalVolumeGroup{})
 	if err != nil {
 		logger.Fatalf("fail to start kubeCache, error: %v", err)
 	}
@@ -153,13 +417,188 @@ func main() {
 	defer eventRecorder.Wait()
 
 	wrappedK8SClient := k8s.NewKubeClient(k8SClient, logger, objects.NewObjectLogger(), *namespace)
+	if *historyMaxEntries > 0 {
+		history := events.NewHistory(wrappedK8SClient, *historyMaxEntries, logger)
+		eventRecorder.SetHistory(history)
+		go history.Run(stopCH)
+	}
 	csiNodeService := node.NewCSINodeService(
 		clientToDriveMgr, nodeID, *nodeName, logger, wrappedK8SClient, kubeCache, eventRecorder, featureConf)
+	if *driveTemperatureThresholds != "" {
+		thresholds, err := node.ParseTemperatureThresholds(*driveTemperatureThresholds)
+		if err != nil {
+			logger.Fatalf("fail to parse drive temperature thresholds: %v", err)
+		}
+		csiNodeService.SetDriveTemperatureThresholds(thresholds, *thermalAwareCapacity)
+	}
+	if *writeCachePolicy != "" {
+		policy, err := node.ParseWriteCachePolicy(*writeCachePolicy)
+		if err != nil {
+			logger.Fatalf("fail to parse write cache policy: %v", err)
+		}
+		csiNodeService.SetWriteCachePolicy(policy)
+	}
+	if *growExpandedDrives {
+		csiNodeService.SetDriveGrowth()
+	}
+	if *queueTuning != "" {
+		tuning, err := node.ParseQueueTuning(*queueTuning)
+		if err != nil {
+			logger.Fatalf("fail to parse queue tuning: %v", err)
+		}
+		csiNodeService.SetQueueTuning(tuning)
+	}
+	if *ioErrorsThreshold > 0 {
+		csiNodeService.SetIOErrorsMonitoring(kernellog.NewKernelLog(command.NewExecutor(logger), logger),
+			*ioErrorsThreshold, *ioErrorsWindow)
+	}
+	if *driveCircuitThreshold > 0 {
+		csiNodeService.SetDriveCircuitBreaker(*driveCircuitThreshold, *driveCircuitProbeInterval, *driveCircuitMaxProbeInterval)
+	}
+	if *nodeConditions {
+		csiNodeService.SetNodeConditionsReporting(*diskPressureThreshold)
+	}
+	if *auditEnabled {
+		csiNodeService.SetAuditor(createAuditor(logger))
+	}
+	if *standbyVolumes != "" {
+		counts, err := node.ParseStandbyVolumes(*standbyVolumes)
+		if err != nil {
+			logger.Fatalf("fail to parse standby volumes: %v", err)
+		}
+		csiNodeService.SetStandbyPool(counts, fs.FileSystem(*standbyFSType))
+	}
+	if *asyncFormatting {
+		csiNodeService.SetAsyncFormatting()
+	}
+	if *pvDriveLabels != "" {
+		attributes, err := node.ParsePVDriveLabels(*pvDriveLabels)
+		if err != nil {
+			logger.Fatalf("fail to parse PV drive labels: %v", err)
+		}
+		csiNodeService.SetPVDriveLabels(attributes)
+	}
+	if *partitionScheme != "" {
+		scheme, err := provisioners.ParsePartitionScheme(*partitionScheme)
+		if err != nil {
+			logger.Fatalf("fail to parse partition scheme: %v", err)
+		}
+		if err = csiNodeService.SetPartitionScheme(scheme); err != nil {
+			logger.Fatalf("fail to set partition scheme: %v", err)
+		}
+	}
+	if err := csiNodeService.SetBusyPartitionPolicy(*busyPartitionPolicy); err != nil {
+		logger.Fatalf("fail to set busy partition policy: %v", err)
+	}
+	if err := csiNodeService.SetUnstageUnmountPolicy(*unstageUnmountPolicy, *unstageUnmountRetries,
+		*unstageUnmountRetryInterval); err != nil {
+		logger.Fatalf("fail to set unstage unmount policy: %v", err)
+	}
+	if *duplicateMountsPolicy != "" {
+		if err := csiNodeService.SetDuplicateMountsPolicy(*duplicateMountsPolicy); err != nil {
+			logger.Fatalf("fail to set duplicate mounts policy: %v", err)
+		}
+	}
+	if *partitionAlignmentPolicy != "" {
+		if err := csiNodeService.SetPartitionAlignmentPolicy(*partitionAlignmentPolicy); err != nil {
+			logger.Fatalf("fail to set partition alignment policy: %v", err)
+		}
+	}
+	if *foreignDataScan {
+		csiNodeService.SetForeignDataScan()
+	}
+	if *reportPartitionUUIDs {
+		csiNodeService.SetPartitionUUIDReport()
+	}
+	if *nbdExportAddress != "" {
+		// NBD clients are authorized by certificates, so export isn't served without mutual TLS
+		listenConfig, err := basenet.ListenConfigFromEnv()
+		if err != nil {
+			logger.Fatalf("wrong listen configuration: %v", err)
+		}
+		if !listenConfig.TLS.MutualEnabled() {
+			logger.Fatalf("NBD export requires mutual TLS")
+		}
+		tlsConfig, err := listenConfig.TLS.Config()
+		if err != nil {
+			logger.Fatalf("fail to prepare TLS config of NBD server: %v", err)
+		}
+		server := nbd.NewServer(tlsConfig, logger)
+		go func() {
+			if err := server.ListenAndServe(*nbdExportAddress); err != nil {
+				logger.Fatalf("NBD server failed with error: %v", err)
+			}
+		}()
+		csiNodeService.SetNBDExport(server)
+	}
+	if *burnIn != "" {
+		cfg, err := node.ParseBurnIn(*burnIn)
+		if err != nil {
+			logger.Fatalf("fail to parse burn-in settings: %v", err)
+		}
+		csiNodeService.SetBurnIn(cfg, burnin.NewBurnIn(command.NewExecutor(logger), logger))
+	}
+	if *selfTest {
+		csiNodeService.SetSelfTest(fs.FileSystem(*selfTestFSType))
+	}
+	if err := csiNodeService.SetDiscoveryIntervals(
+		cfg.GetDiscoveryIntervals(*nodeGroup, *discoverFastInterval, *discoverInterval)); err != nil {
+		logger.Fatalf("fail to set discovery intervals: %v", err)
+	}
+	if *hooksDir != "" {
+		csiNodeService.SetProvisioningHooks(hooks.NewRunner(*hooksDir, *hooksTimeout, logger))
+	}
+	if *replacementDrivePolicy != "" {
+		if err := csiNodeService.SetReplacementDrivePolicy(*replacementDrivePolicy); err != nil {
+			logger.Fatalf("fail to set replacement drive policy: %v", err)
+		}
+	}
+	if *verifyVolumeOwnership {
+		csiNodeService.SetVolumeOwnershipVerification()
+	}
+	if *deviceGraphChecks {
+		if err := csiNodeService.SetDeviceGraph(devicegraph.NewSysfsReader()); err != nil {
+			logger.Fatalf("fail to enable device graph checks: %v", err)
+		}
+	}
+	if *cacheVolumeGroup != "" {
+		csiNodeService.SetCacheTier(provisioners.NewCacheTier(*cacheVolumeGroup, command.NewExecutor(logger), logger))
+	}
+	if *templateVolumeGroup != "" {
+		if err := csiNodeService.SetTemplateVolumeGroup(*templateVolumeGroup); err != nil {
+			logger.Fatalf("fail to set template volume group: %v", err)
+		}
+	}
+	csiNodeService.SetKubeletRootDir(*kubeletRootDir)
+	if *verifyMountPropagation {
+		if err := csiNodeService.VerifyMountPropagation(context.Background()); err != nil {
+			logger.Errorf("Mount propagation verification failed: %v", err)
+		}
+	}
+
+	driveCtrl := drive.NewController(wrappedK8SClient, nodeID, clientToDriveMgr, eventRecorder, logger)
+	driveCtrl.SetCache(kubeCache)
+	driveCtrl.SetDriveMgrInfo(driveMgrInfo)
+	if featureConf.IsEnabled(featureconfig.FeatureDriveEvacuation) {
+		k8SClientset, err := k8s.GetK8SClientset()
+		if err != nil {
+			logger.Fatalf("fail to create kubernetes clientset, error: %v", err)
+		}
+		driveCtrl.SetEvacuator(drive.NewEvacuator(wrappedK8SClient, k8SClientset, eventRecorder, logger))
+	}
+
+	lvgCtrl := lvg.NewController(wrappedK8SClient, nodeID, logger)
+	if *lvmMetadataBackupHistory > 0 {
+		archive := lvmbackup.NewArchive(wrappedK8SClient, lvm.NewLVM(command.NewExecutor(logger), logger), *nodeName,
+			*lvmMetadataBackupHistory, logger)
+		csiNodeService.SetLVMMetadataBackup(archive)
+		lvgCtrl.SetMetadataArchive(archive)
+	}
 
 	mgr := prepareCRDControllerManagers(
 		csiNodeService,
-		lvg.NewController(wrappedK8SClient, nodeID, logger),
-		drive.NewController(wrappedK8SClient, nodeID, clientToDriveMgr, eventRecorder, logger),
+		lvgCtrl,
+		driveCtrl,
 		logger)
 
 	// register CSI calls handler
@@ -167,16 +606,31 @@ func main() {
 	csi.RegisterIdentityServer(csiUDSServer.GRPCServer, csiNodeService)
 
 	handler := util.NewSignalHandler(logger)
-	go handler.SetupSIGTERMHandler(csiUDSServer)
+	drained := make(chan struct{})
+	go func() {
+		handler.SetupGracefulSIGTERMHandler(csiUDSServer, csiNodeService, *shutdownGracePeriod)
+		close(drained)
+	}()
+	if err := runLeakGuard(stopCH, wrappedK8SClient, kubeCache, eventRecorder, logger); err != nil {
+		logger.Fatalf("fail to start leak guard: %v", err)
+	}
+	profiler := diagnostics.NewProfiler(*profileSnapshotDir, diagnostics.DefaultSnapshotHistory, logger)
+	profiler.SetEnabled(cfg.ProfilingEnabled(*debugProfiling))
+	if interval := cfg.GetSnapshotInterval(*profileSnapshotInterval); interval > 0 {
+		go profiler.Run(stopCH, interval)
+	}
 	if enableMetrics {
 		grpc_prometheus.Register(csiUDSServer.GRPCServer)
 		grpc_prometheus.EnableHandlingTimeHistogram()
 		grpc_prometheus.EnableClientHandlingTimeHistogram()
 		prometheus.MustRegister(metrics.BuildInfo)
+		prometheus.MustRegister(diagnostics.DefaultRegistry)
 
 		go func() {
-			http.Handle(*metricspath, promhttp.Handler())
-			if err := http.ListenAndServe(*metricsAddress, nil); err != nil {
+			http.Handle(*metricspath, diagnostics.MetricsHandler())
+			http.Handle(diagnostics.StatusPath, diagnostics.Handler())
+			http.Handle(diagnostics.PprofPath, profiler)
+			if err := basenet.ServeHTTP(*metricsAddress, nil); err != nil {
 				logger.Warnf("metric http returned: %s ", err)
 			}
 		}()
@@ -196,10 +650,58 @@ func main() {
 		}
 	}()
 	go Discovering(csiNodeService, logger)
+	if *queueTuning != "" && *queueTuningInterval > 0 {
+		go VerifyingQueueTuning(csiNodeService, *queueTuningInterval, logger)
+	}
+	if *configPath != "" {
+		configWatcher := config.NewWatcher(*configPath, cfg, logger)
+		configWatcher.OnReload(func(reloaded *config.Config) {
+			logger.SetLevel(reloaded.LogrusLevel(*logLevel))
+			profiler.SetEnabled(reloaded.ProfilingEnabled(*debugProfiling))
+			if err := csiNodeService.SetDiscoveryIntervals(
+				reloaded.GetDiscoveryIntervals(*nodeGroup, *discoverFastInterval, *discoverInterval)); err != nil {
+				logger.Errorf("Discovery intervals aren't reloaded: %v", err)
+			}
+		})
+		go func() {
+			if err := configWatcher.Run(stopCH); err != nil {
+				logger.Errorf("Configuration watcher failed with error: %v", err)
+			}
+		}()
+	}
+	if enableMetrics && *volumeIOStatsInterval > 0 {
+		csiNodeService.SetVolumeIOStats()
+		if *noisyNeighborDetection != "" {
+			cfg, err := node.ParseNoisyNeighborDetection(*noisyNeighborDetection)
+			if err != nil {
+				logger.Fatalf("fail to parse noisy neighbor detection thresholds: %v", err)
+			}
+			csiNodeService.SetNoisyNeighborDetection(cfg)
+		}
+		go CollectingVolumeIOStats(csiNodeService, *volumeIOStatsInterval, logger)
+	}
+	if *volumeAutogrow != "" && *volumeAutogrowInterval > 0 {
+		cfg, err := node.ParseVolumeAutogrow(*volumeAutogrow)
+		if err != nil {
+			logger.Fatalf("fail to parse volume autogrow settings: %v", err)
+		}
+		csiNodeService.SetVolumeAutogrow(cfg)
+		go AutogrowingVolumes(csiNodeService, *volumeAutogrowInterval, logger)
+	}
+	if *transferAddress != "" {
+		if err := startTransferServer(csiNodeService, logger); err != nil {
+			logger.Fatalf("fail to start data transfer service: %v", err)
+		}
+	}
 
 	// wait for readiness
 	waitForVolumeManagerReadiness(csiNodeService, logger)
 
+	// device names might be changed after reboot, volumes should be checked before handling CSI calls
+	if err := csiNodeService.ValidateVolumeDevices(context.Background()); err != nil {
+		logger.Errorf("Unable to validate backing devices of volumes: %v", err)
+	}
+
 	// start to updating Wbt Config
 	wbtWatcher.StartWatch(csiNodeService)
 
@@ -209,6 +711,7 @@ func main() {
 	}
 
 	logger.Info("Got SIGTERM signal")
+	<-drained
 }
 
 func obtainNodeIDWithRetries(client k8sClient.Client, featureConf featureconfig.FeatureChecker,
@@ -249,22 +752,73 @@ func waitForVolumeManagerReadiness(c *node.CSINodeService, logger *logrus.Logger
 	logger.Fatalf("Number of retries %d exceeded. Exiting...", numberOfRetries)
 }
 
-// Discovering performs Discover method of the Node each 30 seconds
+// Discovering performs Discover method of the Node with adaptive interval, see node.VolumeManager.NextDiscoverInterval
 func Discovering(c *node.CSINodeService, logger *logrus.Logger) {
 	var err error
-	// set initial delay
-	discoveringWaitTime := 10 * time.Second
 	checker := c.GetLivenessHelper()
 	for {
-		time.Sleep(discoveringWaitTime)
-		if err = c.Discover(); err != nil {
+		time.Sleep(c.NextDiscoverInterval())
+		done := diagnostics.Observe("discover")
+		err = c.Discover()
+		done(err)
+		if err != nil {
 			checker.Fail()
 			logger.Errorf("Discover finished with error: %v", err)
 		} else {
 			checker.OK()
 			logger.Tracef("Discover finished successful")
-			// Increase wait time, because we don't need to call API often after node initialization
-			discoveringWaitTime = 30 * time.Second
+		}
+	}
+}
+
+// startTransferServer starts data transfer service which serves content of volumes of the node to other node pods
+func startTransferServer(c *node.CSINodeService, logger *logrus.Logger) error {
+	limit, err := resource.ParseQuantity(*transferBandwidthLimit)
+	if err != nil {
+		return fmt.Errorf("wrong bandwidth limit %s: %v", *transferBandwidthLimit, err)
+	}
+	serverConfig, _, err := transfer.LoadTLSConfigs(*transferCertDir)
+	if err != nil {
+		return err
+	}
+	server := transfer.NewServer(c.VolumeDataSource(), serverConfig, transfer.NewLimiter(limit.Value()), logger)
+	go func() {
+		if err := server.ListenAndServe(*transferAddress); err != nil {
+			logger.Fatalf("Data transfer service failed with error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// CollectingVolumeIOStats performs CollectVolumeIOStats method of the Node with interval
+func CollectingVolumeIOStats(c *node.CSINodeService, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := c.CollectVolumeIOStats(context.Background()); err != nil {
+			logger.Errorf("Collection of volume I/O stats finished with error: %v", err)
+		}
+	}
+}
+
+// AutogrowingVolumes performs AutogrowVolumes method of the Node with interval
+func AutogrowingVolumes(c *node.CSINodeService, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := c.AutogrowVolumes(context.Background()); err != nil {
+			logger.Errorf("Autogrow of volumes finished with error: %v", err)
+		}
+	}
+}
+
+// VerifyingQueueTuning performs VerifyQueueTuning method of the Node with interval
+func VerifyingQueueTuning(c *node.CSINodeService, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := c.VerifyQueueTuning(context.Background()); err != nil {
+			logger.Errorf("Verification of queue tuning finished with error: %v", err)
 		}
 	}
 }
@@ -296,7 +850,8 @@ func prepareCRDControllerManagers(volumeCtrl *node.CSINodeService, lvgCtrl *lvg.
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme: scheme,
+		Scheme:     scheme,
+		SyncPeriod: ctrlopts.ResyncInterval(ll),
 	})
 	if err != nil {
 		ll.Fatalf("Unable to create new CRD Controller Manager: %v", err)
@@ -354,3 +909,119 @@ func prepareWbtWatcher(client k8sClient.Client, eventsRecorder *events.Recorder,
 
 	return wbt.NewConfWatcher(client, eventsRecorder, ll, nodeKernel), nil
 }
+
+// negotiateDriveMgrAPIVersion checks that drive manager (in-tree or vendor sidecar) implements supported
+// DriveService API version, exits if version is incompatible
+// Returns description of drive manager, legacy description if drive manager isn't available
+func negotiateDriveMgrAPIVersion(client api.DriveServiceClient, logger *logrus.Logger) *api.DriveManagerInfo {
+	var (
+		ctx      = context.Background()
+		cancelFn = func() {}
+		opts     []grpc.CallOption
+	)
+	if *driveMgrNegotiationTimeout > 0 {
+		ctx, cancelFn = context.WithTimeout(ctx, *driveMgrNegotiationTimeout)
+		opts = append(opts, grpc.WaitForReady(true))
+	}
+	defer cancelFn()
+
+	info, err := drivemgr.NegotiateAPIVersion(ctx, client, opts...)
+	switch {
+	case errors.Is(err, drivemgr.ErrIncompatibleAPIVersion):
+		logger.Fatalf("Unable to use drive manager: %v", err)
+	case err != nil:
+		info = drivemgr.LegacyInfo()
+		logger.Warnf("Unable to get drive manager info, assume API version %s with capabilities %v: %v",
+			info.ApiVersion, info.Capabilities, err)
+	default:
+		logger.Infof("Drive manager %s %s, API version %s, capabilities %v",
+			info.Name, info.Version, info.ApiVersion, info.Capabilities)
+	}
+	return info
+}
+
+// recordNodeInfoWithRetries calls recordNodeInfo until it succeeds, Node CR might be created by node controller
+// after node service is started
+func recordNodeInfoWithRetries(client k8sClient.Client, nodeID string, versions map[string]utilversion.Version,
+	logger *logrus.Logger) (err error) {
+	for i := 0; i < numberOfRetries; i++ {
+		if err = recordNodeInfo(client, nodeID, versions); err == nil {
+			logger.Infof("Protocol version, capabilities and versions of system utilities are recorded in Node CR")
+			return nil
+		}
+		logger.Warningf("Unable to record node info due to %v, sleep and retry...", err)
+		time.Sleep(delayBeforeRetry * time.Second)
+	}
+	return fmt.Errorf("number of retries %d exceeded: %v", numberOfRetries, err)
+}
+
+// recordNodeInfo saves detected versions of system utilities, protocol version and capabilities of node service
+// in Node CR
+func recordNodeInfo(client k8sClient.Client, nodeID string, versions map[string]utilversion.Version) error {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelFn()
+
+	nodes := &nodecrd.NodeList{}
+	if err := client.List(ctx, nodes); err != nil {
+		return err
+	}
+	for i := range nodes.Items {
+		if nodes.Items[i].Spec.UUID != nodeID {
+			continue
+		}
+		nodes.Items[i].Spec.Utilities = utilversion.ToMap(versions)
+		nodeprotocol.Report(&nodes.Items[i].Spec)
+		return client.Update(ctx, &nodes.Items[i])
+	}
+	return fmt.Errorf("node CR with UUID %s isn't found", nodeID)
+}
+
+// createAuditor creates Auditor with file and syslog sinks configured by flags
+func createAuditor(logger *logrus.Logger) *audit.Auditor {
+	var sinks []audit.Sink
+	if *auditLogPath != "" {
+		fileSink, err := audit.NewFileSink(*auditLogPath)
+		if err != nil {
+			logger.Fatalf("fail to create audit log: %v", err)
+		}
+		sinks = append(sinks, fileSink)
+	}
+	if *auditSyslog {
+		syslogSink, err := audit.NewSyslogSink(componentName)
+		if err != nil {
+			logger.Fatalf("fail to create audit syslog sink: %v", err)
+		}
+		sinks = append(sinks, syslogSink)
+	}
+	return audit.NewAuditor(logger, sinks...)
+}
+
+// leakGuardEnabled returns whether any threshold of leak guard is set
+func leakGuardEnabled() bool {
+	return *leakWarningGrowth > 0 || *goroutineRestartThreshold > 0 || *heapRestartThreshold != "0"
+}
+
+// runLeakGuard starts checks of resource leaks if any threshold is set, events are recorded for the pod of the service
+func runLeakGuard(ctx context.Context, kubeClient *k8s.KubeClient, kubeCache *k8s.KubeCache,
+	eventRecorder *events.Recorder, logger *logrus.Logger) error {
+	if !leakGuardEnabled() {
+		return nil
+	}
+	heapThreshold, err := resource.ParseQuantity(*heapRestartThreshold)
+	if err != nil {
+		return fmt.Errorf("wrong heap restart threshold %s: %v", *heapRestartThreshold, err)
+	}
+	var object runtime.Object
+	if pod, err := kubeClient.GetOwnPod(ctx); err == nil {
+		object = pod
+	} else {
+		logger.Warnf("Events of leak guard aren't recorded, unable to read pod: %v", err)
+	}
+	guard := diagnostics.NewLeakGuard(diagnostics.LeakGuardConfig{
+		WarningGrowth:             *leakWarningGrowth,
+		GoroutineRestartThreshold: *goroutineRestartThreshold,
+		HeapRestartThreshold:      uint64(heapThreshold.Value()),
+	}, kubeCache.ObjectCounts, eventRecorder, object, logger)
+	go guard.Run(ctx, *leakCheckInterval)
+	return nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+// RBAC rules of csi-baremetal-node service account, node service and drive managers run in DaemonSet on each node.
+// Cluster scoped CRs (Drive, AvailableCapacity, LogicalVolumeGroup) are managed for the current node only,
+// Node CR of the current node is updated with protocol version, capabilities and versions of system utilities,
+// Volume CRs are created by controller in PVC namespaces and only updated here.
+// Secret lvm-metadata-<node name> keeps backups of LVM metadata when --lvm-metadata-backup-history is set.
+// Rules are generated with `make generate-rbac` and used by csi-baremetal-operator, don't edit generated files.
+
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=drives,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=availablecapacities,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=logicalvolumegroups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=volumes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=drives/status;availablecapacities/status;logicalvolumegroups/status;volumes/status,verbs=update;patch
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=csi-baremetal.dell.com,resources=histories,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes/status,verbs=update;patch
+// +kubebuilder:rbac:groups="",resources=persistentvolumes;persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=update;patch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=update;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",namespace=csi-baremetal,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",namespace=csi-baremetal,resources=secrets,verbs=get;create;update
+
+// OpenShift: node service and drive managers run privileged containers with host mounts, ServiceAccount is allowed
+// to use privileged SecurityContextConstraints. The rule doesn't have any effect on other distributions.
+// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=privileged,verbs=use
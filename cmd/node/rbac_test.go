@@ -0,0 +1,74 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/utilversion"
+	"github.com/dell/csi-baremetal/pkg/base/lvmbackup"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+	"github.com/dell/csi-baremetal/pkg/testutils"
+)
+
+// TestRBAC runs LVM metadata backup and recording of node info over client which records requests
+// and checks that RBAC markers allow each of them
+func TestRBAC(t *testing.T) {
+	var (
+		logger = logrus.New()
+		ctx    = context.Background()
+		lvmOps = &mocklu.MockWrapLVM{}
+	)
+	rules, err := testutils.ParseRBACMarkers("rbac.go")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, rules)
+
+	kubeClient, recorder, err := testutils.NewRecordingKubeClient("csi-baremetal", logger)
+	assert.Nil(t, err)
+	nodeCR := kubeClient.ConstructCSIBMNodeCR("csibmnode-1", api.Node{UUID: "node-1"})
+	assert.Nil(t, kubeClient.CreateCR(ctx, nodeCR.Name, nodeCR))
+	recorder.Reset()
+
+	assert.Nil(t, recordNodeInfo(recorder, "node-1", map[string]utilversion.Version{
+		utilversion.Lsblk: {Major: 2, Minor: 32},
+	}))
+	assert.Nil(t, kubeClient.ReadCR(ctx, nodeCR.Name, "", nodeCR))
+	assert.NotEmpty(t, nodeCR.Spec.ProtocolVersion)
+
+	archive := lvmbackup.NewArchive(kubeClient, lvmOps, "node-1", 2, logger)
+	lvmOps.On("VGCfgBackup", "vg-1", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		assert.Nil(t, ioutil.WriteFile(args.String(1), []byte("vg-1"), 0600))
+	})
+	// Secret is created on first backup and updated on the next one
+	lvmOps.On("GetVGSeqNo", "vg-1").Return(int64(1), nil).Once()
+	assert.Nil(t, archive.Backup(ctx, []string{"vg-1"}))
+	lvmOps.On("GetVGSeqNo", "vg-1").Return(int64(2), nil).Once()
+	assert.Nil(t, archive.Backup(ctx, []string{"vg-1"}))
+
+	calls := recorder.Calls()
+	assert.NotEmpty(t, calls)
+	for _, call := range calls {
+		assert.True(t, testutils.RBACAllows(rules, call), "RBAC rule is missing: %s", call)
+	}
+}
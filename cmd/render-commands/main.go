@@ -0,0 +1,89 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package for main function of developer tool which renders commands that node service runs
+// to reconcile Volume or Drive CR, it runs from workstation, commands aren't run
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dell/csi-baremetal/pkg/base/logger"
+	"github.com/dell/csi-baremetal/pkg/render"
+)
+
+var (
+	manifest = flag.String("manifest", "",
+		"Path of YAML or JSON manifest with Volume or Drive CR, Drive CR of drive based volume is added to the same "+
+			"manifest after \"---\", stdin if empty")
+	operation = flag.String("operation", "",
+		fmt.Sprintf("Operation which is rendered: %s or %s, operation for Volume CR is chosen by its CSI status if empty",
+			render.OperationCreate, render.OperationRelease))
+	logLevel = flag.String("loglevel", "",
+		fmt.Sprintf("Log level of node components, logs are written to stderr and disabled if empty, "+
+			"support values are %s, %s, %s", logger.InfoLevel, logger.DebugLevel, logger.TraceLevel))
+)
+
+func main() {
+	flag.Parse()
+
+	logger, _ := logger.InitLogger("", *logLevel)
+	if logger == nil {
+		fmt.Println("Unable to initialize logger")
+		os.Exit(1)
+	}
+	var output io.Writer = os.Stderr
+	if *logLevel == "" {
+		output = ioutil.Discard
+	}
+	logger.SetOutput(output)
+	// some components log with standard logger
+	logrus.SetOutput(output)
+
+	var (
+		data []byte
+		err  error
+	)
+	if *manifest == "" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(*manifest)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	renderer := render.NewRenderer(logger)
+	if err = renderer.LoadManifests(data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	steps, err := renderer.Render(*operation)
+	for _, step := range steps {
+		fmt.Println(step)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Operation fails after the last command: %v\n", err)
+		os.Exit(1)
+	}
+}
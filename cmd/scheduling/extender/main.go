@@ -29,12 +29,14 @@ import (
 	storageV1 "k8s.io/api/storage/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/dell/csi-baremetal/api/v1/nodecrd"
 	"github.com/dell/csi-baremetal/api/v1/volumecrd"
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/logger"
 	"github.com/dell/csi-baremetal/pkg/base/logger/objects"
+	basenet "github.com/dell/csi-baremetal/pkg/base/net"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	"github.com/dell/csi-baremetal/pkg/scheduler/extender"
 	"github.com/dell/csi-baremetal/pkg/scheduler/extender/healthserver"
@@ -59,6 +61,10 @@ var (
 	healthIP          = flag.String("healthip", base.DefaultHealthIP, "IP for health service")
 	healthPort        = flag.Int("healthport", base.DefaultHealthPort, "Port for health service")
 	isPatchingEnabled = flag.Bool("isPatchingEnabled", false, "should enable readiness probe")
+
+	reservationPreemption = flag.Bool("reservation-preemption", false,
+		"Whether capacity reservations of pending pods with lower priority are preempted when reservation of pod "+
+			"is rejected or not")
 )
 
 // TODO should be passed as parameters https://github.com/dell/csi-baremetal/issues/78
@@ -66,6 +72,7 @@ const (
 	FilterPattern     string = "/filter"
 	PrioritizePattern string = "/prioritize"
 	BindPattern       string = "/bind"
+	PreemptPattern    string = "/preempt"
 )
 
 func main() {
@@ -78,7 +85,7 @@ func main() {
 	if *metricspath != "" {
 		go func() {
 			http.Handle(*metricspath, promhttp.Handler())
-			if err := http.ListenAndServe(*metricsAddress, nil); err != nil {
+			if err := basenet.ServeHTTP(*metricsAddress, nil); err != nil {
 				logger.Warnf("metric http returned: %s ", err)
 			}
 		}()
@@ -97,7 +104,8 @@ func main() {
 	kubeCache, err := k8s.InitKubeCache(stopCH, logger,
 		&coreV1.PersistentVolumeClaim{},
 		&storageV1.StorageClass{},
-		&volumecrd.Volume{})
+		&volumecrd.Volume{},
+		&nodecrd.Node{})
 
 	if err != nil {
 		logger.Fatalf("Fail to init kubeCache: %v", err)
@@ -121,6 +129,7 @@ func main() {
 	if err != nil {
 		logger.Fatalf("Fail to create extender: %v", err)
 	}
+	newExtender.SetReservationPreemption(*reservationPreemption)
 
 	logger.Infof("Starting extender on port %d ...", *port)
 	// filter stage
@@ -135,15 +144,24 @@ func main() {
 	logger.Infof("Registering for bind stage ... ")
 	http.HandleFunc(BindPattern, newExtender.BindHandler)
 
-	var addr = fmt.Sprintf(":%d", *port)
-	if *certFile != "" && *privateKeyFile != "" {
-		logger.Info("Handle with TLS")
-		err = http.ListenAndServeTLS(addr, *certFile, *privateKeyFile, nil)
-	} else {
-		err = http.ListenAndServe(addr, nil)
+	// preempt stage
+	if *reservationPreemption {
+		logger.Infof("Registering for preempt stage ... ")
+		http.HandleFunc(PreemptPattern, newExtender.PreemptHandler)
 	}
 
+	listenConfig, err := basenet.ListenConfigFromEnv()
 	if err != nil {
+		logger.Fatalf("Wrong listen configuration: %v", err)
+	}
+	// certificate from flags takes precedence over the one which is common for all services
+	if *certFile != "" && *privateKeyFile != "" {
+		listenConfig.TLS.CertFile, listenConfig.TLS.KeyFile = *certFile, *privateKeyFile
+	}
+	if listenConfig.TLS.Enabled() {
+		logger.Info("Handle with TLS")
+	}
+	if err = listenConfig.ServeHTTP(fmt.Sprintf(":%d", *port), nil); err != nil {
 		logger.Fatal(err)
 	}
 	os.Exit(0)
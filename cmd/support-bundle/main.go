@@ -0,0 +1,107 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package for main function of support bundle collector, it runs from workstation with kubeconfig or as k8s Job
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/logger"
+	"github.com/dell/csi-baremetal/pkg/base/logger/objects"
+	"github.com/dell/csi-baremetal/pkg/supportbundle"
+)
+
+var (
+	namespace = flag.String("namespace", "default", "Namespace in which CSI is deployed")
+	nodes     = flag.String("nodes", "",
+		"Comma separated names of k8s nodes which logs and state are collected, all nodes if empty")
+	output = flag.String("output", "",
+		"Path of the archive, csi-baremetal-support-<timestamp>.tar.gz in current directory by default")
+	logTailLines = flag.Int64("log-tail-lines", supportbundle.DefaultLogTailLines,
+		"The number of the latest log lines collected per container")
+	nodeContainer = flag.String("node-container", supportbundle.DefaultNodeContainer,
+		"Name of node service container in which node state commands are run")
+	execMode = flag.String("exec-mode", command.ExecModeContainer,
+		fmt.Sprintf("Exec mode of node service, supported values are %s, %s, %s, %s", command.ExecModeContainer,
+			command.ExecModeNsenter, command.ExecModeChroot, command.ExecModeSudo))
+	timeout  = flag.Duration("timeout", 10*time.Minute, "Timeout of collection")
+	logLevel = flag.String("loglevel", logger.InfoLevel,
+		fmt.Sprintf("Log level, support values are %s, %s, %s", logger.InfoLevel, logger.DebugLevel, logger.TraceLevel))
+)
+
+func main() {
+	flag.Parse()
+
+	logger, _ := logger.InitLogger("", *logLevel)
+	if logger == nil {
+		fmt.Println("Unable to initialize logger")
+		os.Exit(1)
+	}
+
+	execPrefix, err := command.ExecPrefix(*execMode)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	config := ctrl.GetConfigOrDie()
+	k8sClient, err := k8s.GetK8SClient()
+	if err != nil {
+		logger.Fatalf("Unable to create k8s client: %v", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		logger.Fatalf("Unable to create k8s clientset: %v", err)
+	}
+	kubeClient := k8s.NewKubeClient(k8sClient, logger, objects.NewObjectLogger(), *namespace)
+
+	collector := supportbundle.NewCollector(kubeClient, clientset, supportbundle.NewPodExecutor(config, clientset), logger)
+	if *nodes != "" {
+		collector.SetNodes(strings.Split(*nodes, ","))
+	}
+	collector.SetNodeContainer(*nodeContainer, execPrefix)
+	collector.SetLogTailLines(*logTailLines)
+
+	path := *output
+	if path == "" {
+		path = fmt.Sprintf("csi-baremetal-support-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		logger.Fatalf("Unable to create %s: %v", path, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	err = collector.Collect(ctx, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.Fatalf("Unable to collect support bundle: %v", err)
+	}
+	logger.Infof("Support bundle is written to %s", path)
+}
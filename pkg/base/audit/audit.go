@@ -0,0 +1,167 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit contains code for recording destructive operations on drives into append-only audit trail
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Operation is a destructive operation on drive
+type Operation string
+
+// Destructive operations which are recorded into audit trail
+const (
+	MakeFS          Operation = "MakeFS"
+	WipeFS          Operation = "WipeFS"
+	DeletePartition Operation = "DeletePartition"
+	RemoveLV        Operation = "RemoveLV"
+)
+
+// Results of destructive operations
+const (
+	ResultSuccess = "Success"
+	ResultFailure = "Failure"
+)
+
+// RequesterAnnotationKey is an annotation of PVC with identity of operator who requested the operation,
+// if annotation isn't set the last field manager of Volume CR is used
+const RequesterAnnotationKey = "csi-baremetal.dell.com/requested-by"
+
+// Record is an entry of audit trail
+type Record struct {
+	Time      time.Time `json:"time"`
+	Operation Operation `json:"operation"`
+	NodeID    string    `json:"nodeID"`
+	Device    string    `json:"device"`
+	VolumeID  string    `json:"volumeID,omitempty"`
+	PVC       string    `json:"pvc,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Requester string    `json:"requester,omitempty"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// String returns human readable representation of Record
+func (r *Record) String() string {
+	return fmt.Sprintf("operation %s on device %s, volume %s, PVC %s/%s, requested by %s, result %s",
+		r.Operation, r.Device, r.VolumeID, r.Namespace, r.PVC, r.Requester, r.Result)
+}
+
+// Sink is a destination of audit records
+type Sink interface {
+	Write(r *Record) error
+}
+
+// Auditor writes audit records into all configured sinks
+type Auditor struct {
+	sinks []Sink
+	log   *logrus.Entry
+}
+
+// NewAuditor is a constructor for Auditor struct
+// Receives logrus logger and optional sinks, records are always written into the log
+func NewAuditor(logger *logrus.Logger, sinks ...Sink) *Auditor {
+	return &Auditor{
+		sinks: sinks,
+		log:   logger.WithField("component", "Auditor"),
+	}
+}
+
+// Record writes audit record into log and all sinks, errors of sinks are logged
+func (a *Auditor) Record(r *Record) {
+	ll := a.log.WithFields(logrus.Fields{
+		"method":   "Record",
+		"volumeID": r.VolumeID,
+	})
+
+	if r.Time.IsZero() {
+		r.Time = time.Now().UTC()
+	}
+	ll.Infof("Audit: %s", r)
+	for _, s := range a.sinks {
+		if err := s.Write(r); err != nil {
+			ll.Errorf("Unable to write audit record into sink: %v", err)
+		}
+	}
+}
+
+// FileSink writes audit records into file as JSON lines, file is opened in append-only mode
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens or creates file for audit records
+// Returns FileSink or error if file can't be opened
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log %s: %v", path, err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write appends record into file and syncs it to disk
+func (f *FileSink) Write(r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err = f.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+// Close closes audit log file
+func (f *FileSink) Close() error {
+	return f.file.Close()
+}
+
+// SyslogSink writes audit records into local syslog daemon
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to local syslog daemon
+// Returns SyslogSink or error if syslog isn't available
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	writer, err := syslog.New(syslog.LOG_NOTICE|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to syslog: %v", err)
+	}
+	return &SyslogSink{writer: writer}, nil
+}
+
+// Write sends record into syslog in JSON format
+func (s *SyslogSink) Write(r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.writer.Notice(string(data))
+}
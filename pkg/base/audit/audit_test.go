@@ -0,0 +1,81 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type memSink struct {
+	records []*Record
+	err     error
+}
+
+func (m *memSink) Write(r *Record) error {
+	m.records = append(m.records, r)
+	return m.err
+}
+
+func TestAuditor_Record(t *testing.T) {
+	var (
+		ok     = &memSink{}
+		broken = &memSink{err: errors.New("broken")}
+		a      = NewAuditor(logrus.New(), broken, ok)
+	)
+
+	a.Record(&Record{Operation: WipeFS, Device: "/dev/sda", VolumeID: "pvc-1", Result: ResultSuccess})
+	assert.Len(t, broken.records, 1)
+	assert.Len(t, ok.records, 1)
+	assert.False(t, ok.records[0].Time.IsZero())
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	for i := 0; i < 2; i++ {
+		s, err := NewFileSink(path)
+		assert.Nil(t, err)
+		assert.Nil(t, s.Write(&Record{Operation: DeletePartition, Device: "/dev/sdb", PVC: "data", Namespace: "default"}))
+		assert.Nil(t, s.Close())
+	}
+
+	file, err := os.Open(path)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r Record
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	assert.Len(t, records, 2)
+	assert.Equal(t, DeletePartition, records[1].Operation)
+	assert.Equal(t, "data", records[1].PVC)
+
+	_, err = NewFileSink(filepath.Join(path, "missing", "audit.log"))
+	assert.NotNil(t, err)
+}
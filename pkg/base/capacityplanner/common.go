@@ -16,34 +16,37 @@ limitations under the License.
 
 package capacityplanner
 
-import "github.com/dell/csi-baremetal/pkg/base/util"
+import (
+	"math"
+
+	"github.com/dell/csi-baremetal/pkg/base/units"
+)
 
 // AcSizeMinThresholdBytes means that if AC size becomes lower then AcSizeMinThresholdBytes that AC should be deleted
-const AcSizeMinThresholdBytes = int64(util.MBYTE) // 1MB
+const AcSizeMinThresholdBytes = int64(units.MiB) // 1MB
 
 // LvgDefaultMetadataSize is additional cost for new VG we should consider.
-const LvgDefaultMetadataSize = int64(util.MBYTE) // 1MB
+const LvgDefaultMetadataSize = int64(units.MiB) // 1MB
 
 // DefaultPESize is the default extent size we should align with
 // TODO: use non default PE size - https://github.com/dell/csi-baremetal/issues/85
-const DefaultPESize = 4 * int64(util.MBYTE)
+const DefaultPESize = 4 * int64(units.MiB)
 
-// AlignSizeByPE make size aligned with default PE
+// AlignSizeByPE make size aligned with default PE, size which overflows int64 after alignment
+// is turned into math.MaxInt64, so it doesn't fit into any AC
 // TODO: use non default PE size - https://github.com/dell/csi-baremetal/issues/85
 func AlignSizeByPE(size int64) int64 {
-	var alignement int64
-	reminder := size % DefaultPESize
-	if reminder != 0 {
-		alignement = DefaultPESize - reminder
+	aligned, err := units.AlignUp(size, DefaultPESize)
+	if err != nil {
+		return math.MaxInt64
 	}
-	return size + alignement
+	return aligned
 }
 
 // SubtractLVMMetadataSize subtracts LVM metadata size from raw drive size
 func SubtractLVMMetadataSize(size int64) int64 {
-	reminder := size % DefaultPESize
-	result := size - reminder
-	if reminder < LvgDefaultMetadataSize {
+	result := units.AlignDown(size, DefaultPESize)
+	if size-result < LvgDefaultMetadataSize {
 		return result - DefaultPESize
 	}
 	return result
@@ -24,6 +24,7 @@ import (
 	v1 "github.com/dell/csi-baremetal/api/v1"
 	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
 	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/pkg/base/units"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 )
 
@@ -75,6 +76,11 @@ func newNodeCapacity(node string, acs []accrd.AvailableCapacity, acrs []acrcrd.A
 
 	// Sort AC to have the persistent order for each reservation
 	sort.Slice(acs, func(i, j int) bool {
+		// By temperature (ACs on hot drives last) to spread thermal load
+		if isHot(acs[i]) != isHot(acs[j]) {
+			return !isHot(acs[i])
+		}
+
 		// By size (the smallest first)
 		if acs[i].Spec.Size < acs[j].Spec.Size {
 			return true
@@ -114,7 +120,7 @@ func newNodeCapacity(node string, acs []accrd.AvailableCapacity, acrs []acrcrd.A
 		}
 		for _, request := range acr.Spec.ReservationRequests {
 			reservedCapacity := &reservedCapacity{
-				Size:         request.CapacityRequest.Size,
+				Size:         requiredSize(request.CapacityRequest.Size, request.CapacityRequest.StorageClass),
 				StorageClass: request.CapacityRequest.StorageClass,
 			}
 			// Add reservation from ACR or update existed one if it repeats more than one time
@@ -138,15 +144,18 @@ func newNodeCapacity(node string, acs []accrd.AvailableCapacity, acrs []acrcrd.A
 }
 
 func (nc *nodeCapacity) selectACForVolume(vol *genV1.Volume) *accrd.AvailableCapacity {
-	requiredSize := vol.GetSize()
-	if util.IsStorageClassLVG(vol.StorageClass) {
-		// we should round up volume size, it should be aligned with LVM PE size
-		// TODO: use non default PE size - https://github.com/dell/csi-baremetal/issues/85
-		requiredSize = AlignSizeByPE(vol.GetSize())
-	}
+	required := requiredSize(vol.GetSize(), vol.StorageClass)
 
 	for _, ac := range nc.acsOrder[vol.StorageClass] {
-		if requiredSize <= nc.acs[ac].Spec.Size {
+		// volume pinned to the drive might use AC of the drive or its LVG only
+		if vol.Location != "" && nc.acs[ac].Spec.Location != vol.Location {
+			continue
+		}
+		capacity := nc.acs[ac].Spec.Size
+		if util.IsStorageClassLVG(vol.StorageClass) {
+			capacity = lvgCapacity(nc.acs[ac])
+		}
+		if required <= capacity {
 			// check if AC is reserved
 			reservation, ok := nc.reservedACs[ac]
 
@@ -154,7 +163,7 @@ func (nc *nodeCapacity) selectACForVolume(vol *genV1.Volume) *accrd.AvailableCap
 			if !ok {
 				foundAC := nc.acs[ac]
 				nc.reservedACs[foundAC.Name] = &reservedCapacity{
-					Size:         vol.Size,
+					Size:         required,
 					StorageClass: vol.StorageClass,
 				}
 				return foundAC
@@ -171,9 +180,9 @@ func (nc *nodeCapacity) selectACForVolume(vol *genV1.Volume) *accrd.AvailableCap
 			}
 
 			// select AC, if it has enough capacity
-			if reservation.Size+requiredSize <= nc.acs[ac].Spec.Size {
+			if total, err := units.Add(reservation.Size, required); err == nil && total <= capacity {
 				foundAC := nc.acs[ac]
-				nc.reservedACs[foundAC.Name].Size += requiredSize
+				nc.reservedACs[foundAC.Name].Size += required
 				return foundAC
 			}
 		}
@@ -182,6 +191,30 @@ func (nc *nodeCapacity) selectACForVolume(vol *genV1.Volume) *accrd.AvailableCap
 	return nil
 }
 
+// requiredSize returns capacity which volume of provided size and storage class takes,
+// LVG volumes are rounded up to LVM PE size
+// TODO: use non default PE size - https://github.com/dell/csi-baremetal/issues/85
+func requiredSize(size int64, storageClass string) int64 {
+	if util.IsStorageClassLVG(storageClass) {
+		return AlignSizeByPE(size)
+	}
+	return size
+}
+
+// lvgCapacity returns size of LVG volumes which fit into AC, LVG which is created on free drive
+// is smaller than AC of the drive by LVM metadata
+func lvgCapacity(ac *accrd.AvailableCapacity) int64 {
+	if util.IsStorageClassLVG(ac.Spec.StorageClass) {
+		return ac.Spec.Size
+	}
+	return SubtractLVMMetadataSize(ac.Spec.Size)
+}
+
+// isHot returns true if AC is marked by node service as located on the drive with high temperature
+func isHot(ac accrd.AvailableCapacity) bool {
+	return ac.GetAnnotations()[v1.DriveAnnotationTemperature] == v1.DriveTemperatureHigh
+}
+
 func buildACMap(acs []accrd.AvailableCapacity) ACMap {
 	acMap := ACMap{}
 	for i, ac := range acs {
@@ -221,6 +221,34 @@ func TestNewNodeCapacity(t *testing.T) {
 	}
 }
 
+func TestNewNodeCapacity_HotACsLast(t *testing.T) {
+	hotAC := *getTestAC(nodeName, testSmallSize, apiV1.StorageClassHDD)
+	hotAC.Annotations = map[string]string{apiV1.DriveAnnotationTemperature: apiV1.DriveTemperatureHigh}
+	coolAC := *getTestAC(nodeName, testLargeSize, apiV1.StorageClassHDD)
+
+	nc := newNodeCapacity(nodeName, []accrd.AvailableCapacity{hotAC, coolAC}, nil)
+	assert.Equal(t, []string{coolAC.Name, hotAC.Name}, nc.acsOrder[apiV1.StorageClassHDD])
+	assert.Equal(t, coolAC.Name, nc.selectACForVolume(&genV1.Volume{Size: testSmallSize, StorageClass: apiV1.StorageClassHDD}).Name)
+}
+
+func TestNewNodeCapacity_PinnedVolume(t *testing.T) {
+	small := *getTestAC(nodeName, testSmallSize, apiV1.StorageClassHDD)
+	small.Spec.Location = "drive-1"
+	large := *getTestAC(nodeName, testLargeSize, apiV1.StorageClassHDD)
+	large.Spec.Location = "drive-2"
+
+	nc := newNodeCapacity(nodeName, []accrd.AvailableCapacity{small, large}, nil)
+	assert.Nil(t, nc.selectACForVolume(&genV1.Volume{Size: testSmallSize, StorageClass: apiV1.StorageClassSSD,
+		Location: "drive-2"}))
+	assert.Equal(t, large.Name, nc.selectACForVolume(&genV1.Volume{Size: testSmallSize,
+		StorageClass: apiV1.StorageClassHDD, Location: "drive-2"}).Name)
+	assert.Equal(t, small.Name, nc.selectACForVolume(&genV1.Volume{Size: testSmallSize,
+		StorageClass: apiV1.StorageClassHDD}).Name)
+	// pinned drive is reserved already
+	assert.Nil(t, nc.selectACForVolume(&genV1.Volume{Size: testSmallSize, StorageClass: apiV1.StorageClassHDD,
+		Location: "drive-1"}))
+}
+
 func TestSelectACForVolume(t *testing.T) {
 	type args struct {
 		nc  *nodeCapacity
@@ -303,20 +331,40 @@ func TestSelectACForVolume(t *testing.T) {
 				nc: newNodeCapacity(nodeName,
 					[]accrd.AvailableCapacity{testACHDD1},
 					nil),
-				vol: getTestVol(nodeName, testSmallSize, apiV1.StorageClassHDDLVG),
+				vol: getTestVol(nodeName, testSmallSize-DefaultPESize, apiV1.StorageClassHDDLVG),
 			},
 			want: &testACHDD1,
 		},
+		{
+			name: "Should reject non-LVG for LVG without space for LVM metadata",
+			args: args{
+				nc: newNodeCapacity(nodeName,
+					[]accrd.AvailableCapacity{testACHDD1},
+					nil),
+				vol: getTestVol(nodeName, testSmallSize, apiV1.StorageClassHDDLVG),
+			},
+			want: nil,
+		},
 		{
 			name: "Should reserve non-LVG for LVG with ACR",
 			args: args{
 				nc: newNodeCapacity(nodeName,
 					[]accrd.AvailableCapacity{testACHDD2},
 					[]acrcrd.AvailableCapacityReservation{testACRHDDLVG1}),
-				vol: getTestVol(nodeName, testSmallSize, apiV1.StorageClassHDDLVG),
+				vol: getTestVol(nodeName, testSmallSize-DefaultPESize, apiV1.StorageClassHDDLVG),
 			},
 			want: &testACHDD2,
 		},
+		{
+			name: "Should reject non-LVG for LVG with ACR if aligned sizes don't fit",
+			args: args{
+				nc: newNodeCapacity(nodeName,
+					[]accrd.AvailableCapacity{testACHDD2},
+					[]acrcrd.AvailableCapacityReservation{testACRHDDLVG1}),
+				vol: getTestVol(nodeName, testSmallSize-DefaultPESize+1, apiV1.StorageClassHDDLVG),
+			},
+			want: nil,
+		},
 		{
 			name: "Should respect HDD AC for ANY SC",
 			args: args{
@@ -0,0 +1,61 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityplanner
+
+import (
+	"fmt"
+
+	genV1 "github.com/dell/csi-baremetal/api/generated/v1"
+	v1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+)
+
+// ValidatePinnedDrive returns error if volume can't be placed on the drive which it is pinned to
+func ValidatePinnedDrive(drive *genV1.Drive, volume *genV1.Volume) error {
+	switch {
+	case drive.IsSystem:
+		return fmt.Errorf("drive %s is a system drive", drive.UUID)
+	case drive.Health != v1.HealthGood:
+		return fmt.Errorf("drive %s has health %s", drive.UUID, drive.Health)
+	case drive.Status != v1.DriveStatusOnline:
+		return fmt.Errorf("drive %s has status %s", drive.UUID, drive.Status)
+	case drive.Usage != v1.DriveUsageInUse:
+		return fmt.Errorf("drive %s has usage %s", drive.UUID, drive.Usage)
+	case drive.Size < volume.Size:
+		return fmt.Errorf("drive %s of size %d is smaller than volume of size %d", drive.UUID, drive.Size, volume.Size)
+	}
+
+	driveSC := util.ConvertDriveTypeToStorageClass(drive.Type)
+	if volume.StorageClass != v1.StorageClassAny && volume.StorageClass != driveSC &&
+		util.GetSubStorageClass(volume.StorageClass) != driveSC {
+		return fmt.Errorf("drive %s of type %s doesn't match storage class %s", drive.UUID, drive.Type,
+			volume.StorageClass)
+	}
+	return nil
+}
+
+// PinnedLocation returns location of AC which volume pinned to the drive must use:
+// name of LVG if the drive is in LVG, drive UUID otherwise
+func PinnedLocation(driveUUID string, lvgs []lvgcrd.LogicalVolumeGroup) string {
+	for _, lvg := range lvgs {
+		if util.ContainsString(lvg.Spec.Locations, driveUUID) {
+			return lvg.Name
+		}
+	}
+	return driveUUID
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityplanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	genV1 "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+)
+
+func TestValidatePinnedDrive(t *testing.T) {
+	drive := &genV1.Drive{UUID: "drive-1", Type: apiV1.DriveTypeHDD, Size: testLargeSize, Health: apiV1.HealthGood,
+		Status: apiV1.DriveStatusOnline, Usage: apiV1.DriveUsageInUse}
+	volume := &genV1.Volume{Size: testSmallSize, StorageClass: apiV1.StorageClassHDD}
+	assert.Nil(t, ValidatePinnedDrive(drive, volume))
+
+	for _, sc := range []string{apiV1.StorageClassAny, apiV1.StorageClassHDDLVG} {
+		volume.StorageClass = sc
+		assert.Nil(t, ValidatePinnedDrive(drive, volume))
+	}
+
+	volume.StorageClass = apiV1.StorageClassSSD
+	assert.NotNil(t, ValidatePinnedDrive(drive, volume))
+	volume.StorageClass = apiV1.StorageClassHDD
+
+	for _, modify := range []func(d *genV1.Drive){
+		func(d *genV1.Drive) { d.Health = apiV1.HealthBad },
+		func(d *genV1.Drive) { d.Status = apiV1.DriveStatusOffline },
+		func(d *genV1.Drive) { d.Usage = apiV1.DriveUsageReleased },
+		func(d *genV1.Drive) { d.IsSystem = true },
+		func(d *genV1.Drive) { d.Size = testSmallSize - 1 },
+	} {
+		invalid := *drive
+		modify(&invalid)
+		assert.NotNil(t, ValidatePinnedDrive(&invalid, volume))
+	}
+}
+
+func TestPinnedLocation(t *testing.T) {
+	lvgs := []lvgcrd.LogicalVolumeGroup{{
+		ObjectMeta: k8smetav1.ObjectMeta{Name: "lvg-1"},
+		Spec:       genV1.LogicalVolumeGroup{Locations: []string{"drive-1", "drive-2"}},
+	}}
+	assert.Equal(t, "lvg-1", PinnedLocation("drive-2", lvgs))
+	assert.Equal(t, "drive-3", PinnedLocation("drive-3", lvgs))
+}
@@ -22,8 +22,11 @@ import (
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
 
+	v1 "github.com/dell/csi-baremetal/api/v1"
 	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
 	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/poolcrd"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 )
@@ -43,6 +46,14 @@ type ACReader struct {
 	logger *logrus.Entry
 	cached bool
 	cache  []accrd.AvailableCapacity
+	// if set, ACs of the node are read, other ACs might be returned if server-side filtering is disabled
+	nodeID string
+}
+
+// WithNodeID makes ACReader read ACs of provided node
+func (acr *ACReader) WithNodeID(nodeID string) *ACReader {
+	acr.nodeID = nodeID
+	return acr
 }
 
 // ReadCapacity returns AC list which was read from kubernetes API or from cache
@@ -52,8 +63,16 @@ func (acr *ACReader) ReadCapacity(ctx context.Context) ([]accrd.AvailableCapacit
 		logger.Tracef("Read AvailableCapacity from cache: %+v", acr.cache)
 		return acr.cache, nil
 	}
-	acList := &accrd.AvailableCapacityList{}
-	if err := acr.client.ReadList(ctx, acList); err != nil {
+	var (
+		acList = &accrd.AvailableCapacityList{}
+		err    error
+	)
+	if acr.nodeID != "" {
+		err = acr.client.ReadListByIndex(ctx, acList, k8s.NodeIDIndex, acr.nodeID)
+	} else {
+		err = acr.client.ReadList(ctx, acList)
+	}
+	if err != nil {
 		logger.Errorf("failed to read AC list: %s", err.Error())
 		return nil, err
 	}
@@ -169,3 +188,129 @@ func (rar *UnreservedACReader) ReadCapacity(ctx context.Context) ([]accrd.Availa
 	logger.Tracef("Read AvailableCapacity: %+v", reservedAC)
 	return reservedAC, nil
 }
+
+// NewDriveReservationACReader returns instance of DriveReservationACReader
+// Receives name of DaemonSet drive reservation of the pod, empty if pod doesn't belong to reservation
+func NewDriveReservationACReader(logger *logrus.Entry, capReader CapacityReader,
+	reservation string) *DriveReservationACReader {
+	return &DriveReservationACReader{
+		capReader:   capReader,
+		reservation: reservation,
+		logger:      logger,
+	}
+}
+
+// DriveReservationACReader capReader which hides ACs of drives held for other DaemonSet drive reservations
+type DriveReservationACReader struct {
+	capReader   CapacityReader
+	reservation string
+	logger      *logrus.Entry
+}
+
+// ReadCapacity returns ACs which aren't held or held for the reservation
+func (drr *DriveReservationACReader) ReadCapacity(ctx context.Context) ([]accrd.AvailableCapacity, error) {
+	logger := util.AddCommonFields(ctx, drr.logger, "DriveReservationACReader.ReadCapacity")
+
+	acList, err := drr.capReader.ReadCapacity(ctx)
+	if err != nil {
+		logger.Errorf("failed to read AC list: %s", err.Error())
+		return nil, err
+	}
+
+	return FilterACList(acList, func(ac accrd.AvailableCapacity) bool {
+		reservedFor := ac.GetAnnotations()[v1.DriveAnnotationReservedFor]
+		return reservedFor == "" || reservedFor == drr.reservation
+	}), nil
+}
+
+// NewPoolACReader returns instance of PoolACReader
+// Receives namespace and names of k8s StorageClasses of volumes which capacity is read for
+func NewPoolACReader(logger *logrus.Entry, capReader CapacityReader, client *k8s.KubeClient,
+	namespace string, storageClasses []string) *PoolACReader {
+	return &PoolACReader{
+		capReader:      capReader,
+		client:         client,
+		namespace:      namespace,
+		storageClasses: storageClasses,
+		logger:         logger,
+	}
+}
+
+// PoolACReader capReader which hides ACs of drives in pools which aren't bound to namespace and StorageClasses
+type PoolACReader struct {
+	capReader      CapacityReader
+	client         *k8s.KubeClient
+	namespace      string
+	storageClasses []string
+	logger         *logrus.Entry
+}
+
+// ReadCapacity returns ACs of drives which aren't in pools or in pools which volumes may use
+func (pr *PoolACReader) ReadCapacity(ctx context.Context) ([]accrd.AvailableCapacity, error) {
+	logger := util.AddCommonFields(ctx, pr.logger, "PoolACReader.ReadCapacity")
+
+	acList, err := pr.capReader.ReadCapacity(ctx)
+	if err != nil {
+		logger.Errorf("failed to read AC list: %s", err.Error())
+		return nil, err
+	}
+
+	poolList := &poolcrd.PoolList{}
+	if err := pr.client.ReadList(ctx, poolList); err != nil {
+		logger.Errorf("failed to read Pool list: %s", err.Error())
+		return nil, err
+	}
+	if len(poolList.Items) == 0 {
+		return acList, nil
+	}
+
+	// location of AC is drive UUID or LVG name, LVG is allowed if one of its drives is allowed
+	locationPools := map[string][]*poolcrd.Pool{}
+	for i := range poolList.Items {
+		for _, location := range poolList.Items[i].Spec.Locations {
+			locationPools[location] = append(locationPools[location], &poolList.Items[i])
+		}
+	}
+	lvgList := &lvgcrd.LogicalVolumeGroupList{}
+	if err := pr.client.ReadList(ctx, lvgList); err != nil {
+		logger.Errorf("failed to read LVG list: %s", err.Error())
+		return nil, err
+	}
+	for _, lvg := range lvgList.Items {
+		for _, location := range lvg.Spec.Locations {
+			if pools, ok := locationPools[location]; ok {
+				locationPools[lvg.Name] = append(locationPools[lvg.Name], pools...)
+			}
+		}
+	}
+
+	return FilterACList(acList, func(ac accrd.AvailableCapacity) bool {
+		pools, ok := locationPools[ac.Spec.Location]
+		if !ok {
+			return true
+		}
+		for _, pool := range pools {
+			if pr.isBound(pool) {
+				return true
+			}
+		}
+		logger.Tracef("AC %s is hidden by pools", ac.Name)
+		return false
+	}), nil
+}
+
+// isBound returns true if volumes of namespace and StorageClasses may use drives of the pool
+func (pr *PoolACReader) isBound(pool *poolcrd.Pool) bool {
+	if len(pool.Spec.Namespaces) > 0 && !util.ContainsString(pool.Spec.Namespaces, pr.namespace) {
+		return false
+	}
+	if len(pool.Spec.StorageClasses) == 0 {
+		return true
+	}
+	for _, sc := range pr.storageClasses {
+		if !util.ContainsString(pool.Spec.StorageClasses, sc) {
+			return false
+		}
+	}
+	return true
+}
@@ -21,10 +21,14 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	genV1 "github.com/dell/csi-baremetal/api/generated/v1"
 	apiV1 "github.com/dell/csi-baremetal/api/v1"
 	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
 	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/poolcrd"
 )
 
 func TestACReader(t *testing.T) {
@@ -83,3 +87,71 @@ func TestACRReader(t *testing.T) {
 	assert.Len(t, resp, 1)
 	assert.Equal(t, *testACs[2], resp[0])
 }*/
+
+func TestDriveReservationACReader(t *testing.T) {
+	ctx := context.Background()
+	logger := testLogger.WithField("component", "test")
+	client := getKubeClient(t)
+	held := getTestAC(testNode1, testLargeSize, apiV1.StorageClassHDD)
+	held.Annotations = map[string]string{apiV1.DriveAnnotationReservedFor: "logs"}
+	testACs := []*accrd.AvailableCapacity{
+		getTestAC(testNode1, testSmallSize, apiV1.StorageClassHDD),
+		held,
+	}
+	createACsInAPi(t, client, testACs)
+
+	// pod without reservation doesn't see held AC
+	resp, err := NewDriveReservationACReader(logger, NewACReader(client, logger, false), "").ReadCapacity(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, resp, 1)
+	assert.Equal(t, testSmallSize, resp[0].Spec.Size)
+
+	resp, err = NewDriveReservationACReader(logger, NewACReader(client, logger, false), "logs").ReadCapacity(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, resp, 2)
+}
+
+func TestPoolACReader(t *testing.T) {
+	ctx := context.Background()
+	logger := testLogger.WithField("component", "test")
+	client := getKubeClient(t)
+	shared := getTestAC(testNode1, testSmallSize, apiV1.StorageClassHDD)
+	shared.Spec.Location = "shared-drive"
+	pooled := getTestAC(testNode1, testLargeSize, apiV1.StorageClassSSD)
+	pooled.Spec.Location = "team-a-drive"
+	pooledLVG := getTestAC(testNode1, testLargeSize, apiV1.StorageClassSSDLVG)
+	pooledLVG.Spec.Location = "team-a-lvg"
+	createACsInAPi(t, client, []*accrd.AvailableCapacity{shared, pooled, pooledLVG})
+	lvg := &lvgcrd.LogicalVolumeGroup{ObjectMeta: k8smetav1.ObjectMeta{Name: "team-a-lvg"},
+		Spec: genV1.LogicalVolumeGroup{Name: "team-a-lvg", Locations: []string{"team-a-drive"}}}
+	assert.Nil(t, client.CreateCR(ctx, lvg.Name, lvg))
+
+	// no pools
+	resp, err := NewPoolACReader(logger, NewACReader(client, logger, false), client,
+		"team-b", []string{"ssd"}).ReadCapacity(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, resp, 3)
+
+	pool := &poolcrd.Pool{ObjectMeta: k8smetav1.ObjectMeta{Name: "team-a"},
+		Spec: genV1.Pool{Locations: []string{"team-a-drive"}, Namespaces: []string{"team-a"},
+			StorageClasses: []string{"team-a-ssd"}}}
+	assert.Nil(t, client.CreateCR(ctx, pool.Name, pool))
+
+	// another namespace
+	resp, err = NewPoolACReader(logger, NewACReader(client, logger, false), client,
+		"team-b", []string{"team-a-ssd"}).ReadCapacity(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, resp, 1)
+	assert.Equal(t, shared.Name, resp[0].Name)
+
+	// StorageClass isn't bound
+	resp, err = NewPoolACReader(logger, NewACReader(client, logger, false), client,
+		"team-a", []string{"team-a-ssd", "hdd"}).ReadCapacity(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, resp, 1)
+
+	resp, err = NewPoolACReader(logger, NewACReader(client, logger, false), client,
+		"team-a", []string{"team-a-ssd"}).ReadCapacity(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, resp, 3)
+}
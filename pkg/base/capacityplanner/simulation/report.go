@@ -0,0 +1,153 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PlacementDiff is a difference of placements of capacity request on node
+type PlacementDiff struct {
+	Reservation string
+	// Node and Request are empty if planner failed for reservation, Baseline and Candidate hold errors then
+	Node    string
+	Request string
+	// Baseline and Candidate describe selected AvailableCapacities, empty if capacity isn't found
+	Baseline  string
+	Candidate string
+}
+
+// String is pretty print function for PlacementDiff
+func (d PlacementDiff) String() string {
+	orNone := func(value string) string {
+		if value == "" {
+			return "<none>"
+		}
+		return value
+	}
+	if d.Node == "" {
+		return fmt.Sprintf("%s: error %s -> %s", d.Reservation, orNone(d.Baseline), orNone(d.Candidate))
+	}
+	return fmt.Sprintf("%s: node %s, request %s: %s -> %s", d.Reservation, d.Node, d.Request,
+		orNone(d.Baseline), orNone(d.Candidate))
+}
+
+// Report holds differences of placements of two planners for the same cluster state
+type Report struct {
+	// Reservations is an amount of compared reservations
+	Reservations int
+	// Changed holds names of reservations which are placed differently
+	Changed []string
+	Diffs   []PlacementDiff
+}
+
+// Empty returns true if placements are the same
+func (r *Report) Empty() bool {
+	return len(r.Diffs) == 0
+}
+
+// String is pretty print function for Report
+func (r *Report) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%d of %d reservations are placed differently", len(r.Changed), r.Reservations)
+	for _, diff := range r.Diffs {
+		fmt.Fprintf(b, "\n  %s", diff)
+	}
+	return b.String()
+}
+
+// Compare finds differences of placements of the same reservations, reservations which are missing in one
+// of placements are compared with empty placement
+func Compare(baseline, candidate []*Placement) *Report {
+	var (
+		report     = &Report{}
+		baselines  = placementsByReservation(baseline)
+		candidates = placementsByReservation(candidate)
+		names      = make([]string, 0, len(baselines))
+	)
+	for name := range baselines {
+		names = append(names, name)
+	}
+	for name := range candidates {
+		if _, ok := baselines[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		diffs := comparePlacement(name, baselines[name], candidates[name])
+		report.Reservations++
+		if len(diffs) == 0 {
+			continue
+		}
+		report.Changed = append(report.Changed, name)
+		report.Diffs = append(report.Diffs, diffs...)
+	}
+	return report
+}
+
+// comparePlacement finds differences of placements of reservation with name, placement might be nil
+func comparePlacement(name string, baseline, candidate *Placement) []PlacementDiff {
+	if baseline == nil {
+		baseline = &Placement{}
+	}
+	if candidate == nil {
+		candidate = &Placement{}
+	}
+
+	var diffs []PlacementDiff
+	if baseline.Error != candidate.Error {
+		diffs = append(diffs, PlacementDiff{Reservation: name, Baseline: baseline.Error, Candidate: candidate.Error})
+	}
+
+	type key struct{ node, request string }
+	keys := make(map[key]struct{})
+	for _, p := range []*Placement{baseline, candidate} {
+		for node, targets := range p.Nodes {
+			for request := range targets {
+				keys[key{node, request}] = struct{}{}
+			}
+		}
+	}
+	for k := range keys {
+		b, c := baseline.Nodes[k.node][k.request], candidate.Nodes[k.node][k.request]
+		if b.AC == c.AC {
+			continue
+		}
+		diffs = append(diffs, PlacementDiff{Reservation: name, Node: k.node, Request: k.request,
+			Baseline: b.String(), Candidate: c.String()})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Node != diffs[j].Node {
+			return diffs[i].Node < diffs[j].Node
+		}
+		return diffs[i].Request < diffs[j].Request
+	})
+	return diffs
+}
+
+// placementsByReservation returns mapping of reservation name to its placement
+func placementsByReservation(placements []*Placement) map[string]*Placement {
+	result := make(map[string]*Placement, len(placements))
+	for _, p := range placements {
+		result[p.Reservation] = p
+	}
+	return result
+}
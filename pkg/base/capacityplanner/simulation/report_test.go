@@ -0,0 +1,65 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	var (
+		baseline = []*Placement{
+			{Reservation: "acr-1", Nodes: map[string]map[string]Target{
+				"node-1": {"pvc-1": {AC: "ac-1", Drive: "SN-1"}},
+				"node-2": {"pvc-1": {AC: "ac-2"}},
+			}},
+			{Reservation: "acr-2", Error: "error"},
+			{Reservation: "acr-3"},
+		}
+		candidate = []*Placement{
+			{Reservation: "acr-1", Nodes: map[string]map[string]Target{
+				"node-1": {"pvc-1": {AC: "ac-3", Drive: "SN-3"}},
+			}},
+			{Reservation: "acr-2", Error: "error"},
+			{Reservation: "acr-4"},
+		}
+	)
+
+	report := Compare(baseline, baseline)
+	assert.True(t, report.Empty())
+	assert.Equal(t, 3, report.Reservations)
+
+	report = Compare(baseline, candidate)
+	assert.False(t, report.Empty())
+	assert.Equal(t, 4, report.Reservations)
+	assert.Equal(t, []string{"acr-1"}, report.Changed)
+	assert.Equal(t, []PlacementDiff{
+		{Reservation: "acr-1", Node: "node-1", Request: "pvc-1", Baseline: "ac-1 (drive SN-1)",
+			Candidate: "ac-3 (drive SN-3)"},
+		{Reservation: "acr-1", Node: "node-2", Request: "pvc-1", Baseline: "ac-2"},
+	}, report.Diffs)
+	assert.Equal(t, "1 of 4 reservations are placed differently\n"+
+		"  acr-1: node node-1, request pvc-1: ac-1 (drive SN-1) -> ac-3 (drive SN-3)\n"+
+		"  acr-1: node node-2, request pvc-1: ac-2 -> <none>", report.String())
+
+	// planner fails
+	report = Compare(baseline, []*Placement{{Reservation: "acr-3", Error: "error"}})
+	assert.Equal(t, []string{"acr-1", "acr-2", "acr-3"}, report.Changed)
+	assert.Equal(t, "acr-3: error <none> -> error", report.Diffs[len(report.Diffs)-1].String())
+}
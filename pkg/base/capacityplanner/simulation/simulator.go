@@ -0,0 +1,209 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	genV1 "github.com/dell/csi-baremetal/api/generated/v1"
+	v1 "github.com/dell/csi-baremetal/api/v1"
+	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
+)
+
+// Target is an AvailableCapacity which is selected for capacity request
+type Target struct {
+	AC string `json:"ac"`
+	// Drive is a serial number of drive of AC, empty for AC of LogicalVolumeGroup or unknown drive
+	Drive string `json:"drive,omitempty"`
+}
+
+// String is pretty print function for Target, empty target is printed as empty string
+func (t Target) String() string {
+	if t.Drive == "" {
+		return t.AC
+	}
+	return fmt.Sprintf("%s (drive %s)", t.AC, t.Drive)
+}
+
+// Placement is a placement of capacity requests of reservation on nodes
+type Placement struct {
+	Reservation string `json:"reservation"`
+	// Nodes holds nodes which have capacity for all requests: node ID -> capacity request name -> target
+	Nodes map[string]map[string]Target `json:"nodes,omitempty"`
+	// Error is an error of planner
+	Error string `json:"error,omitempty"`
+}
+
+// Simulator replays reservations of recorded cluster states against capacity planner
+type Simulator struct {
+	builder capacityplanner.CapacityManagerBuilder
+	logger  *logrus.Entry
+}
+
+// NewSimulator is a constructor for Simulator
+// Receives builder of planner which is validated, e.g. capacityplanner.DefaultCapacityManagerBuilder
+func NewSimulator(builder capacityplanner.CapacityManagerBuilder, logger *logrus.Entry) *Simulator {
+	return &Simulator{
+		builder: builder,
+		logger:  logger.WithField("component", "Simulator"),
+	}
+}
+
+// Replay plans placing of capacity requests of each reservation of state as if reservation was requested again:
+// other reservations hold their capacity, capacity held by replayed reservation is free.
+// Reservations are replayed independently, so result doesn't depend on order of reservations
+// Returns placements sorted by name of reservation
+func (s *Simulator) Replay(ctx context.Context, state *ClusterState) []*Placement {
+	var (
+		drives     = state.driveSerialNumbers()
+		placements = make([]*Placement, 0, len(state.Reservations))
+	)
+	for i := range state.Reservations {
+		placements = append(placements, s.replayReservation(ctx, state, i, drives))
+	}
+	sortPlacements(placements)
+	return placements
+}
+
+// replayReservation plans placing of capacity requests of reservation with index in state
+func (s *Simulator) replayReservation(ctx context.Context, state *ClusterState, index int,
+	drives map[string]string) *Placement {
+	var (
+		reservation = state.Reservations[index]
+		placement   = &Placement{Reservation: reservation.Name}
+		others      = make([]acrcrd.AvailableCapacityReservation, 0, len(state.Reservations))
+		volumes     = make([]*genV1.Volume, 0, len(reservation.Spec.ReservationRequests))
+		nodes       []string
+	)
+	for i := range state.Reservations {
+		if i != index {
+			others = append(others, state.Reservations[i])
+		}
+	}
+	for _, request := range reservation.Spec.ReservationRequests {
+		capacity := request.CapacityRequest
+		volumes = append(volumes, &genV1.Volume{Id: capacity.Name, Size: capacity.Size,
+			StorageClass: capacity.StorageClass})
+	}
+	if reservation.Spec.NodeRequests != nil {
+		nodes = reservation.Spec.NodeRequests.Requested
+	}
+
+	planner := s.builder.GetCapacityManager(s.logger.WithField("reservation", reservation.Name),
+		&capacityReader{acs: state.AvailableCapacities}, &reservationReader{acrs: others})
+	plan, err := planner.PlanVolumesPlacing(ctx, volumes, nodes)
+	if err != nil {
+		placement.Error = err.Error()
+		return placement
+	}
+	if plan == nil {
+		return placement
+	}
+	for _, node := range nodes {
+		mapping := plan.GetVolumesToACMapping(node)
+		if mapping == nil {
+			continue
+		}
+		if placement.Nodes == nil {
+			placement.Nodes = make(map[string]map[string]Target)
+		}
+		placement.Nodes[node] = make(map[string]Target, len(mapping))
+		for volume, ac := range mapping {
+			placement.Nodes[node][volume.Id] = Target{AC: ac.Name, Drive: drives[ac.Spec.Location]}
+		}
+	}
+	return placement
+}
+
+// Recorded returns placements of confirmed reservations of state which were made by planner of the cluster
+func Recorded(state *ClusterState) []*Placement {
+	var (
+		drives     = state.driveSerialNumbers()
+		acs        = make(map[string]*accrd.AvailableCapacity, len(state.AvailableCapacities))
+		placements = make([]*Placement, 0, len(state.Reservations))
+	)
+	for i := range state.AvailableCapacities {
+		acs[state.AvailableCapacities[i].Name] = &state.AvailableCapacities[i]
+	}
+	for _, reservation := range state.Reservations {
+		if reservation.Spec.Status != v1.ReservationConfirmed {
+			continue
+		}
+		placement := &Placement{Reservation: reservation.Name}
+		for _, request := range reservation.Spec.ReservationRequests {
+			for _, name := range request.Reservations {
+				// AC might be removed after reservation, its node is unknown
+				ac, ok := acs[name]
+				if !ok {
+					continue
+				}
+				if placement.Nodes == nil {
+					placement.Nodes = make(map[string]map[string]Target)
+				}
+				if placement.Nodes[ac.Spec.NodeId] == nil {
+					placement.Nodes[ac.Spec.NodeId] = make(map[string]Target)
+				}
+				placement.Nodes[ac.Spec.NodeId][request.CapacityRequest.Name] = Target{AC: name,
+					Drive: drives[ac.Spec.Location]}
+			}
+		}
+		placements = append(placements, placement)
+	}
+	sortPlacements(placements)
+	return placements
+}
+
+// sortPlacements sorts placements by name of reservation
+func sortPlacements(placements []*Placement) {
+	sort.Slice(placements, func(i, j int) bool {
+		return placements[i].Reservation < placements[j].Reservation
+	})
+}
+
+// capacityReader returns copy of recorded AvailableCapacities, planner might change them
+type capacityReader struct {
+	acs []accrd.AvailableCapacity
+}
+
+// ReadCapacity returns copy of recorded AvailableCapacities
+func (r *capacityReader) ReadCapacity(context.Context) ([]accrd.AvailableCapacity, error) {
+	result := make([]accrd.AvailableCapacity, len(r.acs))
+	for i := range r.acs {
+		r.acs[i].DeepCopyInto(&result[i])
+	}
+	return result, nil
+}
+
+// reservationReader returns copy of recorded AvailableCapacityReservations, planner might change them
+type reservationReader struct {
+	acrs []acrcrd.AvailableCapacityReservation
+}
+
+// ReadReservations returns copy of recorded AvailableCapacityReservations
+func (r *reservationReader) ReadReservations(context.Context) ([]acrcrd.AvailableCapacityReservation, error) {
+	result := make([]acrcrd.AvailableCapacityReservation, len(r.acrs))
+	for i := range r.acrs {
+		r.acrs[i].DeepCopyInto(&result[i])
+	}
+	return result, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
+)
+
+// statesEnv holds directory with cluster states recorded outside of repository
+const statesEnv = "CAPACITY_SIMULATION_STATES"
+
+var update = flag.Bool("update", false, "write placements of current planner as expected placements of states")
+
+var testLogger = logrus.NewEntry(logrus.New())
+
+// TestRecordedStates replays cluster states from testdata and from directory in CAPACITY_SIMULATION_STATES
+// and fails if placements differ from expected ones. Expected placements are written with -update flag
+func TestRecordedStates(t *testing.T) {
+	dirs := []string{"testdata"}
+	if dir := os.Getenv(statesEnv); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	simulator := NewSimulator(&capacityplanner.DefaultCapacityManagerBuilder{}, testLogger)
+
+	for _, dir := range dirs {
+		states, err := LoadClusterStates(dir)
+		assert.Nil(t, err)
+		for _, state := range states {
+			state := state
+			path := filepath.Join(dir, state.Name, PlacementsFile)
+			t.Run(state.Name, func(t *testing.T) {
+				placements := simulator.Replay(context.Background(), state)
+				t.Logf("Placements of planner compared with placements recorded in cluster: %s",
+					Compare(Recorded(state), placements))
+				if *update {
+					assert.Nil(t, SavePlacements(path, placements))
+					return
+				}
+				expected, err := LoadPlacements(path)
+				if os.IsNotExist(err) {
+					t.Skipf("%s isn't found, run test with -update flag to create it", path)
+				}
+				assert.Nil(t, err)
+				if report := Compare(expected, placements); !report.Empty() {
+					t.Errorf("Placements are changed: %s", report)
+				}
+			})
+		}
+	}
+}
+
+func TestSimulator_Replay(t *testing.T) {
+	state, err := LoadClusterState(filepath.Join("testdata", "sample-cluster"))
+	assert.Nil(t, err)
+	assert.Len(t, state.AvailableCapacities, 5)
+	assert.Len(t, state.Reservations, 2)
+	assert.Len(t, state.Drives, 5)
+
+	placements := NewSimulator(&capacityplanner.DefaultCapacityManagerBuilder{}, testLogger).
+		Replay(context.Background(), state)
+	// capacity of the first reservation is free during its replay, the second one doesn't fit on node-2
+	assert.Equal(t, []*Placement{
+		{Reservation: "default-app-0", Nodes: map[string]map[string]Target{
+			"node-1": {"data-app-0": {AC: "ac-1", Drive: "SN-1"}},
+			"node-2": {"data-app-0": {AC: "ac-4", Drive: "SN-4"}},
+		}},
+		{Reservation: "default-db-0", Nodes: map[string]map[string]Target{
+			"node-1": {"data-db-0": {AC: "ac-2", Drive: "SN-2"}, "wal-db-0": {AC: "ac-3", Drive: "SN-3"}},
+		}},
+	}, placements)
+	// recorded state isn't changed by planner
+	assert.Equal(t, placements, NewSimulator(&capacityplanner.DefaultCapacityManagerBuilder{}, testLogger).
+		Replay(context.Background(), state))
+
+	// only confirmed reservation has recorded placement
+	assert.Equal(t, placements[:1], Recorded(state))
+
+	// planner error is recorded in placement
+	planner := &capacityplanner.PlannerMock{}
+	planner.On("PlanVolumesPlacing", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+	placements = NewSimulator(&capacityplanner.MockCapacityManagerBuilder{Manager: planner}, testLogger).
+		Replay(context.Background(), state)
+	assert.Len(t, placements, 2)
+	assert.Equal(t, assert.AnError.Error(), placements[0].Error)
+	assert.Empty(t, placements[0].Nodes)
+}
+
+func TestLoadClusterStates(t *testing.T) {
+	states, err := LoadClusterStates("testdata")
+	assert.Nil(t, err)
+	assert.Len(t, states, 1)
+	assert.Equal(t, "sample-cluster", states[0].Name)
+
+	_, err = LoadClusterState(t.TempDir())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulation contains test kit which replays cluster states recorded from real clusters against
+// capacity planner and reports differences of placements, so changes of planner are validated on production data
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+)
+
+// Files of recorded cluster state, each file is an output of kubectl get -o json
+const (
+	// ACFile holds list of AvailableCapacities: kubectl get ac -o json
+	ACFile = "availablecapacities.json"
+	// ACRFile holds list of AvailableCapacityReservations: kubectl get acr -o json
+	ACRFile = "reservations.json"
+	// DriveFile holds list of Drives: kubectl get drives -o json, file is optional
+	DriveFile = "drives.json"
+	// PlacementsFile holds placements of reservations which are expected from planner for the state
+	PlacementsFile = "placements.json"
+)
+
+// ClusterState is a state of capacity of cluster recorded at some moment
+type ClusterState struct {
+	// Name is a name of directory with recorded state
+	Name                string
+	AvailableCapacities []accrd.AvailableCapacity
+	Reservations        []acrcrd.AvailableCapacityReservation
+	Drives              []drivecrd.Drive
+}
+
+// LoadClusterState reads cluster state recorded in directory dir
+func LoadClusterState(dir string) (*ClusterState, error) {
+	var (
+		acList    = &accrd.AvailableCapacityList{}
+		acrList   = &acrcrd.AvailableCapacityReservationList{}
+		driveList = &drivecrd.DriveList{}
+	)
+	if err := readJSON(filepath.Join(dir, ACFile), acList); err != nil {
+		return nil, err
+	}
+	if err := readJSON(filepath.Join(dir, ACRFile), acrList); err != nil {
+		return nil, err
+	}
+	if err := readJSON(filepath.Join(dir, DriveFile), driveList); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return &ClusterState{
+		Name:                filepath.Base(dir),
+		AvailableCapacities: acList.Items,
+		Reservations:        acrList.Items,
+		Drives:              driveList.Items,
+	}, nil
+}
+
+// LoadClusterStates reads cluster states recorded in subdirectories of dir which contain ACFile
+// Returns states sorted by name
+func LoadClusterStates(dir string) ([]*ClusterState, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]*ClusterState, 0, len(entries))
+	for _, entry := range entries {
+		stateDir := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err = os.Stat(filepath.Join(stateDir, ACFile)); err != nil {
+			continue
+		}
+		state, err := LoadClusterState(stateDir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load cluster state %s: %w", stateDir, err)
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states, nil
+}
+
+// LoadPlacements reads placements from file which is written by SavePlacements
+func LoadPlacements(path string) ([]*Placement, error) {
+	var placements []*Placement
+	if err := readJSON(path, &placements); err != nil {
+		return nil, err
+	}
+	return placements, nil
+}
+
+// SavePlacements writes placements to file in JSON format
+func SavePlacements(path string, placements []*Placement) error {
+	data, err := json.MarshalIndent(placements, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// driveSerialNumbers returns mapping of drive UUID to its serial number
+func (s *ClusterState) driveSerialNumbers() map[string]string {
+	result := make(map[string]string, len(s.Drives))
+	for _, drive := range s.Drives {
+		result[drive.Spec.UUID] = drive.Spec.SerialNumber
+	}
+	return result
+}
+
+// readJSON decodes content of file in JSON format into value
+func readJSON(path string, value interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("unable to decode %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityplanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "github.com/dell/csi-baremetal/api/v1"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+)
+
+// Tiers of k8s StorageClasses, volumes of higher tier are allowed to use drives with lower latency
+const (
+	// TierPlatinum volumes may use drives of any media type
+	TierPlatinum = "platinum"
+	// TierGold volumes may use SSD and HDD drives
+	TierGold = "gold"
+	// TierBronze volumes may use HDD drives
+	TierBronze = "bronze"
+)
+
+// latency ranks of media types, the lowest latency first
+const (
+	latencyRankNVMe = iota
+	latencyRankSSD
+	latencyRankHDD
+)
+
+// tierMinLatencyRank is the lowest latency rank of drives which volumes of the tier may use without pressure
+var tierMinLatencyRank = map[string]int{
+	TierPlatinum: latencyRankNVMe,
+	TierGold:     latencyRankSSD,
+	TierBronze:   latencyRankHDD,
+}
+
+// StorageClassTiers holds tiers of k8s StorageClasses and pressure threshold after which drives with lower latency
+// are allowed for volumes of lower tiers
+type StorageClassTiers struct {
+	// key - name of k8s StorageClass, value - tier
+	Tiers map[string]string
+	// minimal free capacity of drives allowed for tier in percents, lower latency drives are used below it
+	PressureThreshold int64
+}
+
+// ParseStorageClassTiers parses tiers of k8s StorageClasses in format sc1=platinum,sc2=gold,sc3=bronze
+func ParseStorageClassTiers(str string) (map[string]string, error) {
+	tiers := make(map[string]string)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("storage class tier %s has wrong format, expected <storage class>=<tier>", item)
+		}
+		tier := strings.ToLower(strings.TrimSpace(parts[1]))
+		if _, ok := tierMinLatencyRank[tier]; !ok {
+			return nil, fmt.Errorf("storage class tier %s has wrong tier, expected %s, %s or %s",
+				item, TierPlatinum, TierGold, TierBronze)
+		}
+		tiers[strings.TrimSpace(parts[0])] = tier
+	}
+	return tiers, nil
+}
+
+// NewTierACReader returns instance of TierACReader
+// Receives names of k8s StorageClasses of volumes which capacity is read for
+func NewTierACReader(logger *logrus.Entry, capReader CapacityReader, client *k8s.KubeClient,
+	tiers *StorageClassTiers, storageClasses []string) *TierACReader {
+	return &TierACReader{
+		capReader:      capReader,
+		client:         client,
+		tiers:          tiers,
+		storageClasses: storageClasses,
+		logger:         logger,
+	}
+}
+
+// TierACReader capReader which hides ACs of drives with lower latency than tier of StorageClasses allows
+// until drives allowed for the tier on the node are under pressure
+type TierACReader struct {
+	capReader      CapacityReader
+	client         *k8s.KubeClient
+	tiers          *StorageClassTiers
+	storageClasses []string
+	logger         *logrus.Entry
+}
+
+// ReadCapacity returns ACs which volumes of tier may use
+func (tr *TierACReader) ReadCapacity(ctx context.Context) ([]accrd.AvailableCapacity, error) {
+	logger := util.AddCommonFields(ctx, tr.logger, "TierACReader.ReadCapacity")
+
+	acList, err := tr.capReader.ReadCapacity(ctx)
+	if err != nil {
+		logger.Errorf("failed to read AC list: %s", err.Error())
+		return nil, err
+	}
+
+	minRank := tr.minLatencyRank()
+	if minRank == latencyRankNVMe {
+		return acList, nil
+	}
+
+	driveList := &drivecrd.DriveList{}
+	if err := tr.client.ReadList(ctx, driveList); err != nil {
+		logger.Errorf("failed to read Drive list: %s", err.Error())
+		return nil, err
+	}
+	// capacity of drives allowed for the tier per node
+	total, free := map[string]int64{}, map[string]int64{}
+	for _, drive := range driveList.Items {
+		rank, ok := driveLatencyRank(drive.Spec.Type)
+		if ok && rank >= minRank && !drive.Spec.IsSystem && drive.Spec.Status == v1.DriveStatusOnline {
+			total[drive.Spec.NodeId] += drive.Spec.Size
+		}
+	}
+	for _, ac := range acList {
+		if rank, ok := acLatencyRank(ac.Spec.StorageClass); ok && rank >= minRank {
+			free[ac.Spec.NodeId] += ac.Spec.Size
+		}
+	}
+
+	return FilterACList(acList, func(ac accrd.AvailableCapacity) bool {
+		rank, ok := acLatencyRank(ac.Spec.StorageClass)
+		if !ok || rank >= minRank {
+			return true
+		}
+		node := ac.Spec.NodeId
+		if total[node] == 0 || free[node]*100 < total[node]*tr.tiers.PressureThreshold {
+			logger.Tracef("AC %s is allowed because of pressure on node %s", ac.Name, node)
+			return true
+		}
+		logger.Tracef("AC %s is kept for higher tiers", ac.Name)
+		return false
+	}), nil
+}
+
+// minLatencyRank returns the lowest latency rank of drives allowed for the volumes, the most permissive tier
+// is used if volumes have StorageClasses of different tiers. StorageClass without tier isn't restricted
+func (tr *TierACReader) minLatencyRank() int {
+	if len(tr.storageClasses) == 0 {
+		return latencyRankNVMe
+	}
+	minRank := latencyRankHDD
+	for _, sc := range tr.storageClasses {
+		tier, ok := tr.tiers.Tiers[sc]
+		if !ok {
+			return latencyRankNVMe
+		}
+		if rank := tierMinLatencyRank[tier]; rank < minRank {
+			minRank = rank
+		}
+	}
+	return minRank
+}
+
+// driveLatencyRank returns latency rank of drive media type
+func driveLatencyRank(driveType string) (int, bool) {
+	switch driveType {
+	case v1.DriveTypeNVMe:
+		return latencyRankNVMe, true
+	case v1.DriveTypeSSD:
+		return latencyRankSSD, true
+	case v1.DriveTypeHDD:
+		return latencyRankHDD, true
+	default:
+		return 0, false
+	}
+}
+
+// acLatencyRank returns latency rank of media type of AC, system LVG isn't ranked
+func acLatencyRank(storageClass string) (int, bool) {
+	switch storageClass {
+	case v1.StorageClassNVMe, v1.StorageClassNVMeLVG:
+		return latencyRankNVMe, true
+	case v1.StorageClassSSD, v1.StorageClassSSDLVG:
+		return latencyRankSSD, true
+	case v1.StorageClassHDD, v1.StorageClassHDDLVG:
+		return latencyRankHDD, true
+	default:
+		return 0, false
+	}
+}
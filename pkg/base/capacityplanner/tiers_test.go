@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityplanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	genV1 "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+)
+
+func TestParseStorageClassTiers(t *testing.T) {
+	tiers, err := ParseStorageClassTiers("fast=platinum, std=Gold,bulk=bronze,")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"fast": TierPlatinum, "std": TierGold, "bulk": TierBronze}, tiers)
+
+	_, err = ParseStorageClassTiers("fast")
+	assert.NotNil(t, err)
+	_, err = ParseStorageClassTiers("fast=silver")
+	assert.NotNil(t, err)
+}
+
+func TestTierACReader(t *testing.T) {
+	ctx := context.Background()
+	logger := testLogger.WithField("component", "test")
+	client := getKubeClient(t)
+	tiers := &StorageClassTiers{
+		Tiers:             map[string]string{"fast": TierPlatinum, "std": TierGold, "bulk": TierBronze},
+		PressureThreshold: 50,
+	}
+	nvme := getTestAC(testNode1, testLargeSize, apiV1.StorageClassNVMe)
+	ssd := getTestAC(testNode1, testLargeSize, apiV1.StorageClassSSD)
+	hdd := getTestAC(testNode1, testLargeSize, apiV1.StorageClassHDD)
+	createACsInAPi(t, client, []*accrd.AvailableCapacity{nvme, ssd, hdd})
+	for name, driveType := range map[string]string{"nvme": apiV1.DriveTypeNVMe, "ssd": apiV1.DriveTypeSSD,
+		"hdd": apiV1.DriveTypeHDD} {
+		drive := &drivecrd.Drive{ObjectMeta: k8smetav1.ObjectMeta{Name: name},
+			Spec: genV1.Drive{UUID: name, NodeId: testNode1, Type: driveType, Size: testLargeSize,
+				Status: apiV1.DriveStatusOnline}}
+		assert.Nil(t, client.CreateCR(ctx, drive.Name, drive))
+	}
+
+	read := func(storageClasses ...string) []accrd.AvailableCapacity {
+		resp, err := NewTierACReader(logger, NewACReader(client, logger, false), client, tiers,
+			storageClasses).ReadCapacity(ctx)
+		assert.Nil(t, err)
+		return resp
+	}
+
+	// platinum and StorageClass without tier aren't restricted
+	assert.Len(t, read("fast"), 3)
+	assert.Len(t, read("bulk", "other"), 3)
+	// the most permissive tier is used
+	assert.Len(t, read("std", "bulk"), 2)
+	resp := read("bulk")
+	assert.Len(t, resp, 1)
+	assert.Equal(t, hdd.Name, resp[0].Name)
+
+	// HDD drives are under pressure, bronze may use all drives
+	hdd.Spec.Size = testLargeSize / 4
+	assert.Nil(t, client.UpdateCR(ctx, hdd))
+	assert.Len(t, read("bulk"), 3)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package circuitbreaker contains circuit breaker which stops operations on a key (e.g. drive) after consecutive failures
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// Breaker tracks circuits of keys. Circuit is opened after threshold of consecutive failures, then operations
+// are rejected except probes which are allowed with exponentially growing intervals. Successful probe closes circuit
+type Breaker struct {
+	mu sync.Mutex
+	// count of consecutive failures after which circuit is opened
+	threshold int
+	// interval before the first probe, it is doubled after each failed probe up to maxProbeInterval
+	probeInterval    time.Duration
+	maxProbeInterval time.Duration
+	circuits         map[string]*circuit
+	now              func() time.Time
+}
+
+// circuit is a state of key
+type circuit struct {
+	failures  int
+	open      bool
+	interval  time.Duration
+	nextProbe time.Time
+}
+
+// New creates Breaker
+func New(threshold int, probeInterval, maxProbeInterval time.Duration) *Breaker {
+	if maxProbeInterval < probeInterval {
+		maxProbeInterval = probeInterval
+	}
+	return &Breaker{
+		threshold:        threshold,
+		probeInterval:    probeInterval,
+		maxProbeInterval: maxProbeInterval,
+		circuits:         make(map[string]*circuit),
+		now:              time.Now,
+	}
+}
+
+// Allow checks whether operation on key might be issued
+// Returns true if circuit is closed or probe is due, otherwise false and time until the next probe.
+// Only one probe is allowed per interval, the next one is allowed after the same interval if result isn't reported
+func (b *Breaker) Allow(key string) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok || !c.open {
+		return true, 0
+	}
+	now := b.now()
+	if now.Before(c.nextProbe) {
+		return false, c.nextProbe.Sub(now)
+	}
+	c.nextProbe = now.Add(c.interval)
+	return true, 0
+}
+
+// Success resets failures of key and closes its circuit
+// Returns true if circuit was open
+func (b *Breaker) Success(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return false
+	}
+	delete(b.circuits, key)
+	return c.open
+}
+
+// Failure counts failure of key, opens circuit when threshold is reached and doubles probe interval of open circuit
+// Returns true if circuit was opened by this failure
+func (b *Breaker) Failure(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	c.failures++
+	now := b.now()
+	if c.open {
+		c.interval *= 2
+		if c.interval > b.maxProbeInterval {
+			c.interval = b.maxProbeInterval
+		}
+		c.nextProbe = now.Add(c.interval)
+		return false
+	}
+	if c.failures < b.threshold {
+		return false
+	}
+	c.open = true
+	c.interval = b.probeInterval
+	c.nextProbe = now.Add(c.interval)
+	return true
+}
+
+// IsOpen checks whether circuit of key is open
+func (b *Breaker) IsOpen(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	return ok && c.open
+}
+
+// Failures returns count of consecutive failures of key
+func (b *Breaker) Failures(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[key]; ok {
+		return c.failures
+	}
+	return 0
+}
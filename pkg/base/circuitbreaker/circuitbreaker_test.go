@@ -0,0 +1,73 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := New(2, time.Minute, 3*time.Minute)
+	b.now = func() time.Time { return now }
+	key := "drive-1"
+
+	// closed
+	allowed, _ := b.Allow(key)
+	assert.True(t, allowed)
+	assert.False(t, b.Failure(key))
+	assert.False(t, b.IsOpen(key))
+
+	// opened after threshold
+	assert.True(t, b.Failure(key))
+	assert.True(t, b.IsOpen(key))
+	allowed, wait := b.Allow(key)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, wait)
+
+	// the only probe is allowed when it is due
+	now = now.Add(time.Minute)
+	allowed, _ = b.Allow(key)
+	assert.True(t, allowed)
+	allowed, _ = b.Allow(key)
+	assert.False(t, allowed)
+
+	// failed probes double interval up to max
+	assert.False(t, b.Failure(key))
+	_, wait = b.Allow(key)
+	assert.Equal(t, 2*time.Minute, wait)
+	assert.False(t, b.Failure(key))
+	_, wait = b.Allow(key)
+	assert.Equal(t, 3*time.Minute, wait)
+	assert.Equal(t, 4, b.Failures(key))
+
+	// successful probe closes circuit
+	now = now.Add(3 * time.Minute)
+	allowed, _ = b.Allow(key)
+	assert.True(t, allowed)
+	assert.True(t, b.Success(key))
+	assert.False(t, b.IsOpen(key))
+	assert.Equal(t, 0, b.Failures(key))
+	assert.False(t, b.Success(key))
+
+	// other keys aren't affected
+	allowed, _ = b.Allow("drive-2")
+	assert.True(t, allowed)
+}
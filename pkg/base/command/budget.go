@@ -0,0 +1,216 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of heavy operations which are limited by operation budget
+const (
+	// OperationMkfs creates filesystem of volume, it is a part of provisioning
+	OperationMkfs = "mkfs"
+	// OperationWipe wipes signatures or data of device, it is a part of provisioning and release
+	OperationWipe = "wipe"
+	// OperationPVMove moves extents of LVM physical volume, it is a background maintenance
+	OperationPVMove = "pvmove"
+	// OperationBenchmark tests drive performance or integrity (burn-in), it is a background maintenance
+	OperationBenchmark = "benchmark"
+
+	// DefaultOperationBudget is a total count of simultaneous heavy operations when it isn't configured
+	DefaultOperationBudget = 4
+)
+
+// backgroundOperations can't take the last slot of budget and wait while provisioning operations are waiting
+var backgroundOperations = map[string]bool{OperationPVMove: true, OperationBenchmark: true}
+
+// operationUtilities maps utility name to kind of operation, mkfs.<type> utilities are matched by prefix
+var operationUtilities = map[string]string{
+	"mke2fs":     OperationMkfs,
+	"wipefs":     OperationWipe,
+	"blkdiscard": OperationWipe,
+	"pvmove":     OperationPVMove,
+	"fio":        OperationBenchmark,
+	"badblocks":  OperationBenchmark,
+}
+
+var (
+	budgetMu sync.RWMutex
+	// budget limits heavy operations which are run by Executor, nil means unlimited
+	budget *OperationBudget
+)
+
+// OperationBudget limits simultaneous heavy operations of the node, so background maintenance
+// can't starve provisioning or saturate drive backplane
+type OperationBudget struct {
+	// max count of all heavy operations
+	total int
+	// max count of operations of each kind, 0 means that only total is applied
+	limits map[string]int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	inUse   int
+	running map[string]int
+	// count of provisioning operations which wait for budget
+	foregroundWaiting int
+}
+
+// NewOperationBudget creates OperationBudget
+// Receives total count of simultaneous heavy operations and limits of kinds
+func NewOperationBudget(total int, limits map[string]int) *OperationBudget {
+	b := &OperationBudget{
+		total:   total,
+		limits:  limits,
+		running: make(map[string]int),
+	}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// ParseOperationBudget parses budget in format "total=4,mkfs=2,wipe=2,pvmove=1,benchmark=1"
+// Omitted total is DefaultOperationBudget, omitted pvmove and benchmark are 1, omitted mkfs and wipe are limited by total only
+// Returns error if format is wrong or limits are invalid
+func ParseOperationBudget(str string) (*OperationBudget, error) {
+	total := DefaultOperationBudget
+	limits := map[string]int{OperationPVMove: 1, OperationBenchmark: 1}
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("operation budget %s has wrong format, expected <kind>=<count>", item)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("operation budget %s has wrong count, expected non-negative integer", item)
+		}
+		switch kind := strings.TrimSpace(parts[0]); kind {
+		case "total":
+			total = count
+		case OperationMkfs, OperationWipe, OperationPVMove, OperationBenchmark:
+			limits[kind] = count
+		default:
+			return nil, fmt.Errorf("operation %s isn't supported, expected total, %s, %s, %s or %s",
+				kind, OperationMkfs, OperationWipe, OperationPVMove, OperationBenchmark)
+		}
+	}
+	// the last slot is reserved for provisioning
+	if total < 2 {
+		return nil, fmt.Errorf("total operation budget must be at least 2, got %d", total)
+	}
+	for kind, limit := range limits {
+		if limit > total {
+			return nil, fmt.Errorf("budget of %s operations %d exceeds total budget %d", kind, limit, total)
+		}
+	}
+	return NewOperationBudget(total, limits), nil
+}
+
+// SetOperationBudget sets budget of heavy operations which are run by all Executors, nil disables the budget
+func SetOperationBudget(b *OperationBudget) {
+	budgetMu.Lock()
+	budget = b
+	budgetMu.Unlock()
+}
+
+// acquireBudget waits for slot of budget if command is a heavy operation
+// Returns function which releases the slot and wait duration
+func acquireBudget(args []string) (func(), time.Duration) {
+	budgetMu.RLock()
+	b := budget
+	budgetMu.RUnlock()
+	if b == nil {
+		return func() {}, 0
+	}
+	kind := operationKind(args)
+	if kind == "" {
+		return func() {}, 0
+	}
+	return b.Acquire(kind)
+}
+
+// operationKind returns kind of heavy operation of command or empty string for other commands,
+// commands which are run with timeout util are matched by wrapped utility
+func operationKind(args []string) string {
+	for i, arg := range args {
+		name := filepath.Base(arg)
+		if strings.HasPrefix(name, "mkfs") {
+			return OperationMkfs
+		}
+		if kind, ok := operationUtilities[name]; ok {
+			return kind
+		}
+		if name == "lvm" && i+1 < len(args) && args[i+1] == "pvmove" {
+			return OperationPVMove
+		}
+		if i == 0 && name != "timeout" {
+			break
+		}
+	}
+	return ""
+}
+
+// Acquire waits until operation of kind fits into budget
+// Returns function which releases the slot and wait duration
+func (b *OperationBudget) Acquire(kind string) (func(), time.Duration) {
+	start := time.Now()
+	background := backgroundOperations[kind]
+
+	b.mu.Lock()
+	if !background {
+		b.foregroundWaiting++
+	}
+	for !b.fits(kind, background) {
+		b.cond.Wait()
+	}
+	if !background {
+		b.foregroundWaiting--
+	}
+	b.inUse++
+	b.running[kind]++
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			b.inUse--
+			b.running[kind]--
+			b.mu.Unlock()
+			b.cond.Broadcast()
+		})
+	}, time.Since(start)
+}
+
+// fits checks whether operation of kind might be started, must be called with locked mu
+func (b *OperationBudget) fits(kind string, background bool) bool {
+	if limit := b.limits[kind]; limit > 0 && b.running[kind] >= limit {
+		return false
+	}
+	if background {
+		return b.foregroundWaiting == 0 && b.inUse < b.total-1
+	}
+	return b.inUse < b.total
+}
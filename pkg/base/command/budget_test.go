@@ -0,0 +1,100 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOperationBudget(t *testing.T) {
+	b, err := ParseOperationBudget("")
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultOperationBudget, b.total)
+	assert.Equal(t, map[string]int{OperationPVMove: 1, OperationBenchmark: 1}, b.limits)
+
+	b, err = ParseOperationBudget("total=6, mkfs=3,benchmark=2")
+	assert.Nil(t, err)
+	assert.Equal(t, 6, b.total)
+	assert.Equal(t, map[string]int{OperationMkfs: 3, OperationPVMove: 1, OperationBenchmark: 2}, b.limits)
+
+	for _, str := range []string{"total", "total=-1", "total=1", "mkfs=5", "dd=1", "wipe=x"} {
+		_, err = ParseOperationBudget(str)
+		assert.NotNil(t, err, str)
+	}
+}
+
+func TestOperationKind(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"mkfs.xfs", "/dev/sda1"}, OperationMkfs},
+		{[]string{"/usr/sbin/mke2fs", "/dev/sda1"}, OperationMkfs},
+		{[]string{"wipefs", "-af", "/dev/sda"}, OperationWipe},
+		{[]string{"/sbin/lvm", "pvmove", "/dev/sda"}, OperationPVMove},
+		{[]string{"timeout", "--signal=INT", "60", "badblocks", "-w", "/dev/sda"}, OperationBenchmark},
+		{[]string{"fio", "--name=burn-in"}, OperationBenchmark},
+		{[]string{"/sbin/lvm", "lvcreate", "--yes"}, ""},
+		{[]string{"lsblk", "/dev/mkfs"}, ""},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, operationKind(tc.args), tc.args)
+	}
+}
+
+func TestOperationBudget_Acquire(t *testing.T) {
+	b := NewOperationBudget(2, map[string]int{OperationBenchmark: 1})
+
+	// background operation can't take the last slot
+	releaseBenchmark, _ := b.Acquire(OperationBenchmark)
+	acquired := make(chan func(), 1)
+	go func() {
+		release, _ := b.Acquire(OperationPVMove)
+		acquired <- release
+	}()
+	releaseMkfs, _ := b.Acquire(OperationMkfs)
+	select {
+	case <-acquired:
+		assert.Fail(t, "background operation took the last slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// provisioning waits for budget and has priority over background operation
+	go func() {
+		release, _ := b.Acquire(OperationWipe)
+		acquired <- release
+	}()
+	time.Sleep(50 * time.Millisecond)
+	releaseMkfs()
+	releaseMkfs()
+	releaseWipe := <-acquired
+	b.mu.Lock()
+	assert.Equal(t, 1, b.running[OperationWipe])
+	assert.Equal(t, 0, b.running[OperationPVMove])
+	b.mu.Unlock()
+
+	releaseWipe()
+	releaseBenchmark()
+	releasePVMove := <-acquired
+	releasePVMove()
+	b.mu.Lock()
+	assert.Equal(t, 0, b.inUse)
+	b.mu.Unlock()
+}
@@ -0,0 +1,228 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// nameRegexp matches identifiers which are used as command arguments: VG/LV names, partition labels, UUIDs
+var nameRegexp = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.+:-]*$`)
+
+// Device is a value of command template which must be absolute path of device, file or directory
+type Device string
+
+// Name is a value of command template which must be an identifier: VG/LV name, partition label, UUID
+type Name string
+
+// List is a value of command template which is expanded into multiple arguments,
+// it's used for lists of devices and options. Placeholder of list must be a separate word of template
+type List []string
+
+// Cmd is a command which is run as argv array without shell. Template is split into arguments by whitespaces
+// before placeholders are substituted, so each value stays inside the argument of its placeholder and
+// can't add arguments or options to the command
+type Cmd struct {
+	args []string
+	line string
+	err  error
+}
+
+// NewCmd builds Cmd from template with %s/%d placeholders, for example NewCmd("sgdisk -d %s %s", partNum, device)
+// Values of string kinds are trimmed, arguments which become empty after substitution are skipped as well as
+// for commands in string form. Validation error is saved in Cmd and returned when command is run
+func NewCmd(tmpl string, values ...interface{}) Cmd {
+	cmd := Cmd{line: fmt.Sprintf(tmpl, lineValues(values)...)}
+	idx := 0
+	for _, field := range strings.Fields(tmpl) {
+		n := countVerbs(field)
+		if idx+n > len(values) {
+			cmd.err = fmt.Errorf("not enough values for command template %s", tmpl)
+			return cmd
+		}
+		args, err := substitute(field, values[idx:idx+n])
+		if err != nil {
+			cmd.err = fmt.Errorf("unable to build command %s: %w", cmd.line, err)
+			return cmd
+		}
+		cmd.args = append(cmd.args, args...)
+		idx += n
+	}
+	if idx != len(values) {
+		cmd.err = fmt.Errorf("too many values for command template %s", tmpl)
+	}
+	if len(cmd.args) == 0 && cmd.err == nil {
+		cmd.err = errors.New("command is empty")
+	}
+	return cmd
+}
+
+// Append adds arguments which don't contain user input, for example options calculated by the caller
+func (c Cmd) Append(args ...string) Cmd {
+	c.args = append(append([]string{}, c.args...), args...)
+	c.line = strings.Join(append([]string{c.line}, args...), " ")
+	return c
+}
+
+// Args returns argv of command
+func (c Cmd) Args() []string {
+	return c.args
+}
+
+// String returns command line as it would be built with fmt.Sprintf from the same template and values,
+// it's used for logging and in mocks of CmdExecutor
+func (c Cmd) String() string {
+	return c.line
+}
+
+// Err returns error of command building or validation
+func (c Cmd) Err() error {
+	return c.err
+}
+
+// ValidateDevice checks that path is absolute and clean and doesn't contain whitespaces or control characters
+func ValidateDevice(path string) error {
+	if err := validateArg(path); err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) || filepath.Clean(path) != path {
+		return fmt.Errorf("path %q must be absolute and clean", path)
+	}
+	return nil
+}
+
+// ValidateName checks that name contains only letters, digits and _.+:- characters and doesn't start with -
+func ValidateName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("name %q must contain only letters, digits and _.+:- characters", name)
+	}
+	return nil
+}
+
+// validateArg checks that value can't be interpreted as several arguments or as option
+func validateArg(value string) error {
+	if strings.HasPrefix(value, "-") {
+		return fmt.Errorf("value %q must not start with -", value)
+	}
+	for _, r := range value {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("value %q must not contain whitespaces or control characters", value)
+		}
+	}
+	return nil
+}
+
+// countVerbs returns count of placeholders in word of template
+func countVerbs(field string) int {
+	n := 0
+	for i := 0; i < len(field); i++ {
+		if field[i] != '%' {
+			continue
+		}
+		if i+1 < len(field) && field[i+1] == '%' {
+			i++
+			continue
+		}
+		n++
+		i++
+	}
+	return n
+}
+
+// substitute substitutes values into word of template
+// Returns arguments, List value produces multiple arguments, empty argument is skipped
+func substitute(field string, values []interface{}) ([]string, error) {
+	if list, ok := singleList(field, values); ok {
+		args := make([]string, 0, len(list))
+		for _, item := range list {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			if strings.IndexFunc(item, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+				return nil, fmt.Errorf("list item %q must not contain whitespaces or control characters", item)
+			}
+			args = append(args, item)
+		}
+		return args, nil
+	}
+	checked := make([]interface{}, len(values))
+	for i, v := range values {
+		value, err := checkValue(v)
+		if err != nil {
+			return nil, err
+		}
+		checked[i] = value
+	}
+	arg := fmt.Sprintf(field, checked...)
+	if arg == "" {
+		return nil, nil
+	}
+	return []string{arg}, nil
+}
+
+// singleList checks whether word of template is a single placeholder of List value
+func singleList(field string, values []interface{}) (List, bool) {
+	if field != "%s" || len(values) != 1 {
+		return nil, false
+	}
+	list, ok := values[0].(List)
+	return list, ok
+}
+
+// checkValue validates value according to its kind
+// Returns value which is substituted into argument
+func checkValue(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case Device:
+		return string(value), ValidateDevice(string(value))
+	case Name:
+		return string(value), ValidateName(string(value))
+	case List:
+		return nil, errors.New("list value must be a separate word of command template")
+	}
+	// strings and named string types, such as FileSystem, are validated as generic arguments
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+		trimmed := strings.TrimSpace(rv.String())
+		return trimmed, validateArg(trimmed)
+	}
+	return v, nil
+}
+
+// lineValues converts values to form which is used for command line
+func lineValues(values []interface{}) []interface{} {
+	res := make([]interface{}, len(values))
+	for i, v := range values {
+		switch value := v.(type) {
+		case Device:
+			res[i] = string(value)
+		case Name:
+			res[i] = string(value)
+		case List:
+			res[i] = strings.Join(value, " ")
+		default:
+			res[i] = v
+		}
+	}
+	return res
+}
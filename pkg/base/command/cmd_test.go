@@ -0,0 +1,102 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFileSystem string
+
+func TestNewCmd(t *testing.T) {
+	cmd := NewCmd("sgdisk -n 1:0:0 -c 1:%s -u 1:%s %s", Name("CSI"), Name("64be631b-62a5"), Device("/dev/sda"))
+	assert.Nil(t, cmd.Err())
+	assert.Equal(t, []string{"sgdisk", "-n", "1:0:0", "-c", "1:CSI", "-u", "1:64be631b-62a5", "/dev/sda"}, cmd.Args())
+	assert.Equal(t, "sgdisk -n 1:0:0 -c 1:CSI -u 1:64be631b-62a5 /dev/sda", cmd.String())
+
+	// list is expanded, line is the same as for fmt.Sprintf
+	cmd = NewCmd("vgcreate --yes %s %s", Name("vg"), List{"/dev/sda", "/dev/sdb"})
+	assert.Nil(t, cmd.Err())
+	assert.Equal(t, []string{"vgcreate", "--yes", "vg", "/dev/sda", "/dev/sdb"}, cmd.Args())
+	assert.Equal(t, "vgcreate --yes vg /dev/sda /dev/sdb", cmd.String())
+
+	// empty arguments are skipped, several values in one argument, named string types and numbers
+	cmd = NewCmd("lsblk %s --paths -u %s:%s mkfs.%s %db", "", "1", "guid", testFileSystem("xfs"), 1024)
+	assert.Nil(t, cmd.Err())
+	assert.Equal(t, []string{"lsblk", "--paths", "-u", "1:guid", "mkfs.xfs", "1024b"}, cmd.Args())
+	assert.Equal(t, "lsblk  --paths -u 1:guid mkfs.xfs 1024b", cmd.String())
+
+	cmd = NewCmd("lvs --select vg_name=%s -o lv_name", " ")
+	assert.Nil(t, cmd.Err())
+	assert.Equal(t, []string{"lvs", "--select", "vg_name=", "-o", "lv_name"}, cmd.Args())
+
+	cmd = NewCmd("mkfs.xfs %s", Device("/dev/sda")).Append("-K")
+	assert.Equal(t, []string{"mkfs.xfs", "/dev/sda", "-K"}, cmd.Args())
+	assert.Equal(t, "mkfs.xfs /dev/sda -K", cmd.String())
+}
+
+func TestNewCmd_Injection(t *testing.T) {
+	for _, cmd := range []Cmd{
+		NewCmd("lvcreate --name %s", "lv --yes"),
+		NewCmd("lvcreate --name %s", "--zap-all"),
+		NewCmd("lvcreate --name %s", "lv\n--yes"),
+		NewCmd("lvcreate --name %s", Name("lv;rm")),
+		NewCmd("lvcreate --name %s", Name("../lv")),
+		NewCmd("wipefs -af %s", Device("/dev/../etc/passwd")),
+		NewCmd("wipefs -af %s", Device("sda")),
+		NewCmd("wipefs -af %s", Device("")),
+		NewCmd("vgcreate %s", List{"/dev/sda /dev/sdb"}),
+		NewCmd("vgcreate x%s", List{"/dev/sda"}),
+		NewCmd("vgcreate %s %s", "vg"),
+		NewCmd("vgcreate %s", "vg", "/dev/sda"),
+		NewCmd("%s", ""),
+	} {
+		assert.NotNil(t, cmd.Err(), cmd.String())
+	}
+
+	e := NewExecutor(logrus.New())
+	_, _, err := e.RunCmd(NewCmd("echo %s", "1 2"))
+	assert.NotNil(t, err)
+}
+
+func TestExecutor_RunCmd_Cmd(t *testing.T) {
+	e := NewExecutor(logrus.New())
+	stdout, _, err := e.RunCmd(NewCmd("echo %s", "$(id);|&`id`"))
+	assert.Nil(t, err)
+	assert.Equal(t, "$(id);|&`id`\n", stdout)
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"vg", "root-vg", "pvc-1b2c_3", "64BE631B-62A5-11E9-A756-00505680D67F", "lv.cache"} {
+		assert.Nil(t, ValidateName(name), name)
+	}
+	for _, name := range []string{"", "-vg", "vg name", "vg/lv", "vg$", "в"} {
+		assert.NotNil(t, ValidateName(name), name)
+	}
+}
+
+func TestValidateDevice(t *testing.T) {
+	for _, path := range []string{"/dev/sda", "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3-part1", "/dev/mapper/vg-lv"} {
+		assert.Nil(t, ValidateDevice(path), path)
+	}
+	for _, path := range []string{"", "sda", "/dev/sda/", "/dev//sda", "/dev/../sda", "/dev/sd a", "-/dev/sda"} {
+		assert.NotNil(t, ValidateDevice(path), path)
+	}
+}
@@ -92,7 +92,7 @@ func (e *Executor) SetLevel(level logrus.Level) {
 }
 
 // RunCmdWithAttempts runs specified command on OS with given attempts and timeout between attempts
-// Receives command as empty interface, It could be string or instance of exec.Cmd; number of attempts; timeout.
+// Receives command as empty interface, It could be string, Cmd or instance of exec.Cmd; number of attempts; timeout.
 // Returns stdout as string, stderr as string and golang error if something went wrong
 func (e *Executor) RunCmdWithAttempts(cmd interface{}, attempts int, timeout time.Duration, opts ...Options) (string, string, error) {
 	options := &CmdOptions{}
@@ -120,7 +120,8 @@ func (e *Executor) RunCmdWithAttempts(cmd interface{}, attempts int, timeout tim
 }
 
 // RunCmd runs specified command on OS
-// Receives command as empty interface. It could be string or instance of exec.Cmd
+// Receives command as empty interface. It could be string, Cmd or instance of exec.Cmd.
+// Cmd should be used when arguments contain values which came from outside, string is split by whitespaces
 // Returns stdout as string, stderr as string and golang error if something went wrong
 func (e *Executor) RunCmd(cmd interface{}, opts ...Options) (string, string, error) {
 	options := &CmdOptions{}
@@ -134,6 +135,13 @@ func (e *Executor) RunCmd(cmd interface{}, opts ...Options) (string, string, err
 	if cmdObj, ok := cmd.(*exec.Cmd); ok {
 		return e.runCmdFromCmdObj(cmdObj)
 	}
+	if cmdArgv, ok := cmd.(Cmd); ok {
+		if err := cmdArgv.Err(); err != nil {
+			return "", "", err
+		}
+		args := cmdArgv.Args()
+		return e.runCmdFromCmdObj(exec.Command(args[0], args[1:]...))
+	}
 	return "", "", fmt.Errorf("could not interpret command from %v", cmd)
 }
 
@@ -162,6 +170,20 @@ func (e *Executor) runCmdFromCmdObj(cmd *exec.Cmd) (outStr string, errStr string
 	if level == 0 {
 		level = logrus.DebugLevel
 	}
+	if runner := getDryRunner(); runner != nil {
+		return runner(cmd.Args)
+	}
+	release, waited := acquireBudget(cmd.Args)
+	defer release()
+	if waited > time.Second {
+		e.log.WithField("cmd", strings.Join(cmd.Args, " ")).
+			Infof("Command waited for budget of heavy operations for %s", waited)
+	}
+	cmd = wrapCmd(cmd)
+	if err = prepareCmd(cmd); err != nil {
+		e.log.WithField("cmd", strings.Join(cmd.Args, " ")).Errorf("Unable to run command: %v", err)
+		return "", "", err
+	}
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
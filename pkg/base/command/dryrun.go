@@ -0,0 +1,39 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+// DryRunner is called by Executors instead of running command on OS when dry run is set
+// Receives command line arguments, returns simulated stdout, stderr and error of the command
+type DryRunner func(args []string) (string, string, error)
+
+// dryRunner is used by all Executors if it isn't nil, is guarded by execModeMu
+var dryRunner DryRunner
+
+// SetDryRun makes all Executors pass commands to runner instead of running them on OS,
+// it is used by developer tools which render commands of node operations. Nil runner disables dry run
+func SetDryRun(runner DryRunner) {
+	execModeMu.Lock()
+	dryRunner = runner
+	execModeMu.Unlock()
+}
+
+// getDryRunner returns runner which is set by SetDryRun or nil
+func getDryRunner() DryRunner {
+	execModeMu.RLock()
+	defer execModeMu.RUnlock()
+	return dryRunner
+}
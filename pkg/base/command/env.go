@@ -0,0 +1,97 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// CmdPath is PATH which is used to find utilities and is passed to external commands
+	CmdPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+	// CmdLocale is locale of external commands, utilities print untranslated messages and
+	// numbers without thousands separators in it, so their output doesn't depend on locale of the node
+	CmdLocale = "C"
+)
+
+// PassthroughEnv contains variables of the process environment which are passed to external commands,
+// other variables are scrubbed
+var PassthroughEnv = []string{"HOME", "TMPDIR", "LVM_SYSTEM_DIR"}
+
+// localeEnv contains locale variables, values of these variables can't be overridden by caller
+var localeEnv = []string{"LC_ALL=" + CmdLocale, "LANG=" + CmdLocale, "LANGUAGE="}
+
+// cmdEnv builds environment of external command: minimal PATH, passthrough variables of the process environment,
+// variables set by caller in exec.Cmd and locale variables
+// Receives environment set by caller
+// Returns environment in os.Environ format
+func cmdEnv(callerEnv []string) []string {
+	env := []string{"PATH=" + CmdPath}
+	for _, name := range PassthroughEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	for _, kv := range callerEnv {
+		if !isLocaleVar(kv) {
+			env = append(env, kv)
+		}
+	}
+	// the last value of duplicated variable is used by exec package
+	return append(env, localeEnv...)
+}
+
+// isLocaleVar checks whether variable in format name=value is one of locale variables
+func isLocaleVar(kv string) bool {
+	name := strings.SplitN(kv, "=", 2)[0]
+	return name == "LANG" || name == "LANGUAGE" || strings.HasPrefix(name, "LC_")
+}
+
+// lookPath searches utility in CmdPath, so utilities aren't taken from directories added to PATH of the process
+// Receives utility name, names with slash are returned as is
+// Returns absolute path of utility or error which wraps exec.ErrNotFound if utility isn't found
+func lookPath(name string) (string, error) {
+	if strings.Contains(name, "/") {
+		return name, nil
+	}
+	for _, dir := range filepath.SplitList(CmdPath) {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w in %s", name, exec.ErrNotFound, CmdPath)
+}
+
+// prepareCmd sets environment of external command and path of utility found in CmdPath
+// Returns error if utility isn't found
+func prepareCmd(cmd *exec.Cmd) error {
+	cmd.Env = cmdEnv(cmd.Env)
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+	path, err := lookPath(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	cmd.Path = path
+	return nil
+}
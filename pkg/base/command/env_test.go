@@ -0,0 +1,93 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func setEnv(t *testing.T, name, value string) {
+	old, ok := os.LookupEnv(name)
+	assert.Nil(t, os.Setenv(name, value))
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(name, old)
+		} else {
+			_ = os.Unsetenv(name)
+		}
+	})
+}
+
+func TestCmdEnv(t *testing.T) {
+	setEnv(t, "LC_ALL", "de_DE.UTF-8")
+	setEnv(t, "TMPDIR", "/var/tmp")
+	setEnv(t, "CSI_TEST_SECRET", "secret")
+
+	env := cmdEnv([]string{"CRYPT_KEY=1", "LC_MESSAGES=ru_RU.UTF-8"})
+	assert.Equal(t, "PATH="+CmdPath, env[0])
+	assert.Contains(t, env, "TMPDIR=/var/tmp")
+	assert.Contains(t, env, "CRYPT_KEY=1")
+	assert.Equal(t, localeEnv, env[len(env)-len(localeEnv):])
+	for _, kv := range env {
+		assert.False(t, strings.HasPrefix(kv, "CSI_TEST_SECRET="), kv)
+		assert.False(t, strings.HasPrefix(kv, "LC_MESSAGES="), kv)
+	}
+}
+
+func TestLookPath(t *testing.T) {
+	path, err := lookPath("/opt/bin/sgdisk")
+	assert.Nil(t, err)
+	assert.Equal(t, "/opt/bin/sgdisk", path)
+
+	_, err = lookPath("csi-not-existing-utility")
+	assert.True(t, errors.Is(err, exec.ErrNotFound))
+
+	path, err = lookPath("sh")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(path, "/"), path)
+}
+
+func TestExecutor_Env(t *testing.T) {
+	setEnv(t, "LC_ALL", "de_DE.UTF-8")
+	setEnv(t, "CSI_TEST_SECRET", "secret")
+	setEnv(t, "PATH", os.Getenv("PATH")+":/csi-test-path")
+
+	e := NewExecutor(logrus.New())
+	stdout, _, err := e.RunCmd("env")
+	assert.Nil(t, err)
+	assert.Contains(t, stdout, "LC_ALL=C\n")
+	assert.Contains(t, stdout, "PATH="+CmdPath+"\n")
+	assert.NotContains(t, stdout, "CSI_TEST_SECRET")
+
+	cmd := exec.Command("env")
+	cmd.Env = []string{"CSI_TEST_VAR=value", "LC_ALL=de_DE.UTF-8"}
+	stdout, _, err = e.RunCmd(cmd)
+	assert.Nil(t, err)
+	assert.Contains(t, stdout, "CSI_TEST_VAR=value\n")
+	assert.NotContains(t, stdout, "de_DE")
+
+	// utility from PATH of the process isn't run
+	_, _, err = e.RunCmd("csi-not-existing-utility")
+	assert.True(t, errors.Is(err, exec.ErrNotFound))
+}
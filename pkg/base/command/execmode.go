@@ -0,0 +1,159 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dell/csi-baremetal/pkg/base"
+)
+
+// Execution modes define where system utilities (parted, sgdisk, lvm, mkfs, etc.) are taken from
+const (
+	// ExecModeContainer runs utilities bundled in the container image, is used for minimal hosts
+	// (Flatcar, Bottlerocket) which don't have these utilities
+	ExecModeContainer = "container"
+	// ExecModeNsenter runs host utilities in host namespaces, requires hostPID
+	ExecModeNsenter = "nsenter"
+	// ExecModeChroot runs host utilities in chroot to host root mounted in the container
+	ExecModeChroot = "chroot"
+	// ExecModeSudo runs utilities with non-interactive sudo, is used when container isn't run as root
+	ExecModeSudo = "sudo"
+)
+
+// RequiredUtilities contains system utilities which are used by node service and drive managers
+var RequiredUtilities = []string{"lsblk", "blkid", "parted", "sgdisk", "partprobe", "wipefs", "lvm",
+	"mkfs.xfs", "mkfs.ext4", "mount", "umount"}
+
+var (
+	execModeMu sync.RWMutex
+	// execPrefix is added before each command which is run by Executor
+	execPrefix []string
+	// utilityPaths contains absolute paths of utilities, key - utility name
+	utilityPaths map[string]string
+)
+
+// SetExecMode sets mode in which all Executors run commands
+// Receives one of ExecModeContainer, ExecModeNsenter, ExecModeChroot
+// Returns error if mode is unknown
+func SetExecMode(mode string) error {
+	prefix, err := ExecPrefix(mode)
+	if err != nil {
+		return err
+	}
+	execModeMu.Lock()
+	execPrefix = prefix
+	execModeMu.Unlock()
+	return nil
+}
+
+// ExecPrefix returns prefix which is added before each command in provided exec mode
+// Returns error if mode is unknown
+func ExecPrefix(mode string) ([]string, error) {
+	switch mode {
+	case ExecModeContainer, "":
+		return nil, nil
+	case ExecModeNsenter:
+		return []string{"nsenter", "--target", "1", "--mount", "--uts", "--ipc", "--net", "--pid", "--"}, nil
+	case ExecModeChroot:
+		return []string{"chroot", base.HostRootPath}, nil
+	case ExecModeSudo:
+		return []string{"sudo", "--non-interactive", "--"}, nil
+	default:
+		return nil, fmt.Errorf("unknown exec mode %s, supported: %s, %s, %s, %s",
+			mode, ExecModeContainer, ExecModeNsenter, ExecModeChroot, ExecModeSudo)
+	}
+}
+
+// SetUtilityPaths sets absolute paths of utilities which are used instead of names from command templates
+// Receives map utility name -> absolute path, for example sgdisk -> /opt/bin/sgdisk
+// Returns error if path isn't absolute
+func SetUtilityPaths(paths map[string]string) error {
+	for name, path := range paths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("path %s of utility %s isn't absolute", path, name)
+		}
+	}
+	execModeMu.Lock()
+	utilityPaths = paths
+	execModeMu.Unlock()
+	return nil
+}
+
+// ParseUtilityPaths parses utility paths in format name=path,name=path, for example parted=/usr/sbin/parted
+// Returns map utility name -> path or error if format is wrong
+func ParseUtilityPaths(str string) (map[string]string, error) {
+	paths := make(map[string]string)
+	if strings.TrimSpace(str) == "" {
+		return paths, nil
+	}
+	for _, pair := range strings.Split(str, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("wrong utility path format %s, expected name=path", pair)
+		}
+		paths[kv[0]] = kv[1]
+	}
+	return paths, nil
+}
+
+// wrapCmd replaces utility name with configured path and adds exec mode prefix to command
+func wrapCmd(cmd *exec.Cmd) *exec.Cmd {
+	execModeMu.RLock()
+	prefix, paths := execPrefix, utilityPaths
+	execModeMu.RUnlock()
+	if len(prefix) == 0 && len(paths) == 0 {
+		return cmd
+	}
+	args := cmd.Args
+	if path, ok := paths[filepath.Base(args[0])]; ok {
+		args = append([]string{path}, args[1:]...)
+	}
+	if len(prefix) > 0 {
+		args = append(append([]string{}, prefix...), args...)
+	}
+	wrapped := exec.Command(args[0], args[1:]...)
+	wrapped.Env = cmd.Env
+	wrapped.Dir = cmd.Dir
+	wrapped.Stdin = cmd.Stdin
+	return wrapped
+}
+
+// CheckUtilities checks that utilities are available in current exec mode
+// Receives CmdExecutor and list of utilities
+// Returns list of missing utilities
+func CheckUtilities(e CmdExecutor, utilities []string) []string {
+	execModeMu.RLock()
+	paths := utilityPaths
+	execModeMu.RUnlock()
+
+	var missing []string
+	for _, u := range utilities {
+		path := u
+		if p, ok := paths[u]; ok {
+			path = p
+		}
+		if _, _, err := e.RunCmd(fmt.Sprintf("which %s", path)); err != nil {
+			missing = append(missing, u)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/base"
+)
+
+func TestSetExecMode(t *testing.T) {
+	defer func() { assert.Nil(t, SetExecMode(ExecModeContainer)) }()
+
+	assert.NotNil(t, SetExecMode("unknown"))
+
+	assert.Nil(t, SetExecMode(ExecModeChroot))
+	cmd := wrapCmd(exec.Command("parted", "-s", "/dev/sda", "print"))
+	assert.Equal(t, []string{"chroot", base.HostRootPath, "parted", "-s", "/dev/sda", "print"}, cmd.Args)
+
+	assert.Nil(t, SetExecMode(ExecModeNsenter))
+	cmd = wrapCmd(exec.Command("lsblk"))
+	assert.Equal(t, "nsenter", cmd.Args[0])
+	assert.Equal(t, "lsblk", cmd.Args[len(cmd.Args)-1])
+
+	assert.Nil(t, SetExecMode(ExecModeContainer))
+	cmd = wrapCmd(exec.Command("lsblk"))
+	assert.Equal(t, []string{"lsblk"}, cmd.Args)
+}
+
+func TestExecPrefix(t *testing.T) {
+	prefix, err := ExecPrefix(ExecModeSudo)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sudo", "--non-interactive", "--"}, prefix)
+
+	prefix, err = ExecPrefix("")
+	assert.Nil(t, err)
+	assert.Empty(t, prefix)
+
+	_, err = ExecPrefix("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestCheckUtilities(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	missing := CheckUtilities(NewExecutor(logrus.New()), []string{"echo", "csi-baremetal-missing-util"})
+	assert.Equal(t, []string{"csi-baremetal-missing-util"}, missing)
+}
+
+func TestSetUtilityPaths(t *testing.T) {
+	defer func() {
+		assert.Nil(t, SetUtilityPaths(nil))
+		assert.Nil(t, SetExecMode(ExecModeContainer))
+	}()
+
+	paths, err := ParseUtilityPaths("sgdisk=/opt/bin/sgdisk, lvm=/usr/sbin/lvm")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"sgdisk": "/opt/bin/sgdisk", "lvm": "/usr/sbin/lvm"}, paths)
+	_, err = ParseUtilityPaths("sgdisk")
+	assert.NotNil(t, err)
+	paths, err = ParseUtilityPaths("")
+	assert.Nil(t, err)
+	assert.Empty(t, paths)
+
+	assert.NotNil(t, SetUtilityPaths(map[string]string{"sgdisk": "bin/sgdisk"}))
+	assert.Nil(t, SetUtilityPaths(map[string]string{"sgdisk": "/opt/bin/sgdisk", "lvm": "/usr/sbin/lvm"}))
+	cmd := wrapCmd(exec.Command("sgdisk", "-o", "/dev/sda"))
+	assert.Equal(t, []string{"/opt/bin/sgdisk", "-o", "/dev/sda"}, cmd.Args)
+	cmd = wrapCmd(exec.Command("/sbin/lvm", "pvs"))
+	assert.Equal(t, []string{"/usr/sbin/lvm", "pvs"}, cmd.Args)
+	cmd = wrapCmd(exec.Command("parted", "-v"))
+	assert.Equal(t, []string{"parted", "-v"}, cmd.Args)
+
+	assert.Nil(t, SetExecMode(ExecModeSudo))
+	cmd = wrapCmd(exec.Command("sgdisk", "-o", "/dev/sda"))
+	assert.Equal(t, []string{"sudo", "--non-interactive", "--", "/opt/bin/sgdisk", "-o", "/dev/sda"}, cmd.Args)
+}
+
+func TestSetDryRun(t *testing.T) {
+	defer SetDryRun(nil)
+
+	var run [][]string
+	SetDryRun(func(args []string) (string, string, error) {
+		run = append(run, args)
+		return "simulated", "", nil
+	})
+	e := NewExecutor(logrus.New())
+	stdout, _, err := e.RunCmd(NewCmd("sgdisk %s -o", Device("/dev/sda")))
+	assert.Nil(t, err)
+	assert.Equal(t, "simulated", stdout)
+	_, _, err = e.RunCmd("csi-baremetal-missing-util --version")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"sgdisk", "/dev/sda", "-o"}, {"csi-baremetal-missing-util", "--version"}}, run)
+
+	SetDryRun(nil)
+	_, _, err = e.RunCmd("csi-baremetal-missing-util --version")
+	assert.NotNil(t, err)
+}
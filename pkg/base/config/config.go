@@ -0,0 +1,332 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config contains versioned configuration file of node and controller services,
+// which is mounted from ConfigMap and reloaded on change or SIGHUP
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/dell/csi-baremetal/pkg/base/ctrlopts"
+	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
+	"github.com/dell/csi-baremetal/pkg/base/logger"
+)
+
+// Version is the version of configuration file schema supported by this release
+const Version = 1
+
+// Keys of controllers section, values are prefixes of environment variables read by ctrlopts
+var controllerPrefixes = map[string]string{
+	"volume":   ctrlopts.VolumeControllerPrefix,
+	"drive":    ctrlopts.DriveControllerPrefix,
+	"lvg":      ctrlopts.LVGControllerPrefix,
+	"capacity": ctrlopts.CapacityControllerPrefix,
+}
+
+// Config is the content of configuration file
+// LogLevel, LowCapacityThresholds, Debug.Profiling and Discovery are applied on reload,
+// other parameters are applied on start only
+type Config struct {
+	Version int `yaml:"version"`
+	// log level, overrides --loglevel option
+	LogLevel string `yaml:"logLevel,omitempty"`
+	// minimal free capacity in percents per media type, overrides --low-capacity-thresholds option of controller
+	LowCapacityThresholds map[string]float64 `yaml:"lowCapacityThresholds,omitempty"`
+
+	// text or json, overrides LOG_FORMAT environment variable
+	LogFormat string `yaml:"logFormat,omitempty"`
+	// overrides RESYNC_INTERVAL environment variable
+	ResyncInterval Duration `yaml:"resyncInterval,omitempty"`
+	// key - volume, drive, lvg or capacity, overrides <NAME>_CONTROLLER_* environment variables
+	Controllers map[string]ControllerConfig `yaml:"controllers,omitempty"`
+	// overrides RESERVATION_* environment variables
+	Reservation ReservationConfig `yaml:"reservation,omitempty"`
+	// key - feature gate name, overrides --feature-gates option
+	FeatureGates map[string]bool `yaml:"featureGates,omitempty"`
+	// profiling of node and controller services
+	Debug DebugConfig `yaml:"debug,omitempty"`
+	// intervals of drive discovery of node service
+	Discovery DiscoveryConfig `yaml:"discovery,omitempty"`
+}
+
+// DiscoveryConfig contains intervals of drive discovery of node service, intervals of node group take precedence
+type DiscoveryConfig struct {
+	DiscoveryIntervals `yaml:",inline"`
+	// key - node group which is set with --nodegroup option of node service
+	NodeGroups map[string]DiscoveryIntervals `yaml:"nodeGroups,omitempty"`
+}
+
+// DiscoveryIntervals contains intervals of drive discovery, see node.VolumeManager.SetDiscoveryIntervals
+type DiscoveryIntervals struct {
+	// interval in steady state, overrides --discover-interval option
+	Interval Duration `yaml:"interval,omitempty"`
+	// interval after drives are changed or discovery fails, overrides --discover-fast-interval option
+	FastInterval Duration `yaml:"fastInterval,omitempty"`
+}
+
+// DebugConfig contains parameters of profiling, see diagnostics.Profiler
+type DebugConfig struct {
+	// enables pprof endpoints and snapshots of profiles in addition to --debug-profiling option
+	Profiling bool `yaml:"profiling,omitempty"`
+	// interval of heap and goroutine snapshots, overrides --profile-snapshot-interval option
+	SnapshotInterval Duration `yaml:"snapshotInterval,omitempty"`
+}
+
+// ControllerConfig contains reconciliation parameters of CR controller, see ctrlopts.Options
+type ControllerConfig struct {
+	MaxConcurrentReconciles int      `yaml:"maxConcurrentReconciles,omitempty"`
+	BaseDelay               Duration `yaml:"baseDelay,omitempty"`
+	MaxDelay                Duration `yaml:"maxDelay,omitempty"`
+}
+
+// ReservationConfig contains parameters of rate limiter of reservation controller
+type ReservationConfig struct {
+	FastDelay       Duration `yaml:"fastDelay,omitempty"`
+	SlowDelay       Duration `yaml:"slowDelay,omitempty"`
+	MaxFastAttempts int      `yaml:"maxFastAttempts,omitempty"`
+}
+
+// Duration is time.Duration which is represented in configuration file as string, e.g. 30s
+type Duration time.Duration
+
+// UnmarshalYAML parses duration string
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalYAML represents duration as string
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// Load reads and validates configuration file
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates content of configuration file, unknown fields are rejected
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse configuration: %v", err)
+	}
+	if len(cfg.LowCapacityThresholds) > 0 {
+		thresholds := make(map[string]float64, len(cfg.LowCapacityThresholds))
+		for mediaType, value := range cfg.LowCapacityThresholds {
+			thresholds[strings.ToUpper(mediaType)] = value
+		}
+		cfg.LowCapacityThresholds = thresholds
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks configuration against schema of supported Version
+func (c *Config) Validate() error {
+	if c.Version != Version {
+		return fmt.Errorf("unsupported configuration version %d, supported version is %d", c.Version, Version)
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "", logger.InfoLevel, logger.DebugLevel, logger.TraceLevel:
+	default:
+		return fmt.Errorf("unsupported log level %s", c.LogLevel)
+	}
+	switch c.LogFormat {
+	case "", logger.LogFormatText, "json":
+	default:
+		return fmt.Errorf("unsupported log format %s", c.LogFormat)
+	}
+	for mediaType, value := range c.LowCapacityThresholds {
+		if value < 0 || value > 100 {
+			return fmt.Errorf("low capacity threshold of %s must be in range [0, 100], got %v", mediaType, value)
+		}
+	}
+	if c.ResyncInterval < 0 {
+		return fmt.Errorf("resync interval must not be negative")
+	}
+	for name, ctrl := range c.Controllers {
+		if _, ok := controllerPrefixes[name]; !ok {
+			return fmt.Errorf("unknown controller %s", name)
+		}
+		if ctrl.MaxConcurrentReconciles < 0 || ctrl.BaseDelay < 0 || ctrl.MaxDelay < 0 {
+			return fmt.Errorf("parameters of %s controller must not be negative", name)
+		}
+		if ctrl.MaxDelay != 0 && ctrl.MaxDelay < ctrl.BaseDelay {
+			return fmt.Errorf("max delay of %s controller is less than base delay", name)
+		}
+	}
+	for name, enabled := range c.FeatureGates {
+		if err := featureconfig.ValidateFeatureGate(name, enabled); err != nil {
+			return err
+		}
+	}
+	if c.Reservation.FastDelay < 0 || c.Reservation.SlowDelay < 0 || c.Reservation.MaxFastAttempts < 0 {
+		return fmt.Errorf("reservation parameters must not be negative")
+	}
+	if c.Debug.SnapshotInterval < 0 {
+		return fmt.Errorf("snapshot interval must not be negative")
+	}
+	if err := c.Discovery.validate("discovery"); err != nil {
+		return err
+	}
+	for group, intervals := range c.Discovery.NodeGroups {
+		if err := intervals.validate("discovery of node group " + group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate checks that intervals aren't negative and fast interval doesn't exceed steady state interval
+func (d DiscoveryIntervals) validate(name string) error {
+	if d.Interval < 0 || d.FastInterval < 0 {
+		return fmt.Errorf("intervals of %s must not be negative", name)
+	}
+	if d.Interval > 0 && d.FastInterval > d.Interval {
+		return fmt.Errorf("fast interval of %s is greater than interval", name)
+	}
+	return nil
+}
+
+// Env returns environment variables which are read by components on start, only set parameters are returned
+func (c *Config) Env() map[string]string {
+	env := make(map[string]string)
+	setDuration := func(name string, d Duration) {
+		if d > 0 {
+			env[name] = time.Duration(d).String()
+		}
+	}
+	setInt := func(name string, n int) {
+		if n > 0 {
+			env[name] = strconv.Itoa(n)
+		}
+	}
+	if c.LogFormat != "" {
+		env[logger.LogFormatEnv] = c.LogFormat
+	}
+	setDuration(ctrlopts.ResyncIntervalEnv, c.ResyncInterval)
+	for name, ctrl := range c.Controllers {
+		prefix := controllerPrefixes[name]
+		setInt(prefix+ctrlopts.MaxConcurrentReconcilesEnvSuffix, ctrl.MaxConcurrentReconciles)
+		setDuration(prefix+ctrlopts.BaseDelayEnvSuffix, ctrl.BaseDelay)
+		setDuration(prefix+ctrlopts.MaxDelayEnvSuffix, ctrl.MaxDelay)
+	}
+	setDuration(ctrlopts.ReservationFastDelayEnv, c.Reservation.FastDelay)
+	setDuration(ctrlopts.ReservationSlowDelayEnv, c.Reservation.SlowDelay)
+	setInt(ctrlopts.ReservationMaxFastAttemptsEnv, c.Reservation.MaxFastAttempts)
+	return env
+}
+
+// ExportEnv sets environment variables returned by Env, values of configuration file take precedence
+// over environment of container. Must be called before components are created
+func (c *Config) ExportEnv() error {
+	for name, value := range c.Env() {
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLogLevel returns log level of configuration or defaultLevel if it isn't set
+func (c *Config) GetLogLevel(defaultLevel string) string {
+	if c.LogLevel == "" {
+		return defaultLevel
+	}
+	return c.LogLevel
+}
+
+// LogrusLevel returns result of GetLogLevel as logrus.Level
+func (c *Config) LogrusLevel(defaultLevel string) logrus.Level {
+	return logger.ParseLevel(c.GetLogLevel(defaultLevel))
+}
+
+// ProfilingEnabled returns whether profiling is enabled by configuration or by enabledByOption
+func (c *Config) ProfilingEnabled(enabledByOption bool) bool {
+	return c.Debug.Profiling || enabledByOption
+}
+
+// GetSnapshotInterval returns interval of snapshots of profiles or defaultInterval if it isn't set
+func (c *Config) GetSnapshotInterval(defaultInterval time.Duration) time.Duration {
+	if c.Debug.SnapshotInterval == 0 {
+		return defaultInterval
+	}
+	return time.Duration(c.Debug.SnapshotInterval)
+}
+
+// GetDiscoveryIntervals returns fast and steady state intervals of drive discovery for node group,
+// intervals which aren't set for node group are taken from common intervals or from defaults
+func (c *Config) GetDiscoveryIntervals(nodeGroup string, defaultFast, defaultSteady time.Duration) (time.Duration,
+	time.Duration) {
+	fast, steady := defaultFast, defaultSteady
+	for _, intervals := range []DiscoveryIntervals{c.Discovery.DiscoveryIntervals, c.Discovery.NodeGroups[nodeGroup]} {
+		if intervals.FastInterval > 0 {
+			fast = time.Duration(intervals.FastInterval)
+		}
+		if intervals.Interval > 0 {
+			steady = time.Duration(intervals.Interval)
+		}
+	}
+	return fast, steady
+}
+
+// restartRequired returns true if parameters which are applied on start only differ
+func (c *Config) restartRequired(other *Config) bool {
+	a, b := *c, *other
+	a.LogLevel, b.LogLevel = "", ""
+	a.LowCapacityThresholds, b.LowCapacityThresholds = nil, nil
+	a.Debug.Profiling, b.Debug.Profiling = false, false
+	a.Discovery, b.Discovery = DiscoveryConfig{}, DiscoveryConfig{}
+	return !reflect.DeepEqual(a, b)
+}
+
+// Init loads configuration file on start and exports parameters which are read from environment variables
+// Empty path means that configuration file isn't used, configuration without parameters is returned in this case
+func Init(path string) (*Config, error) {
+	if path == "" {
+		return &Config{Version: Version}, nil
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, cfg.ExportEnv()
+}
@@ -0,0 +1,207 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/base/ctrlopts"
+	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
+	"github.com/dell/csi-baremetal/pkg/base/logger"
+)
+
+const testConfig = `
+version: 1
+logLevel: debug
+lowCapacityThresholds:
+  ssd: 10
+  HDD: 5.5
+logFormat: text
+resyncInterval: 10m
+controllers:
+  volume:
+    maxConcurrentReconciles: 5
+    baseDelay: 1s
+    maxDelay: 1m
+reservation:
+  fastDelay: 2s
+  maxFastAttempts: 10
+featureGates:
+  VolumeEncryption: false
+debug:
+  profiling: true
+  snapshotInterval: 10m
+discovery:
+  interval: 1m
+  nodeGroups:
+    edge:
+      interval: 5m
+      fastInterval: 20s
+`
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	assert.Nil(t, err)
+	assert.Equal(t, logger.DebugLevel, cfg.LogLevel)
+	assert.Equal(t, map[string]float64{"SSD": 10, "HDD": 5.5}, cfg.LowCapacityThresholds)
+	assert.Equal(t, Duration(10*time.Minute), cfg.ResyncInterval)
+	assert.Equal(t, ControllerConfig{MaxConcurrentReconciles: 5, BaseDelay: Duration(time.Second),
+		MaxDelay: Duration(time.Minute)}, cfg.Controllers["volume"])
+	assert.Equal(t, map[string]bool{featureconfig.FeatureVolumeEncryption: false}, cfg.FeatureGates)
+	assert.Equal(t, DebugConfig{Profiling: true, SnapshotInterval: Duration(10 * time.Minute)}, cfg.Debug)
+	assert.Equal(t, Duration(time.Minute), cfg.Discovery.Interval)
+	assert.Equal(t, DiscoveryIntervals{Interval: Duration(5 * time.Minute), FastInterval: Duration(20 * time.Second)},
+		cfg.Discovery.NodeGroups["edge"])
+
+	for name, data := range map[string]string{
+		"missing version":      "logLevel: debug",
+		"unsupported version":  "version: 2",
+		"unknown field":        "version: 1\nlogLevels: debug",
+		"wrong log level":      "version: 1\nlogLevel: verbose",
+		"wrong log format":     "version: 1\nlogFormat: xml",
+		"wrong threshold":      "version: 1\nlowCapacityThresholds:\n  SSD: 110",
+		"wrong duration":       "version: 1\nresyncInterval: 10",
+		"unknown controller":   "version: 1\ncontrollers:\n  node:\n    maxConcurrentReconciles: 1",
+		"wrong delays":         "version: 1\ncontrollers:\n  drive:\n    baseDelay: 1m\n    maxDelay: 1s",
+		"negative attempts":    "version: 1\nreservation:\n  maxFastAttempts: -1",
+		"unknown feature gate": "version: 1\nfeatureGates:\n  UnknownFeature: true",
+		"negative interval":    "version: 1\ndebug:\n  snapshotInterval: -1m",
+		"wrong discovery":      "version: 1\ndiscovery:\n  interval: 10s\n  fastInterval: 1m",
+		"negative discovery":   "version: 1\ndiscovery:\n  nodeGroups:\n    edge:\n      interval: -1m",
+	} {
+		_, err = Parse([]byte(data))
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestConfig_Env(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		logger.LogFormatEnv:        logger.LogFormatText,
+		ctrlopts.ResyncIntervalEnv: "10m0s",
+		ctrlopts.VolumeControllerPrefix + ctrlopts.MaxConcurrentReconcilesEnvSuffix: "5",
+		ctrlopts.VolumeControllerPrefix + ctrlopts.BaseDelayEnvSuffix:               "1s",
+		ctrlopts.VolumeControllerPrefix + ctrlopts.MaxDelayEnvSuffix:                "1m0s",
+		ctrlopts.ReservationFastDelayEnv:                                            "2s",
+		ctrlopts.ReservationMaxFastAttemptsEnv:                                      "10",
+	}, cfg.Env())
+
+	assert.Empty(t, (&Config{Version: Version}).Env())
+}
+
+func TestConfig_GetLogLevel(t *testing.T) {
+	cfg := &Config{Version: Version}
+	assert.Equal(t, logger.InfoLevel, cfg.GetLogLevel(logger.InfoLevel))
+	assert.Equal(t, logrus.TraceLevel, cfg.LogrusLevel(logger.TraceLevel))
+
+	cfg.LogLevel = logger.DebugLevel
+	assert.Equal(t, logrus.DebugLevel, cfg.LogrusLevel(logger.TraceLevel))
+}
+
+func TestConfig_Profiling(t *testing.T) {
+	cfg := &Config{Version: Version}
+	assert.False(t, cfg.ProfilingEnabled(false))
+	assert.True(t, cfg.ProfilingEnabled(true))
+	assert.Equal(t, time.Minute, cfg.GetSnapshotInterval(time.Minute))
+
+	cfg.Debug = DebugConfig{Profiling: true, SnapshotInterval: Duration(time.Hour)}
+	assert.True(t, cfg.ProfilingEnabled(false))
+	assert.Equal(t, time.Hour, cfg.GetSnapshotInterval(time.Minute))
+}
+
+func TestConfig_GetDiscoveryIntervals(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	assert.Nil(t, err)
+
+	fast, steady := cfg.GetDiscoveryIntervals("", 10*time.Second, 30*time.Second)
+	assert.Equal(t, 10*time.Second, fast)
+	assert.Equal(t, time.Minute, steady)
+
+	fast, steady = cfg.GetDiscoveryIntervals("edge", 10*time.Second, 30*time.Second)
+	assert.Equal(t, 20*time.Second, fast)
+	assert.Equal(t, 5*time.Minute, steady)
+
+	fast, steady = (&Config{Version: Version}).GetDiscoveryIntervals("edge", 10*time.Second, 30*time.Second)
+	assert.Equal(t, 10*time.Second, fast)
+	assert.Equal(t, 30*time.Second, steady)
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("version: 1\nlogLevel: info"), 0600))
+	cfg, err := Load(path)
+	assert.Nil(t, err)
+
+	var (
+		w        = NewWatcher(path, cfg, logrus.New())
+		reloaded []*Config
+	)
+	w.OnReload(func(cfg *Config) {
+		reloaded = append(reloaded, cfg)
+	})
+
+	// configuration isn't changed
+	assert.Nil(t, w.Reload())
+	assert.Empty(t, reloaded)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("version: 1\nlogLevel: debug"), 0600))
+	assert.Nil(t, w.Reload())
+	assert.Len(t, reloaded, 1)
+	assert.Equal(t, logger.DebugLevel, reloaded[0].LogLevel)
+
+	// invalid configuration is rejected
+	assert.Nil(t, ioutil.WriteFile(path, []byte("version: 1\nlogLevel: verbose"), 0600))
+	assert.NotNil(t, w.Reload())
+	assert.Len(t, reloaded, 1)
+
+	assert.Nil(t, os.Remove(path))
+	assert.NotNil(t, w.Reload())
+	assert.Len(t, reloaded, 1)
+}
+
+func TestConfig_restartRequired(t *testing.T) {
+	cfg := &Config{Version: Version, LogLevel: logger.DebugLevel}
+	assert.False(t, cfg.restartRequired(&Config{Version: Version,
+		LowCapacityThresholds: map[string]float64{"SSD": 10}, Debug: DebugConfig{Profiling: true},
+		Discovery: DiscoveryConfig{DiscoveryIntervals: DiscoveryIntervals{Interval: Duration(time.Minute)}}}))
+	assert.True(t, cfg.restartRequired(&Config{Version: Version, LogFormat: logger.LogFormatText}))
+}
+
+func TestInit(t *testing.T) {
+	cfg, err := Init("")
+	assert.Nil(t, err)
+	assert.Equal(t, &Config{Version: Version}, cfg)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("version: 1\nresyncInterval: 5m"), 0600))
+	defer os.Unsetenv(ctrlopts.ResyncIntervalEnv)
+	cfg, err = Init(path)
+	assert.Nil(t, err)
+	assert.Equal(t, Duration(5*time.Minute), cfg.ResyncInterval)
+	assert.Equal(t, "5m0s", os.Getenv(ctrlopts.ResyncIntervalEnv))
+
+	_, err = Init(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// Watcher reloads configuration file when it's changed or SIGHUP is caught and notifies subscribers
+type Watcher struct {
+	path string
+
+	mu       sync.Mutex
+	current  *Config
+	handlers []func(*Config)
+
+	log *logrus.Entry
+}
+
+// NewWatcher is the constructor for Watcher
+// Receives path of configuration file, configuration which was loaded on start and logrus logger
+func NewWatcher(path string, current *Config, logger *logrus.Logger) *Watcher {
+	return &Watcher{
+		path:    path,
+		current: current,
+		log:     logger.WithField("component", "ConfigWatcher"),
+	}
+}
+
+// OnReload registers handler which is called with new configuration after successful reload
+func (w *Watcher) OnReload(handler func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// Reload reads configuration file and notifies handlers if it was changed
+// Invalid configuration is rejected and previous one stays active
+func (w *Watcher) Reload() error {
+	ll := w.log.WithField("method", "Reload")
+	cfg, err := Load(w.path)
+	if err != nil {
+		ll.Errorf("Configuration is rejected, previous one is used: %v", err)
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if reflect.DeepEqual(cfg, w.current) {
+		return nil
+	}
+	if cfg.restartRequired(w.current) {
+		ll.Warn("Parameters which are applied on start are changed, restart pod to apply them")
+	}
+	w.current = cfg
+	for _, handler := range w.handlers {
+		handler(cfg)
+	}
+	ll.Infof("Configuration version %d is reloaded", cfg.Version)
+	return nil
+}
+
+// Run reloads configuration on changes of file and SIGHUP until context is done
+// Directory of file is watched, because ConfigMap volumes are updated by replacement of symlink
+func (w *Watcher) Run(ctx context.Context) error {
+	ll := w.log.WithField("method", "Run")
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer watcher.Close()
+	if err = watcher.Add(filepath.Dir(w.path)); err != nil {
+		return err
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			ll.Info("Got SIGHUP signal")
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			ll.Debugf("File event %s", event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			ll.Errorf("File watcher error: %v", err)
+			continue
+		}
+		// error is logged by Reload
+		_ = w.Reload()
+	}
+}
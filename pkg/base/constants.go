@@ -39,8 +39,10 @@ const (
 	// DefaultExtenderPort is the default http port for scheduler extender
 	DefaultExtenderPort = 8889
 
+	// KubeletRootDir is the default root directory of kubelet
+	KubeletRootDir = "/var/lib/kubelet"
 	// KubeletRootPath is the pods' path on the node
-	KubeletRootPath = "/var/lib/kubelet/pods"
+	KubeletRootPath = KubeletRootDir + "/pods"
 
 	// HostRootPath is root mount
 	HostRootPath = "/hostroot"
@@ -0,0 +1,122 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ctrlopts contains code for configuring reconciliation of CR controllers:
+// reconcile concurrency, work queue backoff and resync interval
+package ctrlopts
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+// Prefixes of environment variables for controllers
+const (
+	VolumeControllerPrefix   = "VOLUME_CONTROLLER"
+	DriveControllerPrefix    = "DRIVE_CONTROLLER"
+	LVGControllerPrefix      = "LVG_CONTROLLER"
+	CapacityControllerPrefix = "CAPACITY_CONTROLLER"
+)
+
+const (
+	// MaxConcurrentReconcilesEnvSuffix - suffix of env variable with amount of simultaneous reconciles
+	MaxConcurrentReconcilesEnvSuffix = "_MAX_CONCURRENT_RECONCILES"
+	// BaseDelayEnvSuffix - suffix of env variable with initial delay of failed requests requeue
+	BaseDelayEnvSuffix = "_BASE_DELAY"
+	// MaxDelayEnvSuffix - suffix of env variable with max delay of failed requests requeue
+	MaxDelayEnvSuffix = "_MAX_DELAY"
+	// ResyncIntervalEnv - env variable with interval of full resync of all watched CRs, common for controller manager
+	ResyncIntervalEnv = "RESYNC_INTERVAL"
+)
+
+// Environment variables with parameters of fast-slow rate limiter of reservation controller
+const (
+	ReservationFastDelayEnv       = "RESERVATION_FAST_DELAY"
+	ReservationSlowDelayEnv       = "RESERVATION_SLOW_DELAY"
+	ReservationMaxFastAttemptsEnv = "RESERVATION_MAX_FAST_ATTEMPTS"
+)
+
+// Options contains reconciliation parameters of controller, zero values mean controller-runtime defaults
+type Options struct {
+	MaxConcurrentReconciles int
+	BaseDelay               time.Duration
+	MaxDelay                time.Duration
+}
+
+// FromEnv reads Options from environment variables with provided prefix, e.g. VOLUME_CONTROLLER_MAX_DELAY.
+// Values which are not set or not parsable are taken from defaults
+func FromEnv(prefix string, defaults Options, log *logrus.Entry) Options {
+	opts := defaults
+	if value, ok := os.LookupEnv(prefix + MaxConcurrentReconcilesEnvSuffix); ok {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			opts.MaxConcurrentReconciles = n
+		} else {
+			log.Errorf("passed %s%s parameter %s is not a positive number, used default - %d",
+				prefix, MaxConcurrentReconcilesEnvSuffix, value, defaults.MaxConcurrentReconciles)
+		}
+	}
+	opts.BaseDelay = durationFromEnv(prefix+BaseDelayEnvSuffix, defaults.BaseDelay, log)
+	opts.MaxDelay = durationFromEnv(prefix+MaxDelayEnvSuffix, defaults.MaxDelay, log)
+	if opts.MaxDelay < opts.BaseDelay {
+		log.Errorf("%s max delay %s is less than base delay %s, used base delay", prefix, opts.MaxDelay, opts.BaseDelay)
+		opts.MaxDelay = opts.BaseDelay
+	}
+	log.Infof("%s parameters: maxConcurrentReconciles - %d, baseDelay - %s, maxDelay - %s",
+		prefix, opts.MaxConcurrentReconciles, opts.BaseDelay, opts.MaxDelay)
+	return opts
+}
+
+// ControllerOptions converts Options to controller-runtime controller.Options
+func (o Options) ControllerOptions() controller.Options {
+	opts := controller.Options{MaxConcurrentReconciles: o.MaxConcurrentReconciles}
+	if o.BaseDelay > 0 {
+		opts.RateLimiter = workqueue.NewItemExponentialFailureRateLimiter(o.BaseDelay, o.MaxDelay)
+	}
+	return opts
+}
+
+// ResyncInterval reads interval of full resync from environment variable
+// Returns nil if variable is not set or not parsable, controller-runtime default is used in this case
+func ResyncInterval(log *logrus.Entry) *time.Duration {
+	value, ok := os.LookupEnv(ResyncIntervalEnv)
+	if !ok {
+		return nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		log.Errorf("passed %s parameter %s is not a positive duration, used default", ResyncIntervalEnv, value)
+		return nil
+	}
+	return &interval
+}
+
+func durationFromEnv(env string, def time.Duration, log *logrus.Entry) time.Duration {
+	value, ok := os.LookupEnv(env)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Errorf("passed %s parameter %s is not parsable as time.Duration, used default - %s", env, value, def)
+		return def
+	}
+	return d
+}
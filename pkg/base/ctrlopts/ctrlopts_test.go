@@ -0,0 +1,68 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ctrlopts
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLog = logrus.New().WithField("component", "test")
+
+func TestFromEnv(t *testing.T) {
+	defaults := Options{MaxConcurrentReconciles: 15}
+
+	opts := FromEnv(VolumeControllerPrefix, defaults, testLog)
+	assert.Equal(t, defaults, opts)
+	assert.Nil(t, opts.ControllerOptions().RateLimiter)
+
+	assert.Nil(t, os.Setenv("VOLUME_CONTROLLER_MAX_CONCURRENT_RECONCILES", "30"))
+	assert.Nil(t, os.Setenv("VOLUME_CONTROLLER_BASE_DELAY", "100ms"))
+	assert.Nil(t, os.Setenv("VOLUME_CONTROLLER_MAX_DELAY", "1m"))
+	defer func() {
+		_ = os.Unsetenv("VOLUME_CONTROLLER_MAX_CONCURRENT_RECONCILES")
+		_ = os.Unsetenv("VOLUME_CONTROLLER_BASE_DELAY")
+		_ = os.Unsetenv("VOLUME_CONTROLLER_MAX_DELAY")
+	}()
+	opts = FromEnv(VolumeControllerPrefix, defaults, testLog)
+	assert.Equal(t, Options{MaxConcurrentReconciles: 30, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Minute}, opts)
+	ctrlOpts := opts.ControllerOptions()
+	assert.Equal(t, 30, ctrlOpts.MaxConcurrentReconciles)
+	assert.NotNil(t, ctrlOpts.RateLimiter)
+
+	// wrong values
+	assert.Nil(t, os.Setenv("VOLUME_CONTROLLER_MAX_CONCURRENT_RECONCILES", "-1"))
+	assert.Nil(t, os.Setenv("VOLUME_CONTROLLER_MAX_DELAY", "10ms"))
+	opts = FromEnv(VolumeControllerPrefix, defaults, testLog)
+	assert.Equal(t, 15, opts.MaxConcurrentReconciles)
+	assert.Equal(t, opts.BaseDelay, opts.MaxDelay)
+}
+
+func TestResyncInterval(t *testing.T) {
+	assert.Nil(t, ResyncInterval(testLog))
+
+	assert.Nil(t, os.Setenv(ResyncIntervalEnv, "wrong"))
+	defer func() { _ = os.Unsetenv(ResyncIntervalEnv) }()
+	assert.Nil(t, ResyncInterval(testLog))
+
+	assert.Nil(t, os.Setenv(ResyncIntervalEnv, "30m"))
+	assert.Equal(t, 30*time.Minute, *ResyncInterval(testLog))
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"fmt"
+	"net/http"
+	"text/tabwriter"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// StatusPath is a default path of status page
+const StatusPath = "/statusz"
+
+// Handler returns handler of status page of DefaultRegistry
+func Handler() http.Handler {
+	return DefaultRegistry
+}
+
+// MetricsHandler returns handler which exposes metrics of default prometheus registry
+// and metrics of controller-runtime, including depth of work queues
+func MetricsHandler() http.Handler {
+	// both registries contain go and process collectors, duplicates of controller-runtime registry are skipped
+	gatherers := prometheus.Gatherers{prometheus.DefaultGatherer, ctrlmetrics.Registry}
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError}))
+}
+
+// ServeHTTP writes state of reconcilers and locks which are held as plain text
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	now := r.now()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RECONCILER\tHEALTH\tQUEUE\tIN FLIGHT\tLONGEST RUNNING\tLAST SUCCESS\tLAST ERROR")
+	for _, s := range r.Reconcilers() {
+		queue := "-"
+		if s.QueueDepth >= 0 {
+			queue = fmt.Sprint(s.QueueDepth)
+		}
+		lastError := since(now, s.LastError)
+		if s.LastErrorMessage != "" {
+			lastError += ": " + s.LastErrorMessage
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", s.Name, s.Health, queue, s.InFlight,
+			s.LongestRunning.Round(time.Second), since(now, s.LastSuccess), lastError)
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MUTEX\tKEY\tHELD FOR")
+	for _, l := range r.Locks() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", l.Mutex, l.Key, l.HeldFor.Round(time.Second))
+	}
+	_ = tw.Flush()
+}
+
+// since returns time passed from t in human readable form, "never" if t is zero
+func since(now, t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return now.Sub(t).Round(time.Second).String() + " ago"
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/keymutex"
+)
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r, clock, depth := newTestRegistry()
+	m := r.NewKeyMutex("volumes", keymutex.NewHashed(0))
+	r.Observe("volume")(nil)
+	r.Observe("drive")(errors.New("drive is offline"))
+	depth.WithLabelValues("volume").Set(2)
+	m.LockKey("pvc-1")
+	clock.now = clock.now.Add(time.Minute)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, StatusPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	assert.Len(t, lines, 6)
+	assert.Equal(t, []string{"drive", "failing", "-", "0", "0s", "never", "1m0s", "ago:", "drive", "is", "offline"},
+		strings.Fields(lines[1]))
+	assert.Equal(t, []string{"volume", "ok", "2", "0", "0s", "1m0s", "ago", "never"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"volumes", "pvc-1", "1m0s"}, strings.Fields(lines[5]))
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/utils/keymutex"
+)
+
+// KeyMutex is a keymutex.KeyMutex which tracks keys which are locked
+type KeyMutex struct {
+	name  string
+	inner keymutex.KeyMutex
+	mu    sync.Mutex
+	// key -> time when it was locked
+	locked map[string]time.Time
+	now    func() time.Time
+}
+
+// NewKeyMutex wraps key mutex for tracking its locks in DefaultRegistry
+// Mutex with the same name which was registered before is replaced
+func NewKeyMutex(name string, inner keymutex.KeyMutex) keymutex.KeyMutex {
+	return DefaultRegistry.NewKeyMutex(name, inner)
+}
+
+// NewKeyMutex wraps key mutex for tracking its locks in registry
+func (r *Registry) NewKeyMutex(name string, inner keymutex.KeyMutex) *KeyMutex {
+	m := &KeyMutex{name: name, inner: inner, locked: make(map[string]time.Time), now: r.now}
+	r.registerMutex(m)
+	return m
+}
+
+// LockKey implements keymutex.KeyMutex
+func (m *KeyMutex) LockKey(id string) {
+	m.inner.LockKey(id)
+	m.mu.Lock()
+	m.locked[id] = m.now()
+	m.mu.Unlock()
+}
+
+// UnlockKey implements keymutex.KeyMutex
+func (m *KeyMutex) UnlockKey(id string) error {
+	m.mu.Lock()
+	delete(m.locked, id)
+	m.mu.Unlock()
+	return m.inner.UnlockKey(id)
+}
+
+// held returns keys which are locked with time when they were locked
+func (m *KeyMutex) held() map[string]time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make(map[string]time.Time, len(m.locked))
+	for key, since := range m.locked {
+		result[key] = since
+	}
+	return result
+}
@@ -0,0 +1,205 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+const (
+	// GoroutinesResource is a name of resource which is a count of goroutines
+	GoroutinesResource = "goroutines"
+	// minLeakBaseline is a minimal baseline of growth, so growth of small counts, e.g. from 0 to 5 objects,
+	// isn't reported
+	minLeakBaseline = 100
+	// restartChecks is an amount of consecutive checks above restart threshold after which service is restarted,
+	// short spikes don't cause restart
+	restartChecks = 3
+)
+
+// LeakGuardConfig contains thresholds of LeakGuard, zero value disables threshold
+type LeakGuardConfig struct {
+	// WarningGrowth is a ratio of current to baseline count of goroutines or objects in informer cache
+	// which triggers warning event
+	WarningGrowth float64
+	// GoroutineRestartThreshold is a count of goroutines which triggers restart
+	GoroutineRestartThreshold int64
+	// HeapRestartThreshold is a size of heap in use in bytes which triggers restart
+	HeapRestartThreshold uint64
+}
+
+// eventRecorder is an interface of events.Recorder which is used by LeakGuard
+type eventRecorder interface {
+	Eventf(object k8sRuntime.Object, event *eventing.EventDescription, messageFmt string, args ...interface{})
+}
+
+// LeakGuard tracks growth of goroutines and objects in informer cache of long-running watchers.
+// Growth over baseline, which is measured on the first check, is reported with warning event once until count
+// goes back. Service is restarted by SIGTERM, so it is shut down gracefully, when count of goroutines or heap
+// stays above restart threshold
+type LeakGuard struct {
+	config       LeakGuardConfig
+	cacheObjects func() map[string]int64
+	recorder     eventRecorder
+	object       k8sRuntime.Object
+	log          *logrus.Entry
+
+	baseline   map[string]int64
+	warned     map[string]bool
+	exceeded   int
+	restarting bool
+	cacheSize  *prometheus.GaugeVec
+
+	numGoroutine func() int
+	heapInUse    func() uint64
+	restart      func()
+}
+
+// NewLeakGuard is a constructor for LeakGuard
+// Receives thresholds, function which returns counts of objects in informer cache by kind, e.g. KubeCache.ObjectCounts,
+// events recorder and object which events are recorded for, events aren't recorded if object is nil
+func NewLeakGuard(config LeakGuardConfig, cacheObjects func() map[string]int64, recorder eventRecorder,
+	object k8sRuntime.Object, logger *logrus.Logger) *LeakGuard {
+	g := &LeakGuard{
+		config:       config,
+		cacheObjects: cacheObjects,
+		recorder:     recorder,
+		object:       object,
+		log:          logger.WithField("component", "LeakGuard"),
+		warned:       map[string]bool{},
+		cacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "informer_cache_objects",
+			Help: "Count of objects in informer cache by kind",
+		}, []string{"kind"}),
+		numGoroutine: runtime.NumGoroutine,
+		heapInUse: func() uint64 {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			return stats.HeapInuse
+		},
+		restart: func() {
+			_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		},
+	}
+	if err := prometheus.Register(g.cacheSize); err != nil {
+		g.log.Errorf("Unable to register metric: %v", err)
+	}
+	return g
+}
+
+// Run checks resources with interval until context is done
+func (g *LeakGuard) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.Check()
+		}
+	}
+}
+
+// Check compares counts of goroutines and objects in informer cache with baseline and restart thresholds
+func (g *LeakGuard) Check() {
+	ll := g.log.WithField("method", "Check")
+
+	counts := map[string]int64{GoroutinesResource: int64(g.numGoroutine())}
+	if g.cacheObjects != nil {
+		for kind, count := range g.cacheObjects() {
+			g.cacheSize.WithLabelValues(kind).Set(float64(count))
+			counts[kind] = count
+		}
+	}
+	if g.baseline == nil {
+		g.baseline = counts
+		ll.Infof("Baseline of resources: %v", counts)
+		return
+	}
+
+	if g.config.WarningGrowth > 0 {
+		resources := make([]string, 0, len(counts))
+		for resource := range counts {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+		for _, resource := range resources {
+			g.checkGrowth(resource, counts[resource])
+		}
+	}
+
+	var reasons []string
+	if g.config.GoroutineRestartThreshold > 0 && counts[GoroutinesResource] > g.config.GoroutineRestartThreshold {
+		reasons = append(reasons, fmt.Sprintf("%d goroutines exceed threshold %d",
+			counts[GoroutinesResource], g.config.GoroutineRestartThreshold))
+	}
+	if g.config.HeapRestartThreshold > 0 {
+		if heap := g.heapInUse(); heap > g.config.HeapRestartThreshold {
+			reasons = append(reasons, fmt.Sprintf("heap in use %d bytes exceeds threshold %d bytes",
+				heap, g.config.HeapRestartThreshold))
+		}
+	}
+	if len(reasons) == 0 {
+		g.exceeded = 0
+		return
+	}
+	g.exceeded++
+	ll.Warnf("Resource usage is above restart threshold on %d consecutive checks: %v", g.exceeded, reasons)
+	if g.exceeded < restartChecks || g.restarting {
+		return
+	}
+	g.restarting = true
+	ll.Errorf("Restarting service because of resource leak: %v", reasons)
+	g.event(eventing.RestartOnResourceLeak, "Service is restarted because of resource leak: %v", reasons)
+	g.restart()
+}
+
+// checkGrowth reports growth of resource over baseline once until count goes back below warning threshold
+func (g *LeakGuard) checkGrowth(resource string, count int64) {
+	baseline := g.baseline[resource]
+	if baseline < minLeakBaseline {
+		baseline = minLeakBaseline
+	}
+	grown := float64(count) >= float64(baseline)*g.config.WarningGrowth
+	if grown && !g.warned[resource] {
+		g.log.WithField("method", "checkGrowth").Warnf("Count of %s grew from %d to %d",
+			resource, g.baseline[resource], count)
+		g.event(eventing.ResourceGrowthDetected, "Count of %s grew from %d to %d since start, it may be a leak",
+			resource, g.baseline[resource], count)
+	}
+	g.warned[resource] = grown
+}
+
+// event records event for object if it's set
+func (g *LeakGuard) event(event *eventing.EventDescription, messageFmt string, args ...interface{}) {
+	if g.object != nil && g.recorder != nil {
+		g.recorder.Eventf(g.object, event, messageFmt, args...)
+	}
+}
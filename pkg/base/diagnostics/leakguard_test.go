@@ -0,0 +1,91 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestLeakGuard_Check(t *testing.T) {
+	var (
+		recorder   = new(mocks.NoOpRecorder)
+		goroutines = 150
+		heap       = uint64(100)
+		volumes    = int64(20)
+		restarts   = 0
+		config     = LeakGuardConfig{WarningGrowth: 2, GoroutineRestartThreshold: 1000, HeapRestartThreshold: 1000}
+		g          = NewLeakGuard(config, func() map[string]int64 { return map[string]int64{"Volume": volumes} },
+			recorder, &corev1.Pod{}, logrus.New())
+		countEvents = func(event *eventing.EventDescription) int {
+			count := 0
+			for _, c := range recorder.Calls {
+				if c.Event == event {
+					count++
+				}
+			}
+			return count
+		}
+	)
+	g.numGoroutine = func() int { return goroutines }
+	g.heapInUse = func() uint64 { return heap }
+	g.restart = func() { restarts++ }
+
+	// baseline
+	g.Check()
+	assert.Empty(t, recorder.Calls)
+
+	// growth of small count below minimal baseline isn't reported
+	volumes = 150
+	g.Check()
+	assert.Empty(t, recorder.Calls)
+
+	// growth is reported once
+	goroutines, volumes = 300, 200
+	g.Check()
+	g.Check()
+	assert.Equal(t, 2, countEvents(eventing.ResourceGrowthDetected))
+
+	// growth is reported again after count goes back
+	goroutines = 150
+	g.Check()
+	goroutines = 300
+	g.Check()
+	assert.Equal(t, 3, countEvents(eventing.ResourceGrowthDetected))
+
+	// spike doesn't cause restart
+	heap = 2000
+	g.Check()
+	g.Check()
+	heap = 100
+	g.Check()
+	assert.Equal(t, 0, restarts)
+
+	// service is restarted once when threshold is exceeded on consecutive checks
+	goroutines = 2000
+	for i := 0; i < restartChecks+1; i++ {
+		g.Check()
+	}
+	assert.Equal(t, 1, restarts)
+	assert.Equal(t, 1, countEvents(eventing.RestartOnResourceLeak))
+}
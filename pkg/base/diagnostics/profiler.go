@@ -0,0 +1,221 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PprofPath is a path prefix of profiling endpoints, e.g. /debug/pprof/heap
+	PprofPath = "/debug/pprof/"
+	// DefaultSnapshotHistory is an amount of snapshots of each profile which are kept
+	DefaultSnapshotHistory = 12
+	// maxCPUProfileDuration limits duration of CPU profile which is requested with seconds parameter
+	maxCPUProfileDuration = 5 * time.Minute
+)
+
+// snapshotProfiles are profiles which are written periodically, they show memory growth and leaked goroutines
+var snapshotProfiles = []string{"heap", "goroutine"}
+
+// Profiler serves pprof endpoints and writes periodic snapshots of heap and goroutine profiles while it's enabled.
+// net/http/pprof isn't used because it registers handlers in http.DefaultServeMux unconditionally
+type Profiler struct {
+	enabled int32
+	dir     string
+	history int
+	log     *logrus.Entry
+}
+
+// NewProfiler is a constructor for Profiler which is disabled
+// Receives directory of snapshots and amount of snapshots of each profile to keep
+func NewProfiler(dir string, history int, logger *logrus.Logger) *Profiler {
+	return &Profiler{
+		dir:     dir,
+		history: history,
+		log:     logger.WithField("component", "Profiler"),
+	}
+}
+
+// SetEnabled enables or disables pprof endpoints and snapshots, it may be called at any time
+func (p *Profiler) SetEnabled(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	if atomic.SwapInt32(&p.enabled, value) != value {
+		p.log.WithField("method", "SetEnabled").Infof("Profiling enabled: %v", enabled)
+	}
+}
+
+// Enabled returns whether profiling is enabled
+func (p *Profiler) Enabled() bool {
+	return atomic.LoadInt32(&p.enabled) == 1
+}
+
+// ServeHTTP serves profiles in format of go tool pprof, e.g. /debug/pprof/heap or /debug/pprof/profile?seconds=30,
+// index of profiles is served on PprofPath. Not found is returned while profiling is disabled
+func (p *Profiler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !p.Enabled() {
+		http.NotFound(w, r)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, PprofPath)
+	switch name {
+	case "":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, profile := range pprof.Profiles() {
+			fmt.Fprintf(w, "%s\t%d\n", profile.Name(), profile.Count())
+		}
+		fmt.Fprintln(w, "profile\tCPU profile, duration is set with seconds parameter")
+	case "profile":
+		p.serveCPUProfile(w, r)
+	default:
+		profile := pprof.Lookup(name)
+		if profile == nil {
+			http.NotFound(w, r)
+			return
+		}
+		debug, _ := strconv.Atoi(r.FormValue("debug"))
+		if name == "heap" && r.FormValue("gc") != "" {
+			runtime.GC()
+		}
+		if debug > 0 {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		}
+		if err := profile.WriteTo(w, debug); err != nil {
+			p.log.WithField("method", "ServeHTTP").Errorf("Unable to write %s profile: %v", name, err)
+		}
+	}
+}
+
+// serveCPUProfile writes CPU profile for duration from seconds parameter, 30 seconds by default
+func (p *Profiler) serveCPUProfile(w http.ResponseWriter, r *http.Request) {
+	duration := 30 * time.Second
+	if seconds, err := strconv.Atoi(r.FormValue("seconds")); err == nil && seconds > 0 {
+		duration = time.Duration(seconds) * time.Second
+	}
+	if duration > maxCPUProfileDuration {
+		duration = maxCPUProfileDuration
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := pprof.StartCPUProfile(w); err != nil {
+		// only one CPU profile may be collected at a time
+		w.Header().Del("Content-Disposition")
+		http.Error(w, fmt.Sprintf("unable to start CPU profile: %v", err), http.StatusConflict)
+		return
+	}
+	select {
+	case <-time.After(duration):
+	case <-r.Context().Done():
+	}
+	pprof.StopCPUProfile()
+}
+
+// Snapshot writes heap and goroutine profiles to directory of snapshots, the oldest snapshots are removed
+// Returns error if profile can't be written
+func (p *Profiler) Snapshot(now time.Time) error {
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return err
+	}
+	for _, name := range snapshotProfiles {
+		path := filepath.Join(p.dir, fmt.Sprintf("%s-%s.pb.gz", name, now.UTC().Format("20060102T150405Z")))
+		if err := writeProfile(name, path); err != nil {
+			return err
+		}
+		if err := p.rotate(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run writes snapshots with interval while profiling is enabled until context is done
+func (p *Profiler) Run(ctx context.Context, interval time.Duration) {
+	ll := p.log.WithField("method", "Run")
+	ll.Infof("Snapshots of profiles are written to %s every %s while profiling is enabled", p.dir, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if !p.Enabled() {
+				continue
+			}
+			if err := p.Snapshot(now); err != nil {
+				ll.Errorf("Unable to write snapshot of profiles: %v", err)
+			}
+		}
+	}
+}
+
+// rotate removes the oldest snapshots of profile which exceed history
+func (p *Profiler) rotate(name string) error {
+	files, err := ioutil.ReadDir(p.dir)
+	if err != nil {
+		return err
+	}
+	var snapshots []string
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), name+"-") && strings.HasSuffix(f.Name(), ".pb.gz") {
+			snapshots = append(snapshots, f.Name())
+		}
+	}
+	// timestamps in names are sorted lexicographically
+	sort.Strings(snapshots)
+	for len(snapshots) > p.history {
+		if err := os.Remove(filepath.Join(p.dir, snapshots[0])); err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// writeProfile writes profile with name to file in binary format
+func writeProfile(name, path string) (err error) {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return pprof.Lookup(name).WriteTo(f, 0)
+}
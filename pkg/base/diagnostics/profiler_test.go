@@ -0,0 +1,81 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiler_ServeHTTP(t *testing.T) {
+	p := NewProfiler(t.TempDir(), DefaultSnapshotHistory, logrus.New())
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// disabled by default
+	assert.Equal(t, http.StatusNotFound, get(PprofPath).Code)
+	assert.Equal(t, http.StatusNotFound, get(PprofPath+"heap").Code)
+
+	p.SetEnabled(true)
+	rec := get(PprofPath)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "goroutine")
+	rec = get(PprofPath + "heap?gc=1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, rec.Body.Bytes())
+	rec = get(PprofPath + "goroutine?debug=1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "TestProfiler_ServeHTTP")
+	rec = get(PprofPath + "profile?seconds=1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, rec.Body.Bytes())
+	assert.Equal(t, http.StatusNotFound, get(PprofPath+"unknown").Code)
+
+	p.SetEnabled(false)
+	assert.Equal(t, http.StatusNotFound, get(PprofPath+"heap").Code)
+}
+
+func TestProfiler_Snapshot(t *testing.T) {
+	var (
+		dir = filepath.Join(t.TempDir(), "profiles")
+		p   = NewProfiler(dir, 2, logrus.New())
+		now = time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, p.Snapshot(now.Add(time.Duration(i)*time.Minute)))
+	}
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	assert.Equal(t, []string{
+		"goroutine-20210601T100100Z.pb.gz", "goroutine-20210601T100200Z.pb.gz",
+		"heap-20210601T100100Z.pb.gz", "heap-20210601T100200Z.pb.gz",
+	}, names)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// trackedReconciler records each reconcile of wrapped reconciler in registry
+type trackedReconciler struct {
+	name       string
+	reconciler reconcile.Reconciler
+	registry   *Registry
+}
+
+// TrackReconciler wraps reconciler for recording its reconciles in DefaultRegistry
+// Name must be equal to name of controller (lower-cased kind by default) to match depth of its work queue
+func TrackReconciler(name string, r reconcile.Reconciler) reconcile.Reconciler {
+	return DefaultRegistry.TrackReconciler(name, r)
+}
+
+// TrackReconciler wraps reconciler for recording its reconciles in registry
+func (r *Registry) TrackReconciler(name string, reconciler reconcile.Reconciler) reconcile.Reconciler {
+	r.mu.Lock()
+	r.reconciler(name)
+	r.mu.Unlock()
+	return &trackedReconciler{name: name, reconciler: reconciler, registry: r}
+}
+
+// Reconcile implements reconcile.Reconciler, requeue is considered as success
+func (t *trackedReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	done := t.registry.Observe(t.name)
+	res, err := t.reconciler.Reconcile(ctx, req)
+	done(err)
+	return res, err
+}
@@ -0,0 +1,334 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diagnostics tracks health of reconcilers and periodic loops and key mutexes which are held,
+// so subsystem which is stuck is visible in /metrics and /statusz pages during incidents
+package diagnostics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Health states of reconciler
+const (
+	// HealthOK means that the last reconcile succeeded
+	HealthOK = "ok"
+	// HealthIdle means that reconciler didn't finish any reconcile yet
+	HealthIdle = "idle"
+	// HealthFailing means that the last reconcile failed
+	HealthFailing = "failing"
+	// HealthStalled means that reconcile runs longer than StallTimeout or queue isn't processed during StallTimeout
+	HealthStalled = "stalled"
+)
+
+const (
+	// DefaultStallTimeout is a default duration after which reconciler is considered stalled
+	DefaultStallTimeout = 5 * time.Minute
+	// queueDepthMetric is a name of controller-runtime metric with depth of work queue labeled by controller name
+	queueDepthMetric = "workqueue_depth"
+)
+
+// DefaultRegistry is a registry which is used by TrackReconciler, Observe and NewKeyMutex
+var DefaultRegistry = NewRegistry(ctrlmetrics.Registry)
+
+// ReconcilerStatus is a state of reconciler or periodic loop
+type ReconcilerStatus struct {
+	Name string
+	// QueueDepth is an amount of requests in work queue of controller, -1 if reconciler has no work queue
+	QueueDepth int64
+	// InFlight is an amount of reconciles which are running now
+	InFlight int
+	// LongestRunning is a duration of the oldest running reconcile
+	LongestRunning time.Duration
+	LastSuccess    time.Time
+	LastError      time.Time
+	// LastErrorMessage is an error of the last failed reconcile
+	LastErrorMessage string
+	Successes        uint64
+	Errors           uint64
+	Health           string
+}
+
+// LockStatus is a key of key mutex which is held
+type LockStatus struct {
+	Mutex   string
+	Key     string
+	HeldFor time.Duration
+}
+
+// reconcilerState holds statistics of reconciler
+type reconcilerState struct {
+	// ID of running reconcile -> its start
+	running     map[uint64]time.Time
+	lastSuccess time.Time
+	lastError   time.Time
+	lastErr     string
+	successes   uint64
+	errors      uint64
+}
+
+// Registry holds states of reconcilers and key mutexes
+type Registry struct {
+	mu          sync.Mutex
+	reconcilers map[string]*reconcilerState
+	mutexes     map[string]*KeyMutex
+	nextID      uint64
+	// queues provides depth of work queues of controllers
+	queues prometheus.Gatherer
+	// StallTimeout is a duration after which reconciler is considered stalled
+	StallTimeout time.Duration
+	now          func() time.Time
+
+	lastSuccessDesc *prometheus.Desc
+	inFlightDesc    *prometheus.Desc
+	longestDesc     *prometheus.Desc
+	stalledDesc     *prometheus.Desc
+	locksDesc       *prometheus.Desc
+	lockHeldDesc    *prometheus.Desc
+}
+
+// NewRegistry is a constructor for Registry
+// Receives gatherer of controller-runtime metrics which provides depth of work queues, might be nil
+func NewRegistry(queues prometheus.Gatherer) *Registry {
+	return &Registry{
+		reconcilers:  make(map[string]*reconcilerState),
+		mutexes:      make(map[string]*KeyMutex),
+		queues:       queues,
+		StallTimeout: DefaultStallTimeout,
+		now:          time.Now,
+		lastSuccessDesc: prometheus.NewDesc("reconciler_last_success_timestamp_seconds",
+			"time of the last successful reconcile", []string{"reconciler"}, nil),
+		inFlightDesc: prometheus.NewDesc("reconciler_in_flight",
+			"number of reconciles which are running", []string{"reconciler"}, nil),
+		longestDesc: prometheus.NewDesc("reconciler_longest_running_seconds",
+			"duration of the oldest running reconcile", []string{"reconciler"}, nil),
+		stalledDesc: prometheus.NewDesc("reconciler_stalled",
+			"whether reconciler is stalled or not", []string{"reconciler"}, nil),
+		locksDesc: prometheus.NewDesc("key_mutex_locks_held",
+			"number of keys of mutex which are locked", []string{"mutex"}, nil),
+		lockHeldDesc: prometheus.NewDesc("key_mutex_longest_held_seconds",
+			"duration of the oldest lock of mutex", []string{"mutex"}, nil),
+	}
+}
+
+// Observe marks start of reconcile or iteration of periodic loop with name
+// Returns function which marks end of reconcile with its error
+func (r *Registry) Observe(name string) func(err error) {
+	r.mu.Lock()
+	state := r.reconciler(name)
+	r.nextID++
+	id := r.nextID
+	state.running[id] = r.now()
+	r.mu.Unlock()
+
+	return func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(state.running, id)
+		if err != nil {
+			state.lastError = r.now()
+			state.lastErr = err.Error()
+			state.errors++
+			return
+		}
+		state.lastSuccess = r.now()
+		state.successes++
+	}
+}
+
+// Observe marks start of reconcile with name in DefaultRegistry
+func Observe(name string) func(err error) {
+	return DefaultRegistry.Observe(name)
+}
+
+// reconciler returns state of reconciler with name, state is created if it doesn't exist
+// Caller must hold mu
+func (r *Registry) reconciler(name string) *reconcilerState {
+	state, ok := r.reconcilers[name]
+	if !ok {
+		state = &reconcilerState{running: make(map[uint64]time.Time)}
+		r.reconcilers[name] = state
+	}
+	return state
+}
+
+// registerMutex adds key mutex to registry, mutex with the same name is replaced
+func (r *Registry) registerMutex(m *KeyMutex) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mutexes[m.name] = m
+}
+
+// Reconcilers returns states of reconcilers sorted by name
+func (r *Registry) Reconcilers() []ReconcilerStatus {
+	depths := r.queueDepths()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	result := make([]ReconcilerStatus, 0, len(r.reconcilers))
+	for name, state := range r.reconcilers {
+		status := ReconcilerStatus{
+			Name:             name,
+			QueueDepth:       -1,
+			InFlight:         len(state.running),
+			LastSuccess:      state.lastSuccess,
+			LastError:        state.lastError,
+			LastErrorMessage: state.lastErr,
+			Successes:        state.successes,
+			Errors:           state.errors,
+		}
+		if depth, ok := depths[name]; ok {
+			status.QueueDepth = depth
+		}
+		for _, start := range state.running {
+			if running := now.Sub(start); running > status.LongestRunning {
+				status.LongestRunning = running
+			}
+		}
+		status.Health = r.health(&status, now)
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// health returns health state of reconciler
+func (r *Registry) health(status *ReconcilerStatus, now time.Time) string {
+	lastActivity := status.LastSuccess
+	if status.LastError.After(lastActivity) {
+		lastActivity = status.LastError
+	}
+	switch {
+	case status.LongestRunning > r.StallTimeout:
+		return HealthStalled
+	// requests wait in queue, but workers don't take them
+	case status.QueueDepth > 0 && status.InFlight == 0 && !lastActivity.IsZero() &&
+		now.Sub(lastActivity) > r.StallTimeout:
+		return HealthStalled
+	case lastActivity.IsZero():
+		return HealthIdle
+	case status.LastError.After(status.LastSuccess):
+		return HealthFailing
+	}
+	return HealthOK
+}
+
+// Locks returns keys of registered mutexes which are locked, sorted by mutex and key
+func (r *Registry) Locks() []LockStatus {
+	r.mu.Lock()
+	mutexes := make([]*KeyMutex, 0, len(r.mutexes))
+	for _, m := range r.mutexes {
+		mutexes = append(mutexes, m)
+	}
+	now := r.now()
+	r.mu.Unlock()
+
+	var result []LockStatus
+	for _, m := range mutexes {
+		for key, since := range m.held() {
+			result = append(result, LockStatus{Mutex: m.name, Key: key, HeldFor: now.Sub(since)})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Mutex != result[j].Mutex {
+			return result[i].Mutex < result[j].Mutex
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// queueDepths returns depths of work queues of controllers by controller name
+func (r *Registry) queueDepths() map[string]int64 {
+	result := make(map[string]int64)
+	if r.queues == nil {
+		return result
+	}
+	families, err := r.queues.Gather()
+	if err != nil {
+		return result
+	}
+	for _, family := range families {
+		if family.GetName() != queueDepthMetric {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "name" {
+					result[label.GetValue()] = int64(metric.GetGauge().GetValue())
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Describe implements prometheus.Collector
+func (r *Registry) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.lastSuccessDesc
+	ch <- r.inFlightDesc
+	ch <- r.longestDesc
+	ch <- r.stalledDesc
+	ch <- r.locksDesc
+	ch <- r.lockHeldDesc
+}
+
+// Collect implements prometheus.Collector, depth of work queues is exposed by controller-runtime
+func (r *Registry) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range r.Reconcilers() {
+		lastSuccess := float64(0)
+		if !s.LastSuccess.IsZero() {
+			lastSuccess = float64(s.LastSuccess.UnixNano()) / float64(time.Second)
+		}
+		stalled := float64(0)
+		if s.Health == HealthStalled {
+			stalled = 1
+		}
+		ch <- prometheus.MustNewConstMetric(r.lastSuccessDesc, prometheus.GaugeValue, lastSuccess, s.Name)
+		ch <- prometheus.MustNewConstMetric(r.inFlightDesc, prometheus.GaugeValue, float64(s.InFlight), s.Name)
+		ch <- prometheus.MustNewConstMetric(r.longestDesc, prometheus.GaugeValue, s.LongestRunning.Seconds(), s.Name)
+		ch <- prometheus.MustNewConstMetric(r.stalledDesc, prometheus.GaugeValue, stalled, s.Name)
+	}
+
+	var (
+		counts  = make(map[string]int)
+		longest = make(map[string]time.Duration)
+	)
+	r.mu.Lock()
+	for name := range r.mutexes {
+		counts[name] = 0
+		longest[name] = 0
+	}
+	r.mu.Unlock()
+	for _, l := range r.Locks() {
+		counts[l.Mutex]++
+		if l.HeldFor > longest[l.Mutex] {
+			longest[l.Mutex] = l.HeldFor
+		}
+	}
+	for name, count := range counts {
+		ch <- prometheus.MustNewConstMetric(r.locksDesc, prometheus.GaugeValue, float64(count), name)
+		ch <- prometheus.MustNewConstMetric(r.lockHeldDesc, prometheus.GaugeValue, longest[name].Seconds(), name)
+	}
+}
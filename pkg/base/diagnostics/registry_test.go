@@ -0,0 +1,127 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/keymutex"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestRegistry() (*Registry, *fakeClock, *prometheus.GaugeVec) {
+	var (
+		queues = prometheus.NewRegistry()
+		depth  = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: queueDepthMetric}, []string{"name"})
+		clock  = &fakeClock{now: time.Unix(1600000000, 0)}
+	)
+	queues.MustRegister(depth)
+	r := NewRegistry(queues)
+	r.now = clock.Now
+	return r, clock, depth
+}
+
+func TestRegistry_Reconcilers(t *testing.T) {
+	r, clock, depth := newTestRegistry()
+
+	tracked := r.TrackReconciler("volume", reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+		return ctrl.Result{}, nil
+	}))
+	statuses := r.Reconcilers()
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, HealthIdle, statuses[0].Health)
+	assert.Equal(t, int64(-1), statuses[0].QueueDepth)
+
+	_, err := tracked.Reconcile(context.Background(), ctrl.Request{})
+	assert.Nil(t, err)
+	depth.WithLabelValues("volume").Set(3)
+	statuses = r.Reconcilers()
+	assert.Equal(t, HealthOK, statuses[0].Health)
+	assert.Equal(t, int64(3), statuses[0].QueueDepth)
+	assert.Equal(t, clock.now, statuses[0].LastSuccess)
+	assert.Equal(t, uint64(1), statuses[0].Successes)
+
+	// queue isn't processed
+	clock.now = clock.now.Add(DefaultStallTimeout + time.Second)
+	assert.Equal(t, HealthStalled, r.Reconcilers()[0].Health)
+
+	// reconcile failed
+	done := r.Observe("volume")
+	assert.Equal(t, 1, r.Reconcilers()[0].InFlight)
+	done(errors.New("drive is offline"))
+	statuses = r.Reconcilers()
+	assert.Equal(t, HealthFailing, statuses[0].Health)
+	assert.Equal(t, "drive is offline", statuses[0].LastErrorMessage)
+	assert.Equal(t, 0, statuses[0].InFlight)
+
+	// reconcile hangs
+	done = r.Observe("discover")
+	clock.now = clock.now.Add(DefaultStallTimeout + time.Second)
+	statuses = r.Reconcilers()
+	assert.Equal(t, "discover", statuses[0].Name)
+	assert.Equal(t, HealthStalled, statuses[0].Health)
+	assert.Equal(t, DefaultStallTimeout+time.Second, statuses[0].LongestRunning)
+	done(nil)
+	assert.Equal(t, HealthOK, r.Reconcilers()[0].Health)
+}
+
+func TestRegistry_Locks(t *testing.T) {
+	r, clock, _ := newTestRegistry()
+	// different keys of hashed mutex might share the same lock
+	volumes := r.NewKeyMutex("volumes", keymutex.NewHashed(0))
+	drives := r.NewKeyMutex("drives", keymutex.NewHashed(0))
+	assert.Empty(t, r.Locks())
+
+	volumes.LockKey("pvc-1")
+	clock.now = clock.now.Add(time.Minute)
+	drives.LockKey("drive-1")
+	assert.Equal(t, []LockStatus{
+		{Mutex: "drives", Key: "drive-1"},
+		{Mutex: "volumes", Key: "pvc-1", HeldFor: time.Minute},
+	}, r.Locks())
+
+	assert.Nil(t, volumes.UnlockKey("pvc-1"))
+	assert.Nil(t, drives.UnlockKey("drive-1"))
+	assert.Empty(t, r.Locks())
+}
+
+func TestRegistry_Collect(t *testing.T) {
+	r, clock, _ := newTestRegistry()
+	m := r.NewKeyMutex("volumes", keymutex.NewHashed(0))
+	r.Observe("volume")(nil)
+	m.LockKey("pvc-1")
+	clock.now = clock.now.Add(time.Minute)
+
+	assert.Equal(t, 6, testutil.CollectAndCount(r))
+	assert.Equal(t, 1, testutil.CollectAndCount(r, "key_mutex_locks_held"))
+	assert.Equal(t, 1, testutil.CollectAndCount(r, "reconciler_last_success_timestamp_seconds"))
+}
@@ -25,6 +25,10 @@ const (
 	FeatureNodeIDFromAnnotation = "NodeIDFromAnnotation"
 	// FeatureExternalAnnotationForNode store name for ExternalAnnotationForNodeID feature
 	FeatureExternalAnnotationForNode = "ExternalAnnotationForNode"
+	// FeatureNamespaceQuota store name for NamespaceQuota feature
+	FeatureNamespaceQuota = "NamespaceQuota"
+	// FeatureDriveEvacuation store name for DriveEvacuation feature
+	FeatureDriveEvacuation = "DriveEvacuation"
 )
 
 // FeatureChecker is a "read" interface for FeatureConfig
@@ -57,11 +61,15 @@ type FeatureConfig struct {
 }
 
 // IsEnabled is implementation of FeatureChecker interface
+// Feature gates which aren't updated have default state of DefaultFeatureGates
 func (f *FeatureConfig) IsEnabled(name string) bool {
 	f.lock.RLock()
 	defer f.lock.RUnlock()
 	enabled, exist := f.features[name]
-	return exist && enabled
+	if !exist {
+		return DefaultFeatureGates[name].Default
+	}
+	return enabled
 }
 
 // List is implementation of FeatureChecker interface
@@ -0,0 +1,109 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureconfig
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Stage is a maturity level of feature
+type Stage string
+
+// Feature stages, Alpha features are disabled by default, Beta features are enabled by default,
+// GA features are always enabled
+const (
+	Alpha = Stage("Alpha")
+	Beta  = Stage("Beta")
+	GA    = Stage("GA")
+)
+
+// FeatureVolumeEncryption store name for VolumeEncryption feature gate
+const FeatureVolumeEncryption = "VolumeEncryption"
+
+// FeatureSpec describes feature gate
+type FeatureSpec struct {
+	Default bool
+	Stage   Stage
+}
+
+// DefaultFeatureGates contains known feature gates of experimental subsystems
+// Subsystem registers its gate here and checks it with FeatureChecker.IsEnabled
+var DefaultFeatureGates = map[string]FeatureSpec{
+	FeatureVolumeEncryption: {Default: true, Stage: Beta},
+}
+
+// ParseFeatureGates parses feature gates in format "Feature1=true,Feature2=false"
+// Returns error if feature gate is unknown, value isn't boolean or GA feature is disabled
+func ParseFeatureGates(str string) (map[string]bool, error) {
+	gates := make(map[string]bool)
+	for _, pair := range strings.Split(str, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.Split(pair, "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("wrong feature gate format %s, expected Feature=true|false", pair)
+		}
+		name := strings.TrimSpace(parts[0])
+		enabled, err := strconv.ParseBool(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("wrong value of feature gate %s: %v", name, err)
+		}
+		if err = ValidateFeatureGate(name, enabled); err != nil {
+			return nil, err
+		}
+		gates[name] = enabled
+	}
+	return gates, nil
+}
+
+// ValidateFeatureGate checks that feature gate is known and GA feature isn't disabled
+func ValidateFeatureGate(name string, enabled bool) error {
+	spec, ok := DefaultFeatureGates[name]
+	if !ok {
+		return fmt.Errorf("unknown feature gate %s", name)
+	}
+	if spec.Stage == GA && !enabled {
+		return fmt.Errorf("feature gate %s is GA and can't be disabled", name)
+	}
+	return nil
+}
+
+// SetFeatureGates enables or disables feature gates, gates which aren't passed keep their current state
+func (f *FeatureConfig) SetFeatureGates(gates map[string]bool) {
+	for name, enabled := range gates {
+		f.Update(name, enabled)
+	}
+}
+
+// FeatureGatesString returns state of all known feature gates, e.g. "VolumeEncryption=true (Beta)"
+func FeatureGatesString(checker FeatureChecker) string {
+	names := make([]string, 0, len(DefaultFeatureGates))
+	for name := range DefaultFeatureGates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	states := make([]string, 0, len(names))
+	for _, name := range names {
+		states = append(states, fmt.Sprintf("%s=%t (%s)", name, checker.IsEnabled(name), DefaultFeatureGates[name].Stage))
+	}
+	return strings.Join(states, ", ")
+}
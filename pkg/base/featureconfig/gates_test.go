@@ -0,0 +1,63 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featureconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFeatureGates(t *testing.T) {
+	defer func(gates map[string]FeatureSpec) { DefaultFeatureGates = gates }(DefaultFeatureGates)
+	DefaultFeatureGates = map[string]FeatureSpec{
+		"AlphaFeature": {Default: false, Stage: Alpha},
+		"GAFeature":    {Default: true, Stage: GA},
+	}
+
+	gates, err := ParseFeatureGates("AlphaFeature=true, GAFeature=true,")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"AlphaFeature": true, "GAFeature": true}, gates)
+
+	gates, err = ParseFeatureGates("")
+	assert.Nil(t, err)
+	assert.Empty(t, gates)
+
+	for _, str := range []string{"AlphaFeature", "AlphaFeature=yes", "UnknownFeature=true", "GAFeature=false"} {
+		_, err = ParseFeatureGates(str)
+		assert.NotNil(t, err, str)
+	}
+}
+
+func TestFeatureConfig_SetFeatureGates(t *testing.T) {
+	defer func(gates map[string]FeatureSpec) { DefaultFeatureGates = gates }(DefaultFeatureGates)
+	DefaultFeatureGates = map[string]FeatureSpec{
+		"AlphaFeature": {Default: false, Stage: Alpha},
+		"BetaFeature":  {Default: true, Stage: Beta},
+	}
+
+	conf := NewFeatureConfig()
+	// default state
+	assert.False(t, conf.IsEnabled("AlphaFeature"))
+	assert.True(t, conf.IsEnabled("BetaFeature"))
+	assert.Equal(t, "AlphaFeature=false (Alpha), BetaFeature=true (Beta)", FeatureGatesString(conf))
+
+	conf.SetFeatureGates(map[string]bool{"AlphaFeature": true, "BetaFeature": false})
+	assert.True(t, conf.IsEnabled("AlphaFeature"))
+	assert.False(t, conf.IsEnabled("BetaFeature"))
+	assert.Equal(t, "AlphaFeature=true (Alpha), BetaFeature=false (Beta)", FeatureGatesString(conf))
+}
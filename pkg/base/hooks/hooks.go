@@ -0,0 +1,164 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hooks runs site-specific executables at hook points of provisioning pipeline
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Hook points of provisioning pipeline
+const (
+	// PrePartition runs before partition or logical volume of volume is created
+	PrePartition = "pre-partition"
+	// PostMkfs runs after device and file system of volume are created
+	PostMkfs = "post-mkfs"
+	// PreMount runs before device of volume is mounted to staging path
+	PreMount = "pre-mount"
+)
+
+// PointEnv is environment variable with name of hook point
+const PointEnv = "CSI_HOOK"
+
+// maxOutputLen is max length of hook output which is added to error
+const maxOutputLen = 512
+
+// Runner runs executables from hooks directory, hook of point is named <point> or <point>.<suffix>,
+// e.g. pre-mount.10-scan, hooks of the same point run in lexical order
+type Runner struct {
+	dir     string
+	timeout time.Duration
+	log     *logrus.Entry
+}
+
+// NewRunner is the constructor for Runner
+// Receives directory with hooks, timeout of each hook and logrus logger
+func NewRunner(dir string, timeout time.Duration, logger *logrus.Logger) *Runner {
+	return &Runner{
+		dir:     dir,
+		timeout: timeout,
+		log:     logger.WithField("component", "HooksRunner"),
+	}
+}
+
+// Run runs hooks of point with provided environment variables in addition to environment of service
+// Returns error of the first failed hook, next hooks of point aren't run in this case
+func (r *Runner) Run(ctx context.Context, point string, env map[string]string) error {
+	ll := r.log.WithFields(logrus.Fields{
+		"method": "Run",
+		"hook":   point,
+	})
+	hooks, err := r.find(point)
+	if err != nil {
+		return err
+	}
+
+	vars := append(os.Environ(), PointEnv+"="+point)
+	for name, value := range env {
+		vars = append(vars, name+"="+value)
+	}
+	for _, hook := range hooks {
+		start := time.Now()
+		output, err := r.runHook(ctx, hook, vars)
+		if err != nil {
+			return fmt.Errorf("hook %s failed: %v, output: %s", filepath.Base(hook), err, truncate(output))
+		}
+		ll.Infof("Hook %s is finished in %s", filepath.Base(hook), time.Since(start))
+	}
+	return nil
+}
+
+// runHook runs hook with timeout and returns its combined output
+func (r *Runner) runHook(ctx context.Context, hook string, env []string) (string, error) {
+	var output bytes.Buffer
+	cmd := exec.Command(hook)
+	cmd.Env = env
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// hook runs in its own process group, which is killed on timeout together with child processes
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	var err error
+	select {
+	case err = <-done:
+		return output.String(), err
+	case <-timer.C:
+		err = fmt.Errorf("timeout %s expired", r.timeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	<-done
+	return output.String(), err
+}
+
+// find returns paths of executable hooks of point in lexical order
+func (r *Runner) find(point string) ([]string, error) {
+	entries, err := ioutil.ReadDir(r.dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read hooks directory: %v", err)
+	}
+	var hooks []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if name != point && !strings.HasPrefix(name, point+".") {
+			continue
+		}
+		path := filepath.Join(r.dir, name)
+		// files of ConfigMap volume are symlinks
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if info.Mode().Perm()&0111 == 0 {
+			r.log.WithField("method", "find").Warnf("Hook %s isn't executable, skip it", name)
+			continue
+		}
+		hooks = append(hooks, path)
+	}
+	sort.Strings(hooks)
+	return hooks, nil
+}
+
+func truncate(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxOutputLen {
+		return output[:maxOutputLen] + "..."
+	}
+	return output
+}
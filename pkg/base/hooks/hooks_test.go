@@ -0,0 +1,74 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeHook(t *testing.T, dir, name, script string) {
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755))
+}
+
+func TestRunner_Run(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		out    = filepath.Join(t.TempDir(), "out")
+		runner = NewRunner(dir, time.Second, logrus.New())
+	)
+	writeHook(t, dir, PrePartition+".20-second", "echo second $CSI_VOLUME_ID >> "+out)
+	writeHook(t, dir, PrePartition+".10-first", "echo first $CSI_HOOK >> "+out)
+	writeHook(t, dir, PreMount, "echo mount >> "+out)
+	// not executable
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, PrePartition+".30-disabled"), []byte("#!/bin/sh\nexit 1\n"), 0644))
+
+	assert.Nil(t, runner.Run(context.Background(), PrePartition, map[string]string{"CSI_VOLUME_ID": "pvc-1"}))
+	data, err := ioutil.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Equal(t, "first pre-partition\nsecond pvc-1\n", string(data))
+
+	// no hooks
+	assert.Nil(t, runner.Run(context.Background(), PostMkfs, nil))
+}
+
+func TestRunner_RunFailed(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		runner = NewRunner(dir, 100*time.Millisecond, logrus.New())
+	)
+	writeHook(t, dir, PrePartition, "echo volume is rejected; exit 1")
+	err := runner.Run(context.Background(), PrePartition, nil)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "volume is rejected"))
+
+	writeHook(t, dir, PreMount, "sleep 5")
+	err = runner.Run(context.Background(), PreMount, nil)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "timeout"))
+
+	// directory doesn't exist
+	runner = NewRunner(filepath.Join(dir, "missing"), time.Second, logrus.New())
+	assert.NotNil(t, runner.Run(context.Background(), PreMount, nil))
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	apisV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdV1 "github.com/dell/csi-baremetal/api/v1"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+)
+
+// setConditions derives status conditions of Drive, Volume, LogicalVolumeGroup and AvailableCapacity CR from its
+// spec, observed generation of changed conditions is set to generation of CR.
+// Returns whether conditions were changed, false is returned for CRs of other kinds
+func setConditions(obj k8sCl.Object) bool {
+	generation := obj.GetGeneration()
+	switch cr := obj.(type) {
+	case *drivecrd.Drive:
+		spec := &cr.Spec
+		var ready bool
+		reason := conditionReason(spec.Usage)
+		switch {
+		case spec.Status != crdV1.DriveStatusOnline:
+			reason = conditionReason(spec.Status)
+		case spec.Health != crdV1.HealthGood:
+			reason = "Unhealthy"
+		case spec.Usage == crdV1.DriveUsageInUse:
+			ready, reason = true, "Online"
+		}
+		message := fmt.Sprintf("drive is %s, health is %s, usage is %s", spec.Status, spec.Health, spec.Usage)
+		changed := setCondition(&cr.Status.Conditions, generation, crdV1.ConditionReady, ready, reason, message)
+		return setHealthyCondition(&cr.Status.Conditions, generation, spec.Health) || changed
+	case *volumecrd.Volume:
+		spec := &cr.Spec
+		ready := spec.CSIStatus == crdV1.Created || spec.CSIStatus == crdV1.VolumeReady ||
+			spec.CSIStatus == crdV1.Published
+		message := fmt.Sprintf("CSI status is %s, operational status is %s", spec.CSIStatus, spec.OperationalStatus)
+		changed := setCondition(&cr.Status.Conditions, generation, crdV1.ConditionReady, ready,
+			conditionReason(spec.CSIStatus), message)
+		return setHealthyCondition(&cr.Status.Conditions, generation, spec.Health) || changed
+	case *lvgcrd.LogicalVolumeGroup:
+		spec := &cr.Spec
+		changed := setCondition(&cr.Status.Conditions, generation, crdV1.ConditionReady, spec.Status == crdV1.Created,
+			conditionReason(spec.Status), fmt.Sprintf("status is %s", spec.Status))
+		return setHealthyCondition(&cr.Status.Conditions, generation, spec.Health) || changed
+	case *accrd.AvailableCapacity:
+		reason := crdV1.ConditionReasonCapacityAvailable
+		if cr.Spec.Size <= 0 {
+			reason = crdV1.ConditionReasonNoCapacity
+		}
+		return setCondition(&cr.Status.Conditions, generation, crdV1.ConditionReady, cr.Spec.Size > 0, reason,
+			fmt.Sprintf("%d bytes are available", cr.Spec.Size))
+	}
+	return false
+}
+
+// setHealthyCondition sets Healthy condition which is true if health is GOOD
+func setHealthyCondition(conditions *[]apisV1.Condition, generation int64, health string) bool {
+	return setCondition(conditions, generation, crdV1.ConditionHealthy, health == crdV1.HealthGood,
+		conditionReason(health), fmt.Sprintf("health is %s", health))
+}
+
+// setCondition sets condition of type, transition time is changed only if status of condition is changed.
+// Observed generation alone doesn't change condition, so status isn't written on each update of spec
+// Returns whether condition was changed
+func setCondition(conditions *[]apisV1.Condition, generation int64, conditionType string, isTrue bool,
+	reason, message string) bool {
+	status := apisV1.ConditionFalse
+	if isTrue {
+		status = apisV1.ConditionTrue
+	}
+	if c := meta.FindStatusCondition(*conditions, conditionType); c != nil && c.Status == status &&
+		c.Reason == reason && c.Message == message {
+		return false
+	}
+	// API server stores time with seconds precision
+	meta.SetStatusCondition(conditions, apisV1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: generation,
+		LastTransitionTime: apisV1.NewTime(time.Now().Truncate(time.Second)),
+		Reason:             reason,
+		Message:            message,
+	})
+	return true
+}
+
+// conditionReason converts status to CamelCase reason of condition, e.g. VOLUME_READY to VolumeReady
+func conditionReason(status string) string {
+	var reason strings.Builder
+	for _, word := range strings.Split(strings.ToLower(status), "_") {
+		if word != "" {
+			reason.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	if reason.Len() == 0 {
+		return "Unknown"
+	}
+	return reason.String()
+}
@@ -0,0 +1,140 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/logger/objects"
+)
+
+// statusErrClient is k8s client which fails status updates, e.g. when CRD has no status subresource
+type statusErrClient struct {
+	k8sCl.Client
+}
+
+func (c statusErrClient) Status() k8sCl.StatusWriter {
+	return statusErrWriter{}
+}
+
+type statusErrWriter struct {
+	k8sCl.StatusWriter
+}
+
+func (w statusErrWriter) Update(context.Context, k8sCl.Object, ...k8sCl.UpdateOption) error {
+	return errors.New("the server could not find the requested resource")
+}
+
+func TestKubeClient_Conditions(t *testing.T) {
+	k, err := GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+
+	var (
+		drive  = testDriveCR.DeepCopy()
+		volume = testVolumeCR.DeepCopy()
+		lvg    = testLVGCR.DeepCopy()
+		ac     = testACCR.DeepCopy()
+		read   = &drivecrd.Drive{}
+		// condition returns condition of type from conditions, it fails test if there is no condition
+		condition = func(conditions []k8smetav1.Condition, conditionType string) k8smetav1.Condition {
+			c := meta.FindStatusCondition(conditions, conditionType)
+			if !assert.NotNil(t, c, conditionType) {
+				return k8smetav1.Condition{}
+			}
+			return *c
+		}
+	)
+
+	// conditions are written to status on creation
+	drive.Spec.Usage = apiV1.DriveUsageInUse
+	assert.Nil(t, k.CreateCR(testCtx, drive.Name, drive))
+	assert.Nil(t, k.ReadCR(testCtx, drive.Name, "", read))
+	ready := condition(read.Status.Conditions, apiV1.ConditionReady)
+	assert.Equal(t, k8smetav1.ConditionTrue, ready.Status)
+	assert.Equal(t, "Online", ready.Reason)
+	healthy := condition(read.Status.Conditions, apiV1.ConditionHealthy)
+	assert.Equal(t, k8smetav1.ConditionTrue, healthy.Status)
+	healthySince := healthy.LastTransitionTime
+
+	// conditions are updated with spec, transition time is kept if status isn't changed
+	read.Spec.Usage = apiV1.DriveUsageReleasing
+	read.Generation = 2
+	assert.Nil(t, k.UpdateCR(testCtx, read))
+	assert.Nil(t, k.ReadCR(testCtx, drive.Name, "", read))
+	ready = condition(read.Status.Conditions, apiV1.ConditionReady)
+	assert.Equal(t, k8smetav1.ConditionFalse, ready.Status)
+	assert.Equal(t, "Releasing", ready.Reason)
+	assert.Equal(t, int64(2), ready.ObservedGeneration)
+	// unchanged condition keeps observed generation
+	healthy = condition(read.Status.Conditions, apiV1.ConditionHealthy)
+	assert.NotEqual(t, int64(2), healthy.ObservedGeneration)
+	assert.True(t, healthySince.Equal(&healthy.LastTransitionTime))
+	assert.False(t, setConditions(read))
+
+	// status isn't written when conditions aren't changed by update of spec
+	read.Generation = 3
+	read.Spec.Slot = "5"
+	assert.False(t, setConditions(read))
+
+	// error of status update is returned
+	failing := NewKubeClient(statusErrClient{Client: k.Client}, testLogger, objects.NewObjectLogger(), testNs)
+	read.Spec.Usage = apiV1.DriveUsageInUse
+	assert.NotNil(t, failing.UpdateCR(testCtx, read))
+	assert.Nil(t, k.ReadCR(testCtx, drive.Name, "", read))
+	assert.Equal(t, apiV1.DriveUsageInUse, read.Spec.Usage)
+	assert.Equal(t, "Releasing", condition(read.Status.Conditions, apiV1.ConditionReady).Reason)
+
+	read.Spec.Health = apiV1.HealthBad
+	assert.True(t, setConditions(read))
+	assert.Equal(t, "Unhealthy", condition(read.Status.Conditions, apiV1.ConditionReady).Reason)
+	assert.Equal(t, "Bad", condition(read.Status.Conditions, apiV1.ConditionHealthy).Reason)
+	read.Spec.Status = apiV1.DriveStatusOffline
+	assert.True(t, setConditions(read))
+	assert.Equal(t, "Offline", condition(read.Status.Conditions, apiV1.ConditionReady).Reason)
+
+	volume.Spec.CSIStatus = apiV1.VolumeReady
+	assert.True(t, setConditions(volume))
+	ready = condition(volume.Status.Conditions, apiV1.ConditionReady)
+	assert.Equal(t, k8smetav1.ConditionTrue, ready.Status)
+	assert.Equal(t, "VolumeReady", ready.Reason)
+	assert.Equal(t, "Unknown", condition(volume.Status.Conditions, apiV1.ConditionHealthy).Reason)
+	volume.Spec.CSIStatus = apiV1.Failed
+	assert.True(t, setConditions(volume))
+	assert.Equal(t, k8smetav1.ConditionFalse, condition(volume.Status.Conditions, apiV1.ConditionReady).Status)
+
+	lvg.Spec.Status = apiV1.Creating
+	assert.True(t, setConditions(lvg))
+	assert.Equal(t, "Creating", condition(lvg.Status.Conditions, apiV1.ConditionReady).Reason)
+
+	ac.Spec.Size = 0
+	assert.True(t, setConditions(ac))
+	ready = condition(ac.Status.Conditions, apiV1.ConditionReady)
+	assert.Equal(t, k8smetav1.ConditionFalse, ready.Status)
+	assert.Equal(t, apiV1.ConditionReasonNoCapacity, ready.Reason)
+
+	// other CRs don't have conditions
+	assert.False(t, setConditions(testPod3.DeepCopy()))
+}
@@ -58,6 +58,15 @@ func (cs *CRHelper) SetReader(reader CRReader) *CRHelper {
 	return cs
 }
 
+// readList reads list of CRs with provided index value if reader supports indexes,
+// otherwise reads the whole list. Caller must filter items anyway
+func (cs *CRHelper) readList(ctx context.Context, obj k8sCl.ObjectList, index, value string) error {
+	if indexed, ok := cs.reader.(IndexedCRReader); ok {
+		return indexed.ReadListByIndex(ctx, obj, index, value)
+	}
+	return cs.reader.ReadList(ctx, obj)
+}
+
 // GetACByLocation reads the whole list of AC CRs from a cluster and searches the AC with provided location
 // Receive context and location name which should be equal to AvailableCapacity.Spec.Location
 // Returns a pointer to the instance of accrd.AvailableCapacity or nil
@@ -68,7 +77,7 @@ func (cs *CRHelper) GetACByLocation(location string) (*accrd.AvailableCapacity,
 	})
 
 	acList := &accrd.AvailableCapacityList{}
-	if err := cs.reader.ReadList(context.Background(), acList); err != nil {
+	if err := cs.readList(context.Background(), acList, LocationIndex, strings.ToLower(location)); err != nil {
 		ll.Errorf("Failed to get available capacity CR list, error %v", err)
 		return nil, err
 	}
@@ -91,7 +100,7 @@ func (cs *CRHelper) DeleteACsByNodeID(nodeID string) error {
 	ll := cs.log.WithFields(logrus.Fields{"method": "DeleteACsByNodeID", "nodeID": nodeID})
 
 	acList := &accrd.AvailableCapacityList{}
-	if err := cs.reader.ReadList(context.Background(), acList); err != nil {
+	if err := cs.readList(context.Background(), acList, NodeIDIndex, nodeID); err != nil {
 		ll.Errorf("Failed to get available capacity CR list, error %v", err)
 		return err
 	}
@@ -126,11 +135,6 @@ func (cs *CRHelper) GetVolumesByLocation(ctx context.Context, location string) (
 	})
 
 	var volumes []*volumecrd.Volume
-	volList := &volumecrd.VolumeList{}
-	if err := cs.reader.ReadList(ctx, volList); err != nil {
-		ll.Errorf("Failed to get volume CR list, error %v", err)
-		return nil, err
-	}
 	lvg, err := cs.GetLVGByDrive(ctx, location)
 	if err != nil {
 		ll.Errorf("Failed to get LogicalVolumeGroup UUID for drive, error %v", err)
@@ -141,6 +145,12 @@ func (cs *CRHelper) GetVolumesByLocation(ctx context.Context, location string) (
 		location = lvg.Name
 	}
 
+	volList := &volumecrd.VolumeList{}
+	if err := cs.readList(ctx, volList, LocationIndex, strings.ToLower(location)); err != nil {
+		ll.Errorf("Failed to get volume CR list, error %v", err)
+		return nil, err
+	}
+
 	for _, v := range volList.Items {
 		v := v
 		if strings.EqualFold(v.Spec.Location, location) {
@@ -238,14 +248,17 @@ func (cs *CRHelper) GetVolumeCRs(node ...string) ([]volumecrd.Volume, error) {
 		err   error
 	)
 
-	if err = cs.reader.ReadList(context.Background(), vList); err != nil {
-		return nil, err
-	}
-
 	if len(node) == 0 {
+		if err = cs.reader.ReadList(context.Background(), vList); err != nil {
+			return nil, err
+		}
 		return vList.Items, nil
 	}
 
+	if err = cs.readList(context.Background(), vList, NodeIDIndex, node[0]); err != nil {
+		return nil, err
+	}
+
 	// if node was provided, collect volumes that are on that node
 	res := make([]volumecrd.Volume, 0)
 	for _, v := range vList.Items {
@@ -297,14 +310,17 @@ func (cs *CRHelper) GetDriveCRs(node ...string) ([]drivecrd.Drive, error) {
 		err   error
 	)
 
-	if err = cs.reader.ReadList(context.Background(), dList); err != nil {
-		return nil, err
-	}
-
 	if len(node) == 0 {
+		if err = cs.reader.ReadList(context.Background(), dList); err != nil {
+			return nil, err
+		}
 		return dList.Items, nil
 	}
 
+	if err = cs.readList(context.Background(), dList, NodeIDIndex, node[0]); err != nil {
+		return nil, err
+	}
+
 	// if node was provided, collect drives that are on that node
 	res := make([]drivecrd.Drive, 0)
 	for _, d := range dList.Items {
@@ -324,14 +340,17 @@ func (cs *CRHelper) GetACCRs(node ...string) ([]accrd.AvailableCapacity, error)
 		err     error
 	)
 
-	if err = cs.reader.ReadList(context.Background(), acsList); err != nil {
-		return nil, err
-	}
-
 	if len(node) == 0 {
+		if err = cs.reader.ReadList(context.Background(), acsList); err != nil {
+			return nil, err
+		}
 		return acsList.Items, nil
 	}
 
+	if err = cs.readList(context.Background(), acsList, NodeIDIndex, node[0]); err != nil {
+		return nil, err
+	}
+
 	// if node was provided, collect drives that are on that node
 	res := make([]accrd.AvailableCapacity, 0)
 	for _, ac := range acsList.Items {
@@ -397,14 +416,17 @@ func (cs *CRHelper) GetLVGCRs(node ...string) ([]lvgcrd.LogicalVolumeGroup, erro
 		err     error
 	)
 
-	if err = cs.reader.ReadList(context.Background(), lvgList); err != nil {
-		return nil, err
-	}
-
 	if len(node) == 0 {
+		if err = cs.reader.ReadList(context.Background(), lvgList); err != nil {
+			return nil, err
+		}
 		return lvgList.Items, nil
 	}
 
+	if err = cs.readList(context.Background(), lvgList, NodeIDIndex, node[0]); err != nil {
+		return nil, err
+	}
+
 	// if node was provided, collect LVGs that are on that node
 	res := make([]lvgcrd.LogicalVolumeGroup, 0)
 	for _, l := range lvgList.Items {
@@ -21,9 +21,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "github.com/dell/csi-baremetal/api/v1"
 	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
 	"github.com/dell/csi-baremetal/api/v1/volumecrd"
 	errTypes "github.com/dell/csi-baremetal/pkg/base/error"
 )
@@ -164,6 +166,43 @@ func TestCRHelper_GetDriveCRs(t *testing.T) {
 	assert.Equal(t, d1.Spec, currentDs[0].Spec)
 }
 
+// indexedReader records indexes requested by CRHelper
+type indexedReader struct {
+	*KubeCache
+	indexes map[string]string
+}
+
+func (r *indexedReader) ReadListByIndex(ctx context.Context, obj k8sCl.ObjectList, index, value string) error {
+	r.indexes[index] = value
+	return r.KubeCache.ReadListByIndex(ctx, obj, index, value)
+}
+
+func TestCRHelper_IndexedReader(t *testing.T) {
+	ch := setup()
+	reader := &indexedReader{KubeCache: NewKubeCache(ch.k8sClient, testLogger), indexes: map[string]string{}}
+	ch.SetReader(reader)
+
+	d1 := testDriveCR.DeepCopy()
+	d2 := testDriveCR.DeepCopy()
+	d2.Name = "anotherName"
+	d2.Spec.NodeId = "anotherNode"
+	assert.Nil(t, ch.k8sClient.CreateCR(testCtx, d1.Name, d1))
+	assert.Nil(t, ch.k8sClient.CreateCR(testCtx, d2.Name, d2))
+
+	// indexes aren't registered in cache, so the whole list is read and filtered by CRHelper
+	dList := &drivecrd.DriveList{}
+	assert.Nil(t, reader.KubeCache.ReadListByIndex(testCtx, dList, NodeIDIndex, d1.Spec.NodeId))
+	assert.Len(t, dList.Items, 2)
+
+	currentDs, err := ch.GetDriveCRs(d1.Spec.NodeId)
+	assert.Nil(t, err)
+	assert.Len(t, currentDs, 1)
+	assert.Equal(t, d1.Spec.NodeId, reader.indexes[NodeIDIndex])
+
+	_, _ = ch.GetACByLocation("Drive-UUID")
+	assert.Equal(t, "drive-uuid", reader.indexes[LocationIndex])
+}
+
 func TestCRHelper_GetVGNameByLVGCRName(t *testing.T) {
 	ch := setup()
 	lvgCR := testLVGCR
@@ -18,14 +18,83 @@ package k8s
 
 import (
 	"context"
+	"reflect"
+	"strings"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
+	toolscache "k8s.io/client-go/tools/cache"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
 	crApiutil "sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/metrics"
+	"github.com/dell/csi-baremetal/pkg/metrics/common"
+)
+
+// Names of cache indexes
+const (
+	// NodeIDIndex indexes Drive, AC, Volume and LogicalVolumeGroup CRs by node ID
+	NodeIDIndex = "spec.NodeId"
+	// LocationIndex indexes AC and Volume CRs by lowercased location
+	LocationIndex = "spec.Location"
+	// StorageClassIndex indexes AC and Volume CRs by storage class
+	StorageClassIndex = "spec.StorageClass"
 )
 
+// IndexedCRReader is a CRReader which is able to read lists of CRs using cache indexes
+type IndexedCRReader interface {
+	CRReader
+	// ReadListByIndex reads CR list with provided value of index
+	ReadListByIndex(ctx context.Context, obj k8sCl.ObjectList, index, value string) error
+}
+
+// indexers contains index functions for CRs which are read in hot paths
+var indexers = map[string]map[string]k8sCl.IndexerFunc{
+	"Drive": {
+		NodeIDIndex: func(obj k8sCl.Object) []string { return []string{obj.(*drivecrd.Drive).Spec.NodeId} },
+	},
+	"AvailableCapacity": {
+		NodeIDIndex: func(obj k8sCl.Object) []string { return []string{obj.(*accrd.AvailableCapacity).Spec.NodeId} },
+		LocationIndex: func(obj k8sCl.Object) []string {
+			return []string{strings.ToLower(obj.(*accrd.AvailableCapacity).Spec.Location)}
+		},
+		StorageClassIndex: func(obj k8sCl.Object) []string {
+			return []string{obj.(*accrd.AvailableCapacity).Spec.StorageClass}
+		},
+	},
+	"Volume": {
+		NodeIDIndex: func(obj k8sCl.Object) []string { return []string{obj.(*volumecrd.Volume).Spec.NodeId} },
+		LocationIndex: func(obj k8sCl.Object) []string {
+			return []string{strings.ToLower(obj.(*volumecrd.Volume).Spec.Location)}
+		},
+		StorageClassIndex: func(obj k8sCl.Object) []string { return []string{obj.(*volumecrd.Volume).Spec.StorageClass} },
+	},
+	"LogicalVolumeGroup": {
+		NodeIDIndex: func(obj k8sCl.Object) []string { return []string{obj.(*lvgcrd.LogicalVolumeGroup).Spec.Node} },
+	},
+}
+
+// kindOf returns kind of provided object or list, empty string if kind isn't indexed
+func kindOf(obj interface{}) string {
+	switch obj.(type) {
+	case *drivecrd.Drive, *drivecrd.DriveList:
+		return "Drive"
+	case *accrd.AvailableCapacity, *accrd.AvailableCapacityList:
+		return "AvailableCapacity"
+	case *volumecrd.Volume, *volumecrd.VolumeList:
+		return "Volume"
+	case *lvgcrd.LogicalVolumeGroup, *lvgcrd.LogicalVolumeGroupList:
+		return "LogicalVolumeGroup"
+	}
+	return ""
+}
+
 // GetK8SCache returns k8s cache
 func GetK8SCache() (cache.Cache, error) {
 	config := ctrl.GetConfigOrDie()
@@ -48,26 +117,62 @@ func GetK8SCache() (cache.Cache, error) {
 // KubeCache is a wrapper for controller-runtime cache
 type KubeCache struct {
 	k8sCl.Reader
-	log *logrus.Entry
+	log     *logrus.Entry
+	metrics metrics.Statistic
+	// kinds for which indexes were registered in cache
+	indexedKinds map[string]bool
+	// kind -> count of objects in informer of the kind, counted by event handler of informer
+	objectCounts map[string]*int64
 }
 
 // ReadCR CRReader implementation
 func (k KubeCache) ReadCR(ctx context.Context, name string, namespace string, obj k8sCl.Object) error {
+	defer k.metrics.EvaluateDurationForMethod("KubeCache.ReadCR")()
 	return k.Get(ctx, k8sCl.ObjectKey{Name: name, Namespace: namespace}, obj)
 }
 
 // ReadList CRReader implementation
 func (k KubeCache) ReadList(ctx context.Context, obj k8sCl.ObjectList) error {
+	defer k.metrics.EvaluateDurationForMethod("KubeCache.ReadList")()
 	return k.List(ctx, obj)
 }
 
+// ReadListByIndex IndexedCRReader implementation, reads the whole list if indexes weren't registered for the kind
+func (k KubeCache) ReadListByIndex(ctx context.Context, obj k8sCl.ObjectList, index, value string) error {
+	defer k.metrics.EvaluateDurationForMethod("KubeCache.ReadListByIndex")()
+	if !k.indexedKinds[kindOf(obj)] {
+		return k.List(ctx, obj)
+	}
+	return k.List(ctx, obj, k8sCl.MatchingFields{index: value})
+}
+
+// ObjectCounts returns counts of objects in informers by kind, only informers of InitKubeCache are counted
+func (k KubeCache) ObjectCounts() map[string]int64 {
+	counts := make(map[string]int64, len(k.objectCounts))
+	for kind, count := range k.objectCounts {
+		counts[kind] = atomic.LoadInt64(count)
+	}
+	return counts
+}
+
+// countObjects adds event handler to informer which counts objects in its store
+func countObjects(informer cache.Informer) *int64 {
+	count := new(int64)
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { atomic.AddInt64(count, 1) },
+		DeleteFunc: func(interface{}) { atomic.AddInt64(count, -1) },
+	})
+	return count
+}
+
 // NewKubeCache is the constructor for KubeCache struct
 // Receives basic reader from controller-runtime, logrus logger
 // Returns an instance of KubeCache struct
 func NewKubeCache(reader k8sCl.Reader, logger *logrus.Logger) *KubeCache {
 	return &KubeCache{
-		Reader: reader,
-		log:    logger.WithField("component", "KubeClient"),
+		Reader:  reader,
+		log:     logger.WithField("component", "KubeClient"),
+		metrics: common.KubeclientDuration,
 	}
 }
 
@@ -79,13 +184,25 @@ func InitKubeCache(stopCH context.Context, logger *logrus.Logger, objects ...k8s
 		logger.Errorf("fail to create cache for kubernetes resources, error: %v", err)
 		return nil, err
 	}
+	indexedKinds := make(map[string]bool)
+	objectCounts := make(map[string]*int64)
 	for _, obj := range objects {
 		// TODO get rid of TODO context https://github.com/dell/csi-baremetal/issues/556
-		_, err := k8sCache.GetInformer(context.TODO(), obj)
+		informer, err := k8sCache.GetInformer(context.TODO(), obj)
 		if err != nil {
 			logger.Errorf("fail to get cache informer for CR, error: %v", err)
 			return nil, err
 		}
+		objectCounts[reflect.TypeOf(obj).Elem().Name()] = countObjects(informer)
+		// indexes must be registered before cache is started
+		kind := kindOf(obj)
+		for index, indexer := range indexers[kind] {
+			if err := k8sCache.IndexField(context.TODO(), obj, index, indexer); err != nil {
+				logger.Errorf("fail to add cache index %s for %s, error: %v", index, kind, err)
+				return nil, err
+			}
+			indexedKinds[kind] = true
+		}
 	}
 	// start cache
 	go func() {
@@ -95,5 +212,8 @@ func InitKubeCache(stopCH context.Context, logger *logrus.Logger, objects ...k8s
 
 	k8sCache.WaitForCacheSync(stopCH)
 
-	return NewKubeCache(k8sCache, logger), nil
+	kubeCache := NewKubeCache(k8sCache, logger)
+	kubeCache.indexedKinds = indexedKinds
+	kubeCache.objectCounts = objectCounts
+	return kubeCache, nil
 }
@@ -18,6 +18,8 @@ package k8s
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -25,8 +27,11 @@ import (
 	v1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
 	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	apisV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,9 +40,12 @@ import (
 	crdV1 "github.com/dell/csi-baremetal/api/v1"
 	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
 	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivebatchcrd"
 	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/historycrd"
 	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
 	"github.com/dell/csi-baremetal/api/v1/nodecrd"
+	"github.com/dell/csi-baremetal/api/v1/poolcrd"
 	"github.com/dell/csi-baremetal/api/v1/volumecrd"
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/logger/objects"
@@ -63,6 +71,12 @@ const (
 	ReleaseLabelKey = "release"
 	// AppLabelValue matches CSI CRs with csi-baremetal app
 	AppLabelValue = "csi-baremetal"
+
+	// ListPageSize is an amount of resources read from API server in one List request
+	ListPageSize = 500
+
+	// PodNameEnv is an environment variable with name of the pod of the service, it is set with downward API
+	PodNameEnv = "POD_NAME"
 )
 
 // KubeClient is the extension of k8s client which supports CSI custom recources
@@ -72,6 +86,8 @@ type KubeClient struct {
 	objectsLogger objects.ObjectLogger
 	Namespace     string
 	metrics       metrics.Statistic
+	// if true, lists of CRs are filtered by node and storage class labels on API server side
+	labelSelectors bool
 }
 
 // CRReader is a reader interface for k8s client wrapper
@@ -109,6 +125,7 @@ func (k *KubeClient) CreateCR(ctx context.Context, name string, obj k8sCl.Object
 		"requestUUID": requestUUID.(string),
 	})
 	crKind := obj.GetObjectKind().GroupVersionKind().Kind
+	refreshCRLabels(obj)
 	ll.Infof("Creating CR '%s': %s", crKind, k.objectsLogger.Log(obj))
 	err := k.Create(ctx, obj)
 	if err != nil {
@@ -120,7 +137,7 @@ func (k *KubeClient) CreateCR(ctx context.Context, name string, obj k8sCl.Object
 		return err
 	}
 	ll.Infof("CR %s %s created", crKind, name)
-	return nil
+	return k.updateConditions(ctx, obj)
 }
 
 // ReadCR reads specified resource from k8s cluster into a pointer of struct that implements runtime.Object
@@ -139,7 +156,83 @@ func (k *KubeClient) ReadCR(ctx context.Context, name string, namespace string,
 // Returns error if something went wrong
 func (k *KubeClient) ReadList(ctx context.Context, obj k8sCl.ObjectList) error {
 	defer k.metrics.EvaluateDurationForMethod("ReadList")()
-	return k.List(ctx, obj)
+	return k.readPages(ctx, obj)
+}
+
+// ReadListByIndex reads a list of resources which labels match provided index value, IndexedCRReader implementation
+// The whole list is read if label selectors are disabled or index has no corresponding label.
+// CRs without labels are read with separate request and filtered on client side, they are created by services
+// of previous version during rolling upgrade
+// Receives golang context, List object pointer where to read, name of index and its value
+// Returns error if something went wrong
+func (k *KubeClient) ReadListByIndex(ctx context.Context, obj k8sCl.ObjectList, index, value string) error {
+	defer k.metrics.EvaluateDurationForMethod("ReadListByIndex")()
+	label, ok := indexLabels[index]
+	indexer, indexed := indexers[kindOf(obj)][index]
+	if !k.labelSelectors || !ok || !indexed {
+		return k.readPages(ctx, obj)
+	}
+
+	notLabeled, err := labels.NewRequirement(label, selection.DoesNotExist, nil)
+	if err != nil {
+		return err
+	}
+	unlabeledList := obj.DeepCopyObject().(k8sCl.ObjectList)
+	if err = k.readPages(ctx, unlabeledList,
+		k8sCl.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*notLabeled)}); err != nil {
+		return err
+	}
+	unlabeled, err := meta.ExtractList(unlabeledList)
+	if err != nil {
+		return err
+	}
+
+	if err = k.readPages(ctx, obj, k8sCl.MatchingLabels{label: value}); err != nil {
+		return err
+	}
+	if len(unlabeled) == 0 {
+		return nil
+	}
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return err
+	}
+	for _, item := range unlabeled {
+		for _, itemValue := range indexer(item.(k8sCl.Object)) {
+			if itemValue == value {
+				items = append(items, item)
+				break
+			}
+		}
+	}
+	return meta.SetList(obj, items)
+}
+
+// readPages reads a list of resources by pages with ListPageSize items to avoid API server limits
+func (k *KubeClient) readPages(ctx context.Context, obj k8sCl.ObjectList, opts ...k8sCl.ListOption) error {
+	var (
+		items []runtime.Object
+		cont  string
+	)
+	for {
+		pageOpts := append([]k8sCl.ListOption{k8sCl.Limit(ListPageSize), k8sCl.Continue(cont)}, opts...)
+		if err := k.List(ctx, obj, pageOpts...); err != nil {
+			return err
+		}
+		cont = obj.GetContinue()
+		if cont == "" && items == nil {
+			// list fits one page
+			return nil
+		}
+		page, err := meta.ExtractList(obj)
+		if err != nil {
+			return err
+		}
+		items = append(items, page...)
+		if cont == "" {
+			return meta.SetList(obj, items)
+		}
+	}
 }
 
 // UpdateCR updates provided resource on k8s cluster
@@ -152,12 +245,30 @@ func (k *KubeClient) UpdateCR(ctx context.Context, obj k8sCl.Object) error {
 		requestUUID = DefaultVolumeID
 	}
 
-	k.log.WithFields(logrus.Fields{
+	ll := k.log.WithFields(logrus.Fields{
 		"method":      "UpdateCR",
 		"requestUUID": requestUUID.(string),
-	}).Infof("Updating CR '%s': %s", obj.GetObjectKind().GroupVersionKind().Kind, k.objectsLogger.Log(obj))
+	})
+	refreshCRLabels(obj)
+	ll.Infof("Updating CR '%s': %s", obj.GetObjectKind().GroupVersionKind().Kind, k.objectsLogger.Log(obj))
 
-	return k.Update(ctx, obj)
+	if err := k.Update(ctx, obj); err != nil {
+		return err
+	}
+	return k.updateConditions(ctx, obj)
+}
+
+// updateConditions writes status conditions which are derived from spec of Drive, Volume, LogicalVolumeGroup and
+// AvailableCapacity CR to status subresource if they were changed
+// Returns error if status can't be updated, e.g. CRD has no status subresource
+func (k *KubeClient) updateConditions(ctx context.Context, obj k8sCl.Object) error {
+	if !setConditions(obj) {
+		return nil
+	}
+	if err := k.Status().Update(ctx, obj); err != nil {
+		return fmt.Errorf("spec of CR %s is updated, but status conditions aren't: %w", obj.GetName(), err)
+	}
+	return nil
 }
 
 // DeleteCR deletes provided resource from k8s cluster
@@ -189,7 +300,7 @@ func (k *KubeClient) ConstructACCR(name string, apiAC api.AvailableCapacity) *ac
 		},
 		ObjectMeta: apisV1.ObjectMeta{
 			Name:   name,
-			Labels: constructDefaultAppMap(),
+			Labels: withCRLabels(constructDefaultAppMap(), apiAC.NodeId, apiAC.StorageClass),
 		},
 		Spec: apiAC,
 	}
@@ -223,7 +334,7 @@ func (k *KubeClient) ConstructLVGCR(name string, apiLVG api.LogicalVolumeGroup)
 		},
 		ObjectMeta: apisV1.ObjectMeta{
 			Name:   name,
-			Labels: constructDefaultAppMap(),
+			Labels: withCRLabels(constructDefaultAppMap(), apiLVG.Node, ""),
 		},
 		Spec: apiLVG,
 	}
@@ -242,7 +353,7 @@ func (k *KubeClient) ConstructVolumeCR(name, namespace string, labels map[string
 		ObjectMeta: apisV1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    withCRLabels(labels, apiVolume.NodeId, apiVolume.StorageClass),
 		},
 		Spec: apiVolume,
 	}
@@ -259,7 +370,7 @@ func (k *KubeClient) ConstructDriveCR(name string, apiDrive api.Drive) *drivecrd
 		},
 		ObjectMeta: apisV1.ObjectMeta{
 			Name:   name,
-			Labels: constructDefaultAppMap(),
+			Labels: withCRLabels(constructDefaultAppMap(), apiDrive.NodeId, ""),
 		},
 		Spec: apiDrive,
 	}
@@ -364,6 +475,20 @@ func (k *KubeClient) GetPods(ctx context.Context, mask string) ([]*coreV1.Pod, e
 	return p, nil
 }
 
+// GetOwnPod returns the pod of the service in namespace of KubeClient, name of the pod is read from PodNameEnv
+// Returns error if PodNameEnv isn't set or the pod can't be read
+func (k *KubeClient) GetOwnPod(ctx context.Context) (*coreV1.Pod, error) {
+	name := os.Getenv(PodNameEnv)
+	if name == "" {
+		return nil, fmt.Errorf("%s environment variable isn't set", PodNameEnv)
+	}
+	pod := &coreV1.Pod{}
+	if err := k.Get(ctx, k8sCl.ObjectKey{Name: name, Namespace: k.Namespace}, pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
 // GetNodes returns list of nodes
 // Receives golang context
 // Returns slice of coreV1.Node or error if something went wrong
@@ -454,6 +579,21 @@ func PrepareScheme() (*runtime.Scheme, error) {
 		return nil, err
 	}
 
+	// register pool crd
+	if err := poolcrd.AddToSchemePool(scheme); err != nil {
+		return nil, err
+	}
+
+	// register drive batch crd
+	if err := drivebatchcrd.AddToSchemeDriveBatch(scheme); err != nil {
+		return nil, err
+	}
+
+	// register history crd
+	if err := historycrd.AddToSchemeHistory(scheme); err != nil {
+		return nil, err
+	}
+
 	return scheme, nil
 }
 
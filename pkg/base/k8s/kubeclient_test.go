@@ -19,6 +19,7 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -226,6 +227,21 @@ var _ = Describe("pods listing", func() {
 			Expect(len(pods)).To(Equal(0))
 		})
 	})
+
+	Context("Obtain pod of the service", func() {
+		It("Must receive pod from environment variable", func() {
+			Expect(os.Setenv(PodNameEnv, testPod1Name)).To(BeNil())
+			defer func() { _ = os.Unsetenv(PodNameEnv) }()
+			pod, err := kubeClient.GetOwnPod(testCtx)
+			Expect(err).To(BeNil())
+			Expect(pod.Name).To(Equal(testPod1Name))
+		})
+
+		It("Must fail without environment variable", func() {
+			_, err := kubeClient.GetOwnPod(testCtx)
+			Expect(err).NotTo(BeNil())
+		})
+	})
 })
 
 // create provided pods via client from provided svc
@@ -467,7 +483,7 @@ var _ = Describe("Constructor methods", func() {
 			Expect(constructedCR.ObjectMeta.Name).To(Equal(testACCR.ObjectMeta.Name))
 			Expect(constructedCR.ObjectMeta.Namespace).To(Equal(testACCR.ObjectMeta.Namespace))
 			Expect(constructedCR.Spec).To(Equal(testACCR.Spec))
-			Expect(constructedCR.Labels).To(Equal(constructDefaultAppMap()))
+			Expect(constructedCR.Labels).To(Equal(withCRLabels(constructDefaultAppMap(), testApiAC.NodeId, testApiAC.StorageClass)))
 		})
 	})
 	Context("ConstructDriveCR", func() {
@@ -478,7 +494,7 @@ var _ = Describe("Constructor methods", func() {
 			Expect(constructedCR.ObjectMeta.Name).To(Equal(testDriveCR.ObjectMeta.Name))
 			Expect(constructedCR.ObjectMeta.Namespace).To(Equal(testDriveCR.ObjectMeta.Namespace))
 			Expect(constructedCR.Spec).To(Equal(testDriveCR.Spec))
-			Expect(constructedCR.Labels).To(Equal(constructDefaultAppMap()))
+			Expect(constructedCR.Labels).To(Equal(withCRLabels(constructDefaultAppMap(), testApiDrive.NodeId, "")))
 		})
 	})
 	Context("ConstructVolumeCR", func() {
@@ -489,7 +505,7 @@ var _ = Describe("Constructor methods", func() {
 			Expect(constructedCR.ObjectMeta.Name).To(Equal(testVolumeCR.ObjectMeta.Name))
 			Expect(constructedCR.ObjectMeta.Namespace).To(Equal(testVolumeCR.ObjectMeta.Namespace))
 			Expect(constructedCR.Spec).To(Equal(testVolumeCR.Spec))
-			Expect(constructedCR.Labels).To(Equal(testAppLabels))
+			Expect(constructedCR.Labels).To(Equal(withCRLabels(testAppLabels, testApiVolume.NodeId, testApiVolume.StorageClass)))
 		})
 	})
 	Context("ConstructLVGCR", func() {
@@ -500,7 +516,7 @@ var _ = Describe("Constructor methods", func() {
 			Expect(constructedCR.ObjectMeta.Name).To(Equal(testLVGCR.ObjectMeta.Name))
 			Expect(constructedCR.ObjectMeta.Namespace).To(Equal(testLVGCR.ObjectMeta.Namespace))
 			Expect(constructedCR.Spec).To(Equal(testLVGCR.Spec))
-			Expect(constructedCR.Labels).To(Equal(constructDefaultAppMap()))
+			Expect(constructedCR.Labels).To(Equal(withCRLabels(constructDefaultAppMap(), testApiLVG.Node, "")))
 		})
 	})
 })
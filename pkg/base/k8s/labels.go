@@ -0,0 +1,149 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+)
+
+const (
+	// NodeIDLabelKey is set on Drive, AC, Volume and LogicalVolumeGroup CRs for server-side filtering by node
+	NodeIDLabelKey = "csi-baremetal.dell.com/node-id"
+	// StorageClassLabelKey is set on AC and Volume CRs for server-side filtering by storage class
+	StorageClassLabelKey = "csi-baremetal.dell.com/storage-class"
+)
+
+// indexLabels maps cache indexes to labels with the same values
+var indexLabels = map[string]string{
+	NodeIDIndex:       NodeIDLabelKey,
+	StorageClassIndex: StorageClassLabelKey,
+}
+
+// withCRLabels adds node ID and storage class labels to provided labels, empty values are skipped
+// Returns new map, provided labels aren't modified
+func withCRLabels(labels map[string]string, nodeID, storageClass string) map[string]string {
+	res := make(map[string]string, len(labels)+2)
+	for k, v := range labels {
+		res[k] = v
+	}
+	if nodeID != "" {
+		res[NodeIDLabelKey] = nodeID
+	}
+	if storageClass != "" {
+		res[StorageClassLabelKey] = storageClass
+	}
+	return res
+}
+
+// crLabelValues returns node ID and storage class which are set as labels of Drive, AC, Volume and
+// LogicalVolumeGroup CR, ok is false for other objects
+func crLabelValues(obj interface{}) (nodeID, storageClass string, ok bool) {
+	switch o := obj.(type) {
+	case *drivecrd.Drive:
+		return o.Spec.NodeId, "", true
+	case *accrd.AvailableCapacity:
+		return o.Spec.NodeId, o.Spec.StorageClass, true
+	case *volumecrd.Volume:
+		return o.Spec.NodeId, o.Spec.StorageClass, true
+	case *lvgcrd.LogicalVolumeGroup:
+		return o.Spec.Node, "", true
+	}
+	return "", "", false
+}
+
+// refreshCRLabels sets node ID and storage class labels of CR from its spec, labels become stale when spec is changed
+// Returns true if labels were changed
+func refreshCRLabels(obj k8sCl.Object) bool {
+	nodeID, storageClass, ok := crLabelValues(obj)
+	if !ok {
+		return false
+	}
+	current := obj.GetLabels()
+	if current[NodeIDLabelKey] == nodeID && current[StorageClassLabelKey] == storageClass {
+		return false
+	}
+	obj.SetLabels(withCRLabels(current, nodeID, storageClass))
+	return true
+}
+
+// EnableLabelSelectors makes KubeClient filter lists of CRs by labels on API server side,
+// CRs without labels which are created by services of previous versions are filtered on client side
+func (k *KubeClient) EnableLabelSelectors() {
+	k.labelSelectors = true
+}
+
+// LabelCRs sets node ID and storage class labels on Drive, AC, Volume and LogicalVolumeGroup CRs
+// which were created before labels were introduced
+// Returns error if some of CRs weren't read or updated
+func (k *KubeClient) LabelCRs(ctx context.Context) error {
+	ll := k.log.WithFields(logrus.Fields{"method": "LabelCRs"})
+
+	var (
+		drives  = &drivecrd.DriveList{}
+		acs     = &accrd.AvailableCapacityList{}
+		volumes = &volumecrd.VolumeList{}
+		lvgs    = &lvgcrd.LogicalVolumeGroupList{}
+	)
+	if err := k.ReadList(ctx, drives); err != nil {
+		return err
+	}
+	if err := k.ReadList(ctx, acs); err != nil {
+		return err
+	}
+	if err := k.ReadList(ctx, volumes); err != nil {
+		return err
+	}
+	if err := k.ReadList(ctx, lvgs); err != nil {
+		return err
+	}
+
+	var lastErr error
+	updated := 0
+	label := func(obj k8sCl.Object) {
+		if !refreshCRLabels(obj) {
+			return
+		}
+		if err := k.UpdateCR(ctx, obj); err != nil {
+			ll.Errorf("Unable to set labels on %s: %v", obj.GetName(), err)
+			lastErr = err
+			return
+		}
+		updated++
+	}
+	for i := range drives.Items {
+		label(&drives.Items[i])
+	}
+	for i := range acs.Items {
+		label(&acs.Items[i])
+	}
+	for i := range volumes.Items {
+		label(&volumes.Items[i])
+	}
+	for i := range lvgs.Items {
+		label(&lvgs.Items[i])
+	}
+	ll.Infof("Labels were set on %d CRs", updated)
+	return lastErr
+}
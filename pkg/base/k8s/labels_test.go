@@ -0,0 +1,88 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+)
+
+func TestKubeClient_LabelCRs(t *testing.T) {
+	k, err := GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+
+	// CRs were created before labels were introduced
+	drive1 := testDriveCR.DeepCopy()
+	drive2 := testDriveCR.DeepCopy()
+	drive2.Name = "another-drive"
+	drive2.Spec.NodeId = "another-node"
+	ac := testACCR.DeepCopy()
+	assert.Nil(t, k.Create(testCtx, drive1))
+	assert.Nil(t, k.Create(testCtx, drive2))
+	assert.Nil(t, k.Create(testCtx, ac))
+
+	// label selectors are disabled, the whole list is read
+	dList := &drivecrd.DriveList{}
+	assert.Nil(t, k.ReadListByIndex(testCtx, dList, NodeIDIndex, testNode1Name))
+	assert.Len(t, dList.Items, 2)
+
+	assert.Nil(t, k.LabelCRs(testCtx))
+	k.EnableLabelSelectors()
+
+	assert.Nil(t, k.ReadListByIndex(testCtx, dList, NodeIDIndex, testNode1Name))
+	assert.Len(t, dList.Items, 1)
+	assert.Equal(t, drive1.Name, dList.Items[0].Name)
+
+	acList := &accrd.AvailableCapacityList{}
+	assert.Nil(t, k.ReadListByIndex(testCtx, acList, StorageClassIndex, ac.Spec.StorageClass))
+	assert.Len(t, acList.Items, 1)
+	assert.Equal(t, testNode1Name, acList.Items[0].Labels[NodeIDLabelKey])
+
+	// location index has no label, the whole list is read
+	assert.Nil(t, k.ReadListByIndex(testCtx, dList, LocationIndex, "location"))
+	assert.Len(t, dList.Items, 2)
+
+	// CR without labels is created by node service of previous version during rolling upgrade
+	drive3 := testDriveCR.DeepCopy()
+	drive3.Name = "not-labeled-drive"
+	assert.Nil(t, k.Create(testCtx, drive3))
+	assert.Nil(t, k.ReadListByIndex(testCtx, dList, NodeIDIndex, testNode1Name))
+	assert.Len(t, dList.Items, 2)
+	assert.Nil(t, k.ReadListByIndex(testCtx, dList, NodeIDIndex, "another-node"))
+	assert.Len(t, dList.Items, 1)
+	assert.Equal(t, drive2.Name, dList.Items[0].Name)
+
+	// labels are refreshed on update
+	assert.Nil(t, k.ReadListByIndex(testCtx, acList, StorageClassIndex, ac.Spec.StorageClass))
+	ac = acList.Items[0].DeepCopy()
+	ac.Spec.StorageClass = "ANOTHER"
+	assert.Nil(t, k.UpdateCR(testCtx, ac))
+	assert.Nil(t, k.ReadListByIndex(testCtx, acList, StorageClassIndex, "ANOTHER"))
+	assert.Len(t, acList.Items, 1)
+}
+
+func TestWithCRLabels(t *testing.T) {
+	labels := map[string]string{AppLabelKey: AppLabelValue}
+	res := withCRLabels(labels, "node", "")
+	assert.Equal(t, "node", res[NodeIDLabelKey])
+	assert.NotContains(t, res, StorageClassLabelKey)
+	assert.Len(t, labels, 1)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package burnin contains destructive burn-in tests of new drives with pattern write and verify
+package burnin
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+)
+
+// Tools which perform burn-in test
+const (
+	// ToolBadblocks writes random pattern with badblocks and reads it back, one pass over whole drive at most
+	ToolBadblocks = "badblocks"
+	// ToolFio writes data with checksums with fio and verifies it during the whole duration
+	ToolFio = "fio"
+)
+
+const (
+	// BadblocksCmdTmpl is a CMD of destructive read-write badblocks test limited by duration in seconds
+	BadblocksCmdTmpl = "timeout --signal=INT %d badblocks -w -b 4096 -t random -e 1 %s"
+	// FioCmdTmpl is a CMD of time based fio write test with verification of written data
+	FioCmdTmpl = "fio --name=burn-in --filename=%s --rw=write --bs=1M --direct=1 --ioengine=libaio --iodepth=16 " +
+		"--verify=crc32c --verify_backlog=1024 --verify_fatal=1 --time_based --runtime=%d"
+
+	// timeoutExitCode is an exit code of timeout util when command was interrupted
+	timeoutExitCode = 124
+)
+
+// WrapBurnIn is an interface that encapsulates burn-in test of drive
+type WrapBurnIn interface {
+	Run(device, tool string, duration time.Duration) error
+}
+
+// BurnIn runs burn-in tests with system utils
+type BurnIn struct {
+	e   command.CmdExecutor
+	log *logrus.Entry
+}
+
+// NewBurnIn is a constructor for BurnIn
+func NewBurnIn(e command.CmdExecutor, logger *logrus.Logger) *BurnIn {
+	return &BurnIn{e: e, log: logger.WithField("component", "BurnIn")}
+}
+
+// Run performs destructive burn-in test of device with tool during duration, data on device is overwritten
+// Returns error if tool found bad blocks or data corruption or if it failed
+func (b *BurnIn) Run(device, tool string, duration time.Duration) error {
+	seconds := int(duration.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	b.log.WithField("method", "Run").Infof("Run burn-in test of %s with %s for %s", device, tool, duration)
+	switch tool {
+	case ToolBadblocks:
+		stdout, stderr, err := b.e.RunCmd(command.NewCmd(BadblocksCmdTmpl, seconds, command.Device(device)))
+		// badblocks doesn't finish pass over large drive in time, blocks which were checked are still reported
+		if err != nil && !isTimeout(err) {
+			return fmt.Errorf("badblocks failed: %v, stderr: %s", err, stderr)
+		}
+		if blocks := badBlocks(stdout); len(blocks) > 0 {
+			return fmt.Errorf("badblocks found bad blocks: %s", strings.Join(blocks, ", "))
+		}
+		return nil
+	case ToolFio:
+		if _, stderr, err := b.e.RunCmd(command.NewCmd(FioCmdTmpl, command.Device(device), seconds)); err != nil {
+			return fmt.Errorf("fio failed: %v, stderr: %s", err, stderr)
+		}
+		return nil
+	}
+	return fmt.Errorf("burn-in tool %s isn't supported, expected %s or %s", tool, ToolBadblocks, ToolFio)
+}
+
+// isTimeout returns true if command was interrupted by timeout util
+func isTimeout(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == timeoutExitCode
+}
+
+// badBlocks returns numbers of bad blocks from badblocks output, each bad block is printed on its own line
+func badBlocks(out string) []string {
+	blocks := make([]string, 0)
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if _, err := strconv.ParseUint(line, 10, 64); err == nil {
+			blocks = append(blocks, line)
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package burnin
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+var (
+	logger  = logrus.New()
+	testErr = errors.New("error")
+)
+
+func TestBurnIn_Run(t *testing.T) {
+	var (
+		e         = &mocks.GoMockExecutor{}
+		b         = NewBurnIn(e, logger)
+		device    = "/dev/sda"
+		badblocks = fmt.Sprintf(BadblocksCmdTmpl, 3600, device)
+		fio       = fmt.Sprintf(FioCmdTmpl, device, 3600)
+	)
+	timeoutErr := exec.Command("sh", "-c", "exit 124").Run()
+
+	e.OnCommand(badblocks).Return("", "", nil).Once()
+	assert.Nil(t, b.Run(device, ToolBadblocks, time.Hour))
+
+	e.OnCommand(badblocks).Return("", "", timeoutErr).Once()
+	assert.Nil(t, b.Run(device, ToolBadblocks, time.Hour))
+
+	e.OnCommand(badblocks).Return("1024\n1025\n", "", timeoutErr).Once()
+	err := b.Run(device, ToolBadblocks, time.Hour)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "1024, 1025")
+
+	e.OnCommand(badblocks).Return("", "Permission denied", testErr).Once()
+	assert.NotNil(t, b.Run(device, ToolBadblocks, time.Hour))
+
+	e.OnCommand(fio).Return("", "", nil).Once()
+	assert.Nil(t, b.Run(device, ToolFio, time.Hour))
+
+	e.OnCommand(fio).Return("", "verify: bad header", testErr).Once()
+	assert.NotNil(t, b.Run(device, ToolFio, time.Hour))
+
+	assert.NotNil(t, b.Run(device, "dd", time.Hour))
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cryptsetup contains code for running system cryptsetup util which manages LUKS encrypted devices
+package cryptsetup
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+)
+
+const (
+	cryptsetup = "cryptsetup"
+	// LuksFSType is a type of LUKS header reported by lsblk and blkid
+	LuksFSType = "crypto_LUKS"
+	// StatusCmdTmpl checks whether LUKS device is opened, exit code is 0 if it is active
+	StatusCmdTmpl = cryptsetup + " status %s"
+	// CloseCmdTmpl closes LUKS device
+	CloseCmdTmpl = cryptsetup + " luksClose %s"
+	// ResizeCmdTmpl grows opened LUKS device up to size of underlying device, volume key is read from kernel keyring
+	ResizeCmdTmpl = cryptsetup + " resize %s"
+	// MapperDir is a directory of opened LUKS devices
+	MapperDir = "/dev/mapper"
+)
+
+// WrapCryptsetup is an interface that encapsulates operation with LUKS devices
+type WrapCryptsetup interface {
+	Format(device, key string) error
+	Open(device, name, key string) (string, error)
+	Close(name string) error
+	Resize(name, key string) error
+}
+
+// Cryptsetup is an implementation of WrapCryptsetup interface
+type Cryptsetup struct {
+	e command.CmdExecutor
+}
+
+// NewCryptsetup is a constructor for Cryptsetup struct
+func NewCryptsetup(e command.CmdExecutor) *Cryptsetup {
+	return &Cryptsetup{e: e}
+}
+
+// keyCmd creates cryptsetup command which reads key from stdin, so key isn't exposed in process list and logs
+func keyCmd(key string, args ...string) *exec.Cmd {
+	cmd := exec.Command(cryptsetup, append([]string{"--key-file=-"}, args...)...)
+	cmd.Stdin = strings.NewReader(key)
+	return cmd
+}
+
+// MapperPath returns path of opened LUKS device
+func MapperPath(name string) string {
+	return filepath.Join(MapperDir, name)
+}
+
+// Format creates LUKS header on device with provided key, all data on device is lost
+// Returns error if key is empty or cryptsetup failed
+func (c *Cryptsetup) Format(device, key string) error {
+	if key == "" {
+		return errors.New("encryption key is empty")
+	}
+	if _, stderr, err := c.e.RunCmd(keyCmd(key, "luksFormat", "--batch-mode", "--type", "luks2", device)); err != nil {
+		return fmt.Errorf("unable to format %s: %v, stderr: %s", device, err, stderr)
+	}
+	return nil
+}
+
+// Open opens LUKS device with provided key, operation is idempotent
+// Returns path of opened device in /dev/mapper or error if key is wrong
+func (c *Cryptsetup) Open(device, name, key string) (string, error) {
+	if _, _, err := c.e.RunCmd(command.NewCmd(StatusCmdTmpl, command.Name(name))); err == nil {
+		return MapperPath(name), nil
+	}
+	if key == "" {
+		return "", errors.New("encryption key is empty")
+	}
+	if _, stderr, err := c.e.RunCmd(keyCmd(key, "luksOpen", device, name)); err != nil {
+		return "", fmt.Errorf("unable to open %s: %v, stderr: %s", device, err, stderr)
+	}
+	return MapperPath(name), nil
+}
+
+// Close closes LUKS device, operation is idempotent
+func (c *Cryptsetup) Close(name string) error {
+	if _, _, err := c.e.RunCmd(command.NewCmd(StatusCmdTmpl, command.Name(name))); err != nil {
+		return nil
+	}
+	if _, stderr, err := c.e.RunCmd(command.NewCmd(CloseCmdTmpl, command.Name(name))); err != nil {
+		return fmt.Errorf("unable to close %s: %v, stderr: %s", name, err, stderr)
+	}
+	return nil
+}
+
+// Resize grows opened LUKS device up to size of underlying device. Key is required for LUKS2 devices if volume key
+// isn't stored in kernel keyring, if key is empty cryptsetup uses keyring
+// Returns error if device isn't opened or cryptsetup failed
+func (c *Cryptsetup) Resize(name, key string) error {
+	if _, _, err := c.e.RunCmd(command.NewCmd(StatusCmdTmpl, command.Name(name))); err != nil {
+		return fmt.Errorf("unable to resize %s: device isn't opened", name)
+	}
+	var (
+		stderr string
+		err    error
+	)
+	if key == "" {
+		_, stderr, err = c.e.RunCmd(command.NewCmd(ResizeCmdTmpl, command.Name(name)))
+	} else {
+		_, stderr, err = c.e.RunCmd(keyCmd(key, "resize", name))
+	}
+	if err != nil {
+		return fmt.Errorf("unable to resize %s: %v, stderr: %s", name, err, stderr)
+	}
+	return nil
+}
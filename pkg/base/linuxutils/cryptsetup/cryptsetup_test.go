@@ -0,0 +1,118 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptsetup
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+const (
+	device = "/dev/sda1"
+	name   = "pvc-1"
+	key    = "secret"
+)
+
+// keyCmdMatcher matches cryptsetup command which reads key from stdin, key mustn't be passed in arguments
+func keyCmdMatcher(action string) interface{} {
+	return mock.MatchedBy(func(cmd interface{}) bool {
+		c, ok := cmd.(*exec.Cmd)
+		if !ok || len(c.Args) < 3 || c.Args[2] != action || c.Stdin == nil {
+			return false
+		}
+		return !strings.Contains(strings.Join(c.Args, " "), key)
+	})
+}
+
+func TestCryptsetup_Format(t *testing.T) {
+	e := &mocks.GoMockExecutor{}
+	c := NewCryptsetup(e)
+
+	assert.NotNil(t, c.Format(device, ""))
+
+	e.On(mocks.RunCmd, keyCmdMatcher("luksFormat")).Return("", "", nil).Once()
+	assert.Nil(t, c.Format(device, key))
+
+	e.On(mocks.RunCmd, keyCmdMatcher("luksFormat")).Return("", "error", errors.New("error")).Once()
+	assert.NotNil(t, c.Format(device, key))
+}
+
+func TestCryptsetup_Open(t *testing.T) {
+	e := &mocks.GoMockExecutor{}
+	c := NewCryptsetup(e)
+
+	// already opened
+	e.OnCommand(fmt.Sprintf(StatusCmdTmpl, name)).Return("", "", nil).Once()
+	path, err := c.Open(device, name, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "/dev/mapper/pvc-1", path)
+
+	e.OnCommand(fmt.Sprintf(StatusCmdTmpl, name)).Return("", "", errors.New("inactive"))
+	_, err = c.Open(device, name, "")
+	assert.NotNil(t, err)
+
+	e.On(mocks.RunCmd, keyCmdMatcher("luksOpen")).Return("", "", nil).Once()
+	path, err = c.Open(device, name, key)
+	assert.Nil(t, err)
+	assert.Equal(t, "/dev/mapper/pvc-1", path)
+
+	e.On(mocks.RunCmd, keyCmdMatcher("luksOpen")).Return("", "No key available", errors.New("error")).Once()
+	_, err = c.Open(device, name, key)
+	assert.NotNil(t, err)
+}
+
+func TestCryptsetup_Close(t *testing.T) {
+	e := &mocks.GoMockExecutor{}
+	c := NewCryptsetup(e)
+
+	e.OnCommand(fmt.Sprintf(StatusCmdTmpl, name)).Return("", "", errors.New("inactive")).Once()
+	assert.Nil(t, c.Close(name))
+
+	e.OnCommand(fmt.Sprintf(StatusCmdTmpl, name)).Return("", "", nil)
+	e.OnCommand(fmt.Sprintf(CloseCmdTmpl, name)).Return("", "", nil).Once()
+	assert.Nil(t, c.Close(name))
+
+	e.OnCommand(fmt.Sprintf(CloseCmdTmpl, name)).Return("", "busy", errors.New("error")).Once()
+	assert.NotNil(t, c.Close(name))
+}
+
+func TestCryptsetup_Resize(t *testing.T) {
+	e := &mocks.GoMockExecutor{}
+	c := NewCryptsetup(e)
+
+	e.OnCommand(fmt.Sprintf(StatusCmdTmpl, name)).Return("", "", errors.New("inactive")).Once()
+	assert.NotNil(t, c.Resize(name, key))
+
+	e.OnCommand(fmt.Sprintf(StatusCmdTmpl, name)).Return("", "", nil)
+	// volume key from kernel keyring
+	e.OnCommand(fmt.Sprintf(ResizeCmdTmpl, name)).Return("", "", nil).Once()
+	assert.Nil(t, c.Resize(name, ""))
+
+	e.On(mocks.RunCmd, keyCmdMatcher("resize")).Return("", "", nil).Once()
+	assert.Nil(t, c.Resize(name, key))
+
+	e.On(mocks.RunCmd, keyCmdMatcher("resize")).Return("", "No key available", errors.New("error")).Once()
+	assert.NotNil(t, c.Resize(name, key))
+}
@@ -19,18 +19,32 @@ package datadiscover
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/datadiscover/types"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lvm"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/base/util"
 )
 
+// signatureUsages describe how device is used by OS if it has signature which isn't a filesystem
+var signatureUsages = map[string]string{
+	"swap":              "is a swap space",
+	"linux_raid_member": "is a member of software RAID",
+	"crypto_LUKS":       "is a LUKS container",
+}
+
 // WrapDataDiscoverImpl is the basic implementation of WrapDataDiscover interface
 type WrapDataDiscoverImpl struct {
 	fsHelper   fs.WrapFS
 	partHelper partitionhelper.WrapPartition
 	lvmHelper  lvm.WrapLVM
+	// graph is used to find devices and mount points which use drive, nil if check is disabled
+	graph devicegraph.Reader
+	// managedTypes are partition type GUIDs which identify partitions created by csi-baremetal
+	managedTypes []string
 }
 
 // NewDataDiscover is a constructor for WrapDataDiscoverImpl
@@ -40,6 +54,20 @@ func NewDataDiscover(fs fs.WrapFS,
 	return &WrapDataDiscoverImpl{fsHelper: fs, partHelper: part, lvmHelper: lvm}
 }
 
+// SetDeviceGraph enables check that device isn't used by other devices (LVM, software RAID, dm-crypt, bcache),
+// mounted in any mount namespace or active swap
+func (w *WrapDataDiscoverImpl) SetDeviceGraph(graph devicegraph.Reader) {
+	w.graph = graph
+}
+
+// SetManagedPartitionTypes enables detection of partitions created by csi-baremetal by their type GUIDs
+func (w *WrapDataDiscoverImpl) SetManagedPartitionTypes(typeGUIDs []string) {
+	w.managedTypes = make([]string, 0, len(typeGUIDs))
+	for _, typeGUID := range typeGUIDs {
+		w.managedTypes = append(w.managedTypes, strings.ToLower(typeGUID))
+	}
+}
+
 // DiscoverData perform linux operation to determine if device has logical entities like filesystem on it
 // It executes lsblk to find file systems and partitions, parted for partition table
 // Receive device path and serial number
@@ -54,6 +82,14 @@ func (w *WrapDataDiscoverImpl) DiscoverData(device, serialNumber string) (*types
 	if fileSystem, err = w.fsHelper.GetFSType(device); err != nil {
 		return nil, err
 	}
+	for _, signature := range strings.Fields(fileSystem) {
+		if usage, ok := signatureUsages[signature]; ok {
+			return &types.DiscoverResult{
+				Message: fmt.Sprintf("Drive with path %s, SN %s %s.", device, serialNumber, usage),
+				HasData: true,
+			}, nil
+		}
+	}
 	if fileSystem != "" {
 		return &types.DiscoverResult{
 			Message: fmt.Sprintf("Drive with path %s, SN %s has filesystem %s.", device, serialNumber, fileSystem),
@@ -65,6 +101,16 @@ func (w *WrapDataDiscoverImpl) DiscoverData(device, serialNumber string) (*types
 		return nil, err
 	}
 	if hasData {
+		if managed, err := w.hasManagedPartitions(device); err != nil {
+			return nil, err
+		} else if managed {
+			return &types.DiscoverResult{
+				Message: fmt.Sprintf("Drive with path %s, SN %s has partitions created by csi-baremetal "+
+					"which aren't used by volumes.", device, serialNumber),
+				HasData: true,
+				Managed: true,
+			}, nil
+		}
 		return &types.DiscoverResult{
 			Message: fmt.Sprintf("Drive with path %s, SN %s has a partition table.", device, serialNumber),
 			HasData: hasData,
@@ -81,8 +127,61 @@ func (w *WrapDataDiscoverImpl) DiscoverData(device, serialNumber string) (*types
 		}, nil
 	}
 
+	if w.graph != nil {
+		graph, err := w.graph.Read()
+		if err != nil {
+			return nil, err
+		}
+		// devices which aren't found in sysfs are checked by lsblk and parted only
+		if users := graph.Users(device); len(users) > 0 {
+			return &types.DiscoverResult{
+				Message: fmt.Sprintf("Drive with path %s, SN %s is used by %s.", device, serialNumber,
+					strings.Join(users, ", ")),
+				HasData: true,
+			}, nil
+		}
+	}
+
 	return &types.DiscoverResult{
 		Message: fmt.Sprintf("Drive with path %s, SN %s doesn't have filesystem, partition table, partitions or PV.", device, serialNumber),
 		HasData: hasData,
 	}, nil
 }
+
+// ScanSignatures lists signatures of filesystems, LVM PVs, RAID members, LUKS and partition tables on device
+// and its partitions. Signatures known by udev are taken from lsblk, device itself is probed by wipefs
+// Returns unique signature types or error if something went wrong
+func (w *WrapDataDiscoverImpl) ScanSignatures(device string) ([]string, error) {
+	fsTypes, err := w.fsHelper.GetFSType(device)
+	if err != nil {
+		return nil, err
+	}
+	probed, err := w.fsHelper.GetSignatures(device)
+	if err != nil {
+		return nil, err
+	}
+	var signatures []string
+	for _, signature := range append(strings.Split(fsTypes, "\n"), probed...) {
+		if signature = strings.TrimSpace(signature); signature != "" && !util.ContainsString(signatures, signature) {
+			signatures = append(signatures, signature)
+		}
+	}
+	return signatures, nil
+}
+
+// hasManagedPartitions returns true if device has partitions and all of them have managed partition type GUID
+func (w *WrapDataDiscoverImpl) hasManagedPartitions(device string) (bool, error) {
+	if len(w.managedTypes) == 0 {
+		return false, nil
+	}
+	partTypes, err := w.partHelper.GetPartitionTypes(device)
+	if err != nil {
+		return false, err
+	}
+	for _, typeGUID := range partTypes {
+		if !util.ContainsString(w.managedTypes, typeGUID) {
+			return false, nil
+		}
+	}
+	return len(partTypes) > 0, nil
+}
@@ -3,10 +3,12 @@ package datadiscover
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
 	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
 )
 
@@ -27,6 +29,23 @@ func Test_DiscoverData(t *testing.T) {
 		assert.Nil(t, err)
 		assert.True(t, discoverResult.HasData)
 	})
+	t.Run("Device is used by OS", func(t *testing.T) {
+		for signature, usage := range map[string]string{
+			"swap":              "swap space",
+			"linux_raid_member": "member of software RAID",
+			"crypto_LUKS":       "LUKS container",
+		} {
+			var (
+				fs           = mocklu.MockWrapFS{}
+				discoverData = NewDataDiscover(&fs, &mocklu.MockWrapPartition{}, &mocklu.MockWrapLVM{})
+			)
+			fs.On("GetFSType", device).Return(signature+"\n", nil).Times(1)
+			discoverResult, err := discoverData.DiscoverData(device, serialNumber)
+			assert.Nil(t, err)
+			assert.True(t, discoverResult.HasData)
+			assert.Contains(t, discoverResult.Message, usage)
+		}
+	})
 	t.Run("Device has partition table", func(t *testing.T) {
 		var (
 			fs           = mocklu.MockWrapFS{}
@@ -110,3 +129,137 @@ func Test_DiscoverData(t *testing.T) {
 		assert.Nil(t, discoverResult)
 	})
 }
+
+// graphReader returns predefined graph of devices
+type graphReader struct {
+	graph *devicegraph.Graph
+	err   error
+}
+
+func (r *graphReader) Read() (*devicegraph.Graph, error) {
+	return r.graph, r.err
+}
+
+func TestWrapDataDiscoverImpl_DiscoverDataDeviceGraph(t *testing.T) {
+	var (
+		device       = "/dev/sda"
+		serialNumber = "test"
+		sda          = &devicegraph.Device{Name: "sda", Type: devicegraph.TypeDisk}
+		md           = &devicegraph.Device{Name: "md127", Type: devicegraph.TypeMD, MountPoints: []string{"/mnt"}}
+	)
+	sda.Holders, md.Slaves = []*devicegraph.Device{md}, []*devicegraph.Device{sda}
+
+	prepare := func(reader devicegraph.Reader) *WrapDataDiscoverImpl {
+		var (
+			fs   = mocklu.MockWrapFS{}
+			part = mocklu.MockWrapPartition{}
+		)
+		fs.On("GetFSType", device).Return("", nil)
+		part.On("DeviceHasPartitionTable", device).Return(false, nil)
+		part.On("DeviceHasPartitions", device).Return(false, nil)
+		discoverData := NewDataDiscover(&fs, &part, &mocklu.MockWrapLVM{})
+		discoverData.SetDeviceGraph(reader)
+		return discoverData
+	}
+
+	t.Run("Device is used by software RAID", func(t *testing.T) {
+		discoverResult, err := prepare(&graphReader{graph: devicegraph.NewGraph(sda, md)}).
+			DiscoverData(device, serialNumber)
+		assert.Nil(t, err)
+		assert.True(t, discoverResult.HasData)
+		assert.Contains(t, discoverResult.Message, "/dev/md127, /mnt")
+	})
+	t.Run("Device is active swap", func(t *testing.T) {
+		swap := &devicegraph.Device{Name: "sda", Type: devicegraph.TypeDisk,
+			MountPoints: []string{devicegraph.SwapMountPoint}}
+		discoverResult, err := prepare(&graphReader{graph: devicegraph.NewGraph(swap)}).
+			DiscoverData(device, serialNumber)
+		assert.Nil(t, err)
+		assert.True(t, discoverResult.HasData)
+		assert.Contains(t, discoverResult.Message, devicegraph.SwapMountPoint)
+	})
+	t.Run("Device isn't found in sysfs", func(t *testing.T) {
+		discoverResult, err := prepare(&graphReader{graph: devicegraph.NewGraph()}).
+			DiscoverData(device, serialNumber)
+		assert.Nil(t, err)
+		assert.False(t, discoverResult.HasData)
+	})
+	t.Run("Graph read failed", func(t *testing.T) {
+		discoverResult, err := prepare(&graphReader{err: errors.New("error")}).DiscoverData(device, serialNumber)
+		assert.NotNil(t, err)
+		assert.Nil(t, discoverResult)
+	})
+}
+
+func TestWrapDataDiscoverImpl_DiscoverDataManagedPartitions(t *testing.T) {
+	var (
+		device       = "/dev/sda"
+		serialNumber = "test"
+		managedType  = "c5c4e9a1-2a2f-4c4e-9a8b-2f1f2b4d6e01"
+		linuxType    = "0fc63daf-8483-4772-8e79-3d69d8477de4"
+	)
+
+	prepare := func(partTypes map[string]string, err error) *WrapDataDiscoverImpl {
+		var (
+			fs   = mocklu.MockWrapFS{}
+			part = mocklu.MockWrapPartition{}
+		)
+		fs.On("GetFSType", device).Return("", nil)
+		part.On("DeviceHasPartitionTable", device).Return(true, nil)
+		part.On("GetPartitionTypes", device).Return(partTypes, err)
+		discoverData := NewDataDiscover(&fs, &part, &mocklu.MockWrapLVM{})
+		discoverData.SetManagedPartitionTypes([]string{strings.ToUpper(managedType)})
+		return discoverData
+	}
+
+	t.Run("All partitions are managed", func(t *testing.T) {
+		discoverResult, err := prepare(map[string]string{"1": managedType}, nil).DiscoverData(device, serialNumber)
+		assert.Nil(t, err)
+		assert.True(t, discoverResult.HasData)
+		assert.True(t, discoverResult.Managed)
+		assert.Contains(t, discoverResult.Message, "created by csi-baremetal")
+	})
+	t.Run("Drive has foreign partition", func(t *testing.T) {
+		discoverResult, err := prepare(map[string]string{"1": managedType, "2": linuxType}, nil).
+			DiscoverData(device, serialNumber)
+		assert.Nil(t, err)
+		assert.True(t, discoverResult.HasData)
+		assert.False(t, discoverResult.Managed)
+	})
+	t.Run("Partition table without partitions", func(t *testing.T) {
+		discoverResult, err := prepare(map[string]string{}, nil).DiscoverData(device, serialNumber)
+		assert.Nil(t, err)
+		assert.True(t, discoverResult.HasData)
+		assert.False(t, discoverResult.Managed)
+	})
+	t.Run("Partition types command failed", func(t *testing.T) {
+		discoverResult, err := prepare(nil, errors.New("error")).DiscoverData(device, serialNumber)
+		assert.NotNil(t, err)
+		assert.Nil(t, discoverResult)
+	})
+}
+
+func TestWrapDataDiscoverImpl_ScanSignatures(t *testing.T) {
+	var (
+		device = "/dev/sda"
+		fs     = mocklu.MockWrapFS{}
+	)
+	discoverData := NewDataDiscover(&fs, &mocklu.MockWrapPartition{}, &mocklu.MockWrapLVM{})
+
+	fs.On("GetFSType", device).Return("\nLVM2_member\ncrypto_LUKS\n", nil).Once()
+	fs.On("GetSignatures", device).Return([]string{"PMBR", "gpt"}, nil).Once()
+	signatures, err := discoverData.ScanSignatures(device)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"LVM2_member", "crypto_LUKS", "PMBR", "gpt"}, signatures)
+
+	fs.On("GetFSType", device).Return("", nil).Once()
+	fs.On("GetSignatures", device).Return(nil, nil).Once()
+	signatures, err = discoverData.ScanSignatures(device)
+	assert.Nil(t, err)
+	assert.Empty(t, signatures)
+
+	fs.On("GetFSType", device).Return("", nil).Once()
+	fs.On("GetSignatures", device).Return(nil, errors.New("error")).Once()
+	_, err = discoverData.ScanSignatures(device)
+	assert.NotNil(t, err)
+}
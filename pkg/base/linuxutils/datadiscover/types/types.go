@@ -20,10 +20,13 @@ package types
 // WrapDataDiscover is the interface which encapsulates method to discover data on drives
 type WrapDataDiscover interface {
 	DiscoverData(device, serialNumber string) (*DiscoverResult, error)
+	ScanSignatures(device string) ([]string, error)
 }
 
 // DiscoverResult encapsulates result of DiscoverData function
 type DiscoverResult struct {
 	Message string
 	HasData bool
+	// Managed is true if all partitions of the drive have partition type GUID of csi-baremetal
+	Managed bool
 }
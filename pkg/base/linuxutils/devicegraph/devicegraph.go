@@ -0,0 +1,408 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package devicegraph contains model of block devices graph built from sysfs holders, slaves and partitions,
+// mount points of devices in all mount namespaces and active swap. It answers questions such as "what sits on top
+// of /dev/sdb2" and "is this device free" without parsing of lsblk tree
+package devicegraph
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicepath"
+)
+
+// Types of block devices
+const (
+	TypeDisk   = "disk"
+	TypePart   = "part"
+	TypeDM     = "dm"
+	TypeMD     = "md"
+	TypeBcache = "bcache"
+	TypeLoop   = "loop"
+
+	// SysClassBlock is a sysfs directory with block devices
+	SysClassBlock = "/sys/class/block"
+	// MountInfo is a mountinfo of node process
+	MountInfo = "/proc/self/mountinfo"
+	// ProcDir contains mountinfo of every process, so mounts of other mount namespaces are found
+	ProcDir = "/proc"
+	// Swaps lists active swap devices and files
+	Swaps = "/proc/swaps"
+	// SwapMountPoint is a mount point of device which is active swap, the same as lsblk shows
+	SwapMountPoint = "[SWAP]"
+
+	devDir       = "/dev/"
+	devMapperDir = "/dev/mapper/"
+)
+
+// Device is a block device in the graph
+type Device struct {
+	// Name is a kernel name of device, e.g. sda1 or dm-3
+	Name string
+	// DevNum is major:minor of device
+	DevNum string
+	Type   string
+	// Alias is a device mapper name of dm device
+	Alias string
+	// Parent is a disk of partition
+	Parent *Device
+	// Partitions are partitions of disk
+	Partitions []*Device
+	// Holders are devices which use the device, e.g. LVM LV on PV
+	Holders []*Device
+	// Slaves are devices which are used by the device, e.g. PVs of LVM LV
+	Slaves []*Device
+	// MountPoints are mount points of device in all mount namespaces, SwapMountPoint if device is active swap
+	MountPoints []string
+}
+
+// Path returns path of device file
+func (d *Device) Path() string {
+	return devDir + d.Name
+}
+
+// IsHeld returns true if device is used by another device or is mounted
+func (d *Device) IsHeld() bool {
+	return len(d.Holders) > 0 || len(d.MountPoints) > 0
+}
+
+// IsFree returns true if device doesn't have partitions, holders and mount points
+func (d *Device) IsFree() bool {
+	return !d.IsHeld() && len(d.Partitions) == 0
+}
+
+// Graph is a graph of block devices of the node
+type Graph struct {
+	devices map[string]*Device
+}
+
+// NewGraph returns graph of devices which are linked with each other already
+func NewGraph(devices ...*Device) *Graph {
+	g := &Graph{devices: make(map[string]*Device, len(devices))}
+	for _, dev := range devices {
+		g.devices[dev.Name] = dev
+	}
+	return g
+}
+
+// Get returns device by path (/dev/sda1, /dev/mapper/vg-lv, /dev/disk/by-id/...) or kernel name,
+// nil if device isn't found
+func (g *Graph) Get(device string) *Device {
+	if strings.HasPrefix(device, devMapperDir) {
+		alias := strings.TrimPrefix(device, devMapperDir)
+		for _, dev := range g.devices {
+			if dev.Alias == alias {
+				return dev
+			}
+		}
+		return nil
+	}
+	if dev, ok := g.devices[strings.TrimPrefix(device, devDir)]; ok {
+		return dev
+	}
+	// udev symlinks such as /dev/disk/by-id/... are resolved to kernel name
+	if path, err := devicepath.Canonicalize(device); err == nil {
+		return g.devices[strings.TrimPrefix(path, devDir)]
+	}
+	return nil
+}
+
+// Above returns partitions and holders of device transitively. Each device goes before device which it sits on,
+// so devices can be removed in order. Returns nil if device isn't found
+func (g *Graph) Above(device string) []*Device {
+	dev := g.Get(device)
+	if dev == nil {
+		return nil
+	}
+	var (
+		res     []*Device
+		visited = map[*Device]bool{dev: true}
+	)
+	var walk func(d *Device)
+	walk = func(d *Device) {
+		for _, upper := range append(append([]*Device{}, d.Partitions...), d.Holders...) {
+			if visited[upper] {
+				continue
+			}
+			visited[upper] = true
+			walk(upper)
+			res = append(res, upper)
+		}
+	}
+	walk(dev)
+	return res
+}
+
+// Below returns devices which device sits on transitively: slaves and disk of partition
+// Returns nil if device isn't found
+func (g *Graph) Below(device string) []*Device {
+	dev := g.Get(device)
+	if dev == nil {
+		return nil
+	}
+	var (
+		res     []*Device
+		visited = map[*Device]bool{dev: true}
+	)
+	var walk func(d *Device)
+	walk = func(d *Device) {
+		lower := append([]*Device{}, d.Slaves...)
+		if d.Parent != nil {
+			lower = append(lower, d.Parent)
+		}
+		for _, l := range lower {
+			if visited[l] {
+				continue
+			}
+			visited[l] = true
+			res = append(res, l)
+			walk(l)
+		}
+	}
+	walk(dev)
+	return res
+}
+
+// IsFree returns true if device exists and doesn't have partitions, holders and mount points
+func (g *Graph) IsFree(device string) bool {
+	dev := g.Get(device)
+	return dev != nil && dev.IsFree()
+}
+
+// Users returns paths of devices which sit on top of device or its partitions and mount points of all of them.
+// Partitions aren't users, device with partitions only isn't used. Returns nil if device isn't found
+func (g *Graph) Users(device string) []string {
+	dev := g.Get(device)
+	if dev == nil {
+		return nil
+	}
+	users := append([]string{}, dev.MountPoints...)
+	for _, upper := range g.Above(device) {
+		if upper.Type != TypePart {
+			users = append(users, upper.Path())
+		}
+		users = append(users, upper.MountPoints...)
+	}
+	return users
+}
+
+// Reader reads graph of block devices
+type Reader interface {
+	Read() (*Graph, error)
+}
+
+// SysfsReader reads graph of block devices from sysfs, mountinfo and swaps
+type SysfsReader struct {
+	SysClassBlock string
+	MountInfo     string
+	// ProcDir is used to read mountinfo of other mount namespaces, only MountInfo is read if empty
+	ProcDir string
+	// Swaps is used to find active swap devices, swap isn't checked if empty
+	Swaps string
+}
+
+// NewSysfsReader is a constructor for SysfsReader which reads /sys/class/block, mountinfo of all processes
+// and /proc/swaps
+func NewSysfsReader() *SysfsReader {
+	return &SysfsReader{SysClassBlock: SysClassBlock, MountInfo: MountInfo, ProcDir: ProcDir, Swaps: Swaps}
+}
+
+// Read builds graph of block devices
+// Returns error if sysfs, mountinfo of node process or swaps can't be read
+func (r *SysfsReader) Read() (*Graph, error) {
+	entries, err := ioutil.ReadDir(r.SysClassBlock)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read block devices from %s: %w", r.SysClassBlock, err)
+	}
+	mounts := make(map[string][]string)
+	if err = readMounts(r.MountInfo, mounts); err != nil {
+		return nil, err
+	}
+	for _, mountInfo := range r.namespaceMountInfos() {
+		// process might exit during scan
+		_ = readMounts(mountInfo, mounts)
+	}
+	swaps, err := r.readSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{devices: make(map[string]*Device, len(entries))}
+	for _, entry := range entries {
+		dev := r.readDevice(entry.Name(), mounts)
+		if swaps[dev.Name] {
+			dev.MountPoints = append(dev.MountPoints, SwapMountPoint)
+		}
+		g.devices[entry.Name()] = dev
+	}
+	for name, dev := range g.devices {
+		dir := filepath.Join(r.SysClassBlock, name)
+		for _, holder := range readNames(filepath.Join(dir, "holders")) {
+			if h, ok := g.devices[holder]; ok {
+				dev.Holders = append(dev.Holders, h)
+			}
+		}
+		for _, slave := range readNames(filepath.Join(dir, "slaves")) {
+			if s, ok := g.devices[slave]; ok {
+				dev.Slaves = append(dev.Slaves, s)
+			}
+		}
+		if dev.Type != TypePart {
+			continue
+		}
+		// sysfs directory of partition is located in directory of its disk
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			if parent, ok := g.devices[filepath.Base(filepath.Dir(real))]; ok {
+				dev.Parent = parent
+				parent.Partitions = append(parent.Partitions, dev)
+			}
+		}
+	}
+	return g, nil
+}
+
+// readDevice reads attributes of device from sysfs
+func (r *SysfsReader) readDevice(name string, mounts map[string][]string) *Device {
+	dir := filepath.Join(r.SysClassBlock, name)
+	dev := &Device{Name: name, Type: TypeDisk}
+	if devNum, err := ioutil.ReadFile(filepath.Join(dir, "dev")); err == nil {
+		dev.DevNum = strings.TrimSpace(string(devNum))
+		dev.MountPoints = append([]string{}, mounts[dev.DevNum]...)
+	}
+	switch {
+	case fileExists(filepath.Join(dir, "partition")):
+		dev.Type = TypePart
+	case strings.HasPrefix(name, "dm-"):
+		dev.Type = TypeDM
+		if alias, err := ioutil.ReadFile(filepath.Join(dir, "dm", "name")); err == nil {
+			dev.Alias = strings.TrimSpace(string(alias))
+		}
+	case strings.HasPrefix(name, "md"):
+		dev.Type = TypeMD
+	case strings.HasPrefix(name, "bcache"):
+		dev.Type = TypeBcache
+	case strings.HasPrefix(name, "loop"):
+		dev.Type = TypeLoop
+	}
+	return dev
+}
+
+// readNames returns names of entries in directory, empty slice if directory doesn't exist
+func readNames(dir string) []string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// fileExists returns true if file exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// namespaceMountInfos returns mountinfo of one process per mount namespace except namespace of node process
+// Processes of host are visible if node service shares PID namespace of host
+func (r *SysfsReader) namespaceMountInfos() []string {
+	if r.ProcDir == "" {
+		return nil
+	}
+	self, _ := os.Readlink(filepath.Join(r.ProcDir, "self", "ns", "mnt"))
+	visited := map[string]bool{self: true}
+	var res []string
+	for _, pid := range readNames(r.ProcDir) {
+		if _, err := strconv.Atoi(pid); err != nil {
+			continue
+		}
+		ns, err := os.Readlink(filepath.Join(r.ProcDir, pid, "ns", "mnt"))
+		if err != nil || visited[ns] {
+			continue
+		}
+		visited[ns] = true
+		res = append(res, filepath.Join(r.ProcDir, pid, "mountinfo"))
+	}
+	return res
+}
+
+// readSwaps returns kernel names of block devices which are active swap
+func (r *SysfsReader) readSwaps() (map[string]bool, error) {
+	swaps := make(map[string]bool)
+	if r.Swaps == "" {
+		return swaps, nil
+	}
+	content, err := ioutil.ReadFile(r.Swaps)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read active swap from %s: %w", r.Swaps, err)
+	}
+	// Filename				Type		Size	Used	Priority
+	// /dev/sdb2                               partition	8388604	0	-2
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[1] != "partition" {
+			// swap files are found by mount point of their filesystem
+			continue
+		}
+		path := fields[0]
+		if real, err := filepath.EvalSymlinks(path); err == nil {
+			path = real
+		}
+		swaps[strings.TrimPrefix(path, devDir)] = true
+	}
+	return swaps, nil
+}
+
+// readMounts reads mountinfo and adds mount points to mounts per major:minor of mounted device
+func readMounts(path string, mounts map[string][]string) error {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || containsString(mounts[fields[2]], fields[4]) {
+			continue
+		}
+		mounts[fields[2]] = append(mounts[fields[2]], fields[4])
+	}
+	return scanner.Err()
+}
+
+// containsString returns true if items contain item
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devicegraph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// prepareSysfs creates sysfs tree like kernel does: devices are located in /sys/devices and
+// /sys/class/block contains symlinks to them, partition directory is located in directory of its disk
+// sda: sda1 <- dm-0 (vg-lv, mounted to /mnt/lv), sda2 is mounted to /mnt/sda2
+// sdb <- md127, sdc is free
+func prepareSysfs(t *testing.T) *SysfsReader {
+	root := t.TempDir()
+	devices := filepath.Join(root, "devices")
+	classBlock := filepath.Join(root, "class", "block")
+	writeFile := func(path, content string) {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(devices, path)), 0700))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(devices, path), []byte(content), 0600))
+	}
+	link := func(name, path string) {
+		assert.Nil(t, os.Symlink(filepath.Join(devices, path), filepath.Join(classBlock, name)))
+	}
+	assert.Nil(t, os.MkdirAll(classBlock, 0700))
+
+	writeFile("sda/dev", "8:0\n")
+	writeFile("sda/sda1/dev", "8:1\n")
+	writeFile("sda/sda1/partition", "1\n")
+	writeFile("sda/sda1/holders/dm-0", "")
+	writeFile("sda/sda2/dev", "8:2\n")
+	writeFile("sda/sda2/partition", "2\n")
+	writeFile("dm-0/dev", "253:0\n")
+	writeFile("dm-0/dm/name", "vg-lv\n")
+	writeFile("dm-0/slaves/sda1", "")
+	writeFile("sdb/dev", "8:16\n")
+	writeFile("sdb/holders/md127", "")
+	writeFile("md127/dev", "9:127\n")
+	writeFile("md127/slaves/sdb", "")
+	writeFile("sdc/dev", "8:32\n")
+	for name, path := range map[string]string{
+		"sda": "sda", "sda1": "sda/sda1", "sda2": "sda/sda2", "dm-0": "dm-0",
+		"sdb": "sdb", "md127": "md127", "sdc": "sdc",
+	} {
+		link(name, path)
+	}
+
+	mountInfo := filepath.Join(root, "mountinfo")
+	assert.Nil(t, ioutil.WriteFile(mountInfo, []byte("22 1 8:48 / / rw - ext4 /dev/sdd rw\n"+
+		"30 22 253:0 / /mnt/lv rw - xfs /dev/mapper/vg-lv rw\n"+
+		"31 22 8:2 / /mnt/sda2 rw - xfs /dev/sda2 rw\n"), 0600))
+	return &SysfsReader{SysClassBlock: classBlock, MountInfo: mountInfo}
+}
+
+func names(devices []*Device) []string {
+	res := make([]string, 0, len(devices))
+	for _, dev := range devices {
+		res = append(res, dev.Name)
+	}
+	return res
+}
+
+func TestSysfsReader_Read(t *testing.T) {
+	g, err := prepareSysfs(t).Read()
+	assert.Nil(t, err)
+
+	sda := g.Get("/dev/sda")
+	assert.NotNil(t, sda)
+	assert.Equal(t, TypeDisk, sda.Type)
+	assert.ElementsMatch(t, []string{"sda1", "sda2"}, names(sda.Partitions))
+
+	sda1 := g.Get("sda1")
+	assert.Equal(t, TypePart, sda1.Type)
+	assert.Equal(t, sda, sda1.Parent)
+	assert.Equal(t, "/dev/sda1", sda1.Path())
+
+	lv := g.Get("/dev/mapper/vg-lv")
+	assert.NotNil(t, lv)
+	assert.Equal(t, "dm-0", lv.Name)
+	assert.Equal(t, TypeDM, lv.Type)
+	assert.Equal(t, []string{"/mnt/lv"}, lv.MountPoints)
+	assert.Equal(t, []*Device{sda1}, lv.Slaves)
+
+	assert.Equal(t, TypeMD, g.Get("md127").Type)
+	assert.Nil(t, g.Get("/dev/sdd"))
+	assert.Nil(t, g.Get("/dev/mapper/not-exists"))
+
+	_, err = (&SysfsReader{SysClassBlock: "/not/exists", MountInfo: MountInfo}).Read()
+	assert.NotNil(t, err)
+}
+
+func TestSysfsReader_ReadNamespacesAndSwap(t *testing.T) {
+	var (
+		reader  = prepareSysfs(t)
+		procDir = t.TempDir()
+		swaps   = filepath.Join(procDir, "swaps")
+		process = func(pid, ns, mountInfo string) {
+			assert.Nil(t, os.MkdirAll(filepath.Join(procDir, pid, "ns"), 0700))
+			assert.Nil(t, os.Symlink(ns, filepath.Join(procDir, pid, "ns", "mnt")))
+			assert.Nil(t, ioutil.WriteFile(filepath.Join(procDir, pid, "mountinfo"), []byte(mountInfo), 0600))
+		}
+	)
+	// node process and container in the same namespace, host namespace mounts sdc and sda2 too
+	process("self", "mnt:[1]", "")
+	process("10", "mnt:[1]", "40 22 8:32 / /not/read rw - xfs /dev/sdc rw\n")
+	process("1", "mnt:[2]", "22 1 8:48 / / rw - ext4 /dev/sdd rw\n"+
+		"50 22 8:32 / /var/lib/data rw - xfs /dev/sdc rw\n"+
+		"51 22 8:2 / /mnt/sda2 rw - xfs /dev/sda2 rw\n")
+	process("2", "mnt:[2]", "52 22 8:32 / /not/read rw - xfs /dev/sdc rw\n")
+	assert.Nil(t, os.MkdirAll(filepath.Join(procDir, "sys"), 0700))
+	assert.Nil(t, ioutil.WriteFile(swaps, []byte("Filename\tType\tSize\tUsed\tPriority\n"+
+		"/dev/sdb\tpartition\t8388604\t0\t-2\n/swapfile\tfile\t1048572\t0\t-3\n"), 0600))
+	reader.ProcDir, reader.Swaps = procDir, swaps
+
+	g, err := reader.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/var/lib/data"}, g.Get("sdc").MountPoints)
+	assert.Equal(t, []string{"/mnt/sda2"}, g.Get("sda2").MountPoints)
+	assert.Equal(t, []string{SwapMountPoint}, g.Get("sdb").MountPoints)
+	assert.False(t, g.IsFree("/dev/sdc"))
+	assert.ElementsMatch(t, []string{"/dev/md127", SwapMountPoint}, g.Users("/dev/sdb"))
+
+	reader.Swaps = filepath.Join(procDir, "not-exists")
+	_, err = reader.Read()
+	assert.NotNil(t, err)
+}
+
+func TestGraph_Above(t *testing.T) {
+	g, err := prepareSysfs(t).Read()
+	assert.Nil(t, err)
+
+	above := names(g.Above("/dev/sda"))
+	assert.ElementsMatch(t, []string{"sda1", "sda2", "dm-0"}, above)
+	// holder goes before device which it sits on
+	assert.Less(t, indexOf(above, "dm-0"), indexOf(above, "sda1"))
+
+	assert.Equal(t, []string{"dm-0"}, names(g.Above("/dev/sda1")))
+	assert.Equal(t, []string{"md127"}, names(g.Above("/dev/sdb")))
+	assert.Empty(t, g.Above("/dev/sdc"))
+	assert.Nil(t, g.Above("/dev/sdd"))
+}
+
+func TestGraph_Below(t *testing.T) {
+	g, err := prepareSysfs(t).Read()
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"sda1", "sda"}, names(g.Below("/dev/mapper/vg-lv")))
+	assert.Equal(t, []string{"sdb"}, names(g.Below("/dev/md127")))
+	assert.Empty(t, g.Below("/dev/sdc"))
+	assert.Nil(t, g.Below("/dev/sdd"))
+}
+
+func TestGraph_IsFree(t *testing.T) {
+	g, err := prepareSysfs(t).Read()
+	assert.Nil(t, err)
+
+	assert.False(t, g.IsFree("/dev/sda"))
+	assert.False(t, g.IsFree("/dev/sda1"))
+	assert.False(t, g.IsFree("/dev/sda2"))
+	assert.False(t, g.IsFree("/dev/sdb"))
+	assert.False(t, g.IsFree("/dev/mapper/vg-lv"))
+	assert.True(t, g.IsFree("/dev/sdc"))
+	assert.True(t, g.IsFree("/dev/md127"))
+	assert.False(t, g.IsFree("/dev/sdd"))
+}
+
+func TestGraph_Users(t *testing.T) {
+	g, err := prepareSysfs(t).Read()
+	assert.Nil(t, err)
+
+	assert.ElementsMatch(t, []string{"/dev/dm-0", "/mnt/lv", "/mnt/sda2"}, g.Users("/dev/sda"))
+	assert.Equal(t, []string{"/dev/dm-0", "/mnt/lv"}, g.Users("/dev/sda1"))
+	assert.Equal(t, []string{"/dev/md127"}, g.Users("/dev/sdb"))
+	assert.Empty(t, g.Users("/dev/sdc"))
+	assert.Nil(t, g.Users("/dev/sdd"))
+}
+
+func indexOf(items []string, item string) int {
+	for i := range items {
+		if items[i] == item {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,242 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package devicepath canonicalizes references to block devices: kernel names, device files, udev symlinks
+// (/dev/disk/by-id, /dev/disk/by-path), LVM and device mapper paths and partitions of disks with different
+// naming conventions (sdb1, nvme0n1p1, mmcblk0p1), so the same device always has the same identity
+package devicepath
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DevDir is a directory with device files
+	DevDir = "/dev"
+	// SysClassBlock is a sysfs directory with block devices
+	SysClassBlock = "/sys/class/block"
+
+	mapperPrefix = "mapper/"
+	dmPrefix     = "dm-"
+)
+
+var (
+	// disks which names end with digit, partition name is <disk>p<number>, e.g. nvme0n1p1, mmcblk0p1, loop0p1
+	digitDiskRegexp = regexp.MustCompile(`^((?:nvme[0-9]+n|mmcblk|loop|md|nbd|rbd|zd|pmem)[0-9]+)(?:p([0-9]+))?$`)
+	// disks which names end with letter, partition name is <disk><number>, e.g. sdb1, vda1, xvdf1
+	letterDiskRegexp = regexp.MustCompile(`^((?:sd|hd|vd|xvd)[a-z]+)([0-9]+)?$`)
+
+	defaultResolver = NewResolver()
+)
+
+// Identity is a normalized identity of block device
+type Identity struct {
+	// Name is a kernel name of device, e.g. sdb1, nvme0n1p1 or dm-3
+	Name string
+	// Disk is a kernel name of disk of partition, equals to Name for whole device
+	Disk string
+	// Partition is a number of partition, empty for whole device
+	Partition string
+}
+
+// Path returns path of device file, e.g. /dev/nvme0n1p1
+func (id Identity) Path() string {
+	return path.Join(DevDir, id.Name)
+}
+
+// DiskPath returns path of device file of disk, e.g. /dev/nvme0n1 for /dev/nvme0n1p1
+func (id Identity) DiskPath() string {
+	return path.Join(DevDir, id.Disk)
+}
+
+// IsPartition returns true if device is a partition of disk
+func (id Identity) IsPartition() bool {
+	return id.Partition != ""
+}
+
+// SplitPartition splits kernel name of partition into kernel name of disk and number of partition
+// according to naming conventions, e.g. sdb1 -> sdb, 1 and nvme0n1p2 -> nvme0n1, 2
+// Returns name itself and false for whole device or unknown naming convention
+func SplitPartition(name string) (disk, num string, ok bool) {
+	for _, re := range []*regexp.Regexp{digitDiskRegexp, letterDiskRegexp} {
+		if m := re.FindStringSubmatch(name); m != nil && m[2] != "" {
+			return m[1], m[2], true
+		}
+	}
+	return name, "", false
+}
+
+// PartitionName returns name or path of partition of disk like kernel does: "p" separates number of partition
+// if name of disk ends with digit, e.g. sdb1 for sdb, nvme0n1p1 for nvme0n1 and /dev/mmcblk0p1 for /dev/mmcblk0
+func PartitionName(disk, num string) string {
+	if disk != "" && disk[len(disk)-1] >= '0' && disk[len(disk)-1] <= '9' {
+		return disk + "p" + num
+	}
+	return disk + num
+}
+
+// Resolver resolves references to block devices using device files and sysfs
+type Resolver struct {
+	DevDir        string
+	SysClassBlock string
+}
+
+// NewResolver is a constructor for Resolver which uses /dev and /sys/class/block
+func NewResolver() *Resolver {
+	return &Resolver{DevDir: DevDir, SysClassBlock: SysClassBlock}
+}
+
+// Resolve returns identity of device referenced by kernel name (nvme0n1p1), device file (/dev/sdb1),
+// udev symlink (/dev/disk/by-id/...), LVM path (/dev/vg/lv) or device mapper path (/dev/mapper/vg-lv).
+// Disk of partition is taken from sysfs, naming conventions are used if device isn't present in sysfs
+// Returns error if reference is malformed or symlink can't be resolved
+func (r *Resolver) Resolve(ref string) (Identity, error) {
+	name, err := r.kernelName(ref)
+	if err != nil {
+		return Identity{}, err
+	}
+	id := Identity{Name: name, Disk: name}
+	dir := filepath.Join(r.SysClassBlock, name)
+	if num, err := ioutil.ReadFile(filepath.Join(dir, "partition")); err == nil {
+		// sysfs directory of partition is located in directory of its disk
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			id.Disk = filepath.Base(filepath.Dir(real))
+			id.Partition = strings.TrimSpace(string(num))
+			return id, nil
+		}
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return id, nil
+	}
+	id.Disk, id.Partition, _ = SplitPartition(name)
+	return id, nil
+}
+
+// Canonicalize returns path of device file with kernel name of device, e.g. /dev/nvme0n1p1 for
+// /dev/disk/by-id/nvme-XXX-part1 or /dev/dm-3 for /dev/mapper/vg-lv
+// Returns error if reference can't be resolved
+func (r *Resolver) Canonicalize(ref string) (string, error) {
+	id, err := r.Resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	return id.Path(), nil
+}
+
+// Same returns true if both references point to the same device
+func (r *Resolver) Same(ref1, ref2 string) bool {
+	id1, err := r.Resolve(ref1)
+	if err != nil {
+		return false
+	}
+	id2, err := r.Resolve(ref2)
+	return err == nil && id1.Name == id2.Name
+}
+
+// PartitionSuffix returns suffix which is appended to kernel name of disk to get kernel name of its partition,
+// e.g. "1" for /dev/sdb and /dev/sdb1, "p1" for /dev/disk/by-id/nvme-XXX and /dev/nvme0n1p1
+// Returns error if partition doesn't belong to disk
+func (r *Resolver) PartitionSuffix(disk, partition string) (string, error) {
+	diskID, err := r.Resolve(disk)
+	if err != nil {
+		return "", err
+	}
+	partID, err := r.Resolve(partition)
+	if err != nil {
+		return "", err
+	}
+	if !partID.IsPartition() || partID.Disk != diskID.Name {
+		return "", fmt.Errorf("device %s isn't a partition of %s", partition, disk)
+	}
+	return strings.TrimPrefix(partID.Name, partID.Disk), nil
+}
+
+// kernelName returns kernel name of device, symlinks in /dev are resolved
+func (r *Resolver) kernelName(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", errors.New("device reference is empty")
+	}
+	if !strings.Contains(ref, "/") {
+		ref = path.Join(DevDir, ref)
+	}
+	if !path.IsAbs(ref) {
+		return "", fmt.Errorf("device reference %q must be an absolute path or a kernel name", ref)
+	}
+	ref = path.Clean(ref)
+	rel := strings.TrimPrefix(ref, DevDir+"/")
+	if rel == ref {
+		return "", fmt.Errorf("device %s is not located in %s", ref, DevDir)
+	}
+	// udev and LVM symlinks are relative, e.g. /dev/disk/by-id/wwn-XXX -> ../../sdb
+	if real, err := filepath.EvalSymlinks(filepath.Join(r.DevDir, rel)); err == nil {
+		if devDir, err := filepath.EvalSymlinks(r.DevDir); err == nil {
+			if resolved, err := filepath.Rel(devDir, real); err == nil && !strings.HasPrefix(resolved, "..") {
+				rel = resolved
+			}
+		}
+	}
+	// files in /dev/mapper might be device nodes instead of symlinks
+	if strings.HasPrefix(rel, mapperPrefix) {
+		if name, ok := r.dmName(strings.TrimPrefix(rel, mapperPrefix)); ok {
+			rel = name
+		}
+	}
+	if strings.Contains(rel, "/") {
+		return "", fmt.Errorf("unable to resolve kernel name of device %s", ref)
+	}
+	return rel, nil
+}
+
+// dmName returns kernel name of device mapper device by its name, e.g. dm-3 for vg-lv
+func (r *Resolver) dmName(alias string) (string, bool) {
+	entries, err := ioutil.ReadDir(r.SysClassBlock)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), dmPrefix) {
+			continue
+		}
+		name, err := ioutil.ReadFile(filepath.Join(r.SysClassBlock, entry.Name(), "dm", "name"))
+		if err == nil && strings.TrimSpace(string(name)) == alias {
+			return entry.Name(), true
+		}
+	}
+	return "", false
+}
+
+// Resolve returns identity of device using /dev and /sys/class/block of the node
+func Resolve(ref string) (Identity, error) {
+	return defaultResolver.Resolve(ref)
+}
+
+// Canonicalize returns path of device file with kernel name of device using /dev and /sys/class/block of the node
+func Canonicalize(ref string) (string, error) {
+	return defaultResolver.Canonicalize(ref)
+}
+
+// PartitionSuffix returns suffix of partition name using /dev and /sys/class/block of the node
+func PartitionSuffix(disk, partition string) (string, error) {
+	return defaultResolver.PartitionSuffix(disk, partition)
+}
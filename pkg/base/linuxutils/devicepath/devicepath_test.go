@@ -0,0 +1,168 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devicepath
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// prepareResolver creates /dev and sysfs trees like udev and kernel do:
+// sdb: sdb1, nvme0n1: nvme0n1p1, dm-0 is vg-lv, by-id and LVM symlinks point to them
+func prepareResolver(t *testing.T) *Resolver {
+	root := t.TempDir()
+	dev := filepath.Join(root, "dev")
+	devices := filepath.Join(root, "sys", "devices")
+	classBlock := filepath.Join(root, "sys", "class", "block")
+	writeFile := func(path, content string) {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+	}
+	link := func(target, path string) {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.Nil(t, os.Symlink(target, path))
+	}
+
+	for _, name := range []string{"sdb", "sdb1", "nvme0n1", "nvme0n1p1", "dm-0"} {
+		writeFile(filepath.Join(dev, name), "")
+	}
+	link("../../sdb", filepath.Join(dev, "disk", "by-id", "wwn-0x5000"))
+	link("../../nvme0n1p1", filepath.Join(dev, "disk", "by-path", "pci-0000:01:00.0-nvme-1-part1"))
+	link("../dm-0", filepath.Join(dev, "vg", "lv"))
+	// device node instead of symlink
+	writeFile(filepath.Join(dev, "mapper", "vg-lv"), "")
+
+	writeFile(filepath.Join(devices, "sdb", "dev"), "8:16\n")
+	writeFile(filepath.Join(devices, "sdb", "sdb1", "partition"), "1\n")
+	writeFile(filepath.Join(devices, "nvme0n1", "dev"), "259:0\n")
+	writeFile(filepath.Join(devices, "nvme0n1", "nvme0n1p1", "partition"), "1\n")
+	writeFile(filepath.Join(devices, "dm-0", "dm", "name"), "vg-lv\n")
+	for name, path := range map[string]string{
+		"sdb": "sdb", "sdb1": "sdb/sdb1", "nvme0n1": "nvme0n1", "nvme0n1p1": "nvme0n1/nvme0n1p1", "dm-0": "dm-0",
+	} {
+		link(filepath.Join(devices, path), filepath.Join(classBlock, name))
+	}
+	return &Resolver{DevDir: dev, SysClassBlock: classBlock}
+}
+
+func TestSplitPartition(t *testing.T) {
+	for name, expected := range map[string][]string{
+		"sdb1":       {"sdb", "1"},
+		"sdaa12":     {"sdaa", "12"},
+		"xvdf1":      {"xvdf", "1"},
+		"nvme0n1p2":  {"nvme0n1", "2"},
+		"nvme10n1p1": {"nvme10n1", "1"},
+		"mmcblk0p1":  {"mmcblk0", "1"},
+		"loop0p3":    {"loop0", "3"},
+		"md127p1":    {"md127", "1"},
+	} {
+		disk, num, ok := SplitPartition(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, []string{disk, num}, name)
+	}
+
+	for _, name := range []string{"sdb", "nvme0n1", "nvme0n10", "mmcblk0", "loop10", "md127", "dm-3", "sr0", ""} {
+		disk, num, ok := SplitPartition(name)
+		assert.False(t, ok, name)
+		assert.Equal(t, name, disk)
+		assert.Equal(t, "", num)
+	}
+}
+
+func TestPartitionName(t *testing.T) {
+	assert.Equal(t, "sdb1", PartitionName("sdb", "1"))
+	assert.Equal(t, "nvme0n1p1", PartitionName("nvme0n1", "1"))
+	assert.Equal(t, "/dev/mmcblk0p2", PartitionName("/dev/mmcblk0", "2"))
+	assert.Equal(t, "/dev/sdaa3", PartitionName("/dev/sdaa", "3"))
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	r := prepareResolver(t)
+
+	for ref, expected := range map[string]Identity{
+		"sdb":                        {Name: "sdb", Disk: "sdb"},
+		"/dev/sdb1":                  {Name: "sdb1", Disk: "sdb", Partition: "1"},
+		"/dev/disk/by-id/wwn-0x5000": {Name: "sdb", Disk: "sdb"},
+		"/dev/disk/by-path/pci-0000:01:00.0-nvme-1-part1": {Name: "nvme0n1p1", Disk: "nvme0n1", Partition: "1"},
+		"/dev/vg/lv":        {Name: "dm-0", Disk: "dm-0"},
+		"/dev/mapper/vg-lv": {Name: "dm-0", Disk: "dm-0"},
+		" /dev//sdb1 ":      {Name: "sdb1", Disk: "sdb", Partition: "1"},
+		// device isn't present, naming conventions are used
+		"/dev/mmcblk0p1": {Name: "mmcblk0p1", Disk: "mmcblk0", Partition: "1"},
+		"/dev/sdc":       {Name: "sdc", Disk: "sdc"},
+	} {
+		id, err := r.Resolve(ref)
+		assert.Nil(t, err, ref)
+		assert.Equal(t, expected, id, ref)
+	}
+
+	for _, ref := range []string{"", "dev/sdb", "/tmp/sdb", "/dev", "/dev/disk/by-id/missing", "/dev/mapper/missing"} {
+		_, err := r.Resolve(ref)
+		assert.NotNil(t, err, ref)
+	}
+}
+
+func TestResolver_Canonicalize(t *testing.T) {
+	r := prepareResolver(t)
+
+	path, err := r.Canonicalize("/dev/disk/by-path/pci-0000:01:00.0-nvme-1-part1")
+	assert.Nil(t, err)
+	assert.Equal(t, "/dev/nvme0n1p1", path)
+
+	path, err = r.Canonicalize("/dev/mapper/vg-lv")
+	assert.Nil(t, err)
+	assert.Equal(t, "/dev/dm-0", path)
+
+	_, err = r.Canonicalize("sdb/../sdc")
+	assert.NotNil(t, err)
+}
+
+func TestResolver_Same(t *testing.T) {
+	r := prepareResolver(t)
+
+	assert.True(t, r.Same("/dev/disk/by-id/wwn-0x5000", "sdb"))
+	assert.True(t, r.Same("/dev/vg/lv", "/dev/mapper/vg-lv"))
+	assert.False(t, r.Same("/dev/sdb", "/dev/sdb1"))
+	assert.False(t, r.Same("/dev/disk/by-id/missing", "/dev/disk/by-id/missing"))
+}
+
+func TestResolver_PartitionSuffix(t *testing.T) {
+	r := prepareResolver(t)
+
+	suffix, err := r.PartitionSuffix("/dev/disk/by-id/wwn-0x5000", "/dev/sdb1")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", suffix)
+
+	suffix, err = r.PartitionSuffix("/dev/nvme0n1", "/dev/disk/by-path/pci-0000:01:00.0-nvme-1-part1")
+	assert.Nil(t, err)
+	assert.Equal(t, "p1", suffix)
+
+	suffix, err = r.PartitionSuffix("/dev/mmcblk0", "/dev/mmcblk0p2")
+	assert.Nil(t, err)
+	assert.Equal(t, "p2", suffix)
+
+	_, err = r.PartitionSuffix("/dev/sdb", "/dev/nvme0n1p1")
+	assert.NotNil(t, err)
+	_, err = r.PartitionSuffix("/dev/sdb", "/dev/sdb")
+	assert.NotNil(t, err)
+	_, err = r.PartitionSuffix("/dev/sda", "/dev/sdaa1")
+	assert.NotNil(t, err)
+}
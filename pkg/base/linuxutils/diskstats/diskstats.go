@@ -0,0 +1,91 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diskstats contains code for reading of I/O statistics of block devices from /proc/diskstats
+package diskstats
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPath is a path of diskstats file
+	DefaultPath = "/proc/diskstats"
+	// SectorSize is a size of sector in diskstats, it doesn't depend on sector size of device
+	SectorSize = 512
+	// minFields is a number of fields in diskstats line before kernel 4.18
+	minFields = 14
+)
+
+// Stats is I/O statistics of block device, fields are cumulative since device appeared
+type Stats struct {
+	ReadsCompleted  uint64
+	ReadSectors     uint64
+	ReadTimeMs      uint64
+	WritesCompleted uint64
+	WriteSectors    uint64
+	WriteTimeMs     uint64
+	IOsInProgress   uint64
+	IOTimeMs        uint64
+}
+
+// Read reads diskstats file and returns statistics per kernel name of device
+// Returns error if file can't be read or has wrong format
+func Read(path string) (map[string]Stats, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	stats := make(map[string]Stats)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// 8  0 sda 1261 412 77190 1004 231 180 4352 372 0 1016 1376 ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < minFields {
+			return nil, fmt.Errorf("line %q of %s has %d fields, expected at least %d",
+				scanner.Text(), path, len(fields), minFields)
+		}
+		values := make([]uint64, minFields-3)
+		for i := range values {
+			if values[i], err = strconv.ParseUint(fields[i+3], 10, 64); err != nil {
+				return nil, fmt.Errorf("unable to parse line %q of %s: %v", scanner.Text(), path, err)
+			}
+		}
+		stats[fields[2]] = Stats{
+			ReadsCompleted:  values[0],
+			ReadSectors:     values[2],
+			ReadTimeMs:      values[3],
+			WritesCompleted: values[4],
+			WriteSectors:    values[6],
+			WriteTimeMs:     values[7],
+			IOsInProgress:   values[8],
+			IOTimeMs:        values[9],
+		}
+	}
+	return stats, scanner.Err()
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskstats
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "diskstats")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(
+		"   8       0 sda 1261 412 77190 1004 231 180 4352 372 0 1016 1376 0 0 0 0\n"+
+			"   8       1 sda1 100 0 800 50 20 0 160 30 1 70 80\n"), 0600))
+
+	stats, err := Read(path)
+	assert.Nil(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, Stats{
+		ReadsCompleted:  1261,
+		ReadSectors:     77190,
+		ReadTimeMs:      1004,
+		WritesCompleted: 231,
+		WriteSectors:    4352,
+		WriteTimeMs:     372,
+		IOsInProgress:   0,
+		IOTimeMs:        1016,
+	}, stats["sda"])
+	assert.Equal(t, uint64(1), stats["sda1"].IOsInProgress)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("8 0 sda 1 2 3\n"), 0600))
+	_, err = Read(path)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("8 0 sda 1 2 3 x 5 6 7 8 9 10 11\n"), 0600))
+	_, err = Read(path)
+	assert.NotNil(t, err)
+
+	_, err = Read(filepath.Join(t.TempDir(), "not-exists"))
+	assert.NotNil(t, err)
+}
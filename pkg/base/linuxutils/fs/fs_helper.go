@@ -23,6 +23,7 @@ import (
 	"path"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/dell/csi-baremetal/pkg/base/command"
@@ -39,6 +40,10 @@ const (
 	EXT4 FileSystem = "ext4"
 	// EXT3 file system
 	EXT3 FileSystem = "ext3"
+	// BTRFS file system
+	BTRFS FileSystem = "btrfs"
+	// F2FS file system
+	F2FS FileSystem = "f2fs"
 
 	// wipefs is a system utility
 	wipefs = "wipefs "
@@ -54,6 +59,8 @@ const (
 	RmDirCmdTmpl = "rm -rf %s"
 	// WipeFSCmdTmpl cmd for wiping FS on device
 	WipeFSCmdTmpl = wipefs + "-af %s" //
+	// GetSignaturesCmdTmpl cmd for listing signatures (filesystem, partition table, LVM PV, RAID, LUKS) on device
+	GetSignaturesCmdTmpl = wipefs + "--no-act --noheadings --output TYPE %s"
 	// GetFSTypeCmdTmpl cmd for detecting FS on device
 	GetFSTypeCmdTmpl = "lsblk %s --output FSTYPE --noheadings"
 	// MountInfoFile "/proc/mounts" path
@@ -64,6 +71,8 @@ const (
 	MountCmdTmpl = "mount %s %s %s"
 	// UnmountCmdTmpl unmount path template
 	UnmountCmdTmpl = "umount %s"
+	// LazyUnmountCmdTmpl detaches path from file system hierarchy, cleans up references when it isn't busy anymore
+	LazyUnmountCmdTmpl = "umount -l %s"
 	// BindOption option for mount operation
 	BindOption = "--bind"
 	// MountOptionsFlag flag to set mount options
@@ -73,17 +82,22 @@ const (
 // WrapFS is an interface that encapsulates operation with file systems
 type WrapFS interface {
 	GetFSSpace(src string) (int64, error)
+	GetFSStats(path string) (*FSStats, error)
 	MkDir(src string) error
 	MkFile(src string) error
 	RmDir(src string) error
 	CreateFS(fsType FileSystem, device string) error
+	ResizeFS(fsType FileSystem, device, mountPoint string) error
+	CheckFS(fsType FileSystem, device string) error
 	WipeFS(device string) error
 	GetFSType(device string) (string, error)
+	GetSignatures(device string) ([]string, error)
 	// Mount operations
 	IsMounted(src string) (bool, error)
 	FindMountPoint(target string) (string, error)
 	Mount(src, dst string, opts ...string) error
 	Unmount(src string) error
+	LazyUnmount(src string) error
 }
 
 // WrapFSImpl is a WrapFS implementer
@@ -107,7 +121,7 @@ func (h *WrapFSImpl) GetFSSpace(src string) (int64, error) {
 				/dev       7982M
 	*/
 
-	stdout, _, err := h.e.RunCmd(fmt.Sprintf(CheckSpaceCmdImpl, src),
+	stdout, _, err := h.e.RunCmd(command.NewCmd(CheckSpaceCmdImpl, command.Device(src)),
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(CheckSpaceCmdImpl, ""))))
 	if err != nil {
@@ -132,11 +146,39 @@ func (h *WrapFSImpl) GetFSSpace(src string) (int64, error) {
 	return 0, fmt.Errorf("wrong df output %s", stdout)
 }
 
+// FSStats holds capacity and inodes of mounted file system
+type FSStats struct {
+	TotalBytes     int64
+	AvailableBytes int64
+	UsedBytes      int64
+	TotalInodes    int64
+	FreeInodes     int64
+	UsedInodes     int64
+}
+
+// GetFSStats calls statfs on path and returns capacity and inodes of file system which path belongs to.
+// Available bytes are bytes available to unprivileged user, so reserved blocks are counted as used
+func (h *WrapFSImpl) GetFSStats(path string) (*FSStats, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return nil, fmt.Errorf("failed to get file system stats of %s: %w", path, err)
+	}
+	stats := &FSStats{
+		TotalBytes:     int64(st.Blocks) * st.Bsize,
+		AvailableBytes: int64(st.Bavail) * st.Bsize,
+		TotalInodes:    int64(st.Files),
+		FreeInodes:     int64(st.Ffree),
+	}
+	stats.UsedBytes = stats.TotalBytes - stats.AvailableBytes
+	stats.UsedInodes = stats.TotalInodes - stats.FreeInodes
+	return stats, nil
+}
+
 // MkDir creates specified path using mkdir if it doesn't exist
 // Receives directory path to create as a string
 // Returns error if something went wrong
 func (h *WrapFSImpl) MkDir(src string) error {
-	cmd := fmt.Sprintf(MkDirCmdTmpl, src)
+	cmd := command.NewCmd(MkDirCmdTmpl, command.Device(src))
 
 	if _, _, err := h.e.RunCmd(cmd,
 		command.UseMetrics(true),
@@ -176,7 +218,7 @@ func (h *WrapFSImpl) MkFile(src string) error {
 // Receives directory of file path to delete as a string
 // Returns error if something went wrong
 func (h *WrapFSImpl) RmDir(src string) error {
-	cmd := fmt.Sprintf(RmDirCmdTmpl, src)
+	cmd := command.NewCmd(RmDirCmdTmpl, command.Device(src))
 
 	if _, _, err := h.e.RunCmd(cmd,
 		command.UseMetrics(true),
@@ -186,21 +228,19 @@ func (h *WrapFSImpl) RmDir(src string) error {
 	return nil
 }
 
-// CreateFS creates specified file system on the provided device using mkfs
+// CreateFS creates specified file system on the provided device using mkfs, options are tuned for device geometry
 // Receives file system as a var of FileSystem type and path of the device as a string
 // Returns error if something went wrong
 func (h *WrapFSImpl) CreateFS(fsType FileSystem, device string) error {
-	var cmd string
-	switch fsType {
-	case XFS:
-		cmd = fmt.Sprintf(MkFSCmdTmpl, fsType, device)
-	case EXT3, EXT4:
-		cmd = fmt.Sprintf(MkFSCmdTmpl, fsType, device) + SpeedUpFsCreationOpts
-	default:
-		return fmt.Errorf("unsupported file system %v", fsType)
+	provider, err := GetProvider(fsType)
+	if err != nil {
+		return err
 	}
+	// default options are used if geometry can't be read
+	geometry, _ := h.GetDeviceGeometry(device)
+	cmd := provider.CreateCmd(device, geometry)
 
-	if _, _, err := h.e.RunCmd(cmd,
+	if _, _, err = h.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(MkFSCmdTmpl, "", "")))); err != nil {
 		return fmt.Errorf("failed to create file system on %s: %w", device, err)
@@ -208,11 +248,45 @@ func (h *WrapFSImpl) CreateFS(fsType FileSystem, device string) error {
 	return nil
 }
 
+// ResizeFS grows file system on the provided device up to size of the device
+// Receives file system as a var of FileSystem type, path of the device and mount point of file system as strings
+// Returns error if something went wrong
+func (h *WrapFSImpl) ResizeFS(fsType FileSystem, device, mountPoint string) error {
+	provider, err := GetProvider(fsType)
+	if err != nil {
+		return err
+	}
+	cmd := provider.ResizeCmd(device, mountPoint)
+	if _, _, err = h.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.Fields(cmd.String())[0])); err != nil {
+		return fmt.Errorf("failed to resize file system on %s: %w", device, err)
+	}
+	return nil
+}
+
+// CheckFS checks unmounted file system on the provided device without repairing
+// Receives file system as a var of FileSystem type and path of the device as a string
+// Returns error if file system is corrupted or something went wrong
+func (h *WrapFSImpl) CheckFS(fsType FileSystem, device string) error {
+	provider, err := GetProvider(fsType)
+	if err != nil {
+		return err
+	}
+	cmd := provider.CheckCmd(device)
+	if _, _, err = h.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.Fields(cmd.String())[0])); err != nil {
+		return fmt.Errorf("failed to check file system on %s: %w", device, err)
+	}
+	return nil
+}
+
 // WipeFS deletes file system from the provided device using wipefs
 // Receives file path of the device as a string
 // Returns error if something went wrong
 func (h *WrapFSImpl) WipeFS(device string) error {
-	cmd := fmt.Sprintf(WipeFSCmdTmpl, device)
+	cmd := command.NewCmd(WipeFSCmdTmpl, command.Device(device))
 
 	if _, _, err := h.e.RunCmd(cmd,
 		command.UseMetrics(true),
@@ -255,7 +329,7 @@ func (h *WrapFSImpl) FindMountPoint(target string) (string, error) {
 	*/
 
 	h.opMutex.Lock()
-	cmd := fmt.Sprintf(FindMntCmdTmpl, target)
+	cmd := command.NewCmd(FindMntCmdTmpl, command.Device(target))
 	h.opMutex.Unlock()
 
 	strOut, _, err := h.e.RunCmd(cmd,
@@ -271,7 +345,8 @@ func (h *WrapFSImpl) FindMountPoint(target string) (string, error) {
 // Receives source path and destination dir and also opts parameters that are used for mount command for example --bind
 // Returns error if something went wrong
 func (h *WrapFSImpl) Mount(src, dir string, opts ...string) error {
-	cmd := fmt.Sprintf(MountCmdTmpl, strings.Join(opts, " "), src, dir)
+	// options might be passed as one string, e.g. "-t tmpfs -o size=1M,ro", they don't contain user input
+	cmd := command.NewCmd(MountCmdTmpl, command.List(strings.Fields(strings.Join(opts, " "))), src, command.Device(dir))
 	h.opMutex.Lock()
 	_, _, err := h.e.RunCmd(cmd,
 		command.UseMetrics(true),
@@ -285,7 +360,7 @@ func (h *WrapFSImpl) Mount(src, dir string, opts ...string) error {
 // Receives path where the device is mounted
 // Returns error if something went wrong
 func (h *WrapFSImpl) Unmount(path string) error {
-	cmd := fmt.Sprintf(UnmountCmdTmpl, path)
+	cmd := command.NewCmd(UnmountCmdTmpl, command.Device(path))
 
 	h.opMutex.Lock()
 	_, _, err := h.e.RunCmd(cmd,
@@ -296,12 +371,27 @@ func (h *WrapFSImpl) Unmount(path string) error {
 	return err
 }
 
+// LazyUnmount detaches file system from the specified path even if it is busy, e.g. has open file handles
+// Receives path where the device is mounted
+// Returns error if something went wrong
+func (h *WrapFSImpl) LazyUnmount(path string) error {
+	cmd := command.NewCmd(LazyUnmountCmdTmpl, command.Device(path))
+
+	h.opMutex.Lock()
+	_, _, err := h.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(LazyUnmountCmdTmpl, ""))))
+	h.opMutex.Unlock()
+
+	return err
+}
+
 // GetFSType detect FS from the provided device using lsblk --output FSTYPE
 // Receives file path of the device as a string
 // Returns error if something went wrong
 func (h *WrapFSImpl) GetFSType(device string) (string, error) {
 	var (
-		cmd    = fmt.Sprintf(GetFSTypeCmdTmpl, device)
+		cmd    = command.NewCmd(GetFSTypeCmdTmpl, command.Device(device))
 		stdout string
 		err    error
 	)
@@ -312,3 +402,24 @@ func (h *WrapFSImpl) GetFSType(device string) (string, error) {
 	}
 	return strings.TrimSpace(stdout), err
 }
+
+// GetSignatures lists types of signatures which are found on the provided device by wipefs, device isn't modified
+// Receives file path of the device as a string
+// Returns unique signature types, e.g. "gpt", "LVM2_member", "crypto_LUKS", or error if something went wrong
+func (h *WrapFSImpl) GetSignatures(device string) ([]string, error) {
+	cmd := command.NewCmd(GetSignaturesCmdTmpl, command.Device(device))
+
+	stdout, _, err := h.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(GetSignaturesCmdTmpl, ""))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signatures on %s: %w", device, err)
+	}
+	var signatures []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !util.ContainsString(signatures, line) {
+			signatures = append(signatures, line)
+		}
+	}
+	return signatures, nil
+}
@@ -106,6 +106,19 @@ func TestGetFSSpace_Success(t *testing.T) {
 	assert.Equal(t, expectedRes, freeBytes)
 }
 
+func TestGetFSStats(t *testing.T) {
+	fh := NewFSImpl(&mocks.GoMockExecutor{})
+
+	stats, err := fh.GetFSStats(t.TempDir())
+	assert.Nil(t, err)
+	assert.True(t, stats.TotalBytes > 0)
+	assert.Equal(t, stats.TotalBytes, stats.UsedBytes+stats.AvailableBytes)
+	assert.Equal(t, stats.TotalInodes, stats.UsedInodes+stats.FreeInodes)
+
+	_, err = fh.GetFSStats("/not/existing/path")
+	assert.NotNil(t, err)
+}
+
 func TestMkDir(t *testing.T) {
 	var (
 		e   = &mocks.GoMockExecutor{}
@@ -151,9 +164,11 @@ func TestCreateFS(t *testing.T) {
 		device = "/dev/sda1"
 		fsType = XFS
 		cmd    = fmt.Sprintf(MkFSCmdTmpl, fsType, device)
+		geoCmd = fmt.Sprintf(DeviceGeometryCmdTmpl, device)
 		err    error
 	)
 
+	e.OnCommand(geoCmd).Return("4096 0 1 0 8001563222016", "", nil)
 	e.OnCommand(cmd).Return("", "", nil).Times(1)
 	err = fh.CreateFS(fsType, device)
 	assert.Nil(t, err)
@@ -227,6 +242,25 @@ func TestUnmount(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestLazyUnmount(t *testing.T) {
+	var (
+		e    = &mocks.GoMockExecutor{}
+		fh   = NewFSImpl(e)
+		path = "/mnt/pod1"
+		cmd  = fmt.Sprintf(LazyUnmountCmdTmpl, path)
+		err  error
+	)
+
+	e.OnCommand(cmd).Return("", "", nil).Times(1)
+	err = fh.LazyUnmount(path)
+	assert.Nil(t, err)
+
+	// cmd failed
+	e.OnCommand(cmd).Return("", "", testError).Times(1)
+	err = fh.LazyUnmount(path)
+	assert.NotNil(t, err)
+}
+
 func Test_GetFSType(t *testing.T) {
 	var (
 		e    = &mocks.GoMockExecutor{}
@@ -250,3 +284,26 @@ func Test_GetFSType(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "xfs", hasData)
 }
+
+func TestGetSignatures(t *testing.T) {
+	var (
+		e      = &mocks.GoMockExecutor{}
+		fh     = NewFSImpl(e)
+		device = "/dev/sda"
+		cmd    = fmt.Sprintf(GetSignaturesCmdTmpl, device)
+	)
+
+	e.OnCommand(cmd).Return("PMBR\ngpt\ngpt\n", "", nil).Times(1)
+	signatures, err := fh.GetSignatures(device)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"PMBR", "gpt"}, signatures)
+
+	e.OnCommand(cmd).Return("", "", nil).Times(1)
+	signatures, err = fh.GetSignatures(device)
+	assert.Nil(t, err)
+	assert.Empty(t, signatures)
+
+	e.OnCommand(cmd).Return("", "", testError).Times(1)
+	_, err = fh.GetSignatures(device)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+)
+
+const (
+	// DeviceGeometryCmdTmpl cmd for reading I/O size hints, rotational flag, discard support and size of device
+	DeviceGeometryCmdTmpl = "lsblk %s --bytes --nodeps --noheadings --output MIN-IO,OPT-IO,ROTA,DISC-MAX,SIZE"
+	// NoDiscardThreshold is a size of non-rotational device starting from which discard during mkfs is disabled,
+	// discard of the whole multi-TB SSD might take hours
+	NoDiscardThreshold int64 = 1024 * 1024 * 1024 * 1024 // 1TiB
+	// extBlockSize is a block size of ext3/ext4 on devices larger than 512MB, stride is calculated in blocks
+	extBlockSize = 4096
+	// extLazyInitOpts speed up creation of ext3 and ext4 FS
+	extLazyInitOpts = "lazy_journal_init=1,lazy_itable_init=1"
+)
+
+// DeviceGeometry holds properties of block device which affect mkfs options
+type DeviceGeometry struct {
+	// MinIOSize is a minimum I/O size, chunk size for striped devices
+	MinIOSize int64
+	// OptIOSize is an optimal I/O size, stripe width for striped devices
+	OptIOSize  int64
+	Rotational bool
+	// Discard is true if device supports discard
+	Discard bool
+	Size    int64
+}
+
+// IsStriped returns true if I/O hints of device describe stripe: optimal I/O size is multiple of minimum one
+func (g *DeviceGeometry) IsStriped() bool {
+	return g.MinIOSize >= extBlockSize && g.MinIOSize%extBlockSize == 0 &&
+		g.OptIOSize > g.MinIOSize && g.OptIOSize%g.MinIOSize == 0
+}
+
+// NeedNoDiscard returns true if discard of the whole device during mkfs should be skipped
+func (g *DeviceGeometry) NeedNoDiscard() bool {
+	return !g.Rotational && g.Discard && g.Size >= NoDiscardThreshold
+}
+
+// GetDeviceGeometry reads I/O size hints, rotational flag, discard support and size of device using lsblk,
+// lsblk reports hints of parent device for partitions and hints of striped LVs calculated by kernel
+// Receives file path of the device as a string
+// Returns DeviceGeometry or error if something went wrong
+func (h *WrapFSImpl) GetDeviceGeometry(device string) (*DeviceGeometry, error) {
+	/*
+		Example of output:
+			~# lsblk /dev/sda --bytes --nodeps --noheadings --output MIN-IO,OPT-IO,ROTA,DISC-MAX,SIZE
+			  4096      0    1        0 8001563222016
+	*/
+	cmd := command.NewCmd(DeviceGeometryCmdTmpl, command.Device(device))
+	stdout, _, err := h.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(fmt.Sprintf(DeviceGeometryCmdTmpl, "")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read geometry of %s: %w", device, err)
+	}
+
+	fields := strings.Fields(stdout)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("wrong lsblk output %s", stdout)
+	}
+	values := make([]int64, len(fields))
+	for i, field := range fields {
+		if values[i], err = strconv.ParseInt(field, 10, 64); err != nil {
+			return nil, fmt.Errorf("wrong lsblk output %s: %w", stdout, err)
+		}
+	}
+	return &DeviceGeometry{
+		MinIOSize:  values[0],
+		OptIOSize:  values[1],
+		Rotational: values[2] == 1,
+		Discard:    values[3] > 0,
+		Size:       values[4],
+	}, nil
+}
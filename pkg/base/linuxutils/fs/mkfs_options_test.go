@@ -0,0 +1,54 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestGetDeviceGeometry(t *testing.T) {
+	var (
+		e      = &mocks.GoMockExecutor{}
+		fh     = NewFSImpl(e)
+		device = "/dev/vg/lv"
+		cmd    = fmt.Sprintf(DeviceGeometryCmdTmpl, device)
+	)
+
+	e.OnCommand(cmd).Return("  65536 262144    0 2147450880 4000787030016\n", "", nil).Once()
+	geometry, err := fh.GetDeviceGeometry(device)
+	assert.Nil(t, err)
+	assert.Equal(t, &DeviceGeometry{
+		MinIOSize: 65536, OptIOSize: 262144, Rotational: false, Discard: true, Size: 4000787030016,
+	}, geometry)
+	assert.True(t, geometry.IsStriped())
+	assert.True(t, geometry.NeedNoDiscard())
+
+	// wrong output
+	e.OnCommand(cmd).Return("4096 0 1", "", nil).Once()
+	_, err = fh.GetDeviceGeometry(device)
+	assert.NotNil(t, err)
+
+	// cmd failed
+	e.OnCommand(cmd).Return("", "", testError).Once()
+	_, err = fh.GetDeviceGeometry(device)
+	assert.NotNil(t, err)
+}
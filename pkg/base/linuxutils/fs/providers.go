@@ -0,0 +1,226 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+)
+
+// Provider encapsulates commands and options which depend on file system type.
+// New file system is supported by implementing Provider and registering it with RegisterProvider
+type Provider interface {
+	// Name returns file system type as it is used in mkfs.<type> and reported by lsblk
+	Name() FileSystem
+	// CreateCmd returns mkfs command for device, options are tuned for device geometry if it isn't nil
+	CreateCmd(device string, geometry *DeviceGeometry) command.Cmd
+	// ResizeCmd returns command which grows file system up to size of device,
+	// file system is mounted to mountPoint if provider supports online resize
+	ResizeCmd(device, mountPoint string) command.Cmd
+	// CheckCmd returns command which checks unmounted file system without repairing
+	CheckCmd(device string) command.Cmd
+	// MountOptions returns options which are added to mount of file system
+	MountOptions() []string
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[FileSystem]Provider)
+)
+
+func init() {
+	RegisterProvider(&extProvider{name: EXT3})
+	RegisterProvider(&extProvider{name: EXT4})
+	RegisterProvider(&xfsProvider{})
+	RegisterProvider(&btrfsProvider{})
+	RegisterProvider(&f2fsProvider{})
+}
+
+// RegisterProvider adds provider to registry, provider with the same name is replaced
+func RegisterProvider(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[p.Name()] = p
+}
+
+// GetProvider returns provider of file system type
+// Returns error if file system isn't supported
+func GetProvider(fsType FileSystem) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	if p, ok := providers[fsType]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unsupported file system %v", fsType)
+}
+
+// SupportedFileSystems returns sorted list of registered file system types
+func SupportedFileSystems() []FileSystem {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	res := make([]FileSystem, 0, len(providers))
+	for name := range providers {
+		res = append(res, name)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// extProvider is a Provider for ext3 and ext4
+type extProvider struct {
+	name FileSystem
+}
+
+// Name returns ext3 or ext4
+func (p *extProvider) Name() FileSystem {
+	return p.name
+}
+
+// CreateCmd returns mkfs.ext3/mkfs.ext4 command with lazy initialization, stride/stripe_width are set
+// for striped devices, discard is disabled for large SSDs
+func (p *extProvider) CreateCmd(device string, geometry *DeviceGeometry) command.Cmd {
+	extended := []string{extLazyInitOpts}
+	if geometry != nil && geometry.IsStriped() {
+		extended = append(extended,
+			fmt.Sprintf("stride=%d", geometry.MinIOSize/extBlockSize),
+			fmt.Sprintf("stripe_width=%d", geometry.OptIOSize/extBlockSize))
+	}
+	if geometry != nil && geometry.NeedNoDiscard() {
+		extended = append(extended, "nodiscard")
+	} else {
+		extended = append(extended, "discard")
+	}
+	return command.NewCmd(MkFSCmdTmpl, p.name, command.Device(device)).Append("-E", strings.Join(extended, ","))
+}
+
+// ResizeCmd returns resize2fs command, ext3/ext4 can be grown online
+func (p *extProvider) ResizeCmd(device, _ string) command.Cmd {
+	return command.NewCmd("resize2fs %s", command.Device(device))
+}
+
+// CheckCmd returns e2fsck command
+func (p *extProvider) CheckCmd(device string) command.Cmd {
+	return command.NewCmd("e2fsck -fn %s", command.Device(device))
+}
+
+// MountOptions returns nil, default mount options are used
+func (p *extProvider) MountOptions() []string {
+	return nil
+}
+
+// xfsProvider is a Provider for xfs
+type xfsProvider struct{}
+
+// Name returns xfs
+func (p *xfsProvider) Name() FileSystem {
+	return XFS
+}
+
+// CreateCmd returns mkfs.xfs command, su/sw are set for striped devices, discard is disabled for large SSDs
+func (p *xfsProvider) CreateCmd(device string, geometry *DeviceGeometry) command.Cmd {
+	cmd := command.NewCmd(MkFSCmdTmpl, XFS, command.Device(device))
+	if geometry != nil && geometry.IsStriped() {
+		cmd = cmd.Append("-d", fmt.Sprintf("su=%d,sw=%d", geometry.MinIOSize, geometry.OptIOSize/geometry.MinIOSize))
+	}
+	if geometry != nil && geometry.NeedNoDiscard() {
+		cmd = cmd.Append("-K")
+	}
+	return cmd
+}
+
+// ResizeCmd returns xfs_growfs command, xfs is grown online by mount point
+func (p *xfsProvider) ResizeCmd(_, mountPoint string) command.Cmd {
+	return command.NewCmd("xfs_growfs %s", command.Device(mountPoint))
+}
+
+// CheckCmd returns xfs_repair command in no-modify mode
+func (p *xfsProvider) CheckCmd(device string) command.Cmd {
+	return command.NewCmd("xfs_repair -n %s", command.Device(device))
+}
+
+// MountOptions returns nil, default mount options are used
+func (p *xfsProvider) MountOptions() []string {
+	return nil
+}
+
+// btrfsProvider is a Provider for btrfs
+type btrfsProvider struct{}
+
+// Name returns btrfs
+func (p *btrfsProvider) Name() FileSystem {
+	return BTRFS
+}
+
+// CreateCmd returns mkfs.btrfs command, discard is disabled for large SSDs
+func (p *btrfsProvider) CreateCmd(device string, geometry *DeviceGeometry) command.Cmd {
+	cmd := command.NewCmd(MkFSCmdTmpl, BTRFS, command.Device(device))
+	if geometry != nil && geometry.NeedNoDiscard() {
+		cmd = cmd.Append("-K")
+	}
+	return cmd
+}
+
+// ResizeCmd returns btrfs resize command, btrfs is grown online by mount point
+func (p *btrfsProvider) ResizeCmd(_, mountPoint string) command.Cmd {
+	return command.NewCmd("btrfs filesystem resize max %s", command.Device(mountPoint))
+}
+
+// CheckCmd returns btrfs check command in read-only mode
+func (p *btrfsProvider) CheckCmd(device string) command.Cmd {
+	return command.NewCmd("btrfs check --readonly %s", command.Device(device))
+}
+
+// MountOptions returns nil, default mount options are used
+func (p *btrfsProvider) MountOptions() []string {
+	return nil
+}
+
+// f2fsProvider is a Provider for f2fs
+type f2fsProvider struct{}
+
+// Name returns f2fs
+func (p *f2fsProvider) Name() FileSystem {
+	return F2FS
+}
+
+// CreateCmd returns mkfs.f2fs command, discard is disabled for large SSDs
+func (p *f2fsProvider) CreateCmd(device string, geometry *DeviceGeometry) command.Cmd {
+	cmd := command.NewCmd(MkFSCmdTmpl, F2FS, command.Device(device))
+	if geometry != nil && geometry.NeedNoDiscard() {
+		cmd = cmd.Append("-t", "0")
+	}
+	return cmd
+}
+
+// ResizeCmd returns resize.f2fs command, f2fs must be unmounted during resize
+func (p *f2fsProvider) ResizeCmd(device, _ string) command.Cmd {
+	return command.NewCmd("resize.f2fs %s", command.Device(device))
+}
+
+// CheckCmd returns fsck.f2fs command in dry-run mode
+func (p *f2fsProvider) CheckCmd(device string) command.Cmd {
+	return command.NewCmd("fsck.f2fs --dry-run %s", command.Device(device))
+}
+
+// MountOptions returns nil, default mount options are used
+func (p *f2fsProvider) MountOptions() []string {
+	return nil
+}
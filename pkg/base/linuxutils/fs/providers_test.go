@@ -0,0 +1,107 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func getTestProvider(t *testing.T, fsType FileSystem) Provider {
+	p, err := GetProvider(fsType)
+	assert.Nil(t, err)
+	assert.Equal(t, fsType, p.Name())
+	return p
+}
+
+func TestGetProvider(t *testing.T) {
+	assert.Equal(t, []FileSystem{BTRFS, EXT3, EXT4, F2FS, XFS}, SupportedFileSystems())
+
+	_, err := GetProvider("anotherFS")
+	assert.NotNil(t, err)
+}
+
+func TestProviders_CreateCmd(t *testing.T) {
+	var (
+		device     = "/dev/sda1"
+		hdd        = &DeviceGeometry{MinIOSize: 4096, Rotational: true, Size: 8001563222016}
+		smallSSD   = &DeviceGeometry{MinIOSize: 4096, Discard: true, Size: 480103981056}
+		largeSSD   = &DeviceGeometry{MinIOSize: 4096, Discard: true, Size: 3840755982336}
+		stripedLV  = &DeviceGeometry{MinIOSize: 65536, OptIOSize: 262144, Rotational: true, Size: 8001563222016}
+		stripedSSD = &DeviceGeometry{MinIOSize: 65536, OptIOSize: 262144, Discard: true, Size: 3840755982336}
+		ext4       = getTestProvider(t, EXT4)
+		xfs        = getTestProvider(t, XFS)
+	)
+
+	// default options
+	assert.Equal(t, "mkfs.ext4 /dev/sda1"+SpeedUpFsCreationOpts, ext4.CreateCmd(device, nil).String())
+	assert.Equal(t, "mkfs.xfs /dev/sda1", xfs.CreateCmd(device, nil).String())
+	assert.Equal(t, "mkfs.ext4 /dev/sda1"+SpeedUpFsCreationOpts, ext4.CreateCmd(device, hdd).String())
+	assert.Equal(t, "mkfs.xfs /dev/sda1", xfs.CreateCmd(device, hdd).String())
+	assert.Equal(t, "mkfs.ext3 /dev/sda1"+SpeedUpFsCreationOpts, getTestProvider(t, EXT3).CreateCmd(device, smallSSD).String())
+	assert.Equal(t, "mkfs.xfs /dev/sda1", xfs.CreateCmd(device, smallSSD).String())
+	// options are separate arguments of command
+	assert.Equal(t, []string{"mkfs.xfs", "/dev/sda1", "-d", "su=65536,sw=4", "-K"}, xfs.CreateCmd(device, stripedSSD).Args())
+
+	assert.Equal(t, "mkfs.ext4 /dev/sda1 -E lazy_journal_init=1,lazy_itable_init=1,nodiscard",
+		ext4.CreateCmd(device, largeSSD).String())
+	assert.Equal(t, "mkfs.xfs /dev/sda1 -K", xfs.CreateCmd(device, largeSSD).String())
+	assert.Equal(t, "mkfs.btrfs /dev/sda1 -K", getTestProvider(t, BTRFS).CreateCmd(device, largeSSD).String())
+	assert.Equal(t, "mkfs.f2fs /dev/sda1 -t 0", getTestProvider(t, F2FS).CreateCmd(device, largeSSD).String())
+
+	assert.Equal(t, "mkfs.ext4 /dev/sda1 -E lazy_journal_init=1,lazy_itable_init=1,stride=16,stripe_width=64,discard",
+		ext4.CreateCmd(device, stripedLV).String())
+	assert.Equal(t, "mkfs.xfs /dev/sda1 -d su=65536,sw=4", xfs.CreateCmd(device, stripedLV).String())
+	assert.Equal(t, "mkfs.xfs /dev/sda1 -d su=65536,sw=4 -K", xfs.CreateCmd(device, stripedSSD).String())
+}
+
+func TestResizeFS(t *testing.T) {
+	var (
+		e          = &mocks.GoMockExecutor{}
+		fh         = NewFSImpl(e)
+		device     = "/dev/sda1"
+		mountPoint = "/mnt/volume"
+	)
+
+	e.OnCommand(fmt.Sprintf("xfs_growfs %s", mountPoint)).Return("", "", nil).Once()
+	assert.Nil(t, fh.ResizeFS(XFS, device, mountPoint))
+
+	e.OnCommand(fmt.Sprintf("resize2fs %s", device)).Return("", "", testError).Once()
+	assert.NotNil(t, fh.ResizeFS(EXT4, device, mountPoint))
+
+	assert.NotNil(t, fh.ResizeFS("anotherFS", device, mountPoint))
+}
+
+func TestCheckFS(t *testing.T) {
+	var (
+		e      = &mocks.GoMockExecutor{}
+		fh     = NewFSImpl(e)
+		device = "/dev/sda1"
+	)
+
+	e.OnCommand(fmt.Sprintf("btrfs check --readonly %s", device)).Return("", "", nil).Once()
+	assert.Nil(t, fh.CheckFS(BTRFS, device))
+
+	e.OnCommand(fmt.Sprintf("xfs_repair -n %s", device)).Return("", "", testError).Once()
+	assert.NotNil(t, fh.CheckFS(XFS, device))
+
+	assert.NotNil(t, fh.CheckFS("anotherFS", device))
+}
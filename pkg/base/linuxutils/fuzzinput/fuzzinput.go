@@ -0,0 +1,93 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fuzzinput generates malformed variants of system utilities output.
+// Variants are used by parsers tests to make sure that truncated, localized or interleaved with stderr output
+// is handled gracefully instead of causing panic.
+package fuzzinput
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// Noise contains lines which system utilities might print instead of or together with expected output:
+// warnings, localized error messages and binary garbage
+var Noise = []string{
+	"  WARNING: Not using device /dev/sdc1 for PV 2DdQcG-u5gq-mqa0-awmS-EsDP-GQFB-sfPHTw.",
+	"File descriptor 7 (/dev/urandom) leaked on lvm invocation. Parent PID 1: /csi-baremetal-node",
+	"Warning: Unable to open /dev/sr0 read-write (Read-only file system).  /dev/sr0 has been opened read-only.",
+	"lsblk: /dev/loop9: не удалось получить размер устройства",
+	"partprobe: Fehler: Gerät oder Ressource belegt",
+	"Error: ",
+	":",
+	"::::",
+	"NAME=",
+	`NAME="`,
+	"\x00\xff\xfe\x80",
+	"\t \t",
+}
+
+// Variants returns seed and its malformed variants: seed truncated at every byte, seed with noise lines
+// interleaved between its lines, seed with randomly replaced bytes and seed with randomly shuffled lines.
+// randomCount defines amount of random mutations. Random generator has fixed seed, so result is reproducible.
+func Variants(seed string, randomCount int) []string {
+	res := make([]string, 0, 2*len(seed)+len(Noise)+randomCount+1)
+	res = append(res, seed)
+
+	for i := 0; i < len(seed); i++ {
+		res = append(res, seed[:i], seed[i:])
+	}
+
+	lines := strings.Split(seed, "\n")
+	for _, n := range Noise {
+		res = append(res, n, interleave(lines, n))
+	}
+
+	r := rand.New(rand.NewSource(int64(len(seed))))
+	for i := 0; i < randomCount; i++ {
+		res = append(res, mutate(r, seed, lines))
+	}
+	return res
+}
+
+// interleave inserts noise line after each line of output
+func interleave(lines []string, noise string) string {
+	res := make([]string, 0, 2*len(lines))
+	for _, l := range lines {
+		res = append(res, l, noise)
+	}
+	return strings.Join(res, "\n")
+}
+
+// mutate applies one random mutation to seed
+func mutate(r *rand.Rand, seed string, lines []string) string {
+	switch r.Intn(3) {
+	case 0:
+		b := []byte(seed)
+		for i := 0; i < len(b)/8+1 && len(b) > 0; i++ {
+			b[r.Intn(len(b))] = byte(r.Intn(256))
+		}
+		return string(b)
+	case 1:
+		shuffled := append([]string(nil), lines...)
+		r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		return strings.Join(shuffled, "\n")
+	default:
+		pos := r.Intn(len(seed) + 1)
+		return seed[:pos] + Noise[r.Intn(len(Noise))] + seed[pos:]
+	}
+}
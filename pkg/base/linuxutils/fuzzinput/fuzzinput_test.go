@@ -0,0 +1,39 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fuzzinput
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariants(t *testing.T) {
+	seed := "line1\nline2"
+
+	res := Variants(seed, 10)
+	assert.Equal(t, seed, res[0])
+	assert.Contains(t, res, "line")
+	assert.Contains(t, res, "line1\n"+Noise[0]+"\nline2\n"+Noise[0])
+	assert.Equal(t, 1+2*len(seed)+2*len(Noise)+10, len(res))
+
+	// random mutations are reproducible
+	assert.Equal(t, res, Variants(seed, 10))
+
+	assert.Equal(t, []string{""}, Variants("", 0)[:1])
+	assert.NotPanics(t, func() { Variants("", 10) })
+}
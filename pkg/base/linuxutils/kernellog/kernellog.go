@@ -0,0 +1,98 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kernellog contains code for reading kernel log and searching block device I/O errors in it
+package kernellog
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+)
+
+const (
+	// JournalctlCmd prints kernel messages of the current boot from journald without metadata,
+	// cursor of the last printed message follows the messages and is used to skip already processed messages
+	JournalctlCmd = "journalctl --dmesg --quiet --no-pager --output=cat --show-cursor"
+	// cursorPrefix precedes cursor in output of journalctl
+	cursorPrefix = "-- cursor: "
+)
+
+// ioErrorRegexp matches block layer errors, for example:
+// blk_update_request: I/O error, dev sdb, sector 2048 op 0x0:(READ) flags 0x0 phys_seg 1 prio class 0
+// blk_update_request: critical medium error, dev sdc, sector 4096 op 0x0:(READ) flags 0x0 phys_seg 1 prio class 0
+var ioErrorRegexp = regexp.MustCompile(`(?:I/O|critical medium|critical target) error, dev ([a-zA-Z0-9]+),`)
+
+// WrapKernelLog is an interface that encapsulates reading of kernel log
+type WrapKernelLog interface {
+	GetIOErrors() (map[string]int, error)
+}
+
+// KernelLog is an implementation of WrapKernelLog interface which reads kernel messages from journald
+type KernelLog struct {
+	e command.CmdExecutor
+	// journald cursor of the last processed message
+	cursor string
+	sync.Mutex
+	log *logrus.Entry
+}
+
+// NewKernelLog is a constructor for KernelLog struct
+func NewKernelLog(e command.CmdExecutor, logger *logrus.Logger) *KernelLog {
+	return &KernelLog{
+		e:   e,
+		log: logger.WithField("component", "KernelLog"),
+	}
+}
+
+// GetIOErrors reads kernel messages which appeared since the previous call and counts I/O errors per device
+// First call processes all messages since host boot
+// Returns map device name (for example, sdb) -> count of I/O errors or error if journalctl failed
+func (k *KernelLog) GetIOErrors() (map[string]int, error) {
+	k.Lock()
+	defer k.Unlock()
+
+	cmd := command.NewCmd(JournalctlCmd)
+	if k.cursor != "" {
+		// cursor is produced by journalctl and isn't user input
+		cmd = cmd.Append("--after-cursor=" + k.cursor)
+	}
+	stdout, _, err := k.e.RunCmd(cmd, command.UseMetrics(true), command.CmdName(JournalctlCmd))
+	if err != nil {
+		return nil, err
+	}
+
+	ioErrors := make(map[string]int)
+	for _, line := range strings.Split(stdout, "\n") {
+		line = strings.TrimSpace(line)
+		// cursor isn't printed if there are no new messages
+		if strings.HasPrefix(line, cursorPrefix) {
+			k.cursor = strings.TrimPrefix(line, cursorPrefix)
+			continue
+		}
+		if match := ioErrorRegexp.FindStringSubmatch(line); match != nil {
+			ioErrors[match[1]]++
+		}
+	}
+	if len(ioErrors) > 0 {
+		k.log.WithField("method", "GetIOErrors").Warnf("I/O errors found in kernel log: %v", ioErrors)
+	}
+	return ioErrors, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kernellog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+var testLogger = logrus.New()
+
+func TestKernelLog_GetIOErrors(t *testing.T) {
+	e := &mocks.GoMockExecutor{}
+	k := NewKernelLog(e, testLogger)
+
+	output := `sd 0:0:1:0: [sdb] tag#0 FAILED Result: hostbyte=DID_OK driverbyte=DRIVER_SENSE
+blk_update_request: I/O error, dev sdb, sector 2048 op 0x0:(READ) flags 0x0 phys_seg 1 prio class 0
+blk_update_request: critical medium error, dev sdc, sector 4096 op 0x0:(READ) flags 0x0
+blk_update_request: I/O error, dev sdb, sector 2056 op 0x0:(READ) flags 0x0 phys_seg 1 prio class 0
+-- cursor: s=739ad463;i=4ece7;b=6c7c6013;m=4fc72436e;t=4c508a72423d9;x=d3e56106
+`
+	e.OnCommand(JournalctlCmd).Return(output, "", nil).Once()
+	ioErrors, err := k.GetIOErrors()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"sdb": 2, "sdc": 1}, ioErrors)
+
+	// only messages after cursor are read
+	afterCursor := JournalctlCmd + " --after-cursor=s=739ad463;i=4ece7;b=6c7c6013;m=4fc72436e;t=4c508a72423d9;x=d3e56106"
+	output = `blk_update_request: I/O error, dev nvme0n1, sector 8 op 0x1:(WRITE)
+-- cursor: s=739ad463;i=4ece8;b=6c7c6013;m=4fc72437e;t=4c508a72423e9;x=d3e56107
+`
+	e.OnCommand(afterCursor).Return(output, "", nil).Once()
+	ioErrors, err = k.GetIOErrors()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"nvme0n1": 1}, ioErrors)
+
+	// cursor isn't printed if there are no new messages, it's kept
+	afterCursor = JournalctlCmd + " --after-cursor=s=739ad463;i=4ece8;b=6c7c6013;m=4fc72437e;t=4c508a72423e9;x=d3e56107"
+	e.OnCommand(afterCursor).Return("", "", nil).Once()
+	ioErrors, err = k.GetIOErrors()
+	assert.Nil(t, err)
+	assert.Empty(t, ioErrors)
+
+	e.OnCommand(afterCursor).Return("", "", errors.New("error")).Once()
+	_, err = k.GetIOErrors()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,30 @@
+//go:build gofuzz
+// +build gofuzz
+
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsblk
+
+// Fuzz is an entry point for go-fuzz, see docs/utility-output-parsing.md
+func Fuzz(data []byte) int {
+	var size CustomInt64
+	_ = size.UnmarshalJSON(data)
+	if devs, err := parsePairs(string(data)); err == nil && len(devs) > 0 {
+		return 1
+	}
+	return 0
+}
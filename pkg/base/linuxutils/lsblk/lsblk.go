@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 
@@ -32,7 +33,7 @@ import (
 const (
 	// CmdTmpl adds device name, if add empty string - command will print info about all devices
 	CmdTmpl = "lsblk %s --paths --json --bytes --fs " +
-		"--output NAME,TYPE,SIZE,ROTA,SERIAL,WWN,VENDOR,MODEL,REV,MOUNTPOINT,FSTYPE,PARTUUID"
+		"--output NAME,TYPE,SIZE,ROTA,SERIAL,WWN,VENDOR,MODEL,REV,MOUNTPOINT,FSTYPE,PARTUUID,UUID"
 	// outputKey is the key to find block devices in lsblk json output
 	outputKey = "blockdevices"
 	// romDeviceType is the constant that represents rom devices to exclude them from lsblk output
@@ -48,13 +49,29 @@ type WrapLsblk interface {
 // LSBLK is a wrap for system lsblk util
 type LSBLK struct {
 	e command.CmdExecutor
+	// pairsMode is 1 when lsblk doesn't support --json, --pairs output is parsed instead.
+	// It is set on creation or by the first call which gets unsupported option error, accessed atomically
+	pairsMode int32
+}
+
+// jsonUnsupported is set on start when detected lsblk version doesn't support --json
+var jsonUnsupported bool
+
+// SetJSONSupported defines whether new LSBLK instances should use --json or --pairs output
+// Receives capability detected based on lsblk version
+func SetJSONSupported(supported bool) {
+	jsonUnsupported = !supported
 }
 
 // NewLSBLK is a constructor for LSBLK struct
 func NewLSBLK(log *logrus.Logger) *LSBLK {
 	e := command.NewExecutor(log)
 	e.SetLevel(logrus.TraceLevel)
-	return &LSBLK{e: e}
+	l := &LSBLK{e: e}
+	if jsonUnsupported {
+		l.pairsMode = 1
+	}
+	return l
 }
 
 // CustomInt64 to handle Size lsblk output - 8001563222016 or "8001563222016"
@@ -65,7 +82,18 @@ type CustomInt64 struct {
 // UnmarshalJSON customizes string size unmarshalling
 func (ci *CustomInt64) UnmarshalJSON(data []byte) error {
 	QuotesByte := byte(34)
+	// size might be empty or null for some devices, for example on ARM boards
+	if string(data) == `""` || string(data) == "null" {
+		ci.Int64 = 0
+		return nil
+	}
+	if len(data) == 0 {
+		return errors.New("CustomInt64: UnmarshalJSON: empty value")
+	}
 	if data[0] == QuotesByte {
+		if len(data) < 2 || data[len(data)-1] != QuotesByte {
+			return errors.New("CustomInt64: UnmarshalJSON: unterminated string " + string(data))
+		}
 		err := json.Unmarshal(data[1:len(data)-1], &ci.Int64)
 		if err != nil {
 			return errors.New("CustomInt64: UnmarshalJSON: " + err.Error())
@@ -95,7 +123,7 @@ func (cb *CustomBool) UnmarshalJSON(data []byte) error {
 	case `"true"`, `true`, `"1"`, `1`:
 		cb.Bool = true
 		return nil
-	case `"false"`, `false`, `"0"`, `0`, `""`:
+	case `"false"`, `false`, `"0"`, `0`, `""`, `null`:
 		cb.Bool = false
 		return nil
 	default:
@@ -122,6 +150,7 @@ type BlockDevice struct {
 	MountPoint string        `json:"mountpoint,omitempty"`
 	FSType     string        `json:"fstype,omitempty"`
 	PartUUID   string        `json:"partuuid,omitempty"`
+	UUID       string        `json:"uuid,omitempty"`
 	Children   []BlockDevice `json:"children,omitempty"`
 }
 
@@ -129,11 +158,18 @@ type BlockDevice struct {
 // Receives device path. If device is empty string, info about all devices will be collected
 // Returns slice of BlockDevice structs or error if something went wrong
 func (l *LSBLK) GetBlockDevices(device string) ([]BlockDevice, error) {
-	cmd := fmt.Sprintf(CmdTmpl, device)
-	strOut, _, err := l.e.RunCmd(cmd,
+	if atomic.LoadInt32(&l.pairsMode) == 1 {
+		return l.getBlockDevicesFromPairs(device)
+	}
+	cmd := command.NewCmd(CmdTmpl, device)
+	strOut, stdErr, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(CmdTmpl, ""))))
 	if err != nil {
+		if isUnsupportedOption(stdErr) {
+			atomic.StoreInt32(&l.pairsMode, 1)
+			return l.getBlockDevicesFromPairs(device)
+		}
 		return nil, err
 	}
 	rawOut := make(map[string][]BlockDevice, 1)
@@ -141,7 +177,6 @@ func (l *LSBLK) GetBlockDevices(device string) ([]BlockDevice, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to unmarshal output to BlockDevice instance, error: %v", err)
 	}
-	res := make([]BlockDevice, 0)
 	var (
 		devs []BlockDevice
 		ok   bool
@@ -149,12 +184,33 @@ func (l *LSBLK) GetBlockDevices(device string) ([]BlockDevice, error) {
 	if devs, ok = rawOut[outputKey]; !ok {
 		return nil, fmt.Errorf("unexpected lsblk output format, missing \"%s\" key", outputKey)
 	}
+	return filterRomDevices(devs), nil
+}
+
+// getBlockDevicesFromPairs runs lsblk with --pairs output for lsblk versions which don't support --json
+func (l *LSBLK) getBlockDevicesFromPairs(device string) ([]BlockDevice, error) {
+	strOut, _, err := l.e.RunCmd(command.NewCmd(PairsCmdTmpl, device),
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(PairsCmdTmpl, ""))))
+	if err != nil {
+		return nil, err
+	}
+	devs, err := parsePairs(strOut)
+	if err != nil {
+		return nil, err
+	}
+	return filterRomDevices(devs), nil
+}
+
+// filterRomDevices excludes rom devices from lsblk output
+func filterRomDevices(devs []BlockDevice) []BlockDevice {
+	res := make([]BlockDevice, 0, len(devs))
 	for _, d := range devs {
 		if d.Type != romDeviceType {
 			res = append(res, d)
 		}
 	}
-	return res, nil
+	return res
 }
 
 // SearchDrivePath if not defined returns drive path based on drive S/N, VID and PID.
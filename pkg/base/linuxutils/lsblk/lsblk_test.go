@@ -19,6 +19,7 @@ package lsblk
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -170,3 +171,83 @@ func TestLSBLK_GetAllV2_Success(t *testing.T) {
 	assert.Equal(t, ssd.Rota.Bool, true)
 	assert.Equal(t, ssd.Size.Int64, int64(8001563222016))
 }
+
+func TestLSBLK_GetBlockDevices_NullValues(t *testing.T) {
+	l := NewLSBLK(testLogger)
+	e := &mocks.GoMockExecutor{}
+	e.On("RunCmd", allDevicesCmd).Return(`{"blockdevices": [
+		{"name": "/dev/mmcblk0", "type": "disk", "size": 31914983424, "rota": null, "serial": null}]}`, "", nil)
+	l.e = e
+
+	out, err := l.GetBlockDevices("")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(out))
+	assert.False(t, out[0].Rota.Bool)
+	assert.Equal(t, "", out[0].Serial)
+}
+
+func TestLSBLK_GetBlockDevices_Pairs(t *testing.T) {
+	l := NewLSBLK(testLogger)
+	e := &mocks.GoMockExecutor{}
+	e.On("RunCmd", allDevicesCmd).Return("", "lsblk: unrecognized option '--json'", errors.New("exit status 1")).Once()
+	e.On("RunCmd", fmt.Sprintf(PairsCmdTmpl, "")).Return(
+		`NAME="/dev/sda" TYPE="disk" SIZE="8001563222016" ROTA="1" SERIAL="sn-1111" WWN="" VENDOR="ATA\x20\x20\x20" MODEL="HDD" REV="" MOUNTPOINT="" FSTYPE="" PARTUUID="" PKNAME=""
+NAME="/dev/sda1" TYPE="part" SIZE="1048576" ROTA="1" SERIAL="" WWN="" VENDOR="" MODEL="" REV="" MOUNTPOINT="/mnt" FSTYPE="xfs" PARTUUID="uuid-1" PKNAME="/dev/sda"
+NAME="/dev/sr0" TYPE="rom" SIZE="1073741312" ROTA="1" SERIAL="" WWN="" VENDOR="" MODEL="" REV="" MOUNTPOINT="" FSTYPE="" PARTUUID="" PKNAME=""
+`, "", nil)
+	l.e = e
+
+	out, err := l.GetBlockDevices("")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(out))
+	assert.Equal(t, "/dev/sda", out[0].Name)
+	assert.Equal(t, int64(8001563222016), out[0].Size.Int64)
+	assert.True(t, out[0].Rota.Bool)
+	assert.Equal(t, "ATA   ", out[0].Vendor)
+	assert.Equal(t, 1, len(out[0].Children))
+	assert.Equal(t, "uuid-1", out[0].Children[0].PartUUID)
+	assert.Equal(t, "/mnt", out[0].Children[0].MountPoint)
+
+	// json isn't used anymore
+	_, err = l.GetBlockDevices("")
+	assert.Nil(t, err)
+	e.AssertNumberOfCalls(t, "RunCmd", 3)
+
+	_, err = parsePairs("not pairs")
+	assert.NotNil(t, err)
+}
+
+func TestLSBLK_GetBlockDevices_PairsConcurrent(t *testing.T) {
+	l := NewLSBLK(testLogger)
+	e := &mocks.GoMockExecutor{}
+	e.On("RunCmd", allDevicesCmd).Return("", "lsblk: unrecognized option '--json'", errors.New("exit status 1"))
+	e.On("RunCmd", fmt.Sprintf(PairsCmdTmpl, "")).Return(
+		`NAME="/dev/sda" TYPE="disk" SIZE="8001563222016" ROTA="1" SERIAL="sn-1111" WWN="" VENDOR="" MODEL="HDD" REV="" MOUNTPOINT="" FSTYPE="" PARTUUID="" PKNAME=""
+`, "", nil)
+	l.e = e
+
+	// fallback to --pairs is detected by concurrent calls, run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := l.GetBlockDevices("")
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(out))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCustomInt64_UnmarshalJSON(t *testing.T) {
+	var ci CustomInt64
+	assert.Nil(t, ci.UnmarshalJSON([]byte(`"8001563222016"`)))
+	assert.Equal(t, int64(8001563222016), ci.Int64)
+	assert.Nil(t, ci.UnmarshalJSON([]byte(`1024`)))
+	assert.Equal(t, int64(1024), ci.Int64)
+
+	for _, data := range []string{"", `"`, `"1`, `abc`} {
+		assert.NotNil(t, ci.UnmarshalJSON([]byte(data)), data)
+	}
+}
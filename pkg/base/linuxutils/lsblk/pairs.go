@@ -0,0 +1,162 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsblk
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PairsCmdTmpl is used when lsblk doesn't support --json (util-linux older than 2.27 or minimal distros),
+	// devices are printed as flat list of KEY="value" pairs, PKNAME is used to restore devices tree
+	PairsCmdTmpl = "lsblk %s --paths --pairs --bytes --fs " +
+		"--output NAME,TYPE,SIZE,ROTA,SERIAL,WWN,VENDOR,MODEL,REV,MOUNTPOINT,FSTYPE,PARTUUID,UUID,PKNAME"
+)
+
+var (
+	// pairRegexp matches KEY="value" pair, value might contain escaped characters
+	pairRegexp = regexp.MustCompile(`([A-Z:\-]+)="((?:[^"\\]|\\.)*)"`)
+	// hexEscapeRegexp matches characters which lsblk escapes in pairs output, for example \x20 for space
+	hexEscapeRegexp = regexp.MustCompile(`\\x([0-9a-fA-F]{2})`)
+	// unsupportedOptionRegexp matches error messages of lsblk implementations which don't support option
+	unsupportedOptionRegexp = regexp.MustCompile(`(?i)(unrecognized|invalid|unknown) option`)
+)
+
+// isUnsupportedOption checks whether lsblk failed because of unsupported command line option
+func isUnsupportedOption(stderr string) bool {
+	return unsupportedOptionRegexp.MatchString(stderr)
+}
+
+// pairsDevice is a device from lsblk --pairs output with reference to parent device
+type pairsDevice struct {
+	dev      BlockDevice
+	parent   string
+	children []*pairsDevice
+}
+
+// setField sets BlockDevice field which corresponds to lsblk column
+func (p *pairsDevice) setField(column, value string) error {
+	switch column {
+	case "NAME":
+		p.dev.Name = value
+	case "TYPE":
+		p.dev.Type = value
+	case "SIZE":
+		if value == "" {
+			return nil
+		}
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("unable to parse size %s of device %s: %v", value, p.dev.Name, err)
+		}
+		p.dev.Size.Int64 = size
+	case "ROTA":
+		p.dev.Rota.Bool = value == "1"
+	case "SERIAL":
+		p.dev.Serial = value
+	case "WWN":
+		p.dev.WWN = value
+	case "VENDOR":
+		p.dev.Vendor = value
+	case "MODEL":
+		p.dev.Model = value
+	case "REV":
+		p.dev.Rev = value
+	case "MOUNTPOINT":
+		p.dev.MountPoint = value
+	case "FSTYPE":
+		p.dev.FSType = value
+	case "PARTUUID":
+		p.dev.PartUUID = value
+	case "UUID":
+		p.dev.UUID = value
+	case "PKNAME":
+		p.parent = value
+	}
+	return nil
+}
+
+// toBlockDevices converts devices tree to slice of BlockDevice structs
+func toBlockDevices(devices []*pairsDevice) []BlockDevice {
+	if len(devices) == 0 {
+		return nil
+	}
+	res := make([]BlockDevice, 0, len(devices))
+	for _, d := range devices {
+		d.dev.Children = toBlockDevices(d.children)
+		res = append(res, d.dev)
+	}
+	return res
+}
+
+// unescapeValue decodes \xNN sequences which lsblk uses to escape spaces and non printable characters.
+// Decoded bytes are collected and converted to string once, so escaped multibyte UTF-8 characters are restored as is
+func unescapeValue(value string) string {
+	matches := hexEscapeRegexp.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return value
+	}
+	res := make([]byte, 0, len(value))
+	prev := 0
+	for _, m := range matches {
+		b, _ := strconv.ParseUint(value[m[2]:m[3]], 16, 8) // We don't expect error here, because value is validated by regex
+		res = append(append(res, value[prev:m[0]]...), byte(b))
+		prev = m[1]
+	}
+	return string(append(res, value[prev:]...))
+}
+
+// parsePairs parses lsblk --pairs output and restores devices tree based on PKNAME column
+// Lines without KEY="value" pairs or without device name (for example, interleaved warnings) are skipped
+// Returns slice of top level BlockDevice structs or error if output doesn't contain any device
+func parsePairs(output string) ([]BlockDevice, error) {
+	var (
+		roots   []*pairsDevice
+		devices = make(map[string]*pairsDevice)
+		skipped []string
+	)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		pairs := pairRegexp.FindAllStringSubmatch(line, -1)
+		d := &pairsDevice{}
+		for _, pair := range pairs {
+			if err := d.setField(pair[1], unescapeValue(pair[2])); err != nil {
+				return nil, err
+			}
+		}
+		if d.dev.Name == "" {
+			skipped = append(skipped, line)
+			continue
+		}
+		if parent, ok := devices[d.parent]; ok {
+			parent.children = append(parent.children, d)
+		} else {
+			roots = append(roots, d)
+		}
+		devices[d.dev.Name] = d
+	}
+	if len(devices) == 0 && len(skipped) > 0 {
+		return nil, fmt.Errorf("unexpected lsblk output line: %s", skipped[0])
+	}
+	return toBlockDevices(roots), nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsblk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fuzzinput"
+)
+
+const testPairsOutput = `NAME="/dev/sda" TYPE="disk" SIZE="8001563222016" ROTA="1" SERIAL="sn-1111" WWN="" VENDOR="ATA\x20\x20\x20" MODEL="HDD" REV="" MOUNTPOINT="" FSTYPE="" PARTUUID="" PKNAME=""
+NAME="/dev/sda1" TYPE="part" SIZE="1048576" ROTA="1" SERIAL="" WWN="" VENDOR="" MODEL="" REV="" MOUNTPOINT="/mnt/\xd1\x82\xd0\xbe\xd0\xbc" FSTYPE="xfs" PARTUUID="uuid-1" PKNAME="/dev/sda"
+`
+
+func TestParsePairs(t *testing.T) {
+	devs, err := parsePairs(testPairsOutput)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(devs))
+	assert.Equal(t, "ATA   ", devs[0].Vendor)
+	// escaped UTF-8 characters are restored as is
+	assert.Equal(t, "/mnt/том", devs[0].Children[0].MountPoint)
+	assert.Equal(t, "a é", unescapeValue(`a\x20\xc3\xa9`))
+	assert.Equal(t, "plain", unescapeValue("plain"))
+
+	// interleaved warnings are skipped
+	devs, err = parsePairs("lsblk: /dev/loop9: не удалось получить размер устройства\n" + testPairsOutput)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(devs))
+	assert.Equal(t, 1, len(devs[0].Children))
+
+	devs, err = parsePairs("")
+	assert.Nil(t, err)
+	assert.Empty(t, devs)
+
+	_, err = parsePairs("lsblk: unknown column: PKNAME")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unexpected lsblk output line")
+}
+
+func TestParsePairs_Fuzz(t *testing.T) {
+	for _, input := range fuzzinput.Variants(testPairsOutput, 500) {
+		assert.NotPanics(t, func() {
+			_, _ = parsePairs(input)
+		}, "input: %q", input)
+	}
+}
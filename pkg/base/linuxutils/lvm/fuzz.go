@@ -0,0 +1,31 @@
+//go:build gofuzz
+// +build gofuzz
+
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lvm
+
+// Fuzz is an entry point for go-fuzz, see docs/utility-output-parsing.md
+func Fuzz(data []byte) int {
+	output := string(data)
+	values := parseReportColumn(output)
+	_, _ = parseReportValue(output)
+	if _, found := parsePVDisplayColon(output, "/dev/sda"); found || len(values) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -42,6 +42,8 @@ const (
 	PVCreateCmdTmpl = lvmPath + "pvcreate --yes %s" // add PV name
 	// PVRemoveCmdTmpl remove PV cmd
 	PVRemoveCmdTmpl = lvmPath + "pvremove --yes %s" // add PV name
+	// PVResizeCmdTmpl resizes PV to the size of its device cmd
+	PVResizeCmdTmpl = lvmPath + "pvresize %s" // add PV name
 	// PVsInVGCmdTmpl print PVs in VG cmd
 	PVsInVGCmdTmpl = lvmPath + "pvs --select vg_name=%s -o pv_name --noheadings" // add VG name
 	// PVsListCmdTmpl print all PVs name on node
@@ -52,14 +54,36 @@ const (
 	VGScanCmdTmpl = lvmPath + "vgscan"
 	// VGRefreshCmdTmpl reactivates an LV using the latest metadata
 	VGRefreshCmdTmpl = lvmPath + "vgchange --refresh %s"
+	// VGMissingPVsCmdTmpl print count of PVs which are missing in VG
+	VGMissingPVsCmdTmpl = lvmPath + "vgs --options vg_missing_pv_count --noheadings %s" // add VG name
+	// VGActivateDegradedCmdTmpl activates LVs of VG with missing PVs which have enough redundancy
+	VGActivateDegradedCmdTmpl = lvmPath + "vgchange --activate y --activationmode degraded %s" // add VG name
+	// VGReduceMissingCmdTmpl removes missing PVs from VG, fails if LVs are located on missing PVs
+	VGReduceMissingCmdTmpl = lvmPath + "vgreduce --removemissing %s" // add VG name
+	// VGSeqNoCmdTmpl print sequence number of VG metadata which is incremented on each change of VG
+	VGSeqNoCmdTmpl = lvmPath + "vgs --options vg_seqno --noheadings %s" // add VG name
+	// VGCfgBackupCmdTmpl writes metadata of VG to file
+	VGCfgBackupCmdTmpl = lvmPath + "vgcfgbackup --file %s %s" // add file path and VG name
+	// VGCfgRestoreCmdTmpl restores metadata of VG from file
+	VGCfgRestoreCmdTmpl = lvmPath + "vgcfgrestore --file %s %s" // add file path and VG name
 	// VGRemoveCmdTmpl remove VG cmd
 	VGRemoveCmdTmpl = lvmPath + "vgremove --yes %s" // add VG name
 	// AllPVsCmd returns all physical volumes on the system
 	AllPVsCmd = lvmPath + "pvs --options pv_name --noheadings"
 	// VGFreeSpaceCmdTmpl check VG free space cmd
 	VGFreeSpaceCmdTmpl = "vgs %s --options vg_free --units b --noheadings" // add VG name
+	// VGSizeCmdTmpl check VG size cmd
+	VGSizeCmdTmpl = "vgs %s --options vg_size --units b --noheadings" // add VG name
 	// LVCreateCmdTmpl create LV on provided VG cmd
 	LVCreateCmdTmpl = lvmPath + "lvcreate --yes --name %s --size %s %s" // add LV name, size and VG name
+	// LVCreateThinSnapshotCmdTmpl creates writable thin snapshot of thin LV, snapshot is activated unlike default
+	LVCreateThinSnapshotCmdTmpl = lvmPath + "lvcreate --yes --snapshot --setactivationskip n --permission rw " +
+		"--name %s %s/%s" // add LV name, VG name and origin LV name
+	// LVCreateReadOnlySnapshotCmdTmpl creates read-only snapshot of LV with space for changes of origin
+	LVCreateReadOnlySnapshotCmdTmpl = lvmPath + "lvcreate --yes --snapshot --setactivationskip n --permission r " +
+		"--size %s --name %s %s/%s" // add size, LV name, VG name and origin LV name
+	// LVSizeCmdTmpl print size of LV in bytes
+	LVSizeCmdTmpl = lvmPath + "lvs --options lv_size --units b --nosuffix --noheadings %s" // add full LV name
 	// LVRemoveCmdTmpl remove LV cmd
 	LVRemoveCmdTmpl = lvmPath + "lvremove --yes %s" // add full LV name
 	// LVsInVGCmdTmpl print LVs in VG cmd
@@ -76,15 +100,26 @@ const (
 type WrapLVM interface {
 	PVCreate(dev string) error
 	PVRemove(name string) error
+	PVResize(dev string) error
 	VGCreate(name string, pvs ...string) error
 	VGScan(name string) (bool, error)
 	VGReactivate(name string) error
+	GetVGMissingPVs(name string) (int, error)
+	VGActivateDegraded(name string) error
+	VGReduceMissing(name string) error
+	GetVGSeqNo(name string) (int64, error)
+	VGCfgBackup(name, file string) error
+	VGCfgRestore(name, file string) error
 	VGRemove(name string) error
 	LVCreate(name, size, vgName string) error
+	LVCreateThinSnapshot(name, origin, vgName string) error
+	LVCreateReadOnlySnapshot(name, size, origin, vgName string) error
+	GetLVSize(fullLVName string) (int64, error)
 	LVRemove(fullLVName string) error
 	IsVGContainsLVs(vgName string) bool
 	RemoveOrphanPVs() error
 	GetVgFreeSpace(vgName string) (int64, error)
+	GetVGSize(vgName string) (int64, error)
 	GetAllPVs() ([]string, error)
 	GetLVsInVG(vgName string) ([]string, error)
 	GetVGNameByPVName(pvName string) (string, error)
@@ -109,7 +144,7 @@ func NewLVM(e command.CmdExecutor, l *logrus.Logger) *LVM {
 // Receives device path
 // Returns error if something went wrong
 func (l *LVM) PVCreate(dev string) error {
-	cmd := fmt.Sprintf(PVCreateCmdTmpl, dev)
+	cmd := command.NewCmd(PVCreateCmdTmpl, command.Device(dev))
 	_, _, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(PVCreateCmdTmpl, ""))))
@@ -120,7 +155,7 @@ func (l *LVM) PVCreate(dev string) error {
 // Receives name of a physical volume to delete
 // Returns error if something went wrong
 func (l *LVM) PVRemove(name string) error {
-	cmd := fmt.Sprintf(PVRemoveCmdTmpl, name)
+	cmd := command.NewCmd(PVRemoveCmdTmpl, command.Device(name))
 	_, stdErr, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(PVRemoveCmdTmpl, ""))))
@@ -130,11 +165,22 @@ func (l *LVM) PVRemove(name string) error {
 	return err
 }
 
+// PVResize grows physical volume to the size of its device, e.g. after expansion of RAID virtual disk
+// Receives name of a physical volume
+// Returns error if something went wrong
+func (l *LVM) PVResize(dev string) error {
+	cmd := command.NewCmd(PVResizeCmdTmpl, command.Device(dev))
+	_, _, err := l.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(PVResizeCmdTmpl, ""))))
+	return err
+}
+
 // ExpandLV expand logical volume
 // Receives full name of a logical volume and requiredSize to resize
 // Returns error if something went wrong
 func (l *LVM) ExpandLV(lvName string, requiredSize int64) error {
-	cmd := fmt.Sprintf(LVExpandCmdTmpl, strconv.FormatInt(requiredSize, 10), lvName)
+	cmd := command.NewCmd(LVExpandCmdTmpl, strconv.FormatInt(requiredSize, 10), command.Device(lvName))
 	_, _, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(LVExpandCmdTmpl, "", ""))))
@@ -148,7 +194,12 @@ func (l *LVM) ExpandLV(lvName string, requiredSize int64) error {
 // Receives name of VG to create and names of physical volumes which VG should based on
 // Returns error if something went wrong
 func (l *LVM) VGCreate(name string, pvs ...string) error {
-	cmd := fmt.Sprintf(VGCreateCmdTmpl, name, strings.Join(pvs, " "))
+	for _, pv := range pvs {
+		if err := command.ValidateDevice(pv); err != nil {
+			return err
+		}
+	}
+	cmd := command.NewCmd(VGCreateCmdTmpl, command.Name(name), command.List(pvs))
 	_, stdErr, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGCreateCmdTmpl, "", ""))))
@@ -199,7 +250,7 @@ func (l *LVM) VGScan(name string) (bool, error) {
 func (l *LVM) VGReactivate(name string) error {
 	l.log.Infof("Trying to re-activate volume group %s", name)
 	// re-activate related LVs
-	if _, _, err := l.e.RunCmd(fmt.Sprintf(VGRefreshCmdTmpl, name), command.UseMetrics(true),
+	if _, _, err := l.e.RunCmd(command.NewCmd(VGRefreshCmdTmpl, command.Name(name)), command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGRefreshCmdTmpl, "")))); err != nil {
 		return err
 	}
@@ -207,11 +258,108 @@ func (l *LVM) VGReactivate(name string) error {
 	return nil
 }
 
+// GetVGMissingPVs returns count of PVs which are missing in volume group, for example after reboot without a drive
+// Receives name of VG
+// Returns count of missing PVs or error if something went wrong
+func (l *LVM) GetVGMissingPVs(name string) (int, error) {
+	cmd := command.NewCmd(VGMissingPVsCmdTmpl, command.Name(name))
+	stdout, _, err := l.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGMissingPVsCmdTmpl, ""))))
+	if err != nil {
+		return 0, err
+	}
+	value, err := parseReportValue(stdout)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse missing PVs count for VG %s from output %s: %v", name, stdout, err)
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse missing PVs count for VG %s from output %s: %v", name, stdout, err)
+	}
+	return count, nil
+}
+
+// VGActivateDegraded activates LVs of volume group with missing PVs
+// LVs without redundancy which are located on missing PVs stay inactive
+// Receives name of VG
+// Returns error if something went wrong
+func (l *LVM) VGActivateDegraded(name string) error {
+	l.log.Infof("Trying to activate volume group %s in degraded mode", name)
+	_, _, err := l.e.RunCmd(command.NewCmd(VGActivateDegradedCmdTmpl, command.Name(name)),
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGActivateDegradedCmdTmpl, ""))))
+	return err
+}
+
+// VGReduceMissing removes missing PVs from volume group
+// Receives name of VG
+// Returns error if something went wrong, for example if some LVs are located on missing PVs
+func (l *LVM) VGReduceMissing(name string) error {
+	l.log.Infof("Trying to remove missing PVs from volume group %s", name)
+	_, stdErr, err := l.e.RunCmd(command.NewCmd(VGReduceMissingCmdTmpl, command.Name(name)),
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGReduceMissingCmdTmpl, ""))))
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stdErr))
+	}
+	return nil
+}
+
+// GetVGSeqNo returns sequence number of volume group metadata, it's changed on each change of VG and its LVs
+// Receives name of VG
+// Returns sequence number or error if something went wrong
+func (l *LVM) GetVGSeqNo(name string) (int64, error) {
+	cmd := command.NewCmd(VGSeqNoCmdTmpl, command.Name(name))
+	stdout, _, err := l.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGSeqNoCmdTmpl, ""))))
+	if err != nil {
+		return 0, err
+	}
+	value, err := parseReportValue(stdout)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse metadata seqno for VG %s from output %s: %v", name, stdout, err)
+	}
+	seqNo, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse metadata seqno for VG %s from output %s: %v", name, stdout, err)
+	}
+	return seqNo, nil
+}
+
+// VGCfgBackup writes metadata of volume group to file
+// Receives name of VG and absolute path of file
+// Returns error if something went wrong
+func (l *LVM) VGCfgBackup(name, file string) error {
+	_, stdErr, err := l.e.RunCmd(command.NewCmd(VGCfgBackupCmdTmpl, command.Device(file), command.Name(name)),
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGCfgBackupCmdTmpl, "", ""))))
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stdErr))
+	}
+	return nil
+}
+
+// VGCfgRestore restores metadata of volume group from file which was written by VGCfgBackup
+// Receives name of VG and absolute path of file
+// Returns error if something went wrong
+func (l *LVM) VGCfgRestore(name, file string) error {
+	l.log.Infof("Trying to restore metadata of volume group %s from %s", name, file)
+	_, stdErr, err := l.e.RunCmd(command.NewCmd(VGCfgRestoreCmdTmpl, command.Device(file), command.Name(name)),
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGCfgRestoreCmdTmpl, "", ""))))
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stdErr))
+	}
+	return nil
+}
+
 // VGRemove removes volume group, ignore error if VG doesn't exist
 // Receives name of VG to remove
 // Returns error if something went wrong
 func (l *LVM) VGRemove(name string) error {
-	cmd := fmt.Sprintf(VGRemoveCmdTmpl, name)
+	cmd := command.NewCmd(VGRemoveCmdTmpl, command.Name(name))
 	_, stdErr, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGRemoveCmdTmpl, ""))))
@@ -225,7 +373,7 @@ func (l *LVM) VGRemove(name string) error {
 // Receives name of created LV, size which is a string like 1.2G, 100M and name of VG which LV should be based on
 // Returns error if something went wrong
 func (l *LVM) LVCreate(name, size, vgName string) error {
-	cmd := fmt.Sprintf(LVCreateCmdTmpl, name, size, vgName)
+	cmd := command.NewCmd(LVCreateCmdTmpl, command.Name(name), size, command.Name(vgName))
 	_, stdErr, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(LVCreateCmdTmpl, "", "", ""))))
@@ -235,11 +383,66 @@ func (l *LVM) LVCreate(name, size, vgName string) error {
 	return err
 }
 
+// LVCreateThinSnapshot creates thin snapshot of thin logical volume origin, ignore error if LV already exists
+// Snapshot shares blocks with origin until they are overwritten, so it is created instantly and its size is
+// the same as size of origin
+// Receives name of created LV, name of origin LV and name of VG where origin is located
+// Returns error if something went wrong
+func (l *LVM) LVCreateThinSnapshot(name, origin, vgName string) error {
+	cmd := command.NewCmd(LVCreateThinSnapshotCmdTmpl, command.Name(name), command.Name(vgName), command.Name(origin))
+	_, stdErr, err := l.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(LVCreateThinSnapshotCmdTmpl, "", "", ""))))
+	if err != nil && strings.Contains(stdErr, "already exists") {
+		return nil
+	}
+	return err
+}
+
+// LVCreateReadOnlySnapshot creates read-only snapshot of logical volume origin, ignore error if LV already exists
+// Snapshot keeps content of origin at the moment of creation, it becomes invalid when changes of origin
+// exceed size of snapshot
+// Receives name of created LV, size of space for changes, name of origin LV and name of VG where origin is located
+// Returns error if something went wrong
+func (l *LVM) LVCreateReadOnlySnapshot(name, size, origin, vgName string) error {
+	cmd := command.NewCmd(LVCreateReadOnlySnapshotCmdTmpl, size, command.Name(name), command.Name(vgName),
+		command.Name(origin))
+	_, stdErr, err := l.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(LVCreateReadOnlySnapshotCmdTmpl, "", "", "", ""))))
+	if err != nil && strings.Contains(stdErr, "already exists") {
+		return nil
+	}
+	return err
+}
+
+// GetLVSize returns size of logical volume in bytes
+// Receives fullLVName that is a path to LV
+// Returns -1 and error if something went wrong
+func (l *LVM) GetLVSize(fullLVName string) (int64, error) {
+	cmd := command.NewCmd(LVSizeCmdTmpl, command.Device(fullLVName))
+	stdout, _, err := l.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(LVSizeCmdTmpl, ""))))
+	if err != nil {
+		return -1, err
+	}
+	value, err := parseReportValue(stdout)
+	if err != nil {
+		return -1, fmt.Errorf("unable to parse size of LV %s from output %s: %v", fullLVName, stdout, err)
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return -1, fmt.Errorf("unable to parse size of LV %s: %v", fullLVName, err)
+	}
+	return size, nil
+}
+
 // LVRemove removes logical volume, ignore error if LV doesn't exist
 // Receives fullLVName that is a path to LV
 // Returns error if something went wrong
 func (l *LVM) LVRemove(fullLVName string) error {
-	cmd := fmt.Sprintf(LVRemoveCmdTmpl, fullLVName)
+	cmd := command.NewCmd(LVRemoveCmdTmpl, command.Device(fullLVName))
 	_, stdErr, err := l.e.RunCmdWithAttempts(cmd, 5, timeoutBetweenAttempts, command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(LVRemoveCmdTmpl, ""))))
 	if err != nil && strings.Contains(stdErr, "Failed to find logical volume") {
@@ -252,7 +455,7 @@ func (l *LVM) LVRemove(fullLVName string) error {
 // Receives Volume Group name to check
 // Returns true in case of error to prevent mistaken VG remove
 func (l *LVM) IsVGContainsLVs(vgName string) bool {
-	cmd := fmt.Sprintf(LVsInVGCmdTmpl, vgName)
+	cmd := command.NewCmd(LVsInVGCmdTmpl, command.Name(vgName))
 	stdout, _, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(LVsInVGCmdTmpl, ""))))
@@ -269,7 +472,7 @@ func (l *LVM) IsVGContainsLVs(vgName string) bool {
 // Receives Volume Group name
 // Returns slice of found logical volumes
 func (l *LVM) GetLVsInVG(vgName string) ([]string, error) {
-	cmd := fmt.Sprintf(LVsInVGCmdTmpl, vgName)
+	cmd := command.NewCmd(LVsInVGCmdTmpl, command.Name(vgName))
 	stdout, _, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(LVsInVGCmdTmpl, ""))))
@@ -277,13 +480,13 @@ func (l *LVM) GetLVsInVG(vgName string) ([]string, error) {
 		return nil, err
 	}
 
-	return util.SplitAndTrimSpace(stdout, "\n"), nil
+	return parseReportColumn(stdout), nil
 }
 
 // RemoveOrphanPVs removes PVs that do not have VG
 // Returns error if something went wrong
 func (l *LVM) RemoveOrphanPVs() error {
-	pvsCmd := fmt.Sprintf(PVsInVGCmdTmpl, EmptyName)
+	pvsCmd := command.NewCmd(PVsInVGCmdTmpl, EmptyName)
 	stdout, _, err := l.e.RunCmd(pvsCmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(PVsInVGCmdTmpl, ""))))
@@ -291,10 +494,7 @@ func (l *LVM) RemoveOrphanPVs() error {
 		return err
 	}
 	var wasError bool
-	for _, pv := range strings.Split(strings.TrimSpace(stdout), "\n") {
-		if len(pv) == 0 {
-			continue
-		}
+	for _, pv := range parseReportColumn(stdout) {
 		if err := l.PVRemove(pv); err != nil {
 			l.log.WithField("method", "RemoveOrphanPVs").Errorf("Unable to remove pv %s: %v", pv, err)
 			wasError = true
@@ -320,7 +520,7 @@ func (l *LVM) GetVgFreeSpace(vgName string) (int64, error) {
 		return -1, errors.New("VG name shouldn't be an empty string")
 	}
 
-	cmd := fmt.Sprintf(VGFreeSpaceCmdTmpl, vgName)
+	cmd := command.NewCmd(VGFreeSpaceCmdTmpl, command.Name(vgName))
 	strOut, _, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGFreeSpaceCmdTmpl, ""))))
@@ -328,7 +528,12 @@ func (l *LVM) GetVgFreeSpace(vgName string) (int64, error) {
 		return -1, err
 	}
 
-	bytes, err := util.StrToBytes(strings.TrimSpace(strOut))
+	value, err := parseReportValue(strOut)
+	if err != nil {
+		return -1, fmt.Errorf("unable to parse free space of VG %s from output %s: %v", vgName, strOut, err)
+	}
+
+	bytes, err := util.StrToBytes(value)
 	if err != nil {
 		return -1, err
 	}
@@ -336,6 +541,24 @@ func (l *LVM) GetVgFreeSpace(vgName string) (int64, error) {
 	return bytes, nil
 }
 
+// GetVGSize returns VG size in bytes
+// Receives VG name
+// Returns size or error if something went wrong
+func (l *LVM) GetVGSize(vgName string) (int64, error) {
+	cmd := command.NewCmd(VGSizeCmdTmpl, command.Name(vgName))
+	strOut, _, err := l.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(VGSizeCmdTmpl, ""))))
+	if err != nil {
+		return 0, err
+	}
+	value, err := parseReportValue(strOut)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse size of VG %s from output %s: %v", vgName, strOut, err)
+	}
+	return util.StrToBytes(value)
+}
+
 // GetAllPVs returns slice with names of all physical volumes in the system
 func (l *LVM) GetAllPVs() ([]string, error) {
 	stdOut, _, err := l.e.RunCmd(AllPVsCmd,
@@ -345,12 +568,12 @@ func (l *LVM) GetAllPVs() ([]string, error) {
 		return nil, err
 	}
 
-	return util.SplitAndTrimSpace(stdOut, "\n"), nil
+	return parseReportColumn(stdOut), nil
 }
 
 // GetVGNameByPVName finds out volume group name based on physical volume name
 func (l *LVM) GetVGNameByPVName(pvName string) (string, error) {
-	cmd := fmt.Sprintf(PVInfoCmdTmpl, pvName)
+	cmd := command.NewCmd(PVInfoCmdTmpl, command.Device(pvName))
 
 	stdOut, _, err := l.e.RunCmd(cmd,
 		command.UseMetrics(true),
@@ -367,15 +590,13 @@ func (l *LVM) GetVGNameByPVName(pvName string) (string, error) {
 	// 		"/dev/sda" is a new physical volume of "<7.28 TiB"
 	//  	/dev/sda::15628053168:-1:0:0:-1:0:0:0:0:2DdQcG-u5gq-mqa0-awmS-EsDP-GQFB-sfPHTw
 	// parse stdOut:
-	trimmed := strings.TrimSpace(stdOut)
-	if len(strings.Split(trimmed, "\n")) > 1 {
-		return "", fmt.Errorf("PV %s isn't related to any VG", pvName)
+	vgName, found := parsePVDisplayColon(stdOut, pvName)
+	if !found {
+		return "", fmt.Errorf("unable to find VG name for PV %s in output %s: ", pvName, strings.TrimSpace(stdOut))
 	}
-
-	splitted := strings.Split(trimmed, ":")
-	if len(splitted) < 2 {
-		return "", fmt.Errorf("unable to find VG name for PV %s in output %s: ", pvName, trimmed)
+	if vgName == "" {
+		return "", fmt.Errorf("PV %s isn't related to any VG", pvName)
 	}
 
-	return splitted[1], nil
+	return vgName, nil
 }
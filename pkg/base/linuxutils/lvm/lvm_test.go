@@ -148,6 +148,86 @@ func TestLinuxUtils_VGScan(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestLinuxUtils_GetVGMissingPVs(t *testing.T) {
+	var (
+		e   = &mocks.GoMockExecutor{}
+		l   = NewLVM(e, testLogger)
+		vg  = "test-vg"
+		cmd = fmt.Sprintf(VGMissingPVsCmdTmpl, vg)
+	)
+
+	e.OnCommand(cmd).Return("  1\n", "", nil).Times(1)
+	count, err := l.GetVGMissingPVs(vg)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	e.OnCommand(cmd).Return("  Volume group \"test-vg\" not found", "", nil).Times(1)
+	_, err = l.GetVGMissingPVs(vg)
+	assert.NotNil(t, err)
+
+	e.OnCommand(cmd).Return("", "", errors.New("error")).Times(1)
+	_, err = l.GetVGMissingPVs(vg)
+	assert.NotNil(t, err)
+}
+
+func TestLinuxUtils_VGActivateDegradedAndReduceMissing(t *testing.T) {
+	var (
+		e  = &mocks.GoMockExecutor{}
+		l  = NewLVM(e, testLogger)
+		vg = "test-vg"
+	)
+
+	e.OnCommand(fmt.Sprintf(VGActivateDegradedCmdTmpl, vg)).Return("", "", nil).Times(1)
+	assert.Nil(t, l.VGActivateDegraded(vg))
+
+	e.OnCommand(fmt.Sprintf(VGReduceMissingCmdTmpl, vg)).Return("", "", nil).Times(1)
+	assert.Nil(t, l.VGReduceMissing(vg))
+
+	e.OnCommand(fmt.Sprintf(VGReduceMissingCmdTmpl, vg)).
+		Return("", "There are still partial LVs in VG test-vg.", errors.New("error")).Times(1)
+	err := l.VGReduceMissing(vg)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "partial LVs")
+}
+
+func TestLinuxUtils_GetVGSeqNo(t *testing.T) {
+	var (
+		e   = &mocks.GoMockExecutor{}
+		l   = NewLVM(e, testLogger)
+		vg  = "test-vg"
+		cmd = fmt.Sprintf(VGSeqNoCmdTmpl, vg)
+	)
+
+	e.OnCommand(cmd).Return("  12\n", "", nil).Times(1)
+	seqNo, err := l.GetVGSeqNo(vg)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12), seqNo)
+
+	e.OnCommand(cmd).Return("", "", errors.New("error")).Times(1)
+	_, err = l.GetVGSeqNo(vg)
+	assert.NotNil(t, err)
+}
+
+func TestLinuxUtils_VGCfgBackupAndRestore(t *testing.T) {
+	var (
+		e    = &mocks.GoMockExecutor{}
+		l    = NewLVM(e, testLogger)
+		vg   = "test-vg"
+		file = "/tmp/test-vg.vg"
+	)
+
+	e.OnCommand(fmt.Sprintf(VGCfgBackupCmdTmpl, file, vg)).Return("", "", nil).Times(1)
+	assert.Nil(t, l.VGCfgBackup(vg, file))
+
+	e.OnCommand(fmt.Sprintf(VGCfgRestoreCmdTmpl, file, vg)).
+		Return("", "Cannot restore Volume Group test-vg with 1 PVs marked as missing.", errors.New("error")).Times(1)
+	err := l.VGCfgRestore(vg, file)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "marked as missing")
+
+	assert.NotNil(t, l.VGCfgBackup(vg, "relative.vg"))
+}
+
 func TestLinuxUtils_VGRemove(t *testing.T) {
 	var (
 		e           = &mocks.GoMockExecutor{}
@@ -196,6 +276,94 @@ func TestLinuxUtils_LVCreate(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 }
 
+func TestLinuxUtils_LVCreateThinSnapshot(t *testing.T) {
+	var (
+		e           = &mocks.GoMockExecutor{}
+		l           = NewLVM(e, testLogger)
+		lv          = "test-lv"
+		origin      = "ubuntu-20.04"
+		vg          = "templates"
+		cmd         = fmt.Sprintf(LVCreateThinSnapshotCmdTmpl, lv, vg, origin)
+		expectedErr = errors.New("error")
+	)
+
+	e.OnCommand(cmd).Return("", "", nil).Times(1)
+	assert.Nil(t, l.LVCreateThinSnapshot(lv, origin, vg))
+
+	e.OnCommand(cmd).Return("", "already exists", expectedErr).Times(1)
+	assert.Nil(t, l.LVCreateThinSnapshot(lv, origin, vg))
+
+	e.OnCommand(cmd).Return("", "", expectedErr).Times(1)
+	assert.Equal(t, expectedErr, l.LVCreateThinSnapshot(lv, origin, vg))
+
+	// origin is validated as a name
+	assert.NotNil(t, l.LVCreateThinSnapshot(lv, "../origin", vg))
+}
+
+func TestLinuxUtils_LVCreateReadOnlySnapshot(t *testing.T) {
+	var (
+		e           = &mocks.GoMockExecutor{}
+		l           = NewLVM(e, testLogger)
+		lv          = "test-lv-nbd"
+		origin      = "test-lv"
+		vg          = "test-vg"
+		cmd         = fmt.Sprintf(LVCreateReadOnlySnapshotCmdTmpl, "1048576b", lv, vg, origin)
+		expectedErr = errors.New("error")
+	)
+
+	e.OnCommand(cmd).Return("", "", nil).Times(1)
+	assert.Nil(t, l.LVCreateReadOnlySnapshot(lv, "1048576b", origin, vg))
+
+	e.OnCommand(cmd).Return("", "already exists", expectedErr).Times(1)
+	assert.Nil(t, l.LVCreateReadOnlySnapshot(lv, "1048576b", origin, vg))
+
+	e.OnCommand(cmd).Return("", "", expectedErr).Times(1)
+	assert.Equal(t, expectedErr, l.LVCreateReadOnlySnapshot(lv, "1048576b", origin, vg))
+}
+
+func TestLinuxUtils_GetLVSize(t *testing.T) {
+	var (
+		e           = &mocks.GoMockExecutor{}
+		l           = NewLVM(e, testLogger)
+		lv          = "/dev/templates/test-lv"
+		cmd         = fmt.Sprintf(LVSizeCmdTmpl, lv)
+		expectedErr = errors.New("error")
+	)
+
+	e.OnCommand(cmd).Return("  10737418240\n", "", nil).Times(1)
+	size, err := l.GetLVSize(lv)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10737418240), size)
+
+	e.OnCommand(cmd).Return("", "", expectedErr).Times(1)
+	size, err = l.GetLVSize(lv)
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, int64(-1), size)
+
+	e.OnCommand(cmd).Return("  10G\n", "", nil).Times(1)
+	_, err = l.GetLVSize(lv)
+	assert.NotNil(t, err)
+
+	e.OnCommand(cmd).Return("", "", nil).Times(1)
+	_, err = l.GetLVSize(lv)
+	assert.NotNil(t, err)
+}
+
+func TestLinuxUtils_InvalidArguments(t *testing.T) {
+	var (
+		e = &mocks.GoMockExecutor{}
+		l = NewLVM(e, testLogger)
+	)
+
+	// executor mock has no expectations, any call would panic
+	assert.NotNil(t, l.LVCreate("lv; reboot", "9g", "test-lvg"))
+	assert.NotNil(t, l.LVCreate("test-lv", "9g", "--yes"))
+	assert.NotNil(t, l.PVCreate("sda"))
+	assert.NotNil(t, l.PVRemove("/dev/sda /dev/sdb"))
+	assert.NotNil(t, l.VGCreate("test-lvg", "/dev/sda", "$(reboot)"))
+	assert.NotNil(t, l.VGRemove("test lvg"))
+}
+
 func TestLinuxUtils_LVRemove(t *testing.T) {
 	var (
 		e           = &mocks.GoMockExecutor{}
@@ -332,6 +500,28 @@ func TestLinuxUtils_GetVgFreeSpace(t *testing.T) {
 	assert.Contains(t, err.Error(), "unknown size unit")
 }
 
+func TestLinuxUtils_PVResizeAndGetVGSize(t *testing.T) {
+	var (
+		e      = &mocks.GoMockExecutor{}
+		l      = NewLVM(e, testLogger)
+		dev    = "/dev/sda"
+		vgName = "vg-1"
+	)
+
+	e.OnCommand(fmt.Sprintf(PVResizeCmdTmpl, dev)).Return("", "", nil).Times(1)
+	assert.Nil(t, l.PVResize(dev))
+	e.OnCommand(fmt.Sprintf(PVResizeCmdTmpl, dev)).Return("", "", errors.New("error")).Times(1)
+	assert.NotNil(t, l.PVResize(dev))
+
+	e.OnCommand(fmt.Sprintf(VGSizeCmdTmpl, vgName)).Return("  2147483648B\n", "", nil).Times(1)
+	size, err := l.GetVGSize(vgName)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2147483648), size)
+	e.OnCommand(fmt.Sprintf(VGSizeCmdTmpl, vgName)).Return("", "", errors.New("error")).Times(1)
+	_, err = l.GetVGSize(vgName)
+	assert.NotNil(t, err)
+}
+
 func TestLinuxUtils_GetAllPVs(t *testing.T) {
 	var (
 		e           = &mocks.GoMockExecutor{}
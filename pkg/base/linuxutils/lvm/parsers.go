@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lvm
+
+import (
+	"errors"
+	"strings"
+)
+
+// errNoValue is returned by parseReportValue when output doesn't contain any value
+var errNoValue = errors.New("output doesn't contain any value")
+
+// isReportValue checks whether trimmed line of lvm report with --noheadings option is a value.
+// Reported values (PV, LV names, sizes, counts) never contain whitespaces, so lines with whitespaces
+// are warnings or error messages which lvm prints to stdout, for example "File descriptor 7 leaked on lvm invocation"
+func isReportValue(line string) bool {
+	return line != "" && !strings.ContainsAny(line, " \t\"")
+}
+
+// parseReportColumn parses output of lvm report command with single column and --noheadings option
+// Returns slice of values, lines which aren't values are skipped
+func parseReportColumn(output string) []string {
+	res := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if isReportValue(line) {
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+// parseReportValue parses output of lvm report command with single column and --noheadings option for one object
+// Returns first value from output or errNoValue if output doesn't contain any value
+func parseReportValue(output string) (string, error) {
+	values := parseReportColumn(output)
+	if len(values) == 0 {
+		return "", errNoValue
+	}
+	return values[0], nil
+}
+
+// parsePVDisplayColon parses output of pvdisplay --colon command
+// output contains colon (:) separated line, where PV name is on the first place and VG name is on the second,
+// the line might be preceded by messages, for example "/dev/sda" is a new physical volume of "<7.28 TiB"
+// Returns VG name, flag whether colon separated line was found and VG name is empty when PV is orphan
+func parsePVDisplayColon(output, pvName string) (vgName string, found bool) {
+	var fallback []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !isReportValue(line) {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < 2 {
+			continue
+		}
+		if fields[0] == pvName {
+			return fields[1], true
+		}
+		if fallback == nil {
+			fallback = fields
+		}
+	}
+	if fallback == nil {
+		return "", false
+	}
+	return fallback[1], true
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fuzzinput"
+)
+
+const (
+	testPVDisplayOrphan = `  "/dev/sda" is a new physical volume of "<7.28 TiB"
+  /dev/sda::15628053168:-1:0:0:-1:0:0:0:0:2DdQcG-u5gq-mqa0-awmS-EsDP-GQFB-sfPHTw`
+	testPVDisplayInVG = "  /dev/sdy2:root-vg:936701952:-1:8:8:-1:4096:114343:77478:36865:H3rxE6-2iAg-1REQ-rOeX-7bz3-iPrh-YBXgxN"
+)
+
+func TestParseReportColumn(t *testing.T) {
+	assert.Equal(t, []string{}, parseReportColumn(""))
+	assert.Equal(t, []string{"/dev/sda", "/dev/sdb"}, parseReportColumn("  /dev/sda\n  /dev/sdb  \n\n"))
+	assert.Equal(t, []string{"lv1"}, parseReportColumn(
+		"File descriptor 7 (/dev/urandom) leaked on lvm invocation. Parent PID 1: /csi-baremetal-node\n  lv1\n"+
+			"  WARNING: Device /dev/sdc has size of 0 sectors"))
+}
+
+func TestParseReportValue(t *testing.T) {
+	value, err := parseReportValue("\t\t 1024B \n")
+	assert.Nil(t, err)
+	assert.Equal(t, "1024B", value)
+
+	value, err = parseReportValue("  WARNING: PV /dev/sdc is missing\n  1\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", value)
+
+	_, err = parseReportValue("  Volume group \"test-vg\" not found")
+	assert.Equal(t, errNoValue, err)
+}
+
+func TestParsePVDisplayColon(t *testing.T) {
+	vg, found := parsePVDisplayColon(testPVDisplayInVG, "/dev/sdy2")
+	assert.True(t, found)
+	assert.Equal(t, "root-vg", vg)
+
+	vg, found = parsePVDisplayColon(testPVDisplayOrphan, "/dev/sda")
+	assert.True(t, found)
+	assert.Equal(t, "", vg)
+
+	// PV might be referenced by symlink
+	vg, found = parsePVDisplayColon(testPVDisplayInVG, "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3-part2")
+	assert.True(t, found)
+	assert.Equal(t, "root-vg", vg)
+
+	vg, found = parsePVDisplayColon("  WARNING: Not using lvmetad\n"+testPVDisplayInVG, "/dev/sdy2")
+	assert.True(t, found)
+	assert.Equal(t, "root-vg", vg)
+
+	_, found = parsePVDisplayColon("/dev/sda", "/dev/sda")
+	assert.False(t, found)
+	_, found = parsePVDisplayColon("", "/dev/sda")
+	assert.False(t, found)
+}
+
+func TestParsers_Fuzz(t *testing.T) {
+	for _, seed := range []string{"  lv1\n  lv2\n", "  1024B\n", testPVDisplayInVG, testPVDisplayOrphan} {
+		for _, input := range fuzzinput.Variants(seed, 200) {
+			assert.NotPanics(t, func() {
+				parseReportColumn(input)
+				_, _ = parseReportValue(input)
+				_, _ = parsePVDisplayColon(input, "/dev/sdy2")
+			}, "input: %q", input)
+		}
+	}
+}
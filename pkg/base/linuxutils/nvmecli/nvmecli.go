@@ -38,11 +38,15 @@ const (
 	NVMeVendorCmdImpl = NVMCliCmdImpl + " id-ctrl %s --output-format=json"
 	// DevicesKey is the key to find NVMe devices in nvme json output
 	DevicesKey = "Devices"
+
+	kelvinOffset = 273
 )
 
 // WrapNvmecli is an interface that encapsulates operation with system nvme util
 type WrapNvmecli interface {
 	GetNVMDevices() ([]NVMDevice, error)
+	ListNVMDevices() ([]NVMDevice, error)
+	FillNVMDevice(device *NVMDevice)
 }
 
 // NVMDevice represents devices from nvme list output
@@ -55,11 +59,25 @@ type NVMDevice struct {
 	// Can VID be string for nvme?
 	Vendor int `json:"vid,omitempty"`
 	Health string
+	// SMARTLog is nil if SMART information isn't available
+	SMARTLog *SMARTLog `json:"-"`
 }
 
 // SMARTLog represents SMART information for NVMe devices
 type SMARTLog struct {
-	CriticalWarning int `json:"critical_warning,omitempty"`
+	CriticalWarning int   `json:"critical_warning,omitempty"`
+	MediaErrors     int64 `json:"media_errors,omitempty"`
+	PercentUsed     int64 `json:"percent_used,omitempty"`
+	// composite temperature in Kelvin
+	Temperature int64 `json:"temperature,omitempty"`
+}
+
+// TemperatureCelsius returns composite temperature in Celsius, 0 if temperature isn't reported
+func (l *SMARTLog) TemperatureCelsius() int64 {
+	if l.Temperature <= kelvinOffset {
+		return 0
+	}
+	return l.Temperature - kelvinOffset
 }
 
 // NVMECLI is a wrap for system nvem_cli util
@@ -75,7 +93,19 @@ func NewNVMECLI(e command.CmdExecutor, logger *logrus.Logger) *NVMECLI {
 
 // GetNVMDevices gets information about NVMDevice using nvme_cli util
 func (na *NVMECLI) GetNVMDevices() ([]NVMDevice, error) {
-	ll := na.log.WithField("method", "GetNVMDevices")
+	devs, err := na.ListNVMDevices()
+	if err != nil {
+		return nil, err
+	}
+	for i := range devs {
+		na.FillNVMDevice(&devs[i])
+	}
+	return devs, nil
+}
+
+// ListNVMDevices gets NVMe devices from nvme list, health, SMART log and vendor aren't filled
+func (na *NVMECLI) ListNVMDevices() ([]NVMDevice, error) {
+	ll := na.log.WithField("method", "ListNVMDevices")
 	strOut, _, err := na.e.RunCmd(NVMeDeviceCmdImpl,
 		command.UseMetrics(true),
 		command.CmdName(NVMeDeviceCmdImpl))
@@ -95,15 +125,18 @@ func (na *NVMECLI) GetNVMDevices() ([]NVMDevice, error) {
 		ll.Errorf("key \"%s\" is not in map %v", DevicesKey, rawOut)
 		return nil, fmt.Errorf("unexpected nvme list output format")
 	}
-	for i, d := range devs {
-		devs[i].Health = na.getNVMDeviceHealth(d.DevicePath)
-		na.fillNVMDeviceVendor(&devs[i])
-	}
 	return devs, nil
 }
 
+// FillNVMDevice fills health, SMART log and vendor of device with per-device nvme_cli commands
+func (na *NVMECLI) FillNVMDevice(device *NVMDevice) {
+	device.Health, device.SMARTLog = na.getNVMDeviceHealth(device.DevicePath)
+	na.fillNVMDeviceVendor(device)
+}
+
 // getNVMDeviceHealth gets information about device health based on critical_warning SMART attribute using nvme_cli smart-log util
-func (na *NVMECLI) getNVMDeviceHealth(path string) string {
+// Returns health and SMART log, SMART log is nil if it can't be read
+func (na *NVMECLI) getNVMDeviceHealth(path string) (string, *SMARTLog) {
 	ll := na.log.WithField("method", "getNVMDeviceHealth")
 	cmd := fmt.Sprintf(NVMeHealthCmdImpl, path)
 	strOut, _, err := na.e.RunCmd(cmd,
@@ -111,22 +144,22 @@ func (na *NVMECLI) getNVMDeviceHealth(path string) string {
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(NVMeHealthCmdImpl, ""))))
 	if err != nil {
 		ll.Errorf("%s failed, set health as %s", cmd, apiV1.HealthUnknown)
-		return apiV1.HealthUnknown
+		return apiV1.HealthUnknown, nil
 	}
 	smartLog := &SMARTLog{}
 	err = json.Unmarshal([]byte(strOut), &smartLog)
 	if err != nil {
 		ll.Errorf("unable to unmarshal output to SMARTLog, set health as %s", apiV1.HealthUnknown)
-		return apiV1.HealthUnknown
+		return apiV1.HealthUnknown, nil
 	}
 	health := smartLog.CriticalWarning
 	if na.isOneOfBitsSet(uint64(health), 0, 3) {
-		return apiV1.HealthSuspect
+		return apiV1.HealthSuspect, smartLog
 	}
 	if na.isOneOfBitsSet(uint64(health), 2, 4, 5) {
-		return apiV1.HealthBad
+		return apiV1.HealthBad, smartLog
 	}
-	return apiV1.HealthGood
+	return apiV1.HealthGood, smartLog
 }
 
 // fillNVMDeviceVendor gets information about device vendor id
@@ -0,0 +1,386 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionhelper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/units"
+)
+
+// Impact of partition misalignment on performance of I/O
+const (
+	// AlignmentImpactNone means that partition is aligned
+	AlignmentImpactNone = "none"
+	// AlignmentImpactMinor means that partition is aligned to physical sectors and optimal I/O size,
+	// but not to 1 MiB boundary which partitioning tools use, e.g. erase blocks of SSD might be crossed
+	AlignmentImpactMinor = "minor"
+	// AlignmentImpactModerate means that I/O requests of optimal size are split between RAID stripes or erase blocks
+	AlignmentImpactModerate = "moderate"
+	// AlignmentImpactSevere means that partition isn't aligned to physical sectors, so each write
+	// of physical sector turns into read-modify-write cycle of the drive
+	AlignmentImpactSevere = "severe"
+
+	// DefaultAlignment is a boundary which partitions are aligned to by sgdisk and parted
+	DefaultAlignment = int64(units.MiB)
+
+	// SysClassBlock is a sysfs directory with block devices and partitions
+	SysClassBlock = "/sys/class/block"
+	// gptEntriesSize is a size of partition entries array of GPT, backup GPT is entries and header block
+	gptEntriesSize = 128 * 128
+	// realignChunkSize is a size of data which is moved at once during partition realignment
+	realignChunkSize = 64 * int64(units.MiB)
+
+	// GetPartitionInfoCmdTmpl reads GPT entry of partition, fill device and part number
+	GetPartitionInfoCmdTmpl = GetPartitionUUIDCmdTmpl
+	// RecreatePartitionCmdTmpl re-creates partition at new location keeping its type, GUID and label,
+	// fill part number, part number, first sector, last sector, part number, type GUID, part number, GUID,
+	// part number, label and device
+	RecreatePartitionCmdTmpl = sgdisk + "-d %s -n %s:%d:%d -t %s:%s -u %s:%s -c %s:%s %s"
+	// RecreatePartitionNoLabelCmdTmpl is the same as RecreatePartitionCmdTmpl for partition without label
+	RecreatePartitionNoLabelCmdTmpl = sgdisk + "-d %s -n %s:%d:%d -t %s:%s -u %s:%s %s"
+)
+
+// ErrRealignNotStarted is returned when partition realignment fails before data is moved, so data is intact
+var ErrRealignNotStarted = errors.New("realignment isn't started")
+
+// PartitionAlignment describes placement of partition on its drive, offsets and sizes are in bytes
+type PartitionAlignment struct {
+	// Device is a path of the drive, e.g. /dev/sda
+	Device string
+	// Partition is a path of the partition, e.g. /dev/sda1
+	Partition string
+	// PartNum is a number of the partition in partition table
+	PartNum           string
+	Start             int64
+	Size              int64
+	DeviceSize        int64
+	LogicalBlockSize  int64
+	PhysicalBlockSize int64
+	OptimalIOSize     int64
+}
+
+// Boundary returns boundary which partition should be aligned to: the least common multiple of
+// DefaultAlignment, physical sector size and optimal I/O size of the drive
+func (a *PartitionAlignment) Boundary() int64 {
+	boundary := DefaultAlignment
+	for _, size := range []int64{a.PhysicalBlockSize, a.OptimalIOSize} {
+		if size > 0 {
+			boundary = boundary / gcd(boundary, size) * size
+		}
+	}
+	return boundary
+}
+
+// Misalignment returns offset of partition start from the nearest preceding boundary
+func (a *PartitionAlignment) Misalignment() int64 {
+	return a.Start - units.AlignDown(a.Start, a.Boundary())
+}
+
+// Impact returns impact of misalignment on performance, AlignmentImpactNone if partition is aligned
+func (a *PartitionAlignment) Impact() string {
+	switch {
+	case a.PhysicalBlockSize > 0 && !units.IsAligned(a.Start, a.PhysicalBlockSize):
+		return AlignmentImpactSevere
+	case a.OptimalIOSize > 0 && !units.IsAligned(a.Start, a.OptimalIOSize):
+		return AlignmentImpactModerate
+	case a.Misalignment() != 0:
+		return AlignmentImpactMinor
+	}
+	return AlignmentImpactNone
+}
+
+// PlanRealign returns new start of partition at boundary. Partition is moved back to the preceding boundary
+// if it doesn't overlap primary GPT, otherwise it is moved forward if it fits before backup GPT
+// Returns error if partition is aligned or there is no space to move it
+func (a *PartitionAlignment) PlanRealign() (int64, error) {
+	misalignment := a.Misalignment()
+	if misalignment == 0 {
+		return 0, fmt.Errorf("partition %s is aligned", a.Partition)
+	}
+	if a.LogicalBlockSize <= 0 {
+		return 0, fmt.Errorf("logical block size of %s is unknown", a.Device)
+	}
+	boundary := a.Boundary()
+	// primary GPT and partition entries fit into the first boundary
+	if start := a.Start - misalignment; start >= boundary {
+		return start, nil
+	}
+	start := a.Start - misalignment + boundary
+	end, err := units.Add(start, a.Size)
+	if limit := a.DeviceSize - gptEntriesSize - a.LogicalBlockSize; err != nil || end > limit {
+		return 0, fmt.Errorf("there is no space to move partition %s by %d bytes", a.Partition, start-a.Start)
+	}
+	return start, nil
+}
+
+// blockDevice is an opened drive which data is moved during partition realignment
+type blockDevice interface {
+	io.ReaderAt
+	io.WriterAt
+	Sync() error
+	Close() error
+}
+
+// WrapAlignment is the interface which encapsulates methods to audit and fix alignment of partitions
+type WrapAlignment interface {
+	GetPartitionAlignment(partition string) (*PartitionAlignment, error)
+	RealignPartition(a *PartitionAlignment, newStart int64) error
+}
+
+// WrapAlignmentImpl is the basic implementation of WrapAlignment interface which reads sysfs
+// and moves data of partition through its drive
+type WrapAlignmentImpl struct {
+	e             command.CmdExecutor
+	sysClassBlock string
+	openDevice    func(path string) (blockDevice, error)
+	chunkSize     int64
+}
+
+// NewWrapAlignmentImpl is a constructor for WrapAlignmentImpl instance
+func NewWrapAlignmentImpl(e command.CmdExecutor) *WrapAlignmentImpl {
+	return &WrapAlignmentImpl{
+		e:             e,
+		sysClassBlock: SysClassBlock,
+		openDevice: func(path string) (blockDevice, error) {
+			return os.OpenFile(filepath.Clean(path), os.O_RDWR, 0)
+		},
+		chunkSize: realignChunkSize,
+	}
+}
+
+// GetPartitionAlignment reads placement of partition and I/O limits of its drive from sysfs
+// Receives partition path, e.g. /dev/sda1
+// Returns PartitionAlignment or error if partition isn't found in sysfs
+func (a *WrapAlignmentImpl) GetPartitionAlignment(partition string) (*PartitionAlignment, error) {
+	partDir, err := filepath.EvalSymlinks(filepath.Join(a.sysClassBlock, filepath.Base(partition)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find %s in sysfs: %w", partition, err)
+	}
+	// partition directory is located in directory of its drive
+	devDir := filepath.Dir(partDir)
+	res := &PartitionAlignment{
+		Device:    filepath.Join(filepath.Dir(partition), filepath.Base(devDir)),
+		Partition: partition,
+	}
+	if res.PartNum, err = readSysfsAttr(partDir, "partition"); err != nil {
+		return nil, fmt.Errorf("%s isn't partition: %w", partition, err)
+	}
+	values := []struct {
+		dir, attr string
+		value     *int64
+		scale     int64
+	}{
+		{partDir, "start", &res.Start, int64(units.Sector)},
+		{partDir, "size", &res.Size, int64(units.Sector)},
+		{devDir, "size", &res.DeviceSize, int64(units.Sector)},
+		{devDir, "queue/logical_block_size", &res.LogicalBlockSize, 1},
+		{devDir, "queue/physical_block_size", &res.PhysicalBlockSize, 1},
+		{devDir, "queue/optimal_io_size", &res.OptimalIOSize, 1},
+	}
+	for _, v := range values {
+		raw, err := readSysfsAttr(v.dir, v.attr)
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s of %s: %w", v.attr, v.dir, err)
+		}
+		if *v.value, err = units.Mul(n, v.scale); err != nil {
+			return nil, fmt.Errorf("unable to convert %s of %s to bytes: %w", v.attr, v.dir, err)
+		}
+	}
+	return res, nil
+}
+
+// RealignPartition moves data of partition to newStart and re-creates the partition there with the same type,
+// GUID and label. Partition must be the only partition of the drive and must not be used
+// Returns error wrapping ErrRealignNotStarted if data wasn't touched
+func (a *WrapAlignmentImpl) RealignPartition(p *PartitionAlignment, newStart int64) error {
+	if !units.IsAligned(newStart, p.LogicalBlockSize) || !units.IsAligned(p.Size, p.LogicalBlockSize) {
+		return fmt.Errorf("%w: start %d isn't aligned to logical block of %s", ErrRealignNotStarted, newStart, p.Device)
+	}
+
+	if err := checkOnlyPartition(a.e, p.Device, p.PartNum); err != nil {
+		return fmt.Errorf("%w: %v", ErrRealignNotStarted, err)
+	}
+	entry, err := readPartitionEntry(a.e, p.Device, p.PartNum)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRealignNotStarted, err)
+	}
+	recreate := entry.recreateCmd(p.Device, newStart/p.LogicalBlockSize, (newStart+p.Size)/p.LogicalBlockSize-1)
+	// command is validated before data is moved, so label which can't be passed to sgdisk doesn't break partition
+	if err = recreate.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrRealignNotStarted, err)
+	}
+
+	if err = a.moveData(p.Device, p.Start, newStart, p.Size); err != nil {
+		return err
+	}
+
+	if err = runRecreate(a.e, recreate, p.Device, p.PartNum); err != nil {
+		return err
+	}
+
+	cmd := command.NewCmd(BlockdevCmdTmpl, command.Device(p.Device))
+	if _, _, err = a.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(BlockdevCmdTmpl, "")))); err != nil {
+		return fmt.Errorf("unable to sync partition table of %s: %v", p.Device, err)
+	}
+	return nil
+}
+
+// moveData copies length bytes of device from src to dst offset. Ranges might overlap, so data is copied
+// from the beginning when it is moved back and from the end when it is moved forward
+func (a *WrapAlignmentImpl) moveData(device string, src, dst, length int64) (err error) {
+	dev, err := a.openDevice(device)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %w", device, err)
+	}
+	defer func() {
+		if closeErr := dev.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("unable to close %s: %w", device, closeErr)
+		}
+	}()
+
+	buf := make([]byte, a.chunkSize)
+	for done := int64(0); done < length; {
+		n := a.chunkSize
+		if length-done < n {
+			n = length - done
+		}
+		offset := done
+		if dst > src {
+			offset = length - done - n
+		}
+		// the whole chunk is read before it is written, so overlapping of its source and destination is safe
+		if _, err = dev.ReadAt(buf[:n], src+offset); err != nil {
+			return fmt.Errorf("unable to read %d bytes of %s at %d: %w", n, device, src+offset, err)
+		}
+		if _, err = dev.WriteAt(buf[:n], dst+offset); err != nil {
+			return fmt.Errorf("unable to write %d bytes of %s at %d: %w", n, device, dst+offset, err)
+		}
+		done += n
+	}
+	if err = dev.Sync(); err != nil {
+		return fmt.Errorf("unable to sync %s: %w", device, err)
+	}
+	return nil
+}
+
+// partitionEntry is GPT entry of partition which is kept when partition is re-created
+type partitionEntry struct {
+	partNum  string
+	typeGUID string
+	partGUID string
+	label    string
+	// first is a first logical block of partition
+	first int64
+}
+
+// checkOnlyPartition returns error if partition isn't the only partition of device
+func checkOnlyPartition(e command.CmdExecutor, device, partNum string) error {
+	table, err := ReadPartitionTable(e, device)
+	if err != nil {
+		return fmt.Errorf("unable to read partitions of %s: %v", device, err)
+	}
+	if len(table.Partitions) != 1 || table.Partitions[0].Number != partNum {
+		return fmt.Errorf("partition %s must be the only partition of %s", partNum, device)
+	}
+	return nil
+}
+
+// readPartitionEntry reads type, GUID, label and first block of partition with sgdisk
+func readPartitionEntry(e command.CmdExecutor, device, partNum string) (*partitionEntry, error) {
+	cmd := command.NewCmd(GetPartitionInfoCmdTmpl, command.Device(device), command.Name(partNum))
+	stdout, _, err := e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(GetPartitionInfoCmdTmpl, "", ""))))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read partition %s of %s: %v", partNum, device, err)
+	}
+	typeGUID, okType := parseSgdiskValue(stdout, "Partition GUID code")
+	partGUID, okGUID := parseSgdiskValue(stdout, "Partition unique GUID")
+	if !okType || !okGUID {
+		return nil, fmt.Errorf("unable to parse GUIDs of partition %s of %s", partNum, device)
+	}
+	label, _ := parseSgdiskValue(stdout, "Partition name")
+	entry := &partitionEntry{
+		partNum: partNum,
+		// GUID code is followed by name of type, e.g. "0FC63DAF-8483-4772-8E79-3D69D8477DE4 (Linux filesystem)"
+		typeGUID: strings.Fields(typeGUID)[0],
+		partGUID: partGUID,
+		label:    strings.Trim(label, "'"),
+		first:    -1,
+	}
+	// "First sector: 2048 (at 1024.0 KiB)"
+	if first, ok := parseSgdiskValue(stdout, "First sector"); ok {
+		if n, err := strconv.ParseInt(strings.Fields(first)[0], 10, 64); err == nil {
+			entry.first = n
+		}
+	}
+	return entry, nil
+}
+
+// recreateCmd returns sgdisk command which re-creates partition from first to last logical block
+// keeping its type, GUID and label, last 0 means the end of free space after first block
+func (p *partitionEntry) recreateCmd(device string, first, last int64) command.Cmd {
+	num := command.Name(p.partNum)
+	if p.label == "" {
+		return command.NewCmd(RecreatePartitionNoLabelCmdTmpl, num, num, first, last, num, command.Name(p.typeGUID),
+			num, command.Name(p.partGUID), command.Device(device))
+	}
+	return command.NewCmd(RecreatePartitionCmdTmpl, num, num, first, last, num, command.Name(p.typeGUID), num,
+		command.Name(p.partGUID), num, command.Name(p.label), command.Device(device))
+}
+
+// runRecreate runs command created by recreateCmd
+func runRecreate(e command.CmdExecutor, recreate command.Cmd, device, partNum string) error {
+	if _, stderr, err := e.RunCmd(recreate,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(sgdisk+"-d -n -t -u -c"))); err != nil {
+		return fmt.Errorf("unable to re-create partition %s of %s: %s, error: %v", partNum, device, stderr, err)
+	}
+	return nil
+}
+
+// readSysfsAttr returns trimmed content of sysfs attribute
+func readSysfsAttr(dir, attr string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(filepath.Join(dir, attr)))
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s of %s: %w", attr, dir, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// gcd returns the greatest common divisor of positive numbers
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,184 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionhelper
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+const (
+	testMiB = 1024 * 1024
+	// partedOnePartition and partedTwoPartitions are parted --machine outputs of /dev/sda
+	partedOnePartition  = "BYT;\n/dev/sda:2097152B:scsi:512:4096:gpt:Mock:;\n1:1048576B:2097151B:1048576B:::;\n"
+	partedTwoPartitions = partedOnePartition + "2:2097152B:3145727B:1048576B:::;\n"
+)
+
+func TestPartitionAlignment_Impact(t *testing.T) {
+	a := &PartitionAlignment{Start: 2048 * 512, PhysicalBlockSize: 4096, LogicalBlockSize: 512}
+	assert.Equal(t, AlignmentImpactNone, a.Impact())
+
+	a.Start = 63 * 512
+	assert.Equal(t, AlignmentImpactSevere, a.Impact())
+
+	a.Start = 8 * 4096
+	assert.Equal(t, AlignmentImpactMinor, a.Impact())
+
+	// optimal I/O size of RAID with 3 stripes of 256 KiB
+	a.OptimalIOSize = 768 * 1024
+	a.Start = testMiB
+	assert.Equal(t, AlignmentImpactModerate, a.Impact())
+	assert.Equal(t, int64(3*testMiB), a.Boundary())
+	assert.Equal(t, int64(testMiB), a.Misalignment())
+}
+
+func TestPartitionAlignment_PlanRealign(t *testing.T) {
+	a := &PartitionAlignment{Partition: "/dev/sda1", Start: testMiB, Size: 10 * testMiB, DeviceSize: 100 * testMiB,
+		LogicalBlockSize: 512, PhysicalBlockSize: 4096}
+	_, err := a.PlanRealign()
+	assert.NotNil(t, err)
+
+	// moved back
+	a.Start = 5*testMiB + 512
+	start, err := a.PlanRealign()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5*testMiB), start)
+
+	// moved forward because primary GPT is at the beginning of the drive
+	a.Start = 63 * 512
+	start, err = a.PlanRealign()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(testMiB), start)
+
+	// no space before backup GPT
+	a.Size = a.DeviceSize - a.Start - gptEntriesSize - a.LogicalBlockSize
+	_, err = a.PlanRealign()
+	assert.NotNil(t, err)
+}
+
+func TestWrapAlignmentImpl_GetPartitionAlignment(t *testing.T) {
+	var (
+		root     = t.TempDir()
+		devDir   = filepath.Join(root, "devices", "sda")
+		partDir  = filepath.Join(devDir, "sda1")
+		classDir = filepath.Join(root, "class")
+		write    = func(dir, attr, value string) {
+			assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, attr)), 0700))
+			assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, attr), []byte(value+"\n"), 0600))
+		}
+		aligner = NewWrapAlignmentImpl(mocks.NewMockExecutor(map[string]mocks.CmdOut{}))
+	)
+	aligner.sysClassBlock = classDir
+	write(partDir, "partition", "1")
+	write(partDir, "start", "63")
+	write(partDir, "size", "2048")
+	write(devDir, "size", "4096")
+	write(devDir, "queue/logical_block_size", "512")
+	write(devDir, "queue/physical_block_size", "4096")
+	write(devDir, "queue/optimal_io_size", "0")
+	assert.Nil(t, os.MkdirAll(classDir, 0700))
+	assert.Nil(t, os.Symlink(partDir, filepath.Join(classDir, "sda1")))
+
+	a, err := aligner.GetPartitionAlignment("/dev/sda1")
+	assert.Nil(t, err)
+	assert.Equal(t, &PartitionAlignment{Device: "/dev/sda", Partition: "/dev/sda1", PartNum: "1", Start: 63 * 512,
+		Size: testMiB, DeviceSize: 2 * testMiB, LogicalBlockSize: 512, PhysicalBlockSize: 4096}, a)
+	assert.Equal(t, AlignmentImpactSevere, a.Impact())
+
+	_, err = aligner.GetPartitionAlignment("/dev/sdb1")
+	assert.NotNil(t, err)
+}
+
+func TestWrapAlignmentImpl_RealignPartition(t *testing.T) {
+	var (
+		device = "/dev/sda"
+		image  = filepath.Join(t.TempDir(), "sda")
+		data   = make([]byte, 64*1024)
+		part   = &PartitionAlignment{Device: device, Partition: "/dev/sda1", PartNum: "1", Start: 4096 + 512,
+			Size: int64(len(data)), LogicalBlockSize: 512}
+		info = "Partition GUID code: 0FC63DAF-8483-4772-8E79-3D69D8477DE4 (Linux filesystem)\n" +
+			"Partition unique GUID: 5209CFD8-3AB1-4720-BCEA-DFA80315EC92\n" +
+			"Partition name: 'CSI'"
+		cmds = map[string]mocks.CmdOut{
+			"parted --script --machine /dev/sda unit B print": {Stdout: partedOnePartition},
+			"sgdisk /dev/sda --info=1":                        {Stdout: info},
+			"blockdev --rereadpt -v /dev/sda":                 mocks.EmptyOutSuccess,
+		}
+		recreate = func(first, last string) string {
+			return "sgdisk -d 1 -n 1:" + first + ":" + last + " -t 1:0FC63DAF-8483-4772-8E79-3D69D8477DE4 " +
+				"-u 1:5209CFD8-3AB1-4720-BCEA-DFA80315EC92 -c 1:CSI /dev/sda"
+		}
+		aligner = NewWrapAlignmentImpl(mocks.NewMockExecutor(cmds))
+		opened  []string
+	)
+	aligner.chunkSize = 1000
+	// period of data doesn't divide offsets, so data which is copied to wrong place is detected
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	aligner.openDevice = func(path string) (blockDevice, error) {
+		opened = append(opened, path)
+		return os.OpenFile(image, os.O_RDWR, 0)
+	}
+	writeImage := func() {
+		content := make([]byte, 2*len(data)+16384)
+		copy(content[part.Start:], data)
+		assert.Nil(t, ioutil.WriteFile(image, content, 0600))
+	}
+	readPartition := func(start int64) []byte {
+		content, err := ioutil.ReadFile(image)
+		assert.Nil(t, err)
+		return content[start : start+part.Size]
+	}
+
+	// move back
+	writeImage()
+	cmds[recreate("8", "135")] = mocks.EmptyOutSuccess
+	assert.Nil(t, aligner.RealignPartition(part, 4096))
+	assert.Equal(t, data, readPartition(4096))
+	assert.Equal(t, []string{device}, opened)
+
+	// move forward
+	writeImage()
+	cmds[recreate("16", "143")] = mocks.EmptyOutSuccess
+	assert.Nil(t, aligner.RealignPartition(part, 8192))
+	assert.Equal(t, data, readPartition(8192))
+
+	// sgdisk fails after data is moved
+	writeImage()
+	err := aligner.RealignPartition(part, 12288)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrRealignNotStarted))
+
+	// drive has several partitions
+	cmds["parted --script --machine /dev/sda unit B print"] = mocks.CmdOut{Stdout: partedTwoPartitions}
+	opened = nil
+	err = aligner.RealignPartition(part, 4096)
+	assert.True(t, errors.Is(err, ErrRealignNotStarted))
+	assert.Empty(t, opened)
+
+	// start isn't aligned to logical block
+	err = aligner.RealignPartition(part, 4097)
+	assert.True(t, errors.Is(err, ErrRealignNotStarted))
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionhelper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+)
+
+const (
+	// MoveBackupGPTCmdTmpl moves backup GPT to the end of drive which became larger, fill device
+	MoveBackupGPTCmdTmpl = sgdisk + "--move-second-header %s"
+	// UpdatePartitionCmdTmpl informs kernel about new size of partition which might be in use,
+	// fill part number and device
+	UpdatePartitionCmdTmpl = "partx --update --nr %s %s"
+)
+
+// WrapGrow is the interface which encapsulates methods to use space added to the end of drive,
+// e.g. after expansion of RAID virtual disk
+type WrapGrow interface {
+	GrowPartition(device, partNum string) error
+}
+
+// WrapGrowImpl is the basic implementation of WrapGrow interface which uses sgdisk and partx
+type WrapGrowImpl struct {
+	e command.CmdExecutor
+}
+
+// NewWrapGrowImpl is a constructor for WrapGrowImpl instance
+func NewWrapGrowImpl(e command.CmdExecutor) *WrapGrowImpl {
+	return &WrapGrowImpl{e: e}
+}
+
+// GrowPartition moves backup GPT to the end of drive and extends partition up to it keeping its start, type,
+// GUID and label. Partition must be the only partition of the drive, its data isn't moved, so it might be in use.
+// Filesystem on partition isn't resized
+// Returns error if something went wrong
+func (g *WrapGrowImpl) GrowPartition(device, partNum string) error {
+	if err := checkOnlyPartition(g.e, device, partNum); err != nil {
+		return err
+	}
+	entry, err := readPartitionEntry(g.e, device, partNum)
+	if err != nil {
+		return err
+	}
+	if entry.first < 0 {
+		return fmt.Errorf("unable to parse first sector of partition %s of %s", partNum, device)
+	}
+	recreate := entry.recreateCmd(device, entry.first, 0)
+	if err = recreate.Err(); err != nil {
+		return err
+	}
+
+	cmd := command.NewCmd(MoveBackupGPTCmdTmpl, command.Device(device))
+	if _, stderr, err := g.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(MoveBackupGPTCmdTmpl, "")))); err != nil {
+		return fmt.Errorf("unable to move backup GPT of %s: %s, error: %v", device, stderr, err)
+	}
+	if err = runRecreate(g.e, recreate, device, partNum); err != nil {
+		return err
+	}
+	// kernel doesn't re-read partition table of drive which partition is in use
+	cmd = command.NewCmd(UpdatePartitionCmdTmpl, command.Name(partNum), command.Device(device))
+	if _, stderr, err := g.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(UpdatePartitionCmdTmpl, "", "")))); err != nil {
+		return fmt.Errorf("unable to update size of partition %s of %s: %s, error: %v", partNum, device, stderr, err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestWrapGrowImpl_GrowPartition(t *testing.T) {
+	var (
+		info = "Partition GUID code: 0FC63DAF-8483-4772-8E79-3D69D8477DE4 (Linux filesystem)\n" +
+			"Partition unique GUID: 5209CFD8-3AB1-4720-BCEA-DFA80315EC92\n" +
+			"First sector: 2048 (at 1024.0 KiB)\n" +
+			"Last sector: 2099199 (at 1.0 GiB)\n" +
+			"Partition name: 'CSI'"
+		recreate = "sgdisk -d 1 -n 1:2048:0 -t 1:0FC63DAF-8483-4772-8E79-3D69D8477DE4 " +
+			"-u 1:5209CFD8-3AB1-4720-BCEA-DFA80315EC92 -c 1:CSI /dev/sda"
+		cmds = map[string]mocks.CmdOut{
+			"parted --script --machine /dev/sda unit B print": {Stdout: partedOnePartition},
+			"sgdisk /dev/sda --info=1":                        {Stdout: info},
+			"sgdisk --move-second-header /dev/sda":            mocks.EmptyOutSuccess,
+			recreate:                                          mocks.EmptyOutSuccess,
+			"partx --update --nr 1 /dev/sda":                  mocks.EmptyOutSuccess,
+		}
+		grow = NewWrapGrowImpl(mocks.NewMockExecutor(cmds))
+	)
+	assert.Nil(t, grow.GrowPartition("/dev/sda", "1"))
+
+	// kernel isn't informed about new size
+	delete(cmds, "partx --update --nr 1 /dev/sda")
+	assert.NotNil(t, grow.GrowPartition("/dev/sda", "1"))
+
+	// first sector is unknown
+	cmds["sgdisk /dev/sda --info=1"] = mocks.CmdOut{Stdout: "Partition GUID code: 0FC63DAF-8483-4772-8E79-3D69D8477DE4\n" +
+		"Partition unique GUID: 5209CFD8-3AB1-4720-BCEA-DFA80315EC92"}
+	assert.NotNil(t, grow.GrowPartition("/dev/sda", "1"))
+
+	// drive has several partitions
+	cmds["parted --script --machine /dev/sda unit B print"] = mocks.CmdOut{Stdout: partedTwoPartitions}
+	assert.NotNil(t, grow.GrowPartition("/dev/sda", "1"))
+}
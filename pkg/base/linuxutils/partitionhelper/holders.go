@@ -0,0 +1,167 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionhelper
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
+)
+
+// Types of holders of block device
+const (
+	// HolderTypeDM is a device mapper device (LVM LV, dm-crypt, dm-cache)
+	HolderTypeDM = "dm"
+	// HolderTypeBcache is a bcache device
+	HolderTypeBcache = "bcache"
+	// HolderTypeMD is a software RAID array
+	HolderTypeMD = "md"
+	// HolderTypeMount is a mount point of block device
+	HolderTypeMount = "mount"
+	// HolderTypeUnknown is a holder which can't be removed automatically
+	HolderTypeUnknown = "unknown"
+
+	// DMSetupRemoveCmdTmpl removes device mapper device, fill device mapper name
+	DMSetupRemoveCmdTmpl = "dmsetup remove %s"
+	// MDAdmStopCmdTmpl stops software RAID array, fill device path
+	MDAdmStopCmdTmpl = "mdadm --stop %s"
+	// UmountCmdTmpl unmounts mount point, fill mount point
+	UmountCmdTmpl = "umount %s"
+)
+
+// Holder is a device or mount point which uses block device, block device can't be removed until holder exists
+type Holder struct {
+	Type string
+	// Name is a kernel name of holder device or mount point
+	Name string
+	// Alias is a device mapper name of dm device
+	Alias string
+}
+
+// String returns human readable representation of holder
+func (h Holder) String() string {
+	if h.Alias != "" {
+		return fmt.Sprintf("%s %s (%s)", h.Type, h.Name, h.Alias)
+	}
+	return fmt.Sprintf("%s %s", h.Type, h.Name)
+}
+
+// WrapHolders is the interface which encapsulates methods to find and remove holders of block devices
+type WrapHolders interface {
+	GetHolders(device string) ([]Holder, error)
+	RemoveHolder(holder Holder) error
+}
+
+// WrapHoldersImpl is the basic implementation of WrapHolders interface which reads holders from device graph
+type WrapHoldersImpl struct {
+	e     command.CmdExecutor
+	sysfs *devicegraph.SysfsReader
+}
+
+// NewWrapHoldersImpl is a constructor for WrapHoldersImpl instance
+func NewWrapHoldersImpl(e command.CmdExecutor) *WrapHoldersImpl {
+	return &WrapHoldersImpl{e: e, sysfs: devicegraph.NewSysfsReader()}
+}
+
+// GetHolders returns holders chain of device such as /dev/sda1: mount points and devices which use device directly
+// or through other holders. Each holder goes before device which it holds, so the chain can be removed in order
+func (h *WrapHoldersImpl) GetHolders(device string) ([]Holder, error) {
+	graph, err := h.sysfs.Read()
+	if err != nil {
+		return nil, err
+	}
+	dev := graph.Get(device)
+	if dev == nil {
+		return nil, fmt.Errorf("unable to find device %s in sysfs", device)
+	}
+	var holders []Holder
+	// mount points of device go right before device itself
+	for _, upper := range graph.Above(device) {
+		holders = append(holders, mountHolders(upper)...)
+		holders = append(holders, newHolder(upper))
+	}
+	return append(holders, mountHolders(dev)...), nil
+}
+
+// RemoveHolder unmounts mount point, removes device mapper device, stops bcache device or software RAID array
+// Returns error if holder can't be removed
+func (h *WrapHoldersImpl) RemoveHolder(holder Holder) error {
+	var cmd command.Cmd
+	switch holder.Type {
+	case HolderTypeMount:
+		cmd = command.NewCmd(UmountCmdTmpl, command.Device(holder.Name))
+	case HolderTypeDM:
+		name := holder.Alias
+		if name == "" {
+			name = holder.Name
+		}
+		cmd = command.NewCmd(DMSetupRemoveCmdTmpl, command.Name(name))
+	case HolderTypeMD:
+		cmd = command.NewCmd(MDAdmStopCmdTmpl, command.Device("/dev/"+holder.Name))
+	case HolderTypeBcache:
+		// bcache device is stopped through sysfs
+		stop := filepath.Join(h.sysfs.SysClassBlock, holder.Name, "bcache", "stop")
+		if err := ioutil.WriteFile(stop, []byte("1"), 0200); err != nil {
+			return fmt.Errorf("unable to stop %s: %w", holder, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s can't be removed automatically", holder)
+	}
+	if _, stderr, err := h.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.Fields(cmd.String())[0])); err != nil {
+		return fmt.Errorf("unable to remove %s: %s, error: %v", holder, stderr, err)
+	}
+	return nil
+}
+
+// FormatHolders returns holders chain as a string
+func FormatHolders(holders []Holder) string {
+	res := make([]string, 0, len(holders))
+	for _, holder := range holders {
+		res = append(res, holder.String())
+	}
+	return strings.Join(res, " <- ")
+}
+
+// newHolder returns holder with type detected by type of device
+func newHolder(dev *devicegraph.Device) Holder {
+	holder := Holder{Name: dev.Name, Alias: dev.Alias, Type: HolderTypeUnknown}
+	switch dev.Type {
+	case devicegraph.TypeDM:
+		holder.Type = HolderTypeDM
+	case devicegraph.TypeBcache:
+		holder.Type = HolderTypeBcache
+	case devicegraph.TypeMD:
+		holder.Type = HolderTypeMD
+	}
+	return holder
+}
+
+// mountHolders returns mount points of device as holders
+func mountHolders(dev *devicegraph.Device) []Holder {
+	holders := make([]Holder, 0, len(dev.MountPoints))
+	for _, mountPoint := range dev.MountPoints {
+		holders = append(holders, Holder{Type: HolderTypeMount, Name: mountPoint})
+	}
+	return holders
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionhelper
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+// prepareSysfs creates sysfs tree: sda1 <- dm-3 (vg-lv, mounted to /mnt/old), sdb1 is mounted to /mnt/sdb1
+func prepareSysfs(t *testing.T) *devicegraph.SysfsReader {
+	root := t.TempDir()
+	writeFile := func(path, content string) {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0700))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(root, path), []byte(content), 0600))
+	}
+	writeFile("sda1/dev", "8:1\n")
+	writeFile("sda1/holders/dm-3", "")
+	writeFile("dm-3/dev", "253:3\n")
+	writeFile("dm-3/dm/name", "vg-lv\n")
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "dm-3", "holders"), 0700))
+	writeFile("sdb1/dev", "8:17\n")
+	writeFile("bcache0/bcache/stop", "")
+	writeFile("mountinfo", "22 1 8:2 / / rw - ext4 /dev/sda2 rw\n"+
+		"30 22 253:3 / /mnt/old rw - xfs /dev/mapper/vg-lv rw\n"+
+		"31 22 8:17 / /mnt/sdb1 rw - xfs /dev/sdb1 rw\n")
+
+	return &devicegraph.SysfsReader{SysClassBlock: root, MountInfo: filepath.Join(root, "mountinfo")}
+}
+
+func TestWrapHoldersImpl_GetHolders(t *testing.T) {
+	h := NewWrapHoldersImpl(mocks.NewMockExecutor(map[string]mocks.CmdOut{}))
+	h.sysfs = prepareSysfs(t)
+
+	holders, err := h.GetHolders("/dev/sda1")
+	assert.Nil(t, err)
+	assert.Equal(t, []Holder{
+		{Type: HolderTypeMount, Name: "/mnt/old"},
+		{Type: HolderTypeDM, Name: "dm-3", Alias: "vg-lv"},
+	}, holders)
+	assert.Equal(t, "mount /mnt/old <- dm dm-3 (vg-lv)", FormatHolders(holders))
+
+	holders, err = h.GetHolders("/dev/sdb1")
+	assert.Nil(t, err)
+	assert.Equal(t, []Holder{{Type: HolderTypeMount, Name: "/mnt/sdb1"}}, holders)
+
+	_, err = h.GetHolders("/dev/sdc1")
+	assert.NotNil(t, err)
+}
+
+func TestWrapHoldersImpl_RemoveHolder(t *testing.T) {
+	h := NewWrapHoldersImpl(mocks.NewMockExecutor(map[string]mocks.CmdOut{
+		fmt.Sprintf(UmountCmdTmpl, "/mnt/old"):        mocks.EmptyOutSuccess,
+		fmt.Sprintf(DMSetupRemoveCmdTmpl, "vg-lv"):    mocks.EmptyOutSuccess,
+		fmt.Sprintf(MDAdmStopCmdTmpl, "/dev/md127"):   mocks.EmptyOutFail,
+		fmt.Sprintf(DMSetupRemoveCmdTmpl, "dm-4"):     mocks.EmptyOutFail,
+		fmt.Sprintf(UmountCmdTmpl, "/mnt/not-exists"): mocks.EmptyOutFail,
+	}))
+	h.sysfs = prepareSysfs(t)
+
+	assert.Nil(t, h.RemoveHolder(Holder{Type: HolderTypeMount, Name: "/mnt/old"}))
+	assert.Nil(t, h.RemoveHolder(Holder{Type: HolderTypeDM, Name: "dm-3", Alias: "vg-lv"}))
+	assert.Nil(t, h.RemoveHolder(Holder{Type: HolderTypeBcache, Name: "bcache0"}))
+	stop, err := ioutil.ReadFile(filepath.Join(h.sysfs.SysClassBlock, "bcache0", "bcache", "stop"))
+	assert.Nil(t, err)
+	assert.Equal(t, "1", string(stop))
+
+	assert.NotNil(t, h.RemoveHolder(Holder{Type: HolderTypeMD, Name: "md127"}))
+	assert.NotNil(t, h.RemoveHolder(Holder{Type: HolderTypeDM, Name: "dm-4"}))
+	assert.NotNil(t, h.RemoveHolder(Holder{Type: HolderTypeBcache, Name: "bcache1"}))
+	assert.NotNil(t, h.RemoveHolder(Holder{Type: HolderTypeUnknown, Name: "zram0"}))
+}
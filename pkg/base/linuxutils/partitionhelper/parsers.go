@@ -0,0 +1,195 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// partedUnknownLabel is a type of partition table which parted prints for device without partition table
+const partedUnknownLabel = "unknown"
+
+// partedJSON is an output of parted --json print command
+type partedJSON struct {
+	Disk *struct {
+		Path               string `json:"path"`
+		Size               string `json:"size"`
+		LogicalSectorSize  int64  `json:"logical-sector-size"`
+		PhysicalSectorSize int64  `json:"physical-sector-size"`
+		Label              string `json:"label"`
+		Partitions         []struct {
+			Number     int      `json:"number"`
+			Start      string   `json:"start"`
+			End        string   `json:"end"`
+			Size       string   `json:"size"`
+			Name       string   `json:"name"`
+			FileSystem string   `json:"filesystem"`
+			Flags      []string `json:"flags"`
+		} `json:"partitions"`
+	} `json:"disk"`
+}
+
+// parsePartedMachine parses output of parted --machine print command in bytes units, example of output:
+// BYT;
+// /dev/sdy:1000204886016B:scsi:512:4096:gpt:ATA ST1000DM003:;
+// 1:1048576B:511705087B:510656512B:xfs:primary:boot, esp;
+// the first line with device is used, lines in another format (warnings, truncated lines) are skipped.
+// Offsets of partition which can't be parsed are 0
+// Returns PartitionTable and flag whether output contains line with device
+func parsePartedMachine(output string) (*PartitionTable, bool) {
+	var table *PartitionTable
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		// each record is terminated by semicolon, line without it is truncated
+		if !strings.HasSuffix(line, ";") {
+			continue
+		}
+		fields := splitPartedFields(strings.TrimSuffix(line, ";"))
+		switch {
+		case table == nil && len(fields) >= 7 && strings.HasPrefix(fields[0], "/"):
+			table = &PartitionTable{
+				Device:             fields[0],
+				Type:               partedLabel(fields[5]),
+				Size:               parsePartedBytes(fields[1]),
+				LogicalSectorSize:  parsePartedBytes(fields[3]),
+				PhysicalSectorSize: parsePartedBytes(fields[4]),
+			}
+		case table != nil && len(fields) >= 7 && isPartNum(fields[0]):
+			table.Partitions = append(table.Partitions, Partition{
+				Number:     fields[0],
+				Start:      parsePartedBytes(fields[1]),
+				End:        parsePartedBytes(fields[2]),
+				Size:       parsePartedBytes(fields[3]),
+				FileSystem: fields[4],
+				Name:       fields[5],
+				Flags:      splitPartedFlags(fields[6]),
+			})
+		}
+	}
+	return table, table != nil
+}
+
+// parsePartedJSON parses output of parted --json print command in bytes units
+// Returns PartitionTable or error if output isn't valid JSON with disk object
+func parsePartedJSON(output string) (*PartitionTable, error) {
+	var res partedJSON
+	if err := json.Unmarshal([]byte(output), &res); err != nil {
+		return nil, err
+	}
+	if res.Disk == nil || res.Disk.Path == "" {
+		return nil, fmt.Errorf("disk isn't found in output")
+	}
+	table := &PartitionTable{
+		Device:             res.Disk.Path,
+		Type:               partedLabel(res.Disk.Label),
+		Size:               parsePartedBytes(res.Disk.Size),
+		LogicalSectorSize:  res.Disk.LogicalSectorSize,
+		PhysicalSectorSize: res.Disk.PhysicalSectorSize,
+	}
+	for _, part := range res.Disk.Partitions {
+		table.Partitions = append(table.Partitions, Partition{
+			Number:     strconv.Itoa(part.Number),
+			Start:      parsePartedBytes(part.Start),
+			End:        parsePartedBytes(part.End),
+			Size:       parsePartedBytes(part.Size),
+			FileSystem: part.FileSystem,
+			Name:       part.Name,
+			Flags:      part.Flags,
+		})
+	}
+	return table, nil
+}
+
+// splitPartedFields splits record of parted --machine output by colons,
+// colons and backslashes in values (e.g. in model or partition name) are escaped by backslash
+func splitPartedFields(record string) []string {
+	var (
+		fields  []string
+		field   strings.Builder
+		escaped bool
+	)
+	for _, r := range record {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteRune(r)
+		}
+	}
+	return append(fields, field.String())
+}
+
+// splitPartedFlags splits comma separated partition flags, e.g. "boot, esp"
+func splitPartedFlags(value string) []string {
+	var flags []string
+	for _, flag := range strings.Split(value, ",") {
+		if flag = strings.TrimSpace(flag); flag != "" {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// parsePartedBytes parses size in bytes units, e.g. "1048576B" or "512"
+// Returns 0 if value isn't a number of bytes
+func parsePartedBytes(value string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), "B"), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// partedLabel returns type of partition table, empty string if device has no partition table
+func partedLabel(label string) string {
+	if label == partedUnknownLabel {
+		return ""
+	}
+	return label
+}
+
+// isPartNum checks that value is a positive partition number
+func isPartNum(value string) bool {
+	n, err := strconv.Atoi(value)
+	return err == nil && n > 0
+}
+
+// parseSgdiskValue parses output of sgdisk --info command and returns value for key,
+// for example "5209CFD8-3AB1-4720-BCEA-DFA80315EC92" for key "Partition unique GUID"
+// Returns value and flag whether key with non empty value was found
+func parseSgdiskValue(output, key string) (string, bool) {
+	prefix := key + ":"
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		if value := strings.TrimSpace(line[len(prefix):]); value != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fuzzinput"
+)
+
+const testSgdiskInfo = `Partition GUID code: 0FC63DAF-8483-4772-8E79-3D69D8477DE4 (Linux filesystem)
+Partition unique GUID: 5209CFD8-3AB1-4720-BCEA-DFA80315EC92
+First sector: 2048 (at 1024.0 KiB)
+Partition name: ''`
+
+const testPartedMachine = `BYT;
+/dev/sdy:1000204886016B:scsi:512:4096:gpt:ATA ST1000DM003:;
+1:1048576B:511705087B:510656512B:xfs:primary:boot, esp;
+2:511705088B:1000204869119B:999693164032B::name\:with\\colon:;
+`
+
+func TestParsePartedMachine(t *testing.T) {
+	table, ok := parsePartedMachine(testPartedMachine)
+	assert.True(t, ok)
+	assert.Equal(t, "/dev/sdy", table.Device)
+	assert.Equal(t, PartitionGPT, table.Type)
+	assert.Equal(t, int64(4096), table.PhysicalSectorSize)
+	assert.Equal(t, []string{"1", "2"}, table.PartitionNumbers())
+	assert.Equal(t, Partition{Number: "1", Start: 1048576, End: 511705087, Size: 510656512, FileSystem: "xfs",
+		Name: "primary", Flags: []string{"boot", "esp"}}, table.Partitions[0])
+	assert.Equal(t, `name:with\colon`, table.Partitions[1].Name)
+	assert.Empty(t, table.Partitions[1].Flags)
+
+	// device without partition table
+	table, ok = parsePartedMachine("BYT;\n/dev/sdy:1000204886016B:scsi:512:512:unknown:Mock:;\n")
+	assert.True(t, ok)
+	assert.Equal(t, "", table.Type)
+	assert.False(t, table.HasPartitionTable())
+
+	// warnings and truncated lines are skipped
+	table, ok = parsePartedMachine("Warning: Unable to open /dev/sr0 read-write (Read-only file system).\n" +
+		testPartedMachine[:len(testPartedMachine)-10])
+	assert.True(t, ok)
+	assert.Equal(t, []string{"1"}, table.PartitionNumbers())
+
+	for _, output := range []string{"", "BYT;", "Error: /dev/sdy: unrecognised disk label",
+		"1:1048576B:511705087B:510656512B:xfs:primary:;"} {
+		_, ok = parsePartedMachine(output)
+		assert.False(t, ok, output)
+	}
+}
+
+func TestParsePartedJSON(t *testing.T) {
+	table, err := parsePartedJSON(`{"disk": {"path": "/dev/sdy", "size": "1000204886016B", "label": "msdos",
+		"logical-sector-size": 512, "physical-sector-size": 512,
+		"partitions": [{"number": 5, "start": "1048576B", "end": "2097151B", "size": "1048576B", "type": "logical"}]}}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "msdos", table.Type)
+	assert.Equal(t, []Partition{{Number: "5", Start: 1048576, End: 2097151, Size: 1048576}}, table.Partitions)
+
+	table, err = parsePartedJSON(`{"disk": {"path": "/dev/sdy", "label": "unknown"}}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "", table.Type)
+
+	for _, output := range []string{"", "{}", `{"disk": {"path": "/dev/sdy"`, "Error: unrecognised disk label"} {
+		_, err = parsePartedJSON(output)
+		assert.NotNil(t, err, output)
+	}
+}
+
+func TestParseSgdiskValue(t *testing.T) {
+	value, ok := parseSgdiskValue(testSgdiskInfo, "Partition unique GUID")
+	assert.True(t, ok)
+	assert.Equal(t, "5209CFD8-3AB1-4720-BCEA-DFA80315EC92", value)
+
+	_, ok = parseSgdiskValue("Partition unique GUID:", "Partition unique GUID")
+	assert.False(t, ok)
+	_, ok = parseSgdiskValue("Problem opening /dev/sdy for reading! Error is 2.", "Partition unique GUID")
+	assert.False(t, ok)
+}
+
+func TestParsers_Fuzz(t *testing.T) {
+	for _, seed := range []string{testPartedMachine, testSgdiskInfo} {
+		for _, input := range fuzzinput.Variants(seed, 200) {
+			assert.NotPanics(t, func() {
+				_, _ = parsePartedMachine(input)
+				_, _ = parsePartedJSON(input)
+				_, _ = parseSgdiskValue(input, "Partition unique GUID")
+			}, "input: %q", input)
+		}
+	}
+}
@@ -15,10 +15,11 @@ limitations under the License.
 */
 
 // Package partitionhelper contains code for manipulating with block device partitions and
-// run such system utilites as parted, partprobe, sgdisk
+// run such system utilites as parted, sgdisk, blockdev
 package partitionhelper
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -26,6 +27,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicepath"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 )
@@ -38,6 +40,9 @@ type WrapPartition interface {
 	CreatePartition(device, label, partUUID string, setUUID bool) (err error)
 	DeletePartition(device, partNum string) (err error)
 	GetPartitionUUID(device, partNum string) (string, error)
+	SetPartitionUUID(device, partNum, partUUID string) error
+	GetPartitionTypes(device string) (map[string]string, error)
+	SetPartitionType(device, partNum, typeGUID string) error
 	SyncPartitionTable(device string) error
 	GetPartitionNameByUUID(device, partUUID string) (string, error)
 	DeviceHasPartitionTable(device string) (bool, error)
@@ -47,17 +52,19 @@ type WrapPartition interface {
 const (
 	// PartitionGPT is the const for GPT partition table
 	PartitionGPT = "gpt"
-	// partprobe is a name of system util
-	partprobe = "partprobe "
+	// PartitionLoop is a type of partition table which parted reports for filesystem on the whole device
+	PartitionLoop = "loop"
+	// parted is a name of system util
+	parted = "parted "
 	// sgdisk is a name of system util
 	sgdisk = "sgdisk "
-	// fdiks is a name of system util
-	fdisk = "fdisk "
 	// blockdev is a name of system util
 	blockdev = "blockdev "
 
-	// PartprobeDeviceCmdTmpl check that device has partition cmd
-	PartprobeDeviceCmdTmpl = partprobe + "-d -s %s"
+	// PartedPrintCmdTmpl prints partition table of device in machine readable format, fill device
+	PartedPrintCmdTmpl = parted + "--script --machine %s unit B print"
+	// PartedPrintJSONCmdTmpl prints partition table of device in JSON format, parted 3.5+, fill device
+	PartedPrintJSONCmdTmpl = parted + "--script --json %s unit B print"
 	// BlockdevCmdTmpl synchronize the partition table
 	BlockdevCmdTmpl = blockdev + "--rereadpt -v %s"
 
@@ -71,16 +78,104 @@ const (
 	// DeletePartitionCmdTmpl delete partition from provided device cmd template, fill device and partition number
 	DeletePartitionCmdTmpl = sgdisk + "-d %s %s"
 
-	// DetectPartitionTableCmdTmpl is used to print information, which contain partition table
-	DetectPartitionTableCmdTmpl = fdisk + "--list %s"
-
 	// GetPartitionUUIDCmdTmpl command for read GUID of the first partition, fill device and part number
 	GetPartitionUUIDCmdTmpl = sgdisk + "%s --info=%s"
+	// SetPartitionUUIDCmdTmpl command for change GUID of partition, fill part number, GUID and device
+	SetPartitionUUIDCmdTmpl = sgdisk + "-u %s:%s %s"
+	// SetPartitionTypeCmdTmpl command for change type GUID of partition, fill part number, type GUID and device
+	SetPartitionTypeCmdTmpl = sgdisk + "-t %s:%s %s"
+
+	// LinuxFilesystemTypeGUID is a type GUID of "Linux filesystem" partitions which sgdisk creates by default
+	LinuxFilesystemTypeGUID = "0fc63daf-8483-4772-8e79-3d69d8477de4"
 )
 
 // supportedTypes list of supported partition table types
 var supportedTypes = []string{PartitionGPT}
 
+// errUnparsedOutput is returned when parted output doesn't contain device
+var errUnparsedOutput = errors.New("unable to parse output")
+
+// partedJSONSupported is set on start when detected parted version supports --json
+var partedJSONSupported bool
+
+// SetPartedJSONSupported defines whether partition tables should be read with --json or --machine output
+// Receives capability detected based on parted version
+func SetPartedJSONSupported(supported bool) {
+	partedJSONSupported = supported
+}
+
+// PartitionTable describes partition table of device read with parted, sizes and offsets are in bytes
+type PartitionTable struct {
+	// Device is a path of device printed by parted
+	Device string
+	// Type is a type of partition table, e.g. gpt or msdos, empty if device has no partition table
+	Type               string
+	Size               int64
+	LogicalSectorSize  int64
+	PhysicalSectorSize int64
+	Partitions         []Partition
+}
+
+// Partition describes partition in partition table, offsets are in bytes, End is the last byte of partition
+type Partition struct {
+	Number     string
+	Start      int64
+	End        int64
+	Size       int64
+	FileSystem string
+	// Name is a GPT partition name, it is empty for msdos partition table
+	Name  string
+	Flags []string
+}
+
+// HasPartitionTable returns true if device has partition table, filesystem on the whole device isn't one
+func (t *PartitionTable) HasPartitionTable() bool {
+	return t.Type != "" && t.Type != PartitionLoop
+}
+
+// PartitionNumbers returns numbers of partitions in order of partition table
+func (t *PartitionTable) PartitionNumbers() []string {
+	res := make([]string, 0, len(t.Partitions))
+	for _, part := range t.Partitions {
+		res = append(res, part.Number)
+	}
+	return res
+}
+
+// ReadPartitionTable reads partition table of device with parted, --json output is used if it is supported
+// Receives executor and device path
+// Returns PartitionTable with empty Type if device has no partition table or error if something went wrong
+func ReadPartitionTable(e command.CmdExecutor, device string) (*PartitionTable, error) {
+	tmpl := PartedPrintCmdTmpl
+	if partedJSONSupported {
+		tmpl = PartedPrintJSONCmdTmpl
+	}
+	cmd := command.NewCmd(tmpl, command.Device(device))
+	stdout, stderr, err := e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(tmpl, ""))))
+
+	var (
+		table    *PartitionTable
+		parseErr error
+	)
+	if partedJSONSupported {
+		table, parseErr = parsePartedJSON(stdout)
+	} else if parsed, ok := parsePartedMachine(stdout); ok {
+		table = parsed
+	} else {
+		parseErr = errors.New("device isn't found in output")
+	}
+	// parted prints device and fails if device has no partition table
+	if parseErr == nil && (err == nil || table.Type == "") {
+		return table, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read partition table of %s: %s, error: %v", device, stderr, err)
+	}
+	return nil, fmt.Errorf("%w '%s' for device %s: %v", errUnparsedOutput, stdout, device, parseErr)
+}
+
 // WrapPartitionImpl is the basic implementation of WrapPartition interface
 type WrapPartitionImpl struct {
 	e         command.CmdExecutor
@@ -97,35 +192,27 @@ func NewWrapPartitionImpl(e command.CmdExecutor, log *logrus.Logger) *WrapPartit
 }
 
 // IsPartitionExists checks if a partition exists in a provided device
+// Any partition of device is reported as existing, so partition table with other partitions isn't re-created
 // Receives path to a device to check a partition existence
 // Returns partition existence status or error if something went wrong
 func (p *WrapPartitionImpl) IsPartitionExists(device, partNum string) (bool, error) {
-	cmd := fmt.Sprintf(PartprobeDeviceCmdTmpl, device)
-	/*
-		example of output:
-		$ partprobe -d -s /dev/sdy
-		/dev/sdy: gpt partitions 1 2
-	*/
-
-	p.opMutex.Lock()
-	stdout, _, err := p.e.RunCmd(cmd,
-		command.UseMetrics(true),
-		command.CmdName(strings.TrimSpace(fmt.Sprintf(PartprobeDeviceCmdTmpl, ""))))
-	p.opMutex.Unlock()
-
-	if err != nil {
-		return false, fmt.Errorf("unable to check partition %#v existence for %s", partNum, device)
+	table, err := p.GetPartitionTable(device)
+	if errors.Is(err, errUnparsedOutput) {
+		return false, nil
 	}
-
-	stdout = strings.TrimSpace(stdout)
-
-	s := strings.Split(stdout, "partitions")
-	// after splitting partition number might appear on 2nd place in slice
-	if len(s) > 1 && s[1] != "" {
-		return true, nil
+	if err != nil {
+		return false, fmt.Errorf("unable to check partition %#v existence for %s: %v", partNum, device, err)
 	}
+	return len(table.Partitions) > 0, nil
+}
 
-	return false, nil
+// GetPartitionTable reads partition table of a provided device with parted
+// Receives device path
+// Returns PartitionTable with empty Type if device has no partition table or error if something went wrong
+func (p *WrapPartitionImpl) GetPartitionTable(device string) (*PartitionTable, error) {
+	p.opMutex.Lock()
+	defer p.opMutex.Unlock()
+	return ReadPartitionTable(p.e, device)
 }
 
 // CreatePartitionTable created partition table on a provided device
@@ -137,7 +224,7 @@ func (p *WrapPartitionImpl) CreatePartitionTable(device, partTableType string) e
 			device, partTableType)
 	}
 
-	cmd := fmt.Sprintf(CreatePartitionTableCmdTmpl, device)
+	cmd := command.NewCmd(CreatePartitionTableCmdTmpl, command.Device(device))
 	_, _, err := p.e.RunCmd(cmd,
 		command.UseMetrics(true),
 		command.CmdName(strings.TrimSpace(fmt.Sprintf(CreatePartitionTableCmdTmpl, ""))))
@@ -153,31 +240,24 @@ func (p *WrapPartitionImpl) CreatePartitionTable(device, partTableType string) e
 // Receives device path from which partition table type should be got
 // Returns partition table type as a string or error if something went wrong
 func (p *WrapPartitionImpl) GetPartitionTableType(device string) (string, error) {
-	cmd := fmt.Sprintf(PartprobeDeviceCmdTmpl, device)
-
-	stdout, _, err := p.e.RunCmd(cmd,
-		command.UseMetrics(true),
-		command.CmdName(strings.TrimSpace(fmt.Sprintf(PartprobeDeviceCmdTmpl, ""))))
-
+	table, err := p.GetPartitionTable(device)
+	if errors.Is(err, errUnparsedOutput) {
+		return "", err
+	}
 	if err != nil {
 		return "", fmt.Errorf("unable to get partition table for device %s", device)
 	}
-	// /dev/sda: msdos partitions 1
-	s := strings.Split(stdout, " ")
-	if len(s) < 2 {
-		return "", fmt.Errorf("unable to parse output '%s' for device %s", stdout, device)
-	}
-	// partition table type is on 2nd place in slice
-	return s[1], nil
+	return table.Type, nil
 }
 
 // CreatePartition creates partition with name partName on a device
 // Receives device path to create a partition
 // Returns error if something went wrong
 func (p *WrapPartitionImpl) CreatePartition(device, label, partUUID string, setUUID bool) error {
-	cmd := fmt.Sprintf(CreatePartitionCmdTmpl, label, device)
+	cmd := command.NewCmd(CreatePartitionCmdTmpl, command.Name(label), command.Device(device))
 	if setUUID {
-		cmd = fmt.Sprintf(CreatePartitionCmdWithUUIDTmpl, label, partUUID, device)
+		cmd = command.NewCmd(CreatePartitionCmdWithUUIDTmpl, command.Name(label), command.Name(partUUID),
+			command.Device(device))
 	}
 
 	p.opMutex.Lock()
@@ -197,7 +277,7 @@ func (p *WrapPartitionImpl) CreatePartition(device, label, partUUID string, setU
 // Receives device path and it's partition which should be deleted
 // Returns error if something went wrong
 func (p *WrapPartitionImpl) DeletePartition(device, partNum string) error {
-	cmd := fmt.Sprintf(DeletePartitionCmdTmpl, partNum, device)
+	cmd := command.NewCmd(DeletePartitionCmdTmpl, command.Name(partNum), command.Device(device))
 
 	p.opMutex.Lock()
 	_, stderr, err := p.e.RunCmd(cmd,
@@ -228,8 +308,8 @@ func (p *WrapPartitionImpl) GetPartitionUUID(device, partNum string) (string, er
 		Attribute flags: 0000000000000000
 		Partition name: ''
 	*/
-	cmd := fmt.Sprintf(GetPartitionUUIDCmdTmpl, device, partNum)
-	partitionPresentation := "Partition unique GUID:"
+	cmd := command.NewCmd(GetPartitionUUIDCmdTmpl, command.Device(device), command.Name(partNum))
+	partitionPresentation := "Partition unique GUID"
 
 	stdout, _, err := p.e.RunCmd(cmd,
 		command.UseMetrics(true),
@@ -239,23 +319,89 @@ func (p *WrapPartitionImpl) GetPartitionUUID(device, partNum string) (string, er
 		return "", err
 	}
 
-	for _, line := range strings.Split(stdout, "\n") {
-		if strings.Contains(line, partitionPresentation) {
-			res := strings.Split(strings.TrimSpace(line), partitionPresentation)
-			if len(res) > 1 {
-				return strings.ToLower(strings.TrimSpace(res[1])), nil
-			}
-		}
+	if uuid, ok := parseSgdiskValue(stdout, partitionPresentation); ok {
+		return strings.ToLower(uuid), nil
 	}
 
 	return "", fmt.Errorf("unable to get partition GUID for device %s", device)
 }
 
+// SetPartitionUUID changes unique GUID of the partition partNum of a provided device
+// Receives device path, partition number and new GUID
+// Returns error if something went wrong
+func (p *WrapPartitionImpl) SetPartitionUUID(device, partNum, partUUID string) error {
+	cmd := command.NewCmd(SetPartitionUUIDCmdTmpl, command.Name(partNum), command.Name(partUUID), command.Device(device))
+
+	p.opMutex.Lock()
+	_, stderr, err := p.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(SetPartitionUUIDCmdTmpl, "", "", ""))))
+	p.opMutex.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("unable to set UUID %s for partition %s on device %s: %s, error: %v",
+			partUUID, partNum, device, stderr, err)
+	}
+
+	return nil
+}
+
+// GetPartitionTypes reads type GUIDs of all partitions of a provided device
+// Receives device path
+// Returns map of partition number to lower case type GUID or error if something went wrong
+func (p *WrapPartitionImpl) GetPartitionTypes(device string) (map[string]string, error) {
+	table, err := p.GetPartitionTable(device)
+	if errors.Is(err, errUnparsedOutput) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to list partitions of device %s: %v", device, err)
+	}
+
+	types := make(map[string]string, len(table.Partitions))
+	for _, partNum := range table.PartitionNumbers() {
+		cmd := command.NewCmd(GetPartitionUUIDCmdTmpl, command.Device(device), command.Name(partNum))
+		stdout, _, err := p.e.RunCmd(cmd,
+			command.UseMetrics(true),
+			command.CmdName(strings.TrimSpace(fmt.Sprintf(GetPartitionUUIDCmdTmpl, "", ""))))
+		if err != nil {
+			return nil, err
+		}
+		// GUID code is followed by name of type, e.g. "0FC63DAF-8483-4772-8E79-3D69D8477DE4 (Linux filesystem)"
+		typeGUID, ok := parseSgdiskValue(stdout, "Partition GUID code")
+		if !ok {
+			return nil, fmt.Errorf("unable to get type GUID of partition %s for device %s", partNum, device)
+		}
+		types[partNum] = strings.ToLower(strings.Fields(typeGUID)[0])
+	}
+	return types, nil
+}
+
+// SetPartitionType changes type GUID of the partition partNum of a provided device
+// Receives device path, partition number and type GUID
+// Returns error if something went wrong
+func (p *WrapPartitionImpl) SetPartitionType(device, partNum, typeGUID string) error {
+	cmd := command.NewCmd(SetPartitionTypeCmdTmpl, command.Name(partNum), command.Name(typeGUID), command.Device(device))
+
+	p.opMutex.Lock()
+	_, stderr, err := p.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(SetPartitionTypeCmdTmpl, "", "", ""))))
+	p.opMutex.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("unable to set type GUID %s for partition %s on device %s: %s, error: %v",
+			typeGUID, partNum, device, stderr, err)
+	}
+
+	return nil
+}
+
 // SyncPartitionTable syncs partition table for specific device
 // Receives device path to sync with partprobe, device could be an empty string (sync for all devices in the system)
 // Returns error if something went wrong
 func (p *WrapPartitionImpl) SyncPartitionTable(device string) error {
-	cmd := fmt.Sprintf(BlockdevCmdTmpl, device)
+	cmd := command.NewCmd(BlockdevCmdTmpl, device)
 
 	p.opMutex.Lock()
 	_, _, err := p.e.RunCmd(cmd,
@@ -271,7 +417,7 @@ func (p *WrapPartitionImpl) SyncPartitionTable(device string) error {
 }
 
 // GetPartitionNameByUUID gets partition name by it's UUID
-// for example "1" for /dev/sda1,  "1p2" for /dev/nvme1p2,  "0p3" for /dev/loopback0p3
+// for example "1" for /dev/sda1, "p2" for /dev/nvme0n1p2, "p3" for /dev/loop0p3
 // Receives a device path and uuid of partition to find
 // Returns a partition number or error if something went wrong
 func (p *WrapPartitionImpl) GetPartitionNameByUUID(device, partUUID string) (string, error) {
@@ -301,7 +447,11 @@ func (p *WrapPartitionImpl) GetPartitionNameByUUID(device, partUUID string) (str
 				return "", fmt.Errorf("partition %s for device %s found but name is not present",
 					partUUID, device)
 			}
-			return strings.Replace(id.Name, device, "", 1), nil
+			if strings.HasPrefix(id.Name, device) {
+				return strings.TrimPrefix(id.Name, device), nil
+			}
+			// device is referenced by symlink, e.g. /dev/disk/by-id/...
+			return devicepath.PartitionSuffix(device, id.Name)
 		}
 	}
 
@@ -309,36 +459,15 @@ func (p *WrapPartitionImpl) GetPartitionNameByUUID(device, partUUID string) (str
 		partUUID, device, blockdevices)
 }
 
-// DeviceHasPartitionTable calls parted  and determine if device has partition table from output
+// DeviceHasPartitionTable calls parted and determines if device has partition table
 // Receive device path
 // Return true if device has partition table, false in opposite, error if something went wrong
 func (p *WrapPartitionImpl) DeviceHasPartitionTable(device string) (bool, error) {
-	/*
-		Disk /dev/sda: 931.5 GiB, 1000204886016 bytes, 1953525168 sectors
-		Units: sectors of 1 * 512 = 512 bytes
-		Sector size (logical/physical): 512 bytes / 512 bytes
-		I/O size (minimum/optimal): 512 bytes / 512 bytes
-		Disklabel type: gpt
-
-	*/
-	labelKey := "Disklabel type"
-
-	cmd := fmt.Sprintf(DetectPartitionTableCmdTmpl, device)
-
-	p.opMutex.Lock()
-	stdout, _, err := p.e.RunCmd(cmd,
-		command.UseMetrics(true),
-		command.CmdName(strings.TrimSpace(fmt.Sprintf(DetectPartitionTableCmdTmpl, ""))))
-	p.opMutex.Unlock()
-
+	table, err := p.GetPartitionTable(device)
 	if err != nil {
 		return false, err
 	}
-
-	if strings.Contains(stdout, labelKey) {
-		return true, nil
-	}
-	return false, nil
+	return table.HasPartitionTable(), nil
 }
 
 // DeviceHasPartitions calls lsblk and determine if device has partitions (children)
@@ -19,6 +19,9 @@ package partitionhelper
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -118,6 +121,43 @@ func TestGetPartitionUUIDFail(t *testing.T) {
 	assert.Equal(t, errors.New("error"), err)
 }
 
+func TestSetPartitionUUID(t *testing.T) {
+	err := testPartitioner.SetPartitionUUID("/dev/sda", testPartNum, testPartUUID)
+	assert.Nil(t, err)
+
+	err = testPartitioner.SetPartitionUUID("/dev/sdb", testPartNum, testPartUUID)
+	assert.NotNil(t, err)
+}
+
+func TestGetPartitionTypes(t *testing.T) {
+	types, err := testPartitioner.GetPartitionTypes("/dev/sda")
+	assert.Nil(t, err)
+	assert.Empty(t, types)
+
+	types, err = testPartitioner.GetPartitionTypes("/dev/sdb")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{testPartNum: LinuxFilesystemTypeGUID}, types)
+
+	_, err = testPartitioner.GetPartitionTypes("/dev/sdd")
+	assert.NotNil(t, err)
+
+	partitioner := NewWrapPartitionImpl(mocks.NewMockExecutor(map[string]mocks.CmdOut{
+		"parted --script --machine /dev/sda unit B print": {Stdout: "BYT;\n/dev/sda:1B:scsi:512:512:gpt:Mock:;\n" +
+			"1:1048576B:2097151B:1048576B:::;\n"},
+		"sgdisk /dev/sda --info=1": {Stdout: "Partition unique GUID: 64BE631B-62A5-11E9-A756-00505680D67F"},
+	}), testLogger)
+	_, err = partitioner.GetPartitionTypes("/dev/sda")
+	assert.NotNil(t, err)
+}
+
+func TestSetPartitionType(t *testing.T) {
+	err := testPartitioner.SetPartitionType("/dev/sda", testPartNum, LinuxFilesystemTypeGUID)
+	assert.Nil(t, err)
+
+	err = testPartitioner.SetPartitionType("/dev/sdb", testPartNum, LinuxFilesystemTypeGUID)
+	assert.NotNil(t, err)
+}
+
 func TestSyncPartitionTable(t *testing.T) {
 	err := testPartitioner.SyncPartitionTable("/dev/sde")
 	assert.Nil(t, err)
@@ -275,6 +315,54 @@ func TestLinuxUtils_DeviceHasPartitions(t *testing.T) {
 	})
 }
 
+func TestWrapPartitionImpl_GetPartitionTable(t *testing.T) {
+	var (
+		e      = &mocks.GoMockExecutor{}
+		p      = NewWrapPartitionImpl(e, testLogger)
+		device = "/dev/sda"
+	)
+	defer SetPartedJSONSupported(false)
+
+	e.OnCommand(fmt.Sprintf(PartedPrintCmdTmpl, device)).Return("BYT;\n"+
+		"/dev/sda:1000204886016B:scsi:512:4096:gpt:ATA ST1000\\:DM003:;\n"+
+		"1:1048576B:511705087B:510656512B:xfs:CSI:boot, esp;\n", "", nil).Once()
+	table, err := p.GetPartitionTable(device)
+	assert.Nil(t, err)
+	assert.Equal(t, &PartitionTable{Device: device, Type: PartitionGPT, Size: 1000204886016, LogicalSectorSize: 512,
+		PhysicalSectorSize: 4096, Partitions: []Partition{{Number: "1", Start: 1048576, End: 511705087,
+			Size: 510656512, FileSystem: "xfs", Name: "CSI", Flags: []string{"boot", "esp"}}}}, table)
+
+	// parted fails on device without partition table, but prints the device
+	e.OnCommand(fmt.Sprintf(PartedPrintCmdTmpl, device)).Return("BYT;\n"+
+		"/dev/sda:1000204886016B:scsi:512:4096:unknown:ATA ST1000DM003:;\n",
+		"Error: /dev/sda: unrecognised disk label", errors.New("exit status 1")).Once()
+	table, err = p.GetPartitionTable(device)
+	assert.Nil(t, err)
+	assert.False(t, table.HasPartitionTable())
+	assert.Empty(t, table.Partitions)
+
+	e.OnCommand(fmt.Sprintf(PartedPrintCmdTmpl, device)).Return("", "Error: Could not stat device",
+		errors.New("exit status 1")).Once()
+	_, err = p.GetPartitionTable(device)
+	assert.NotNil(t, err)
+
+	SetPartedJSONSupported(true)
+	e.OnCommand(fmt.Sprintf(PartedPrintJSONCmdTmpl, device)).Return(`{"disk": {"path": "/dev/sda",
+		"size": "1000204886016B", "logical-sector-size": 512, "physical-sector-size": 4096, "label": "gpt",
+		"partitions": [{"number": 1, "start": "1048576B", "end": "511705087B", "size": "510656512B",
+		"name": "CSI", "filesystem": "xfs", "flags": ["boot", "esp"]}]}}`, "", nil).Once()
+	jsonTable, err := p.GetPartitionTable(device)
+	assert.Nil(t, err)
+	assert.Equal(t, table.Device, jsonTable.Device)
+	assert.Equal(t, []Partition{{Number: "1", Start: 1048576, End: 511705087, Size: 510656512, FileSystem: "xfs",
+		Name: "CSI", Flags: []string{"boot", "esp"}}}, jsonTable.Partitions)
+
+	// truncated output
+	e.OnCommand(fmt.Sprintf(PartedPrintJSONCmdTmpl, device)).Return(`{"disk": {"path": "/dev/sda",`, "", nil).Once()
+	_, err = p.GetPartitionTable(device)
+	assert.True(t, errors.Is(err, errUnparsedOutput))
+}
+
 func TestLinuxUtils_DeviceHasPartitionTable(t *testing.T) {
 	var (
 		e      = mocks.GoMockExecutor{}
@@ -282,24 +370,59 @@ func TestLinuxUtils_DeviceHasPartitionTable(t *testing.T) {
 		device = "/dev/sda"
 	)
 	t.Run("Device has partition table", func(t *testing.T) {
-		e.On("RunCmd", fmt.Sprintf(DetectPartitionTableCmdTmpl, device)).
-			Return("Disklabel type: gpt", "", nil).Times(1)
+		e.On("RunCmd", fmt.Sprintf(PartedPrintCmdTmpl, device)).
+			Return("BYT;\n/dev/sda:1B:scsi:512:512:gpt:Mock:;\n", "", nil).Times(1)
 		hasPart, err := p.DeviceHasPartitionTable(device)
 		assert.Nil(t, err)
 		assert.True(t, hasPart)
 	})
 	t.Run("Device doesn't have partition table", func(t *testing.T) {
-		e.On("RunCmd", fmt.Sprintf(DetectPartitionTableCmdTmpl, device)).
-			Return(" ", "", nil).Times(1)
+		e.On("RunCmd", fmt.Sprintf(PartedPrintCmdTmpl, device)).
+			Return("BYT;\n/dev/sda:1B:scsi:512:512:unknown:Mock:;\n", "", errors.New("exit status 1")).Times(1)
+		hasPart, err := p.DeviceHasPartitionTable(device)
+		assert.Nil(t, err)
+		assert.False(t, hasPart)
+	})
+	t.Run("Device has filesystem", func(t *testing.T) {
+		e.On("RunCmd", fmt.Sprintf(PartedPrintCmdTmpl, device)).
+			Return("BYT;\n/dev/sda:1B:scsi:512:512:loop:Mock:;\n1:0B:0B:1B:xfs::;\n", "", nil).Times(1)
 		hasPart, err := p.DeviceHasPartitionTable(device)
 		assert.Nil(t, err)
 		assert.False(t, hasPart)
 	})
 	t.Run("Command failed", func(t *testing.T) {
-		e.On("RunCmd", fmt.Sprintf(DetectPartitionTableCmdTmpl, device)).
+		e.On("RunCmd", fmt.Sprintf(PartedPrintCmdTmpl, device)).
 			Return("", "", errors.New("error")).Times(1)
 		hasPart, err := p.DeviceHasPartitionTable(device)
 		assert.NotNil(t, err)
 		assert.False(t, hasPart)
 	})
 }
+
+func TestLinuxUtils_DeviceHasPartitionTable_Localized(t *testing.T) {
+	// fake parted prints localized messages, as real one does, unless locale is C; machine output isn't localized
+	partedPath := filepath.Join(t.TempDir(), "parted")
+	script := `#!/bin/sh
+if [ "$LC_ALL" != "C" ]; then echo "Warnung: Gerät wird verwendet"; fi
+echo "BYT;"
+echo "/dev/sda:1000204886016B:scsi:512:512:gpt:Mock:;"
+`
+	assert.Nil(t, ioutil.WriteFile(partedPath, []byte(script), 0700))
+	assert.Nil(t, command.SetUtilityPaths(map[string]string{"parted": partedPath}))
+	defer func() { _ = command.SetUtilityPaths(nil) }()
+
+	oldLocale, ok := os.LookupEnv("LC_ALL")
+	assert.Nil(t, os.Setenv("LC_ALL", "de_DE.UTF-8"))
+	defer func() {
+		if ok {
+			_ = os.Setenv("LC_ALL", oldLocale)
+		} else {
+			_ = os.Unsetenv("LC_ALL")
+		}
+	}()
+
+	p := NewWrapPartitionImpl(command.NewExecutor(testLogger), testLogger)
+	hasPart, err := p.DeviceHasPartitionTable("/dev/sda")
+	assert.Nil(t, err)
+	assert.True(t, hasPart)
+}
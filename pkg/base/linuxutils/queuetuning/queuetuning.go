@@ -0,0 +1,111 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package queuetuning reads and writes tunables of request queue of block devices in sysfs
+package queuetuning
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SysBlock is a sysfs directory with block devices (without partitions)
+	SysBlock = "/sys/block"
+
+	// AttrScheduler is an I/O scheduler of device, for example mq-deadline, bfq, kyber or none
+	AttrScheduler = "scheduler"
+	// AttrNrRequests is a number of requests which can be allocated in request queue
+	AttrNrRequests = "nr_requests"
+	// AttrReadAheadKB is a maximum number of kilobytes to read-ahead
+	AttrReadAheadKB = "read_ahead_kb"
+	// AttrMaxSectorsKB is a maximum size of request in kilobytes
+	AttrMaxSectorsKB = "max_sectors_kb"
+	// AttrRqAffinity is a policy of completion of requests on CPU which submitted them
+	AttrRqAffinity = "rq_affinity"
+	// AttrNoMerges is a policy of merging of requests
+	AttrNoMerges = "nomerges"
+)
+
+// ErrNotSupported is returned when device doesn't have queue attribute
+var ErrNotSupported = errors.New("queue attribute isn't supported by device")
+
+// WrapQueueTuning is an interface that encapsulates reading and writing of queue attributes of block devices
+type WrapQueueTuning interface {
+	GetQueueAttribute(device, attr string) (string, error)
+	SetQueueAttribute(device, attr, value string) error
+}
+
+// WrapQueueTuningImpl is an implementation of WrapQueueTuning which works with /sys/block/<device>/queue
+type WrapQueueTuningImpl struct {
+	sysBlock string
+}
+
+// NewWrapQueueTuningImpl is a constructor for WrapQueueTuningImpl
+func NewWrapQueueTuningImpl() *WrapQueueTuningImpl {
+	return &WrapQueueTuningImpl{sysBlock: SysBlock}
+}
+
+// GetQueueAttribute reads queue attribute of device, for example /dev/sda
+// Returns only selected scheduler for AttrScheduler and ErrNotSupported if device doesn't have the attribute
+func (q *WrapQueueTuningImpl) GetQueueAttribute(device, attr string) (string, error) {
+	data, err := ioutil.ReadFile(q.attrPath(device, attr))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%s of %s: %w", attr, device, ErrNotSupported)
+		}
+		return "", fmt.Errorf("unable to read %s of %s: %w", attr, device, err)
+	}
+	value := strings.TrimSpace(string(data))
+	if attr == AttrScheduler {
+		return selectedScheduler(value), nil
+	}
+	return value, nil
+}
+
+// SetQueueAttribute writes queue attribute of device, for example /dev/sda
+// Kernel is allowed to adjust value, so it must be read back to check whether value is accepted as is
+func (q *WrapQueueTuningImpl) SetQueueAttribute(device, attr, value string) error {
+	path := q.attrPath(device, attr)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("%s of %s: %w", attr, device, ErrNotSupported)
+	}
+	// sysfs attribute is truncated by kernel, file isn't created
+	if err := ioutil.WriteFile(path, []byte(value), 0); err != nil {
+		return fmt.Errorf("unable to set %s of %s to %s: %w", attr, device, value, err)
+	}
+	return nil
+}
+
+// attrPath returns sysfs path of queue attribute of device
+func (q *WrapQueueTuningImpl) attrPath(device, attr string) string {
+	return filepath.Join(q.sysBlock, filepath.Base(device), "queue", attr)
+}
+
+// selectedScheduler returns scheduler in brackets from list of available schedulers "mq-deadline [bfq] none"
+// Device without scheduler reports "none"
+func selectedScheduler(schedulers string) string {
+	for _, s := range strings.Fields(schedulers) {
+		if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+			return strings.Trim(s, "[]")
+		}
+	}
+	return schedulers
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queuetuning
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapQueueTuningImpl(t *testing.T) {
+	var (
+		root  = t.TempDir()
+		queue = filepath.Join(root, "sda", "queue")
+		q     = NewWrapQueueTuningImpl()
+	)
+	q.sysBlock = root
+	assert.Nil(t, os.MkdirAll(queue, 0700))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(queue, AttrScheduler), []byte("mq-deadline kyber [bfq] none\n"), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(queue, AttrReadAheadKB), []byte("128\n"), 0600))
+
+	value, err := q.GetQueueAttribute("/dev/sda", AttrScheduler)
+	assert.Nil(t, err)
+	assert.Equal(t, "bfq", value)
+	value, err = q.GetQueueAttribute("/dev/sda", AttrReadAheadKB)
+	assert.Nil(t, err)
+	assert.Equal(t, "128", value)
+
+	assert.Nil(t, q.SetQueueAttribute("/dev/sda", AttrReadAheadKB, "4096"))
+	value, err = q.GetQueueAttribute("/dev/sda", AttrReadAheadKB)
+	assert.Nil(t, err)
+	assert.Equal(t, "4096", value)
+
+	_, err = q.GetQueueAttribute("/dev/sda", AttrNrRequests)
+	assert.True(t, errors.Is(err, ErrNotSupported))
+	err = q.SetQueueAttribute("/dev/sdb", AttrNrRequests, "256")
+	assert.True(t, errors.Is(err, ErrNotSupported))
+}
+
+func TestSelectedScheduler(t *testing.T) {
+	assert.Equal(t, "mq-deadline", selectedScheduler("[mq-deadline] kyber none"))
+	assert.Equal(t, "none", selectedScheduler("[none] mq-deadline"))
+	assert.Equal(t, "none", selectedScheduler("none"))
+}
@@ -0,0 +1,213 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sgses discovers drive slots of SCSI enclosures with sg_ses util from sg3-utils
+package sgses
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicepath"
+)
+
+const (
+	// ConfigurationPageCmdTmpl is a CMD to read configuration diagnostic page of enclosure
+	ConfigurationPageCmdTmpl = "sg_ses --page=cf %s"
+	// AdditionalStatusPageCmdTmpl is a CMD to read additional element status diagnostic page of enclosure,
+	// the page contains SAS addresses of devices in slots
+	AdditionalStatusPageCmdTmpl = "sg_ses --page=aes %s"
+	// SysClassSCSIGeneric is a sysfs directory with SCSI generic devices
+	SysClassSCSIGeneric = "/sys/class/scsi_generic"
+	// SysBlock is a sysfs directory with block devices
+	SysBlock = "/sys/block"
+
+	// enclosureDeviceType is SCSI peripheral device type of enclosure services device
+	enclosureDeviceType = "13"
+	enclosureIDPrefix   = "enclosure logical identifier (hex):"
+	slotNumberPrefix    = "device slot number:"
+	sasAddressPrefix    = "SAS address:"
+	elementTypePrefix   = "Element type:"
+)
+
+// Slot is a drive slot of enclosure
+type Slot struct {
+	// Enclosure is a logical identifier of enclosure
+	Enclosure string
+	// Number is a device slot number which is usually printed on the chassis
+	Number int
+	// SASAddresses are SAS addresses of ports of device in the slot, dual ported drive has two addresses
+	SASAddresses []string
+}
+
+// WrapSgSes is an interface that encapsulates operation with system sg_ses util
+type WrapSgSes interface {
+	GetSlots() ([]Slot, error)
+	GetSASAddress(device string) (string, error)
+}
+
+// SGSES is a wrap for system sg_ses util
+type SGSES struct {
+	e                   command.CmdExecutor
+	sysClassSCSIGeneric string
+	sysBlock            string
+	log                 *logrus.Entry
+}
+
+// NewSGSES is a constructor for SGSES
+func NewSGSES(e command.CmdExecutor, logger *logrus.Logger) *SGSES {
+	return &SGSES{
+		e:                   e,
+		sysClassSCSIGeneric: SysClassSCSIGeneric,
+		sysBlock:            SysBlock,
+		log:                 logger.WithField("component", "SGSES"),
+	}
+}
+
+// GetSlots returns occupied slots of all enclosures of the node
+// Enclosure which can't be read is skipped
+func (s *SGSES) GetSlots() ([]Slot, error) {
+	ll := s.log.WithField("method", "GetSlots")
+	enclosures, err := s.getEnclosures()
+	if err != nil {
+		return nil, err
+	}
+	slots := make([]Slot, 0)
+	for _, enclosure := range enclosures {
+		cf, _, err := s.e.RunCmd(command.NewCmd(ConfigurationPageCmdTmpl, command.Device(enclosure)))
+		if err != nil {
+			ll.Errorf("Unable to read configuration of enclosure %s: %v", enclosure, err)
+			continue
+		}
+		id := parseEnclosureID(cf)
+		if id == "" {
+			ll.Errorf("Logical identifier of enclosure %s isn't found", enclosure)
+			continue
+		}
+		aes, _, err := s.e.RunCmd(command.NewCmd(AdditionalStatusPageCmdTmpl, command.Device(enclosure)))
+		if err != nil {
+			ll.Errorf("Unable to read additional element status of enclosure %s: %v", enclosure, err)
+			continue
+		}
+		slots = append(slots, parseSlots(aes, id)...)
+	}
+	return slots, nil
+}
+
+// GetSASAddress returns SAS address of block device, e.g. /dev/sda, from sysfs
+// Returns error if device isn't SAS device
+func (s *SGSES) GetSASAddress(device string) (string, error) {
+	id, err := devicepath.Resolve(device)
+	if err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(s.sysBlock, id.Name, "device", "sas_address"))
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(strings.TrimSpace(string(data))), nil
+}
+
+// getEnclosures returns paths of SCSI generic devices of enclosures, e.g. /dev/sg3
+func (s *SGSES) getEnclosures() ([]string, error) {
+	entries, err := ioutil.ReadDir(s.sysClassSCSIGeneric)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read SCSI generic devices: %w", err)
+	}
+	res := make([]string, 0)
+	for _, entry := range entries {
+		devType, err := ioutil.ReadFile(filepath.Join(s.sysClassSCSIGeneric, entry.Name(), "device", "type"))
+		if err == nil && strings.TrimSpace(string(devType)) == enclosureDeviceType {
+			res = append(res, "/dev/"+entry.Name())
+		}
+	}
+	return res, nil
+}
+
+// parseEnclosureID returns logical identifier of primary subenclosure from configuration page
+func parseEnclosureID(out string) string {
+	/*
+		Configuration diagnostic page:
+		  ...
+		  enclosure descriptor list
+		    Subenclosure identifier: 0 [primary]
+		      enclosure logical identifier (hex): 500056b3d2e4b300
+	*/
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		idx := strings.Index(strings.ToLower(line), enclosureIDPrefix)
+		if idx >= 0 {
+			return strings.ToLower(strings.TrimSpace(line[idx+len(enclosureIDPrefix):]))
+		}
+	}
+	return ""
+}
+
+// parseSlots returns slots with SAS addresses of devices from additional element status page
+func parseSlots(out, enclosure string) []Slot {
+	/*
+		Element type: Array device slot, subenclosure id: 0 [ti=0]
+		  Element index: 0  eiioe=1
+		    Transport protocol: SAS
+		    number of phys: 1, not all phys: 0, device slot number: 7
+		    phy index: 0
+		      SAS device type: end device
+		      attached SAS address: 0x500056b3d2e4b3ff
+		      SAS address: 0x5000c500a1b2c3d5
+	*/
+	var (
+		slots     = make([]Slot, 0)
+		slotTypes bool
+		current   *Slot
+	)
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, elementTypePrefix):
+			// SAS addresses of expanders are listed in elements of other types
+			slotTypes = strings.Contains(strings.ToLower(line), "device slot")
+			current = nil
+		case slotTypes && strings.Contains(line, slotNumberPrefix):
+			value := strings.TrimSpace(line[strings.Index(line, slotNumberPrefix)+len(slotNumberPrefix):])
+			number, err := strconv.Atoi(strings.Fields(value + " ")[0])
+			if err != nil {
+				current = nil
+				continue
+			}
+			slots = append(slots, Slot{Enclosure: enclosure, Number: number})
+			current = &slots[len(slots)-1]
+		case current != nil && strings.HasPrefix(line, sasAddressPrefix):
+			address := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, sasAddressPrefix)))
+			// empty slot has zero SAS address
+			if address != "" && strings.Trim(strings.TrimPrefix(address, "0x"), "0") != "" {
+				current.SASAddresses = append(current.SASAddresses, address)
+			}
+		}
+	}
+	res := make([]Slot, 0, len(slots))
+	for _, slot := range slots {
+		if len(slot.SASAddresses) > 0 {
+			res = append(res, slot)
+		}
+	}
+	return res
+}
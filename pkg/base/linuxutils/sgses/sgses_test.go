@@ -0,0 +1,146 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sgses
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+const (
+	cfPage = `  Primary enclosure logical identifier (hex): 500056b3d2e4b300
+Configuration diagnostic page:
+  number of secondary subenclosures: 0
+  generation code: 0x0
+  enclosure descriptor list
+    Subenclosure identifier: 0 [primary]
+      relative ES process id: 1, number of ES processes: 1
+      number of type descriptor headers: 3
+      enclosure logical identifier (hex): 500056B3D2E4B300
+      enclosure vendor: DELL      product: MD1400   rev: 1.07
+`
+	aesPage = `Additional element status diagnostic page:
+  generation code: 0x0
+  additional element status descriptor list
+    Element type: Array device slot, subenclosure id: 0 [ti=0]
+      Element index: 0  eiioe=1
+        Transport protocol: SAS
+        number of phys: 1, not all phys: 0, device slot number: 0
+        phy index: 0
+          SAS device type: end device
+          initiator port for:
+          target port for: SSP
+          attached SAS address: 0x500056b3d2e4b3ff
+          SAS address: 0x5000C500A1B2C3D5
+          phy identifier: 0x0
+      Element index: 1  eiioe=1
+        Transport protocol: SAS
+        number of phys: 1, not all phys: 0, device slot number: 1
+        phy index: 0
+          SAS device type: no SAS device attached
+          attached SAS address: 0x500056b3d2e4b3ff
+          SAS address: 0x0
+          phy identifier: 0x0
+      Element index: 7  eiioe=1
+        Transport protocol: SAS
+        number of phys: 2, not all phys: 0, device slot number: 7
+        phy index: 0
+          SAS device type: end device
+          attached SAS address: 0x500056b3d2e4b3ff
+          SAS address: 0x5000c500a1b2c3e1
+          phy identifier: 0x0
+        phy index: 1
+          SAS device type: end device
+          attached SAS address: 0x500056b3d2e4b3fe
+          SAS address: 0x5000c500a1b2c3e2
+          phy identifier: 0x1
+    Element type: SAS expander, subenclosure id: 0 [ti=2]
+      Element index: 14  eiioe=1
+        Transport protocol: SAS
+        number of expander phys: 26
+        SAS address: 0x500056b3d2e4b3ff
+`
+)
+
+var logger = logrus.New()
+
+// prepareSysfs creates sysfs with SCSI generic devices sg0 (disk) and sg1 (enclosure)
+// and block device sda with SAS address
+func prepareSysfs(t *testing.T, s *SGSES) {
+	root := t.TempDir()
+	writeFile := func(path, content string) {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0700))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(root, path), []byte(content), 0600))
+	}
+	writeFile("class/scsi_generic/sg0/device/type", "0\n")
+	writeFile("class/scsi_generic/sg1/device/type", "13\n")
+	writeFile("block/sda/device/sas_address", "0x5000C500A1B2C3D5\n")
+	writeFile("block/sdb/device/type", "0\n")
+	s.sysClassSCSIGeneric = filepath.Join(root, "class", "scsi_generic")
+	s.sysBlock = filepath.Join(root, "block")
+}
+
+func TestSGSES_GetSlots(t *testing.T) {
+	e := &mocks.GoMockExecutor{}
+	s := NewSGSES(e, logger)
+	prepareSysfs(t, s)
+
+	e.OnCommand(fmt.Sprintf(ConfigurationPageCmdTmpl, "/dev/sg1")).Return(cfPage, "", nil).Once()
+	e.OnCommand(fmt.Sprintf(AdditionalStatusPageCmdTmpl, "/dev/sg1")).Return(aesPage, "", nil).Once()
+	slots, err := s.GetSlots()
+	assert.Nil(t, err)
+	assert.Equal(t, []Slot{
+		{Enclosure: "500056b3d2e4b300", Number: 0, SASAddresses: []string{"0x5000c500a1b2c3d5"}},
+		{Enclosure: "500056b3d2e4b300", Number: 7, SASAddresses: []string{"0x5000c500a1b2c3e1", "0x5000c500a1b2c3e2"}},
+	}, slots)
+
+	// enclosure which can't be read is skipped
+	e.OnCommand(fmt.Sprintf(ConfigurationPageCmdTmpl, "/dev/sg1")).Return("", "", errors.New("error")).Once()
+	slots, err = s.GetSlots()
+	assert.Nil(t, err)
+	assert.Empty(t, slots)
+
+	s.sysClassSCSIGeneric = "/not/exists"
+	_, err = s.GetSlots()
+	assert.NotNil(t, err)
+}
+
+func TestSGSES_GetSASAddress(t *testing.T) {
+	s := NewSGSES(&mocks.GoMockExecutor{}, logger)
+	prepareSysfs(t, s)
+
+	address, err := s.GetSASAddress("/dev/sda")
+	assert.Nil(t, err)
+	assert.Equal(t, "0x5000c500a1b2c3d5", address)
+
+	_, err = s.GetSASAddress("/dev/sdb")
+	assert.NotNil(t, err)
+}
+
+func Test_parseEnclosureID(t *testing.T) {
+	assert.Equal(t, "500056b3d2e4b300", parseEnclosureID(cfPage))
+	assert.Equal(t, "", parseEnclosureID("Configuration diagnostic page:\n"))
+}
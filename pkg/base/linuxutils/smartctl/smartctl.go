@@ -29,8 +29,22 @@ const (
 	SmartctlCmdImpl = "smartctl"
 	// SmartctlDeviceInfoCmdImpl is a CMD to get basic SMART information and health about device in JSON format
 	SmartctlDeviceInfoCmdImpl = SmartctlCmdImpl + " --info --json %s"
-	// SmartctlHealthCmdImpl is a CMD to get  SMART status of device in JSON format
-	SmartctlHealthCmdImpl = SmartctlCmdImpl + " --health --json %s"
+	// SmartctlHealthCmdImpl is a CMD to get  SMART status and SMART attributes of device in JSON format
+	SmartctlHealthCmdImpl = SmartctlCmdImpl + " --health --attributes --json %s"
+)
+
+// ATA SMART attributes which are used for failure prediction
+const (
+	// ReallocatedSectorsAttrID is ID of Reallocated_Sector_Ct attribute
+	ReallocatedSectorsAttrID = 5
+	// PendingSectorsAttrID is ID of Current_Pending_Sector attribute
+	PendingSectorsAttrID = 197
+	// WearLevelingAttrID is ID of Wear_Leveling_Count attribute
+	WearLevelingAttrID = 177
+	// MediaWearoutAttrID is ID of Media_Wearout_Indicator attribute
+	MediaWearoutAttrID = 233
+	// UnknownValue is returned when SMART attribute isn't reported by device
+	UnknownValue = -1
 )
 
 // WrapSmartctl is an interface that encapsulates operation with system smartctl util
@@ -43,6 +57,71 @@ type DeviceSMARTInfo struct {
 	SerialNumber string          `json:"serial_number"`
 	SmartStatus  map[string]bool `json:"smart_status"`
 	Rotation     int             `json:"rotation_rate"`
+	// ATA devices report attributes in table, SCSI devices report separate counters
+	ATAAttributes     ATASMARTAttributes `json:"ata_smart_attributes"`
+	SCSIGrownDefects  *int64             `json:"scsi_grown_defect_list,omitempty"`
+	SCSIEnduranceUsed *int64             `json:"scsi_percentage_used_endurance_indicator,omitempty"`
+	Temperature       struct {
+		// current temperature in Celsius
+		Current int64 `json:"current"`
+	} `json:"temperature"`
+}
+
+// ATASMARTAttributes represents table of ATA SMART attributes
+type ATASMARTAttributes struct {
+	Table []ATASMARTAttribute `json:"table"`
+}
+
+// ATASMARTAttribute represents one ATA SMART attribute with normalized and raw values
+type ATASMARTAttribute struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+	Raw   struct {
+		Value int64 `json:"value"`
+	} `json:"raw"`
+}
+
+// ReallocatedSectors returns count of reallocated sectors or grown defects, UnknownValue if it isn't reported
+func (d *DeviceSMARTInfo) ReallocatedSectors() int64 {
+	if d.SCSIGrownDefects != nil {
+		return *d.SCSIGrownDefects
+	}
+	if attr := d.attribute(ReallocatedSectorsAttrID); attr != nil {
+		return attr.Raw.Value
+	}
+	return UnknownValue
+}
+
+// PendingSectors returns count of sectors which are waiting for reallocation, UnknownValue if it isn't reported
+func (d *DeviceSMARTInfo) PendingSectors() int64 {
+	if attr := d.attribute(PendingSectorsAttrID); attr != nil {
+		return attr.Raw.Value
+	}
+	return UnknownValue
+}
+
+// WearUsed returns percentage of used endurance for SSD, UnknownValue if it isn't reported
+func (d *DeviceSMARTInfo) WearUsed() int64 {
+	if d.SCSIEnduranceUsed != nil {
+		return *d.SCSIEnduranceUsed
+	}
+	// normalized value of wear attributes starts from 100 and decreases while drive wears out
+	for _, id := range []int{WearLevelingAttrID, MediaWearoutAttrID} {
+		if attr := d.attribute(id); attr != nil && attr.Value <= 100 {
+			return 100 - attr.Value
+		}
+	}
+	return UnknownValue
+}
+
+func (d *DeviceSMARTInfo) attribute(id int) *ATASMARTAttribute {
+	for i := range d.ATAAttributes.Table {
+		if d.ATAAttributes.Table[i].ID == id {
+			return &d.ATAAttributes.Table[i]
+		}
+	}
+	return nil
 }
 
 // SMARTCTL is a wrap for system smartctl util
@@ -112,3 +112,38 @@ func TestSMARCTL_fillSmartStatusUnmarshallError(t *testing.T) {
 	err := l.fillSmartStatus(&DeviceSMARTInfo{}, "/dev/sdd")
 	assert.NotNil(t, err)
 }
+
+func TestDeviceSMARTInfo_Attributes(t *testing.T) {
+	output := `{
+    "smart_status": {
+        "passed": true
+    },
+    "ata_smart_attributes": {
+        "table": [
+            {"id": 5, "name": "Reallocated_Sector_Ct", "value": 100, "raw": {"value": 8}},
+            {"id": 177, "name": "Wear_Leveling_Count", "value": 93, "raw": {"value": 71}},
+            {"id": 197, "name": "Current_Pending_Sector", "value": 100, "raw": {"value": 2}}
+        ]
+    },
+    "temperature": {
+        "current": 41
+    }}`
+	cmd := fmt.Sprintf(SmartctlHealthCmdImpl, "/dev/sdd")
+	e := &mocks.GoMockExecutor{}
+	l := NewSMARTCTL(e)
+
+	e.On("RunCmd", cmd).Return(output, "", nil)
+	info := &DeviceSMARTInfo{}
+	assert.Nil(t, l.fillSmartStatus(info, "/dev/sdd"))
+	assert.Equal(t, int64(8), info.ReallocatedSectors())
+	assert.Equal(t, int64(2), info.PendingSectors())
+	assert.Equal(t, int64(7), info.WearUsed())
+	assert.Equal(t, int64(41), info.Temperature.Current)
+
+	// SCSI device
+	defects, endurance := int64(3), int64(12)
+	info = &DeviceSMARTInfo{SCSIGrownDefects: &defects, SCSIEnduranceUsed: &endurance}
+	assert.Equal(t, defects, info.ReallocatedSectors())
+	assert.Equal(t, int64(UnknownValue), info.PendingSectors())
+	assert.Equal(t, endurance, info.WearUsed())
+}
@@ -0,0 +1,206 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sysblock discovers MMC/eMMC and virtio-blk drives from sysfs. Such drives aren't reported by lsscsi
+// and nvme utils, but they are the only drives of edge devices and virtual machines of test clusters
+package sysblock
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SysBlock is a sysfs directory with block devices (without partitions)
+	SysBlock = "/sys/block"
+
+	// ClassMMC is a class of MMC/eMMC and SD card drives
+	ClassMMC = "mmc"
+	// ClassVirtio is a class of virtio-blk drives
+	ClassVirtio = "virtio"
+
+	// VirtioVendorID is a PCI vendor ID of virtio devices
+	VirtioVendorID = "0x1af4"
+	// VirtioModel is a model of virtio-blk drives
+	VirtioModel = "virtio-blk"
+
+	// UnknownWear is a value of Wear when eMMC doesn't report life time estimation
+	UnknownWear = -1
+
+	devDir     = "/dev"
+	sectorSize = 512
+	// lifeTimeExceeded is a value of life time estimation when eMMC exceeded its maximum estimated life time
+	lifeTimeExceeded = 0x0b
+	// preEOLUrgent is a value of pre EOL information when 90% of reserved blocks of eMMC are consumed
+	preEOLUrgent = 0x03
+)
+
+// only whole drives are discovered, boot and RPMB hardware partitions of eMMC (mmcblk0boot0, mmcblk0rpmb)
+// are skipped
+var (
+	mmcRegexp    = regexp.MustCompile(`^mmcblk[0-9]+$`)
+	virtioRegexp = regexp.MustCompile(`^vd[a-z]+$`)
+)
+
+// Device represents MMC or virtio-blk drive
+type Device struct {
+	Path       string
+	Class      string
+	Serial     string
+	Vendor     string
+	Model      string
+	Firmware   string
+	Size       int64
+	Rotational bool
+	// Wear is a percent of used life time of eMMC, UnknownWear if isn't reported
+	Wear int64
+	// EOL is set when eMMC exceeded its life time or consumed reserved blocks
+	EOL bool
+}
+
+// WrapSysBlock is an interface that encapsulates discovery of drives from sysfs
+type WrapSysBlock interface {
+	GetDevices() ([]Device, error)
+}
+
+// SysBlockReader is an implementation of WrapSysBlock interface which reads /sys/block
+type SysBlockReader struct {
+	sysBlock string
+	log      *logrus.Entry
+}
+
+// NewSysBlockReader is a constructor for SysBlockReader
+func NewSysBlockReader(logger *logrus.Logger) *SysBlockReader {
+	return &SysBlockReader{
+		sysBlock: SysBlock,
+		log:      logger.WithField("component", "SysBlockReader"),
+	}
+}
+
+// GetDevices returns MMC/eMMC and virtio-blk drives of the node
+// Returns error if sysfs can't be read
+func (s *SysBlockReader) GetDevices() ([]Device, error) {
+	entries, err := ioutil.ReadDir(s.sysBlock)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read block devices from %s: %w", s.sysBlock, err)
+	}
+	devices := make([]Device, 0)
+	for _, entry := range entries {
+		var (
+			dev  *Device
+			name = entry.Name()
+		)
+		switch {
+		case mmcRegexp.MatchString(name):
+			dev = s.readMMC(name)
+		case virtioRegexp.MatchString(name):
+			dev = s.readVirtio(name)
+		default:
+			continue
+		}
+		if dev != nil {
+			devices = append(devices, *dev)
+		}
+	}
+	return devices, nil
+}
+
+// readMMC reads attributes of MMC/eMMC drive, serial number is a product serial number from CID register
+// Returns nil if device isn't MMC drive
+func (s *SysBlockReader) readMMC(name string) *Device {
+	device := filepath.Join(s.sysBlock, name, "device")
+	if readAttr(device, "type") == "" {
+		s.log.WithField("method", "readMMC").Debugf("%s isn't MMC device", name)
+		return nil
+	}
+	dev := s.readCommon(name, ClassMMC)
+	dev.Serial = normalizeHex(readAttr(device, "serial"))
+	dev.Vendor = normalizeHex(readAttr(device, "manfid"))
+	dev.Model = readAttr(device, "name")
+	dev.Firmware = normalizeHex(readAttr(device, "fwrev"))
+	if dev.Firmware == "" {
+		dev.Firmware = normalizeHex(readAttr(device, "prv"))
+	}
+	// life time estimation of SLC and MLC areas, 0x01 - 0-10% used ... 0x0a - 90-100% used, 0x0b - exceeded
+	for _, value := range strings.Fields(readAttr(device, "life_time")) {
+		lifeTime, err := strconv.ParseInt(value, 0, 64)
+		if err != nil || lifeTime == 0 {
+			continue
+		}
+		if lifeTime >= lifeTimeExceeded {
+			dev.EOL = true
+		}
+		if wear := lifeTime * 10; wear > dev.Wear {
+			dev.Wear = wear
+		}
+		if dev.Wear > 100 {
+			dev.Wear = 100
+		}
+	}
+	if preEOL, err := strconv.ParseInt(readAttr(device, "pre_eol_info"), 0, 32); err == nil && preEOL >= preEOLUrgent {
+		dev.EOL = true
+	}
+	return dev
+}
+
+// readVirtio reads attributes of virtio-blk drive, serial number is set by hypervisor
+// Returns nil if device isn't virtio-blk drive
+func (s *SysBlockReader) readVirtio(name string) *Device {
+	if vendor := readAttr(filepath.Join(s.sysBlock, name, "device"), "vendor"); vendor != VirtioVendorID {
+		s.log.WithField("method", "readVirtio").Debugf("%s isn't virtio device, vendor: %s", name, vendor)
+		return nil
+	}
+	dev := s.readCommon(name, ClassVirtio)
+	dev.Serial = readAttr(filepath.Join(s.sysBlock, name), "serial")
+	dev.Vendor = VirtioVendorID
+	dev.Model = VirtioModel
+	return dev
+}
+
+// readCommon reads attributes which are the same for all block devices
+func (s *SysBlockReader) readCommon(name, class string) *Device {
+	dir := filepath.Join(s.sysBlock, name)
+	dev := &Device{
+		Path:       filepath.Join(devDir, name),
+		Class:      class,
+		Rotational: readAttr(dir, "queue/rotational") == "1",
+		Wear:       UnknownWear,
+	}
+	if sectors, err := strconv.ParseInt(readAttr(dir, "size"), 10, 64); err == nil {
+		dev.Size = sectors * sectorSize
+	}
+	return dev
+}
+
+// readAttr returns trimmed value of sysfs attribute, empty string if attribute doesn't exist
+func readAttr(dir, name string) string {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// normalizeHex removes 0x prefix of hex value and converts it to upper case, e.g. 0x1f2e3d4c -> 1F2E3D4C
+func normalizeHex(value string) string {
+	return strings.ToUpper(strings.TrimPrefix(strings.ToLower(value), "0x"))
+}
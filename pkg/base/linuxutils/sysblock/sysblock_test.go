@@ -0,0 +1,125 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysblock
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// prepareSysBlock creates /sys/block tree with eMMC mmcblk0 (and its boot and RPMB partitions),
+// SD card mmcblk1, virtio-blk vda and vdb, SCSI sda
+func prepareSysBlock(t *testing.T) *SysBlockReader {
+	root := t.TempDir()
+	writeFile := func(path, content string) {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0700))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(root, path), []byte(content), 0600))
+	}
+
+	writeFile("mmcblk0/size", "30535680\n")
+	writeFile("mmcblk0/queue/rotational", "0\n")
+	writeFile("mmcblk0/device/type", "MMC\n")
+	writeFile("mmcblk0/device/name", "DG4016\n")
+	writeFile("mmcblk0/device/serial", "0x1f2e3d4c\n")
+	writeFile("mmcblk0/device/manfid", "0x000045\n")
+	writeFile("mmcblk0/device/prv", "0x1\n")
+	writeFile("mmcblk0/device/life_time", "0x02 0x03\n")
+	writeFile("mmcblk0/device/pre_eol_info", "0x01\n")
+	writeFile("mmcblk0boot0/size", "8192\n")
+	writeFile("mmcblk0rpmb/size", "8192\n")
+
+	writeFile("mmcblk1/size", "62333952\n")
+	writeFile("mmcblk1/queue/rotational", "0\n")
+	writeFile("mmcblk1/device/type", "SD\n")
+	writeFile("mmcblk1/device/name", "SD64G\n")
+	writeFile("mmcblk1/device/serial", "0x0000abcd\n")
+	writeFile("mmcblk1/device/manfid", "0x000003\n")
+	writeFile("mmcblk1/device/fwrev", "0x0\n")
+
+	writeFile("vda/size", "41943040\n")
+	writeFile("vda/serial", "vol-0123456789\n")
+	writeFile("vda/queue/rotational", "1\n")
+	writeFile("vda/device/vendor", "0x1af4\n")
+	// serial isn't set by hypervisor
+	writeFile("vdb/size", "2097152\n")
+	writeFile("vdb/queue/rotational", "0\n")
+	writeFile("vdb/device/vendor", "0x1af4\n")
+
+	writeFile("sda/size", "1953525168\n")
+	writeFile("sda/device/vendor", "ATA\n")
+
+	return &SysBlockReader{sysBlock: root, log: logrus.New().WithField("component", "SysBlockReader")}
+}
+
+func TestSysBlockReader_GetDevices(t *testing.T) {
+	s := prepareSysBlock(t)
+
+	devices, err := s.GetDevices()
+	assert.Nil(t, err)
+	assert.Equal(t, []Device{
+		{
+			Path: "/dev/mmcblk0", Class: ClassMMC, Serial: "1F2E3D4C", Vendor: "000045", Model: "DG4016",
+			Firmware: "1", Size: 30535680 * 512, Wear: 30,
+		},
+		{
+			Path: "/dev/mmcblk1", Class: ClassMMC, Serial: "0000ABCD", Vendor: "000003", Model: "SD64G",
+			Firmware: "0", Size: 62333952 * 512, Wear: UnknownWear,
+		},
+		{
+			Path: "/dev/vda", Class: ClassVirtio, Serial: "vol-0123456789", Vendor: VirtioVendorID,
+			Model: VirtioModel, Size: 41943040 * 512, Rotational: true, Wear: UnknownWear,
+		},
+		{
+			Path: "/dev/vdb", Class: ClassVirtio, Vendor: VirtioVendorID, Model: VirtioModel,
+			Size: 2097152 * 512, Wear: UnknownWear,
+		},
+	}, devices)
+
+	s.sysBlock = "/not/exists"
+	_, err = s.GetDevices()
+	assert.NotNil(t, err)
+}
+
+func TestSysBlockReader_readMMC_EOL(t *testing.T) {
+	s := prepareSysBlock(t)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(s.sysBlock, "mmcblk0/device/life_time"), []byte("0x0b 0x01\n"), 0600))
+	dev := s.readMMC("mmcblk0")
+	assert.True(t, dev.EOL)
+	assert.Equal(t, int64(100), dev.Wear)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(s.sysBlock, "mmcblk0/device/life_time"), []byte("0x01 0x01\n"), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(s.sysBlock, "mmcblk0/device/pre_eol_info"), []byte("0x03\n"), 0600))
+	dev = s.readMMC("mmcblk0")
+	assert.True(t, dev.EOL)
+	assert.Equal(t, int64(10), dev.Wear)
+
+	// not MMC device
+	assert.Nil(t, os.Remove(filepath.Join(s.sysBlock, "mmcblk0/device/type")))
+	assert.Nil(t, s.readMMC("mmcblk0"))
+}
+
+func Test_normalizeHex(t *testing.T) {
+	assert.Equal(t, "1F2E3D4C", normalizeHex("0x1f2e3d4c"))
+	assert.Equal(t, "ABC", normalizeHex("abc"))
+	assert.Equal(t, "", normalizeHex(""))
+}
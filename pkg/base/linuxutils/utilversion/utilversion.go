@@ -0,0 +1,161 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package utilversion contains code for detecting versions of system utilities and their optional capabilities
+package utilversion
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+)
+
+// Utility names
+const (
+	Parted  = "parted"
+	Sgdisk  = "sgdisk"
+	Lsblk   = "lsblk"
+	LVM     = "lvm"
+	MkfsXFS = "mkfs.xfs"
+	MkfsExt = "mke2fs"
+)
+
+// versionCmds contains commands which print version of utility
+var versionCmds = map[string]string{
+	Parted:  "parted --version",
+	Sgdisk:  "sgdisk --version",
+	Lsblk:   "lsblk --version",
+	LVM:     "lvm version",
+	MkfsXFS: "mkfs.xfs -V",
+	MkfsExt: "mke2fs -V",
+}
+
+// versionRegexp matches the first version number in utility output, for example:
+// parted (GNU parted) 3.2
+// GPT fdisk (sgdisk) version 1.0.3
+// lsblk from util-linux 2.31.1
+// LVM version:     2.02.176(2) (2017-11-03)
+// mkfs.xfs version 4.9.0
+// mke2fs 1.44.1 (24-Mar-2018)
+var versionRegexp = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// Version is a version of system utility
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// String returns version in format major.minor.patch
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast checks that version is greater or equal to provided major.minor.patch
+func (v Version) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+// ParseVersion searches version in utility output
+// Returns Version or error if version isn't found
+func ParseVersion(output string) (Version, error) {
+	match := versionRegexp.FindStringSubmatch(output)
+	if match == nil {
+		return Version{}, fmt.Errorf("version isn't found in output: %s", output)
+	}
+	var v Version
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+	if match[3] != "" {
+		v.Patch, _ = strconv.Atoi(match[3])
+	}
+	return v, nil
+}
+
+// Capabilities contains optional features of system utilities which depend on their versions
+type Capabilities struct {
+	// LsblkJSON - lsblk supports --json output, util-linux 2.27+
+	LsblkJSON bool
+	// PartedJSON - parted supports --json output, parted 3.5+
+	PartedJSON bool
+}
+
+// Detector detects versions of system utilities
+type Detector struct {
+	e   command.CmdExecutor
+	log *logrus.Entry
+}
+
+// NewDetector is a constructor for Detector struct
+func NewDetector(e command.CmdExecutor, logger *logrus.Logger) *Detector {
+	return &Detector{
+		e:   e,
+		log: logger.WithField("component", "Detector"),
+	}
+}
+
+// Detect runs version commands of system utilities
+// Returns map utility name -> version, utilities which versions can't be detected are skipped
+func (d *Detector) Detect() map[string]Version {
+	ll := d.log.WithField("method", "Detect")
+
+	versions := make(map[string]Version, len(versionCmds))
+	for name, cmd := range versionCmds {
+		// some utilities print version to stderr
+		stdout, stderr, err := d.e.RunCmd(cmd)
+		if err != nil {
+			ll.Warnf("Unable to detect version of %s: %v", name, err)
+			continue
+		}
+		v, err := ParseVersion(stdout + stderr)
+		if err != nil {
+			ll.Warnf("Unable to parse version of %s: %v", name, err)
+			continue
+		}
+		versions[name] = v
+	}
+	return versions
+}
+
+// GetCapabilities returns capabilities of system utilities based on their versions
+// Capability is considered as unsupported if version of utility is unknown
+func GetCapabilities(versions map[string]Version) Capabilities {
+	var c Capabilities
+	if v, ok := versions[Lsblk]; ok {
+		c.LsblkJSON = v.AtLeast(2, 27, 0)
+	}
+	if v, ok := versions[Parted]; ok {
+		c.PartedJSON = v.AtLeast(3, 5, 0)
+	}
+	return c
+}
+
+// ToMap converts versions to map utility name -> version string
+func ToMap(versions map[string]Version) map[string]string {
+	res := make(map[string]string, len(versions))
+	for name, v := range versions {
+		res[name] = v.String()
+	}
+	return res
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utilversion
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]Version{
+		"parted (GNU parted) 3.2\nCopyright (C) 2014": {3, 2, 0},
+		"GPT fdisk (sgdisk) version 1.0.3\n":          {1, 0, 3},
+		"lsblk from util-linux 2.31.1\n":              {2, 31, 1},
+		"  LVM version:     2.02.176(2) (2017-11-03)": {2, 2, 176},
+		"mkfs.xfs version 4.9.0\n":                    {4, 9, 0},
+		"mke2fs 1.44.1 (24-Mar-2018)\n":               {1, 44, 1},
+	}
+	for output, expected := range tests {
+		v, err := ParseVersion(output)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, v, output)
+	}
+
+	_, err := ParseVersion("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestVersion_AtLeast(t *testing.T) {
+	v := Version{2, 27, 1}
+	assert.True(t, v.AtLeast(2, 27, 0))
+	assert.True(t, v.AtLeast(2, 27, 1))
+	assert.True(t, v.AtLeast(1, 30, 0))
+	assert.False(t, v.AtLeast(2, 27, 2))
+	assert.False(t, v.AtLeast(2, 28, 0))
+	assert.False(t, v.AtLeast(3, 0, 0))
+	assert.Equal(t, "2.27.1", v.String())
+}
+
+func TestDetector_Detect(t *testing.T) {
+	e := &mocks.GoMockExecutor{}
+	e.OnCommand(versionCmds[Parted]).Return("parted (GNU parted) 3.2", "", nil)
+	e.OnCommand(versionCmds[Sgdisk]).Return("GPT fdisk (sgdisk) version 1.0.3", "", nil)
+	e.OnCommand(versionCmds[Lsblk]).Return("lsblk from util-linux 2.23.2", "", nil)
+	e.OnCommand(versionCmds[LVM]).Return("  LVM version:     2.02.176(2) (2017-11-03)", "", nil)
+	e.OnCommand(versionCmds[MkfsXFS]).Return("", "", errors.New("not found"))
+	// mke2fs prints version to stderr
+	e.OnCommand(versionCmds[MkfsExt]).Return("", "mke2fs 1.44.1 (24-Mar-2018)", nil)
+
+	versions := NewDetector(e, logrus.New()).Detect()
+	assert.Len(t, versions, 5)
+	assert.Equal(t, "1.44.1", ToMap(versions)[MkfsExt])
+
+	c := GetCapabilities(versions)
+	assert.False(t, c.LsblkJSON)
+	assert.False(t, c.PartedJSON)
+
+	assert.Equal(t, Capabilities{}, GetCapabilities(nil))
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package writecache provides control of volatile write cache of drives
+package writecache
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/command"
+)
+
+const (
+	// HdparmGetWriteCacheCmdTmpl reads write cache state of SATA/SAS drive
+	HdparmGetWriteCacheCmdTmpl = "hdparm -W %s"
+	// HdparmSetWriteCacheCmdTmpl enables (1) or disables (0) write cache of SATA/SAS drive
+	HdparmSetWriteCacheCmdTmpl = "hdparm -W%d %s"
+	// NVMeGetWriteCacheCmdTmpl reads Volatile Write Cache feature (0x06) of NVMe drive
+	NVMeGetWriteCacheCmdTmpl = "nvme get-feature %s --feature-id=6"
+	// NVMeSetWriteCacheCmdTmpl enables (1) or disables (0) Volatile Write Cache feature of NVMe drive
+	NVMeSetWriteCacheCmdTmpl = "nvme set-feature %s --feature-id=6 --value=%d"
+)
+
+var (
+	// ErrNotSupported is returned when drive doesn't have write cache which can be controlled
+	ErrNotSupported = errors.New("write cache control isn't supported")
+
+	// hdparmRegexp matches " write-caching =  1 (on)"
+	hdparmRegexp = regexp.MustCompile(`write-caching\s*=\s*(\d+|not supported)`)
+	// nvmeRegexp matches "get-feature:0x06 (Volatile Write Cache), Current value:0x00000001"
+	nvmeRegexp = regexp.MustCompile(`Current value:\s*(?:0x)?([0-9a-fA-F]+)`)
+)
+
+// WrapWriteCache is an interface that encapsulates control of volatile write cache of drives
+type WrapWriteCache interface {
+	GetWriteCache(device, driveType string) (bool, error)
+	SetWriteCache(device, driveType string, enabled bool) error
+}
+
+// WrapWriteCacheImpl is an implementation of WrapWriteCache which uses hdparm for HDD/SSD and nvme-cli for NVMe
+type WrapWriteCacheImpl struct {
+	e command.CmdExecutor
+}
+
+// NewWrapWriteCacheImpl is a constructor for WrapWriteCacheImpl
+func NewWrapWriteCacheImpl(e command.CmdExecutor) *WrapWriteCacheImpl {
+	return &WrapWriteCacheImpl{e: e}
+}
+
+// GetWriteCache returns whether write cache of device is enabled
+// Returns error wrapping ErrNotSupported if drive doesn't report write cache state
+func (w *WrapWriteCacheImpl) GetWriteCache(device, driveType string) (bool, error) {
+	tmpl := HdparmGetWriteCacheCmdTmpl
+	if driveType == apiV1.DriveTypeNVMe {
+		tmpl = NVMeGetWriteCacheCmdTmpl
+	}
+	stdout, stderr, err := w.e.RunCmd(command.NewCmd(tmpl, command.Device(device)),
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(tmpl, ""))))
+	if err != nil {
+		if driveType == apiV1.DriveTypeNVMe {
+			// controller without volatile write cache rejects the feature
+			return false, fmt.Errorf("%w: unable to read write cache of %s: %v, %s", ErrNotSupported, device, err,
+				strings.TrimSpace(stderr))
+		}
+		return false, fmt.Errorf("unable to read write cache of %s: %v", device, err)
+	}
+
+	if driveType == apiV1.DriveTypeNVMe {
+		match := nvmeRegexp.FindStringSubmatch(stdout)
+		if match == nil {
+			return false, fmt.Errorf("unable to parse write cache of %s from %q", device, stdout)
+		}
+		value, err := strconv.ParseUint(match[1], 16, 32)
+		if err != nil {
+			return false, fmt.Errorf("unable to parse write cache of %s: %v", device, err)
+		}
+		return value&1 == 1, nil
+	}
+
+	match := hdparmRegexp.FindStringSubmatch(stdout)
+	switch {
+	case match == nil:
+		return false, fmt.Errorf("unable to parse write cache of %s from %q", device, stdout)
+	case match[1] == "not supported":
+		return false, fmt.Errorf("%w: %s", ErrNotSupported, device)
+	}
+	return match[1] != "0", nil
+}
+
+// SetWriteCache enables or disables write cache of device, setting isn't persistent across power cycles
+func (w *WrapWriteCacheImpl) SetWriteCache(device, driveType string, enabled bool) error {
+	value := 0
+	if enabled {
+		value = 1
+	}
+	cmd := command.NewCmd(HdparmSetWriteCacheCmdTmpl, value, command.Device(device))
+	name := "hdparm -W"
+	if driveType == apiV1.DriveTypeNVMe {
+		cmd = command.NewCmd(NVMeSetWriteCacheCmdTmpl, command.Device(device), value)
+		name = "nvme set-feature"
+	}
+	if _, stderr, err := w.e.RunCmd(cmd, command.UseMetrics(true), command.CmdName(name)); err != nil {
+		return fmt.Errorf("unable to set write cache of %s to %t: %v, %s", device, enabled, err,
+			strings.TrimSpace(stderr))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package writecache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestWrapWriteCacheImpl_GetWriteCache(t *testing.T) {
+	cmds := map[string]mocks.CmdOut{
+		"hdparm -W /dev/sda": {Stdout: "\n/dev/sda:\n write-caching =  1 (on)\n"},
+		"hdparm -W /dev/sdb": {Stdout: "\n/dev/sdb:\n write-caching =  0 (off)\n"},
+		"hdparm -W /dev/sdc": {Stdout: "\n/dev/sdc:\n write-caching = not supported\n"},
+		"nvme get-feature /dev/nvme0n1 --feature-id=6": {
+			Stdout: "get-feature:0x06 (Volatile Write Cache), Current value:0x00000001"},
+		"nvme get-feature /dev/nvme1n1 --feature-id=6": {Stderr: "NVMe status: INVALID_FIELD", Err: mocks.Err},
+	}
+	wc := NewWrapWriteCacheImpl(mocks.NewMockExecutor(cmds))
+
+	enabled, err := wc.GetWriteCache("/dev/sda", apiV1.DriveTypeHDD)
+	assert.Nil(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = wc.GetWriteCache("/dev/sdb", apiV1.DriveTypeSSD)
+	assert.Nil(t, err)
+	assert.False(t, enabled)
+
+	_, err = wc.GetWriteCache("/dev/sdc", apiV1.DriveTypeHDD)
+	assert.True(t, errors.Is(err, ErrNotSupported))
+
+	enabled, err = wc.GetWriteCache("/dev/nvme0n1", apiV1.DriveTypeNVMe)
+	assert.Nil(t, err)
+	assert.True(t, enabled)
+
+	_, err = wc.GetWriteCache("/dev/nvme1n1", apiV1.DriveTypeNVMe)
+	assert.True(t, errors.Is(err, ErrNotSupported))
+
+	// command isn't mocked
+	_, err = wc.GetWriteCache("/dev/sdd", apiV1.DriveTypeHDD)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrNotSupported))
+}
+
+func TestWrapWriteCacheImpl_SetWriteCache(t *testing.T) {
+	cmds := map[string]mocks.CmdOut{
+		"hdparm -W0 /dev/sda": mocks.EmptyOutSuccess,
+		"nvme set-feature /dev/nvme0n1 --feature-id=6 --value=1": mocks.EmptyOutSuccess,
+	}
+	wc := NewWrapWriteCacheImpl(mocks.NewMockExecutor(cmds))
+
+	assert.Nil(t, wc.SetWriteCache("/dev/sda", apiV1.DriveTypeHDD, false))
+	assert.Nil(t, wc.SetWriteCache("/dev/nvme0n1", apiV1.DriveTypeNVMe, true))
+	assert.NotNil(t, wc.SetWriteCache("/dev/sdb", apiV1.DriveTypeHDD, true))
+}
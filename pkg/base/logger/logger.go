@@ -27,6 +27,8 @@ import (
 const (
 	// LogFormatText represents human readable log format
 	LogFormatText = "text"
+	// LogFormatEnv is environment variable with log format
+	LogFormatEnv = "LOG_FORMAT"
 
 	// DebugLevel represents debug level for logger
 	DebugLevel = "debug"
@@ -44,7 +46,7 @@ func InitLogger(logPath string, logLevel string) (*logrus.Logger, error) {
 	logger := logrus.New()
 	// TODO: should be configured in helm chart https://github.com/dell/csi-baremetal/issues/83
 	var formatter logrus.Formatter
-	if os.Getenv("LOG_FORMAT") == "text" {
+	if os.Getenv(LogFormatEnv) == LogFormatText {
 		formatter = &nested.Formatter{
 			HideKeys:    true,
 			NoColors:    true,
@@ -59,20 +61,8 @@ func InitLogger(logPath string, logLevel string) (*logrus.Logger, error) {
 	}
 	logger.SetFormatter(formatter)
 
-	var level logrus.Level
 	// set log level
-	switch strings.ToLower(logLevel) {
-	case InfoLevel:
-		level = logrus.InfoLevel
-	case DebugLevel:
-		level = logrus.DebugLevel
-	case TraceLevel:
-		level = logrus.TraceLevel
-	default:
-		level = logrus.InfoLevel
-	}
-
-	logger.SetLevel(level)
+	logger.SetLevel(ParseLevel(logLevel))
 
 	// set output
 	if logPath != "" {
@@ -88,3 +78,15 @@ func InitLogger(logPath string, logLevel string) (*logrus.Logger, error) {
 
 	return logger, nil
 }
+
+// ParseLevel converts log level to logrus.Level, unknown levels are converted to info level
+func ParseLevel(logLevel string) logrus.Level {
+	switch strings.ToLower(logLevel) {
+	case DebugLevel:
+		return logrus.DebugLevel
+	case TraceLevel:
+		return logrus.TraceLevel
+	default:
+		return logrus.InfoLevel
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -55,3 +56,9 @@ func TestInitLoggerWrongPath(t *testing.T) {
 
 	assert.Equal(t, logger.Out, os.Stdout, "Logger's defalut output should be set to the stdout")
 }
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, logrus.DebugLevel, ParseLevel("DEBUG"))
+	assert.Equal(t, logrus.TraceLevel, ParseLevel(TraceLevel))
+	assert.Equal(t, logrus.InfoLevel, ParseLevel("unknown"))
+}
@@ -0,0 +1,256 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lvmbackup archives LVM metadata of volume groups of node into Secret and restores it,
+// so corruption of VG metadata on drives doesn't mean loss of all LVs of the volume group
+package lvmbackup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lvm"
+)
+
+const (
+	// SecretPrefix is a prefix of name of Secret with LVM metadata backups of node, suffix is node name
+	SecretPrefix = "lvm-metadata-"
+	// keySeparator separates VG name and metadata sequence number in key of Secret data
+	keySeparator = "."
+)
+
+// ErrNotFound is returned when there is no backup of volume group metadata which can be restored
+var ErrNotFound = errors.New("LVM metadata backup isn't found")
+
+// Archive keeps last backups of metadata of volume groups of node in Secret,
+// each backup is stored in key <VG name>.<metadata seqno>
+type Archive struct {
+	k8sClient  *k8s.KubeClient
+	lvmOps     lvm.WrapLVM
+	secretName string
+	// number of kept backups per volume group
+	history int
+	// seqNo holds sequence number of archived metadata per volume group
+	seqNo map[string]int64
+	log   *logrus.Entry
+}
+
+// NewArchive is a constructor for Archive
+// Receives number of backups which are kept per volume group
+func NewArchive(k8sClient *k8s.KubeClient, lvmOps lvm.WrapLVM, nodeName string, history int,
+	logger *logrus.Logger) *Archive {
+	return &Archive{
+		k8sClient:  k8sClient,
+		lvmOps:     lvmOps,
+		secretName: SecretPrefix + nodeName,
+		history:    history,
+		seqNo:      map[string]int64{},
+		log:        logger.WithField("component", "LVMBackup"),
+	}
+}
+
+// Backup archives metadata of volume groups which was changed since previous backup. Metadata of VG which
+// can't be read isn't archived, so the last good backup isn't rotated out. Backups of other VGs are removed
+// Receives names of volume groups of node
+// Returns error if Secret can't be read or saved
+func (a *Archive) Backup(ctx context.Context, vgs []string) error {
+	ll := a.log.WithField("method", "Backup")
+
+	secret, exists, err := a.readSecret(ctx)
+	if err != nil {
+		return err
+	}
+	changed := false
+	current := make(map[string]bool, len(vgs))
+	for _, vg := range vgs {
+		current[vg] = true
+		seqNo, err := a.lvmOps.GetVGSeqNo(vg)
+		if err != nil {
+			ll.Errorf("Unable to read metadata seqno of VG %s: %v", vg, err)
+			continue
+		}
+		if a.seqNo[vg] == seqNo {
+			continue
+		}
+		// backup was made before restart
+		if _, ok := secret.Data[backupKey(vg, seqNo)]; ok {
+			a.seqNo[vg] = seqNo
+			continue
+		}
+		metadata, err := a.dumpMetadata(vg)
+		if err != nil {
+			ll.Errorf("Unable to backup metadata of VG %s: %v", vg, err)
+			continue
+		}
+		secret.Data[backupKey(vg, seqNo)] = metadata
+		a.rotate(secret, vg)
+		a.seqNo[vg] = seqNo
+		changed = true
+		ll.Infof("Metadata of VG %s with seqno %d is archived", vg, seqNo)
+	}
+
+	// volume groups which were removed
+	for key := range secret.Data {
+		if vg, _, ok := parseBackupKey(key); ok && !current[vg] {
+			delete(secret.Data, key)
+			delete(a.seqNo, vg)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if exists {
+		err = a.k8sClient.Update(ctx, secret)
+	} else {
+		err = a.k8sClient.Create(ctx, secret)
+	}
+	if err != nil {
+		// backups are made again next time
+		a.seqNo = map[string]int64{}
+		return fmt.Errorf("unable to save Secret %s: %w", a.secretName, err)
+	}
+	return nil
+}
+
+// Restore writes archived metadata of volume group to the drives with vgcfgrestore
+// Receives name of volume group and metadata seqno of backup, the latest backup is restored if seqNo is 0
+// Returns seqno of restored backup or error if backup isn't found or can't be restored
+func (a *Archive) Restore(ctx context.Context, vg string, seqNo int64) (int64, error) {
+	secret, _, err := a.readSecret(ctx)
+	if err != nil {
+		return 0, err
+	}
+	versions := backupVersions(secret, vg)
+	if len(versions) == 0 {
+		return 0, fmt.Errorf("VG %s: %w", vg, ErrNotFound)
+	}
+	if seqNo == 0 {
+		seqNo = versions[len(versions)-1]
+	}
+	metadata, ok := secret.Data[backupKey(vg, seqNo)]
+	if !ok {
+		return 0, fmt.Errorf("VG %s with seqno %d: %w", vg, seqNo, ErrNotFound)
+	}
+
+	file, err := ioutil.TempFile("", SecretPrefix)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = os.Remove(file.Name())
+	}()
+	if _, err = file.Write(metadata); err != nil {
+		_ = file.Close()
+		return 0, err
+	}
+	if err = file.Close(); err != nil {
+		return 0, err
+	}
+	if err = a.lvmOps.VGCfgRestore(vg, file.Name()); err != nil {
+		return 0, err
+	}
+	return seqNo, nil
+}
+
+// readSecret reads Secret with backups, empty Secret is returned if it doesn't exist
+// Returns Secret, whether it exists or error if it can't be read
+func (a *Archive) readSecret(ctx context.Context) (*coreV1.Secret, bool, error) {
+	secret := &coreV1.Secret{}
+	err := a.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: a.secretName, Namespace: a.k8sClient.Namespace}, secret)
+	switch {
+	case k8sError.IsNotFound(err):
+		return &coreV1.Secret{
+			ObjectMeta: metaV1.ObjectMeta{Name: a.secretName, Namespace: a.k8sClient.Namespace},
+			Type:       coreV1.SecretTypeOpaque,
+			Data:       map[string][]byte{},
+		}, false, nil
+	case err != nil:
+		return nil, false, fmt.Errorf("unable to read Secret %s: %w", a.secretName, err)
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	return secret, true, nil
+}
+
+// dumpMetadata returns metadata of volume group in vgcfgbackup format
+func (a *Archive) dumpMetadata(vg string) ([]byte, error) {
+	dir, err := ioutil.TempDir("", SecretPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	file := filepath.Join(dir, vg)
+	if err = a.lvmOps.VGCfgBackup(vg, file); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(file)
+}
+
+// rotate removes the oldest backups of volume group which exceed history
+func (a *Archive) rotate(secret *coreV1.Secret, vg string) {
+	versions := backupVersions(secret, vg)
+	for i := 0; i < len(versions)-a.history; i++ {
+		delete(secret.Data, backupKey(vg, versions[i]))
+	}
+}
+
+// backupVersions returns sorted seqno of archived metadata of volume group
+func backupVersions(secret *coreV1.Secret, vg string) []int64 {
+	var versions []int64
+	for key := range secret.Data {
+		if name, seqNo, ok := parseBackupKey(key); ok && name == vg {
+			versions = append(versions, seqNo)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+// backupKey returns key of Secret data with metadata of volume group
+func backupKey(vg string, seqNo int64) string {
+	return vg + keySeparator + strconv.FormatInt(seqNo, 10)
+}
+
+// parseBackupKey returns VG name and metadata seqno from key of Secret data
+func parseBackupKey(key string) (string, int64, bool) {
+	idx := strings.LastIndex(key, keySeparator)
+	if idx <= 0 {
+		return "", 0, false
+	}
+	seqNo, err := strconv.ParseInt(key[idx+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return key[:idx], seqNo, true
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lvmbackup
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	coreV1 "k8s.io/api/core/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+var (
+	testCtx    = context.Background()
+	testLogger = logrus.New()
+	testNs     = "default"
+	testNode   = "node-1"
+)
+
+func TestArchive_BackupAndRestore(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	var (
+		lvmOps     = &mocklu.MockWrapLVM{}
+		archive    = NewArchive(kubeClient, lvmOps, testNode, 2, testLogger)
+		secret     = &coreV1.Secret{}
+		restored   string
+		readSecret = func() {
+			// decoding into the existing object keeps keys of its Data map
+			*secret = coreV1.Secret{}
+			assert.Nil(t, kubeClient.Get(testCtx, k8sCl.ObjectKey{Name: SecretPrefix + testNode, Namespace: testNs},
+				secret))
+		}
+		backup = func(vg string, seqNo int64) {
+			lvmOps.On("GetVGSeqNo", vg).Return(seqNo, nil).Once()
+			lvmOps.On("VGCfgBackup", vg, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				assert.Nil(t, ioutil.WriteFile(args.String(1), []byte(vg+" seqno "+strconv.FormatInt(seqNo, 10)), 0600))
+			}).Once()
+		}
+	)
+
+	backup("vg-1", 1)
+	backup("vg-2", 1)
+	assert.Nil(t, archive.Backup(testCtx, []string{"vg-1", "vg-2"}))
+	readSecret()
+	assert.Equal(t, map[string][]byte{"vg-1.1": []byte("vg-1 seqno 1"), "vg-2.1": []byte("vg-2 seqno 1")},
+		secret.Data)
+
+	// metadata of vg-2 is corrupted, its backup is kept, only history of vg-1 is kept
+	backup("vg-1", 2)
+	lvmOps.On("GetVGSeqNo", "vg-2").Return(int64(0), errors.New("checksum error")).Once()
+	assert.Nil(t, archive.Backup(testCtx, []string{"vg-1", "vg-2"}))
+	backup("vg-1", 3)
+	lvmOps.On("GetVGSeqNo", "vg-2").Return(int64(0), errors.New("checksum error")).Once()
+	assert.Nil(t, archive.Backup(testCtx, []string{"vg-1", "vg-2"}))
+	readSecret()
+	assert.Len(t, secret.Data, 3)
+	assert.Equal(t, []int64{2, 3}, backupVersions(secret, "vg-1"))
+
+	// metadata isn't changed
+	lvmOps.On("GetVGSeqNo", "vg-1").Return(int64(3), nil).Once()
+	assert.Nil(t, archive.Backup(testCtx, []string{"vg-1"}))
+	lvmOps.AssertNumberOfCalls(t, "VGCfgBackup", 4)
+	// vg-2 was removed
+	readSecret()
+	assert.Equal(t, []int64{2, 3}, backupVersions(secret, "vg-1"))
+	assert.Empty(t, backupVersions(secret, "vg-2"))
+
+	// node service was restarted
+	archive = NewArchive(kubeClient, lvmOps, testNode, 2, testLogger)
+	lvmOps.On("GetVGSeqNo", "vg-1").Return(int64(3), nil).Once()
+	assert.Nil(t, archive.Backup(testCtx, []string{"vg-1"}))
+	lvmOps.AssertNumberOfCalls(t, "VGCfgBackup", 4)
+
+	lvmOps.On("VGCfgRestore", "vg-1", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		data, err := ioutil.ReadFile(args.String(1))
+		assert.Nil(t, err)
+		restored = string(data)
+	})
+	seqNo, err := archive.Restore(testCtx, "vg-1", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), seqNo)
+	assert.Equal(t, "vg-1 seqno 3", restored)
+	seqNo, err = archive.Restore(testCtx, "vg-1", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), seqNo)
+	assert.Equal(t, "vg-1 seqno 2", restored)
+
+	_, err = archive.Restore(testCtx, "vg-1", 1)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = archive.Restore(testCtx, "vg-2", 0)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestParseBackupKey(t *testing.T) {
+	vg, seqNo, ok := parseBackupKey("centos_root.vg.12")
+	assert.True(t, ok)
+	assert.Equal(t, "centos_root.vg", vg)
+	assert.Equal(t, int64(12), seqNo)
+
+	for _, key := range []string{"vg", ".12", "vg.latest"} {
+		_, _, ok = parseBackupKey(key)
+		assert.False(t, ok)
+	}
+}
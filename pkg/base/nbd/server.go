@@ -0,0 +1,550 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nbd contains read-only server of network block device (NBD) protocol which exports block devices
+// for inspection by NBD clients, e.g. nbd-client or qemu-nbd. Only fixed newstyle negotiation is supported,
+// clients must upgrade connection to mutual TLS with NBD_OPT_STARTTLS and are authorized per export
+package nbd
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	basenet "github.com/dell/csi-baremetal/pkg/base/net"
+)
+
+// Protocol constants, see https://github.com/NetworkBlockDevice/nbd/blob/master/doc/proto.md
+const (
+	nbdMagic         uint64 = 0x4e42444d41474943 // "NBDMAGIC"
+	optionMagic      uint64 = 0x49484156454F5054 // "IHAVEOPT"
+	optionReplyMagic uint64 = 0x3e889045565a9
+	requestMagic     uint32 = 0x25609513
+	simpleReplyMagic uint32 = 0x67446698
+
+	flagFixedNewstyle  uint16 = 1 << 0
+	flagNoZeroes       uint16 = 1 << 1
+	clientFlagNoZeroes uint32 = 1 << 1
+
+	optExportName uint32 = 1
+	optAbort      uint32 = 2
+	optList       uint32 = 3
+	optStartTLS   uint32 = 5
+	optInfo       uint32 = 6
+	optGo         uint32 = 7
+
+	repAck        uint32 = 1
+	repServer     uint32 = 2
+	repInfo       uint32 = 3
+	repErrUnsup   uint32 = 1<<31 + 1
+	repErrPolicy  uint32 = 1<<31 + 2
+	repErrInvalid uint32 = 1<<31 + 3
+	repErrTLSReqd uint32 = 1<<31 + 5
+	repErrUnknown uint32 = 1<<31 + 6
+
+	infoExport uint16 = 0
+
+	transmissionFlagHasFlags     uint16 = 1 << 0
+	transmissionFlagReadOnly     uint16 = 1 << 1
+	transmissionFlagCanMultiConn uint16 = 1 << 8
+
+	cmdRead        uint16 = 0
+	cmdWrite       uint16 = 1
+	cmdDisc        uint16 = 2
+	cmdFlush       uint16 = 3
+	cmdTrim        uint16 = 4
+	cmdWriteZeroes uint16 = 6
+
+	errPerm  uint32 = 1
+	errIO    uint32 = 5
+	errInval uint32 = 22
+
+	// maxOptionLength limits length of option data sent by client
+	maxOptionLength = 4096
+	// MaxReadLength is a max length of data which is read by one request
+	MaxReadLength = 32 * 1024 * 1024
+	// zeroesLength is a length of padding after export info of NBD_OPT_EXPORT_NAME
+	zeroesLength = 124
+)
+
+// Device is a block device or its snapshot which is exported read-only
+type Device interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// ErrExportExists is returned by AddExport if export with the same name is already added
+var ErrExportExists = errors.New("export already exists")
+
+// export is a device which is exported by name, identities of clients which are allowed to read it
+// and connections of clients which use it
+type export struct {
+	device  Device
+	size    int64
+	clients map[string]bool
+	conns   map[net.Conn]struct{}
+}
+
+// Server serves exports to NBD clients, exports are added and removed while server is running
+type Server struct {
+	mu      sync.Mutex
+	exports map[string]*export
+	// TLS config which requires and verifies client certificates
+	tlsConfig *tls.Config
+	log       *logrus.Entry
+}
+
+// NewServer is a constructor for Server
+// Receives TLS config which must require and verify client certificates, e.g. from basenet.TLSOptions with CA
+func NewServer(tlsConfig *tls.Config, logger *logrus.Logger) *Server {
+	return &Server{
+		exports:   map[string]*export{},
+		tlsConfig: tlsConfig,
+		log:       logger.WithField("component", "NBDServer"),
+	}
+}
+
+// AddExport exports device of size bytes with name, server takes ownership of device and closes it on removal.
+// Export is visible only to clients which certificate has common name or DNS name from clients
+// Returns ErrExportExists if export with name is already added
+func (s *Server) AddExport(name string, device Device, size int64, clients []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.exports[name]; ok {
+		return fmt.Errorf("%s: %w", name, ErrExportExists)
+	}
+	allowed := make(map[string]bool, len(clients))
+	for _, client := range clients {
+		allowed[client] = true
+	}
+	s.exports[name] = &export{device: device, size: size, clients: allowed, conns: map[net.Conn]struct{}{}}
+	s.log.Infof("Export %s of size %d is added for clients %v", name, size, clients)
+	return nil
+}
+
+// RemoveExport closes connections of clients which use export with name and closes its device
+// Returns error of device close, removal of unknown export is ignored
+func (s *Server) RemoveExport(name string) error {
+	s.mu.Lock()
+	exp, ok := s.exports[name]
+	delete(s.exports, name)
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	for conn := range exp.conns {
+		_ = conn.Close()
+	}
+	s.log.Infof("Export %s is removed", name)
+	return exp.device.Close()
+}
+
+// Exports returns sorted names of exports
+func (s *Server) Exports() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.exports))
+	for name := range s.exports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ListenAndServe serves clients on TCP address until error occurs
+func (s *Server) ListenAndServe(address string) error {
+	listener, err := basenet.Listen(address)
+	if err != nil {
+		return err
+	}
+	s.log.Infof("Starting NBD server on %s", address)
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on listener and serves them in background until error occurs
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn negotiates export with client and serves its requests until client disconnects or export is removed
+func (s *Server) ServeConn(conn net.Conn) {
+	ll := s.log.WithFields(logrus.Fields{
+		"method": "ServeConn",
+		"remote": conn.RemoteAddr().String(),
+	})
+	defer func() {
+		// connection is replaced by TLS connection during negotiation
+		_ = conn.Close()
+	}()
+
+	conn, name, exp, err := s.negotiate(conn)
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			ll.Warnf("Negotiation failed: %v", err)
+		}
+		return
+	}
+	if exp == nil {
+		return
+	}
+	ll.Infof("Client is connected to export %s", name)
+	err = s.transmit(conn, exp)
+	s.mu.Lock()
+	delete(exp.conns, conn)
+	s.mu.Unlock()
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+		ll.Warnf("Transmission of export %s failed: %v", name, err)
+		return
+	}
+	ll.Infof("Client is disconnected from export %s", name)
+}
+
+// negotiate performs handshake, upgrades connection to TLS and handles options of client
+// Returns connection, name and export which client selected, nil export if client aborted negotiation
+func (s *Server) negotiate(conn net.Conn) (net.Conn, string, *export, error) {
+	if err := write(conn, nbdMagic, optionMagic, flagFixedNewstyle|flagNoZeroes); err != nil {
+		return conn, "", nil, err
+	}
+	var clientFlags uint32
+	if err := binary.Read(conn, binary.BigEndian, &clientFlags); err != nil {
+		return conn, "", nil, err
+	}
+	noZeroes := clientFlags&clientFlagNoZeroes != 0
+
+	// identities of client from its certificate, nil until TLS is established
+	var identities []string
+	for {
+		var header struct {
+			Magic  uint64
+			Option uint32
+			Length uint32
+		}
+		if err := binary.Read(conn, binary.BigEndian, &header); err != nil {
+			return conn, "", nil, err
+		}
+		if header.Magic != optionMagic {
+			return conn, "", nil, fmt.Errorf("wrong option magic %x", header.Magic)
+		}
+		if header.Length > maxOptionLength {
+			return conn, "", nil, fmt.Errorf("option %d is too long: %d bytes", header.Option, header.Length)
+		}
+		data := make([]byte, header.Length)
+		if _, err := io.ReadFull(conn, data); err != nil {
+			return conn, "", nil, err
+		}
+
+		if identities == nil {
+			switch header.Option {
+			case optStartTLS, optAbort:
+			case optExportName:
+				return conn, "", nil, errors.New("client selected export without TLS")
+			default:
+				if err := writeOptionReply(conn, header.Option, repErrTLSReqd, nil); err != nil {
+					return conn, "", nil, err
+				}
+				continue
+			}
+		}
+
+		switch header.Option {
+		case optStartTLS:
+			if identities != nil || len(data) > 0 {
+				if err := writeOptionReply(conn, header.Option, repErrInvalid, nil); err != nil {
+					return conn, "", nil, err
+				}
+				continue
+			}
+			if err := writeOptionReply(conn, header.Option, repAck, nil); err != nil {
+				return conn, "", nil, err
+			}
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return conn, "", nil, fmt.Errorf("TLS handshake failed: %w", err)
+			}
+			conn = tlsConn
+			if identities = clientIdentities(tlsConn.ConnectionState()); len(identities) == 0 {
+				return conn, "", nil, errors.New("client certificate has no common name or DNS names")
+			}
+		case optExportName:
+			name := string(data)
+			exp, _ := s.attach(name, identities, conn)
+			if exp == nil {
+				// there is no way to report error for this option, client sees closed connection
+				return conn, "", nil, fmt.Errorf("export %s isn't found or isn't allowed for %v", name, identities)
+			}
+			if err := write(conn, uint64(exp.size), transmissionFlags()); err != nil {
+				return conn, "", nil, err
+			}
+			if !noZeroes {
+				if _, err := conn.Write(make([]byte, zeroesLength)); err != nil {
+					return conn, "", nil, err
+				}
+			}
+			return conn, name, exp, nil
+		case optAbort:
+			_ = writeOptionReply(conn, header.Option, repAck, nil)
+			return conn, "", nil, nil
+		case optList:
+			if err := s.replyList(conn, header.Option, identities); err != nil {
+				return conn, "", nil, err
+			}
+		case optInfo, optGo:
+			name, ok := parseInfoRequest(data)
+			if !ok {
+				if err := writeOptionReply(conn, header.Option, repErrInvalid, nil); err != nil {
+					return conn, "", nil, err
+				}
+				continue
+			}
+			size, replyErr := s.exportSize(name, identities)
+			if replyErr != 0 {
+				if err := writeOptionReply(conn, header.Option, replyErr, nil); err != nil {
+					return conn, "", nil, err
+				}
+				continue
+			}
+			info := make([]byte, 12)
+			binary.BigEndian.PutUint16(info[0:], infoExport)
+			binary.BigEndian.PutUint64(info[2:], uint64(size))
+			binary.BigEndian.PutUint16(info[10:], transmissionFlags())
+			if err := writeOptionReply(conn, header.Option, repInfo, info); err != nil {
+				return conn, "", nil, err
+			}
+			if header.Option == optInfo {
+				if err := writeOptionReply(conn, header.Option, repAck, nil); err != nil {
+					return conn, "", nil, err
+				}
+				continue
+			}
+			exp, replyErr := s.attach(name, identities, conn)
+			if exp == nil {
+				if err := writeOptionReply(conn, header.Option, replyErr, nil); err != nil {
+					return conn, "", nil, err
+				}
+				continue
+			}
+			if err := writeOptionReply(conn, header.Option, repAck, nil); err != nil {
+				return conn, "", nil, err
+			}
+			return conn, name, exp, nil
+		default:
+			// structured replies aren't supported
+			if err := writeOptionReply(conn, header.Option, repErrUnsup, nil); err != nil {
+				return conn, "", nil, err
+			}
+		}
+	}
+}
+
+// clientIdentities returns common name and DNS names of verified client certificate
+func clientIdentities(state tls.ConnectionState) []string {
+	if len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	cert := state.PeerCertificates[0]
+	var identities []string
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	return append(identities, cert.DNSNames...)
+}
+
+// allows checks whether client with one of identities is allowed to read export
+func (e *export) allows(identities []string) bool {
+	for _, identity := range identities {
+		if e.clients[identity] {
+			return true
+		}
+	}
+	return false
+}
+
+// transmit serves requests of client to export, writes are rejected
+func (s *Server) transmit(conn net.Conn, exp *export) error {
+	for {
+		var req struct {
+			Magic  uint32
+			Flags  uint16
+			Type   uint16
+			Handle uint64
+			Offset uint64
+			Length uint32
+		}
+		if err := binary.Read(conn, binary.BigEndian, &req); err != nil {
+			return err
+		}
+		if req.Magic != requestMagic {
+			return fmt.Errorf("wrong request magic %x", req.Magic)
+		}
+
+		switch req.Type {
+		case cmdRead:
+			if req.Length > MaxReadLength || req.Offset > uint64(exp.size) ||
+				uint64(req.Length) > uint64(exp.size)-req.Offset {
+				if err := write(conn, simpleReplyMagic, errInval, req.Handle); err != nil {
+					return err
+				}
+				continue
+			}
+			buf := make([]byte, req.Length)
+			if _, err := exp.device.ReadAt(buf, int64(req.Offset)); err != nil && !errors.Is(err, io.EOF) {
+				if err := write(conn, simpleReplyMagic, errIO, req.Handle); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := write(conn, simpleReplyMagic, uint32(0), req.Handle); err != nil {
+				return err
+			}
+			if _, err := conn.Write(buf); err != nil {
+				return err
+			}
+		case cmdDisc:
+			return nil
+		case cmdFlush:
+			if err := write(conn, simpleReplyMagic, uint32(0), req.Handle); err != nil {
+				return err
+			}
+		case cmdWrite:
+			// payload of write must be consumed to keep stream in sync
+			if _, err := io.CopyN(ioutil.Discard, conn, int64(req.Length)); err != nil {
+				return err
+			}
+			if err := write(conn, simpleReplyMagic, errPerm, req.Handle); err != nil {
+				return err
+			}
+		case cmdTrim, cmdWriteZeroes:
+			if err := write(conn, simpleReplyMagic, errPerm, req.Handle); err != nil {
+				return err
+			}
+		default:
+			if err := write(conn, simpleReplyMagic, errInval, req.Handle); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// attach registers connection of client in export with name, so connection is closed when export is removed
+// Returns export or nil and reply error if export isn't found or client isn't allowed to read it
+func (s *Server) attach(name string, identities []string, conn net.Conn) (*export, uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp, ok := s.exports[name]
+	if !ok {
+		return nil, repErrUnknown
+	}
+	if !exp.allows(identities) {
+		return nil, repErrPolicy
+	}
+	exp.conns[conn] = struct{}{}
+	return exp, 0
+}
+
+// exportSize returns size of export with name or reply error if export isn't found or client isn't allowed to read it
+func (s *Server) exportSize(name string, identities []string) (int64, uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp, ok := s.exports[name]
+	if !ok {
+		return 0, repErrUnknown
+	}
+	if !exp.allows(identities) {
+		return 0, repErrPolicy
+	}
+	return exp.size, 0
+}
+
+// replyList sends names of exports which client is allowed to read as reply to NBD_OPT_LIST
+func (s *Server) replyList(conn net.Conn, option uint32, identities []string) error {
+	s.mu.Lock()
+	var names []string
+	for name, exp := range s.exports {
+		if exp.allows(identities) {
+			names = append(names, name)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(names)
+	for _, name := range names {
+		data := make([]byte, 4+len(name))
+		binary.BigEndian.PutUint32(data, uint32(len(name)))
+		copy(data[4:], name)
+		if err := writeOptionReply(conn, option, repServer, data); err != nil {
+			return err
+		}
+	}
+	return writeOptionReply(conn, option, repAck, nil)
+}
+
+// parseInfoRequest parses data of NBD_OPT_INFO and NBD_OPT_GO: length of name, name and list of info requests
+// Returns export name and flag whether data is valid, info requests are ignored because only export info is sent
+func parseInfoRequest(data []byte) (string, bool) {
+	if len(data) < 6 {
+		return "", false
+	}
+	nameLength := binary.BigEndian.Uint32(data)
+	if uint64(nameLength)+6 > uint64(len(data)) {
+		return "", false
+	}
+	name := string(data[4 : 4+nameLength])
+	count := binary.BigEndian.Uint16(data[4+nameLength:])
+	if int(count)*2 != len(data)-6-int(nameLength) {
+		return "", false
+	}
+	return name, true
+}
+
+// transmissionFlags returns flags of read-only export
+func transmissionFlags() uint16 {
+	return transmissionFlagHasFlags | transmissionFlagReadOnly | transmissionFlagCanMultiConn
+}
+
+// writeOptionReply sends reply to option of client
+func writeOptionReply(w io.Writer, option, replyType uint32, data []byte) error {
+	if err := write(w, optionReplyMagic, option, replyType, uint32(len(data))); err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// write sends values in network byte order
+func write(w io.Writer, values ...interface{}) error {
+	for _, value := range values {
+		if err := binary.Write(w, binary.BigEndian, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nbd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// testDevice is an exported device which records whether it is closed
+type testDevice struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (d *testDevice) Close() error {
+	d.closed = true
+	return nil
+}
+
+// testClient is a client side of connection to server
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+}
+
+func newTestClient(t *testing.T, s *Server) *testClient {
+	server, client := net.Pipe()
+	go s.ServeConn(server)
+	c := &testClient{t: t, conn: client}
+	var handshake struct {
+		NBDMagic    uint64
+		OptionMagic uint64
+		Flags       uint16
+	}
+	assert.Nil(t, binary.Read(client, binary.BigEndian, &handshake))
+	assert.Equal(t, nbdMagic, handshake.NBDMagic)
+	assert.Equal(t, optionMagic, handshake.OptionMagic)
+	assert.Equal(t, flagFixedNewstyle|flagNoZeroes, handshake.Flags)
+	assert.Nil(t, write(client, clientFlagNoZeroes))
+	return c
+}
+
+// startTLS upgrades connection to TLS with client certificate
+func (c *testClient) startTLS(cert tls.Certificate, serverCA *x509.CertPool) {
+	c.option(optStartTLS, nil)
+	replyType, _ := c.optionReply(optStartTLS)
+	assert.Equal(c.t, repAck, replyType)
+	conn := tls.Client(c.conn, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      serverCA,
+		ServerName:   "localhost",
+		MinVersion:   tls.VersionTLS12,
+	})
+	assert.Nil(c.t, conn.Handshake())
+	c.conn = conn
+}
+
+func (c *testClient) option(option uint32, data []byte) {
+	assert.Nil(c.t, write(c.conn, optionMagic, option, uint32(len(data))))
+	if len(data) > 0 {
+		_, err := c.conn.Write(data)
+		assert.Nil(c.t, err)
+	}
+}
+
+func (c *testClient) optionReply(option uint32) (uint32, []byte) {
+	var reply struct {
+		Magic  uint64
+		Option uint32
+		Type   uint32
+		Length uint32
+	}
+	assert.Nil(c.t, binary.Read(c.conn, binary.BigEndian, &reply))
+	assert.Equal(c.t, optionReplyMagic, reply.Magic)
+	assert.Equal(c.t, option, reply.Option)
+	data := make([]byte, reply.Length)
+	_, err := io.ReadFull(c.conn, data)
+	assert.Nil(c.t, err)
+	return reply.Type, data
+}
+
+func (c *testClient) request(cmd uint16, offset uint64, length uint32, payload []byte) (uint32, []byte) {
+	assert.Nil(c.t, write(c.conn, requestMagic, uint16(0), cmd, uint64(42), offset, length))
+	if len(payload) > 0 {
+		_, err := c.conn.Write(payload)
+		assert.Nil(c.t, err)
+	}
+	var reply struct {
+		Magic  uint32
+		Error  uint32
+		Handle uint64
+	}
+	assert.Nil(c.t, binary.Read(c.conn, binary.BigEndian, &reply))
+	assert.Equal(c.t, simpleReplyMagic, reply.Magic)
+	assert.Equal(c.t, uint64(42), reply.Handle)
+	if cmd != cmdRead || reply.Error != 0 {
+		return reply.Error, nil
+	}
+	data := make([]byte, length)
+	_, err := io.ReadFull(c.conn, data)
+	assert.Nil(c.t, err)
+	return reply.Error, data
+}
+
+func infoRequest(name string) []byte {
+	data := make([]byte, 4+len(name)+2)
+	binary.BigEndian.PutUint32(data, uint32(len(name)))
+	copy(data[4:], name)
+	return data
+}
+
+// selfSignedCert generates self-signed certificate with common name which is valid for localhost
+func selfSignedCert(t *testing.T, commonName string) (tls.Certificate, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, cert
+}
+
+// testCerts are certificates of server, allowed client "backup" and not allowed client "other"
+type testCerts struct {
+	serverCA *x509.CertPool
+	backup   tls.Certificate
+	other    tls.Certificate
+}
+
+func prepareServer(t *testing.T) (*Server, *testDevice, []byte, testCerts) {
+	content := make([]byte, 8192)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	device := &testDevice{Reader: bytes.NewReader(content)}
+
+	serverCert, serverX509 := selfSignedCert(t, "csi-baremetal-node")
+	backupCert, backupX509 := selfSignedCert(t, "backup")
+	otherCert, otherX509 := selfSignedCert(t, "other")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(backupX509)
+	clientCAs.AddCert(otherX509)
+	certs := testCerts{serverCA: x509.NewCertPool(), backup: backupCert, other: otherCert}
+	certs.serverCA.AddCert(serverX509)
+
+	s := NewServer(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}, logrus.New())
+	assert.Nil(t, s.AddExport("pvc-1", device, int64(len(content)), []string{"backup"}))
+	return s, device, content, certs
+}
+
+func TestServer_Exports(t *testing.T) {
+	s, device, _, _ := prepareServer(t)
+	assert.ErrorIs(t, s.AddExport("pvc-1", device, 1, nil), ErrExportExists)
+	assert.Nil(t, s.AddExport("pvc-0", &testDevice{}, 1, nil))
+	assert.Equal(t, []string{"pvc-0", "pvc-1"}, s.Exports())
+
+	assert.Nil(t, s.RemoveExport("pvc-1"))
+	assert.True(t, device.closed)
+	assert.Nil(t, s.RemoveExport("pvc-1"))
+	assert.Equal(t, []string{"pvc-0"}, s.Exports())
+}
+
+func TestServer_Negotiation(t *testing.T) {
+	s, _, _, certs := prepareServer(t)
+	c := newTestClient(t, s)
+	defer c.conn.Close()
+
+	// TLS is required before any option except abort
+	c.option(optList, nil)
+	replyType, _ := c.optionReply(optList)
+	assert.Equal(t, repErrTLSReqd, replyType)
+	c.option(optGo, infoRequest("pvc-1"))
+	replyType, _ = c.optionReply(optGo)
+	assert.Equal(t, repErrTLSReqd, replyType)
+
+	c.startTLS(certs.backup, certs.serverCA)
+
+	c.option(optList, nil)
+	replyType, data := c.optionReply(optList)
+	assert.Equal(t, repServer, replyType)
+	assert.Equal(t, append([]byte{0, 0, 0, 5}, "pvc-1"...), data)
+	replyType, _ = c.optionReply(optList)
+	assert.Equal(t, repAck, replyType)
+
+	c.option(optInfo, infoRequest("pvc-2"))
+	replyType, _ = c.optionReply(optInfo)
+	assert.Equal(t, repErrUnknown, replyType)
+
+	c.option(optInfo, []byte{0, 0})
+	replyType, _ = c.optionReply(optInfo)
+	assert.Equal(t, repErrInvalid, replyType)
+
+	// TLS is already established
+	c.option(optStartTLS, nil)
+	replyType, _ = c.optionReply(optStartTLS)
+	assert.Equal(t, repErrInvalid, replyType)
+
+	c.option(optGo, infoRequest("pvc-1"))
+	replyType, data = c.optionReply(optGo)
+	assert.Equal(t, repInfo, replyType)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0x20, 0, 0x1, 0x3}, data)
+	replyType, _ = c.optionReply(optGo)
+	assert.Equal(t, repAck, replyType)
+
+	// server closes connection without reply
+	assert.Nil(t, write(c.conn, requestMagic, uint16(0), cmdDisc, uint64(42), uint64(0), uint32(0)))
+	_, err := c.conn.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestServer_Transmission(t *testing.T) {
+	s, _, content, certs := prepareServer(t)
+	c := newTestClient(t, s)
+	defer c.conn.Close()
+
+	c.startTLS(certs.backup, certs.serverCA)
+	c.option(optExportName, []byte("pvc-1"))
+	var export struct {
+		Size  uint64
+		Flags uint16
+	}
+	assert.Nil(t, binary.Read(c.conn, binary.BigEndian, &export))
+	assert.Equal(t, uint64(len(content)), export.Size)
+	assert.Equal(t, transmissionFlags(), export.Flags)
+
+	errCode, data := c.request(cmdRead, 1000, 4096, nil)
+	assert.Equal(t, uint32(0), errCode)
+	assert.Equal(t, content[1000:5096], data)
+
+	errCode, _ = c.request(cmdRead, 8000, 4096, nil)
+	assert.Equal(t, errInval, errCode)
+
+	errCode, _ = c.request(cmdWrite, 0, 512, make([]byte, 512))
+	assert.Equal(t, errPerm, errCode)
+	errCode, _ = c.request(cmdTrim, 0, 512, nil)
+	assert.Equal(t, errPerm, errCode)
+	errCode, _ = c.request(cmdFlush, 0, 0, nil)
+	assert.Equal(t, uint32(0), errCode)
+
+	// data isn't changed by write
+	errCode, data = c.request(cmdRead, 0, 512, nil)
+	assert.Equal(t, uint32(0), errCode)
+	assert.Equal(t, content[:512], data)
+
+	// client is disconnected when export is removed
+	disconnected := make(chan error)
+	go func() {
+		_, err := c.conn.Read(make([]byte, 1))
+		disconnected <- err
+	}()
+	assert.Nil(t, s.RemoveExport("pvc-1"))
+	assert.Equal(t, io.EOF, <-disconnected)
+}
+
+func TestServer_Authorization(t *testing.T) {
+	s, _, _, certs := prepareServer(t)
+	c := newTestClient(t, s)
+	defer c.conn.Close()
+
+	c.startTLS(certs.other, certs.serverCA)
+
+	// export isn't listed for client which isn't allowed to read it
+	c.option(optList, nil)
+	replyType, _ := c.optionReply(optList)
+	assert.Equal(t, repAck, replyType)
+
+	c.option(optInfo, infoRequest("pvc-1"))
+	replyType, _ = c.optionReply(optInfo)
+	assert.Equal(t, repErrPolicy, replyType)
+	c.option(optGo, infoRequest("pvc-1"))
+	replyType, _ = c.optionReply(optGo)
+	assert.Equal(t, repErrPolicy, replyType)
+
+	// server closes connection without reply
+	c.option(optExportName, []byte("pvc-1"))
+	_, err := c.conn.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+}
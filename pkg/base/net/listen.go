@@ -0,0 +1,293 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables with bind addresses and TLS options of listening endpoints. They are common for all
+// services of the driver, so operator sets them for all containers from the same section of its CR
+const (
+	// BindAddressesEnv holds comma separated IPs and CIDRs, e.g. "10.10.0.0/16,fd00:10::/64". Endpoint without host
+	// is bound to each IP and to each address of node interfaces which belongs to any CIDR
+	BindAddressesEnv = "BIND_ADDRESSES"
+	// TLSCertFileEnv and TLSKeyFileEnv enable TLS of HTTP endpoints
+	TLSCertFileEnv = "TLS_CERT_FILE"
+	TLSKeyFileEnv  = "TLS_KEY_FILE"
+	// TLSClientCAFileEnv enables verification of client certificates of HTTP endpoints
+	TLSClientCAFileEnv = "TLS_CLIENT_CA_FILE"
+	// TLSMinVersionEnv is a minimal TLS version of HTTP endpoints: 1.2 (default) or 1.3
+	TLSMinVersionEnv = "TLS_MIN_VERSION"
+	// TLSCipherSuitesEnv holds comma separated names of TLS 1.2 cipher suites, Go defaults are used if it's empty
+	TLSCipherSuitesEnv = "TLS_CIPHER_SUITES"
+)
+
+const readHeaderTimeout = 10 * time.Second
+
+// ListenConfig describes addresses which endpoints are bound to and TLS options of HTTP endpoints
+type ListenConfig struct {
+	// IPs and CIDRs, empty list means all addresses of both IPv4 and IPv6 families
+	Addresses []string
+	TLS       TLSOptions
+
+	interfaceAddrs func() ([]net.Addr, error)
+}
+
+// ListenConfigFromEnv reads ListenConfig from environment variables
+// Returns error if bind addresses or TLS options are wrong
+func ListenConfigFromEnv() (*ListenConfig, error) {
+	c := &ListenConfig{
+		TLS: TLSOptions{
+			CertFile:     os.Getenv(TLSCertFileEnv),
+			KeyFile:      os.Getenv(TLSKeyFileEnv),
+			ClientCAFile: os.Getenv(TLSClientCAFileEnv),
+			MinVersion:   os.Getenv(TLSMinVersionEnv),
+			CipherSuites: splitList(os.Getenv(TLSCipherSuitesEnv)),
+		},
+		Addresses:      splitList(os.Getenv(BindAddressesEnv)),
+		interfaceAddrs: net.InterfaceAddrs,
+	}
+	for _, addr := range c.Addresses {
+		if net.ParseIP(addr) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(addr); err != nil {
+			return nil, fmt.Errorf("%s contains %s which is neither IP nor CIDR", BindAddressesEnv, addr)
+		}
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return nil, fmt.Errorf("both %s and %s must be set to enable TLS", TLSCertFileEnv, TLSKeyFileEnv)
+	}
+	return c, nil
+}
+
+// Listen creates TCP listener for address host:port. Explicit host is used as is. When host is empty,
+// listener accepts connections on each bind address, loopback addresses are bound as well, so containers
+// of the pod are able to connect to each other. Without bind addresses all addresses of both families are used
+func (c *ListenConfig) Listen(address string) (net.Listener, error) {
+	// the same default as in http.ListenAndServe
+	if address == "" {
+		address = ":http"
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if host != "" || len(c.Addresses) == 0 {
+		return net.Listen("tcp", address)
+	}
+	ips, err := c.bindIPs()
+	if err != nil {
+		return nil, err
+	}
+
+	listeners := make([]net.Listener, 0, len(ips))
+	for _, ip := range ips {
+		l, err := net.Listen("tcp", net.JoinHostPort(ip, port))
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	if len(listeners) == 1 {
+		return listeners[0], nil
+	}
+	return newMultiListener(listeners), nil
+}
+
+// ServeHTTP serves handler on address, DefaultServeMux is used if handler is nil.
+// HTTPS is served if certificate is set in TLS options
+func (c *ListenConfig) ServeHTTP(address string, handler http.Handler) error {
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: readHeaderTimeout}
+	if c.TLS.Enabled() {
+		tlsConfig, err := c.TLS.Config()
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = tlsConfig
+	}
+	listener, err := c.Listen(address)
+	if err != nil {
+		return err
+	}
+	if srv.TLSConfig != nil {
+		return srv.ServeTLS(listener, "", "")
+	}
+	return srv.Serve(listener)
+}
+
+// bindIPs returns IPs from bind addresses, addresses of node interfaces from CIDRs and loopback addresses
+func (c *ListenConfig) bindIPs() ([]string, error) {
+	ifaceAddrs, err := c.interfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read addresses of network interfaces: %w", err)
+	}
+	var (
+		ips  []string
+		seen = map[string]bool{}
+		add  = func(ip net.IP) {
+			if !seen[ip.String()] {
+				seen[ip.String()] = true
+				ips = append(ips, ip.String())
+			}
+		}
+		cidrs []*net.IPNet
+	)
+	for _, addr := range c.Addresses {
+		if ip := net.ParseIP(addr); ip != nil {
+			add(ip)
+			continue
+		}
+		_, cidr, _ := net.ParseCIDR(addr)
+		cidrs = append(cidrs, cidr)
+	}
+	matched := false
+	for _, ifaceAddr := range ifaceAddrs {
+		ipNet, ok := ifaceAddr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		for _, cidr := range cidrs {
+			if cidr.Contains(ipNet.IP) {
+				add(ipNet.IP)
+				matched = true
+			}
+		}
+	}
+	if len(cidrs) > 0 && !matched {
+		return nil, fmt.Errorf("node doesn't have addresses in %s", strings.Join(c.Addresses, ","))
+	}
+	for _, ifaceAddr := range ifaceAddrs {
+		if ipNet, ok := ifaceAddr.(*net.IPNet); ok && ipNet.IP.IsLoopback() {
+			add(ipNet.IP)
+		}
+	}
+	return ips, nil
+}
+
+// Listen creates TCP listener for address with ListenConfig from environment variables
+func Listen(address string) (net.Listener, error) {
+	c, err := ListenConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return c.Listen(address)
+}
+
+// ServeHTTP serves handler on address with ListenConfig from environment variables
+func ServeHTTP(address string, handler http.Handler) error {
+	c, err := ListenConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	return c.ServeHTTP(address, handler)
+}
+
+// multiListener accepts connections from several listeners, e.g. bound to IPv4 and IPv6 addresses
+type multiListener struct {
+	listeners []net.Listener
+	accepted  chan acceptResult
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+func newMultiListener(listeners []net.Listener) *multiListener {
+	m := &multiListener{
+		listeners: listeners,
+		accepted:  make(chan acceptResult),
+		closed:    make(chan struct{}),
+	}
+	for _, l := range listeners {
+		go m.accept(l)
+	}
+	return m
+}
+
+// accept passes connections of listener to Accept until listener fails or multiListener is closed
+func (m *multiListener) accept(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		select {
+		case m.accepted <- acceptResult{conn: conn, err: err}:
+		case <-m.closed:
+			if conn != nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		var netErr net.Error
+		if err != nil && !(errors.As(err, &netErr) && netErr.Temporary()) {
+			return
+		}
+	}
+}
+
+// Accept waits for the next connection on any of listeners
+func (m *multiListener) Accept() (net.Conn, error) {
+	select {
+	case res := <-m.accepted:
+		return res.conn, res.err
+	case <-m.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes all listeners
+func (m *multiListener) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.closed)
+		for _, l := range m.listeners {
+			if closeErr := l.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	})
+	return err
+}
+
+// Addr returns address of the first listener
+func (m *multiListener) Addr() net.Addr {
+	return m.listeners[0].Addr()
+}
+
+// splitList splits comma separated list and removes empty items
+func splitList(str string) []string {
+	var items []string
+	for _, item := range strings.Split(str, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
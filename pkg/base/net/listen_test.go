@@ -0,0 +1,99 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenConfigFromEnv(t *testing.T) {
+	defer func() {
+		_ = os.Unsetenv(BindAddressesEnv)
+		_ = os.Unsetenv(TLSCertFileEnv)
+	}()
+	assert.Nil(t, os.Setenv(BindAddressesEnv, "10.10.0.0/16, fd00:10::1,"))
+	c, err := ListenConfigFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.10.0.0/16", "fd00:10::1"}, c.Addresses)
+	assert.False(t, c.TLS.Enabled())
+
+	assert.Nil(t, os.Setenv(BindAddressesEnv, "node-1"))
+	_, err = ListenConfigFromEnv()
+	assert.NotNil(t, err)
+
+	assert.Nil(t, os.Setenv(BindAddressesEnv, ""))
+	assert.Nil(t, os.Setenv(TLSCertFileEnv, "/certs/tls.crt"))
+	_, err = ListenConfigFromEnv()
+	assert.NotNil(t, err)
+}
+
+func TestListenConfig_Listen(t *testing.T) {
+	var (
+		ifaceAddrs = []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("127.0.0.2"), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("192.0.2.10"), Mask: net.CIDRMask(24, 32)},
+		}
+		c = &ListenConfig{
+			Addresses:      []string{"127.0.0.2/32", "127.0.0.3"},
+			interfaceAddrs: func() ([]net.Addr, error) { return ifaceAddrs, nil },
+		}
+	)
+	ips, err := c.bindIPs()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"127.0.0.3", "127.0.0.2", "127.0.0.1"}, ips)
+
+	c.Addresses = []string{"198.51.100.0/24"}
+	_, err = c.bindIPs()
+	assert.NotNil(t, err)
+
+	// listener accepts connections on all bind addresses
+	c.Addresses = []string{"127.0.0.0/8"}
+	listener, err := c.Listen(":0")
+	assert.Nil(t, err)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	_ = listener.Close()
+	listener, err = c.Listen(":" + port)
+	assert.Nil(t, err)
+	go func() {
+		_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		}))
+	}()
+	for _, ip := range []string{"127.0.0.1", "127.0.0.2"} {
+		resp, err := http.Get("http://" + net.JoinHostPort(ip, port))
+		assert.Nil(t, err)
+		body, _ := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.Equal(t, "ok", string(body))
+	}
+	assert.Nil(t, listener.Close())
+	_, err = listener.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+
+	// explicit host isn't changed
+	listener, err = c.Listen("127.0.0.1:0")
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1", listener.Addr().(*net.TCPAddr).IP.String())
+	assert.Nil(t, listener.Close())
+}
@@ -0,0 +1,226 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Supported values of minimal TLS version
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// TLSOptions are TLS options of endpoints
+type TLSOptions struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	// TLSVersion12 is used if it's empty
+	MinVersion string
+	// names of TLS 1.2 cipher suites, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+	CipherSuites []string
+}
+
+// Enabled returns whether certificate is set
+func (o TLSOptions) Enabled() bool {
+	return o.CertFile != "" && o.KeyFile != ""
+}
+
+// MutualEnabled returns whether certificate and CA are set, so both sides of connections are verified
+func (o TLSOptions) MutualEnabled() bool {
+	return o.Enabled() && o.ClientCAFile != ""
+}
+
+// Config builds TLS config of server from options. Certificate and client CA are read again when files are
+// changed, so certificates which are rotated by cert-manager or operator are picked up without restart
+// Returns error if certificate or CA can't be loaded or options are wrong
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config, err := o.baseConfig()
+	if err != nil {
+		return nil, err
+	}
+	certs := &certificateReloader{certFile: o.CertFile, keyFile: o.KeyFile}
+	if _, err = certs.get(); err != nil {
+		return nil, err
+	}
+	config.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return certs.get()
+	}
+	if o.ClientCAFile == "" {
+		return config, nil
+	}
+
+	cas := &caReloader{caFile: o.ClientCAFile}
+	if _, err = cas.get(); err != nil {
+		return nil, err
+	}
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool, err := cas.get()
+		if err != nil {
+			return nil, err
+		}
+		clientConfig := config.Clone()
+		clientConfig.GetConfigForClient = nil
+		clientConfig.ClientCAs = pool
+		return clientConfig, nil
+	}
+	return config, nil
+}
+
+// ClientConfig builds TLS config of client from options, certificate is presented to server and server
+// certificate is verified with CA from ClientCAFile for serverName. Certificate is read again when files are changed
+// Returns error if certificate or CA can't be loaded or options are wrong
+func (o TLSOptions) ClientConfig(serverName string) (*tls.Config, error) {
+	if !o.MutualEnabled() {
+		return nil, fmt.Errorf("certificate, key and CA must be set for client")
+	}
+	config, err := o.baseConfig()
+	if err != nil {
+		return nil, err
+	}
+	certs := &certificateReloader{certFile: o.CertFile, keyFile: o.KeyFile}
+	if _, err = certs.get(); err != nil {
+		return nil, err
+	}
+	config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return certs.get()
+	}
+	if config.RootCAs, err = (&caReloader{caFile: o.ClientCAFile}).get(); err != nil {
+		return nil, err
+	}
+	config.ServerName = serverName
+	return config, nil
+}
+
+// baseConfig returns TLS config with minimal version and cipher suites
+func (o TLSOptions) baseConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	switch o.MinVersion {
+	case "", TLSVersion12:
+	case TLSVersion13:
+		config.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported minimal TLS version %s, expected %s or %s", o.MinVersion,
+			TLSVersion12, TLSVersion13)
+	}
+	if len(o.CipherSuites) > 0 {
+		suites := make(map[string]uint16)
+		for _, suite := range tls.CipherSuites() {
+			suites[suite.Name] = suite.ID
+		}
+		for _, name := range o.CipherSuites {
+			id, ok := suites[name]
+			if !ok {
+				return nil, fmt.Errorf("unsupported or insecure cipher suite %s", name)
+			}
+			config.CipherSuites = append(config.CipherSuites, id)
+		}
+	}
+	return config, nil
+}
+
+// certificateReloader keeps key pair and loads it again when certificate or key file is modified.
+// Previous key pair is used if new one can't be loaded, e.g. when only one of files is updated yet
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func (r *certificateReloader) get() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err == nil && r.cert != nil && modTime.Equal(r.modTime) {
+		return r.cert, nil
+	}
+	if err == nil {
+		var cert tls.Certificate
+		if cert, err = tls.LoadX509KeyPair(r.certFile, r.keyFile); err == nil {
+			r.cert, r.modTime = &cert, modTime
+			return r.cert, nil
+		}
+	}
+	if r.cert != nil {
+		return r.cert, nil
+	}
+	return nil, fmt.Errorf("unable to load certificate %s: %w", r.certFile, err)
+}
+
+// caReloader keeps CA pool and loads it again when CA file is modified
+type caReloader struct {
+	caFile string
+
+	mu      sync.Mutex
+	pool    *x509.CertPool
+	modTime time.Time
+}
+
+func (r *caReloader) get() (*x509.CertPool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := latestModTime(r.caFile)
+	if err == nil && r.pool != nil && modTime.Equal(r.modTime) {
+		return r.pool, nil
+	}
+	if err == nil {
+		var ca []byte
+		if ca, err = ioutil.ReadFile(filepath.Clean(r.caFile)); err == nil {
+			pool := x509.NewCertPool()
+			if pool.AppendCertsFromPEM(ca) {
+				r.pool, r.modTime = pool, modTime
+				return r.pool, nil
+			}
+			err = fmt.Errorf("file doesn't contain PEM certificates")
+		}
+	}
+	if r.pool != nil {
+		return r.pool, nil
+	}
+	return nil, fmt.Errorf("unable to load CA %s: %w", r.caFile, err)
+}
+
+// latestModTime returns the latest modification time of files, symlinks are followed because
+// kubelet updates mounted secrets by switching symlinks
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
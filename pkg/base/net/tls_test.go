@@ -0,0 +1,185 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTLSOptions_Config(t *testing.T) {
+	dir := t.TempDir()
+	opts := TLSOptions{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "tls.crt"),
+		MinVersion:   TLSVersion13,
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+	}
+	writeSelfSignedCert(t, opts.CertFile, opts.KeyFile)
+	assert.True(t, opts.Enabled())
+
+	assert.True(t, opts.MutualEnabled())
+
+	config, err := opts.Config()
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, config.CipherSuites)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+	cert, err := config.GetCertificate(nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, cert)
+	clientConfig, err := config.GetConfigForClient(nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, clientConfig.ClientCAs)
+
+	config, err = opts.ClientConfig("localhost")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", config.ServerName)
+	assert.NotNil(t, config.RootCAs)
+	cert, err = config.GetClientCertificate(nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, cert)
+	_, err = TLSOptions{CertFile: opts.CertFile, KeyFile: opts.KeyFile}.ClientConfig("localhost")
+	assert.NotNil(t, err)
+
+	for _, wrong := range []TLSOptions{
+		{CertFile: opts.CertFile, KeyFile: opts.KeyFile, MinVersion: "1.1"},
+		{CertFile: opts.CertFile, KeyFile: opts.KeyFile, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		{CertFile: opts.CertFile, KeyFile: opts.KeyFile, ClientCAFile: opts.KeyFile},
+		{CertFile: opts.CertFile, KeyFile: opts.KeyFile, ClientCAFile: filepath.Join(dir, "missing.crt")},
+		{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: opts.KeyFile},
+	} {
+		_, err = wrong.Config()
+		assert.NotNil(t, err)
+	}
+}
+
+func TestTLSOptions_MutualHandshake(t *testing.T) {
+	dir := t.TempDir()
+	opts := TLSOptions{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "tls.crt"),
+	}
+	writeSelfSignedCert(t, opts.CertFile, opts.KeyFile)
+	serverConfig, err := opts.Config()
+	assert.Nil(t, err)
+	clientConfig, err := opts.ClientConfig("localhost")
+	assert.Nil(t, err)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	assert.Nil(t, err)
+	defer func() { _ = listener.Close() }()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_, _ = conn.Write([]byte("ok"))
+			_ = conn.Close()
+		}
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	assert.Nil(t, err)
+	reply, err := ioutil.ReadAll(conn)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", string(reply))
+	_ = conn.Close()
+
+	// client without certificate is rejected
+	clientConfig.GetClientCertificate = nil
+	conn, err = tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err == nil {
+		_, err = ioutil.ReadAll(conn)
+		_ = conn.Close()
+	}
+	assert.NotNil(t, err)
+}
+
+func TestCertificateReloader(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		reloader = &certificateReloader{certFile: filepath.Join(dir, "tls.crt"), keyFile: filepath.Join(dir, "tls.key")}
+	)
+	_, err := reloader.get()
+	assert.NotNil(t, err)
+
+	writeSelfSignedCert(t, reloader.certFile, reloader.keyFile)
+	first, err := reloader.get()
+	assert.Nil(t, err)
+	cert, err := reloader.get()
+	assert.Nil(t, err)
+	assert.True(t, first == cert)
+
+	// rotated certificate is loaded
+	writeSelfSignedCert(t, reloader.certFile, reloader.keyFile)
+	later := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(reloader.certFile, later, later))
+	cert, err = reloader.get()
+	assert.Nil(t, err)
+	assert.False(t, first == cert)
+	assert.False(t, bytes.Equal(first.Certificate[0], cert.Certificate[0]))
+
+	// previous certificate is used until both files are updated
+	rotated := cert
+	assert.Nil(t, ioutil.WriteFile(reloader.keyFile, []byte("partial"), 0600))
+	later = later.Add(time.Minute)
+	assert.Nil(t, os.Chtimes(reloader.keyFile, later, later))
+	cert, err = reloader.get()
+	assert.Nil(t, err)
+	assert.True(t, rotated == cert)
+}
+
+func writeSelfSignedCert(t *testing.T, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "csi-baremetal"},
+		DNSNames:     []string{"localhost"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		0600))
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodeprotocol contains versioning of protocol between controller and node services.
+// Controller creates Volume CRs which are processed by node service of the volume node, during rolling upgrade
+// node service might be older than controller, so node service reports protocol version and capabilities
+// in Node CR and controller doesn't place volumes with features which node service doesn't support.
+package nodeprotocol
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+)
+
+// ErrIncompatibleNode is returned when node service doesn't support protocol version or features of the volume
+var ErrIncompatibleNode = errors.New("incompatible node service")
+
+var (
+	// SupportedVersions are versions of protocol which controller supports
+	SupportedVersions = []string{apiV1.NodeProtocolVersion}
+	// Capabilities are capabilities of node service of this release
+	Capabilities = []string{apiV1.NodeCapabilityEncryption, apiV1.NodeCapabilityLazyFormat,
+		apiV1.NodeCapabilityCache, apiV1.NodeCapabilityTemplate}
+	// LegacyCapabilities are capabilities of node services which don't report protocol version,
+	// they are released before versioning was introduced and don't support any optional feature
+	LegacyCapabilities []string
+)
+
+// Storage class parameters which enable optional features of volume, they match parameters of CreateVolume request
+const (
+	encryptionParameter = "encryption"
+	lazyFormatParameter = "lazyFormat"
+	cacheModeParameter  = "cacheMode"
+	templateParameter   = "template"
+)
+
+// Report fills protocol version and capabilities of node service in Node CR spec
+func Report(node *api.Node) {
+	node.ProtocolVersion = apiV1.NodeProtocolVersion
+	node.Capabilities = Capabilities
+}
+
+// RequiredCapabilities returns capabilities which node service must have to process the volume
+func RequiredCapabilities(volume *api.Volume) []string {
+	var required []string
+	if volume.GetEncryption() != "" {
+		required = append(required, apiV1.NodeCapabilityEncryption)
+	}
+	if volume.GetLazyFormat() {
+		required = append(required, apiV1.NodeCapabilityLazyFormat)
+	}
+	if volume.GetCacheMode() != "" {
+		required = append(required, apiV1.NodeCapabilityCache)
+	}
+	if volume.GetTemplate() != "" {
+		required = append(required, apiV1.NodeCapabilityTemplate)
+	}
+	return required
+}
+
+// RequiredCapabilitiesByParameters returns capabilities which node service must have to process volumes
+// of storage class with provided parameters
+func RequiredCapabilitiesByParameters(params map[string]string) []string {
+	lazyFormat, _ := strconv.ParseBool(params[lazyFormatParameter])
+	return RequiredCapabilities(&api.Volume{
+		Encryption: params[encryptionParameter],
+		LazyFormat: lazyFormat,
+		CacheMode:  params[cacheModeParameter],
+		Template:   params[templateParameter],
+	})
+}
+
+// Check checks that node service supports protocol version and features of the volume
+// Returns wrapped ErrIncompatibleNode if node service isn't compatible
+func Check(node *api.Node, volume *api.Volume) error {
+	if err := CheckCapabilities(node, RequiredCapabilities(volume)); err != nil {
+		return fmt.Errorf("%w, volume %s", err, volume.GetId())
+	}
+	return nil
+}
+
+// CheckCapabilities checks that node service supports protocol version and has required capabilities
+// Node which doesn't report protocol version is treated as legacy node of protocol v1
+// Returns wrapped ErrIncompatibleNode if node service isn't compatible
+func CheckCapabilities(node *api.Node, required []string) error {
+	version, capabilities := node.GetProtocolVersion(), node.GetCapabilities()
+	if version == "" {
+		version, capabilities = apiV1.NodeProtocolVersion, LegacyCapabilities
+	}
+	if !util.ContainsString(SupportedVersions, version) {
+		return fmt.Errorf("%w: node %s implements protocol %s, supported %v",
+			ErrIncompatibleNode, node.GetUUID(), version, SupportedVersions)
+	}
+	for _, capability := range required {
+		if !util.ContainsString(capabilities, capability) {
+			return fmt.Errorf("%w: node %s doesn't support %s",
+				ErrIncompatibleNode, node.GetUUID(), capability)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeprotocol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+func TestRequiredCapabilities(t *testing.T) {
+	assert.Empty(t, RequiredCapabilities(&api.Volume{Id: "volume"}))
+	assert.Equal(t, []string{apiV1.NodeCapabilityEncryption, apiV1.NodeCapabilityCache},
+		RequiredCapabilities(&api.Volume{Encryption: apiV1.EncryptionLUKS, CacheMode: apiV1.CacheModeBcache}))
+}
+
+func TestRequiredCapabilitiesByParameters(t *testing.T) {
+	assert.Empty(t, RequiredCapabilitiesByParameters(nil))
+	assert.Empty(t, RequiredCapabilitiesByParameters(map[string]string{lazyFormatParameter: "false"}))
+	assert.Equal(t, []string{apiV1.NodeCapabilityLazyFormat, apiV1.NodeCapabilityTemplate},
+		RequiredCapabilitiesByParameters(map[string]string{lazyFormatParameter: "true", templateParameter: "base"}))
+}
+
+func TestCheck(t *testing.T) {
+	encrypted := &api.Volume{Id: "volume", Encryption: apiV1.EncryptionLUKS}
+
+	// current node service
+	node := &api.Node{UUID: "node"}
+	Report(node)
+	assert.Nil(t, Check(node, encrypted))
+
+	// legacy node service doesn't support optional features
+	assert.Nil(t, Check(&api.Node{UUID: "node"}, &api.Volume{Id: "volume"}))
+	assert.True(t, errors.Is(Check(&api.Node{UUID: "node"}, encrypted), ErrIncompatibleNode))
+
+	// node service without capability
+	node = &api.Node{UUID: "node", ProtocolVersion: apiV1.NodeProtocolVersion}
+	assert.Nil(t, Check(node, &api.Volume{Id: "volume"}))
+	err := Check(node, encrypted)
+	assert.True(t, errors.Is(err, ErrIncompatibleNode))
+	assert.Contains(t, err.Error(), apiV1.NodeCapabilityEncryption)
+
+	// node service of unsupported protocol
+	node = &api.Node{UUID: "node", ProtocolVersion: "v0"}
+	assert.True(t, errors.Is(Check(node, &api.Volume{Id: "volume"}), ErrIncompatibleNode))
+}
+
+// TestCompatibility guards mixed-version upgrades: node service of this release must support every feature which
+// controller of this release requests, and legacy node services must not be asked for features they don't have
+func TestCompatibility(t *testing.T) {
+	assert.Contains(t, SupportedVersions, apiV1.NodeProtocolVersion)
+
+	all := RequiredCapabilities(&api.Volume{
+		Encryption: apiV1.EncryptionLUKS,
+		LazyFormat: true,
+		CacheMode:  apiV1.CacheModeBcache,
+		Template:   "template",
+	})
+	for _, capability := range all {
+		assert.Contains(t, Capabilities, capability)
+	}
+	for _, capability := range LegacyCapabilities {
+		assert.Contains(t, Capabilities, capability, "capability of protocol v1 can't be removed")
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"google.golang.org/grpc/credentials"
+
+	basenet "github.com/dell/csi-baremetal/pkg/base/net"
+)
+
+// ServerCredentials returns credentials of gRPC server on endpoint which require client certificate when mutual TLS
+// is configured with environment variables of pkg/base/net. Nil credentials are returned for UNIX sockets and
+// when mutual TLS isn't configured
+// Returns error if TLS options are wrong or certificates can't be loaded
+func ServerCredentials(endpoint string) (credentials.TransportCredentials, error) {
+	options, err := mutualTLSOptions(endpoint)
+	if err != nil || options == nil {
+		return nil, err
+	}
+	config, err := options.Config()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// ClientCredentials returns credentials of gRPC client of endpoint which present client certificate when mutual TLS
+// is configured with environment variables of pkg/base/net. Certificate of server is verified for host of endpoint,
+// "localhost" is used for endpoint without host. Nil credentials are returned for UNIX sockets and when mutual TLS
+// isn't configured
+// Returns error if TLS options are wrong or certificates can't be loaded
+func ClientCredentials(endpoint string) (credentials.TransportCredentials, error) {
+	options, err := mutualTLSOptions(endpoint)
+	if err != nil || options == nil {
+		return nil, err
+	}
+	u, _ := url.Parse(endpoint)
+	serverName := u.Hostname()
+	if ip := net.ParseIP(serverName); serverName == "" || (ip != nil && ip.IsUnspecified()) {
+		serverName = "localhost"
+	}
+	config, err := options.ClientConfig(serverName)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// mutualTLSOptions returns TLS options from environment variables for TCP endpoint, nil if mutual TLS isn't used
+func mutualTLSOptions(endpoint string) (*basenet.TLSOptions, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("wrong endpoint %s: %w", endpoint, err)
+	}
+	if u.Scheme == unix {
+		return nil, nil
+	}
+	config, err := basenet.ListenConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if !config.TLS.MutualEnabled() {
+		return nil, nil
+	}
+	return &config.TLS, nil
+}
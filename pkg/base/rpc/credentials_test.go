@@ -0,0 +1,97 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	basenet "github.com/dell/csi-baremetal/pkg/base/net"
+)
+
+func TestCredentials(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		certFile = filepath.Join(dir, "tls.crt")
+		keyFile  = filepath.Join(dir, "tls.key")
+	)
+	defer func() {
+		for _, env := range []string{basenet.TLSCertFileEnv, basenet.TLSKeyFileEnv, basenet.TLSClientCAFileEnv} {
+			_ = os.Unsetenv(env)
+		}
+	}()
+
+	// mutual TLS isn't configured
+	creds, err := ServerCredentials("tcp://:8888")
+	assert.Nil(t, err)
+	assert.Nil(t, creds)
+
+	assert.Nil(t, os.Setenv(basenet.TLSCertFileEnv, certFile))
+	assert.Nil(t, os.Setenv(basenet.TLSKeyFileEnv, keyFile))
+	assert.Nil(t, os.Setenv(basenet.TLSClientCAFileEnv, certFile))
+	_, err = ServerCredentials("tcp://:8888")
+	assert.NotNil(t, err)
+
+	writeTestCert(t, certFile, keyFile)
+	creds, err = ServerCredentials("tcp://:8888")
+	assert.Nil(t, err)
+	assert.NotNil(t, creds)
+	creds, err = ClientCredentials("tcp://:8888")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", creds.Info().ServerName)
+	creds, err = ClientCredentials("tcp://drivemgr.default.svc:8888")
+	assert.Nil(t, err)
+	assert.Equal(t, "drivemgr.default.svc", creds.Info().ServerName)
+
+	// UNIX sockets aren't changed
+	creds, err = ServerCredentials("unix:///tmp/csi.sock")
+	assert.Nil(t, err)
+	assert.Nil(t, creds)
+}
+
+func writeTestCert(t *testing.T, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "csi-baremetal"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		0600))
+}
@@ -20,11 +20,14 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"time"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+
+	basenet "github.com/dell/csi-baremetal/pkg/base/net"
 )
 
 const (
@@ -86,7 +89,12 @@ func (sr *ServerRunner) RunServer() error {
 		// try to remove
 		_ = os.Remove(endpoint)
 	}
-	sr.listener, err = net.Listen(socket, endpoint)
+	if socket == tcp {
+		// TCP endpoints bind addresses from BIND_ADDRESSES environment variable if it is set
+		sr.listener, err = basenet.Listen(endpoint)
+	} else {
+		sr.listener, err = net.Listen(socket, endpoint)
+	}
 	if err != nil {
 		sr.log.Errorf("failed to create listener for endpoint %s: %v", endpoint, err)
 		return err
@@ -101,6 +109,27 @@ func (sr *ServerRunner) StopServer() {
 	sr.GRPCServer.GracefulStop()
 }
 
+// StopServerWithTimeout gracefully stops gRPC server, calls which aren't finished within timeout are cancelled
+// Returns false if server was stopped forcibly
+func (sr *ServerRunner) StopServerWithTimeout(timeout time.Duration) bool {
+	sr.log.Infof("Stopping server, in-flight calls are waited for %s", timeout)
+	stopped := make(chan struct{})
+	go func() {
+		sr.GRPCServer.GracefulStop()
+		close(stopped)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+		return true
+	case <-timer.C:
+		sr.log.Warn("In-flight calls aren't finished in time, stop server forcibly")
+		sr.GRPCServer.Stop()
+		return false
+	}
+}
+
 // GetEndpoint returns endpoint representation
 // Returns url.Path if Scheme is unix or url.Host otherwise
 func (sr *ServerRunner) GetEndpoint() (string, string) {
@@ -21,6 +21,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -93,3 +94,9 @@ func TestServerRunner_StopServer(t *testing.T) {
 	// stop server
 	nonSecureSR.StopServer()
 }
+
+func TestServerRunner_StopServerWithTimeout(t *testing.T) {
+	sr := NewServerRunner(nil, "tcp://localhost:50199", false, serverLogger)
+	// server isn't serving, so it is stopped gracefully
+	assert.True(t, sr.StopServerWithTimeout(time.Second))
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharding contains code for partitioning of CR reconciliation between controller replicas by node
+package sharding
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Shard describes part of nodes which is handled by controller replica
+type Shard struct {
+	index int
+	count int
+}
+
+// NewShard is a constructor for Shard
+// Receives index of the replica and total amount of replicas
+// Returns error if index is out of range
+func NewShard(index, count int) (*Shard, error) {
+	if count < 1 || index < 0 || index >= count {
+		return nil, fmt.Errorf("shard index %d is out of range, shards count %d", index, count)
+	}
+	return &Shard{index: index, count: count}, nil
+}
+
+// IndexFromPodName returns ordinal of StatefulSet pod, e.g. 2 for csi-baremetal-controller-2
+func IndexFromPodName(name string) (int, error) {
+	idx := strings.LastIndex(name, "-")
+	if idx < 0 {
+		return 0, fmt.Errorf("unable to get ordinal from pod name %s", name)
+	}
+	return strconv.Atoi(name[idx+1:])
+}
+
+// Owns returns true if node is handled by the shard, nil shard owns all nodes
+func (s *Shard) Owns(nodeID string) bool {
+	if s == nil || s.count == 1 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(nodeID))
+	return int(h.Sum32()%uint32(s.count)) == s.index
+}
+
+// IsLeader returns true for the first shard, which handles CRs not bound to node
+func (s *Shard) IsLeader() bool {
+	return s == nil || s.index == 0
+}
+
+// String returns string representation of the shard
+func (s *Shard) String() string {
+	if s == nil {
+		return "1/1"
+	}
+	return fmt.Sprintf("%d/%d", s.index+1, s.count)
+}
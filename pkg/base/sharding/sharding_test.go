@@ -0,0 +1,74 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShard(t *testing.T) {
+	_, err := NewShard(3, 3)
+	assert.NotNil(t, err)
+	_, err = NewShard(0, 0)
+	assert.NotNil(t, err)
+
+	s, err := NewShard(1, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, "2/3", s.String())
+	assert.False(t, s.IsLeader())
+}
+
+func TestShard_Owns(t *testing.T) {
+	var nilShard *Shard
+	assert.True(t, nilShard.Owns("node"))
+	assert.True(t, nilShard.IsLeader())
+
+	shards := make([]*Shard, 3)
+	for i := range shards {
+		shards[i], _ = NewShard(i, 3)
+	}
+	// each node is owned by exactly one shard
+	owned := make([]int, len(shards))
+	for n := 0; n < 100; n++ {
+		nodeID := fmt.Sprintf("node-%d", n)
+		owners := 0
+		for i, s := range shards {
+			if s.Owns(nodeID) {
+				owners++
+				owned[i]++
+			}
+		}
+		assert.Equal(t, 1, owners)
+	}
+	for _, o := range owned {
+		assert.NotZero(t, o)
+	}
+}
+
+func TestIndexFromPodName(t *testing.T) {
+	idx, err := IndexFromPodName("csi-baremetal-controller-2")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, idx)
+
+	_, err = IndexFromPodName("controller")
+	assert.NotNil(t, err)
+	_, err = IndexFromPodName("csi-baremetal-controller-5d8f")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shutdown tracks in-flight operations of service and drains them during graceful shutdown
+package shutdown
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tracker tracks in-flight operations, new operations are rejected after draining is started
+type Tracker struct {
+	mu       sync.Mutex
+	draining bool
+	// operation name -> amount of running operations with this name
+	inflight map[string]int
+	// closed when tracker is draining and there are no in-flight operations
+	drained   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewTracker is a constructor for Tracker
+func NewTracker() *Tracker {
+	return &Tracker{inflight: map[string]int{}, drained: make(chan struct{})}
+}
+
+// Begin registers operation, caller must call returned function when operation is finished
+// Returns false if draining is started and operation must not be performed
+func (t *Tracker) Begin(operation string) (func(), bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return nil, false
+	}
+	t.inflight[operation]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.inflight[operation]--
+			if t.inflight[operation] <= 0 {
+				delete(t.inflight, operation)
+			}
+			t.closeIfDrained()
+		})
+	}, true
+}
+
+// IsDraining returns true if draining is started
+func (t *Tracker) IsDraining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// Drain stops acceptance of new operations and waits for in-flight ones during timeout
+// Returns sorted names of operations which weren't finished in time, nil if all operations were finished
+func (t *Tracker) Drain(timeout time.Duration) []string {
+	t.mu.Lock()
+	t.draining = true
+	t.closeIfDrained()
+	t.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-t.drained:
+		return nil
+	case <-timer.C:
+		return t.InFlight()
+	}
+}
+
+// InFlight returns sorted names of running operations
+func (t *Tracker) InFlight() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := make([]string, 0, len(t.inflight))
+	for name := range t.inflight {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// closeIfDrained closes drained channel if tracker is draining and there are no in-flight operations
+// Caller must hold mutex
+func (t *Tracker) closeIfDrained() {
+	if t.draining && len(t.inflight) == 0 {
+		t.closeOnce.Do(func() { close(t.drained) })
+	}
+}
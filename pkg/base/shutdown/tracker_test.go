@@ -0,0 +1,56 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shutdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker_Drain(t *testing.T) {
+	tracker := NewTracker()
+	done1, ok := tracker.Begin("create volume-1")
+	assert.True(t, ok)
+	done2, ok := tracker.Begin("create volume-2")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"create volume-1", "create volume-2"}, tracker.InFlight())
+	done2()
+	// done is idempotent
+	done2()
+	assert.Equal(t, []string{"create volume-1"}, tracker.InFlight())
+
+	assert.Equal(t, []string{"create volume-1"}, tracker.Drain(10*time.Millisecond))
+	assert.True(t, tracker.IsDraining())
+	_, ok = tracker.Begin("create volume-3")
+	assert.False(t, ok)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		done1()
+	}()
+	assert.Nil(t, tracker.Drain(time.Second))
+	assert.Empty(t, tracker.InFlight())
+}
+
+func TestTracker_DrainWithoutOperations(t *testing.T) {
+	tracker := NewTracker()
+	assert.False(t, tracker.IsDraining())
+	assert.Nil(t, tracker.Drain(time.Second))
+	assert.Nil(t, tracker.Drain(time.Second))
+}
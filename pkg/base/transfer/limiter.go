@@ -0,0 +1,86 @@
+/*
+Copyright © 2020 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transfer
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket which limits bandwidth of all transfers which share it, burst is one second of transfer
+// nil Limiter and Limiter with zero rate don't limit bandwidth
+type Limiter struct {
+	// rate is a limit in bytes per second
+	rate   float64
+	tokens float64
+	last   time.Time
+	m      sync.Mutex
+}
+
+// NewLimiter is a constructor for Limiter with limit in bytes per second
+func NewLimiter(bytesPerSecond int64) *Limiter {
+	return &Limiter{rate: float64(bytesPerSecond), tokens: float64(bytesPerSecond), last: time.Now()}
+}
+
+// WaitN blocks until n bytes can be transferred or context is done
+// Bytes are reserved immediately, so concurrent transfers are served in order of calls
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || l.rate <= 0 {
+		return nil
+	}
+	l.m.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens -= float64(n)
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.m.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Writer returns writer which writes to w with bandwidth limit
+func (l *Limiter) Writer(ctx context.Context, w io.Writer) io.Writer {
+	return &limitedWriter{ctx: ctx, w: w, limiter: l}
+}
+
+// limitedWriter waits for limiter before each write
+type limitedWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	limiter *Limiter
+}
+
+// Write writes p to underlying writer when limiter allows it
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if err := lw.limiter.WaitN(lw.ctx, len(p)); err != nil {
+		return 0, err
+	}
+	return lw.w.Write(p)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2020 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transfer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// Files of certificates directory, layout is the same as layout of kubernetes.io/tls Secret with CA
+const (
+	CertFile = "tls.crt"
+	KeyFile  = "tls.key"
+	CAFile   = "ca.crt"
+	// ServerName is a name which certificates of node services are issued for. Node services are connected by pod IP,
+	// so client verifies server certificate against ServerName instead of address
+	ServerName = "csi-baremetal-node"
+)
+
+// LoadTLSConfigs reads certificate, key and CA from dir and returns TLS configs of server and client.
+// Both sides require certificates issued by the CA, so only node services can connect to each other
+func LoadTLSConfigs(dir string) (server *tls.Config, client *tls.Config, err error) {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, CertFile), filepath.Join(dir, KeyFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load certificate from %s: %w", dir, err)
+	}
+	ca, err := ioutil.ReadFile(filepath.Clean(filepath.Join(dir, CAFile)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, nil, fmt.Errorf("CA file %s doesn't contain PEM certificates", filepath.Join(dir, CAFile))
+	}
+	server = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	client = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   ServerName,
+	}
+	return server, client, nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright © 2020 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package transfer contains data transfer service between node pods which is used for data movement
+// between nodes. Connections are authenticated with mutual TLS and bandwidth of transfers is limited per node
+package transfer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	basenet "github.com/dell/csi-baremetal/pkg/base/net"
+)
+
+const (
+	// DataPath is a path of data endpoint, name of data follows it
+	DataPath = "/v1/data/"
+	// OffsetParam is a query parameter with offset in bytes which transfer starts from
+	OffsetParam = "offset"
+
+	readHeaderTimeout = 10 * time.Second
+)
+
+// ErrNotFound is returned by Source if data with name doesn't exist
+var ErrNotFound = errors.New("data isn't found")
+
+// Source provides data by name, e.g. content of volume by volume ID
+type Source interface {
+	// Open returns reader of data which starts from offset, ErrNotFound if data doesn't exist
+	Open(ctx context.Context, name string, offset int64) (io.ReadCloser, error)
+}
+
+// Server serves data of Source to other node services over HTTPS with mutual TLS
+type Server struct {
+	source    Source
+	tlsConfig *tls.Config
+	limiter   *Limiter
+	log       *logrus.Entry
+}
+
+// NewServer is a constructor for Server
+// Receives source of data, server TLS config from LoadTLSConfigs and limiter which is shared with Client of the node
+func NewServer(source Source, tlsConfig *tls.Config, limiter *Limiter, logger *logrus.Logger) *Server {
+	return &Server{
+		source:    source,
+		tlsConfig: tlsConfig,
+		limiter:   limiter,
+		log:       logger.WithField("component", "TransferServer"),
+	}
+}
+
+// ListenAndServe serves transfers on address until error occurs
+func (s *Server) ListenAndServe(address string) error {
+	mux := http.NewServeMux()
+	mux.Handle(DataPath, s)
+	srv := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		TLSConfig:         s.tlsConfig,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	listener, err := basenet.Listen(address)
+	if err != nil {
+		return err
+	}
+	s.log.Infof("Starting transfer server on %s", address)
+	return srv.ServeTLS(listener, "", "")
+}
+
+// ServeHTTP streams data with name from request path
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, DataPath)
+	ll := s.log.WithFields(logrus.Fields{
+		"method": "ServeHTTP",
+		"name":   name,
+		"remote": r.RemoteAddr,
+	})
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if name == "" || strings.Contains(name, "/") {
+		http.Error(w, "wrong name of data", http.StatusBadRequest)
+		return
+	}
+	var offset int64
+	if value := r.URL.Query().Get(OffsetParam); value != "" {
+		var err error
+		if offset, err = strconv.ParseInt(value, 10, 64); err != nil || offset < 0 {
+			http.Error(w, "wrong offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	reader, err := s.source.Open(r.Context(), name, offset)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		ll.Errorf("Unable to open data: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	written, err := io.Copy(s.limiter.Writer(r.Context(), w), reader)
+	if err != nil {
+		// response is already started, client detects incomplete transfer by error of body read
+		ll.Errorf("Transfer is interrupted after %d bytes: %v", written, err)
+		return
+	}
+	ll.Infof("%d bytes are transferred from offset %d", written, offset)
+}
+
+// Client fetches data from transfer servers of other nodes
+type Client struct {
+	http    *http.Client
+	limiter *Limiter
+}
+
+// NewClient is a constructor for Client
+// Receives client TLS config from LoadTLSConfigs and limiter which is shared with Server of the node
+func NewClient(tlsConfig *tls.Config, limiter *Limiter) *Client {
+	return &Client{
+		http:    &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+		limiter: limiter,
+	}
+}
+
+// Fetch writes data with name from offset to w, address is host:port of transfer server
+// Returns count of written bytes, caller can continue interrupted transfer from offset + written bytes
+func (c *Client) Fetch(ctx context.Context, address, name string, offset int64, w io.Writer) (int64, error) {
+	u := url.URL{
+		Scheme:   "https",
+		Host:     address,
+		Path:     DataPath + name,
+		RawQuery: url.Values{OffsetParam: []string{strconv.FormatInt(offset, 10)}}.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return 0, fmt.Errorf("%s from %s: %w", name, address, ErrNotFound)
+	default:
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("unable to fetch %s from %s: %s: %s", name, address, resp.Status,
+			strings.TrimSpace(string(msg)))
+	}
+	return io.Copy(c.limiter.Writer(ctx, w), resp.Body)
+}
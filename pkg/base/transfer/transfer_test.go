@@ -0,0 +1,161 @@
+/*
+Copyright © 2020 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// bytesSource serves data from map
+type bytesSource map[string][]byte
+
+func (s bytesSource) Open(_ context.Context, name string, offset int64) (io.ReadCloser, error) {
+	data, ok := s[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(data[offset:])), nil
+}
+
+// writeCerts writes CA and certificate issued by it for ServerName to dir
+func writeCerts(t *testing.T, dir string) {
+	writePEM := func(name, typ string, der []byte) {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600))
+	}
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	assert.Nil(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: ServerName},
+		DNSNames:     []string{ServerName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caTmpl, &key.PublicKey, caKey)
+	assert.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	writePEM(CAFile, "CERTIFICATE", caDER)
+	writePEM(CertFile, "CERTIFICATE", der)
+	writePEM(KeyFile, "EC PRIVATE KEY", keyDER)
+}
+
+func TestTransfer(t *testing.T) {
+	dir := t.TempDir()
+	writeCerts(t, dir)
+	serverConfig, clientConfig, err := LoadTLSConfigs(dir)
+	assert.Nil(t, err)
+
+	var (
+		data    = bytes.Repeat([]byte("data"), 1024)
+		limiter = NewLimiter(0)
+		server  = httptest.NewUnstartedServer(NewServer(bytesSource{"volume": data}, serverConfig, limiter,
+			logrus.New()))
+	)
+	server.TLS = serverConfig
+	server.StartTLS()
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	client := NewClient(clientConfig, limiter)
+
+	buf := &bytes.Buffer{}
+	n, err := client.Fetch(context.Background(), u.Host, "volume", 0, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	assert.Equal(t, data, buf.Bytes())
+
+	// interrupted transfer is continued from offset
+	buf.Reset()
+	n, err = client.Fetch(context.Background(), u.Host, "volume", 4000, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(data)-4000), n)
+	assert.Equal(t, data[4000:], buf.Bytes())
+
+	_, err = client.Fetch(context.Background(), u.Host, "not-exists", 0, buf)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// client without certificate is rejected
+	noCert := clientConfig.Clone()
+	noCert.Certificates = nil
+	_, err = NewClient(noCert, limiter).Fetch(context.Background(), u.Host, "volume", 0, buf)
+	assert.NotNil(t, err)
+
+	_, _, err = LoadTLSConfigs(t.TempDir())
+	assert.NotNil(t, err)
+}
+
+func TestLimiter(t *testing.T) {
+	var (
+		limiter = NewLimiter(1000)
+		buf     = &bytes.Buffer{}
+		w       = limiter.Writer(context.Background(), buf)
+		start   = time.Now()
+	)
+	// burst is one second of transfer
+	_, err := w.Write(make([]byte, 1000))
+	assert.Nil(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+	_, err = w.Write(make([]byte, 500))
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(400*time.Millisecond))
+	assert.Equal(t, 1500, buf.Len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NotNil(t, limiter.WaitN(ctx, 1000))
+
+	// nil limiter doesn't limit bandwidth
+	var unlimited *Limiter
+	assert.Nil(t, unlimited.WaitN(ctx, 1<<30))
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package units contains units of information and arithmetic of sizes in bytes which detects overflow and
+// rounds explicitly, so capacity planner, partitions and LVM agree on sizes of volumes up to a byte
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Unit is a unit of information, its value is a number of bytes in the unit
+type Unit int64
+
+const (
+	// Byte represents 1 byte
+	Byte Unit = 1
+	// KiB represents 1 kibibyte
+	KiB = 1024 * Byte
+	// MiB represents 1 mebibyte
+	MiB = 1024 * KiB
+	// GiB represents 1 gibibyte
+	GiB = 1024 * MiB
+	// TiB represents 1 tebibyte
+	TiB = 1024 * GiB
+	// Sector represents sector in which kernel reports start and size of block devices in sysfs,
+	// it doesn't depend on logical block size of the device
+	Sector = 512 * Byte
+)
+
+var (
+	// ErrOverflow means that size in bytes doesn't fit into int64
+	ErrOverflow = errors.New("size overflows int64")
+	// ErrPrecisionLoss means that size isn't a whole number of units
+	ErrPrecisionLoss = errors.New("precision loss")
+
+	sizeStrFmt = regexp.MustCompile(`(\d+(\.\d+)?)\s*(\S+)`)
+	// suffixes maps lower-cased suffixes of sizes to units, decimal suffixes are treated as binary ones
+	suffixes = map[string]Unit{
+		"b": Byte,
+		"k": KiB, "kb": KiB, "ki": KiB, "kib": KiB, "e3": KiB,
+		"m": MiB, "mb": MiB, "mi": MiB, "mib": MiB, "e6": MiB,
+		"g": GiB, "gb": GiB, "gi": GiB, "gib": GiB, "e9": GiB,
+		"t": TiB, "tb": TiB, "ti": TiB, "tib": TiB, "e12": TiB,
+	}
+)
+
+// Parse parses the first size with unit in provided string and returns its value in bytes,
+// e.g. "15 Kb" -> 15360, "1.5GiB" -> 1610612736. Fraction of byte is truncated
+// Returns error if string doesn't contain size, unit is unknown or size overflows int64
+func Parse(str string) (int64, error) {
+	matches := sizeStrFmt.FindStringSubmatch(str)
+	if matches == nil {
+		return 0, fmt.Errorf("unparseable size definition: %v", str)
+	}
+	unit, ok := suffixes[strings.ToLower(matches[3])]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %v in supplied value %v", matches[3], str)
+	}
+	// number is validated by regex, fraction is kept exact unlike float64
+	value, _ := new(big.Rat).SetString(matches[1])
+	value.Mul(value, new(big.Rat).SetInt64(int64(unit)))
+	bytes := new(big.Int).Quo(value.Num(), value.Denom())
+	if !bytes.IsInt64() {
+		return 0, fmt.Errorf("%w: %v", ErrOverflow, str)
+	}
+	return bytes.Int64(), nil
+}
+
+// Add returns sum of sizes
+// Returns ErrOverflow if sum doesn't fit into int64
+func Add(a, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, fmt.Errorf("%w: %d + %d", ErrOverflow, a, b)
+	}
+	return a + b, nil
+}
+
+// Mul returns product of size and number
+// Returns ErrOverflow if product doesn't fit into int64
+func Mul(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, fmt.Errorf("%w: %d * %d", ErrOverflow, a, b)
+	}
+	return c, nil
+}
+
+// ToBytes converts value in provided unit to bytes
+// Returns ErrOverflow if size doesn't fit into int64
+func ToBytes(value int64, unit Unit) (int64, error) {
+	return Mul(value, int64(unit))
+}
+
+// ToUnit converts size in bytes to provided unit
+// Returns truncated value and ErrPrecisionLoss if size isn't a whole number of units
+func ToUnit(size int64, unit Unit) (int64, error) {
+	if unit <= 0 {
+		return 0, fmt.Errorf("invalid unit %d", unit)
+	}
+	res := size / int64(unit)
+	if size%int64(unit) != 0 {
+		return res, fmt.Errorf("%w: %d bytes aren't a whole number of units of %d bytes", ErrPrecisionLoss, size, unit)
+	}
+	return res, nil
+}
+
+// ToUnitCeil converts non-negative size in bytes to provided unit rounding up,
+// so size in returned units isn't less than provided one
+func ToUnitCeil(size int64, unit Unit) int64 {
+	res, err := ToUnit(size, unit)
+	if errors.Is(err, ErrPrecisionLoss) && size > 0 {
+		res++
+	}
+	return res
+}
+
+// AlignUp rounds size up to the nearest multiple of boundary
+// Returns error if boundary isn't positive or aligned size doesn't fit into int64
+func AlignUp(size, boundary int64) (int64, error) {
+	if boundary <= 0 {
+		return 0, fmt.Errorf("invalid boundary %d", boundary)
+	}
+	rem := remainder(size, boundary)
+	if rem == 0 {
+		return size, nil
+	}
+	return Add(size, boundary-rem)
+}
+
+// AlignDown rounds size down to the nearest multiple of boundary, size is returned as is if boundary isn't positive
+func AlignDown(size, boundary int64) int64 {
+	if boundary <= 0 {
+		return size
+	}
+	return size - remainder(size, boundary)
+}
+
+// IsAligned checks that size is a multiple of positive boundary
+func IsAligned(size, boundary int64) bool {
+	return boundary > 0 && size%boundary == 0
+}
+
+// remainder returns non-negative remainder of division of size by positive boundary
+func remainder(size, boundary int64) int64 {
+	rem := size % boundary
+	if rem < 0 {
+		rem += boundary
+	}
+	return rem
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for str, expected := range map[string]int64{
+		"15 b":                             15,
+		"601B":                             601,
+		"48e3":                             48 * int64(KiB),
+		"9851 Mi":                          9851 * int64(MiB),
+		"1.5GiB":                           3 * int64(GiB) / 2,
+		"0.1k":                             102,
+		"7.28t":                            8004444650209,
+		"This disk has 5 gb of free space": 5 * int64(GiB),
+		"8388607t":                         8388607 * int64(TiB),
+	} {
+		bytes, err := Parse(str)
+		assert.Nil(t, err, str)
+		assert.Equal(t, expected, bytes, str)
+	}
+
+	_, err := Parse("foo")
+	assert.Contains(t, err.Error(), "unparseable")
+	_, err = Parse("15Cm")
+	assert.Contains(t, err.Error(), "unknown size unit Cm")
+	_, err = Parse("8388608t")
+	assert.True(t, errors.Is(err, ErrOverflow))
+}
+
+func TestArithmetic(t *testing.T) {
+	sum, err := Add(math.MaxInt64-1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(math.MaxInt64), sum)
+	_, err = Add(math.MaxInt64, 1)
+	assert.True(t, errors.Is(err, ErrOverflow))
+	_, err = Add(math.MinInt64, -1)
+	assert.True(t, errors.Is(err, ErrOverflow))
+
+	bytes, err := ToBytes(3, GiB)
+	assert.Nil(t, err)
+	assert.Equal(t, 3*int64(GiB), bytes)
+	_, err = ToBytes(8*1024*1024, TiB)
+	assert.True(t, errors.Is(err, ErrOverflow))
+	_, err = Mul(-1, math.MinInt64)
+	assert.True(t, errors.Is(err, ErrOverflow))
+
+	value, err := ToUnit(4095*int64(KiB), MiB)
+	assert.True(t, errors.Is(err, ErrPrecisionLoss))
+	assert.Equal(t, int64(3), value)
+	value, err = ToUnit(4*int64(MiB), MiB)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), value)
+	assert.Equal(t, int64(4), ToUnitCeil(4095*int64(KiB), MiB))
+	assert.Equal(t, int64(4), ToUnitCeil(4*int64(MiB), MiB))
+	assert.Equal(t, int64(0), ToUnitCeil(0, MiB))
+}
+
+func TestAlignment(t *testing.T) {
+	pe := 4 * int64(MiB)
+	for size, expected := range map[int64]int64{0: 0, 1: pe, pe: pe, pe + 1: 2 * pe, -1: 0} {
+		aligned, err := AlignUp(size, pe)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, aligned, size)
+	}
+	_, err := AlignUp(math.MaxInt64, pe)
+	assert.True(t, errors.Is(err, ErrOverflow))
+	_, err = AlignUp(1, 0)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, pe, AlignDown(2*pe-1, pe))
+	assert.Equal(t, -pe, AlignDown(-1, pe))
+	assert.Equal(t, int64(5), AlignDown(5, 0))
+
+	assert.True(t, IsAligned(63*int64(Sector), int64(Sector)))
+	assert.False(t, IsAligned(63*int64(Sector), 4096))
+	assert.False(t, IsAligned(4096, 0))
+}
@@ -25,7 +25,11 @@ import (
 
 // SetupAndStartHealthCheckServer starts gRPC server to handle Health checking requests
 func SetupAndStartHealthCheckServer(c health.HealthServer, logger *logrus.Logger, endpoint string) error {
-	healthServer := rpc.NewServerRunner(nil, endpoint, false, logger)
+	creds, err := rpc.ServerCredentials(endpoint)
+	if err != nil {
+		return err
+	}
+	healthServer := rpc.NewServerRunner(creds, endpoint, false, logger)
 	// register Health checks
 	logger.Info("Registering health check service")
 	health.RegisterHealthServer(healthServer.GRPCServer, c)
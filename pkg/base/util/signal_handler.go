@@ -20,6 +20,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -43,6 +44,31 @@ func (sh *SignalHandler) SetupSIGTERMHandler(server *rpc.ServerRunner) {
 	server.StopServer()
 }
 
+// Drainer stops acceptance of new operations and waits for in-flight ones
+type Drainer interface {
+	// Drain returns names of operations which weren't finished within timeout
+	Drain(timeout time.Duration) []string
+}
+
+// SetupGracefulSIGTERMHandler stops gRPC server and drains in-flight operations of service within grace period,
+// when SIGTERM is caught. Returns when server is stopped and draining is finished
+func (sh *SignalHandler) SetupGracefulSIGTERMHandler(server *rpc.ServerRunner, drainer Drainer, grace time.Duration) {
+	sh.setupSignalHandler(syscall.SIGTERM)
+	ll := sh.log.WithField("method", "SetupGracefulSIGTERMHandler")
+	start := time.Now()
+	// in-flight calls and background operations are waited for in parallel within the same grace period
+	unfinished := make(chan []string, 1)
+	go func() {
+		unfinished <- drainer.Drain(grace)
+	}()
+	server.StopServerWithTimeout(grace)
+	if ops := <-unfinished; len(ops) > 0 {
+		ll.Warnf("Grace period %s expired, unfinished operations will be resumed after restart: %v", grace, ops)
+		return
+	}
+	ll.Infof("Service is drained in %s", time.Since(start).Round(time.Millisecond))
+}
+
 // SetupSIGHUPHandler tries to make cleanup, when SIGHUP is caught
 func (sh *SignalHandler) SetupSIGHUPHandler(cleanupFn func()) {
 	sh.setupSignalHandler(syscall.SIGHUP)
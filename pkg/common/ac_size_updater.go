@@ -0,0 +1,155 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+// acUpdateAttempts is an amount of AC updates in case of conflicts with concurrent writers
+const acUpdateAttempts = 5
+
+// acSizeRequest is a single change of AC size waiting to be applied
+type acSizeRequest struct {
+	delta int64
+	done  chan error
+}
+
+// acSizeBatch holds requests for one AC, flushing is true while some caller applies them
+type acSizeBatch struct {
+	pending  []*acSizeRequest
+	flushing bool
+}
+
+// acSizeUpdater batches concurrent size changes of the same AC into one AC update.
+// First caller for AC applies all requests which arrived while previous update was in progress,
+// so N simultaneous volumes on one LVG cost about two AC updates instead of N serialized ones
+type acSizeUpdater struct {
+	k8sClient *k8s.KubeClient
+	log       *logrus.Entry
+
+	mu      sync.Mutex
+	batches map[string]*acSizeBatch
+}
+
+func newACSizeUpdater(k8sClient *k8s.KubeClient, log *logrus.Entry) *acSizeUpdater {
+	return &acSizeUpdater{
+		k8sClient: k8sClient,
+		log:       log,
+		batches:   make(map[string]*acSizeBatch),
+	}
+}
+
+// Update changes size of AC with name acName on delta bytes, blocks until change is applied.
+// Decrease which makes size negative is rejected with OutOfRange error, other requests of batch are applied
+func (u *acSizeUpdater) Update(ctx context.Context, acName string, delta int64) error {
+	req := &acSizeRequest{delta: delta, done: make(chan error, 1)}
+
+	u.mu.Lock()
+	batch, ok := u.batches[acName]
+	if !ok {
+		batch = &acSizeBatch{}
+		u.batches[acName] = batch
+	}
+	batch.pending = append(batch.pending, req)
+	leader := !batch.flushing
+	batch.flushing = true
+	u.mu.Unlock()
+
+	if leader {
+		u.flush(ctx, acName, batch)
+	}
+	return <-req.done
+}
+
+// flush applies pending requests of batch until no new requests arrive
+func (u *acSizeUpdater) flush(ctx context.Context, acName string, batch *acSizeBatch) {
+	for {
+		u.mu.Lock()
+		requests := batch.pending
+		batch.pending = nil
+		if len(requests) == 0 {
+			batch.flushing = false
+			delete(u.batches, acName)
+			u.mu.Unlock()
+			return
+		}
+		u.mu.Unlock()
+
+		results := u.apply(ctx, acName, requests)
+		for i, req := range requests {
+			req.done <- results[i]
+		}
+	}
+}
+
+// apply reads the latest AC version and updates its size with requests, re-reads AC on conflict
+// Returns result for each request
+func (u *acSizeUpdater) apply(ctx context.Context, acName string, requests []*acSizeRequest) []error {
+	ll := u.log.WithFields(logrus.Fields{
+		"method": "acSizeUpdater.apply",
+		"ac":     acName,
+	})
+
+	var (
+		results = make([]error, len(requests))
+		err     error
+	)
+	for i := 0; i < acUpdateAttempts; i++ {
+		ac := &accrd.AvailableCapacity{}
+		if err = u.k8sClient.ReadCR(ctx, acName, "", ac); err != nil {
+			break
+		}
+		size := ac.Spec.Size
+		for j, req := range requests {
+			if size+req.delta < 0 {
+				results[j] = status.Errorf(codes.OutOfRange,
+					"not enough capacity in AC %s: requested - %d, available - %d", acName, -req.delta, size)
+				continue
+			}
+			results[j] = nil
+			size += req.delta
+		}
+		if size == ac.Spec.Size {
+			return results
+		}
+		ac.Spec.Size = size
+		if err = u.k8sClient.UpdateCR(ctx, ac); err == nil {
+			ll.Debugf("Size of AC was changed to %d with %d request(s)", size, len(requests))
+			return results
+		}
+		if !k8sError.IsConflict(err) {
+			break
+		}
+		ll.Warnf("AC was modified concurrently, attempt %d out of %d", i+1, acUpdateAttempts)
+	}
+
+	ll.Errorf("Unable to update AC size: %v", err)
+	for j := range results {
+		results[j] = err
+	}
+	return results
+}
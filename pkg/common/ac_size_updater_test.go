@@ -0,0 +1,66 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+func TestACSizeUpdater_Update(t *testing.T) {
+	k8sClient, err := k8s.GetFakeKubeClient(testNS, testLogger)
+	assert.Nil(t, err)
+	updater := newACSizeUpdater(k8sClient, testLogger.WithField("component", "test"))
+
+	ac := testAC4.DeepCopy()
+	ac.Spec.Size = 1000
+	assert.Nil(t, k8sClient.CreateCR(testCtx, ac.Name, ac))
+
+	// concurrent requests are applied together
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, updater.Update(testCtx, ac.Name, -50))
+		}()
+	}
+	wg.Wait()
+	assert.Nil(t, k8sClient.ReadCR(testCtx, ac.Name, "", ac))
+	assert.Equal(t, int64(500), ac.Spec.Size)
+	assert.Empty(t, updater.batches)
+
+	// increase
+	assert.Nil(t, updater.Update(testCtx, ac.Name, 200))
+	assert.Nil(t, k8sClient.ReadCR(testCtx, ac.Name, "", ac))
+	assert.Equal(t, int64(700), ac.Spec.Size)
+
+	// not enough capacity
+	err = updater.Update(testCtx, ac.Name, -701)
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+	assert.Nil(t, k8sClient.ReadCR(testCtx, ac.Name, "", ac))
+	assert.Equal(t, int64(700), ac.Spec.Size)
+
+	// AC doesn't exist
+	assert.NotNil(t, updater.Update(testCtx, "unknown", 10))
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+)
+
+const (
+	// QuotaAnnotationPrefix is a prefix of namespace annotations which hold capacity quota per media type
+	// For example, quota.csi-baremetal.dell.com/hdd: 500Gi
+	QuotaAnnotationPrefix = "quota.csi-baremetal.dell.com/"
+)
+
+// QuotaOperations is the interface for checking namespace-scoped capacity quotas
+type QuotaOperations interface {
+	// CheckQuota returns ResourceExhausted error if volume with size and storageClass doesn't fit in namespace quota
+	CheckQuota(ctx context.Context, namespace, volumeID, storageClass string, size int64) error
+}
+
+// QuotaOperationsImpl is the basic implementation of QuotaOperations interface, quotas are read from
+// annotations of the k8s Namespace object, consumed capacity is calculated based on Volume CRs in that namespace
+type QuotaOperationsImpl struct {
+	k8sClient *k8s.KubeClient
+	log       *logrus.Entry
+}
+
+// NewQuotaOperationsImpl is the constructor for QuotaOperationsImpl struct
+// Receives an instance of base.KubeClient and logrus logger
+// Returns an instance of QuotaOperationsImpl
+func NewQuotaOperationsImpl(k8sClient *k8s.KubeClient, logger *logrus.Logger) *QuotaOperationsImpl {
+	return &QuotaOperationsImpl{
+		k8sClient: k8sClient,
+		log:       logger.WithField("component", "QuotaOperationsImpl"),
+	}
+}
+
+// CheckQuota reads quota for media type of the storageClass from namespace annotations and compares it with
+// capacity which is already consumed by volumes in that namespace.
+// Receives golang context, namespace, volumeID which is excluded from calculation, storage class and size of volume
+// Returns nil if quota isn't set or isn't exceeded, ResourceExhausted error if quota is exceeded
+func (q *QuotaOperationsImpl) CheckQuota(ctx context.Context, namespace, volumeID, storageClass string, size int64) error {
+	ll := q.log.WithFields(logrus.Fields{
+		"method":    "CheckQuota",
+		"volumeID":  volumeID,
+		"namespace": namespace,
+	})
+
+	ns := &corev1.Namespace{}
+	if err := q.k8sClient.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		if k8sError.IsNotFound(err) {
+			return nil
+		}
+		ll.Errorf("Unable to read namespace: %v", err)
+		return status.Errorf(codes.Internal, "unable to read quota for namespace %s", namespace)
+	}
+
+	mediaType := GetQuotaMediaType(storageClass)
+	quotaStr, ok := ns.GetAnnotations()[QuotaAnnotationPrefix+strings.ToLower(mediaType)]
+	if !ok {
+		return nil
+	}
+	quota, err := util.StrToBytes(quotaStr)
+	if err != nil {
+		ll.Errorf("Unable to parse quota %s for media type %s: %v", quotaStr, mediaType, err)
+		return status.Errorf(codes.InvalidArgument, "invalid %s quota %s for namespace %s", mediaType, quotaStr, namespace)
+	}
+
+	volumes := &volumecrd.VolumeList{}
+	if err = q.k8sClient.List(ctx, volumes, client.InNamespace(namespace)); err != nil {
+		ll.Errorf("Unable to read volumes list: %v", err)
+		return status.Errorf(codes.Internal, "unable to calculate consumed capacity for namespace %s", namespace)
+	}
+
+	var used int64
+	for _, v := range volumes.Items {
+		if v.Namespace != namespace || v.Name == volumeID {
+			continue
+		}
+		if v.Spec.CSIStatus == apiV1.Removed || v.Spec.CSIStatus == apiV1.Failed {
+			continue
+		}
+		if GetQuotaMediaType(v.Spec.StorageClass) == mediaType {
+			used += v.Spec.Size
+		}
+	}
+
+	if used+size > quota {
+		ll.Warnf("Quota exceeded: requested %d, used %d, quota %d", size, used, quota)
+		return status.Errorf(codes.ResourceExhausted,
+			"namespace %s exceeds %s capacity quota: requested %d bytes, used %d bytes, quota %d bytes",
+			namespace, mediaType, size, used, quota)
+	}
+	return nil
+}
+
+// GetQuotaMediaType returns media type which is used to account capacity of the storage class,
+// LVG storage classes are accounted together with underlying drives
+func GetQuotaMediaType(storageClass string) string {
+	if sc := util.GetSubStorageClass(storageClass); sc != "" {
+		return sc
+	}
+	if storageClass == apiV1.StorageClassSystemLVG {
+		return apiV1.StorageClassSSD
+	}
+	return storageClass
+}
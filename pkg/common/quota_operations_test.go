@@ -0,0 +1,87 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+)
+
+func TestQuotaOperationsImpl_CheckQuota(t *testing.T) {
+	k8sClient, err := k8s.GetFakeKubeClient(testNS, testLogger)
+	assert.Nil(t, err)
+	quotaOps := NewQuotaOperationsImpl(k8sClient, testLogger)
+
+	// namespace doesn't exist - no quota
+	assert.Nil(t, quotaOps.CheckQuota(testCtx, testNS, "vol", apiV1.StorageClassHDD, int64(util.TBYTE)))
+
+	ns := &corev1.Namespace{ObjectMeta: k8smetav1.ObjectMeta{
+		Name:        testNS,
+		Annotations: map[string]string{QuotaAnnotationPrefix + "hdd": "100Gi"},
+	}}
+	assert.Nil(t, k8sClient.Create(testCtx, ns))
+
+	// quota for other media type isn't set
+	assert.Nil(t, quotaOps.CheckQuota(testCtx, testNS, "vol", apiV1.StorageClassSSD, int64(util.TBYTE)))
+
+	// fits quota
+	assert.Nil(t, quotaOps.CheckQuota(testCtx, testNS, "vol", apiV1.StorageClassHDD, int64(util.GBYTE)*60))
+
+	existing := k8sClient.ConstructVolumeCR("existing", testNS, nil, api.Volume{
+		Id:           "existing",
+		StorageClass: apiV1.StorageClassHDDLVG,
+		Size:         int64(util.GBYTE) * 50,
+		CSIStatus:    apiV1.Created,
+	})
+	assert.Nil(t, k8sClient.CreateCR(testCtx, existing.Name, existing))
+
+	// LVG volume is accounted as HDD
+	err = quotaOps.CheckQuota(testCtx, testNS, "vol", apiV1.StorageClassHDD, int64(util.GBYTE)*60)
+	assert.NotNil(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// volume itself isn't accounted
+	assert.Nil(t, quotaOps.CheckQuota(testCtx, testNS, "existing", apiV1.StorageClassHDD, int64(util.GBYTE)*60))
+
+	// removed volumes aren't accounted
+	existing.Spec.CSIStatus = apiV1.Removed
+	assert.Nil(t, k8sClient.UpdateCR(testCtx, existing))
+	assert.Nil(t, quotaOps.CheckQuota(testCtx, testNS, "vol", apiV1.StorageClassHDD, int64(util.GBYTE)*60))
+
+	// invalid quota
+	ns.Annotations[QuotaAnnotationPrefix+"hdd"] = "unknown"
+	assert.Nil(t, k8sClient.Update(testCtx, ns))
+	err = quotaOps.CheckQuota(testCtx, testNS, "vol", apiV1.StorageClassHDD, int64(util.GBYTE))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetQuotaMediaType(t *testing.T) {
+	assert.Equal(t, apiV1.StorageClassHDD, GetQuotaMediaType(apiV1.StorageClassHDD))
+	assert.Equal(t, apiV1.StorageClassHDD, GetQuotaMediaType(apiV1.StorageClassHDDLVG))
+	assert.Equal(t, apiV1.StorageClassNVMe, GetQuotaMediaType(apiV1.StorageClassNVMeLVG))
+	assert.Equal(t, apiV1.StorageClassSSD, GetQuotaMediaType(apiV1.StorageClassSystemLVG))
+}
@@ -29,6 +29,7 @@ import (
 	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
 	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/keymutex"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	api "github.com/dell/csi-baremetal/api/generated/v1"
@@ -40,6 +41,8 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/cache"
 	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
+	baseerr "github.com/dell/csi-baremetal/pkg/base/error"
 	fc "github.com/dell/csi-baremetal/pkg/base/featureconfig"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/util"
@@ -61,9 +64,18 @@ type VolumeOperations interface {
 // VolumeOperationsImpl is the basic implementation of VolumeOperations interface
 type VolumeOperationsImpl struct {
 	acProvider             AvailableCapacityOperations
+	quotaProvider          QuotaOperations
 	k8sClient              *k8s.KubeClient
 	capacityManagerBuilder capacityplanner.CapacityManagerBuilder
 	crHelper               *k8s.CRHelper
+	// batches size changes of the same AC from concurrent requests
+	acSizeUpdater *acSizeUpdater
+	// serializes conversion and removal of the same AC, requests on different ACs run in parallel
+	acMu keymutex.KeyMutex
+	// serializes release of requests of the same reservation
+	reservationMu keymutex.KeyMutex
+	// serializes quota check and creation of volume CRs in the same namespace, quota check is check-then-act
+	namespaceMu keymutex.KeyMutex
 
 	metrics        metrics.Statistic
 	cache          cache.Interface
@@ -85,11 +97,17 @@ func NewVolumeOperationsImpl(k8sClient *k8s.KubeClient, logger *logrus.Logger, c
 		logger.WithField("component", "NewVolumeOperationsImpl").
 			Errorf("Failed to register metric: %v", err)
 	}
+	log := logger.WithField("component", "VolumeOperationsImpl")
 	vo := &VolumeOperationsImpl{
 		k8sClient:              k8sClient,
 		crHelper:               k8s.NewCRHelper(k8sClient, logger),
 		acProvider:             NewACOperationsImpl(k8sClient, logger),
-		log:                    logger.WithField("component", "VolumeOperationsImpl"),
+		quotaProvider:          NewQuotaOperationsImpl(k8sClient, logger),
+		log:                    log,
+		acSizeUpdater:          newACSizeUpdater(k8sClient, log),
+		acMu:                   diagnostics.NewKeyMutex("available-capacities", keymutex.NewHashed(0)),
+		reservationMu:          diagnostics.NewKeyMutex("reservations", keymutex.NewHashed(0)),
+		namespaceMu:            diagnostics.NewKeyMutex("namespaces", keymutex.NewHashed(0)),
 		featureChecker:         featureConf,
 		capacityManagerBuilder: &capacityplanner.DefaultCapacityManagerBuilder{},
 		cache:                  cache,
@@ -182,10 +200,8 @@ func (vo *VolumeOperationsImpl) handleVolumeCreation(ctx context.Context, log *l
 	}
 
 	if ac.Spec.StorageClass != v.StorageClass && util.IsStorageClassLVG(v.StorageClass) {
-		// AC needs to be converted to LogicalVolumeGroup AC, LogicalVolumeGroup doesn't exist yet
-		if ac = vo.acProvider.RecreateACToLVGSC(ctx, v.StorageClass, *ac); ac == nil {
-			return nil, status.Errorf(codes.Internal,
-				"unable to prepare underlying storage for storage class %s", v.StorageClass)
+		if ac, err = vo.convertACToLVG(ctx, log, ac, v.StorageClass); err != nil {
+			return nil, err
 		}
 	}
 	log.Infof("AC %v was selected", ac)
@@ -206,6 +222,22 @@ func (vo *VolumeOperationsImpl) handleVolumeCreation(ctx context.Context, log *l
 		locationType = apiV1.LocationTypeDrive
 	}
 
+	if vo.featureChecker.IsEnabled(fc.FeatureNamespaceQuota) {
+		// concurrent requests must see volume CR of each other, otherwise both of them pass the quota
+		vo.namespaceMu.LockKey(podNamespace)
+		defer vo.unlockNamespace(log, podNamespace)
+		if err = vo.quotaProvider.CheckQuota(ctx, podNamespace, v.Id, sc, allocatedBytes); err != nil {
+			log.Errorf("Quota check failed: %v", err)
+			// release reservation, otherwise reserved capacity isn't available for other volumes
+			if status.Code(err) == codes.ResourceExhausted {
+				if releaseErr := vo.deleteVolumeReservation(ctx, podReservation, volumeReservationNum); releaseErr != nil {
+					log.Errorf("Unable to release volume reservation: %v", releaseErr)
+				}
+			}
+			return nil, err
+		}
+	}
+
 	if !v.Ephemeral {
 		claimLabels, err = vo.getPersistentVolumeClaimLabels(ctx, reservationName, podNamespace)
 		if err != nil {
@@ -229,20 +261,33 @@ func (vo *VolumeOperationsImpl) handleVolumeCreation(ctx context.Context, log *l
 		Usage:             apiV1.VolumeUsageInUse,
 		Mode:              v.Mode,
 		Type:              v.Type,
+		Encryption:        v.Encryption,
+		LazyFormat:        v.LazyFormat,
+		CacheMode:         v.CacheMode,
+		CacheSize:         v.CacheSize,
+		Template:          v.Template,
 	}
 	volumeCR := vo.k8sClient.ConstructVolumeCR(v.Id, podNamespace, claimLabels, apiVolume)
 
+	// decrease AC size before volume CR is created, volume CR mustn't exist without consumed capacity
+	if err = vo.acSizeUpdater.Update(ctx, ac.Name, -allocatedBytes); err != nil {
+		log.Errorf("Unable to decrease size of AC %s on %d, error: %v", ac.Name, allocatedBytes, err)
+		if status.Code(err) == codes.OutOfRange {
+			return nil, status.Errorf(codes.ResourceExhausted, "not enough capacity for volume %s", v.Id)
+		}
+		return nil, status.Errorf(codes.Internal, "unable to consume capacity for volume %s", v.Id)
+	}
+
 	if err = vo.k8sClient.CreateCR(ctx, v.Id, volumeCR); err != nil {
 		log.Errorf("Unable to create CR, error: %v", err)
+		// return consumed capacity
+		if acErr := vo.acSizeUpdater.Update(ctx, ac.Name, allocatedBytes); acErr != nil {
+			log.Errorf("Unable to increase size of AC %s on %d, error: %v", ac.Name, allocatedBytes, acErr)
+		}
 		return nil, status.Errorf(codes.Internal, "unable to create volume CR")
 	}
 	vo.cache.Set(v.Id, podNamespace)
 
-	// decrease AC size
-	ac.Spec.Size -= allocatedBytes
-	if err = vo.k8sClient.UpdateCRWithAttempts(ctx, ac, 5); err != nil {
-		log.Errorf("Unable to set size for AC %s to %d, error: %v", ac.Name, ac.Spec.Size, err)
-	}
 	// release reservation
 	if err := vo.deleteVolumeReservation(ctx, podReservation, volumeReservationNum); err != nil {
 		return nil, err
@@ -251,6 +296,36 @@ func (vo *VolumeOperationsImpl) handleVolumeCreation(ctx context.Context, log *l
 	return &volumeCR.Spec, nil
 }
 
+// unlockNamespace unlocks namespace and logs error if unlock failed
+func (vo *VolumeOperationsImpl) unlockNamespace(log *logrus.Entry, namespace string) {
+	if err := vo.namespaceMu.UnlockKey(namespace); err != nil {
+		log.Warnf("Unlocking namespace %s with error %s", namespace, err)
+	}
+}
+
+// convertACToLVG converts AC to LogicalVolumeGroup AC if it wasn't converted by concurrent request yet
+// Returns AC with LogicalVolumeGroup location or error
+func (vo *VolumeOperationsImpl) convertACToLVG(ctx context.Context, log *logrus.Entry, ac *accrd.AvailableCapacity,
+	sc string) (*accrd.AvailableCapacity, error) {
+	vo.acMu.LockKey(ac.Name)
+	defer vo.unlockAC(log, ac.Name)
+
+	// read the latest version, AC might be converted while lock was acquired
+	latest := &accrd.AvailableCapacity{}
+	if err := vo.k8sClient.ReadCR(ctx, ac.Name, "", latest); err != nil {
+		log.Errorf("Failed to read capacity %s: %v", ac.Name, err)
+		return nil, err
+	}
+	if latest.Spec.StorageClass == sc {
+		return latest, nil
+	}
+	// AC needs to be converted to LogicalVolumeGroup AC, LogicalVolumeGroup doesn't exist yet
+	if converted := vo.acProvider.RecreateACToLVGSC(ctx, sc, *latest); converted != nil {
+		return converted, nil
+	}
+	return nil, status.Errorf(codes.Internal, "unable to prepare underlying storage for storage class %s", sc)
+}
+
 func (vo *VolumeOperationsImpl) handleVolumeInProgress(ctx context.Context, log *logrus.Entry, volumeCR *volumecrd.Volume,
 	podNamespace string, reservationName string) (*api.Volume, error) {
 	log.Infof("Volume exists, current status: %s.", volumeCR.Spec.CSIStatus)
@@ -331,13 +406,35 @@ func (vo *VolumeOperationsImpl) getVolumeReservation(ctx context.Context, log *l
 func (vo *VolumeOperationsImpl) deleteVolumeReservation(ctx context.Context,
 	reservation *acrcrd.AvailableCapacityReservation, number int) error {
 	// release reservation if exists
-	if reservation != nil {
-		capReader := capacityplanner.NewACReader(vo.k8sClient, vo.log, true)
-		resHelper := capacityplanner.NewReservationHelper(vo.log, vo.k8sClient, capReader)
+	if reservation == nil {
+		return nil
+	}
 
-		return resHelper.ReleaseReservation(ctx, reservation, number)
+	vo.reservationMu.LockKey(reservation.Name)
+	defer func() {
+		if err := vo.reservationMu.UnlockKey(reservation.Name); err != nil {
+			vo.log.Warnf("Unlocking reservation %s with error %s", reservation.Name, err)
+		}
+	}()
+
+	// requests of the same reservation might be released concurrently, find request in the latest version
+	requestName := reservation.Spec.ReservationRequests[number].CapacityRequest.Name
+	latest := &acrcrd.AvailableCapacityReservation{}
+	if err := vo.k8sClient.ReadCR(ctx, reservation.Name, "", latest); err != nil {
+		if k8sError.IsNotFound(err) {
+			return nil
+		}
+		return err
 	}
+	for i, request := range latest.Spec.ReservationRequests {
+		if request.CapacityRequest.Name == requestName {
+			capReader := capacityplanner.NewACReader(vo.k8sClient, vo.log, true)
+			resHelper := capacityplanner.NewReservationHelper(vo.log, vo.k8sClient, capReader)
 
+			return resHelper.ReleaseReservation(ctx, latest, i)
+		}
+	}
+	// already released
 	return nil
 }
 
@@ -428,7 +525,7 @@ func (vo *VolumeOperationsImpl) UpdateCRsAfterVolumeDeletion(ctx context.Context
 	vo.cache.Delete(volumeID)
 	// find corresponding AC CR
 	acList := accrd.AvailableCapacityList{}
-	if err = vo.k8sClient.ReadList(ctx, &acList); err != nil {
+	if err = vo.k8sClient.ReadListByIndex(ctx, &acList, k8s.NodeIDIndex, volumeCR.Spec.NodeId); err != nil {
 		ll.Errorf("Volume was deleted but corresponding AC with SC %s hadn't updated, unable to read list: %v",
 			volumeCR.Spec.StorageClass, err)
 	}
@@ -450,29 +547,38 @@ func (vo *VolumeOperationsImpl) UpdateCRsAfterVolumeDeletion(ctx context.Context
 	// for LogicalVolumeGroup SCs we need to delete AC CR when no volumes remain to avoid new allocations since
 	// underlying LogicalVolumeGroup CR is destroying. For other SC just to increase size
 	isDeleted := false
-	lvg := &lvgcrd.LogicalVolumeGroup{}
 	if util.IsStorageClassLVG(volumeCR.Spec.StorageClass) {
+		// concurrent deletions and conversions of the same AC must be serialized
+		vo.acMu.LockKey(acCR.Name)
+		lvg := &lvgcrd.LogicalVolumeGroup{}
 		if err = vo.k8sClient.ReadCR(context.Background(), volumeCR.Spec.Location, "", lvg); err != nil {
 			ll.Errorf("Unable to get LogicalVolumeGroup %s: %v", volumeCR.Spec.Location, err)
+			vo.unlockAC(ll, acCR.Name)
 			return
 		}
 
 		if isDeleted, err = vo.deleteLVGIfVolumesNotExistOrUpdate(lvg, volumeCR.Name, &acCR); err != nil {
 			ll.Errorf("Unable to remove volume reference from LogicalVolumeGroup %s: %v", volumeCR.Spec.Location, err)
 		}
+		vo.unlockAC(ll, acCR.Name)
 	}
 
 	// if LogicalVolumeGroup wasn't deleted and health of volume is GOOD increase AC size
 	// We don't increase AC size for unhealthy volume to avoid new allocations on top of unhealthy drive/lvg
 	if !isDeleted && volumeCR.Spec.Health == apiV1.HealthGood {
 		// Increase size of AC using volume size
-		acCR.Spec.Size += volumeCR.Spec.Size
-		if err = vo.k8sClient.UpdateCRWithAttempts(ctx, &acCR, 5); err != nil {
+		if err = vo.acSizeUpdater.Update(ctx, acCR.Name, volumeCR.Spec.Size); err != nil {
 			ll.Errorf("Unable to update AC %s size: %v", acCR.Name, err)
 		}
 	}
 }
 
+func (vo *VolumeOperationsImpl) unlockAC(log *logrus.Entry, name string) {
+	if err := vo.acMu.UnlockKey(name); err != nil {
+		log.Warnf("Unlocking AC %s with error %s", name, err)
+	}
+}
+
 // WaitStatus check volume status until it will be reached one of the statuses
 // return error if context is done or volume reaches failed status, return nil if reached status != failed
 func (vo *VolumeOperationsImpl) WaitStatus(ctx context.Context, volumeID string, statuses ...string) error {
@@ -508,8 +614,9 @@ func (vo *VolumeOperationsImpl) WaitStatus(ctx context.Context, volumeID string,
 				}
 				continue
 			}
+			currStatus := getWaitStatus(v)
 			for _, s := range statuses {
-				if v.Spec.CSIStatus == s {
+				if currStatus == s {
 					if s == apiV1.Failed {
 						return fmt.Errorf("volume has reached Failed status")
 					}
@@ -520,6 +627,16 @@ func (vo *VolumeOperationsImpl) WaitStatus(ctx context.Context, volumeID string,
 	}
 }
 
+// getWaitStatus returns CSI status of volume, Creating volume with asynchronous filesystem creation
+// in progress has Formatting status
+func getWaitStatus(v *volumecrd.Volume) string {
+	if v.Spec.CSIStatus == apiV1.Creating &&
+		v.Annotations[apiV1.VolumeAnnotationFormat] == apiV1.VolumeAnnotationFormatInProgress {
+		return apiV1.Formatting
+	}
+	return v.Spec.CSIStatus
+}
+
 // ExpandVolume updates Volume status to Resizing to trigger expansion in reconcile, if volume has already had status
 // Resizing or Resized, function doesn't do anything. In case of statuses beside VolumeReady, Created, Published function return error
 // Receive golang context, volume CR, requiredBytes as int
@@ -539,20 +656,34 @@ func (vo *VolumeOperationsImpl) ExpandVolume(ctx context.Context, volume *volume
 			return status.Error(codes.FailedPrecondition,
 				fmt.Sprintf("StorageClass %s doesn't support resizing", volume.Spec.StorageClass))
 		}
+		acSize := requiredBytes - volume.Spec.Size
 		capacity, err := vo.crHelper.GetACByLocation(volume.Spec.Location)
 		if err != nil {
+			// AC of LVG is removed when the whole LVG is used by volumes
+			if errors.Is(err, baseerr.ErrorNotFound) {
+				return status.Errorf(codes.OutOfRange,
+					"Not enough capacity to expand volume: requested - %d, available - 0", acSize)
+			}
 			ll.Errorf("Failed to get AC by location %s", volume.Spec.Location)
 			return status.Error(codes.Internal, "Unable to read AC")
 		}
-
-		acSize := requiredBytes - volume.Spec.Size
-		if capacity.Spec.Size < acSize {
+		// capacity reserved for volumes of scheduled pods can't be used for expansion
+		reserved, err := vo.getReservedSize(ctx, capacity.Name)
+		if err != nil {
+			ll.Errorf("Failed to read reservations of AC %s: %v", capacity.Name, err)
+			return status.Error(codes.Internal, "Unable to read reservations")
+		}
+		available := capacity.Spec.Size - reserved
+		if available < acSize {
 			return status.Error(codes.OutOfRange,
-				fmt.Sprintf("Not enough capacity to expand volume: requested - %d, available - %d", requiredBytes, capacity.Spec.Size))
+				fmt.Sprintf("Not enough capacity to expand volume: requested - %d, available - %d, reserved - %d",
+					acSize, available, reserved))
 		}
-		capacity.Spec.Size -= acSize
-		if err := vo.k8sClient.UpdateCRWithAttempts(ctx, capacity, 5); err != nil {
+		if err := vo.acSizeUpdater.Update(ctx, capacity.Name, -acSize); err != nil {
 			ll.Errorf("Failed to update AC, error: %v", err)
+			if status.Code(err) == codes.OutOfRange {
+				return err
+			}
 			return status.Error(codes.Internal, "Unable to reserve AC")
 		}
 
@@ -574,6 +705,28 @@ func (vo *VolumeOperationsImpl) ExpandVolume(ctx context.Context, volume *volume
 	return nil
 }
 
+// getReservedSize returns capacity of AC which is reserved by confirmed reservations for volumes which aren't created yet
+func (vo *VolumeOperationsImpl) getReservedSize(ctx context.Context, acName string) (int64, error) {
+	resReader := capacityplanner.NewACRReader(vo.k8sClient, vo.log, false)
+	reservations, err := resReader.ReadReservations(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var reserved int64
+	for _, reservation := range reservations {
+		if reservation.Spec.Status != apiV1.ReservationConfirmed {
+			continue
+		}
+		for _, request := range reservation.Spec.ReservationRequests {
+			if util.ContainsString(request.Reservations, acName) {
+				reserved += request.CapacityRequest.Size
+			}
+		}
+	}
+	return reserved, nil
+}
+
 // UpdateCRsAfterVolumeExpansion update volume and AC crs after volume expansion
 // Receive golang context, volume spec
 // Return error
@@ -610,8 +763,7 @@ func (vo *VolumeOperationsImpl) UpdateCRsAfterVolumeExpansion(ctx context.Contex
 			ll.Errorf("Failed to read AC: %v", err)
 		} else {
 			acSize := requiredBytes - volume.Spec.Size
-			ac.Spec.Size += acSize
-			if err = vo.k8sClient.UpdateCRWithAttempts(ctx, ac, 5); err != nil {
+			if err = vo.acSizeUpdater.Update(ctx, ac.Name, acSize); err != nil {
 				ll.Errorf("Failed to update AC: %v", err)
 			}
 		}
@@ -262,6 +262,102 @@ func TestVolumeOperationsImpl_CreateVolume_HDDLVGVolumeCreated(t *testing.T) {
 	assert.Equal(t, expectedVolume, createdVolume)
 }
 
+func TestVolumeOperationsImpl_CreateVolume_QuotaExceeded(t *testing.T) {
+	var (
+		testAC     = testAC1.DeepCopy()
+		testVolume = testVolume1.DeepCopy()
+		testPVC    = testPVC1.DeepCopy()
+	)
+	k8sClient, err := k8s.GetFakeKubeClient(namespace, testLogger)
+	assert.Nil(t, err)
+	featureConf := featureconfig.NewFeatureConfig()
+	featureConf.Update(featureconfig.FeatureNamespaceQuota, true)
+	svc := NewVolumeOperationsImpl(k8sClient, testLogger, cache.NewMemCache(), featureConf)
+
+	parameters := map[string]string{
+		util.ClaimNamespaceKey: testNS,
+		util.ClaimNameKey:      testPVC.Name,
+	}
+	volumeInfo, err := util.NewVolumeInfo(parameters)
+	assert.Nil(t, err)
+	ctx := context.WithValue(testCtx, util.VolumeInfoKey, volumeInfo)
+
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        testNS,
+		Annotations: map[string]string{QuotaAnnotationPrefix + "hdd": "1Mi"},
+	}}
+	assert.Nil(t, svc.k8sClient.Create(testCtx, ns))
+	assert.Nil(t, svc.k8sClient.CreateCR(ctx, testAC.Name, testAC))
+	assert.Nil(t, svc.k8sClient.Create(testCtx, testPVC))
+	testACR := getTestACR(testVolume.Spec.Size, apiV1.StorageClassHDD, parameters[util.ClaimNameKey],
+		testVolume.Namespace, []*accrd.AvailableCapacity{testAC})
+	assert.Nil(t, svc.k8sClient.CreateCR(ctx, testACR.Name, testACR))
+
+	_, err = svc.CreateVolume(ctx, api.Volume{
+		Id:           testVolume.Spec.Id,
+		StorageClass: testVolume.Spec.StorageClass,
+		NodeId:       testVolume.Spec.NodeId,
+		Size:         testVolume.Spec.Size,
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// reservation is released
+	acr := &acrcrd.AvailableCapacityReservation{}
+	assert.True(t, k8sError.IsNotFound(svc.k8sClient.ReadCR(ctx, testACR.Name, "", acr)))
+}
+
+// AC was consumed by concurrent request, Volume CR isn't created
+func TestVolumeOperationsImpl_CreateVolume_HDDLVGNotEnoughCapacity(t *testing.T) {
+	var (
+		svc           = setupVOOperationsTest(t)
+		requiredSC    = apiV1.StorageClassHDDLVG
+		volumeID      = "pvc-aaaa-bbbb"
+		acName        = "aaaa-1111"
+		requiredBytes = int64(util.GBYTE)
+		testPVC       = testPVC1.DeepCopy()
+		ctxWithID     = context.WithValue(testCtx, base.RequestUUID, volumeID)
+		testAC        = &accrd.AvailableCapacity{
+			TypeMeta:   k8smetav1.TypeMeta{Kind: "AvailableCapacity", APIVersion: apiV1.APIV1Version},
+			ObjectMeta: k8smetav1.ObjectMeta{Name: acName, CreationTimestamp: k8smetav1.NewTime(time.Now())},
+			Spec: api.AvailableCapacity{
+				StorageClass: requiredSC,
+				Size:         requiredBytes / 2,
+			},
+		}
+		testACR = &acrcrd.AvailableCapacityReservation{
+			TypeMeta:   k8smetav1.TypeMeta{Kind: "AvailableCapacityReservation", APIVersion: apiV1.APIV1Version},
+			ObjectMeta: k8smetav1.ObjectMeta{Name: "test-ac", CreationTimestamp: k8smetav1.NewTime(time.Now())},
+			Spec: api.AvailableCapacityReservation{
+				Namespace: testNS,
+				Status:    apiV1.ReservationConfirmed,
+				ReservationRequests: []*api.ReservationRequest{
+					{
+						CapacityRequest: &api.CapacityRequest{
+							StorageClass: requiredSC,
+							Size:         requiredBytes,
+							Name:         volumeID,
+						},
+						Reservations: []string{acName}},
+				},
+			},
+		}
+	)
+	testPVC.ObjectMeta.Name = volumeID
+	assert.Nil(t, svc.k8sClient.Create(ctxWithID, testPVC))
+	assert.Nil(t, svc.k8sClient.CreateCR(ctxWithID, testAC.Name, testAC))
+	assert.Nil(t, svc.k8sClient.CreateCR(ctxWithID, testACR.Name, testACR))
+
+	ctx := context.WithValue(testCtx, util.VolumeInfoKey, &util.VolumeInfo{Name: volumeID, Namespace: testNS})
+	_, err := svc.CreateVolume(ctx, api.Volume{Id: volumeID, StorageClass: requiredSC, Size: requiredBytes})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	volume := &volumecrd.Volume{}
+	assert.True(t, k8sError.IsNotFound(svc.k8sClient.ReadCR(ctx, volumeID, testNS, volume)))
+	ac := &accrd.AvailableCapacity{}
+	assert.Nil(t, svc.k8sClient.ReadCR(ctx, acName, "", ac))
+	assert.Equal(t, requiredBytes/2, ac.Spec.Size)
+}
+
 // Volume CR exists and has "failed" CSIStatus
 func TestVolumeOperationsImpl_CreateVolume_FaileCauseExist(t *testing.T) {
 	var (
@@ -498,6 +594,24 @@ func TestVolumeOperationsImpl_WaitStatus_Success(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestVolumeOperationsImpl_WaitStatus_Formatting(t *testing.T) {
+	var (
+		svc = setupVOOperationsTest(t)
+		v   = testVolume1.DeepCopy()
+	)
+	v.Spec.CSIStatus = apiV1.Creating
+	v.Annotations = map[string]string{apiV1.VolumeAnnotationFormat: apiV1.VolumeAnnotationFormatInProgress}
+	svc.cache.Set(v.Name, v.Namespace)
+	err := svc.k8sClient.CreateCR(testCtx, v.Name, v)
+	assert.Nil(t, err)
+
+	ctx, closeFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer closeFn()
+
+	err = svc.WaitStatus(ctx, v.Name, apiV1.Failed, apiV1.Created, apiV1.Formatting)
+	assert.Nil(t, err)
+}
+
 func TestVolumeOperationsImpl_WaitStatus_Fails(t *testing.T) {
 	var (
 		svc     = setupVOOperationsTest(t)
@@ -583,7 +697,7 @@ func TestVolumeOperationsImpl_ExpandVolume_DifferentStatuses(t *testing.T) {
 	err = svc.k8sClient.CreateCR(testCtx, testVolume1Name, &volumeCR)
 	volAC := &accrd.AvailableCapacity{
 		TypeMeta:   k8smetav1.TypeMeta{Kind: "AvailableCapacity", APIVersion: apiV1.APIV1Version},
-		ObjectMeta: k8smetav1.ObjectMeta{Name: uuid.New().String(), Namespace: testNS},
+		ObjectMeta: k8smetav1.ObjectMeta{Name: uuid.New().String()},
 		Spec: api.AvailableCapacity{
 			Size:         10000,
 			StorageClass: apiV1.StorageClassSystemLVG,
@@ -642,18 +756,18 @@ func TestVolumeOperationsImpl_ExpandVolume_Fail(t *testing.T) {
 		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
 	}
 
-	// Failed to get AC
+	// AC doesn't exist, LVG is fully used
 	volumeCR.ObjectMeta.ResourceVersion = ""
 	volumeCR.Spec.StorageClass = apiV1.StorageClassSystemLVG
 	assert.NotNil(t, svc.k8sClient.UpdateCR(testCtx, volumeCR))
 	err := svc.ExpandVolume(testCtx, volumeCR, capacity)
 	assert.NotNil(t, err)
-	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
 
 	// Required capacity is more than capacity of AC
 	volAC := &accrd.AvailableCapacity{
 		TypeMeta:   k8smetav1.TypeMeta{Kind: "AvailableCapacity", APIVersion: apiV1.APIV1Version},
-		ObjectMeta: k8smetav1.ObjectMeta{Name: uuid.New().String(), Namespace: testNS},
+		ObjectMeta: k8smetav1.ObjectMeta{Name: uuid.New().String()},
 		Spec: api.AvailableCapacity{
 			Size:         10000,
 			StorageClass: apiV1.StorageClassSystemLVG,
@@ -666,6 +780,50 @@ func TestVolumeOperationsImpl_ExpandVolume_Fail(t *testing.T) {
 	assert.Equal(t, codes.OutOfRange, status.Code(err))
 }
 
+func TestVolumeOperationsImpl_ExpandVolume_Reserved(t *testing.T) {
+	var (
+		svc      = setupVOOperationsTest(t)
+		volumeCR = testVolume1.DeepCopy()
+		acName   = uuid.New().String()
+		volAC    = &accrd.AvailableCapacity{
+			TypeMeta:   k8smetav1.TypeMeta{Kind: "AvailableCapacity", APIVersion: apiV1.APIV1Version},
+			ObjectMeta: k8smetav1.ObjectMeta{Name: acName},
+			Spec: api.AvailableCapacity{
+				Size:         10000,
+				StorageClass: apiV1.StorageClassHDDLVG,
+				Location:     testDrive1UUID,
+			},
+		}
+		acr = &acrcrd.AvailableCapacityReservation{
+			TypeMeta:   k8smetav1.TypeMeta{Kind: "AvailableCapacityReservation", APIVersion: apiV1.APIV1Version},
+			ObjectMeta: k8smetav1.ObjectMeta{Name: "pending-pod"},
+			Spec: api.AvailableCapacityReservation{
+				Namespace: testNS,
+				Status:    apiV1.ReservationConfirmed,
+				ReservationRequests: []*api.ReservationRequest{{
+					CapacityRequest: &api.CapacityRequest{StorageClass: apiV1.StorageClassHDDLVG, Size: 8000, Name: "pvc"},
+					Reservations:    []string{acName},
+				}},
+			},
+		}
+	)
+	volumeCR.ObjectMeta.ResourceVersion = ""
+	volumeCR.Spec.CSIStatus = apiV1.Published
+	volumeCR.Spec.StorageClass = apiV1.StorageClassHDDLVG
+	volumeCR.Spec.Location = testDrive1UUID
+	assert.Nil(t, svc.k8sClient.CreateCR(testCtx, volumeCR.Name, volumeCR))
+	assert.Nil(t, svc.k8sClient.CreateCR(testCtx, acName, volAC))
+	assert.Nil(t, svc.k8sClient.CreateCR(testCtx, acr.Name, acr))
+
+	// 10000 is free, but 8000 is reserved for volume of scheduled pod
+	err := svc.ExpandVolume(testCtx, volumeCR, volumeCR.Spec.Size+5000)
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+	assert.Contains(t, err.Error(), "reserved - 8000")
+
+	assert.Nil(t, svc.ExpandVolume(testCtx, volumeCR, volumeCR.Spec.Size+2000))
+	assert.Equal(t, apiV1.Resizing, volumeCR.Spec.CSIStatus)
+}
+
 func TestVolumeOperationsImpl_UpdateCRsAfterVolumeExpansion(t *testing.T) {
 	var (
 		svc      = setupVOOperationsTest(t)
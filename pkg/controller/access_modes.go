@@ -0,0 +1,69 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// supportedAccessModes are access modes of volume which is located on local drive and is available on one node only
+var supportedAccessModes = []csi.VolumeCapability_AccessMode_Mode{
+	csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+	csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
+	csi.VolumeCapability_AccessMode_SINGLE_NODE_SINGLE_WRITER,
+	csi.VolumeCapability_AccessMode_SINGLE_NODE_MULTI_WRITER,
+}
+
+// validateAccessModes checks that access modes of all capabilities can be provided by the volume.
+// Multi node access modes (RWX, ROX) are rejected before provisioning, otherwise volume is created and pods on other
+// nodes fail on attachment
+// Returns INVALID_ARGUMENT error if access mode is missing or isn't supported
+func validateAccessModes(capabilities []*csi.VolumeCapability) error {
+	for _, c := range capabilities {
+		if c.GetAccessMode() == nil {
+			return status.Error(codes.InvalidArgument, "Volume access mode missing in request")
+		}
+		mode := c.GetAccessMode().GetMode()
+		if !isAccessModeSupported(mode) {
+			return status.Errorf(codes.InvalidArgument,
+				"access mode %s isn't supported: volume is located on local drive and is available on one node only, "+
+					"supported: %s", mode, supportedAccessModesString())
+		}
+	}
+	return nil
+}
+
+func isAccessModeSupported(mode csi.VolumeCapability_AccessMode_Mode) bool {
+	for _, m := range supportedAccessModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func supportedAccessModesString() string {
+	modes := make([]string, 0, len(supportedAccessModes))
+	for _, m := range supportedAccessModes {
+		modes = append(modes, m.String())
+	}
+	return strings.Join(modes, ", ")
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func capabilityWithMode(mode csi.VolumeCapability_AccessMode_Mode) *csi.VolumeCapability {
+	return &csi.VolumeCapability{AccessMode: &csi.VolumeCapability_AccessMode{Mode: mode}}
+}
+
+func TestValidateAccessModes(t *testing.T) {
+	for _, mode := range supportedAccessModes {
+		assert.Nil(t, validateAccessModes([]*csi.VolumeCapability{capabilityWithMode(mode)}), mode.String())
+	}
+
+	for _, mode := range []csi.VolumeCapability_AccessMode_Mode{
+		csi.VolumeCapability_AccessMode_UNKNOWN,
+		csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
+		csi.VolumeCapability_AccessMode_MULTI_NODE_SINGLE_WRITER,
+		csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+	} {
+		err := validateAccessModes([]*csi.VolumeCapability{
+			capabilityWithMode(csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER),
+			capabilityWithMode(mode),
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), mode.String())
+		assert.Contains(t, err.Error(), mode.String())
+	}
+
+	err := validateAccessModes([]*csi.VolumeCapability{{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,194 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admission contains validating admission webhook which protects volumes from pod deletion
+// while volume is in state where unmount leaves it broken
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+const (
+	// Path is HTTP path of pod deletion webhook
+	Path = "/validate-pod-deletion"
+	// PodAnnotationAllowUnsafeDeletion allows deletion of pod which volumes are in unsafe state
+	PodAnnotationAllowUnsafeDeletion = "csi-baremetal.dell.com/allow-unsafe-deletion"
+	// maxRequestSize limits size of AdmissionReview body
+	maxRequestSize = 3 * 1024 * 1024
+)
+
+// PodDeletionWebhook rejects deletion of pods which volumes are being formatted or resized,
+// pod with PodAnnotationAllowUnsafeDeletion annotation is deleted anyway
+type PodDeletionWebhook struct {
+	client   *k8s.KubeClient
+	crHelper *k8s.CRHelper
+	log      *logrus.Entry
+}
+
+// NewPodDeletionWebhook is the constructor for PodDeletionWebhook struct
+// Receives an instance of base.KubeClient, CRReader (cache) and logrus logger
+// Returns an instance of PodDeletionWebhook
+func NewPodDeletionWebhook(client *k8s.KubeClient, k8sCache k8s.CRReader, logger *logrus.Logger) *PodDeletionWebhook {
+	return &PodDeletionWebhook{
+		client:   client,
+		crHelper: k8s.NewCRHelper(client, logger).SetReader(k8sCache),
+		log:      logger.WithField("component", "PodDeletionWebhook"),
+	}
+}
+
+// ServeHTTP reads AdmissionReview and writes it back with response
+func (w *PodDeletionWebhook) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ll := w.log.WithFields(logrus.Fields{
+		"method": "ServeHTTP",
+		"remote": req.RemoteAddr,
+	})
+
+	if req.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, maxRequestSize))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		ll.Errorf("Unable to decode AdmissionReview: %v", err)
+		http.Error(rw, "AdmissionReview is expected", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = w.review(req.Context(), review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(review); err != nil {
+		ll.Errorf("Unable to write AdmissionReview: %v", err)
+	}
+}
+
+// review allows deletion of pod unless one of its volumes is in unsafe state,
+// errors of reading volumes are logged and deletion is allowed
+func (w *PodDeletionWebhook) review(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	ll := w.log.WithFields(logrus.Fields{
+		"method": "review",
+		"pod":    req.Namespace + "/" + req.Name,
+	})
+	allowed := &admissionv1.AdmissionResponse{Allowed: true}
+
+	if req.Operation != admissionv1.Delete || req.OldObject.Raw == nil {
+		return allowed
+	}
+	pod := &coreV1.Pod{}
+	if err := json.Unmarshal(req.OldObject.Raw, pod); err != nil {
+		ll.Errorf("Unable to decode pod: %v", err)
+		return allowed
+	}
+	if pod.GetAnnotations()[PodAnnotationAllowUnsafeDeletion] == "true" {
+		ll.Infof("Unsafe deletion is allowed by annotation %s", PodAnnotationAllowUnsafeDeletion)
+		return allowed
+	}
+
+	reasons := w.getUnsafeVolumes(ctx, ll, pod)
+	if len(reasons) == 0 {
+		return allowed
+	}
+	msg := fmt.Sprintf("pod volumes are in unsafe state: %s, wait for completion or set annotation %s=true",
+		strings.Join(reasons, "; "), PodAnnotationAllowUnsafeDeletion)
+	ll.Warnf("Deletion is rejected: %s", msg)
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonForbidden,
+			Code:    http.StatusForbidden,
+			Message: msg,
+		},
+	}
+}
+
+// getUnsafeVolumes returns reasons of unsafe state of CSI volumes of the pod
+func (w *PodDeletionWebhook) getUnsafeVolumes(ctx context.Context, ll *logrus.Entry, pod *coreV1.Pod) []string {
+	var reasons []string
+	for _, v := range pod.Spec.Volumes {
+		claimName := ""
+		switch {
+		case v.PersistentVolumeClaim != nil:
+			claimName = v.PersistentVolumeClaim.ClaimName
+		case v.Ephemeral != nil:
+			// PVC of generic ephemeral volume is named <pod name>-<volume name>
+			claimName = pod.Name + "-" + v.Name
+		default:
+			continue
+		}
+
+		pvc := &coreV1.PersistentVolumeClaim{}
+		if err := w.client.ReadCR(ctx, claimName, pod.Namespace, pvc); err != nil {
+			ll.Debugf("Unable to read PVC %s: %v", claimName, err)
+			continue
+		}
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv := &coreV1.PersistentVolume{}
+		if err := w.client.Get(ctx, k8sCl.ObjectKey{Name: pvc.Spec.VolumeName}, pv); err != nil {
+			ll.Debugf("Unable to read PV %s: %v", pvc.Spec.VolumeName, err)
+			continue
+		}
+		if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != base.PluginName {
+			continue
+		}
+		volume, err := w.crHelper.GetVolumeByID(pv.Spec.CSI.VolumeHandle)
+		if err != nil {
+			ll.Errorf("Unable to read volume %s: %v", pv.Spec.CSI.VolumeHandle, err)
+			continue
+		}
+		if reason := getUnsafeReason(volume); reason != "" {
+			reasons = append(reasons, fmt.Sprintf("volume %s %s", volume.Spec.Id, reason))
+		}
+	}
+	return reasons
+}
+
+// getUnsafeReason returns description of operation which is performed on volume device and is broken by
+// unstage of the volume, empty if there is no such operation
+func getUnsafeReason(volume *volumecrd.Volume) string {
+	switch {
+	case volume.Spec.CSIStatus == apiV1.Resizing:
+		return "is being resized"
+	case volume.Annotations[apiV1.VolumeAnnotationFormat] == apiV1.VolumeAnnotationFormatInProgress:
+		return "is being wiped and formatted"
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+var (
+	testCtx    = context.Background()
+	testLogger = logrus.New()
+	testNs     = "default"
+	testVolID  = "pvc-1"
+)
+
+func prepareWebhook(t *testing.T, csiStatus string) *PodDeletionWebhook {
+	client, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+
+	pvc := &coreV1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: testNs},
+		Spec: coreV1.PersistentVolumeClaimSpec{VolumeName: testVolID}}
+	assert.Nil(t, client.CreateCR(testCtx, pvc.Name, pvc))
+	pv := &coreV1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: testVolID},
+		Spec: coreV1.PersistentVolumeSpec{PersistentVolumeSource: coreV1.PersistentVolumeSource{
+			CSI: &coreV1.CSIPersistentVolumeSource{Driver: base.PluginName, VolumeHandle: testVolID}}}}
+	assert.Nil(t, client.CreateCR(testCtx, pv.Name, pv))
+	volume := client.ConstructVolumeCR(testVolID, testNs, nil, api.Volume{Id: testVolID, CSIStatus: csiStatus})
+	assert.Nil(t, client.CreateCR(testCtx, volume.Name, volume))
+
+	return NewPodDeletionWebhook(client, client, testLogger)
+}
+
+func getDeleteRequest(t *testing.T, annotations map[string]string) *admissionv1.AdmissionRequest {
+	pod := &coreV1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: testNs, Annotations: annotations},
+		Spec: coreV1.PodSpec{Volumes: []coreV1.Volume{
+			{Name: "data", VolumeSource: coreV1.VolumeSource{
+				PersistentVolumeClaim: &coreV1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+			{Name: "config", VolumeSource: coreV1.VolumeSource{ConfigMap: &coreV1.ConfigMapVolumeSource{}}},
+		}}}
+	raw, err := json.Marshal(pod)
+	assert.Nil(t, err)
+	return &admissionv1.AdmissionRequest{UID: types.UID("uid"), Name: pod.Name, Namespace: pod.Namespace,
+		Operation: admissionv1.Delete, OldObject: runtime.RawExtension{Raw: raw}}
+}
+
+func TestPodDeletionWebhook_review(t *testing.T) {
+	// volume is healthy
+	w := prepareWebhook(t, apiV1.Published)
+	assert.True(t, w.review(testCtx, getDeleteRequest(t, nil)).Allowed)
+
+	// volume is being resized
+	w = prepareWebhook(t, apiV1.Resizing)
+	resp := w.review(testCtx, getDeleteRequest(t, nil))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, testVolID)
+
+	// override
+	resp = w.review(testCtx, getDeleteRequest(t, map[string]string{PodAnnotationAllowUnsafeDeletion: "true"}))
+	assert.True(t, resp.Allowed)
+
+	// not a deletion
+	req := getDeleteRequest(t, nil)
+	req.Operation = admissionv1.Update
+	assert.True(t, w.review(testCtx, req).Allowed)
+}
+
+func TestPodDeletionWebhook_ServeHTTP(t *testing.T) {
+	w := prepareWebhook(t, apiV1.Resizing)
+
+	body, err := json.Marshal(&admissionv1.AdmissionReview{Request: getDeleteRequest(t, nil)})
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	w.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(body)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	review := &admissionv1.AdmissionReview{}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), review))
+	assert.False(t, review.Response.Allowed)
+	assert.Equal(t, types.UID("uid"), review.Response.UID)
+
+	resp = httptest.NewRecorder()
+	w.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, Path, bytes.NewReader([]byte("{}"))))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,254 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package alerting contains sink which forwards critical CSI events to external webhook or Alertmanager
+// for clusters which metrics aren't scraped by Prometheus
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+const (
+	// FormatWebhook sends every event as JSON object in separate request
+	FormatWebhook = "webhook"
+	// FormatAlertmanager sends events as alerts to Alertmanager API v2, URL must point to /api/v2/alerts
+	FormatAlertmanager = "alertmanager"
+
+	// DefaultReasons are reasons of events which are forwarded by default: drive failure, low capacity and
+	// evacuation of volumes from failed drive
+	DefaultReasons = "DriveHealthFailure,NodeDriveFailure,NodeCapacityLow,ClusterCapacityLow,DriveEvacuation"
+	// DefaultCheckInterval is the default interval between checks of new events
+	DefaultCheckInterval = 30 * time.Second
+
+	requestTimeout = 10 * time.Second
+)
+
+// Alert is a payload of webhook format
+type Alert struct {
+	Reason    string    `json:"reason"`
+	Type      string    `json:"type"`
+	SymptomID string    `json:"symptomID"`
+	Message   string    `json:"message"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace,omitempty"`
+	Node      string    `json:"node,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// alertmanagerAlert is an alert of Alertmanager API v2
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+}
+
+// Sink periodically reads CSI events with symptom code and sends events with configured reasons to URL
+type Sink struct {
+	client  *k8s.KubeClient
+	http    *http.Client
+	url     string
+	format  string
+	reasons map[string]bool
+	// events which are already sent, key - namespace/name of event, value - count of event occurrences
+	sent map[string]int32
+	// events which were last seen before start of the sink aren't sent
+	since time.Time
+	log   *logrus.Entry
+}
+
+// NewSink is the constructor for Sink struct
+// Receives an instance of base.KubeClient, URL, format (webhook or alertmanager), comma separated reasons of events
+// and logrus logger
+// Returns an instance of Sink or error if format or reasons are invalid
+func NewSink(client *k8s.KubeClient, url, format, reasons string, logger *logrus.Logger) (*Sink, error) {
+	if format != FormatWebhook && format != FormatAlertmanager {
+		return nil, fmt.Errorf("format %s isn't supported, expected %s or %s", format, FormatWebhook, FormatAlertmanager)
+	}
+	s := &Sink{
+		client:  client,
+		http:    &http.Client{Timeout: requestTimeout},
+		url:     url,
+		format:  format,
+		reasons: make(map[string]bool),
+		sent:    make(map[string]int32),
+		since:   time.Now(),
+		log:     logger.WithField("component", "AlertingSink"),
+	}
+	for _, reason := range strings.Split(reasons, ",") {
+		if reason = strings.TrimSpace(reason); reason != "" {
+			s.reasons[reason] = true
+		}
+	}
+	if len(s.reasons) == 0 {
+		return nil, fmt.Errorf("reasons of events aren't set")
+	}
+	return s, nil
+}
+
+// Run performs Check with the provided interval until context is done
+func (s *Sink) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Check(ctx); err != nil {
+				s.log.Errorf("Alerting check failed: %v", err)
+			}
+		}
+	}
+}
+
+// Check reads events and sends new occurrences of events with configured reasons
+// Events which weren't sent because of error are retried on the next check
+func (s *Sink) Check(ctx context.Context) error {
+	ll := s.log.WithField("method", "Check")
+	events := &coreV1.EventList{}
+	if err := s.client.List(ctx, events, k8sCl.HasLabels{eventing.SymptomCodeLabelKey}); err != nil {
+		return err
+	}
+
+	seen := make(map[string]int32, len(events.Items))
+	var alerts []*coreV1.Event
+	for i := range events.Items {
+		event := &events.Items[i]
+		if !s.reasons[event.Reason] || lastTimestamp(event).Before(s.since) {
+			continue
+		}
+		key := event.Namespace + "/" + event.Name
+		seen[key] = event.Count
+		if count, ok := s.sent[key]; ok && count >= event.Count {
+			continue
+		}
+		alerts = append(alerts, event)
+	}
+
+	var lastErr error
+	for _, event := range alerts {
+		if err := s.send(ctx, event); err != nil {
+			ll.Errorf("Unable to send event %s of %s %s: %v", event.Reason,
+				event.InvolvedObject.Kind, event.InvolvedObject.Name, err)
+			lastErr = err
+			// keep previous count, so event is sent again on the next check
+			key := event.Namespace + "/" + event.Name
+			if count, ok := s.sent[key]; ok {
+				seen[key] = count
+			} else {
+				delete(seen, key)
+			}
+			continue
+		}
+		ll.Infof("Event %s of %s %s is sent", event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name)
+	}
+	// events removed by TTL are forgotten
+	s.sent = seen
+	return lastErr
+}
+
+// send posts event to URL in configured format
+func (s *Sink) send(ctx context.Context, event *coreV1.Event) error {
+	alert := toAlert(event)
+	var (
+		body []byte
+		err  error
+	)
+	if s.format == FormatAlertmanager {
+		body, err = json.Marshal([]alertmanagerAlert{toAlertmanagerAlert(alert)})
+	} else {
+		body, err = json.Marshal(alert)
+	}
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// toAlert converts k8s Event to Alert
+func toAlert(event *coreV1.Event) *Alert {
+	return &Alert{
+		Reason:    event.Reason,
+		Type:      event.Type,
+		SymptomID: event.Labels[eventing.SymptomCodeLabelKey],
+		Message:   event.Message,
+		Kind:      event.InvolvedObject.Kind,
+		Name:      event.InvolvedObject.Name,
+		Namespace: event.InvolvedObject.Namespace,
+		Node:      event.Source.Host,
+		Time:      lastTimestamp(event),
+	}
+}
+
+// toAlertmanagerAlert converts Alert to alert of Alertmanager API, reason is used as alert name
+func toAlertmanagerAlert(alert *Alert) alertmanagerAlert {
+	labels := map[string]string{
+		"alertname": alert.Reason,
+		"severity":  strings.ToLower(alert.Type),
+		"symptomID": alert.SymptomID,
+		"kind":      alert.Kind,
+		"name":      alert.Name,
+	}
+	if alert.Namespace != "" {
+		labels["namespace"] = alert.Namespace
+	}
+	if alert.Node != "" {
+		labels["node"] = alert.Node
+	}
+	return alertmanagerAlert{
+		Labels:      labels,
+		Annotations: map[string]string{"message": alert.Message},
+		StartsAt:    alert.Time,
+	}
+}
+
+// lastTimestamp returns time of the last occurrence of event
+func lastTimestamp(event *coreV1.Event) time.Time {
+	if event.LastTimestamp.IsZero() {
+		return event.FirstTimestamp.Time
+	}
+	return event.LastTimestamp.Time
+}
@@ -0,0 +1,155 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+var (
+	testCtx    = context.Background()
+	testLogger = logrus.New()
+)
+
+// receiver stores bodies of requests and responds with status
+type receiver struct {
+	sync.Mutex
+	bodies [][]byte
+	status int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+	body, _ := ioutil.ReadAll(req.Body)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.status)
+}
+
+func TestNewSink(t *testing.T) {
+	client, err := k8s.GetFakeKubeClient("default", testLogger)
+	assert.Nil(t, err)
+
+	_, err = NewSink(client, "http://localhost", "unknown", DefaultReasons, testLogger)
+	assert.NotNil(t, err)
+	_, err = NewSink(client, "http://localhost", FormatWebhook, " , ", testLogger)
+	assert.NotNil(t, err)
+
+	sink, err := NewSink(client, "http://localhost", FormatAlertmanager, DefaultReasons, testLogger)
+	assert.Nil(t, err)
+	assert.Len(t, sink.reasons, 5)
+}
+
+func TestSink_Check_Webhook(t *testing.T) {
+	sink, client, recv := prepareSink(t, FormatWebhook)
+	createEvent(t, client, "drive-failed", "DriveHealthFailure", sink.since.Add(time.Second))
+	createEvent(t, client, "drive-good", "DriveHealthGood", sink.since.Add(time.Second))
+	createEvent(t, client, "drive-failed-before-start", "DriveHealthFailure", sink.since.Add(-time.Minute))
+
+	assert.Nil(t, sink.Check(testCtx))
+	assert.Len(t, recv.bodies, 1)
+	alert := &Alert{}
+	assert.Nil(t, json.Unmarshal(recv.bodies[0], alert))
+	assert.Equal(t, "DriveHealthFailure", alert.Reason)
+	assert.Equal(t, "Drive", alert.Kind)
+	assert.Equal(t, "drive-uuid", alert.Name)
+	assert.Equal(t, "node-1", alert.Node)
+	assert.Equal(t, "CSI-01", alert.SymptomID)
+
+	// event is sent once
+	assert.Nil(t, sink.Check(testCtx))
+	assert.Len(t, recv.bodies, 1)
+}
+
+func TestSink_Check_Alertmanager(t *testing.T) {
+	sink, client, recv := prepareSink(t, FormatAlertmanager)
+	createEvent(t, client, "capacity-low", "NodeCapacityLow", sink.since.Add(time.Second))
+
+	assert.Nil(t, sink.Check(testCtx))
+	assert.Len(t, recv.bodies, 1)
+	var alerts []alertmanagerAlert
+	assert.Nil(t, json.Unmarshal(recv.bodies[0], &alerts))
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "NodeCapacityLow", alerts[0].Labels["alertname"])
+	assert.Equal(t, "warning", alerts[0].Labels["severity"])
+	assert.Equal(t, "message", alerts[0].Annotations["message"])
+}
+
+func TestSink_Check_Retry(t *testing.T) {
+	sink, client, recv := prepareSink(t, FormatWebhook)
+	recv.status = http.StatusServiceUnavailable
+	createEvent(t, client, "drive-failed", "DriveHealthFailure", sink.since.Add(time.Second))
+
+	assert.NotNil(t, sink.Check(testCtx))
+	assert.Len(t, recv.bodies, 1)
+
+	recv.status = http.StatusOK
+	assert.Nil(t, sink.Check(testCtx))
+	assert.Len(t, recv.bodies, 2)
+	assert.Nil(t, sink.Check(testCtx))
+	assert.Len(t, recv.bodies, 2)
+}
+
+func prepareSink(t *testing.T, format string) (*Sink, *k8s.KubeClient, *receiver) {
+	client, err := k8s.GetFakeKubeClient("default", testLogger)
+	assert.Nil(t, err)
+	recv := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(recv)
+	t.Cleanup(server.Close)
+
+	sink, err := NewSink(client, server.URL, format, DefaultReasons, testLogger)
+	assert.Nil(t, err)
+	return sink, client, recv
+}
+
+func createEvent(t *testing.T, client *k8s.KubeClient, name, reason string, timestamp time.Time) {
+	severity, symptomID := eventing.WarningType, "CSI-06"
+	if reason == "DriveHealthFailure" {
+		severity, symptomID = eventing.ErrorType, "CSI-01"
+	}
+	event := &coreV1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{eventing.SymptomCodeLabelKey: symptomID},
+		},
+		InvolvedObject: coreV1.ObjectReference{Kind: "Drive", Name: "drive-uuid"},
+		Reason:         reason,
+		Message:        "message",
+		Type:           severity,
+		Source:         coreV1.EventSource{Host: "node-1"},
+		FirstTimestamp: metav1.NewTime(timestamp),
+		LastTimestamp:  metav1.NewTime(timestamp),
+		Count:          1,
+	}
+	assert.Nil(t, client.Create(testCtx, event))
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2020 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+// Parameter keys of cache tier
+const (
+	// CacheModeKey is a parameter key to front HDD volume by SSD cache, the only supported value is bcache
+	CacheModeKey = "cacheMode"
+	// CacheSizeKey is a parameter key of cache size: percent of volume size (10%) or quantity (20Gi)
+	CacheSizeKey = "cacheSize"
+	// DefaultCacheSizePercent is a cache size in percents of volume size if CacheSizeKey isn't set
+	DefaultCacheSizePercent = 10
+)
+
+// parseCacheParameters returns cache mode and cache size in bytes of volume with size, storageClass and mode
+// Returns empty mode if cache isn't requested, error if parameters are wrong or volume can't be cached
+func parseCacheParameters(params map[string]string, storageClass, mode string, size int64) (string, int64, error) {
+	cacheMode := params[CacheModeKey]
+	if cacheMode == "" {
+		return "", 0, nil
+	}
+	if cacheMode != apiV1.CacheModeBcache {
+		return "", 0, fmt.Errorf("cache mode %s isn't supported, supported: %s", cacheMode, apiV1.CacheModeBcache)
+	}
+	if storageClass != apiV1.StorageClassHDD {
+		return "", 0, fmt.Errorf("cache is supported for %s storage class only", apiV1.StorageClassHDD)
+	}
+	if mode != apiV1.ModeFS {
+		return "", 0, fmt.Errorf("cache is supported for filesystem volumes only")
+	}
+
+	var (
+		cacheSize = int64(DefaultCacheSizePercent) * size / 100
+		value     = strings.TrimSpace(params[CacheSizeKey])
+	)
+	switch {
+	case value == "":
+	case strings.HasSuffix(value, "%"):
+		percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || percent <= 0 || percent > 100 {
+			return "", 0, fmt.Errorf("%s parameter has wrong value %s, expected percent from 1%% to 100%%",
+				CacheSizeKey, value)
+		}
+		cacheSize = int64(percent) * size / 100
+	default:
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return "", 0, fmt.Errorf("%s parameter has wrong value %s: %v", CacheSizeKey, value, err)
+		}
+		cacheSize = quantity.Value()
+	}
+	if cacheSize <= 0 {
+		return "", 0, fmt.Errorf("cache size must be positive, volume size is %d", size)
+	}
+	return cacheMode, cacheSize, nil
+}
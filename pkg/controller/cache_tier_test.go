@@ -0,0 +1,67 @@
+/*
+Copyright © 2020 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+func Test_parseCacheParameters(t *testing.T) {
+	const size = int64(1000 * 1024 * 1024)
+	hdd, fs := apiV1.StorageClassHDD, apiV1.ModeFS
+
+	mode, cacheSize, err := parseCacheParameters(map[string]string{}, hdd, fs, size)
+	assert.Nil(t, err)
+	assert.Equal(t, "", mode)
+	assert.Equal(t, int64(0), cacheSize)
+
+	mode, cacheSize, err = parseCacheParameters(map[string]string{CacheModeKey: "bcache"}, hdd, fs, size)
+	assert.Nil(t, err)
+	assert.Equal(t, apiV1.CacheModeBcache, mode)
+	assert.Equal(t, size/10, cacheSize)
+
+	_, cacheSize, err = parseCacheParameters(map[string]string{CacheModeKey: "bcache", CacheSizeKey: "25%"},
+		hdd, fs, size)
+	assert.Nil(t, err)
+	assert.Equal(t, size/4, cacheSize)
+
+	_, cacheSize, err = parseCacheParameters(map[string]string{CacheModeKey: "bcache", CacheSizeKey: "1Gi"},
+		hdd, fs, size)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1024*1024*1024), cacheSize)
+
+	for _, params := range []map[string]string{
+		{CacheModeKey: "dm-cache"},
+		{CacheModeKey: "bcache", CacheSizeKey: "0%"},
+		{CacheModeKey: "bcache", CacheSizeKey: "150%"},
+		{CacheModeKey: "bcache", CacheSizeKey: "big"},
+		{CacheModeKey: "bcache", CacheSizeKey: "0"},
+	} {
+		_, _, err = parseCacheParameters(params, hdd, fs, size)
+		assert.NotNil(t, err, params)
+	}
+	_, _, err = parseCacheParameters(map[string]string{CacheModeKey: "bcache"}, apiV1.StorageClassSSD, fs, size)
+	assert.NotNil(t, err)
+	_, _, err = parseCacheParameters(map[string]string{CacheModeKey: "bcache"}, hdd, apiV1.ModeRAW, size)
+	assert.NotNil(t, err)
+	_, _, err = parseCacheParameters(map[string]string{CacheModeKey: "bcache"}, hdd, fs, 0)
+	assert.NotNil(t, err)
+}
@@ -23,8 +23,11 @@ import (
 	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
+	"github.com/dell/csi-baremetal/pkg/base/ctrlopts"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	errTypes "github.com/dell/csi-baremetal/pkg/base/error"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/sharding"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	metricsC "github.com/dell/csi-baremetal/pkg/metrics/common"
 )
@@ -38,7 +41,13 @@ type Controller struct {
 	crHelper *k8s.CRHelper
 	// CRHelper instance which reads from cache
 	cachedCrHelper *k8s.CRHelper
-	log            *logrus.Entry
+	// part of nodes which Drives and LVGs are reconciled by the controller, nil if sharding is disabled
+	shard *sharding.Shard
+	// holds drives for DaemonSet drive reservations, nil if disabled
+	driveReservations *driveReservations
+	// sends events about duplicated partition GUIDs, nil if check of duplicates is disabled
+	recorder eventRecorder
+	log      *logrus.Entry
 }
 
 // NewCapacityController creates new instance of Controller structure
@@ -53,11 +62,17 @@ func NewCapacityController(client *k8s.KubeClient, k8sCache k8s.CRReader, log *l
 	}
 }
 
+// SetShard makes controller reconcile only Drives and LVGs located on nodes of the shard
+func (d *Controller) SetShard(shard *sharding.Shard) {
+	d.shard = shard
+}
+
 // SetupWithManager registers Controller to ControllerManager
 func (d *Controller) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&drivecrd.Drive{}).
 		Watches(&source.Kind{Type: &lvgcrd.LogicalVolumeGroup{}}, &handler.EnqueueRequestForObject{}).
+		WithOptions(ctrlopts.FromEnv(ctrlopts.CapacityControllerPrefix, ctrlopts.Options{}, d.log).ControllerOptions()).
 		WithEventFilter(predicate.Funcs{
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				return false
@@ -66,7 +81,8 @@ func (d *Controller) SetupWithManager(mgr ctrl.Manager) error {
 				return d.filterUpdateEvent(e.ObjectOld, e.ObjectNew)
 			},
 		}).
-		Complete(d)
+		WithEventFilter(predicate.NewPredicateFuncs(d.isOwnedByShard)).
+		Complete(diagnostics.TrackReconciler("drive", d))
 }
 
 // Reconcile reconciles Drive custom resources
@@ -126,18 +142,29 @@ func (d *Controller) reconcileDrive(ctx context.Context, drive *drivecrd.Drive)
 		status = drive.Spec.GetStatus()
 		usage  = drive.Spec.GetUsage()
 	)
+	if err := d.checkDuplicatePartUUIDs(ctx, drive); err != nil {
+		d.log.Errorf("Unable to check duplicated partition GUIDs of drive %s: %v", drive.Name, err)
+	}
 	switch {
 	case (health != apiV1.HealthGood && health != apiV1.HealthUnknown) ||
 		status != apiV1.DriveStatusOnline ||
-		usage != apiV1.DriveUsageInUse:
+		usage != apiV1.DriveUsageInUse ||
+		drive.GetAnnotations()[apiV1.DriveAnnotationCordon] == "true" ||
+		drive.GetAnnotations()[apiV1.DriveAnnotationSelfTest] != "" ||
+		drive.GetAnnotations()[apiV1.DriveAnnotationDuplicatePartUUID] != "" ||
+		drive.IsBurnInBlocking():
 		return d.handleInaccessibleDrive(ctx, drive.Spec)
 	default:
-		return d.createOrUpdateCapacity(ctx, drive.Spec)
+		if err := d.holdDriveIfNeeded(ctx, drive); err != nil {
+			d.log.Errorf("Unable to hold drive %s for drive reservation: %v", drive.Name, err)
+		}
+		return d.createOrUpdateCapacity(ctx, drive.Spec, drive.GetAnnotations()[apiV1.DriveAnnotationReservedFor])
 	}
 }
 
 // createOrUpdateCapacity tries to create AC for drive or update its size if AC already exists
-func (d *Controller) createOrUpdateCapacity(ctx context.Context, drive api.Drive) (ctrl.Result, error) {
+// AC is annotated with name of DaemonSet drive reservation if drive is held for it
+func (d *Controller) createOrUpdateCapacity(ctx context.Context, drive api.Drive, reservedFor string) (ctrl.Result, error) {
 	log := d.log.WithFields(logrus.Fields{
 		"method": "createOrUpdateCapacity",
 	})
@@ -151,8 +178,9 @@ func (d *Controller) createOrUpdateCapacity(ctx context.Context, drive api.Drive
 	switch {
 	case err == nil:
 		// If ac is exists, update its size to drive size
-		if ac.Spec.Size != size {
+		if ac.Spec.Size != size || ac.GetAnnotations()[apiV1.DriveAnnotationReservedFor] != reservedFor {
 			ac.Spec.Size = size
+			setReservedFor(ac, reservedFor)
 			if err := d.client.Update(context.WithValue(ctx, base.RequestUUID, ac.Name), ac); err != nil {
 				log.Errorf("Error during update AvailableCapacity request to k8s: %v, error: %v", ac, err)
 				return ctrl.Result{}, err
@@ -171,6 +199,7 @@ func (d *Controller) createOrUpdateCapacity(ctx context.Context, drive api.Drive
 			NodeId:       drive.GetNodeId(),
 		}
 		newAC := d.client.ConstructACCR(name, *capacity)
+		setReservedFor(newAC, reservedFor)
 		if err := d.client.CreateCR(context.WithValue(ctx, base.RequestUUID, name), name, newAC); err != nil {
 			log.Errorf("Error during create AvailableCapacity request to k8s: %v, error: %v",
 				capacity, err)
@@ -298,7 +327,24 @@ func (d *Controller) filterUpdateEvent(old runtime.Object, new runtime.Object) b
 		return handleLVGObjects(old, new)
 	}
 	if newDrive, ok = new.(*drivecrd.Drive); ok {
-		return filter(oldDrive.Spec, newDrive.Spec)
+		return filter(oldDrive.Spec, newDrive.Spec) ||
+			oldDrive.GetAnnotations()[apiV1.DriveAnnotationCordon] != newDrive.GetAnnotations()[apiV1.DriveAnnotationCordon] ||
+			oldDrive.GetAnnotations()[apiV1.DriveAnnotationBurnIn] != newDrive.GetAnnotations()[apiV1.DriveAnnotationBurnIn] ||
+			oldDrive.GetAnnotations()[apiV1.DriveAnnotationSelfTest] != newDrive.GetAnnotations()[apiV1.DriveAnnotationSelfTest] ||
+			oldDrive.GetAnnotations()[apiV1.DriveAnnotationPartUUIDs] != newDrive.GetAnnotations()[apiV1.DriveAnnotationPartUUIDs] ||
+			oldDrive.GetAnnotations()[apiV1.DriveAnnotationDuplicatePartUUID] != newDrive.GetAnnotations()[apiV1.DriveAnnotationDuplicatePartUUID] ||
+			oldDrive.GetAnnotations()[apiV1.DriveAnnotationReservedFor] != newDrive.GetAnnotations()[apiV1.DriveAnnotationReservedFor]
+	}
+	return true
+}
+
+// isOwnedByShard checks whether Drive or LVG is located on node of the shard
+func (d *Controller) isOwnedByShard(obj client.Object) bool {
+	switch o := obj.(type) {
+	case *drivecrd.Drive:
+		return d.shard.Owns(o.Spec.NodeId)
+	case *lvgcrd.LogicalVolumeGroup:
+		return d.shard.Owns(o.Spec.Node)
 	}
 	return true
 }
@@ -319,11 +365,12 @@ func handleLVGObjects(old runtime.Object, new runtime.Object) bool {
 }
 
 func filter(old api.Drive, new api.Drive) bool {
-	// controller perform reconcile for drives, which have different statuses, health or isClean field.
+	// controller perform reconcile for drives, which have different statuses, health, size or isClean field.
 	// Another drives are skipped
 	return old.GetIsClean() != new.GetIsClean() ||
 		old.GetStatus() != new.GetStatus() ||
-		old.GetHealth() != new.GetHealth()
+		old.GetHealth() != new.GetHealth() ||
+		old.GetSize() != new.GetSize()
 }
 
 func filterLVG(old *lvgcrd.LogicalVolumeGroup, new *lvgcrd.LogicalVolumeGroup) bool {
@@ -33,6 +33,7 @@ import (
 	"github.com/dell/csi-baremetal/api/v1/drivecrd"
 	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/sharding"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 )
 
@@ -133,6 +134,8 @@ type inputData struct {
 	driveHealth      string
 	driveACIsPresent bool
 	driveIsClean     bool
+	driveCordoned    bool
+	driveBurnIn      string
 }
 
 // expectedResult represents expected results for drive reconciliation
@@ -146,6 +149,26 @@ func Test_NewLVGController(t *testing.T) {
 	assert.NotNil(t, c)
 }
 
+func TestController_isOwnedByShard(t *testing.T) {
+	c := NewCapacityController(nil, nil, testLogger)
+	drive := &drivecrd.Drive{Spec: api.Drive{NodeId: node1ID}}
+	lvg := &lvgcrd.LogicalVolumeGroup{Spec: api.LogicalVolumeGroup{Node: node1ID}}
+	// sharding is disabled
+	assert.True(t, c.isOwnedByShard(drive))
+
+	owners := 0
+	for i := 0; i < 2; i++ {
+		shard, err := sharding.NewShard(i, 2)
+		assert.Nil(t, err)
+		c.SetShard(shard)
+		assert.Equal(t, c.isOwnedByShard(drive), c.isOwnedByShard(lvg))
+		if c.isOwnedByShard(drive) {
+			owners++
+		}
+	}
+	assert.Equal(t, 1, owners)
+}
+
 func TestController_ReconcileDrive(t *testing.T) {
 	for _, testData := range []struct {
 		testCaseName   string
@@ -259,6 +282,54 @@ func TestController_ReconcileDrive(t *testing.T) {
 				},
 			},
 		},
+		{
+			testCaseName: "Drive is good and cordoned, AC is present",
+			inputData: inputData{
+				driveHealth:      apiV1.HealthGood,
+				driveACIsPresent: true,
+				driveIsClean:     true,
+				driveCordoned:    true,
+			},
+			expectedResult: expectedResult{
+				reconcileError: nil,
+				acList: accrd.AvailableCapacityList{
+					Items: []accrd.AvailableCapacity{
+						{
+							Spec: api.AvailableCapacity{
+								Location:     drive1UUID,
+								NodeId:       apiDrive1.NodeId,
+								StorageClass: apiDrive1.Type,
+								Size:         0,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			testCaseName: "Drive is good and burn-in test is running, AC is present",
+			inputData: inputData{
+				driveHealth:      apiV1.HealthGood,
+				driveACIsPresent: true,
+				driveIsClean:     true,
+				driveBurnIn:      apiV1.DriveAnnotationBurnInInProgress,
+			},
+			expectedResult: expectedResult{
+				reconcileError: nil,
+				acList: accrd.AvailableCapacityList{
+					Items: []accrd.AvailableCapacity{
+						{
+							Spec: api.AvailableCapacity{
+								Location:     drive1UUID,
+								NodeId:       apiDrive1.NodeId,
+								StorageClass: apiDrive1.Type,
+								Size:         0,
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			testCaseName: "Drive is good and not clean, AC is present",
 			inputData: inputData{
@@ -317,6 +388,12 @@ func TestController_ReconcileDrive(t *testing.T) {
 			testDrive := drive1CR.DeepCopy()
 			testDrive.Spec.Health = testData.inputData.driveHealth
 			testDrive.Spec.IsClean = testData.inputData.driveIsClean
+			if testData.inputData.driveCordoned {
+				testDrive.Annotations = map[string]string{apiV1.DriveAnnotationCordon: "true"}
+			}
+			if testData.inputData.driveBurnIn != "" {
+				testDrive.Annotations = map[string]string{apiV1.DriveAnnotationBurnIn: testData.inputData.driveBurnIn}
+			}
 			err = kubeClient.Create(tCtx, testDrive)
 			assert.Nil(t, err)
 			if testData.inputData.driveACIsPresent {
@@ -491,6 +568,26 @@ func TestController_filterUpdateEvent_Drive(t *testing.T) {
 		testDrive2.Spec.IsClean = !testDrive.Spec.IsClean
 		assert.True(t, controller.filterUpdateEvent(&testDrive, &testDrive2))
 	})
+	t.Run("Drives have different size", func(t *testing.T) {
+		kubeClient, err := k8s.GetFakeKubeClient(ns, testLogger)
+		assert.Nil(t, err)
+		controller := NewCapacityController(kubeClient, kubeClient, testLogger)
+		assert.NotNil(t, controller)
+		testDrive := drive1CR
+		testDrive2 := drive1CR
+		testDrive2.Spec.Size = testDrive.Spec.Size * 2
+		assert.True(t, controller.filterUpdateEvent(&testDrive, &testDrive2))
+	})
+	t.Run("Drives have different cordon annotation", func(t *testing.T) {
+		kubeClient, err := k8s.GetFakeKubeClient(ns, testLogger)
+		assert.Nil(t, err)
+		controller := NewCapacityController(kubeClient, kubeClient, testLogger)
+		assert.NotNil(t, controller)
+		testDrive := drive1CR
+		testDrive2 := drive1CR
+		testDrive2.Annotations = map[string]string{apiV1.DriveAnnotationCordon: "true"}
+		assert.True(t, controller.filterUpdateEvent(&testDrive, &testDrive2))
+	})
 	t.Run("Drives are filtered", func(t *testing.T) {
 		kubeClient, err := k8s.GetFakeKubeClient(ns, testLogger)
 		assert.Nil(t, err)
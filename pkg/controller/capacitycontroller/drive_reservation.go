@@ -0,0 +1,141 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitycontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/utils/keymutex"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+)
+
+// driveReservations holds DaemonSet drive reservations: one free drive of the storage class is held on every node
+// for pods labeled with apiV1.PodLabelDriveReservation, other pods can't use capacity of held drive
+type driveReservations struct {
+	// reservation name -> storage class
+	storageClasses map[string]string
+	// serializes selection of held drives on the same node
+	nodeMu keymutex.KeyMutex
+}
+
+// SetDriveReservations enables holding of drives for DaemonSet drive reservations
+// Receives map reservation name -> storage class of held drive
+func (d *Controller) SetDriveReservations(reservations map[string]string) {
+	d.driveReservations = &driveReservations{
+		storageClasses: reservations,
+		nodeMu:         diagnostics.NewKeyMutex("drive-reservation-nodes", keymutex.NewHashed(0)),
+	}
+}
+
+// holdDriveIfNeeded marks free drive with reserved-for annotation if there is no held drive of the same storage class
+// for reservation on the node yet. Held drive stays reserved after volume of reservation is created on it
+func (d *Controller) holdDriveIfNeeded(ctx context.Context, drive *drivecrd.Drive) error {
+	if d.driveReservations == nil || drive.GetAnnotations()[apiV1.DriveAnnotationReservedFor] != "" ||
+		drive.Spec.IsSystem || !drive.Spec.IsClean {
+		return nil
+	}
+	ll := d.log.WithFields(logrus.Fields{
+		"method": "holdDriveIfNeeded",
+		"drive":  drive.Name,
+	})
+	sc := util.ConvertDriveTypeToStorageClass(drive.Spec.Type)
+
+	nodeID := drive.Spec.NodeId
+	d.driveReservations.nodeMu.LockKey(nodeID)
+	defer func() {
+		_ = d.driveReservations.nodeMu.UnlockKey(nodeID)
+	}()
+
+	// read drives from API, cache might not contain annotation set by concurrent reconcile yet
+	drives, err := d.crHelper.GetDriveCRs(nodeID)
+	if err != nil {
+		return err
+	}
+	held := make(map[string]bool)
+	for _, nodeDrive := range drives {
+		if name := nodeDrive.GetAnnotations()[apiV1.DriveAnnotationReservedFor]; name != "" {
+			held[name] = true
+		}
+	}
+	if volumes, err := d.crHelper.GetVolumesByLocation(ctx, drive.Spec.UUID); err != nil || len(volumes) > 0 {
+		return err
+	}
+
+	names := make([]string, 0, len(d.driveReservations.storageClasses))
+	for name := range d.driveReservations.storageClasses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if held[name] || d.driveReservations.storageClasses[name] != sc {
+			continue
+		}
+		if drive.Annotations == nil {
+			drive.Annotations = make(map[string]string)
+		}
+		drive.Annotations[apiV1.DriveAnnotationReservedFor] = name
+		if err = d.client.UpdateCR(ctx, drive); err != nil {
+			return err
+		}
+		ll.Infof("Drive %s is held for drive reservation %s", drive.Spec.SerialNumber, name)
+		return nil
+	}
+	return nil
+}
+
+// setReservedFor sets or removes annotation with name of DaemonSet drive reservation, CR isn't updated
+func setReservedFor(ac *accrd.AvailableCapacity, reservedFor string) {
+	if reservedFor == "" {
+		delete(ac.Annotations, apiV1.DriveAnnotationReservedFor)
+		return
+	}
+	if ac.Annotations == nil {
+		ac.Annotations = make(map[string]string)
+	}
+	ac.Annotations[apiV1.DriveAnnotationReservedFor] = reservedFor
+}
+
+// ParseDriveReservations parses DaemonSet drive reservations in format "logs=HDD,cache=NVME"
+// Returns map reservation name -> storage class or error if format is wrong
+func ParseDriveReservations(str string) (map[string]string, error) {
+	reservations := make(map[string]string)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("drive reservation %s has wrong format, expected <name>=<storage class>", item)
+		}
+		sc := util.ConvertStorageClass(strings.TrimSpace(parts[1]))
+		if sc != apiV1.StorageClassHDD && sc != apiV1.StorageClassSSD && sc != apiV1.StorageClassNVMe {
+			return nil, fmt.Errorf("drive reservation %s has wrong storage class, expected HDD, SSD or NVME", item)
+		}
+		reservations[strings.TrimSpace(parts[0])] = sc
+	}
+	return reservations, nil
+}
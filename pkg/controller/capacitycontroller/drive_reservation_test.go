@@ -0,0 +1,77 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitycontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+func TestParseDriveReservations(t *testing.T) {
+	reservations, err := ParseDriveReservations("logs=hdd, cache=NVME,")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"logs": apiV1.StorageClassHDD, "cache": apiV1.StorageClassNVMe}, reservations)
+
+	for _, wrong := range []string{"logs", "=HDD", "logs=HDDLVG", "logs=unknown"} {
+		_, err = ParseDriveReservations(wrong)
+		assert.NotNil(t, err, wrong)
+	}
+}
+
+func TestController_holdDriveIfNeeded(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(ns, testLogger)
+	assert.Nil(t, err)
+	controller := NewCapacityController(kubeClient, kubeClient, testLogger)
+	controller.SetDriveReservations(map[string]string{"logs": apiV1.StorageClassHDD, "cache": apiV1.StorageClassNVMe})
+
+	drive1 := drive1CR.DeepCopy()
+	drive2 := drive1CR.DeepCopy()
+	drive2.Name, drive2.Spec.UUID, drive2.Spec.SerialNumber = "uuid-drive2", "uuid-drive2", "hdd2"
+	assert.Nil(t, kubeClient.Create(tCtx, drive1))
+	assert.Nil(t, kubeClient.Create(tCtx, drive2))
+
+	for _, drive := range []*drivecrd.Drive{drive1, drive2} {
+		_, err = controller.Reconcile(tCtx, ctrl.Request{NamespacedName: types.NamespacedName{Name: drive.Name}})
+		assert.Nil(t, err)
+	}
+
+	// only one HDD is held on the node
+	assert.Nil(t, kubeClient.ReadCR(tCtx, drive1.Name, "", drive1))
+	assert.Equal(t, "logs", drive1.Annotations[apiV1.DriveAnnotationReservedFor])
+	assert.Nil(t, kubeClient.ReadCR(tCtx, drive2.Name, "", drive2))
+	assert.Empty(t, drive2.Annotations[apiV1.DriveAnnotationReservedFor])
+
+	// AC of held drive is annotated
+	acList := &accrd.AvailableCapacityList{}
+	assert.Nil(t, kubeClient.ReadList(tCtx, acList))
+	assert.Len(t, acList.Items, 2)
+	for _, ac := range acList.Items {
+		if ac.Spec.Location == drive1.Spec.UUID {
+			assert.Equal(t, "logs", ac.Annotations[apiV1.DriveAnnotationReservedFor])
+		} else {
+			assert.Empty(t, ac.Annotations[apiV1.DriveAnnotationReservedFor])
+		}
+	}
+}
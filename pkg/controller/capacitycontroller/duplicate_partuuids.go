@@ -0,0 +1,188 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitycontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// eventRecorder interface for sending events
+type eventRecorder interface {
+	Eventf(object runtime.Object, event *eventing.EventDescription, messageFmt string, args ...interface{})
+}
+
+// SetDuplicatePartUUIDCheck enables check of partition GUIDs reported by node services: drives holding partitions
+// with the same GUID, e.g. after dd clone of the disk, are quarantined until duplication is resolved
+func (d *Controller) SetDuplicatePartUUIDCheck(recorder eventRecorder) {
+	d.recorder = recorder
+}
+
+// checkDuplicatePartUUIDs compares partition GUIDs of the drive with GUIDs of other online drives in the cluster,
+// sets DriveAnnotationDuplicatePartUUID if some of them are duplicated and removes it when duplication is resolved.
+// Other holders of the GUIDs and drives which are still annotated are checked in the same way, so annotation
+// of the peer is set and cleared together with annotation of the drive
+// Drive CRs are updated if annotation is changed
+func (d *Controller) checkDuplicatePartUUIDs(ctx context.Context, drive *drivecrd.Drive) error {
+	if d.recorder == nil {
+		return nil
+	}
+	// drives of other shards are read and updated too, their reconciliation doesn't notice change of this drive
+	drives, err := d.cachedCrHelper.GetDriveCRs()
+	if err != nil {
+		return err
+	}
+	for i := range drives {
+		if drives[i].Name == drive.Name {
+			drives[i] = *drive
+		}
+	}
+
+	value, holders := duplicatedPartUUIDs(drive, drives)
+	if err := d.setDuplicatePartUUIDs(ctx, drive, value, holders); err != nil {
+		return err
+	}
+
+	own := make(map[string]bool)
+	for _, partUUID := range splitPartUUIDs(drive.GetAnnotations()[apiV1.DriveAnnotationPartUUIDs]) {
+		own[partUUID] = true
+	}
+	for i := range drives {
+		other := &drives[i]
+		if other.Name == drive.Name {
+			continue
+		}
+		annotated := other.GetAnnotations()[apiV1.DriveAnnotationDuplicatePartUUID] != ""
+		if !annotated && !holdsAny(other, own) {
+			continue
+		}
+		peer := &drivecrd.Drive{}
+		if err := d.client.ReadCR(ctx, other.Name, "", peer); err != nil {
+			return err
+		}
+		value, holders := duplicatedPartUUIDs(peer, drives)
+		if err := d.setDuplicatePartUUIDs(ctx, peer, value, holders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// duplicatedPartUUIDs returns comma separated sorted partition GUIDs of the drive which are found on other online
+// drives and descriptions of these drives, GUIDs of offline drives aren't compared
+func duplicatedPartUUIDs(drive *drivecrd.Drive, drives []drivecrd.Drive) (string, []string) {
+	own := make(map[string]bool)
+	for _, partUUID := range splitPartUUIDs(drive.GetAnnotations()[apiV1.DriveAnnotationPartUUIDs]) {
+		own[partUUID] = true
+	}
+	if len(own) == 0 || drive.Spec.Status != apiV1.DriveStatusOnline {
+		return "", nil
+	}
+	var (
+		duplicated = make(map[string]bool)
+		holders    []string
+	)
+	for _, other := range drives {
+		if other.Name == drive.Name || other.Spec.Status != apiV1.DriveStatusOnline {
+			continue
+		}
+		found := false
+		for _, partUUID := range splitPartUUIDs(other.GetAnnotations()[apiV1.DriveAnnotationPartUUIDs]) {
+			if own[partUUID] {
+				duplicated[partUUID] = true
+				found = true
+			}
+		}
+		if found {
+			holders = append(holders, fmt.Sprintf("%s on node %s", other.Spec.SerialNumber, other.Spec.NodeId))
+		}
+	}
+
+	uuids := make([]string, 0, len(duplicated))
+	for partUUID := range duplicated {
+		uuids = append(uuids, partUUID)
+	}
+	sort.Strings(uuids)
+	sort.Strings(holders)
+	return strings.Join(uuids, ","), holders
+}
+
+// setDuplicatePartUUIDs sets DriveAnnotationDuplicatePartUUID of the drive to value or removes it if value is empty,
+// Drive CR is updated and event is sent only if annotation is changed
+func (d *Controller) setDuplicatePartUUIDs(ctx context.Context, drive *drivecrd.Drive, value string,
+	holders []string) error {
+	ll := d.log.WithFields(logrus.Fields{
+		"method": "setDuplicatePartUUIDs",
+		"drive":  drive.Name,
+	})
+	current := drive.GetAnnotations()[apiV1.DriveAnnotationDuplicatePartUUID]
+	if value == current {
+		return nil
+	}
+
+	if value == "" {
+		delete(drive.Annotations, apiV1.DriveAnnotationDuplicatePartUUID)
+	} else {
+		if drive.Annotations == nil {
+			drive.Annotations = make(map[string]string)
+		}
+		drive.Annotations[apiV1.DriveAnnotationDuplicatePartUUID] = value
+	}
+	if err := d.client.UpdateCR(ctx, drive); err != nil {
+		return err
+	}
+	if value == "" {
+		ll.Infof("Duplication of partition GUIDs %s is resolved", current)
+		d.recorder.Eventf(drive, eventing.DrivePartitionUUIDResolved,
+			"Partition GUIDs %s aren't duplicated anymore, drive is returned to provisioning. %s",
+			current, drive.GetDriveDescription())
+		return nil
+	}
+	ll.Errorf("Partition GUIDs %s are found on drives %s too", value, strings.Join(holders, ", "))
+	d.recorder.Eventf(drive, eventing.DrivePartitionUUIDDuplicated,
+		"Partition GUIDs %s are found on drives %s too, drive is excluded from provisioning until GUIDs are "+
+			"changed or partitions are removed on one of drives. %s",
+		value, strings.Join(holders, ", "), drive.GetDriveDescription())
+	return nil
+}
+
+// holdsAny checks whether drive reports one of partition GUIDs
+func holdsAny(drive *drivecrd.Drive, partUUIDs map[string]bool) bool {
+	for _, partUUID := range splitPartUUIDs(drive.GetAnnotations()[apiV1.DriveAnnotationPartUUIDs]) {
+		if partUUIDs[partUUID] {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPartUUIDs returns GUIDs from value of DriveAnnotationPartUUIDs
+func splitPartUUIDs(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitycontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestController_checkDuplicatePartUUIDs(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(ns, testLogger)
+	assert.Nil(t, err)
+	recorder := new(mocks.NoOpRecorder)
+	controller := NewCapacityController(kubeClient, kubeClient, testLogger)
+	controller.SetDuplicatePartUUIDCheck(recorder)
+
+	drive1 := drive1CR.DeepCopy()
+	drive1.Annotations = map[string]string{apiV1.DriveAnnotationPartUUIDs: "aaaa,bbbb"}
+	drive2 := drive1CR.DeepCopy()
+	drive2.Name, drive2.Spec.UUID, drive2.Spec.SerialNumber = "uuid-drive2", "uuid-drive2", "hdd2"
+	drive2.Spec.NodeId = "node2"
+	drive2.Annotations = map[string]string{apiV1.DriveAnnotationPartUUIDs: "bbbb,cccc"}
+	drive3 := drive1CR.DeepCopy()
+	drive3.Name, drive3.Spec.UUID, drive3.Spec.SerialNumber = "uuid-drive3", "uuid-drive3", "hdd3"
+	drive3.Annotations = map[string]string{apiV1.DriveAnnotationPartUUIDs: "dddd"}
+	assert.Nil(t, kubeClient.Create(tCtx, drive1))
+	assert.Nil(t, kubeClient.Create(tCtx, drive2))
+	assert.Nil(t, kubeClient.Create(tCtx, drive3))
+
+	reconcileDrive := func(drive *drivecrd.Drive) {
+		_, err = controller.Reconcile(tCtx, ctrl.Request{NamespacedName: types.NamespacedName{Name: drive.Name}})
+		assert.Nil(t, err)
+	}
+	reconcile := func() {
+		for _, drive := range []*drivecrd.Drive{drive1, drive2, drive3} {
+			reconcileDrive(drive)
+		}
+	}
+	acSize := func(drive *drivecrd.Drive) int64 {
+		ac, err := controller.crHelper.GetACByLocation(drive.Spec.UUID)
+		assert.Nil(t, err)
+		return ac.Spec.Size
+	}
+	countEvents := func(event *eventing.EventDescription) int {
+		count := 0
+		for _, c := range recorder.Calls {
+			if c.Event == event {
+				count++
+			}
+		}
+		return count
+	}
+
+	// both drives with the same partition GUID are quarantined on reconciliation of one of them
+	reconcileDrive(drive1)
+	assert.Nil(t, kubeClient.ReadCR(tCtx, drive1.Name, "", drive1))
+	assert.Equal(t, "bbbb", drive1.Annotations[apiV1.DriveAnnotationDuplicatePartUUID])
+	assert.Nil(t, kubeClient.ReadCR(tCtx, drive2.Name, "", drive2))
+	assert.Equal(t, "bbbb", drive2.Annotations[apiV1.DriveAnnotationDuplicatePartUUID])
+	assert.Equal(t, 2, countEvents(eventing.DrivePartitionUUIDDuplicated))
+	reconcile()
+	assert.Nil(t, kubeClient.ReadCR(tCtx, drive3.Name, "", drive3))
+	assert.Empty(t, drive3.Annotations[apiV1.DriveAnnotationDuplicatePartUUID])
+	assert.Equal(t, 2, countEvents(eventing.DrivePartitionUUIDDuplicated))
+	acList := &accrd.AvailableCapacityList{}
+	assert.Nil(t, kubeClient.ReadList(tCtx, acList))
+	assert.Len(t, acList.Items, 1)
+	assert.Equal(t, drive3.Spec.Size, acSize(drive3))
+
+	// events aren't repeated
+	reconcile()
+	assert.Equal(t, 2, countEvents(eventing.DrivePartitionUUIDDuplicated))
+
+	// GUID of partition was changed on one of drives, annotation of the peer is cleared too
+	assert.Nil(t, kubeClient.ReadCR(tCtx, drive2.Name, "", drive2))
+	drive2.Annotations[apiV1.DriveAnnotationPartUUIDs] = "cccc,eeee"
+	assert.Nil(t, kubeClient.UpdateCR(tCtx, drive2))
+	reconcileDrive(drive2)
+	for _, drive := range []*drivecrd.Drive{drive1, drive2} {
+		// decoding into the existing object keeps keys of its annotations map
+		drive.Annotations = nil
+		assert.Nil(t, kubeClient.ReadCR(tCtx, drive.Name, "", drive))
+		_, ok := drive.Annotations[apiV1.DriveAnnotationDuplicatePartUUID]
+		assert.False(t, ok)
+	}
+	assert.Equal(t, 2, countEvents(eventing.DrivePartitionUUIDResolved))
+	reconcile()
+	for _, drive := range []*drivecrd.Drive{drive1, drive2} {
+		assert.Equal(t, drive.Spec.Size, acSize(drive))
+	}
+	assert.Equal(t, 2, countEvents(eventing.DrivePartitionUUIDResolved))
+}
@@ -0,0 +1,304 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package capacitymonitor contains monitor which compares free capacity on nodes and in the cluster with
+// configured thresholds and reports low capacity via events and metrics
+package capacitymonitor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/nodecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/common"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+const (
+	// ClusterScope is a value of node label for cluster-wide capacity metrics
+	ClusterScope = "cluster"
+	// DefaultCheckInterval is the default interval between capacity checks
+	DefaultCheckInterval = time.Minute
+)
+
+// eventRecorder interface for sending events
+type eventRecorder interface {
+	Eventf(object runtime.Object, event *eventing.EventDescription, messageFmt string, args ...interface{})
+}
+
+// capacity holds total and free capacity for the media type
+type capacity struct {
+	total int64
+	free  int64
+}
+
+// freeRatio returns free capacity as a percentage of total capacity
+func (c capacity) freeRatio() float64 {
+	if c.total == 0 {
+		return 100
+	}
+	return float64(c.free) / float64(c.total) * 100
+}
+
+// Monitor periodically calculates free capacity per node and media type and compares it with thresholds
+type Monitor struct {
+	client   *k8s.KubeClient
+	crHelper *k8s.CRHelper
+	recorder eventRecorder
+	// object for cluster-wide events, e.g. pod of the controller, events aren't sent if it is nil
+	clusterObject runtime.Object
+	// key - media type (HDD/SSD/NVME), value - minimal free capacity in percents
+	thresholds map[string]float64
+	// guards thresholds which might be changed by configuration reload
+	thresholdsMu sync.RWMutex
+	// holds node/media type and cluster/media type pairs which are below threshold, uses to send events only on transitions
+	lowCapacity map[string]bool
+
+	freeRatio *prometheus.GaugeVec
+	low       *prometheus.GaugeVec
+	log       *logrus.Entry
+}
+
+// NewMonitor is the constructor for Monitor struct
+// Receives an instance of base.KubeClient, CRReader (cache), event recorder, thresholds and logrus logger
+// Returns an instance of Monitor
+func NewMonitor(client *k8s.KubeClient, k8sCache k8s.CRReader, recorder eventRecorder,
+	thresholds map[string]float64, logger *logrus.Logger) *Monitor {
+	m := &Monitor{
+		client:      client,
+		crHelper:    k8s.NewCRHelper(client, logger).SetReader(k8sCache),
+		recorder:    recorder,
+		thresholds:  thresholds,
+		lowCapacity: make(map[string]bool),
+		freeRatio: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "available_capacity_free_ratio",
+			Help: "Free capacity in percents of total capacity per node and media type",
+		}, []string{"node", "media_type"}),
+		low: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "available_capacity_low",
+			Help: "1 if free capacity of the node and media type is below the configured threshold, 0 otherwise",
+		}, []string{"node", "media_type"}),
+		log: logger.WithField("component", "CapacityMonitor"),
+	}
+	for _, c := range []prometheus.Collector{m.freeRatio, m.low} {
+		if err := prometheus.Register(c); err != nil {
+			m.log.Errorf("Failed to register metric: %v", err)
+		}
+	}
+	return m
+}
+
+// Run performs Check with the provided interval until context is done
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Check(ctx); err != nil {
+				m.log.Errorf("Capacity check failed: %v", err)
+			}
+		}
+	}
+}
+
+// Check calculates free capacity per node and media type, updates metrics and sends events
+// for nodes and the cluster where free capacity crossed the threshold
+func (m *Monitor) Check(ctx context.Context) error {
+	ll := m.log.WithField("method", "Check")
+	thresholds := m.getThresholds()
+
+	perNode, cluster, err := m.calculate()
+	if err != nil {
+		return err
+	}
+
+	nodes := &nodecrd.NodeList{}
+	if err = m.client.ReadList(ctx, nodes); err != nil {
+		return err
+	}
+	nodeCRs := make(map[string]*nodecrd.Node, len(nodes.Items))
+	for i := range nodes.Items {
+		nodeCRs[nodes.Items[i].Spec.UUID] = &nodes.Items[i]
+	}
+
+	for nodeID, caps := range perNode {
+		for mediaType, c := range caps {
+			ratio := c.freeRatio()
+			isLow := isLow(thresholds, mediaType, ratio)
+			m.setMetrics(nodeID, mediaType, ratio, isLow)
+
+			key := nodeID + "/" + mediaType
+			if isLow == m.lowCapacity[key] {
+				continue
+			}
+			m.lowCapacity[key] = isLow
+			node, ok := nodeCRs[nodeID]
+			if !ok {
+				ll.Warnf("Node CR for node %s isn't found, skip event", nodeID)
+				continue
+			}
+			if isLow {
+				m.recorder.Eventf(node, eventing.NodeCapacityLow,
+					"Free %s capacity is %.1f%% (%d of %d bytes), threshold %.1f%%",
+					mediaType, ratio, c.free, c.total, thresholds[mediaType])
+			} else {
+				m.recorder.Eventf(node, eventing.NodeCapacityRestored,
+					"Free %s capacity is %.1f%%, threshold %.1f%%", mediaType, ratio, thresholds[mediaType])
+			}
+		}
+	}
+
+	for mediaType, c := range cluster {
+		ratio := c.freeRatio()
+		isLow := isLow(thresholds, mediaType, ratio)
+		m.setMetrics(ClusterScope, mediaType, ratio, isLow)
+		if isLow {
+			ll.Warnf("Free %s capacity in the cluster is %.1f%%, threshold %.1f%%",
+				mediaType, ratio, thresholds[mediaType])
+		}
+
+		key := ClusterScope + "/" + mediaType
+		if isLow == m.lowCapacity[key] {
+			continue
+		}
+		m.lowCapacity[key] = isLow
+		if m.clusterObject == nil {
+			continue
+		}
+		if isLow {
+			m.recorder.Eventf(m.clusterObject, eventing.ClusterCapacityLow,
+				"Free %s capacity in the cluster is %.1f%% (%d of %d bytes), threshold %.1f%%",
+				mediaType, ratio, c.free, c.total, thresholds[mediaType])
+		} else {
+			m.recorder.Eventf(m.clusterObject, eventing.ClusterCapacityRestored,
+				"Free %s capacity in the cluster is %.1f%%, threshold %.1f%%", mediaType, ratio, thresholds[mediaType])
+		}
+	}
+	return nil
+}
+
+// SetClusterObject sets object which cluster-wide low capacity events are recorded for
+func (m *Monitor) SetClusterObject(object runtime.Object) {
+	m.clusterObject = object
+}
+
+// calculate returns total and free capacity per node and media type and the same for the whole cluster
+func (m *Monitor) calculate() (map[string]map[string]capacity, map[string]capacity, error) {
+	drives, err := m.crHelper.GetDriveCRs()
+	if err != nil {
+		return nil, nil, err
+	}
+	acs, err := m.crHelper.GetACCRs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		perNode = make(map[string]map[string]capacity)
+		cluster = make(map[string]capacity)
+	)
+	add := func(nodeID, mediaType string, total, free int64) {
+		if _, ok := perNode[nodeID]; !ok {
+			perNode[nodeID] = make(map[string]capacity)
+		}
+		c := perNode[nodeID][mediaType]
+		c.total += total
+		c.free += free
+		perNode[nodeID][mediaType] = c
+
+		c = cluster[mediaType]
+		c.total += total
+		c.free += free
+		cluster[mediaType] = c
+	}
+
+	for _, d := range drives {
+		if d.Spec.IsSystem || d.Spec.Usage != apiV1.DriveUsageInUse {
+			continue
+		}
+		add(d.Spec.NodeId, d.Spec.Type, d.Spec.Size, 0)
+	}
+	for _, ac := range acs {
+		if ac.Spec.StorageClass == apiV1.StorageClassSystemLVG {
+			continue
+		}
+		add(ac.Spec.NodeId, common.GetQuotaMediaType(ac.Spec.StorageClass), 0, ac.Spec.Size)
+	}
+	return perNode, cluster, nil
+}
+
+// SetThresholds replaces thresholds, new values are used starting from the next Check
+func (m *Monitor) SetThresholds(thresholds map[string]float64) {
+	m.thresholdsMu.Lock()
+	defer m.thresholdsMu.Unlock()
+	m.thresholds = thresholds
+}
+
+func (m *Monitor) getThresholds() map[string]float64 {
+	m.thresholdsMu.RLock()
+	defer m.thresholdsMu.RUnlock()
+	return m.thresholds
+}
+
+func isLow(thresholds map[string]float64, mediaType string, ratio float64) bool {
+	threshold, ok := thresholds[mediaType]
+	return ok && ratio < threshold
+}
+
+func (m *Monitor) setMetrics(nodeID, mediaType string, ratio float64, isLow bool) {
+	labels := prometheus.Labels{"node": nodeID, "media_type": mediaType}
+	m.freeRatio.With(labels).Set(ratio)
+	if isLow {
+		m.low.With(labels).Set(1)
+	} else {
+		m.low.With(labels).Set(0)
+	}
+}
+
+// ParseThresholds parses thresholds in format "SSD=10,HDD=5" where value is minimal free capacity in percents
+// Returns map media type -> threshold or error if format is wrong
+func ParseThresholds(str string) (map[string]float64, error) {
+	thresholds := make(map[string]float64)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("threshold %s has wrong format, expected <media type>=<percents>", item)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || value < 0 || value > 100 {
+			return nil, fmt.Errorf("threshold %s has wrong value, expected percents in range 0-100", item)
+		}
+		thresholds[strings.ToUpper(strings.TrimSpace(parts[0]))] = value
+	}
+	return thresholds, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitymonitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+var (
+	testCtx    = context.Background()
+	testLogger = logrus.New()
+	testNodeID = "node-uuid"
+)
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds("ssd=10, HDD=5.5,")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]float64{apiV1.DriveTypeSSD: 10, apiV1.DriveTypeHDD: 5.5}, thresholds)
+
+	thresholds, err = ParseThresholds("")
+	assert.Nil(t, err)
+	assert.Empty(t, thresholds)
+
+	_, err = ParseThresholds("SSD")
+	assert.NotNil(t, err)
+	_, err = ParseThresholds("SSD=110")
+	assert.NotNil(t, err)
+}
+
+func TestMonitor_Check(t *testing.T) {
+	client, err := k8s.GetFakeKubeClient("default", testLogger)
+	assert.Nil(t, err)
+
+	node := client.ConstructCSIBMNodeCR("csibmnode", api.Node{UUID: testNodeID})
+	assert.Nil(t, client.CreateCR(testCtx, node.Name, node))
+	drive := client.ConstructDriveCR("drive", api.Drive{
+		UUID:   "drive",
+		NodeId: testNodeID,
+		Type:   apiV1.DriveTypeSSD,
+		Size:   int64(util.GBYTE) * 100,
+		Usage:  apiV1.DriveUsageInUse,
+	})
+	assert.Nil(t, client.CreateCR(testCtx, drive.Name, drive))
+	ac := client.ConstructACCR("ac", api.AvailableCapacity{
+		Location:     "drive",
+		NodeId:       testNodeID,
+		StorageClass: apiV1.StorageClassSSDLVG,
+		Size:         int64(util.GBYTE) * 5,
+	})
+	assert.Nil(t, client.CreateCR(testCtx, ac.Name, ac))
+
+	recorder := new(mocks.NoOpRecorder)
+	m := NewMonitor(client, client, recorder, map[string]float64{apiV1.DriveTypeSSD: 10}, testLogger)
+
+	// 5% free - event is sent once
+	assert.Nil(t, m.Check(testCtx))
+	assert.Nil(t, m.Check(testCtx))
+	assert.Len(t, recorder.Calls, 1)
+	assert.Equal(t, eventing.NodeCapacityLow, recorder.Calls[0].Event)
+
+	// 50% free - capacity is restored
+	ac.Spec.Size = int64(util.GBYTE) * 50
+	assert.Nil(t, client.UpdateCR(testCtx, ac))
+	assert.Nil(t, m.Check(testCtx))
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.NodeCapacityRestored, recorder.Calls[1].Event)
+
+	// threshold is raised by configuration reload
+	m.SetThresholds(map[string]float64{apiV1.DriveTypeSSD: 60})
+	assert.Nil(t, m.Check(testCtx))
+	assert.Len(t, recorder.Calls, 3)
+	assert.Equal(t, eventing.NodeCapacityLow, recorder.Calls[2].Event)
+}
+
+func TestMonitor_CheckCluster(t *testing.T) {
+	client, err := k8s.GetFakeKubeClient("default", testLogger)
+	assert.Nil(t, err)
+
+	drive := client.ConstructDriveCR("drive", api.Drive{
+		UUID:   "drive",
+		NodeId: testNodeID,
+		Type:   apiV1.DriveTypeSSD,
+		Size:   int64(util.GBYTE) * 100,
+		Usage:  apiV1.DriveUsageInUse,
+	})
+	assert.Nil(t, client.CreateCR(testCtx, drive.Name, drive))
+	ac := client.ConstructACCR("ac", api.AvailableCapacity{
+		Location:     "drive",
+		NodeId:       testNodeID,
+		StorageClass: apiV1.StorageClassSSDLVG,
+		Size:         int64(util.GBYTE) * 5,
+	})
+	assert.Nil(t, client.CreateCR(testCtx, ac.Name, ac))
+
+	recorder := new(mocks.NoOpRecorder)
+	m := NewMonitor(client, client, recorder, map[string]float64{apiV1.DriveTypeSSD: 10}, testLogger)
+	pod := &coreV1.Pod{ObjectMeta: metaV1.ObjectMeta{Name: "csi-baremetal-controller", Namespace: "default"}}
+	m.SetClusterObject(pod)
+
+	// Node CR is absent, so only cluster event is sent, once
+	assert.Nil(t, m.Check(testCtx))
+	assert.Nil(t, m.Check(testCtx))
+	assert.Len(t, recorder.Calls, 1)
+	assert.Equal(t, eventing.ClusterCapacityLow, recorder.Calls[0].Event)
+	assert.Equal(t, pod, recorder.Calls[0].Object)
+
+	ac.Spec.Size = int64(util.GBYTE) * 50
+	assert.Nil(t, client.UpdateCR(testCtx, ac))
+	assert.Nil(t, m.Check(testCtx))
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.ClusterCapacityRestored, recorder.Calls[1].Event)
+}
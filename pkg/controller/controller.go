@@ -20,8 +20,8 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/golang/protobuf/ptypes/wrappers"
@@ -30,14 +30,19 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/keymutex"
 
 	api "github.com/dell/csi-baremetal/api/generated/v1"
 	apiV1 "github.com/dell/csi-baremetal/api/v1"
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/cache"
 	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	"github.com/dell/csi-baremetal/pkg/base/sharding"
+	"github.com/dell/csi-baremetal/pkg/base/shutdown"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	"github.com/dell/csi-baremetal/pkg/common"
 	"github.com/dell/csi-baremetal/pkg/controller/mountoptions"
@@ -54,12 +59,19 @@ const (
 	RawPartModeValue = "true"
 )
 
+// EncryptionKey is a parameter key to enable volume encryption, the only supported value is luks
+const EncryptionKey = "encryption"
+
+// LazyFormatKey is a parameter key to defer filesystem creation to the first NodeStageVolume, value is true or false
+const LazyFormatKey = "lazyFormat"
+
 // CSIControllerService is the implementation of ControllerServer interface from GO CSI specification
 type CSIControllerService struct {
 	k8sclient *k8s.KubeClient
 
-	// mutex for csi request
-	reqMu sync.Mutex
+	// serializes requests for the same volume, requests for different volumes are handled in parallel
+	// and synchronized on AC level by VolumeOperations
+	volMu keymutex.KeyMutex
 	log   *logrus.Entry
 
 	svc common.VolumeOperations
@@ -71,6 +83,15 @@ type CSIControllerService struct {
 
 	crHelper *k8s.CRHelper
 
+	// part of nodes which volumes are handled by background routines, nil if sharding is disabled
+	shard *sharding.Shard
+	// path of provisioning freeze toggle, empty if freeze isn't supported
+	freezeConfig string
+	// state of feature gates
+	featureConf featureconfig.FeatureChecker
+	// tracks in-flight CreateVolume and DeleteVolume calls which are drained during graceful shutdown
+	operations *shutdown.Tracker
+
 	csi.IdentityServer
 	grpc_health_v1.HealthServer
 }
@@ -87,6 +108,9 @@ func NewControllerService(k8sClient *k8s.KubeClient, logger *logrus.Logger,
 		nodeServicesStateMonitor: node.NewNodeServicesStateMonitor(k8sClient, logger),
 		IdentityServer:           NewIdentityServer(base.PluginName, base.PluginVersion),
 		crHelper:                 k8s.NewCRHelper(k8sClient, logger),
+		volMu:                    diagnostics.NewKeyMutex("controller-volumes", keymutex.NewHashed(0)),
+		operations:               shutdown.NewTracker(),
+		featureConf:              featureConf,
 	}
 
 	// run health monitor
@@ -95,6 +119,12 @@ func NewControllerService(k8sClient *k8s.KubeClient, logger *logrus.Logger,
 	return c
 }
 
+// SetShard makes controller service update CRs and clean stuck volumes only on nodes of the shard
+func (c *CSIControllerService) SetShard(shard *sharding.Shard) {
+	c.shard = shard
+	c.nodeServicesStateMonitor.SetShard(shard)
+}
+
 // Probe is the implementation of CSI Spec Probe for IdentityServer.
 // This method checks if CSI driver is ready to serve requests
 // overrides same method from defaultIdentityServer struct
@@ -124,6 +154,10 @@ func (c *CSIControllerService) Check(context.Context, *grpc_health_v1.HealthChec
 	ll := c.log.WithFields(logrus.Fields{
 		"method": "Check",
 	})
+	if c.operations.IsDraining() {
+		ll.Info("Controller svc is shutting down")
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	}
 	// If controller service is ready we don't need to update cache often
 	if !c.ready {
 		c.nodeServicesStateMonitor.UpdateNodeHealthCache()
@@ -162,6 +196,18 @@ func (c *CSIControllerService) CreateVolume(ctx context.Context, req *csi.Create
 	if req.GetVolumeCapabilities() == nil || len(req.GetVolumeCapabilities()) == 0 {
 		return nil, status.Error(codes.InvalidArgument, "Volume capabilities missing in request")
 	}
+	if err := validateAccessModes(req.GetVolumeCapabilities()); err != nil {
+		ll.Errorf("Failed to create volume: %v", err)
+		return nil, err
+	}
+	if err := c.checkProvisioningFreeze("CreateVolume"); err != nil {
+		return nil, err
+	}
+	done, ok := c.operations.Begin("CreateVolume " + req.Name)
+	if !ok {
+		return nil, status.Error(codes.Unavailable, "controller service is shutting down")
+	}
+	defer done()
 
 	preferredNode := ""
 	if req.GetAccessibilityRequirements() != nil && len(req.GetAccessibilityRequirements().Preferred) > 0 {
@@ -197,6 +243,11 @@ func (c *CSIControllerService) CreateVolume(ctx context.Context, req *csi.Create
 		// ext4 by default (from request)
 		fsType = strings.ToLower(accessType.Mount.FsType)
 		mode = apiV1.ModeFS
+		if fsType != "" {
+			if _, err = fs.GetProvider(fs.FileSystem(fsType)); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "%v, supported: %v", err, fs.SupportedFileSystems())
+			}
+		}
 
 		// check mountFlags
 		if !mountoptions.IsOptionsSupported(accessType.Mount.GetMountFlags()) {
@@ -211,16 +262,52 @@ func (c *CSIControllerService) CreateVolume(ctx context.Context, req *csi.Create
 		mode = apiV1.ModeRAWPART
 	}
 
-	c.reqMu.Lock()
+	encryption := req.GetParameters()[EncryptionKey]
+	if encryption != "" && encryption != apiV1.EncryptionLUKS {
+		return nil, status.Errorf(codes.InvalidArgument, "encryption %s isn't supported, supported: %s",
+			encryption, apiV1.EncryptionLUKS)
+	}
+	if encryption != "" && !c.featureConf.IsEnabled(featureconfig.FeatureVolumeEncryption) {
+		return nil, status.Errorf(codes.InvalidArgument, "encryption isn't allowed, feature gate %s is disabled",
+			featureconfig.FeatureVolumeEncryption)
+	}
+
+	lazyFormat := false
+	if value, ok := req.GetParameters()[LazyFormatKey]; ok {
+		if lazyFormat, err = strconv.ParseBool(value); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s parameter has wrong value %s", LazyFormatKey, value)
+		}
+	}
+
+	storageClass := util.ConvertStorageClass(req.Parameters[base.StorageTypeKey])
+	cacheMode, cacheSize, err := parseCacheParameters(req.GetParameters(), storageClass, mode,
+		req.GetCapacityRange().GetRequiredBytes())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	template, err := parseTemplateParameter(req.GetParameters(), storageClass, encryption)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err = parseSerialPassthroughParameter(req.GetParameters()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	c.volMu.LockKey(req.Name)
 	vol, err = c.svc.CreateVolume(ctxValue, api.Volume{
 		Id:           req.Name,
-		StorageClass: util.ConvertStorageClass(req.Parameters[base.StorageTypeKey]),
+		StorageClass: storageClass,
 		NodeId:       preferredNode,
 		Size:         req.GetCapacityRange().GetRequiredBytes(),
 		Mode:         mode,
 		Type:         fsType,
+		Encryption:   encryption,
+		LazyFormat:   lazyFormat,
+		CacheMode:    cacheMode,
+		CacheSize:    cacheSize,
+		Template:     template,
 	})
-	c.reqMu.Unlock()
+	c.unlockVolume(ll, req.Name)
 
 	if err != nil {
 		ll.Errorf("Failed to create volume: %v", err)
@@ -229,12 +316,18 @@ func (c *CSIControllerService) CreateVolume(ctx context.Context, req *csi.Create
 
 	if vol.CSIStatus == apiV1.Creating {
 		ll.Infof("Waiting until volume will reach Created status. Current status - %s", vol.CSIStatus)
-		if err := c.svc.WaitStatus(ctx, vol.Id, apiV1.Failed, apiV1.Created); err != nil {
+		// filesystem might be created asynchronously, NodeStageVolume waits for its completion
+		if err := c.svc.WaitStatus(ctx, vol.Id, apiV1.Failed, apiV1.Created, apiV1.Formatting); err != nil {
 			return nil, status.Error(codes.Internal, "Unable to create volume")
 		}
 	}
 
 	ll.Infof("Construct response based on volume: %v", vol)
+	volumeContext, err := c.getVolumeContext(ctx, req.GetParameters(), vol)
+	if err != nil {
+		ll.Errorf("Failed to construct volume attributes: %v", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
 	topologyList := []*csi.Topology{
 		{Segments: map[string]string{csibmnodeconst.NodeIDTopologyLabelKey: vol.NodeId}},
 	}
@@ -243,7 +336,7 @@ func (c *CSIControllerService) CreateVolume(ctx context.Context, req *csi.Create
 		Volume: &csi.Volume{
 			VolumeId:           req.Name,
 			CapacityBytes:      vol.Size,
-			VolumeContext:      req.GetParameters(),
+			VolumeContext:      volumeContext,
 			AccessibleTopology: topologyList,
 		},
 	}, nil
@@ -264,11 +357,22 @@ func (c *CSIControllerService) DeleteVolume(ctx context.Context, req *csi.Delete
 	if req.VolumeId == "" {
 		return nil, status.Error(codes.InvalidArgument, "Volume ID must be provided")
 	}
+	if err := c.checkProvisioningFreeze("DeleteVolume"); err != nil {
+		return nil, err
+	}
+	if err := c.checkDeletionProtection(ctx, req.VolumeId); err != nil {
+		return nil, err
+	}
+	done, ok := c.operations.Begin("DeleteVolume " + req.VolumeId)
+	if !ok {
+		return nil, status.Error(codes.Unavailable, "controller service is shutting down")
+	}
+	defer done()
 	ctxWithID := context.WithValue(context.Background(), base.RequestUUID, req.VolumeId)
 
-	c.reqMu.Lock()
+	c.volMu.LockKey(req.VolumeId)
 	err := c.svc.DeleteVolume(ctxWithID, req.GetVolumeId())
-	c.reqMu.Unlock()
+	c.unlockVolume(ll, req.VolumeId)
 
 	if err != nil {
 		if k8sError.IsNotFound(err) || (status.Code(err) == codes.NotFound) {
@@ -284,9 +388,9 @@ func (c *CSIControllerService) DeleteVolume(ctx context.Context, req *csi.Delete
 		return nil, status.Error(codes.Internal, "Unable to delete volume")
 	}
 
-	c.reqMu.Lock()
+	c.volMu.LockKey(req.VolumeId)
 	c.svc.UpdateCRsAfterVolumeDeletion(ctxWithID, req.VolumeId)
-	c.reqMu.Unlock()
+	c.unlockVolume(ll, req.VolumeId)
 
 	ll.Debug("Volume was successfully deleted")
 
@@ -351,18 +455,9 @@ func (c *CSIControllerService) ValidateVolumeCapabilities(context.Context, *csi.
 	return nil, status.Error(codes.Unimplemented, "not implemented yet")
 }
 
-// ListVolumes is not implemented yet
-func (c *CSIControllerService) ListVolumes(context.Context, *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "not implemented yet")
-}
-
-// GetCapacity is not implemented yet
-func (c *CSIControllerService) GetCapacity(context.Context, *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "not implemented yet")
-}
-
 // ControllerGetCapabilities is the implementation of CSI Spec ControllerGetCapabilities.
-// Provides Controller capabilities of CSI driver to k8s CREATE/DELETE Volume and PUBLISH/UNPUBLISH Volume for now.
+// Provides Controller capabilities of CSI driver to k8s: CREATE/DELETE, PUBLISH/UNPUBLISH and EXPAND Volume,
+// LIST_VOLUMES with published nodes and condition of volumes, GET_CAPACITY.
 // Receives golang context and CSI Spec ControllerGetCapabilitiesRequest
 // Returns CSI Spec ControllerGetCapabilitiesResponse and nil error
 func (c *CSIControllerService) ControllerGetCapabilities(context.Context, *csi.ControllerGetCapabilitiesRequest) (*csi.ControllerGetCapabilitiesResponse, error) {
@@ -385,6 +480,10 @@ func (c *CSIControllerService) ControllerGetCapabilities(context.Context, *csi.C
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
 		csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
 		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES_PUBLISHED_NODES,
+		csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
+		csi.ControllerServiceCapability_RPC_GET_CAPACITY,
 	} {
 		caps = append(caps, newCap(c))
 	}
@@ -440,6 +539,11 @@ func (c *CSIControllerService) ControllerExpandVolume(ctx context.Context, req *
 	if volID == "" {
 		return nil, status.Error(codes.InvalidArgument, "Volume name missing in request")
 	}
+	// size of LV is aligned by physical extent, so limit might be exceeded for unaligned required bytes
+	if limit := req.GetCapacityRange().GetLimitBytes(); limit > 0 && requiredBytes > limit {
+		return nil, status.Errorf(codes.OutOfRange, "required capacity %d aligned by PE exceeds limit %d",
+			requiredBytes, limit)
+	}
 
 	volume, err := c.crHelper.GetVolumeByID(volID)
 
@@ -453,9 +557,9 @@ func (c *CSIControllerService) ControllerExpandVolume(ctx context.Context, req *
 		}, nil
 	}
 
-	c.reqMu.Lock()
+	c.volMu.LockKey(volID)
 	err = c.svc.ExpandVolume(ctx, volume, requiredBytes)
-	c.reqMu.Unlock()
+	c.unlockVolume(ll, volID)
 
 	if err != nil {
 		return nil, err
@@ -463,9 +567,9 @@ func (c *CSIControllerService) ControllerExpandVolume(ctx context.Context, req *
 
 	err = c.svc.WaitStatus(ctxWithID, volID, apiV1.Failed, apiV1.Resized)
 
-	c.reqMu.Lock()
+	c.volMu.LockKey(volID)
 	c.svc.UpdateCRsAfterVolumeExpansion(ctx, volID, requiredBytes)
-	c.reqMu.Unlock()
+	c.unlockVolume(ll, volID)
 
 	if err != nil {
 		return nil, status.Error(codes.Internal, "Unable to expand volume")
@@ -473,13 +577,25 @@ func (c *CSIControllerService) ControllerExpandVolume(ctx context.Context, req *
 
 	return &csi.ControllerExpandVolumeResponse{
 		CapacityBytes:         requiredBytes,
-		NodeExpansionRequired: false,
+		NodeExpansionRequired: isNodeExpansionRequired(&volume.Spec),
 	}, nil
 }
 
+// isNodeExpansionRequired checks whether NodeExpandVolume must be called after LV is extended:
+// file system of volume in mount mode and LUKS device of encrypted volume are grown on node
+func isNodeExpansionRequired(volume *api.Volume) bool {
+	return volume.Mode == apiV1.ModeFS || volume.Encryption != ""
+}
+
 func isNeedForRawPart(params map[string]string) bool {
 	if value, ok := params[RawPartModeKey]; ok && value == RawPartModeValue {
 		return true
 	}
 	return false
 }
+
+func (c *CSIControllerService) unlockVolume(log *logrus.Entry, volumeID string) {
+	if err := c.volMu.UnlockKey(volumeID); err != nil {
+		log.Warnf("Unlocking volume with error %s", err)
+	}
+}
@@ -47,6 +47,7 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	"github.com/dell/csi-baremetal/pkg/base/nodeprotocol"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	"github.com/dell/csi-baremetal/pkg/common"
 	csibmnodeconst "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
@@ -200,6 +201,46 @@ var _ = Describe("CSIControllerService CreateVolume", func() {
 			Expect(err).ToNot(BeNil())
 			Expect(err.Error()).To(ContainSubstring("Volume capabilities missing in request"))
 		})
+		It("Multi node access mode", func() {
+			req := getCreateVolumeRequest("req1", 1024*1024*1024, testNode1Name, "", false, false)
+			req.VolumeCapabilities[0].AccessMode.Mode = csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER
+			resp, err := controller.CreateVolume(context.Background(), req)
+			Expect(resp).To(BeNil())
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+			Expect(err.Error()).To(ContainSubstring("MULTI_NODE_MULTI_WRITER"))
+		})
+		It("Unsupported encryption", func() {
+			req := getCreateVolumeRequest("req1", 1024*1024*1024, testNode1Name, "", false, false)
+			req.Parameters[EncryptionKey] = "dm-crypt"
+			resp, err := controller.CreateVolume(context.Background(), req)
+			Expect(resp).To(BeNil())
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+		It("Encryption feature gate is disabled", func() {
+			featureConf := featureconfig.NewFeatureConfig()
+			featureConf.Update(featureconfig.FeatureVolumeEncryption, false)
+			controller.featureConf = featureConf
+			req := getCreateVolumeRequest("req1", 1024*1024*1024, testNode1Name, "", false, false)
+			req.Parameters[EncryptionKey] = apiV1.EncryptionLUKS
+			resp, err := controller.CreateVolume(context.Background(), req)
+			Expect(resp).To(BeNil())
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+			Expect(err.Error()).To(ContainSubstring(featureconfig.FeatureVolumeEncryption))
+		})
+		It("Unsupported file system", func() {
+			req := getCreateVolumeRequest("req1", 1024*1024*1024, testNode1Name, "", false, false)
+			req.VolumeCapabilities[0].GetMount().FsType = "zfs"
+			resp, err := controller.CreateVolume(context.Background(), req)
+			Expect(resp).To(BeNil())
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+		It("Wrong lazy format value", func() {
+			req := getCreateVolumeRequest("req1", 1024*1024*1024, testNode1Name, "", false, false)
+			req.Parameters[LazyFormatKey] = "sometimes"
+			resp, err := controller.CreateVolume(context.Background(), req)
+			Expect(resp).To(BeNil())
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
 		It("Reservation not found", func() {
 			req := getCreateVolumeRequest("req1", 1024*1024*1024*1024, "", "testClaim", false, false)
 
@@ -632,6 +673,10 @@ var _ = Describe("CSIControllerService ControllerGetCapabilities", func() {
 				csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
 				csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
 				csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
+				csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+				csi.ControllerServiceCapability_RPC_LIST_VOLUMES_PUBLISHED_NODES,
+				csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
+				csi.ControllerServiceCapability_RPC_GET_CAPACITY,
 			}
 		)
 
@@ -769,6 +814,15 @@ var _ = Describe("CSIControllerService ControllerExpandVolume", func() {
 			Expect(resp).To(BeNil())
 			Expect(err).To(Equal(status.Error(codes.InvalidArgument, "Volume name missing in request")))
 		})
+		It("Required capacity exceeds limit", func() {
+			req := &csi.ControllerExpandVolumeRequest{
+				VolumeId:      uuid,
+				CapacityRange: &csi.CapacityRange{RequiredBytes: 1025, LimitBytes: 1025},
+			}
+			resp, err := controller.ControllerExpandVolume(context.Background(), req)
+			Expect(resp).To(BeNil())
+			Expect(status.Code(err)).To(Equal(codes.OutOfRange))
+		})
 		It("Volume doesn't exist", func() {
 			req := &csi.ControllerExpandVolumeRequest{VolumeId: "unknown", VolumeCapability: &csi.VolumeCapability{}}
 			resp, err := controller.ControllerExpandVolume(context.Background(), req)
@@ -895,11 +949,29 @@ var _ = Describe("CSIControllerService ControllerExpandVolume", func() {
 			Expect(resp).ToNot(BeNil())
 			Expect(err).To(BeNil())
 			Expect(resp.CapacityBytes).To(Equal(capacityplanner.AlignSizeByPE(capacity)))
-
+			// volume in block mode without encryption
+			Expect(resp.NodeExpansionRequired).To(BeFalse())
 		})
 	})
 })
 
+func TestController_isNodeExpansionRequired(t *testing.T) {
+	assert.True(t, isNodeExpansionRequired(&api.Volume{Mode: apiV1.ModeFS}))
+	assert.True(t, isNodeExpansionRequired(&api.Volume{Mode: apiV1.ModeRAW, Encryption: apiV1.EncryptionLUKS}))
+	assert.False(t, isNodeExpansionRequired(&api.Volume{Mode: apiV1.ModeRAW}))
+}
+
+// scheduler extender filters nodes by capabilities which are derived from the same storage class parameters
+func TestController_parametersRequireNodeCapabilities(t *testing.T) {
+	params := map[string]string{
+		EncryptionKey: apiV1.EncryptionLUKS,
+		LazyFormatKey: "true",
+		CacheModeKey:  apiV1.CacheModeBcache,
+		TemplateKey:   "ubuntu-20.04",
+	}
+	assert.ElementsMatch(t, nodeprotocol.Capabilities, nodeprotocol.RequiredCapabilitiesByParameters(params))
+}
+
 func TestController_UnimplementedMethods(t *testing.T) {
 
 	controller := newSvc()
@@ -910,11 +982,6 @@ func TestController_UnimplementedMethods(t *testing.T) {
 		assert.True(t, strings.Contains(err.Error(), expected))
 	})
 
-	t.Run("ListVolumes", func(t *testing.T) {
-		_, err := controller.ListVolumes(testCtx, nil)
-		assert.True(t, strings.Contains(err.Error(), expected))
-	})
-
 	t.Run("CreateSnapshot", func(t *testing.T) {
 		_, err := controller.CreateSnapshot(testCtx, nil)
 		assert.True(t, strings.Contains(err.Error(), expected))
@@ -934,11 +1001,6 @@ func TestController_UnimplementedMethods(t *testing.T) {
 		_, err := controller.ControllerGetVolume(testCtx, nil)
 		assert.True(t, strings.Contains(err.Error(), expected))
 	})
-
-	t.Run("GetCapacity", func(t *testing.T) {
-		_, err := controller.GetCapacity(testCtx, nil)
-		assert.True(t, strings.Contains(err.Error(), expected))
-	})
 }
 
 // create and instance of CSIControllerService with scheme for working with CRD
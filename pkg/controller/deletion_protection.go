@@ -0,0 +1,56 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+// checkDeletionProtection returns FailedPrecondition error if PersistentVolume of the volume is labeled with
+// retain-data=true and deletion of its data isn't confirmed by annotation, so sidecar retries request later and
+// data survives accidental deletion of PVC, e.g. together with namespace, until administrator confirms it
+func (c *CSIControllerService) checkDeletionProtection(ctx context.Context, volumeID string) error {
+	ll := c.log.WithFields(logrus.Fields{
+		"method":   "checkDeletionProtection",
+		"volumeID": volumeID,
+	})
+
+	// PV name matches volume ID since volume ID is a name of CreateVolume request
+	pv := &corev1.PersistentVolume{}
+	if err := c.k8sclient.Get(ctx, k8sCl.ObjectKey{Name: volumeID}, pv); err != nil {
+		if k8sError.IsNotFound(err) {
+			return nil
+		}
+		ll.Errorf("Unable to read PV: %v", err)
+		return status.Errorf(codes.Unavailable, "unable to check deletion protection of volume: %v", err)
+	}
+	if pv.Labels[apiV1.PVLabelRetainData] != "true" || pv.Annotations[apiV1.PVAnnotationConfirmDataDeletion] == "true" {
+		return nil
+	}
+	ll.Warnf("Deletion of volume data is blocked, PV has label %s=true, set annotation %s=true to confirm deletion",
+		apiV1.PVLabelRetainData, apiV1.PVAnnotationConfirmDataDeletion)
+	return status.Errorf(codes.FailedPrecondition, "PV %s is protected by label %s=true, set annotation %s=true "+
+		"to confirm deletion of data", pv.Name, apiV1.PVLabelRetainData, apiV1.PVAnnotationConfirmDataDeletion)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controller
+
+import (
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+func TestCSIControllerService_checkDeletionProtection(t *testing.T) {
+	var (
+		svc = newSvc()
+		pv  = &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}}
+	)
+	// PV is already removed
+	assert.Nil(t, svc.checkDeletionProtection(testCtx, pv.Name))
+
+	assert.Nil(t, svc.k8sclient.CreateCR(testCtx, pv.Name, pv))
+	assert.Nil(t, svc.checkDeletionProtection(testCtx, pv.Name))
+
+	pv.Labels = map[string]string{apiV1.PVLabelRetainData: "true"}
+	assert.Nil(t, svc.k8sclient.UpdateCR(testCtx, pv))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(svc.checkDeletionProtection(testCtx, pv.Name)))
+	_, err := svc.DeleteVolume(testCtx, &csi.DeleteVolumeRequest{VolumeId: pv.Name})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	pv.Annotations = map[string]string{apiV1.PVAnnotationConfirmDataDeletion: "false"}
+	assert.Nil(t, svc.k8sclient.UpdateCR(testCtx, pv))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(svc.checkDeletionProtection(testCtx, pv.Name)))
+
+	pv.Annotations[apiV1.PVAnnotationConfirmDataDeletion] = "true"
+	assert.Nil(t, svc.k8sclient.UpdateCR(testCtx, pv))
+	assert.Nil(t, svc.checkDeletionProtection(testCtx, pv.Name))
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	csibmnodeconst "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
+)
+
+// GetCapacity is the implementation of CSI Spec GetCapacity. Returns free capacity of storage type from
+// StorageClass parameters on the node from topology or in the whole cluster if topology isn't set.
+// Capacity reserved for pods being scheduled isn't counted.
+// Receives golang context and CSI Spec GetCapacityRequest
+// Returns CSI Spec GetCapacityResponse or error if something went wrong
+func (c *CSIControllerService) GetCapacity(ctx context.Context, req *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
+	ll := c.log.WithFields(logrus.Fields{
+		"method": "GetCapacity",
+	})
+
+	node := req.GetAccessibleTopology().GetSegments()[csibmnodeconst.NodeIDTopologyLabelKey]
+	if req.GetAccessibleTopology() != nil && node == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "topology segment %s is missing",
+			csibmnodeconst.NodeIDTopologyLabelKey)
+	}
+	storageClass := util.ConvertStorageClass(req.GetParameters()[base.StorageTypeKey])
+
+	capReader := capacityplanner.NewUnreservedACReader(ll, capacityplanner.NewACReader(c.k8sclient, ll, false).WithNodeID(node),
+		capacityplanner.NewACRReader(c.k8sclient, ll, false))
+	acs, err := capReader.ReadCapacity(ctx)
+	if err != nil {
+		ll.Errorf("Unable to read available capacity: %v", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var (
+		matching  = matchingStorageClasses(storageClass)
+		available int64
+		maximum   int64
+	)
+	for _, ac := range acs {
+		if (node != "" && ac.Spec.NodeId != node) || !util.ContainsString(matching, ac.Spec.StorageClass) {
+			continue
+		}
+		size := ac.Spec.Size
+		// LVM metadata takes space of the drive, LVG which is created on free drive is smaller than its AC
+		if util.IsStorageClassLVG(storageClass) && !util.IsStorageClassLVG(ac.Spec.StorageClass) {
+			size = capacityplanner.SubtractLVMMetadataSize(size)
+		}
+		if size <= 0 {
+			continue
+		}
+		available += size
+		if size > maximum {
+			maximum = size
+		}
+	}
+
+	ll.Debugf("Capacity of %s on node %q: available %d, maximum volume size %d", storageClass, node,
+		available, maximum)
+	return &csi.GetCapacityResponse{
+		AvailableCapacity: available,
+		MaximumVolumeSize: &wrappers.Int64Value{Value: maximum},
+	}, nil
+}
+
+// matchingStorageClasses returns storage classes of ACs which volume of storageClass might be placed on,
+// order of selection is the same as in capacity planner
+func matchingStorageClasses(storageClass string) []string {
+	switch storageClass {
+	case apiV1.StorageClassAny:
+		return []string{apiV1.StorageClassHDD, apiV1.StorageClassSSD, apiV1.StorageClassNVMe}
+	case apiV1.StorageClassHDDLVG:
+		return []string{apiV1.StorageClassHDDLVG, apiV1.StorageClassHDD}
+	case apiV1.StorageClassSSDLVG:
+		return []string{apiV1.StorageClassSSDLVG, apiV1.StorageClassSSD}
+	case apiV1.StorageClassNVMeLVG:
+		return []string{apiV1.StorageClassNVMeLVG, apiV1.StorageClassNVMe}
+	default:
+		return []string{storageClass}
+	}
+}
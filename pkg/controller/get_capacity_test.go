@@ -0,0 +1,80 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
+	csibmnodeconst "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
+)
+
+func TestCSIControllerService_GetCapacity(t *testing.T) {
+	var (
+		svc  = newSvc()
+		gb   = int64(1024 * 1024 * 1024)
+		node = func(id string) *csi.Topology {
+			return &csi.Topology{Segments: map[string]string{csibmnodeconst.NodeIDTopologyLabelKey: id}}
+		}
+		params = func(sc string) map[string]string {
+			return map[string]string{base.StorageTypeKey: sc}
+		}
+	)
+	for _, ac := range []api.AvailableCapacity{
+		{Location: "drive1", NodeId: testNode1Name, StorageClass: apiV1.StorageClassHDD, Size: 100 * gb},
+		{Location: "drive2", NodeId: testNode1Name, StorageClass: apiV1.StorageClassHDD, Size: 50 * gb},
+		{Location: "drive3", NodeId: testNode2Name, StorageClass: apiV1.StorageClassSSD, Size: 20 * gb},
+		{Location: "lvg1", NodeId: testNode2Name, StorageClass: apiV1.StorageClassSSDLVG, Size: 10 * gb},
+	} {
+		cr := svc.k8sclient.ConstructACCR(ac.Location, ac)
+		assert.Nil(t, svc.k8sclient.CreateCR(testCtx, cr.Name, cr))
+	}
+
+	resp, err := svc.GetCapacity(testCtx, &csi.GetCapacityRequest{Parameters: params(apiV1.StorageClassHDD),
+		AccessibleTopology: node(testNode1Name)})
+	assert.Nil(t, err)
+	assert.Equal(t, 150*gb, resp.AvailableCapacity)
+	assert.Equal(t, 100*gb, resp.MaximumVolumeSize.GetValue())
+
+	resp, err = svc.GetCapacity(testCtx, &csi.GetCapacityRequest{Parameters: params(apiV1.StorageClassHDD),
+		AccessibleTopology: node(testNode2Name)})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), resp.AvailableCapacity)
+
+	// whole cluster
+	resp, err = svc.GetCapacity(testCtx, &csi.GetCapacityRequest{Parameters: params(apiV1.StorageClassAny)})
+	assert.Nil(t, err)
+	assert.Equal(t, 170*gb, resp.AvailableCapacity)
+
+	// LVG volume might be placed on free drive
+	resp, err = svc.GetCapacity(testCtx, &csi.GetCapacityRequest{Parameters: params(apiV1.StorageClassSSDLVG),
+		AccessibleTopology: node(testNode2Name)})
+	assert.Nil(t, err)
+	assert.Equal(t, 10*gb+capacityplanner.SubtractLVMMetadataSize(20*gb), resp.AvailableCapacity)
+	assert.Equal(t, capacityplanner.SubtractLVMMetadataSize(20*gb), resp.MaximumVolumeSize.GetValue())
+
+	_, err = svc.GetCapacity(testCtx, &csi.GetCapacityRequest{AccessibleTopology: &csi.Topology{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
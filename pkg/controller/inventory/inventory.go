@@ -0,0 +1,238 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inventory contains read-only HTTP endpoint which serves drives, volumes and capacity per node in JSON
+// for external CMDB and asset management systems which can't use kubernetes API
+package inventory
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dell/csi-baremetal/api/v1/nodecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+// Path is HTTP path of inventory endpoint
+const Path = "/inventory"
+
+// Drive holds inventory data of drive
+type Drive struct {
+	UUID         string `json:"uuid"`
+	SerialNumber string `json:"serialNumber"`
+	Vendor       string `json:"vendor"`
+	Model        string `json:"model"`
+	Firmware     string `json:"firmware,omitempty"`
+	Type         string `json:"type"`
+	Size         int64  `json:"size"`
+	Health       string `json:"health"`
+	Status       string `json:"status"`
+	Usage        string `json:"usage"`
+	Slot         string `json:"slot,omitempty"`
+	Bay          string `json:"bay,omitempty"`
+	IsSystem     bool   `json:"isSystem"`
+}
+
+// Volume holds inventory data of volume
+type Volume struct {
+	ID           string `json:"id"`
+	Namespace    string `json:"namespace"`
+	Location     string `json:"location"`
+	StorageClass string `json:"storageClass"`
+	Size         int64  `json:"size"`
+	Health       string `json:"health"`
+	Status       string `json:"status"`
+	Usage        string `json:"usage"`
+}
+
+// Capacity holds free capacity of the node per storage class
+type Capacity struct {
+	StorageClass string `json:"storageClass"`
+	Free         int64  `json:"free"`
+}
+
+// Node holds inventory of the node
+type Node struct {
+	ID       string     `json:"id"`
+	Hostname string     `json:"hostname,omitempty"`
+	Drives   []Drive    `json:"drives"`
+	Volumes  []Volume   `json:"volumes"`
+	Capacity []Capacity `json:"capacity"`
+}
+
+// Inventory is a response of inventory endpoint
+type Inventory struct {
+	Nodes []*Node `json:"nodes"`
+}
+
+// Server serves inventory endpoint, requests must have bearer token
+type Server struct {
+	client   *k8s.KubeClient
+	crHelper *k8s.CRHelper
+	token    string
+	log      *logrus.Entry
+}
+
+// NewServer is the constructor for Server struct
+// Receives an instance of base.KubeClient, CRReader (cache), bearer token of clients and logrus logger
+// Returns an instance of Server
+func NewServer(client *k8s.KubeClient, k8sCache k8s.CRReader, token string, logger *logrus.Logger) *Server {
+	return &Server{
+		client:   client,
+		crHelper: k8s.NewCRHelper(client, logger).SetReader(k8sCache),
+		token:    token,
+		log:      logger.WithField("component", "InventoryServer"),
+	}
+}
+
+// ReadToken reads bearer token from file, token mustn't be empty
+func ReadToken(path string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// ServeHTTP checks bearer token and writes inventory in JSON
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ll := s.log.WithFields(logrus.Fields{
+		"method": "ServeHTTP",
+		"remote": req.RemoteAddr,
+	})
+
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		ll.Warn("Unauthorized inventory request")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	inventory, err := s.Inventory(req.Context())
+	if err != nil {
+		ll.Errorf("Unable to collect inventory: %v", err)
+		http.Error(w, "unable to collect inventory", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(inventory); err != nil {
+		ll.Errorf("Unable to write response: %v", err)
+	}
+}
+
+// Inventory collects drives, volumes and free capacity of all nodes, nodes are sorted by ID
+func (s *Server) Inventory(ctx context.Context) (*Inventory, error) {
+	drives, err := s.crHelper.GetDriveCRs()
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := s.crHelper.GetVolumeCRs()
+	if err != nil {
+		return nil, err
+	}
+	acs, err := s.crHelper.GetACCRs()
+	if err != nil {
+		return nil, err
+	}
+	nodeCRs := &nodecrd.NodeList{}
+	if err = s.client.ReadList(ctx, nodeCRs); err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*Node)
+	getNode := func(id string) *Node {
+		if _, ok := nodes[id]; !ok {
+			nodes[id] = &Node{ID: id, Drives: []Drive{}, Volumes: []Volume{}, Capacity: []Capacity{}}
+		}
+		return nodes[id]
+	}
+	for _, nodeCR := range nodeCRs.Items {
+		getNode(nodeCR.Spec.UUID).Hostname = nodeCR.Spec.Addresses["Hostname"]
+	}
+	for _, d := range drives {
+		node := getNode(d.Spec.NodeId)
+		node.Drives = append(node.Drives, Drive{
+			UUID:         d.Spec.UUID,
+			SerialNumber: d.Spec.SerialNumber,
+			Vendor:       d.Spec.VID,
+			Model:        d.Spec.PID,
+			Firmware:     d.Spec.Firmware,
+			Type:         d.Spec.Type,
+			Size:         d.Spec.Size,
+			Health:       d.Spec.Health,
+			Status:       d.Spec.Status,
+			Usage:        d.Spec.Usage,
+			Slot:         d.Spec.Slot,
+			Bay:          d.Spec.Bay,
+			IsSystem:     d.Spec.IsSystem,
+		})
+	}
+	for _, v := range volumes {
+		node := getNode(v.Spec.NodeId)
+		node.Volumes = append(node.Volumes, Volume{
+			ID:           v.Spec.Id,
+			Namespace:    v.Namespace,
+			Location:     v.Spec.Location,
+			StorageClass: v.Spec.StorageClass,
+			Size:         v.Spec.Size,
+			Health:       v.Spec.Health,
+			Status:       v.Spec.CSIStatus,
+			Usage:        v.Spec.Usage,
+		})
+	}
+	free := make(map[string]map[string]int64)
+	for _, ac := range acs {
+		if free[ac.Spec.NodeId] == nil {
+			free[ac.Spec.NodeId] = make(map[string]int64)
+		}
+		free[ac.Spec.NodeId][ac.Spec.StorageClass] += ac.Spec.Size
+	}
+	for nodeID, perSC := range free {
+		node := getNode(nodeID)
+		for sc, size := range perSC {
+			node.Capacity = append(node.Capacity, Capacity{StorageClass: sc, Free: size})
+		}
+		sort.Slice(node.Capacity, func(i, j int) bool {
+			return node.Capacity[i].StorageClass < node.Capacity[j].StorageClass
+		})
+	}
+
+	inventory := &Inventory{Nodes: make([]*Node, 0, len(nodes))}
+	for _, node := range nodes {
+		inventory.Nodes = append(inventory.Nodes, node)
+	}
+	sort.Slice(inventory.Nodes, func(i, j int) bool {
+		return inventory.Nodes[i].ID < inventory.Nodes[j].ID
+	})
+	return inventory, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+var (
+	testCtx    = context.Background()
+	testLogger = logrus.New()
+	testNodeID = "node-uuid"
+	testToken  = "secret"
+)
+
+func TestServer_ServeHTTP(t *testing.T) {
+	client, err := k8s.GetFakeKubeClient("default", testLogger)
+	assert.Nil(t, err)
+
+	node := client.ConstructCSIBMNodeCR("csibmnode", api.Node{UUID: testNodeID,
+		Addresses: map[string]string{"Hostname": "host1"}})
+	assert.Nil(t, client.CreateCR(testCtx, node.Name, node))
+	drive := client.ConstructDriveCR("drive-uuid", api.Drive{UUID: "drive-uuid", NodeId: testNodeID,
+		SerialNumber: "sn1", Type: apiV1.DriveTypeHDD, Size: 100, Health: apiV1.HealthGood, Bay: "3"})
+	assert.Nil(t, client.CreateCR(testCtx, drive.Name, drive))
+	ac := client.ConstructACCR("ac", api.AvailableCapacity{Location: "drive-uuid", NodeId: testNodeID,
+		StorageClass: apiV1.StorageClassHDD, Size: 40})
+	assert.Nil(t, client.CreateCR(testCtx, ac.Name, ac))
+	volume := client.ConstructVolumeCR("pvc-1", "default", nil, api.Volume{Id: "pvc-1", NodeId: testNodeID,
+		Location: "drive-uuid", Size: 60, CSIStatus: apiV1.Published})
+	assert.Nil(t, client.CreateCR(testCtx, volume.Name, volume))
+
+	server := NewServer(client, client, testToken, testLogger)
+
+	// unauthorized
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	req := httptest.NewRequest(http.MethodGet, Path, nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp = httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// read-only
+	req = httptest.NewRequest(http.MethodPost, Path, nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp = httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+
+	req = httptest.NewRequest(http.MethodGet, Path, nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp = httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	inventory := &Inventory{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(inventory))
+	assert.Len(t, inventory.Nodes, 1)
+	result := inventory.Nodes[0]
+	assert.Equal(t, "host1", result.Hostname)
+	assert.Len(t, result.Drives, 1)
+	assert.Equal(t, "3", result.Drives[0].Bay)
+	assert.Len(t, result.Volumes, 1)
+	assert.Equal(t, apiV1.Published, result.Volumes[0].Status)
+	assert.Equal(t, []Capacity{{StorageClass: apiV1.StorageClassHDD, Free: 40}}, result.Capacity)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+)
+
+const (
+	// SerialPassthroughKey is a parameter key which enables exposing of drive serial number in volume attributes
+	SerialPassthroughKey = "serialPassthrough"
+	// DriveSerialAttribute is a volume attribute which holds serial number of drive of the volume,
+	// might be passed to KubeVirt VM disk as is
+	DriveSerialAttribute = "csi-baremetal.dell.com/drive-serial"
+)
+
+// parseSerialPassthroughParameter returns true if serial number of volume drive is requested in volume attributes
+func parseSerialPassthroughParameter(params map[string]string) (bool, error) {
+	value, ok := params[SerialPassthroughKey]
+	if !ok {
+		return false, nil
+	}
+	passthrough, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s parameter has wrong value %s", SerialPassthroughKey, value)
+	}
+	return passthrough, nil
+}
+
+// getVolumeContext returns attributes of created volume, serial number of drive is added for volume
+// located on the whole drive if it's requested. Volume on LVG doesn't own drive, serial isn't added for it
+func (c *CSIControllerService) getVolumeContext(ctx context.Context, params map[string]string,
+	vol *api.Volume) (map[string]string, error) {
+	passthrough, err := parseSerialPassthroughParameter(params)
+	if err != nil || !passthrough || vol.LocationType != apiV1.LocationTypeDrive {
+		return params, err
+	}
+
+	drive := &drivecrd.Drive{}
+	if err := c.k8sclient.ReadCR(ctx, vol.Location, "", drive); err != nil {
+		return nil, fmt.Errorf("unable to read drive %s of volume: %v", vol.Location, err)
+	}
+	volumeContext := make(map[string]string, len(params)+1)
+	for key, value := range params {
+		volumeContext[key] = value
+	}
+	volumeContext[DriveSerialAttribute] = drive.Spec.SerialNumber
+	return volumeContext, nil
+}
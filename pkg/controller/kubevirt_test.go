@@ -0,0 +1,73 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+func Test_parseSerialPassthroughParameter(t *testing.T) {
+	passthrough, err := parseSerialPassthroughParameter(map[string]string{})
+	assert.Nil(t, err)
+	assert.False(t, passthrough)
+
+	passthrough, err = parseSerialPassthroughParameter(map[string]string{SerialPassthroughKey: "true"})
+	assert.Nil(t, err)
+	assert.True(t, passthrough)
+
+	_, err = parseSerialPassthroughParameter(map[string]string{SerialPassthroughKey: "yes-please"})
+	assert.NotNil(t, err)
+}
+
+func TestCSIControllerService_getVolumeContext(t *testing.T) {
+	var (
+		svc    = newSvc()
+		params = map[string]string{SerialPassthroughKey: "true"}
+		serial = "S3EVNX0M123456"
+		vol    = &api.Volume{Id: testID, Location: testDriveLocation1, LocationType: apiV1.LocationTypeDrive}
+	)
+
+	// drive CR doesn't exist
+	_, err := svc.getVolumeContext(testCtx, params, vol)
+	assert.NotNil(t, err)
+
+	drive := svc.k8sclient.ConstructDriveCR(testDriveLocation1, api.Drive{UUID: testDriveLocation1,
+		SerialNumber: serial, NodeId: testNode1Name})
+	assert.Nil(t, svc.k8sclient.CreateCR(testCtx, testDriveLocation1, drive))
+
+	volumeContext, err := svc.getVolumeContext(testCtx, params, vol)
+	assert.Nil(t, err)
+	assert.Equal(t, serial, volumeContext[DriveSerialAttribute])
+	assert.NotContains(t, params, DriveSerialAttribute)
+
+	// volume on LVG
+	vol.LocationType = apiV1.LocationTypeLVM
+	volumeContext, err = svc.getVolumeContext(testCtx, params, vol)
+	assert.Nil(t, err)
+	assert.NotContains(t, volumeContext, DriveSerialAttribute)
+
+	// passthrough isn't requested
+	vol.LocationType = apiV1.LocationTypeDrive
+	volumeContext, err = svc.getVolumeContext(testCtx, map[string]string{}, vol)
+	assert.Nil(t, err)
+	assert.NotContains(t, volumeContext, DriveSerialAttribute)
+}
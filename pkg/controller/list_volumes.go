@@ -0,0 +1,118 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	csibmnodeconst "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
+)
+
+// ListVolumes is the implementation of CSI Spec ListVolumes. Returns volumes sorted by ID, their nodes and health.
+// Starting token is an index of the first volume in the list, inline volumes aren't listed.
+// Receives golang context and CSI Spec ListVolumesRequest
+// Returns CSI Spec ListVolumesResponse or error if something went wrong
+func (c *CSIControllerService) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
+	ll := c.log.WithFields(logrus.Fields{
+		"method": "ListVolumes",
+	})
+
+	if req.GetMaxEntries() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "max_entries must not be negative: %d", req.GetMaxEntries())
+	}
+	start := 0
+	if token := req.GetStartingToken(); token != "" {
+		var err error
+		if start, err = strconv.Atoi(token); err != nil || start < 0 {
+			return nil, status.Errorf(codes.Aborted, "starting_token %s is invalid", token)
+		}
+	}
+
+	volumeCRs, err := c.crHelper.GetVolumeCRs()
+	if err != nil {
+		ll.Errorf("Unable to read volumes: %v", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	volumes := make([]volumecrd.Volume, 0, len(volumeCRs))
+	for _, volume := range volumeCRs {
+		if !volume.Spec.Ephemeral {
+			volumes = append(volumes, volume)
+		}
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].Spec.Id < volumes[j].Spec.Id
+	})
+	if start > len(volumes) {
+		return nil, status.Errorf(codes.Aborted, "starting_token %d is greater than number of volumes %d",
+			start, len(volumes))
+	}
+
+	end := len(volumes)
+	if req.GetMaxEntries() > 0 && start+int(req.GetMaxEntries()) < end {
+		end = start + int(req.GetMaxEntries())
+	}
+	resp := &csi.ListVolumesResponse{Entries: make([]*csi.ListVolumesResponse_Entry, 0, end-start)}
+	for i := start; i < end; i++ {
+		resp.Entries = append(resp.Entries, volumeToListEntry(&volumes[i]))
+	}
+	if end < len(volumes) {
+		resp.NextToken = strconv.Itoa(end)
+	}
+	ll.Debugf("Return %d volumes starting from %d", len(resp.Entries), start)
+	return resp, nil
+}
+
+// volumeToListEntry converts Volume CR to ListVolumes entry, volume is abnormal if it isn't healthy or operative
+func volumeToListEntry(volume *volumecrd.Volume) *csi.ListVolumesResponse_Entry {
+	spec := &volume.Spec
+	entry := &csi.ListVolumesResponse_Entry{
+		Volume: &csi.Volume{
+			VolumeId:      spec.Id,
+			CapacityBytes: spec.Size,
+			AccessibleTopology: []*csi.Topology{
+				{Segments: map[string]string{csibmnodeconst.NodeIDTopologyLabelKey: spec.NodeId}},
+			},
+		},
+		Status: &csi.ListVolumesResponse_VolumeStatus{
+			VolumeCondition: &csi.VolumeCondition{Message: "volume is healthy"},
+		},
+	}
+	if spec.CSIStatus == apiV1.Published {
+		entry.Status.PublishedNodeIds = []string{spec.NodeId}
+	}
+	switch {
+	case spec.Health != "" && spec.Health != apiV1.HealthGood:
+		entry.Status.VolumeCondition = &csi.VolumeCondition{Abnormal: true,
+			Message: fmt.Sprintf("volume health is %s", spec.Health)}
+	case spec.OperationalStatus != "" && spec.OperationalStatus != apiV1.OperationalStatusOperative:
+		entry.Status.VolumeCondition = &csi.VolumeCondition{Abnormal: true,
+			Message: fmt.Sprintf("volume operational status is %s", spec.OperationalStatus)}
+	case spec.CSIStatus == apiV1.Failed:
+		entry.Status.VolumeCondition = &csi.VolumeCondition{Abnormal: true, Message: "volume is in FAILED status"}
+	}
+	return entry
+}
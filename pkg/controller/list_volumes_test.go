@@ -0,0 +1,65 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+func TestCSIControllerService_ListVolumes(t *testing.T) {
+	svc := newSvc()
+	for _, v := range []api.Volume{
+		{Id: "pvc-3", NodeId: testNode1Name, Size: 300, CSIStatus: apiV1.Published, Health: apiV1.HealthGood},
+		{Id: "pvc-1", NodeId: testNode1Name, Size: 100, CSIStatus: apiV1.Created, Health: apiV1.HealthBad},
+		{Id: "pvc-2", NodeId: testNode2Name, Size: 200, CSIStatus: apiV1.VolumeReady, Health: apiV1.HealthGood},
+		{Id: "inline", NodeId: testNode2Name, Size: 200, Ephemeral: true},
+	} {
+		volume := svc.k8sclient.ConstructVolumeCR(v.Id, testNs, nil, v)
+		assert.Nil(t, svc.k8sclient.CreateCR(testCtx, v.Id, volume))
+	}
+
+	resp, err := svc.ListVolumes(testCtx, &csi.ListVolumesRequest{MaxEntries: 2})
+	assert.Nil(t, err)
+	assert.Len(t, resp.Entries, 2)
+	assert.Equal(t, "pvc-1", resp.Entries[0].Volume.VolumeId)
+	assert.True(t, resp.Entries[0].Status.VolumeCondition.Abnormal)
+	assert.Empty(t, resp.Entries[0].Status.PublishedNodeIds)
+	assert.Equal(t, "2", resp.NextToken)
+
+	resp, err = svc.ListVolumes(testCtx, &csi.ListVolumesRequest{StartingToken: resp.NextToken})
+	assert.Nil(t, err)
+	assert.Len(t, resp.Entries, 1)
+	assert.Equal(t, "pvc-3", resp.Entries[0].Volume.VolumeId)
+	assert.Equal(t, []string{testNode1Name}, resp.Entries[0].Status.PublishedNodeIds)
+	assert.False(t, resp.Entries[0].Status.VolumeCondition.Abnormal)
+	assert.Empty(t, resp.NextToken)
+
+	_, err = svc.ListVolumes(testCtx, &csi.ListVolumesRequest{StartingToken: "10"})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	_, err = svc.ListVolumes(testCtx, &csi.ListVolumesRequest{StartingToken: "abc"})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	_, err = svc.ListVolumes(testCtx, &csi.ListVolumesRequest{MaxEntries: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
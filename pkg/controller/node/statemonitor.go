@@ -27,6 +27,7 @@ import (
 
 	apiV1 "github.com/dell/csi-baremetal/api/v1"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/sharding"
 )
 
 // constants for state monitoring
@@ -67,6 +68,8 @@ type ServicesStateMonitor struct {
 	nodeHealthMap map[string]*serviceState
 	// mutex to protect map access
 	lock *sync.RWMutex
+	// part of nodes which CRs are updated by the monitor, nil if sharding is disabled
+	shard *sharding.Shard
 }
 
 // serviceState keeps current state of the node service and last timestamp when its changed
@@ -101,6 +104,13 @@ func (n *ServicesStateMonitor) Run() {
 	go n.updateCRs()
 }
 
+// SetShard makes monitor update CRs only on nodes of the shard, health of all nodes is tracked anyway
+func (n *ServicesStateMonitor) SetShard(shard *sharding.Shard) {
+	n.lock.Lock()
+	n.shard = shard
+	n.lock.Unlock()
+}
+
 // GetUnreadyPods obtains list of Unready pods. Blocking for read
 func (n *ServicesStateMonitor) GetUnreadyPods() []string {
 	unready := make([]string, 0)
@@ -132,6 +142,22 @@ func (n *ServicesStateMonitor) GetReadyPods() []string {
 	return ready
 }
 
+// GetPermanentDownPods obtains list of node IDs which are in PermanentDown state longer than provided duration.
+// Blocking for read
+func (n *ServicesStateMonitor) GetPermanentDownPods(duration time.Duration) []string {
+	down := make([]string, 0)
+
+	n.lock.RLock()
+	for name, state := range n.nodeHealthMap {
+		if state.status == PermanentDown && time.Since(state.time) > duration {
+			down = append(down, name)
+		}
+	}
+	n.lock.RUnlock()
+
+	return down
+}
+
 // UpdateNodeHealthCache check if node service pods are ready and update nodeHealthMap
 func (n *ServicesStateMonitor) UpdateNodeHealthCache() {
 	log := n.log.WithFields(logrus.Fields{"method": "UpdateNodeHealthCache"})
@@ -294,6 +320,9 @@ func (n *ServicesStateMonitor) updateCRs() {
 		// obtain read lock
 		n.lock.RLock()
 		for id, state := range n.nodeHealthMap {
+			if !n.shard.Owns(id) {
+				continue
+			}
 			switch state.status {
 			case Unready:
 				unready = append(unready, id)
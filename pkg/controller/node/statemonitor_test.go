@@ -112,3 +112,13 @@ func TestPodIsUnderStartupProtection(t *testing.T) {
 		serviceState{Unready, time.Now(), false},
 		components))
 }
+
+func TestGetPermanentDownPods(t *testing.T) {
+	monitor := NewNodeServicesStateMonitor(nil, logrus.New())
+	monitor.nodeHealthMap["node-1"] = &serviceState{status: PermanentDown, time: time.Now().Add(-time.Hour)}
+	monitor.nodeHealthMap["node-2"] = &serviceState{status: PermanentDown, time: time.Now()}
+	monitor.nodeHealthMap["node-3"] = &serviceState{status: Unready, time: time.Now().Add(-time.Hour)}
+
+	assert.Equal(t, []string{"node-1"}, monitor.GetPermanentDownPods(time.Minute))
+	assert.Len(t, monitor.GetPermanentDownPods(0), 2)
+}
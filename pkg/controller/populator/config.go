@@ -0,0 +1,52 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package populator
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ParsePopulators parses populators in format "example.com/Dataset=registry/dataset-populator:1.0,..."
+// Returns map kind of data source -> image of populator pod or error if format is wrong
+func ParsePopulators(str string) (map[schema.GroupKind]string, error) {
+	populators := make(map[schema.GroupKind]string)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("populator %s has wrong format, expected <group>/<kind>=<image>", item)
+		}
+		kind := strings.TrimSpace(parts[0])
+		i := strings.LastIndex(kind, "/")
+		// core kinds (PVC) are handled by external-provisioner, so group is required
+		if i <= 0 || i == len(kind)-1 {
+			return nil, fmt.Errorf("populator %s has wrong data source kind, expected <group>/<kind>", item)
+		}
+		gk := schema.GroupKind{Group: kind[:i], Kind: kind[i+1:]}
+		if gk.Group == snapshotGroup {
+			return nil, fmt.Errorf("populator %s: volume snapshots are restored by CSI, not by populator", item)
+		}
+		populators[gk] = strings.TrimSpace(parts[1])
+	}
+	return populators, nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package populator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParsePopulators(t *testing.T) {
+	populators, err := ParsePopulators(" example.com/Dataset=registry/dataset-populator:1.0, images.example.com/Image=img ,")
+	assert.Nil(t, err)
+	assert.Equal(t, map[schema.GroupKind]string{
+		{Group: "example.com", Kind: "Dataset"}:      "registry/dataset-populator:1.0",
+		{Group: "images.example.com", Kind: "Image"}: "img",
+	}, populators)
+
+	populators, err = ParsePopulators("")
+	assert.Nil(t, err)
+	assert.Empty(t, populators)
+
+	for _, str := range []string{
+		"example.com/Dataset",
+		"example.com/Dataset=",
+		"Dataset=img",
+		"/Dataset=img",
+		"example.com/=img",
+		"snapshot.storage.k8s.io/VolumeSnapshot=img",
+	} {
+		_, err = ParsePopulators(str)
+		assert.NotNil(t, err, str)
+	}
+}
@@ -0,0 +1,353 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package populator contains controller which fills new volumes from custom data sources
+// using volume populator pattern of Kubernetes
+package populator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
+	metricsC "github.com/dell/csi-baremetal/pkg/metrics/common"
+)
+
+const (
+	// DataSourceEnv is an environment variable of populator pod with JSON of data source spec
+	DataSourceEnv = "DATA_SOURCE"
+	// VolumePathEnv is an environment variable of populator pod with path of the volume:
+	// mount point for Filesystem volumes and device for Block volumes
+	VolumePathEnv = "VOLUME_PATH"
+	// VolumeMountPath is a mount point of Filesystem volume in populator pod
+	VolumeMountPath = "/mnt/volume"
+	// VolumeDevicePath is a device of Block volume in populator pod
+	VolumeDevicePath = "/dev/volume"
+
+	// PopulatedFromAnnotation is set on PV filled by populator, value is <group>/<kind>/<name> of data source
+	PopulatedFromAnnotation = "csi-baremetal.dell.com/populated-from"
+	// targetAnnotation is set on prime PVC and populator pod, value is <namespace>/<name> of PVC being populated
+	targetAnnotation = "csi-baremetal.dell.com/populator-target"
+	// finalizer keeps PVC being populated until prime PVC and populator pod are removed
+	finalizer = "csi-baremetal.dell.com/populator"
+	// selectedNodeAnnotation is set by scheduler on PVC with WaitForFirstConsumer binding mode
+	selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+
+	// prime PVC and populator pod are named populate-<UID of PVC being populated>
+	primePrefix = "populate-"
+	// populatorContainer is a name of container and volume of populator pod
+	populatorContainer = "populate"
+	snapshotGroup      = "snapshot.storage.k8s.io"
+
+	// PollInterval is an interval of checks of populator pod and PV binding
+	PollInterval   = 10 * time.Second
+	contextTimeout = 60 * time.Second
+)
+
+// Controller fills volumes of PVCs which dataSourceRef points to custom resource of registered kind.
+// Volume is provisioned for prime PVC in CSI namespace, populator pod writes content of data source to it
+// and then PV is rebound to the original PVC
+type Controller struct {
+	client client.Client
+	// reader reads objects directly from API server, so pods, PVs and data sources aren't cached cluster wide
+	reader     client.Reader
+	mapper     meta.RESTMapper
+	namespace  string
+	populators map[schema.GroupKind]string
+	log        *logrus.Entry
+}
+
+// NewController creates new instance of Controller structure
+// Receives map kind of data source -> image of populator pod and namespace where prime PVCs and pods are created
+// Returns an instance of Controller
+func NewController(client client.Client, reader client.Reader, mapper meta.RESTMapper, namespace string,
+	populators map[schema.GroupKind]string, log *logrus.Logger) *Controller {
+	return &Controller{
+		client:     client,
+		reader:     reader,
+		mapper:     mapper,
+		namespace:  namespace,
+		populators: populators,
+		log:        log.WithField("component", "PopulatorController"),
+	}
+}
+
+// SetupWithManager registers Controller to ControllerManager
+func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("populator").
+		For(&corev1.PersistentVolumeClaim{}).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+			return ok && c.getImage(pvc) != ""
+		})).
+		Complete(diagnostics.TrackReconciler("populator", c))
+}
+
+// Reconcile populates PVC with custom data source
+func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	defer metricsC.ReconcileDuration.EvaluateDurationForType("populator_controller")()
+	ctx, cancelFn := context.WithTimeout(ctx, contextTimeout)
+	defer cancelFn()
+
+	log := c.log.WithFields(logrus.Fields{"method": "Reconcile", "name": req.NamespacedName.String()})
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := c.client.Get(ctx, req.NamespacedName, pvc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	image := c.getImage(pvc)
+	if image == "" {
+		return ctrl.Result{}, nil
+	}
+	// PVC is deleted or already bound to populated PV
+	if pvc.DeletionTimestamp != nil || pvc.Spec.VolumeName != "" {
+		return ctrl.Result{}, c.cleanup(ctx, log, pvc)
+	}
+
+	sc, err := c.getStorageClass(ctx, pvc)
+	if err != nil || sc == nil {
+		return ctrl.Result{}, err
+	}
+	nodeName := pvc.Annotations[selectedNodeAnnotation]
+	if sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer &&
+		nodeName == "" {
+		log.Debug("Waiting for consumer of PVC to be scheduled")
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(pvc, finalizer) {
+		controllerutil.AddFinalizer(pvc, finalizer)
+		if err := c.client.Update(ctx, pvc); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	prime, err := c.ensurePrimePVC(ctx, log, pvc, nodeName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	pod, err := c.ensurePod(ctx, log, pvc, prime, image, nodeName)
+	if err != nil || pod == nil {
+		return ctrl.Result{RequeueAfter: PollInterval}, err
+	}
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return ctrl.Result{RequeueAfter: PollInterval}, c.rebind(ctx, log, pvc, prime)
+	case corev1.PodFailed:
+		// pod is recreated on the next attempt
+		log.Errorf("Populator pod %s failed: %s", pod.Name, pod.Status.Message)
+		return ctrl.Result{RequeueAfter: PollInterval}, c.deleteIgnoreNotFound(ctx, pod)
+	default:
+		return ctrl.Result{RequeueAfter: PollInterval}, nil
+	}
+}
+
+// getImage returns image of populator of PVC data source, empty string if data source isn't handled by the controller
+func (c *Controller) getImage(pvc *corev1.PersistentVolumeClaim) string {
+	ref := pvc.Spec.DataSourceRef
+	if ref == nil || ref.APIGroup == nil {
+		return ""
+	}
+	return c.populators[schema.GroupKind{Group: *ref.APIGroup, Kind: ref.Kind}]
+}
+
+// getStorageClass returns storage class of PVC, nil if volumes of the class aren't provisioned by the driver
+func (c *Controller) getStorageClass(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (*storagev1.StorageClass, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return nil, nil
+	}
+	sc := &storagev1.StorageClass{}
+	if err := c.reader.Get(ctx, client.ObjectKey{Name: *pvc.Spec.StorageClassName}, sc); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if sc.Provisioner != base.PluginName {
+		return nil, nil
+	}
+	return sc, nil
+}
+
+// ensurePrimePVC creates PVC in CSI namespace with the same parameters as PVC being populated
+func (c *Controller) ensurePrimePVC(ctx context.Context, log *logrus.Entry, pvc *corev1.PersistentVolumeClaim,
+	nodeName string) (*corev1.PersistentVolumeClaim, error) {
+	prime := &corev1.PersistentVolumeClaim{}
+	err := c.client.Get(ctx, c.primeKey(pvc), prime)
+	if !k8serrors.IsNotFound(err) {
+		return prime, err
+	}
+	prime = &corev1.PersistentVolumeClaim{
+		ObjectMeta: c.primeMeta(pvc),
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      pvc.Spec.AccessModes,
+			Resources:        pvc.Spec.Resources,
+			StorageClassName: pvc.Spec.StorageClassName,
+			VolumeMode:       pvc.Spec.VolumeMode,
+		},
+	}
+	if nodeName != "" {
+		prime.Annotations[selectedNodeAnnotation] = nodeName
+	}
+	log.Infof("Creating prime PVC %s/%s", prime.Namespace, prime.Name)
+	return prime, c.client.Create(ctx, prime)
+}
+
+// ensurePod creates populator pod which writes data source to prime PVC
+// Returns nil pod if it was created
+func (c *Controller) ensurePod(ctx context.Context, log *logrus.Entry, pvc, prime *corev1.PersistentVolumeClaim,
+	image, nodeName string) (*corev1.Pod, error) {
+	pod := &corev1.Pod{}
+	err := c.reader.Get(ctx, c.primeKey(pvc), pod)
+	if !k8serrors.IsNotFound(err) {
+		return pod, err
+	}
+	// PV is already rebound, volume mustn't be written again
+	if prime.Status.Phase == corev1.ClaimLost {
+		return nil, nil
+	}
+	source, err := c.getDataSourceSpec(ctx, pvc)
+	if err != nil {
+		return nil, err
+	}
+	pod = &corev1.Pod{
+		ObjectMeta: c.primeMeta(pvc),
+		Spec: corev1.PodSpec{
+			// prime PVC is provisioned on the node selected for PVC being populated
+			NodeName:      nodeName,
+			RestartPolicy: corev1.RestartPolicyOnFailure,
+			Containers: []corev1.Container{{
+				Name:            populatorContainer,
+				Image:           image,
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Env:             []corev1.EnvVar{{Name: DataSourceEnv, Value: source}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: populatorContainer,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: prime.Name},
+				},
+			}},
+		},
+	}
+	container := &pod.Spec.Containers[0]
+	if pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		container.VolumeDevices = []corev1.VolumeDevice{{Name: populatorContainer, DevicePath: VolumeDevicePath}}
+		container.Env = append(container.Env, corev1.EnvVar{Name: VolumePathEnv, Value: VolumeDevicePath})
+	} else {
+		container.VolumeMounts = []corev1.VolumeMount{{Name: populatorContainer, MountPath: VolumeMountPath}}
+		container.Env = append(container.Env, corev1.EnvVar{Name: VolumePathEnv, Value: VolumeMountPath})
+	}
+	log.Infof("Creating populator pod %s/%s with image %s", pod.Namespace, pod.Name, image)
+	return nil, c.client.Create(ctx, pod)
+}
+
+// getDataSourceSpec reads custom resource which PVC dataSourceRef points to and returns JSON of its spec
+func (c *Controller) getDataSourceSpec(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (string, error) {
+	ref := pvc.Spec.DataSourceRef
+	mapping, err := c.mapper.RESTMapping(schema.GroupKind{Group: *ref.APIGroup, Kind: ref.Kind})
+	if err != nil {
+		return "", fmt.Errorf("unable to find version of data source kind %s: %v", ref.Kind, err)
+	}
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err := c.reader.Get(ctx, client.ObjectKey{Namespace: pvc.Namespace, Name: ref.Name}, source); err != nil {
+		return "", fmt.Errorf("unable to read data source %s %s: %v", ref.Kind, ref.Name, err)
+	}
+	spec, _, err := unstructured.NestedMap(source.Object, "spec")
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(spec)
+	return string(data), err
+}
+
+// rebind points PV of prime PVC to PVC being populated, kubernetes binds PVC to the PV then
+func (c *Controller) rebind(ctx context.Context, log *logrus.Entry, pvc, prime *corev1.PersistentVolumeClaim) error {
+	if prime.Spec.VolumeName == "" {
+		return nil
+	}
+	pv := &corev1.PersistentVolume{}
+	if err := c.reader.Get(ctx, client.ObjectKey{Name: prime.Spec.VolumeName}, pv); err != nil {
+		return err
+	}
+	if pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.UID == pvc.UID {
+		return nil
+	}
+	ref := pvc.Spec.DataSourceRef
+	if pv.Annotations == nil {
+		pv.Annotations = map[string]string{}
+	}
+	pv.Annotations[PopulatedFromAnnotation] = fmt.Sprintf("%s/%s/%s", *ref.APIGroup, ref.Kind, ref.Name)
+	pv.Spec.ClaimRef = &corev1.ObjectReference{
+		Kind:            "PersistentVolumeClaim",
+		APIVersion:      "v1",
+		Namespace:       pvc.Namespace,
+		Name:            pvc.Name,
+		UID:             pvc.UID,
+		ResourceVersion: pvc.ResourceVersion,
+	}
+	log.Infof("Rebinding PV %s to PVC %s/%s", pv.Name, pvc.Namespace, pvc.Name)
+	return c.client.Update(ctx, pv)
+}
+
+// cleanup removes prime PVC and populator pod and finalizer of PVC
+func (c *Controller) cleanup(ctx context.Context, log *logrus.Entry, pvc *corev1.PersistentVolumeClaim) error {
+	if !controllerutil.ContainsFinalizer(pvc, finalizer) {
+		return nil
+	}
+	primeMeta := c.primeMeta(pvc)
+	log.Infof("Removing prime PVC and populator pod %s/%s", primeMeta.Namespace, primeMeta.Name)
+	if err := c.deleteIgnoreNotFound(ctx, &corev1.Pod{ObjectMeta: primeMeta}); err != nil {
+		return err
+	}
+	// PV isn't deleted with prime PVC after rebinding
+	if err := c.deleteIgnoreNotFound(ctx, &corev1.PersistentVolumeClaim{ObjectMeta: primeMeta}); err != nil {
+		return err
+	}
+	controllerutil.RemoveFinalizer(pvc, finalizer)
+	return c.client.Update(ctx, pvc)
+}
+
+func (c *Controller) deleteIgnoreNotFound(ctx context.Context, obj client.Object) error {
+	return client.IgnoreNotFound(c.client.Delete(ctx, obj))
+}
+
+func (c *Controller) primeKey(pvc *corev1.PersistentVolumeClaim) client.ObjectKey {
+	return client.ObjectKey{Namespace: c.namespace, Name: primePrefix + string(pvc.UID)}
+}
+
+func (c *Controller) primeMeta(pvc *corev1.PersistentVolumeClaim) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace:   c.namespace,
+		Name:        primePrefix + string(pvc.UID),
+		Annotations: map[string]string{targetAnnotation: pvc.Namespace + "/" + pvc.Name},
+	}
+}
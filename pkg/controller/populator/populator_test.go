@@ -0,0 +1,213 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package populator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dell/csi-baremetal/pkg/base"
+)
+
+const (
+	testNS      = "csi"
+	testPVCNS   = "default"
+	testImage   = "dataset-populator:1.0"
+	testNode    = "node1"
+	testPVName  = "pvc-1"
+	testSCName  = "csi-baremetal-sc"
+	testPVCName = "data"
+)
+
+var (
+	testCtx    = context.Background()
+	testLogger = logrus.New()
+	// any namespaced kind with spec can be used as data source in test
+	testSourceKind = schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	testReplicas   = int32(3)
+
+	testSource = &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testPVCNS, Name: "dataset"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &testReplicas},
+	}
+)
+
+func newTestController(t *testing.T, objects ...client.Object) (*Controller, client.Client) {
+	wffc := storagev1.VolumeBindingWaitForFirstConsumer
+	sc := &storagev1.StorageClass{
+		ObjectMeta:        metav1.ObjectMeta{Name: testSCName},
+		Provisioner:       base.PluginName,
+		VolumeBindingMode: &wffc,
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithObjects(append(objects, sc, testSource)...).Build()
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{appsv1.SchemeGroupVersion})
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	c := NewController(k8sClient, k8sClient, mapper, testNS, map[schema.GroupKind]string{testSourceKind: testImage}, testLogger)
+	assert.NotNil(t, c)
+	return c, k8sClient
+}
+
+func newTestPVC(selectedNode string) *corev1.PersistentVolumeClaim {
+	group := testSourceKind.Group
+	sc := testSCName
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testPVCNS, Name: testPVCName, UID: "uid-1"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &sc,
+			DataSourceRef: &corev1.TypedLocalObjectReference{
+				APIGroup: &group,
+				Kind:     testSourceKind.Kind,
+				Name:     testSource.Name,
+			},
+		},
+	}
+	if selectedNode != "" {
+		pvc.Annotations = map[string]string{selectedNodeAnnotation: selectedNode}
+	}
+	return pvc
+}
+
+func reconcile(t *testing.T, c *Controller) {
+	_, err := c.Reconcile(testCtx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: testPVCNS, Name: testPVCName}})
+	assert.Nil(t, err)
+}
+
+func TestController_Reconcile(t *testing.T) {
+	pvc := newTestPVC(testNode)
+	c, k8sClient := newTestController(t, pvc)
+	primeKey := client.ObjectKey{Namespace: testNS, Name: primePrefix + string(pvc.UID)}
+
+	// prime PVC and populator pod are created
+	reconcile(t, c)
+	assert.Nil(t, k8sClient.Get(testCtx, client.ObjectKeyFromObject(pvc), pvc))
+	assert.Contains(t, pvc.Finalizers, finalizer)
+
+	prime := &corev1.PersistentVolumeClaim{}
+	assert.Nil(t, k8sClient.Get(testCtx, primeKey, prime))
+	assert.Equal(t, testNode, prime.Annotations[selectedNodeAnnotation])
+	assert.Equal(t, testPVCNS+"/"+testPVCName, prime.Annotations[targetAnnotation])
+	assert.Equal(t, pvc.Spec.StorageClassName, prime.Spec.StorageClassName)
+	assert.Nil(t, prime.Spec.DataSourceRef)
+
+	pod := &corev1.Pod{}
+	assert.Nil(t, k8sClient.Get(testCtx, primeKey, pod))
+	assert.Equal(t, testNode, pod.Spec.NodeName)
+	assert.Equal(t, prime.Name, pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	container := pod.Spec.Containers[0]
+	assert.Equal(t, testImage, container.Image)
+	assert.Equal(t, VolumeMountPath, container.VolumeMounts[0].MountPath)
+	assert.Equal(t, DataSourceEnv, container.Env[0].Name)
+	source := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(container.Env[0].Value), &source))
+	assert.Equal(t, float64(testReplicas), source["replicas"])
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: VolumePathEnv, Value: VolumeMountPath})
+
+	// PV isn't rebound until pod succeeds
+	prime.Spec.VolumeName = testPVName
+	assert.Nil(t, k8sClient.Update(testCtx, prime))
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: testPVName},
+		Spec: corev1.PersistentVolumeSpec{
+			ClaimRef: &corev1.ObjectReference{Namespace: testNS, Name: prime.Name},
+		},
+	}
+	assert.Nil(t, k8sClient.Create(testCtx, pv))
+	reconcile(t, c)
+	assert.Nil(t, k8sClient.Get(testCtx, client.ObjectKeyFromObject(pv), pv))
+	assert.Equal(t, prime.Name, pv.Spec.ClaimRef.Name)
+
+	// PV is rebound to PVC
+	pod.Status.Phase = corev1.PodSucceeded
+	assert.Nil(t, k8sClient.Update(testCtx, pod))
+	reconcile(t, c)
+	assert.Nil(t, k8sClient.Get(testCtx, client.ObjectKeyFromObject(pv), pv))
+	assert.Equal(t, testPVCNS, pv.Spec.ClaimRef.Namespace)
+	assert.Equal(t, testPVCName, pv.Spec.ClaimRef.Name)
+	assert.Equal(t, pvc.UID, pv.Spec.ClaimRef.UID)
+	assert.Equal(t, "apps/Deployment/dataset", pv.Annotations[PopulatedFromAnnotation])
+
+	// prime PVC and pod are removed after PVC is bound
+	assert.Nil(t, k8sClient.Get(testCtx, client.ObjectKeyFromObject(pvc), pvc))
+	pvc.Spec.VolumeName = testPVName
+	assert.Nil(t, k8sClient.Update(testCtx, pvc))
+	reconcile(t, c)
+	assert.True(t, k8serrors.IsNotFound(k8sClient.Get(testCtx, primeKey, &corev1.Pod{})))
+	assert.True(t, k8serrors.IsNotFound(k8sClient.Get(testCtx, primeKey, &corev1.PersistentVolumeClaim{})))
+	// empty finalizers are omitted, so PVC is read into the new object
+	bound := &corev1.PersistentVolumeClaim{}
+	assert.Nil(t, k8sClient.Get(testCtx, client.ObjectKeyFromObject(pvc), bound))
+	assert.NotContains(t, bound.Finalizers, finalizer)
+}
+
+func TestController_Reconcile_Block(t *testing.T) {
+	pvc := newTestPVC(testNode)
+	block := corev1.PersistentVolumeBlock
+	pvc.Spec.VolumeMode = &block
+	c, k8sClient := newTestController(t, pvc)
+
+	reconcile(t, c)
+	pod := &corev1.Pod{}
+	assert.Nil(t, k8sClient.Get(testCtx, client.ObjectKey{Namespace: testNS, Name: primePrefix + string(pvc.UID)}, pod))
+	assert.Equal(t, VolumeDevicePath, pod.Spec.Containers[0].VolumeDevices[0].DevicePath)
+	assert.Empty(t, pod.Spec.Containers[0].VolumeMounts)
+	assert.Contains(t, pod.Spec.Containers[0].Env, corev1.EnvVar{Name: VolumePathEnv, Value: VolumeDevicePath})
+}
+
+func TestController_Reconcile_Skip(t *testing.T) {
+	// consumer isn't scheduled yet
+	pvc := newTestPVC("")
+	c, k8sClient := newTestController(t, pvc)
+	reconcile(t, c)
+	assert.Nil(t, k8sClient.Get(testCtx, client.ObjectKeyFromObject(pvc), pvc))
+	assert.Empty(t, pvc.Finalizers)
+
+	// data source isn't handled by populator
+	pvc = newTestPVC(testNode)
+	pvc.Spec.DataSourceRef.Kind = "StatefulSet"
+	c, k8sClient = newTestController(t, pvc)
+	reconcile(t, c)
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	assert.Nil(t, k8sClient.List(testCtx, pvcList, client.InNamespace(testNS)))
+	assert.Empty(t, pvcList.Items)
+
+	// storage class of other provisioner
+	pvc = newTestPVC(testNode)
+	sc := "other"
+	pvc.Spec.StorageClassName = &sc
+	c, k8sClient = newTestController(t, pvc, &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: sc}, Provisioner: "other"})
+	reconcile(t, c)
+	assert.Nil(t, k8sClient.List(testCtx, pvcList, client.InNamespace(testNS)))
+	assert.Empty(t, pvcList.Items)
+}
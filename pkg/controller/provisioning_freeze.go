@@ -0,0 +1,69 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultProvisioningFreezeConfig is a path where ConfigMap with provisioning freeze toggle is mounted by operator
+const DefaultProvisioningFreezeConfig = "/etc/controller_config/provisioning-freeze.yaml"
+
+// ProvisioningFreeze is a cluster-wide toggle which makes controller reject creation and deletion of volumes,
+// published volumes keep working
+type ProvisioningFreeze struct {
+	Enabled bool   `yaml:"enabled"`
+	Reason  string `yaml:"reason"`
+}
+
+// SetProvisioningFreezeConfig makes CreateVolume and DeleteVolume check provisioning freeze toggle in file
+// which is usually mounted from ConfigMap, so freeze is switched without restart of controller
+func (c *CSIControllerService) SetProvisioningFreezeConfig(path string) {
+	c.freezeConfig = path
+}
+
+// checkProvisioningFreeze returns Unavailable error if provisioning is frozen, so sidecar retries request later
+// Missing file means that provisioning isn't frozen, file which can't be parsed freezes provisioning
+func (c *CSIControllerService) checkProvisioningFreeze(method string) error {
+	if c.freezeConfig == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(filepath.Clean(c.freezeConfig))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	freeze := &ProvisioningFreeze{}
+	if err == nil {
+		err = yaml.Unmarshal(data, freeze)
+	}
+	if err != nil {
+		c.log.WithField("method", method).Errorf("Unable to read provisioning freeze config, consider "+
+			"provisioning as frozen: %v", err)
+		return status.Errorf(codes.Unavailable, "provisioning freeze config can't be read: %v", err)
+	}
+	if !freeze.Enabled {
+		return nil
+	}
+	c.log.WithField("method", method).Warnf("Provisioning is frozen: %s", freeze.Reason)
+	return status.Errorf(codes.Unavailable, "provisioning is frozen: %s", freeze.Reason)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCSIControllerService_checkProvisioningFreeze(t *testing.T) {
+	var (
+		svc  = newSvc()
+		path = filepath.Join(t.TempDir(), "provisioning-freeze.yaml")
+	)
+	// toggle isn't configured
+	assert.Nil(t, svc.checkProvisioningFreeze("CreateVolume"))
+
+	svc.SetProvisioningFreezeConfig(path)
+	// ConfigMap isn't created
+	assert.Nil(t, svc.checkProvisioningFreeze("CreateVolume"))
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("enabled: false\n"), 0600))
+	assert.Nil(t, svc.checkProvisioningFreeze("CreateVolume"))
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("enabled: true\nreason: incident 42\n"), 0600))
+	err := svc.checkProvisioningFreeze("CreateVolume")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "incident 42")
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("enabled: [\n"), 0600))
+	assert.Equal(t, codes.Unavailable, status.Code(svc.checkProvisioningFreeze("CreateVolume")))
+}
+
+func TestCSIControllerService_ProvisioningFreeze(t *testing.T) {
+	var (
+		svc  = newSvc()
+		path = filepath.Join(t.TempDir(), "provisioning-freeze.yaml")
+	)
+	svc.SetProvisioningFreezeConfig(path)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("enabled: true\nreason: maintenance\n"), 0600))
+
+	_, err := svc.CreateVolume(testCtx, getCreateVolumeRequest("req1", 1024*1024*1024, "", "", false, false))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = svc.DeleteVolume(testCtx, &csi.DeleteVolumeRequest{VolumeId: "req1"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
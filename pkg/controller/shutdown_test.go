@@ -0,0 +1,50 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestCSIControllerService_Drain(t *testing.T) {
+	svc := newSvc()
+	done, ok := svc.operations.Begin("CreateVolume pvc-1")
+	assert.True(t, ok)
+
+	// in-flight call isn't finished within timeout
+	assert.Equal(t, []string{"CreateVolume pvc-1"}, svc.Drain(10*time.Millisecond))
+
+	done()
+	assert.Nil(t, svc.Drain(10*time.Millisecond))
+
+	// new calls are rejected
+	_, err := svc.CreateVolume(testCtx, getCreateVolumeRequest("pvc-2", 1024, "", "claim", false, false))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	_, err = svc.DeleteVolume(testCtx, &csi.DeleteVolumeRequest{VolumeId: "pvc-1"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	resp, err := svc.Check(testCtx, &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+)
+
+// TemplateKey is a parameter key of template LV which volume is created from as thin snapshot
+const TemplateKey = "template"
+
+// parseTemplateParameter returns name of template LV of volume with storageClass and encryption
+// Returns empty name if template isn't requested, error if parameter is wrong or volume can't be created from template
+func parseTemplateParameter(params map[string]string, storageClass, encryption string) (string, error) {
+	template := params[TemplateKey]
+	if template == "" {
+		return "", nil
+	}
+	if err := command.ValidateName(template); err != nil {
+		return "", fmt.Errorf("%s parameter has wrong value: %v", TemplateKey, err)
+	}
+	if !util.IsStorageClassLVG(storageClass) {
+		return "", fmt.Errorf("template is supported for LVG storage classes only")
+	}
+	// LUKS would be formatted over content of template
+	if encryption != "" {
+		return "", fmt.Errorf("template isn't supported for encrypted volumes")
+	}
+	return template, nil
+}
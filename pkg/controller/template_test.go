@@ -0,0 +1,45 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+func Test_parseTemplateParameter(t *testing.T) {
+	template, err := parseTemplateParameter(map[string]string{}, apiV1.StorageClassHDD, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "", template)
+
+	template, err = parseTemplateParameter(map[string]string{TemplateKey: "ubuntu-20.04"}, apiV1.StorageClassSSDLVG, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "ubuntu-20.04", template)
+
+	_, err = parseTemplateParameter(map[string]string{TemplateKey: "ubuntu-20.04"}, apiV1.StorageClassSSD, "")
+	assert.NotNil(t, err)
+
+	_, err = parseTemplateParameter(map[string]string{TemplateKey: "ubuntu-20.04"}, apiV1.StorageClassSSDLVG,
+		apiV1.EncryptionLUKS)
+	assert.NotNil(t, err)
+
+	_, err = parseTemplateParameter(map[string]string{TemplateKey: "vg/ubuntu"}, apiV1.StorageClassSSDLVG, "")
+	assert.NotNil(t, err)
+}
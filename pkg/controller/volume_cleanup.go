@@ -0,0 +1,111 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// DefaultStuckVolumesCheckInterval is an interval between checks of terminating volumes
+const DefaultStuckVolumesCheckInterval = 5 * time.Minute
+
+// eventRecorder interface for sending events
+type eventRecorder interface {
+	Eventf(object runtime.Object, event *eventing.EventDescription, messageFmt string, args ...interface{})
+}
+
+// RunStuckVolumesCleanup periodically takes over cleanup of terminating volumes which owner node is lost,
+// node is treated as lost when its node service is in PermanentDown state longer than takeoverTimeout.
+// Missing Node CR isn't treated as lost node. Event is sent for every volume which cleanup is taken over.
+// Blocks until context is done
+func (c *CSIControllerService) RunStuckVolumesCleanup(ctx context.Context, recorder eventRecorder,
+	interval, takeoverTimeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := c.cleanupStuckVolumes(ctx, recorder,
+				c.nodeServicesStateMonitor.GetPermanentDownPods(takeoverTimeout),
+				c.nodeServicesStateMonitor.GetReadyPods())
+			if err != nil {
+				c.log.Errorf("Stuck volumes cleanup failed: %v", err)
+			}
+		}
+	}
+}
+
+// cleanupStuckVolumes removes finalizer of terminating volumes which can't be handled by owner node:
+// volumes on lost nodes and volumes with force-cleanup annotation on nodes with unready node service.
+// Receives list of lost node IDs and list of node IDs with ready node service
+func (c *CSIControllerService) cleanupStuckVolumes(ctx context.Context, recorder eventRecorder,
+	lostNodes, readyNodes []string) error {
+	ll := c.log.WithFields(logrus.Fields{
+		"method": "cleanupStuckVolumes",
+	})
+
+	volumes := &volumecrd.VolumeList{}
+	if err := c.k8sclient.ReadList(ctx, volumes); err != nil {
+		return err
+	}
+
+	for i := range volumes.Items {
+		volume := &volumes.Items[i]
+		if volume.DeletionTimestamp.IsZero() || !util.ContainsString(volume.Finalizers, apiV1.VolumeFinalizer) {
+			continue
+		}
+		nodeID := volume.Spec.NodeId
+		if !c.shard.Owns(nodeID) {
+			continue
+		}
+		forced := volume.Annotations[apiV1.VolumeForceCleanupAnnotation] == apiV1.VolumeForceCleanupValue
+		var reason string
+		switch {
+		case util.ContainsString(lostNodes, nodeID):
+			reason = "node service is permanently down"
+		case forced && !util.ContainsString(readyNodes, nodeID):
+			reason = "node service is unready and force cleanup is requested by annotation " +
+				apiV1.VolumeForceCleanupAnnotation
+		default:
+			// owner node is responsible for cleanup
+			continue
+		}
+		ll.Warnf("Take over cleanup of volume %s on node %s, %s. Storage isn't released", volume.Name, nodeID, reason)
+
+		// status isn't changed, volume is removed together with its last finalizer
+		volume.Finalizers = util.RemoveString(volume.Finalizers, apiV1.VolumeFinalizer)
+		if err := c.k8sclient.UpdateCR(ctx, volume); err != nil {
+			ll.Errorf("Unable to remove finalizer of volume %s: %v", volume.Name, err)
+			continue
+		}
+		recorder.Eventf(volume, eventing.VolumeCleanupTakenOver,
+			"Finalizer was removed by controller, %s, CSIStatus='%s', NodeID='%s'. Storage isn't released",
+			reason, volume.Spec.CSIStatus, nodeID)
+	}
+	return nil
+}
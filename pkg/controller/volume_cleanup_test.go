@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	k8sError "k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/nodecrd"
+	vcrd "github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestCSIControllerService_cleanupStuckVolumes(t *testing.T) {
+	var (
+		svc         = newSvc()
+		recorder    = new(mocks.NoOpRecorder)
+		lostNode    = "lost-node"
+		readyNode   = "ready-node"
+		downNode    = "down-node"
+		removedNode = "removed-node"
+	)
+	for _, id := range []string{lostNode, readyNode, downNode} {
+		node := &nodecrd.Node{
+			TypeMeta:   k8smetav1.TypeMeta{Kind: apiV1.CSIBMNodeKind, APIVersion: apiV1.APIV1Version},
+			ObjectMeta: k8smetav1.ObjectMeta{Name: id},
+			Spec:       api.Node{UUID: id},
+		}
+		assert.Nil(t, svc.k8sclient.CreateCR(testCtx, id, node))
+	}
+
+	createVolume := func(name, nodeID string, forced bool) {
+		volume := testVolume.DeepCopy()
+		volume.Name = name
+		volume.Spec.Id = name
+		volume.Spec.NodeId = nodeID
+		volume.Spec.CSIStatus = apiV1.Failed
+		volume.Finalizers = []string{apiV1.VolumeFinalizer}
+		volume.DeletionTimestamp = &k8smetav1.Time{Time: time.Now()}
+		if forced {
+			volume.Annotations = map[string]string{apiV1.VolumeForceCleanupAnnotation: apiV1.VolumeForceCleanupValue}
+		}
+		assert.Nil(t, svc.k8sclient.CreateCR(testCtx, name, volume))
+	}
+	createVolume("on-lost-node", lostNode, false)
+	createVolume("on-removed-node", removedNode, false)
+	createVolume("forced-on-down-node", downNode, true)
+	createVolume("on-down-node", downNode, false)
+	createVolume("forced-on-ready-node", readyNode, true)
+
+	assert.Nil(t, svc.cleanupStuckVolumes(testCtx, recorder, []string{lostNode}, []string{readyNode}))
+
+	// volume is removed by fake client together with its last finalizer,
+	// missing Node CR doesn't mean that node is lost
+	expected := map[string]bool{
+		"on-lost-node":         true,
+		"on-removed-node":      false,
+		"forced-on-down-node":  true,
+		"on-down-node":         false,
+		"forced-on-ready-node": false,
+	}
+	for name, cleaned := range expected {
+		volume := &vcrd.Volume{}
+		err := svc.k8sclient.ReadCR(testCtx, name, testNs, volume)
+		if cleaned {
+			assert.True(t, k8sError.IsNotFound(err), name)
+			continue
+		}
+		assert.Nil(t, err, name)
+		assert.Equal(t, []string{apiV1.VolumeFinalizer}, volume.Finalizers, name)
+	}
+
+	// event is sent for every taken over volume
+	events := map[string]*eventing.EventDescription{}
+	for _, call := range recorder.Calls {
+		events[call.Object.(*vcrd.Volume).Name] = call.Event
+	}
+	assert.Equal(t, map[string]*eventing.EventDescription{
+		"on-lost-node":        eventing.VolumeCleanupTakenOver,
+		"forced-on-down-node": eventing.VolumeCleanupTakenOver,
+	}, events)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drive
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// disruptionBudgets tracks disruptions which PodDisruptionBudgets allow during one stage of evacuation.
+// Budgets are read once per namespace, each eviction decreases allowed disruptions of budgets which select the pod,
+// so evictions of one stage don't violate budgets before their status is updated by disruption controller
+type disruptionBudgets struct {
+	clientset kubernetes.Interface
+	// budgets holds PodDisruptionBudgets per namespace
+	budgets map[string][]policyv1beta1.PodDisruptionBudget
+	// allowed holds disruptions which are left in the stage per namespace/name of budget
+	allowed map[string]int32
+}
+
+// newDisruptionBudgets is a constructor for disruptionBudgets
+func newDisruptionBudgets(clientset kubernetes.Interface) *disruptionBudgets {
+	return &disruptionBudgets{
+		clientset: clientset,
+		budgets:   map[string][]policyv1beta1.PodDisruptionBudget{},
+		allowed:   map[string]int32{},
+	}
+}
+
+// blocking returns namespace/name of budgets which select the pod and don't allow disruption anymore
+func (d *disruptionBudgets) blocking(ctx context.Context, pod *corev1.Pod) ([]string, error) {
+	budgets, err := d.matching(ctx, pod)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0)
+	for _, name := range budgets {
+		if d.allowed[name] <= 0 {
+			res = append(res, name)
+		}
+	}
+	return res, nil
+}
+
+// disrupt decreases allowed disruptions of budgets which select evicted pod
+func (d *disruptionBudgets) disrupt(ctx context.Context, pod *corev1.Pod) error {
+	budgets, err := d.matching(ctx, pod)
+	if err != nil {
+		return err
+	}
+	for _, name := range budgets {
+		d.allowed[name]--
+	}
+	return nil
+}
+
+// matching returns namespace/name of budgets which select the pod
+func (d *disruptionBudgets) matching(ctx context.Context, pod *corev1.Pod) ([]string, error) {
+	budgets, ok := d.budgets[pod.Namespace]
+	if !ok {
+		list, err := d.clientset.PolicyV1beta1().PodDisruptionBudgets(pod.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		budgets = list.Items
+		d.budgets[pod.Namespace] = budgets
+		for _, pdb := range budgets {
+			d.allowed[pdb.Namespace+"/"+pdb.Name] = pdb.Status.DisruptionsAllowed
+		}
+	}
+	res := make([]string, 0)
+	for _, pdb := range budgets {
+		// budget with nil or empty selector doesn't select any pod
+		if pdb.Spec.Selector == nil || len(pdb.Spec.Selector.MatchLabels)+len(pdb.Spec.Selector.MatchExpressions) == 0 {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			res = append(res, pdb.Namespace+"/"+pdb.Name)
+		}
+	}
+	return res, nil
+}
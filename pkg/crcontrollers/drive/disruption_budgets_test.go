@@ -0,0 +1,61 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newPDB(name string, selector *metav1.LabelSelector, allowed int32) *policyv1beta1.PodDisruptionBudget {
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNs},
+		Spec:       policyv1beta1.PodDisruptionBudgetSpec{Selector: selector},
+		Status:     policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+	}
+}
+
+func TestDisruptionBudgets(t *testing.T) {
+	var (
+		app   = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+		empty = &metav1.LabelSelector{}
+		pod   = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: testNs,
+			Labels: map[string]string{"app": "db"}}}
+		other = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: testNs,
+			Labels: map[string]string{"app": "web"}}}
+		budgets = newDisruptionBudgets(fake.NewSimpleClientset(newPDB("db", app, 1), newPDB("all", empty, 0)))
+	)
+
+	blocking, err := budgets.blocking(testCtx, pod)
+	assert.Nil(t, err)
+	assert.Empty(t, blocking)
+	// budget allows one disruption in the stage
+	assert.Nil(t, budgets.disrupt(testCtx, pod))
+	blocking, err = budgets.blocking(testCtx, pod)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{testNs + "/db"}, blocking)
+
+	// budget with empty selector doesn't select pods
+	blocking, err = budgets.blocking(testCtx, other)
+	assert.Nil(t, err)
+	assert.Empty(t, blocking)
+}
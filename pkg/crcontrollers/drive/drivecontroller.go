@@ -17,8 +17,11 @@ import (
 	"github.com/dell/csi-baremetal/api/v1/drivecrd"
 	"github.com/dell/csi-baremetal/api/v1/volumecrd"
 	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/ctrlopts"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	errTypes "github.com/dell/csi-baremetal/pkg/base/error"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/drivemgr"
 	"github.com/dell/csi-baremetal/pkg/eventing"
 	"github.com/dell/csi-baremetal/pkg/events"
 	metricsC "github.com/dell/csi-baremetal/pkg/metrics/common"
@@ -30,8 +33,12 @@ type Controller struct {
 	crHelper       *k8s.CRHelper
 	nodeID         string
 	driveMgrClient api.DriveServiceClient
-	eventRecorder  *events.Recorder
-	log            *logrus.Entry
+	// description of drive manager which is received during API negotiation
+	driveMgrInfo  *api.DriveManagerInfo
+	eventRecorder *events.Recorder
+	// performs evacuation of BAD drives, nil if feature is disabled
+	evacuator *Evacuator
+	log       *logrus.Entry
 }
 
 const (
@@ -59,15 +66,34 @@ func NewController(client *k8s.KubeClient, nodeID string, serviceClient api.Driv
 		crHelper:       k8s.NewCRHelper(client, log),
 		nodeID:         nodeID,
 		driveMgrClient: serviceClient,
+		driveMgrInfo:   drivemgr.LegacyInfo(),
 		eventRecorder:  eventRecorder,
 		log:            log.WithField("component", "Controller"),
 	}
 }
 
+// SetCache makes controller read Volume, AC and LogicalVolumeGroup CRs from informer cache instead of API server,
+// stale reads are possible, so updates of read objects may fail with conflict and be retried on next reconcile
+func (c *Controller) SetCache(k8sCache k8s.CRReader) {
+	c.crHelper.SetReader(k8sCache)
+}
+
+// SetDriveMgrInfo sets description of drive manager which is received during API negotiation,
+// methods of capabilities which drive manager doesn't report aren't called
+func (c *Controller) SetDriveMgrInfo(info *api.DriveManagerInfo) {
+	c.driveMgrInfo = info
+}
+
+// SetEvacuator enables automated evacuation of volumes from drives which health became BAD
+func (c *Controller) SetEvacuator(evacuator *Evacuator) {
+	c.evacuator = evacuator
+}
+
 // SetupWithManager registers Controller to ControllerManager
 func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&drivecrd.Drive{}).
+		WithOptions(ctrlopts.FromEnv(ctrlopts.DriveControllerPrefix, ctrlopts.Options{}, c.log).ControllerOptions()).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool {
 				return c.filterCRs(e.Object)
@@ -82,7 +108,7 @@ func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
 				return c.filterCRs(e.Object)
 			},
 		}).
-		Complete(c)
+		Complete(diagnostics.TrackReconciler("drive", c))
 }
 
 func (c *Controller) filterCRs(obj runtime.Object) bool {
@@ -133,6 +159,10 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 
+	// postponed evictions are retried when disruption budgets allow them
+	if c.evacuator != nil && IsEvacuationInProgress(drive) {
+		return ctrl.Result{RequeueAfter: evictionRetryInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -151,11 +181,25 @@ func (c *Controller) handleDriveUpdate(ctx context.Context, log *logrus.Entry, d
 	toUpdate := false
 	switch usage {
 	case apiV1.DriveUsageInUse:
+		if health == apiV1.HealthBad && c.evacuator != nil {
+			if _, err := c.evacuator.Evacuate(ctx, drive); err != nil {
+				log.Errorf("Failed to evacuate drive %s: %v", drive.Name, err)
+				return ignore, err
+			}
+		}
 		if health == apiV1.HealthSuspect || health == apiV1.HealthBad {
 			drive.Spec.Usage = apiV1.DriveUsageReleasing
 			toUpdate = true
 		}
 	case apiV1.DriveUsageReleasing:
+		if c.evacuator != nil && IsEvacuationInProgress(drive) {
+			changed, err := c.evacuator.Evacuate(ctx, drive)
+			if err != nil {
+				log.Errorf("Failed to evacuate drive %s: %v", drive.Name, err)
+				return ignore, err
+			}
+			toUpdate = changed
+		}
 		volumes, err := c.crHelper.GetVolumesByLocation(ctx, id)
 		if err != nil {
 			return ignore, err
@@ -315,14 +359,17 @@ func (c *Controller) handleDriveUsageRemoving(ctx context.Context, log *logrus.E
 		return ignore, nil
 	}
 	drive.Spec.Usage = apiV1.DriveUsageRemoved
-	if drive.Spec.Status == apiV1.DriveStatusOnline {
+	switch {
+	case drive.Spec.Status == apiV1.DriveStatusOnline:
 		c.locateDriveLED(ctx, log, drive)
-	} else {
+	case !drivemgr.HasCapability(c.driveMgrInfo, apiV1.DriveMgrCapabilityLocateNode):
+		log.Infof("Drive manager doesn't support node LED, skip locate of node %s", drive.Spec.NodeId)
+	default:
 		// We can not set locate for missing disks, try to locate Node instead
 		log.Infof("Try to locate node LED %s", drive.Spec.NodeId)
 		if _, locateErr := c.driveMgrClient.LocateNode(ctx, &api.NodeLocateRequest{Action: apiV1.LocateStart}); locateErr != nil {
-			log.Errorf("Failed to start node locate: %s", err.Error())
-			return ignore, err
+			log.Errorf("Failed to start node locate: %s", locateErr.Error())
+			return ignore, locateErr
 		}
 	}
 	return update, nil
@@ -344,8 +391,13 @@ func (c *Controller) handleDriveUsageRemoved(ctx context.Context, log *logrus.En
 }
 
 func (c *Controller) locateDriveLED(ctx context.Context, log *logrus.Entry, drive *drivecrd.Drive) {
-	// try to enable LED
-	status, err := c.driveMgrClient.Locate(ctx, &api.DriveLocateRequest{Action: apiV1.LocateStart, DriveSerialNumber: drive.Spec.SerialNumber})
+	// drive manager without LED support is handled as drive without LED
+	status := &api.DriveLocateResponse{Status: apiV1.LocateStatusNotAvailable}
+	var err error
+	if drivemgr.HasCapability(c.driveMgrInfo, apiV1.DriveMgrCapabilityLocate) {
+		// try to enable LED
+		status, err = c.driveMgrClient.Locate(ctx, &api.DriveLocateRequest{Action: apiV1.LocateStart, DriveSerialNumber: drive.Spec.SerialNumber})
+	}
 	if err != nil || (status.Status != apiV1.LocateStatusOn && status.Status != apiV1.LocateStatusNotAvailable) {
 		log.Errorf("Failed to locate LED of drive %s, LED status - %+v, err %v", drive.Spec.SerialNumber, status, err)
 		drive.Spec.Usage = apiV1.DriveUsageFailed
@@ -360,6 +412,9 @@ func (c *Controller) locateDriveLED(ctx context.Context, log *logrus.Entry, driv
 }
 
 func (c *Controller) stopLocateNodeLED(ctx context.Context, log *logrus.Entry, curDrive *drivecrd.Drive) error {
+	if !drivemgr.HasCapability(c.driveMgrInfo, apiV1.DriveMgrCapabilityLocateNode) {
+		return nil
+	}
 	driveList := &drivecrd.DriveList{}
 	if err := c.client.ReadList(ctx, driveList); err != nil {
 		log.Errorf("Unable to read Drive List")
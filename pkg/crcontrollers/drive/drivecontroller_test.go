@@ -0,0 +1,55 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestController_handleDriveUsageRemoving_Capabilities(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	// mock drive manager fails LED requests
+	c := NewController(kubeClient, testDrive.NodeId, mocks.NewMockDriveMgrClient(nil), nil, testLogger)
+
+	offline := testDrive
+	offline.Status = apiV1.DriveStatusOffline
+	offline.Usage = apiV1.DriveUsageRemoving
+	drive := kubeClient.ConstructDriveCR(offline.UUID, offline)
+
+	// drive manager without GetInfo is assumed to support node LED
+	_, err = c.handleDriveUsageRemoving(testCtx, c.log, drive)
+	assert.NotNil(t, err)
+	drive.Spec.Usage = apiV1.DriveUsageRemoved
+	assert.NotNil(t, c.stopLocateNodeLED(testCtx, c.log, drive))
+
+	// node LED isn't requested from drive manager which doesn't report LOCATE_NODE capability
+	c.SetDriveMgrInfo(&api.DriveManagerInfo{Name: "vendor", ApiVersion: apiV1.DriveMgrAPIVersion})
+	drive.Spec.Usage = apiV1.DriveUsageRemoving
+	status, err := c.handleDriveUsageRemoving(testCtx, c.log, drive)
+	assert.Nil(t, err)
+	assert.Equal(t, update, status)
+	assert.Equal(t, apiV1.DriveUsageRemoved, drive.Spec.Usage)
+	assert.Nil(t, c.stopLocateNodeLED(testCtx, c.log, drive))
+}
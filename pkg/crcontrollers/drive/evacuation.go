@@ -0,0 +1,268 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drive
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// evictionRetryInterval is an interval of retries of evictions which are postponed by disruption budgets
+var evictionRetryInterval = 30 * time.Second
+
+// eventRecorder interface for sending events
+type eventRecorder interface {
+	Eventf(object runtime.Object, event *eventing.EventDescription, messageFmt string, args ...interface{})
+}
+
+// Evacuator moves workload away from the drive which health became BAD:
+// cordons the drive, marks PersistentVolumes located on it, evicts pods which opted in
+// and sends an event with recovery runbook
+type Evacuator struct {
+	client        *k8s.KubeClient
+	crHelper      *k8s.CRHelper
+	clientset     kubernetes.Interface
+	eventRecorder eventRecorder
+	log           *logrus.Entry
+}
+
+// NewEvacuator is a constructor for Evacuator
+// Receives clientset which is used for pods eviction since eviction is a subresource of the pod
+func NewEvacuator(client *k8s.KubeClient, clientset kubernetes.Interface, eventRecorder eventRecorder,
+	logger *logrus.Logger) *Evacuator {
+	return &Evacuator{
+		client:        client,
+		crHelper:      k8s.NewCRHelper(client, logger),
+		clientset:     clientset,
+		eventRecorder: eventRecorder,
+		log:           logger.WithField("component", "Evacuator"),
+	}
+}
+
+// Evacuate performs evacuation of the drive if it wasn't done before
+// Pods are evicted in stages: pod is evicted only if PodDisruptionBudgets which select it allow disruption.
+// Postponed evictions are listed in evacuation/blocked annotation and evacuation stays in progress,
+// caller is responsible for calling Evacuate again until evacuation is done
+// Sets cordon and evacuation annotations on the drive object, caller is responsible for its update
+// Returns true if drive object was changed
+func (e *Evacuator) Evacuate(ctx context.Context, drive *drivecrd.Drive) (bool, error) {
+	ll := e.log.WithFields(logrus.Fields{
+		"method": "Evacuate",
+		"drive":  drive.Name,
+	})
+	if drive.GetAnnotations()[apiV1.DriveAnnotationEvacuation] == apiV1.DriveAnnotationEvacuationDone {
+		return false, nil
+	}
+	ll.Infof("Evacuate drive %s with health %s", drive.Name, drive.Spec.Health)
+
+	volumes, err := e.getVolumes(ctx, drive)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		pvs     = make([]string, 0, len(volumes))
+		evicted = splitPods(drive.GetAnnotations()[apiV1.DriveAnnotationEvacuationEvicted])
+		skipped = make([]string, 0)
+		blocked = make([]string, 0)
+		budgets = newDisruptionBudgets(e.clientset)
+	)
+	for _, vol := range volumes {
+		if err = e.markPV(ctx, vol.Name, drive.Name); err != nil {
+			return false, err
+		}
+		pvs = append(pvs, vol.Name)
+		for _, owner := range vol.Spec.Owners {
+			podName := vol.Namespace + "/" + owner
+			// pod of StatefulSet is recreated with the same name, it mustn't be evicted twice
+			if util.ContainsString(evicted, podName) {
+				continue
+			}
+			result, blockedBy, err := e.evictPod(ctx, owner, vol.Namespace, budgets)
+			if err != nil {
+				return false, err
+			}
+			switch result {
+			case podEvicted:
+				evicted = append(evicted, podName)
+			case podBlocked:
+				blocked = append(blocked, fmt.Sprintf("%s (%s)", podName, blockedBy))
+			default:
+				skipped = append(skipped, podName)
+			}
+		}
+	}
+
+	if drive.Annotations == nil {
+		drive.Annotations = map[string]string{}
+	}
+	before := evacuationAnnotations(drive)
+	drive.Annotations[apiV1.DriveAnnotationCordon] = "true"
+	if len(blocked) > 0 {
+		drive.Annotations[apiV1.DriveAnnotationEvacuation] = apiV1.DriveAnnotationEvacuationInProgress
+		drive.Annotations[apiV1.DriveAnnotationEvacuationEvicted] = strings.Join(evicted, ",")
+		drive.Annotations[apiV1.DriveAnnotationEvacuationBlocked] = strings.Join(blocked, ",")
+		if before[apiV1.DriveAnnotationEvacuationBlocked] != drive.Annotations[apiV1.DriveAnnotationEvacuationBlocked] {
+			ll.Warnf("Evictions are blocked by disruption budgets: %v", blocked)
+			e.eventRecorder.Eventf(drive, eventing.DriveEvacuationBlocked,
+				"Drive health is %s, drive is cordoned. Evictions are postponed to keep disruption budgets "+
+					"and will be retried. Blocked pods: [%s]. Evicted pods: [%s]. %s",
+				drive.Spec.Health, strings.Join(blocked, ", "), strings.Join(evicted, ", "),
+				drive.GetDriveDescription())
+		}
+		return !reflect.DeepEqual(before, evacuationAnnotations(drive)), nil
+	}
+	drive.Annotations[apiV1.DriveAnnotationEvacuation] = apiV1.DriveAnnotationEvacuationDone
+	delete(drive.Annotations, apiV1.DriveAnnotationEvacuationEvicted)
+	delete(drive.Annotations, apiV1.DriveAnnotationEvacuationBlocked)
+
+	e.eventRecorder.Eventf(drive, eventing.DriveEvacuation,
+		"Drive health is %s, drive is cordoned. Affected PVs: [%s]. Evicted pods: [%s]. "+
+			"Pods which didn't opt in eviction: [%s]. Recovery runbook: "+
+			"1) restore data of affected PVs from backup or application replicas; "+
+			"2) delete affected PVCs to release volumes; "+
+			"3) replace drive using documented removal procedure; "+
+			"4) remove '%s' annotation from Drive CR if drive is returned to service. %s",
+		drive.Spec.Health, strings.Join(pvs, ", "), strings.Join(evicted, ", "), strings.Join(skipped, ", "),
+		apiV1.DriveAnnotationCordon, drive.GetDriveDescription())
+	return true, nil
+}
+
+// IsEvacuationInProgress returns true if evictions of pods of the drive are postponed by disruption budgets
+func IsEvacuationInProgress(drive *drivecrd.Drive) bool {
+	return drive.GetAnnotations()[apiV1.DriveAnnotationEvacuation] == apiV1.DriveAnnotationEvacuationInProgress
+}
+
+// evacuationAnnotations returns copy of annotations which Evacuate sets
+func evacuationAnnotations(drive *drivecrd.Drive) map[string]string {
+	res := map[string]string{}
+	for _, key := range []string{apiV1.DriveAnnotationCordon, apiV1.DriveAnnotationEvacuation,
+		apiV1.DriveAnnotationEvacuationEvicted, apiV1.DriveAnnotationEvacuationBlocked} {
+		if value, ok := drive.Annotations[key]; ok {
+			res[key] = value
+		}
+	}
+	return res
+}
+
+// splitPods returns pods from comma separated annotation value
+func splitPods(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+// getVolumes returns volumes located on the drive directly or on LVG based on the drive
+func (e *Evacuator) getVolumes(ctx context.Context, drive *drivecrd.Drive) ([]*volumecrd.Volume, error) {
+	volumes, err := e.crHelper.GetVolumesByLocation(ctx, drive.Spec.UUID)
+	if err != nil {
+		return nil, err
+	}
+	lvg, err := e.crHelper.GetLVGByDrive(ctx, drive.Spec.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if lvg == nil {
+		return volumes, nil
+	}
+	lvgVolumes, err := e.crHelper.GetVolumesByLocation(ctx, lvg.Name)
+	if err != nil {
+		return nil, err
+	}
+	return append(volumes, lvgVolumes...), nil
+}
+
+// markPV sets drive failed annotation on PersistentVolume, PV might be already removed
+func (e *Evacuator) markPV(ctx context.Context, pvName, driveUUID string) error {
+	pv := &corev1.PersistentVolume{}
+	if err := e.client.Get(ctx, k8sCl.ObjectKey{Name: pvName}, pv); err != nil {
+		return k8sCl.IgnoreNotFound(err)
+	}
+	if pv.Annotations[apiV1.PVAnnotationDriveFailed] == driveUUID {
+		return nil
+	}
+	if pv.Annotations == nil {
+		pv.Annotations = map[string]string{}
+	}
+	pv.Annotations[apiV1.PVAnnotationDriveFailed] = driveUUID
+	return e.client.Update(ctx, pv)
+}
+
+// evictionResult is a result of pod eviction
+type evictionResult int
+
+const (
+	// podSkipped means that pod doesn't exist or didn't opt in eviction
+	podSkipped evictionResult = iota
+	podEvicted
+	// podBlocked means that eviction would violate PodDisruptionBudget and is postponed
+	podBlocked
+)
+
+// evictPod evicts pod if it has opt-in annotation and its disruption budgets allow eviction
+// Returns result of eviction and budgets which block eviction
+func (e *Evacuator) evictPod(ctx context.Context, name, namespace string,
+	budgets *disruptionBudgets) (evictionResult, string, error) {
+	pod := &corev1.Pod{}
+	if err := e.client.Get(ctx, k8sCl.ObjectKey{Name: name, Namespace: namespace}, pod); err != nil {
+		return podSkipped, "", k8sCl.IgnoreNotFound(err)
+	}
+	if pod.Annotations[apiV1.PodAnnotationEvictOnDriveFailure] != "true" {
+		return podSkipped, "", nil
+	}
+	blocking, err := budgets.blocking(ctx, pod)
+	if err != nil {
+		return podSkipped, "", fmt.Errorf("unable to read disruption budgets of pod %s/%s: %v", namespace, name, err)
+	}
+	if len(blocking) > 0 {
+		return podBlocked, strings.Join(blocking, " "), nil
+	}
+	err = e.clientset.PolicyV1beta1().Evictions(namespace).Evict(ctx, &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	})
+	switch {
+	case err == nil:
+		return podEvicted, "", budgets.disrupt(ctx, pod)
+	case k8serrors.IsNotFound(err):
+		return podSkipped, "", nil
+	case k8serrors.IsTooManyRequests(err):
+		// status of budget isn't up to date yet, API server rejects eviction
+		return podBlocked, "disruption budget", nil
+	default:
+		return podSkipped, "", err
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+var (
+	testNs     = "default"
+	testLogger = logrus.New()
+	testCtx    = context.Background()
+	testDrive  = api.Drive{
+		UUID:         "drive-uuid",
+		SerialNumber: "sn",
+		NodeId:       "node",
+		Health:       apiV1.HealthBad,
+		Status:       apiV1.DriveStatusOnline,
+		Usage:        apiV1.DriveUsageInUse,
+	}
+)
+
+func TestEvacuator_Evacuate(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	drive := kubeClient.ConstructDriveCR(testDrive.UUID, testDrive)
+	assert.Nil(t, kubeClient.CreateCR(testCtx, drive.Name, drive))
+	volume := kubeClient.ConstructVolumeCR("pvc-1", testNs, nil, api.Volume{
+		Id: "pvc-1", Location: testDrive.UUID, NodeId: testDrive.NodeId, Owners: []string{"opted-in", "other"}})
+	assert.Nil(t, kubeClient.CreateCR(testCtx, volume.Name, volume))
+
+	pv := &corev1.PersistentVolume{}
+	pv.Name = volume.Name
+	assert.Nil(t, kubeClient.Create(testCtx, pv))
+	optedIn := &corev1.Pod{}
+	optedIn.Name, optedIn.Namespace = "opted-in", testNs
+	optedIn.Annotations = map[string]string{apiV1.PodAnnotationEvictOnDriveFailure: "true"}
+	assert.Nil(t, kubeClient.Create(testCtx, optedIn))
+	other := &corev1.Pod{}
+	other.Name, other.Namespace = "other", testNs
+	assert.Nil(t, kubeClient.Create(testCtx, other))
+
+	clientset := fake.NewSimpleClientset(optedIn.DeepCopy(), other.DeepCopy())
+	recorder := new(mocks.NoOpRecorder)
+	evacuator := NewEvacuator(kubeClient, clientset, recorder, testLogger)
+
+	changed, err := evacuator.Evacuate(testCtx, drive)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "true", drive.Annotations[apiV1.DriveAnnotationCordon])
+	assert.Equal(t, apiV1.DriveAnnotationEvacuationDone, drive.Annotations[apiV1.DriveAnnotationEvacuation])
+
+	assert.Nil(t, kubeClient.Get(testCtx, k8sCl.ObjectKey{Name: pv.Name}, pv))
+	assert.Equal(t, testDrive.UUID, pv.Annotations[apiV1.PVAnnotationDriveFailed])
+
+	// only opted in pod is evicted
+	assert.Equal(t, 1, countEvictions(clientset))
+
+	assert.Len(t, recorder.Calls, 1)
+	assert.Equal(t, eventing.DriveEvacuation, recorder.Calls[0].Event)
+
+	// evacuation is performed once
+	changed, err = evacuator.Evacuate(testCtx, drive)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+	assert.Len(t, recorder.Calls, 1)
+}
+
+func TestController_handleDriveUpdate_Evacuation(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	recorder := new(mocks.NoOpRecorder)
+	c := NewController(kubeClient, testDrive.NodeId, nil, nil, testLogger)
+	c.SetEvacuator(NewEvacuator(kubeClient, fake.NewSimpleClientset(), recorder, testLogger))
+
+	drive := kubeClient.ConstructDriveCR(testDrive.UUID, testDrive)
+	assert.Nil(t, kubeClient.CreateCR(testCtx, drive.Name, drive))
+
+	status, err := c.handleDriveUpdate(testCtx, c.log, drive)
+	assert.Nil(t, err)
+	assert.Equal(t, update, status)
+	assert.Equal(t, apiV1.DriveUsageReleasing, drive.Spec.Usage)
+	assert.Equal(t, "true", drive.Annotations[apiV1.DriveAnnotationCordon])
+	assert.Len(t, recorder.Calls, 1)
+}
+
+func TestEvacuator_Evacuate_DisruptionBudgets(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	drive := kubeClient.ConstructDriveCR(testDrive.UUID, testDrive)
+	assert.Nil(t, kubeClient.CreateCR(testCtx, drive.Name, drive))
+	volume := kubeClient.ConstructVolumeCR("pvc-1", testNs, nil, api.Volume{
+		Id: "pvc-1", Location: testDrive.UUID, NodeId: testDrive.NodeId, Owners: []string{"db-0", "db-1"}})
+	assert.Nil(t, kubeClient.CreateCR(testCtx, volume.Name, volume))
+
+	pods := make([]*corev1.Pod, 0)
+	for _, name := range volume.Spec.Owners {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNs,
+			Labels:      map[string]string{"app": "db"},
+			Annotations: map[string]string{apiV1.PodAnnotationEvictOnDriveFailure: "true"}}}
+		assert.Nil(t, kubeClient.Create(testCtx, pod))
+		pods = append(pods, pod)
+	}
+	pdb := newPDB("db", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}, 1)
+	clientset := fake.NewSimpleClientset(pods[0].DeepCopy(), pods[1].DeepCopy(), pdb)
+	recorder := new(mocks.NoOpRecorder)
+	evacuator := NewEvacuator(kubeClient, clientset, recorder, testLogger)
+
+	// the first stage evicts one pod, eviction of the second pod is postponed
+	changed, err := evacuator.Evacuate(testCtx, drive)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.True(t, IsEvacuationInProgress(drive))
+	assert.Equal(t, "true", drive.Annotations[apiV1.DriveAnnotationCordon])
+	assert.Equal(t, testNs+"/db-0", drive.Annotations[apiV1.DriveAnnotationEvacuationEvicted])
+	assert.Equal(t, testNs+"/db-1 ("+testNs+"/db)", drive.Annotations[apiV1.DriveAnnotationEvacuationBlocked])
+	assert.Len(t, recorder.Calls, 1)
+	assert.Equal(t, eventing.DriveEvacuationBlocked, recorder.Calls[0].Event)
+
+	// budget doesn't allow disruption yet, event isn't repeated
+	pdb.Status.DisruptionsAllowed = 0
+	_, err = clientset.PolicyV1beta1().PodDisruptionBudgets(testNs).UpdateStatus(testCtx, pdb, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	changed, err = evacuator.Evacuate(testCtx, drive)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+	assert.Len(t, recorder.Calls, 1)
+
+	// evicted pod is recovered, the second pod is evicted
+	pdb.Status.DisruptionsAllowed = 1
+	_, err = clientset.PolicyV1beta1().PodDisruptionBudgets(testNs).UpdateStatus(testCtx, pdb, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	changed, err = evacuator.Evacuate(testCtx, drive)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.False(t, IsEvacuationInProgress(drive))
+	assert.Equal(t, apiV1.DriveAnnotationEvacuationDone, drive.Annotations[apiV1.DriveAnnotationEvacuation])
+	assert.NotContains(t, drive.Annotations, apiV1.DriveAnnotationEvacuationBlocked)
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.DriveEvacuation, recorder.Calls[1].Event)
+
+	assert.Equal(t, 2, countEvictions(clientset))
+}
+
+// countEvictions returns number of pod evictions requested with clientset, disruption budgets are read too
+func countEvictions(clientset *fake.Clientset) int {
+	evictions := 0
+	for _, action := range clientset.Actions() {
+		if action.GetSubresource() == "eviction" {
+			evictions++
+		}
+	}
+	return evictions
+}
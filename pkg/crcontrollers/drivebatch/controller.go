@@ -0,0 +1,194 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivebatch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivebatchcrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	csibmnodeconst "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
+	metricsC "github.com/dell/csi-baremetal/pkg/metrics/common"
+)
+
+// Controller reconciles DriveBatch custom resources: applies annotation operation of the batch to Drive CRs
+// which match node selector, model and type of the batch
+type Controller struct {
+	client *k8s.KubeClient
+	log    *logrus.Entry
+}
+
+// NewController creates new instance of Controller structure
+// Receives an instance of base.KubeClient and logrus logger
+// Returns an instance of Controller
+func NewController(client *k8s.KubeClient, log *logrus.Logger) *Controller {
+	return &Controller{
+		client: client,
+		log:    log.WithField("component", "DriveBatchController"),
+	}
+}
+
+// SetupWithManager registers Controller to ControllerManager
+func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&drivebatchcrd.DriveBatch{}).
+		Complete(diagnostics.TrackReconciler("drivebatch", c))
+}
+
+// Reconcile processes DriveBatch CR which isn't processed yet. Batch is processed once, its status is set to DONE
+// when all matched drives are updated or to FAILED otherwise, status might be cleared to process batch again
+func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	defer metricsC.ReconcileDuration.EvaluateDurationForType("drive_batch_controller")()
+	ll := c.log.WithFields(logrus.Fields{
+		"method": "Reconcile",
+		"name":   req.Name,
+	})
+
+	batch := &drivebatchcrd.DriveBatch{}
+	if err := c.client.ReadCR(ctx, req.Name, "", batch); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if batch.Spec.Status == apiV1.DriveBatchStatusDone || batch.Spec.Status == apiV1.DriveBatchStatusFailed {
+		return ctrl.Result{}, nil
+	}
+
+	selector, err := validate(batch)
+	if err != nil {
+		ll.Errorf("Batch is invalid: %v", err)
+		batch.Spec.Status = apiV1.DriveBatchStatusFailed
+		return ctrl.Result{}, c.client.UpdateCR(ctx, batch)
+	}
+
+	if batch.Spec.Status != apiV1.DriveBatchStatusInProgress {
+		batch.Spec.Status = apiV1.DriveBatchStatusInProgress
+		if err = c.client.UpdateCR(ctx, batch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	drives, err := c.getMatchedDrives(ctx, batch, selector)
+	if err != nil {
+		ll.Errorf("Unable to find drives: %v", err)
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	batch.Spec.Updated, batch.Spec.Failed = nil, nil
+	for i := range drives {
+		drive := &drives[i]
+		if !applyOperation(batch, drive) {
+			batch.Spec.Updated = append(batch.Spec.Updated, drive.Spec.UUID)
+			continue
+		}
+		if err = c.client.UpdateCR(ctx, drive); err != nil {
+			ll.Errorf("Unable to update drive %s: %v", drive.Spec.UUID, err)
+			batch.Spec.Failed = append(batch.Spec.Failed, drive.Spec.UUID)
+			continue
+		}
+		batch.Spec.Updated = append(batch.Spec.Updated, drive.Spec.UUID)
+	}
+
+	batch.Spec.Status = apiV1.DriveBatchStatusDone
+	if len(batch.Spec.Failed) > 0 {
+		batch.Spec.Status = apiV1.DriveBatchStatusFailed
+	}
+	ll.Infof("Batch is processed with status %s: %d drives updated, %d failed",
+		batch.Spec.Status, len(batch.Spec.Updated), len(batch.Spec.Failed))
+	return ctrl.Result{}, c.client.UpdateCR(ctx, batch)
+}
+
+// validate checks operation of the batch and parses its node selector
+func validate(batch *drivebatchcrd.DriveBatch) (labels.Selector, error) {
+	switch batch.Spec.Operation {
+	case apiV1.DriveBatchOperationAnnotate, apiV1.DriveBatchOperationRemoveAnnotation:
+	default:
+		return nil, fmt.Errorf("operation %s isn't supported, expected %s or %s", batch.Spec.Operation,
+			apiV1.DriveBatchOperationAnnotate, apiV1.DriveBatchOperationRemoveAnnotation)
+	}
+	if batch.Spec.AnnotationKey == "" {
+		return nil, fmt.Errorf("annotation key is empty")
+	}
+	selector, err := labels.Parse(batch.Spec.NodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("node selector %s is invalid: %v", batch.Spec.NodeSelector, err)
+	}
+	return selector, nil
+}
+
+// getMatchedDrives returns Drive CRs on k8s Nodes matched by selector with model and type of the batch,
+// drives are bound to k8s Nodes by node ID label which is set by node controller
+func (c *Controller) getMatchedDrives(ctx context.Context, batch *drivebatchcrd.DriveBatch,
+	selector labels.Selector) ([]drivecrd.Drive, error) {
+	nodeList := &coreV1.NodeList{}
+	if err := c.client.List(ctx, nodeList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	nodeIDs := make(map[string]bool, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		if id, ok := node.GetLabels()[csibmnodeconst.NodeIDTopologyLabelKey]; ok {
+			nodeIDs[id] = true
+		}
+	}
+
+	driveList := &drivecrd.DriveList{}
+	if err := c.client.ReadList(ctx, driveList); err != nil {
+		return nil, err
+	}
+	var drives []drivecrd.Drive
+	for _, drive := range driveList.Items {
+		if !nodeIDs[drive.Spec.NodeId] ||
+			(batch.Spec.Model != "" && drive.Spec.PID != batch.Spec.Model) ||
+			(batch.Spec.Type != "" && drive.Spec.Type != batch.Spec.Type) {
+			continue
+		}
+		drives = append(drives, drive)
+	}
+	return drives, nil
+}
+
+// applyOperation applies annotation operation of the batch to the drive
+// Returns false if drive already has expected annotations
+func applyOperation(batch *drivebatchcrd.DriveBatch, drive *drivecrd.Drive) bool {
+	annotations := drive.GetAnnotations()
+	value, ok := annotations[batch.Spec.AnnotationKey]
+	if batch.Spec.Operation == apiV1.DriveBatchOperationRemoveAnnotation {
+		if !ok {
+			return false
+		}
+		delete(annotations, batch.Spec.AnnotationKey)
+		drive.SetAnnotations(annotations)
+		return true
+	}
+	if ok && value == batch.Spec.AnnotationValue {
+		return false
+	}
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[batch.Spec.AnnotationKey] = batch.Spec.AnnotationValue
+	drive.SetAnnotations(annotations)
+	return true
+}
@@ -0,0 +1,178 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivebatch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivebatchcrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	csibmnodeconst "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
+)
+
+var (
+	tCtx       = context.Background()
+	testLogger = logrus.New()
+	testNS     = "default"
+	batchName  = "cordon-model-x"
+	modelX     = "model-x"
+)
+
+func TestReconcile_Annotate(t *testing.T) {
+	c, client := setup(t)
+	createNode(t, client, "node-1", "id-1", "rack-1")
+	createNode(t, client, "node-2", "id-2", "rack-2")
+	createDrive(t, client, "drive-1", "id-1", modelX, apiV1.DriveTypeHDD)
+	createDrive(t, client, "drive-2", "id-1", "model-y", apiV1.DriveTypeHDD)
+	createDrive(t, client, "drive-3", "id-2", modelX, apiV1.DriveTypeHDD)
+	createDrive(t, client, "drive-4", "id-1", modelX, apiV1.DriveTypeSSD)
+	batch := createBatch(t, client, api.DriveBatch{
+		NodeSelector:    "rack=rack-1",
+		Model:           modelX,
+		Type:            apiV1.DriveTypeHDD,
+		Operation:       apiV1.DriveBatchOperationAnnotate,
+		AnnotationKey:   apiV1.DriveAnnotationCordon,
+		AnnotationValue: "true",
+	})
+
+	res, err := c.Reconcile(tCtx, request(batch))
+	assert.Nil(t, err)
+	assert.Equal(t, ctrl.Result{}, res)
+
+	batch = readBatch(t, client)
+	assert.Equal(t, apiV1.DriveBatchStatusDone, batch.Spec.Status)
+	assert.Equal(t, []string{"drive-1"}, batch.Spec.Updated)
+	assert.Empty(t, batch.Spec.Failed)
+
+	for name, expected := range map[string]bool{"drive-1": true, "drive-2": false, "drive-3": false, "drive-4": false} {
+		drive := &drivecrd.Drive{}
+		assert.Nil(t, client.ReadCR(tCtx, name, "", drive))
+		_, ok := drive.GetAnnotations()[apiV1.DriveAnnotationCordon]
+		assert.Equal(t, expected, ok, name)
+	}
+
+	// processed batch isn't applied again
+	drive := &drivecrd.Drive{}
+	assert.Nil(t, client.ReadCR(tCtx, "drive-1", "", drive))
+	drive.SetAnnotations(nil)
+	assert.Nil(t, client.UpdateCR(tCtx, drive))
+	_, err = c.Reconcile(tCtx, request(batch))
+	assert.Nil(t, err)
+	assert.Nil(t, client.ReadCR(tCtx, "drive-1", "", drive))
+	assert.Empty(t, drive.GetAnnotations())
+}
+
+func TestReconcile_RemoveAnnotation(t *testing.T) {
+	c, client := setup(t)
+	createNode(t, client, "node-1", "id-1", "rack-1")
+	drive := createDrive(t, client, "drive-1", "id-1", modelX, apiV1.DriveTypeHDD)
+	drive.SetAnnotations(map[string]string{apiV1.DriveAnnotationCordon: "true", "other": "value"})
+	assert.Nil(t, client.UpdateCR(tCtx, drive))
+	batch := createBatch(t, client, api.DriveBatch{
+		Operation:     apiV1.DriveBatchOperationRemoveAnnotation,
+		AnnotationKey: apiV1.DriveAnnotationCordon,
+	})
+
+	_, err := c.Reconcile(tCtx, request(batch))
+	assert.Nil(t, err)
+
+	batch = readBatch(t, client)
+	assert.Equal(t, apiV1.DriveBatchStatusDone, batch.Spec.Status)
+	assert.Equal(t, []string{"drive-1"}, batch.Spec.Updated)
+	// decoding into the existing object keeps keys of its annotations map
+	drive = &drivecrd.Drive{}
+	assert.Nil(t, client.ReadCR(tCtx, "drive-1", "", drive))
+	assert.Equal(t, map[string]string{"other": "value"}, drive.GetAnnotations())
+}
+
+func TestReconcile_Invalid(t *testing.T) {
+	for _, spec := range []api.DriveBatch{
+		{Operation: "unknown", AnnotationKey: apiV1.DriveAnnotationCordon},
+		{Operation: apiV1.DriveBatchOperationAnnotate},
+		{Operation: apiV1.DriveBatchOperationAnnotate, AnnotationKey: apiV1.DriveAnnotationCordon, NodeSelector: "rack in"},
+	} {
+		c, client := setup(t)
+		batch := createBatch(t, client, spec)
+
+		_, err := c.Reconcile(tCtx, request(batch))
+		assert.Nil(t, err)
+		assert.Equal(t, apiV1.DriveBatchStatusFailed, readBatch(t, client).Spec.Status)
+	}
+}
+
+func TestReconcile_NotFound(t *testing.T) {
+	c, _ := setup(t)
+	res, err := c.Reconcile(tCtx, ctrl.Request{NamespacedName: types.NamespacedName{Name: batchName}})
+	assert.Nil(t, err)
+	assert.Equal(t, ctrl.Result{}, res)
+}
+
+func setup(t *testing.T) (*Controller, *k8s.KubeClient) {
+	client, err := k8s.GetFakeKubeClient(testNS, testLogger)
+	assert.Nil(t, err)
+	return NewController(client, testLogger), client
+}
+
+func createNode(t *testing.T, client *k8s.KubeClient, name, id, rack string) {
+	node := &coreV1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{csibmnodeconst.NodeIDTopologyLabelKey: id, "rack": rack},
+	}}
+	assert.Nil(t, client.Create(tCtx, node))
+}
+
+func createDrive(t *testing.T, client *k8s.KubeClient, uuid, nodeID, model, driveType string) *drivecrd.Drive {
+	drive := client.ConstructDriveCR(uuid, api.Drive{
+		UUID:   uuid,
+		NodeId: nodeID,
+		PID:    model,
+		Type:   driveType,
+	})
+	assert.Nil(t, client.CreateCR(tCtx, uuid, drive))
+	return drive
+}
+
+func createBatch(t *testing.T, client *k8s.KubeClient, spec api.DriveBatch) *drivebatchcrd.DriveBatch {
+	batch := &drivebatchcrd.DriveBatch{
+		TypeMeta:   metav1.TypeMeta{Kind: apiV1.DriveBatchKind, APIVersion: apiV1.APIV1Version},
+		ObjectMeta: metav1.ObjectMeta{Name: batchName},
+		Spec:       spec,
+	}
+	assert.Nil(t, client.CreateCR(tCtx, batchName, batch))
+	return batch
+}
+
+func readBatch(t *testing.T, client *k8s.KubeClient) *drivebatchcrd.DriveBatch {
+	batch := &drivebatchcrd.DriveBatch{}
+	assert.Nil(t, client.ReadCR(tCtx, batchName, "", batch))
+	return batch
+}
+
+func request(batch *drivebatchcrd.DriveBatch) ctrl.Request {
+	return ctrl.Request{NamespacedName: types.NamespacedName{Name: batch.Name}}
+}
@@ -35,11 +35,17 @@ import (
 	vccrd "github.com/dell/csi-baremetal/api/v1/volumecrd"
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/ctrlopts"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lvm"
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/base/lvmbackup"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	metricsC "github.com/dell/csi-baremetal/pkg/metrics/common"
+	"github.com/dell/csi-baremetal/pkg/node/provisioners"
+	"github.com/dell/csi-baremetal/pkg/node/provisioners/utilwrappers"
 )
 
 const lvgFinalizer = "dell.emc.csi/lvg-cleanup"
@@ -52,6 +58,10 @@ type Controller struct {
 	listBlk lsblk.WrapLsblk
 	lvmOps  lvm.WrapLVM
 	e       command.CmdExecutor
+	// releases pre-created partitions of standby drives
+	standby *provisioners.StandbyPartition
+	// restores VG metadata from backups, nil if LVM metadata backup is disabled
+	metadataArchive *lvmbackup.Archive
 
 	node string
 	log  *logrus.Entry
@@ -70,9 +80,16 @@ func NewController(k8sClient *k8s.KubeClient, nodeID string, log *logrus.Logger)
 		e:         e,
 		lvmOps:    lvm.NewLVM(e, log),
 		listBlk:   lsblk.NewLSBLK(log),
+		standby: provisioners.NewStandbyPartition(ph.NewWrapPartitionImpl(e, log),
+			utilwrappers.NewFSOperationsImpl(e, log), log),
 	}
 }
 
+// SetMetadataArchive enables restore of VG metadata with LVGRepairRestoreMetadata repair action
+func (c *Controller) SetMetadataArchive(archive *lvmbackup.Archive) {
+	c.metadataArchive = archive
+}
+
 // Reconcile is the main Reconcile loop of Controller. This loop handles creation of VG matched to LogicalVolumeGroup CR on
 // Controller's node if LogicalVolumeGroup.Spec.Status is Creating. Also this loop handles VG deletion on the node if
 // LogicalVolumeGroup.ObjectMeta.DeletionTimestamp is not zero and VG is not placed on system drive.
@@ -110,6 +127,13 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		ll.Info("Creating LogicalVolumeGroup")
 		return c.handlerLVGCreation(lvg)
 	}
+	if lvg.Spec.Status == apiV1.Created {
+		// VG with corrupted metadata can't be checked for missing PVs
+		if lvg.GetAnnotations()[apiV1.LVGRepairAnnotation] == apiV1.LVGRepairRestoreMetadata {
+			return c.handleMetadataRestore(ctx, lvg)
+		}
+		return c.handleMissingPVs(ctx, lvg)
+	}
 
 	return ctrl.Result{}, nil
 }
@@ -165,6 +189,116 @@ func (c *Controller) handlerLVGCreation(lvg *lvgcrd.LogicalVolumeGroup) (ctrl.Re
 	return ctrl.Result{}, nil
 }
 
+// handleMissingPVs detects PVs which are missing in VG (for example, drive isn't found after reboot),
+// activates VG in degraded mode, reports count of missing PVs in LogicalVolumeGroup CR annotation
+// and performs repair action if it is requested with annotation
+func (c *Controller) handleMissingPVs(ctx context.Context, lvg *lvgcrd.LogicalVolumeGroup) (ctrl.Result, error) {
+	ll := c.log.WithFields(logrus.Fields{
+		"method":  "handleMissingPVs",
+		"LVGName": lvg.Name,
+	})
+
+	missing, err := c.lvmOps.GetVGMissingPVs(lvg.Name)
+	if err != nil {
+		ll.Warnf("Unable to check missing PVs: %v", err)
+		return ctrl.Result{}, nil
+	}
+	reported, isReported := lvg.GetAnnotations()[apiV1.LVGMissingPVsAnnotation]
+
+	switch {
+	case missing == 0 && !isReported:
+		return ctrl.Result{}, nil
+	case missing == 0:
+		ll.Infof("All PVs are present in LogicalVolumeGroup")
+		delete(lvg.Annotations, apiV1.LVGMissingPVsAnnotation)
+		delete(lvg.Annotations, apiV1.LVGRepairAnnotation)
+	case lvg.GetAnnotations()[apiV1.LVGRepairAnnotation] == apiV1.LVGRepairRemoveMissing:
+		if err = c.lvmOps.VGReduceMissing(lvg.Name); err != nil {
+			ll.Errorf("Unable to remove missing PVs: %v", err)
+			lvg.Annotations[apiV1.LVGRepairAnnotation] = apiV1.LVGRepairFailed
+			break
+		}
+		ll.Infof("%d missing PVs were removed from LogicalVolumeGroup", missing)
+		delete(lvg.Annotations, apiV1.LVGMissingPVsAnnotation)
+		delete(lvg.Annotations, apiV1.LVGRepairAnnotation)
+		lvg.Spec.Locations = c.getOnlineLocations(ctx, lvg.Spec.Locations)
+	case reported == strconv.Itoa(missing):
+		return ctrl.Result{}, nil
+	default:
+		ll.Warnf("%d PVs are missing in LogicalVolumeGroup", missing)
+		// only LVs with enough redundancy are activated, LVs located on missing PVs stay inactive
+		if err = c.lvmOps.VGActivateDegraded(lvg.Name); err != nil {
+			ll.Errorf("Unable to activate LogicalVolumeGroup in degraded mode: %v", err)
+		}
+		if lvg.Annotations == nil {
+			lvg.Annotations = make(map[string]string, 1)
+		}
+		lvg.Annotations[apiV1.LVGMissingPVsAnnotation] = strconv.Itoa(missing)
+	}
+
+	if err = c.k8sClient.UpdateCR(ctx, lvg); err != nil {
+		ll.Errorf("Unable to update LogicalVolumeGroup: %v", err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// handleMetadataRestore restores VG metadata from backup which is selected by LVGRestoreMetadataSeqNoAnnotation,
+// repair annotations are removed on success and LVGRepairFailed is set on failure
+func (c *Controller) handleMetadataRestore(ctx context.Context, lvg *lvgcrd.LogicalVolumeGroup) (ctrl.Result, error) {
+	ll := c.log.WithFields(logrus.Fields{
+		"method":  "handleMetadataRestore",
+		"LVGName": lvg.Name,
+	})
+
+	seqNo, err := c.restoreMetadata(ctx, lvg)
+	if err != nil {
+		ll.Errorf("Unable to restore metadata of LogicalVolumeGroup: %v", err)
+		lvg.Annotations[apiV1.LVGRepairAnnotation] = apiV1.LVGRepairFailed
+	} else {
+		ll.Infof("Metadata of LogicalVolumeGroup is restored from backup with seqno %d", seqNo)
+		delete(lvg.Annotations, apiV1.LVGRepairAnnotation)
+		delete(lvg.Annotations, apiV1.LVGRestoreMetadataSeqNoAnnotation)
+	}
+
+	if err = c.k8sClient.UpdateCR(ctx, lvg); err != nil {
+		ll.Errorf("Unable to update LogicalVolumeGroup: %v", err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// restoreMetadata restores VG metadata from backup, the latest backup is used if seqno isn't set in annotation
+// Returns seqno of restored backup or error if something went wrong
+func (c *Controller) restoreMetadata(ctx context.Context, lvg *lvgcrd.LogicalVolumeGroup) (int64, error) {
+	if c.metadataArchive == nil {
+		return 0, errors.New("LVM metadata backup is disabled")
+	}
+	var seqNo int64
+	if value, ok := lvg.GetAnnotations()[apiV1.LVGRestoreMetadataSeqNoAnnotation]; ok {
+		var err error
+		if seqNo, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return 0, fmt.Errorf("wrong metadata seqno %s: %v", value, err)
+		}
+	}
+	return c.metadataArchive.Restore(ctx, lvg.Name, seqNo)
+}
+
+// getOnlineLocations returns UUIDs of drives which are present on the node
+func (c *Controller) getOnlineLocations(ctx context.Context, locations []string) []string {
+	online := make([]string, 0, len(locations))
+	for _, driveUUID := range locations {
+		drive := &drivecrd.Drive{}
+		if err := c.k8sClient.ReadCR(ctx, driveUUID, "", drive); err != nil {
+			continue
+		}
+		if drive.Spec.Status == apiV1.DriveStatusOnline {
+			online = append(online, driveUUID)
+		}
+	}
+	return online
+}
+
 // handleLVGRemoving handles removing of LogicalVolumeGroup CR, removes LogicalVolumeGroup from the system and removes finalizers
 func (c *Controller) handleLVGRemoving(lvg *lvgcrd.LogicalVolumeGroup) (ctrl.Result, error) {
 	ll := logrus.WithField("LVGName", lvg.Name)
@@ -176,7 +310,7 @@ func (c *Controller) handleLVGRemoving(lvg *lvgcrd.LogicalVolumeGroup) (ctrl.Res
 	volumes := &vccrd.VolumeList{}
 
 	// TODO - Remove context.Background() usage - https://github.com/dell/csi-baremetal/issues/703
-	err := c.k8sClient.ReadList(context.Background(), volumes)
+	err := c.k8sClient.ReadListByIndex(context.Background(), volumes, k8s.NodeIDIndex, lvg.Spec.Node)
 	if err != nil {
 		ll.Errorf("Unable to read volume list: %v", err)
 		return ctrl.Result{Requeue: true}, err
@@ -211,6 +345,7 @@ func (c *Controller) handleLVGRemoving(lvg *lvgcrd.LogicalVolumeGroup) (ctrl.Res
 func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&lvgcrd.LogicalVolumeGroup{}).
+		WithOptions(ctrlopts.FromEnv(ctrlopts.LVGControllerPrefix, ctrlopts.Options{}, c.log).ControllerOptions()).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool {
 				return c.filterCRs(e.Object)
@@ -225,7 +360,7 @@ func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
 				return c.filterCRs(e.Object)
 			},
 		}).
-		Complete(c)
+		Complete(diagnostics.TrackReconciler("logicalvolumegroup", c))
 }
 
 func (c *Controller) filterCRs(obj runtime.Object) bool {
@@ -264,6 +399,13 @@ func (c *Controller) createSystemLVG(lvg *lvgcrd.LogicalVolumeGroup) (locations
 			ll.Error(err)
 			continue
 		}
+		// drive might have pre-created partition which must be removed before PV creation
+		if provisioners.IsStandby(drive) {
+			if err := c.releaseStandbyDrive(drive, dev); err != nil {
+				ll.Errorf("Unable to release standby drive %s: %v", driveUUID, err)
+				continue
+			}
+		}
 		// create PV
 		if err := c.lvmOps.PVCreate(dev); err != nil {
 			ll.Errorf("Unable to create PV for device %s: %v", dev, err)
@@ -284,6 +426,16 @@ func (c *Controller) createSystemLVG(lvg *lvgcrd.LogicalVolumeGroup) (locations
 	return locations, nil
 }
 
+// releaseStandbyDrive removes pre-created partition and FS from standby drive and its standby annotations
+func (c *Controller) releaseStandbyDrive(drive *drivecrd.Drive, device string) error {
+	if err := c.standby.Release(drive, device); err != nil {
+		return err
+	}
+	provisioners.UnmarkStandby(drive)
+	// TODO - Remove context.Background() usage - https://github.com/dell/csi-baremetal/issues/703
+	return c.k8sClient.UpdateCR(context.Background(), drive)
+}
+
 // removeLVGArtifacts removes LogicalVolumeGroup and PVs that doesn't correspond to particular LogicalVolumeGroup
 // when LogicalVolumeGroup is removed all PVs that were in that LogicalVolumeGroup becomes orphans
 func (c *Controller) removeLVGArtifacts(lvgName string) error {
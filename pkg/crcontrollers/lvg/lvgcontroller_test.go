@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"testing"
 	"time"
 
@@ -39,6 +40,7 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lvm"
+	"github.com/dell/csi-baremetal/pkg/base/lvmbackup"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	"github.com/dell/csi-baremetal/pkg/mocks"
 	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
@@ -185,6 +187,7 @@ func TestReconcile_SuccessCreatingLVG(t *testing.T) {
 	listBlk.On("SearchDrivePath", mock.Anything).Return("", nil)
 	lvmOps.On("PVCreate", mock.Anything).Return(nil)
 	lvmOps.On("VGCreate", mock.Anything, mock.Anything).Return(nil)
+	lvmOps.On("GetVGMissingPVs", fLVG.Name).Return(0, nil)
 
 	res, err := c.Reconcile(tCtx, req)
 	assert.Nil(t, err)
@@ -208,6 +211,9 @@ func TestReconcile_LVGHealthBad(t *testing.T) {
 	fLVG.Spec.Health = apiV1.HealthBad
 	fLVG.Finalizers = []string{lvgFinalizer}
 	c := setup(t, node1ID, fLVG)
+	lvmOps := &mocklu.MockWrapLVM{}
+	lvmOps.On("GetVGMissingPVs", fLVG.Name).Return(0, nil)
+	c.lvmOps = lvmOps
 
 	err := c.k8sClient.CreateCR(tCtx, acCR1Name, &acCR1)
 	assert.Nil(t, err)
@@ -219,6 +225,110 @@ func TestReconcile_LVGHealthBad(t *testing.T) {
 	assert.Equal(t, res, ctrl.Result{})
 }
 
+func TestReconcile_MissingPVs(t *testing.T) {
+	var (
+		fLVG   = lvgCR1.DeepCopy()
+		lvmOps = &mocklu.MockWrapLVM{}
+		lvg    = &lvgcrd.LogicalVolumeGroup{}
+	)
+	fLVG.Spec.Status = apiV1.Created
+	fLVG.Finalizers = []string{lvgFinalizer}
+	c := setup(t, node1ID, fLVG)
+	c.lvmOps = lvmOps
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: fLVG.Name}}
+
+	// drive2 isn't found after reboot
+	offlineDrive := &drivecrd.Drive{}
+	assert.Nil(t, c.k8sClient.ReadCR(tCtx, drive2UUID, "", offlineDrive))
+	offlineDrive.Spec.Status = apiV1.DriveStatusOffline
+	assert.Nil(t, c.k8sClient.UpdateCR(tCtx, offlineDrive))
+
+	lvmOps.On("GetVGMissingPVs", fLVG.Name).Return(1, nil)
+	lvmOps.On("VGActivateDegraded", fLVG.Name).Return(nil).Once()
+	_, err := c.Reconcile(tCtx, req)
+	assert.Nil(t, err)
+	assert.Nil(t, c.k8sClient.ReadCR(tCtx, req.Name, "", lvg))
+	assert.Equal(t, "1", lvg.Annotations[apiV1.LVGMissingPVsAnnotation])
+
+	// state is reported already
+	_, err = c.Reconcile(tCtx, req)
+	assert.Nil(t, err)
+	lvmOps.AssertNumberOfCalls(t, "VGActivateDegraded", 1)
+
+	// repair failed
+	lvg.Annotations[apiV1.LVGRepairAnnotation] = apiV1.LVGRepairRemoveMissing
+	assert.Nil(t, c.k8sClient.UpdateCR(tCtx, lvg))
+	lvmOps.On("VGReduceMissing", fLVG.Name).Return(errors.New("partial LVs")).Once()
+	_, err = c.Reconcile(tCtx, req)
+	assert.Nil(t, err)
+	assert.Nil(t, c.k8sClient.ReadCR(tCtx, req.Name, "", lvg))
+	assert.Equal(t, apiV1.LVGRepairFailed, lvg.Annotations[apiV1.LVGRepairAnnotation])
+
+	// repair succeeded
+	lvg.Annotations[apiV1.LVGRepairAnnotation] = apiV1.LVGRepairRemoveMissing
+	assert.Nil(t, c.k8sClient.UpdateCR(tCtx, lvg))
+	lvmOps.On("VGReduceMissing", fLVG.Name).Return(nil).Once()
+	_, err = c.Reconcile(tCtx, req)
+	assert.Nil(t, err)
+	// decoding into the existing object keeps keys of its annotations map
+	lvg = &lvgcrd.LogicalVolumeGroup{}
+	assert.Nil(t, c.k8sClient.ReadCR(tCtx, req.Name, "", lvg))
+	assert.Empty(t, lvg.Annotations[apiV1.LVGMissingPVsAnnotation])
+	assert.Empty(t, lvg.Annotations[apiV1.LVGRepairAnnotation])
+	assert.Equal(t, []string{drive1UUID}, lvg.Spec.Locations)
+}
+
+func TestReconcile_RestoreMetadata(t *testing.T) {
+	var (
+		fLVG   = lvgCR1.DeepCopy()
+		lvmOps = &mocklu.MockWrapLVM{}
+		lvg    = &lvgcrd.LogicalVolumeGroup{}
+	)
+	fLVG.Spec.Status = apiV1.Created
+	fLVG.Finalizers = []string{lvgFinalizer}
+	fLVG.Annotations = map[string]string{apiV1.LVGRepairAnnotation: apiV1.LVGRepairRestoreMetadata}
+	c := setup(t, node1ID, fLVG)
+	c.lvmOps = lvmOps
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: fLVG.Name}}
+
+	// backup is disabled
+	_, err := c.Reconcile(tCtx, req)
+	assert.Nil(t, err)
+	assert.Nil(t, c.k8sClient.ReadCR(tCtx, req.Name, "", lvg))
+	assert.Equal(t, apiV1.LVGRepairFailed, lvg.Annotations[apiV1.LVGRepairAnnotation])
+
+	lvmOps.On("GetVGSeqNo", fLVG.Name).Return(int64(7), nil).Once()
+	lvmOps.On("VGCfgBackup", fLVG.Name, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		assert.Nil(t, ioutil.WriteFile(args.String(1), []byte("metadata"), 0600))
+	}).Once()
+	archive := lvmbackup.NewArchive(c.k8sClient, lvmOps, node1ID, 1, testLogger)
+	assert.Nil(t, archive.Backup(tCtx, []string{fLVG.Name}))
+	c.SetMetadataArchive(archive)
+
+	// backup with seqno isn't found
+	lvg.Annotations[apiV1.LVGRepairAnnotation] = apiV1.LVGRepairRestoreMetadata
+	lvg.Annotations[apiV1.LVGRestoreMetadataSeqNoAnnotation] = "6"
+	assert.Nil(t, c.k8sClient.UpdateCR(tCtx, lvg))
+	_, err = c.Reconcile(tCtx, req)
+	assert.Nil(t, err)
+	assert.Nil(t, c.k8sClient.ReadCR(tCtx, req.Name, "", lvg))
+	assert.Equal(t, apiV1.LVGRepairFailed, lvg.Annotations[apiV1.LVGRepairAnnotation])
+
+	// the latest backup is restored
+	lvg.Annotations[apiV1.LVGRepairAnnotation] = apiV1.LVGRepairRestoreMetadata
+	delete(lvg.Annotations, apiV1.LVGRestoreMetadataSeqNoAnnotation)
+	assert.Nil(t, c.k8sClient.UpdateCR(tCtx, lvg))
+	lvmOps.On("VGCfgRestore", fLVG.Name, mock.Anything).Return(nil).Once()
+	_, err = c.Reconcile(tCtx, req)
+	assert.Nil(t, err)
+	// decoding into the existing object keeps keys of its annotations map
+	lvg = &lvgcrd.LogicalVolumeGroup{}
+	assert.Nil(t, c.k8sClient.ReadCR(tCtx, req.Name, "", lvg))
+	assert.Empty(t, lvg.Annotations[apiV1.LVGRepairAnnotation])
+	lvmOps.AssertCalled(t, "VGCfgRestore", fLVG.Name, mock.Anything)
+	lvmOps.AssertNotCalled(t, "GetVGMissingPVs", fLVG.Name)
+}
+
 func TestReconcile_SuccessDeletion(t *testing.T) {
 	var (
 		c   = setup(t, node1ID)
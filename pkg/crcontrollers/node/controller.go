@@ -37,6 +37,7 @@ import (
 
 	api "github.com/dell/csi-baremetal/api/generated/v1"
 	"github.com/dell/csi-baremetal/api/v1/nodecrd"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
 )
@@ -222,7 +223,7 @@ func (bmc *Controller) SetupWithManager(m ctrl.Manager) error {
 				return !annotationAreTheSame || !addressesAreTheSame || !labelsAreTheSame
 			},
 		}).
-		Complete(bmc)
+		Complete(diagnostics.TrackReconciler("node", bmc))
 }
 
 // Reconcile reconciles Node CR and k8s Node objects
@@ -2,11 +2,14 @@ package reservation
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -15,7 +18,11 @@ import (
 	v1api "github.com/dell/csi-baremetal/api/generated/v1"
 	v1 "github.com/dell/csi-baremetal/api/v1"
 	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
 	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
+	"github.com/dell/csi-baremetal/pkg/base/ctrlopts"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	baseerr "github.com/dell/csi-baremetal/pkg/base/error"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	metrics "github.com/dell/csi-baremetal/pkg/metrics/common"
@@ -24,11 +31,6 @@ import (
 const (
 	contextTimeoutSeconds = 60
 
-	// reservation parameters
-	fastDelayEnv       = "RESERVATION_FAST_DELAY"
-	slowDelayEnv       = "RESERVATION_SLOW_DELAY"
-	maxFastAttemptsEnv = "RESERVATION_MAX_FAST_ATTEMPTS"
-
 	defaulFastDelay       = 1500 * time.Millisecond
 	defaulSlowDelay       = 12 * time.Second
 	defaulMaxFastAttempts = 30
@@ -42,6 +44,10 @@ type Controller struct {
 	fastDelay              time.Duration
 	slowDelay              time.Duration
 	maxFastAttempts        uint64
+	// tiers of k8s StorageClasses, nil if tiering is disabled
+	tiers *capacityplanner.StorageClassTiers
+	// whether PVCs might pin volumes to drives with annotation or not
+	drivePinning bool
 }
 
 // NewController creates new instance of Controller structure
@@ -57,6 +63,17 @@ func NewController(client *k8s.KubeClient, log *logrus.Logger, sequentialLVGRese
 	return c
 }
 
+// SetStorageClassTiers enables tiering: drives with low latency are kept for volumes of StorageClasses
+// with high tier until drives allowed for lower tiers are under pressure
+func (c *Controller) SetStorageClassTiers(tiers *capacityplanner.StorageClassTiers) {
+	c.tiers = tiers
+}
+
+// SetDrivePinning enables pinning of volume to the drive with PVC annotation
+func (c *Controller) SetDrivePinning(enabled bool) {
+	c.drivePinning = enabled
+}
+
 // SetupWithManager registers Controller to ControllerManager
 func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -72,7 +89,7 @@ func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
 				int(c.maxFastAttempts),
 			),
 		}).
-		Complete(c)
+		Complete(diagnostics.TrackReconciler("availablecapacityreservation", c))
 }
 
 // Reconcile reconciles ACR custom resources
@@ -114,9 +131,25 @@ func (c *Controller) handleReservationUpdate(ctx context.Context, log *logrus.En
 			capacity := request.CapacityRequest
 			volumes[i] = &v1api.Volume{Id: capacity.Name, Size: capacity.Size, StorageClass: capacity.StorageClass}
 		}
+		if c.drivePinning {
+			if err := c.pinVolumes(ctx, log, reservation, volumes); err != nil {
+				log.Warningf("Unable to pin volumes to drives: %v", err)
+				return c.rejectReservation(ctx, log, reservation)
+			}
+		}
 
 		// TODO: do not read all ACs and ACRs for each request: https://github.com/dell/csi-baremetal/issues/89
-		acReader := capacityplanner.NewACReader(c.client, log, true)
+		// ACs of drives held for DaemonSet drive reservations are hidden from other pods
+		// ACs of drives in pools are hidden from volumes of namespaces and StorageClasses not bound to the pool
+		// ACs of drives with low latency are hidden from volumes of lower tiers until the node is under pressure
+		storageClasses := c.getStorageClassNames(ctx, log, reservation)
+		var acReader capacityplanner.CapacityReader = capacityplanner.NewDriveReservationACReader(log,
+			capacityplanner.NewPoolACReader(log, capacityplanner.NewACReader(c.client, log, true), c.client,
+				reservation.Spec.Namespace, storageClasses),
+			c.getDriveReservation(ctx, log, reservation))
+		if c.tiers != nil {
+			acReader = capacityplanner.NewTierACReader(log, acReader, c.client, c.tiers, storageClasses)
+		}
 		acrReader := capacityplanner.NewACRReader(c.client, log, true)
 		capManager := c.capacityManagerBuilder.GetCapacityManager(log, acReader, acrReader)
 
@@ -150,12 +183,7 @@ func (c *Controller) handleReservationUpdate(ctx context.Context, log *logrus.En
 				return ctrl.Result{Requeue: true}, err
 			}
 		} else {
-			// reject reservation
-			reservation.Spec.Status = v1.ReservationRejected
-			if err := c.client.UpdateCR(ctx, reservation); err != nil {
-				log.Errorf("Unable to reject reservation %s: %v", reservation.Name, err)
-				return ctrl.Result{Requeue: true}, err
-			}
+			return c.rejectReservation(ctx, log, reservation)
 		}
 		log.Infof("CR obtained")
 		return ctrl.Result{}, nil
@@ -165,11 +193,94 @@ func (c *Controller) handleReservationUpdate(ctx context.Context, log *logrus.En
 	}
 }
 
+// rejectReservation sets ACR status to REJECTED, so scheduler extender requests reservation again
+func (c *Controller) rejectReservation(ctx context.Context, log *logrus.Entry,
+	reservation *acrcrd.AvailableCapacityReservation) (ctrl.Result, error) {
+	reservation.Spec.Status = v1.ReservationRejected
+	if err := c.client.UpdateCR(ctx, reservation); err != nil {
+		log.Errorf("Unable to reject reservation %s: %v", reservation.Name, err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// pinVolumes sets location of volumes which PVCs are annotated with pinned drive to location of the drive,
+// returns error if pinned drive can't hold the volume. Name of PVC is equal to volume ID
+func (c *Controller) pinVolumes(ctx context.Context, log *logrus.Entry,
+	reservation *acrcrd.AvailableCapacityReservation, volumes []*v1api.Volume) error {
+	for _, volume := range volumes {
+		pvc := &coreV1.PersistentVolumeClaim{}
+		if err := c.client.ReadCR(ctx, volume.Id, reservation.Spec.Namespace, pvc); err != nil {
+			continue
+		}
+		driveUUID := pvc.GetAnnotations()[v1.PVCAnnotationPinnedDrive]
+		if driveUUID == "" {
+			continue
+		}
+
+		drive := &drivecrd.Drive{}
+		if err := c.client.ReadCR(ctx, driveUUID, "", drive); err != nil {
+			return fmt.Errorf("unable to read drive %s pinned by PVC %s: %v", driveUUID, pvc.Name, err)
+		}
+		if err := capacityplanner.ValidatePinnedDrive(&drive.Spec, volume); err != nil {
+			return fmt.Errorf("volume of PVC %s can't be pinned: %v", pvc.Name, err)
+		}
+		lvgList := &lvgcrd.LogicalVolumeGroupList{}
+		if err := c.client.ReadListByIndex(ctx, lvgList, k8s.NodeIDIndex, drive.Spec.NodeId); err != nil {
+			return fmt.Errorf("unable to read LVG list: %v", err)
+		}
+		volume.Location = capacityplanner.PinnedLocation(driveUUID, lvgList.Items)
+		log.Infof("Volume of PVC %s is pinned to drive %s on node %s, location %s", pvc.Name, driveUUID,
+			drive.Spec.NodeId, volume.Location)
+	}
+	return nil
+}
+
+// getDriveReservation returns name of DaemonSet drive reservation of the pod which requested ACR,
+// empty if pod doesn't have drive reservation label
+func (c *Controller) getDriveReservation(ctx context.Context, log *logrus.Entry,
+	reservation *acrcrd.AvailableCapacityReservation) string {
+	// ACR name is <pod namespace>-<pod name>
+	namespace := reservation.Spec.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	podName := strings.TrimPrefix(reservation.Name, namespace+"-")
+
+	pod := &coreV1.Pod{}
+	if err := c.client.ReadCR(ctx, podName, namespace, pod); err != nil {
+		log.Debugf("Unable to read pod %s/%s: %v", namespace, podName, err)
+		return ""
+	}
+	return pod.GetLabels()[v1.PodLabelDriveReservation]
+}
+
+// getStorageClassNames returns names of StorageClasses of PVCs requested in ACR, name of PVC is equal to
+// capacity request name. Name is empty for inline volume or if PVC can't be read
+func (c *Controller) getStorageClassNames(ctx context.Context, log *logrus.Entry,
+	reservation *acrcrd.AvailableCapacityReservation) []string {
+	names := make([]string, 0, len(reservation.Spec.ReservationRequests))
+	for _, request := range reservation.Spec.ReservationRequests {
+		pvc := &coreV1.PersistentVolumeClaim{}
+		if err := c.client.ReadCR(ctx, request.CapacityRequest.Name, reservation.Spec.Namespace, pvc); err != nil {
+			log.Debugf("Unable to read PVC %s/%s: %v", reservation.Spec.Namespace, request.CapacityRequest.Name, err)
+			names = append(names, "")
+			continue
+		}
+		name := ""
+		if pvc.Spec.StorageClassName != nil {
+			name = *pvc.Spec.StorageClassName
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
 func (c *Controller) setReservationParameters() {
 	var (
-		fastDelayStr       = os.Getenv(fastDelayEnv)
-		slowDelayStr       = os.Getenv(slowDelayEnv)
-		maxFastAttemptsStr = os.Getenv(maxFastAttemptsEnv)
+		fastDelayStr       = os.Getenv(ctrlopts.ReservationFastDelayEnv)
+		slowDelayStr       = os.Getenv(ctrlopts.ReservationSlowDelayEnv)
+		maxFastAttemptsStr = os.Getenv(ctrlopts.ReservationMaxFastAttemptsEnv)
 
 		fastDelay       time.Duration
 		slowDelay       time.Duration
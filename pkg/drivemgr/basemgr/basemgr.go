@@ -28,7 +28,11 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base/command"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsscsi"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/nvmecli"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/sgses"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/smartctl"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/sysblock"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/identitycache"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/smarttrend"
 )
 
 // BaseManager is a drive manager based on Linux system utils
@@ -38,6 +42,15 @@ type BaseManager struct {
 	lsscsi   lsscsi.WrapLsscsi
 	smartctl smartctl.WrapSmartctl
 	nvme     nvmecli.WrapNvmecli
+	sysBlock sysblock.WrapSysBlock
+	// trend is used for failure prediction based on SMART history, nil if prediction is disabled
+	trend *smarttrend.Store
+	// ses is used for discovery of enclosure slots of drives, nil if discovery is disabled
+	ses sgses.WrapSgSes
+	// identity is used instead of per-device commands on cold start, nil if cache is disabled
+	identity *identitycache.Cache
+	// sysBlockDir is a sysfs directory with block devices, WWID of SCSI devices is read from it
+	sysBlockDir string
 }
 
 // GetDrivesList gets api.Drive slice using Linux system utils
@@ -46,6 +59,7 @@ func (mgr BaseManager) GetDrivesList() ([]*api.Drive, error) {
 	var (
 		devices    []*api.Drive
 		nvmDevices []*api.Drive
+		sysDevices []*api.Drive
 		err        error
 	)
 	if devices, err = mgr.GetSCSIDevices(); err != nil {
@@ -54,7 +68,12 @@ func (mgr BaseManager) GetDrivesList() ([]*api.Drive, error) {
 	if nvmDevices, err = mgr.GetNVMDevices(); err != nil {
 		ll.Errorf("Failed to initialize devices, Error: %v", err)
 	}
+	if sysDevices, err = mgr.GetSysBlockDevices(); err != nil {
+		ll.Errorf("Failed to initialize devices, Error: %v", err)
+	}
 	devices = append(devices, nvmDevices...)
+	devices = append(devices, sysDevices...)
+	mgr.retainIdentities(devices)
 	return devices, nil
 }
 
@@ -72,12 +91,33 @@ func (mgr *BaseManager) LocateNode(action int32) error {
 // New is a constructor BaseManager
 func New(exec command.CmdExecutor, logger *logrus.Logger) *BaseManager {
 	return &BaseManager{
-		exec:     exec,
-		log:      logger.WithField("component", "BaseManager"),
-		lsscsi:   lsscsi.NewLSSCSI(exec, logger),
-		smartctl: smartctl.NewSMARTCTL(exec),
-		nvme:     nvmecli.NewNVMECLI(exec, logger),
+		exec:        exec,
+		log:         logger.WithField("component", "BaseManager"),
+		lsscsi:      lsscsi.NewLSSCSI(exec, logger),
+		smartctl:    smartctl.NewSMARTCTL(exec),
+		nvme:        nvmecli.NewNVMECLI(exec, logger),
+		sysBlock:    sysblock.NewSysBlockReader(logger),
+		sysBlockDir: sysblock.SysBlock,
+	}
+}
+
+// SetSMARTTrendStore enables drive failure prediction based on SMART history which is kept in the store
+func (mgr *BaseManager) SetSMARTTrendStore(store *smarttrend.Store) *BaseManager {
+	mgr.trend = store
+	return mgr
+}
+
+// predictHealth returns HealthSuspect if health is good but SMART trend predicts drive failure
+func (mgr *BaseManager) predictHealth(drive *api.Drive, sample smarttrend.Sample) string {
+	if mgr.trend == nil || drive.Health != apiV1.HealthGood {
+		return drive.Health
 	}
+	if suspect, reason := mgr.trend.Observe(drive.SerialNumber, sample); suspect {
+		mgr.log.WithField("method", "predictHealth").
+			Warnf("Drive %s is predicted to fail: %s, set health as %s", drive.SerialNumber, reason, apiV1.HealthSuspect)
+		return apiV1.HealthSuspect
+	}
+	return drive.Health
 }
 
 // GetSCSIDevices get []*api.Drive using lsscsi system util
@@ -100,6 +140,15 @@ func (mgr *BaseManager) GetSCSIDevices() ([]*api.Drive, error) {
 	}
 	devices := make([]*api.Drive, 0)
 	for i, device := range allDevices {
+		fingerprint := mgr.scsiFingerprint(device)
+		if entry, ok := mgr.cachedIdentity(device.Path, fingerprint); ok {
+			device.SerialNumber = entry.SerialNumber
+			device.Type = entry.Type
+			device.Health = entry.Health
+			device.Temperature = entry.Temperature
+			devices = append(devices, device)
+			continue
+		}
 		smartInfo, err := mgr.smartctl.GetDriveInfoByPath(device.Path)
 		if err != nil {
 			// We don't fail whole drivemgr because of error with just one device, we don't add it in allDevices slice
@@ -117,12 +166,20 @@ func (mgr *BaseManager) GetSCSIDevices() ([]*api.Drive, error) {
 				} else {
 					allDevices[i].Health = apiV1.HealthBad
 				}
+				allDevices[i].Temperature = smartInfo.Temperature.Current
+				allDevices[i].Health = mgr.predictHealth(allDevices[i], smarttrend.Sample{
+					ReallocatedSectors: smartInfo.ReallocatedSectors(),
+					PendingSectors:     smartInfo.PendingSectors(),
+					WearUsed:           smartInfo.WearUsed(),
+				})
+				mgr.storeIdentity(fingerprint, allDevices[i])
 				devices = append(devices, allDevices[i])
 			} else {
 				ll.Errorf("Device has empty VID, PID or SN field: %v", allDevices[i])
 			}
 		}
 	}
+	mgr.fillSlots(devices)
 	return devices, nil
 }
 
@@ -130,14 +187,14 @@ func (mgr *BaseManager) GetSCSIDevices() ([]*api.Drive, error) {
 func (mgr *BaseManager) GetNVMDevices() ([]*api.Drive, error) {
 	ll := mgr.log.WithField("method", "GetNVMDevices")
 	devices := make([]*api.Drive, 0)
-	nvmeDevices, err := mgr.nvme.GetNVMDevices()
+	nvmeDevices, cached, err := mgr.getNVMDevices()
 	if err != nil {
 		ll.Errorf("Failed to get NVMe devices, Error: %v", err)
 		return nil, err
 	}
 	for _, device := range nvmeDevices {
 		if device.Vendor != 0 && device.ModelNumber != "" && device.SerialNumber != "" {
-			devices = append(devices, &api.Drive{
+			drive := &api.Drive{
 				Health:       device.Health,
 				PID:          device.ModelNumber,
 				VID:          strconv.Itoa(device.Vendor),
@@ -146,10 +203,70 @@ func (mgr *BaseManager) GetNVMDevices() ([]*api.Drive, error) {
 				Size:         device.PhysicalSize,
 				Firmware:     device.Firmware,
 				Path:         device.DevicePath,
-			})
+			}
+			if entry, ok := cached[device.DevicePath]; ok {
+				drive.Temperature = entry.Temperature
+				devices = append(devices, drive)
+				continue
+			}
+			if device.SMARTLog != nil {
+				drive.Temperature = device.SMARTLog.TemperatureCelsius()
+				drive.Health = mgr.predictHealth(drive, smarttrend.Sample{
+					ReallocatedSectors: device.SMARTLog.MediaErrors,
+					PendingSectors:     smarttrend.UnknownValue,
+					WearUsed:           device.SMARTLog.PercentUsed,
+				})
+			}
+			mgr.storeIdentity(nvmeFingerprint(&device), drive)
+			devices = append(devices, drive)
 		} else {
 			ll.Errorf("Device has empty VID, PID or SN field: %v", device)
 		}
 	}
 	return devices, nil
 }
+
+// GetSysBlockDevices get []*api.Drive of MMC/eMMC and virtio-blk drives from sysfs
+// SMART isn't available for such drives, health is based on life time estimation of eMMC
+func (mgr *BaseManager) GetSysBlockDevices() ([]*api.Drive, error) {
+	ll := mgr.log.WithField("method", "GetSysBlockDevices")
+	devices := make([]*api.Drive, 0)
+	sysDevices, err := mgr.sysBlock.GetDevices()
+	if err != nil {
+		ll.Errorf("Failed to get MMC and virtio devices, Error: %v", err)
+		return nil, err
+	}
+	for _, device := range sysDevices {
+		if device.Serial == "" {
+			// serial number of virtio drive is set by hypervisor, e.g. with serial property of QEMU drive
+			ll.Errorf("Device %s (%s) has empty SN field", device.Path, device.Class)
+			continue
+		}
+		drive := &api.Drive{
+			Health:       apiV1.HealthGood,
+			PID:          device.Model,
+			VID:          device.Vendor,
+			SerialNumber: device.Serial,
+			Type:         apiV1.DriveTypeSSD,
+			Size:         device.Size,
+			Firmware:     device.Firmware,
+			Path:         device.Path,
+		}
+		if device.Rotational {
+			drive.Type = apiV1.DriveTypeHDD
+		}
+		if device.EOL {
+			ll.Warnf("Drive %s reached end of life, set health as %s", device.Serial, apiV1.HealthSuspect)
+			drive.Health = apiV1.HealthSuspect
+		}
+		if device.Wear != sysblock.UnknownWear {
+			drive.Health = mgr.predictHealth(drive, smarttrend.Sample{
+				ReallocatedSectors: smarttrend.UnknownValue,
+				PendingSectors:     smarttrend.UnknownValue,
+				WearUsed:           device.Wear,
+			})
+		}
+		devices = append(devices, drive)
+	}
+	return devices, nil
+}
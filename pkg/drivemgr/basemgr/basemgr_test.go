@@ -24,10 +24,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	api "github.com/dell/csi-baremetal/api/generated/v1"
 	apiV1 "github.com/dell/csi-baremetal/api/v1"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsscsi"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/nvmecli"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/sgses"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/smartctl"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/sysblock"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/smarttrend"
 	"github.com/dell/csi-baremetal/pkg/mocks"
 	"github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
 )
@@ -306,3 +310,108 @@ func TestLoopBackManager_GetDrivesListSuccess(t *testing.T) {
 
 	assert.Nil(t, err)
 }
+
+func TestBaseManager_GetNVMDevicesPredictedFailure(t *testing.T) {
+	var (
+		mockexec = &mocks.GoMockExecutor{}
+		manager  = New(mockexec, logger).SetSMARTTrendStore(smarttrend.NewStore("", smarttrend.DefaultConfig(), logger))
+		mockNvme = &linuxutils.MockWrapNvmecli{}
+	)
+	nvmeDevice := []nvmecli.NVMDevice{{
+		DevicePath:   "testPath",
+		ModelNumber:  "testModel",
+		SerialNumber: "testSN",
+		Vendor:       2311,
+		Health:       apiV1.HealthGood,
+		SMARTLog:     &nvmecli.SMARTLog{PercentUsed: 99},
+	}}
+	mockNvme.On("GetNVMDevices", mock.Anything).Return(nvmeDevice, nil).Once()
+
+	manager.nvme = mockNvme
+	devices, err := manager.GetNVMDevices()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(devices))
+	assert.Equal(t, apiV1.HealthSuspect, devices[0].Health)
+}
+
+func TestBaseManager_GetSysBlockDevices(t *testing.T) {
+	var (
+		manager      = New(&mocks.GoMockExecutor{}, logger)
+		mockSysBlock = &linuxutils.MockWrapSysBlock{}
+	)
+	sysDevices := []sysblock.Device{
+		{Path: "/dev/mmcblk0", Class: sysblock.ClassMMC, Serial: "1F2E3D4C", Vendor: "000045", Model: "DG4016",
+			Firmware: "1", Size: 1000, Wear: 30},
+		{Path: "/dev/mmcblk1", Class: sysblock.ClassMMC, Serial: "0000ABCD", Vendor: "000003", Model: "SD64G",
+			Size: 1000, Wear: sysblock.UnknownWear, EOL: true},
+		{Path: "/dev/vda", Class: sysblock.ClassVirtio, Serial: "vol-0123", Vendor: sysblock.VirtioVendorID,
+			Model: sysblock.VirtioModel, Size: 2000, Rotational: true, Wear: sysblock.UnknownWear},
+		// serial isn't set by hypervisor
+		{Path: "/dev/vdb", Class: sysblock.ClassVirtio, Vendor: sysblock.VirtioVendorID,
+			Model: sysblock.VirtioModel, Size: 2000, Wear: sysblock.UnknownWear},
+	}
+	mockSysBlock.On("GetDevices").Return(sysDevices, nil).Once()
+	manager.sysBlock = mockSysBlock
+
+	devices, err := manager.GetSysBlockDevices()
+	assert.Nil(t, err)
+	assert.Equal(t, []*api.Drive{
+		{Path: "/dev/mmcblk0", SerialNumber: "1F2E3D4C", VID: "000045", PID: "DG4016", Firmware: "1", Size: 1000,
+			Type: apiV1.DriveTypeSSD, Health: apiV1.HealthGood},
+		{Path: "/dev/mmcblk1", SerialNumber: "0000ABCD", VID: "000003", PID: "SD64G", Size: 1000,
+			Type: apiV1.DriveTypeSSD, Health: apiV1.HealthSuspect},
+		{Path: "/dev/vda", SerialNumber: "vol-0123", VID: sysblock.VirtioVendorID, PID: sysblock.VirtioModel,
+			Size: 2000, Type: apiV1.DriveTypeHDD, Health: apiV1.HealthGood},
+	}, devices)
+
+	mockSysBlock.On("GetDevices").Return(nil, fmt.Errorf("error")).Once()
+	_, err = manager.GetSysBlockDevices()
+	assert.NotNil(t, err)
+}
+
+func TestBaseManager_GetSysBlockDevicesPredictedFailure(t *testing.T) {
+	var (
+		manager = New(&mocks.GoMockExecutor{}, logger).
+			SetSMARTTrendStore(smarttrend.NewStore("", smarttrend.DefaultConfig(), logger))
+		mockSysBlock = &linuxutils.MockWrapSysBlock{}
+	)
+	mockSysBlock.On("GetDevices").Return([]sysblock.Device{{Path: "/dev/mmcblk0", Class: sysblock.ClassMMC,
+		Serial: "1F2E3D4C", Vendor: "000045", Model: "DG4016", Wear: 100}}, nil).Once()
+	manager.sysBlock = mockSysBlock
+
+	devices, err := manager.GetSysBlockDevices()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(devices))
+	assert.Equal(t, apiV1.HealthSuspect, devices[0].Health)
+}
+
+func TestBaseManager_fillSlots(t *testing.T) {
+	var (
+		manager = New(&mocks.GoMockExecutor{}, logger)
+		mockSes = &linuxutils.MockWrapSgSes{}
+		drives  = []*api.Drive{{Path: "/dev/sda"}, {Path: "/dev/sdb"}, {Path: "/dev/sdc"}}
+	)
+	// discovery is disabled
+	manager.fillSlots(drives)
+	assert.Empty(t, drives[0].Slot)
+
+	manager.SetEnclosureServices(mockSes)
+	mockSes.On("GetSlots").Return([]sgses.Slot{
+		{Enclosure: "500056b3d2e4b300", Number: 7, SASAddresses: []string{"0x5000c500a1b2c3e1", "0x5000c500a1b2c3e2"}},
+	}, nil).Once()
+	mockSes.On("GetSASAddress", "/dev/sda").Return("0x5000c500a1b2c3e2", nil)
+	mockSes.On("GetSASAddress", "/dev/sdb").Return("0x5000c500a1b2c3f0", nil)
+	mockSes.On("GetSASAddress", "/dev/sdc").Return("", fmt.Errorf("not SAS device"))
+	manager.fillSlots(drives)
+	assert.Equal(t, "500056b3d2e4b300", drives[0].Enclosure)
+	assert.Equal(t, "7", drives[0].Slot)
+	assert.Empty(t, drives[1].Slot)
+	assert.Empty(t, drives[2].Slot)
+
+	// drives are left as is if enclosures can't be read
+	drives = []*api.Drive{{Path: "/dev/sda"}}
+	mockSes.On("GetSlots").Return([]sgses.Slot{}, fmt.Errorf("error")).Once()
+	manager.fillSlots(drives)
+	assert.Empty(t, drives[0].Slot)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package basemgr
+
+import (
+	"strconv"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/sgses"
+)
+
+// SetEnclosureServices enables discovery of enclosure and slot numbers of SCSI drives with SES
+func (mgr *BaseManager) SetEnclosureServices(ses sgses.WrapSgSes) *BaseManager {
+	mgr.ses = ses
+	return mgr
+}
+
+// fillSlots sets Enclosure and Slot of drives which are located in SES enclosures of the node
+// Drive is matched with slot by SAS address, drives which aren't found in enclosures are left as is
+func (mgr *BaseManager) fillSlots(drives []*api.Drive) {
+	if mgr.ses == nil || len(drives) == 0 {
+		return
+	}
+	ll := mgr.log.WithField("method", "fillSlots")
+	slots, err := mgr.ses.GetSlots()
+	if err != nil {
+		ll.Errorf("Failed to discover enclosure slots: %v", err)
+		return
+	}
+	bySASAddress := make(map[string]sgses.Slot)
+	for _, slot := range slots {
+		for _, address := range slot.SASAddresses {
+			bySASAddress[address] = slot
+		}
+	}
+	for _, drive := range drives {
+		address, err := mgr.ses.GetSASAddress(drive.Path)
+		if err != nil {
+			ll.Debugf("SAS address of drive %s isn't found: %v", drive.Path, err)
+			continue
+		}
+		if slot, ok := bySASAddress[address]; ok {
+			drive.Enclosure = slot.Enclosure
+			drive.Slot = strconv.Itoa(slot.Number)
+		} else {
+			ll.Debugf("Drive %s with SAS address %s isn't found in enclosures", drive.Path, address)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package basemgr
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/nvmecli"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/identitycache"
+)
+
+// SetIdentityCache enables cache of drive identity and SMART baseline which is used on cold start
+// instead of smartctl and nvme commands for every device
+func (mgr *BaseManager) SetIdentityCache(cache *identitycache.Cache) *BaseManager {
+	mgr.identity = cache
+	return mgr
+}
+
+// cachedIdentity returns cached identity of device, false if cache is disabled or doesn't have valid entry
+func (mgr *BaseManager) cachedIdentity(path, fingerprint string) (identitycache.Entry, bool) {
+	if mgr.identity == nil {
+		return identitycache.Entry{}, false
+	}
+	return mgr.identity.Get(path, fingerprint)
+}
+
+// storeIdentity stores identity of drive which was read by per-device commands
+func (mgr *BaseManager) storeIdentity(fingerprint string, drive *api.Drive) {
+	if mgr.identity == nil {
+		return
+	}
+	mgr.identity.Put(drive.Path, identitycache.Entry{
+		Fingerprint:  fingerprint,
+		SerialNumber: drive.SerialNumber,
+		VID:          drive.VID,
+		Type:         drive.Type,
+		Health:       drive.Health,
+		Temperature:  drive.Temperature,
+	})
+}
+
+// retainIdentities persists cache with entries of discovered drives only
+func (mgr *BaseManager) retainIdentities(drives []*api.Drive) {
+	if mgr.identity == nil {
+		return
+	}
+	paths := make([]string, 0, len(drives))
+	for _, drive := range drives {
+		paths = append(paths, drive.Path)
+	}
+	mgr.identity.Retain(paths)
+}
+
+// scsiFingerprint returns WWID of SCSI device from sysfs with vendor, model, firmware and size
+// Returns empty string if WWID isn't available, such device is always queried with smartctl
+func (mgr *BaseManager) scsiFingerprint(drive *api.Drive) string {
+	data, err := ioutil.ReadFile(filepath.Join(mgr.sysBlockDir, filepath.Base(drive.Path), "device", "wwid"))
+	wwid := strings.TrimSpace(string(data))
+	if err != nil || wwid == "" {
+		return ""
+	}
+	return strings.Join([]string{wwid, drive.VID, drive.PID, drive.Firmware, strconv.FormatInt(drive.Size, 10)}, "/")
+}
+
+// nvmeFingerprint returns serial number of NVMe device with model, firmware and size from nvme list
+func nvmeFingerprint(device *nvmecli.NVMDevice) string {
+	if device.SerialNumber == "" {
+		return ""
+	}
+	return strings.Join([]string{device.SerialNumber, device.ModelNumber, device.Firmware,
+		strconv.FormatInt(device.PhysicalSize, 10)}, "/")
+}
+
+// getNVMDevices returns NVMe devices, devices with cached identity aren't queried with per-device nvme commands
+// Returns devices, cached entries by device path and error
+func (mgr *BaseManager) getNVMDevices() ([]nvmecli.NVMDevice, map[string]identitycache.Entry, error) {
+	if mgr.identity == nil {
+		devices, err := mgr.nvme.GetNVMDevices()
+		return devices, nil, err
+	}
+	devices, err := mgr.nvme.ListNVMDevices()
+	if err != nil {
+		return nil, nil, err
+	}
+	cached := make(map[string]identitycache.Entry)
+	for i := range devices {
+		if entry, ok := mgr.cachedIdentity(devices[i].DevicePath, nvmeFingerprint(&devices[i])); ok {
+			if vendor, err := strconv.Atoi(entry.VID); err == nil {
+				devices[i].Vendor = vendor
+				devices[i].Health = entry.Health
+				cached[devices[i].DevicePath] = entry
+				continue
+			}
+		}
+		mgr.nvme.FillNVMDevice(&devices[i])
+	}
+	return devices, cached, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package basemgr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsscsi"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/nvmecli"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/smartctl"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/identitycache"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	"github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+func TestBaseManager_IdentityCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "basemgr")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	cachePath := filepath.Join(dir, "identity.json")
+	sysBlockDir := filepath.Join(dir, "block")
+	assert.Nil(t, os.MkdirAll(filepath.Join(sysBlockDir, "sda", "device"), 0750))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(sysBlockDir, "sda", "device", "wwid"), []byte("naa.5000c500\n"), 0600))
+
+	scsiDevices := []*lsscsi.SCSIDevice{
+		{ID: "[0:0:0:1]", Path: "/dev/sda", Size: 1000, Vendor: "vendor", Model: "model", Firmware: "fw"},
+		// device without WWID is always queried
+		{ID: "[0:0:0:2]", Path: "/dev/sdb", Size: 1000, Vendor: "vendor", Model: "model", Firmware: "fw"},
+	}
+	nvmeDevice := nvmecli.NVMDevice{DevicePath: "/dev/nvme0n1", ModelNumber: "nvme-model", SerialNumber: "nvme-sn",
+		Firmware: "fw", PhysicalSize: 2000}
+	filledNVMeDevice := nvmeDevice
+	filledNVMeDevice.Vendor = 2311
+	filledNVMeDevice.Health = apiV1.HealthGood
+
+	newManager := func() (*BaseManager, *linuxutils.MockWrapSmartctl, *linuxutils.MockWrapNvmecli) {
+		var (
+			manager      = New(&mocks.GoMockExecutor{}, logger)
+			mockLsscsi   = &linuxutils.MockWrapLsscsi{}
+			mockSmartctl = &linuxutils.MockWrapSmartctl{}
+			mockNvme     = &linuxutils.MockWrapNvmecli{}
+			mockSysBlock = &linuxutils.MockWrapSysBlock{}
+		)
+		mockLsscsi.On("GetSCSIDevices").Return(scsiDevices, nil)
+		mockNvme.On("ListNVMDevices").Return([]nvmecli.NVMDevice{nvmeDevice}, nil)
+		mockNvme.On("FillNVMDevice", "/dev/nvme0n1").Return(filledNVMeDevice)
+		mockSysBlock.On("GetDevices").Return(nil, nil)
+		for _, path := range []string{"/dev/sda", "/dev/sdb"} {
+			mockSmartctl.On("GetDriveInfoByPath", path).Return(&smartctl.DeviceSMARTInfo{
+				SerialNumber: "sn" + path,
+				SmartStatus:  map[string]bool{"passed": true},
+				Rotation:     7200,
+			}, nil)
+		}
+		manager.lsscsi, manager.smartctl, manager.nvme, manager.sysBlock = mockLsscsi, mockSmartctl, mockNvme, mockSysBlock
+		manager.sysBlockDir = sysBlockDir
+		manager.SetIdentityCache(identitycache.NewCache(cachePath, time.Hour, logger))
+		return manager, mockSmartctl, mockNvme
+	}
+
+	// the first start, all devices are queried
+	manager, mockSmartctl, mockNvme := newManager()
+	drives, err := manager.GetDrivesList()
+	assert.Nil(t, err)
+	assert.Len(t, drives, 3)
+	mockSmartctl.AssertNumberOfCalls(t, "GetDriveInfoByPath", 2)
+	mockNvme.AssertNumberOfCalls(t, "FillNVMDevice", 1)
+
+	// restart, cached drives aren't queried
+	manager, mockSmartctl, mockNvme = newManager()
+	cachedDrives, err := manager.GetDrivesList()
+	assert.Nil(t, err)
+	assert.Equal(t, drives, cachedDrives)
+	mockSmartctl.AssertNumberOfCalls(t, "GetDriveInfoByPath", 1)
+	mockSmartctl.AssertCalled(t, "GetDriveInfoByPath", "/dev/sdb")
+	mockNvme.AssertNumberOfCalls(t, "FillNVMDevice", 0)
+
+	// the next discovery queries all devices
+	_, err = manager.GetDrivesList()
+	assert.Nil(t, err)
+	mockSmartctl.AssertNumberOfCalls(t, "GetDriveInfoByPath", 3)
+	mockNvme.AssertNumberOfCalls(t, "FillNVMDevice", 1)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package credentials provides credentials of out-of-band drive manager backends (Redfish, storcli)
+// which are stored in kubernetes Secrets
+package credentials
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Keys of kubernetes Secret which is mounted to drive manager container
+const (
+	UsernameKey = "username"
+	PasswordKey = "password"
+	// EndpointKey is a default endpoint of backend, for example BMC address
+	EndpointKey = "endpoint"
+	// nodeGroupEndpointKeyPrefix is a prefix of per-nodegroup endpoint key, for example endpoint.rack-1
+	nodeGroupEndpointKeyPrefix = EndpointKey + "."
+)
+
+// Credentials contains user's credentials and endpoint of backend
+type Credentials struct {
+	Username string
+	Password string
+	// Endpoint might be empty, in this case drive manager detects it by itself
+	Endpoint string
+}
+
+// Provider provides actual credentials of backend
+type Provider interface {
+	GetCredentials() (Credentials, error)
+}
+
+// SecretProvider reads credentials from kubernetes Secret mounted as a volume.
+// Kubelet updates mounted Secret atomically, so credentials are read on each call to support rotation
+type SecretProvider struct {
+	dir       string
+	nodeGroup string
+	log       *logrus.Entry
+
+	mu      sync.Mutex
+	current Credentials
+}
+
+// NewSecretProvider is a constructor for SecretProvider struct
+// Receives directory where Secret is mounted, node group of current node (might be empty) and logrus logger
+func NewSecretProvider(dir, nodeGroup string, logger *logrus.Logger) *SecretProvider {
+	return &SecretProvider{
+		dir:       dir,
+		nodeGroup: nodeGroup,
+		log:       logger.WithField("component", "SecretProvider"),
+	}
+}
+
+// GetCredentials reads credentials from mounted Secret
+// Endpoint for node group has priority over default endpoint
+// Returns Credentials or error if username or password are missing
+func (s *SecretProvider) GetCredentials() (Credentials, error) {
+	ll := s.log.WithField("method", "GetCredentials")
+
+	var (
+		creds Credentials
+		err   error
+	)
+	if creds.Username, err = s.readKey(UsernameKey); err != nil || creds.Username == "" {
+		return Credentials{}, fmt.Errorf("username isn't found in %s: %v", s.dir, err)
+	}
+	if creds.Password, err = s.readKey(PasswordKey); err != nil || creds.Password == "" {
+		return Credentials{}, fmt.Errorf("password isn't found in %s: %v", s.dir, err)
+	}
+	if s.nodeGroup != "" {
+		creds.Endpoint, _ = s.readKey(nodeGroupEndpointKeyPrefix + s.nodeGroup)
+	}
+	if creds.Endpoint == "" {
+		creds.Endpoint, _ = s.readKey(EndpointKey)
+	}
+
+	s.mu.Lock()
+	if s.current != creds {
+		if s.current.Username != "" {
+			ll.Infof("Credentials are rotated, user %s, endpoint %s", creds.Username, creds.Endpoint)
+		}
+		s.current = creds
+	}
+	s.mu.Unlock()
+	return creds, nil
+}
+
+// readKey reads value of Secret key from file, returns empty string if key doesn't exist
+func (s *SecretProvider) readKey(key string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// StaticProvider returns the same credentials on each call, is used for backward compatibility
+type StaticProvider Credentials
+
+// GetCredentials returns static credentials
+func (s StaticProvider) GetCredentials() (Credentials, error) {
+	return Credentials(s), nil
+}
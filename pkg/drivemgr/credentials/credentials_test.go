@@ -0,0 +1,66 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKey(t *testing.T, dir, key, value string) {
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, key), []byte(value), 0600))
+}
+
+func TestSecretProvider_GetCredentials(t *testing.T) {
+	dir := t.TempDir()
+	p := NewSecretProvider(dir, "rack-1", logrus.New())
+
+	// secret is empty
+	_, err := p.GetCredentials()
+	assert.NotNil(t, err)
+
+	writeKey(t, dir, UsernameKey, "root\n")
+	_, err = p.GetCredentials()
+	assert.NotNil(t, err)
+
+	writeKey(t, dir, PasswordKey, "passwd\n")
+	creds, err := p.GetCredentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{Username: "root", Password: "passwd"}, creds)
+
+	writeKey(t, dir, EndpointKey, "10.10.10.10")
+	creds, err = p.GetCredentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "10.10.10.10", creds.Endpoint)
+
+	// per-nodegroup endpoint and rotated password
+	writeKey(t, dir, nodeGroupEndpointKeyPrefix+"rack-1", "10.10.10.11")
+	writeKey(t, dir, PasswordKey, "new-passwd")
+	creds, err = p.GetCredentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{Username: "root", Password: "new-passwd", Endpoint: "10.10.10.11"}, creds)
+}
+
+func TestStaticProvider_GetCredentials(t *testing.T) {
+	creds, err := StaticProvider{Username: "user", Password: "password"}.GetCredentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "user", creds.Username)
+}
@@ -29,3 +29,10 @@ type DriveManager interface {
 	// LocateNode manipulates of node's led state, which should be synced with drive's led
 	LocateNode(action int32) error
 }
+
+// InfoProvider is an optional interface for drive managers which report own name, version and capabilities
+// over DriveService GetInfo method. Out-of-tree drive managers implement GetInfo of gRPC API directly
+type InfoProvider interface {
+	// GetInfo returns description of drive manager, API version is filled by DriveServiceServerImpl
+	GetInfo() *api.DriveManagerInfo
+}
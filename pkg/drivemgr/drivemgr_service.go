@@ -25,6 +25,7 @@ import (
 
 	api "github.com/dell/csi-baremetal/api/generated/v1"
 	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base"
 )
 
 // DriveServiceServerImpl is the implementation of gRPC server that gives possibility to invoke DriveManager's methods
@@ -85,3 +86,18 @@ func (svc *DriveServiceServerImpl) LocateNode(ctx context.Context, req *api.Node
 	}
 	return new(api.Empty), nil
 }
+
+// GetInfo returns DriveService API version, name, version and capabilities of DriveManager
+// DriveManager could customize response by implementing InfoProvider interface
+func (svc *DriveServiceServerImpl) GetInfo(ctx context.Context, req *api.Empty) (*api.DriveManagerInfo, error) {
+	info := &api.DriveManagerInfo{
+		Name:         base.PluginName,
+		Version:      base.PluginVersion,
+		Capabilities: []string{apiV1.DriveMgrCapabilityLocate, apiV1.DriveMgrCapabilityLocateNode},
+	}
+	if provider, ok := svc.mgr.(InfoProvider); ok {
+		info = provider.GetInfo()
+	}
+	info.ApiVersion = apiV1.DriveMgrAPIVersion
+	return info, nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package identitycache contains a local cache of drive identity and SMART baseline which is used on cold start
+// of drive manager instead of SMART and identify commands for every device
+package identitycache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTTL is max age of entry which is used on cold start
+const DefaultTTL = 24 * time.Hour
+
+// Entry holds identity and SMART baseline of drive which are reported by slow per-device commands
+type Entry struct {
+	// Fingerprint is built from cheap sources (sysfs, list commands), entry is used only if it matches current one
+	Fingerprint  string `json:"fp"`
+	SerialNumber string `json:"sn"`
+	VID          string `json:"vid,omitempty"`
+	Type         string `json:"type"`
+	Health       string `json:"health"`
+	Temperature  int64  `json:"temp,omitempty"`
+	// Timestamp in seconds since epoch
+	Timestamp int64 `json:"ts"`
+}
+
+// Cache keeps entries per device path and persists them in a local file. Each entry is used at most once after start,
+// the next discoveries run full commands and refresh the entry, so health of drives isn't stale
+type Cache struct {
+	sync.Mutex
+	path string
+	ttl  time.Duration
+	// key - device path
+	entries map[string]Entry
+	// devices which entries were used or refreshed after start
+	used map[string]bool
+	now  func() time.Time
+	log  *logrus.Entry
+}
+
+// NewCache is a constructor for Cache, loads entries from the file by path if it exists
+func NewCache(path string, ttl time.Duration, logger *logrus.Logger) *Cache {
+	c := &Cache{
+		path:    path,
+		ttl:     ttl,
+		entries: make(map[string]Entry),
+		used:    make(map[string]bool),
+		now:     time.Now,
+		log:     logger.WithField("component", "DriveIdentityCache"),
+	}
+	if err := c.load(); err != nil {
+		c.log.Errorf("Unable to load drive identity cache from %s, start with empty cache: %v", path, err)
+	}
+	return c
+}
+
+// Get returns cached entry of device if it wasn't used after start, its fingerprint matches and it isn't expired
+// Empty fingerprint means that device can't be identified cheaply and is never served from cache
+func (c *Cache) Get(devicePath, fingerprint string) (Entry, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if fingerprint == "" || c.used[devicePath] {
+		return Entry{}, false
+	}
+	c.used[devicePath] = true
+	entry, ok := c.entries[devicePath]
+	if !ok || entry.Fingerprint != fingerprint ||
+		time.Duration(c.now().Unix()-entry.Timestamp)*time.Second > c.ttl {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Put stores entry of device which was read by full commands
+func (c *Cache) Put(devicePath string, entry Entry) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.used[devicePath] = true
+	if entry.Fingerprint == "" {
+		return
+	}
+	entry.Timestamp = c.now().Unix()
+	c.entries[devicePath] = entry
+}
+
+// Retain removes entries of devices which aren't present anymore and persists the cache
+func (c *Cache) Retain(devicePaths []string) {
+	c.Lock()
+	defer c.Unlock()
+
+	present := make(map[string]bool, len(devicePaths))
+	for _, path := range devicePaths {
+		present[path] = true
+	}
+	for path := range c.entries {
+		if !present[path] {
+			delete(c.entries, path)
+		}
+	}
+	if err := c.save(); err != nil {
+		c.log.Errorf("Unable to persist drive identity cache to %s: %v", c.path, err)
+	}
+}
+
+func (c *Cache) load() error {
+	if c.path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, &c.entries)
+}
+
+// save writes entries to the temporary file and renames it to avoid partially written file on crash
+func (c *Cache) save() error {
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(c.path), 0750); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identitycache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLogger = logrus.New()
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identitycache")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "cache", "identity.json")
+	entry := Entry{Fingerprint: "wwid", SerialNumber: "sn", Type: "HDD", Health: "GOOD", Temperature: 30}
+
+	c := NewCache(path, time.Hour, testLogger)
+	_, ok := c.Get("/dev/sda", "wwid")
+	assert.False(t, ok)
+	c.Put("/dev/sda", entry)
+	c.Put("/dev/sdb", Entry{Fingerprint: "wwid-b", SerialNumber: "sn-b"})
+	// device without fingerprint isn't cached
+	c.Put("/dev/sdc", Entry{SerialNumber: "sn-c"})
+	c.Retain([]string{"/dev/sda", "/dev/sdc"})
+
+	// restart
+	now := time.Now()
+	c = NewCache(path, time.Hour, testLogger)
+	c.now = func() time.Time { return now }
+	cached, ok := c.Get("/dev/sda", "wwid")
+	assert.True(t, ok)
+	assert.Equal(t, "sn", cached.SerialNumber)
+	assert.Equal(t, int64(30), cached.Temperature)
+	// entry is used once after start
+	_, ok = c.Get("/dev/sda", "wwid")
+	assert.False(t, ok)
+	// removed device
+	_, ok = c.Get("/dev/sdb", "wwid-b")
+	assert.False(t, ok)
+	_, ok = c.Get("/dev/sdc", "")
+	assert.False(t, ok)
+
+	// replaced drive and expired entry
+	c = NewCache(path, time.Hour, testLogger)
+	_, ok = c.Get("/dev/sda", "other-wwid")
+	assert.False(t, ok)
+	c = NewCache(path, time.Hour, testLogger)
+	c.now = func() time.Time { return now.Add(2 * time.Hour) }
+	_, ok = c.Get("/dev/sda", "wwid")
+	assert.False(t, ok)
+}
+
+func TestCache_CorruptedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identitycache")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "identity.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("{"), 0600))
+
+	c := NewCache(path, time.Hour, testLogger)
+	assert.Empty(t, c.entries)
+}
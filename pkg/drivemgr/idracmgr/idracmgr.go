@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -32,6 +33,7 @@ import (
 
 	api "github.com/dell/csi-baremetal/api/generated/v1"
 	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/credentials"
 )
 
 const (
@@ -46,6 +48,10 @@ type IDRACManager struct {
 	ip       string
 	user     string
 	password string
+	// provides rotated credentials and endpoint from kubernetes Secret, nil if static credentials are used
+	credentials credentials.Provider
+	// protects credentials and ip during drives inspection
+	mu sync.Mutex
 }
 
 // NewIDRACManager is the constructor of IDRACManager struct
@@ -64,6 +70,30 @@ func NewIDRACManager(log *logrus.Logger, timeout time.Duration, user string, pas
 	}
 }
 
+// SetCredentialsProvider sets provider which is used to refresh credentials and iDRAC endpoint before each
+// drives inspection, endpoint from provider overrides iDRAC IP passed to constructor
+func (mgr *IDRACManager) SetCredentialsProvider(provider credentials.Provider) {
+	mgr.mu.Lock()
+	mgr.credentials = provider
+	mgr.mu.Unlock()
+}
+
+// refreshCredentials updates credentials and endpoint from provider, must be called under mu
+func (mgr *IDRACManager) refreshCredentials() error {
+	if mgr.credentials == nil {
+		return nil
+	}
+	creds, err := mgr.credentials.GetCredentials()
+	if err != nil {
+		return fmt.Errorf("unable to get iDRAC credentials: %v", err)
+	}
+	mgr.user, mgr.password = creds.Username, creds.Password
+	if creds.Endpoint != "" {
+		mgr.ip = creds.Endpoint
+	}
+	return nil
+}
+
 // Storage contains urls of controller, enclosure etc, example @odata.id:/redfish/v1/Systems/System.Embedded.1/Storage/NonRAID.Integrated.1-1
 /*
 ...
@@ -103,6 +133,11 @@ type IDRACDrive struct {
 // GetDrivesList returns slice of *api.Drive created from iDRAC drives
 // Returns slice of *api.Drives struct or error if something went wrong
 func (mgr *IDRACManager) GetDrivesList() ([]*api.Drive, error) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if err := mgr.refreshCredentials(); err != nil {
+		return nil, err
+	}
 	controllerURL := mgr.getControllerURLs()
 	if len(controllerURL) == 0 {
 		return nil, errors.New("unable to inspect iDRAC controller")
@@ -27,6 +27,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	api "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/drivemgr/credentials"
 )
 
 var logger = logrus.New()
@@ -139,3 +140,24 @@ func Test_getDrive(t *testing.T) {
 	drive = idracManager.getDrive(server.URL)
 	assert.Nil(t, drive)
 }
+
+func TestIDRACManager_refreshCredentials(t *testing.T) {
+	idracManager := NewIDRACManager(logger, time.Second, "user", "password", "10.10.10.10")
+	assert.Nil(t, idracManager.refreshCredentials())
+	assert.Equal(t, "user", idracManager.user)
+
+	idracManager.SetCredentialsProvider(credentials.StaticProvider{Username: "root", Password: "passwd"})
+	assert.Nil(t, idracManager.refreshCredentials())
+	assert.Equal(t, "root", idracManager.user)
+	assert.Equal(t, "passwd", idracManager.password)
+	assert.Equal(t, "10.10.10.10", idracManager.ip)
+
+	idracManager.SetCredentialsProvider(credentials.StaticProvider{Username: "root", Password: "passwd", Endpoint: "10.10.10.11"})
+	assert.Nil(t, idracManager.refreshCredentials())
+	assert.Equal(t, "10.10.10.11", idracManager.ip)
+
+	// secret isn't mounted
+	idracManager.SetCredentialsProvider(credentials.NewSecretProvider(t.TempDir(), "", logger))
+	_, err := idracManager.GetDrivesList()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopbackmgr
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dell/csi-baremetal/pkg/base/command"
+)
+
+const (
+	// dm-delay device is created on top of loop device of image file, drive is another loop device on top of
+	// dm-delay device, so partitions of drive are handled by loop driver as for drive without latency
+	delayDMPrefix  = "loopback-delay-"
+	mapperDir      = "/dev/mapper/"
+	dmsetupCmd     = "dmsetup"
+	delayTableTmpl = "0 %d delay %s 0 %d %s 0 %d"
+
+	getSectorsCmdTmpl      = "blockdev --getsz %s"
+	infoDelayDeviceCmdTmpl = dmsetupCmd + " info %s"
+	resumeDelayCmdTmpl     = dmsetupCmd + " resume %s"
+	removeDelayCmdTmpl     = dmsetupCmd + " remove %s"
+)
+
+// latencyProfiles are predefined latencies of simulated drives
+var latencyProfiles = map[string]Latency{
+	// 7200 RPM drive: seek and rotational delay
+	"hdd": {Read: "8ms", Write: "10ms"},
+	// SATA SSD, dm-delay granularity is millisecond
+	"ssd": {Read: "1ms", Write: "2ms"},
+	// drive with slow sectors, triggers timeouts of benchmarks and health checks
+	"slow-hdd": {Read: "50ms", Write: "100ms"},
+	// failing drive, triggers watchdogs and I/O timeouts
+	"degraded": {Read: "500ms", Write: "2s"},
+}
+
+// Latency struct describes latency of I/O operations of simulated drive
+type Latency struct {
+	// Profile is a name of predefined latency profile: hdd, ssd, slow-hdd or degraded
+	Profile string `yaml:"profile"`
+	// Read is a latency of read operations, e.g. 20ms, overrides latency of profile
+	Read string `yaml:"read"`
+	// Write is a latency of write operations, e.g. 20ms, overrides latency of profile
+	Write string `yaml:"write"`
+}
+
+// delays returns read and write delays in milliseconds, zero delays for nil latency
+// Returns error if profile is unknown or delay can't be parsed
+func (l *Latency) delays() (int64, int64, error) {
+	if l == nil {
+		return 0, 0, nil
+	}
+	read, write := l.Read, l.Write
+	if l.Profile != "" {
+		profile, ok := latencyProfiles[l.Profile]
+		if !ok {
+			return 0, 0, fmt.Errorf("unknown latency profile %s", l.Profile)
+		}
+		if read == "" {
+			read = profile.Read
+		}
+		if write == "" {
+			write = profile.Write
+		}
+	}
+	readMs, err := parseDelay(read)
+	if err != nil {
+		return 0, 0, err
+	}
+	writeMs, err := parseDelay(write)
+	if err != nil {
+		return 0, 0, err
+	}
+	return readMs, writeMs, nil
+}
+
+// parseDelay converts duration such as 8ms or 2s to milliseconds, empty value is zero delay
+func parseDelay(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse latency %s: %v", value, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("latency %s is negative", value)
+	}
+	return d.Milliseconds(), nil
+}
+
+// latencyEquals returns true if latencies are the same, nil latency is equal to empty one
+func latencyEquals(l1, l2 *Latency) bool {
+	if l1 == nil {
+		l1 = &Latency{}
+	}
+	if l2 == nil {
+		l2 = &Latency{}
+	}
+	return *l1 == *l2
+}
+
+// delayDeviceName returns name of dm-delay device of simulated drive
+func (d *LoopBackDevice) delayDeviceName() string {
+	return delayDMPrefix + d.SerialNumber
+}
+
+// drivePath returns path of device which is reported as drive
+func (d *LoopBackDevice) drivePath() string {
+	if d.shapedPath != "" {
+		return d.shapedPath
+	}
+	return d.devicePath
+}
+
+// shapeDevice applies latency of device with dm-delay target and attaches loop device on top of dm-delay device
+// Device without latency isn't shaped. Latency of shaped device is reset to zero when it's removed from config,
+// so path of the drive isn't changed
+// Receives device which is bound to loop device and mapping between backing files and loop devices
+// Returns error if something went wrong
+func (mgr *LoopBackManager) shapeDevice(device *LoopBackDevice, loopDeviceMapping map[string][]string) error {
+	dmName := device.delayDeviceName()
+	dmPath := mapperDir + dmName
+	if device.Latency == nil && device.delayTable == "" && len(loopDeviceMapping[dmPath]) == 0 {
+		return nil
+	}
+	read, write, err := device.Latency.delays()
+	if err != nil {
+		return err
+	}
+	stdout, _, err := mgr.exec.RunCmd(command.NewCmd(getSectorsCmdTmpl, command.Device(device.devicePath)))
+	if err != nil {
+		return fmt.Errorf("unable to get size of %s: %v", device.devicePath, err)
+	}
+	sectors, err := strconv.ParseInt(strings.TrimSpace(stdout), 10, 64)
+	if err != nil {
+		return fmt.Errorf("unable to parse size of %s: %v", device.devicePath, err)
+	}
+	table := fmt.Sprintf(delayTableTmpl, sectors, device.devicePath, read, device.devicePath, write)
+	if table != device.delayTable {
+		if err = mgr.loadDelayTable(dmName, table); err != nil {
+			return err
+		}
+		device.delayTable = table
+	}
+
+	if loopDevs := loopDeviceMapping[dmPath]; len(loopDevs) > 0 {
+		device.shapedPath = loopDevs[0]
+		return nil
+	}
+	stdout, stderr, err := mgr.exec.RunCmd(fmt.Sprintf(setupLoopBackDeviceCmdTmpl, dmPath))
+	if err != nil {
+		return fmt.Errorf("unable to create loopback device for %s: %s", dmPath, stderr)
+	}
+	device.shapedPath = strings.TrimSuffix(stdout, "\n")
+	return nil
+}
+
+// loadDelayTable creates dm-delay device with table or replaces table of existing device
+func (mgr *LoopBackManager) loadDelayTable(name, table string) error {
+	if _, _, err := mgr.exec.RunCmd(command.NewCmd(infoDelayDeviceCmdTmpl, command.Name(name))); err != nil {
+		if _, stderr, err := mgr.exec.RunCmd(exec.Command(dmsetupCmd, "create", name, "--table", table)); err != nil {
+			return fmt.Errorf("unable to create dm-delay device %s: %s", name, stderr)
+		}
+		return nil
+	}
+	// new table of existing device is activated on resume
+	if _, stderr, err := mgr.exec.RunCmd(exec.Command(dmsetupCmd, "reload", name, "--table", table)); err != nil {
+		return fmt.Errorf("unable to reload table of dm-delay device %s: %s", name, stderr)
+	}
+	if _, stderr, err := mgr.exec.RunCmd(command.NewCmd(resumeDelayCmdTmpl, command.Name(name))); err != nil {
+		return fmt.Errorf("unable to resume dm-delay device %s: %s", name, stderr)
+	}
+	return nil
+}
+
+// unshapeDevice detaches loop device on top of dm-delay device and removes dm-delay device
+func (mgr *LoopBackManager) unshapeDevice(device *LoopBackDevice) {
+	ll := mgr.log.WithField("method", "unshapeDevice")
+	if device.shapedPath != "" {
+		if _, _, err := mgr.exec.RunCmd(fmt.Sprintf(detachLoopBackDeviceCmdTmpl, device.shapedPath)); err != nil {
+			ll.Errorf("Unable to detach loopback device %s", device.shapedPath)
+		}
+	}
+	if device.delayTable != "" {
+		if _, _, err := mgr.exec.RunCmd(command.NewCmd(removeDelayCmdTmpl, command.Name(device.delayDeviceName()))); err != nil {
+			ll.Errorf("Unable to remove dm-delay device %s", device.delayDeviceName())
+		}
+	}
+	device.shapedPath, device.delayTable = "", ""
+}
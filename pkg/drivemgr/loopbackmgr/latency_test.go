@@ -0,0 +1,129 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopbackmgr
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestLatency_delays(t *testing.T) {
+	var l *Latency
+	read, write, err := l.delays()
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{0, 0}, []int64{read, write})
+
+	read, write, err = (&Latency{Profile: "hdd"}).delays()
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{8, 10}, []int64{read, write})
+
+	read, write, err = (&Latency{Profile: "degraded", Read: "20ms"}).delays()
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{20, 2000}, []int64{read, write})
+
+	read, write, err = (&Latency{Write: "1.5s"}).delays()
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{0, 1500}, []int64{read, write})
+
+	for _, latency := range []*Latency{{Profile: "tape"}, {Read: "fast"}, {Write: "-1ms"}} {
+		_, _, err = latency.delays()
+		assert.NotNil(t, err)
+	}
+}
+
+func TestLoopBackDevice_Equals_Latency(t *testing.T) {
+	d1 := &LoopBackDevice{SerialNumber: "LOOPBACK1"}
+	d2 := &LoopBackDevice{SerialNumber: "LOOPBACK1", Latency: &Latency{}}
+	assert.True(t, d1.Equals(d2))
+
+	d2.Latency.Profile = "hdd"
+	assert.False(t, d1.Equals(d2))
+}
+
+// dmsetupCmdMatcher returns matcher of dmsetup command which is run without shell
+func dmsetupCmdMatcher(args string) interface{} {
+	return mock.MatchedBy(func(cmd *exec.Cmd) bool {
+		return strings.Join(cmd.Args, " ") == args
+	})
+}
+
+func TestLoopBackManager_shapeDevice(t *testing.T) {
+	var (
+		mockexec = &mocks.GoMockExecutor{}
+		manager  = NewLoopBackManager(mockexec, "", "", logger)
+		device   = &LoopBackDevice{SerialNumber: "LOOPBACK1", devicePath: "/dev/loop0", Latency: &Latency{Profile: "hdd"}}
+		dmName   = delayDMPrefix + device.SerialNumber
+		dmPath   = mapperDir + dmName
+	)
+
+	// device without latency isn't shaped, executor isn't called
+	assert.Nil(t, manager.shapeDevice(&LoopBackDevice{SerialNumber: "LOOPBACK2", devicePath: "/dev/loop1"}, nil))
+
+	// dm-delay device is created
+	mockexec.On("RunCmd", fmt.Sprintf(getSectorsCmdTmpl, "/dev/loop0")).Return("204800\n", "", nil)
+	mockexec.On("RunCmd", fmt.Sprintf(infoDelayDeviceCmdTmpl, dmName)).
+		Return("", "Device does not exist.", errors.New("exit status 1")).Once()
+	mockexec.On("RunCmd", dmsetupCmdMatcher("dmsetup create "+dmName+
+		" --table 0 204800 delay /dev/loop0 0 8 /dev/loop0 0 10")).Return("", "", nil).Once()
+	mockexec.On("RunCmd", fmt.Sprintf(setupLoopBackDeviceCmdTmpl, dmPath)).Return("/dev/loop5\n", "", nil).Once()
+
+	assert.Nil(t, manager.shapeDevice(device, map[string][]string{}))
+	assert.Equal(t, "/dev/loop5", device.drivePath())
+
+	// table is the same, nothing is changed
+	assert.Nil(t, manager.shapeDevice(device, map[string][]string{dmPath: {"/dev/loop5"}}))
+
+	// latency is removed from config, table of existing device is reloaded with zero delays
+	device.Latency = nil
+	mockexec.On("RunCmd", fmt.Sprintf(infoDelayDeviceCmdTmpl, dmName)).Return("", "", nil).Once()
+	mockexec.On("RunCmd", dmsetupCmdMatcher("dmsetup reload "+dmName+
+		" --table 0 204800 delay /dev/loop0 0 0 /dev/loop0 0 0")).Return("", "", nil).Once()
+	mockexec.On("RunCmd", fmt.Sprintf(resumeDelayCmdTmpl, dmName)).Return("", "", nil).Once()
+
+	assert.Nil(t, manager.shapeDevice(device, map[string][]string{dmPath: {"/dev/loop5"}}))
+	assert.Equal(t, "/dev/loop5", device.drivePath())
+
+	// unknown profile
+	device.Latency = &Latency{Profile: "tape"}
+	assert.NotNil(t, manager.shapeDevice(device, nil))
+}
+
+func TestLoopBackManager_deleteShapedDevice(t *testing.T) {
+	var (
+		mockexec = &mocks.GoMockExecutor{}
+		manager  = NewLoopBackManager(mockexec, "", "", logger)
+		device   = &LoopBackDevice{SerialNumber: "LOOPBACK1", devicePath: "/dev/loop0", fileName: "loopback.img",
+			shapedPath: "/dev/loop5", delayTable: "0 204800 delay /dev/loop0 0 8 /dev/loop0 0 10"}
+	)
+
+	mockexec.On("RunCmd", fmt.Sprintf(detachLoopBackDeviceCmdTmpl, "/dev/loop5")).Return("", "", nil).Once()
+	mockexec.On("RunCmd", fmt.Sprintf(removeDelayCmdTmpl, delayDMPrefix+"LOOPBACK1")).Return("", "", nil).Once()
+	mockexec.On("RunCmd", fmt.Sprintf(detachLoopBackDeviceCmdTmpl, "/dev/loop0")).Return("", "", nil).Once()
+	mockexec.On("RunCmd", fmt.Sprintf(deleteFileCmdTmpl, "loopback.img")).Return("", "", nil).Once()
+
+	manager.deleteLoopbackDevice(device)
+	assert.Equal(t, "/dev/loop0", device.drivePath())
+	mockexec.AssertExpectations(t)
+}
@@ -89,10 +89,16 @@ type LoopBackDevice struct {
 	Health       string `yaml:"health"`
 	DriveType    string `yaml:"driveType"`
 	LED          int    `yaml:"led"`
+	// Latency is applied to I/O of device with dm-delay target, nil if device isn't shaped
+	Latency *Latency `yaml:"latency"`
 
 	fileName string
 	// for example, /dev/loop0
 	devicePath string
+	// loop device on top of dm-delay device which is reported as drive, empty if device isn't shaped
+	shapedPath string
+	// table of dm-delay device which is loaded
+	delayTable string
 }
 
 // Node struct represents particular configuration of LoopBackManager for specified node
@@ -209,7 +215,7 @@ func (d *LoopBackDevice) Equals(device *LoopBackDevice) bool {
 	return d.Removed == device.Removed && d.DriveType == device.DriveType &&
 		d.Health == device.Health && d.Size == device.Size &&
 		d.SerialNumber == device.SerialNumber && d.ProductID == device.ProductID &&
-		d.VendorID == device.VendorID
+		d.VendorID == device.VendorID && latencyEquals(d.Latency, device.Latency)
 }
 
 // fillEmptyFieldsWithDefaults fills fields of LoopBackDevice which are not provided in configuration with defaults
@@ -344,6 +350,8 @@ func (mgr *LoopBackManager) overrideDevicesFromNodeConfig(deviceCount int, devic
 							device.Size = mgrDevice.Size
 						default:
 							device.devicePath = mgrDevice.devicePath
+							device.shapedPath = mgrDevice.shapedPath
+							device.delayTable = mgrDevice.delayTable
 						}
 						device.fileName = mgrDevice.fileName
 					}
@@ -401,6 +409,7 @@ func (mgr *LoopBackManager) createDefaultDevices(deviceCount int) {
 // deleteLoopbackDevice detach specified loopback device and delete according file
 func (mgr *LoopBackManager) deleteLoopbackDevice(device *LoopBackDevice) {
 	ll := mgr.log.WithField("method", "deleteLoopbackDevice")
+	mgr.unshapeDevice(device)
 	_, _, err := mgr.exec.RunCmd(fmt.Sprintf(detachLoopBackDeviceCmdTmpl, device.devicePath))
 	if err != nil {
 		ll.Errorf("Unable to detach loopback device %s", device.devicePath)
@@ -483,6 +492,10 @@ func (mgr *LoopBackManager) Init() {
 			mgr.devices[i].devicePath = strings.TrimSuffix(stdout, "\n")
 			mgr.devices[i].Removed = false
 		}
+		// device stays available without latency if it can't be shaped
+		if err = mgr.shapeDevice(mgr.devices[i], loopDeviceMapping); err != nil {
+			ll.Errorf("Unable to apply latency to device %s: %v", mgr.devices[i].SerialNumber, err)
+		}
 	}
 }
 
@@ -508,7 +521,7 @@ func (mgr *LoopBackManager) GetDrivesList() ([]*api.Drive, error) {
 			Type:         strings.ToUpper(mgr.devices[i].DriveType),
 			Size:         sizeBytes,
 			Status:       driveStatus,
-			Path:         mgr.devices[i].devicePath,
+			Path:         mgr.devices[i].drivePath(),
 		}
 		drives = append(drives, drive)
 	}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivemgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+// ErrIncompatibleAPIVersion is returned when drive manager implements unsupported version of DriveService API
+var ErrIncompatibleAPIVersion = errors.New("incompatible DriveService API version")
+
+// NegotiateAPIVersion requests description of drive manager over DriveService GetInfo method and checks
+// that drive manager implements API version which is supported by node service.
+// Drive managers which don't implement GetInfo are treated as in-tree managers of API version v1.
+// Returns drive manager description or error (wrapped ErrIncompatibleAPIVersion if versions don't match)
+func NegotiateAPIVersion(ctx context.Context, client api.DriveServiceClient,
+	opts ...grpc.CallOption) (*api.DriveManagerInfo, error) {
+	info, err := client.GetInfo(ctx, &api.Empty{}, opts...)
+	if err != nil {
+		if status.Code(err) != codes.Unimplemented {
+			return nil, err
+		}
+		info = LegacyInfo()
+	}
+	if info.ApiVersion == "" {
+		info.ApiVersion = apiV1.DriveMgrAPIVersion
+	}
+	if info.ApiVersion != apiV1.DriveMgrAPIVersion {
+		return nil, fmt.Errorf("%w: drive manager %s implements %s, supported %s",
+			ErrIncompatibleAPIVersion, info.Name, info.ApiVersion, apiV1.DriveMgrAPIVersion)
+	}
+	return info, nil
+}
+
+// LegacyInfo returns description of in-tree drive manager which doesn't implement GetInfo,
+// it implements API version v1 with all capabilities
+func LegacyInfo() *api.DriveManagerInfo {
+	return &api.DriveManagerInfo{
+		ApiVersion:   apiV1.DriveMgrAPIVersion,
+		Capabilities: []string{apiV1.DriveMgrCapabilityLocate, apiV1.DriveMgrCapabilityLocateNode},
+	}
+}
+
+// HasCapability checks whether drive manager reported provided capability or not
+func HasCapability(info *api.DriveManagerInfo, capability string) bool {
+	for _, c := range info.GetCapabilities() {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
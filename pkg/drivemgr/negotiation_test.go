@@ -0,0 +1,89 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivemgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+// infoClient is DriveServiceClient which returns info of DriveServiceServerImpl
+type infoClient struct {
+	mocks.MockDriveMgrClient
+	server *DriveServiceServerImpl
+	info   *api.DriveManagerInfo
+}
+
+func (c *infoClient) GetInfo(ctx context.Context, in *api.Empty, opts ...grpc.CallOption) (*api.DriveManagerInfo, error) {
+	if c.server != nil {
+		return c.server.GetInfo(ctx, in)
+	}
+	return c.info, nil
+}
+
+// vendorManager is DriveManager which implements InfoProvider
+type vendorManager struct {
+	DriveManager
+}
+
+func (m *vendorManager) GetInfo() *api.DriveManagerInfo {
+	return &api.DriveManagerInfo{Name: "vendor", Version: "2.0", ApiVersion: "v0"}
+}
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	logger := logrus.New()
+
+	// in-tree drive manager
+	server := NewDriveServer(logger, nil)
+	info, err := NegotiateAPIVersion(context.Background(), &infoClient{server: &server})
+	assert.Nil(t, err)
+	assert.Equal(t, apiV1.DriveMgrAPIVersion, info.ApiVersion)
+	assert.True(t, HasCapability(info, apiV1.DriveMgrCapabilityLocate))
+
+	// drive manager with custom info, API version is set by server
+	server = NewDriveServer(logger, &vendorManager{})
+	info, err = NegotiateAPIVersion(context.Background(), &infoClient{server: &server})
+	assert.Nil(t, err)
+	assert.Equal(t, "vendor", info.Name)
+	assert.Equal(t, apiV1.DriveMgrAPIVersion, info.ApiVersion)
+	assert.False(t, HasCapability(info, apiV1.DriveMgrCapabilityLocate))
+
+	// drive manager without GetInfo
+	info, err = NegotiateAPIVersion(context.Background(), mocks.NewMockDriveMgrClient(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, apiV1.DriveMgrAPIVersion, info.ApiVersion)
+	assert.True(t, HasCapability(info, apiV1.DriveMgrCapabilityLocateNode))
+
+	// out-of-tree drive manager with unsupported API version
+	_, err = NegotiateAPIVersion(context.Background(),
+		&infoClient{info: &api.DriveManagerInfo{Name: "vendor", ApiVersion: "v2"}})
+	assert.True(t, errors.Is(err, ErrIncompatibleAPIVersion))
+
+	// drive manager isn't available
+	_, err = NegotiateAPIVersion(context.Background(), &mocks.MockDriveMgrClientFail{})
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrIncompatibleAPIVersion))
+}
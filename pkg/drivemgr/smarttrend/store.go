@@ -0,0 +1,195 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package smarttrend contains a local store of periodic SMART samples per drive and trend-based
+// prediction of drive failure
+package smarttrend
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UnknownValue means that SMART counter isn't reported by the drive
+const UnknownValue = -1
+
+// Sample is a compact snapshot of SMART counters which are used for failure prediction
+type Sample struct {
+	// Timestamp in seconds since epoch
+	Timestamp int64 `json:"ts"`
+	// ReallocatedSectors is count of reallocated sectors (grown defects for SCSI, media errors for NVMe)
+	ReallocatedSectors int64 `json:"realloc"`
+	// PendingSectors is count of sectors which wait for reallocation
+	PendingSectors int64 `json:"pending"`
+	// WearUsed is percentage of used endurance
+	WearUsed int64 `json:"wear"`
+}
+
+// Config holds size of history and thresholds for failure prediction
+type Config struct {
+	// Capacity is maximum count of samples which are kept per drive
+	Capacity int
+	// SampleInterval is minimal interval between samples which are kept in history
+	SampleInterval time.Duration
+	// ReallocatedGrowth is increase of reallocated sectors over the history which marks drive as suspect
+	ReallocatedGrowth int64
+	// PendingGrowth is increase of pending sectors over the history which marks drive as suspect
+	PendingGrowth int64
+	// WearLimit is percentage of used endurance which marks drive as suspect
+	WearLimit int64
+	// WearHorizon marks drive as suspect if its endurance is projected to run out within this duration
+	WearHorizon time.Duration
+}
+
+// DefaultConfig returns Config with history for a week with hourly samples
+func DefaultConfig() Config {
+	return Config{
+		Capacity:          168,
+		SampleInterval:    time.Hour,
+		ReallocatedGrowth: 10,
+		PendingGrowth:     10,
+		WearLimit:         95,
+		WearHorizon:       30 * 24 * time.Hour,
+	}
+}
+
+// Store keeps bounded history of SMART samples per drive serial number and persists it in a local file
+type Store struct {
+	sync.Mutex
+	cfg  Config
+	path string
+	// key - drive serial number, value - samples from the oldest to the newest, len is limited by cfg.Capacity
+	history map[string][]Sample
+	log     *logrus.Entry
+}
+
+// NewStore is a constructor for Store, loads history from the file by path if it exists
+// Empty path means that history is kept in memory only
+func NewStore(path string, cfg Config, logger *logrus.Logger) *Store {
+	s := &Store{
+		cfg:     cfg,
+		path:    path,
+		history: make(map[string][]Sample),
+		log:     logger.WithField("component", "SMARTTrendStore"),
+	}
+	if err := s.load(); err != nil {
+		s.log.Errorf("Unable to load SMART history from %s, start with empty history: %v", path, err)
+	}
+	return s
+}
+
+// Observe adds sample to the history of the drive and checks trend of SMART counters
+// Returns true and reason if drive failure is predicted
+func (s *Store) Observe(serialNumber string, sample Sample) (bool, string) {
+	s.Lock()
+	defer s.Unlock()
+
+	if sample.Timestamp == 0 {
+		sample.Timestamp = time.Now().Unix()
+	}
+	history := s.history[serialNumber]
+	suspect, reason := s.predict(history, sample)
+
+	if len(history) == 0 ||
+		time.Duration(sample.Timestamp-history[len(history)-1].Timestamp)*time.Second >= s.cfg.SampleInterval {
+		history = append(history, sample)
+		if len(history) > s.cfg.Capacity {
+			history = history[len(history)-s.cfg.Capacity:]
+		}
+		s.history[serialNumber] = history
+		if err := s.save(); err != nil {
+			s.log.Errorf("Unable to persist SMART history to %s: %v", s.path, err)
+		}
+	}
+	return suspect, reason
+}
+
+// predict compares current sample with the oldest sample in the history
+func (s *Store) predict(history []Sample, current Sample) (bool, string) {
+	if current.WearUsed != UnknownValue && current.WearUsed >= s.cfg.WearLimit {
+		return true, fmt.Sprintf("wear used %d%% reached limit %d%%", current.WearUsed, s.cfg.WearLimit)
+	}
+	if len(history) == 0 {
+		return false, ""
+	}
+	oldest := history[0]
+	if grown(oldest.ReallocatedSectors, current.ReallocatedSectors) >= s.cfg.ReallocatedGrowth {
+		return true, fmt.Sprintf("reallocated sectors increased from %d to %d since %s",
+			oldest.ReallocatedSectors, current.ReallocatedSectors, time.Unix(oldest.Timestamp, 0).UTC())
+	}
+	if grown(oldest.PendingSectors, current.PendingSectors) >= s.cfg.PendingGrowth {
+		return true, fmt.Sprintf("pending sectors increased from %d to %d since %s",
+			oldest.PendingSectors, current.PendingSectors, time.Unix(oldest.Timestamp, 0).UTC())
+	}
+	wearGrowth := grown(oldest.WearUsed, current.WearUsed)
+	elapsed := current.Timestamp - oldest.Timestamp
+	if wearGrowth > 0 && elapsed > 0 {
+		// linear projection of the time when endurance runs out
+		left := time.Duration(float64(100-current.WearUsed)/float64(wearGrowth)*float64(elapsed)) * time.Second
+		if left < s.cfg.WearHorizon {
+			return true, fmt.Sprintf("wear used %d%% is projected to reach 100%% in %s", current.WearUsed, left)
+		}
+	}
+	return false, ""
+}
+
+// grown returns increase of the counter or 0 if counter is unknown
+func grown(old, current int64) int64 {
+	if old == UnknownValue || current == UnknownValue {
+		return 0
+	}
+	return current - old
+}
+
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, &s.history)
+}
+
+// save writes history to the temporary file and renames it to avoid partially written file on crash
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.history)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.path), 0750); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smarttrend
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testLogger = logrus.New()
+	testSN     = "sn"
+	hour       = int64(time.Hour / time.Second)
+)
+
+func TestStore_ObserveReallocated(t *testing.T) {
+	s := NewStore("", DefaultConfig(), testLogger)
+
+	suspect, _ := s.Observe(testSN, Sample{Timestamp: hour, ReallocatedSectors: 0, PendingSectors: 0, WearUsed: UnknownValue})
+	assert.False(t, suspect)
+	// sample isn't kept because interval isn't elapsed
+	suspect, _ = s.Observe(testSN, Sample{Timestamp: hour + 1, ReallocatedSectors: 5, WearUsed: UnknownValue})
+	assert.False(t, suspect)
+	assert.Len(t, s.history[testSN], 1)
+
+	suspect, reason := s.Observe(testSN, Sample{Timestamp: 2 * hour, ReallocatedSectors: 20, WearUsed: UnknownValue})
+	assert.True(t, suspect)
+	assert.Contains(t, reason, "reallocated sectors")
+	assert.Len(t, s.history[testSN], 2)
+
+	// unknown counters don't trigger prediction
+	suspect, _ = s.Observe("other", Sample{Timestamp: hour, ReallocatedSectors: UnknownValue, WearUsed: UnknownValue})
+	assert.False(t, suspect)
+	suspect, _ = s.Observe("other", Sample{Timestamp: 2 * hour, ReallocatedSectors: 100, WearUsed: UnknownValue})
+	assert.False(t, suspect)
+}
+
+func TestStore_ObserveWear(t *testing.T) {
+	s := NewStore("", DefaultConfig(), testLogger)
+
+	suspect, _ := s.Observe(testSN, Sample{Timestamp: hour, WearUsed: 96})
+	assert.True(t, suspect)
+
+	// 10% per day, 40% left
+	s = NewStore("", DefaultConfig(), testLogger)
+	suspect, _ = s.Observe(testSN, Sample{Timestamp: hour, WearUsed: 50})
+	assert.False(t, suspect)
+	suspect, reason := s.Observe(testSN, Sample{Timestamp: hour + 24*hour, WearUsed: 60})
+	assert.True(t, suspect)
+	assert.Contains(t, reason, "projected")
+
+	// 1% per 30 days
+	s = NewStore("", DefaultConfig(), testLogger)
+	s.Observe(testSN, Sample{Timestamp: hour, WearUsed: 50})
+	suspect, _ = s.Observe(testSN, Sample{Timestamp: hour + 30*24*hour, WearUsed: 51})
+	assert.False(t, suspect)
+}
+
+func TestStore_CapacityAndPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "smarttrend")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "history", "smart.json")
+
+	cfg := DefaultConfig()
+	cfg.Capacity = 3
+	s := NewStore(path, cfg, testLogger)
+	for i := int64(1); i <= 5; i++ {
+		s.Observe(testSN, Sample{Timestamp: i * hour, ReallocatedSectors: i, WearUsed: UnknownValue})
+	}
+	assert.Len(t, s.history[testSN], 3)
+	assert.Equal(t, int64(3), s.history[testSN][0].ReallocatedSectors)
+
+	loaded := NewStore(path, cfg, testLogger)
+	assert.Equal(t, s.history, loaded.history)
+}
@@ -37,6 +37,8 @@ const (
 	DriveStatusChangedSymptomCode = "03"
 	DriveHealthGoodSymptomCode    = "04"
 	FakeAttachSymptomCode         = "05"
+	LowCapacitySymptomCode        = "06"
+	DriveTemperatureSymptomCode   = "07"
 
 	NoneSymptomCode = "NONE"
 
@@ -91,6 +93,31 @@ var (
 		severity:    WarningType,
 		symptomCode: DriveHealthFailureSymptomCode,
 	}
+	DriveIOErrors = &EventDescription{
+		reason:      "DriveIOErrors",
+		severity:    WarningType,
+		symptomCode: DriveHealthFailureSymptomCode,
+	}
+	DriveCircuitOpen = &EventDescription{
+		reason:      "DriveCircuitOpen",
+		severity:    WarningType,
+		symptomCode: DriveHealthFailureSymptomCode,
+	}
+	DriveCircuitClosed = &EventDescription{
+		reason:      "DriveCircuitClosed",
+		severity:    NormalType,
+		symptomCode: DriveHealthFailureSymptomCode,
+	}
+	DriveEvacuation = &EventDescription{
+		reason:      "DriveEvacuation",
+		severity:    WarningType,
+		symptomCode: DriveHealthFailureSymptomCode,
+	}
+	DriveEvacuationBlocked = &EventDescription{
+		reason:      "DriveEvacuationBlocked",
+		severity:    WarningType,
+		symptomCode: DriveHealthFailureSymptomCode,
+	}
 
 	DriveDiscovered = &EventDescription{
 		reason:      "DriveDiscovered",
@@ -98,6 +125,58 @@ var (
 		symptomCode: DriveDiscoveredSymptomCode,
 	}
 
+	DriveReplaced = &EventDescription{
+		reason:      "DriveReplaced",
+		severity:    NormalType,
+		symptomCode: DriveDiscoveredSymptomCode,
+	}
+
+	DriveBurnInPassed = &EventDescription{
+		reason:      "DriveBurnInPassed",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+	DriveBurnInFailed = &EventDescription{
+		reason:      "DriveBurnInFailed",
+		severity:    ErrorType,
+		symptomCode: DriveHealthFailureSymptomCode,
+	}
+	DriveHasForeignData = &EventDescription{
+		reason:      "DriveHasForeignData",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	DriveForeignDataWiped = &EventDescription{
+		reason:      "DriveForeignDataWiped",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+	DriveForeignDataWipeFailed = &EventDescription{
+		reason:      "DriveForeignDataWipeFailed",
+		severity:    ErrorType,
+		symptomCode: NoneSymptomCode,
+	}
+	DriveWriteCacheChanged = &EventDescription{
+		reason:      "DriveWriteCacheChanged",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+	DriveWriteCacheFailed = &EventDescription{
+		reason:      "DriveWriteCacheFailed",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	DriveCapacityGrown = &EventDescription{
+		reason:      "DriveCapacityGrown",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+	DriveCapacityGrowFailed = &EventDescription{
+		reason:      "DriveCapacityGrowFailed",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+
 	DriveStatusOffline = &EventDescription{
 		reason:      "DriveStatusOffline",
 		severity:    ErrorType,
@@ -151,6 +230,51 @@ var (
 		severity:    WarningType,
 		symptomCode: NoneSymptomCode,
 	}
+	VolumeDeviceMissing = &EventDescription{
+		reason:      "VolumeDeviceMissing",
+		severity:    ErrorType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeLocationChanged = &EventDescription{
+		reason:      "VolumeLocationChanged",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	DestructiveOperationPerformed = &EventDescription{
+		reason:      "DestructiveOperationPerformed",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+	DestructiveOperationFailed = &EventDescription{
+		reason:      "DestructiveOperationFailed",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeForceCleanup = &EventDescription{
+		reason:      "VolumeForceCleanup",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeCleanupTakenOver = &EventDescription{
+		reason:      "VolumeCleanupTakenOver",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeOwnershipMismatch = &EventDescription{
+		reason:      "VolumeOwnershipMismatch",
+		severity:    ErrorType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeCacheDegraded = &EventDescription{
+		reason:      "VolumeCacheDegraded",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	NoisyNeighbor = &EventDescription{
+		reason:      "NoisyNeighbor",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
 
 	DriveHealthUnknown = &EventDescription{
 		reason:      "DriveHealthUnknown",
@@ -194,6 +318,130 @@ var (
 		symptomCode: NoneSymptomCode,
 	}
 
+	NodeCapacityLow = &EventDescription{
+		reason:      "NodeCapacityLow",
+		severity:    WarningType,
+		symptomCode: LowCapacitySymptomCode,
+	}
+	NodeCapacityRestored = &EventDescription{
+		reason:      "NodeCapacityRestored",
+		severity:    NormalType,
+		symptomCode: LowCapacitySymptomCode,
+	}
+	ClusterCapacityLow = &EventDescription{
+		reason:      "ClusterCapacityLow",
+		severity:    WarningType,
+		symptomCode: LowCapacitySymptomCode,
+	}
+	ClusterCapacityRestored = &EventDescription{
+		reason:      "ClusterCapacityRestored",
+		severity:    NormalType,
+		symptomCode: LowCapacitySymptomCode,
+	}
+
+	NodeDriveFailure = &EventDescription{
+		reason:      "DriveFailure",
+		severity:    WarningType,
+		symptomCode: DriveHealthFailureSymptomCode,
+	}
+	NodeDiskPressureOnDataDrives = &EventDescription{
+		reason:      "DiskPressureOnDataDrives",
+		severity:    WarningType,
+		symptomCode: LowCapacitySymptomCode,
+	}
+	NodeNeedsReboot = &EventDescription{
+		reason:      "NeedsReboot",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	NodeStorageSelfTestFailed = &EventDescription{
+		reason:      "StorageSelfTestFailed",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	NodeStorageSelfTestPassed = &EventDescription{
+		reason:      "StorageSelfTestPassed",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+
+	DriveTemperatureHigh = &EventDescription{
+		reason:      "DriveTemperatureHigh",
+		severity:    WarningType,
+		symptomCode: DriveTemperatureSymptomCode,
+	}
+	DriveTemperatureNormal = &EventDescription{
+		reason:      "DriveTemperatureNormal",
+		severity:    NormalType,
+		symptomCode: DriveTemperatureSymptomCode,
+	}
+	DrivePartitionUUIDDuplicated = &EventDescription{
+		reason:      "DrivePartitionUUIDDuplicated",
+		severity:    ErrorType,
+		symptomCode: NoneSymptomCode,
+	}
+	DrivePartitionUUIDResolved = &EventDescription{
+		reason:      "DrivePartitionUUIDResolved",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+
+	VolumeLazyUnmounted = &EventDescription{
+		reason:      "VolumeLazyUnmounted",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeDuplicateMount = &EventDescription{
+		reason:      "VolumeDuplicateMount",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumePartitionMisaligned = &EventDescription{
+		reason:      "VolumePartitionMisaligned",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumePartitionRealigned = &EventDescription{
+		reason:      "VolumePartitionRealigned",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumePartitionRealignFailed = &EventDescription{
+		reason:      "VolumePartitionRealignFailed",
+		severity:    ErrorType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeAutogrown = &EventDescription{
+		reason:      "VolumeAutogrown",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeAutogrowFailed = &EventDescription{
+		reason:      "VolumeAutogrowFailed",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeAutogrowLimitReached = &EventDescription{
+		reason:      "VolumeAutogrowLimitReached",
+		severity:    WarningType,
+		symptomCode: LowCapacitySymptomCode,
+	}
+	VolumeExportStarted = &EventDescription{
+		reason:      "VolumeExportStarted",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeExportStopped = &EventDescription{
+		reason:      "VolumeExportStopped",
+		severity:    NormalType,
+		symptomCode: NoneSymptomCode,
+	}
+	VolumeExportFailed = &EventDescription{
+		reason:      "VolumeExportFailed",
+		severity:    ErrorType,
+		symptomCode: NoneSymptomCode,
+	}
+
 	WBTValueSetFailed = &EventDescription{
 		reason:      "WBTValueSetFailed",
 		severity:    ErrorType,
@@ -209,4 +457,15 @@ var (
 		severity:    WarningType,
 		symptomCode: NoneSymptomCode,
 	}
+
+	ResourceGrowthDetected = &EventDescription{
+		reason:      "ResourceGrowthDetected",
+		severity:    WarningType,
+		symptomCode: NoneSymptomCode,
+	}
+	RestartOnResourceLeak = &EventDescription{
+		reason:      "RestartOnResourceLeak",
+		severity:    ErrorType,
+		symptomCode: NoneSymptomCode,
+	}
 )
@@ -18,6 +18,7 @@ package events
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
@@ -38,6 +39,7 @@ type EventRecorder interface {
 type Recorder struct {
 	eventRecorder EventRecorder
 	eventManager  *eventing.EventManager
+	history       *History
 	// Wait is blocking wait operation until all events are processed
 	Wait func()
 }
@@ -70,6 +72,15 @@ func (r *Recorder) Eventf(object runtime.Object, event *eventing.EventDescriptio
 	} else {
 		r.eventRecorder.Eventf(object, severity, reason, messageFmt, args...)
 	}
+
+	if r.history != nil {
+		r.history.Record(object, severity, reason, fmt.Sprintf(messageFmt, args...))
+	}
+}
+
+// SetHistory enables persisting of events about Drive and Volume CRs to History CRs
+func (r *Recorder) SetHistory(history *History) {
+	r.history = history
 }
 
 // New makes Recorder for a simple usage
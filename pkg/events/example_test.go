@@ -59,5 +59,5 @@ func Example() {
 
 	// Send event
 	drive := new(drivecrd.Drive)
-	eventRecorder.Eventf(drive, eventManager.GenerateFake(), "DriveIsDead", "drive &s is dead", drive.GetName())
+	eventRecorder.Eventf(drive, eventManager.GenerateFake(), "drive %s is dead", drive.GetName())
 }
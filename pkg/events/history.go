@@ -0,0 +1,168 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/historycrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+const (
+	// historyQueueSize is the number of events which wait to be persisted, events are dropped when queue is full
+	historyQueueSize = 1000
+	// historyAttempts is the number of attempts to persist event when History CR is modified concurrently
+	historyAttempts = 3
+)
+
+// History persists lifecycle events of Drive and Volume CRs to History CRs, which outlive both
+// k8s Events TTL and the objects themselves
+type History struct {
+	client     *k8s.KubeClient
+	maxEntries int
+	queue      chan *historyRecord
+	log        *logrus.Entry
+}
+
+// historyRecord is an event waiting to be persisted
+type historyRecord struct {
+	kind      string
+	name      string
+	namespace string
+	entry     *api.HistoryEntry
+}
+
+// NewHistory creates History which keeps up to maxEntries the latest events per object
+func NewHistory(client *k8s.KubeClient, maxEntries int, logger *logrus.Logger) *History {
+	return &History{
+		client:     client,
+		maxEntries: maxEntries,
+		queue:      make(chan *historyRecord, historyQueueSize),
+		log:        logger.WithField("component", "History"),
+	}
+}
+
+// HistoryName returns name of History CR of the object
+func HistoryName(kind, name string) string {
+	return strings.ToLower(kind) + "-" + name
+}
+
+// Record queues event about the object, events about objects other than Drive and Volume CRs are ignored
+func (h *History) Record(object runtime.Object, eventType, reason, message string) {
+	record := &historyRecord{
+		entry: &api.HistoryEntry{
+			Time:    time.Now().Unix(),
+			Type:    eventType,
+			Reason:  reason,
+			Message: message,
+		},
+	}
+	switch obj := object.(type) {
+	case *drivecrd.Drive:
+		record.kind, record.name = apiV1.DriveKind, obj.Name
+	case *volumecrd.Volume:
+		record.kind, record.name, record.namespace = apiV1.VolumeKind, obj.Name, obj.Namespace
+	default:
+		return
+	}
+
+	select {
+	case h.queue <- record:
+	default:
+		h.log.WithField("method", "Record").Warnf("Queue is full, event %s of %s %s isn't persisted",
+			reason, record.kind, record.name)
+	}
+}
+
+// Run persists queued events until context is done
+func (h *History) Run(ctx context.Context) {
+	ll := h.log.WithField("method", "Run")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-h.queue:
+			if err := h.persist(ctx, record); err != nil {
+				ll.Errorf("Unable to persist event %s of %s %s: %v", record.entry.Reason, record.kind, record.name, err)
+			}
+		}
+	}
+}
+
+// persist appends entry of the record to History CR of the object, creates History CR if it doesn't exist
+func (h *History) persist(ctx context.Context, record *historyRecord) error {
+	name := HistoryName(record.kind, record.name)
+	var err error
+	for i := 0; i < historyAttempts; i++ {
+		history := &historycrd.History{}
+		if err = h.client.ReadCR(ctx, name, "", history); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return err
+			}
+			history = &historycrd.History{
+				TypeMeta:   metav1.TypeMeta{Kind: apiV1.HistoryKind, APIVersion: apiV1.APIV1Version},
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: api.History{
+					Kind:      record.kind,
+					Name:      record.name,
+					Namespace: record.namespace,
+					Entries:   []*api.HistoryEntry{record.entry},
+				},
+			}
+			err = h.client.CreateCR(ctx, name, history)
+		} else {
+			if !h.append(history, record.entry) {
+				return nil
+			}
+			err = h.client.UpdateCR(ctx, history)
+		}
+		if !k8serrors.IsConflict(err) && !k8serrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// append adds entry to the history, the oldest entries are dropped when limit is reached
+// Returns false if entry repeats the last one, history is kept compact and only first occurrence is stored
+func (h *History) append(history *historycrd.History, entry *api.HistoryEntry) bool {
+	entries := history.Spec.Entries
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		if last.Reason == entry.Reason && last.Message == entry.Message {
+			return false
+		}
+	}
+	entries = append(entries, entry)
+	if len(entries) > h.maxEntries {
+		entries = entries[len(entries)-h.maxEntries:]
+	}
+	history.Spec.Entries = entries
+	return true
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/historycrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+func TestHistory_Record(t *testing.T) {
+	history := prepareHistory(t, 10)
+	drive := &drivecrd.Drive{ObjectMeta: metav1.ObjectMeta{Name: "drive-uuid"}}
+	volume := &volumecrd.Volume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-uuid", Namespace: "app"}}
+
+	history.Record(drive, eventing.WarningType, "DriveHealthFailure", "drive failed")
+	history.Record(volume, eventing.NormalType, "VolumeDiscovered", "volume created")
+	history.Record(&lvgcrd.LogicalVolumeGroup{}, eventing.NormalType, "VolumeGroupScanInvolved", "scan")
+	assert.Len(t, history.queue, 2)
+
+	record := <-history.queue
+	assert.Equal(t, apiV1.DriveKind, record.kind)
+	assert.Equal(t, "drive-uuid", record.name)
+	assert.Equal(t, "DriveHealthFailure", record.entry.Reason)
+
+	record = <-history.queue
+	assert.Equal(t, apiV1.VolumeKind, record.kind)
+	assert.Equal(t, "app", record.namespace)
+}
+
+func TestHistory_Persist(t *testing.T) {
+	history := prepareHistory(t, 3)
+	ctx := context.Background()
+	name := HistoryName(apiV1.DriveKind, "drive-uuid")
+	assert.Equal(t, "drive-drive-uuid", name)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, history.persist(ctx, &historyRecord{
+			kind:  apiV1.DriveKind,
+			name:  "drive-uuid",
+			entry: &api.HistoryEntry{Time: int64(i), Reason: "Reason", Message: fmt.Sprintf("message %d", i)},
+		}))
+	}
+
+	cr := &historycrd.History{}
+	assert.Nil(t, history.client.ReadCR(ctx, name, "", cr))
+	assert.Equal(t, apiV1.DriveKind, cr.Spec.Kind)
+	assert.Equal(t, "drive-uuid", cr.Spec.Name)
+	assert.Len(t, cr.Spec.Entries, 3)
+	assert.Equal(t, "message 2", cr.Spec.Entries[0].Message)
+	assert.Equal(t, "message 4", cr.Spec.Entries[2].Message)
+
+	// repeated event isn't stored
+	assert.Nil(t, history.persist(ctx, &historyRecord{
+		kind:  apiV1.DriveKind,
+		name:  "drive-uuid",
+		entry: &api.HistoryEntry{Time: 5, Reason: "Reason", Message: "message 4"},
+	}))
+	assert.Nil(t, history.client.ReadCR(ctx, name, "", cr))
+	assert.Len(t, cr.Spec.Entries, 3)
+	assert.Equal(t, int64(4), cr.Spec.Entries[2].Time)
+}
+
+func TestHistory_Run(t *testing.T) {
+	history := prepareHistory(t, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		history.Run(ctx)
+		close(done)
+	}()
+
+	history.Record(&volumecrd.Volume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-uuid"}},
+		eventing.NormalType, "VolumeDiscovered", "volume created")
+	assert.Eventually(t, func() bool {
+		cr := &historycrd.History{}
+		return history.client.ReadCR(ctx, HistoryName(apiV1.VolumeKind, "pvc-uuid"), "", cr) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func prepareHistory(t *testing.T, maxEntries int) *History {
+	client, err := k8s.GetFakeKubeClient("default", logrus.New())
+	assert.Nil(t, err)
+	return NewHistory(client, maxEntries, logrus.New())
+}
@@ -37,29 +37,30 @@ var EmptyOutFail = CmdOut{
 
 // DiskCommands is the map that contains Linux commands output
 var DiskCommands = map[string]CmdOut{
-	"partprobe -d -s /dev/sda": {
-		Stdout: "(no output)",
+	"parted --script --machine /dev/sda unit B print": {
+		Stdout: "BYT;\n/dev/sda:1000204886016B:scsi:512:512:gpt:Mock disk:;\n",
 		Stderr: "",
 		Err:    nil,
 	},
-	"partprobe -d -s /dev/sdb": {
-		Stdout: "/dev/sda: msdos partitions 1",
+	"parted --script --machine /dev/sdb unit B print": {
+		Stdout: "BYT;\n/dev/sdb:1000204886016B:scsi:512:512:msdos:Mock disk:;\n" +
+			"1:1048576B:1000204140543B:1000203091968B:xfs::;\n",
 		Stderr: "",
 		Err:    nil,
 	},
-	"partprobe -d -s /dev/sdc": {
-		Stdout: "/dev/sda: msdos partitions",
+	"parted --script --machine /dev/sdc unit B print": {
+		Stdout: "BYT;\n/dev/sdc:1000204886016B:scsi:512:512:msdos:Mock disk:;\n",
 		Stderr: "",
 		Err:    nil,
 	},
-	"partprobe -d -s /dev/sdd": {
+	"parted --script --machine /dev/sdd unit B print": {
 		Stdout: "",
 		Stderr: "",
 		Err:    errors.New("unable to check partition existence for /dev/sdd"),
 	},
-	"partprobe -d -s /dev/sde":        EmptyOutSuccess,
-	"blockdev --rereadpt -v /dev/sde": EmptyOutSuccess,
-	"partprobe -d -s /dev/sdqwe": {
+	"parted --script --machine /dev/sde unit B print": EmptyOutSuccess,
+	"blockdev --rereadpt -v /dev/sde":                 EmptyOutSuccess,
+	"parted --script --machine /dev/sdqwe unit B print": {
 		Stdout: "",
 		Stderr: "",
 		Err:    errors.New("unable to get partition table"),
@@ -110,6 +111,16 @@ Partition name: 'CSI'`,
 		Err:    nil,
 	},
 	"sgdisk /dev/sdc --info=1": EmptyOutFail,
+	"sgdisk -u 1:64be631b-62a5-11e9-a756-00505680d67f /dev/sda": {
+		Stdout: "The operation has completed successfully.",
+		Stderr: "",
+		Err:    nil,
+	},
+	"sgdisk -t 1:0fc63daf-8483-4772-8e79-3d69d8477de4 /dev/sda": {
+		Stdout: "The operation has completed successfully.",
+		Stderr: "",
+		Err:    nil,
+	},
 }
 
 // NoLsblkKeyStr imitates lsblk output without normal key
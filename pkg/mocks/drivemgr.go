@@ -52,6 +52,11 @@ func (m *MockDriveMgrClientFail) LocateNode(ctx context.Context, in *api.NodeLoc
 	return nil, errors.New("locate node failed")
 }
 
+// GetInfo is a stub for GetInfo DriveManager's method
+func (m *MockDriveMgrClientFail) GetInfo(ctx context.Context, in *api.Empty, opts ...grpc.CallOption) (*api.DriveManagerInfo, error) {
+	return nil, errors.New("get info failed")
+}
+
 // NewMockDriveMgrClient returns new instance of MockDriveMgrClient
 // Receives slice of api.Drive which would be used in imitation of GetDrivesList
 func NewMockDriveMgrClient(drives []*api.Drive) *MockDriveMgrClient {
@@ -86,3 +91,8 @@ func (m *MockDriveMgrClient) Locate(ctx context.Context, in *api.DriveLocateRequ
 func (m *MockDriveMgrClient) LocateNode(ctx context.Context, in *api.NodeLocateRequest, opts ...grpc.CallOption) (*api.Empty, error) {
 	return nil, status.Error(codes.Unimplemented, "method LocateNode not implemented in MockDriveMgrClient")
 }
+
+// GetInfo is a stub for GetInfo DriveManager's method, imitates drive manager which doesn't support API negotiation
+func (m *MockDriveMgrClient) GetInfo(ctx context.Context, in *api.Empty, opts ...grpc.CallOption) (*api.DriveManagerInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetInfo not implemented in MockDriveMgrClient")
+}
@@ -133,7 +133,10 @@ func (e *MockExecutor) AddSecondRun(cmd string, res CmdOut) {
 // Receives cmd as interface and cast it to a string
 // Returns stdout, stderr, error for a given command
 func (e *MockExecutor) RunCmd(cmd interface{}, opts ...command.Options) (string, string, error) {
-	cmdStr := cmd.(string)
+	if err := cmdErr(cmd); err != nil {
+		return "", "", err
+	}
+	cmdStr := cmdKey(cmd).(string)
 	if len(e.secondRun) > 0 {
 		for _, c := range e.runBefore {
 			if c == cmdStr {
@@ -163,6 +166,22 @@ func (e *MockExecutor) RunCmdWithAttempts(cmd interface{}, attempts int, timeout
 	return e.RunCmd(cmd)
 }
 
+// cmdKey converts command.Cmd to command line, so it matches commands in string form which are set in mocks
+func cmdKey(cmd interface{}) interface{} {
+	if c, ok := cmd.(command.Cmd); ok {
+		return c.String()
+	}
+	return cmd
+}
+
+// cmdErr returns validation error of command.Cmd, such command isn't run by Executor
+func cmdErr(cmd interface{}) error {
+	if c, ok := cmd.(command.Cmd); ok {
+		return c.Err()
+	}
+	return nil
+}
+
 // RunCmd is the name of CmdExecutor method name
 var (
 	RunCmd             = "RunCmd"
@@ -177,13 +196,19 @@ type GoMockExecutor struct {
 
 // RunCmdWithAttempts simulates execution of a command with OnCommandWithAttempts where user can set what the method should return
 func (g *GoMockExecutor) RunCmdWithAttempts(cmd interface{}, attempts int, timeout time.Duration, opts ...command.Options) (string, string, error) {
-	args := g.Mock.Called(cmd.(string), attempts, timeout)
+	if err := cmdErr(cmd); err != nil {
+		return "", "", err
+	}
+	args := g.Mock.Called(cmdKey(cmd).(string), attempts, timeout)
 	return args.String(0), args.String(1), args.Error(2)
 }
 
 // RunCmd simulates execution of a command with OnCommand where user can set what the method should return
 func (g *GoMockExecutor) RunCmd(cmd interface{}, opts ...command.Options) (string, string, error) {
-	args := g.Mock.Called(cmd)
+	if err := cmdErr(cmd); err != nil {
+		return "", "", err
+	}
+	args := g.Mock.Called(cmdKey(cmd))
 	return args.String(0), args.String(1), args.Error(2)
 }
 
@@ -17,3 +17,13 @@ func (m *MockWrapDataDiscover) DiscoverData(device, serialNumber string) (*types
 
 	return args.Get(0).(*types.DiscoverResult), args.Error(1)
 }
+
+// ScanSignatures is a mock implementations
+func (m *MockWrapDataDiscover) ScanSignatures(device string) ([]string, error) {
+	args := m.Mock.Called(device)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
@@ -34,6 +34,16 @@ func (m *MockWrapFS) GetFSType(device string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+// GetSignatures is a mock implementations
+func (m *MockWrapFS) GetSignatures(device string) ([]string, error) {
+	args := m.Mock.Called(device)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 // GetFSSpace is a mock implementations
 func (m *MockWrapFS) GetFSSpace(src string) (int64, error) {
 	args := m.Mock.Called(src)
@@ -41,6 +51,16 @@ func (m *MockWrapFS) GetFSSpace(src string) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
+// GetFSStats is a mock implementations
+func (m *MockWrapFS) GetFSStats(path string) (*fs.FSStats, error) {
+	args := m.Mock.Called(path)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*fs.FSStats), args.Error(1)
+}
+
 // MkDir is a mock implementations
 func (m *MockWrapFS) MkDir(src string) error {
 	args := m.Mock.Called(src)
@@ -69,6 +89,20 @@ func (m *MockWrapFS) CreateFS(fsType fs.FileSystem, device string) error {
 	return args.Error(0)
 }
 
+// ResizeFS is a mock implementations
+func (m *MockWrapFS) ResizeFS(fsType fs.FileSystem, device, mountPoint string) error {
+	args := m.Mock.Called(fsType, device, mountPoint)
+
+	return args.Error(0)
+}
+
+// CheckFS is a mock implementations
+func (m *MockWrapFS) CheckFS(fsType fs.FileSystem, device string) error {
+	args := m.Mock.Called(fsType, device)
+
+	return args.Error(0)
+}
+
 // WipeFS is a mock implementations
 func (m *MockWrapFS) WipeFS(device string) error {
 	args := m.Mock.Called(device)
@@ -103,3 +137,10 @@ func (m *MockWrapFS) Unmount(src string) error {
 
 	return args.Error(0)
 }
+
+// LazyUnmount is a mock implementations
+func (m *MockWrapFS) LazyUnmount(src string) error {
+	args := m.Mock.Called(src)
+
+	return args.Error(0)
+}
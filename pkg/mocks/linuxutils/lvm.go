@@ -25,6 +25,20 @@ type MockWrapLVM struct {
 	mock.Mock
 }
 
+// PVResize is a mock implementations
+func (m *MockWrapLVM) PVResize(dev string) error {
+	args := m.Mock.Called(dev)
+
+	return args.Error(0)
+}
+
+// GetVGSize is a mock implementations
+func (m *MockWrapLVM) GetVGSize(vgName string) (int64, error) {
+	args := m.Mock.Called(vgName)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // ExpandLV is a mock implementations
 func (m *MockWrapLVM) ExpandLV(lvName string, requiredSize int64) error {
 	args := m.Mock.Called(lvName, requiredSize)
@@ -67,6 +81,48 @@ func (m *MockWrapLVM) VGReactivate(name string) error {
 	return args.Error(0)
 }
 
+// GetVGMissingPVs is a mock implementation
+func (m *MockWrapLVM) GetVGMissingPVs(name string) (int, error) {
+	args := m.Mock.Called(name)
+
+	return args.Int(0), args.Error(1)
+}
+
+// VGActivateDegraded is a mock implementation
+func (m *MockWrapLVM) VGActivateDegraded(name string) error {
+	args := m.Mock.Called(name)
+
+	return args.Error(0)
+}
+
+// VGReduceMissing is a mock implementation
+func (m *MockWrapLVM) VGReduceMissing(name string) error {
+	args := m.Mock.Called(name)
+
+	return args.Error(0)
+}
+
+// GetVGSeqNo is a mock implementations
+func (m *MockWrapLVM) GetVGSeqNo(name string) (int64, error) {
+	args := m.Mock.Called(name)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// VGCfgBackup is a mock implementations
+func (m *MockWrapLVM) VGCfgBackup(name, file string) error {
+	args := m.Mock.Called(name, file)
+
+	return args.Error(0)
+}
+
+// VGCfgRestore is a mock implementations
+func (m *MockWrapLVM) VGCfgRestore(name, file string) error {
+	args := m.Mock.Called(name, file)
+
+	return args.Error(0)
+}
+
 // VGRemove is a mock implementations
 func (m *MockWrapLVM) VGRemove(name string) error {
 	args := m.Mock.Called(name)
@@ -81,6 +137,27 @@ func (m *MockWrapLVM) LVCreate(name, size, vgName string) error {
 	return args.Error(0)
 }
 
+// LVCreateThinSnapshot is a mock implementations
+func (m *MockWrapLVM) LVCreateThinSnapshot(name, origin, vgName string) error {
+	args := m.Mock.Called(name, origin, vgName)
+
+	return args.Error(0)
+}
+
+// LVCreateReadOnlySnapshot is a mock implementations
+func (m *MockWrapLVM) LVCreateReadOnlySnapshot(name, size, origin, vgName string) error {
+	args := m.Mock.Called(name, size, origin, vgName)
+
+	return args.Error(0)
+}
+
+// GetLVSize is a mock implementations
+func (m *MockWrapLVM) GetLVSize(fullLVName string) (int64, error) {
+	args := m.Mock.Called(fullLVName)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // LVRemove is a mock implementations
 func (m *MockWrapLVM) LVRemove(fullLVName string) error {
 	args := m.Mock.Called(fullLVName)
@@ -33,3 +33,17 @@ func (m *MockWrapNvmecli) GetNVMDevices() ([]nvmecli.NVMDevice, error) {
 
 	return args.Get(0).([]nvmecli.NVMDevice), args.Error(1)
 }
+
+// ListNVMDevices is a mock implementations
+func (m *MockWrapNvmecli) ListNVMDevices() ([]nvmecli.NVMDevice, error) {
+	args := m.Mock.Called()
+
+	return args.Get(0).([]nvmecli.NVMDevice), args.Error(1)
+}
+
+// FillNVMDevice is a mock implementations, fields of device are replaced with the mocked value
+func (m *MockWrapNvmecli) FillNVMDevice(device *nvmecli.NVMDevice) {
+	args := m.Mock.Called(device.DevicePath)
+
+	*device = args.Get(0).(nvmecli.NVMDevice)
+}
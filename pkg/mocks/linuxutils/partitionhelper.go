@@ -81,6 +81,30 @@ func (m *MockWrapPartition) GetPartitionUUID(device, partNum string) (string, er
 	return args.String(0), args.Error(1)
 }
 
+// SetPartitionUUID is a mock implementations
+func (m *MockWrapPartition) SetPartitionUUID(device, partNum, partUUID string) error {
+	args := m.Mock.Called(device, partNum, partUUID)
+
+	return args.Error(0)
+}
+
+// GetPartitionTypes is a mock implementations
+func (m *MockWrapPartition) GetPartitionTypes(device string) (map[string]string, error) {
+	args := m.Mock.Called(device)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+// SetPartitionType is a mock implementations
+func (m *MockWrapPartition) SetPartitionType(device, partNum, typeGUID string) error {
+	args := m.Mock.Called(device, partNum, typeGUID)
+
+	return args.Error(0)
+}
+
 // SyncPartitionTable is a mock implementations
 func (m *MockWrapPartition) SyncPartitionTable(device string) error {
 	args := m.Mock.Called(device)
@@ -0,0 +1,45 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+)
+
+// MockWrapAlignment is a mock implementation of WrapAlignment interface from partitionhelper package
+type MockWrapAlignment struct {
+	mock.Mock
+}
+
+// GetPartitionAlignment is a mock implementation
+func (m *MockWrapAlignment) GetPartitionAlignment(partition string) (*ph.PartitionAlignment, error) {
+	args := m.Mock.Called(partition)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ph.PartitionAlignment), args.Error(1)
+}
+
+// RealignPartition is a mock implementation
+func (m *MockWrapAlignment) RealignPartition(a *ph.PartitionAlignment, newStart int64) error {
+	args := m.Mock.Called(a, newStart)
+
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWrapGrow is a mock implementation of WrapGrow interface from partitionhelper package
+type MockWrapGrow struct {
+	mock.Mock
+}
+
+// GrowPartition is a mock implementation
+func (m *MockWrapGrow) GrowPartition(device, partNum string) error {
+	args := m.Mock.Called(device, partNum)
+
+	return args.Error(0)
+}
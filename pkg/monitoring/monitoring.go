@@ -0,0 +1,317 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package monitoring generates Grafana dashboard and Prometheus alerting rules for metrics of CSI components.
+// Operator creates generated objects for the installed feature set and regenerates them when it changes
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Feature is an optional feature of CSI which exposes its own metrics
+type Feature string
+
+// Features which have panels and alerts. FeatureBase covers metrics which are exposed by any installation
+const (
+	FeatureBase               Feature = "base"
+	FeatureCapacityMonitoring Feature = "capacity-monitoring"
+	FeatureDriveTemperature   Feature = "drive-temperature"
+	FeatureVolumeIOStats      Feature = "volume-io-stats"
+)
+
+const (
+	// DashboardConfigMapName is a name of ConfigMap with Grafana dashboard
+	DashboardConfigMapName = "csi-baremetal-grafana-dashboard"
+	// DashboardKey is a key of dashboard JSON model in ConfigMap
+	DashboardKey = "csi-baremetal.json"
+	// DashboardLabel is a label which Grafana sidecar uses to discover ConfigMaps with dashboards
+	DashboardLabel = "grafana_dashboard"
+	// PrometheusRuleName is a name of PrometheusRule object
+	PrometheusRuleName = "csi-baremetal-alerts"
+	// FeaturesAnnotation holds comma separated features which objects were generated for.
+	// Operator compares it with installed features to find out whether objects should be regenerated
+	FeaturesAnnotation = "csi-baremetal.dell.com/monitoring-features"
+
+	dashboardUID   = "csi-baremetal"
+	dashboardTitle = "CSI Baremetal"
+	panelWidth     = 12
+	panelHeight    = 8
+)
+
+// PrometheusRuleGVK is GroupVersionKind of PrometheusRule of Prometheus operator
+var PrometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+// panel is a graph of Grafana dashboard
+type panel struct {
+	title  string
+	unit   string
+	legend string
+	expr   string
+}
+
+// Rule is an alerting rule of PrometheusRule
+type Rule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// RuleGroup is a group of alerting rules of PrometheusRule
+type RuleGroup struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// featureMonitoring holds panels and alerting rules of feature
+type featureMonitoring struct {
+	panels []panel
+	rules  []Rule
+}
+
+// features holds panels and alerting rules per feature, panels are shown in dashboard row named as feature
+var features = map[Feature]featureMonitoring{
+	FeatureBase: {
+		panels: []panel{
+			{title: "Discovered drives", legend: "{{pod}}", expr: "discovery_drive_count"},
+			{title: "Volume operations p95 duration", unit: "s", legend: "{{method}}",
+				expr: "histogram_quantile(0.95, sum by (le, method) (rate(volume_operations_duration_bucket[5m])))"},
+			{title: "Partition operations p95 duration", unit: "s", legend: "{{method}}",
+				expr: "histogram_quantile(0.95, sum by (le, method) (rate(partition_operations_duration_bucket[5m])))"},
+			{title: "Drive manager discovery p95 duration", unit: "s", legend: "{{pod}}",
+				expr: "histogram_quantile(0.95, sum by (le, pod) (rate(discovery_duration_seconds_bucket[5m])))"},
+		},
+		rules: []Rule{
+			{
+				Alert: "CSIBaremetalSlowVolumeOperations",
+				Expr:  "histogram_quantile(0.95, sum by (le, method) (rate(volume_operations_duration_bucket[5m]))) > 60",
+				For:   "15m",
+				Annotations: map[string]string{
+					"summary": "95th percentile of {{ $labels.method }} volume operation duration is higher than 60s",
+				},
+			},
+		},
+	},
+	FeatureCapacityMonitoring: {
+		panels: []panel{
+			{title: "Free capacity", unit: "percent", legend: "{{node}} {{media_type}}",
+				expr: "available_capacity_free_ratio"},
+		},
+		rules: []Rule{
+			{
+				Alert: "CSIBaremetalLowCapacity",
+				Expr:  "available_capacity_low == 1",
+				For:   "5m",
+				Annotations: map[string]string{
+					"summary": "Free {{ $labels.media_type }} capacity of node {{ $labels.node }} is below the threshold",
+				},
+			},
+		},
+	},
+	FeatureDriveTemperature: {
+		panels: []panel{
+			{title: "Drive temperature", unit: "celsius", legend: "{{serial_number}} {{type}}",
+				expr: "drive_temperature_celsius"},
+		},
+	},
+	FeatureVolumeIOStats: {
+		panels: []panel{
+			{title: "Volume IOPS", unit: "iops", legend: "{{namespace}}/{{pvc}} {{operation}}",
+				expr: "volume_iops"},
+			{title: "Volume throughput", unit: "Bps", legend: "{{namespace}}/{{pvc}} {{operation}}",
+				expr: "volume_throughput_bytes_per_second"},
+			{title: "Volume I/O latency", unit: "s", legend: "{{namespace}}/{{pvc}} {{operation}}",
+				expr: "volume_io_latency_seconds"},
+		},
+		rules: []Rule{
+			{
+				Alert: "CSIBaremetalHighVolumeLatency",
+				Expr:  "volume_io_latency_seconds > 0.5",
+				For:   "10m",
+				Annotations: map[string]string{
+					"summary": "Average {{ $labels.operation }} latency of volume of PVC " +
+						"{{ $labels.namespace }}/{{ $labels.pvc }} is higher than 500ms",
+				},
+			},
+		},
+	},
+}
+
+// normalize returns sorted features with FeatureBase, error if feature is unknown
+func normalize(installed []Feature) ([]Feature, error) {
+	set := map[Feature]bool{FeatureBase: true}
+	for _, f := range installed {
+		if _, ok := features[f]; !ok {
+			return nil, fmt.Errorf("monitoring of feature %s isn't supported", f)
+		}
+		set[f] = true
+	}
+	res := make([]Feature, 0, len(set))
+	for f := range set {
+		res = append(res, f)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		// base row goes first
+		if res[i] == FeatureBase || res[j] == FeatureBase {
+			return res[i] == FeatureBase
+		}
+		return res[i] < res[j]
+	})
+	return res, nil
+}
+
+// FeaturesValue returns value of FeaturesAnnotation for installed features
+func FeaturesValue(installed []Feature) (string, error) {
+	normalized, err := normalize(installed)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(normalized))
+	for _, f := range normalized {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ","), nil
+}
+
+// Dashboard returns JSON model of Grafana dashboard with row of panels per installed feature
+func Dashboard(installed []Feature) ([]byte, error) {
+	normalized, err := normalize(installed)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		panels = make([]map[string]interface{}, 0)
+		id     = 1
+		y      = 0
+	)
+	for _, f := range normalized {
+		panels = append(panels, map[string]interface{}{
+			"id":      id,
+			"type":    "row",
+			"title":   string(f),
+			"gridPos": map[string]int{"h": 1, "w": 2 * panelWidth, "x": 0, "y": y},
+		})
+		id++
+		y++
+		for i, p := range features[f].panels {
+			panels = append(panels, map[string]interface{}{
+				"id":          id,
+				"type":        "timeseries",
+				"title":       p.title,
+				"datasource":  "${datasource}",
+				"fieldConfig": map[string]interface{}{"defaults": map[string]string{"unit": p.unit}},
+				"gridPos":     map[string]int{"h": panelHeight, "w": panelWidth, "x": (i % 2) * panelWidth, "y": y},
+				"targets": []map[string]string{
+					{"expr": p.expr, "legendFormat": p.legend, "refId": "A"},
+				},
+			})
+			id++
+			if i%2 == 1 {
+				y += panelHeight
+			}
+		}
+		if len(features[f].panels)%2 == 1 {
+			y += panelHeight
+		}
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"uid":           dashboardUID,
+		"title":         dashboardTitle,
+		"schemaVersion": 30,
+		"editable":      false,
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"templating": map[string]interface{}{
+			"list": []map[string]interface{}{
+				{"name": "datasource", "type": "datasource", "query": "prometheus"},
+			},
+		},
+		"panels": panels,
+	}, "", "  ")
+}
+
+// DashboardConfigMap returns ConfigMap with Grafana dashboard for installed features in namespace
+func DashboardConfigMap(installed []Feature, namespace string) (*corev1.ConfigMap, error) {
+	dashboard, err := Dashboard(installed)
+	if err != nil {
+		return nil, err
+	}
+	value, err := FeaturesValue(installed)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        DashboardConfigMapName,
+			Namespace:   namespace,
+			Labels:      map[string]string{DashboardLabel: "1"},
+			Annotations: map[string]string{FeaturesAnnotation: value},
+		},
+		Data: map[string]string{DashboardKey: string(dashboard)},
+	}, nil
+}
+
+// RuleGroups returns group of alerting rules per installed feature which has rules
+func RuleGroups(installed []Feature) ([]RuleGroup, error) {
+	normalized, err := normalize(installed)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]RuleGroup, 0, len(normalized))
+	for _, f := range normalized {
+		if len(features[f].rules) == 0 {
+			continue
+		}
+		groups = append(groups, RuleGroup{Name: "csi-baremetal-" + string(f), Rules: features[f].rules})
+	}
+	return groups, nil
+}
+
+// PrometheusRule returns PrometheusRule object with alerting rules for installed features in namespace.
+// Object is unstructured, so Prometheus operator API isn't required to create it
+func PrometheusRule(installed []Feature, namespace string) (*unstructured.Unstructured, error) {
+	groups, err := RuleGroups(installed)
+	if err != nil {
+		return nil, err
+	}
+	value, err := FeaturesValue(installed)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&struct {
+		Groups []RuleGroup `json:"groups"`
+	}{Groups: groups})
+	if err != nil {
+		return nil, err
+	}
+	rule := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	rule.SetGroupVersionKind(PrometheusRuleGVK)
+	rule.SetName(PrometheusRuleName)
+	rule.SetNamespace(namespace)
+	rule.SetAnnotations(map[string]string{FeaturesAnnotation: value})
+	return rule, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitoring
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeaturesValue(t *testing.T) {
+	value, err := FeaturesValue([]Feature{FeatureVolumeIOStats, FeatureCapacityMonitoring, FeatureVolumeIOStats})
+	assert.Nil(t, err)
+	assert.Equal(t, "base,capacity-monitoring,volume-io-stats", value)
+
+	value, err = FeaturesValue(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "base", value)
+
+	_, err = FeaturesValue([]Feature{"unknown"})
+	assert.NotNil(t, err)
+}
+
+func TestDashboardConfigMap(t *testing.T) {
+	cm, err := DashboardConfigMap([]Feature{FeatureVolumeIOStats}, "csi")
+	assert.Nil(t, err)
+	assert.Equal(t, "csi", cm.Namespace)
+	assert.Equal(t, "1", cm.Labels[DashboardLabel])
+	assert.Equal(t, "base,volume-io-stats", cm.Annotations[FeaturesAnnotation])
+
+	var dashboard struct {
+		UID    string `json:"uid"`
+		Panels []struct {
+			Type    string `json:"type"`
+			Title   string `json:"title"`
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(cm.Data[DashboardKey]), &dashboard))
+	assert.Equal(t, dashboardUID, dashboard.UID)
+	rows, exprs := []string{}, []string{}
+	for _, p := range dashboard.Panels {
+		if p.Type == "row" {
+			rows = append(rows, p.Title)
+			continue
+		}
+		exprs = append(exprs, p.Targets[0].Expr)
+	}
+	assert.Equal(t, []string{"base", "volume-io-stats"}, rows)
+	assert.Contains(t, exprs, "volume_iops")
+	assert.NotContains(t, exprs, "drive_temperature_celsius")
+
+	_, err = DashboardConfigMap([]Feature{"unknown"}, "csi")
+	assert.NotNil(t, err)
+}
+
+func TestPrometheusRule(t *testing.T) {
+	// drive temperature doesn't have alerts
+	rule, err := PrometheusRule([]Feature{FeatureCapacityMonitoring, FeatureDriveTemperature}, "csi")
+	assert.Nil(t, err)
+	assert.Equal(t, PrometheusRuleGVK, rule.GroupVersionKind())
+	assert.Equal(t, "csi", rule.GetNamespace())
+	assert.Equal(t, "base,capacity-monitoring,drive-temperature", rule.GetAnnotations()[FeaturesAnnotation])
+
+	groups, ok := rule.Object["spec"].(map[string]interface{})["groups"].([]interface{})
+	assert.True(t, ok)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "csi-baremetal-capacity-monitoring", groups[1].(map[string]interface{})["name"])
+
+	_, err = PrometheusRule([]Feature{"unknown"}, "csi")
+	assert.NotNil(t, err)
+}
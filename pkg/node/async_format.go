@@ -0,0 +1,183 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base"
+)
+
+const (
+	// formatStatusUpdateAttempts is an amount of attempts to set result of asynchronous formatting
+	formatStatusUpdateAttempts = 5
+	// formatWaitTimeout is a max time which NodeStageVolume waits for asynchronous formatting
+	formatWaitTimeout = 60 * time.Second
+	// formatCheckInterval is an interval between volume status checks in NodeStageVolume
+	formatCheckInterval = time.Second
+)
+
+// asyncFormatter tracks volumes which filesystem is being created in background
+type asyncFormatter struct {
+	// volume ID -> struct{}
+	jobs sync.Map
+}
+
+// SetAsyncFormatting enables asynchronous filesystem creation: volume stays in Creating status with format annotation
+// while mkfs is running, so CreateVolume doesn't wait for it, and NodeStageVolume waits for completion
+func (m *VolumeManager) SetAsyncFormatting() {
+	m.asyncFormatter = &asyncFormatter{}
+}
+
+// isAsyncFormatting returns true if filesystem of volume should be created in background
+func (m *VolumeManager) isAsyncFormatting(volume *volumecrd.Volume) bool {
+	return m.asyncFormatter != nil && !volume.Spec.Ephemeral && len(prepareOperations(volume)) > 0
+}
+
+// prepareVolumeAsync marks volume with in-progress format annotation and prepares it in background
+// Caller must hold lock of volume location
+func (m *VolumeManager) prepareVolumeAsync(ctx context.Context, volume *volumecrd.Volume) (ctrl.Result, error) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "prepareVolumeAsync",
+		"volumeID": volume.Spec.Id,
+	})
+
+	if _, running := m.asyncFormatter.jobs.Load(volume.Name); running {
+		ll.Debug("Volume is being formatted")
+		return ctrl.Result{}, nil
+	}
+
+	done, ok := m.operations.Begin("format " + volume.Name)
+	if !ok {
+		ll.Info("Node service is shutting down, volume will be formatted after restart")
+		return ctrl.Result{RequeueAfter: base.DefaultRequeueForVolume}, nil
+	}
+
+	if volume.Annotations[apiV1.VolumeAnnotationFormat] == apiV1.VolumeAnnotationFormatInProgress {
+		// node service was restarted during formatting, FS might be created partially
+		ll.Warn("Formatting of volume was interrupted, restart it")
+		if path, err := m.getProvisionerForVolume(&volume.Spec).GetVolumePath(&volume.Spec); err == nil {
+			if err = m.fsOps.WipeFS(path); err != nil {
+				ll.Errorf("Unable to wipe FS on %s: %v", path, err)
+			}
+		}
+	}
+
+	if volume.Annotations == nil {
+		volume.Annotations = make(map[string]string)
+	}
+	volume.Annotations[apiV1.VolumeAnnotationFormat] = apiV1.VolumeAnnotationFormatInProgress
+	delete(volume.Annotations, apiV1.VolumeAnnotationFormatError)
+	if err := m.k8sClient.UpdateCRWithAttempts(ctx, volume, formatStatusUpdateAttempts); err != nil {
+		ll.Errorf("Unable to set format annotation: %v", err)
+		done()
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	m.asyncFormatter.jobs.Store(volume.Name, struct{}{})
+	go func() {
+		defer done()
+		m.formatVolume(volume.DeepCopy())
+	}()
+	ll.Infof("Volume with FS %s is being formatted in background", volume.Spec.Type)
+	return ctrl.Result{}, nil
+}
+
+// formatVolume prepares volume holding lock of its location and sets result in Volume CR
+func (m *VolumeManager) formatVolume(volume *volumecrd.Volume) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "formatVolume",
+		"volumeID": volume.Spec.Id,
+	})
+	ctx := context.WithValue(context.Background(), base.RequestUUID, volume.Name)
+	defer m.asyncFormatter.jobs.Delete(volume.Name)
+	unlock := m.lockLocation(ll, volume.Spec.Location)
+	defer unlock()
+
+	startTime := time.Now()
+	err := m.prepareVolumeDevice(ctx, volume)
+	m.auditVolumeOperations(ctx, volume, volume.Spec.Location, prepareOperations(volume), err)
+	m.reportDriveOperation(ctx, volume, err)
+
+	newStatus, formatStatus := apiV1.Created, apiV1.VolumeAnnotationFormatDone
+	if err != nil {
+		ll.Errorf("Unable to create volume size of %d bytes: %v. Set volume status to Failed", volume.Spec.Size, err)
+		newStatus, formatStatus = apiV1.Failed, apiV1.VolumeAnnotationFormatFailed
+	} else {
+		ll.Infof("Volume was formatted in %s", time.Since(startTime))
+	}
+
+	// volume CR might be changed during formatting, so the latest version is updated
+	for i := 0; i < formatStatusUpdateAttempts; i++ {
+		if readErr := m.k8sClient.ReadCR(ctx, volume.Name, volume.Namespace, volume); readErr != nil {
+			ll.Errorf("Unable to read volume CR: %v", readErr)
+			continue
+		}
+		volume.Spec.CSIStatus = newStatus
+		if volume.Annotations == nil {
+			volume.Annotations = make(map[string]string)
+		}
+		volume.Annotations[apiV1.VolumeAnnotationFormat] = formatStatus
+		if err != nil {
+			volume.Annotations[apiV1.VolumeAnnotationFormatError] = err.Error()
+		} else {
+			m.recordVolumeOwnership(volume)
+		}
+		updateErr := m.k8sClient.UpdateCR(ctx, volume)
+		if updateErr == nil {
+			return
+		}
+		ll.Warnf("Unable to update volume status to %s, attempt %d out of %d: %v",
+			newStatus, i+1, formatStatusUpdateAttempts, updateErr)
+	}
+	ll.Errorf("Unable to update volume status to %s, volume will be formatted again", newStatus)
+}
+
+// waitForFormatting waits until asynchronous formatting of volume is finished
+// Returns the latest Volume CR, volume is still Creating if formatting isn't finished in time
+func (s *CSINodeService) waitForFormatting(ctx context.Context, volume *volumecrd.Volume) *volumecrd.Volume {
+	ll := s.log.WithFields(logrus.Fields{
+		"method":   "waitForFormatting",
+		"volumeID": volume.Spec.Id,
+	})
+	ctx, cancelFn := context.WithTimeout(ctx, formatWaitTimeout)
+	defer cancelFn()
+
+	ll.Info("Waiting for volume formatting")
+	for volume.Spec.CSIStatus == apiV1.Creating {
+		select {
+		case <-ctx.Done():
+			ll.Warn("Volume is still being formatted")
+			return volume
+		case <-time.After(formatCheckInterval):
+			updated, err := s.crHelper.GetVolumeByID(volume.Spec.Id)
+			if err != nil {
+				ll.Errorf("Unable to read volume: %v", err)
+				continue
+			}
+			volume = updated
+		}
+	}
+	return volume
+}
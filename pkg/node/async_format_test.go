@@ -0,0 +1,116 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	vcrd "github.com/dell/csi-baremetal/api/v1/volumecrd"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+func TestVolumeManager_prepareVolumeAsync(t *testing.T) {
+	var (
+		vm      = prepareSuccessVolumeManager(t)
+		testVol = volCR.DeepCopy()
+		volume  = &vcrd.Volume{}
+	)
+	vm.SetAsyncFormatting()
+	assert.True(t, vm.isAsyncFormatting(testVol))
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, testVol.Name, testVol))
+
+	// volume stays in Creating status until formatting is finished
+	pMock := &mockProv.MockProvisioner{}
+	done := make(chan time.Time)
+	pMock.On("PrepareVolume", &testVol.Spec).Return(nil).WaitUntil(done)
+	vm.SetProvisioners(map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: pMock})
+
+	_, err := vm.prepareVolume(testCtx, testVol)
+	assert.Nil(t, err)
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, testVol.Name, testNs, volume))
+	assert.Equal(t, apiV1.Creating, volume.Spec.CSIStatus)
+	assert.Equal(t, apiV1.VolumeAnnotationFormatInProgress, volume.Annotations[apiV1.VolumeAnnotationFormat])
+
+	// repeated reconcile doesn't start formatting again
+	_, err = vm.prepareVolume(testCtx, volume)
+	assert.Nil(t, err)
+
+	close(done)
+	assert.Eventually(t, func() bool {
+		_ = vm.k8sClient.ReadCR(testCtx, testVol.Name, testNs, volume)
+		return volume.Spec.CSIStatus == apiV1.Created
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Equal(t, apiV1.VolumeAnnotationFormatDone, volume.Annotations[apiV1.VolumeAnnotationFormat])
+	pMock.AssertNumberOfCalls(t, "PrepareVolume", 1)
+
+	// formatting failed
+	vm = prepareSuccessVolumeManager(t)
+	vm.SetAsyncFormatting()
+	testVol = volCR.DeepCopy()
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, testVol.Name, testVol))
+	pMock = &mockProv.MockProvisioner{}
+	pMock.On("PrepareVolume", &testVol.Spec).Return(testErr)
+	vm.SetProvisioners(map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: pMock})
+
+	_, err = vm.prepareVolume(testCtx, testVol)
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		_ = vm.k8sClient.ReadCR(testCtx, testVol.Name, testNs, volume)
+		return volume.Spec.CSIStatus == apiV1.Failed
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Equal(t, apiV1.VolumeAnnotationFormatFailed, volume.Annotations[apiV1.VolumeAnnotationFormat])
+	assert.Equal(t, testErr.Error(), volume.Annotations[apiV1.VolumeAnnotationFormatError])
+
+	// raw volume is prepared synchronously
+	testVol.Spec.Mode = apiV1.ModeRAW
+	assert.False(t, vm.isAsyncFormatting(testVol))
+}
+
+func TestCSINodeService_NodeStageVolume_Formatting(t *testing.T) {
+	node := newNodeService()
+	req := getNodeStageRequest(testVolume1.Id, *testVolumeCap)
+
+	// formatting isn't finished in time
+	vol := &vcrd.Volume{}
+	assert.Nil(t, node.k8sClient.ReadCR(testCtx, testVolume1.Id, "", vol))
+	vol.Spec.CSIStatus = apiV1.Creating
+	vol.Annotations = map[string]string{apiV1.VolumeAnnotationFormat: apiV1.VolumeAnnotationFormatInProgress}
+	assert.Nil(t, node.k8sClient.UpdateCR(testCtx, vol))
+
+	ctx, cancelFn := context.WithTimeout(testCtx, 200*time.Millisecond)
+	defer cancelFn()
+	_, err := node.NodeStageVolume(ctx, req)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// formatting failed
+	vol.Spec.CSIStatus = apiV1.Failed
+	vol.Annotations[apiV1.VolumeAnnotationFormat] = apiV1.VolumeAnnotationFormatFailed
+	vol.Annotations[apiV1.VolumeAnnotationFormatError] = "mkfs error"
+	assert.Nil(t, node.k8sClient.UpdateCR(testCtx, vol))
+
+	_, err = node.NodeStageVolume(testCtx, req)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, err.Error(), "mkfs error")
+}
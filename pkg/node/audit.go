@@ -0,0 +1,114 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/audit"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// SetAuditor enables recording of destructive operations (mkfs, wipefs, partition delete, LV removal)
+// into audit trail, each record is also sent as event for Volume CR
+func (m *VolumeManager) SetAuditor(auditor *audit.Auditor) {
+	m.auditor = auditor
+}
+
+// prepareOperations returns destructive operations which are performed by provisioner during volume preparation
+func prepareOperations(volume *volumecrd.Volume) []audit.Operation {
+	if volume.Spec.Mode == apiV1.ModeRAW || volume.Spec.Mode == apiV1.ModeRAWPART || volume.Spec.Encryption != "" ||
+		volume.Spec.LazyFormat {
+		return nil
+	}
+	return []audit.Operation{audit.MakeFS}
+}
+
+// releaseOperations returns destructive operations which are performed by provisioner during volume removal
+func releaseOperations(volume *volumecrd.Volume) []audit.Operation {
+	if util.IsStorageClassLVG(volume.Spec.StorageClass) {
+		return []audit.Operation{audit.WipeFS, audit.RemoveLV}
+	}
+	return []audit.Operation{audit.WipeFS, audit.DeletePartition}
+}
+
+// auditVolumeOperations records destructive operations performed for volume into audit trail
+// Receives golang context, Volume CR, device on which operations were performed, list of operations and
+// error of provisioner
+func (m *VolumeManager) auditVolumeOperations(ctx context.Context, volume *volumecrd.Volume, device string,
+	operations []audit.Operation, opErr error) {
+	if m.auditor == nil || len(operations) == 0 {
+		return
+	}
+
+	pvcName, namespace, requester := m.getVolumeRequester(ctx, volume)
+	result, errMsg := audit.ResultSuccess, ""
+	event := eventing.DestructiveOperationPerformed
+	if opErr != nil {
+		result, errMsg = audit.ResultFailure, opErr.Error()
+		event = eventing.DestructiveOperationFailed
+	}
+
+	for _, op := range operations {
+		record := &audit.Record{
+			Operation: op,
+			NodeID:    m.nodeID,
+			Device:    device,
+			VolumeID:  volume.Spec.Id,
+			PVC:       pvcName,
+			Namespace: namespace,
+			Requester: requester,
+			Result:    result,
+			Error:     errMsg,
+		}
+		m.auditor.Record(record)
+		m.recorder.Eventf(volume, event, "Audit: %s, NodeName='%s'", record, m.nodeName)
+	}
+}
+
+// getVolumeRequester determines PVC, namespace and identity of operator who requested volume operation
+// PVC is taken from claim reference of PV, PV might be already removed during volume deletion.
+// Requester is taken from PVC annotation or from the last field manager of Volume CR
+func (m *VolumeManager) getVolumeRequester(ctx context.Context, volume *volumecrd.Volume) (pvcName, namespace,
+	requester string) {
+	namespace = volume.Namespace
+	if n := len(volume.ManagedFields); n > 0 {
+		requester = volume.ManagedFields[n-1].Manager
+	}
+
+	pv := &corev1.PersistentVolume{}
+	if err := m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: volume.Spec.Id}, pv); err != nil || pv.Spec.ClaimRef == nil {
+		m.log.Debugf("Unable to determine PVC for volume %s: %v", volume.Spec.Id, err)
+		return
+	}
+	pvcName, namespace = pv.Spec.ClaimRef.Name, pv.Spec.ClaimRef.Namespace
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: pvcName, Namespace: namespace}, pvc); err != nil {
+		return
+	}
+	if value, ok := pvc.Annotations[audit.RequesterAnnotationKey]; ok && value != "" {
+		requester = value
+	}
+	return
+}
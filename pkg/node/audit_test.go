@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/audit"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+type auditSinkMock struct {
+	records []*audit.Record
+}
+
+func (a *auditSinkMock) Write(r *audit.Record) error {
+	a.records = append(a.records, r)
+	return nil
+}
+
+func TestVolumeManager_auditVolumeOperations(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		sink     = &auditSinkMock{}
+		pvc      = &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name:        "data",
+			Namespace:   "app",
+			Annotations: map[string]string{audit.RequesterAnnotationKey: "admin"},
+		}}
+		pv = &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: volCR.Spec.Id},
+			Spec: corev1.PersistentVolumeSpec{
+				ClaimRef: &corev1.ObjectReference{Name: pvc.Name, Namespace: pvc.Namespace},
+			},
+		}
+	)
+	vm.recorder = recorder
+
+	testVol := volCR.DeepCopy()
+	testVol.Spec.CSIStatus = apiV1.Removing
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, testVol.Name, testVol))
+	drive := testDriveCR.DeepCopy()
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, testVol.Spec.Location, drive))
+	vm.SetProvisioners(map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: mockProv.GetMockProvisionerSuccess("/some/path")})
+
+	// disabled
+	_, err := vm.handleRemovingStatus(testCtx, testVol)
+	assert.Nil(t, err)
+	assert.Empty(t, recorder.Calls)
+
+	vm.SetAuditor(audit.NewAuditor(logrus.New(), sink))
+	assert.Nil(t, vm.k8sClient.Create(testCtx, pv))
+	assert.Nil(t, vm.k8sClient.Create(testCtx, pvc))
+
+	_, err = vm.handleRemovingStatus(testCtx, testVol)
+	assert.Nil(t, err)
+	assert.Len(t, sink.records, 2)
+	assert.Equal(t, audit.WipeFS, sink.records[0].Operation)
+	assert.Equal(t, audit.DeletePartition, sink.records[1].Operation)
+	for _, r := range sink.records {
+		assert.Equal(t, drive.Spec.Path, r.Device)
+		assert.Equal(t, testVol.Spec.Id, r.VolumeID)
+		assert.Equal(t, "data", r.PVC)
+		assert.Equal(t, "app", r.Namespace)
+		assert.Equal(t, "admin", r.Requester)
+		assert.Equal(t, audit.ResultSuccess, r.Result)
+		assert.Equal(t, nodeID, r.NodeID)
+	}
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.DestructiveOperationPerformed, recorder.Calls[0].Event)
+
+	// mkfs isn't performed for raw volumes
+	testVol.Spec.Mode = apiV1.ModeRAW
+	assert.Empty(t, prepareOperations(testVol))
+	testVol.Spec.Mode = apiV1.ModeFS
+	assert.Equal(t, []audit.Operation{audit.MakeFS}, prepareOperations(testVol))
+	testVol.Spec.StorageClass = apiV1.StorageClassHDDLVG
+	assert.Equal(t, []audit.Operation{audit.WipeFS, audit.RemoveLV}, releaseOperations(testVol))
+}
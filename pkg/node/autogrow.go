@@ -0,0 +1,295 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	baseerr "github.com/dell/csi-baremetal/pkg/base/error"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// Default settings of volume autogrow
+const (
+	DefaultAutogrowThreshold   = 80
+	DefaultAutogrowStepPercent = 10
+)
+
+// VolumeAutogrow holds settings of automatic expansion of LVG volumes.
+// Volume is expanded by step when used bytes of its file system reach Threshold percent of total bytes.
+// Step is StepBytes if it is set and StepPercent of volume size otherwise. Volume isn't expanded above MaxSize,
+// zero MaxSize means that volume is bounded only by free capacity of its volume group
+type VolumeAutogrow struct {
+	Threshold   float64
+	StepBytes   int64
+	StepPercent float64
+	MaxSize     int64
+}
+
+// ParseVolumeAutogrow parses settings in format "threshold=80,step=10Gi,max=1Ti", step is either quantity or
+// percent of volume size, e.g. "step=20%". Omitted settings have default values.
+// Returns error if format is wrong or settings are invalid
+func ParseVolumeAutogrow(str string) (*VolumeAutogrow, error) {
+	cfg := &VolumeAutogrow{
+		Threshold:   DefaultAutogrowThreshold,
+		StepPercent: DefaultAutogrowStepPercent,
+	}
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("volume autogrow option %s has wrong format, expected <key>=<value>", item)
+		}
+		var (
+			value = strings.TrimSpace(parts[1])
+			err   error
+		)
+		switch strings.TrimSpace(parts[0]) {
+		case "threshold":
+			cfg.Threshold, err = strconv.ParseFloat(value, 64)
+		case "step":
+			if strings.HasSuffix(value, "%") {
+				cfg.StepBytes = 0
+				cfg.StepPercent, err = strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			} else {
+				cfg.StepPercent = 0
+				cfg.StepBytes, err = parseQuantity(value)
+			}
+		case "max":
+			cfg.MaxSize, err = parseQuantity(value)
+		default:
+			return nil, fmt.Errorf("volume autogrow option %s isn't supported, expected threshold, step or max", item)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("volume autogrow option %s has wrong value: %v", item, err)
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// parseQuantity parses quantity, e.g. 10Gi, and returns its value in bytes
+func parseQuantity(value string) (int64, error) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, err
+	}
+	return quantity.Value(), nil
+}
+
+// Validate checks that threshold is in range (0, 100], step is positive and max size isn't negative
+func (c *VolumeAutogrow) Validate() error {
+	if c.Threshold <= 0 || c.Threshold > 100 {
+		return fmt.Errorf("threshold %v must be in range (0, 100]", c.Threshold)
+	}
+	if c.StepBytes < 0 || (c.StepBytes == 0 && c.StepPercent <= 0) {
+		return fmt.Errorf("step must be positive")
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("max size %d must not be negative", c.MaxSize)
+	}
+	return nil
+}
+
+// step returns number of bytes which volume of size is expanded by
+func (c *VolumeAutogrow) step(size int64) int64 {
+	if c.StepBytes > 0 {
+		return c.StepBytes
+	}
+	return int64(float64(size) * c.StepPercent / 100)
+}
+
+// volumeAutogrow holds state of volume autogrow between checks
+type volumeAutogrow struct {
+	cfg VolumeAutogrow
+	// limited holds IDs of volumes which events about reached limit were sent for
+	limited map[string]bool
+}
+
+// SetVolumeAutogrow enables automatic expansion of LVG volumes which file systems are filled up by AutogrowVolumes
+func (m *VolumeManager) SetVolumeAutogrow(cfg *VolumeAutogrow) {
+	m.autogrow = &volumeAutogrow{
+		cfg:     *cfg,
+		limited: map[string]bool{},
+	}
+}
+
+// getVolumeUsage returns usage of volume which is published or staged to path.
+// Usage of file system is reported for volume in mount mode and size of volume for volume in block mode
+func (m *VolumeManager) getVolumeUsage(volume *volumecrd.Volume, path string) ([]*csi.VolumeUsage, error) {
+	if volume.Spec.Mode == apiV1.ModeRAW || volume.Spec.Mode == apiV1.ModeRAWPART {
+		return []*csi.VolumeUsage{{Unit: csi.VolumeUsage_BYTES, Total: volume.Spec.Size}}, nil
+	}
+	stats, err := m.fsOps.GetFSStats(path)
+	if err != nil {
+		return nil, err
+	}
+	return []*csi.VolumeUsage{
+		{
+			Unit:      csi.VolumeUsage_BYTES,
+			Available: stats.AvailableBytes,
+			Total:     stats.TotalBytes,
+			Used:      stats.UsedBytes,
+		},
+		{
+			Unit:      csi.VolumeUsage_INODES,
+			Available: stats.FreeInodes,
+			Total:     stats.TotalInodes,
+			Used:      stats.UsedInodes,
+		},
+	}, nil
+}
+
+// AutogrowVolumes checks usage of file systems of published LVG volumes and requests expansion of volumes which
+// usage reached threshold. Expansion is requested through PersistentVolumeClaim, so it is performed by
+// external resizer and the same way as expansion requested by user
+func (m *VolumeManager) AutogrowVolumes(ctx context.Context) error {
+	if m.autogrow == nil {
+		return nil
+	}
+	volumes, err := m.cachedCrHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	published := make(map[string]bool, len(volumes))
+	for i := range volumes {
+		volume := &volumes[i]
+		if volume.Spec.CSIStatus != apiV1.Published || volume.Spec.Mode != apiV1.ModeFS ||
+			volume.Spec.Ephemeral || !util.IsStorageClassLVG(volume.Spec.StorageClass) {
+			continue
+		}
+		published[volume.Spec.Id] = true
+		m.autogrowVolume(ctx, volume)
+	}
+	// state of volumes which aren't published anymore is removed
+	for volumeID := range m.autogrow.limited {
+		if !published[volumeID] {
+			delete(m.autogrow.limited, volumeID)
+		}
+	}
+	return nil
+}
+
+// autogrowVolume requests expansion of volume if usage of its file system reached threshold
+func (m *VolumeManager) autogrowVolume(ctx context.Context, volume *volumecrd.Volume) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "autogrowVolume",
+		"volumeID": volume.Spec.Id,
+	})
+
+	targets, err := getPublishTargets(volume)
+	if err != nil || len(targets) == 0 {
+		ll.Debugf("Target path of volume is unknown: %v", err)
+		return
+	}
+	paths := make([]string, 0, len(targets))
+	for target := range targets {
+		paths = append(paths, target)
+	}
+	sort.Strings(paths)
+	usage, err := m.getVolumeUsage(volume, paths[0])
+	if err != nil {
+		ll.Warnf("Unable to get usage of volume: %v", err)
+		return
+	}
+	space := usage[0]
+	if space.Total == 0 || float64(space.Used)*100 < m.autogrow.cfg.Threshold*float64(space.Total) {
+		delete(m.autogrow.limited, volume.Spec.Id)
+		return
+	}
+	percent := space.Used * 100 / space.Total
+
+	pvc, err := m.getPVCForVolume(volume.Spec.Id)
+	if err != nil {
+		ll.Warnf("Unable to read PersistentVolumeClaim of volume: %v", err)
+		return
+	}
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+	if requested.Cmp(capacity) > 0 {
+		ll.Debugf("Expansion of volume to %s is in progress", requested.String())
+		return
+	}
+
+	size, limit := m.autogrowSize(volume)
+	if size <= volume.Spec.Size {
+		if !m.autogrow.limited[volume.Spec.Id] {
+			ll.Warnf("Volume is %d%% full, but it can't be expanded: %s", percent, limit)
+			m.recorder.Eventf(volume, eventing.VolumeAutogrowLimitReached,
+				"Volume %s is %d%% full, but it can't be expanded: %s",
+				volume.Name, percent, limit)
+			m.autogrow.limited[volume.Spec.Id] = true
+		}
+		return
+	}
+
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = *resource.NewQuantity(size, resource.BinarySI)
+	if err := m.k8sClient.Update(ctx, pvc); err != nil {
+		ll.Errorf("Unable to request expansion of volume to %d bytes: %v", size, err)
+		m.recorder.Eventf(volume, eventing.VolumeAutogrowFailed,
+			"Unable to expand volume %s from %d to %d bytes: %v", volume.Name, volume.Spec.Size, size, err)
+		return
+	}
+	delete(m.autogrow.limited, volume.Spec.Id)
+	ll.Infof("Volume is %d%% full, expansion from %d to %d bytes is requested",
+		percent, volume.Spec.Size, size)
+	m.recorder.Eventf(volume, eventing.VolumeAutogrown,
+		"Volume %s is %d%% full, expansion from %d to %d bytes is requested in PVC %s/%s",
+		volume.Name, percent, volume.Spec.Size, size, pvc.Namespace, pvc.Name)
+}
+
+// autogrowSize returns size which volume should be expanded to, step is limited by max size and free capacity
+// of volume group. Reason of limitation is returned if volume can't be expanded
+func (m *VolumeManager) autogrowSize(volume *volumecrd.Volume) (int64, string) {
+	cfg := m.autogrow.cfg
+	size := volume.Spec.Size + cfg.step(volume.Spec.Size)
+	if cfg.MaxSize > 0 && size > cfg.MaxSize {
+		size = cfg.MaxSize
+		if size <= volume.Spec.Size {
+			return size, fmt.Sprintf("max size %d bytes is reached", cfg.MaxSize)
+		}
+	}
+	ac, err := m.cachedCrHelper.GetACByLocation(volume.Spec.Location)
+	switch {
+	case errors.Is(err, baseerr.ErrorNotFound) || (err == nil && ac.Spec.Size == 0):
+		return volume.Spec.Size, "there is no free space in volume group"
+	case err != nil:
+		return volume.Spec.Size, fmt.Sprintf("unable to read available capacity of volume group: %v", err)
+	}
+	if size-volume.Spec.Size > ac.Spec.Size {
+		size = volume.Spec.Size + ac.Spec.Size
+	}
+	return size, ""
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+)
+
+func TestParseVolumeAutogrow(t *testing.T) {
+	cfg, err := ParseVolumeAutogrow("threshold=90, step=5Gi, max=100Gi")
+	assert.Nil(t, err)
+	assert.Equal(t, &VolumeAutogrow{Threshold: 90, StepBytes: 5 * int64(util.GBYTE),
+		MaxSize: 100 * int64(util.GBYTE)}, cfg)
+
+	cfg, err = ParseVolumeAutogrow("step=20%")
+	assert.Nil(t, err)
+	assert.Equal(t, &VolumeAutogrow{Threshold: DefaultAutogrowThreshold, StepPercent: 20}, cfg)
+	assert.Equal(t, int64(200), cfg.step(1000))
+
+	for _, str := range []string{"threshold", "threshold=abc", "threshold=0", "threshold=120", "step=0%",
+		"step=-1Gi", "step=abc", "max=-1Gi", "size=1Gi"} {
+		_, err = ParseVolumeAutogrow(str)
+		assert.NotNil(t, err, str)
+	}
+}
+
+func TestVolumeManager_AutogrowVolumes(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		fsOps    = &mockProv.MockFsOpts{}
+		gib      = int64(util.GBYTE)
+		target   = "/var/lib/kubelet/pods/pod-uuid/volumes/kubernetes.io~csi/pvc-uuid/mount"
+		volume   = testVolumeLVGCR.DeepCopy()
+		ac       = vm.k8sClient.ConstructACCR("ac", api.AvailableCapacity{Location: testLVGCR.Name, NodeId: nodeID,
+			StorageClass: apiV1.StorageClassHDDLVG, Size: 100 * gib})
+		pv = &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: volume.Spec.Id},
+			Spec: corev1.PersistentVolumeSpec{
+				ClaimRef: &corev1.ObjectReference{Name: "data", Namespace: testNs},
+			},
+		}
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: testNs},
+			Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: *resource.NewQuantity(150*gib, resource.BinarySI)},
+			}},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: *resource.NewQuantity(150*gib, resource.BinarySI)},
+			},
+		}
+		// check sets usage of file system, runs autogrow and returns requested size of PVC
+		check = func(usedPercent int64) int64 {
+			stats := &fs.FSStats{TotalBytes: volume.Spec.Size, UsedBytes: volume.Spec.Size * usedPercent / 100}
+			stats.AvailableBytes = stats.TotalBytes - stats.UsedBytes
+			fsOps.ExpectedCalls = nil
+			fsOps.On("GetFSStats", target).Return(stats, nil)
+			assert.Nil(t, vm.AutogrowVolumes(testCtx))
+			assert.Nil(t, vm.k8sClient.ReadCR(testCtx, pvc.Name, pvc.Namespace, pvc))
+			requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			return requested.Value()
+		}
+		// resize completes expansion of volume to requested size of PVC
+		resize = func() {
+			requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			pvc.Status.Capacity[corev1.ResourceStorage] = requested
+			assert.Nil(t, vm.k8sClient.Status().Update(testCtx, pvc))
+			volume.Spec.Size = requested.Value()
+			assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+		}
+		countEvents = func(event *eventing.EventDescription) int {
+			count := 0
+			for _, c := range recorder.Calls {
+				if c.Event == event {
+					count++
+				}
+			}
+			return count
+		}
+	)
+	vm.recorder = recorder
+	vm.fsOps = fsOps
+	volume.Spec.CSIStatus = apiV1.Published
+	volume.Spec.Size = 150 * gib
+	assert.Nil(t, addPublishTarget(volume, target, "app-0"))
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, volume.Name, volume))
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, ac.Name, ac))
+	assert.Nil(t, vm.k8sClient.Create(testCtx, pv))
+	assert.Nil(t, vm.k8sClient.Create(testCtx, pvc))
+
+	// disabled
+	assert.Equal(t, 150*gib, check(95))
+
+	vm.SetVolumeAutogrow(&VolumeAutogrow{Threshold: 80, StepBytes: 10 * gib, MaxSize: 165 * gib})
+	assert.Equal(t, 150*gib, check(50))
+	assert.Empty(t, recorder.Calls)
+
+	// usage reached threshold
+	assert.Equal(t, 160*gib, check(85))
+	assert.Equal(t, 1, countEvents(eventing.VolumeAutogrown))
+
+	// expansion is in progress
+	assert.Equal(t, 160*gib, check(90))
+	assert.Equal(t, 1, countEvents(eventing.VolumeAutogrown))
+
+	// step is limited by max size
+	resize()
+	assert.Equal(t, 165*gib, check(90))
+	assert.Equal(t, 2, countEvents(eventing.VolumeAutogrown))
+
+	// max size is reached, event is sent once
+	resize()
+	assert.Equal(t, 165*gib, check(90))
+	assert.Equal(t, 165*gib, check(95))
+	assert.Equal(t, 1, countEvents(eventing.VolumeAutogrowLimitReached))
+
+	// step is limited by free capacity of volume group
+	vm.autogrow.cfg.MaxSize = 0
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, ac.Name, "", ac))
+	ac.Spec.Size = 2 * gib
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, ac))
+	assert.Equal(t, 167*gib, check(95))
+	assert.Equal(t, 3, countEvents(eventing.VolumeAutogrown))
+
+	// there is no free space
+	resize()
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, ac.Name, "", ac))
+	ac.Spec.Size = 0
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, ac))
+	assert.Equal(t, 167*gib, check(95))
+	assert.Equal(t, 2, countEvents(eventing.VolumeAutogrowLimitReached))
+}
@@ -0,0 +1,240 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/burnin"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// Default settings of burn-in test
+const (
+	DefaultBurnInTool     = burnin.ToolFio
+	DefaultBurnInDuration = 4 * time.Hour
+
+	// burnInStatusUpdateAttempts is an amount of attempts to set result of burn-in test
+	burnInStatusUpdateAttempts = 5
+)
+
+// BurnIn holds settings of burn-in test of new drives
+type BurnIn struct {
+	Tool     string
+	Duration time.Duration
+}
+
+// ParseBurnIn parses settings in format "tool=fio,duration=4h", omitted settings have default values
+// Returns error if format is wrong or settings are invalid
+func ParseBurnIn(str string) (*BurnIn, error) {
+	cfg := &BurnIn{Tool: DefaultBurnInTool, Duration: DefaultBurnInDuration}
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("burn-in option %s has wrong format, expected <key>=<value>", item)
+		}
+		value := strings.TrimSpace(parts[1])
+		switch strings.TrimSpace(parts[0]) {
+		case "tool":
+			cfg.Tool = value
+		case "duration":
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("burn-in option %s has wrong value: %v", item, err)
+			}
+			cfg.Duration = duration
+		default:
+			return nil, fmt.Errorf("burn-in option %s isn't supported, expected tool or duration", item)
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks that tool is supported and duration is positive
+func (c *BurnIn) Validate() error {
+	if c.Tool != burnin.ToolFio && c.Tool != burnin.ToolBadblocks {
+		return fmt.Errorf("burn-in tool %s isn't supported, expected %s or %s", c.Tool, burnin.ToolFio, burnin.ToolBadblocks)
+	}
+	if c.Duration <= 0 {
+		return fmt.Errorf("burn-in duration %v must be positive", c.Duration)
+	}
+	return nil
+}
+
+// burnInRunner runs burn-in tests of new drives in background
+type burnInRunner struct {
+	cfg    BurnIn
+	tester burnin.WrapBurnIn
+	// drive name -> start time of running test
+	jobs sync.Map
+}
+
+// SetBurnIn enables burn-in test of drives which are installed after node service start,
+// drive isn't schedulable until test is passed
+func (m *VolumeManager) SetBurnIn(cfg *BurnIn, tester burnin.WrapBurnIn) {
+	m.burnIn = &burnInRunner{cfg: *cfg, tester: tester}
+}
+
+// scheduleBurnIn marks new drive as pending for burn-in test, drives discovered during the first discovery
+// and system drives aren't tested. Caller is responsible for creation of the drive object
+func (m *VolumeManager) scheduleBurnIn(drive *drivecrd.Drive, firstIteration bool) {
+	if m.burnIn == nil || firstIteration || drive.Spec.IsSystem {
+		return
+	}
+	if drive.Annotations == nil {
+		drive.Annotations = map[string]string{}
+	}
+	drive.Annotations[apiV1.DriveAnnotationBurnIn] = apiV1.DriveAnnotationBurnInPending
+}
+
+// processBurnIn starts burn-in tests of pending drives and updates progress of running tests
+// Test which was interrupted by restart of node service is started again
+func (m *VolumeManager) processBurnIn(ctx context.Context) error {
+	if m.burnIn == nil {
+		return nil
+	}
+	ll := m.log.WithField("method", "processBurnIn")
+	// cache might not contain the latest clean state of drive, so drives are read from API server
+	drives, err := m.crHelper.GetDriveCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	for i := range drives {
+		drive := &drives[i]
+		state := drive.Annotations[apiV1.DriveAnnotationBurnIn]
+		if state != apiV1.DriveAnnotationBurnInPending && state != apiV1.DriveAnnotationBurnInInProgress {
+			continue
+		}
+		if start, running := m.burnIn.jobs.Load(drive.Name); running {
+			m.updateBurnInProgress(ctx, drive, start.(time.Time))
+			continue
+		}
+		// clean state of drive is unknown until it is scanned for foreign data
+		if drive.Spec.Status != apiV1.DriveStatusOnline ||
+			drive.Annotations[apiV1.DriveAnnotationForeignData] == apiV1.DriveAnnotationForeignDataPending {
+			continue
+		}
+		if err := m.startBurnIn(ctx, drive); err != nil {
+			ll.Errorf("Unable to start burn-in test of drive %s: %v", drive.Name, err)
+		}
+	}
+	return nil
+}
+
+// startBurnIn marks drive as being tested and runs test in background
+// Drive which isn't clean is skipped to keep data on it
+func (m *VolumeManager) startBurnIn(ctx context.Context, drive *drivecrd.Drive) error {
+	if !drive.Spec.IsClean || drive.Spec.Usage != apiV1.DriveUsageInUse {
+		drive.Annotations[apiV1.DriveAnnotationBurnIn] = apiV1.DriveAnnotationBurnInSkipped
+		drive.Annotations[apiV1.DriveAnnotationBurnInResult] = "drive isn't clean"
+		delete(drive.Annotations, apiV1.DriveAnnotationBurnInProgress)
+		return m.k8sClient.UpdateCR(ctx, drive)
+	}
+	drive.Annotations[apiV1.DriveAnnotationBurnIn] = apiV1.DriveAnnotationBurnInInProgress
+	drive.Annotations[apiV1.DriveAnnotationBurnInProgress] = "0%"
+	delete(drive.Annotations, apiV1.DriveAnnotationBurnInResult)
+	if err := m.k8sClient.UpdateCR(ctx, drive); err != nil {
+		return err
+	}
+	m.burnIn.jobs.Store(drive.Name, time.Now())
+	go m.burnInDrive(drive.DeepCopy())
+	return nil
+}
+
+// updateBurnInProgress sets percent of elapsed duration of running test
+func (m *VolumeManager) updateBurnInProgress(ctx context.Context, drive *drivecrd.Drive, start time.Time) {
+	percent := int(time.Since(start) * 100 / m.burnIn.cfg.Duration)
+	// badblocks might take a bit longer than duration
+	if percent > 99 {
+		percent = 99
+	}
+	progress := strconv.Itoa(percent) + "%"
+	if drive.Annotations[apiV1.DriveAnnotationBurnInProgress] == progress {
+		return
+	}
+	drive.Annotations[apiV1.DriveAnnotationBurnInProgress] = progress
+	if err := m.k8sClient.UpdateCR(ctx, drive); err != nil {
+		m.log.WithField("method", "updateBurnInProgress").
+			Warnf("Unable to update burn-in progress of drive %s: %v", drive.Name, err)
+	}
+}
+
+// burnInDrive runs burn-in test holding lock of drive location and sets result in Drive CR
+func (m *VolumeManager) burnInDrive(drive *drivecrd.Drive) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":  "burnInDrive",
+		"driveSN": drive.Spec.SerialNumber,
+	})
+	ctx := context.Background()
+	defer m.burnIn.jobs.Delete(drive.Name)
+	unlock := m.lockLocation(ll, drive.Spec.UUID)
+	defer unlock()
+
+	startTime := time.Now()
+	err := m.burnIn.tester.Run(drive.Spec.Path, m.burnIn.cfg.Tool, m.burnIn.cfg.Duration)
+	if err == nil {
+		// test pattern must not be recognized as filesystem or partition table
+		err = m.fsOps.WipeFS(drive.Spec.Path)
+	}
+	state, result := apiV1.DriveAnnotationBurnInPassed, fmt.Sprintf("passed in %s", time.Since(startTime).Round(time.Second))
+	if err != nil {
+		state, result = apiV1.DriveAnnotationBurnInFailed, err.Error()
+		ll.Errorf("Burn-in test failed: %v", err)
+	} else {
+		ll.Info(result)
+	}
+
+	// drive CR might be changed during test, so the latest version is updated
+	for i := 0; i < burnInStatusUpdateAttempts; i++ {
+		if readErr := m.k8sClient.ReadCR(ctx, drive.Name, "", drive); readErr != nil {
+			ll.Errorf("Unable to read drive CR: %v", readErr)
+			continue
+		}
+		if drive.Annotations == nil {
+			drive.Annotations = map[string]string{}
+		}
+		drive.Annotations[apiV1.DriveAnnotationBurnIn] = state
+		drive.Annotations[apiV1.DriveAnnotationBurnInProgress] = "100%"
+		drive.Annotations[apiV1.DriveAnnotationBurnInResult] = result
+		updateErr := m.k8sClient.UpdateCR(ctx, drive)
+		if updateErr == nil {
+			if err != nil {
+				m.recorder.Eventf(drive, eventing.DriveBurnInFailed, "Burn-in test with %s failed, drive isn't schedulable: %s. %s",
+					m.burnIn.cfg.Tool, result, drive.GetDriveDescription())
+			} else {
+				m.recorder.Eventf(drive, eventing.DriveBurnInPassed, "Burn-in test with %s %s. %s",
+					m.burnIn.cfg.Tool, result, drive.GetDriveDescription())
+			}
+			return
+		}
+		ll.Warnf("Unable to set burn-in result, attempt %d out of %d: %v", i+1, burnInStatusUpdateAttempts, updateErr)
+	}
+	ll.Errorf("Unable to set burn-in result, drive will be tested again")
+}
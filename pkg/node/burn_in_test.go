@@ -0,0 +1,124 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/burnin"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+// fakeBurnIn returns err from Run
+type fakeBurnIn struct {
+	err error
+}
+
+func (f *fakeBurnIn) Run(device, tool string, duration time.Duration) error {
+	return f.err
+}
+
+func TestParseBurnIn(t *testing.T) {
+	cfg, err := ParseBurnIn("tool=badblocks")
+	assert.Nil(t, err)
+	assert.Equal(t, &BurnIn{Tool: burnin.ToolBadblocks, Duration: DefaultBurnInDuration}, cfg)
+
+	cfg, err = ParseBurnIn(" duration=30m, tool=fio ")
+	assert.Nil(t, err)
+	assert.Equal(t, &BurnIn{Tool: burnin.ToolFio, Duration: 30 * time.Minute}, cfg)
+
+	for _, str := range []string{"tool=dd", "duration=0s", "duration=1", "passes=2", "tool"} {
+		_, err = ParseBurnIn(str)
+		assert.NotNil(t, err, str)
+	}
+}
+
+func TestVolumeManager_scheduleBurnIn(t *testing.T) {
+	vm := prepareSuccessVolumeManager(t)
+	drive := &drivecrd.Drive{Spec: drive2}
+	drive.Spec.IsSystem = false
+
+	// burn-in is disabled
+	vm.scheduleBurnIn(drive, false)
+	assert.Empty(t, drive.Annotations)
+
+	vm.SetBurnIn(&BurnIn{Tool: burnin.ToolFio, Duration: time.Hour}, &fakeBurnIn{})
+	vm.scheduleBurnIn(drive, true)
+	assert.Empty(t, drive.Annotations)
+	drive.Spec.IsSystem = true
+	vm.scheduleBurnIn(drive, false)
+	assert.Empty(t, drive.Annotations)
+	drive.Spec.IsSystem = false
+	vm.scheduleBurnIn(drive, false)
+	assert.Equal(t, apiV1.DriveAnnotationBurnInPending, drive.Annotations[apiV1.DriveAnnotationBurnIn])
+	assert.True(t, drive.IsBurnInBlocking())
+}
+
+func TestVolumeManager_processBurnIn(t *testing.T) {
+	testCases := []struct {
+		name    string
+		clean   bool
+		testErr error
+		state   string
+		event   *eventing.EventDescription
+	}{
+		{name: "passed", clean: true, state: apiV1.DriveAnnotationBurnInPassed, event: eventing.DriveBurnInPassed},
+		{name: "failed", clean: true, testErr: errors.New("badblocks found bad blocks: 1024"),
+			state: apiV1.DriveAnnotationBurnInFailed, event: eventing.DriveBurnInFailed},
+		{name: "not clean", state: apiV1.DriveAnnotationBurnInSkipped},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				vm       = prepareSuccessVolumeManager(t)
+				recorder = new(mocks.NoOpRecorder)
+				spec     = drive2
+			)
+			vm.recorder = recorder
+			vm.SetBurnIn(&BurnIn{Tool: burnin.ToolFio, Duration: time.Hour}, &fakeBurnIn{err: tc.testErr})
+			spec.IsClean, spec.IsSystem, spec.Usage = tc.clean, false, apiV1.DriveUsageInUse
+			drive := vm.k8sClient.ConstructDriveCR(spec.UUID, spec)
+			drive.Annotations = map[string]string{apiV1.DriveAnnotationBurnIn: apiV1.DriveAnnotationBurnInPending}
+			addDriveCRs(vm.k8sClient, drive)
+
+			assert.Nil(t, vm.processBurnIn(testCtx))
+			assert.Eventually(t, func() bool {
+				_, running := vm.burnIn.jobs.Load(drive.Name)
+				return !running
+			}, time.Second, 10*time.Millisecond)
+
+			updated := &drivecrd.Drive{}
+			assert.Nil(t, vm.k8sClient.ReadCR(testCtx, drive.Name, "", updated))
+			assert.Equal(t, tc.state, updated.Annotations[apiV1.DriveAnnotationBurnIn])
+			assert.Equal(t, tc.state == apiV1.DriveAnnotationBurnInFailed, updated.IsBurnInBlocking())
+			if tc.event == nil {
+				assert.Empty(t, recorder.Calls)
+				return
+			}
+			assert.Equal(t, "100%", updated.Annotations[apiV1.DriveAnnotationBurnInProgress])
+			assert.Len(t, recorder.Calls, 1)
+			assert.Equal(t, tc.event, recorder.Calls[0].Event)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+// SetCacheTier enables caching of HDD volumes with cache mode by bcache devices, cache state and hit ratio
+// of cached volumes are reported in Volume CR annotations during Discover
+func (m *VolumeManager) SetCacheTier(cacheTier *p.CacheTier) {
+	m.cacheTier = cacheTier
+	if dp, ok := m.provisioners[p.DriveBasedVolumeType].(*p.DriveProvisioner); ok {
+		dp.SetCacheTier(cacheTier)
+	}
+}
+
+// updateCacheStats sets state, hit ratio and dirty data of cache of cached volumes to Volume CR annotations
+// VolumeCacheDegraded event is sent when cache becomes unhealthy
+func (m *VolumeManager) updateCacheStats(ctx context.Context) error {
+	if m.cacheTier == nil {
+		return nil
+	}
+	ll := m.log.WithField("method", "updateCacheStats")
+
+	volumes, err := m.cachedCrHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	for i := range volumes {
+		volume := &volumes[i]
+		if volume.Spec.CacheMode == "" || !isCacheActive(volume) {
+			continue
+		}
+		if err = m.updateVolumeCacheStats(ctx, volume); err != nil {
+			ll.WithField("volumeID", volume.Spec.Id).Errorf("Unable to update cache stats: %v", err)
+		}
+	}
+	return nil
+}
+
+// updateVolumeCacheStats reads stats of bcache device of volume and updates Volume CR if they were changed
+func (m *VolumeManager) updateVolumeCacheStats(ctx context.Context, volume *volumecrd.Volume) error {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "updateVolumeCacheStats",
+		"volumeID": volume.Spec.Id,
+	})
+
+	device, err := m.getProvisionerForVolume(&volume.Spec).GetVolumePath(&volume.Spec)
+	if err != nil {
+		return err
+	}
+	stats, err := m.cacheTier.Stats(device)
+	if err != nil {
+		return err
+	}
+
+	annotations := map[string]string{
+		apiV1.VolumeAnnotationCacheState:     stats.State,
+		apiV1.VolumeAnnotationCacheHitRatio:  stats.HitRatio,
+		apiV1.VolumeAnnotationCacheDirtyData: stats.DirtyData,
+	}
+	changed := false
+	for key, value := range annotations {
+		if volume.Annotations[key] != value {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+	prevState := volume.Annotations[apiV1.VolumeAnnotationCacheState]
+	if volume.Annotations == nil {
+		volume.Annotations = make(map[string]string, len(annotations))
+	}
+	for key, value := range annotations {
+		volume.Annotations[key] = value
+	}
+	if err = m.k8sClient.UpdateCR(ctx, volume); err != nil {
+		return err
+	}
+
+	if !stats.IsHealthy() && prevState != stats.State {
+		ll.Warnf("Cache of volume is degraded, bcache device %s state is %s", device, stats.State)
+		m.recorder.Eventf(volume, eventing.VolumeCacheDegraded,
+			"Cache of volume is degraded, bcache device %s state is %s", device, stats.State)
+	}
+	return nil
+}
+
+// isCacheActive returns true if volume is created and isn't being removed
+func isCacheActive(volume *volumecrd.Volume) bool {
+	switch volume.Spec.CSIStatus {
+	case apiV1.Created, apiV1.VolumeReady, apiV1.Published:
+		return true
+	}
+	return false
+}
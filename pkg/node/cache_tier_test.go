@@ -0,0 +1,59 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+func TestVolumeManager_updateCacheStats(t *testing.T) {
+	vm := prepareSuccessVolumeManager(t)
+	// cache tier isn't configured
+	assert.Nil(t, vm.updateCacheStats(testCtx))
+
+	vm.SetCacheTier(p.NewCacheTier("cache-vg", mocks.NewMockExecutor(nil), logrus.New()))
+	assert.NotNil(t, vm.cacheTier)
+
+	// volume isn't cached
+	volume := volCR.DeepCopy()
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, volume.Name, volume))
+	assert.Nil(t, vm.updateCacheStats(testCtx))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, volume.Name, volume.Namespace, volume))
+	assert.Empty(t, volume.Annotations[apiV1.VolumeAnnotationCacheState])
+}
+
+func TestIsCacheActive(t *testing.T) {
+	volume := volCR.DeepCopy()
+	for status, active := range map[string]bool{
+		apiV1.Creating:    false,
+		apiV1.Created:     true,
+		apiV1.VolumeReady: true,
+		apiV1.Published:   true,
+		apiV1.Removing:    false,
+		apiV1.Failed:      false,
+	} {
+		volume.Spec.CSIStatus = status
+		assert.Equal(t, active, isCacheActive(volume), status)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/circuitbreaker"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// SetDriveCircuitBreaker enables circuit breaker of drives: after threshold of consecutive failed operations
+// (volume creation, removal or expansion) commands aren't issued to the drive and Drive CR is annotated.
+// Volumes of LogicalVolumeGroup are tracked by circuit of the group, since failure can't be attributed to one of
+// its drives. Operations are probed with interval which is doubled after each failed probe up to maxProbeInterval
+func (m *VolumeManager) SetDriveCircuitBreaker(threshold int, probeInterval, maxProbeInterval time.Duration) {
+	m.driveBreaker = circuitbreaker.New(threshold, probeInterval, maxProbeInterval)
+}
+
+// checkDriveCircuits checks circuit of location of volume which is being created, removed or expanded
+// Returns zero duration if operation is allowed, otherwise time until the next probe of location
+func (m *VolumeManager) checkDriveCircuits(ctx context.Context, volume *volumecrd.Volume) time.Duration {
+	if m.driveBreaker == nil {
+		return 0
+	}
+	switch volume.Spec.CSIStatus {
+	case apiV1.Creating, apiV1.Removing, apiV1.Resizing:
+	default:
+		return 0
+	}
+	if allowed, wait := m.driveBreaker.Allow(volume.Spec.Location); !allowed {
+		return wait
+	}
+	return 0
+}
+
+// rejectVolumeOperation fails creation of volume immediately, removal and expansion are requeued
+// till the next probe of drive
+func (m *VolumeManager) rejectVolumeOperation(ctx context.Context, volume *volumecrd.Volume,
+	wait time.Duration) (ctrl.Result, error) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "rejectVolumeOperation",
+		"volumeID": volume.Name,
+	})
+
+	if volume.Spec.CSIStatus != apiV1.Creating {
+		ll.Debugf("Circuit of drive is open, %s volume is postponed for %s", volume.Spec.CSIStatus, wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+	ll.Warnf("Circuit of drive %s is open, set volume status to Failed", volume.Spec.Location)
+	volume.Spec.CSIStatus = apiV1.Failed
+	if err := m.k8sClient.UpdateCRWithAttempts(ctx, volume, 5); err != nil {
+		ll.Errorf("Unable to update volume status to %s: %v", apiV1.Failed, err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// reportDriveOperation reports result of operation on location of volume to circuit breaker,
+// Drive or LogicalVolumeGroup CR is annotated when circuit is opened and annotation is removed when it's closed
+func (m *VolumeManager) reportDriveOperation(ctx context.Context, volume *volumecrd.Volume, opErr error) {
+	if m.driveBreaker == nil {
+		return
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "reportDriveOperation",
+		"volumeID": volume.Name,
+	})
+
+	location := volume.Spec.Location
+	if opErr == nil {
+		if !m.driveBreaker.Success(location) {
+			return
+		}
+		ll.Infof("Operation on %s succeeded, circuit is closed", location)
+	} else {
+		if !m.driveBreaker.Failure(location) {
+			return
+		}
+		ll.Warnf("Circuit of %s is opened after %d failed operations, the last error: %v",
+			location, m.driveBreaker.Failures(location), opErr)
+	}
+
+	var (
+		obj        k8sCl.Object
+		annotation string
+	)
+	if util.IsStorageClassLVG(volume.Spec.StorageClass) {
+		obj, annotation = &lvgcrd.LogicalVolumeGroup{}, apiV1.LVGCircuitAnnotation
+	} else {
+		obj, annotation = &drivecrd.Drive{}, apiV1.DriveAnnotationCircuit
+	}
+	if err := m.k8sClient.ReadCR(ctx, location, "", obj); err != nil {
+		ll.Errorf("Unable to read CR %s: %v", location, err)
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if opErr == nil {
+		delete(annotations, annotation)
+	} else {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[annotation] = apiV1.DriveAnnotationCircuitOpen
+	}
+	obj.SetAnnotations(annotations)
+	if err := m.k8sClient.UpdateCR(ctx, obj); err != nil {
+		ll.Errorf("Unable to update annotation %s of %s: %v", annotation, location, err)
+	}
+
+	event, messageFmt, args := eventing.DriveCircuitClosed, "Operation succeeded, commands are issued to %s.",
+		[]interface{}{location}
+	if opErr != nil {
+		event, messageFmt = eventing.DriveCircuitOpen,
+			"%d operations failed, commands aren't issued to %s except probes, the last error: %v."
+		args = []interface{}{m.driveBreaker.Failures(location), location, opErr}
+	}
+	if drive, ok := obj.(*drivecrd.Drive); ok {
+		m.sendEventForDrive(drive, event, messageFmt, args...)
+		return
+	}
+	m.recorder.Eventf(obj, event, messageFmt, args...)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	vcrd "github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestVolumeManager_DriveCircuitBreaker(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		opErr    = errors.New("sgdisk failed")
+	)
+	vm.recorder = recorder
+	vm.SetDriveCircuitBreaker(2, time.Hour, 4*time.Hour)
+
+	d1, d2 := disk1, disk2
+	addDriveCRs(vm.k8sClient, vm.k8sClient.ConstructDriveCR(d1.UUID, d1), vm.k8sClient.ConstructDriveCR(d2.UUID, d2))
+
+	volume := testVolumeCR1.DeepCopy()
+	volume.Spec.CSIStatus = apiV1.Creating
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, volume.Name, volume))
+
+	// circuit is opened after threshold
+	assert.Zero(t, vm.checkDriveCircuits(testCtx, volume))
+	vm.reportDriveOperation(testCtx, volume, opErr)
+	assert.Len(t, recorder.Calls, 0)
+	vm.reportDriveOperation(testCtx, volume, opErr)
+	assert.Len(t, recorder.Calls, 1)
+	assert.Equal(t, eventing.DriveCircuitOpen, recorder.Calls[0].Event)
+
+	// drive with open circuit is annotated, its health isn't changed
+	drive := &drivecrd.Drive{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, d1.UUID, "", drive))
+	assert.Equal(t, apiV1.DriveAnnotationCircuitOpen, drive.Annotations[apiV1.DriveAnnotationCircuit])
+	assert.Equal(t, d1.Health, drive.Spec.Health)
+	// decoding into the existing object keeps keys of its annotations map
+	drive = &drivecrd.Drive{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, d2.UUID, "", drive))
+	assert.Empty(t, drive.Annotations[apiV1.DriveAnnotationCircuit])
+
+	// creation fails without commands
+	wait := vm.checkDriveCircuits(testCtx, volume)
+	assert.True(t, wait > 0 && wait <= time.Hour)
+	res, err := vm.rejectVolumeOperation(testCtx, volume, wait)
+	assert.Nil(t, err)
+	assert.Zero(t, res.RequeueAfter)
+	updated := &vcrd.Volume{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, volume.Name, volume.Namespace, updated))
+	assert.Equal(t, apiV1.Failed, updated.Spec.CSIStatus)
+
+	// removal is postponed till the next probe
+	updated.Spec.CSIStatus = apiV1.Removing
+	res, err = vm.rejectVolumeOperation(testCtx, updated, wait)
+	assert.Nil(t, err)
+	assert.Equal(t, wait, res.RequeueAfter)
+
+	// volumes in other statuses aren't checked
+	updated.Spec.CSIStatus = apiV1.Created
+	assert.Zero(t, vm.checkDriveCircuits(testCtx, updated))
+
+	// successful probe closes circuit
+	vm.reportDriveOperation(testCtx, volume, nil)
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.DriveCircuitClosed, recorder.Calls[1].Event)
+	assert.Zero(t, vm.checkDriveCircuits(testCtx, volume))
+	drive = &drivecrd.Drive{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, d1.UUID, "", drive))
+	_, ok := drive.Annotations[apiV1.DriveAnnotationCircuit]
+	assert.False(t, ok)
+}
+
+func TestVolumeManager_DriveCircuitBreaker_LVG(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		opErr    = errors.New("lvcreate failed")
+		lvg      = testLVGCR.DeepCopy()
+	)
+	vm.recorder = recorder
+	vm.SetDriveCircuitBreaker(1, time.Hour, 4*time.Hour)
+
+	d1 := disk1
+	addDriveCRs(vm.k8sClient, vm.k8sClient.ConstructDriveCR(d1.UUID, d1))
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, lvg.Name, lvg))
+
+	lvmVolume := testVolumeCR1.DeepCopy()
+	lvmVolume.Spec.CSIStatus, lvmVolume.Spec.StorageClass, lvmVolume.Spec.Location =
+		apiV1.Creating, apiV1.StorageClassHDDLVG, lvg.Name
+	driveVolume := testVolumeCR1.DeepCopy()
+	driveVolume.Spec.CSIStatus = apiV1.Creating
+
+	// failures of LVG volumes open circuit of LVG, not of its drives
+	vm.reportDriveOperation(testCtx, lvmVolume, opErr)
+	assert.Len(t, recorder.Calls, 1)
+	assert.True(t, vm.checkDriveCircuits(testCtx, lvmVolume) > 0)
+	assert.Zero(t, vm.checkDriveCircuits(testCtx, driveVolume))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, lvg.Name, "", lvg))
+	assert.Equal(t, apiV1.DriveAnnotationCircuitOpen, lvg.Annotations[apiV1.LVGCircuitAnnotation])
+	drive := &drivecrd.Drive{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, d1.UUID, "", drive))
+	assert.Empty(t, drive.Annotations[apiV1.DriveAnnotationCircuit])
+
+	vm.reportDriveOperation(testCtx, lvmVolume, nil)
+	// decoding into the existing object keeps keys of its annotations map
+	updatedLVG := &lvgcrd.LogicalVolumeGroup{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, lvg.Name, "", updatedLVG))
+	assert.Empty(t, updatedLVG.Annotations[apiV1.LVGCircuitAnnotation])
+}
+
+func TestVolumeManager_DriveCircuitBreaker_Disabled(t *testing.T) {
+	vm := prepareSuccessVolumeManager(t)
+	volume := testVolumeCR1.DeepCopy()
+	volume.Spec.CSIStatus = apiV1.Creating
+
+	vm.reportDriveOperation(testCtx, volume, errors.New("error"))
+	assert.Zero(t, vm.checkDriveCircuits(testCtx, volume))
+}
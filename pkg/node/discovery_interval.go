@@ -0,0 +1,95 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDiscoverFastInterval is interval of Discover on start, after drives are changed and after failure
+	DefaultDiscoverFastInterval = 10 * time.Second
+	// DefaultDiscoverInterval is interval of Discover in steady state
+	DefaultDiscoverInterval = 30 * time.Second
+)
+
+// discoveryInterval adapts interval between Discover calls: fast interval is used after drives are changed
+// or Discover fails, interval is doubled after each Discover without changes until steady state interval is reached
+type discoveryInterval struct {
+	mu     sync.Mutex
+	fast   time.Duration
+	steady time.Duration
+	next   time.Duration
+}
+
+func newDiscoveryInterval(fast, steady time.Duration) *discoveryInterval {
+	return &discoveryInterval{fast: fast, steady: steady, next: fast}
+}
+
+// set changes intervals, interval of the next Discover is kept within new bounds
+func (d *discoveryInterval) set(fast, steady time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fast, d.steady = fast, steady
+	d.next = d.bound(d.next)
+}
+
+// observe calculates interval of the next Discover by result of the current one
+func (d *discoveryInterval) observe(changed bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if changed {
+		d.next = d.fast
+		return
+	}
+	d.next = d.bound(2 * d.next)
+}
+
+// get returns interval of the next Discover
+func (d *discoveryInterval) get() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.next
+}
+
+func (d *discoveryInterval) bound(interval time.Duration) time.Duration {
+	if interval < d.fast {
+		return d.fast
+	}
+	if interval > d.steady {
+		return d.steady
+	}
+	return interval
+}
+
+// SetDiscoveryIntervals sets fast interval of Discover after drives are changed or Discover fails and interval
+// in steady state, it might be called while Discover is running, e.g. on reload of configuration file
+func (m *VolumeManager) SetDiscoveryIntervals(fast, steady time.Duration) error {
+	if fast <= 0 || steady < fast {
+		return fmt.Errorf("wrong discovery intervals %s and %s, fast interval must be positive and not greater "+
+			"than steady state interval", fast, steady)
+	}
+	m.discovery.set(fast, steady)
+	return nil
+}
+
+// NextDiscoverInterval returns time to wait before the next Discover
+func (m *VolumeManager) NextDiscoverInterval() time.Duration {
+	return m.discovery.get()
+}
@@ -0,0 +1,52 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVolumeManager_SetDiscoveryIntervals(t *testing.T) {
+	vm := &VolumeManager{discovery: newDiscoveryInterval(DefaultDiscoverFastInterval, DefaultDiscoverInterval)}
+	assert.Equal(t, DefaultDiscoverFastInterval, vm.NextDiscoverInterval())
+
+	assert.NotNil(t, vm.SetDiscoveryIntervals(0, time.Minute))
+	assert.NotNil(t, vm.SetDiscoveryIntervals(time.Minute, time.Second))
+	assert.Nil(t, vm.SetDiscoveryIntervals(5*time.Second, time.Minute))
+	// current interval is within new bounds
+	assert.Equal(t, DefaultDiscoverFastInterval, vm.NextDiscoverInterval())
+
+	// interval grows twice after each discovery without changes
+	for _, expected := range []time.Duration{20 * time.Second, 40 * time.Second, time.Minute, time.Minute} {
+		vm.discovery.observe(false)
+		assert.Equal(t, expected, vm.NextDiscoverInterval())
+	}
+
+	// fast interval after changes
+	vm.discovery.observe(true)
+	assert.Equal(t, 5*time.Second, vm.NextDiscoverInterval())
+
+	// the next interval is kept within new bounds
+	assert.Nil(t, vm.SetDiscoveryIntervals(10*time.Second, 10*time.Minute))
+	assert.Equal(t, 10*time.Second, vm.NextDiscoverInterval())
+	vm.discovery.observe(false)
+	assert.Nil(t, vm.SetDiscoveryIntervals(time.Second, 15*time.Second))
+	assert.Equal(t, 15*time.Second, vm.NextDiscoverInterval())
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/util/retry"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+// SetDriveGrowth enables use of space which is added to drives, e.g. after expansion of RAID virtual disk.
+// Drive which became larger is detected during Discover by size reported by drive manager
+func (m *VolumeManager) SetDriveGrowth() {
+	m.driveGrowth = ph.NewWrapGrowImpl(command.NewExecutor(m.log.Logger))
+}
+
+// growExpandedDrives uses space added to drives: PV of LogicalVolumeGroup is resized and AvailableCapacity
+// of LogicalVolumeGroup is increased, partition of drive-based volume is extended to the end of drive.
+// AvailableCapacity of clean drive follows size of Drive CR. Drive CR is annotated with its previous size
+// until added space is used, so failed attempt is retried during next Discover
+func (m *VolumeManager) growExpandedDrives(ctx context.Context, updates *driveUpdates) {
+	if m.driveGrowth == nil {
+		return
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "growExpandedDrives",
+	})
+
+	driveCRs := append([]*drivecrd.Drive{}, updates.NotChanged...)
+	for _, upd := range updates.Updated {
+		drive, previousSize := upd.CurrentState, upd.PreviousState.Spec.Size
+		if _, ok := drive.GetAnnotations()[apiV1.DriveAnnotationGrownFrom]; !ok &&
+			previousSize > 0 && drive.Spec.Size > previousSize {
+			if drive.Annotations == nil {
+				drive.Annotations = map[string]string{}
+			}
+			drive.Annotations[apiV1.DriveAnnotationGrownFrom] = strconv.FormatInt(previousSize, 10)
+		}
+		driveCRs = append(driveCRs, drive)
+	}
+
+	for _, drive := range driveCRs {
+		value, ok := drive.GetAnnotations()[apiV1.DriveAnnotationGrownFrom]
+		if !ok || drive.Spec.Status != apiV1.DriveStatusOnline || drive.Spec.IsSystem {
+			continue
+		}
+		grownFrom, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			err = m.growDrive(ctx, drive)
+		}
+		if err != nil {
+			ll.Errorf("Unable to use space added to drive %s: %v", drive.Spec.SerialNumber, err)
+			m.sendEventForDrive(drive, eventing.DriveCapacityGrowFailed,
+				"Unable to use space added to drive: %v.", err)
+			// annotation is saved to retry
+			if updateErr := m.k8sClient.UpdateCR(ctx, drive); updateErr != nil {
+				ll.Errorf("Unable to update drive %s: %v", drive.Name, updateErr)
+			}
+			continue
+		}
+		m.sendEventForDrive(drive, eventing.DriveCapacityGrown, "Drive grew from %d to %d bytes.",
+			grownFrom, drive.Spec.Size)
+		delete(drive.Annotations, apiV1.DriveAnnotationGrownFrom)
+		if err = m.k8sClient.UpdateCR(ctx, drive); err != nil {
+			ll.Errorf("Unable to remove %s annotation of drive %s: %v", apiV1.DriveAnnotationGrownFrom, drive.Name, err)
+		}
+	}
+}
+
+// growDrive uses space added to the drive by PV of its LogicalVolumeGroup or by partition of its volume
+func (m *VolumeManager) growDrive(ctx context.Context, drive *drivecrd.Drive) error {
+	lvgs, err := m.cachedCrHelper.GetLVGCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	for i := range lvgs {
+		if util.ContainsString(lvgs[i].Spec.Locations, drive.Spec.UUID) {
+			return m.growLVG(ctx, &lvgs[i], drive.Spec.Path)
+		}
+	}
+
+	volumes, err := m.cachedCrHelper.GetVolumesByLocation(ctx, drive.Spec.UUID)
+	if err != nil {
+		return err
+	}
+	if len(volumes) == 0 {
+		// AvailableCapacity of clean drive is updated by capacity controller
+		return nil
+	}
+	// volume size isn't changed, added space is available in partition of the volume
+	return m.driveGrowth.GrowPartition(drive.Spec.Path, p.DefaultPartitionNumber)
+}
+
+// growLVG resizes PV on device and adds space which appeared in the volume group to LogicalVolumeGroup
+// and its AvailableCapacity. Size of LogicalVolumeGroup is updated first, so space isn't added to
+// AvailableCapacity twice. AvailableCapacity is re-read on conflict, since it's changed by controller concurrently,
+// size of LogicalVolumeGroup is restored if AvailableCapacity isn't updated, so added space is found on retry
+func (m *VolumeManager) growLVG(ctx context.Context, lvg *lvgcrd.LogicalVolumeGroup, device string) error {
+	if err := m.lvmOps.PVResize(device); err != nil {
+		return fmt.Errorf("unable to resize PV %s: %v", device, err)
+	}
+	vgSize, err := m.lvmOps.GetVGSize(lvg.Spec.Name)
+	if err != nil {
+		return err
+	}
+	added := vgSize - lvg.Spec.Size
+	if added <= 0 {
+		return nil
+	}
+	ac, err := m.crHelper.GetACByLocation(lvg.Name)
+	if err != nil {
+		return fmt.Errorf("unable to read AvailableCapacity of LogicalVolumeGroup %s: %v", lvg.Name, err)
+	}
+	lvg.Spec.Size = vgSize
+	if err = m.k8sClient.UpdateCR(ctx, lvg); err != nil {
+		return err
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if ac, err = m.crHelper.GetACByLocation(lvg.Name); err != nil {
+			return err
+		}
+		ac.Spec.Size += added
+		return m.k8sClient.UpdateCR(ctx, ac)
+	})
+	if err != nil {
+		lvg.Spec.Size -= added
+		if updateErr := m.k8sClient.UpdateCR(ctx, lvg); updateErr != nil {
+			m.log.Errorf("Unable to restore size of LogicalVolumeGroup %s: %v", lvg.Name, updateErr)
+		}
+		return fmt.Errorf("unable to update AvailableCapacity of LogicalVolumeGroup %s: %v", lvg.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/logger/objects"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+)
+
+func TestVolumeManager_growExpandedDrives(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	var (
+		recorder = new(mocks.NoOpRecorder)
+		lvmOps   = &mocklu.MockWrapLVM{}
+		grow     = &mockProv.MockWrapGrow{}
+		vm       = NewVolumeManager(nil, nil, testLogger, kubeClient, kubeClient, recorder, nodeID, nodeName)
+		lvgDrive = testDriveCR.DeepCopy()
+		volDrive = testDriveCR.DeepCopy()
+		lvg      = testLVGCR.DeepCopy()
+		volume   = volCR.DeepCopy()
+		ac       = kubeClient.ConstructACCR("ac", api.AvailableCapacity{Location: lvg.Name, NodeId: nodeID,
+			StorageClass: apiV1.StorageClassHDDLVG, Size: 100})
+		driveCR    = &drivecrd.Drive{}
+		updatedLVG = &lvgcrd.LogicalVolumeGroup{}
+		updatedAC  = &accrd.AvailableCapacity{}
+	)
+	lvgDrive.Spec.IsSystem = false
+	volDrive.Name, volDrive.Spec.UUID, volDrive.Spec.Path, volDrive.Spec.IsSystem = "drive2", "drive2", "/dev/sdb", false
+	volume.Spec.Location = volDrive.Spec.UUID
+	assert.Nil(t, kubeClient.CreateCR(testCtx, lvgDrive.Name, lvgDrive))
+	assert.Nil(t, kubeClient.CreateCR(testCtx, volDrive.Name, volDrive))
+	assert.Nil(t, kubeClient.CreateCR(testCtx, lvg.Name, lvg))
+	assert.Nil(t, kubeClient.CreateCR(testCtx, volume.Name, volume))
+	assert.Nil(t, kubeClient.CreateCR(testCtx, ac.Name, ac))
+	grown := func(drive *drivecrd.Drive, size int64) updatedDrive {
+		current := drive.DeepCopy()
+		current.Spec.Size = size
+		return updatedDrive{PreviousState: drive, CurrentState: current}
+	}
+	updates := &driveUpdates{Updated: []updatedDrive{
+		grown(lvgDrive, lvgDrive.Spec.Size+1000), grown(volDrive, volDrive.Spec.Size+1000)}}
+
+	// disabled
+	vm.growExpandedDrives(testCtx, updates)
+	assert.Empty(t, recorder.Calls)
+
+	vm.SetDriveGrowth()
+	vm.lvmOps = lvmOps
+	vm.driveGrowth = grow
+	lvmOps.On("PVResize", "/dev/sda").Return(nil)
+	lvmOps.On("GetVGSize", lvg.Spec.Name).Return(lvg.Spec.Size+900, nil)
+	grow.On("GrowPartition", "/dev/sdb", "1").Return(testErr).Once()
+
+	// PV of LVG is resized, partition of volume isn't extended
+	vm.growExpandedDrives(testCtx, updates)
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.DriveCapacityGrown, recorder.Calls[0].Event)
+	assert.Equal(t, eventing.DriveCapacityGrowFailed, recorder.Calls[1].Event)
+	assert.Nil(t, kubeClient.ReadCR(testCtx, lvg.Name, "", updatedLVG))
+	assert.Equal(t, lvg.Spec.Size+900, updatedLVG.Spec.Size)
+	assert.Nil(t, kubeClient.ReadCR(testCtx, ac.Name, "", updatedAC))
+	assert.Equal(t, int64(1000), updatedAC.Spec.Size)
+	assert.Nil(t, kubeClient.ReadCR(testCtx, lvgDrive.Name, "", driveCR))
+	assert.Empty(t, driveCR.Annotations[apiV1.DriveAnnotationGrownFrom])
+	assert.Nil(t, kubeClient.ReadCR(testCtx, volDrive.Name, "", driveCR))
+	assert.Equal(t, strconv.FormatInt(volDrive.Spec.Size, 10), driveCR.Annotations[apiV1.DriveAnnotationGrownFrom])
+
+	// failed growth is retried
+	grow.On("GrowPartition", "/dev/sdb", "1").Return(nil).Once()
+	vm.growExpandedDrives(testCtx, &driveUpdates{NotChanged: []*drivecrd.Drive{driveCR}})
+	assert.Len(t, recorder.Calls, 3)
+	assert.Equal(t, eventing.DriveCapacityGrown, recorder.Calls[2].Event)
+	assert.Nil(t, kubeClient.ReadCR(testCtx, volDrive.Name, "", driveCR))
+	assert.Empty(t, driveCR.Annotations[apiV1.DriveAnnotationGrownFrom])
+}
+
+// acUpdateErrClient returns errors on update of AvailableCapacity
+type acUpdateErrClient struct {
+	k8sCl.Client
+	errs []error
+}
+
+func (c *acUpdateErrClient) Update(ctx context.Context, obj k8sCl.Object, opts ...k8sCl.UpdateOption) error {
+	if _, ok := obj.(*accrd.AvailableCapacity); ok && len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestVolumeManager_growLVG(t *testing.T) {
+	fakeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	var (
+		errClient  = &acUpdateErrClient{Client: fakeClient.Client}
+		kubeClient = k8s.NewKubeClient(errClient, testLogger, objects.NewObjectLogger(), testNs)
+		lvmOps     = &mocklu.MockWrapLVM{}
+		vm         = NewVolumeManager(nil, nil, testLogger, kubeClient, kubeClient, new(mocks.NoOpRecorder), nodeID,
+			nodeName)
+		lvg = testLVGCR.DeepCopy()
+		ac  = kubeClient.ConstructACCR("ac", api.AvailableCapacity{Location: lvg.Name, NodeId: nodeID,
+			StorageClass: apiV1.StorageClassHDDLVG, Size: 100})
+		updatedLVG = &lvgcrd.LogicalVolumeGroup{}
+		updatedAC  = &accrd.AvailableCapacity{}
+		conflict   = k8serrors.NewConflict(schema.GroupResource{Resource: "availablecapacities"}, ac.Name, testErr)
+	)
+	vm.lvmOps = lvmOps
+	assert.Nil(t, kubeClient.CreateCR(testCtx, lvg.Name, lvg))
+	assert.Nil(t, kubeClient.CreateCR(testCtx, ac.Name, ac))
+	lvmOps.On("PVResize", "/dev/sda").Return(nil)
+	lvmOps.On("GetVGSize", lvg.Spec.Name).Return(lvg.Spec.Size+900, nil)
+
+	// AvailableCapacity isn't updated, size of LVG is restored
+	errClient.errs = []error{testErr}
+	assert.NotNil(t, vm.growLVG(testCtx, lvg.DeepCopy(), "/dev/sda"))
+	assert.Nil(t, kubeClient.ReadCR(testCtx, lvg.Name, "", updatedLVG))
+	assert.Equal(t, lvg.Spec.Size, updatedLVG.Spec.Size)
+	assert.Nil(t, kubeClient.ReadCR(testCtx, ac.Name, "", updatedAC))
+	assert.Equal(t, int64(100), updatedAC.Spec.Size)
+
+	// AvailableCapacity is re-read and updated after conflict
+	errClient.errs = []error{conflict, conflict}
+	assert.Nil(t, vm.growLVG(testCtx, updatedLVG, "/dev/sda"))
+	assert.Nil(t, kubeClient.ReadCR(testCtx, lvg.Name, "", updatedLVG))
+	assert.Equal(t, lvg.Spec.Size+900, updatedLVG.Spec.Size)
+	assert.Nil(t, kubeClient.ReadCR(testCtx, ac.Name, "", updatedAC))
+	assert.Equal(t, int64(1000), updatedAC.Spec.Size)
+}
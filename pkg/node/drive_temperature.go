@@ -0,0 +1,165 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// driveTemperatureMonitor holds temperature thresholds per drive type and exposes drive temperature as a metric
+type driveTemperatureMonitor struct {
+	// key - drive type (HDD/SSD/NVME), value - max temperature in Celsius
+	thresholds map[string]int64
+	// whether AvailableCapacity of hot drives should be marked to be de-prioritized by capacity planner
+	markCapacity bool
+	metric       *prometheus.GaugeVec
+}
+
+// SetDriveTemperatureThresholds enables drive temperature monitoring during Discover
+// Receives thresholds in Celsius per drive type and whether AvailableCapacity of hot drives should be annotated
+func (m *VolumeManager) SetDriveTemperatureThresholds(thresholds map[string]int64, markCapacity bool) {
+	tm := &driveTemperatureMonitor{
+		thresholds:   thresholds,
+		markCapacity: markCapacity,
+		metric: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "drive_temperature_celsius",
+			Help: "last drive temperature reported by drive manager",
+		}, []string{"serial_number", "type"}),
+	}
+	if err := prometheus.Register(tm.metric); err != nil {
+		m.log.WithField("method", "SetDriveTemperatureThresholds").
+			Errorf("Failed to register metric: %v", err)
+	}
+	m.temperatureMonitor = tm
+}
+
+// checkDrivesTemperature compares temperature of discovered drives with thresholds, sends events and
+// sets temperature annotation on Drive CRs (and AvailableCapacity CRs if configured) when drive becomes hot or cools down
+func (m *VolumeManager) checkDrivesTemperature(ctx context.Context, updates *driveUpdates, drivesFromMgr []*api.Drive) {
+	if m.temperatureMonitor == nil {
+		return
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "checkDrivesTemperature",
+	})
+
+	driveCRs := append(append([]*drivecrd.Drive{}, updates.Created...), updates.NotChanged...)
+	for _, upd := range updates.Updated {
+		driveCRs = append(driveCRs, upd.CurrentState)
+	}
+
+	for _, driveCR := range driveCRs {
+		var temperature int64
+		for _, d := range drivesFromMgr {
+			if m.drivesAreTheSame(d, &driveCR.Spec) {
+				temperature = d.Temperature
+				break
+			}
+		}
+		// temperature isn't reported
+		if temperature == 0 {
+			continue
+		}
+		m.temperatureMonitor.metric.With(prometheus.Labels{
+			"serial_number": driveCR.Spec.SerialNumber, "type": driveCR.Spec.Type}).Set(float64(temperature))
+
+		threshold, ok := m.temperatureMonitor.thresholds[driveCR.Spec.Type]
+		if !ok {
+			continue
+		}
+		isHot := temperature >= threshold
+		wasHot := driveCR.GetAnnotations()[apiV1.DriveAnnotationTemperature] == apiV1.DriveTemperatureHigh
+		if isHot == wasHot {
+			continue
+		}
+
+		if isHot {
+			m.sendEventForDrive(driveCR, eventing.DriveTemperatureHigh,
+				"Drive temperature is %d°C, threshold %d°C.", temperature, threshold)
+		} else {
+			m.sendEventForDrive(driveCR, eventing.DriveTemperatureNormal,
+				"Drive temperature is %d°C, threshold %d°C.", temperature, threshold)
+		}
+		if err := m.setTemperatureAnnotation(ctx, driveCR, isHot); err != nil {
+			ll.Errorf("Unable to update temperature annotation for drive %s: %v", driveCR.Name, err)
+			continue
+		}
+		if !m.temperatureMonitor.markCapacity {
+			continue
+		}
+		location := driveCR.Spec.UUID
+		if lvg, err := m.crHelper.GetLVGByDrive(ctx, driveCR.Spec.UUID); err == nil && lvg != nil {
+			location = lvg.Name
+		}
+		ac, err := m.crHelper.GetACByLocation(location)
+		if err != nil {
+			ll.Warnf("Unable to find AvailableCapacity for location %s: %v", location, err)
+			continue
+		}
+		if err = m.setTemperatureAnnotation(ctx, ac, isHot); err != nil {
+			ll.Errorf("Unable to update temperature annotation for AvailableCapacity %s: %v", ac.Name, err)
+		}
+	}
+}
+
+func (m *VolumeManager) setTemperatureAnnotation(ctx context.Context, obj k8sCl.Object, isHot bool) error {
+	annotations := obj.GetAnnotations()
+	if isHot {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[apiV1.DriveAnnotationTemperature] = apiV1.DriveTemperatureHigh
+	} else {
+		delete(annotations, apiV1.DriveAnnotationTemperature)
+	}
+	obj.SetAnnotations(annotations)
+	return m.k8sClient.UpdateCR(ctx, obj)
+}
+
+// ParseTemperatureThresholds parses thresholds in format "HDD=55,SSD=70" where value is temperature in Celsius
+// Returns map drive type -> threshold or error if format is wrong
+func ParseTemperatureThresholds(str string) (map[string]int64, error) {
+	thresholds := make(map[string]int64)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("threshold %s has wrong format, expected <drive type>=<temperature>", item)
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("threshold %s has wrong value, expected positive temperature in Celsius", item)
+		}
+		thresholds[strings.ToUpper(strings.TrimSpace(parts[0]))] = value
+	}
+	return thresholds, nil
+}
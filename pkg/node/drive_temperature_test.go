@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestParseTemperatureThresholds(t *testing.T) {
+	thresholds, err := ParseTemperatureThresholds("hdd=55, NVME=75")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int64{apiV1.DriveTypeHDD: 55, apiV1.DriveTypeNVMe: 75}, thresholds)
+
+	_, err = ParseTemperatureThresholds("HDD")
+	assert.NotNil(t, err)
+	_, err = ParseTemperatureThresholds("HDD=hot")
+	assert.NotNil(t, err)
+}
+
+func TestVolumeManager_checkDrivesTemperature(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	recorder := new(mocks.NoOpRecorder)
+	vm := NewVolumeManager(nil, nil, testLogger, kubeClient, kubeClient, recorder, nodeID, nodeName)
+	vm.SetDriveTemperatureThresholds(map[string]int64{apiV1.DriveTypeHDD: 50}, true)
+
+	driveCR := testDriveCR.DeepCopy()
+	assert.Nil(t, kubeClient.CreateCR(testCtx, driveCR.Name, driveCR))
+	ac := kubeClient.ConstructACCR("ac", api.AvailableCapacity{
+		Location: drive1.UUID, NodeId: nodeID, StorageClass: apiV1.StorageClassHDD, Size: drive1.Size})
+	assert.Nil(t, kubeClient.CreateCR(testCtx, ac.Name, ac))
+
+	hot := drive1
+	hot.Temperature = 60
+	vm.checkDrivesTemperature(testCtx, &driveUpdates{NotChanged: []*drivecrd.Drive{driveCR}}, []*api.Drive{&hot})
+	assert.Len(t, recorder.Calls, 1)
+	assert.Equal(t, eventing.DriveTemperatureHigh, recorder.Calls[0].Event)
+
+	updatedDrive := &drivecrd.Drive{}
+	assert.Nil(t, kubeClient.ReadCR(testCtx, driveCR.Name, "", updatedDrive))
+	assert.Equal(t, apiV1.DriveTemperatureHigh, updatedDrive.Annotations[apiV1.DriveAnnotationTemperature])
+	updatedAC := &accrd.AvailableCapacity{}
+	assert.Nil(t, kubeClient.ReadCR(testCtx, ac.Name, "", updatedAC))
+	assert.Equal(t, apiV1.DriveTemperatureHigh, updatedAC.Annotations[apiV1.DriveAnnotationTemperature])
+
+	// still hot - no new events
+	vm.checkDrivesTemperature(testCtx, &driveUpdates{NotChanged: []*drivecrd.Drive{updatedDrive}}, []*api.Drive{&hot})
+	assert.Len(t, recorder.Calls, 1)
+
+	cool := drive1
+	cool.Temperature = 40
+	vm.checkDrivesTemperature(testCtx, &driveUpdates{NotChanged: []*drivecrd.Drive{updatedDrive}}, []*api.Drive{&cool})
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.DriveTemperatureNormal, recorder.Calls[1].Event)
+	// decoding into the existing object keeps keys of its annotations map
+	updatedAC = &accrd.AvailableCapacity{}
+	assert.Nil(t, kubeClient.ReadCR(testCtx, ac.Name, "", updatedAC))
+	assert.Empty(t, updatedAC.Annotations[apiV1.DriveAnnotationTemperature])
+}
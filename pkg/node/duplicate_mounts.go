@@ -0,0 +1,165 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// Policies of handling of volumes which are mounted at target paths unknown to node service,
+// e.g. at directories of old pod UIDs which are left by kubelet after restart
+const (
+	// DuplicateMountsPolicyReport reports duplicate mounts with events and metrics
+	DuplicateMountsPolicyReport = "report"
+	// DuplicateMountsPolicyUnmount reports duplicate mounts and unmounts them
+	DuplicateMountsPolicyUnmount = "unmount"
+)
+
+// duplicateMountsReconciler holds settings and state of detection of duplicate mounts
+type duplicateMountsReconciler struct {
+	unmount bool
+	// duplicate mount points detected during previous reconciliation, mount point is unmounted only when it is
+	// detected twice in a row, so mount of in-flight NodePublishVolume isn't treated as duplicate
+	detected map[string]bool
+	// metrics
+	detectedCount prometheus.Counter
+	removedCount  prometheus.Counter
+}
+
+// SetDuplicateMountsPolicy enables detection of duplicate mounts of volumes during Discover
+// Receives policy: report or unmount
+func (m *VolumeManager) SetDuplicateMountsPolicy(policy string) error {
+	if policy != DuplicateMountsPolicyReport && policy != DuplicateMountsPolicyUnmount {
+		return fmt.Errorf("duplicate mounts policy %s isn't supported, expected %s or %s",
+			policy, DuplicateMountsPolicyReport, DuplicateMountsPolicyUnmount)
+	}
+	r := &duplicateMountsReconciler{
+		unmount:  policy == DuplicateMountsPolicyUnmount,
+		detected: make(map[string]bool),
+		detectedCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "duplicate_mounts_detected_total",
+			Help:        "number of target paths where volumes were mounted in addition to the expected ones",
+			ConstLabels: prometheus.Labels{"node": m.nodeName},
+		}),
+		removedCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "duplicate_mounts_removed_total",
+			Help:        "number of duplicate mounts of volumes which were unmounted",
+			ConstLabels: prometheus.Labels{"node": m.nodeName},
+		}),
+	}
+	for _, c := range []prometheus.Collector{r.detectedCount, r.removedCount} {
+		if err := prometheus.Register(c); err != nil {
+			m.log.WithField("method", "SetDuplicateMountsPolicy").Errorf("Failed to register metric: %v", err)
+		}
+	}
+	m.duplicateMounts = r
+	return nil
+}
+
+// reconcileDuplicateMounts finds mounts of volumes at kubelet target paths which aren't recorded as publish targets
+// of the volumes and unmounts them if policy allows
+func (m *VolumeManager) reconcileDuplicateMounts() error {
+	if m.duplicateMounts == nil {
+		return nil
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "reconcileDuplicateMounts",
+	})
+
+	mounts, err := readMountInfo(mountInfoPath)
+	if err != nil {
+		return err
+	}
+	volumes, err := m.cachedCrHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+
+	detected := make(map[string]bool)
+	for i := range volumes {
+		volume := &volumes[i]
+		targets, ok := m.expectedPublishTargets(volume)
+		if !ok {
+			continue
+		}
+		for _, mount := range mounts {
+			if _, expected := targets[mount.MountPoint]; expected || !m.isPublishTargetOf(mount.MountPoint, volume.Name) {
+				continue
+			}
+			detected[mount.MountPoint] = true
+			if !m.duplicateMounts.detected[mount.MountPoint] {
+				ll.Warnf("Volume %s is mounted at unexpected target %s", volume.Name, mount.MountPoint)
+				m.duplicateMounts.detectedCount.Inc()
+				m.recorder.Eventf(volume, eventing.VolumeDuplicateMount,
+					"Volume %s is mounted at unexpected target %s", volume.Name, mount.MountPoint)
+				continue
+			}
+			if !m.duplicateMounts.unmount {
+				continue
+			}
+			if err = m.fsOps.UnmountWithCheck(mount.MountPoint); err != nil {
+				ll.Errorf("Unable to unmount duplicate mount %s of volume %s: %v", mount.MountPoint, volume.Name, err)
+				continue
+			}
+			ll.Infof("Duplicate mount %s of volume %s was unmounted", mount.MountPoint, volume.Name)
+			m.duplicateMounts.removedCount.Inc()
+			delete(detected, mount.MountPoint)
+		}
+	}
+	m.duplicateMounts.detected = detected
+	return nil
+}
+
+// expectedPublishTargets returns target paths where the volume is expected to be mounted
+// Returns false if expected targets can't be determined, e.g. for published volume which CR is written
+// by older version of the driver without publish targets annotation
+func (m *VolumeManager) expectedPublishTargets(volume *volumecrd.Volume) (map[string]string, bool) {
+	switch volume.Spec.CSIStatus {
+	case apiV1.Created, apiV1.VolumeReady:
+		return map[string]string{}, true
+	case apiV1.Published:
+		targets, err := getPublishTargets(volume)
+		if err != nil || len(targets) == 0 {
+			return nil, false
+		}
+		return targets, true
+	default:
+		return nil, false
+	}
+}
+
+// isPublishTargetOf returns true if path is target path which kubelet passes to NodePublishVolume for the volume:
+// <kubelet root>/pods/<pod UID>/volumes/kubernetes.io~csi/<volume>/mount for filesystem mode or
+// <kubelet root>/plugins/kubernetes.io/csi/volumeDevices/publish/<volume>/<pod UID> for block mode
+func (m *VolumeManager) isPublishTargetOf(path, volumeName string) bool {
+	fsTarget := filepath.Join("volumes", "kubernetes.io~csi", volumeName, "mount")
+	if strings.HasPrefix(path, m.kubeletPodsPath()+"/") && strings.HasSuffix(path, "/"+fsTarget) {
+		return true
+	}
+	blockTargets := filepath.Join(m.getKubeletRootDir(), "plugins", "kubernetes.io", "csi", "volumeDevices",
+		"publish", volumeName)
+	return filepath.Dir(path) == blockTargets
+}
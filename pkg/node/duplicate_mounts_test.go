@@ -0,0 +1,113 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+)
+
+func TestVolumeManager_reconcileDuplicateMounts(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		fsOps    = &mockProv.MockFsOpts{}
+		recorder = new(mocks.NoOpRecorder)
+		rootDir  = "/var/lib/kubelet"
+		fsTarget = func(podUID, volume string) string {
+			return filepath.Join(rootDir, "pods", podUID, "volumes", "kubernetes.io~csi", volume, "mount")
+		}
+		blockTarget = func(podUID, volume string) string {
+			return filepath.Join(rootDir, "plugins", "kubernetes.io", "csi", "volumeDevices", "publish", volume, podUID)
+		}
+		published   = testVolumeCR1.DeepCopy()
+		ready       = testVolumeCR2.DeepCopy()
+		expected    = fsTarget("pod-2", published.Name)
+		staleFS     = fsTarget("pod-1", published.Name)
+		staleBlock  = blockTarget("pod-3", ready.Name)
+		writeMounts = func(paths ...string) {
+			var lines []string
+			for i, path := range paths {
+				lines = append(lines, fmt.Sprintf("%d 22 8:%d / %s rw - xfs /dev/sda%d rw", 30+i, i, path, i))
+			}
+			assert.Nil(t, ioutil.WriteFile(mountInfoPath, []byte(strings.Join(lines, "\n")), 0600))
+		}
+	)
+	vm.fsOps = fsOps
+	vm.recorder = recorder
+	vm.SetKubeletRootDir(rootDir)
+	defer func(path string) { mountInfoPath = path }(mountInfoPath)
+	mountInfoPath = filepath.Join(t.TempDir(), "mountinfo")
+
+	// disabled
+	assert.Nil(t, vm.reconcileDuplicateMounts())
+	assert.NotNil(t, vm.SetDuplicateMountsPolicy("remove"))
+
+	published.Spec.CSIStatus = apiV1.Published
+	assert.Nil(t, addPublishTarget(published, expected, "pod-2"))
+	ready.Spec.CSIStatus = apiV1.VolumeReady
+	addVolumeCRs(vm.k8sClient, published, ready)
+	// staging path and mounts of other volumes are ignored
+	writeMounts("/", expected, staleFS, staleBlock, filepath.Join(rootDir, "plugins", "kubernetes.io", "csi",
+		"pv", published.Name, "globalmount"), fsTarget("pod-4", "pvc-other"))
+
+	// duplicate mounts are reported once
+	assert.Nil(t, vm.SetDuplicateMountsPolicy(DuplicateMountsPolicyReport))
+	assert.Nil(t, vm.reconcileDuplicateMounts())
+	assert.Nil(t, vm.reconcileDuplicateMounts())
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.VolumeDuplicateMount, recorder.Calls[0].Event)
+	fsOps.AssertNotCalled(t, "UnmountWithCheck", staleFS)
+
+	// duplicate mounts are unmounted when they are detected twice in a row
+	assert.Nil(t, vm.SetDuplicateMountsPolicy(DuplicateMountsPolicyUnmount))
+	fsOps.On("UnmountWithCheck", staleFS).Return(nil).Once()
+	fsOps.On("UnmountWithCheck", staleBlock).Return(nil).Once()
+	assert.Nil(t, vm.reconcileDuplicateMounts())
+	fsOps.AssertNotCalled(t, "UnmountWithCheck", staleFS)
+	assert.Nil(t, vm.reconcileDuplicateMounts())
+	fsOps.AssertNumberOfCalls(t, "UnmountWithCheck", 2)
+	assert.Empty(t, vm.duplicateMounts.detected)
+}
+
+func TestVolumeManager_expectedPublishTargets(t *testing.T) {
+	vm := prepareSuccessVolumeManager(t)
+	volume := testVolumeCR1.DeepCopy()
+
+	volume.Spec.CSIStatus = apiV1.Created
+	targets, ok := vm.expectedPublishTargets(volume)
+	assert.True(t, ok)
+	assert.Empty(t, targets)
+
+	// CR is written by older version of the driver
+	volume.Spec.CSIStatus = apiV1.Published
+	_, ok = vm.expectedPublishTargets(volume)
+	assert.False(t, ok)
+
+	volume.Spec.CSIStatus = apiV1.Failed
+	_, ok = vm.expectedPublishTargets(volume)
+	assert.False(t, ok)
+}
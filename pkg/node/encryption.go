@@ -0,0 +1,69 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/cryptsetup"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+)
+
+// openEncryptedVolume opens LUKS device of encrypted volume with key from node stage secret.
+// LUKS header is created during the first stage of volume, FS for FS mode volumes is created on opened device.
+// Receives api.Volume, path of partition/LV of the volume and secrets of NodeStageVolume request
+// Returns path of opened device or error if device contains unexpected data or key is wrong
+func (m *VolumeManager) openEncryptedVolume(vol *api.Volume, device string, secrets map[string]string) (string, error) {
+	if vol.Encryption != apiV1.EncryptionLUKS {
+		return "", fmt.Errorf("encryption %s isn't supported", vol.Encryption)
+	}
+	key := secrets[apiV1.EncryptionKeySecretKey]
+
+	fsType, err := m.fsOps.GetFSType(device)
+	if err != nil {
+		return "", err
+	}
+	switch fsType {
+	case cryptsetup.LuksFSType:
+	case "":
+		m.log.WithField("volumeID", vol.Id).Infof("Creating LUKS header on %s", device)
+		if err := m.cryptOps.Format(device, key); err != nil {
+			return "", err
+		}
+	default:
+		// device must not be formatted if it contains data, for example volume was created before encryption
+		return "", fmt.Errorf("device %s of encrypted volume contains %s", device, fsType)
+	}
+
+	path, err := m.cryptOps.Open(device, vol.Id, key)
+	if err != nil {
+		return "", err
+	}
+	if vol.Mode == apiV1.ModeFS {
+		if err := m.fsOps.CreateFSIfNotExist(fs.FileSystem(vol.Type), path); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// closeEncryptedVolume closes LUKS device of encrypted volume, is called after volume is unstaged
+func (m *VolumeManager) closeEncryptedVolume(vol *api.Volume) error {
+	return m.cryptOps.Close(vol.Id)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/cryptsetup"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+)
+
+func TestVolumeManager_openEncryptedVolume(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		fsOps    = &mockProv.MockFsOpts{}
+		cryptOps = &mocklu.MockWrapCryptsetup{}
+		device   = "/dev/sda1"
+		mapper   = "/dev/mapper/pvc-1"
+		secrets  = map[string]string{apiV1.EncryptionKeySecretKey: "key"}
+		vol      = &api.Volume{Id: "pvc-1", Mode: apiV1.ModeFS, Type: string(fs.XFS), Encryption: apiV1.EncryptionLUKS}
+	)
+	vm.fsOps = fsOps
+	vm.cryptOps = cryptOps
+
+	// first stage, LUKS header and FS are created
+	fsOps.On("GetFSType", device).Return("", nil).Once()
+	cryptOps.On("Format", device, "key").Return(nil).Once()
+	cryptOps.On("Open", device, vol.Id, "key").Return(mapper, nil)
+	fsOps.On("CreateFSIfNotExist", fs.XFS, mapper).Return(nil)
+	path, err := vm.openEncryptedVolume(vol, device, secrets)
+	assert.Nil(t, err)
+	assert.Equal(t, mapper, path)
+	cryptOps.AssertNumberOfCalls(t, "Format", 1)
+
+	// next stage, device is already formatted
+	fsOps.On("GetFSType", device).Return(cryptsetup.LuksFSType, nil).Once()
+	_, err = vm.openEncryptedVolume(vol, device, secrets)
+	assert.Nil(t, err)
+	cryptOps.AssertNumberOfCalls(t, "Format", 1)
+
+	// device contains data
+	fsOps.On("GetFSType", device).Return(string(fs.XFS), nil).Once()
+	_, err = vm.openEncryptedVolume(vol, device, secrets)
+	assert.NotNil(t, err)
+
+	// unsupported encryption
+	_, err = vm.openEncryptedVolume(&api.Volume{Encryption: "unknown"}, device, secrets)
+	assert.NotNil(t, err)
+
+	cryptOps.On("Close", vol.Id).Return(errors.New("busy")).Once()
+	assert.NotNil(t, vm.closeEncryptedVolume(vol))
+}
+
+func TestCSINodeService_SecretsAreNotLogged(t *testing.T) {
+	var (
+		out           = &bytes.Buffer{}
+		key           = "secret-passphrase"
+		secrets       = map[string]string{apiV1.EncryptionKeySecretKey: key}
+		partitionPath = "/partition/path/for/volume2"
+	)
+	testLogger.SetOutput(out)
+	defer testLogger.SetOutput(os.Stderr)
+	setVariables()
+
+	stageReq := getNodeStageRequest(testVolume2.Id, *testVolumeCap)
+	stageReq.Secrets = secrets
+	prov.On("GetVolumePath", &testVolume2).Return(partitionPath, nil)
+	fsOps.On("PrepareAndPerformMount",
+		partitionPath, path.Join(stageReq.GetStagingTargetPath(), stagingFileName), true, false).Return(nil)
+	_, err := node.NodeStageVolume(testCtx, stageReq)
+	assert.Nil(t, err)
+
+	publishReq := getNodePublishRequest(testV1ID, targetPath, *testVolumeCap)
+	publishReq.Secrets = secrets
+	fsOps.On("PrepareAndPerformMount",
+		path.Join(publishReq.GetStagingTargetPath(), stagingFileName), publishReq.GetTargetPath(), false, true).
+		Return(nil)
+	_, err = node.NodePublishVolume(testCtx, publishReq)
+	assert.Nil(t, err)
+
+	assert.Contains(t, out.String(), stageReq.GetStagingTargetPath())
+	assert.NotContains(t, out.String(), key)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// partitionDeviceType is a type of partition in lsblk output
+const partitionDeviceType = "part"
+
+// SetForeignDataScan enables scan of drives which don't have Drive CR yet for foreign data:
+// new drive isn't clean until it is scanned, drive with signatures of filesystem, LVM PV, RAID, LUKS or partition
+// table isn't clean until user sets DriveAnnotationClean to confirm that data can be destroyed
+func (m *VolumeManager) SetForeignDataScan() {
+	m.foreignDataScan = true
+}
+
+// scheduleForeignDataScan marks new drive as pending for foreign data scan, system drives aren't scanned.
+// Drives discovered during the first discovery are scanned too, they might be attached while node was down.
+// Caller is responsible for creation of the drive object
+func (m *VolumeManager) scheduleForeignDataScan(drive *drivecrd.Drive) {
+	if !m.foreignDataScan || drive.Spec.IsSystem {
+		return
+	}
+	if drive.Annotations == nil {
+		drive.Annotations = map[string]string{}
+	}
+	drive.Annotations[apiV1.DriveAnnotationForeignData] = apiV1.DriveAnnotationForeignDataPending
+	drive.Spec.IsClean = false
+}
+
+// processForeignData scans drive which is pending for foreign data scan and wipes foreign data of drive
+// which is annotated as clean by user
+// Returns true if drive is held by foreign data check, data discovery mustn't change clean state of such drive
+func (m *VolumeManager) processForeignData(drive *drivecrd.Drive) bool {
+	state, ok := drive.Annotations[apiV1.DriveAnnotationForeignData]
+	if !ok {
+		return false
+	}
+	if drive.Spec.Status != apiV1.DriveStatusOnline {
+		return true
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method":  "processForeignData",
+		"driveSN": drive.Spec.SerialNumber,
+	})
+
+	if state == apiV1.DriveAnnotationForeignDataPending {
+		signatures, err := m.dataDiscover.ScanSignatures(drive.Spec.Path)
+		if err != nil {
+			ll.Errorf("Unable to scan drive for foreign data: %v", err)
+			return true
+		}
+		if len(signatures) == 0 {
+			ll.Info("Foreign data isn't found")
+			delete(drive.Annotations, apiV1.DriveAnnotationForeignData)
+			m.sendEventForDrive(drive, eventing.DriveClean, "Foreign data isn't found on drive.")
+			m.changeDriveIsCleanField(drive, true)
+			return true
+		}
+		ll.Warnf("Drive has signatures %v, it isn't clean until it is annotated with %s=true",
+			signatures, apiV1.DriveAnnotationClean)
+		drive.Annotations[apiV1.DriveAnnotationForeignData] = strings.Join(signatures, ",")
+		m.sendEventForDrive(drive, eventing.DriveHasForeignData,
+			"Drive has signatures %s, set annotation %s=true to destroy data and use the drive.",
+			strings.Join(signatures, ", "), apiV1.DriveAnnotationClean)
+		m.changeDriveIsCleanField(drive, false)
+		return true
+	}
+
+	if drive.Annotations[apiV1.DriveAnnotationClean] != "true" {
+		return true
+	}
+	unlock := m.lockLocation(ll, drive.Spec.UUID)
+	defer unlock()
+	if err := m.wipeForeignData(drive.Spec.Path); err != nil {
+		ll.Errorf("Unable to wipe foreign data: %v", err)
+		// user has to confirm wipe again after the cause is fixed
+		delete(drive.Annotations, apiV1.DriveAnnotationClean)
+		m.sendEventForDrive(drive, eventing.DriveForeignDataWipeFailed, "Unable to wipe foreign data: %v.", err)
+		m.changeDriveIsCleanField(drive, false)
+		return true
+	}
+	ll.Infof("Foreign data %s was wiped", state)
+	delete(drive.Annotations, apiV1.DriveAnnotationForeignData)
+	delete(drive.Annotations, apiV1.DriveAnnotationClean)
+	m.sendEventForDrive(drive, eventing.DriveForeignDataWiped, "Foreign data %s was wiped.", state)
+	m.changeDriveIsCleanField(drive, true)
+	return true
+}
+
+// wipeForeignData wipes signatures of partitions and then signatures of device, so filesystem of old partition
+// doesn't appear again in partition which is created at the same offset
+// Returns error if device or its partition is mounted or is used by other device (LVM, RAID, LUKS)
+func (m *VolumeManager) wipeForeignData(device string) error {
+	bdevs, err := m.listBlk.GetBlockDevices(device)
+	if err != nil {
+		return err
+	}
+	if len(bdevs) == 0 {
+		return fmt.Errorf("device %s isn't found", device)
+	}
+	if bdevs[0].MountPoint != "" {
+		return fmt.Errorf("device %s is mounted to %s", device, bdevs[0].MountPoint)
+	}
+	for _, child := range bdevs[0].Children {
+		switch {
+		case child.Type != partitionDeviceType:
+			return fmt.Errorf("device %s is used by %s", device, child.Name)
+		case child.MountPoint != "":
+			return fmt.Errorf("partition %s is mounted to %s", child.Name, child.MountPoint)
+		case len(child.Children) > 0:
+			return fmt.Errorf("partition %s is used by %s", child.Name, child.Children[0].Name)
+		}
+	}
+	for _, child := range bdevs[0].Children {
+		if err = m.fsOps.WipeFS(child.Name); err != nil {
+			return err
+		}
+	}
+	return m.fsOps.WipeFS(device)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+)
+
+func TestVolumeManager_scheduleForeignDataScan(t *testing.T) {
+	vm := prepareSuccessVolumeManager(t)
+	drive := &drivecrd.Drive{Spec: drive2}
+	drive.Spec.IsClean = true
+
+	// scan is disabled
+	vm.scheduleForeignDataScan(drive)
+	assert.Empty(t, drive.Annotations)
+
+	vm.SetForeignDataScan()
+	vm.scheduleForeignDataScan(drive)
+	assert.Empty(t, drive.Annotations)
+	drive.Spec.IsSystem = false
+	vm.scheduleForeignDataScan(drive)
+	assert.Equal(t, apiV1.DriveAnnotationForeignDataPending, drive.Annotations[apiV1.DriveAnnotationForeignData])
+	assert.False(t, drive.Spec.IsClean)
+}
+
+func TestVolumeManager_processForeignData(t *testing.T) {
+	var (
+		vm           = prepareSuccessVolumeManager(t)
+		recorder     = new(mocks.NoOpRecorder)
+		dataDiscover = &mocklu.MockWrapDataDiscover{}
+		listBlk      = &mocklu.MockWrapLsblk{}
+		fsOps        = &mockProv.MockFsOpts{}
+		spec         = drive2
+		updated      = &drivecrd.Drive{}
+		lastEvent    = func() *eventing.EventDescription {
+			return recorder.Calls[len(recorder.Calls)-1].Event
+		}
+	)
+	vm.recorder, vm.dataDiscover, vm.listBlk, vm.fsOps = recorder, dataDiscover, listBlk, fsOps
+	spec.IsSystem = false
+	drive := vm.k8sClient.ConstructDriveCR(spec.UUID, spec)
+	addDriveCRs(vm.k8sClient, drive)
+
+	// drive isn't held by foreign data check
+	assert.False(t, vm.processForeignData(drive))
+
+	// scan failed, drive isn't clean
+	drive.Annotations = map[string]string{apiV1.DriveAnnotationForeignData: apiV1.DriveAnnotationForeignDataPending}
+	dataDiscover.On("ScanSignatures", spec.Path).Return(nil, errors.New("error")).Once()
+	assert.True(t, vm.processForeignData(drive))
+	assert.Empty(t, recorder.Calls)
+
+	// foreign data isn't found
+	dataDiscover.On("ScanSignatures", spec.Path).Return([]string{}, nil).Once()
+	assert.True(t, vm.processForeignData(drive))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, drive.Name, "", updated))
+	assert.True(t, updated.Spec.IsClean)
+	assert.Empty(t, updated.Annotations[apiV1.DriveAnnotationForeignData])
+	assert.Equal(t, eventing.DriveClean, lastEvent())
+
+	// drive has LVM PV, it isn't clean until user confirms wipe
+	updated.Annotations = map[string]string{apiV1.DriveAnnotationForeignData: apiV1.DriveAnnotationForeignDataPending}
+	dataDiscover.On("ScanSignatures", spec.Path).Return([]string{"LVM2_member"}, nil).Once()
+	assert.True(t, vm.processForeignData(updated))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, drive.Name, "", updated))
+	assert.False(t, updated.Spec.IsClean)
+	assert.Equal(t, "LVM2_member", updated.Annotations[apiV1.DriveAnnotationForeignData])
+	assert.Equal(t, eventing.DriveHasForeignData, lastEvent())
+	assert.True(t, vm.processForeignData(updated))
+	fsOps.AssertNotCalled(t, "WipeFS", spec.Path)
+
+	// logical volume of PV is active, wipe is refused and has to be confirmed again
+	updated.Annotations[apiV1.DriveAnnotationClean] = "true"
+	listBlk.On("GetBlockDevices", spec.Path).Return([]lsblk.BlockDevice{{Name: spec.Path,
+		Children: []lsblk.BlockDevice{{Name: "/dev/mapper/vg-lv", Type: "lvm"}}}}, nil).Once()
+	assert.True(t, vm.processForeignData(updated))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, drive.Name, "", updated))
+	assert.False(t, updated.Spec.IsClean)
+	assert.Empty(t, updated.Annotations[apiV1.DriveAnnotationClean])
+	assert.Equal(t, eventing.DriveForeignDataWipeFailed, lastEvent())
+	fsOps.AssertNotCalled(t, "WipeFS", spec.Path)
+
+	// partitions are wiped before device
+	updated.Annotations[apiV1.DriveAnnotationClean] = "true"
+	listBlk.On("GetBlockDevices", spec.Path).Return([]lsblk.BlockDevice{{Name: spec.Path,
+		Children: []lsblk.BlockDevice{{Name: spec.Path + "1", Type: partitionDeviceType}}}}, nil).Once()
+	fsOps.On("WipeFS", spec.Path+"1").Return(nil).Once()
+	fsOps.On("WipeFS", spec.Path).Return(nil).Once()
+	assert.True(t, vm.processForeignData(updated))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, drive.Name, "", updated))
+	assert.True(t, updated.Spec.IsClean)
+	assert.Empty(t, updated.Annotations)
+	assert.Equal(t, eventing.DriveForeignDataWiped, lastEvent())
+	fsOps.AssertExpectations(t)
+}
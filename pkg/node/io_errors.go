@@ -0,0 +1,157 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicepath"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/kernellog"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// ioErrorsMonitor counts I/O errors from kernel log per drive in sliding window
+// and marks drives with too many errors as SUSPECT
+type ioErrorsMonitor struct {
+	kernelLog kernellog.WrapKernelLog
+	// count of I/O errors in window after which drive health is set to SUSPECT
+	threshold int
+	// period in which I/O errors are counted, older errors are forgotten
+	window time.Duration
+	// key - drive serial number, value - I/O errors found by checks in window
+	errors map[string][]ioErrorsRecord
+	// serial numbers of drives which health is overridden
+	suspected map[string]bool
+	now       func() time.Time
+}
+
+// ioErrorsRecord is a count of I/O errors of drive found by one check
+type ioErrorsRecord struct {
+	time  time.Time
+	count int
+}
+
+// SetIOErrorsMonitoring enables kernel log scraping during Discover
+// Receives kernel log reader, count of I/O errors after which drive health is set to SUSPECT
+// and period in which errors are counted
+func (m *VolumeManager) SetIOErrorsMonitoring(kernelLog kernellog.WrapKernelLog, threshold int, window time.Duration) {
+	m.ioErrorsMonitor = &ioErrorsMonitor{
+		kernelLog: kernelLog,
+		threshold: threshold,
+		window:    window,
+		errors:    make(map[string][]ioErrorsRecord),
+		suspected: make(map[string]bool),
+		now:       time.Now,
+	}
+}
+
+// count removes errors of drive which left the window and returns count of remaining ones
+func (i *ioErrorsMonitor) count(serialNumber string, now time.Time) int {
+	var (
+		records = i.errors[serialNumber]
+		kept    = records[:0]
+		count   int
+	)
+	for _, record := range records {
+		if now.Sub(record.time) < i.window {
+			kept = append(kept, record)
+			count += record.count
+		}
+	}
+	if len(kept) == 0 {
+		delete(i.errors, serialNumber)
+	} else {
+		i.errors[serialNumber] = kept
+	}
+	return count
+}
+
+// checkIOErrors correlates I/O errors from kernel log with drives reported by drive manager
+// and replaces GOOD/UNKNOWN health of drives with too many I/O errors in window with SUSPECT.
+// Health reported by drive manager is kept again when errors leave the window without new ones.
+// Health override annotation is applied later and has higher priority
+func (m *VolumeManager) checkIOErrors(drivesFromMgr []*api.Drive) {
+	if m.ioErrorsMonitor == nil {
+		return
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "checkIOErrors",
+	})
+
+	ioErrors, err := m.ioErrorsMonitor.kernelLog.GetIOErrors()
+	if err != nil {
+		ll.Errorf("Unable to read kernel log: %v", err)
+	}
+
+	now := m.ioErrorsMonitor.now()
+	for _, drive := range drivesFromMgr {
+		if drive.Path == "" {
+			continue
+		}
+		deviceName := filepath.Base(drive.Path)
+		var found int
+		for dev, count := range ioErrors {
+			// errors of partitions are counted for disk, e.g. nvme0n1p1 for nvme0n1, but not nvme0n10
+			if disk, _, _ := devicepath.SplitPartition(dev); disk == deviceName {
+				found += count
+			}
+		}
+		count := m.ioErrorsMonitor.count(drive.SerialNumber, now) + found
+		if found > 0 {
+			m.ioErrorsMonitor.errors[drive.SerialNumber] = append(m.ioErrorsMonitor.errors[drive.SerialNumber],
+				ioErrorsRecord{time: now, count: found})
+		}
+
+		if count < m.ioErrorsMonitor.threshold {
+			if m.ioErrorsMonitor.suspected[drive.SerialNumber] {
+				ll.Infof("No new I/O errors of drive with S/N %s in %s, health %s is kept",
+					drive.SerialNumber, m.ioErrorsMonitor.window, drive.Health)
+				delete(m.ioErrorsMonitor.suspected, drive.SerialNumber)
+			}
+			continue
+		}
+		if !m.ioErrorsMonitor.suspected[drive.SerialNumber] {
+			ll.Warnf("Drive with S/N %s has %d I/O errors in kernel log in %s",
+				drive.SerialNumber, count, m.ioErrorsMonitor.window)
+			m.sendIOErrorsEvent(drive, count)
+			m.ioErrorsMonitor.suspected[drive.SerialNumber] = true
+		}
+		if drive.Health == apiV1.HealthGood || drive.Health == apiV1.HealthUnknown {
+			drive.Health = apiV1.HealthSuspect
+		}
+	}
+}
+
+func (m *VolumeManager) sendIOErrorsEvent(drive *api.Drive, count int) {
+	driveCRs, err := m.cachedCrHelper.GetDriveCRs(m.nodeID)
+	if err != nil {
+		return
+	}
+	for i := range driveCRs {
+		if m.drivesAreTheSame(drive, &driveCRs[i].Spec) {
+			m.sendEventForDrive(&driveCRs[i], eventing.DriveIOErrors,
+				"%d I/O errors found in kernel log for device %s in %s, threshold %d.",
+				count, drive.Path, m.ioErrorsMonitor.window, m.ioErrorsMonitor.threshold)
+			return
+		}
+	}
+}
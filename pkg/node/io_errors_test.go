@@ -0,0 +1,115 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+func TestVolumeManager_checkIOErrors(t *testing.T) {
+	var (
+		vm        = prepareSuccessVolumeManager(t)
+		recorder  = new(mocks.NoOpRecorder)
+		kernelLog = &mocklu.MockWrapKernelLog{}
+	)
+	vm.recorder = recorder
+	vm.SetIOErrorsMonitoring(kernelLog, 3, time.Hour)
+	now := time.Now()
+	vm.ioErrorsMonitor.now = func() time.Time { return now }
+
+	d1, d2 := disk1, disk2
+	d1.Path, d2.Path = "/dev/sdb", "/dev/sdc"
+	addDriveCRs(vm.k8sClient, vm.k8sClient.ConstructDriveCR(d1.UUID, d1))
+
+	getDrives := func() []*api.Drive {
+		drive1, drive2 := d1, d2
+		drive1.Health, drive2.Health = apiV1.HealthGood, apiV1.HealthGood
+		return []*api.Drive{&drive1, &drive2}
+	}
+
+	kernelLog.On("GetIOErrors").Return(map[string]int{"sdb": 1, "sdb1": 1, "sdc": 1, "sdbb": 5}, nil).Once()
+	drives := getDrives()
+	vm.checkIOErrors(drives)
+	assert.Equal(t, apiV1.HealthGood, drives[0].Health)
+	assert.Equal(t, apiV1.HealthGood, drives[1].Health)
+
+	now = now.Add(30 * time.Minute)
+	kernelLog.On("GetIOErrors").Return(map[string]int{"sdb": 1}, nil).Once()
+	drives = getDrives()
+	vm.checkIOErrors(drives)
+	assert.Equal(t, apiV1.HealthSuspect, drives[0].Health)
+	assert.Equal(t, apiV1.HealthGood, drives[1].Health)
+	assert.Len(t, recorder.Calls, 1)
+	assert.Equal(t, eventing.DriveIOErrors, recorder.Calls[0].Event)
+
+	// drive stays SUSPECT, event isn't repeated
+	kernelLog.On("GetIOErrors").Return(nil, errors.New("journalctl failed")).Once()
+	drives = getDrives()
+	vm.checkIOErrors(drives)
+	assert.Equal(t, apiV1.HealthSuspect, drives[0].Health)
+	assert.Len(t, recorder.Calls, 1)
+
+	// the first errors left the window
+	now = now.Add(30 * time.Minute)
+	kernelLog.On("GetIOErrors").Return(map[string]int{}, nil).Once()
+	drives = getDrives()
+	vm.checkIOErrors(drives)
+	assert.Equal(t, apiV1.HealthGood, drives[0].Health)
+	assert.Equal(t, 1, vm.ioErrorsMonitor.count(d1.SerialNumber, now))
+
+	// threshold is reached again, event is sent again
+	kernelLog.On("GetIOErrors").Return(map[string]int{"sdb": 2}, nil).Once()
+	drives = getDrives()
+	vm.checkIOErrors(drives)
+	assert.Equal(t, apiV1.HealthSuspect, drives[0].Health)
+	assert.Len(t, recorder.Calls, 2)
+
+	// no errors in window, counter is reset
+	now = now.Add(2 * time.Hour)
+	kernelLog.On("GetIOErrors").Return(map[string]int{}, nil).Once()
+	drives = getDrives()
+	vm.checkIOErrors(drives)
+	assert.Equal(t, apiV1.HealthGood, drives[0].Health)
+	assert.Empty(t, vm.ioErrorsMonitor.errors)
+}
+
+func TestVolumeManager_checkIOErrors_NVMe(t *testing.T) {
+	var (
+		vm        = prepareSuccessVolumeManager(t)
+		kernelLog = &mocklu.MockWrapKernelLog{}
+	)
+	vm.SetIOErrorsMonitoring(kernelLog, 3, time.Hour)
+
+	d1 := disk1
+	d1.Path, d1.Health = "/dev/nvme0n1", apiV1.HealthGood
+
+	// errors of nvme0n10 and its partitions don't belong to nvme0n1
+	kernelLog.On("GetIOErrors").Return(map[string]int{"nvme0n1p1": 2, "nvme0n10": 5, "nvme0n10p1": 5}, nil).Once()
+	vm.checkIOErrors([]*api.Drive{&d1})
+	assert.Equal(t, apiV1.HealthGood, d1.Health)
+	assert.Equal(t, 2, vm.ioErrorsMonitor.count(d1.SerialNumber, time.Now()))
+}
@@ -0,0 +1,43 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/audit"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+)
+
+// formatOnStage creates FS of lazy formatted volume if device doesn't contain it yet,
+// mkfs of such volumes is skipped during creation and is performed with mkfs of the node during the first stage
+// Receives golang context, Volume CR and path of partition/LV of the volume
+func (m *VolumeManager) formatOnStage(ctx context.Context, volume *volumecrd.Volume, device string) error {
+	fsType, err := m.fsOps.GetFSType(device)
+	if err != nil {
+		return err
+	}
+	if fsType != "" {
+		return nil
+	}
+
+	m.log.WithField("volumeID", volume.Spec.Id).Infof("Creating FS %s on %s", volume.Spec.Type, device)
+	err = m.fsOps.CreateFSIfNotExist(fs.FileSystem(volume.Spec.Type), device)
+	m.auditVolumeOperations(ctx, volume, device, []audit.Operation{audit.MakeFS}, err)
+	return err
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+)
+
+func TestVolumeManager_formatOnStage(t *testing.T) {
+	var (
+		vm     = prepareSuccessVolumeManager(t)
+		fsOps  = &mockProv.MockFsOpts{}
+		device = "/dev/sda1"
+		vol    = volCR.DeepCopy()
+	)
+	vm.fsOps = fsOps
+	vol.Spec.Type = string(fs.XFS)
+	vol.Spec.LazyFormat = true
+	assert.Empty(t, prepareOperations(vol))
+
+	// first stage, FS is created
+	fsOps.On("GetFSType", device).Return("", nil).Once()
+	fsOps.On("CreateFSIfNotExist", fs.XFS, device).Return(nil).Once()
+	assert.Nil(t, vm.formatOnStage(testCtx, vol, device))
+
+	// next stage, device is already formatted
+	fsOps.On("GetFSType", device).Return(string(fs.XFS), nil).Once()
+	assert.Nil(t, vm.formatOnStage(testCtx, vol, device))
+	fsOps.AssertNumberOfCalls(t, "CreateFSIfNotExist", 1)
+
+	// mkfs failed
+	fsOps.On("GetFSType", device).Return("", nil).Once()
+	fsOps.On("CreateFSIfNotExist", fs.XFS, device).Return(errors.New("mkfs error")).Once()
+	assert.NotNil(t, vm.formatOnStage(testCtx, vol, device))
+
+	vol.Spec.Mode = apiV1.ModeRAW
+	assert.Empty(t, prepareOperations(vol))
+}
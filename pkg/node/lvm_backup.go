@@ -0,0 +1,47 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/lvmbackup"
+)
+
+// SetLVMMetadataBackup enables archiving of metadata of volume groups of the node during Discover
+func (m *VolumeManager) SetLVMMetadataBackup(archive *lvmbackup.Archive) {
+	m.lvmBackup = archive
+}
+
+// backupLVMMetadata archives metadata of created volume groups which was changed since previous discovery
+func (m *VolumeManager) backupLVMMetadata(ctx context.Context) error {
+	if m.lvmBackup == nil {
+		return nil
+	}
+	lvgs, err := m.cachedCrHelper.GetLVGCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	vgs := make([]string, 0, len(lvgs))
+	for _, lvg := range lvgs {
+		if lvg.Spec.Status == apiV1.Created {
+			vgs = append(vgs, lvg.Name)
+		}
+	}
+	return m.lvmBackup.Backup(ctx, vgs)
+}
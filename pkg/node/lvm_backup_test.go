@@ -0,0 +1,57 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/lvmbackup"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+func TestVolumeManager_backupLVMMetadata(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	var (
+		lvmOps   = &mocklu.MockWrapLVM{}
+		vm       = NewVolumeManager(nil, nil, testLogger, kubeClient, kubeClient, nil, nodeID, nodeName)
+		created  = testLVGCR.DeepCopy()
+		creating = testLVGCR.DeepCopy()
+	)
+	// disabled
+	assert.Nil(t, vm.backupLVMMetadata(testCtx))
+
+	creating.Name, creating.Spec.Name, creating.Spec.Status = "creating", "creating", apiV1.Creating
+	assert.Nil(t, kubeClient.CreateCR(testCtx, created.Name, created))
+	assert.Nil(t, kubeClient.CreateCR(testCtx, creating.Name, creating))
+	vm.SetLVMMetadataBackup(lvmbackup.NewArchive(kubeClient, lvmOps, nodeName, 1, testLogger))
+
+	lvmOps.On("GetVGSeqNo", created.Name).Return(int64(3), nil)
+	lvmOps.On("VGCfgBackup", created.Name, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		assert.Nil(t, ioutil.WriteFile(args.String(1), []byte("metadata"), 0600))
+	})
+	assert.Nil(t, vm.backupLVMMetadata(testCtx))
+	assert.Nil(t, vm.backupLVMMetadata(testCtx))
+	lvmOps.AssertNumberOfCalls(t, "VGCfgBackup", 1)
+	lvmOps.AssertNotCalled(t, "GetVGSeqNo", creating.Name)
+}
@@ -0,0 +1,264 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dell/csi-baremetal/pkg/base"
+)
+
+// NodeConditionKubeletDirNotShared is True when kubelet root directory isn't mounted into node service
+// with shared propagation, volumes mounted by node service aren't visible for kubelet and pods in this case
+const NodeConditionKubeletDirNotShared corev1.NodeConditionType = "KubeletDirNotShared"
+
+// mountPropagationHint is a remediation hint for wrong propagation of kubelet root directory
+const mountPropagationHint = "set mountPropagation: Bidirectional for kubelet root directory volume " +
+	"in node DaemonSet and make sure that the directory is shared on the host (mount --make-rshared /)"
+
+// knownKubeletRootDirs are kubelet root directories of kubernetes distributions with non-default layout,
+// k3s, RKE2 and OpenShift use default base.KubeletRootDir
+var knownKubeletRootDirs = []string{
+	// microk8s
+	"/var/snap/microk8s/common/var/lib/kubelet",
+	// k0s
+	"/var/lib/k0s/kubelet",
+}
+
+// mountInfoPath is a path to mountinfo of node service process, variable for tests
+var mountInfoPath = "/proc/self/mountinfo"
+
+// mountInfo holds fields of /proc/self/mountinfo entry which are used for propagation check
+type mountInfo struct {
+	MountPoint string
+	// optional fields such as shared:N or master:N
+	Optional []string
+}
+
+// isShared returns true if mount propagates mount events to peer group (shared or rshared mount)
+func (mi *mountInfo) isShared() bool {
+	for _, field := range mi.Optional {
+		if strings.HasPrefix(field, "shared:") {
+			return true
+		}
+	}
+	return false
+}
+
+// SetKubeletRootDir sets root directory of kubelet, which is used for propagation check and system drive detection
+// If dir is empty it is discovered from mount points of node service, default directory is used if discovery fails
+func (m *VolumeManager) SetKubeletRootDir(dir string) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "SetKubeletRootDir",
+	})
+	if dir == "" {
+		mounts, err := readMountInfo(mountInfoPath)
+		if err != nil {
+			ll.Errorf("Unable to read mounts: %v. Use default kubelet root directory %s", err, base.KubeletRootDir)
+			return
+		}
+		if dir = discoverKubeletRootDir(mounts); dir == "" {
+			ll.Warnf("Kubelet root directory isn't found. Use default %s", base.KubeletRootDir)
+			return
+		}
+		ll.Infof("Kubelet root directory %s was discovered", dir)
+	}
+	m.kubeletRootDir = filepath.Clean(dir)
+}
+
+// getKubeletRootDir returns root directory of kubelet
+func (m *VolumeManager) getKubeletRootDir() string {
+	if m.kubeletRootDir == "" {
+		return base.KubeletRootDir
+	}
+	return m.kubeletRootDir
+}
+
+// kubeletPodsPath returns pods' directory of kubelet
+func (m *VolumeManager) kubeletPodsPath() string {
+	return filepath.Join(m.getKubeletRootDir(), "pods")
+}
+
+// VerifyMountPropagation checks that kubelet root directory is mounted into node service with shared propagation
+// and sets KubeletDirNotShared condition of kubernetes Node. Health check of node service fails if verification fails
+// Returns error with remediation hint if propagation is wrong
+func (m *VolumeManager) VerifyMountPropagation(ctx context.Context) error {
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "VerifyMountPropagation",
+	})
+
+	mounts, err := readMountInfo(mountInfoPath)
+	if err == nil {
+		err = checkMountPropagation(mounts, m.getKubeletRootDir())
+	}
+	m.mountPropagationErr = err
+
+	cond := corev1.NodeCondition{
+		Type:    NodeConditionKubeletDirNotShared,
+		Status:  corev1.ConditionFalse,
+		Reason:  "KubeletDirShared",
+		Message: fmt.Sprintf("Kubelet root directory %s is mounted with shared propagation", m.getKubeletRootDir()),
+	}
+	if err != nil {
+		cond.Status = corev1.ConditionTrue
+		cond.Reason = "KubeletDirNotShared"
+		cond.Message = err.Error()
+	}
+	if condErr := m.setKubeletDirCondition(ctx, cond); condErr != nil {
+		ll.Warnf("Unable to set node condition %s: %v", cond.Type, condErr)
+	}
+	return err
+}
+
+// setKubeletDirCondition sets condition in status of kubernetes Node object
+func (m *VolumeManager) setKubeletDirCondition(ctx context.Context, cond corev1.NodeCondition) error {
+	k8sNode := &corev1.Node{}
+	if err := m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: m.nodeName}, k8sNode); err != nil {
+		return err
+	}
+	setNodeCondition(k8sNode, cond)
+	return m.k8sClient.Status().Update(ctx, k8sNode)
+}
+
+// checkMountPropagation finds mount which contains dir and checks that it is shared
+// Returns error with remediation hint if mount isn't found or isn't shared
+func checkMountPropagation(mounts []mountInfo, dir string) error {
+	mount := findMount(mounts, dir)
+	if mount == nil {
+		return fmt.Errorf("mount of kubelet root directory %s isn't found, %s", dir, mountPropagationHint)
+	}
+	if !mount.isShared() {
+		return fmt.Errorf("kubelet root directory %s is mounted to %s without shared propagation (%s), %s",
+			dir, mount.MountPoint, strings.Join(mount.Optional, " "), mountPropagationHint)
+	}
+	return nil
+}
+
+// findMount returns the nearest mount which contains path, nil if there is no such mount
+func findMount(mounts []mountInfo, path string) *mountInfo {
+	var found *mountInfo
+	path = filepath.Clean(path)
+	for i := range mounts {
+		mp := mounts[i].MountPoint
+		if mp != "/" && path != mp && !strings.HasPrefix(path, mp+"/") {
+			continue
+		}
+		// the latest mount over the same mount point overlaps previous ones
+		if found == nil || len(mp) >= len(found.MountPoint) {
+			found = &mounts[i]
+		}
+	}
+	return found
+}
+
+// discoverKubeletRootDir searches kubelet root directory among default one, directories of known distributions
+// and mount points of node service, kubelet root directory contains pods and plugins directories
+// Returns empty string if directory isn't found
+func discoverKubeletRootDir(mounts []mountInfo) string {
+	candidates := append([]string{base.KubeletRootDir}, knownKubeletRootDirs...)
+	for _, mount := range mounts {
+		candidates = append(candidates, mount.MountPoint)
+		// pods directory might be mounted separately
+		if filepath.Base(mount.MountPoint) == "pods" {
+			candidates = append(candidates, filepath.Dir(mount.MountPoint))
+		}
+	}
+	for _, dir := range candidates {
+		if isKubeletRootDir(dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// isKubeletRootDir returns true if dir contains pods and plugins directories
+func isKubeletRootDir(dir string) bool {
+	for _, sub := range []string{"pods", "plugins"} {
+		info, err := os.Stat(filepath.Join(dir, sub))
+		if err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// readMountInfo reads and parses mountinfo file
+func readMountInfo(path string) ([]mountInfo, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return parseMountInfo(file)
+}
+
+// parseMountInfo parses mountinfo in format described in proc(5):
+// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func parseMountInfo(r io.Reader) ([]mountInfo, error) {
+	var mounts []mountInfo
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("wrong format of mountinfo line: %s", scanner.Text())
+		}
+		mount := mountInfo{MountPoint: unescapeMountPath(fields[4])}
+		for _, field := range fields[6:] {
+			if field == "-" {
+				break
+			}
+			mount.Optional = append(mount.Optional, field)
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, scanner.Err()
+}
+
+// unescapeMountPath replaces octal escapes of space, tab, newline and backslash in mount path
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, "\\") {
+		return path
+	}
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if code, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(path[i])
+	}
+	return sb.String()
+}
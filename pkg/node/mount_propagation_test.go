@@ -0,0 +1,122 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dell/csi-baremetal/pkg/base"
+)
+
+const testMountInfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+25 22 0:5 / /dev rw,nosuid - devtmpfs udev rw
+30 22 8:2 /var/lib/kubelet /var/lib/kubelet rw,relatime master:7 - ext4 /dev/sda2 rw
+31 22 8:3 /data/kube\040let /data/kube\040let rw,relatime shared:9 master:9 - ext4 /dev/sda3 rw
+`
+
+func TestParseMountInfo(t *testing.T) {
+	mounts, err := parseMountInfo(strings.NewReader(testMountInfo))
+	assert.Nil(t, err)
+	assert.Len(t, mounts, 4)
+	assert.Equal(t, "/data/kube let", mounts[3].MountPoint)
+	assert.Equal(t, []string{"shared:9", "master:9"}, mounts[3].Optional)
+	assert.Empty(t, mounts[1].Optional)
+
+	_, err = parseMountInfo(strings.NewReader("22 1 8:1 /"))
+	assert.NotNil(t, err)
+}
+
+func TestCheckMountPropagation(t *testing.T) {
+	mounts, err := parseMountInfo(strings.NewReader(testMountInfo))
+	assert.Nil(t, err)
+
+	// slave mount isn't enough
+	err = checkMountPropagation(mounts, "/var/lib/kubelet")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Bidirectional")
+	// subdirectory of shared mount
+	assert.Nil(t, checkMountPropagation(mounts, "/data/kube let/"))
+	// covered by shared root mount
+	assert.Nil(t, checkMountPropagation(mounts, "/opt/kubelet"))
+	// no mounts
+	assert.NotNil(t, checkMountPropagation(nil, "/var/lib/kubelet"))
+}
+
+func TestVolumeManager_VerifyMountPropagation(t *testing.T) {
+	var (
+		vm      = prepareSuccessVolumeManager(t)
+		k8sNode = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		updated = &corev1.Node{}
+		rootDir = t.TempDir()
+	)
+	assert.Nil(t, vm.k8sClient.Create(testCtx, k8sNode))
+	for _, sub := range []string{"pods", "plugins"} {
+		assert.Nil(t, os.Mkdir(filepath.Join(rootDir, sub), 0700))
+	}
+
+	defer func(path string) { mountInfoPath = path }(mountInfoPath)
+	mountInfoPath = filepath.Join(t.TempDir(), "mountinfo")
+	writeMountInfo := func(optional string) {
+		content := fmt.Sprintf("22 1 8:1 / / rw - ext4 /dev/sda1 rw\n30 22 8:2 / %s rw %s - ext4 /dev/sda2 rw\n",
+			rootDir, optional)
+		assert.Nil(t, ioutil.WriteFile(mountInfoPath, []byte(content), 0600))
+	}
+
+	// kubelet root directory is discovered
+	writeMountInfo("master:1")
+	vm.SetKubeletRootDir("")
+	assert.Equal(t, rootDir, vm.getKubeletRootDir())
+	assert.Equal(t, filepath.Join(rootDir, "pods"), vm.kubeletPodsPath())
+
+	// not shared
+	assert.NotNil(t, vm.VerifyMountPropagation(testCtx))
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+	cond := getNodeCondition(updated, NodeConditionKubeletDirNotShared)
+	assert.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+
+	svc := newNodeService()
+	svc.initialized = true
+	svc.mountPropagationErr = vm.mountPropagationErr
+	resp, err := svc.Check(testCtx, &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	// shared
+	writeMountInfo("shared:5")
+	assert.Nil(t, vm.VerifyMountPropagation(testCtx))
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+	cond = getNodeCondition(updated, NodeConditionKubeletDirNotShared)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+
+	// explicit directory
+	vm.SetKubeletRootDir("/opt/kubelet/")
+	assert.Equal(t, "/opt/kubelet", vm.getKubeletRootDir())
+	vm.kubeletRootDir = ""
+	assert.Equal(t, base.KubeletRootDir, vm.getKubeletRootDir())
+}
@@ -0,0 +1,386 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/util/retry"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/nbd"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+const (
+	// MaxNBDExportDuration limits duration of NBD export of volume snapshot
+	MaxNBDExportDuration = 24 * time.Hour
+	// nbdSnapshotSuffix is appended to name of volume LV to get name of exported snapshot LV
+	nbdSnapshotSuffix = "-nbd"
+	// nbdSnapshotSizePercent is a size of space for changes of origin LV which snapshot holds, in percents of volume
+	nbdSnapshotSizePercent = 10
+	// nbdSnapshotMinSize is a min size of space for changes of origin LV which snapshot holds
+	nbdSnapshotMinSize = int64(64 * util.MBYTE)
+)
+
+// nbdExporter holds settings and state of NBD export of volume snapshots
+type nbdExporter struct {
+	server     *nbd.Server
+	openDevice func(path string) (nbd.Device, int64, error)
+	// volume ID -> exported snapshot, accessed by Discover only
+	exports map[string]nbdExport
+}
+
+// nbdExport is a snapshot LV which is exported over NBD
+type nbdExport struct {
+	// path of snapshot LV
+	snapshot string
+	// name of LVG and size in bytes which is reserved in its AvailableCapacity for snapshot
+	lvg      string
+	reserved int64
+}
+
+// SetNBDExport enables read-only export of snapshots of volumes annotated with VolumeAnnotationNBDExport
+// by NBD server during Discover
+func (m *VolumeManager) SetNBDExport(server *nbd.Server) {
+	m.nbdExport = &nbdExporter{
+		server:     server,
+		openDevice: openNBDDevice,
+		exports:    make(map[string]nbdExport),
+	}
+}
+
+// openNBDDevice opens block device read-only
+// Returns opened device and its size in bytes
+func openNBDDevice(path string) (nbd.Device, int64, error) {
+	device, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := device.Seek(0, io.SeekEnd)
+	if err != nil {
+		_ = device.Close()
+		return nil, 0, fmt.Errorf("unable to get size of %s: %w", path, err)
+	}
+	return device, size, nil
+}
+
+// processNBDExports starts export of snapshots of volumes which are annotated for export and stops exports
+// which are expired or aren't requested anymore. Exports which were active before restart of node service
+// are started again from the same snapshots
+func (m *VolumeManager) processNBDExports(ctx context.Context) error {
+	if m.nbdExport == nil {
+		return nil
+	}
+	volumes, err := m.cachedCrHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(volumes))
+	for i := range volumes {
+		found[volumes[i].Spec.Id] = true
+		m.reconcileNBDExport(ctx, volumes[i].DeepCopy())
+	}
+	// volume CR is removed, volume LV and its snapshot are removed by provisioner
+	for volumeID := range m.nbdExport.exports {
+		if !found[volumeID] {
+			m.stopNBDExport(ctx, volumeID, nil)
+		}
+	}
+	return nil
+}
+
+// reconcileNBDExport starts or stops NBD export of snapshot of volume according to its annotations
+func (m *VolumeManager) reconcileNBDExport(ctx context.Context, volume *volumecrd.Volume) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "reconcileNBDExport",
+		"volumeID": volume.Spec.Id,
+	})
+
+	var (
+		e             = m.nbdExport
+		_, exported   = e.exports[volume.Spec.Id]
+		requested, ok = volume.Annotations[apiV1.VolumeAnnotationNBDExport]
+		state         = volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus]
+	)
+	if !ok || !isNBDExportable(volume) {
+		_, reserved := volume.Annotations[apiV1.VolumeAnnotationNBDExportReserved]
+		if exported || state == apiV1.VolumeAnnotationNBDExportActive || reserved {
+			m.stopNBDExport(ctx, volume.Spec.Id, volume)
+			m.recorder.Eventf(volume, eventing.VolumeExportStopped, "NBD export of volume %s is stopped", volume.Name)
+		}
+		if state == "" && !reserved {
+			return
+		}
+		delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportStatus)
+		delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportExpires)
+		delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportError)
+		if err := m.k8sClient.UpdateCR(ctx, volume); err != nil {
+			ll.Errorf("Unable to remove NBD export annotations: %v", err)
+		}
+		return
+	}
+
+	switch state {
+	case apiV1.VolumeAnnotationNBDExportFailed, apiV1.VolumeAnnotationNBDExportExpired:
+		// export isn't retried until annotation is removed
+		return
+	case apiV1.VolumeAnnotationNBDExportActive:
+		expires, err := time.Parse(time.RFC3339, volume.Annotations[apiV1.VolumeAnnotationNBDExportExpires])
+		if err != nil || !time.Now().Before(expires) {
+			m.stopNBDExport(ctx, volume.Spec.Id, volume)
+			ll.Info("NBD export is expired")
+			m.recorder.Eventf(volume, eventing.VolumeExportStopped, "NBD export of volume %s is expired", volume.Name)
+			volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus] = apiV1.VolumeAnnotationNBDExportExpired
+			if err = m.k8sClient.UpdateCR(ctx, volume); err != nil {
+				ll.Errorf("Unable to set NBD export status: %v", err)
+			}
+			return
+		}
+		if exported {
+			return
+		}
+		// node service was restarted, snapshot which was created before restart is exported
+		if err = m.startNBDExport(ctx, volume); err != nil {
+			m.setNBDExportFailed(ctx, volume, err)
+		}
+		return
+	}
+
+	duration, err := time.ParseDuration(requested)
+	if err == nil && (duration <= 0 || duration > MaxNBDExportDuration) {
+		err = fmt.Errorf("duration %s is out of range (0, %s]", duration, MaxNBDExportDuration)
+	}
+	if err == nil {
+		err = m.startNBDExport(ctx, volume)
+	}
+	if err != nil {
+		m.setNBDExportFailed(ctx, volume, err)
+		return
+	}
+
+	expires := time.Now().Add(duration).UTC().Format(time.RFC3339)
+	volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus] = apiV1.VolumeAnnotationNBDExportActive
+	volume.Annotations[apiV1.VolumeAnnotationNBDExportExpires] = expires
+	delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportError)
+	if err = m.k8sClient.UpdateCR(ctx, volume); err != nil {
+		// export is kept, status is set during the next Discover
+		ll.Errorf("Unable to set NBD export status: %v", err)
+		return
+	}
+	ll.Infof("Snapshot of volume is exported over NBD until %s", expires)
+	m.recorder.Eventf(volume, eventing.VolumeExportStarted,
+		"Read-only snapshot of volume %s is exported over NBD as %s until %s", volume.Name, volume.Spec.Id, expires)
+}
+
+// isNBDExportable returns true if volume is in status which allows to export its snapshot
+func isNBDExportable(volume *volumecrd.Volume) bool {
+	switch volume.Spec.CSIStatus {
+	case apiV1.Created, apiV1.VolumeReady, apiV1.Published:
+		return true
+	}
+	return false
+}
+
+// nbdSnapshotPath returns path of snapshot LV of volume which is exported over NBD, empty if path is unknown
+func (m *VolumeManager) nbdSnapshotPath(volume *volumecrd.Volume) string {
+	if !util.IsStorageClassLVG(volume.Spec.StorageClass) {
+		return ""
+	}
+	path, err := m.getProvisionerForVolume(&volume.Spec).GetVolumePath(&volume.Spec)
+	if err != nil {
+		return ""
+	}
+	return path + nbdSnapshotSuffix
+}
+
+// startNBDExport reserves space for read-only snapshot of volume LV in AvailableCapacity of LVG, creates snapshot
+// and exports it over NBD with name of volume ID to clients from VolumeAnnotationNBDExportClients annotation.
+// Reserved size is kept in VolumeAnnotationNBDExportReserved annotation, which is updated by caller.
+// Snapshot isn't created and space isn't reserved again if they exist
+func (m *VolumeManager) startNBDExport(ctx context.Context, volume *volumecrd.Volume) error {
+	e := m.nbdExport
+	if exp, ok := e.exports[volume.Spec.Id]; ok {
+		volume.Annotations[apiV1.VolumeAnnotationNBDExportReserved] = strconv.FormatInt(exp.reserved, 10)
+		return nil
+	}
+	// partitions of drives are used by workloads directly and have no snapshots
+	if !util.IsStorageClassLVG(volume.Spec.StorageClass) {
+		return fmt.Errorf("snapshots aren't supported for volumes of storage class %s, LVG storage class is expected",
+			volume.Spec.StorageClass)
+	}
+	clients := nbdExportClients(volume)
+	if len(clients) == 0 {
+		return fmt.Errorf("annotation %s with names of client certificates is required",
+			apiV1.VolumeAnnotationNBDExportClients)
+	}
+	path, err := m.getProvisionerForVolume(&volume.Spec).GetVolumePath(&volume.Spec)
+	if err != nil {
+		return fmt.Errorf("unable to find LV of volume: %w", err)
+	}
+
+	var (
+		vgName   = filepath.Base(filepath.Dir(path))
+		exp      = nbdExport{snapshot: path + nbdSnapshotSuffix, lvg: volume.Spec.Location}
+		size     = volume.Spec.Size * nbdSnapshotSizePercent / 100
+		reserved = volume.Annotations[apiV1.VolumeAnnotationNBDExportReserved]
+	)
+	if size < nbdSnapshotMinSize {
+		size = nbdSnapshotMinSize
+	}
+	if reserved != "" {
+		// space was reserved before restart of node service
+		if exp.reserved, err = strconv.ParseInt(reserved, 10, 64); err != nil {
+			return fmt.Errorf("wrong value of annotation %s: %w", apiV1.VolumeAnnotationNBDExportReserved, err)
+		}
+	} else {
+		if err = m.changeLVGCapacity(ctx, exp.lvg, -size); err != nil {
+			return fmt.Errorf("unable to reserve space for snapshot of volume: %w", err)
+		}
+		exp.reserved = size
+		volume.Annotations[apiV1.VolumeAnnotationNBDExportReserved] = strconv.FormatInt(size, 10)
+	}
+
+	if err = m.lvmOps.LVCreateReadOnlySnapshot(filepath.Base(exp.snapshot), fmt.Sprintf("%db", size),
+		filepath.Base(path), vgName); err != nil {
+		m.releaseNBDSnapshotSpace(ctx, volume, exp)
+		return fmt.Errorf("unable to create snapshot of volume: %w", err)
+	}
+	device, deviceSize, err := e.openDevice(exp.snapshot)
+	if err == nil {
+		err = e.server.AddExport(volume.Spec.Id, device, deviceSize, clients)
+		if err != nil {
+			_ = device.Close()
+		}
+	}
+	if err != nil {
+		if removeErr := m.lvmOps.LVRemove(exp.snapshot); removeErr != nil {
+			m.log.WithField("method", "startNBDExport").Errorf("Unable to remove snapshot %s: %v",
+				exp.snapshot, removeErr)
+		} else {
+			m.releaseNBDSnapshotSpace(ctx, volume, exp)
+		}
+		return fmt.Errorf("unable to export snapshot of volume: %w", err)
+	}
+	e.exports[volume.Spec.Id] = exp
+	return nil
+}
+
+// nbdExportClients returns names of client certificates which are allowed to read NBD export of volume
+func nbdExportClients(volume *volumecrd.Volume) []string {
+	var clients []string
+	for _, client := range strings.Split(volume.Annotations[apiV1.VolumeAnnotationNBDExportClients], ",") {
+		if client = strings.TrimSpace(client); client != "" {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// stopNBDExport disconnects clients of NBD export of volume, removes its snapshot and releases space
+// which is reserved for snapshot in AvailableCapacity of LVG
+// Receives volume ID and Volume CR which is used if export isn't started by this process, nil if CR is removed
+func (m *VolumeManager) stopNBDExport(ctx context.Context, volumeID string, volume *volumecrd.Volume) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "stopNBDExport",
+		"volumeID": volumeID,
+	})
+
+	e := m.nbdExport
+	exp, ok := e.exports[volumeID]
+	if ok {
+		if err := e.server.RemoveExport(volumeID); err != nil {
+			ll.Errorf("Unable to close snapshot: %v", err)
+		}
+		delete(e.exports, volumeID)
+	} else if volume != nil {
+		exp.snapshot = m.nbdSnapshotPath(volume)
+		exp.lvg = volume.Spec.Location
+		exp.reserved, _ = strconv.ParseInt(volume.Annotations[apiV1.VolumeAnnotationNBDExportReserved], 10, 64)
+	}
+	if exp.snapshot == "" {
+		return
+	}
+	if err := m.lvmOps.LVRemove(exp.snapshot); err != nil {
+		// space is still used by snapshot, so it is kept reserved
+		ll.Errorf("Unable to remove snapshot %s: %v", exp.snapshot, err)
+		return
+	}
+	m.releaseNBDSnapshotSpace(ctx, volume, exp)
+	ll.Infof("NBD export is stopped, snapshot %s is removed", exp.snapshot)
+}
+
+// releaseNBDSnapshotSpace returns space which is reserved for snapshot to AvailableCapacity of LVG and removes
+// VolumeAnnotationNBDExportReserved annotation from volume, if it isn't nil
+func (m *VolumeManager) releaseNBDSnapshotSpace(ctx context.Context, volume *volumecrd.Volume, exp nbdExport) {
+	if exp.reserved > 0 {
+		if err := m.changeLVGCapacity(ctx, exp.lvg, exp.reserved); err != nil {
+			m.log.WithField("method", "releaseNBDSnapshotSpace").Errorf(
+				"Unable to release %d bytes of snapshot %s: %v", exp.reserved, exp.snapshot, err)
+			return
+		}
+	}
+	if volume != nil {
+		delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportReserved)
+	}
+}
+
+// changeLVGCapacity adds delta bytes to AvailableCapacity of LVG
+// Returns error if AvailableCapacity isn't found, has less than -delta bytes or can't be updated
+func (m *VolumeManager) changeLVGCapacity(ctx context.Context, lvg string, delta int64) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ac, err := m.crHelper.GetACByLocation(lvg)
+		if err != nil {
+			return err
+		}
+		if ac.Spec.Size+delta < 0 {
+			return fmt.Errorf("LogicalVolumeGroup %s has %d free bytes, %d bytes are required",
+				lvg, ac.Spec.Size, -delta)
+		}
+		ac.Spec.Size += delta
+		return m.k8sClient.UpdateCR(ctx, ac)
+	})
+}
+
+// setNBDExportFailed sets failed status and error of NBD export in annotations of Volume CR and sends event
+func (m *VolumeManager) setNBDExportFailed(ctx context.Context, volume *volumecrd.Volume, exportErr error) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "setNBDExportFailed",
+		"volumeID": volume.Spec.Id,
+	})
+
+	ll.Errorf("Unable to export snapshot of volume over NBD: %v", exportErr)
+	m.recorder.Eventf(volume, eventing.VolumeExportFailed, "Unable to export snapshot of volume %s over NBD: %v",
+		volume.Name, exportErr)
+	volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus] = apiV1.VolumeAnnotationNBDExportFailed
+	volume.Annotations[apiV1.VolumeAnnotationNBDExportError] = exportErr.Error()
+	delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportExpires)
+	if err := m.k8sClient.UpdateCR(ctx, volume); err != nil {
+		ll.Errorf("Unable to set NBD export status: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	vcrd "github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/nbd"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+// nbdTestDevice is a snapshot which is opened by test
+type nbdTestDevice struct {
+	*bytes.Reader
+}
+
+func (d *nbdTestDevice) Close() error {
+	return nil
+}
+
+func TestVolumeManager_processNBDExports(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		lvmOps   = &mocklu.MockWrapLVM{}
+		server   = nbd.NewServer(nil, testLogger)
+		gib      = int64(util.GBYTE)
+		reserved = int64(16106127360)
+		lvgVol   = testVolumeLVGCR.DeepCopy()
+		driveVol = testVolumeCR2.DeepCopy()
+		volume   *vcrd.Volume
+		ac       = vm.k8sClient.ConstructACCR("ac", api.AvailableCapacity{Location: testLVGCR.Name, NodeId: nodeID,
+			StorageClass: apiV1.StorageClassHDDLVG, Size: 100 * gib})
+		snapshot = "/dev/vg/" + volLVGName + nbdSnapshotSuffix
+		opened   []string
+		events   = func() []*eventing.EventDescription {
+			var result []*eventing.EventDescription
+			for _, c := range recorder.Calls {
+				result = append(result, c.Event)
+			}
+			return result
+		}
+		// decoding into the existing object keeps keys of its annotations map, so volume is read into new object
+		readVolume = func(name string) *vcrd.Volume {
+			current := &vcrd.Volume{}
+			assert.Nil(t, vm.k8sClient.ReadCR(testCtx, name, testNs, current))
+			return current
+		}
+		acSize = func() int64 {
+			current := &accrd.AvailableCapacity{}
+			assert.Nil(t, vm.k8sClient.ReadCR(testCtx, ac.Name, "", current))
+			return current.Spec.Size
+		}
+	)
+	vm.recorder = recorder
+	vm.lvmOps = lvmOps
+	vm.SetProvisioners(map[p.VolumeType]p.Provisioner{
+		p.LVMBasedVolumeType:   mockProv.GetMockProvisionerSuccess("/dev/vg/" + volLVGName),
+		p.DriveBasedVolumeType: mockProv.GetMockProvisionerSuccess("/dev/sda1"),
+	})
+	lvgVol.Spec.CSIStatus = apiV1.Published
+	lvgVol.Annotations = map[string]string{apiV1.VolumeAnnotationNBDExport: "2h",
+		apiV1.VolumeAnnotationNBDExportClients: "backup, audit"}
+	driveVol.Annotations = map[string]string{apiV1.VolumeAnnotationNBDExport: "2h",
+		apiV1.VolumeAnnotationNBDExportClients: "backup"}
+	addVolumeCRs(vm.k8sClient, lvgVol, driveVol)
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, ac.Name, ac))
+
+	// disabled
+	assert.Nil(t, vm.processNBDExports(testCtx))
+
+	vm.SetNBDExport(server)
+	vm.nbdExport.openDevice = func(path string) (nbd.Device, int64, error) {
+		opened = append(opened, path)
+		return &nbdTestDevice{Reader: bytes.NewReader(make([]byte, 4096))}, 4096, nil
+	}
+	lvmOps.On("LVCreateReadOnlySnapshot", volLVGName+nbdSnapshotSuffix, "16106127360b", volLVGName, "vg").
+		Return(nil)
+	lvmOps.On("LVRemove", snapshot).Return(nil)
+
+	// snapshot of LVG volume is exported, drive volume has no snapshots
+	assert.Nil(t, vm.processNBDExports(testCtx))
+	assert.Equal(t, []string{volLVGName}, server.Exports())
+	assert.Equal(t, []string{snapshot}, opened)
+	volume = readVolume(lvgVol.Name)
+	assert.Equal(t, apiV1.VolumeAnnotationNBDExportActive, volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus])
+	expires, err := time.Parse(time.RFC3339, volume.Annotations[apiV1.VolumeAnnotationNBDExportExpires])
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), expires, time.Minute)
+	// space of snapshot is reserved in AC of LVG
+	assert.Equal(t, "16106127360", volume.Annotations[apiV1.VolumeAnnotationNBDExportReserved])
+	assert.Equal(t, 100*gib-reserved, acSize())
+	volume = readVolume(driveVol.Name)
+	assert.Equal(t, apiV1.VolumeAnnotationNBDExportFailed, volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus])
+	assert.NotEmpty(t, volume.Annotations[apiV1.VolumeAnnotationNBDExportError])
+	assert.ElementsMatch(t, []*eventing.EventDescription{eventing.VolumeExportStarted, eventing.VolumeExportFailed},
+		events())
+
+	// export isn't started twice
+	assert.Nil(t, vm.processNBDExports(testCtx))
+	lvmOps.AssertNumberOfCalls(t, "LVCreateReadOnlySnapshot", 1)
+
+	// node service is restarted, snapshot is exported again
+	vm.SetNBDExport(server)
+	assert.Nil(t, server.RemoveExport(volLVGName))
+	opened = nil
+	vm.nbdExport.openDevice = func(path string) (nbd.Device, int64, error) {
+		opened = append(opened, path)
+		return &nbdTestDevice{Reader: bytes.NewReader(make([]byte, 4096))}, 4096, nil
+	}
+	assert.Nil(t, vm.processNBDExports(testCtx))
+	assert.Equal(t, []string{volLVGName}, server.Exports())
+	assert.Equal(t, []string{snapshot}, opened)
+	// space which was reserved before restart is used
+	assert.Equal(t, 100*gib-reserved, acSize())
+
+	// export is expired
+	volume = readVolume(lvgVol.Name)
+	volume.Annotations[apiV1.VolumeAnnotationNBDExportExpires] = time.Now().Add(-time.Minute).Format(time.RFC3339)
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+	assert.Nil(t, vm.processNBDExports(testCtx))
+	assert.Empty(t, server.Exports())
+	lvmOps.AssertCalled(t, "LVRemove", snapshot)
+	volume = readVolume(lvgVol.Name)
+	assert.Equal(t, apiV1.VolumeAnnotationNBDExportExpired, volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus])
+	assert.Empty(t, volume.Annotations[apiV1.VolumeAnnotationNBDExportReserved])
+	assert.Equal(t, 100*gib, acSize())
+	assert.Contains(t, events(), eventing.VolumeExportStopped)
+
+	// annotations are removed when request is removed
+	delete(volume.Annotations, apiV1.VolumeAnnotationNBDExport)
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+	assert.Nil(t, vm.processNBDExports(testCtx))
+	volume = readVolume(lvgVol.Name)
+	assert.Empty(t, volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus])
+	assert.Empty(t, volume.Annotations[apiV1.VolumeAnnotationNBDExportExpires])
+
+	// names of client certificates are required
+	volume.Annotations[apiV1.VolumeAnnotationNBDExport] = "1h"
+	delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportClients)
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+	assert.Nil(t, vm.processNBDExports(testCtx))
+	volume = readVolume(lvgVol.Name)
+	assert.Equal(t, apiV1.VolumeAnnotationNBDExportFailed, volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus])
+	assert.Contains(t, volume.Annotations[apiV1.VolumeAnnotationNBDExportError],
+		apiV1.VolumeAnnotationNBDExportClients)
+	assert.Equal(t, 100*gib, acSize())
+
+	// LVG has not enough free space for snapshot
+	delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportStatus)
+	volume.Annotations[apiV1.VolumeAnnotationNBDExportClients] = "backup"
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, ac.Name, "", ac))
+	ac.Spec.Size = gib
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, ac))
+	assert.Nil(t, vm.processNBDExports(testCtx))
+	volume = readVolume(lvgVol.Name)
+	assert.Equal(t, apiV1.VolumeAnnotationNBDExportFailed, volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus])
+	assert.Equal(t, gib, acSize())
+	ac.Spec.Size = 100 * gib
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, ac))
+
+	// duration is out of range
+	delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportStatus)
+	volume.Annotations[apiV1.VolumeAnnotationNBDExport] = "48h"
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+	assert.Nil(t, vm.processNBDExports(testCtx))
+	volume = readVolume(lvgVol.Name)
+	assert.Equal(t, apiV1.VolumeAnnotationNBDExportFailed, volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus])
+	lvmOps.AssertNumberOfCalls(t, "LVCreateReadOnlySnapshot", 2)
+
+	// snapshot can't be opened, it is removed and reserved space is released
+	delete(volume.Annotations, apiV1.VolumeAnnotationNBDExportStatus)
+	volume.Annotations[apiV1.VolumeAnnotationNBDExport] = "1h"
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+	vm.nbdExport.openDevice = func(path string) (nbd.Device, int64, error) {
+		return nil, 0, errors.New("error")
+	}
+	assert.Nil(t, vm.processNBDExports(testCtx))
+	assert.Empty(t, server.Exports())
+	volume = readVolume(lvgVol.Name)
+	assert.Equal(t, apiV1.VolumeAnnotationNBDExportFailed, volume.Annotations[apiV1.VolumeAnnotationNBDExportStatus])
+	assert.Empty(t, volume.Annotations[apiV1.VolumeAnnotationNBDExportReserved])
+	assert.Equal(t, 100*gib, acSize())
+	lvmOps.AssertNumberOfCalls(t, "LVRemove", 2)
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"strconv"
 	"strings"
@@ -40,9 +41,13 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/cache"
 	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	baseerr "github.com/dell/csi-baremetal/pkg/base/error"
 	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
+	"github.com/dell/csi-baremetal/pkg/base/hooks"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/cryptsetup"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	"github.com/dell/csi-baremetal/pkg/common"
 	"github.com/dell/csi-baremetal/pkg/controller"
@@ -59,6 +64,8 @@ const (
 
 	wbtChangedVolumeAnnotation = "wbt-changed"
 	wbtChangedVolumeKey        = "yes"
+
+	readOnlyMountOption = "ro"
 )
 
 // CSINodeService is the implementation of NodeServer interface from GO CSI specification.
@@ -101,7 +108,7 @@ func NewCSINodeService(client api.DriveServiceClient,
 		VolumeManager:  *NewVolumeManager(client, e, logger, k8sClient, k8sCache, recorder, nodeID, nodeName),
 		svc:            common.NewVolumeOperationsImpl(k8sClient, logger, cache.NewMemCache(), featureConf),
 		IdentityServer: controller.NewIdentityServer(base.PluginName, base.PluginVersion),
-		volMu:          keymutex.NewHashed(0),
+		volMu:          diagnostics.NewKeyMutex("node-service-volumes", keymutex.NewHashed(0)),
 		livenessCheck:  NewLivenessCheckHelper(logger, nil, nil),
 	}
 	s.log = logger.WithField("component", "CSINodeService")
@@ -150,7 +157,9 @@ func (s *CSINodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 		"volumeID": req.GetVolumeId(),
 	})
 
-	ll.Infof("locking volume on request: %v", req)
+	// request isn't logged as is, it holds secrets with encryption key of the volume
+	ll.Infof("locking volume on request: staging path %s, volume capability %v",
+		req.GetStagingTargetPath(), req.GetVolumeCapability())
 	s.volMu.LockKey(req.GetVolumeId())
 	defer func() {
 		err := s.volMu.UnlockKey(req.GetVolumeId())
@@ -181,6 +190,32 @@ func (s *CSINodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 		return nil, status.Error(codes.NotFound, message)
 	}
 
+	if volumeCR.Spec.CSIStatus == apiV1.Creating &&
+		volumeCR.Annotations[apiV1.VolumeAnnotationFormat] == apiV1.VolumeAnnotationFormatInProgress {
+		volumeCR = s.waitForFormatting(ctx, volumeCR)
+	}
+	switch volumeCR.Annotations[apiV1.VolumeAnnotationFormat] {
+	case apiV1.VolumeAnnotationFormatInProgress:
+		return nil, status.Error(codes.Unavailable, "volume is being formatted")
+	case apiV1.VolumeAnnotationFormatFailed:
+		return nil, status.Errorf(codes.Internal, "volume formatting failed: %s",
+			volumeCR.Annotations[apiV1.VolumeAnnotationFormatError])
+	}
+
+	if s.partitionAlignment != nil {
+		if !s.partitionAlignment.acquire(volumeID, "stage") {
+			return nil, status.Error(codes.Unavailable, "partition of volume is being realigned")
+		}
+		defer s.partitionAlignment.release(volumeID)
+		// realignment might be finished after volume was read
+		if volumeCR, err = s.crHelper.GetVolumeByID(volumeID); err != nil {
+			return nil, status.Errorf(codes.NotFound, "Unable to find volume with ID %s", volumeID)
+		}
+	}
+	if err = checkAlignmentStatus(volumeCR); err != nil {
+		return nil, err
+	}
+
 	currStatus := volumeCR.Spec.CSIStatus
 	switch currStatus {
 	// expected currStatus in [Created (first call), VolumeReady (retry), Published (multiple pods)]
@@ -224,10 +259,24 @@ func (s *CSINodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 		ll.Errorf("failed to get partition for volume %v: %v", volumeCR.Spec, err)
 		ignoreErrorIfFakeAttach(err)
 	} else {
-		ll.Infof("Partition to stage: %s", partition)
-		if err := s.fsOps.PrepareAndPerformMount(partition, targetPath, true, false); err != nil {
-			ll.Errorf("Unable to stage volume: %v", err)
+		if volumeCR.Spec.Encryption != "" {
+			partition, err = s.openEncryptedVolume(&volumeCR.Spec, partition, req.GetSecrets())
+		} else if volumeCR.Spec.LazyFormat && volumeCR.Spec.Mode == apiV1.ModeFS {
+			err = s.formatOnStage(ctx, volumeCR, partition)
+		}
+		if err != nil {
+			ll.Errorf("Unable to prepare device of volume: %v", err)
 			ignoreErrorIfFakeAttach(err)
+		} else if err = s.runHook(ctx, hooks.PreMount, volumeCR, partition, targetPath); err != nil {
+			ll.Errorf("Pre-mount hook failed: %v", err)
+			newStatus = apiV1.Failed
+			resp, errToReturn = nil, status.Errorf(codes.Internal, "failed to stage volume: %v", err)
+		} else {
+			ll.Infof("Partition to stage: %s", partition)
+			if err := s.fsOps.PrepareAndPerformMount(partition, targetPath, true, false); err != nil {
+				ll.Errorf("Unable to stage volume: %v", err)
+				ignoreErrorIfFakeAttach(err)
+			}
 		}
 	}
 
@@ -255,6 +304,12 @@ func (s *CSINodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 		}
 	}
 
+	if newStatus == apiV1.VolumeReady {
+		if err := s.labelPV(ctx, volumeCR); err != nil {
+			ll.Errorf("Unable to label PersistentVolume with drive attributes: %v", err)
+		}
+	}
+
 	if currStatus != apiV1.VolumeReady {
 		volumeCR.Spec.CSIStatus = newStatus
 		if err := s.k8sClient.UpdateCR(ctx, volumeCR); err != nil {
@@ -324,10 +379,24 @@ func (s *CSINodeService) NodeUnstageVolume(ctx context.Context, req *csi.NodeUns
 
 	if volumeCR.Annotations[fakeAttachVolumeAnnotation] != fakeAttachVolumeKey {
 		targetPath := getStagingPath(ll, req.GetStagingTargetPath())
-		errToReturn = s.fsOps.UnmountWithCheck(targetPath)
+		var lazyUnmounted bool
+		lazyUnmounted, errToReturn = s.unmountStagingPath(targetPath)
 		if errToReturn == nil {
 			errToReturn = s.fsOps.RmDir(targetPath)
 		}
+		if lazyUnmounted {
+			s.markLazyUnmounted(ctx, volumeCR)
+		}
+		if errToReturn == nil && volumeCR.Spec.Encryption != "" {
+			if err := s.closeEncryptedVolume(&volumeCR.Spec); err != nil {
+				if !lazyUnmounted {
+					errToReturn = err
+				} else {
+					// LUKS device is held by lazily unmounted file system until the node is rebooted
+					ll.Warnf("Unable to close encrypted volume after lazy unmount: %v", err)
+				}
+			}
+		}
 
 		if errToReturn != nil {
 			volumeCR.Spec.CSIStatus = apiV1.Failed
@@ -364,7 +433,9 @@ func (s *CSINodeService) NodePublishVolume(ctx context.Context, req *csi.NodePub
 		"volumeID": req.GetVolumeId(),
 	})
 
-	ll.Infof("locking volume on request: %v", req)
+	// request isn't logged as is, it holds secrets of the volume
+	ll.Infof("locking volume on request: staging path %s, target path %s, readonly %t, volume capability %v",
+		req.GetStagingTargetPath(), req.GetTargetPath(), req.GetReadonly(), req.GetVolumeCapability())
 	s.volMu.LockKey(req.GetVolumeId())
 	defer func() {
 		err := s.volMu.UnlockKey(req.GetVolumeId())
@@ -394,6 +465,14 @@ func (s *CSINodeService) NodePublishVolume(ctx context.Context, req *csi.NodePub
 
 	if accessType, ok := req.GetVolumeCapability().AccessType.(*csi.VolumeCapability_Mount); ok {
 		mountOptions = mountoptions.FilterWithType(mountoptions.PublishCmdOpt, accessType.Mount.GetMountFlags())
+		if provider, err := fs.GetProvider(fs.FileSystem(strings.ToLower(accessType.Mount.GetFsType()))); err == nil {
+			mountOptions = append(provider.MountOptions(), mountOptions...)
+		}
+		// volume with single node read only access mode is published as read-only file system
+		if req.GetReadonly() ||
+			req.GetVolumeCapability().GetAccessMode().GetMode() == csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY {
+			mountOptions = append(mountOptions, readOnlyMountOption)
+		}
 	}
 
 	if req.GetVolumeContext() != nil {
@@ -475,6 +554,13 @@ func (s *CSINodeService) NodePublishVolume(ctx context.Context, req *csi.NodePub
 		owners = append(owners, podName)
 		volumeCR.Spec.Owners = owners
 	}
+	// volume in block mode might be published to several targets, e.g. disk of KubeVirt VM is hot-plugged
+	// into attachment pod, they are tracked to keep volume published until the last target is unpublished
+	if errToReturn == nil {
+		if err = addPublishTarget(volumeCR, dstPath, podName); err != nil {
+			ll.Warnf("Unable to record publish target: %v", err)
+		}
+	}
 
 	ctxWithID := context.WithValue(context.Background(), base.RequestUUID, volumeID)
 	volumeCR.Spec.CSIStatus = newStatus
@@ -604,7 +690,12 @@ func (s *CSINodeService) NodeUnpublishVolume(ctx context.Context, req *csi.NodeU
 		return nil, status.Error(codes.Internal, "unmount error")
 	}
 
-	volumeCR.Spec.Owners = nil
+	targetsLeft, err := removePublishTarget(volumeCR, req.GetTargetPath())
+	if err != nil {
+		ll.Warnf("Unable to remove publish target: %v", err)
+		targetsLeft = 0
+		volumeCR.Spec.Owners = nil
+	}
 	// k8s doesn't call DeleteVolume for inline volumes, so we perform DeleteVolume operation in Unpublish request
 	if volumeCR.Spec.Ephemeral {
 		s.reqMu.Lock()
@@ -629,8 +720,12 @@ func (s *CSINodeService) NodeUnpublishVolume(ctx context.Context, req *csi.NodeU
 		s.reqMu.Unlock()
 	} else {
 		volumeCR.Spec.CSIStatus = apiV1.VolumeReady
+		if targetsLeft > 0 {
+			ll.Infof("Volume is still published to %d target(s)", targetsLeft)
+			volumeCR.Spec.CSIStatus = apiV1.Published
+		}
 		if updateErr := s.k8sClient.UpdateCR(ctxWithID, volumeCR); updateErr != nil {
-			ll.Errorf("Unable to set volume CR status to VolumeReady: %v", updateErr)
+			ll.Errorf("Unable to set volume CR status to %s: %v", volumeCR.Spec.CSIStatus, updateErr)
 			return nil, status.Error(codes.Internal, updateErr.Error())
 		}
 	}
@@ -639,30 +734,170 @@ func (s *CSINodeService) NodeUnpublishVolume(ctx context.Context, req *csi.NodeU
 	return &csi.NodeUnpublishVolumeResponse{}, nil
 }
 
-// NodeGetVolumeStats returns empty response
-func (s *CSINodeService) NodeGetVolumeStats(_ context.Context, _ *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
-	return &csi.NodeGetVolumeStatsResponse{}, nil
+// NodeGetVolumeStats is the implementation of CSI Spec NodeGetVolumeStats. Reports used, available and total
+// bytes and inodes of file system of volume in mount mode and size of volume in block mode.
+// Receives golang context and CSI Spec NodeGetVolumeStatsRequest
+// Returns CSI Spec NodeGetVolumeStatsResponse or error if something went wrong
+func (s *CSINodeService) NodeGetVolumeStats(_ context.Context, req *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
+	ll := s.log.WithFields(logrus.Fields{
+		"method":   "NodeGetVolumeStats",
+		"volumeID": req.GetVolumeId(),
+	})
+
+	// Check arguments
+	if len(req.GetVolumeId()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID missing in request")
+	}
+	if len(req.GetVolumePath()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume Path missing in request")
+	}
+
+	volumeCR, err := s.crHelper.GetVolumeByID(req.GetVolumeId())
+	if err != nil {
+		message := fmt.Sprintf("Unable to find volume with ID %s", req.GetVolumeId())
+		ll.Error(message)
+		return nil, status.Error(codes.NotFound, message)
+	}
+	if _, err := os.Stat(req.GetVolumePath()); err != nil {
+		if os.IsNotExist(err) {
+			return nil, status.Errorf(codes.NotFound, "volume path %s doesn't exist", req.GetVolumePath())
+		}
+		return nil, status.Errorf(codes.Internal, "unable to check volume path %s: %v", req.GetVolumePath(), err)
+	}
+
+	usage, err := s.getVolumeUsage(volumeCR, req.GetVolumePath())
+	if err != nil {
+		ll.Errorf("Unable to get usage of volume: %v", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &csi.NodeGetVolumeStatsResponse{Usage: usage}, nil
+}
+
+// NodeExpandVolume is the implementation of CSI Spec NodeExpandVolume. Performs after ControllerExpandVolume
+// when volume is staged. LV of the volume is already extended, so this method grows LUKS device of encrypted volume
+// with key from secrets and file system of volume in mount mode. Nothing is done on device for volume in block mode.
+// Receives golang context and CSI Spec NodeExpandVolumeRequest
+// Returns CSI Spec NodeExpandVolumeResponse or error if something went wrong
+func (s *CSINodeService) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
+	ll := s.log.WithFields(logrus.Fields{
+		"method":   "NodeExpandVolume",
+		"volumeID": req.GetVolumeId(),
+	})
+
+	ll.Infof("locking volume on request: volume path %s, staging path %s, capacity %v",
+		req.GetVolumePath(), req.GetStagingTargetPath(), req.GetCapacityRange())
+	s.volMu.LockKey(req.GetVolumeId())
+	defer func() {
+		err := s.volMu.UnlockKey(req.GetVolumeId())
+		if err != nil {
+			ll.Warnf("Unlocking volume with error %s", err)
+		}
+	}()
+	if err := s.checkRequestContext(ctx, ll); err != nil {
+		return nil, err
+	}
+
+	// Check arguments
+	if len(req.GetVolumeId()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID missing in request")
+	}
+	if len(req.GetVolumePath()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume Path missing in request")
+	}
+
+	volumeID := req.GetVolumeId()
+	volumeCR, err := s.crHelper.GetVolumeByID(volumeID)
+	if err != nil {
+		message := fmt.Sprintf("Unable to find volume with ID %s", volumeID)
+		ll.Error(message)
+		return nil, status.Error(codes.NotFound, message)
+	}
+
+	currStatus := volumeCR.Spec.CSIStatus
+	if currStatus != apiV1.VolumeReady && currStatus != apiV1.Published {
+		msg := fmt.Sprintf("current volume CR status - %s, expected to be in [%s, %s]",
+			currStatus, apiV1.VolumeReady, apiV1.Published)
+		ll.Error(msg)
+		return nil, status.Error(codes.FailedPrecondition, msg)
+	}
+	if required := req.GetCapacityRange().GetRequiredBytes(); required > volumeCR.Spec.Size {
+		return nil, status.Errorf(codes.OutOfRange, "required capacity %d is greater than volume size %d",
+			required, volumeCR.Spec.Size)
+	}
+
+	resp := &csi.NodeExpandVolumeResponse{CapacityBytes: volumeCR.Spec.Size}
+	if volumeCR.Annotations[fakeAttachVolumeAnnotation] == fakeAttachVolumeKey {
+		ll.Warn("Volume is fake-attached, skip expansion")
+		return resp, nil
+	}
+
+	// staging path is provided by CO which supports STAGE_UNSTAGE_VOLUME, volume must be staged there
+	if len(req.GetStagingTargetPath()) != 0 {
+		mounted, err := s.fsOps.IsMounted(getStagingPath(ll, req.GetStagingTargetPath()))
+		if err != nil {
+			ll.Errorf("Unable to check staging path: %v", err)
+			return nil, status.Errorf(codes.Internal, "unable to check staging path: %v", err)
+		}
+		if !mounted {
+			return nil, status.Errorf(codes.FailedPrecondition, "volume isn't staged to %s", req.GetStagingTargetPath())
+		}
+	}
+
+	if err = s.expandVolumeOnNode(&volumeCR.Spec, req); err != nil {
+		ll.Errorf("Unable to expand volume: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to expand volume: %v", err)
+	}
+
+	ll.Infof("Volume is expanded to %d bytes", resp.CapacityBytes)
+	return resp, nil
+}
+
+// expandVolumeOnNode grows LUKS device and file system of the volume up to size of extended LV
+func (s *CSINodeService) expandVolumeOnNode(vol *api.Volume, req *csi.NodeExpandVolumeRequest) error {
+	device, err := s.getProvisionerForVolume(vol).GetVolumePath(vol)
+	if err != nil {
+		return err
+	}
+	if vol.Encryption != "" {
+		if err = s.cryptOps.Resize(vol.Id, req.GetSecrets()[apiV1.EncryptionKeySecretKey]); err != nil {
+			return err
+		}
+		device = cryptsetup.MapperPath(vol.Id)
+	}
+
+	isBlock := vol.Mode != apiV1.ModeFS
+	if req.GetVolumeCapability() != nil {
+		isBlock = req.GetVolumeCapability().GetBlock() != nil
+	}
+	if isBlock {
+		return nil
+	}
+	return s.fsOps.ResizeFS(fs.FileSystem(vol.Type), device, req.GetVolumePath())
 }
 
-// NodeExpandVolume returns empty response
-func (s *CSINodeService) NodeExpandVolume(_ context.Context, _ *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
-	return &csi.NodeExpandVolumeResponse{}, nil
+// nodeCapabilities contains RPC capabilities of node service, each of them must be implemented
+var nodeCapabilities = []csi.NodeServiceCapability_RPC_Type{
+	csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+	csi.NodeServiceCapability_RPC_EXPAND_VOLUME,
+	csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
 }
 
 // NodeGetCapabilities is the implementation of CSI Spec NodeGetCapabilities.
-// Provides Node capabilities of CSI driver to k8s. STAGE/UNSTAGE Volume for now.
+// Provides Node capabilities of CSI driver to k8s. STAGE/UNSTAGE, EXPAND Volume and GET_VOLUME_STATS for now.
 // Receives golang context and CSI Spec NodeGetCapabilitiesRequest
 // Returns CSI Spec NodeGetCapabilitiesResponse and nil error
 func (s *CSINodeService) NodeGetCapabilities(_ context.Context, _ *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
-	return &csi.NodeGetCapabilitiesResponse{Capabilities: []*csi.NodeServiceCapability{
-		{
+	caps := make([]*csi.NodeServiceCapability, 0, len(nodeCapabilities))
+	for _, c := range nodeCapabilities {
+		caps = append(caps, &csi.NodeServiceCapability{
 			Type: &csi.NodeServiceCapability_Rpc{
 				Rpc: &csi.NodeServiceCapability_RPC{
-					Type: csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+					Type: c,
 				},
 			},
-		}},
-	}, nil
+		})
+	}
+	return &csi.NodeGetCapabilitiesResponse{Capabilities: caps}, nil
 }
 
 // NodeGetInfo is the implementation of CSI Spec NodeGetInfo. It plays a role in CSI Topology feature when Controller
@@ -700,6 +935,14 @@ func (s *CSINodeService) Check(ctx context.Context, req *grpc_health_v1.HealthCh
 		ll.Info("Node svc is not ready yet")
 		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
 	}
+	if s.mountPropagationErr != nil {
+		ll.Errorf("Mount propagation is wrong: %v", s.mountPropagationErr)
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	if s.operations.IsDraining() {
+		ll.Info("Node svc is shutting down")
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	}
 
 	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
 }
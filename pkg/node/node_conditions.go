@@ -0,0 +1,148 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// Node conditions in format of node-problem-detector, they are set in status of kubernetes Node object
+const (
+	// NodeConditionDriveFailure is True when one or more drives on the node have BAD health
+	NodeConditionDriveFailure corev1.NodeConditionType = "DriveFailure"
+	// NodeConditionDiskPressureOnDataDrives is True when free capacity of data drives is lower than threshold
+	NodeConditionDiskPressureOnDataDrives corev1.NodeConditionType = "DiskPressureOnDataDrives"
+)
+
+// nodeConditionsReporter holds settings for node conditions reporting
+type nodeConditionsReporter struct {
+	// minimal free capacity of data drives in percents
+	diskPressureThreshold int64
+}
+
+// SetNodeConditionsReporting enables reporting of node-problem-detector compatible conditions during Discover
+// Receives minimal free capacity of data drives in percents
+func (m *VolumeManager) SetNodeConditionsReporting(diskPressureThreshold int64) {
+	m.conditionsReporter = &nodeConditionsReporter{diskPressureThreshold: diskPressureThreshold}
+}
+
+// reportNodeConditions calculates DriveFailure and DiskPressureOnDataDrives conditions based on Drive and
+// AvailableCapacity CRs and sets them in status of kubernetes Node object. Sends event when condition becomes True
+func (m *VolumeManager) reportNodeConditions(ctx context.Context) error {
+	if m.conditionsReporter == nil {
+		return nil
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "reportNodeConditions",
+	})
+
+	drives, err := m.cachedCrHelper.GetDriveCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	acs, err := m.cachedCrHelper.GetACCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		badDrives         []string
+		total, free       int64
+		driveFailure      = corev1.NodeCondition{Type: NodeConditionDriveFailure, Status: corev1.ConditionFalse}
+		diskPressure      = corev1.NodeCondition{Type: NodeConditionDiskPressureOnDataDrives, Status: corev1.ConditionFalse}
+		diskPressureLimit = m.conditionsReporter.diskPressureThreshold
+	)
+	for _, d := range drives {
+		if d.Spec.Health == apiV1.HealthBad && d.Spec.Usage != apiV1.DriveUsageRemoved {
+			badDrives = append(badDrives, d.Spec.SerialNumber)
+		}
+		if !d.Spec.IsSystem && d.Spec.Status == apiV1.DriveStatusOnline {
+			total += d.Spec.Size
+		}
+	}
+	for _, ac := range acs {
+		if ac.Spec.StorageClass != apiV1.StorageClassSystemLVG {
+			free += ac.Spec.Size
+		}
+	}
+
+	if len(badDrives) > 0 {
+		driveFailure.Status = corev1.ConditionTrue
+		driveFailure.Reason = "DriveHealthBad"
+		driveFailure.Message = fmt.Sprintf("Drives with BAD health: %s", strings.Join(badDrives, ", "))
+	} else {
+		driveFailure.Reason = "DrivesHealthy"
+		driveFailure.Message = "All drives have acceptable health"
+	}
+	if total > 0 && free*100 < total*diskPressureLimit {
+		diskPressure.Status = corev1.ConditionTrue
+		diskPressure.Reason = "LowFreeCapacity"
+		diskPressure.Message = fmt.Sprintf("Free capacity of data drives is %d%%, threshold %d%%",
+			free*100/total, diskPressureLimit)
+	} else {
+		diskPressure.Reason = "EnoughFreeCapacity"
+		diskPressure.Message = fmt.Sprintf("Free capacity of data drives is above %d%%", diskPressureLimit)
+	}
+
+	k8sNode := &corev1.Node{}
+	if err = m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: m.nodeName}, k8sNode); err != nil {
+		return err
+	}
+	for _, cond := range []corev1.NodeCondition{driveFailure, diskPressure} {
+		if !setNodeCondition(k8sNode, cond) {
+			continue
+		}
+		ll.Warnf("Node condition %s is True: %s", cond.Type, cond.Message)
+		switch cond.Type {
+		case NodeConditionDriveFailure:
+			m.recorder.Eventf(k8sNode, eventing.NodeDriveFailure, "%s", cond.Message)
+		case NodeConditionDiskPressureOnDataDrives:
+			m.recorder.Eventf(k8sNode, eventing.NodeDiskPressureOnDataDrives, "%s", cond.Message)
+		}
+	}
+	return m.k8sClient.Status().Update(ctx, k8sNode)
+}
+
+// setNodeCondition adds or replaces condition in Node status, keeps transition time if status isn't changed
+// Returns true if condition status became True
+func setNodeCondition(k8sNode *corev1.Node, cond corev1.NodeCondition) bool {
+	now := metav1.Now()
+	cond.LastHeartbeatTime = now
+	cond.LastTransitionTime = now
+	for i, existing := range k8sNode.Status.Conditions {
+		if existing.Type != cond.Type {
+			continue
+		}
+		if existing.Status == cond.Status {
+			cond.LastTransitionTime = existing.LastTransitionTime
+		}
+		k8sNode.Status.Conditions[i] = cond
+		return cond.Status == corev1.ConditionTrue && existing.Status != corev1.ConditionTrue
+	}
+	k8sNode.Status.Conditions = append(k8sNode.Status.Conditions, cond)
+	return cond.Status == corev1.ConditionTrue
+}
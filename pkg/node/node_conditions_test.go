@@ -0,0 +1,99 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func getNodeCondition(k8sNode *corev1.Node, condType corev1.NodeConditionType) *corev1.NodeCondition {
+	for i := range k8sNode.Status.Conditions {
+		if k8sNode.Status.Conditions[i].Type == condType {
+			return &k8sNode.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestVolumeManager_reportNodeConditions(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		k8sNode  = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		updated  = &corev1.Node{}
+	)
+	vm.recorder = recorder
+
+	// disabled
+	assert.Nil(t, vm.reportNodeConditions(testCtx))
+
+	vm.SetNodeConditionsReporting(10)
+	assert.Nil(t, vm.k8sClient.Create(testCtx, k8sNode))
+
+	good, bad := drive1, drive2
+	good.IsSystem, bad.IsSystem = false, false
+	bad.Health = apiV1.HealthBad
+	goodCR := vm.k8sClient.ConstructDriveCR(good.UUID, good)
+	addDriveCRs(vm.k8sClient, goodCR, vm.k8sClient.ConstructDriveCR(bad.UUID, bad))
+	ac := vm.k8sClient.ConstructACCR("ac", api.AvailableCapacity{
+		Location: good.UUID, NodeId: nodeID, StorageClass: apiV1.StorageClassHDD, Size: good.Size})
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, ac.Name, ac))
+
+	assert.Nil(t, vm.reportNodeConditions(testCtx))
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+	driveFailure := getNodeCondition(updated, NodeConditionDriveFailure)
+	assert.NotNil(t, driveFailure)
+	assert.Equal(t, corev1.ConditionTrue, driveFailure.Status)
+	assert.Contains(t, driveFailure.Message, bad.SerialNumber)
+	diskPressure := getNodeCondition(updated, NodeConditionDiskPressureOnDataDrives)
+	assert.NotNil(t, diskPressure)
+	assert.Equal(t, corev1.ConditionFalse, diskPressure.Status)
+	assert.Len(t, recorder.Calls, 1)
+	assert.Equal(t, eventing.NodeDriveFailure, recorder.Calls[0].Event)
+
+	// capacity is consumed, drive failure is still reported - event is sent only for disk pressure
+	ac.Spec.Size = 0
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, ac))
+	assert.Nil(t, vm.reportNodeConditions(testCtx))
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+	assert.Equal(t, corev1.ConditionTrue, getNodeCondition(updated, NodeConditionDiskPressureOnDataDrives).Status)
+	assert.Equal(t, driveFailure.LastTransitionTime.Unix(),
+		getNodeCondition(updated, NodeConditionDriveFailure).LastTransitionTime.Unix())
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.NodeDiskPressureOnDataDrives, recorder.Calls[1].Event)
+
+	// failed drive is removed
+	removed := &drivecrd.Drive{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, bad.UUID, "", removed))
+	removed.Spec.Usage = apiV1.DriveUsageRemoved
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, removed))
+	assert.Nil(t, vm.reportNodeConditions(testCtx))
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+	assert.Equal(t, corev1.ConditionFalse, getNodeCondition(updated, NodeConditionDriveFailure).Status)
+	assert.Len(t, recorder.Calls, 2)
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	baseerr "github.com/dell/csi-baremetal/pkg/base/error"
+	"os"
 	"path"
 	"testing"
 	"time"
@@ -40,6 +41,8 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/featureconfig"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/cryptsetup"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	csibmnodeconst "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
 	"github.com/dell/csi-baremetal/pkg/mocks"
@@ -112,6 +115,9 @@ var _ = Describe("CSINodeService NodePublish()", func() {
 			err = node.k8sClient.ReadCR(testCtx, testV1ID, "", volumeCR)
 			Expect(err).To(BeNil())
 			Expect(len(volumeCR.Spec.Owners)).To(Equal(1))
+			targets, err := getPublishTargets(volumeCR)
+			Expect(err).To(BeNil())
+			Expect(targets).To(Equal(map[string]string{targetPath: testPodName}))
 		})
 	})
 
@@ -362,24 +368,29 @@ var _ = Describe("CSINodeService NodeUnPublish()", func() {
 			Expect(volumeCR.Spec.CSIStatus).To(Equal(apiV1.VolumeReady))
 			Expect(volumeCR.Spec.Owners).To(BeNil())
 		})
-		//It("Should unpublish volume and don't change volume CR status", func() {
-		//	req := getNodeUnpublishRequest(testV1ID, targetPath)
-		//	vol1 := testVolumeCR1
-		//	vol1.Spec.Owners = []string{"pod-1", "pod-2"}
-		//	vol1.Spec.CSIStatus = apiV1.Published
-		//	err := node.k8sClient.UpdateCR(testCtx, &vol1)
-		//	Expect(err).To(BeNil())
-		//	fsOps.On("UnmountWithCheck", req.GetTargetPath()).Return(nil)
-		//
-		//	resp, err := node.NodeUnpublishVolume(testCtx, req)
-		//	Expect(resp).NotTo(BeNil())
-		//	Expect(err).To(BeNil())
-		//	// check volume CR status
-		//	volumeCR := &vcrd.Volume{}
-		//	err = node.k8sClient.ReadCR(testCtx, testV1ID, volumeCR)
-		//	Expect(err).To(BeNil())
-		//	Expect(volumeCR.Spec.CSIStatus).To(Equal(apiV1.Published))
-		//})
+		It("Should unpublish volume and don't change volume CR status", func() {
+			req := getNodeUnpublishRequest(testV1ID, targetPath)
+			vol1 := &vcrd.Volume{}
+			err := node.k8sClient.ReadCR(testCtx, testV1ID, "", vol1)
+			Expect(err).To(BeNil())
+			vol1.Spec.Owners = []string{"pod-1", "pod-2"}
+			vol1.Spec.CSIStatus = apiV1.Published
+			Expect(addPublishTarget(vol1, targetPath, "pod-1")).To(BeNil())
+			Expect(addPublishTarget(vol1, targetPath+"-hotplug", "pod-2")).To(BeNil())
+			err = node.k8sClient.UpdateCR(testCtx, vol1)
+			Expect(err).To(BeNil())
+			fsOps.On("UnmountWithCheck", req.GetTargetPath()).Return(nil)
+
+			resp, err := node.NodeUnpublishVolume(testCtx, req)
+			Expect(resp).NotTo(BeNil())
+			Expect(err).To(BeNil())
+			// check volume CR status
+			volumeCR := &vcrd.Volume{}
+			err = node.k8sClient.ReadCR(testCtx, testV1ID, "", volumeCR)
+			Expect(err).To(BeNil())
+			Expect(volumeCR.Spec.CSIStatus).To(Equal(apiV1.Published))
+			Expect(volumeCR.Spec.Owners).To(Equal([]string{"pod-2"}))
+		})
 
 	})
 
@@ -453,6 +464,28 @@ var _ = Describe("CSINodeService NodeUnStage()", func() {
 			Expect(err).To(BeNil())
 			Expect(volumeCR.Spec.CSIStatus).To(Equal(apiV1.Created))
 		})
+		It("Should unstage volume with lazy unmount", func() {
+			req := getNodeUnstageRequest(testV1ID, stagePath)
+			targetPath := path.Join(req.GetStagingTargetPath(), stagingFileName)
+			Expect(node.SetUnstageUnmountPolicy(UnstageUnmountPolicyLazy, 1, 0)).To(BeNil())
+			k8sNode := &corev1.Node{}
+			k8sNode.Name = nodeName
+			k8sNode.Status.NodeInfo.BootID = "boot-1"
+			Expect(node.k8sClient.Create(testCtx, k8sNode)).To(BeNil())
+			fsOps.On("UnmountWithCheck", targetPath).Return(errors.New("target is busy"))
+			fsOps.On("LazyUnmount", targetPath).Return(nil)
+			fsOps.On("RmDir", targetPath).Return(nil)
+
+			resp, err := node.NodeUnstageVolume(testCtx, req)
+			Expect(resp).NotTo(BeNil())
+			Expect(err).To(BeNil())
+			// check CSI status and tracking annotation
+			volumeCR := &vcrd.Volume{}
+			err = node.k8sClient.ReadCR(testCtx, testV1ID, "", volumeCR)
+			Expect(err).To(BeNil())
+			Expect(volumeCR.Spec.CSIStatus).To(Equal(apiV1.Created))
+			Expect(volumeCR.Annotations[apiV1.VolumeAnnotationLazyUnmount]).To(Equal("boot-1"))
+		})
 	})
 
 	Context("NodeUnStage() failure", func() {
@@ -565,20 +598,185 @@ var _ = Describe("CSINodeService NodeGetInfo()", func() {
 })
 
 var _ = Describe("CSINodeService NodeGetCapabilities()", func() {
-	It("Should return STAGE_UNSTAGE_VOLUME capabilities", func() {
+	It("Should return STAGE_UNSTAGE_VOLUME, EXPAND_VOLUME and GET_VOLUME_STATS capabilities", func() {
 		node := newNodeService()
 
 		resp, err := node.NodeGetCapabilities(testCtx, &csi.NodeGetCapabilitiesRequest{})
 		Expect(err).To(BeNil())
 		Expect(resp).ToNot(BeNil())
 		capabilities := resp.GetCapabilities()
-		expectedCapability := &csi.NodeServiceCapability_Rpc{
-			Rpc: &csi.NodeServiceCapability_RPC{
-				Type: csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
-			},
+		Expect(len(capabilities)).To(Equal(3))
+		for i, c := range []csi.NodeServiceCapability_RPC_Type{
+			csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+			csi.NodeServiceCapability_RPC_EXPAND_VOLUME,
+			csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+		} {
+			Expect(capabilities[i].GetRpc().GetType()).To(Equal(c))
 		}
-		Expect(len(capabilities)).To(Equal(1))
-		Expect(capabilities[0].Type).To(Equal(expectedCapability))
+	})
+})
+
+var _ = Describe("CSINodeService NodeGetVolumeStats()", func() {
+	var volumePath = os.TempDir()
+
+	BeforeEach(func() {
+		setVariables()
+	})
+
+	It("Should return usage of file system", func() {
+		fsOps.On("GetFSStats", volumePath).Return(&fs.FSStats{TotalBytes: 100, AvailableBytes: 40, UsedBytes: 60,
+			TotalInodes: 10, FreeInodes: 7, UsedInodes: 3}, nil)
+
+		resp, err := node.NodeGetVolumeStats(testCtx, &csi.NodeGetVolumeStatsRequest{
+			VolumeId: testV1ID, VolumePath: volumePath})
+		Expect(err).To(BeNil())
+		Expect(resp.GetUsage()).To(Equal([]*csi.VolumeUsage{
+			{Unit: csi.VolumeUsage_BYTES, Available: 40, Total: 100, Used: 60},
+			{Unit: csi.VolumeUsage_INODES, Available: 7, Total: 10, Used: 3},
+		}))
+	})
+
+	It("Should return size of block volume", func() {
+		vol1 := &vcrd.Volume{}
+		Expect(node.k8sClient.ReadCR(testCtx, testV1ID, "", vol1)).To(BeNil())
+		vol1.Spec.Mode = apiV1.ModeRAW
+		Expect(node.k8sClient.UpdateCR(testCtx, vol1)).To(BeNil())
+
+		resp, err := node.NodeGetVolumeStats(testCtx, &csi.NodeGetVolumeStatsRequest{
+			VolumeId: testV1ID, VolumePath: volumePath})
+		Expect(err).To(BeNil())
+		Expect(resp.GetUsage()).To(Equal([]*csi.VolumeUsage{{Unit: csi.VolumeUsage_BYTES, Total: vol1.Spec.Size}}))
+		fsOps.AssertNotCalled(GinkgoT(), "GetFSStats", mock.Anything)
+	})
+
+	It("Should fail", func() {
+		_, err := node.NodeGetVolumeStats(testCtx, &csi.NodeGetVolumeStatsRequest{VolumePath: volumePath})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+
+		_, err = node.NodeGetVolumeStats(testCtx, &csi.NodeGetVolumeStatsRequest{VolumeId: "unknown",
+			VolumePath: volumePath})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+
+		_, err = node.NodeGetVolumeStats(testCtx, &csi.NodeGetVolumeStatsRequest{VolumeId: testV1ID,
+			VolumePath: path.Join(volumePath, "not-existing")})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+
+		fsOps.On("GetFSStats", volumePath).Return(nil, errors.New("statfs error"))
+		_, err = node.NodeGetVolumeStats(testCtx, &csi.NodeGetVolumeStatsRequest{VolumeId: testV1ID,
+			VolumePath: volumePath})
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+	})
+})
+
+var _ = Describe("CSINodeService NodeExpandVolume()", func() {
+	var (
+		volumePath  = "/var/lib/kubelet/pods/pod-uuid/volumes/kubernetes.io~csi/pvc-uuid/mount"
+		partition   = "/dev/vg/lv"
+		stagingPath = path.Join(stagePath, stagingFileName)
+		volumeSize  = int64(util.GBYTE)
+		cryptOps    *mocklu.MockWrapCryptsetup
+	)
+
+	BeforeEach(func() {
+		setVariables()
+		cryptOps = &mocklu.MockWrapCryptsetup{}
+		node.cryptOps = cryptOps
+
+		vol1 := &vcrd.Volume{}
+		Expect(node.k8sClient.ReadCR(testCtx, testVolumeCR1.Name, testVolumeCR1.Namespace, vol1)).To(BeNil())
+		vol1.Spec.Mode = apiV1.ModeFS
+		vol1.Spec.Type = string(fs.XFS)
+		vol1.Spec.Size = volumeSize
+		Expect(node.k8sClient.UpdateCR(testCtx, vol1)).To(BeNil())
+		prov.On("GetVolumePath", mock.Anything).Return(partition, nil)
+	})
+
+	getRequest := func(volumeID string) *csi.NodeExpandVolumeRequest {
+		return &csi.NodeExpandVolumeRequest{
+			VolumeId:          volumeID,
+			VolumePath:        volumePath,
+			StagingTargetPath: stagePath,
+			CapacityRange:     &csi.CapacityRange{RequiredBytes: volumeSize},
+			VolumeCapability:  testVolumeCap,
+		}
+	}
+
+	Context("NodeExpandVolume() success", func() {
+		It("Should resize file system", func() {
+			fsOps.On("IsMounted", stagingPath).Return(true, nil)
+			fsOps.On("ResizeFS", fs.XFS, partition, volumePath).Return(nil).Once()
+
+			resp, err := node.NodeExpandVolume(testCtx, getRequest(testV1ID))
+			Expect(err).To(BeNil())
+			Expect(resp.GetCapacityBytes()).To(Equal(volumeSize))
+			fsOps.AssertExpectations(GinkgoT())
+		})
+		It("Should skip file system for block volume", func() {
+			req := getRequest(testV1ID)
+			req.VolumeCapability = &csi.VolumeCapability{AccessType: &csi.VolumeCapability_Block{
+				Block: &csi.VolumeCapability_BlockVolume{}}}
+			fsOps.On("IsMounted", stagingPath).Return(true, nil)
+
+			_, err := node.NodeExpandVolume(testCtx, req)
+			Expect(err).To(BeNil())
+			fsOps.AssertNotCalled(GinkgoT(), "ResizeFS", mock.Anything, mock.Anything, mock.Anything)
+		})
+		It("Should resize LUKS device with key from secrets", func() {
+			vol1 := &vcrd.Volume{}
+			Expect(node.k8sClient.ReadCR(testCtx, testV1ID, "", vol1)).To(BeNil())
+			vol1.Spec.Encryption = apiV1.EncryptionLUKS
+			Expect(node.k8sClient.UpdateCR(testCtx, vol1)).To(BeNil())
+			req := getRequest(testV1ID)
+			req.Secrets = map[string]string{apiV1.EncryptionKeySecretKey: "key"}
+
+			fsOps.On("IsMounted", stagingPath).Return(true, nil)
+			cryptOps.On("Resize", testV1ID, "key").Return(nil).Once()
+			fsOps.On("ResizeFS", fs.XFS, cryptsetup.MapperPath(testV1ID), volumePath).Return(nil).Once()
+
+			_, err := node.NodeExpandVolume(testCtx, req)
+			Expect(err).To(BeNil())
+			cryptOps.AssertExpectations(GinkgoT())
+			fsOps.AssertExpectations(GinkgoT())
+		})
+	})
+
+	Context("NodeExpandVolume() failure", func() {
+		It("Should fail with missing arguments", func() {
+			req := getRequest("")
+			_, err := node.NodeExpandVolume(testCtx, req)
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+
+			req = getRequest(testV1ID)
+			req.VolumePath = ""
+			_, err = node.NodeExpandVolume(testCtx, req)
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+		It("Should fail if volume doesn't exist", func() {
+			_, err := node.NodeExpandVolume(testCtx, getRequest("unknown-volume"))
+			Expect(status.Code(err)).To(Equal(codes.NotFound))
+		})
+		It("Should fail if volume isn't staged", func() {
+			// volume CR with Created status
+			_, err := node.NodeExpandVolume(testCtx, getRequest(testV2ID))
+			Expect(status.Code(err)).To(Equal(codes.FailedPrecondition))
+
+			fsOps.On("IsMounted", stagingPath).Return(false, nil)
+			_, err = node.NodeExpandVolume(testCtx, getRequest(testV1ID))
+			Expect(status.Code(err)).To(Equal(codes.FailedPrecondition))
+		})
+		It("Should fail if LV isn't extended", func() {
+			req := getRequest(testV1ID)
+			req.CapacityRange.RequiredBytes = volumeSize * 2
+			_, err := node.NodeExpandVolume(testCtx, req)
+			Expect(status.Code(err)).To(Equal(codes.OutOfRange))
+		})
+		It("Should fail if file system resize failed", func() {
+			fsOps.On("IsMounted", stagingPath).Return(true, nil)
+			fsOps.On("ResizeFS", fs.XFS, partition, volumePath).Return(errors.New("error"))
+
+			_, err := node.NodeExpandVolume(testCtx, getRequest(testV1ID))
+			Expect(status.Code(err)).To(Equal(codes.Internal))
+		})
 	})
 })
 
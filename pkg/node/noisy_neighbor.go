@@ -0,0 +1,301 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/diskstats"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// Default thresholds of noisy neighbor detection
+const (
+	DefaultNoisyNeighborShare       = 80
+	DefaultNoisyNeighborUtilization = 90
+	DefaultNoisyNeighborDuration    = 5 * time.Minute
+)
+
+// NoisyNeighborDetection holds thresholds of noisy neighbor detection.
+// Volume is noisy if it performs at least Share percent of I/O of drives of its volume group
+// while drives are busy for at least Utilization percent of time during Duration
+type NoisyNeighborDetection struct {
+	Share       float64
+	Utilization float64
+	Duration    time.Duration
+}
+
+// ParseNoisyNeighborDetection parses thresholds in format "share=80,utilization=90,duration=5m",
+// omitted thresholds have default values. Returns error if format is wrong or thresholds are invalid
+func ParseNoisyNeighborDetection(str string) (*NoisyNeighborDetection, error) {
+	cfg := &NoisyNeighborDetection{
+		Share:       DefaultNoisyNeighborShare,
+		Utilization: DefaultNoisyNeighborUtilization,
+		Duration:    DefaultNoisyNeighborDuration,
+	}
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("noisy neighbor detection option %s has wrong format, expected <key>=<value>", item)
+		}
+		var (
+			value = strings.TrimSpace(parts[1])
+			err   error
+		)
+		switch strings.TrimSpace(parts[0]) {
+		case "share":
+			cfg.Share, err = strconv.ParseFloat(value, 64)
+		case "utilization":
+			cfg.Utilization, err = strconv.ParseFloat(value, 64)
+		case "duration":
+			cfg.Duration, err = time.ParseDuration(value)
+		default:
+			return nil, fmt.Errorf("noisy neighbor detection option %s isn't supported, "+
+				"expected share, utilization or duration", item)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("noisy neighbor detection option %s has wrong value: %v", item, err)
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks that percent thresholds are in range (0, 100] and duration isn't negative
+func (c *NoisyNeighborDetection) Validate() error {
+	if c.Share <= 0 || c.Share > 100 {
+		return fmt.Errorf("share threshold %v must be in range (0, 100]", c.Share)
+	}
+	if c.Utilization <= 0 || c.Utilization > 100 {
+		return fmt.Errorf("utilization threshold %v must be in range (0, 100]", c.Utilization)
+	}
+	if c.Duration < 0 {
+		return fmt.Errorf("duration %v must not be negative", c.Duration)
+	}
+	return nil
+}
+
+// noisyNeighborDetector holds state of noisy neighbor detection between collections of I/O statistics
+type noisyNeighborDetector struct {
+	cfg NoisyNeighborDetection
+	// since holds time when volume became noisy per volume ID
+	since map[string]time.Time
+	// reported holds volume IDs which events were sent for
+	reported map[string]bool
+	// prev holds diskstats of previous collection per kernel name of drive
+	prev map[string]diskstats.Stats
+}
+
+// SetNoisyNeighborDetection enables detection of volumes which saturate drives shared with other volumes.
+// Detection works on I/O statistics of volumes, so it is performed only if SetVolumeIOStats is called
+func (m *VolumeManager) SetNoisyNeighborDetection(cfg *NoisyNeighborDetection) {
+	m.noisyNeighbors = &noisyNeighborDetector{
+		cfg:      *cfg,
+		since:    map[string]time.Time{},
+		reported: map[string]bool{},
+		prev:     map[string]diskstats.Stats{},
+	}
+}
+
+// detectNoisyNeighbors checks volumes of each volume group which holds more than one volume.
+// sectors holds sectors read and written by volume since previous collection per volume ID,
+// volumes without deltas aren't checked
+func (m *VolumeManager) detectNoisyNeighbors(ctx context.Context, volumes []volumecrd.Volume,
+	sectors map[string]uint64, stats map[string]diskstats.Stats, now time.Time, interval float64) error {
+	d := m.noisyNeighbors
+	if d == nil {
+		return nil
+	}
+	groupDevices, err := m.volumeGroupDevices()
+	if err != nil {
+		return err
+	}
+
+	// drive based volume occupies the whole drive, only volumes of volume group share drives
+	groups := map[string][]*volumecrd.Volume{}
+	for i := range volumes {
+		volume := &volumes[i]
+		if _, ok := sectors[volume.Spec.Id]; !ok || !util.IsStorageClassLVG(volume.Spec.StorageClass) {
+			continue
+		}
+		if _, ok := groupDevices[volume.Spec.Location]; ok {
+			groups[volume.Spec.Location] = append(groups[volume.Spec.Location], volume)
+		}
+	}
+
+	checked := map[string]bool{}
+	for group, groupVolumes := range groups {
+		if len(groupVolumes) < 2 || interval <= 0 {
+			continue
+		}
+		utilization, total, ok := d.drivesUsage(groupDevices[group], stats, interval)
+		if !ok || total == 0 {
+			continue
+		}
+		for _, volume := range groupVolumes {
+			share := float64(sectors[volume.Spec.Id]) / float64(total) * 100
+			if utilization < d.cfg.Utilization || share < d.cfg.Share {
+				continue
+			}
+			checked[volume.Spec.Id] = true
+			since, ok := d.since[volume.Spec.Id]
+			if !ok {
+				since = now
+				d.since[volume.Spec.Id] = now
+			}
+			if d.reported[volume.Spec.Id] || now.Sub(since) < d.cfg.Duration {
+				continue
+			}
+			d.reported[volume.Spec.Id] = true
+			m.reportNoisyNeighbor(ctx, volume, fmt.Sprintf("Volume %s performs %.0f%% of I/O of drives %s "+
+				"of volume group %s which are %.0f%% utilized for %s", volume.Spec.Id, share,
+				strings.Join(groupDevices[group], ", "), group, utilization, now.Sub(since).Round(time.Second)))
+		}
+	}
+
+	// state of volumes which aren't noisy anymore is reset, event is sent again if volume becomes noisy
+	for volumeID := range d.since {
+		if !checked[volumeID] {
+			delete(d.since, volumeID)
+			delete(d.reported, volumeID)
+		}
+	}
+	d.prev = make(map[string]diskstats.Stats, len(d.prev))
+	for _, devices := range groupDevices {
+		for _, device := range devices {
+			if cur, ok := stats[device]; ok {
+				d.prev[device] = cur
+			}
+		}
+	}
+	return nil
+}
+
+// volumeGroupDevices returns kernel names of drives per name of volume group of the node
+func (m *VolumeManager) volumeGroupDevices() (map[string][]string, error) {
+	drives, err := m.cachedCrHelper.GetDriveCRs(m.nodeID)
+	if err != nil {
+		return nil, err
+	}
+	lvgs, err := m.cachedCrHelper.GetLVGCRs(m.nodeID)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]string, len(drives))
+	for _, drive := range drives {
+		paths[drive.Spec.UUID] = drive.Spec.Path
+	}
+	res := make(map[string][]string, len(lvgs))
+	for _, lvg := range lvgs {
+		for _, location := range lvg.Spec.Locations {
+			if path := paths[location]; path != "" {
+				res[lvg.Name] = append(res[lvg.Name], filepath.Base(path))
+			}
+		}
+	}
+	return res, nil
+}
+
+// drivesUsage returns max utilization of drives in percent and sum of sectors read and written by drives
+// since previous collection. Returns false if previous diskstats of any drive aren't known
+func (d *noisyNeighborDetector) drivesUsage(devices []string, stats map[string]diskstats.Stats,
+	interval float64) (float64, uint64, bool) {
+	var (
+		utilization float64
+		sectors     uint64
+	)
+	for _, device := range devices {
+		cur, ok := stats[device]
+		if !ok {
+			return 0, 0, false
+		}
+		prev, ok := d.prev[device]
+		// counters are reset when device appears again
+		if !ok || cur.IOTimeMs < prev.IOTimeMs || cur.ReadSectors < prev.ReadSectors ||
+			cur.WriteSectors < prev.WriteSectors {
+			return 0, 0, false
+		}
+		sectors += cur.ReadSectors - prev.ReadSectors + cur.WriteSectors - prev.WriteSectors
+		if u := float64(cur.IOTimeMs-prev.IOTimeMs) / (interval * 1000) * 100; u > utilization {
+			utilization = u
+		}
+	}
+	return utilization, sectors, true
+}
+
+// reportNoisyNeighbor sends NoisyNeighbor event for PersistentVolumeClaim of volume and pods of the node
+// which use it. Event is sent for volume CR if volume doesn't have PersistentVolumeClaim
+func (m *VolumeManager) reportNoisyNeighbor(ctx context.Context, volume *volumecrd.Volume, msg string) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "reportNoisyNeighbor",
+		"volumeID": volume.Spec.Id,
+	})
+	ll.Warn(msg)
+
+	claim := m.volumeClaim(ctx, volume)
+	if claim.name == "" {
+		m.recorder.Eventf(volume, eventing.NoisyNeighbor, "%s", msg)
+		return
+	}
+	objects := make([]runtime.Object, 0)
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: claim.name, Namespace: claim.namespace}, pvc); err != nil {
+		ll.Errorf("Unable to read PersistentVolumeClaim %s/%s: %v", claim.namespace, claim.name, err)
+	} else {
+		objects = append(objects, pvc)
+	}
+	pods := &corev1.PodList{}
+	if err := m.k8sClient.List(ctx, pods, k8sCl.InNamespace(claim.namespace)); err != nil {
+		ll.Errorf("Unable to read pods of namespace %s: %v", claim.namespace, err)
+	}
+	for i := range pods.Items {
+		if pods.Items[i].Spec.NodeName == m.nodeName && usesClaim(&pods.Items[i], claim.name) {
+			objects = append(objects, &pods.Items[i])
+		}
+	}
+	if len(objects) == 0 {
+		objects = append(objects, volume)
+	}
+	for _, obj := range objects {
+		m.recorder.Eventf(obj, eventing.NoisyNeighbor, "%s", msg)
+	}
+}
+
+// usesClaim returns true if pod mounts PersistentVolumeClaim with name
+func usesClaim(pod *corev1.Pod, name string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestParseNoisyNeighborDetection(t *testing.T) {
+	cfg, err := ParseNoisyNeighborDetection("share=70, duration=1m")
+	assert.Nil(t, err)
+	assert.Equal(t, &NoisyNeighborDetection{Share: 70, Utilization: DefaultNoisyNeighborUtilization,
+		Duration: time.Minute}, cfg)
+
+	for _, str := range []string{"share", "share=abc", "share=120", "utilization=0", "duration=-1m", "iops=10"} {
+		_, err = ParseNoisyNeighborDetection(str)
+		assert.NotNil(t, err, str)
+	}
+}
+
+func TestVolumeManager_detectNoisyNeighbors(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		path     = filepath.Join(t.TempDir(), "diskstats")
+		lvg      = testLVGCR.DeepCopy()
+		noisy    = testVolumeLVGCR.DeepCopy()
+		quiet    = testVolumeLVGCR.DeepCopy()
+		pv       = &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: noisy.Spec.Id},
+			Spec: corev1.PersistentVolumeSpec{
+				ClaimRef: &corev1.ObjectReference{Name: "data", Namespace: testNs},
+			},
+		}
+		pvc = &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: testNs}}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-0", Namespace: testNs},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+				}}},
+			},
+		}
+		// collect writes diskstats and collects statistics as if interval seconds passed
+		collect = func(interval int, ioTimeMs, noisySectors, quietSectors uint64) {
+			vm.ioStats.prevTime = time.Now().Add(-time.Duration(interval) * time.Second)
+			content := fmt.Sprintf("8 0 sda 0 0 0 0 0 0 %d 0 0 %d 0\n"+
+				"253 0 dm-0 0 0 0 0 0 0 %d 0 0 0 0\n"+
+				"253 1 dm-1 0 0 0 0 0 0 %d 0 0 0 0\n",
+				noisySectors+quietSectors, ioTimeMs, noisySectors, quietSectors)
+			assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+			assert.Nil(t, vm.CollectVolumeIOStats(testCtx))
+		}
+	)
+	vm.recorder = recorder
+	vm.SetVolumeIOStats()
+	vm.SetNoisyNeighborDetection(&NoisyNeighborDetection{Share: 80, Utilization: 90, Duration: 15 * time.Second})
+	vm.ioStats.diskstatsPath = path
+
+	noisy.Spec.CSIStatus = apiV1.Published
+	quiet.Name, quiet.Spec.Id, quiet.Spec.CSIStatus = "quiet", "quiet", apiV1.VolumeReady
+	vm.ioStats.devices[noisy.Spec.Id] = "dm-0"
+	vm.ioStats.devices[quiet.Spec.Id] = "dm-1"
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, testDriveCR.Name, testDriveCR.DeepCopy()))
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, lvg.Name, lvg))
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, noisy.Name, noisy))
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, quiet.Name, quiet))
+	assert.Nil(t, vm.k8sClient.Create(testCtx, pv))
+	assert.Nil(t, vm.k8sClient.Create(testCtx, pvc))
+	assert.Nil(t, vm.k8sClient.Create(testCtx, pod))
+
+	collect(10, 0, 0, 0)
+	// drive is 95% utilized, noisy volume performs 90% of I/O
+	collect(10, 9500, 900, 100)
+	assert.Empty(t, recorder.Calls)
+	assert.Contains(t, vm.noisyNeighbors.since, noisy.Spec.Id)
+	assert.NotContains(t, vm.noisyNeighbors.since, quiet.Spec.Id)
+
+	// volume is noisy for duration
+	vm.noisyNeighbors.since[noisy.Spec.Id] = time.Now().Add(-20 * time.Second)
+	collect(10, 19000, 1800, 200)
+	assert.Len(t, recorder.Calls, 2)
+	for _, call := range recorder.Calls {
+		assert.Equal(t, eventing.NoisyNeighbor, call.Event)
+	}
+	assert.IsType(t, &corev1.PersistentVolumeClaim{}, recorder.Calls[0].Object)
+	assert.IsType(t, &corev1.Pod{}, recorder.Calls[1].Object)
+
+	// event is sent once
+	collect(10, 28500, 2700, 300)
+	assert.Len(t, recorder.Calls, 2)
+
+	// drive isn't saturated anymore
+	collect(10, 29500, 3600, 400)
+	assert.Empty(t, vm.noisyNeighbors.since)
+	assert.Empty(t, vm.noisyNeighbors.reported)
+	assert.Len(t, recorder.Calls, 2)
+}
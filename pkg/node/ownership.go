@@ -0,0 +1,168 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+// ErrOwnershipMismatch is returned when device of the volume drive doesn't hold partition or filesystem
+// recorded in Volume CR, device might be swapped out underneath
+var ErrOwnershipMismatch = errors.New("volume ownership verification failed")
+
+// SetVolumeOwnershipVerification enables recording of partition GUID and filesystem UUID of drive based volumes
+// after creation and verification of them before volume release
+func (m *VolumeManager) SetVolumeOwnershipVerification() {
+	m.ownershipVerification = true
+}
+
+// isOwnershipTracked returns true if volume is located on partition which GUID is derived from volume ID
+func (m *VolumeManager) isOwnershipTracked(volume *volumecrd.Volume) bool {
+	return m.ownershipVerification && !util.IsStorageClassLVG(volume.Spec.StorageClass) &&
+		volume.Spec.Mode != apiV1.ModeRAW && !volume.Spec.Ephemeral
+}
+
+// recordVolumeOwnership records ownership of created volume, errors are logged only
+func (m *VolumeManager) recordVolumeOwnership(volume *volumecrd.Volume) {
+	if !m.isOwnershipTracked(volume) {
+		return
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "recordVolumeOwnership",
+		"volumeID": volume.Spec.Id,
+	})
+	drive, err := m.crHelper.GetDriveCRByVolume(volume)
+	if err == nil {
+		err = m.recordOwnership(volume, drive)
+	}
+	if err != nil {
+		ll.Errorf("Unable to record volume ownership: %v", err)
+	}
+}
+
+// recordOwnership sets annotations with GUID of volume partition and UUID of its filesystem, CR isn't updated
+// Filesystem of encrypted and lazy formatted volumes isn't created yet, only partition GUID is recorded for them
+func (m *VolumeManager) recordOwnership(volume *volumecrd.Volume, drive *drivecrd.Drive) error {
+	if !m.isOwnershipTracked(volume) {
+		return nil
+	}
+	dev, err := m.getDriveDevice(drive)
+	if err != nil {
+		return err
+	}
+	candidates := m.partScheme.PartUUIDCandidates(volume.Spec.Id)
+	for _, part := range dev.Children {
+		if p.MatchPartUUID(candidates, part.PartUUID) == "" {
+			continue
+		}
+		if volume.Annotations == nil {
+			volume.Annotations = make(map[string]string, 2)
+		}
+		volume.Annotations[apiV1.VolumeAnnotationPartUUID] = strings.ToLower(part.PartUUID)
+		if part.UUID != "" && volume.Spec.Encryption == "" {
+			volume.Annotations[apiV1.VolumeAnnotationFSUUID] = strings.ToLower(part.UUID)
+		}
+		return nil
+	}
+	return fmt.Errorf("partition with GUID %s isn't found on device %s", strings.Join(candidates, " or "),
+		dev.Name)
+}
+
+// verifyOwnership checks that device of the volume drive holds partition with GUID recorded in Volume CR
+// and that the partition doesn't hold foreign filesystem. Volumes without recorded GUID aren't verified
+// Returns ErrOwnershipMismatch with details if device was swapped out underneath
+func (m *VolumeManager) verifyOwnership(volume *volumecrd.Volume, drive *drivecrd.Drive) error {
+	partUUID := volume.Annotations[apiV1.VolumeAnnotationPartUUID]
+	if !m.isOwnershipTracked(volume) || partUUID == "" {
+		return nil
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "verifyOwnership",
+		"volumeID": volume.Spec.Id,
+	})
+
+	dev, err := m.getDriveDevice(drive)
+	if err != nil {
+		return err
+	}
+	var found *lsblk.BlockDevice
+	for i := range dev.Children {
+		if strings.EqualFold(dev.Children[i].PartUUID, partUUID) {
+			found = &dev.Children[i]
+			break
+		}
+	}
+	if found == nil {
+		// release of volume which partition was already removed wipes device, it is allowed for empty device only
+		if len(dev.Children) > 0 {
+			return fmt.Errorf("%w: partition with GUID %s isn't found on device %s (drive S/N %s), "+
+				"device holds partitions with GUID %s", ErrOwnershipMismatch, partUUID, dev.Name,
+				drive.Spec.SerialNumber, strings.Join(partUUIDs(dev.Children), ", "))
+		}
+		if dev.FSType != "" {
+			return fmt.Errorf("%w: partition with GUID %s isn't found on device %s (drive S/N %s), "+
+				"device holds %s filesystem with UUID %s", ErrOwnershipMismatch, partUUID, dev.Name,
+				drive.Spec.SerialNumber, dev.FSType, dev.UUID)
+		}
+		ll.Infof("Partition with GUID %s was already removed from device %s", partUUID, dev.Name)
+		return nil
+	}
+
+	fsUUID := volume.Annotations[apiV1.VolumeAnnotationFSUUID]
+	if fsUUID != "" && found.UUID != "" && !strings.EqualFold(found.UUID, fsUUID) {
+		return fmt.Errorf("%w: partition %s holds filesystem with UUID %s, volume filesystem UUID is %s",
+			ErrOwnershipMismatch, found.Name, found.UUID, fsUUID)
+	}
+	ll.Debugf("Ownership of partition %s is verified", found.Name)
+	return nil
+}
+
+// getDriveDevice returns block device of the drive with its partitions
+func (m *VolumeManager) getDriveDevice(drive *drivecrd.Drive) (*lsblk.BlockDevice, error) {
+	device, err := m.listBlk.SearchDrivePath(&drive.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find device of drive with S/N %s: %w", drive.Spec.SerialNumber, err)
+	}
+	devices, err := m.listBlk.GetBlockDevices(device)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("device %s of drive with S/N %s isn't found", device, drive.Spec.SerialNumber)
+	}
+	return &devices[0], nil
+}
+
+// partUUIDs returns GUIDs of partitions
+func partUUIDs(partitions []lsblk.BlockDevice) []string {
+	res := make([]string, 0, len(partitions))
+	for _, part := range partitions {
+		res = append(res, part.PartUUID)
+	}
+	return res
+}
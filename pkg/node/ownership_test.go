@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+func TestVolumeManager_recordOwnership(t *testing.T) {
+	var (
+		vm      = prepareSuccessVolumeManager(t)
+		listBlk = &mocklu.MockWrapLsblk{}
+		volume  = volCR.DeepCopy()
+		drive   = testDriveCR.DeepCopy()
+		device  = "/dev/sda"
+	)
+	vm.listBlk = listBlk
+	listBlk.On("SearchDrivePath", mock.Anything).Return(device, nil)
+	listBlk.On("GetBlockDevices", device).Return([]lsblk.BlockDevice{{Name: device, Children: []lsblk.BlockDevice{
+		{Name: device + "1", PartUUID: "ANOTHER"},
+		{Name: device + "2", PartUUID: volume.Spec.Id, UUID: "FS-UUID"},
+	}}}, nil)
+
+	// disabled
+	assert.Nil(t, vm.recordOwnership(volume, drive))
+	assert.Empty(t, volume.Annotations)
+
+	vm.SetVolumeOwnershipVerification()
+	assert.Nil(t, vm.recordOwnership(volume, drive))
+	assert.Equal(t, volume.Spec.Id, volume.Annotations[apiV1.VolumeAnnotationPartUUID])
+	assert.Equal(t, "fs-uuid", volume.Annotations[apiV1.VolumeAnnotationFSUUID])
+
+	// partition isn't found
+	volume = volCR.DeepCopy()
+	volume.Spec.Id = "another-volume"
+	assert.NotNil(t, vm.recordOwnership(volume, drive))
+}
+
+func TestVolumeManager_verifyOwnership(t *testing.T) {
+	var (
+		vm     = prepareSuccessVolumeManager(t)
+		volume = volCR.DeepCopy()
+		drive  = testDriveCR.DeepCopy()
+		device = "/dev/sda"
+	)
+	vm.SetVolumeOwnershipVerification()
+	volume.Annotations = map[string]string{
+		apiV1.VolumeAnnotationPartUUID: volume.Spec.Id,
+		apiV1.VolumeAnnotationFSUUID:   "fs-uuid",
+	}
+	verify := func(dev lsblk.BlockDevice) error {
+		listBlk := &mocklu.MockWrapLsblk{}
+		listBlk.On("SearchDrivePath", mock.Anything).Return(device, nil)
+		listBlk.On("GetBlockDevices", device).Return([]lsblk.BlockDevice{dev}, nil)
+		vm.listBlk = listBlk
+		return vm.verifyOwnership(volume, drive)
+	}
+
+	// partition and filesystem match
+	assert.Nil(t, verify(lsblk.BlockDevice{Name: device, Children: []lsblk.BlockDevice{
+		{Name: device + "1", PartUUID: volume.Spec.Id, UUID: "FS-UUID"}}}))
+	// partition was already removed
+	assert.Nil(t, verify(lsblk.BlockDevice{Name: device}))
+
+	// foreign filesystem on partition
+	err := verify(lsblk.BlockDevice{Name: device, Children: []lsblk.BlockDevice{
+		{Name: device + "1", PartUUID: volume.Spec.Id, UUID: "foreign"}}})
+	assert.True(t, errors.Is(err, ErrOwnershipMismatch))
+	assert.Contains(t, err.Error(), "foreign")
+	// device was swapped
+	err = verify(lsblk.BlockDevice{Name: device, Children: []lsblk.BlockDevice{
+		{Name: device + "1", PartUUID: "another"}}})
+	assert.True(t, errors.Is(err, ErrOwnershipMismatch))
+	assert.Contains(t, err.Error(), "another")
+	err = verify(lsblk.BlockDevice{Name: device, FSType: "ext4", UUID: "foreign"})
+	assert.True(t, errors.Is(err, ErrOwnershipMismatch))
+
+	// ownership isn't recorded
+	volume.Annotations = nil
+	assert.Nil(t, verify(lsblk.BlockDevice{Name: device, FSType: "ext4"}))
+}
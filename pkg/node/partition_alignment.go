@@ -0,0 +1,357 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// Policies of handling of misaligned partitions of volumes, e.g. partitions created by older versions
+// of the driver or manually
+const (
+	// PartitionAlignmentPolicyReport reports misaligned partitions with events, annotations and metrics
+	PartitionAlignmentPolicyReport = "report"
+	// PartitionAlignmentPolicyRealign reports misaligned partitions and moves partitions of unused volumes
+	// to aligned location
+	PartitionAlignmentPolicyRealign = "realign"
+
+	// alignmentStatusUpdateAttempts is an amount of attempts to set result of realignment
+	alignmentStatusUpdateAttempts = 5
+)
+
+// partitionAligner holds settings and state of partition alignment audit
+type partitionAligner struct {
+	aligner ph.WrapAlignment
+	realign bool
+	// volume ID -> alignment of volume partition, partition is read once per process or after realignment
+	checked map[string]*ph.PartitionAlignment
+	// volumes which are being realigned or staged, volume is realigned only when it isn't staged
+	busyMu sync.Mutex
+	busy   map[string]string
+	// metrics
+	misaligned *prometheus.GaugeVec
+}
+
+// SetPartitionAlignmentPolicy enables audit of alignment of volume partitions during Discover
+// Receives policy: report or realign
+func (m *VolumeManager) SetPartitionAlignmentPolicy(policy string) error {
+	if policy != PartitionAlignmentPolicyReport && policy != PartitionAlignmentPolicyRealign {
+		return fmt.Errorf("partition alignment policy %s isn't supported, expected %s or %s",
+			policy, PartitionAlignmentPolicyReport, PartitionAlignmentPolicyRealign)
+	}
+	a := &partitionAligner{
+		aligner: ph.NewWrapAlignmentImpl(command.NewExecutor(m.log.Logger)),
+		realign: policy == PartitionAlignmentPolicyRealign,
+		checked: make(map[string]*ph.PartitionAlignment),
+		busy:    make(map[string]string),
+		misaligned: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "misaligned_partitions",
+			Help:        "number of misaligned partitions of volumes by impact on performance",
+			ConstLabels: prometheus.Labels{"node": m.nodeName},
+		}, []string{"impact"}),
+	}
+	if err := prometheus.Register(a.misaligned); err != nil {
+		m.log.WithField("method", "SetPartitionAlignmentPolicy").Errorf("Failed to register metric: %v", err)
+	}
+	m.partitionAlignment = a
+	return nil
+}
+
+// acquire marks volume as busy with operation
+// Returns false if volume is busy with another operation
+func (a *partitionAligner) acquire(volumeID, operation string) bool {
+	a.busyMu.Lock()
+	defer a.busyMu.Unlock()
+	if _, busy := a.busy[volumeID]; busy {
+		return false
+	}
+	a.busy[volumeID] = operation
+	return true
+}
+
+// release marks volume as not busy
+func (a *partitionAligner) release(volumeID string) {
+	a.busyMu.Lock()
+	defer a.busyMu.Unlock()
+	delete(a.busy, volumeID)
+}
+
+// isRealigning returns true if partition of any volume is being realigned
+func (a *partitionAligner) isRealigning() bool {
+	a.busyMu.Lock()
+	defer a.busyMu.Unlock()
+	for _, operation := range a.busy {
+		if operation == "realign" {
+			return true
+		}
+	}
+	return false
+}
+
+// auditPartitionAlignment finds volumes which partitions aren't aligned to physical sectors, optimal I/O size and
+// 1 MiB boundary of the drive and starts realignment of unused volume if policy allows. Only one volume of the node
+// is realigned at a time
+func (m *VolumeManager) auditPartitionAlignment(ctx context.Context) error {
+	if m.partitionAlignment == nil {
+		return nil
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "auditPartitionAlignment",
+	})
+
+	volumes, err := m.cachedCrHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		a        = m.partitionAlignment
+		counts   = map[string]int{ph.AlignmentImpactMinor: 0, ph.AlignmentImpactModerate: 0, ph.AlignmentImpactSevere: 0}
+		checked  = make(map[string]*ph.PartitionAlignment)
+		realigns = a.isRealigning()
+	)
+	for i := range volumes {
+		volume := volumes[i].DeepCopy()
+		if !hasOwnPartition(volume) {
+			continue
+		}
+		if volume.Annotations[apiV1.VolumeAnnotationAlignment] == apiV1.VolumeAnnotationAlignmentInProgress {
+			if a.acquire(volume.Spec.Id, "audit") {
+				// node service was restarted during realignment, data might be moved partially
+				m.setAlignmentResult(ctx, volume, errors.New("realignment was interrupted"))
+				a.release(volume.Spec.Id)
+			}
+			continue
+		}
+
+		alignment, ok := a.checked[volume.Spec.Id]
+		if !ok {
+			path, err := m.getProvisionerForVolume(&volume.Spec).GetVolumePath(&volume.Spec)
+			if err != nil {
+				ll.Errorf("Unable to find partition of volume %s: %v", volume.Name, err)
+				continue
+			}
+			if alignment, err = a.aligner.GetPartitionAlignment(path); err != nil {
+				ll.Errorf("Unable to read alignment of partition %s of volume %s: %v", path, volume.Name, err)
+				continue
+			}
+		}
+		checked[volume.Spec.Id] = alignment
+
+		impact := alignment.Impact()
+		if impact == ph.AlignmentImpactNone {
+			continue
+		}
+		counts[impact]++
+		reported := volume.Annotations[apiV1.VolumeAnnotationAlignmentImpact] == impact
+		state := volume.Annotations[apiV1.VolumeAnnotationAlignment]
+		if !reported || state == "" || state == apiV1.VolumeAnnotationAlignmentDone {
+			if !reported {
+				ll.Warnf("Partition %s of volume %s is misaligned by %d bytes, impact: %s",
+					alignment.Partition, volume.Name, alignment.Misalignment(), impact)
+				m.recorder.Eventf(volume, eventing.VolumePartitionMisaligned,
+					"Partition %s of volume %s is misaligned by %d bytes, impact on performance: %s",
+					alignment.Partition, volume.Name, alignment.Misalignment(), impact)
+			}
+			if volume.Annotations == nil {
+				volume.Annotations = make(map[string]string)
+			}
+			if state != apiV1.VolumeAnnotationAlignmentFailed {
+				volume.Annotations[apiV1.VolumeAnnotationAlignment] = apiV1.VolumeAnnotationAlignmentMisaligned
+			}
+			volume.Annotations[apiV1.VolumeAnnotationAlignmentImpact] = impact
+			if err = m.k8sClient.UpdateCR(ctx, volume); err != nil {
+				ll.Errorf("Unable to annotate misaligned volume %s: %v", volume.Name, err)
+				continue
+			}
+		}
+
+		// realignment which failed isn't retried until error annotation is removed
+		if !a.realign || realigns || volume.Spec.CSIStatus != apiV1.Created ||
+			volume.Annotations[apiV1.VolumeAnnotationAlignment] != apiV1.VolumeAnnotationAlignmentMisaligned ||
+			volume.Annotations[apiV1.VolumeAnnotationAlignmentError] != "" {
+			continue
+		}
+		if m.startRealignment(ctx, volume, alignment) {
+			realigns = true
+			// partition is read again after realignment
+			delete(checked, volume.Spec.Id)
+		}
+	}
+	a.checked = checked
+
+	for impact, count := range counts {
+		a.misaligned.WithLabelValues(impact).Set(float64(count))
+	}
+	return nil
+}
+
+// hasOwnPartition returns true if volume is a partition of drive which is created by node service
+func hasOwnPartition(volume *volumecrd.Volume) bool {
+	if util.IsStorageClassLVG(volume.Spec.StorageClass) || volume.Spec.Mode == apiV1.ModeRAW ||
+		volume.Spec.CacheMode != "" {
+		return false
+	}
+	switch volume.Spec.CSIStatus {
+	case apiV1.Created, apiV1.VolumeReady, apiV1.Published:
+		return true
+	}
+	return false
+}
+
+// startRealignment marks volume with in-progress realignment annotation and realigns its partition in background.
+// Volume isn't staged during realignment
+// Returns true if realignment is started
+func (m *VolumeManager) startRealignment(ctx context.Context, volume *volumecrd.Volume,
+	alignment *ph.PartitionAlignment) bool {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "startRealignment",
+		"volumeID": volume.Spec.Id,
+	})
+
+	a := m.partitionAlignment
+	if !a.acquire(volume.Spec.Id, "realign") {
+		ll.Debug("Volume is being staged")
+		return false
+	}
+	done, ok := m.operations.Begin("realign " + volume.Name)
+	if !ok {
+		a.release(volume.Spec.Id)
+		return false
+	}
+	// volume might be staged after it was read from cache
+	if err := m.k8sClient.ReadCR(ctx, volume.Name, volume.Namespace, volume); err != nil ||
+		volume.Spec.CSIStatus != apiV1.Created {
+		a.release(volume.Spec.Id)
+		done()
+		return false
+	}
+
+	if volume.Annotations == nil {
+		volume.Annotations = make(map[string]string)
+	}
+	volume.Annotations[apiV1.VolumeAnnotationAlignment] = apiV1.VolumeAnnotationAlignmentInProgress
+	delete(volume.Annotations, apiV1.VolumeAnnotationAlignmentError)
+	if err := m.k8sClient.UpdateCR(ctx, volume); err != nil {
+		ll.Errorf("Unable to set realignment annotation: %v", err)
+		a.release(volume.Spec.Id)
+		done()
+		return false
+	}
+
+	ll.Infof("Partition %s is being realigned", alignment.Partition)
+	go func() {
+		defer done()
+		defer a.release(volume.Spec.Id)
+		m.realignPartition(volume.DeepCopy(), alignment)
+	}()
+	return true
+}
+
+// realignPartition moves partition of volume to aligned location holding lock of volume drive and sets result
+// in Volume CR
+func (m *VolumeManager) realignPartition(volume *volumecrd.Volume, alignment *ph.PartitionAlignment) {
+	ctx := context.WithValue(context.Background(), base.RequestUUID, volume.Name)
+	unlock := m.lockLocation(m.log.WithField("method", "realignPartition"), volume.Spec.Location)
+	newStart, err := alignment.PlanRealign()
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ph.ErrRealignNotStarted, err)
+	} else {
+		err = m.partitionAlignment.aligner.RealignPartition(alignment, newStart)
+	}
+	unlock()
+	m.setAlignmentResult(ctx, volume, err)
+}
+
+// setAlignmentResult sets result of partition realignment in annotations of Volume CR and sends event.
+// Volume which data might be moved partially is marked as failed and isn't staged
+func (m *VolumeManager) setAlignmentResult(ctx context.Context, volume *volumecrd.Volume, err error) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "setAlignmentResult",
+		"volumeID": volume.Spec.Id,
+	})
+
+	state := apiV1.VolumeAnnotationAlignmentDone
+	switch {
+	case err == nil:
+		ll.Info("Partition of volume is realigned")
+		m.recorder.Eventf(volume, eventing.VolumePartitionRealigned, "Partition of volume %s is realigned", volume.Name)
+	case errors.Is(err, ph.ErrRealignNotStarted):
+		state = apiV1.VolumeAnnotationAlignmentMisaligned
+		ll.Warnf("Unable to realign partition of volume, data is intact: %v", err)
+		m.recorder.Eventf(volume, eventing.VolumePartitionRealignFailed,
+			"Unable to realign partition of volume %s, data is intact: %v", volume.Name, err)
+	default:
+		state = apiV1.VolumeAnnotationAlignmentFailed
+		ll.Errorf("Realignment of partition failed, data of volume might be corrupted: %v", err)
+		m.recorder.Eventf(volume, eventing.VolumePartitionRealignFailed,
+			"Realignment of partition of volume %s failed, data might be corrupted: %v", volume.Name, err)
+	}
+
+	// volume CR might be changed during realignment, so the latest version is updated
+	for i := 0; i < alignmentStatusUpdateAttempts; i++ {
+		if readErr := m.k8sClient.ReadCR(ctx, volume.Name, volume.Namespace, volume); readErr != nil {
+			ll.Errorf("Unable to read volume CR: %v", readErr)
+			continue
+		}
+		if volume.Annotations == nil {
+			volume.Annotations = make(map[string]string)
+		}
+		volume.Annotations[apiV1.VolumeAnnotationAlignment] = state
+		if err != nil {
+			volume.Annotations[apiV1.VolumeAnnotationAlignmentError] = err.Error()
+		} else {
+			delete(volume.Annotations, apiV1.VolumeAnnotationAlignmentImpact)
+		}
+		updateErr := m.k8sClient.UpdateCR(ctx, volume)
+		if updateErr == nil {
+			return
+		}
+		ll.Warnf("Unable to set realignment status %s, attempt %d out of %d: %v",
+			state, i+1, alignmentStatusUpdateAttempts, updateErr)
+	}
+	ll.Errorf("Unable to set realignment status %s", state)
+}
+
+// checkAlignmentStatus returns error if volume can't be staged because its partition is being realigned
+// or realignment failed
+func checkAlignmentStatus(volume *volumecrd.Volume) error {
+	switch volume.Annotations[apiV1.VolumeAnnotationAlignment] {
+	case apiV1.VolumeAnnotationAlignmentInProgress:
+		return status.Error(codes.Unavailable, "partition of volume is being realigned")
+	case apiV1.VolumeAnnotationAlignmentFailed:
+		return status.Errorf(codes.Internal, "realignment of volume partition failed, data might be corrupted: %s",
+			volume.Annotations[apiV1.VolumeAnnotationAlignmentError])
+	}
+	return nil
+}
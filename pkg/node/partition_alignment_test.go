@@ -0,0 +1,138 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	vcrd "github.com/dell/csi-baremetal/api/v1/volumecrd"
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+func TestVolumeManager_auditPartitionAlignment(t *testing.T) {
+	var (
+		vm        = prepareSuccessVolumeManager(t)
+		recorder  = new(mocks.NoOpRecorder)
+		aligner   = &mockProv.MockWrapAlignment{}
+		ready     = testVolumeCR1.DeepCopy()
+		created   = testVolumeCR2.DeepCopy()
+		volume    = &vcrd.Volume{}
+		done      = make(chan time.Time)
+		mib       = int64(1024 * 1024)
+		alignment = &ph.PartitionAlignment{Device: "/dev/sda", Partition: "/dev/sda1", PartNum: "1", Start: 63 * 512,
+			Size: 100 * mib, DeviceSize: 200 * mib, LogicalBlockSize: 512, PhysicalBlockSize: 4096}
+		countEvents = func(event *eventing.EventDescription) int {
+			count := 0
+			for _, c := range recorder.Calls {
+				if c.Event == event {
+					count++
+				}
+			}
+			return count
+		}
+	)
+	vm.recorder = recorder
+	vm.SetProvisioners(map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: mockProv.GetMockProvisionerSuccess("/dev/sda1")})
+	addVolumeCRs(vm.k8sClient, ready, created)
+
+	// disabled
+	assert.Nil(t, vm.auditPartitionAlignment(testCtx))
+	assert.NotNil(t, vm.SetPartitionAlignmentPolicy("fix"))
+
+	assert.Nil(t, vm.SetPartitionAlignmentPolicy(PartitionAlignmentPolicyRealign))
+	vm.partitionAlignment.aligner = aligner
+	aligner.On("GetPartitionAlignment", "/dev/sda1").Return(alignment, nil)
+	aligner.On("RealignPartition", alignment, mib).Return(nil).WaitUntil(done)
+
+	// both volumes are reported, only unused volume is realigned
+	assert.Nil(t, vm.auditPartitionAlignment(testCtx))
+	assert.Equal(t, 2, countEvents(eventing.VolumePartitionMisaligned))
+	assert.Equal(t, float64(2), testutil.ToFloat64(vm.partitionAlignment.misaligned.WithLabelValues(ph.AlignmentImpactSevere)))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, ready.Name, testNs, volume))
+	assert.Equal(t, apiV1.VolumeAnnotationAlignmentMisaligned, volume.Annotations[apiV1.VolumeAnnotationAlignment])
+	assert.Equal(t, ph.AlignmentImpactSevere, volume.Annotations[apiV1.VolumeAnnotationAlignmentImpact])
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, created.Name, testNs, volume))
+	assert.Equal(t, apiV1.VolumeAnnotationAlignmentInProgress, volume.Annotations[apiV1.VolumeAnnotationAlignment])
+
+	// volume isn't staged during realignment, volumes aren't reported twice
+	assert.False(t, vm.partitionAlignment.acquire(created.Spec.Id, "stage"))
+	assert.Equal(t, codes.Unavailable, status.Code(checkAlignmentStatus(volume)))
+	assert.Nil(t, vm.auditPartitionAlignment(testCtx))
+	assert.Equal(t, 2, countEvents(eventing.VolumePartitionMisaligned))
+
+	close(done)
+	assert.Eventually(t, func() bool {
+		// decoding into the existing object keeps keys of its annotations map
+		volume = &vcrd.Volume{}
+		_ = vm.k8sClient.ReadCR(testCtx, created.Name, testNs, volume)
+		return volume.Annotations[apiV1.VolumeAnnotationAlignment] == apiV1.VolumeAnnotationAlignmentDone
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Empty(t, volume.Annotations[apiV1.VolumeAnnotationAlignmentImpact])
+	assert.Nil(t, checkAlignmentStatus(volume))
+	assert.Eventually(t, func() bool {
+		return vm.partitionAlignment.acquire(created.Spec.Id, "stage")
+	}, 10*time.Second, 100*time.Millisecond)
+	vm.partitionAlignment.release(created.Spec.Id)
+	assert.Equal(t, 1, countEvents(eventing.VolumePartitionRealigned))
+	aligner.AssertNumberOfCalls(t, "RealignPartition", 1)
+
+	// node service was restarted during realignment
+	volume.Annotations[apiV1.VolumeAnnotationAlignment] = apiV1.VolumeAnnotationAlignmentInProgress
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+	assert.Nil(t, vm.auditPartitionAlignment(testCtx))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, created.Name, testNs, volume))
+	assert.Equal(t, apiV1.VolumeAnnotationAlignmentFailed, volume.Annotations[apiV1.VolumeAnnotationAlignment])
+	assert.Equal(t, codes.Internal, status.Code(checkAlignmentStatus(volume)))
+
+	// there is no space to move partition, data is intact and realignment isn't retried
+	vm = prepareSuccessVolumeManager(t)
+	vm.recorder = recorder
+	vm.SetProvisioners(map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: mockProv.GetMockProvisionerSuccess("/dev/sda1")})
+	created = testVolumeCR2.DeepCopy()
+	addVolumeCRs(vm.k8sClient, created)
+	assert.Nil(t, vm.SetPartitionAlignmentPolicy(PartitionAlignmentPolicyRealign))
+	full := *alignment
+	full.Size = full.DeviceSize - full.Start
+	aligner = &mockProv.MockWrapAlignment{}
+	aligner.On("GetPartitionAlignment", "/dev/sda1").Return(&full, nil)
+	vm.partitionAlignment.aligner = aligner
+
+	assert.Nil(t, vm.auditPartitionAlignment(testCtx))
+	assert.Eventually(t, func() bool {
+		_ = vm.k8sClient.ReadCR(testCtx, created.Name, testNs, volume)
+		return volume.Annotations[apiV1.VolumeAnnotationAlignmentError] != ""
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Equal(t, apiV1.VolumeAnnotationAlignmentMisaligned, volume.Annotations[apiV1.VolumeAnnotationAlignment])
+	assert.Nil(t, checkAlignmentStatus(volume))
+	assert.Eventually(t, func() bool {
+		return !vm.partitionAlignment.isRealigning()
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Nil(t, vm.auditPartitionAlignment(testCtx))
+	assert.False(t, vm.partitionAlignment.isRealigning())
+	aligner.AssertNotCalled(t, "RealignPartition", &full, mib)
+}
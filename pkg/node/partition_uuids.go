@@ -0,0 +1,82 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+// SetPartitionUUIDReport enables annotation of Drive CRs with GUIDs of partitions found on drives,
+// controller checks them for duplicates across the cluster
+func (m *VolumeManager) SetPartitionUUIDReport() {
+	m.partUUIDReport = true
+}
+
+// reportPartitionUUIDs sets DriveAnnotationPartUUIDs of online drives of the node to sorted GUIDs of their
+// partitions, annotation is removed from drive without partitions. Errors of single drive are logged only
+func (m *VolumeManager) reportPartitionUUIDs(ctx context.Context) error {
+	if !m.partUUIDReport {
+		return nil
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "reportPartitionUUIDs",
+	})
+	drives, err := m.cachedCrHelper.GetDriveCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	for i := range drives {
+		drive := &drives[i]
+		if drive.Spec.Status != apiV1.DriveStatusOnline {
+			continue
+		}
+		dev, err := m.getDriveDevice(drive)
+		if err != nil {
+			ll.Errorf("Unable to read partitions of drive %s: %v", drive.Spec.SerialNumber, err)
+			continue
+		}
+		uuids := make([]string, 0, len(dev.Children))
+		for _, child := range dev.Children {
+			if child.Type == partitionDeviceType && child.PartUUID != "" {
+				uuids = append(uuids, strings.ToLower(child.PartUUID))
+			}
+		}
+		sort.Strings(uuids)
+		reported := strings.Join(uuids, ",")
+		if drive.Annotations[apiV1.DriveAnnotationPartUUIDs] == reported {
+			continue
+		}
+		if reported == "" {
+			delete(drive.Annotations, apiV1.DriveAnnotationPartUUIDs)
+		} else {
+			if drive.Annotations == nil {
+				drive.Annotations = make(map[string]string, 1)
+			}
+			drive.Annotations[apiV1.DriveAnnotationPartUUIDs] = reported
+		}
+		if err = m.k8sClient.UpdateCR(ctx, drive); err != nil {
+			ll.Errorf("Unable to update Drive CR %s: %v", drive.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+func TestVolumeManager_reportPartitionUUIDs(t *testing.T) {
+	var (
+		vm      = prepareSuccessVolumeManager(t)
+		listBlk = &mocklu.MockWrapLsblk{}
+		drive   = testDriveCR.DeepCopy()
+		device  = "/dev/sda"
+		read    = &drivecrd.Drive{}
+	)
+	vm.listBlk = listBlk
+	addDriveCRs(vm.k8sClient, drive)
+	listBlk.On("SearchDrivePath", mock.Anything).Return(device, nil)
+	listBlk.On("GetBlockDevices", device).Return([]lsblk.BlockDevice{{Name: device, Children: []lsblk.BlockDevice{
+		{Name: device + "2", Type: partitionDeviceType, PartUUID: "BBBB"},
+		{Name: device + "1", Type: partitionDeviceType, PartUUID: "AAAA"},
+	}}}, nil).Once()
+
+	// disabled
+	assert.Nil(t, vm.reportPartitionUUIDs(testCtx))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, drive.Name, "", read))
+	assert.Empty(t, read.Annotations[apiV1.DriveAnnotationPartUUIDs])
+
+	vm.SetPartitionUUIDReport()
+	assert.Nil(t, vm.reportPartitionUUIDs(testCtx))
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, drive.Name, "", read))
+	assert.Equal(t, "aaaa,bbbb", read.Annotations[apiV1.DriveAnnotationPartUUIDs])
+
+	// partitions were removed
+	listBlk.On("GetBlockDevices", device).Return([]lsblk.BlockDevice{{Name: device}}, nil)
+	assert.Nil(t, vm.reportPartitionUUIDs(testCtx))
+	// decoding into the existing object keeps keys of its annotations map
+	read = &drivecrd.Drive{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, drive.Name, "", read))
+	_, ok := read.Annotations[apiV1.DriveAnnotationPartUUIDs]
+	assert.False(t, ok)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+)
+
+// Policies of handling of partition which can't be released because it is held by another device or mount
+const (
+	// BusyPartitionPolicyReport fails release with holders chain in error
+	BusyPartitionPolicyReport = "report"
+	// BusyPartitionPolicyRemove removes holders chain and retries release once
+	BusyPartitionPolicyRemove = "remove"
+)
+
+// ErrPartitionBusy is returned when partition of released volume is held by leftover device or mount
+var ErrPartitionBusy = errors.New("partition is busy")
+
+// SetBusyPartitionPolicy sets policy of handling of volume partition which is held by leftover device mapper,
+// bcache or md device or mount point during volume release
+func (d *DriveProvisioner) SetBusyPartitionPolicy(policy string) error {
+	if policy != BusyPartitionPolicyReport && policy != BusyPartitionPolicyRemove {
+		return fmt.Errorf("busy partition policy %s isn't supported, expected %s or %s",
+			policy, BusyPartitionPolicyReport, BusyPartitionPolicyRemove)
+	}
+	d.busyPartitionPolicy = policy
+	return nil
+}
+
+// releaseHolders handles failed release of partition. If partition has holders they are reported in error
+// or removed depending on policy. Returns cause if partition has no holders
+// Returns nil if holders were removed and release might be retried
+func (d *DriveProvisioner) releaseHolders(partition string, cause error) error {
+	ll := d.log.WithFields(logrus.Fields{
+		"method":    "releaseHolders",
+		"partition": partition,
+	})
+
+	holders, err := d.holders.GetHolders(partition)
+	if err != nil {
+		ll.Warnf("Unable to get holders: %v", err)
+		return cause
+	}
+	if len(holders) == 0 {
+		return cause
+	}
+	chain := ph.FormatHolders(holders)
+	if d.busyPartitionPolicy != BusyPartitionPolicyRemove {
+		return fmt.Errorf("%w: %s is held by %s, remove holders manually: %v", ErrPartitionBusy, partition, chain, cause)
+	}
+
+	ll.Warnf("Partition is held by %s, remove holders", chain)
+	for _, holder := range holders {
+		if err = d.holders.RemoveHolder(holder); err != nil {
+			return fmt.Errorf("%w: %s is held by %s: %v", ErrPartitionBusy, partition, chain, err)
+		}
+		ll.Infof("Holder %s was removed", holder)
+	}
+	return nil
+}
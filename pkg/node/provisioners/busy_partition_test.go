@@ -0,0 +1,88 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+)
+
+func TestDriveProvisioner_releaseHolders(t *testing.T) {
+	var (
+		dp, _, _, _ = setupTestDriveProvisioner()
+		holders     = &mockProv.MockHolders{}
+		partition   = "/dev/sda1"
+		dmHolder    = ph.Holder{Type: ph.HolderTypeDM, Name: "dm-3", Alias: "vg-lv"}
+		mountHolder = ph.Holder{Type: ph.HolderTypeMount, Name: "/mnt/old"}
+	)
+	dp.holders = holders
+	assert.NotNil(t, dp.SetBusyPartitionPolicy("ignore"))
+
+	// holders aren't found
+	holders.On("GetHolders", "/dev/sdb1").Return(nil, errTest).Once()
+	assert.Equal(t, errTest, dp.releaseHolders("/dev/sdb1", errTest))
+	holders.On("GetHolders", "/dev/sdb1").Return([]ph.Holder{}, nil).Once()
+	assert.Equal(t, errTest, dp.releaseHolders("/dev/sdb1", errTest))
+
+	// report
+	holders.On("GetHolders", partition).Return([]ph.Holder{mountHolder, dmHolder}, nil)
+	err := dp.releaseHolders(partition, errTest)
+	assert.True(t, errors.Is(err, ErrPartitionBusy))
+	assert.Contains(t, err.Error(), "mount /mnt/old <- dm dm-3 (vg-lv)")
+	holders.AssertNotCalled(t, "RemoveHolder", dmHolder)
+
+	// remove
+	assert.Nil(t, dp.SetBusyPartitionPolicy(BusyPartitionPolicyRemove))
+	holders.On("RemoveHolder", mountHolder).Return(nil).Once()
+	holders.On("RemoveHolder", dmHolder).Return(nil).Once()
+	assert.Nil(t, dp.releaseHolders(partition, errTest))
+
+	holders.On("RemoveHolder", mountHolder).Return(errTest).Once()
+	err = dp.releaseHolders(partition, errTest)
+	assert.True(t, errors.Is(err, ErrPartitionBusy))
+}
+
+func TestDriveProvisioner_ReleaseVolume_BusyPartition(t *testing.T) {
+	var (
+		dp, mockLsblk, mockPH, mockFS = setupTestDriveProvisioner()
+		holders                       = &mockProv.MockHolders{}
+		device                        = "/dev/sdw"
+		partition                     = device + "1"
+		dmHolder                      = ph.Holder{Type: ph.HolderTypeDM, Name: "dm-3"}
+	)
+	dp.holders = holders
+	assert.Nil(t, dp.SetBusyPartitionPolicy(BusyPartitionPolicyRemove))
+
+	mockLsblk.On("SearchDrivePath", &testDriveCR.Spec).Return(device, nil)
+	mockPH.On("SearchPartName", device, testVolume2.Id).Return("1")
+	mockFS.On("WipeFS", partition).Return(errTest).Once()
+	holders.On("GetHolders", partition).Return([]ph.Holder{dmHolder}, nil).Once()
+	holders.On("RemoveHolder", dmHolder).Return(nil).Once()
+	// release is retried after holders removal
+	mockFS.On("WipeFS", partition).Return(nil).Once()
+	mockPH.On("ReleasePartition", mock.Anything).Return(nil).Once()
+	mockFS.On("WipeFS", device).Return(nil).Once()
+
+	assert.Nil(t, dp.ReleaseVolume(&testVolume2, &testDriveCR.Spec))
+	holders.AssertCalled(t, "RemoveHolder", dmHolder)
+}
@@ -0,0 +1,265 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lvm"
+)
+
+const (
+	// MakeBcacheCmdTmpl formats backing and cache devices and attaches them, fill backing and cache device
+	MakeBcacheCmdTmpl = "make-bcache --wipe-bcache -B %s -C %s"
+	// BcacheRegisterPath is a sysfs file to register bcache device in kernel
+	BcacheRegisterPath = "/sys/fs/bcache/register"
+	// cacheLVSuffix is a suffix of name of LV which holds cache of volume
+	cacheLVSuffix = "-cache"
+	// bcacheStopAttempts is a number of checks that bcache device disappeared after stop
+	bcacheStopAttempts = 10
+)
+
+// bcacheStopInterval is an interval between checks that bcache device disappeared after stop, variable for tests
+var bcacheStopInterval = 500 * time.Millisecond
+
+// errCacheTierNotConfigured is returned for volume with cache mode if cache volume group isn't set on node
+var errCacheTierNotConfigured = errors.New("cache tier isn't configured on node")
+
+// CacheStats is a state of cache of cached volume read from bcache sysfs
+type CacheStats struct {
+	// State is clean, dirty, inconsistent or no cache
+	State string
+	// HitRatio is a cache hit ratio in percents since device was registered
+	HitRatio string
+	// DirtyData is an amount of cached data which isn't written to backing device yet
+	DirtyData string
+}
+
+// CacheTier fronts volume partitions on HDD by bcache devices which cache is located in LV of SSD volume group
+type CacheTier struct {
+	vg     string
+	e      command.CmdExecutor
+	lvmOps lvm.WrapLVM
+	sysfs  *devicegraph.SysfsReader
+	// registerPath is BcacheRegisterPath, field for tests
+	registerPath string
+
+	log *logrus.Entry
+}
+
+// NewCacheTier is a constructor for CacheTier which creates cache LVs in volume group vg
+func NewCacheTier(vg string, e command.CmdExecutor, log *logrus.Logger) *CacheTier {
+	return &CacheTier{
+		vg:           vg,
+		e:            e,
+		lvmOps:       lvm.NewLVM(e, log),
+		sysfs:        devicegraph.NewSysfsReader(),
+		registerPath: BcacheRegisterPath,
+		log:          log.WithField("component", "CacheTier"),
+	}
+}
+
+// Attach creates cache LV of size bytes for volume and bcache device on top of partition and cache LV
+// Returns path of bcache device, existing bcache device is returned if partition is cached already
+func (c *CacheTier) Attach(volumeID, partition string, size int64) (string, error) {
+	ll := c.log.WithFields(logrus.Fields{
+		"method":   "Attach",
+		"volumeID": volumeID,
+	})
+
+	if device, err := c.Device(partition); err != nil || device != "" {
+		return device, err
+	}
+
+	lv := volumeID + cacheLVSuffix
+	ll.Infof("Creating cache LV %s of size %d bytes in VG %s", lv, size, c.vg)
+	if err := c.lvmOps.LVCreate(lv, fmt.Sprintf("%db", size), c.vg); err != nil {
+		return "", fmt.Errorf("unable to create cache LV in VG %s: %v", c.vg, err)
+	}
+	cacheDevice := c.lvPath(volumeID)
+	cmd := command.NewCmd(MakeBcacheCmdTmpl, command.Device(partition), command.Device(cacheDevice))
+	if _, stderr, err := c.e.RunCmd(cmd,
+		command.UseMetrics(true),
+		command.CmdName(strings.TrimSpace(fmt.Sprintf(MakeBcacheCmdTmpl, "", "")))); err != nil {
+		return "", fmt.Errorf("unable to create bcache on %s and %s: %s, error: %v", partition, cacheDevice,
+			stderr, err)
+	}
+	// devices are registered by udev usually, registration of registered device fails
+	for _, dev := range []string{partition, cacheDevice} {
+		if err := ioutil.WriteFile(c.registerPath, []byte(dev), 0200); err != nil {
+			ll.Debugf("Unable to register %s: %v", dev, err)
+		}
+	}
+
+	device, err := c.Device(partition)
+	if err == nil && device == "" {
+		err = fmt.Errorf("bcache device on top of %s isn't found", partition)
+	}
+	if err != nil {
+		return "", err
+	}
+	ll.Infof("Partition %s is cached by %s, bcache device %s", partition, cacheDevice, device)
+	return device, nil
+}
+
+// Detach stops bcache device on top of partition and removes cache LV of volume
+func (c *CacheTier) Detach(volumeID, partition string) error {
+	ll := c.log.WithFields(logrus.Fields{
+		"method":   "Detach",
+		"volumeID": volumeID,
+	})
+
+	device, err := c.Device(partition)
+	if err != nil {
+		return err
+	}
+	if device != "" {
+		// dirty data is written to backing device before bcache device disappears
+		stop := filepath.Join(c.sysfs.SysClassBlock, filepath.Base(device), "bcache", "stop")
+		if err = ioutil.WriteFile(stop, []byte("1"), 0200); err != nil {
+			return fmt.Errorf("unable to stop bcache device %s: %w", device, err)
+		}
+		if err = c.waitStopped(partition); err != nil {
+			return err
+		}
+		ll.Infof("Bcache device %s was stopped", device)
+	}
+	if cacheDevice, err := filepath.EvalSymlinks(c.lvPath(volumeID)); err == nil {
+		// cache set is stopped after the last backing device is detached
+		stop := filepath.Join(c.sysfs.SysClassBlock, filepath.Base(cacheDevice), "bcache", "set", "stop")
+		if err = ioutil.WriteFile(stop, []byte("1"), 0200); err != nil {
+			ll.Debugf("Unable to stop cache set of %s: %v", cacheDevice, err)
+		}
+	}
+	return c.lvmOps.LVRemove(c.lvPath(volumeID))
+}
+
+// Device returns path of bcache device on top of partition, empty string if partition isn't cached
+func (c *CacheTier) Device(partition string) (string, error) {
+	graph, err := c.sysfs.Read()
+	if err != nil {
+		return "", err
+	}
+	for _, dev := range graph.Above(partition) {
+		if dev.Type == devicegraph.TypeBcache {
+			return dev.Path(), nil
+		}
+	}
+	return "", nil
+}
+
+// Stats returns state, hit ratio and dirty data of bcache device
+func (c *CacheTier) Stats(device string) (*CacheStats, error) {
+	dir := filepath.Join(c.sysfs.SysClassBlock, filepath.Base(device), "bcache")
+	read := func(name string) (string, error) {
+		value, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "", fmt.Errorf("unable to read bcache stats of %s: %w", device, err)
+		}
+		return strings.TrimSpace(string(value)), nil
+	}
+	var (
+		stats = &CacheStats{}
+		err   error
+	)
+	if stats.State, err = read("state"); err != nil {
+		return nil, err
+	}
+	if stats.HitRatio, err = read(filepath.Join("stats_total", "cache_hit_ratio")); err != nil {
+		return nil, err
+	}
+	if stats.DirtyData, err = read("dirty_data"); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// IsHealthy returns true if bcache device has attached consistent cache
+func (s *CacheStats) IsHealthy() bool {
+	return s.State == "clean" || s.State == "dirty"
+}
+
+// waitStopped waits until bcache device on top of partition disappears
+func (c *CacheTier) waitStopped(partition string) error {
+	for i := 0; i < bcacheStopAttempts; i++ {
+		device, err := c.Device(partition)
+		if err != nil {
+			return err
+		}
+		if device == "" {
+			return nil
+		}
+		time.Sleep(bcacheStopInterval)
+	}
+	return fmt.Errorf("bcache device on top of %s wasn't stopped in %s", partition,
+		bcacheStopInterval*bcacheStopAttempts)
+}
+
+// lvPath returns path of cache LV of volume
+func (c *CacheTier) lvPath(volumeID string) string {
+	return fmt.Sprintf("/dev/%s/%s%s", c.vg, volumeID, cacheLVSuffix)
+}
+
+// SetCacheTier enables caching of volumes with cache mode
+func (d *DriveProvisioner) SetCacheTier(cacheTier *CacheTier) {
+	d.cacheTier = cacheTier
+}
+
+// attachCache fronts volume partition by bcache device and returns path of bcache device
+func (d *DriveProvisioner) attachCache(vol *api.Volume, partition string) (string, error) {
+	if d.cacheTier == nil {
+		return "", fmt.Errorf("unable to cache volume %s: %w", vol.Id, errCacheTierNotConfigured)
+	}
+	device, err := d.cacheTier.Attach(vol.Id, partition, vol.CacheSize)
+	if err != nil {
+		return "", fmt.Errorf("unable to cache volume %s: %w", vol.Id, err)
+	}
+	return device, nil
+}
+
+// detachCache stops bcache device on top of volume partition and removes cache of volume
+func (d *DriveProvisioner) detachCache(vol *api.Volume, partition string) error {
+	if d.cacheTier == nil {
+		return fmt.Errorf("unable to remove cache of volume %s: %w", vol.Id, errCacheTierNotConfigured)
+	}
+	if err := d.cacheTier.Detach(vol.Id, partition); err != nil {
+		return fmt.Errorf("unable to remove cache of volume %s: %w", vol.Id, err)
+	}
+	return nil
+}
+
+// cacheDevice returns path of bcache device on top of volume partition
+func (d *DriveProvisioner) cacheDevice(partition string) (string, error) {
+	if d.cacheTier == nil {
+		return "", errCacheTierNotConfigured
+	}
+	device, err := d.cacheTier.Device(partition)
+	if err == nil && device == "" {
+		err = fmt.Errorf("bcache device on top of %s isn't found", partition)
+	}
+	return device, err
+}
@@ -0,0 +1,152 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+// prepareCacheTier creates CacheTier with sysfs tree: sdw1 <- bcache0, sdx1 isn't cached
+func prepareCacheTier(t *testing.T, cmds map[string]mocks.CmdOut) (*CacheTier, *mocklu.MockWrapLVM) {
+	root := t.TempDir()
+	writeFile := func(path, content string) {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0700))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(root, path), []byte(content), 0600))
+	}
+	writeFile("sdw1/dev", "8:1\n")
+	writeFile("sdw1/holders/bcache0", "")
+	writeFile("bcache0/dev", "252:0\n")
+	writeFile("bcache0/bcache/state", "dirty\n")
+	writeFile("bcache0/bcache/stats_total/cache_hit_ratio", "87\n")
+	writeFile("bcache0/bcache/dirty_data", "1.5M\n")
+	writeFile("bcache0/bcache/stop", "")
+	writeFile("sdx1/dev", "8:17\n")
+	writeFile("mountinfo", "")
+	writeFile("register", "")
+
+	lvmOps := &mocklu.MockWrapLVM{}
+	c := NewCacheTier("cache-vg", mocks.NewMockExecutor(cmds), testLogger)
+	c.lvmOps = lvmOps
+	c.sysfs = &devicegraph.SysfsReader{SysClassBlock: root, MountInfo: filepath.Join(root, "mountinfo")}
+	c.registerPath = filepath.Join(root, "register")
+	return c, lvmOps
+}
+
+func TestCacheTier_Device(t *testing.T) {
+	c, _ := prepareCacheTier(t, nil)
+
+	device, err := c.Device("/dev/sdw1")
+	assert.Nil(t, err)
+	assert.Equal(t, "/dev/bcache0", device)
+
+	device, err = c.Device("/dev/sdx1")
+	assert.Nil(t, err)
+	assert.Equal(t, "", device)
+}
+
+func TestCacheTier_Attach(t *testing.T) {
+	volumeID := "pvc-1"
+	c, lvmOps := prepareCacheTier(t, map[string]mocks.CmdOut{
+		fmt.Sprintf(MakeBcacheCmdTmpl, "/dev/sdx1", "/dev/cache-vg/pvc-1-cache"): mocks.EmptyOutSuccess,
+		fmt.Sprintf(MakeBcacheCmdTmpl, "/dev/sdy1", "/dev/cache-vg/pvc-1-cache"): mocks.EmptyOutFail,
+	})
+
+	// partition is cached already
+	device, err := c.Attach(volumeID, "/dev/sdw1", 1024)
+	assert.Nil(t, err)
+	assert.Equal(t, "/dev/bcache0", device)
+	lvmOps.AssertNotCalled(t, "LVCreate", volumeID+cacheLVSuffix, "1024b", "cache-vg")
+
+	// bcache device doesn't appear in sysfs
+	lvmOps.On("LVCreate", volumeID+cacheLVSuffix, "1024b", "cache-vg").Return(nil)
+	_, err = c.Attach(volumeID, "/dev/sdx1", 1024)
+	assert.NotNil(t, err)
+	lvmOps.AssertCalled(t, "LVCreate", volumeID+cacheLVSuffix, "1024b", "cache-vg")
+
+	_, err = c.Attach(volumeID, "/dev/sdy1", 1024)
+	assert.NotNil(t, err)
+
+	lvmOps.On("LVCreate", "pvc-2"+cacheLVSuffix, "1024b", "cache-vg").Return(errTest)
+	_, err = c.Attach("pvc-2", "/dev/sdx1", 1024)
+	assert.NotNil(t, err)
+}
+
+func TestCacheTier_Detach(t *testing.T) {
+	bcacheStopInterval = time.Millisecond
+	defer func() { bcacheStopInterval = 500 * time.Millisecond }()
+	c, lvmOps := prepareCacheTier(t, nil)
+	lvmOps.On("LVRemove", "/dev/cache-vg/pvc-1-cache").Return(nil)
+
+	assert.Nil(t, c.Detach("pvc-1", "/dev/sdx1"))
+	lvmOps.AssertCalled(t, "LVRemove", "/dev/cache-vg/pvc-1-cache")
+
+	// bcache device isn't stopped since sysfs isn't changed
+	assert.NotNil(t, c.Detach("pvc-1", "/dev/sdw1"))
+	stop, err := ioutil.ReadFile(filepath.Join(c.sysfs.SysClassBlock, "bcache0", "bcache", "stop"))
+	assert.Nil(t, err)
+	assert.Equal(t, "1", string(stop))
+}
+
+func TestCacheTier_Stats(t *testing.T) {
+	c, _ := prepareCacheTier(t, nil)
+
+	stats, err := c.Stats("/dev/bcache0")
+	assert.Nil(t, err)
+	assert.Equal(t, &CacheStats{State: "dirty", HitRatio: "87", DirtyData: "1.5M"}, stats)
+	assert.True(t, stats.IsHealthy())
+	assert.False(t, (&CacheStats{State: "no cache"}).IsHealthy())
+
+	_, err = c.Stats("/dev/bcache1")
+	assert.NotNil(t, err)
+}
+
+func TestDriveProvisioner_cache(t *testing.T) {
+	var (
+		dp, _, _, _ = setupTestDriveProvisioner()
+		vol         = &api.Volume{Id: "pvc-1", CacheMode: "bcache", CacheSize: 1024}
+	)
+
+	_, err := dp.attachCache(vol, "/dev/sdw1")
+	assert.True(t, errors.Is(err, errCacheTierNotConfigured))
+	assert.True(t, errors.Is(dp.detachCache(vol, "/dev/sdw1"), errCacheTierNotConfigured))
+	_, err = dp.cacheDevice("/dev/sdw1")
+	assert.True(t, errors.Is(err, errCacheTierNotConfigured))
+
+	c, _ := prepareCacheTier(t, nil)
+	dp.SetCacheTier(c)
+	device, err := dp.attachCache(vol, "/dev/sdw1")
+	assert.Nil(t, err)
+	assert.Equal(t, "/dev/bcache0", device)
+	device, err = dp.cacheDevice("/dev/sdw1")
+	assert.Nil(t, err)
+	assert.Equal(t, "/dev/bcache0", device)
+	_, err = dp.cacheDevice("/dev/sdx1")
+	assert.NotNil(t, err)
+}
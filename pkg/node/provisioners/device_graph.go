@@ -0,0 +1,62 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
+)
+
+// bcacheDevicePrefix is a prefix of path of bcache devices
+const bcacheDevicePrefix = "/dev/bcache"
+
+// ErrDeviceInUse is returned when drive device is used by another device or mounted outside of CSI
+var ErrDeviceInUse = errors.New("device is in use")
+
+// SetDeviceGraph enables check that drive device isn't used by other devices (LVM, software RAID, bcache)
+// or mounted before volume is created on it
+func (d *DriveProvisioner) SetDeviceGraph(graph devicegraph.Reader) {
+	d.graph = graph
+}
+
+// checkDeviceNotUsed returns ErrDeviceInUse with users of device if device or its partitions are held or mounted
+// Device which isn't found in sysfs isn't checked
+func (d *DriveProvisioner) checkDeviceNotUsed(device string, vol *api.Volume) error {
+	if d.graph == nil {
+		return nil
+	}
+	graph, err := d.graph.Read()
+	if err != nil {
+		return fmt.Errorf("unable to read device graph: %w", err)
+	}
+	var users []string
+	for _, user := range graph.Users(device) {
+		// bcache device of cached volume might be created by previous attempt of volume creation
+		if vol.CacheMode != "" && strings.HasPrefix(user, bcacheDevicePrefix) {
+			continue
+		}
+		users = append(users, user)
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("%w: %s is used by %s", ErrDeviceInUse, device, strings.Join(users, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
+)
+
+// graphReader returns predefined graph of devices
+type graphReader struct {
+	graph *devicegraph.Graph
+	err   error
+}
+
+func (r *graphReader) Read() (*devicegraph.Graph, error) {
+	return r.graph, r.err
+}
+
+func TestDriveProvisioner_PrepareVolume_DeviceInUse(t *testing.T) {
+	var (
+		dp, mockLsblk, mockPH, _ = setupTestDriveProvisioner()
+		device                   = "/dev/sdw"
+		sdw                      = &devicegraph.Device{Name: "sdw", Type: devicegraph.TypeDisk}
+		sdw1                     = &devicegraph.Device{Name: "sdw1", Type: devicegraph.TypePart, Parent: sdw}
+		dm                       = &devicegraph.Device{Name: "dm-1", Type: devicegraph.TypeDM}
+	)
+	sdw.Partitions, sdw1.Holders = []*devicegraph.Device{sdw1}, []*devicegraph.Device{dm}
+	assert.Nil(t, dp.k8sClient.CreateCR(testCtx, testDriveCR.Name, testDriveCR.DeepCopy()))
+	mockLsblk.On("SearchDrivePath", &testDriveCR.Spec).Return(device, nil)
+
+	dp.SetDeviceGraph(&graphReader{graph: devicegraph.NewGraph(sdw, sdw1, dm)})
+	err := dp.PrepareVolume(&testVolume2)
+	assert.True(t, errors.Is(err, ErrDeviceInUse))
+	assert.Contains(t, err.Error(), "/dev/dm-1")
+	mockPH.AssertNotCalled(t, "PreparePartition")
+
+	dp.SetDeviceGraph(&graphReader{err: errTest})
+	assert.NotNil(t, dp.PrepareVolume(&testVolume2))
+}
+
+func TestDriveProvisioner_checkDeviceNotUsed(t *testing.T) {
+	var (
+		dp, _, _, _ = setupTestDriveProvisioner()
+		sdw         = &devicegraph.Device{Name: "sdw", Type: devicegraph.TypeDisk}
+		sdw1        = &devicegraph.Device{Name: "sdw1", Type: devicegraph.TypePart, Parent: sdw}
+	)
+	sdw.Partitions = []*devicegraph.Device{sdw1}
+
+	// check is disabled
+	assert.Nil(t, dp.checkDeviceNotUsed("/dev/sdw", &testVolume2))
+
+	dp.SetDeviceGraph(&graphReader{graph: devicegraph.NewGraph(sdw, sdw1)})
+	// partitions don't use device
+	assert.Nil(t, dp.checkDeviceNotUsed("/dev/sdw", &testVolume2))
+	// device isn't found in sysfs
+	assert.Nil(t, dp.checkDeviceNotUsed("/some/device", &testVolume2))
+
+	// bcache device of cached volume
+	bcache := &devicegraph.Device{Name: "bcache0", Type: devicegraph.TypeBcache}
+	sdw1.Holders = []*devicegraph.Device{bcache}
+	dp.SetDeviceGraph(&graphReader{graph: devicegraph.NewGraph(sdw, sdw1, bcache)})
+	assert.True(t, errors.Is(dp.checkDeviceNotUsed("/dev/sdw", &testVolume2), ErrDeviceInUse))
+	assert.Nil(t, dp.checkDeviceNotUsed("/dev/sdw", &api.Volume{Id: testVolume2.Id, CacheMode: "bcache"}))
+
+	sdw1.MountPoints = []string{"/mnt/sdw1"}
+	assert.True(t, errors.Is(dp.checkDeviceNotUsed("/dev/sdw", &testVolume2), ErrDeviceInUse))
+}
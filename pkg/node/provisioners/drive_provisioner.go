@@ -29,6 +29,7 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base/command"
 	baseerr "github.com/dell/csi-baremetal/pkg/base/error"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
@@ -51,10 +52,21 @@ type DriveProvisioner struct {
 	fsOps uw.FSOperations
 	// partOps uses for operations with partitions
 	partOps uw.PartitionOperations
+	// holders uses for search and removal of devices and mounts which hold volume partition
+	holders partitionhelper.WrapHolders
 
 	k8sClient *k8s.KubeClient
 	crHelper  *k8s.CRHelper
 
+	// partScheme describes GUID and label of volume partitions, nil means legacy scheme
+	partScheme *PartitionScheme
+	// busyPartitionPolicy is BusyPartitionPolicyReport or BusyPartitionPolicyRemove
+	busyPartitionPolicy string
+	// graph is used to check that device isn't used before volume creation, nil if check is disabled
+	graph devicegraph.Reader
+	// cacheTier fronts partitions of volumes with cache mode by bcache devices, nil if cache tier isn't configured
+	cacheTier *CacheTier
+
 	log *logrus.Entry
 }
 
@@ -67,12 +79,21 @@ func NewDriveProvisioner(
 		listBlk:   lsblk.NewLSBLK(log),
 		fsOps:     uw.NewFSOperationsImpl(e, log),
 		partOps:   uw.NewPartitionOperationsImpl(e, log),
+		holders:   partitionhelper.NewWrapHoldersImpl(e),
 		k8sClient: k,
 		crHelper:  k8s.NewCRHelper(k, log),
-		log:       log.WithField("component", "DriveProvisioner"),
+
+		busyPartitionPolicy: BusyPartitionPolicyReport,
+		log:                 log.WithField("component", "DriveProvisioner"),
 	}
 }
 
+// SetPartitionScheme sets scheme of GUID and label of volume partitions
+// Partitions created with legacy scheme are still found after scheme is changed
+func (d *DriveProvisioner) SetPartitionScheme(scheme *PartitionScheme) {
+	d.partScheme = scheme
+}
+
 // PrepareVolume create partition and FS based on vol attributes.
 // After that partition is ready for mount operations
 func (d *DriveProvisioner) PrepareVolume(vol *api.Volume) error {
@@ -98,18 +119,27 @@ func (d *DriveProvisioner) PrepareVolume(vol *api.Volume) error {
 	if err != nil {
 		return err
 	}
+	if err = d.checkDeviceNotUsed(device, vol); err != nil {
+		return err
+	}
+
+	if IsStandby(drive) {
+		if err = d.useStandbyPartition(ctxWithID, drive, device, vol); err != nil {
+			return err
+		}
+	}
 
 	if vol.Mode == apiV1.ModeRAW {
 		return nil
 	}
 
-	partUUID, _ := util.GetVolumeUUID(vol.Id)
 	part := uw.Partition{
 		Device:    device,
 		TableType: partitionhelper.PartitionGPT,
-		Label:     DefaultPartitionLabel,
+		Label:     d.partLabel(vol),
 		Num:       DefaultPartitionNumber,
-		PartUUID:  partUUID,
+		PartUUID:  d.resolvePartUUID(device, vol.Id),
+		TypeGUID:  d.partScheme.PartTypeGUID(vol.StorageClass),
 		Ephemeral: vol.Ephemeral,
 	}
 
@@ -121,11 +151,34 @@ func (d *DriveProvisioner) PrepareVolume(vol *api.Volume) error {
 	}
 	ll.Infof("Partition was created successfully %+v", partPtr)
 
-	if vol.Mode == apiV1.ModeRAWPART {
+	volumeDevice := partPtr.GetFullPath()
+	if vol.CacheMode != "" {
+		if volumeDevice, err = d.attachCache(vol, volumeDevice); err != nil {
+			return err
+		}
+	}
+
+	// FS of encrypted and lazy formatted volumes is created during NodeStageVolume
+	if vol.Mode == apiV1.ModeRAWPART || vol.Encryption != "" || vol.LazyFormat {
 		return nil
 	}
 
-	return d.fsOps.CreateFSIfNotExist(fs.FileSystem(vol.Type), partPtr.GetFullPath())
+	return d.fsOps.CreateFSIfNotExist(fs.FileSystem(vol.Type), volumeDevice)
+}
+
+// useStandbyPartition claims pre-created partition of standby drive for volume or releases it
+// if volume can't use it, drive stops being standby in both cases
+func (d *DriveProvisioner) useStandbyPartition(ctx context.Context, drive *drivecrd.Drive, device string,
+	vol *api.Volume) error {
+	standby := &StandbyPartition{partOps: d.partOps, fsOps: d.fsOps, partScheme: d.partScheme, log: d.log}
+	claimed, err := standby.Claim(drive, device, vol)
+	if err != nil {
+		return fmt.Errorf("unable to use standby partition of drive %s: %w", drive.Name, err)
+	}
+	d.log.WithField("volumeID", vol.Id).Infof("Standby partition of drive %s claimed: %v", drive.Name, claimed)
+
+	UnmarkStandby(drive)
+	return d.k8sClient.UpdateCR(ctx, drive)
 }
 
 // ReleaseVolume remove FS and partition based on vol attributes.
@@ -144,14 +197,11 @@ func (d *DriveProvisioner) ReleaseVolume(vol *api.Volume, drive *api.Drive) erro
 	}
 	ll.Debugf("Got device %s", device)
 
-	var (
-		partUUID, _ = util.GetVolumeUUID(vol.Id)
-		part        = uw.Partition{
-			Device:   device,
-			Num:      DefaultPartitionNumber,
-			PartUUID: partUUID,
-		}
-	)
+	part := uw.Partition{
+		Device:   device,
+		Num:      DefaultPartitionNumber,
+		PartUUID: d.resolvePartUUID(device, vol.Id),
+	}
 
 	// TODO: temporary solution because of ephemeral volumes volume id - https://github.com/dell/csi-baremetal/issues/87
 	if vol.Ephemeral {
@@ -168,14 +218,30 @@ func (d *DriveProvisioner) ReleaseVolume(vol *api.Volume, drive *api.Drive) erro
 			fmt.Errorf("unable to find partition name for volume %s", vol.Id), ll)
 	}
 
-	// wipe FS on partition
-	if err = d.fsOps.WipeFS(part.GetFullPath()); err != nil {
-		return err
+	if vol.CacheMode != "" {
+		if err = d.detachCache(vol, part.GetFullPath()); err != nil {
+			return err
+		}
 	}
 
-	err = d.partOps.ReleasePartition(part)
-	if err != nil {
-		return fmt.Errorf("unable to release partition: %v", err)
+	release := func() error {
+		// wipe FS on partition
+		if err := d.fsOps.WipeFS(part.GetFullPath()); err != nil {
+			return err
+		}
+		if err := d.partOps.ReleasePartition(part); err != nil {
+			return fmt.Errorf("unable to release partition: %v", err)
+		}
+		return nil
+	}
+	if err = release(); err != nil {
+		// leftover holders of partition (device mapper, bcache, old mount) keep it busy
+		if err = d.releaseHolders(part.GetFullPath(), err); err != nil {
+			return err
+		}
+		if err = release(); err != nil {
+			return err
+		}
 	}
 
 	// wipe all superblocks (wipe partition table signature)
@@ -235,12 +301,50 @@ func (d *DriveProvisioner) GetVolumePath(vol *api.Volume) (string, error) {
 	if vol.Mode == apiV1.ModeRAW {
 		return device, nil
 	}
-	volumeUUID, _ = util.GetVolumeUUID(volumeUUID)
+	if vol.Ephemeral {
+		volumeUUID, _ = util.GetVolumeUUID(volumeUUID)
+	} else {
+		volumeUUID = d.resolvePartUUID(device, vol.Id)
+	}
 
 	partNum := d.partOps.SearchPartName(device, volumeUUID)
 	if partNum == "" {
 		// on device disconnect or node reboot device name might change and we need to re-sync drive info
 		return "", fmt.Errorf("unable to find part name for device %s by uuid %s", device, volumeUUID)
 	}
+	if vol.CacheMode != "" {
+		return d.cacheDevice(device + partNum)
+	}
 	return device + partNum, nil
 }
+
+// resolvePartUUID returns GUID of volume partition on device. If partition scheme isn't legacy, GUID of existing
+// partition is matched against GUIDs of current and legacy schemes, GUID of current scheme is returned otherwise
+func (d *DriveProvisioner) resolvePartUUID(device, volumeID string) string {
+	candidates := d.partScheme.PartUUIDCandidates(volumeID)
+	if len(candidates) == 0 {
+		return ""
+	}
+	if len(candidates) > 1 {
+		if currUUID, err := d.partOps.GetPartitionUUID(device, DefaultPartitionNumber); err == nil {
+			if matched := MatchPartUUID(candidates, currUUID); matched != "" {
+				return matched
+			}
+		}
+	}
+	return candidates[0]
+}
+
+// partLabel returns label of volume partition, namespace of volume is read from Volume CR if label contains its hash
+func (d *DriveProvisioner) partLabel(vol *api.Volume) string {
+	var namespace string
+	if d.partScheme != nil && d.partScheme.NamespaceHash {
+		volumeCR, err := d.crHelper.GetVolumeByID(vol.Id)
+		if err != nil {
+			d.log.WithField("volumeID", vol.Id).Warnf("Unable to read Volume CR to get namespace: %v", err)
+		} else {
+			namespace = volumeCR.Namespace
+		}
+	}
+	return d.partScheme.PartLabel(namespace)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package provisioners
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -28,22 +29,33 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lvm"
+	"github.com/dell/csi-baremetal/pkg/base/units"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	uw "github.com/dell/csi-baremetal/pkg/node/provisioners/utilwrappers"
 )
 
+// XFSGenerateUUIDCmdTmpl sets new UUID of xfs, xfs with the same UUID as mounted one can't be mounted
+const XFSGenerateUUIDCmdTmpl = "xfs_admin -U generate %s"
+
+// errTemplatesNotConfigured is returned for volume with template if template volume group isn't set on node
+var errTemplatesNotConfigured = errors.New("template volumes aren't configured on node")
+
 // LVMProvisioner is a implementation of Provisioner interface
 // Work with volumes based on Volume Groups
 type LVMProvisioner struct {
+	e        command.CmdExecutor
 	lvmOps   lvm.WrapLVM
 	fsOps    uw.FSOperations
 	crHelper *k8s.CRHelper
-	log      *logrus.Entry
+	// volume group with thin pool where template LVs are located, empty if template volumes are disabled
+	templateVG string
+	log        *logrus.Entry
 }
 
 // NewLVMProvisioner is a constructor for LVMProvisioner
 func NewLVMProvisioner(e command.CmdExecutor, k *k8s.KubeClient, log *logrus.Logger) *LVMProvisioner {
 	return &LVMProvisioner{
+		e:        e,
 		lvmOps:   lvm.NewLVM(e, log),
 		fsOps:    uw.NewFSOperationsImpl(e, log),
 		crHelper: k8s.NewCRHelper(k, log),
@@ -51,6 +63,12 @@ func NewLVMProvisioner(e command.CmdExecutor, k *k8s.KubeClient, log *logrus.Log
 	}
 }
 
+// SetTemplateVolumeGroup enables template volumes: volumes with template are created as thin snapshots
+// of template LVs of volume group vg
+func (l *LVMProvisioner) SetTemplateVolumeGroup(vg string) {
+	l.templateVG = vg
+}
+
 // PrepareVolume search volume group based on vol attributes, creates Logical Volume
 // and create file system on it. After that Logical Volume is ready for mount operations
 func (l *LVMProvisioner) PrepareVolume(vol *api.Volume) error {
@@ -65,16 +83,18 @@ func (l *LVMProvisioner) PrepareVolume(vol *api.Volume) error {
 		err    error
 	)
 
-	// prepare size in megabytes for the argument
-	size, _ := util.ToSizeUnit(vol.Size, util.BYTE, util.MBYTE)
-	sizeStr := strconv.FormatInt(size, 10)
-	sizeStr += "m"
+	// prepare size in megabytes for the argument, it is rounded up since LV mustn't be smaller than volume
+	sizeStr := strconv.FormatInt(units.ToUnitCeil(vol.Size, units.MiB), 10) + "m"
 
 	vgName, err = l.getVGName(vol)
 	if err != nil {
 		return err
 	}
 
+	if vol.Template != "" {
+		return l.prepareTemplateVolume(ll, vol, vgName)
+	}
+
 	// create lv with name /dev/VG_NAME/vol.Id
 	ll.Infof("Creating LV %s sizeof %s in VG %s", vol.Id, sizeStr, vgName)
 	if err = l.lvmOps.LVCreate(vol.Id, sizeStr, vgName); err != nil {
@@ -83,9 +103,43 @@ func (l *LVMProvisioner) PrepareVolume(vol *api.Volume) error {
 
 	deviceFile := fmt.Sprintf("/dev/%s/%s", vgName, vol.Id)
 	ll.Debugf("Creating FS on %s", deviceFile)
+	// FS of encrypted and lazy formatted volumes is created during NodeStageVolume
+	if vol.Mode == apiV1.ModeRAW || vol.Mode == apiV1.ModeRAWPART || vol.Encryption != "" || vol.LazyFormat {
+		return nil
+	}
+	return l.fsOps.CreateFSIfNotExist(fs.FileSystem(vol.Type), deviceFile)
+}
+
+// prepareTemplateVolume creates volume as thin snapshot of template LV and extends it up to volume size,
+// volume has content of template
+func (l *LVMProvisioner) prepareTemplateVolume(ll *logrus.Entry, vol *api.Volume, vgName string) error {
+	ll.Infof("Creating LV %s as snapshot of template %s in VG %s", vol.Id, vol.Template, vgName)
+	if err := l.lvmOps.LVCreateThinSnapshot(vol.Id, vol.Template, vgName); err != nil {
+		return fmt.Errorf("unable to create LV from template %s: %v", vol.Template, err)
+	}
+
+	deviceFile := fmt.Sprintf("/dev/%s/%s", vgName, vol.Id)
+	// snapshots of the same template share xfs UUID
+	if fsType, err := l.fsOps.GetFSType(deviceFile); err == nil && fs.FileSystem(fsType) == fs.XFS {
+		if _, _, err := l.e.RunCmd(command.NewCmd(XFSGenerateUUIDCmdTmpl, command.Device(deviceFile))); err != nil {
+			return fmt.Errorf("unable to set UUID of xfs on %s: %v", deviceFile, err)
+		}
+	}
+
+	size, err := l.lvmOps.GetLVSize(deviceFile)
+	if err != nil {
+		return err
+	}
+	if size < vol.Size {
+		ll.Infof("Extending LV %s from template size %d to %d", deviceFile, size, vol.Size)
+		if err := l.lvmOps.ExpandLV(deviceFile, vol.Size); err != nil {
+			return err
+		}
+	}
 	if vol.Mode == apiV1.ModeRAW || vol.Mode == apiV1.ModeRAWPART {
 		return nil
 	}
+	// file system is created if template is empty, file system of template must have type of volume
 	return l.fsOps.CreateFSIfNotExist(fs.FileSystem(vol.Type), deviceFile)
 }
 
@@ -145,6 +199,14 @@ func (l *LVMProvisioner) GetVolumePath(vol *api.Volume) (string, error) {
 func (l *LVMProvisioner) getVGName(vol *api.Volume) (string, error) {
 	var vgName = vol.Location
 
+	// volumes created from template are located in VG of templates
+	if vol.Template != "" {
+		if l.templateVG == "" {
+			return "", errTemplatesNotConfigured
+		}
+		return l.templateVG, nil
+	}
+
 	// Volume.Location is an LVG CR name, LVG CR name in general is the same as a real VG name on node,
 	// however for LVG based on system disk LVG CR name is not the same as a VG name
 	// we need to read appropriate LVG CR and use LVG CR.Spec.Name as VG name
@@ -28,6 +28,7 @@ import (
 	"github.com/dell/csi-baremetal/pkg/base/command"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	"github.com/dell/csi-baremetal/pkg/mocks"
 	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
 	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
 )
@@ -202,3 +203,49 @@ func TestLVMProvisioner_getVGName_Success(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, testVolume1.Location, vgName)
 }
+
+func TestLVMProvisioner_PrepareVolume_Template(t *testing.T) {
+	setupTestLVMProvisioner()
+	e := &mocks.GoMockExecutor{}
+	lp.e = e
+
+	vol := testVolume1
+	vol.Template = "ubuntu-20.04"
+	vol.Size = 20 * 1024 * 1024 * 1024
+	devFile := fmt.Sprintf("/dev/%s/%s", "templates", vol.Id)
+
+	// template volume group isn't set
+	assert.Equal(t, errTemplatesNotConfigured, lp.PrepareVolume(&vol))
+
+	lp.SetTemplateVolumeGroup("templates")
+	lvmOps.On("LVCreateThinSnapshot", vol.Id, vol.Template, "templates").Return(nil).Times(1)
+	fsOps.On("GetFSType", devFile).Return(string(fs.XFS), nil).Times(1)
+	e.OnCommand(fmt.Sprintf(XFSGenerateUUIDCmdTmpl, devFile)).Return("", "", nil).Times(1)
+	lvmOps.On("GetLVSize", devFile).Return(vol.Size/2, nil).Times(1)
+	lvmOps.On("ExpandLV", devFile, vol.Size).Return(nil).Times(1)
+	fsOps.On("CreateFSIfNotExist", fs.XFS, devFile).Return(nil).Times(2)
+	assert.Nil(t, lp.PrepareVolume(&vol))
+
+	path, err := lp.GetVolumePath(&vol)
+	assert.Nil(t, err)
+	assert.Equal(t, devFile, path)
+
+	// empty template has size of volume, file system is created
+	lvmOps.On("LVCreateThinSnapshot", vol.Id, vol.Template, "templates").Return(nil).Times(1)
+	fsOps.On("GetFSType", devFile).Return("", nil).Times(1)
+	lvmOps.On("GetLVSize", devFile).Return(vol.Size, nil).Times(1)
+	assert.Nil(t, lp.PrepareVolume(&vol))
+	lvmOps.AssertNumberOfCalls(t, "ExpandLV", 1)
+	fsOps.AssertNumberOfCalls(t, "CreateFSIfNotExist", 2)
+
+	// block volume
+	vol.Mode = apiV1.ModeRAW
+	lvmOps.On("LVCreateThinSnapshot", vol.Id, vol.Template, "templates").Return(nil).Times(1)
+	fsOps.On("GetFSType", devFile).Return("", nil).Times(1)
+	lvmOps.On("GetLVSize", devFile).Return(vol.Size, nil).Times(1)
+	assert.Nil(t, lp.PrepareVolume(&vol))
+	fsOps.AssertNumberOfCalls(t, "CreateFSIfNotExist", 2)
+
+	lvmOps.On("LVCreateThinSnapshot", vol.Id, vol.Template, "templates").Return(errTest).Times(1)
+	assert.NotNil(t, lp.PrepareVolume(&vol))
+}
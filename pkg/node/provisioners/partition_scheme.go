@@ -0,0 +1,225 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+)
+
+const (
+	// maxGUIDPrefixLength is a max length of GUID prefix, prefix replaces the first group of partition GUID at most
+	maxGUIDPrefixLength = 8
+	// maxPartitionLabelLength is a max length of GPT partition name
+	maxPartitionLabelLength = 36
+	// namespaceHashLength is a length of hex encoded hash of volume namespace in partition label
+	namespaceHashLength = 8
+)
+
+var (
+	guidPrefixRegexp     = regexp.MustCompile(`^[0-9a-f]*$`)
+	partitionLabelRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+	typeGUIDRegexp       = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	// typeGUIDClasses are storage classes of volumes which own a partition
+	typeGUIDClasses = []string{apiV1.StorageClassAny, apiV1.StorageClassHDD, apiV1.StorageClassSSD,
+		apiV1.StorageClassNVMe}
+)
+
+// PartitionScheme describes how volume identity is encoded in GUID and label of volume partition.
+// Zero value (and nil) is a legacy scheme: GUID is volume UUID and label is DefaultPartitionLabel
+type PartitionScheme struct {
+	// GUIDPrefix replaces leading hex digits of volume UUID in partition GUID
+	GUIDPrefix string
+	// Label is a partition label, DefaultPartitionLabel is used if it is empty
+	Label string
+	// NamespaceHash appends hash of volume namespace to partition label
+	NamespaceHash bool
+	// TypeGUID is a partition type GUID, sgdisk default (Linux filesystem) is used if it is empty
+	TypeGUID string
+	// ClassTypeGUIDs overrides TypeGUID for volumes of specific storage class
+	ClassTypeGUIDs map[string]string
+}
+
+// ParsePartitionScheme parses partition scheme in format
+// "guid-prefix=0e5a,label=DC01,namespace-hash=true,type-guid=<guid>,type-guid.ssd=<guid>"
+// Returns error if format is wrong or scheme is invalid
+func ParsePartitionScheme(str string) (*PartitionScheme, error) {
+	scheme := &PartitionScheme{}
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("partition scheme option %s has wrong format, expected <key>=<value>", item)
+		}
+		value := strings.TrimSpace(parts[1])
+		switch strings.TrimSpace(parts[0]) {
+		case "guid-prefix":
+			scheme.GUIDPrefix = strings.ToLower(value)
+		case "label":
+			scheme.Label = value
+		case "namespace-hash":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("partition scheme option %s has wrong value: %v", item, err)
+			}
+			scheme.NamespaceHash = enabled
+		case "type-guid":
+			scheme.TypeGUID = strings.ToLower(value)
+		default:
+			key := strings.TrimSpace(parts[0])
+			if strings.HasPrefix(key, "type-guid.") {
+				if scheme.ClassTypeGUIDs == nil {
+					scheme.ClassTypeGUIDs = map[string]string{}
+				}
+				scheme.ClassTypeGUIDs[strings.ToUpper(strings.TrimPrefix(key, "type-guid."))] = strings.ToLower(value)
+				continue
+			}
+			return nil, fmt.Errorf("partition scheme option %s isn't supported, "+
+				"expected guid-prefix, label, namespace-hash, type-guid or type-guid.<storage class>", item)
+		}
+	}
+	return scheme, scheme.Validate()
+}
+
+// Validate checks that GUID prefix contains hex digits only and that partition label fits into GPT partition name
+func (s *PartitionScheme) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if len(s.GUIDPrefix) > maxGUIDPrefixLength || !guidPrefixRegexp.MatchString(s.GUIDPrefix) {
+		return fmt.Errorf("GUID prefix %s must contain at most %d hex digits", s.GUIDPrefix, maxGUIDPrefixLength)
+	}
+	if !partitionLabelRegexp.MatchString(s.Label) {
+		return fmt.Errorf("partition label %s must contain letters, digits, '-', '_' or '.' only", s.Label)
+	}
+	if len(s.PartLabel("")) > maxPartitionLabelLength {
+		return fmt.Errorf("partition label %s is longer than %d characters", s.PartLabel(""),
+			maxPartitionLabelLength)
+	}
+	if s.TypeGUID != "" && !typeGUIDRegexp.MatchString(s.TypeGUID) {
+		return fmt.Errorf("partition type GUID %s must be lower case GUID", s.TypeGUID)
+	}
+	for class, typeGUID := range s.ClassTypeGUIDs {
+		if !util.ContainsString(typeGUIDClasses, class) {
+			return fmt.Errorf("partition type GUID can't be set for storage class %s, expected one of %v",
+				class, typeGUIDClasses)
+		}
+		if !typeGUIDRegexp.MatchString(typeGUID) {
+			return fmt.Errorf("partition type GUID %s for storage class %s must be lower case GUID",
+				typeGUID, class)
+		}
+	}
+	return nil
+}
+
+// IsLegacy returns true if partition GUID is volume UUID
+func (s *PartitionScheme) IsLegacy() bool {
+	return s == nil || s.GUIDPrefix == ""
+}
+
+// PartUUID returns GUID of partition for volume with volumeID
+func (s *PartitionScheme) PartUUID(volumeID string) (string, error) {
+	volumeUUID, err := util.GetVolumeUUID(volumeID)
+	if err != nil || s.IsLegacy() {
+		return volumeUUID, err
+	}
+	if len(volumeUUID) < maxGUIDPrefixLength || strings.Contains(volumeUUID[:maxGUIDPrefixLength], "-") {
+		return "", fmt.Errorf("volume ID %s isn't UUID, GUID prefix can't be applied", volumeID)
+	}
+	return s.GUIDPrefix + volumeUUID[len(s.GUIDPrefix):], nil
+}
+
+// PartUUIDCandidates returns GUIDs which partition of the volume might have: GUID of current scheme goes first,
+// GUID of legacy scheme goes next if it is different. Partitions created before scheme change are found this way
+func (s *PartitionScheme) PartUUIDCandidates(volumeID string) []string {
+	var candidates []string
+	if partUUID, err := s.PartUUID(volumeID); err == nil {
+		candidates = append(candidates, partUUID)
+	}
+	if legacyUUID, err := util.GetVolumeUUID(volumeID); err == nil &&
+		(len(candidates) == 0 || !strings.EqualFold(candidates[0], legacyUUID)) {
+		candidates = append(candidates, legacyUUID)
+	}
+	return candidates
+}
+
+// PartLabel returns label of partition for volume in namespace
+func (s *PartitionScheme) PartLabel(namespace string) string {
+	if s == nil || s.Label == "" && !s.NamespaceHash {
+		return DefaultPartitionLabel
+	}
+	label := s.Label
+	if label == "" {
+		label = DefaultPartitionLabel
+	}
+	if s.NamespaceHash {
+		sum := sha256.Sum256([]byte(namespace))
+		label += "-" + hex.EncodeToString(sum[:])[:namespaceHashLength]
+	}
+	return label
+}
+
+// PartTypeGUID returns partition type GUID for volume of storageClass, empty string if sgdisk default is used
+func (s *PartitionScheme) PartTypeGUID(storageClass string) string {
+	if s == nil {
+		return ""
+	}
+	if typeGUID, ok := s.ClassTypeGUIDs[strings.ToUpper(storageClass)]; ok {
+		return typeGUID
+	}
+	return s.TypeGUID
+}
+
+// ManagedTypeGUIDs returns configured partition type GUIDs which identify partitions created by csi-baremetal.
+// Linux filesystem type GUID is skipped because foreign partitions usually have it too
+func (s *PartitionScheme) ManagedTypeGUIDs() []string {
+	if s == nil {
+		return nil
+	}
+	var typeGUIDs []string
+	add := func(typeGUID string) {
+		if typeGUID != "" && typeGUID != ph.LinuxFilesystemTypeGUID && !util.ContainsString(typeGUIDs, typeGUID) {
+			typeGUIDs = append(typeGUIDs, typeGUID)
+		}
+	}
+	add(s.TypeGUID)
+	for _, class := range typeGUIDClasses {
+		add(s.ClassTypeGUIDs[class])
+	}
+	return typeGUIDs
+}
+
+// MatchPartUUID returns candidate which is equal to partition GUID ignoring case, empty string if there is no such one
+func MatchPartUUID(candidates []string, partUUID string) string {
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, partUUID) {
+			return candidate
+		}
+	}
+	return ""
+}
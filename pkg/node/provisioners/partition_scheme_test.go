@@ -0,0 +1,116 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+)
+
+const (
+	testSchemeVolumeID = "pvc-5a1b2c3d-1111-2222-3333-444455556666"
+	testSchemeTypeGUID = "c5c4e9a1-2a2f-4c4e-9a8b-2f1f2b4d6e01"
+)
+
+func TestParsePartitionScheme(t *testing.T) {
+	scheme, err := ParsePartitionScheme("guid-prefix=0E5A, label=DC01,namespace-hash=true")
+	assert.Nil(t, err)
+	assert.Equal(t, &PartitionScheme{GUIDPrefix: "0e5a", Label: "DC01", NamespaceHash: true}, scheme)
+
+	for _, str := range []string{
+		"guid-prefix=0e5a0e5a0", "guid-prefix=xyz", "label=a b", "label=" + strings.Repeat("a", 30) +
+			",namespace-hash=true", "namespace-hash=maybe", "prefix=0e5a", "label", "type-guid=linux",
+		"type-guid.hddlvg=" + testSchemeTypeGUID, "type-guid.ssd=0fc63daf",
+	} {
+		_, err = ParsePartitionScheme(str)
+		assert.NotNil(t, err, str)
+	}
+}
+
+func TestPartitionScheme_PartUUID(t *testing.T) {
+	var legacy *PartitionScheme
+	partUUID, err := legacy.PartUUID(testSchemeVolumeID)
+	assert.Nil(t, err)
+	assert.Equal(t, "5a1b2c3d-1111-2222-3333-444455556666", partUUID)
+	assert.Equal(t, []string{partUUID}, legacy.PartUUIDCandidates(testSchemeVolumeID))
+
+	scheme := &PartitionScheme{GUIDPrefix: "0e5a"}
+	partUUID, err = scheme.PartUUID(testSchemeVolumeID)
+	assert.Nil(t, err)
+	assert.Equal(t, "0e5a2c3d-1111-2222-3333-444455556666", partUUID)
+	assert.Equal(t, []string{partUUID, "5a1b2c3d-1111-2222-3333-444455556666"},
+		scheme.PartUUIDCandidates(testSchemeVolumeID))
+	assert.Equal(t, "5a1b2c3d-1111-2222-3333-444455556666",
+		MatchPartUUID(scheme.PartUUIDCandidates(testSchemeVolumeID), "5A1B2C3D-1111-2222-3333-444455556666"))
+	assert.Empty(t, MatchPartUUID(scheme.PartUUIDCandidates(testSchemeVolumeID), "another"))
+
+	// volume ID isn't UUID
+	_, err = scheme.PartUUID("volume-2")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"volume-2"}, scheme.PartUUIDCandidates("volume-2"))
+	assert.Empty(t, scheme.PartUUIDCandidates(""))
+}
+
+func TestPartitionScheme_PartLabel(t *testing.T) {
+	var legacy *PartitionScheme
+	assert.Equal(t, DefaultPartitionLabel, legacy.PartLabel("default"))
+	assert.Equal(t, "DC01", (&PartitionScheme{Label: "DC01"}).PartLabel("default"))
+
+	scheme := &PartitionScheme{NamespaceHash: true}
+	label := scheme.PartLabel("default")
+	assert.True(t, strings.HasPrefix(label, DefaultPartitionLabel+"-"))
+	assert.Len(t, label, len(DefaultPartitionLabel)+1+namespaceHashLength)
+	assert.NotEqual(t, label, scheme.PartLabel("another"))
+}
+
+func TestPartitionScheme_PartTypeGUID(t *testing.T) {
+	var legacy *PartitionScheme
+	assert.Empty(t, legacy.PartTypeGUID("HDD"))
+	assert.Empty(t, legacy.ManagedTypeGUIDs())
+
+	nvmeTypeGUID := "c5c4e9a1-2a2f-4c4e-9a8b-2f1f2b4d6e02"
+	scheme, err := ParsePartitionScheme("type-guid=" + strings.ToUpper(testSchemeTypeGUID) +
+		",type-guid.nvme=" + nvmeTypeGUID + ",type-guid.SSD=" + ph.LinuxFilesystemTypeGUID)
+	assert.Nil(t, err)
+	assert.Equal(t, testSchemeTypeGUID, scheme.PartTypeGUID("HDD"))
+	assert.Equal(t, nvmeTypeGUID, scheme.PartTypeGUID("NVME"))
+	assert.Equal(t, ph.LinuxFilesystemTypeGUID, scheme.PartTypeGUID("SSD"))
+	// Linux filesystem type doesn't identify partitions of csi-baremetal
+	assert.Equal(t, []string{testSchemeTypeGUID, nvmeTypeGUID}, scheme.ManagedTypeGUIDs())
+}
+
+func TestDriveProvisioner_resolvePartUUID(t *testing.T) {
+	var (
+		dp, _, mockPH, _ = setupTestDriveProvisioner()
+		device           = "/dev/sda"
+		legacyUUID       = "5a1b2c3d-1111-2222-3333-444455556666"
+	)
+	// legacy scheme doesn't read partition
+	assert.Equal(t, legacyUUID, dp.resolvePartUUID(device, testSchemeVolumeID))
+
+	dp.SetPartitionScheme(&PartitionScheme{GUIDPrefix: "0e5a"})
+	mockPH.MockWrapPartition.On("GetPartitionUUID", device, DefaultPartitionNumber).
+		Return(strings.ToUpper(legacyUUID), nil).Once()
+	assert.Equal(t, legacyUUID, dp.resolvePartUUID(device, testSchemeVolumeID))
+
+	mockPH.MockWrapPartition.On("GetPartitionUUID", device, DefaultPartitionNumber).Return("", errTest).Once()
+	assert.Equal(t, "0e5a2c3d-1111-2222-3333-444455556666", dp.resolvePartUUID(device, testSchemeVolumeID))
+}
@@ -0,0 +1,155 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	uw "github.com/dell/csi-baremetal/pkg/node/provisioners/utilwrappers"
+)
+
+// StandbyPartition prepares, claims and releases pre-formatted partitions on free drives (standby drives).
+// Partition of standby drive has random UUID, on claim the UUID is changed to UUID of volume,
+// so PrepareVolume treats partition as already created one and filesystem creation is skipped
+type StandbyPartition struct {
+	partOps ph.WrapPartition
+	fsOps   uw.FSOperations
+	// partScheme describes GUID and type of claimed partition, nil means legacy scheme
+	partScheme *PartitionScheme
+	log        *logrus.Entry
+}
+
+// NewStandbyPartition is a constructor for StandbyPartition instance
+func NewStandbyPartition(partOps ph.WrapPartition, fsOps uw.FSOperations, log *logrus.Logger) *StandbyPartition {
+	return &StandbyPartition{
+		partOps: partOps,
+		fsOps:   fsOps,
+		log:     log.WithField("component", "StandbyPartition"),
+	}
+}
+
+// IsStandby returns true if drive has pre-created partition and filesystem
+func IsStandby(drive *drivecrd.Drive) bool {
+	return drive.Annotations[apiV1.DriveAnnotationStandby] != ""
+}
+
+// MarkStandby sets annotations of standby drive with filesystem type and partition UUID, CR isn't updated
+func MarkStandby(drive *drivecrd.Drive, fsType fs.FileSystem, partUUID string) {
+	if drive.Annotations == nil {
+		drive.Annotations = make(map[string]string)
+	}
+	drive.Annotations[apiV1.DriveAnnotationStandby] = string(fsType)
+	drive.Annotations[apiV1.DriveAnnotationStandbyPartUUID] = partUUID
+}
+
+// UnmarkStandby removes annotations of standby drive, CR isn't updated
+func UnmarkStandby(drive *drivecrd.Drive) {
+	delete(drive.Annotations, apiV1.DriveAnnotationStandby)
+	delete(drive.Annotations, apiV1.DriveAnnotationStandbyPartUUID)
+}
+
+// Prepare creates partition and filesystem on device of standby drive, drive must be marked with MarkStandby
+func (s *StandbyPartition) Prepare(drive *drivecrd.Drive, device string) error {
+	ll := s.log.WithFields(logrus.Fields{
+		"method": "Prepare",
+		"drive":  drive.Name,
+	})
+	var (
+		fsType   = fs.FileSystem(drive.Annotations[apiV1.DriveAnnotationStandby])
+		partUUID = drive.Annotations[apiV1.DriveAnnotationStandbyPartUUID]
+	)
+
+	if err := s.partOps.CreatePartitionTable(device, ph.PartitionGPT); err != nil {
+		return fmt.Errorf("unable to create partition table: %v", err)
+	}
+	if err := s.partOps.CreatePartition(device, DefaultPartitionLabel, partUUID, true); err != nil {
+		return fmt.Errorf("unable to create partition: %v", err)
+	}
+	_ = s.partOps.SyncPartitionTable(device)
+
+	name, err := s.partOps.GetPartitionNameByUUID(device, partUUID)
+	if err != nil {
+		return err
+	}
+	if err = s.fsOps.CreateFSIfNotExist(fsType, device+name); err != nil {
+		return err
+	}
+	ll.Infof("Standby partition %s with FS %s was created on device %s", partUUID, fsType, device)
+	return nil
+}
+
+// Claim assigns standby partition to volume if volume can use it as is: filesystem volume without encryption
+// with the same FS type. Otherwise standby partition is released.
+// Returns true if partition was claimed, drive annotations should be removed by caller in both cases
+func (s *StandbyPartition) Claim(drive *drivecrd.Drive, device string, vol *api.Volume) (bool, error) {
+	ll := s.log.WithFields(logrus.Fields{
+		"method":   "Claim",
+		"volumeID": vol.Id,
+	})
+	partUUID := drive.Annotations[apiV1.DriveAnnotationStandbyPartUUID]
+
+	if vol.Mode == apiV1.ModeRAW || vol.Mode == apiV1.ModeRAWPART || vol.Encryption != "" || vol.Ephemeral ||
+		drive.Annotations[apiV1.DriveAnnotationStandby] != vol.Type {
+		ll.Infof("Volume can't use standby partition, release it")
+		return false, s.Release(drive, device)
+	}
+
+	currUUID, err := s.partOps.GetPartitionUUID(device, DefaultPartitionNumber)
+	if err != nil || !strings.EqualFold(currUUID, partUUID) {
+		ll.Warnf("Standby partition %s isn't found on device %s, release it", partUUID, device)
+		return false, s.Release(drive, device)
+	}
+
+	volUUID, err := s.partScheme.PartUUID(vol.Id)
+	if err != nil {
+		return false, err
+	}
+	if err = s.partOps.SetPartitionUUID(device, DefaultPartitionNumber, volUUID); err != nil {
+		return false, err
+	}
+	if typeGUID := s.partScheme.PartTypeGUID(vol.StorageClass); typeGUID != "" {
+		if err = s.partOps.SetPartitionType(device, DefaultPartitionNumber, typeGUID); err != nil {
+			return false, err
+		}
+	}
+	_ = s.partOps.SyncPartitionTable(device)
+	ll.Infof("Standby partition %s on device %s was claimed", partUUID, device)
+	return true, nil
+}
+
+// Release wipes filesystem and partition of standby drive
+func (s *StandbyPartition) Release(drive *drivecrd.Drive, device string) error {
+	partUUID := drive.Annotations[apiV1.DriveAnnotationStandbyPartUUID]
+	// partition might be not created if preparation was interrupted
+	if name, err := s.partOps.GetPartitionNameByUUID(device, partUUID); err == nil {
+		if err = s.fsOps.WipeFS(device + name); err != nil {
+			return err
+		}
+		if err = s.partOps.DeletePartition(device, DefaultPartitionNumber); err != nil {
+			return err
+		}
+	}
+	return s.fsOps.WipeFS(device)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	uw "github.com/dell/csi-baremetal/pkg/node/provisioners/utilwrappers"
+)
+
+func TestDriveProvisioner_PrepareVolume_Standby(t *testing.T) {
+	var (
+		dp, mockLsblk, mockPH, mockFS = setupTestDriveProvisioner()
+		device                        = "/some/device"
+		standbyUUID                   = "standby-uuid"
+		drive                         = testDriveCR.DeepCopy()
+		part                          = uw.Partition{
+			Device:    device,
+			TableType: partitionhelper.PartitionGPT,
+			Label:     DefaultPartitionLabel,
+			Num:       DefaultPartitionNumber,
+			PartUUID:  testVolume2.Id,
+		}
+		preparedPart = part
+	)
+	preparedPart.Name = "1"
+	MarkStandby(drive, fs.XFS, standbyUUID)
+	assert.Nil(t, dp.k8sClient.CreateCR(testCtx, drive.Name, drive))
+
+	mockLsblk.On("SearchDrivePath", mock.Anything).Return(device, nil)
+	mockPH.MockWrapPartition.On("GetPartitionUUID", device, DefaultPartitionNumber).Return(standbyUUID, nil).Once()
+	mockPH.MockWrapPartition.On("SetPartitionUUID", device, DefaultPartitionNumber, testVolume2.Id).Return(nil).Once()
+	mockPH.MockWrapPartition.On("SyncPartitionTable", device).Return(nil)
+	mockPH.On("PreparePartition", part).Return(&preparedPart, nil)
+	mockFS.On("CreateFSIfNotExist", fs.XFS, preparedPart.GetFullPath()).Return(nil)
+
+	// partition is claimed, drive isn't standby anymore
+	assert.Nil(t, dp.PrepareVolume(&testVolume2))
+	mockPH.MockWrapPartition.AssertCalled(t, "SetPartitionUUID", device, DefaultPartitionNumber, testVolume2.Id)
+	mockFS.AssertNotCalled(t, "WipeFS", device)
+	updated := &drivecrd.Drive{}
+	assert.Nil(t, dp.k8sClient.ReadCR(testCtx, drive.Name, "", updated))
+	assert.False(t, IsStandby(updated))
+
+	// raw volume releases standby partition
+	MarkStandby(updated, fs.XFS, standbyUUID)
+	assert.Nil(t, dp.k8sClient.UpdateCR(testCtx, updated))
+	mockPH.MockWrapPartition.On("GetPartitionNameByUUID", device, standbyUUID).Return("1", nil).Once()
+	mockFS.On("WipeFS", device+"1").Return(nil).Once()
+	mockPH.MockWrapPartition.On("DeletePartition", device, DefaultPartitionNumber).Return(nil).Once()
+	mockFS.On("WipeFS", device).Return(nil).Once()
+
+	assert.Nil(t, dp.PrepareVolume(&testVolume2Raw))
+	mockPH.MockWrapPartition.AssertCalled(t, "DeletePartition", device, DefaultPartitionNumber)
+	// decoding into the existing object keeps keys of its annotations map
+	updated = &drivecrd.Drive{}
+	assert.Nil(t, dp.k8sClient.ReadCR(testCtx, drive.Name, "", updated))
+	assert.False(t, IsStandby(updated))
+}
+
+func TestStandbyPartition_Prepare(t *testing.T) {
+	var (
+		_, _, mockPH, mockFS = setupTestDriveProvisioner()
+		standby              = NewStandbyPartition(mockPH, mockFS, testLogger)
+		device               = "/some/device"
+		drive                = testDriveCR.DeepCopy()
+	)
+	MarkStandby(drive, fs.XFS, "standby-uuid")
+
+	mockPH.MockWrapPartition.On("CreatePartitionTable", device, partitionhelper.PartitionGPT).Return(nil)
+	mockPH.MockWrapPartition.On("CreatePartition", device, DefaultPartitionLabel, "standby-uuid", true).Return(nil)
+	mockPH.MockWrapPartition.On("SyncPartitionTable", device).Return(nil)
+	mockPH.MockWrapPartition.On("GetPartitionNameByUUID", device, "standby-uuid").Return("1", nil)
+	mockFS.On("CreateFSIfNotExist", fs.XFS, device+"1").Return(nil)
+	assert.Nil(t, standby.Prepare(drive, device))
+
+	// volume with another FS can't claim partition
+	mockFS.On("WipeFS", device+"1").Return(nil)
+	mockPH.MockWrapPartition.On("DeletePartition", device, DefaultPartitionNumber).Return(nil)
+	mockFS.On("WipeFS", device).Return(nil)
+	vol := testVolume2
+	vol.Type = string(fs.EXT4)
+	claimed, err := standby.Claim(drive, device, &vol)
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+}
@@ -55,6 +55,8 @@ type Partition struct {
 	TableType string
 	Label     string
 	PartUUID  string
+	// TypeGUID is a GPT partition type GUID, sgdisk default (Linux filesystem) is used if empty
+	TypeGUID  string
 	Ephemeral bool
 }
 
@@ -128,6 +130,11 @@ func (d *PartitionOperationsImpl) PreparePartition(p Partition) (*Partition, err
 	if err = d.CreatePartition(p.Device, p.Label, p.PartUUID, !p.Ephemeral); err != nil {
 		return nil, fmt.Errorf("unable to create partition: %v", err)
 	}
+	if p.TypeGUID != "" {
+		if err = d.SetPartitionType(p.Device, p.Num, p.TypeGUID); err != nil {
+			return nil, fmt.Errorf("unable to set partition type: %v", err)
+		}
+	}
 	_ = d.SyncPartitionTable(p.Device)
 
 	if p.Ephemeral {
@@ -124,6 +124,33 @@ func TestDriveProvisioner_PreparePartition_Success(t *testing.T) {
 	mockPH.AssertCalled(t, "GetPartitionUUID", testPart1.Device, testPart1.Num)
 }
 
+func TestDriveProvisioner_PreparePartition_TypeGUID(t *testing.T) {
+	var (
+		partOps, mockPH = setupTestPartitioner()
+		typeGUID        = "c5c4e9a1-2a2f-4c4e-9a8b-2f1f2b4d6e01"
+		p               = testPart1
+	)
+	p.TypeGUID = typeGUID
+
+	mockPH.On("IsPartitionExists", p.Device, p.Num).Return(false, nil)
+	mockPH.On("CreatePartitionTable", p.Device, p.TableType).Return(nil)
+	mockPH.On("CreatePartition", p.Device, p.Label, p.PartUUID, true).Return(nil)
+	mockPH.On("SyncPartitionTable", p.Device).Return(nil)
+	mockPH.On("GetPartitionNameByUUID", p.Device, p.PartUUID).Return("1", nil)
+	mockPH.On("SetPartitionType", p.Device, p.Num, typeGUID).Return(nil).Once()
+
+	currentPPtr, err := partOps.PreparePartition(p)
+	assert.Nil(t, err)
+	assert.Equal(t, typeGUID, currentPPtr.TypeGUID)
+	mockPH.AssertCalled(t, "SetPartitionType", p.Device, p.Num, typeGUID)
+
+	// sgdisk failed to set type
+	mockPH.On("SetPartitionType", p.Device, p.Num, typeGUID).Return(errors.New("error")).Once()
+	currentPPtr, err = partOps.PreparePartition(p)
+	assert.Nil(t, currentPPtr)
+	assert.NotNil(t, err)
+}
+
 func TestDriveProvisioner_PreparePartition_Failed(t *testing.T) {
 	var (
 		partOps, mockPH = setupTestPartitioner()
@@ -0,0 +1,94 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/hooks"
+)
+
+// Environment variables with volume context which are passed to provisioning hooks
+const (
+	HookEnvVolumeID        = "CSI_VOLUME_ID"
+	HookEnvVolumeNamespace = "CSI_VOLUME_NAMESPACE"
+	HookEnvVolumeSize      = "CSI_VOLUME_SIZE"
+	HookEnvStorageClass    = "CSI_VOLUME_STORAGE_CLASS"
+	HookEnvVolumeMode      = "CSI_VOLUME_MODE"
+	HookEnvFSType          = "CSI_VOLUME_FS_TYPE"
+	HookEnvLocation        = "CSI_VOLUME_LOCATION"
+	HookEnvNodeID          = "CSI_NODE_ID"
+	HookEnvDevice          = "CSI_VOLUME_DEVICE"
+	HookEnvTargetPath      = "CSI_VOLUME_TARGET_PATH"
+)
+
+// hookRunner runs site-specific hooks at hook points of provisioning pipeline
+type hookRunner interface {
+	Run(ctx context.Context, point string, env map[string]string) error
+}
+
+// SetProvisioningHooks enables hooks which are run before partitioning, after file system creation
+// and before mount of volumes
+func (m *VolumeManager) SetProvisioningHooks(runner *hooks.Runner) {
+	m.hooks = runner
+}
+
+// runHook runs hooks of point with volume context, device and target path are passed if they are known
+func (m *VolumeManager) runHook(ctx context.Context, point string, volume *volumecrd.Volume, device, targetPath string) error {
+	if m.hooks == nil {
+		return nil
+	}
+	env := map[string]string{
+		HookEnvVolumeID:        volume.Spec.Id,
+		HookEnvVolumeNamespace: volume.Namespace,
+		HookEnvVolumeSize:      strconv.FormatInt(volume.Spec.Size, 10),
+		HookEnvStorageClass:    volume.Spec.StorageClass,
+		HookEnvVolumeMode:      volume.Spec.Mode,
+		HookEnvFSType:          volume.Spec.Type,
+		HookEnvLocation:        volume.Spec.Location,
+		HookEnvNodeID:          m.nodeID,
+	}
+	if device != "" {
+		env[HookEnvDevice] = device
+	}
+	if targetPath != "" {
+		env[HookEnvTargetPath] = targetPath
+	}
+	return m.hooks.Run(ctx, point, env)
+}
+
+// prepareVolumeDevice creates partition or logical volume and file system of volume surrounded by
+// pre-partition and post-mkfs hooks
+func (m *VolumeManager) prepareVolumeDevice(ctx context.Context, volume *volumecrd.Volume) error {
+	if err := m.runHook(ctx, hooks.PrePartition, volume, "", ""); err != nil {
+		return err
+	}
+	provisioner := m.getProvisionerForVolume(&volume.Spec)
+	if err := provisioner.PrepareVolume(&volume.Spec); err != nil {
+		return err
+	}
+	if m.hooks == nil {
+		return nil
+	}
+	device, err := provisioner.GetVolumePath(&volume.Spec)
+	if err != nil {
+		return err
+	}
+	return m.runHook(ctx, hooks.PostMkfs, volume, device, "")
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/hooks"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+type hookCall struct {
+	point string
+	env   map[string]string
+}
+
+// fakeHookRunner records calls and fails hooks of failPoint
+type fakeHookRunner struct {
+	calls     []hookCall
+	failPoint string
+}
+
+func (f *fakeHookRunner) Run(_ context.Context, point string, env map[string]string) error {
+	f.calls = append(f.calls, hookCall{point: point, env: env})
+	if point == f.failPoint {
+		return errors.New("hook failed")
+	}
+	return nil
+}
+
+func TestVolumeManager_prepareVolumeDevice(t *testing.T) {
+	t.Run("hooks are disabled", func(t *testing.T) {
+		vm := prepareSuccessVolumeManager(t)
+		vm.SetProvisioners(map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: mockProv.GetMockProvisionerSuccess("/dev/sda1")})
+		assert.Nil(t, vm.prepareVolumeDevice(testCtx, volCR.DeepCopy()))
+	})
+
+	t.Run("hooks are run", func(t *testing.T) {
+		vm := prepareSuccessVolumeManager(t)
+		vm.SetProvisioners(map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: mockProv.GetMockProvisionerSuccess("/dev/sda1")})
+		runner := &fakeHookRunner{}
+		vm.hooks = runner
+
+		volume := volCR.DeepCopy()
+		assert.Nil(t, vm.prepareVolumeDevice(testCtx, volume))
+		assert.Len(t, runner.calls, 2)
+		assert.Equal(t, hooks.PrePartition, runner.calls[0].point)
+		assert.Equal(t, volume.Spec.Id, runner.calls[0].env[HookEnvVolumeID])
+		assert.Equal(t, vm.nodeID, runner.calls[0].env[HookEnvNodeID])
+		assert.NotContains(t, runner.calls[0].env, HookEnvDevice)
+		assert.Equal(t, hooks.PostMkfs, runner.calls[1].point)
+		assert.Equal(t, "/dev/sda1", runner.calls[1].env[HookEnvDevice])
+	})
+
+	t.Run("pre-partition hook failed", func(t *testing.T) {
+		vm := prepareSuccessVolumeManager(t)
+		pMock := &mockProv.MockProvisioner{}
+		vm.SetProvisioners(map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: pMock})
+		runner := &fakeHookRunner{failPoint: hooks.PrePartition}
+		vm.hooks = runner
+
+		// volume isn't created
+		assert.NotNil(t, vm.prepareVolumeDevice(testCtx, volCR.DeepCopy()))
+		assert.Len(t, runner.calls, 1)
+		pMock.AssertNotCalled(t, "PrepareVolume", mock.Anything)
+	})
+
+	t.Run("volume is failed on post-mkfs hook failure", func(t *testing.T) {
+		vm := prepareSuccessVolumeManager(t)
+		vm.SetProvisioners(map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: mockProv.GetMockProvisionerSuccess("/dev/sda1")})
+		vm.hooks = &fakeHookRunner{failPoint: hooks.PostMkfs}
+
+		volume := volCR.DeepCopy()
+		assert.Nil(t, vm.k8sClient.CreateCR(testCtx, volume.Name, volume))
+		_, err := vm.prepareVolume(testCtx, volume)
+		assert.NotNil(t, err)
+		assert.Equal(t, apiV1.Failed, volume.Spec.CSIStatus)
+	})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+)
+
+// getPublishTargets returns target paths of the volume mapped to pod names, CR written by older version of the
+// driver doesn't hold annotation, empty map is returned in this case
+func getPublishTargets(volume *volumecrd.Volume) (map[string]string, error) {
+	targets := make(map[string]string)
+	value, ok := volume.Annotations[apiV1.VolumeAnnotationPublishTargets]
+	if !ok || value == "" {
+		return targets, nil
+	}
+	if err := json.Unmarshal([]byte(value), &targets); err != nil {
+		return nil, fmt.Errorf("unable to parse annotation %s: %v", apiV1.VolumeAnnotationPublishTargets, err)
+	}
+	return targets, nil
+}
+
+// setPublishTargets records targets in the volume annotation, annotation is removed if there are no targets left,
+// CR isn't updated
+func setPublishTargets(volume *volumecrd.Volume, targets map[string]string) error {
+	if len(targets) == 0 {
+		delete(volume.Annotations, apiV1.VolumeAnnotationPublishTargets)
+		return nil
+	}
+	value, err := json.Marshal(targets)
+	if err != nil {
+		return err
+	}
+	if volume.Annotations == nil {
+		volume.Annotations = make(map[string]string, 1)
+	}
+	volume.Annotations[apiV1.VolumeAnnotationPublishTargets] = string(value)
+	return nil
+}
+
+// addPublishTarget records target path of published volume with the pod which consumes it
+func addPublishTarget(volume *volumecrd.Volume, target, podName string) error {
+	targets, err := getPublishTargets(volume)
+	if err != nil {
+		return err
+	}
+	targets[target] = podName
+	return setPublishTargets(volume, targets)
+}
+
+// removePublishTarget removes target path of unpublished volume and returns number of targets left,
+// owners of the volume are set to the pods of remaining targets
+func removePublishTarget(volume *volumecrd.Volume, target string) (int, error) {
+	targets, err := getPublishTargets(volume)
+	if err != nil {
+		return 0, err
+	}
+	delete(targets, target)
+	owners := make([]string, 0, len(targets))
+	for _, podName := range targets {
+		if !util.ContainsString(owners, podName) {
+			owners = append(owners, podName)
+		}
+	}
+	sort.Strings(owners)
+	volume.Spec.Owners = nil
+	if len(owners) > 0 {
+		volume.Spec.Owners = owners
+	}
+	return len(targets), setPublishTargets(volume, targets)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+func TestPublishTargets(t *testing.T) {
+	volume := testVolumeCR1.DeepCopy()
+
+	targets, err := getPublishTargets(volume)
+	assert.Nil(t, err)
+	assert.Empty(t, targets)
+
+	assert.Nil(t, addPublishTarget(volume, "/target/1", "virt-launcher"))
+	assert.Nil(t, addPublishTarget(volume, "/target/2", "hp-volume"))
+	targets, err = getPublishTargets(volume)
+	assert.Nil(t, err)
+	assert.Len(t, targets, 2)
+
+	left, err := removePublishTarget(volume, "/target/1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, left)
+	assert.Equal(t, []string{"hp-volume"}, volume.Spec.Owners)
+
+	left, err = removePublishTarget(volume, "/target/2")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, left)
+	assert.Nil(t, volume.Spec.Owners)
+	assert.NotContains(t, volume.Annotations, apiV1.VolumeAnnotationPublishTargets)
+
+	// broken annotation
+	volume.Annotations[apiV1.VolumeAnnotationPublishTargets] = "{"
+	_, err = removePublishTarget(volume, "/target/1")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+)
+
+// Drive attributes which might be propagated to labels of PersistentVolume
+const (
+	PVLabelMediaType  = "media-type"
+	PVLabelModel      = "model"
+	PVLabelSerialHash = "serial-hash"
+	PVLabelBay        = "bay"
+	// pvLabelPrefix is a prefix of PersistentVolume labels with drive attributes
+	pvLabelPrefix = "drive.csi-baremetal.dell.com/"
+	// serialHashLength is a length of hex encoded hash of drive serial number
+	serialHashLength = 16
+	// maxLabelValueLength is a max length of kubernetes label value
+	maxLabelValueLength = 63
+)
+
+// invalidLabelChars matches characters which aren't allowed in kubernetes label value
+var invalidLabelChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// SetPVDriveLabels enables propagation of drive attributes to labels of PersistentVolume during NodeStageVolume
+// Receives list of attributes: media-type, model, serial-hash, bay
+func (m *VolumeManager) SetPVDriveLabels(attributes []string) {
+	m.pvDriveLabels = attributes
+}
+
+// ParsePVDriveLabels parses comma separated list of drive attributes
+// Returns error if attribute isn't supported
+func ParsePVDriveLabels(str string) ([]string, error) {
+	var attributes []string
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		switch item {
+		case "":
+			continue
+		case PVLabelMediaType, PVLabelModel, PVLabelSerialHash, PVLabelBay:
+			attributes = append(attributes, item)
+		default:
+			return nil, fmt.Errorf("drive attribute %s isn't supported, expected one of %s, %s, %s, %s",
+				item, PVLabelMediaType, PVLabelModel, PVLabelSerialHash, PVLabelBay)
+		}
+	}
+	return attributes, nil
+}
+
+// labelPV sets labels with attributes of the volume drive on PersistentVolume, PV name is equal to volume ID
+// Drive of LVG volume is the first drive of LVG
+func (m *VolumeManager) labelPV(ctx context.Context, volume *volumecrd.Volume) error {
+	if len(m.pvDriveLabels) == 0 {
+		return nil
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "labelPV",
+		"volumeID": volume.Spec.Id,
+	})
+
+	drive, err := m.crHelper.GetDriveCRByVolume(volume)
+	if err != nil {
+		return err
+	}
+	pv := &corev1.PersistentVolume{}
+	if err = m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: volume.Spec.Id}, pv); err != nil {
+		return k8sCl.IgnoreNotFound(err)
+	}
+
+	labels := driveLabels(drive, m.pvDriveLabels)
+	changed := false
+	for key, value := range labels {
+		if pv.Labels[key] != value {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if pv.Labels == nil {
+		pv.Labels = make(map[string]string, len(labels))
+	}
+	for key, value := range labels {
+		pv.Labels[key] = value
+	}
+	if err = m.k8sClient.Update(ctx, pv); err != nil {
+		return err
+	}
+	ll.Infof("PersistentVolume is labeled with drive attributes %v", labels)
+	return nil
+}
+
+// driveLabels returns labels with drive attributes, attributes with empty value are skipped
+func driveLabels(drive *drivecrd.Drive, attributes []string) map[string]string {
+	labels := make(map[string]string, len(attributes))
+	for _, attr := range attributes {
+		var value string
+		switch attr {
+		case PVLabelMediaType:
+			value = drive.Spec.Type
+		case PVLabelModel:
+			value = drive.Spec.PID
+		case PVLabelSerialHash:
+			// serial number isn't exposed as is
+			if drive.Spec.SerialNumber != "" {
+				sum := sha256.Sum256([]byte(drive.Spec.SerialNumber))
+				value = hex.EncodeToString(sum[:])[:serialHashLength]
+			}
+		case PVLabelBay:
+			value = drive.Spec.Bay
+		}
+		if value = toLabelValue(value); value != "" {
+			labels[pvLabelPrefix+attr] = value
+		}
+	}
+	return labels
+}
+
+// toLabelValue replaces characters which aren't allowed in label value and truncates it
+func toLabelValue(value string) string {
+	value = invalidLabelChars.ReplaceAllString(strings.TrimSpace(value), "_")
+	if len(value) > maxLabelValueLength {
+		value = value[:maxLabelValueLength]
+	}
+	// label value must start and end with alphanumeric character
+	return strings.Trim(value, "_.-")
+}
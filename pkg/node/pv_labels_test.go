@@ -0,0 +1,81 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+func TestParsePVDriveLabels(t *testing.T) {
+	attributes, err := ParsePVDriveLabels("media-type, model,,bay")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{PVLabelMediaType, PVLabelModel, PVLabelBay}, attributes)
+
+	_, err = ParsePVDriveLabels("media-type,serial")
+	assert.NotNil(t, err)
+}
+
+func TestDriveLabels(t *testing.T) {
+	drive := testDriveCR.DeepCopy()
+	drive.Spec.PID = " ST4000NM/0035 "
+	drive.Spec.Bay = ""
+
+	labels := driveLabels(drive, []string{PVLabelMediaType, PVLabelModel, PVLabelSerialHash, PVLabelBay})
+	assert.Len(t, labels, 3)
+	assert.Equal(t, apiV1.DriveTypeHDD, labels[pvLabelPrefix+PVLabelMediaType])
+	assert.Equal(t, "ST4000NM_0035", labels[pvLabelPrefix+PVLabelModel])
+	hash := labels[pvLabelPrefix+PVLabelSerialHash]
+	assert.Len(t, hash, serialHashLength)
+	assert.NotContains(t, hash, drive.Spec.SerialNumber)
+
+	assert.Len(t, toLabelValue(strings.Repeat("a", 100)), maxLabelValueLength)
+}
+
+func TestVolumeManager_labelPV(t *testing.T) {
+	var (
+		vm      = prepareSuccessVolumeManager(t)
+		volume  = volCR.DeepCopy()
+		pv      = &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: volume.Spec.Id}}
+		updated = &corev1.PersistentVolume{}
+	)
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, testDriveCR.Name, testDriveCR.DeepCopy()))
+
+	// disabled
+	assert.Nil(t, vm.labelPV(testCtx, volume))
+
+	vm.SetPVDriveLabels([]string{PVLabelMediaType, PVLabelSerialHash})
+	// PV doesn't exist
+	assert.Nil(t, vm.labelPV(testCtx, volume))
+
+	assert.Nil(t, vm.k8sClient.Create(testCtx, pv))
+	assert.Nil(t, vm.labelPV(testCtx, volume))
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: volume.Spec.Id}, updated))
+	assert.Equal(t, apiV1.DriveTypeHDD, updated.Labels[pvLabelPrefix+PVLabelMediaType])
+	assert.Len(t, updated.Labels[pvLabelPrefix+PVLabelSerialHash], serialHashLength)
+
+	// drive doesn't exist
+	volume.Spec.Location = "unknown"
+	assert.NotNil(t, vm.labelPV(testCtx, volume))
+}
@@ -0,0 +1,204 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/queuetuning"
+)
+
+// queueTuning holds required queue attributes per drive type and verifies them periodically,
+// because udev rules and driver rescans (e.g. after repartitioning) reset them to defaults
+type queueTuning struct {
+	// key - drive type (HDD/SSD/NVME), value - queue attribute -> required value
+	attrs map[string]map[string]string
+	qt    queuetuning.WrapQueueTuning
+	// applied holds attributes which were set to required value per drive UUID,
+	// only change of such attribute is counted as drift
+	applied map[string]map[string]bool
+	drifts  *prometheus.CounterVec
+}
+
+// SetQueueTuning enables verification of queue attributes of drives by VerifyQueueTuning
+// Receives queue attribute -> required value per drive type, drives of other types aren't touched
+func (m *VolumeManager) SetQueueTuning(attrs map[string]map[string]string) {
+	q := &queueTuning{
+		attrs:   attrs,
+		qt:      queuetuning.NewWrapQueueTuningImpl(),
+		applied: map[string]map[string]bool{},
+		drifts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "queue_tuning_drift_total",
+			Help:        "number of times queue attributes of drives were found changed and were reapplied",
+			ConstLabels: prometheus.Labels{"node": m.nodeName},
+		}, []string{"attribute", "drive_type"}),
+	}
+	if err := prometheus.Register(q.drifts); err != nil {
+		m.log.WithField("method", "SetQueueTuning").Errorf("Failed to register metric: %v", err)
+	}
+	m.queueTuning = q
+}
+
+// VerifyQueueTuning sets queue attributes of online non-system drives to required values.
+// Attribute which differs from required value after it was applied is counted as drift and is reapplied
+func (m *VolumeManager) VerifyQueueTuning(ctx context.Context) error {
+	if m.queueTuning == nil {
+		return nil
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "VerifyQueueTuning",
+	})
+
+	drives, err := m.cachedCrHelper.GetDriveCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]bool, len(drives))
+	for _, drive := range drives {
+		attrs, ok := m.queueTuning.attrs[drive.Spec.Type]
+		if !ok || drive.Spec.IsSystem || drive.Spec.Status != apiV1.DriveStatusOnline {
+			continue
+		}
+		current[drive.Spec.UUID] = true
+		applied, ok := m.queueTuning.applied[drive.Spec.UUID]
+		if !ok {
+			applied = map[string]bool{}
+			m.queueTuning.applied[drive.Spec.UUID] = applied
+		}
+		// scheduler is set first, because it resets nr_requests
+		for _, attr := range sortedQueueAttributes(attrs) {
+			value := attrs[attr]
+			actual, err := m.queueTuning.qt.GetQueueAttribute(drive.Spec.Path, attr)
+			if err != nil {
+				if errors.Is(err, queuetuning.ErrNotSupported) {
+					ll.Debugf("Skip %s of drive %s: %v", attr, drive.Spec.SerialNumber, err)
+				} else {
+					ll.Errorf("Unable to read %s of drive %s: %v", attr, drive.Spec.SerialNumber, err)
+				}
+				continue
+			}
+			if actual == value {
+				applied[attr] = true
+				continue
+			}
+			if applied[attr] {
+				ll.Warnf("%s of drive %s drifted from %s to %s, reapplying", attr, drive.Spec.SerialNumber,
+					value, actual)
+				m.queueTuning.drifts.WithLabelValues(attr, drive.Spec.Type).Inc()
+			}
+			applied[attr] = m.applyQueueAttribute(ll, drive.Spec.Path, attr, value)
+		}
+	}
+
+	// drives which were removed or went offline are forgotten
+	for uuid := range m.queueTuning.applied {
+		if !current[uuid] {
+			delete(m.queueTuning.applied, uuid)
+		}
+	}
+	return nil
+}
+
+// applyQueueAttribute sets queue attribute of device and reads it back
+// Returns whether the attribute has required value
+func (m *VolumeManager) applyQueueAttribute(ll *logrus.Entry, device, attr, value string) bool {
+	if err := m.queueTuning.qt.SetQueueAttribute(device, attr, value); err != nil {
+		ll.Errorf("Unable to set %s of %s: %v", attr, device, err)
+		return false
+	}
+	actual, err := m.queueTuning.qt.GetQueueAttribute(device, attr)
+	if err != nil {
+		ll.Errorf("Unable to read %s of %s: %v", attr, device, err)
+		return false
+	}
+	// kernel clamps values which are out of device limits
+	if actual != value {
+		ll.Errorf("%s of %s is %s after setting to %s", attr, device, actual, value)
+		return false
+	}
+	ll.Infof("%s of %s is set to %s", attr, device, value)
+	return true
+}
+
+// sortedQueueAttributes returns queue attributes in order of application, scheduler is the first
+func sortedQueueAttributes(attrs map[string]string) []string {
+	names := make([]string, 0, len(attrs))
+	for attr := range attrs {
+		names = append(names, attr)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == queuetuning.AttrScheduler) != (names[j] == queuetuning.AttrScheduler) {
+			return names[i] == queuetuning.AttrScheduler
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// ParseQueueTuning parses queue attributes in format "HDD:scheduler=mq-deadline,read_ahead_kb=4096;NVME:scheduler=none"
+// Returns map drive type -> queue attribute -> value or error if format is wrong
+func ParseQueueTuning(str string) (map[string]map[string]string, error) {
+	tuning := make(map[string]map[string]string)
+	for _, item := range strings.Split(str, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("queue tuning %s has wrong format, expected <drive type>:<attribute>=<value>,...",
+				item)
+		}
+		driveType := strings.ToUpper(strings.TrimSpace(parts[0]))
+		switch driveType {
+		case apiV1.DriveTypeHDD, apiV1.DriveTypeSSD, apiV1.DriveTypeNVMe:
+		default:
+			return nil, fmt.Errorf("queue tuning %s has unknown drive type %s", item, driveType)
+		}
+		attrs := make(map[string]string)
+		for _, pair := range strings.Split(parts[1], ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[1]) == "" {
+				return nil, fmt.Errorf("queue tuning %s has wrong attribute %s, expected <attribute>=<value>",
+					item, pair)
+			}
+			attr, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+			switch attr {
+			case queuetuning.AttrScheduler:
+			case queuetuning.AttrNrRequests, queuetuning.AttrReadAheadKB, queuetuning.AttrMaxSectorsKB,
+				queuetuning.AttrRqAffinity, queuetuning.AttrNoMerges:
+				if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+					return nil, fmt.Errorf("queue tuning %s has wrong value of %s: %v", item, attr, err)
+				}
+			default:
+				return nil, fmt.Errorf("queue tuning %s has unsupported attribute %s", item, attr)
+			}
+			attrs[attr] = value
+		}
+		tuning[driveType] = attrs
+	}
+	return tuning, nil
+}
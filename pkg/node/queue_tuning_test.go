@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	qt "github.com/dell/csi-baremetal/pkg/base/linuxutils/queuetuning"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+func TestParseQueueTuning(t *testing.T) {
+	tuning, err := ParseQueueTuning("hdd:scheduler=mq-deadline, read_ahead_kb=4096; NVME:scheduler=none")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		apiV1.DriveTypeHDD:  {qt.AttrScheduler: "mq-deadline", qt.AttrReadAheadKB: "4096"},
+		apiV1.DriveTypeNVMe: {qt.AttrScheduler: "none"},
+	}, tuning)
+
+	for _, str := range []string{"HDD", "TAPE:scheduler=none", "HDD:scheduler", "HDD:nr_requests=many",
+		"HDD:rotational=0"} {
+		_, err = ParseQueueTuning(str)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestSortedQueueAttributes(t *testing.T) {
+	attrs := map[string]string{qt.AttrReadAheadKB: "128", qt.AttrNrRequests: "256", qt.AttrScheduler: "none"}
+	assert.Equal(t, []string{qt.AttrScheduler, qt.AttrNrRequests, qt.AttrReadAheadKB}, sortedQueueAttributes(attrs))
+}
+
+func TestVolumeManager_VerifyQueueTuning(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	var (
+		tuning = &mocklu.MockWrapQueueTuning{}
+		vm     = NewVolumeManager(nil, nil, testLogger, kubeClient, kubeClient, nil, nodeID, nodeName)
+		hdd    = testDriveCR.DeepCopy()
+		system = testDriveCR.DeepCopy()
+	)
+	// disabled
+	assert.Nil(t, vm.VerifyQueueTuning(testCtx))
+
+	vm.SetQueueTuning(map[string]map[string]string{
+		apiV1.DriveTypeHDD: {qt.AttrScheduler: "mq-deadline", qt.AttrNrRequests: "256"},
+	})
+	vm.queueTuning.qt = tuning
+	hdd.Spec.IsSystem = false
+	system.Name, system.Spec.UUID, system.Spec.Path = "system", "system", "/dev/sdz"
+	for _, d := range []*drivecrd.Drive{hdd, system} {
+		assert.Nil(t, kubeClient.CreateCR(testCtx, d.Name, d))
+	}
+	drifts := func(attr string) float64 {
+		return testutil.ToFloat64(vm.queueTuning.drifts.WithLabelValues(attr, apiV1.DriveTypeHDD))
+	}
+
+	// initial application isn't a drift
+	tuning.On("GetQueueAttribute", "/dev/sda", qt.AttrScheduler).Return("none", nil).Once()
+	tuning.On("SetQueueAttribute", "/dev/sda", qt.AttrScheduler, "mq-deadline").Return(nil)
+	tuning.On("GetQueueAttribute", "/dev/sda", qt.AttrScheduler).Return("mq-deadline", nil).Once()
+	tuning.On("GetQueueAttribute", "/dev/sda", qt.AttrNrRequests).Return("256", nil).Once()
+	assert.Nil(t, vm.VerifyQueueTuning(testCtx))
+	assert.Equal(t, float64(0), drifts(qt.AttrScheduler))
+	tuning.AssertNotCalled(t, "GetQueueAttribute", "/dev/sdz", qt.AttrScheduler)
+
+	// udev reset scheduler after repartitioning
+	tuning.On("GetQueueAttribute", "/dev/sda", qt.AttrScheduler).Return("none", nil).Once()
+	tuning.On("GetQueueAttribute", "/dev/sda", qt.AttrScheduler).Return("mq-deadline", nil).Once()
+	tuning.On("GetQueueAttribute", "/dev/sda", qt.AttrNrRequests).Return("256", nil).Once()
+	assert.Nil(t, vm.VerifyQueueTuning(testCtx))
+	assert.Equal(t, float64(1), drifts(qt.AttrScheduler))
+	assert.Equal(t, float64(0), drifts(qt.AttrNrRequests))
+	tuning.AssertNumberOfCalls(t, "SetQueueAttribute", 2)
+
+	// kernel clamps nr_requests, repeated failures to apply it aren't counted as drifts
+	tuning.On("GetQueueAttribute", "/dev/sda", qt.AttrScheduler).Return("mq-deadline", nil)
+	tuning.On("GetQueueAttribute", "/dev/sda", qt.AttrNrRequests).Return("64", nil)
+	tuning.On("SetQueueAttribute", "/dev/sda", qt.AttrNrRequests, "256").Return(nil)
+	assert.Nil(t, vm.VerifyQueueTuning(testCtx))
+	assert.Nil(t, vm.VerifyQueueTuning(testCtx))
+	assert.Equal(t, float64(1), drifts(qt.AttrNrRequests))
+	assert.False(t, vm.queueTuning.applied[hdd.Spec.UUID][qt.AttrNrRequests])
+
+	// drive is removed
+	assert.Nil(t, kubeClient.DeleteCR(testCtx, hdd))
+	assert.Nil(t, vm.VerifyQueueTuning(testCtx))
+	assert.Empty(t, vm.queueTuning.applied)
+}
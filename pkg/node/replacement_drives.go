@@ -0,0 +1,121 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// Policies of acceptance of drive which is installed in slot of BAD or removed drive
+const (
+	// ReplacementDrivePolicyAuto adds replacement drive to the free pool as any new drive
+	ReplacementDrivePolicyAuto = "auto"
+	// ReplacementDrivePolicyManual cordons replacement drive until user removes cordon annotation
+	ReplacementDrivePolicyManual = "manual"
+)
+
+// replacementDrives detects drives which are installed in slots of replaced drives
+type replacementDrives struct {
+	policy string
+	// vacated holds serial number of BAD or removed drive per slot, slot is remembered after Drive CR is deleted
+	vacated map[string]string
+}
+
+// SetReplacementDrivePolicy enables detection of replacement drives during Discover
+// Receives policy of acceptance of replacement drives: auto or manual
+func (m *VolumeManager) SetReplacementDrivePolicy(policy string) error {
+	if policy != ReplacementDrivePolicyAuto && policy != ReplacementDrivePolicyManual {
+		return fmt.Errorf("replacement drive policy %s isn't supported, expected %s or %s",
+			policy, ReplacementDrivePolicyAuto, ReplacementDrivePolicyManual)
+	}
+	m.replacementDrives = &replacementDrives{policy: policy, vacated: map[string]string{}}
+	return nil
+}
+
+// driveSlot returns location of drive in chassis: enclosure and slot or bay, empty if location is unknown
+func driveSlot(drive *api.Drive) string {
+	switch {
+	case drive.Slot != "":
+		return drive.Enclosure + "/" + drive.Slot
+	case drive.Bay != "":
+		return "bay/" + drive.Bay
+	}
+	return ""
+}
+
+// rememberVacatedSlots remembers slots of BAD drives and drives which are being replaced
+func (m *VolumeManager) rememberVacatedSlots(drives []drivecrd.Drive) {
+	if m.replacementDrives == nil {
+		return
+	}
+	for _, drive := range drives {
+		slot := driveSlot(&drive.Spec)
+		if slot == "" {
+			continue
+		}
+		if drive.Spec.Health == apiV1.HealthBad || drive.Spec.Usage != apiV1.DriveUsageInUse {
+			m.replacementDrives.vacated[slot] = drive.Spec.SerialNumber
+		}
+	}
+}
+
+// acceptReplacementDrive annotates drive which is installed in vacated slot as replacement and cordons it
+// if policy is manual. Caller is responsible for creation of the drive object
+// Returns serial number of replaced drive, empty if drive isn't replacement
+func (m *VolumeManager) acceptReplacementDrive(drive *drivecrd.Drive) string {
+	if m.replacementDrives == nil {
+		return ""
+	}
+	slot := driveSlot(&drive.Spec)
+	replaced, ok := m.replacementDrives.vacated[slot]
+	if slot == "" || !ok || replaced == drive.Spec.SerialNumber {
+		return ""
+	}
+	delete(m.replacementDrives.vacated, slot)
+	if drive.Annotations == nil {
+		drive.Annotations = map[string]string{}
+	}
+	drive.Annotations[apiV1.DriveAnnotationReplacementOf] = replaced
+	if m.replacementDrives.policy == ReplacementDrivePolicyManual {
+		drive.Annotations[apiV1.DriveAnnotationCordon] = "true"
+	}
+	m.log.WithFields(logrus.Fields{
+		"method":   "acceptReplacementDrive",
+		"driveSN":  drive.Spec.SerialNumber,
+		"replaced": replaced,
+	}).Infof("Drive is installed in slot %s of replaced drive, policy is %s", slot, m.replacementDrives.policy)
+	return replaced
+}
+
+// reportReplacementDrive records event about replacement drive on its Drive CR
+func (m *VolumeManager) reportReplacementDrive(drive *drivecrd.Drive, replaced string) {
+	if m.replacementDrives.policy == ReplacementDrivePolicyManual {
+		m.recorder.Eventf(drive, eventing.DriveReplaced,
+			"Drive replaces drive %s and is cordoned, remove %s annotation to accept it. %s",
+			replaced, apiV1.DriveAnnotationCordon, drive.GetDriveDescription())
+		return
+	}
+	m.recorder.Eventf(drive, eventing.DriveReplaced, "Drive replaces drive %s and is accepted automatically. %s",
+		replaced, drive.GetDriveDescription())
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+)
+
+func TestVolumeManager_SetReplacementDrivePolicy(t *testing.T) {
+	vm := prepareSuccessVolumeManager(t)
+	assert.NotNil(t, vm.SetReplacementDrivePolicy("always"))
+	assert.Nil(t, vm.replacementDrives)
+	assert.Nil(t, vm.SetReplacementDrivePolicy(ReplacementDrivePolicyAuto))
+	assert.NotNil(t, vm.replacementDrives)
+}
+
+func Test_driveSlot(t *testing.T) {
+	assert.Equal(t, "500056b3d2e4b300/7", driveSlot(&api.Drive{Enclosure: "500056b3d2e4b300", Slot: "7", Bay: "3"}))
+	assert.Equal(t, "bay/3", driveSlot(&api.Drive{Bay: "3"}))
+	assert.Equal(t, "", driveSlot(&api.Drive{}))
+}
+
+func TestVolumeManager_acceptReplacementDrive(t *testing.T) {
+	for _, policy := range []string{ReplacementDrivePolicyAuto, ReplacementDrivePolicyManual} {
+		t.Run(policy, func(t *testing.T) {
+			var (
+				vm       = prepareSuccessVolumeManager(t)
+				recorder = new(mocks.NoOpRecorder)
+				bad      = drive1
+				other    = drive2
+			)
+			vm.recorder = recorder
+			assert.Nil(t, vm.SetReplacementDrivePolicy(policy))
+
+			bad.Enclosure, bad.Slot, bad.Health = "500056b3d2e4b300", "7", apiV1.HealthBad
+			other.Enclosure, other.Slot = "500056b3d2e4b300", "8"
+			_, err := vm.updateDrivesCRs(testCtx, getDriveMgrRespBasedOnDrives(bad, other))
+			assert.Nil(t, err)
+
+			// BAD drive is pulled out and new drive is installed in the same slot
+			replacement := bad
+			replacement.SerialNumber, replacement.Health, replacement.UUID = "hdd3-serial", apiV1.HealthGood, ""
+			updates, err := vm.updateDrivesCRs(testCtx, getDriveMgrRespBasedOnDrives(replacement, other))
+			assert.Nil(t, err)
+			assert.Len(t, updates.Created, 1)
+			created := &drivecrd.Drive{}
+			assert.Nil(t, vm.k8sClient.ReadCR(testCtx, updates.Created[0].Name, "", created))
+			assert.Equal(t, bad.SerialNumber, created.Annotations[apiV1.DriveAnnotationReplacementOf])
+			if policy == ReplacementDrivePolicyManual {
+				assert.Equal(t, "true", created.Annotations[apiV1.DriveAnnotationCordon])
+			} else {
+				assert.Empty(t, created.Annotations[apiV1.DriveAnnotationCordon])
+			}
+			assert.Len(t, recorder.Calls, 1)
+			assert.Equal(t, eventing.DriveReplaced, recorder.Calls[0].Event)
+
+			// drive installed in slot of healthy drive isn't replacement
+			newDrive := other
+			newDrive.SerialNumber, newDrive.UUID = "hdd4-serial", ""
+			updates, err = vm.updateDrivesCRs(testCtx, getDriveMgrRespBasedOnDrives(replacement, newDrive))
+			assert.Nil(t, err)
+			assert.Len(t, updates.Created, 1)
+			assert.Empty(t, updates.Created[0].Annotations[apiV1.DriveAnnotationReplacementOf])
+			assert.Len(t, recorder.Calls, 1)
+		})
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package node
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+const (
+	// NodeConditionStorageSelfTestFailed is True until storage self-test passes
+	NodeConditionStorageSelfTestFailed = corev1.NodeConditionType(apiV1.NodeConditionStorageSelfTestFailed)
+	// DefaultSelfTestMountDir is a directory where test partition is mounted during storage self-test
+	DefaultSelfTestMountDir = "/tmp/csi-baremetal-self-test"
+
+	selfTestFile = "self-test"
+	selfTestData = "csi-baremetal storage self-test"
+)
+
+// selfTest holds state of storage self-test which checks that volume can be created, formatted and mounted
+// on the node before volumes are scheduled to it
+type selfTest struct {
+	fsType   fs.FileSystem
+	mountDir string
+	passed   bool
+}
+
+// SetSelfTest enables storage self-test of node which runs during Discover until it passes,
+// test partition with filesystem of fsType is created on one free drive and removed after the test
+func (m *VolumeManager) SetSelfTest(fsType fs.FileSystem) {
+	m.selfTest = &selfTest{fsType: fsType, mountDir: DefaultSelfTestMountDir}
+}
+
+// runSelfTest runs storage self-test on free drive and reports result in StorageSelfTestFailed node condition,
+// condition is Unknown while there is no free drive for the test
+func (m *VolumeManager) runSelfTest(ctx context.Context) error {
+	if m.selfTest == nil || m.selfTest.passed {
+		return nil
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "runSelfTest",
+	})
+
+	// volumes must not be scheduled to the node while the test runs, e.g. after restart of node service
+	if err := m.setSelfTestCondition(ctx, corev1.ConditionTrue, "InProgress",
+		"Storage self-test is in progress"); err != nil {
+		return err
+	}
+
+	drive, err := m.runSelfTestOnFreeDrive(ctx)
+	switch {
+	case err != nil:
+		ll.Errorf("Storage self-test failed: %v", err)
+		return m.setSelfTestCondition(ctx, corev1.ConditionTrue, "Failed",
+			fmt.Sprintf("Storage self-test failed: %v", err))
+	case drive == nil:
+		ll.Info("There is no free drive for storage self-test")
+		return m.setSelfTestCondition(ctx, corev1.ConditionUnknown, "NoFreeDrive",
+			"There is no free drive for storage self-test")
+	}
+	ll.Infof("Storage self-test passed on drive %s", drive.Spec.SerialNumber)
+	m.selfTest.passed = true
+	return m.setSelfTestCondition(ctx, corev1.ConditionFalse, "Passed",
+		fmt.Sprintf("Storage self-test passed on drive %s", drive.Spec.SerialNumber))
+}
+
+// runSelfTestOnFreeDrive releases partitions of interrupted tests and runs the test on the first free drive
+// Returns tested drive, nil if there is no free drive
+func (m *VolumeManager) runSelfTestOnFreeDrive(ctx context.Context) (*drivecrd.Drive, error) {
+	// cache might not contain the latest annotations of drive, so drives are read from API server
+	drives, err := m.crHelper.GetDriveCRs(m.nodeID)
+	if err != nil {
+		return nil, err
+	}
+	acs, err := m.cachedCrHelper.GetACCRs(m.nodeID)
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := m.cachedCrHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	freeSize := make(map[string]int64, len(acs))
+	for _, ac := range acs {
+		freeSize[ac.Spec.Location] = ac.Spec.Size
+	}
+	usedLocations := make(map[string]bool, len(volumes))
+	for _, v := range volumes {
+		usedLocations[v.Spec.Location] = true
+	}
+
+	for i := range drives {
+		drive := &drives[i]
+		if drive.Annotations[apiV1.DriveAnnotationSelfTest] != "" {
+			if err = m.releaseSelfTestDrive(ctx, drive); err != nil {
+				return nil, fmt.Errorf("unable to release partition of interrupted test on drive %s: %v",
+					drive.Spec.SerialNumber, err)
+			}
+		}
+	}
+	for i := range drives {
+		drive := &drives[i]
+		if p.IsStandby(drive) ||
+			!m.isStandbyCandidate(drive, freeSize[drive.Spec.UUID], usedLocations[drive.Spec.UUID]) {
+			continue
+		}
+		tested, err := m.selfTestDrive(ctx, drive)
+		if err != nil {
+			return nil, fmt.Errorf("drive %s: %v", drive.Spec.SerialNumber, err)
+		}
+		if tested {
+			return drive, nil
+		}
+	}
+	return nil, nil
+}
+
+// selfTestDrive creates partition and filesystem on drive, mounts it, writes and reads back test file and
+// releases the partition holding lock of drive location. Drive is annotated during the test, so AC of the drive
+// isn't used and interrupted test is cleaned during the next run
+// Returns false if volume was created on drive after it was selected
+func (m *VolumeManager) selfTestDrive(ctx context.Context, drive *drivecrd.Drive) (bool, error) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "selfTestDrive",
+		"drive":  drive.Name,
+	})
+	defer m.lockLocation(ll, drive.Spec.UUID)()
+
+	if volumes, err := m.crHelper.GetVolumesByLocation(ctx, drive.Spec.UUID); err != nil || len(volumes) > 0 {
+		return false, err
+	}
+	device, err := m.listBlk.SearchDrivePath(&drive.Spec)
+	if err != nil {
+		return false, err
+	}
+
+	if drive.Annotations == nil {
+		drive.Annotations = make(map[string]string)
+	}
+	drive.Annotations[apiV1.DriveAnnotationSelfTest] = uuid.New().String()
+	if err = m.k8sClient.UpdateCR(ctx, drive); err != nil {
+		return false, err
+	}
+	ll.Infof("Run storage self-test on device %s", device)
+	testErr := m.selfTestPartition(device, drive.Annotations[apiV1.DriveAnnotationSelfTest])
+	if err = m.releaseSelfTestPartition(drive, device); err != nil {
+		// annotation is kept, so release is retried during the next run
+		return true, fmt.Errorf("unable to release test partition: %v, test result: %v", err, testErr)
+	}
+	delete(drive.Annotations, apiV1.DriveAnnotationSelfTest)
+	if err = m.k8sClient.UpdateCR(ctx, drive); err != nil {
+		return true, err
+	}
+	return true, testErr
+}
+
+// selfTestPartition creates partition with partUUID and filesystem on device, mounts it, writes and reads back
+// test file, partition is unmounted but isn't removed
+func (m *VolumeManager) selfTestPartition(device, partUUID string) error {
+	if err := m.partOps.CreatePartitionTable(device, ph.PartitionGPT); err != nil {
+		return fmt.Errorf("unable to create partition table: %v", err)
+	}
+	if err := m.partOps.CreatePartition(device, p.DefaultPartitionLabel, partUUID, true); err != nil {
+		return fmt.Errorf("unable to create partition: %v", err)
+	}
+	_ = m.partOps.SyncPartitionTable(device)
+	name, err := m.partOps.GetPartitionNameByUUID(device, partUUID)
+	if err != nil {
+		return err
+	}
+	partition := device + name
+	if err = m.fsOps.CreateFS(m.selfTest.fsType, partition); err != nil {
+		return fmt.Errorf("unable to create filesystem: %v", err)
+	}
+
+	dir := m.selfTest.mountDir
+	if err = m.fsOps.MkDir(dir); err != nil {
+		return err
+	}
+	if err = m.fsOps.Mount(partition, dir); err != nil {
+		return fmt.Errorf("unable to mount partition: %v", err)
+	}
+	defer func() {
+		if err := m.fsOps.Unmount(dir); err != nil {
+			m.log.WithField("method", "selfTestPartition").Errorf("Unable to unmount %s: %v", dir, err)
+		}
+	}()
+	// test file mustn't be written to the container filesystem if mount silently failed
+	if mounted, err := m.fsOps.IsMounted(dir); err != nil || !mounted {
+		return fmt.Errorf("partition isn't mounted to %s: %v", dir, err)
+	}
+
+	file := filepath.Join(dir, selfTestFile)
+	if err = ioutil.WriteFile(file, []byte(selfTestData), 0600); err != nil {
+		return fmt.Errorf("unable to write test file: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return fmt.Errorf("unable to read test file: %v", err)
+	}
+	if !bytes.Equal(data, []byte(selfTestData)) {
+		return errors.New("content of test file is corrupted")
+	}
+	return nil
+}
+
+// releaseSelfTestDrive releases partition of interrupted test and removes annotation of drive
+func (m *VolumeManager) releaseSelfTestDrive(ctx context.Context, drive *drivecrd.Drive) error {
+	defer m.lockLocation(m.log.WithField("method", "releaseSelfTestDrive"), drive.Spec.UUID)()
+	device, err := m.listBlk.SearchDrivePath(&drive.Spec)
+	if err != nil {
+		return err
+	}
+	if err = m.releaseSelfTestPartition(drive, device); err != nil {
+		return err
+	}
+	delete(drive.Annotations, apiV1.DriveAnnotationSelfTest)
+	return m.k8sClient.UpdateCR(ctx, drive)
+}
+
+// releaseSelfTestPartition wipes filesystem and test partition of drive and partition table of device
+func (m *VolumeManager) releaseSelfTestPartition(drive *drivecrd.Drive, device string) error {
+	partUUID := drive.Annotations[apiV1.DriveAnnotationSelfTest]
+	// partition might be not created if test was interrupted
+	if name, err := m.partOps.GetPartitionNameByUUID(device, partUUID); err == nil {
+		if err = m.fsOps.WipeFS(device + name); err != nil {
+			return err
+		}
+		if err = m.partOps.DeletePartition(device, p.DefaultPartitionNumber); err != nil {
+			return err
+		}
+	}
+	return m.fsOps.WipeFS(device)
+}
+
+// setSelfTestCondition sets StorageSelfTestFailed condition of kubernetes Node,
+// event is sent when test fails or passes
+func (m *VolumeManager) setSelfTestCondition(ctx context.Context, status corev1.ConditionStatus,
+	reason, message string) error {
+	k8sNode := &corev1.Node{}
+	if err := m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: m.nodeName}, k8sNode); err != nil {
+		return err
+	}
+	setNodeCondition(k8sNode, corev1.NodeCondition{Type: NodeConditionStorageSelfTestFailed, Status: status,
+		Reason: reason, Message: message})
+	switch reason {
+	case "Failed":
+		m.recorder.Eventf(k8sNode, eventing.NodeStorageSelfTestFailed, "%s", message)
+	case "Passed":
+		m.recorder.Eventf(k8sNode, eventing.NodeStorageSelfTestPassed, "%s", message)
+	}
+	return m.k8sClient.Status().Update(ctx, k8sNode)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+func TestVolumeManager_runSelfTest(t *testing.T) {
+	var (
+		device    = drive1.Path
+		recorder  = new(mocks.NoOpRecorder)
+		k8sNode   = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		updated   = &corev1.Node{}
+		drive     = &drivecrd.Drive{}
+		condition = func(vm *VolumeManager) *corev1.NodeCondition {
+			assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+			return getNodeCondition(updated, NodeConditionStorageSelfTestFailed)
+		}
+		prepare = func(mountErr error) (*VolumeManager, *mocklu.MockWrapPartition, *mockProv.MockFsOpts) {
+			vm := prepareSuccessVolumeManager(t)
+			partOps, fsOps := &mocklu.MockWrapPartition{}, &mockProv.MockFsOpts{}
+			vm.recorder, vm.partOps, vm.fsOps, vm.listBlk = recorder, partOps, fsOps, mocklu.GetMockWrapLsblk(device)
+			vm.SetSelfTest(fs.XFS)
+			vm.selfTest.mountDir = t.TempDir()
+			assert.Nil(t, vm.k8sClient.Create(testCtx, k8sNode.DeepCopy()))
+
+			partOps.On("CreatePartitionTable", device, "gpt").Return(nil)
+			partOps.On("CreatePartition", device, p.DefaultPartitionLabel, mock.Anything, true).Return(nil)
+			partOps.On("SyncPartitionTable", device).Return(nil)
+			partOps.On("GetPartitionNameByUUID", device, mock.Anything).Return("1", nil)
+			partOps.On("DeletePartition", device, p.DefaultPartitionNumber).Return(nil)
+			fsOps.On("CreateFS", fs.XFS, device+"1").Return(nil)
+			fsOps.On("MkDir", vm.selfTest.mountDir).Return(nil)
+			fsOps.On("Mount", device+"1", vm.selfTest.mountDir, mock.Anything).Return(mountErr)
+			fsOps.On("IsMounted", vm.selfTest.mountDir).Return(true, nil)
+			fsOps.On("Unmount", vm.selfTest.mountDir).Return(nil)
+			fsOps.On("WipeFS", mock.Anything).Return(nil)
+			return vm, partOps, fsOps
+		}
+	)
+	spec := drive1
+	spec.IsSystem, spec.IsClean, spec.Usage = false, true, apiV1.DriveUsageInUse
+
+	// there is no free drive
+	vm, _, _ := prepare(nil)
+	assert.Nil(t, vm.runSelfTest(testCtx))
+	assert.Equal(t, corev1.ConditionUnknown, condition(vm).Status)
+	assert.False(t, vm.selfTest.passed)
+
+	// test partition is created, mounted and released
+	addDriveCRs(vm.k8sClient, vm.k8sClient.ConstructDriveCR(spec.UUID, spec))
+	ac := vm.k8sClient.ConstructACCR("ac", api.AvailableCapacity{
+		Location: spec.UUID, NodeId: nodeID, StorageClass: apiV1.StorageClassHDD, Size: spec.Size})
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, ac.Name, ac))
+	assert.Nil(t, vm.runSelfTest(testCtx))
+	assert.Equal(t, corev1.ConditionFalse, condition(vm).Status)
+	assert.True(t, vm.selfTest.passed)
+	assert.Equal(t, eventing.NodeStorageSelfTestPassed, recorder.Calls[len(recorder.Calls)-1].Event)
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, spec.UUID, "", drive))
+	assert.Empty(t, drive.Annotations[apiV1.DriveAnnotationSelfTest])
+	assert.FileExists(t, vm.selfTest.mountDir+"/"+selfTestFile)
+
+	// test isn't repeated after it passed
+	assert.Nil(t, vm.runSelfTest(testCtx))
+	vm.partOps.(*mocklu.MockWrapPartition).AssertNumberOfCalls(t, "CreatePartition", 1)
+
+	// mount fails, partition is released and test is repeated during the next run
+	vm, partOps, fsOps := prepare(testErr)
+	addDriveCRs(vm.k8sClient, vm.k8sClient.ConstructDriveCR(spec.UUID, spec))
+	ac = vm.k8sClient.ConstructACCR("ac", api.AvailableCapacity{
+		Location: spec.UUID, NodeId: nodeID, StorageClass: apiV1.StorageClassHDD, Size: spec.Size})
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, ac.Name, ac))
+	assert.Nil(t, vm.runSelfTest(testCtx))
+	assert.Equal(t, corev1.ConditionTrue, condition(vm).Status)
+	assert.Equal(t, "Failed", condition(vm).Reason)
+	assert.False(t, vm.selfTest.passed)
+	assert.Equal(t, eventing.NodeStorageSelfTestFailed, recorder.Calls[len(recorder.Calls)-1].Event)
+	partOps.AssertCalled(t, "DeletePartition", device, p.DefaultPartitionNumber)
+	fsOps.AssertCalled(t, "WipeFS", device)
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, spec.UUID, "", drive))
+	assert.Empty(t, drive.Annotations[apiV1.DriveAnnotationSelfTest])
+
+	// partition of interrupted test is released before drive is selected
+	drive.Annotations = map[string]string{apiV1.DriveAnnotationSelfTest: "interrupted"}
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, drive))
+	assert.Nil(t, vm.runSelfTest(testCtx))
+	partOps.AssertCalled(t, "GetPartitionNameByUUID", device, "interrupted")
+	// decoding into the existing object keeps keys of its annotations map
+	drive = &drivecrd.Drive{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, spec.UUID, "", drive))
+	assert.Empty(t, drive.Annotations[apiV1.DriveAnnotationSelfTest])
+}
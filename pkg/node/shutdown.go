@@ -0,0 +1,27 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"time"
+)
+
+// Drain stops acceptance of new volume operations and waits for in-flight ones within timeout
+// Returns names of operations which weren't finished, they are resumed by Reconcile after restart
+func (m *VolumeManager) Drain(timeout time.Duration) []string {
+	return m.operations.Drain(timeout)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+// standbyPool holds settings of standby drives - free drives with pre-created partition and filesystem,
+// volume created on standby drive claims partition and skips mkfs
+type standbyPool struct {
+	// desired amount of standby drives per storage class
+	counts map[string]int
+	// type of pre-created filesystem, volumes with other FS release standby partition
+	fsType    fs.FileSystem
+	partition *p.StandbyPartition
+}
+
+// SetStandbyPool enables preparation of standby drives during Discover
+// Receives desired amount of standby drives per storage class and filesystem type
+func (m *VolumeManager) SetStandbyPool(counts map[string]int, fsType fs.FileSystem) {
+	m.standbyPool = &standbyPool{
+		counts:    counts,
+		fsType:    fsType,
+		partition: p.NewStandbyPartition(m.partOps, m.fsOps, m.log.Logger),
+	}
+}
+
+// refillStandbyPool prepares free drives as standby ones until desired amount is reached for each storage class
+func (m *VolumeManager) refillStandbyPool(ctx context.Context) error {
+	if m.standbyPool == nil {
+		return nil
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "refillStandbyPool",
+	})
+
+	drives, err := m.cachedCrHelper.GetDriveCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	acs, err := m.cachedCrHelper.GetACCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	volumes, err := m.cachedCrHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+
+	freeSize := make(map[string]int64, len(acs))
+	for _, ac := range acs {
+		freeSize[ac.Spec.Location] = ac.Spec.Size
+	}
+	usedLocations := make(map[string]bool, len(volumes))
+	for _, v := range volumes {
+		usedLocations[v.Spec.Location] = true
+	}
+
+	var (
+		ready      = make(map[string]int)
+		candidates = make(map[string][]drivecrd.Drive)
+	)
+	for _, drive := range drives {
+		drive := drive
+		sc := util.ConvertDriveTypeToStorageClass(drive.Spec.Type)
+		if p.IsStandby(&drive) {
+			if !usedLocations[drive.Spec.UUID] {
+				ready[sc]++
+			}
+			continue
+		}
+		if m.isStandbyCandidate(&drive, freeSize[drive.Spec.UUID], usedLocations[drive.Spec.UUID]) {
+			candidates[sc] = append(candidates[sc], drive)
+		}
+	}
+
+	for sc, count := range m.standbyPool.counts {
+		for i := 0; i < count-ready[sc] && i < len(candidates[sc]); i++ {
+			drive := candidates[sc][i]
+			if err := m.prepareStandbyDrive(ctx, &drive); err != nil {
+				ll.Errorf("Unable to prepare standby drive %s: %v", drive.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// isStandbyCandidate checks that drive is healthy, clean and whole drive is available for allocation
+func (m *VolumeManager) isStandbyCandidate(drive *drivecrd.Drive, freeSize int64, used bool) bool {
+	return !drive.Spec.IsSystem && drive.Spec.IsClean && !used &&
+		drive.Spec.Health == apiV1.HealthGood &&
+		drive.Spec.Status == apiV1.DriveStatusOnline &&
+		drive.Spec.Usage == apiV1.DriveUsageInUse &&
+		drive.Annotations[apiV1.DriveAnnotationCordon] != "true" &&
+		drive.Annotations[apiV1.DriveAnnotationSelfTest] == "" &&
+		!drive.IsBurnInBlocking() &&
+		freeSize == drive.Spec.Size
+}
+
+// prepareStandbyDrive creates partition and filesystem on drive holding lock of its location,
+// drive is marked as standby before preparation, so interrupted preparation is cleaned on claim
+func (m *VolumeManager) prepareStandbyDrive(ctx context.Context, drive *drivecrd.Drive) error {
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "prepareStandbyDrive",
+		"drive":  drive.Name,
+	})
+	defer m.lockLocation(ll, drive.Spec.UUID)()
+
+	// volume might be created on drive after it was selected
+	if volumes, err := m.crHelper.GetVolumesByLocation(ctx, drive.Spec.UUID); err != nil || len(volumes) > 0 {
+		return err
+	}
+	device, err := m.listBlk.SearchDrivePath(&drive.Spec)
+	if err != nil {
+		return err
+	}
+
+	p.MarkStandby(drive, m.standbyPool.fsType, uuid.New().String())
+	if err = m.k8sClient.UpdateCR(ctx, drive); err != nil {
+		return err
+	}
+	if err = m.standbyPool.partition.Prepare(drive, device); err != nil {
+		if releaseErr := m.standbyPool.partition.Release(drive, device); releaseErr != nil {
+			ll.Errorf("Unable to release standby partition: %v", releaseErr)
+			return err
+		}
+		p.UnmarkStandby(drive)
+		if updateErr := m.k8sClient.UpdateCR(ctx, drive); updateErr != nil {
+			ll.Errorf("Unable to remove standby annotations: %v", updateErr)
+		}
+		return err
+	}
+	ll.Infof("Drive %s with FS %s is ready for volumes", drive.Spec.SerialNumber, m.standbyPool.fsType)
+	return nil
+}
+
+// ParseStandbyVolumes parses amount of standby drives in format "HDD=2,SSD=1"
+// Returns map storage class -> amount of standby drives or error if format is wrong
+func ParseStandbyVolumes(str string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("standby volumes %s have wrong format, expected <storage class>=<amount>", item)
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("standby volumes %s have wrong value, expected non-negative number", item)
+		}
+		counts[strings.ToUpper(strings.TrimSpace(parts[0]))] = value
+	}
+	return counts, nil
+}
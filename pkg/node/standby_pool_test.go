@@ -0,0 +1,61 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+)
+
+func TestParseStandbyVolumes(t *testing.T) {
+	counts, err := ParseStandbyVolumes("")
+	assert.Nil(t, err)
+	assert.Empty(t, counts)
+
+	counts, err = ParseStandbyVolumes("hdd=2, SSD=1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"HDD": 2, "SSD": 1}, counts)
+
+	_, err = ParseStandbyVolumes("HDD")
+	assert.NotNil(t, err)
+
+	_, err = ParseStandbyVolumes("HDD=-1")
+	assert.NotNil(t, err)
+}
+
+func TestVolumeManager_isStandbyCandidate(t *testing.T) {
+	vm := prepareSuccessVolumeManager(t)
+	drive := &drivecrd.Drive{Spec: drive1}
+	drive.Spec.IsSystem = false
+	drive.Spec.IsClean = true
+	drive.Spec.Health = apiV1.HealthGood
+	drive.Spec.Status = apiV1.DriveStatusOnline
+	drive.Spec.Usage = apiV1.DriveUsageInUse
+
+	assert.True(t, vm.isStandbyCandidate(drive, drive.Spec.Size, false))
+	// drive has volume
+	assert.False(t, vm.isStandbyCandidate(drive, drive.Spec.Size, true))
+	// part of drive is reserved
+	assert.False(t, vm.isStandbyCandidate(drive, drive.Spec.Size-1, false))
+
+	drive.Spec.Health = apiV1.HealthBad
+	assert.False(t, vm.isStandbyCandidate(drive, drive.Spec.Size, false))
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/transfer"
+)
+
+// volumeDataSource streams content of volume devices of the node to data transfer service
+type volumeDataSource struct {
+	m *VolumeManager
+}
+
+// VolumeDataSource returns source of data transfer service which provides content of volume device by volume ID
+func (m *VolumeManager) VolumeDataSource() transfer.Source {
+	return &volumeDataSource{m: m}
+}
+
+// Open opens device of volume of the node for reading from offset
+// Returns transfer.ErrNotFound if volume isn't located on the node or isn't created yet
+func (s *volumeDataSource) Open(_ context.Context, volumeID string, offset int64) (io.ReadCloser, error) {
+	volumes, err := s.m.cachedCrHelper.GetVolumeCRs(s.m.nodeID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range volumes {
+		volume := &volumes[i]
+		if volume.Spec.Id != volumeID {
+			continue
+		}
+		switch volume.Spec.CSIStatus {
+		case apiV1.Created, apiV1.VolumeReady, apiV1.Published:
+		default:
+			return nil, fmt.Errorf("volume %s in status %s: %w", volumeID, volume.Spec.CSIStatus, transfer.ErrNotFound)
+		}
+		path, err := s.m.getProvisionerForVolume(&volume.Spec).GetVolumePath(&volume.Spec)
+		if err != nil {
+			return nil, err
+		}
+		device, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return nil, err
+		}
+		if _, err = device.Seek(offset, io.SeekStart); err != nil {
+			_ = device.Close()
+			return nil, err
+		}
+		return device, nil
+	}
+	return nil, fmt.Errorf("volume %s: %w", volumeID, transfer.ErrNotFound)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/base/transfer"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+)
+
+func TestVolumeManager_VolumeDataSource(t *testing.T) {
+	var (
+		vm     = prepareSuccessVolumeManager(t)
+		prov   = &mockProv.MockProvisioner{}
+		volume = volCR.DeepCopy()
+		device = filepath.Join(t.TempDir(), "sdb1")
+		source = vm.VolumeDataSource()
+	)
+	vm.provisioners = map[p.VolumeType]p.Provisioner{p.DriveBasedVolumeType: prov}
+	assert.Nil(t, ioutil.WriteFile(device, []byte("volume data"), 0600))
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, volume.Name, volume))
+
+	// volume isn't created yet
+	_, err := source.Open(testCtx, volume.Spec.Id, 0)
+	assert.True(t, errors.Is(err, transfer.ErrNotFound))
+
+	volume.Spec.CSIStatus = apiV1.Published
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+	prov.On("GetVolumePath", mock.Anything).Return(device, nil)
+	reader, err := source.Open(testCtx, volume.Spec.Id, 7)
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "data", string(data))
+	assert.Nil(t, reader.Close())
+
+	_, err = source.Open(testCtx, "unknown", 0)
+	assert.True(t, errors.Is(err, transfer.ErrNotFound))
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// Policies of handling of staging path which can't be unmounted during NodeUnstageVolume,
+// e.g. because file handles of the volume are leaked by processes on the node
+const (
+	// UnstageUnmountPolicyFail fails NodeUnstageVolume after the first unsuccessful unmount
+	UnstageUnmountPolicyFail = "fail"
+	// UnstageUnmountPolicyRetry retries unmount and fails NodeUnstageVolume when all attempts are unsuccessful
+	UnstageUnmountPolicyRetry = "retry"
+	// UnstageUnmountPolicyLazy retries unmount and detaches busy file system with lazy unmount when all attempts
+	// are unsuccessful, volume is tracked and node reports NeedsReboot condition
+	UnstageUnmountPolicyLazy = "lazy"
+)
+
+// NodeConditionNeedsReboot is True when staging paths of volumes were lazily unmounted during current boot
+// and file systems of the volumes are kept busy by leaked file handles
+const NodeConditionNeedsReboot corev1.NodeConditionType = "NeedsReboot"
+
+// unstageUnmountPolicy holds settings of escalation of failed unmount during NodeUnstageVolume
+type unstageUnmountPolicy struct {
+	policy string
+	// number of unmount attempts after the first unsuccessful one
+	retries int
+	// delay between unmount attempts
+	interval time.Duration
+}
+
+// SetUnstageUnmountPolicy sets policy of handling of staging path which can't be unmounted during
+// NodeUnstageVolume: fail, retry or lazy. Receives number of retries and delay between them
+func (m *VolumeManager) SetUnstageUnmountPolicy(policy string, retries int, interval time.Duration) error {
+	switch policy {
+	case UnstageUnmountPolicyFail, UnstageUnmountPolicyRetry, UnstageUnmountPolicyLazy:
+	default:
+		return fmt.Errorf("unstage unmount policy %s isn't supported, expected %s, %s or %s",
+			policy, UnstageUnmountPolicyFail, UnstageUnmountPolicyRetry, UnstageUnmountPolicyLazy)
+	}
+	if retries < 0 {
+		return fmt.Errorf("number of unmount retries must not be negative, got %d", retries)
+	}
+	m.unmountPolicy = &unstageUnmountPolicy{policy: policy, retries: retries, interval: interval}
+	return nil
+}
+
+// unmountStagingPath unmounts staging path of volume according to unstage unmount policy
+// Returns true if path was detached with lazy unmount or error if path is still mounted
+func (m *VolumeManager) unmountStagingPath(path string) (bool, error) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "unmountStagingPath",
+		"path":   path,
+	})
+
+	err := m.fsOps.UnmountWithCheck(path)
+	if err == nil || m.unmountPolicy == nil || m.unmountPolicy.policy == UnstageUnmountPolicyFail {
+		return false, err
+	}
+
+	for i := 1; i <= m.unmountPolicy.retries; i++ {
+		ll.Warnf("Unable to unmount: %v. Retry %d out of %d in %s", err, i, m.unmountPolicy.retries,
+			m.unmountPolicy.interval)
+		time.Sleep(m.unmountPolicy.interval)
+		if err = m.fsOps.UnmountWithCheck(path); err == nil {
+			return false, nil
+		}
+	}
+	if m.unmountPolicy.policy != UnstageUnmountPolicyLazy {
+		return false, err
+	}
+
+	ll.Warnf("Unable to unmount: %v. Perform lazy unmount, file system stays busy until the node is rebooted", err)
+	if lazyErr := m.fsOps.LazyUnmount(path); lazyErr != nil {
+		return false, fmt.Errorf("unable to unmount %s: %v, lazy unmount failed: %v", path, err, lazyErr)
+	}
+	return true, nil
+}
+
+// markLazyUnmounted records boot ID of the node in annotation of volume which staging path was lazily unmounted,
+// sends event and sets NeedsReboot condition for the node
+func (m *VolumeManager) markLazyUnmounted(ctx context.Context, volume *volumecrd.Volume) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "markLazyUnmounted",
+		"volumeID": volume.Spec.Id,
+	})
+
+	k8sNode := &corev1.Node{}
+	if err := m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: m.nodeName}, k8sNode); err != nil {
+		ll.Errorf("Unable to read node %s: %v", m.nodeName, err)
+		return
+	}
+	if volume.Annotations == nil {
+		volume.Annotations = map[string]string{}
+	}
+	volume.Annotations[apiV1.VolumeAnnotationLazyUnmount] = k8sNode.Status.NodeInfo.BootID
+	m.recorder.Eventf(volume, eventing.VolumeLazyUnmounted,
+		"Volume %s was lazily unmounted, file system is busy until node %s is rebooted", volume.Spec.Id, m.nodeName)
+
+	// volume CR isn't updated yet, so it is added to the volumes which are found in cache
+	if err := m.reportNeedsReboot(ctx, volume.Spec.Id); err != nil {
+		ll.Errorf("Unable to set %s condition: %v", NodeConditionNeedsReboot, err)
+	}
+}
+
+// reportNeedsReboot sets NeedsReboot condition for the node based on volumes which were lazily unmounted
+// during current boot of the node. Receives IDs of volumes which aren't recorded in Volume CRs yet
+func (m *VolumeManager) reportNeedsReboot(ctx context.Context, volumeIDs ...string) error {
+	if m.unmountPolicy == nil || m.unmountPolicy.policy != UnstageUnmountPolicyLazy {
+		return nil
+	}
+
+	k8sNode := &corev1.Node{}
+	if err := m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: m.nodeName}, k8sNode); err != nil {
+		return err
+	}
+	volumes, err := m.cachedCrHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	bootID := k8sNode.Status.NodeInfo.BootID
+	for _, v := range volumes {
+		if val, ok := v.Annotations[apiV1.VolumeAnnotationLazyUnmount]; ok && val == bootID &&
+			!util.ContainsString(volumeIDs, v.Spec.Id) {
+			volumeIDs = append(volumeIDs, v.Spec.Id)
+		}
+	}
+
+	cond := corev1.NodeCondition{Type: NodeConditionNeedsReboot, Status: corev1.ConditionFalse,
+		Reason: "NoLazyUnmounts", Message: "Staging paths of all volumes were unmounted"}
+	if len(volumeIDs) > 0 {
+		sort.Strings(volumeIDs)
+		cond.Status = corev1.ConditionTrue
+		cond.Reason = "LazyUnmount"
+		cond.Message = fmt.Sprintf("Volumes were lazily unmounted because of leaked file handles: %s",
+			strings.Join(volumeIDs, ", "))
+	}
+	if setNodeCondition(k8sNode, cond) {
+		m.log.WithField("method", "reportNeedsReboot").Warnf("Node condition %s is True: %s", cond.Type, cond.Message)
+		m.recorder.Eventf(k8sNode, eventing.NodeNeedsReboot, "%s", cond.Message)
+	}
+	return m.k8sClient.Status().Update(ctx, k8sNode)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mockProv "github.com/dell/csi-baremetal/pkg/mocks/provisioners"
+)
+
+func TestVolumeManager_SetUnstageUnmountPolicy(t *testing.T) {
+	vm := prepareSuccessVolumeManager(t)
+
+	assert.NotNil(t, vm.SetUnstageUnmountPolicy("detach", 1, 0))
+	assert.NotNil(t, vm.SetUnstageUnmountPolicy(UnstageUnmountPolicyRetry, -1, 0))
+	assert.Nil(t, vm.unmountPolicy)
+	assert.Nil(t, vm.SetUnstageUnmountPolicy(UnstageUnmountPolicyLazy, 2, 0))
+	assert.Equal(t, UnstageUnmountPolicyLazy, vm.unmountPolicy.policy)
+}
+
+func TestVolumeManager_unmountStagingPath(t *testing.T) {
+	var (
+		vm    = prepareSuccessVolumeManager(t)
+		fsOps = &mockProv.MockFsOpts{}
+		path  = "/staging/dev"
+		busy  = errors.New("target is busy")
+	)
+	vm.fsOps = fsOps
+
+	// policy isn't set, first error is returned
+	fsOps.On("UnmountWithCheck", path).Return(busy).Once()
+	lazy, err := vm.unmountStagingPath(path)
+	assert.False(t, lazy)
+	assert.Equal(t, busy, err)
+
+	// retry succeeds
+	assert.Nil(t, vm.SetUnstageUnmountPolicy(UnstageUnmountPolicyRetry, 2, 0))
+	fsOps.On("UnmountWithCheck", path).Return(busy).Once()
+	fsOps.On("UnmountWithCheck", path).Return(nil).Once()
+	lazy, err = vm.unmountStagingPath(path)
+	assert.False(t, lazy)
+	assert.Nil(t, err)
+
+	// all retries fail
+	fsOps.On("UnmountWithCheck", path).Return(busy).Times(3)
+	_, err = vm.unmountStagingPath(path)
+	assert.NotNil(t, err)
+	fsOps.AssertNotCalled(t, "LazyUnmount", path)
+
+	// lazy unmount after retries
+	assert.Nil(t, vm.SetUnstageUnmountPolicy(UnstageUnmountPolicyLazy, 1, 0))
+	fsOps.On("UnmountWithCheck", path).Return(busy).Times(2)
+	fsOps.On("LazyUnmount", path).Return(nil).Once()
+	lazy, err = vm.unmountStagingPath(path)
+	assert.True(t, lazy)
+	assert.Nil(t, err)
+
+	// lazy unmount fails
+	fsOps.On("UnmountWithCheck", path).Return(busy).Times(2)
+	fsOps.On("LazyUnmount", path).Return(errors.New("error")).Once()
+	lazy, err = vm.unmountStagingPath(path)
+	assert.False(t, lazy)
+	assert.NotNil(t, err)
+}
+
+func TestVolumeManager_markLazyUnmounted(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		k8sNode  = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		updated  = &corev1.Node{}
+		volume   = testVolumeCR1.DeepCopy()
+	)
+	vm.recorder = recorder
+	k8sNode.Status.NodeInfo.BootID = "boot-1"
+	assert.Nil(t, vm.k8sClient.Create(testCtx, k8sNode))
+	assert.Nil(t, vm.SetUnstageUnmountPolicy(UnstageUnmountPolicyLazy, 0, 0))
+
+	// volume isn't lazily unmounted
+	assert.Nil(t, vm.reportNeedsReboot(testCtx))
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+	assert.Equal(t, corev1.ConditionFalse, getNodeCondition(updated, NodeConditionNeedsReboot).Status)
+
+	vm.markLazyUnmounted(testCtx, volume)
+	assert.Equal(t, "boot-1", volume.Annotations[apiV1.VolumeAnnotationLazyUnmount])
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+	cond := getNodeCondition(updated, NodeConditionNeedsReboot)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Contains(t, cond.Message, volume.Spec.Id)
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.VolumeLazyUnmounted, recorder.Calls[0].Event)
+	assert.Equal(t, eventing.NodeNeedsReboot, recorder.Calls[1].Event)
+
+	// volume CR is updated, condition is still True during Discover
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, volume.Name, volume))
+	assert.Nil(t, vm.reportNeedsReboot(testCtx))
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+	assert.Equal(t, corev1.ConditionTrue, getNodeCondition(updated, NodeConditionNeedsReboot).Status)
+	assert.Len(t, recorder.Calls, 2)
+
+	// node is rebooted
+	updated.Status.NodeInfo.BootID = "boot-2"
+	assert.Nil(t, vm.k8sClient.Status().Update(testCtx, updated))
+	assert.Nil(t, vm.reportNeedsReboot(testCtx))
+	assert.Nil(t, vm.k8sClient.Get(testCtx, k8sCl.ObjectKey{Name: nodeName}, updated))
+	assert.Equal(t, corev1.ConditionFalse, getNodeCondition(updated, NodeConditionNeedsReboot).Status)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// ValidateVolumeDevices re-resolves backing devices of volumes owned by the node after node service start
+// (device names might be changed after reboot). Partition based volumes are searched by PARTUUID, LVM based
+// volumes are searched by LV name in VG. If partition is found on another drive, Volume location is fixed.
+// If backing device is not found, Volume operational status is set to MISSING and event is sent.
+// Returns error if volumes or block devices can't be read
+func (m *VolumeManager) ValidateVolumeDevices(ctx context.Context) error {
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "ValidateVolumeDevices",
+	})
+
+	volumes, err := m.crHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	drives, err := m.crHelper.GetDriveCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	devices, err := m.listBlk.GetBlockDevices("")
+	if err != nil {
+		return err
+	}
+
+	// key - PARTUUID in lower case, value - serial number of the parent device
+	partitions := make(map[string]string)
+	for _, dev := range devices {
+		for _, child := range dev.Children {
+			if child.PartUUID != "" {
+				partitions[strings.ToLower(child.PartUUID)] = dev.Serial
+			}
+		}
+	}
+	// key - drive serial number, value - drive UUID
+	driveBySerial := make(map[string]string, len(drives))
+	for _, d := range drives {
+		driveBySerial[d.Spec.SerialNumber] = d.Spec.UUID
+	}
+
+	for i := range volumes {
+		vol := &volumes[i]
+		switch vol.Spec.CSIStatus {
+		case apiV1.Created, apiV1.VolumeReady, apiV1.Published:
+		default:
+			continue
+		}
+
+		var location string
+		switch {
+		case vol.Spec.LocationType == apiV1.LocationTypeLVM:
+			location, err = m.resolveLVMVolumeLocation(vol)
+		case vol.Spec.Mode == apiV1.ModeRAW || vol.Spec.Ephemeral:
+			// whole drive is used or PARTUUID isn't known, drive status is handled by drive controller
+			continue
+		default:
+			location, err = m.resolvePartitionVolumeLocation(vol, partitions, driveBySerial)
+		}
+		if err != nil {
+			ll.Errorf("Unable to find backing device for volume %s: %v", vol.Name, err)
+			m.recorder.Eventf(vol, eventing.VolumeDeviceMissing,
+				"Backing device for volume on node %s is not found: %v", m.nodeName, err)
+			if err = m.crHelper.UpdateVolumeOpStatus(ctx, vol, apiV1.OperationalStatusMissing); err != nil {
+				ll.Errorf("Unable to update operational status for volume %s: %v", vol.Name, err)
+			}
+			continue
+		}
+		if location == vol.Spec.Location {
+			continue
+		}
+		ll.Warnf("Backing device for volume %s is found on drive %s instead of %s",
+			vol.Name, location, vol.Spec.Location)
+		m.recorder.Eventf(vol, eventing.VolumeLocationChanged,
+			"Backing device for volume is found on drive %s instead of %s", location, vol.Spec.Location)
+		vol.Spec.Location = location
+		if err = m.k8sClient.UpdateCR(ctx, vol); err != nil {
+			ll.Errorf("Unable to update location for volume %s: %v", vol.Name, err)
+		}
+	}
+	return nil
+}
+
+// resolvePartitionVolumeLocation returns UUID of the drive which holds partition with volume PARTUUID
+// PARTUUID of current and legacy partition schemes are checked
+func (m *VolumeManager) resolvePartitionVolumeLocation(vol *volumecrd.Volume, partitions map[string]string,
+	driveBySerial map[string]string) (string, error) {
+	candidates := m.partScheme.PartUUIDCandidates(vol.Spec.Id)
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("volume ID %s is empty", vol.Spec.Id)
+	}
+	var (
+		partUUID string
+		serial   string
+		ok       bool
+	)
+	// partition might be created with legacy scheme
+	for _, partUUID = range candidates {
+		if serial, ok = partitions[strings.ToLower(partUUID)]; ok {
+			break
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("partition with PARTUUID %s is not found", strings.Join(candidates, " or "))
+	}
+	driveUUID, ok := driveBySerial[serial]
+	if !ok {
+		return "", fmt.Errorf("partition with PARTUUID %s is found on unknown drive with S/N %s", partUUID, serial)
+	}
+	return driveUUID, nil
+}
+
+// resolveLVMVolumeLocation checks that LV exists in VG, location of LVM volumes isn't changed
+func (m *VolumeManager) resolveLVMVolumeLocation(vol *volumecrd.Volume) (string, error) {
+	vgName := vol.Spec.Location
+	switch {
+	case vol.Spec.Template != "":
+		// volume is a snapshot of template LV
+		if m.templateVG == "" {
+			return "", fmt.Errorf("volume is created from template %s, but template volume group isn't set",
+				vol.Spec.Template)
+		}
+		vgName = m.templateVG
+	case vol.Spec.StorageClass == apiV1.StorageClassSystemLVG:
+		var err error
+		if vgName, err = m.crHelper.GetVGNameByLVGCRName(vol.Spec.Location); err != nil {
+			return "", err
+		}
+	}
+	lvs, err := m.lvmOps.GetLVsInVG(vgName)
+	if err != nil {
+		return "", err
+	}
+	if !util.ContainsString(lvs, vol.Spec.Id) {
+		return "", fmt.Errorf("logical volume %s is not found in volume group %s", vol.Spec.Id, vgName)
+	}
+	return vol.Spec.Location, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	vcrd "github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+func TestVolumeManager_ValidateVolumeDevices(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		listBlk  = &mocklu.MockWrapLsblk{}
+		lvmOps   = &mocklu.MockWrapLVM{}
+	)
+	vm.recorder = recorder
+	vm.listBlk = listBlk
+	vm.lvmOps = lvmOps
+	addDriveCRs(vm.k8sClient, vm.k8sClient.ConstructDriveCR(disk1.UUID, disk1),
+		vm.k8sClient.ConstructDriveCR(disk2.UUID, disk2))
+
+	// device names were changed after reboot, partition of volume 1 is found on the second drive
+	moved := testVolumeCR1.DeepCopy()
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, moved.Name, moved))
+	// partition of volume 2 isn't found
+	vanished := testVolumeCR2.DeepCopy()
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, vanished.Name, vanished))
+	lvmVolume := testVolumeCR3.DeepCopy()
+	lvmVolume.Spec.Location = testLVGName
+	lvmVolume.Spec.LocationType = apiV1.LocationTypeLVM
+	lvmVolume.Spec.CSIStatus = apiV1.Published
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, lvmVolume.Name, lvmVolume))
+
+	listBlk.On("GetBlockDevices", "").Return([]lsblk.BlockDevice{
+		{Name: "/dev/sda", Serial: disk2.SerialNumber, Children: []lsblk.BlockDevice{{Name: "/dev/sda1", PartUUID: testV1ID}}},
+		{Name: "/dev/sdb", Serial: disk1.SerialNumber},
+	}, nil)
+	lvmOps.On("GetLVsInVG", testLVGName).Return([]string{testV3ID}, nil)
+
+	assert.Nil(t, vm.ValidateVolumeDevices(testCtx))
+
+	vol := &vcrd.Volume{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, moved.Name, testNs, vol))
+	assert.Equal(t, disk2.UUID, vol.Spec.Location)
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, vanished.Name, testNs, vol))
+	assert.Equal(t, apiV1.OperationalStatusMissing, vol.Spec.OperationalStatus)
+	// empty fields are omitted, so volume is read into the new object
+	vol = &vcrd.Volume{}
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, lvmVolume.Name, testNs, vol))
+	assert.Empty(t, vol.Spec.OperationalStatus)
+
+	assert.Len(t, recorder.Calls, 2)
+	events := []*eventing.EventDescription{recorder.Calls[0].Event, recorder.Calls[1].Event}
+	assert.Contains(t, events, eventing.VolumeLocationChanged)
+	assert.Contains(t, events, eventing.VolumeDeviceMissing)
+}
+
+func TestVolumeManager_resolveLVMVolumeLocation_Template(t *testing.T) {
+	var (
+		vm     = prepareSuccessVolumeManager(t)
+		lvmOps = &mocklu.MockWrapLVM{}
+		vol    = testVolumeCR3.DeepCopy()
+	)
+	vm.lvmOps = lvmOps
+	vol.Spec.Location = testLVGName
+	vol.Spec.LocationType = apiV1.LocationTypeLVM
+	vol.Spec.Template = "ubuntu-20.04"
+
+	// template volume group isn't set
+	_, err := vm.resolveLVMVolumeLocation(vol)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, vm.SetTemplateVolumeGroup("templates"))
+	lvmOps.On("GetLVsInVG", "templates").Return([]string{testV3ID}, nil)
+	location, err := vm.resolveLVMVolumeLocation(vol)
+	assert.Nil(t, err)
+	assert.Equal(t, testLVGName, location)
+}
@@ -0,0 +1,245 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicepath"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/diskstats"
+)
+
+// Labels of per-volume I/O metrics
+const (
+	ioStatsLabelVolumeID  = "volume_id"
+	ioStatsLabelPVC       = "pvc"
+	ioStatsLabelNamespace = "namespace"
+	ioStatsLabelOperation = "operation"
+	ioStatsOperationRead  = "read"
+	ioStatsOperationWrite = "write"
+)
+
+// volumeClaim is a PersistentVolumeClaim of volume
+type volumeClaim struct {
+	name      string
+	namespace string
+}
+
+// volumeIOStats collects diskstats of volume devices and exposes IOPS, throughput and latency of volumes
+type volumeIOStats struct {
+	diskstatsPath string
+	// devices holds kernel name of device per volume ID
+	devices map[string]string
+	// claims holds PersistentVolumeClaim per volume ID
+	claims map[string]volumeClaim
+	// exposed holds PersistentVolumeClaim which metrics of volume are labeled with per volume ID
+	exposed map[string]volumeClaim
+	// prev holds diskstats of previous collection per volume ID
+	prev     map[string]diskstats.Stats
+	prevTime time.Time
+
+	iops       *prometheus.GaugeVec
+	throughput *prometheus.GaugeVec
+	latency    *prometheus.GaugeVec
+}
+
+// SetVolumeIOStats enables collection of per-volume I/O statistics by CollectVolumeIOStats
+func (m *VolumeManager) SetVolumeIOStats() {
+	labels := []string{ioStatsLabelVolumeID, ioStatsLabelPVC, ioStatsLabelNamespace, ioStatsLabelOperation}
+	s := &volumeIOStats{
+		diskstatsPath: diskstats.DefaultPath,
+		devices:       map[string]string{},
+		claims:        map[string]volumeClaim{},
+		exposed:       map[string]volumeClaim{},
+		prev:          map[string]diskstats.Stats{},
+		iops: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "volume_iops",
+			Help: "I/O operations per second of volume",
+		}, labels),
+		throughput: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "volume_throughput_bytes_per_second",
+			Help: "bytes read or written per second of volume",
+		}, labels),
+		latency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "volume_io_latency_seconds",
+			Help: "average latency of I/O operation of volume",
+		}, labels),
+	}
+	for _, metric := range []prometheus.Collector{s.iops, s.throughput, s.latency} {
+		if err := prometheus.Register(metric); err != nil {
+			m.log.WithField("method", "SetVolumeIOStats").Errorf("Failed to register metric: %v", err)
+		}
+	}
+	m.ioStats = s
+}
+
+// CollectVolumeIOStats reads diskstats of devices of staged volumes and updates I/O metrics of volumes
+// Rates are calculated between two subsequent calls
+func (m *VolumeManager) CollectVolumeIOStats(ctx context.Context) error {
+	if m.ioStats == nil {
+		return nil
+	}
+	ll := m.log.WithField("method", "CollectVolumeIOStats")
+
+	volumes, err := m.cachedCrHelper.GetVolumeCRs(m.nodeID)
+	if err != nil {
+		return err
+	}
+	stats, err := diskstats.Read(m.ioStats.diskstatsPath)
+	if err != nil {
+		return err
+	}
+	var (
+		now      = time.Now()
+		interval = now.Sub(m.ioStats.prevTime).Seconds()
+		current  = make(map[string]diskstats.Stats, len(volumes))
+		sectors  = make(map[string]uint64, len(volumes))
+	)
+	for i := range volumes {
+		volume := &volumes[i]
+		if volume.Spec.CSIStatus != apiV1.VolumeReady && volume.Spec.CSIStatus != apiV1.Published {
+			continue
+		}
+		cur, ok := m.volumeDiskStats(volume, stats)
+		if !ok {
+			ll.WithField("volumeID", volume.Spec.Id).Debugf("Diskstats of volume device aren't found")
+			continue
+		}
+		current[volume.Spec.Id] = cur
+		prev, ok := m.ioStats.prev[volume.Spec.Id]
+		// counters are reset when device appears again
+		if !ok || interval <= 0 || cur.ReadsCompleted < prev.ReadsCompleted ||
+			cur.WritesCompleted < prev.WritesCompleted {
+			continue
+		}
+		claim := m.volumeClaim(ctx, volume)
+		m.ioStats.set(volume.Spec.Id, claim, ioStatsOperationRead, cur.ReadsCompleted-prev.ReadsCompleted,
+			cur.ReadSectors-prev.ReadSectors, cur.ReadTimeMs-prev.ReadTimeMs, interval)
+		m.ioStats.set(volume.Spec.Id, claim, ioStatsOperationWrite, cur.WritesCompleted-prev.WritesCompleted,
+			cur.WriteSectors-prev.WriteSectors, cur.WriteTimeMs-prev.WriteTimeMs, interval)
+		sectors[volume.Spec.Id] = cur.ReadSectors - prev.ReadSectors + cur.WriteSectors - prev.WriteSectors
+	}
+	if err := m.detectNoisyNeighbors(ctx, volumes, sectors, stats, now, interval); err != nil {
+		ll.Errorf("Unable to detect noisy neighbors: %v", err)
+	}
+
+	// metrics of volumes which were unstaged or removed are deleted
+	for volumeID := range m.ioStats.exposed {
+		if _, ok := current[volumeID]; !ok {
+			m.ioStats.delete(volumeID)
+		}
+	}
+	for volumeID := range m.ioStats.devices {
+		if _, ok := current[volumeID]; !ok {
+			delete(m.ioStats.devices, volumeID)
+			delete(m.ioStats.claims, volumeID)
+		}
+	}
+	m.ioStats.prev, m.ioStats.prevTime = current, now
+	return nil
+}
+
+// volumeDiskStats returns diskstats of volume device, device of volume is resolved once and is cached
+func (m *VolumeManager) volumeDiskStats(volume *volumecrd.Volume,
+	stats map[string]diskstats.Stats) (diskstats.Stats, bool) {
+	if device, ok := m.ioStats.devices[volume.Spec.Id]; ok {
+		if cur, ok := stats[device]; ok {
+			return cur, true
+		}
+	}
+	// device name might be changed after reboot
+	path, err := m.getProvisionerForVolume(&volume.Spec).GetVolumePath(&volume.Spec)
+	if err != nil {
+		return diskstats.Stats{}, false
+	}
+	device := filepath.Base(path)
+	// LVM volume path is a symlink to device mapper device
+	if id, err := devicepath.Resolve(path); err == nil {
+		device = id.Name
+	}
+	m.ioStats.devices[volume.Spec.Id] = device
+	cur, ok := stats[device]
+	return cur, ok
+}
+
+// volumeClaim returns PersistentVolumeClaim of volume from claim reference of PersistentVolume
+// Claim name is empty for ephemeral volumes and if PersistentVolume can't be read
+func (m *VolumeManager) volumeClaim(ctx context.Context, volume *volumecrd.Volume) volumeClaim {
+	if claim, ok := m.ioStats.claims[volume.Spec.Id]; ok {
+		return claim
+	}
+	claim := volumeClaim{namespace: volume.Namespace}
+	pv := &corev1.PersistentVolume{}
+	if volume.Spec.Ephemeral {
+		m.ioStats.claims[volume.Spec.Id] = claim
+		return claim
+	}
+	if err := m.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: volume.Spec.Id}, pv); err != nil {
+		m.log.WithFields(logrus.Fields{
+			"method":   "volumeClaim",
+			"volumeID": volume.Spec.Id,
+		}).Debugf("Unable to read PersistentVolume: %v", err)
+		return claim
+	}
+	if pv.Spec.ClaimRef != nil {
+		claim = volumeClaim{name: pv.Spec.ClaimRef.Name, namespace: pv.Spec.ClaimRef.Namespace}
+	}
+	m.ioStats.claims[volume.Spec.Id] = claim
+	return claim
+}
+
+// set updates metrics of operation of volume with deltas of completed operations, sectors and time in milliseconds
+func (s *volumeIOStats) set(volumeID string, claim volumeClaim, operation string, ops, sectors, timeMs uint64,
+	interval float64) {
+	if exposed, ok := s.exposed[volumeID]; ok && exposed != claim {
+		s.delete(volumeID)
+	}
+	s.exposed[volumeID] = claim
+	labels := prometheus.Labels{
+		ioStatsLabelVolumeID:  volumeID,
+		ioStatsLabelPVC:       claim.name,
+		ioStatsLabelNamespace: claim.namespace,
+		ioStatsLabelOperation: operation,
+	}
+	s.iops.With(labels).Set(float64(ops) / interval)
+	s.throughput.With(labels).Set(float64(sectors*diskstats.SectorSize) / interval)
+	latency := 0.0
+	if ops > 0 {
+		latency = float64(timeMs) / float64(ops) / 1000
+	}
+	s.latency.With(labels).Set(latency)
+}
+
+// delete removes metrics of volume
+func (s *volumeIOStats) delete(volumeID string) {
+	claim := s.exposed[volumeID]
+	for _, metric := range []*prometheus.GaugeVec{s.iops, s.throughput, s.latency} {
+		for _, operation := range []string{ioStatsOperationRead, ioStatsOperationWrite} {
+			metric.DeleteLabelValues(volumeID, claim.name, claim.namespace, operation)
+		}
+	}
+	delete(s.exposed, volumeID)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+)
+
+func TestVolumeManager_CollectVolumeIOStats(t *testing.T) {
+	var (
+		vm     = prepareSuccessVolumeManager(t)
+		volume = volCR.DeepCopy()
+		path   = filepath.Join(t.TempDir(), "diskstats")
+		pv     = &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: volume.Spec.Id},
+			Spec: corev1.PersistentVolumeSpec{
+				ClaimRef: &corev1.ObjectReference{Name: "data", Namespace: "app"},
+			},
+		}
+	)
+	// disabled
+	assert.Nil(t, vm.CollectVolumeIOStats(testCtx))
+
+	vm.SetVolumeIOStats()
+	vm.ioStats.diskstatsPath = path
+	vm.ioStats.devices[volume.Spec.Id] = "sdb1"
+	volume.Spec.CSIStatus = apiV1.Published
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, volume.Name, volume))
+	assert.Nil(t, vm.k8sClient.Create(testCtx, pv))
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("8 17 sdb1 100 0 800 50 20 0 160 30 0 70 80\n"), 0600))
+	assert.Nil(t, vm.CollectVolumeIOStats(testCtx))
+	// rates aren't calculated after the first collection
+	assert.Equal(t, 0, testutil.CollectAndCount(vm.ioStats.iops))
+
+	vm.ioStats.prevTime = time.Now().Add(-10 * time.Second)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("8 17 sdb1 200 0 1600 250 20 0 160 30 0 70 80\n"), 0600))
+	assert.Nil(t, vm.CollectVolumeIOStats(testCtx))
+	assert.Equal(t, 2, testutil.CollectAndCount(vm.ioStats.iops))
+	read := vm.ioStats.iops.WithLabelValues(volume.Spec.Id, "data", "app", ioStatsOperationRead)
+	assert.InDelta(t, 10, testutil.ToFloat64(read), 0.1)
+	throughput := vm.ioStats.throughput.WithLabelValues(volume.Spec.Id, "data", "app", ioStatsOperationRead)
+	assert.InDelta(t, 800*512/10, testutil.ToFloat64(throughput), 500)
+	latency := vm.ioStats.latency.WithLabelValues(volume.Spec.Id, "data", "app", ioStatsOperationRead)
+	assert.InDelta(t, 0.002, testutil.ToFloat64(latency), 0.0001)
+
+	// volume is unstaged
+	volume.Spec.CSIStatus = apiV1.Created
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, volume))
+	assert.Nil(t, vm.CollectVolumeIOStats(testCtx))
+	assert.Equal(t, 0, testutil.CollectAndCount(vm.ioStats.iops))
+	assert.Empty(t, vm.ioStats.devices)
+}
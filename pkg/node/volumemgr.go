@@ -18,6 +18,7 @@ package node
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -35,7 +36,6 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
 	crevent "sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
@@ -45,13 +45,21 @@ import (
 	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
 	"github.com/dell/csi-baremetal/api/v1/volumecrd"
 	"github.com/dell/csi-baremetal/pkg/base"
+	"github.com/dell/csi-baremetal/pkg/base/audit"
+	"github.com/dell/csi-baremetal/pkg/base/circuitbreaker"
 	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/ctrlopts"
+	"github.com/dell/csi-baremetal/pkg/base/diagnostics"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/cryptsetup"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/datadiscover"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/datadiscover/types"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/devicegraph"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
 	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lvm"
 	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/base/lvmbackup"
+	"github.com/dell/csi-baremetal/pkg/base/shutdown"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	"github.com/dell/csi-baremetal/pkg/common"
 	"github.com/dell/csi-baremetal/pkg/eventing"
@@ -64,7 +72,7 @@ import (
 )
 
 const (
-	volumeFinalizer = "dell.emc.csi/volume-cleanup"
+	volumeFinalizer = apiV1.VolumeFinalizer
 
 	deleteVolumeFailedMsg = "Failed to remove volume %s with error: %s"
 
@@ -105,6 +113,8 @@ type VolumeManager struct {
 	lvmOps lvm.WrapLVM
 	// uses for running lsblk util
 	listBlk lsblk.WrapLsblk
+	// uses for opening and closing LUKS devices of encrypted volumes
+	cryptOps cryptsetup.WrapCryptsetup
 	// uses for disable/enable WBT
 	wbtOps    wbtops.WrapWbt
 	wbtConfig *wbtconf.WbtConfig
@@ -127,6 +137,12 @@ type VolumeManager struct {
 	recorder eventRecorder
 	// reconcile lock
 	volMu keymutex.KeyMutex
+	// serializes operations on the same drive or LogicalVolumeGroup, volumes on different locations are handled in parallel
+	locMu keymutex.KeyMutex
+	// prepares standby drives with pre-created filesystem, nil if disabled
+	standbyPool *standbyPool
+	// creates filesystem of volumes in background, nil if formatting is synchronous
+	asyncFormatter *asyncFormatter
 	// systemDrivesUUIDs represent system drive uuids, used to avoid unnecessary calls to Kubernetes API.
 	// We use slice in case of RAID and multiple system disks
 	systemDrivesUUIDs []string
@@ -137,6 +153,68 @@ type VolumeManager struct {
 
 	// discover data on drive
 	dataDiscover types.WrapDataDiscover
+	// checks drive temperature during Discover, nil if temperature monitoring is disabled
+	temperatureMonitor *driveTemperatureMonitor
+	// marks drives with I/O errors in kernel log as SUSPECT during Discover, nil if it is disabled
+	ioErrorsMonitor *ioErrorsMonitor
+	// stops commands to drives with consecutive failed operations, nil if it is disabled
+	driveBreaker *circuitbreaker.Breaker
+	// sets node-problem-detector compatible conditions for the node during Discover, nil if it is disabled
+	conditionsReporter *nodeConditionsReporter
+	// escalation of failed unmount of staging path during NodeUnstageVolume, nil means fail on the first error
+	unmountPolicy *unstageUnmountPolicy
+	// detects mounts of volumes at unexpected target paths during Discover, nil if detection is disabled
+	duplicateMounts *duplicateMountsReconciler
+	// detects misaligned partitions of volumes during Discover and realigns them, nil if audit is disabled
+	partitionAlignment *partitionAligner
+	// records destructive operations into audit trail, nil if audit is disabled
+	auditor *audit.Auditor
+	// root directory of kubelet, base.KubeletRootDir is used if empty
+	kubeletRootDir string
+	// fronts HDD volumes with cache mode by bcache devices, nil if cache tier isn't configured
+	cacheTier *p.CacheTier
+	// exposes per-volume I/O metrics, nil if collection is disabled
+	ioStats *volumeIOStats
+	// detects volumes which saturate drives of volume group, nil if detection is disabled
+	noisyNeighbors *noisyNeighborDetector
+	// detects drives which are installed in slots of replaced drives, nil if detection is disabled
+	replacementDrives *replacementDrives
+	// runs burn-in tests of new drives, nil if burn-in is disabled
+	burnIn *burnInRunner
+	// storage self-test of node, nil if self-test is disabled
+	selfTest *selfTest
+	// adapts interval between Discover calls to changes of drives
+	discovery *discoveryInterval
+	// runs site-specific hooks of provisioning pipeline, nil if hooks are disabled
+	hooks hookRunner
+	// tracks in-flight volume operations which are drained during graceful shutdown
+	operations *shutdown.Tracker
+	// result of mount propagation verification of kubelet root directory, health check fails if it isn't nil
+	mountPropagationErr error
+	// drive attributes which are set as labels of PersistentVolume, empty if propagation is disabled
+	pvDriveLabels []string
+	// partScheme describes GUID and label of volume partitions, nil means legacy scheme
+	partScheme *p.PartitionScheme
+	// volume group with template LVs which volumes with template are snapshots of, empty if templates are disabled
+	templateVG string
+	// whether partition GUID and filesystem UUID of volume are recorded and verified before release or not
+	ownershipVerification bool
+	// foreignDataScan holds new drives out of the free pool until they are scanned for foreign data
+	foreignDataScan bool
+	// partUUIDReport makes node annotate Drive CRs with GUIDs of their partitions for check of duplicates
+	partUUIDReport bool
+	// exports read-only snapshots of volumes over NBD, nil if export is disabled
+	nbdExport *nbdExporter
+	// controls volatile write cache of drives per drive type, nil if write cache isn't controlled
+	writeCache *writeCachePolicy
+	// uses space added to drives, nil if growth of drives is disabled
+	driveGrowth ph.WrapGrow
+	// verifies queue attributes of drives per drive type, nil if queue tuning isn't configured
+	queueTuning *queueTuning
+	// archives metadata of volume groups, nil if LVM metadata backup is disabled
+	lvmBackup *lvmbackup.Archive
+	// expands LVG volumes which file systems are filled up, nil if autogrow is disabled
+	autogrow *volumeAutogrow
 }
 
 // driveStates internal struct, holds info about drive updates
@@ -223,6 +301,7 @@ func NewVolumeManager(
 		fsOps:                  fsOps,
 		lvmOps:                 lvmOps,
 		listBlk:                lsblk.NewLSBLK(logger),
+		cryptOps:               cryptsetup.NewCryptsetup(executor),
 		partOps:                partImpl,
 		wbtOps:                 wbtOps,
 		nodeID:                 nodeID,
@@ -230,11 +309,14 @@ func NewVolumeManager(
 		log:                    logger.WithField("component", "VolumeManager"),
 		recorder:               recorder,
 		discoverSystemLVG:      true,
-		volMu:                  keymutex.NewHashed(0),
+		volMu:                  diagnostics.NewKeyMutex("volume-manager-volumes", keymutex.NewHashed(0)),
+		locMu:                  diagnostics.NewKeyMutex("volume-manager-locations", keymutex.NewHashed(0)),
 		systemDrivesUUIDs:      make([]string, 0),
 		metricDriveMgrDuration: driveMgrDuration,
 		metricDriveMgrCount:    driveMgrCount,
 		dataDiscover:           datadiscover.NewDataDiscover(fsOps, partImpl, lvmOps),
+		operations:             shutdown.NewTracker(),
+		discovery:              newDiscoveryInterval(DefaultDiscoverFastInterval, DefaultDiscoverInterval),
 	}
 	return vm
 }
@@ -245,6 +327,82 @@ func (m *VolumeManager) SetProvisioners(provs map[p.VolumeType]p.Provisioner) {
 	m.provisioners = provs
 }
 
+// SetPartitionScheme sets scheme of GUID, label and type of volume partitions for drive provisioner
+// and for search of volume partitions, configured partition types are used for data discovery
+// Returns error if drive provisioner or data discovery doesn't support partition scheme
+func (m *VolumeManager) SetPartitionScheme(scheme *p.PartitionScheme) error {
+	dp, err := m.driveProvisioner()
+	if err != nil {
+		return err
+	}
+	dd, err := m.wrapDataDiscover()
+	if err != nil {
+		return err
+	}
+	m.partScheme = scheme
+	dp.SetPartitionScheme(scheme)
+	dd.SetManagedPartitionTypes(scheme.ManagedTypeGUIDs())
+	return nil
+}
+
+// SetTemplateVolumeGroup enables template volumes: LVG volumes with template are created as thin snapshots
+// of template LVs of volume group vg
+// Returns error if LVM provisioner doesn't support template volumes
+func (m *VolumeManager) SetTemplateVolumeGroup(vg string) error {
+	lp, ok := m.provisioners[p.LVMBasedVolumeType].(*p.LVMProvisioner)
+	if !ok {
+		return fmt.Errorf("LVM provisioner %T doesn't support template volumes", m.provisioners[p.LVMBasedVolumeType])
+	}
+	m.templateVG = vg
+	lp.SetTemplateVolumeGroup(vg)
+	return nil
+}
+
+// SetBusyPartitionPolicy sets policy of handling of volume partition which is held by leftover devices
+// or mounts during release: report or remove
+// Returns error if policy is unknown or drive provisioner doesn't support it
+func (m *VolumeManager) SetBusyPartitionPolicy(policy string) error {
+	dp, err := m.driveProvisioner()
+	if err != nil {
+		return err
+	}
+	return dp.SetBusyPartitionPolicy(policy)
+}
+
+// SetDeviceGraph enables checks based on graph of block devices: drive which is used by other devices
+// or mounted isn't clean during Discover and volume isn't created on it
+// Returns error if drive provisioner or data discovery doesn't support device graph
+func (m *VolumeManager) SetDeviceGraph(graph devicegraph.Reader) error {
+	dp, err := m.driveProvisioner()
+	if err != nil {
+		return err
+	}
+	dd, err := m.wrapDataDiscover()
+	if err != nil {
+		return err
+	}
+	dd.SetDeviceGraph(graph)
+	dp.SetDeviceGraph(graph)
+	return nil
+}
+
+// driveProvisioner returns drive provisioner or error if provisioner of drive volumes is replaced by other type
+func (m *VolumeManager) driveProvisioner() (*p.DriveProvisioner, error) {
+	if dp, ok := m.provisioners[p.DriveBasedVolumeType].(*p.DriveProvisioner); ok {
+		return dp, nil
+	}
+	return nil, fmt.Errorf("drive provisioner %T doesn't support options of drive volumes",
+		m.provisioners[p.DriveBasedVolumeType])
+}
+
+// wrapDataDiscover returns data discovery or error if it is replaced by other type
+func (m *VolumeManager) wrapDataDiscover() (*datadiscover.WrapDataDiscoverImpl, error) {
+	if dd, ok := m.dataDiscover.(*datadiscover.WrapDataDiscoverImpl); ok {
+		return dd, nil
+	}
+	return nil, fmt.Errorf("data discovery %T doesn't support options of drive volumes", m.dataDiscover)
+}
+
 // SetListBlk sets listBlk for current VolumeManager instance
 // uses in Sanity testing
 func (m *VolumeManager) SetListBlk(listBlk lsblk.WrapLsblk) {
@@ -268,6 +426,12 @@ func (m *VolumeManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			ll.Warnf("Unlocking  volume with error %s", err)
 		}
 	}()
+	done, ok := m.operations.Begin("volume " + req.Name)
+	if !ok {
+		ll.Info("Node service is shutting down, volume will be reconciled after restart")
+		return ctrl.Result{RequeueAfter: base.DefaultRequeueForVolume}, nil
+	}
+	defer done()
 	ctx, cancelFn := context.WithTimeout(
 		context.WithValue(ctx, base.RequestUUID, req.Name),
 		VolumeOperationsTimeout)
@@ -279,6 +443,11 @@ func (m *VolumeManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	if err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	// volume might be taken over by controller when node was lost, finalizer is managed by owner node only
+	if volume.Spec.NodeId != m.nodeID {
+		ll.Warnf("Volume belongs to node %s, skip it", volume.Spec.NodeId)
+		return ctrl.Result{}, nil
+	}
 	if volume.DeletionTimestamp.IsZero() {
 		if !util.ContainsString(volume.ObjectMeta.Finalizers, volumeFinalizer) && volume.Spec.CSIStatus != apiV1.Empty {
 			ll.Debug("Appending finalizer for volume")
@@ -320,20 +489,29 @@ func (m *VolumeManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			}
 			return ctrl.Result{}, nil
 		default:
+			if volume.Annotations[apiV1.VolumeForceCleanupAnnotation] == apiV1.VolumeForceCleanupValue {
+				return m.forceVolumeCleanup(ctx, volume)
+			}
 			ll.Warnf("Volume wasn't deleted, because it has CSI status %s", volume.Spec.CSIStatus)
 			return ctrl.Result{}, nil
 		}
 	}
 	ll.Infof("Processing for status %s", volume.Spec.CSIStatus)
+	if wait := m.checkDriveCircuits(ctx, volume); wait > 0 {
+		return m.rejectVolumeOperation(ctx, volume, wait)
+	}
 	switch volume.Spec.CSIStatus {
 	case apiV1.Creating:
+		defer m.lockLocation(ll, volume.Spec.Location)()
 		if util.IsStorageClassLVG(volume.Spec.StorageClass) {
 			return m.handleCreatingVolumeInLVG(ctx, volume)
 		}
 		return m.prepareVolume(ctx, volume)
 	case apiV1.Removing:
+		defer m.lockLocation(ll, volume.Spec.Location)()
 		return m.handleRemovingStatus(ctx, volume)
 	case apiV1.Resizing:
+		defer m.lockLocation(ll, volume.Spec.Location)()
 		return m.handleExpandingStatus(ctx, volume)
 	}
 
@@ -358,6 +536,16 @@ func (m *VolumeManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	return ctrl.Result{}, nil
 }
 
+// lockLocation locks drive or LogicalVolumeGroup of volume, returns function which releases the lock
+func (m *VolumeManager) lockLocation(log *logrus.Entry, location string) func() {
+	m.locMu.LockKey(location)
+	return func() {
+		if err := m.locMu.UnlockKey(location); err != nil {
+			log.Warnf("Unlocking location %s with error %s", location, err)
+		}
+	}
+}
+
 func (m *VolumeManager) updateVolumeAndDriveUsageStatus(ctx context.Context, volume *volumecrd.Volume,
 	volumeStatus, driveStatus string) (ctrl.Result, error) {
 	ll := m.log.WithFields(logrus.Fields{
@@ -461,12 +649,20 @@ func (m *VolumeManager) prepareVolume(ctx context.Context, volume *volumecrd.Vol
 		"volumeID": volume.Spec.Id,
 	})
 
+	if m.isAsyncFormatting(volume) {
+		return m.prepareVolumeAsync(ctx, volume)
+	}
+
 	newStatus := apiV1.Created
 
-	err := m.getProvisionerForVolume(&volume.Spec).PrepareVolume(&volume.Spec)
+	err := m.prepareVolumeDevice(ctx, volume)
+	m.auditVolumeOperations(ctx, volume, volume.Spec.Location, prepareOperations(volume), err)
+	m.reportDriveOperation(ctx, volume, err)
 	if err != nil {
 		ll.Errorf("Unable to create volume size of %d bytes: %v. Set volume status to Failed", volume.Spec.Size, err)
 		newStatus = apiV1.Failed
+	} else {
+		m.recordVolumeOwnership(volume)
 	}
 
 	volume.Spec.CSIStatus = newStatus
@@ -500,6 +696,30 @@ func (m *VolumeManager) handleRemovingStatus(ctx context.Context, volume *volume
 	return ctrl.Result{}, err
 }
 
+// forceVolumeCleanup removes finalizer of terminating volume CR with force-cleanup annotation
+// it is used for volumes stuck in statuses which can't be handled automatically (Failed, Creating, etc.)
+// storage of the volume isn't released, drive annotation isn't updated
+func (m *VolumeManager) forceVolumeCleanup(ctx context.Context, volume *volumecrd.Volume) (ctrl.Result, error) {
+	ll := m.log.WithFields(logrus.Fields{
+		"method":   "forceVolumeCleanup",
+		"volumeID": volume.Name,
+	})
+
+	if !util.ContainsString(volume.ObjectMeta.Finalizers, volumeFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	ll.Warnf("Force cleanup of volume with CSI status %s, storage isn't released", volume.Spec.CSIStatus)
+	volume.ObjectMeta.Finalizers = util.RemoveString(volume.ObjectMeta.Finalizers, volumeFinalizer)
+	if err := m.k8sClient.UpdateCR(ctx, volume); err != nil {
+		ll.Errorf("Unable to remove finalizer: %v", err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	m.recorder.Eventf(volume, eventing.VolumeForceCleanup,
+		"Finalizer was removed by annotation %s, CSIStatus='%s', NodeName='%s'",
+		apiV1.VolumeForceCleanupAnnotation, volume.Spec.CSIStatus, m.nodeName)
+	return ctrl.Result{}, nil
+}
+
 func (m *VolumeManager) performVolumeRemoving(ctx context.Context, volume *volumecrd.Volume) (string, error) {
 	ll := m.log.WithFields(logrus.Fields{
 		"method":   "performVolumeRemoving",
@@ -520,7 +740,19 @@ func (m *VolumeManager) performVolumeRemoving(ctx context.Context, volume *volum
 	}
 	ll.Debugf("Got drive %+v", drive)
 
-	if err := m.getProvisionerForVolume(&volume.Spec).ReleaseVolume(&volume.Spec, &drive.Spec); err != nil {
+	if err = m.verifyOwnership(volume, drive); err != nil {
+		ll.Errorf("Volume won't be released: %v", err)
+		if errors.Is(err, ErrOwnershipMismatch) {
+			m.recorder.Eventf(volume, eventing.VolumeOwnershipMismatch, "Volume won't be released: %v", err)
+			return apiV1.Failed, err
+		}
+		return "", err
+	}
+
+	err = m.getProvisionerForVolume(&volume.Spec).ReleaseVolume(&volume.Spec, &drive.Spec)
+	m.auditVolumeOperations(ctx, volume, drive.Spec.Path, releaseOperations(volume), err)
+	m.reportDriveOperation(ctx, volume, err)
+	if err != nil {
 		ll.Errorf("Failed to remove volume - %s. Error: %v. Set status to Failed", volume.Spec.Id, err)
 		drive.Spec.Usage = apiV1.DriveUsageFailed
 		if err := m.k8sClient.UpdateCRWithAttempts(ctx, drive, 5); err != nil {
@@ -540,9 +772,8 @@ func (m *VolumeManager) performVolumeRemoving(ctx context.Context, volume *volum
 func (m *VolumeManager) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&volumecrd.Volume{}).
-		WithOptions(controller.Options{
-			MaxConcurrentReconciles: maxConcurrentReconciles,
-		}).
+		WithOptions(ctrlopts.FromEnv(ctrlopts.VolumeControllerPrefix,
+			ctrlopts.Options{MaxConcurrentReconciles: maxConcurrentReconciles}, m.log).ControllerOptions()).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(e crevent.CreateEvent) bool {
 				return m.isCorrespondedToNodePredicate(e.Object)
@@ -557,7 +788,7 @@ func (m *VolumeManager) SetupWithManager(mgr ctrl.Manager) error {
 				return m.isCorrespondedToNodePredicate(e.Object)
 			},
 		}).
-		Complete(m)
+		Complete(diagnostics.TrackReconciler("volume", m))
 }
 
 // isCorrespondedToNodePredicate checks is a provided obj is aVolume CR object
@@ -574,11 +805,14 @@ func (m *VolumeManager) isCorrespondedToNodePredicate(obj runtime.Object) bool {
 
 // Discover inspects actual drives structs from DriveManager and create volume object if partition exist on some of them
 // (in case of VolumeManager restart). Updates Drives CRs based on gathered from DriveManager information.
-// Also this method creates AC CRs. Performs at some intervals in a goroutine
+// Also this method creates AC CRs. Performs at some intervals in a goroutine, see NextDiscoverInterval
 // Returns error if something went wrong during discovering
-func (m *VolumeManager) Discover() error {
+func (m *VolumeManager) Discover() (err error) {
 	ctx, cancelFn := context.WithTimeout(context.Background(), DiscoverDrivesTimeout)
 	defer cancelFn()
+	// the next Discover is run soon if drives are changed or Discover fails
+	changed := true
+	defer func() { m.discovery.observe(changed || err != nil) }()
 
 	driveMgrDoneFunc := m.metricDriveMgrDuration.EvaluateDuration(prometheus.Labels{})
 	drivesResponse, err := m.driveMgrClient.GetDrivesList(ctx, &api.DrivesRequest{NodeId: m.nodeID})
@@ -587,12 +821,17 @@ func (m *VolumeManager) Discover() error {
 		return err
 	}
 	m.metricDriveMgrCount.Set(float64(len(drivesResponse.Disks)))
+	m.checkIOErrors(drivesResponse.Disks)
 
 	updates, err := m.updateDrivesCRs(ctx, drivesResponse.Disks)
 	if err != nil {
 		return fmt.Errorf("updateDrivesCRs return error: %v", err)
 	}
+	changed = len(updates.Created) > 0 || len(updates.Updated) > 0
 	m.handleDriveUpdates(ctx, updates)
+	m.checkDrivesTemperature(ctx, updates, drivesResponse.Disks)
+	m.applyWriteCachePolicy(ctx, updates)
+	m.growExpandedDrives(ctx, updates)
 
 	if m.discoverSystemLVG {
 		if err = m.discoverLVGOnSystemDrive(); err != nil {
@@ -605,6 +844,44 @@ func (m *VolumeManager) Discover() error {
 		return fmt.Errorf("discoverDataOnDrives return error: %v", err)
 	}
 
+	if err = m.reportNodeConditions(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to report node conditions: %v", err)
+	}
+	if err = m.reportNeedsReboot(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to report %s condition: %v", NodeConditionNeedsReboot, err)
+	}
+	if err = m.reconcileDuplicateMounts(); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to reconcile duplicate mounts: %v", err)
+	}
+	if err = m.reportPartitionUUIDs(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to report GUIDs of partitions: %v", err)
+	}
+	if err = m.auditPartitionAlignment(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to audit alignment of partitions: %v", err)
+	}
+	if err = m.processNBDExports(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to process NBD exports of volumes: %v", err)
+	}
+	if err = m.backupLVMMetadata(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to backup LVM metadata: %v", err)
+	}
+
+	if err = m.runSelfTest(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to run storage self-test: %v", err)
+	}
+
+	if err = m.processBurnIn(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to process burn-in tests: %v", err)
+	}
+
+	if err = m.refillStandbyPool(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to prepare standby drives: %v", err)
+	}
+
+	if err = m.updateCacheStats(ctx); err != nil {
+		m.log.WithField("method", "Discover").Errorf("unable to update cache stats of volumes: %v", err)
+	}
+
 	m.initialized = true
 	return nil
 }
@@ -629,6 +906,7 @@ func (m *VolumeManager) updateDrivesCRs(ctx context.Context, drivesFromMgr []*ap
 		return nil, err
 	}
 	firstIteration = len(driveCRs) == 0
+	m.rememberVacatedSlots(driveCRs)
 
 	var updates = new(driveUpdates)
 	var searchSystemDrives = len(m.systemDrivesUUIDs) == 0
@@ -693,8 +971,13 @@ func (m *VolumeManager) updateDrivesCRs(ctx context.Context, drivesFromMgr []*ap
 			}
 			toCreateSpec.IsSystem = isSystem
 			driveCR := m.k8sClient.ConstructDriveCR(toCreateSpec.UUID, toCreateSpec)
+			replaced := m.acceptReplacementDrive(driveCR)
+			m.scheduleBurnIn(driveCR, firstIteration)
+			m.scheduleForeignDataScan(driveCR)
 			if err := m.k8sClient.CreateCR(ctx, driveCR.Name, driveCR); err != nil {
 				ll.Errorf("Failed to create drive CR %v, error: %v", driveCR, err)
+			} else if replaced != "" {
+				m.reportReplacementDrive(driveCR, replaced)
 			}
 			updates.AddCreated(driveCR)
 			driveCRs = append(driveCRs, *driveCR)
@@ -798,13 +1081,24 @@ func (m *VolumeManager) discoverDataOnDrives() error {
 			}
 			continue
 		}
+		// partition and FS of standby drive are created by node service and available for volumes
+		if p.IsStandby(&drive) {
+			continue
+		}
+		if m.processForeignData(&drive) {
+			continue
+		}
 		if discoverResult, err = m.dataDiscover.DiscoverData(drive.Spec.Path, drive.Spec.SerialNumber); err != nil {
 			ll.Errorf("Failed to discover data on drive %s, err: %v", drive.Spec.SerialNumber, err)
 			continue
 		}
 		if discoverResult.HasData {
 			if drive.Spec.IsClean {
-				ll.Info(discoverResult.Message)
+				if discoverResult.Managed {
+					ll.Warn(discoverResult.Message)
+				} else {
+					ll.Info(discoverResult.Message)
+				}
 				m.sendEventForDrive(&drive, eventing.DriveHasData, discoverResult.Message)
 				m.changeDriveIsCleanField(&drive, false)
 			}
@@ -1140,7 +1434,7 @@ func (m *VolumeManager) isDriveSystem(path string) (bool, error) {
 // Returns true if device has root mountpoint, false in opposite
 func (m *VolumeManager) isRootMountpoint(devs []lsblk.BlockDevice) bool {
 	for _, device := range devs {
-		if strings.TrimSpace(device.MountPoint) == base.KubeletRootPath ||
+		if strings.TrimSpace(device.MountPoint) == m.kubeletPodsPath() ||
 			strings.HasPrefix(strings.TrimSpace(device.MountPoint), base.HostRootPath) {
 			return true
 		}
@@ -1174,7 +1468,9 @@ func (m *VolumeManager) handleExpandingStatus(ctx context.Context, volume *volum
 		ll.Errorf("Failed to get volume path, err: %v", err)
 		return ctrl.Result{Requeue: true}, err
 	}
-	if err = m.lvmOps.ExpandLV(volumePath, volume.Spec.Size); err != nil {
+	err = m.lvmOps.ExpandLV(volumePath, volume.Spec.Size)
+	m.reportDriveOperation(ctx, volume, err)
+	if err != nil {
 		volume.Spec.CSIStatus = apiV1.Failed
 	} else {
 		volume.Spec.CSIStatus = apiV1.Resized
@@ -450,6 +450,53 @@ func TestReconcile_SuccessDeleteVolume(t *testing.T) {
 	assert.Equal(t, res, ctrl.Result{})
 }
 
+func TestReconcile_ForceCleanupVolume(t *testing.T) {
+	var (
+		vm       = prepareSuccessVolumeManager(t)
+		recorder = new(mocks.NoOpRecorder)
+		req      = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: testNs, Name: volCR.Name}}
+		vol      = volCR.DeepCopy()
+	)
+	vm.recorder = recorder
+	vol.Spec.CSIStatus = apiV1.Failed
+	vol.Finalizers = []string{volumeFinalizer}
+	vol.DeletionTimestamp = &v1.Time{Time: time.Now()}
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, vol.Name, vol))
+
+	// volume in Failed status isn't cleaned without annotation
+	res, err := vm.Reconcile(testCtx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ctrl.Result{}, res)
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, vol.Name, testNs, vol))
+	assert.Contains(t, vol.Finalizers, volumeFinalizer)
+
+	vol.Annotations = map[string]string{apiV1.VolumeForceCleanupAnnotation: apiV1.VolumeForceCleanupValue}
+	assert.Nil(t, vm.k8sClient.UpdateCR(testCtx, vol))
+	res, err = vm.Reconcile(testCtx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ctrl.Result{}, res)
+	// volume which is being deleted is removed with the last finalizer
+	assert.True(t, k8sError.IsNotFound(vm.k8sClient.ReadCR(testCtx, vol.Name, testNs, vol)))
+	assert.Equal(t, eventing.VolumeForceCleanup, recorder.Calls[0].Event)
+}
+
+func TestReconcile_VolumeOnAnotherNode(t *testing.T) {
+	var (
+		vm  = prepareSuccessVolumeManager(t)
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: testNs, Name: volCR.Name}}
+		vol = volCR.DeepCopy()
+	)
+	vol.Spec.NodeId = "another-node"
+	vol.Spec.CSIStatus = apiV1.Created
+	assert.Nil(t, vm.k8sClient.CreateCR(testCtx, vol.Name, vol))
+
+	res, err := vm.Reconcile(testCtx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ctrl.Result{}, res)
+	assert.Nil(t, vm.k8sClient.ReadCR(testCtx, vol.Name, testNs, vol))
+	assert.Empty(t, vol.Finalizers)
+}
+
 func TestVolumeManager_handleCreatingVolumeInLVG(t *testing.T) {
 	var (
 		vm                 *VolumeManager
@@ -598,6 +645,25 @@ func TestNewVolumeManager_SetProvisioners(t *testing.T) {
 	assert.Equal(t, newProv, vm.provisioners[p.DriveBasedVolumeType])
 }
 
+func TestVolumeManager_SetProvisionerOptions(t *testing.T) {
+	vm := NewVolumeManager(nil, mocks.EmptyExecutorSuccess{},
+		logrus.New(), nil, nil, new(mocks.NoOpRecorder), nodeID, nodeName)
+	assert.Nil(t, vm.SetPartitionScheme(&p.PartitionScheme{GUIDPrefix: "0e5a"}))
+	assert.Nil(t, vm.SetBusyPartitionPolicy(p.BusyPartitionPolicyRemove))
+	assert.Nil(t, vm.SetTemplateVolumeGroup("templates"))
+	assert.Nil(t, vm.SetDeviceGraph(nil))
+
+	// options aren't silently ignored by provisioners which don't support them
+	vm.SetProvisioners(map[p.VolumeType]p.Provisioner{
+		p.DriveBasedVolumeType: &mockProv.MockProvisioner{},
+		p.LVMBasedVolumeType:   &mockProv.MockProvisioner{},
+	})
+	assert.NotNil(t, vm.SetPartitionScheme(&p.PartitionScheme{GUIDPrefix: "0e5a"}))
+	assert.NotNil(t, vm.SetBusyPartitionPolicy(p.BusyPartitionPolicyRemove))
+	assert.NotNil(t, vm.SetTemplateVolumeGroup("templates"))
+	assert.NotNil(t, vm.SetDeviceGraph(nil))
+}
+
 func TestVolumeManager_DiscoverFail(t *testing.T) {
 	var (
 		vm  *VolumeManager
@@ -30,10 +30,11 @@ const (
 	wbtValuePath = "/sys/block/%s/queue/wbt_lat_usec"
 	// isWBTEnabledCmdImpl is a CMD to check current WBT value
 	checkWbtValueCmd = "cat " + wbtValuePath
-	// enableWBTCmdImpl is a CMD to check current WBT value
-	restoreWbtValueCmd = "echo -1 > " + wbtValuePath
-	// disableWBTCmdImpl is a CMD to check current WBT value
-	setWbtValueCmd = "echo %d > " + wbtValuePath
+	// restoreWbtValue is a WBT value which restores default
+	restoreWbtValue = "-1"
+	// setWbtValueScript writes value to WBT file, value and path are passed as positional parameters,
+	// so they aren't interpreted by shell
+	setWbtValueScript = `echo "$1" > "$2"`
 	// exec cmd via sh
 	defaultShellCmd = "sh"
 	shellCmdOption  = "-c"
@@ -60,7 +61,10 @@ func NewWbt(e command.CmdExecutor) *Wbt {
 // SetValue checks Wbt value for given device and change it if not equal
 // Example output: sh -c echo <value> /sys/block/<device>/queue/wbt_lat_usec
 func (w *Wbt) SetValue(device string, value uint32) error {
-	strOut, stdErr, err := w.e.RunCmd(fmt.Sprintf(checkWbtValueCmd, device))
+	if err := command.ValidateName(device); err != nil {
+		return err
+	}
+	strOut, stdErr, err := w.e.RunCmd(command.NewCmd(checkWbtValueCmd, command.Name(device)))
 	if err != nil {
 		// Invalid argument is acceptable error, means that value for WBT is not set yet
 		if !strings.Contains(stdErr, invalidArgError) {
@@ -72,8 +76,7 @@ func (w *Wbt) SetValue(device string, value uint32) error {
 		return nil
 	}
 
-	cmd := exec.Command(defaultShellCmd, shellCmdOption, fmt.Sprintf(setWbtValueCmd, value, device))
-	_, _, err = w.e.RunCmd(cmd)
+	_, _, err = w.e.RunCmd(setWbtValueCmd(strconv.Itoa(int(value)), device))
 	if err != nil {
 		return err
 	}
@@ -84,11 +87,19 @@ func (w *Wbt) SetValue(device string, value uint32) error {
 // RestoreDefault restores default Wbt value for given device
 // Example output: sh -c echo -1 /sys/block/<device>/queue/wbt_lat_usec
 func (w *Wbt) RestoreDefault(device string) error {
-	cmd := exec.Command(defaultShellCmd, shellCmdOption, fmt.Sprintf(restoreWbtValueCmd, device))
-	_, _, err := w.e.RunCmd(cmd)
+	if err := command.ValidateName(device); err != nil {
+		return err
+	}
+	_, _, err := w.e.RunCmd(setWbtValueCmd(restoreWbtValue, device))
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// setWbtValueCmd returns shell command which writes value to WBT file of device
+func setWbtValueCmd(value, device string) *exec.Cmd {
+	return exec.Command(defaultShellCmd, shellCmdOption, setWbtValueScript, defaultShellCmd,
+		value, fmt.Sprintf(wbtValuePath, device))
+}
@@ -2,10 +2,11 @@ package operations
 
 import (
 	"fmt"
-	"os/exec"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/dell/csi-baremetal/pkg/mocks"
 )
@@ -19,7 +20,7 @@ func TestWbt_SetValue(t *testing.T) {
 			device          = "sdb"
 			value    uint32 = 0
 			cmdCheck        = fmt.Sprintf(checkWbtValueCmd, device)
-			cmdSet          = exec.Command(defaultShellCmd, shellCmdOption, fmt.Sprintf(setWbtValueCmd, value, device))
+			cmdSet          = setWbtValueCmd(strconv.Itoa(int(value)), device)
 		)
 
 		e := &mocks.GoMockExecutor{}
@@ -70,7 +71,7 @@ func TestWbt_SetValue(t *testing.T) {
 			device          = "sdb"
 			value    uint32 = 0
 			cmdCheck        = fmt.Sprintf(checkWbtValueCmd, device)
-			cmdSet          = exec.Command(defaultShellCmd, shellCmdOption, fmt.Sprintf(setWbtValueCmd, value, device))
+			cmdSet          = setWbtValueCmd(strconv.Itoa(int(value)), device)
 		)
 
 		e := &mocks.GoMockExecutor{}
@@ -89,7 +90,7 @@ func TestWbt_SetValue(t *testing.T) {
 			device          = "sdb"
 			value    uint32 = 0
 			cmdCheck        = fmt.Sprintf(checkWbtValueCmd, device)
-			cmdSet          = exec.Command(defaultShellCmd, shellCmdOption, fmt.Sprintf(setWbtValueCmd, value, device))
+			cmdSet          = setWbtValueCmd(strconv.Itoa(int(value)), device)
 		)
 
 		e := &mocks.GoMockExecutor{}
@@ -108,7 +109,7 @@ func TestWbt_RestoreDefault(t *testing.T) {
 		var (
 			emptyErr = ""
 			device   = "sdb"
-			cmdSet   = exec.Command(defaultShellCmd, shellCmdOption, fmt.Sprintf(restoreWbtValueCmd, device))
+			cmdSet   = setWbtValueCmd(restoreWbtValue, device)
 		)
 
 		e := &mocks.GoMockExecutor{}
@@ -122,7 +123,7 @@ func TestWbt_RestoreDefault(t *testing.T) {
 		var (
 			errStr = "some error"
 			device = "sdb"
-			cmdSet = exec.Command(defaultShellCmd, shellCmdOption, fmt.Sprintf(restoreWbtValueCmd, device))
+			cmdSet = setWbtValueCmd(restoreWbtValue, device)
 		)
 
 		e := &mocks.GoMockExecutor{}
@@ -133,3 +134,19 @@ func TestWbt_RestoreDefault(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestWbt_InvalidDevice(t *testing.T) {
+	e := &mocks.GoMockExecutor{}
+	wbt := NewWbt(e)
+
+	assert.NotNil(t, wbt.SetValue("sdb; reboot", 0))
+	assert.NotNil(t, wbt.RestoreDefault("../sdb"))
+	e.AssertNotCalled(t, "RunCmd", mock.Anything)
+}
+
+func TestSetWbtValueCmd(t *testing.T) {
+	cmd := setWbtValueCmd(restoreWbtValue, "sdb")
+	// value and path are passed as positional parameters of script
+	assert.Equal(t, []string{defaultShellCmd, shellCmdOption, setWbtValueScript, defaultShellCmd,
+		"-1", "/sys/block/sdb/queue/wbt_lat_usec"}, cmd.Args)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/writecache"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+)
+
+// Values of write cache policy
+const (
+	WriteCacheOn  = "on"
+	WriteCacheOff = "off"
+)
+
+// writeCachePolicy holds required state of volatile write cache per drive type
+type writeCachePolicy struct {
+	// key - drive type (HDD/SSD/NVME), value - whether write cache must be enabled
+	enabled map[string]bool
+	wc      writecache.WrapWriteCache
+}
+
+// SetWriteCachePolicy enables control of volatile write cache of drives during Discover
+// Receives whether write cache must be enabled per drive type, drives of other types aren't touched
+func (m *VolumeManager) SetWriteCachePolicy(policy map[string]bool) {
+	m.writeCache = &writeCachePolicy{
+		enabled: policy,
+		wc:      writecache.NewWrapWriteCacheImpl(command.NewExecutor(m.log.Logger)),
+	}
+}
+
+// applyWriteCachePolicy sets write cache of online non-system drives according to the policy.
+// Setting is lost after power cycle of the drive, so state is checked during each discovery.
+// Drive CR is annotated with the state, events are sent when the state changes
+func (m *VolumeManager) applyWriteCachePolicy(ctx context.Context, updates *driveUpdates) {
+	if m.writeCache == nil {
+		return
+	}
+	ll := m.log.WithFields(logrus.Fields{
+		"method": "applyWriteCachePolicy",
+	})
+
+	driveCRs := append(append([]*drivecrd.Drive{}, updates.Created...), updates.NotChanged...)
+	for _, upd := range updates.Updated {
+		driveCRs = append(driveCRs, upd.CurrentState)
+	}
+
+	for _, drive := range driveCRs {
+		enabled, ok := m.writeCache.enabled[drive.Spec.Type]
+		if !ok || drive.Spec.IsSystem || drive.Spec.Status != apiV1.DriveStatusOnline {
+			continue
+		}
+		state, err := m.enforceWriteCache(drive, enabled)
+		if err != nil {
+			ll.Errorf("Unable to apply write cache policy to drive %s: %v", drive.Spec.SerialNumber, err)
+		}
+		if drive.GetAnnotations()[apiV1.DriveAnnotationWriteCache] == state {
+			continue
+		}
+
+		switch state {
+		case apiV1.DriveAnnotationWriteCacheFailed:
+			m.sendEventForDrive(drive, eventing.DriveWriteCacheFailed,
+				"Unable to set write cache to %s: %v.", writeCacheValue(enabled), err)
+		case apiV1.DriveAnnotationWriteCacheUnsupported:
+			ll.Infof("Drive %s doesn't support write cache control: %v", drive.Spec.SerialNumber, err)
+		default:
+			m.sendEventForDrive(drive, eventing.DriveWriteCacheChanged, "Write cache is %s by policy.", state)
+		}
+		if drive.Annotations == nil {
+			drive.Annotations = map[string]string{}
+		}
+		drive.Annotations[apiV1.DriveAnnotationWriteCache] = state
+		if err = m.k8sClient.UpdateCR(ctx, drive); err != nil {
+			ll.Errorf("Unable to update write cache annotation of drive %s: %v", drive.Name, err)
+		}
+	}
+}
+
+// enforceWriteCache sets write cache of the drive to the required state if it differs
+// Returns state of write cache for DriveAnnotationWriteCache and error if the state can't be read or set
+func (m *VolumeManager) enforceWriteCache(drive *drivecrd.Drive, enabled bool) (string, error) {
+	path, driveType := drive.Spec.Path, drive.Spec.Type
+	current, err := m.writeCache.wc.GetWriteCache(path, driveType)
+	switch {
+	case errors.Is(err, writecache.ErrNotSupported):
+		return apiV1.DriveAnnotationWriteCacheUnsupported, err
+	case err != nil:
+		return apiV1.DriveAnnotationWriteCacheFailed, err
+	case current == enabled:
+		return writeCacheState(enabled), nil
+	}
+
+	if err = m.writeCache.wc.SetWriteCache(path, driveType, enabled); err != nil {
+		return apiV1.DriveAnnotationWriteCacheFailed, err
+	}
+	// some drives accept the command but keep the cache as is
+	if current, err = m.writeCache.wc.GetWriteCache(path, driveType); err != nil {
+		return apiV1.DriveAnnotationWriteCacheFailed, err
+	}
+	if current != enabled {
+		return apiV1.DriveAnnotationWriteCacheFailed, errors.New("drive ignored write cache change")
+	}
+	return writeCacheState(enabled), nil
+}
+
+// writeCacheState returns value of DriveAnnotationWriteCache for state of write cache
+func writeCacheState(enabled bool) string {
+	if enabled {
+		return apiV1.DriveAnnotationWriteCacheEnabled
+	}
+	return apiV1.DriveAnnotationWriteCacheDisabled
+}
+
+// writeCacheValue returns value of write cache policy for state of write cache
+func writeCacheValue(enabled bool) string {
+	if enabled {
+		return WriteCacheOn
+	}
+	return WriteCacheOff
+}
+
+// ParseWriteCachePolicy parses policy in format "HDD=on,NVME=off"
+// Returns map drive type -> whether write cache must be enabled or error if format is wrong
+func ParseWriteCachePolicy(str string) (map[string]bool, error) {
+	policy := make(map[string]bool)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("write cache policy %s has wrong format, expected <drive type>=on|off", item)
+		}
+		driveType := strings.ToUpper(strings.TrimSpace(parts[0]))
+		switch driveType {
+		case apiV1.DriveTypeHDD, apiV1.DriveTypeSSD, apiV1.DriveTypeNVMe:
+		default:
+			return nil, fmt.Errorf("write cache policy %s has unknown drive type %s", item, driveType)
+		}
+		switch strings.ToLower(strings.TrimSpace(parts[1])) {
+		case WriteCacheOn:
+			policy[driveType] = true
+		case WriteCacheOff:
+			policy[driveType] = false
+		default:
+			return nil, fmt.Errorf("write cache policy %s has wrong value, expected %s or %s", item,
+				WriteCacheOn, WriteCacheOff)
+		}
+	}
+	return policy, nil
+}
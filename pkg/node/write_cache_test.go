@@ -0,0 +1,98 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/writecache"
+	"github.com/dell/csi-baremetal/pkg/eventing"
+	"github.com/dell/csi-baremetal/pkg/mocks"
+	mocklu "github.com/dell/csi-baremetal/pkg/mocks/linuxutils"
+)
+
+func TestParseWriteCachePolicy(t *testing.T) {
+	policy, err := ParseWriteCachePolicy("hdd=off, NVME=On")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{apiV1.DriveTypeHDD: false, apiV1.DriveTypeNVMe: true}, policy)
+
+	for _, str := range []string{"HDD", "HDD=disabled", "TAPE=off"} {
+		_, err = ParseWriteCachePolicy(str)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestVolumeManager_applyWriteCachePolicy(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	var (
+		recorder = new(mocks.NoOpRecorder)
+		wc       = &mocklu.MockWrapWriteCache{}
+		vm       = NewVolumeManager(nil, nil, testLogger, kubeClient, kubeClient, recorder, nodeID, nodeName)
+		hdd      = testDriveCR.DeepCopy()
+		nvme     = testDriveCR.DeepCopy()
+		system   = testDriveCR.DeepCopy()
+		updated  = &drivecrd.Drive{}
+	)
+	// disabled
+	vm.applyWriteCachePolicy(testCtx, &driveUpdates{NotChanged: []*drivecrd.Drive{hdd}})
+
+	vm.SetWriteCachePolicy(map[string]bool{apiV1.DriveTypeHDD: false, apiV1.DriveTypeNVMe: false})
+	vm.writeCache.wc = wc
+	hdd.Spec.IsSystem = false
+	nvme.Name, nvme.Spec.UUID, nvme.Spec.Path, nvme.Spec.Type = "nvme", "nvme", "/dev/nvme0n1", apiV1.DriveTypeNVMe
+	nvme.Spec.IsSystem = false
+	system.Name, system.Spec.Path = "system", "/dev/sdz"
+	for _, d := range []*drivecrd.Drive{hdd, nvme, system} {
+		assert.Nil(t, kubeClient.CreateCR(testCtx, d.Name, d))
+	}
+	wc.On("GetWriteCache", "/dev/sda", apiV1.DriveTypeHDD).Return(true, nil).Once()
+	wc.On("SetWriteCache", "/dev/sda", apiV1.DriveTypeHDD, false).Return(nil).Once()
+	wc.On("GetWriteCache", "/dev/sda", apiV1.DriveTypeHDD).Return(false, nil)
+	wc.On("GetWriteCache", "/dev/nvme0n1", apiV1.DriveTypeNVMe).Return(false, writecache.ErrNotSupported)
+
+	updates := &driveUpdates{NotChanged: []*drivecrd.Drive{hdd, nvme, system}}
+	vm.applyWriteCachePolicy(testCtx, updates)
+	assert.Nil(t, kubeClient.ReadCR(testCtx, hdd.Name, "", updated))
+	assert.Equal(t, apiV1.DriveAnnotationWriteCacheDisabled, updated.Annotations[apiV1.DriveAnnotationWriteCache])
+	assert.Nil(t, kubeClient.ReadCR(testCtx, nvme.Name, "", updated))
+	assert.Equal(t, apiV1.DriveAnnotationWriteCacheUnsupported, updated.Annotations[apiV1.DriveAnnotationWriteCache])
+	assert.Len(t, recorder.Calls, 1)
+	assert.Equal(t, eventing.DriveWriteCacheChanged, recorder.Calls[0].Event)
+	wc.AssertNotCalled(t, "GetWriteCache", "/dev/sdz", apiV1.DriveTypeHDD)
+
+	// state isn't changed - no events and no writes
+	vm.applyWriteCachePolicy(testCtx, updates)
+	assert.Len(t, recorder.Calls, 1)
+	wc.AssertNumberOfCalls(t, "SetWriteCache", 1)
+
+	// drive was power cycled and ignores the change
+	wc.ExpectedCalls = nil
+	wc.On("GetWriteCache", "/dev/sda", apiV1.DriveTypeHDD).Return(true, nil)
+	wc.On("SetWriteCache", "/dev/sda", apiV1.DriveTypeHDD, false).Return(nil)
+	wc.On("GetWriteCache", "/dev/nvme0n1", apiV1.DriveTypeNVMe).Return(false, writecache.ErrNotSupported)
+	vm.applyWriteCachePolicy(testCtx, updates)
+	assert.Nil(t, kubeClient.ReadCR(testCtx, hdd.Name, "", updated))
+	assert.Equal(t, apiV1.DriveAnnotationWriteCacheFailed, updated.Annotations[apiV1.DriveAnnotationWriteCache])
+	assert.Len(t, recorder.Calls, 2)
+	assert.Equal(t, eventing.DriveWriteCacheFailed, recorder.Calls[1].Event)
+}
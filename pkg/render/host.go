@@ -0,0 +1,178 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/lsblk"
+)
+
+const (
+	// partitionInfoTmpl is a part of sgdisk --info output which is parsed by partition helper
+	partitionInfoTmpl = "Partition unique GUID: %s\n"
+	// partedDiskTmpl is a part of parted --machine print output with device, fill device
+	partedDiskTmpl = "BYT;\n%s:0B:scsi:512:512:gpt:Simulated disk:;\n"
+	// partedPartition is a line of parted --machine print output with the first partition
+	partedPartition = "1:1048576B:1048576B:0B:::;\n"
+	// randomPartUUID is GUID of partition which is created without GUID, sgdisk generates random one
+	randomPartUUID = "00000000-0000-0000-0000-000000000001"
+)
+
+// disk is a simulated drive on node
+type disk struct {
+	serial string
+	vendor string
+	model  string
+}
+
+// host simulates state of block devices on node which is changed by rendered commands,
+// so the next commands see results of the previous ones as they would on real node.
+// Commands which don't change simulated state succeed with empty output
+type host struct {
+	// disks contains simulated drives, key - device path
+	disks map[string]disk
+	// partitions contains GUID of the only partition of drive, key - device path
+	partitions map[string]string
+	// fileSystems contains type of file system on device or partition, key - device path
+	fileSystems map[string]string
+}
+
+func newHost() *host {
+	return &host{
+		disks:       make(map[string]disk),
+		partitions:  make(map[string]string),
+		fileSystems: make(map[string]string),
+	}
+}
+
+// run returns simulated output of command and updates simulated state
+func (h *host) run(args []string) (string, string, error) {
+	device := deviceArg(args)
+	switch filepath.Base(args[0]) {
+	case "parted":
+		return h.parted(device), "", nil
+	case "sgdisk":
+		return h.sgdisk(device, args[1:])
+	case "lsblk":
+		return h.lsblk(device, args[1:])
+	case "wipefs":
+		if !hasArg(args, "--no-act") {
+			delete(h.fileSystems, device)
+		}
+	default:
+		if strings.HasPrefix(filepath.Base(args[0]), "mkfs.") {
+			h.fileSystems[device] = strings.TrimPrefix(filepath.Base(args[0]), "mkfs.")
+		}
+	}
+	return "", "", nil
+}
+
+// sgdisk simulates creation, removal and reading of GUID of the first partition
+func (h *host) sgdisk(device string, args []string) (string, string, error) {
+	for i, arg := range args {
+		switch {
+		case arg == "-o":
+			delete(h.partitions, device)
+		case arg == "-d":
+			delete(h.partitions, device)
+		case arg == "-n":
+			h.partitions[device] = randomPartUUID
+		case arg == "-u" && i+1 < len(args):
+			h.partitions[device] = strings.ToLower(strings.TrimPrefix(args[i+1], "1:"))
+		case strings.HasPrefix(arg, "--info="):
+			partUUID, ok := h.partitions[device]
+			if !ok {
+				return "", "", fmt.Errorf("partition isn't found on %s", device)
+			}
+			return fmt.Sprintf(partitionInfoTmpl, strings.ToUpper(partUUID)), "", nil
+		}
+	}
+	return "", "", nil
+}
+
+// parted simulates parted --machine print output with GPT and the only partition if it is created
+func (h *host) parted(device string) string {
+	out := fmt.Sprintf(partedDiskTmpl, device)
+	if _, ok := h.partitions[device]; ok {
+		out += partedPartition
+	}
+	return out
+}
+
+// lsblk simulates lsblk output in json format and output of file system type
+func (h *host) lsblk(device string, args []string) (string, string, error) {
+	if !hasArg(args, "--json") {
+		if hasArg(args, "FSTYPE") {
+			return h.fileSystems[device] + "\n", "", nil
+		}
+		return "", "", nil
+	}
+	paths := make([]string, 0, len(h.disks))
+	for path := range h.disks {
+		if device == "" || device == path {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	devices := make([]lsblk.BlockDevice, 0, len(paths))
+	for _, path := range paths {
+		d := h.disks[path]
+		bdev := lsblk.BlockDevice{Name: path, Type: "disk", Serial: d.serial, Vendor: d.vendor, Model: d.model,
+			FSType: h.fileSystems[path]}
+		if partUUID, ok := h.partitions[path]; ok {
+			name := partitionName(path)
+			bdev.Children = []lsblk.BlockDevice{{Name: name, Type: "part", PartUUID: partUUID,
+				FSType: h.fileSystems[name]}}
+		}
+		devices = append(devices, bdev)
+	}
+	out, err := json.Marshal(map[string][]lsblk.BlockDevice{"blockdevices": devices})
+	return string(out), "", err
+}
+
+// partitionName returns path of the first partition of device, e.g. /dev/sda1 or /dev/nvme0n1p1
+func partitionName(device string) string {
+	if r := []rune(device); len(r) > 0 && unicode.IsDigit(r[len(r)-1]) {
+		return device + "p1"
+	}
+	return device + "1"
+}
+
+// deviceArg returns the first argument which is a path of device
+func deviceArg(args []string) string {
+	for _, arg := range args[1:] {
+		if strings.HasPrefix(arg, "/dev/") {
+			return arg
+		}
+	}
+	return ""
+}
+
+func hasArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
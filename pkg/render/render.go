@@ -0,0 +1,320 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package render contains developer tool which renders commands that node service runs to reconcile
+// Volume and Drive CRs. Real provisioners are used, commands aren't run but passed to simulated host,
+// so rendered sequence is the same as on node with the same state of drives
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiV1 "github.com/dell/csi-baremetal/api/v1"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/command"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/linuxutils/fs"
+	ph "github.com/dell/csi-baremetal/pkg/base/linuxutils/partitionhelper"
+	"github.com/dell/csi-baremetal/pkg/base/util"
+	p "github.com/dell/csi-baremetal/pkg/node/provisioners"
+	uw "github.com/dell/csi-baremetal/pkg/node/provisioners/utilwrappers"
+)
+
+const (
+	// OperationCreate renders creation of volume or preparation of standby partition of drive
+	OperationCreate = "create"
+	// OperationRelease renders removal of volume or release of standby partition of drive
+	OperationRelease = "release"
+
+	// defaultNamespace is used for Volume CRs without namespace
+	defaultNamespace = "default"
+	// linuxutilsPkg is a part of function name of linuxutils operations
+	linuxutilsPkg = "/pkg/base/linuxutils/"
+)
+
+// Step is a single command which node runs during reconcile
+type Step struct {
+	// Operation is linuxutils method which runs the command, e.g. partitionhelper.WrapPartitionImpl.CreatePartition
+	Operation string
+	// Command is the command line
+	Command string
+}
+
+// String returns step in format "<operation>: <command>"
+func (s Step) String() string {
+	return fmt.Sprintf("%s: %s", s.Operation, s.Command)
+}
+
+// Renderer renders commands of node operations for Volume or Drive CR manifest
+type Renderer struct {
+	volumes []*volumecrd.Volume
+	drives  []*drivecrd.Drive
+	lvgs    []*lvgcrd.LogicalVolumeGroup
+
+	host  *host
+	steps []Step
+	log   *logrus.Logger
+}
+
+// NewRenderer is a constructor for Renderer
+func NewRenderer(log *logrus.Logger) *Renderer {
+	return &Renderer{host: newHost(), log: log}
+}
+
+// LoadManifests reads YAML or JSON manifests of Volume, Drive and LogicalVolumeGroup CRs, several manifests
+// are separated by "---". Drive CR of drive based volume and LogicalVolumeGroup CR of system LVG volume
+// must be provided together with Volume CR
+func (r *Renderer) LoadManifests(data []byte) error {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data))
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("unable to decode manifest: %w", err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		if err := r.addObject(raw); err != nil {
+			return err
+		}
+	}
+}
+
+// addObject decodes manifest to CR of its kind
+func (r *Renderer) addObject(raw []byte) error {
+	typeMeta := &metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, typeMeta); err != nil {
+		return fmt.Errorf("unable to decode kind of manifest: %w", err)
+	}
+	var obj interface{}
+	switch typeMeta.Kind {
+	case apiV1.VolumeKind:
+		volume := &volumecrd.Volume{}
+		r.volumes = append(r.volumes, volume)
+		obj = volume
+	case apiV1.DriveKind:
+		drive := &drivecrd.Drive{}
+		r.drives = append(r.drives, drive)
+		obj = drive
+	case apiV1.LVGKind:
+		lvg := &lvgcrd.LogicalVolumeGroup{}
+		r.lvgs = append(r.lvgs, lvg)
+		obj = lvg
+	default:
+		return fmt.Errorf("unsupported kind %q, supported kinds are %s, %s, %s",
+			typeMeta.Kind, apiV1.VolumeKind, apiV1.DriveKind, apiV1.LVGKind)
+	}
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return fmt.Errorf("unable to decode %s: %w", typeMeta.Kind, err)
+	}
+	return nil
+}
+
+// Render returns commands which node runs for operation on loaded Volume CR or on Drive CR if there is no Volume CR.
+// If operation is empty, it is chosen by CSI status of Volume CR: CREATING - create, REMOVING - release,
+// operation on Drive CR is create by default
+func (r *Renderer) Render(operation string) ([]Step, error) {
+	if len(r.volumes) > 1 {
+		return nil, fmt.Errorf("only one Volume CR might be rendered, got %d", len(r.volumes))
+	}
+	if len(r.volumes) == 0 && len(r.drives) != 1 {
+		return nil, fmt.Errorf("one Volume CR or one Drive CR is required, got %d Drive CRs", len(r.drives))
+	}
+
+	k8sClient, err := r.prepareClient()
+	if err != nil {
+		return nil, err
+	}
+	r.steps = nil
+	command.SetDryRun(r.record)
+	defer command.SetDryRun(nil)
+
+	if len(r.volumes) == 1 {
+		err = r.renderVolume(k8sClient, r.volumes[0], operation)
+	} else {
+		err = r.renderDrive(r.drives[0], operation)
+	}
+	// steps before failure show where operation fails
+	return r.steps, err
+}
+
+// prepareClient puts loaded CRs to fake k8s client which is used by provisioners and adds drives to simulated host
+func (r *Renderer) prepareClient() (*k8s.KubeClient, error) {
+	namespace := defaultNamespace
+	if len(r.volumes) == 1 {
+		if r.volumes[0].Namespace == "" {
+			r.volumes[0].Namespace = namespace
+		}
+		namespace = r.volumes[0].Namespace
+	}
+	k8sClient, err := k8s.GetFakeKubeClient(namespace, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []k8sCl.Object
+	for i, drive := range r.drives {
+		path := drive.Spec.Path
+		if path == "" {
+			// device is found by serial number with lsblk as on node with drive manager which doesn't report path
+			path = fmt.Sprintf("/dev/sd%c", 'b'+i)
+		}
+		r.host.disks[path] = disk{serial: drive.Spec.SerialNumber, vendor: drive.Spec.VID, model: drive.Spec.PID}
+		objects = append(objects, drive)
+	}
+	for _, lvg := range r.lvgs {
+		objects = append(objects, lvg)
+	}
+	for _, volume := range r.volumes {
+		objects = append(objects, volume)
+	}
+	ctx := context.Background()
+	for _, obj := range objects {
+		obj.SetResourceVersion("")
+		if err = k8sClient.CreateCR(ctx, obj.GetName(), obj); err != nil {
+			return nil, err
+		}
+	}
+	return k8sClient, nil
+}
+
+// renderVolume runs provisioner of volume, partition and file system of volume exist before release
+func (r *Renderer) renderVolume(k8sClient *k8s.KubeClient, volume *volumecrd.Volume, operation string) error {
+	if operation == "" {
+		switch volume.Spec.CSIStatus {
+		case apiV1.Creating:
+			operation = OperationCreate
+		case apiV1.Removing:
+			operation = OperationRelease
+		default:
+			return fmt.Errorf("volume %s with CSI status %s isn't reconciled with commands, set operation explicitly",
+				volume.Name, volume.Spec.CSIStatus)
+		}
+	}
+
+	var (
+		vol         = &volume.Spec
+		provisioner p.Provisioner
+		drive       = &drivecrd.Drive{}
+		device      string
+		e           = command.NewExecutor(r.log)
+	)
+	if util.IsStorageClassLVG(vol.StorageClass) {
+		provisioner = p.NewLVMProvisioner(e, k8sClient, r.log)
+	} else {
+		provisioner = p.NewDriveProvisioner(e, k8sClient, r.log)
+		if err := k8sClient.ReadCR(context.Background(), vol.Location, "", drive); err != nil {
+			return fmt.Errorf("unable to read Drive CR %s of volume %s: %w", vol.Location, volume.Name, err)
+		}
+		device = r.devicePath(drive)
+	}
+
+	switch operation {
+	case OperationCreate:
+		return provisioner.PrepareVolume(vol)
+	case OperationRelease:
+		if device != "" && vol.Mode != apiV1.ModeRAW {
+			partUUID, _ := util.GetVolumeUUID(vol.Id)
+			r.host.partitions[device] = partUUID
+			r.host.fileSystems[partitionName(device)] = vol.Type
+		}
+		return provisioner.ReleaseVolume(vol, &drive.Spec)
+	default:
+		return fmt.Errorf("unknown operation %s, supported: %s, %s", operation, OperationCreate, OperationRelease)
+	}
+}
+
+// renderDrive prepares or releases standby partition of drive, drive without standby annotations is marked
+// as standby drive with xfs
+func (r *Renderer) renderDrive(drive *drivecrd.Drive, operation string) error {
+	if !p.IsStandby(drive) {
+		p.MarkStandby(drive, fs.XFS, drive.Spec.UUID)
+	}
+	var (
+		e       = command.NewExecutor(r.log)
+		standby = p.NewStandbyPartition(ph.NewWrapPartitionImpl(e, r.log), uw.NewFSOperationsImpl(e, r.log), r.log)
+		device  = r.devicePath(drive)
+	)
+	switch operation {
+	case OperationCreate, "":
+		return standby.Prepare(drive, device)
+	case OperationRelease:
+		r.host.partitions[device] = drive.Annotations[apiV1.DriveAnnotationStandbyPartUUID]
+		r.host.fileSystems[partitionName(device)] = drive.Annotations[apiV1.DriveAnnotationStandby]
+		return standby.Release(drive, device)
+	default:
+		return fmt.Errorf("unknown operation %s, supported: %s, %s", operation, OperationCreate, OperationRelease)
+	}
+}
+
+// devicePath returns path of drive on simulated host
+func (r *Renderer) devicePath(drive *drivecrd.Drive) string {
+	for path, d := range r.host.disks {
+		if d.serial == drive.Spec.SerialNumber && d.vendor == drive.Spec.VID && d.model == drive.Spec.PID {
+			return path
+		}
+	}
+	return ""
+}
+
+// record is used as command.DryRunner, it saves command with linuxutils operation which runs it
+func (r *Renderer) record(args []string) (string, string, error) {
+	r.steps = append(r.steps, Step{Operation: caller(), Command: strings.Join(args, " ")})
+	return r.host.run(args)
+}
+
+// caller returns the outermost linuxutils function in the chain of calls which runs command,
+// or the function which runs command directly if it isn't linuxutils function
+func caller() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	operation := ""
+frames:
+	for more := true; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		switch {
+		case strings.Contains(frame.Function, "/pkg/base/command.") || strings.Contains(frame.Function, "/pkg/render."):
+			continue
+		case strings.Contains(frame.Function, linuxutilsPkg):
+			operation = frame.Function
+			continue
+		case operation == "":
+			operation = frame.Function
+		}
+		break frames
+	}
+	// github.com/dell/csi-baremetal/pkg/base/linuxutils/fs.(*WrapFSImpl).WipeFS -> fs.WrapFSImpl.WipeFS
+	operation = operation[strings.LastIndex(operation, "/")+1:]
+	return strings.NewReplacer("(*", "", ")", "").Replace(operation)
+}
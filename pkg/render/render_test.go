@@ -0,0 +1,145 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testDrive = `
+apiVersion: csi-baremetal.dell.com/v1
+kind: Drive
+metadata:
+  name: 2c0bfa4f-d4b4-4d52-86b6-a6e4ef3fdd02
+spec:
+  UUID: 2c0bfa4f-d4b4-4d52-86b6-a6e4ef3fdd02
+  SerialNumber: hdd-1
+  VID: vendor
+  PID: model
+  Type: HDD
+  Size: 1000000000
+  NodeId: node-1
+`
+	testVolume = `
+apiVersion: csi-baremetal.dell.com/v1
+kind: Volume
+metadata:
+  name: pvc-4c8b1d8b-7f4e-4d4b-9b9e-3c2b3a4f5e6d
+  namespace: test
+spec:
+  Id: pvc-4c8b1d8b-7f4e-4d4b-9b9e-3c2b3a4f5e6d
+  Location: 2c0bfa4f-d4b4-4d52-86b6-a6e4ef3fdd02
+  StorageClass: HDD
+  Type: xfs
+  Mode: FS
+  Size: 1000000000
+  NodeId: node-1
+  CSIStatus: CREATING
+`
+	testLVMVolume = `
+{"apiVersion": "csi-baremetal.dell.com/v1", "kind": "Volume",
+ "metadata": {"name": "pvc-lvm"},
+ "spec": {"Id": "pvc-lvm", "Location": "vg-1", "StorageClass": "HDDLVG", "Type": "ext4", "Mode": "FS",
+          "Size": 104857600, "CSIStatus": "REMOVING"}}
+`
+)
+
+// assertSteps checks that steps contain expected steps in the same order, expected command is a prefix
+func assertSteps(t *testing.T, steps []Step, expected ...Step) {
+	i := 0
+	for _, step := range steps {
+		if i < len(expected) && step.Operation == expected[i].Operation &&
+			strings.HasPrefix(step.Command, expected[i].Command) {
+			i++
+		}
+	}
+	assert.Equal(t, len(expected), i, "expected step %v isn't found in %v", expected[i%len(expected)], steps)
+}
+
+func TestRenderer_RenderVolume(t *testing.T) {
+	r := NewRenderer(logrus.New())
+	assert.Nil(t, r.LoadManifests([]byte(testVolume+"---"+testDrive)))
+
+	steps, err := r.Render("")
+	assert.Nil(t, err)
+	assertSteps(t, steps,
+		Step{"lsblk.LSBLK.SearchDrivePath", "lsblk --paths --json"},
+		Step{"partitionhelper.WrapPartitionImpl.IsPartitionExists",
+			"parted --script --machine /dev/sdb unit B print"},
+		Step{"partitionhelper.WrapPartitionImpl.CreatePartitionTable", "sgdisk /dev/sdb -o"},
+		Step{"partitionhelper.WrapPartitionImpl.CreatePartition",
+			"sgdisk -n 1:0:0 -c 1:CSI -u 1:4c8b1d8b-7f4e-4d4b-9b9e-3c2b3a4f5e6d /dev/sdb"},
+		Step{"partitionhelper.WrapPartitionImpl.GetPartitionNameByUUID", "lsblk /dev/sdb --paths --json"},
+		Step{"fs.WrapFSImpl.CreateFS", "mkfs.xfs /dev/sdb1"})
+
+	// volume is released from the same state of drive
+	steps, err = r.Render(OperationRelease)
+	assert.Nil(t, err)
+	assertSteps(t, steps,
+		Step{"fs.WrapFSImpl.WipeFS", "wipefs -af /dev/sdb1"},
+		Step{"partitionhelper.WrapPartitionImpl.DeletePartition", "sgdisk -d 1 /dev/sdb"},
+		Step{"fs.WrapFSImpl.WipeFS", "wipefs -af /dev/sdb"})
+
+	_, err = r.Render("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestRenderer_RenderLVMVolume(t *testing.T) {
+	r := NewRenderer(logrus.New())
+	assert.Nil(t, r.LoadManifests([]byte(testLVMVolume)))
+
+	steps, err := r.Render("")
+	assert.Nil(t, err)
+	assertSteps(t, steps,
+		Step{"fs.WrapFSImpl.WipeFS", "wipefs -af /dev/vg-1/pvc-lvm"},
+		Step{"lvm.LVM.LVRemove", "/sbin/lvm lvremove --yes /dev/vg-1/pvc-lvm"})
+}
+
+func TestRenderer_RenderDrive(t *testing.T) {
+	r := NewRenderer(logrus.New())
+	assert.Nil(t, r.LoadManifests([]byte(testDrive)))
+
+	steps, err := r.Render(OperationCreate)
+	assert.Nil(t, err)
+	assertSteps(t, steps,
+		Step{"partitionhelper.WrapPartitionImpl.CreatePartitionTable", "sgdisk /dev/sdb -o"},
+		Step{"partitionhelper.WrapPartitionImpl.CreatePartition",
+			"sgdisk -n 1:0:0 -c 1:CSI -u 1:2c0bfa4f-d4b4-4d52-86b6-a6e4ef3fdd02 /dev/sdb"},
+		Step{"fs.WrapFSImpl.CreateFS", "mkfs.xfs /dev/sdb1"})
+}
+
+func TestRenderer_LoadManifests(t *testing.T) {
+	r := NewRenderer(logrus.New())
+	assert.NotNil(t, r.LoadManifests([]byte("kind: Pod\nmetadata:\n  name: pod")))
+	assert.NotNil(t, r.LoadManifests([]byte("kind: [")))
+
+	// volume in VOLUME_READY status requires operation
+	assert.Nil(t, r.LoadManifests([]byte(strings.Replace(testVolume, "CREATING", "VOLUME_READY", 1)+"---"+testDrive)))
+	_, err := r.Render("")
+	assert.NotNil(t, err)
+
+	// Drive CR of volume is required
+	r = NewRenderer(logrus.New())
+	assert.Nil(t, r.LoadManifests([]byte(testVolume)))
+	_, err = r.Render("")
+	assert.NotNil(t, err)
+}
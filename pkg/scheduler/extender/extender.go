@@ -37,12 +37,14 @@ import (
 	genV1 "github.com/dell/csi-baremetal/api/generated/v1"
 	v1 "github.com/dell/csi-baremetal/api/v1"
 	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
+	"github.com/dell/csi-baremetal/api/v1/nodecrd"
 	volcrd "github.com/dell/csi-baremetal/api/v1/volumecrd"
 	"github.com/dell/csi-baremetal/pkg/base"
 	"github.com/dell/csi-baremetal/pkg/base/capacityplanner"
 	baseerr "github.com/dell/csi-baremetal/pkg/base/error"
 	fc "github.com/dell/csi-baremetal/pkg/base/featureconfig"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/nodeprotocol"
 	"github.com/dell/csi-baremetal/pkg/base/util"
 	annotations "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
 )
@@ -60,6 +62,8 @@ type Extender struct {
 	sync.Mutex
 	logger                 *logrus.Entry
 	capacityManagerBuilder capacityplanner.CapacityManagerBuilder
+	// whether reservations of pending pods with lower priority are preempted or not
+	reservationPreemption bool
 }
 
 // NewExtender returns new instance of Extender struct
@@ -122,7 +126,26 @@ func (e *Extender) FilterHandler(w http.ResponseWriter, req *http.Request) {
 	}
 	ll.Debugf("Required capacity: %v", requests)
 
-	matchedNodes, failedNodes, err := e.filter(ctxWithVal, pod, extenderArgs.Nodes.Items, requests)
+	var (
+		nodes, failedNodes = filterSelfTestedNodes(extenderArgs.Nodes.Items, requests)
+		matchedNodes       []coreV1.Node
+	)
+	// node services which don't support features of pod volumes aren't requested
+	if len(nodes) > 0 && len(requests) > 0 {
+		var incompatibleNodes schedulerapi.FailedNodesMap
+		nodes, incompatibleNodes, err = e.filterCompatibleNodes(ctxWithVal, pod, nodes)
+		for name, reason := range incompatibleNodes {
+			failedNodes[name] = reason
+		}
+	}
+	// reservation isn't requested if there are no nodes which passed storage self-test
+	if err == nil && len(nodes) > 0 {
+		var filteredNodes schedulerapi.FailedNodesMap
+		matchedNodes, filteredNodes, err = e.filter(ctxWithVal, pod, nodes, requests)
+		for name, reason := range filteredNodes {
+			failedNodes[name] = reason
+		}
+	}
 
 	if err != nil {
 		ll.Errorf("filter finished with error: %v", err)
@@ -396,7 +419,119 @@ func (e *Extender) filter(ctx context.Context, pod *coreV1.Pod, nodes []coreV1.N
 	}
 
 	// reservation found
-	return e.handleReservation(ctx, reservation, nodes)
+	return e.handleReservation(ctx, pod, reservation, nodes)
+}
+
+// filterSelfTestedNodes filters out nodes which storage self-test isn't passed if pod has volumes
+func filterSelfTestedNodes(nodes []coreV1.Node, capacities []*genV1.CapacityRequest) ([]coreV1.Node,
+	schedulerapi.FailedNodesMap) {
+	failedNodes := schedulerapi.FailedNodesMap{}
+	if len(capacities) == 0 {
+		return nodes, failedNodes
+	}
+	testedNodes := make([]coreV1.Node, 0, len(nodes))
+	for _, node := range nodes {
+		isTested := true
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == v1.NodeConditionStorageSelfTestFailed && condition.Status == coreV1.ConditionTrue {
+				isTested = false
+				failedNodes[node.Name] = fmt.Sprintf("Storage self-test isn't passed on the node %s: %s",
+					node.Name, condition.Message)
+				break
+			}
+		}
+		if isTested {
+			testedNodes = append(testedNodes, node)
+		}
+	}
+	return testedNodes, failedNodes
+}
+
+// filterCompatibleNodes filters out nodes which node service doesn't support features requested by pod volumes,
+// node service might be older than controller during rolling upgrade. Node without Node CR is treated as legacy node
+func (e *Extender) filterCompatibleNodes(ctx context.Context, pod *coreV1.Pod, nodes []coreV1.Node) ([]coreV1.Node,
+	schedulerapi.FailedNodesMap, error) {
+	required, err := e.gatherRequiredCapabilities(ctx, pod)
+	if err != nil {
+		return nil, nil, err
+	}
+	failedNodes := schedulerapi.FailedNodesMap{}
+	if len(required) == 0 {
+		return nodes, failedNodes, nil
+	}
+
+	csiNodes := &nodecrd.NodeList{}
+	if err = e.k8sCache.ReadList(ctx, csiNodes); err != nil {
+		return nil, nil, err
+	}
+	compatibleNodes := make([]coreV1.Node, 0, len(nodes))
+	for _, node := range nodes {
+		node := node
+		nodeID, err := annotations.GetNodeID(&node, e.annotationKey, e.featureChecker)
+		if err != nil {
+			e.logger.Errorf("failed to get NodeID: %s", err)
+			continue
+		}
+		csiNode := &genV1.Node{UUID: nodeID}
+		for i := range csiNodes.Items {
+			if csiNodes.Items[i].Spec.UUID == nodeID {
+				csiNode = &csiNodes.Items[i].Spec
+				break
+			}
+		}
+		if err = nodeprotocol.CheckCapabilities(csiNode, required); err != nil {
+			failedNodes[node.Name] = fmt.Sprintf("Node service on the node %s isn't compatible: %v", node.Name, err)
+			continue
+		}
+		compatibleNodes = append(compatibleNodes, node)
+	}
+	return compatibleNodes, failedNodes, nil
+}
+
+// gatherRequiredCapabilities returns capabilities of node service which are required by not provisioned pod volumes
+func (e *Extender) gatherRequiredCapabilities(ctx context.Context, pod *coreV1.Pod) ([]string, error) {
+	scs := &storageV1.StorageClassList{}
+	if err := e.k8sCache.ReadList(ctx, scs); err != nil {
+		return nil, err
+	}
+	parameters := make(map[string]map[string]string, len(scs.Items))
+	for _, sc := range scs.Items {
+		if sc.Provisioner == e.provisioner {
+			parameters[sc.Name] = sc.Parameters
+		}
+	}
+
+	var required []string
+	add := func(params map[string]string) {
+		for _, capability := range nodeprotocol.RequiredCapabilitiesByParameters(params) {
+			if !util.ContainsString(required, capability) {
+				required = append(required, capability)
+			}
+		}
+	}
+	for _, v := range pod.Spec.Volumes {
+		switch {
+		case v.CSI != nil:
+			if v.CSI.Driver == e.provisioner {
+				add(v.CSI.VolumeAttributes)
+			}
+		case v.Ephemeral != nil:
+			if name := v.Ephemeral.VolumeClaimTemplate.Spec.StorageClassName; name != nil {
+				add(parameters[*name])
+			}
+		case v.PersistentVolumeClaim != nil:
+			pvc := &coreV1.PersistentVolumeClaim{}
+			if err := e.k8sCache.ReadCR(ctx, v.PersistentVolumeClaim.ClaimName, pod.Namespace, pvc); err != nil {
+				return nil, err
+			}
+			if pvc.Spec.StorageClassName == nil ||
+				pvc.Status.Phase == coreV1.ClaimBound || pvc.Status.Phase == coreV1.ClaimLost {
+				continue
+			}
+			add(parameters[*pvc.Spec.StorageClassName])
+		}
+	}
+	return required, nil
 }
 
 func getReservationName(pod *coreV1.Pod) string {
@@ -463,8 +598,8 @@ func (e *Extender) prepareListOfRequestedNodes(nodes []coreV1.Node) ([]string, e
 	return requestedNodes, nil
 }
 
-func (e *Extender) handleReservation(ctx context.Context, reservation *acrcrd.AvailableCapacityReservation,
-	nodes []coreV1.Node) (matchedNodes []coreV1.Node, filteredNodes schedulerapi.FailedNodesMap, err error) {
+func (e *Extender) handleReservation(ctx context.Context, pod *coreV1.Pod,
+	reservation *acrcrd.AvailableCapacityReservation, nodes []coreV1.Node) (matchedNodes []coreV1.Node, filteredNodes schedulerapi.FailedNodesMap, err error) {
 	// handle reservation status
 	switch reservation.Spec.Status {
 	case v1.ReservationRequested:
@@ -503,6 +638,11 @@ func (e *Extender) handleReservation(ctx context.Context, reservation *acrcrd.Av
 		return matchedNodes, filteredNodes, nil
 	case v1.ReservationRejected:
 		// no available capacity
+		if e.reservationPreemption {
+			if err := e.preemptReservations(ctx, pod, reservation, nodes); err != nil {
+				e.logger.Errorf("Unable to preempt reservations for pod %s: %v", pod.Name, err)
+			}
+		}
 		// request reservation again
 		return nil, nil, e.resendReservationRequest(ctx, reservation, nodes)
 	}
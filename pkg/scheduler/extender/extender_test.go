@@ -42,6 +42,7 @@ import (
 	fc "github.com/dell/csi-baremetal/pkg/base/featureconfig"
 	"github.com/dell/csi-baremetal/pkg/base/k8s"
 	"github.com/dell/csi-baremetal/pkg/base/logger/objects"
+	"github.com/dell/csi-baremetal/pkg/base/nodeprotocol"
 	annotations "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
 )
 
@@ -712,3 +713,65 @@ func Test_createReservation(t *testing.T) {
 	assert.Equal(t, len(nodes), len(reservationResource.Spec.NodeRequests.Requested))
 	assert.Equal(t, len(capacityRequests), len(reservationResource.Spec.ReservationRequests))
 }
+
+func Test_filterSelfTestedNodes(t *testing.T) {
+	var (
+		tested   = coreV1.Node{ObjectMeta: metaV1.ObjectMeta{Name: "node-1"}}
+		untested = coreV1.Node{ObjectMeta: metaV1.ObjectMeta{Name: "node-2"}, Status: coreV1.NodeStatus{
+			Conditions: []coreV1.NodeCondition{{Type: v1.NodeConditionStorageSelfTestFailed,
+				Status: coreV1.ConditionTrue, Message: "mount failed"}}}}
+		nodes      = []coreV1.Node{tested, untested}
+		capacities = []*genV1.CapacityRequest{{Name: "pvc-1", Size: 100, StorageClass: "HDD"}}
+	)
+
+	// pod doesn't have volumes
+	matched, failed := filterSelfTestedNodes(nodes, nil)
+	assert.Equal(t, nodes, matched)
+	assert.Empty(t, failed)
+
+	matched, failed = filterSelfTestedNodes(nodes, capacities)
+	assert.Equal(t, []coreV1.Node{tested}, matched)
+	assert.Contains(t, failed[untested.Name], "mount failed")
+
+	untested.Status.Conditions[0].Status = coreV1.ConditionFalse
+	matched, _ = filterSelfTestedNodes([]coreV1.Node{untested}, capacities)
+	assert.Len(t, matched, 1)
+}
+
+func TestExtender_filterCompatibleNodes(t *testing.T) {
+	var (
+		e        = setup(t)
+		upgraded = coreV1.Node{ObjectMeta: metaV1.ObjectMeta{Name: "node-1", UID: "uid-1"}}
+		legacy   = coreV1.Node{ObjectMeta: metaV1.ObjectMeta{Name: "node-2", UID: "uid-2"}}
+		nodes    = []coreV1.Node{upgraded, legacy}
+		sc       = testSC1.DeepCopy()
+		pvc      = testPVC1.DeepCopy()
+		pod      = testPod.DeepCopy()
+	)
+	csiNode := e.k8sClient.ConstructCSIBMNodeCR("csibmnode-1", genV1.Node{UUID: string(upgraded.UID)})
+	nodeprotocol.Report(&csiNode.Spec)
+	applyObjs(t, e.k8sClient, sc, pvc, csiNode)
+	pod.Spec.Volumes = []coreV1.Volume{{VolumeSource: coreV1.VolumeSource{
+		PersistentVolumeClaim: &coreV1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name}}}}
+
+	// volume doesn't require any capability
+	matched, failed, err := e.filterCompatibleNodes(testCtx, pod, nodes)
+	assert.Nil(t, err)
+	assert.Equal(t, nodes, matched)
+	assert.Empty(t, failed)
+
+	// legacy node service doesn't support encryption
+	sc.Parameters["encryption"] = v1.EncryptionLUKS
+	assert.Nil(t, e.k8sClient.Update(testCtx, sc))
+	matched, failed, err = e.filterCompatibleNodes(testCtx, pod, nodes)
+	assert.Nil(t, err)
+	assert.Equal(t, []coreV1.Node{upgraded}, matched)
+	assert.Contains(t, failed[legacy.Name], v1.NodeCapabilityEncryption)
+
+	// bound PVC doesn't require capabilities
+	pvc.Status.Phase = coreV1.ClaimBound
+	assert.Nil(t, e.k8sClient.Update(testCtx, pvc))
+	matched, _, err = e.filterCompatibleNodes(testCtx, pod, nodes)
+	assert.Nil(t, err)
+	assert.Equal(t, nodes, matched)
+}
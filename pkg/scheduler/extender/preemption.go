@@ -0,0 +1,282 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package extender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	schedulerapi "k8s.io/kube-scheduler/extender/v1"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/dell/csi-baremetal/api/v1"
+	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
+	"github.com/dell/csi-baremetal/pkg/base"
+	annotations "github.com/dell/csi-baremetal/pkg/crcontrollers/node/common"
+)
+
+// selectedNodeAnnotation is set on PVC by scheduler when pod is assumed on node, volume is provisioned right after it
+const selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+
+// SetReservationPreemption enables preemption of capacity reservations held by pending pods with lower priority
+// when reservation of pod is rejected due to lack of capacity
+func (e *Extender) SetReservationPreemption(enabled bool) {
+	e.reservationPreemption = enabled
+}
+
+// PreemptHandler extracts ExtenderPreemptionArgs struct from req and writes ExtenderPreemptionResult to the w.
+// Eviction of running pods doesn't release their local volumes, so victims chosen by scheduler are kept only on
+// nodes where capacity is reserved for the pod, capacity which is held by pending pods is preempted in filter stage
+func (e *Extender) PreemptHandler(w http.ResponseWriter, req *http.Request) {
+	sessionUUID := uuid.New().String()
+	ll := e.logger.WithFields(logrus.Fields{
+		"sessionUUID": sessionUUID,
+		"method":      "PreemptHandler",
+	})
+	ll.Infof("Processing request: %v", req)
+
+	w.Header().Set("Content-Type", "application/json")
+	resp := json.NewEncoder(w)
+
+	var (
+		extenderArgs schedulerapi.ExtenderPreemptionArgs
+		extenderRes  = &schedulerapi.ExtenderPreemptionResult{}
+	)
+
+	if err := json.NewDecoder(req.Body).Decode(&extenderArgs); err != nil {
+		ll.Errorf("Unable to decode request body: %v", err)
+		if err := resp.Encode(extenderRes); err != nil {
+			ll.Errorf("Unable to write response %v: %v", extenderRes, err)
+		}
+		return
+	}
+
+	ll = ll.WithField("pod", extenderArgs.Pod.Name)
+	ctxWithVal := context.WithValue(req.Context(), base.RequestUUID, sessionUUID)
+	extenderRes.NodeNameToMetaVictims = e.filterVictims(ctxWithVal, ll, extenderArgs.Pod, getMetaVictims(&extenderArgs))
+	ll.Infof("Victims are kept on %d nodes", len(extenderRes.NodeNameToMetaVictims))
+
+	if err := resp.Encode(extenderRes); err != nil {
+		ll.Errorf("Unable to write response %v: %v", extenderRes, err)
+	}
+}
+
+// filterVictims returns victims on nodes where capacity is reserved for volumes of the pod,
+// all victims are returned if pod doesn't have volumes provisioned by CSI Baremetal
+func (e *Extender) filterVictims(ctx context.Context, ll *logrus.Entry, pod *coreV1.Pod,
+	victims map[string]*schedulerapi.MetaVictims) map[string]*schedulerapi.MetaVictims {
+	requests, err := e.gatherCapacityRequestsByProvisioner(ctx, pod)
+	if err != nil {
+		ll.Warningf("Unable to gather capacity requests, skip preemption: %v", err)
+		return map[string]*schedulerapi.MetaVictims{}
+	}
+	if len(requests) == 0 {
+		return victims
+	}
+
+	reservation := &acrcrd.AvailableCapacityReservation{}
+	if err := e.k8sClient.ReadCR(ctx, getReservationName(pod), "", reservation); err != nil {
+		ll.Infof("Unable to read reservation, skip preemption: %v", err)
+		return map[string]*schedulerapi.MetaVictims{}
+	}
+	if reservation.Spec.Status != v1.ReservationConfirmed {
+		ll.Infof("Reservation is in %s status, eviction of running pods doesn't release capacity",
+			reservation.Spec.Status)
+		return map[string]*schedulerapi.MetaVictims{}
+	}
+
+	reserved := make(map[string]bool, len(reservation.Spec.NodeRequests.Reserved))
+	for _, nodeID := range reservation.Spec.NodeRequests.Reserved {
+		reserved[nodeID] = true
+	}
+	result := make(map[string]*schedulerapi.MetaVictims, len(victims))
+	for name, nodeVictims := range victims {
+		node := &coreV1.Node{}
+		if err := e.k8sClient.Get(ctx, k8sCl.ObjectKey{Name: name}, node); err != nil {
+			ll.Errorf("Unable to read node %s: %v", name, err)
+			continue
+		}
+		nodeID, err := annotations.GetNodeID(node, e.annotationKey, e.featureChecker)
+		if err != nil {
+			ll.Errorf("Failed to get NodeID of node %s: %v", name, err)
+			continue
+		}
+		if reserved[nodeID] {
+			result[name] = nodeVictims
+		}
+	}
+	return result
+}
+
+// getMetaVictims returns victims from the request, scheduler sends either full pods or their UIDs
+// depending on nodeCacheCapable setting of extender
+func getMetaVictims(args *schedulerapi.ExtenderPreemptionArgs) map[string]*schedulerapi.MetaVictims {
+	if args.NodeNameToMetaVictims != nil {
+		return args.NodeNameToMetaVictims
+	}
+	victims := make(map[string]*schedulerapi.MetaVictims, len(args.NodeNameToVictims))
+	for name, nodeVictims := range args.NodeNameToVictims {
+		metaVictims := &schedulerapi.MetaVictims{NumPDBViolations: nodeVictims.NumPDBViolations}
+		for _, pod := range nodeVictims.Pods {
+			metaVictims.Pods = append(metaVictims.Pods, &schedulerapi.MetaPod{UID: string(pod.UID)})
+		}
+		victims[name] = metaVictims
+	}
+	return victims
+}
+
+// preemptReservations removes confirmed reservations of pending pods with priority lower than priority of the pod
+// on the requested nodes, so reservation of the pod which is requested again might be confirmed by planner.
+// Reservations are removed in order of priority until removed capacity covers capacity requested by the pod.
+// Reservations of pods assigned to nodes or with volumes which are being provisioned are never removed
+func (e *Extender) preemptReservations(ctx context.Context, pod *coreV1.Pod,
+	reservation *acrcrd.AvailableCapacityReservation, nodes []coreV1.Node) error {
+	ll := e.logger.WithFields(logrus.Fields{
+		"sessionUUID": ctx.Value(base.RequestUUID),
+		"method":      "preemptReservations",
+		"pod":         pod.Name,
+	})
+
+	priority := getPodPriority(pod)
+	requestedSize := getReservationSize(reservation)
+	nodeIDs, err := e.prepareListOfRequestedNodes(nodes)
+	if err != nil {
+		return err
+	}
+	requestedNodes := make(map[string]bool, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		requestedNodes[nodeID] = true
+	}
+
+	pods := &coreV1.PodList{}
+	if err := e.k8sClient.ReadList(ctx, pods); err != nil {
+		return err
+	}
+	pendingPods := make(map[string]*coreV1.Pod, len(pods.Items))
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if p.Spec.NodeName == "" && p.DeletionTimestamp == nil && getPodPriority(p) < priority {
+			pendingPods[getReservationName(p)] = p
+		}
+	}
+
+	reservations := &acrcrd.AvailableCapacityReservationList{}
+	if err := e.k8sClient.ReadList(ctx, reservations); err != nil {
+		return err
+	}
+	var candidates []acrcrd.AvailableCapacityReservation
+	for _, acr := range reservations.Items {
+		if acr.Name == reservation.Name || acr.Spec.Status != v1.ReservationConfirmed || pendingPods[acr.Name] == nil {
+			continue
+		}
+		for _, nodeID := range acr.Spec.NodeRequests.GetReserved() {
+			if requestedNodes[nodeID] {
+				candidates = append(candidates, acr)
+				break
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return getPodPriority(pendingPods[candidates[i].Name]) < getPodPriority(pendingPods[candidates[j].Name])
+	})
+
+	var preemptedSize int64
+	for i := range candidates {
+		if preemptedSize >= requestedSize {
+			break
+		}
+		acr := &candidates[i]
+		victim := pendingPods[acr.Name]
+		if !e.isPreemptable(ctx, acr, victim) {
+			ll.Debugf("Reservation %s is being provisioned or changed, skip it", acr.Name)
+			continue
+		}
+		// reservation isn't removed if it is changed after the check, e.g. volumes are created from it
+		err := e.k8sClient.Delete(ctx, acr, k8sCl.Preconditions{ResourceVersion: &acr.ResourceVersion})
+		if k8serrors.IsConflict(err) {
+			ll.Debugf("Reservation %s is changed, skip it", acr.Name)
+			continue
+		}
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		preemptedSize += getReservationSize(acr)
+		ll.Infof("Reservation %s of pod %s/%s with priority %d is preempted by pod with priority %d",
+			acr.Name, victim.Namespace, victim.Name, getPodPriority(victim), priority)
+	}
+	return nil
+}
+
+// isPreemptable reads reservation and its pod again right before deletion and checks that reservation is still
+// confirmed, pod is still pending and volumes of reservation aren't being provisioned.
+// Reservation is replaced with the read one, so it is deleted only if it isn't changed after the check
+func (e *Extender) isPreemptable(ctx context.Context, reservation *acrcrd.AvailableCapacityReservation,
+	pod *coreV1.Pod) bool {
+	current := &acrcrd.AvailableCapacityReservation{}
+	if err := e.k8sClient.ReadCR(ctx, reservation.Name, "", current); err != nil ||
+		current.Spec.Status != v1.ReservationConfirmed {
+		return false
+	}
+	currentPod := &coreV1.Pod{}
+	if err := e.k8sClient.ReadCR(ctx, pod.Name, pod.Namespace, currentPod); err != nil ||
+		currentPod.UID != pod.UID || currentPod.Spec.NodeName != "" || currentPod.DeletionTimestamp != nil {
+		return false
+	}
+	if e.isProvisioning(ctx, current) {
+		return false
+	}
+	*reservation = *current
+	return true
+}
+
+// isProvisioning checks whether PVCs of the reservation are bound or selected node is set for them,
+// volumes are created from the reservation by controller service in this case
+func (e *Extender) isProvisioning(ctx context.Context, reservation *acrcrd.AvailableCapacityReservation) bool {
+	for _, request := range reservation.Spec.ReservationRequests {
+		pvc := &coreV1.PersistentVolumeClaim{}
+		err := e.k8sClient.ReadCR(ctx, request.GetCapacityRequest().GetName(), reservation.Spec.Namespace, pvc)
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil || pvc.Spec.VolumeName != "" || pvc.Annotations[selectedNodeAnnotation] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getPodPriority returns priority of pod, pods without priority have zero priority
+func getPodPriority(pod *coreV1.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+// getReservationSize returns total size of capacity requested by reservation
+func getReservationSize(reservation *acrcrd.AvailableCapacityReservation) int64 {
+	var size int64
+	for _, request := range reservation.Spec.ReservationRequests {
+		size += request.GetCapacityRequest().GetSize()
+	}
+	return size
+}
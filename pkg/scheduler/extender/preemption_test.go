@@ -0,0 +1,181 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package extender
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulerapi "k8s.io/kube-scheduler/extender/v1"
+
+	genV1 "github.com/dell/csi-baremetal/api/generated/v1"
+	v1 "github.com/dell/csi-baremetal/api/v1"
+	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
+)
+
+func newPreemptionPod(name string, priority int32) *coreV1.Pod {
+	return &coreV1.Pod{
+		ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: testNs},
+		Spec: coreV1.PodSpec{
+			Priority: &priority,
+			Volumes:  []coreV1.Volume{{Name: "data", VolumeSource: coreV1.VolumeSource{CSI: &testCSIVolumeSrc}}},
+		},
+	}
+}
+
+func newPreemptionACR(e *Extender, pod *coreV1.Pod, status string, size int64,
+	nodes ...string) *acrcrd.AvailableCapacityReservation {
+	return e.k8sClient.ConstructACRCR(getReservationName(pod), genV1.AvailableCapacityReservation{
+		Namespace:    testNs,
+		Status:       status,
+		NodeRequests: &genV1.NodeRequests{Requested: nodes, Reserved: nodes},
+		ReservationRequests: []*genV1.ReservationRequest{
+			{CapacityRequest: &genV1.CapacityRequest{Name: pod.Name + "-data", Size: size}}},
+	})
+}
+
+func TestExtender_preemptReservations(t *testing.T) {
+	var (
+		e     = setup(t)
+		nodes = []coreV1.Node{
+			{ObjectMeta: metaV1.ObjectMeta{UID: "node-1-uid", Name: "node-1"}},
+			{ObjectMeta: metaV1.ObjectMeta{UID: "node-2-uid", Name: "node-2"}},
+		}
+		pod          = newPreemptionPod("high", 100)
+		low          = newPreemptionPod("low", 1)
+		lower        = newPreemptionPod("lower", 0)
+		provisioning = newPreemptionPod("provisioning", -1)
+		assigned     = newPreemptionPod("assigned", -1)
+		higher       = newPreemptionPod("higher", 200)
+		otherNode    = newPreemptionPod("other-node", -1)
+		reservation  = newPreemptionACR(e, pod, v1.ReservationRejected, 100)
+		pvc          = &coreV1.PersistentVolumeClaim{ObjectMeta: metaV1.ObjectMeta{Name: "provisioning-data",
+			Namespace: testNs, Annotations: map[string]string{selectedNodeAnnotation: "node-1"}}}
+		exists = func(p *coreV1.Pod) bool {
+			return e.k8sClient.ReadCR(testCtx, getReservationName(p), "", &acrcrd.AvailableCapacityReservation{}) == nil
+		}
+	)
+	assigned.Spec.NodeName = "node-1"
+	applyObjs(t, e.k8sClient, pod, low, lower, provisioning, assigned, higher, otherNode, pvc, reservation,
+		newPreemptionACR(e, low, v1.ReservationConfirmed, 100, "node-1-uid"),
+		newPreemptionACR(e, lower, v1.ReservationConfirmed, 50, "node-1-uid"),
+		newPreemptionACR(e, provisioning, v1.ReservationConfirmed, 100, "node-1-uid"),
+		newPreemptionACR(e, assigned, v1.ReservationConfirmed, 100, "node-1-uid"),
+		newPreemptionACR(e, higher, v1.ReservationConfirmed, 100, "node-2-uid"),
+		newPreemptionACR(e, otherNode, v1.ReservationConfirmed, 100, "node-3-uid"))
+
+	// reservations are preempted in order of priority until requested capacity is released
+	assert.Nil(t, e.preemptReservations(testCtx, pod, reservation, nodes))
+	assert.False(t, exists(lower))
+	assert.False(t, exists(low))
+	assert.True(t, exists(provisioning))
+	assert.True(t, exists(assigned))
+	assert.True(t, exists(higher))
+	assert.True(t, exists(otherNode))
+	assert.True(t, exists(pod))
+
+	// reservation is requested again after preemption
+	e.SetReservationPreemption(true)
+	applyObjs(t, e.k8sClient, newPreemptionACR(e, low, v1.ReservationConfirmed, 100, "node-1-uid"))
+	matched, failed, err := e.handleReservation(testCtx, pod, reservation, nodes)
+	assert.Nil(t, err)
+	assert.Nil(t, matched)
+	assert.Nil(t, failed)
+	assert.False(t, exists(low))
+	assert.Nil(t, e.k8sClient.ReadCR(testCtx, reservation.Name, "", reservation))
+	assert.Equal(t, v1.ReservationRequested, reservation.Spec.Status)
+}
+
+func TestExtender_isPreemptable(t *testing.T) {
+	var (
+		e       = setup(t)
+		victim  = newPreemptionPod("low", 1)
+		acr     = newPreemptionACR(e, victim, v1.ReservationConfirmed, 100, "node-1-uid")
+		current = &acrcrd.AvailableCapacityReservation{}
+	)
+	applyObjs(t, e.k8sClient, victim, acr)
+	listed := acr.DeepCopy()
+	assert.True(t, e.isPreemptable(testCtx, listed, victim))
+
+	// pod is assigned to node after reservations are listed
+	assigned := victim.DeepCopy()
+	assigned.Spec.NodeName = "node-1"
+	assert.Nil(t, e.k8sClient.UpdateCR(testCtx, assigned))
+	assert.False(t, e.isPreemptable(testCtx, listed, victim))
+	assigned.Spec.NodeName = ""
+	assert.Nil(t, e.k8sClient.UpdateCR(testCtx, assigned))
+
+	// volumes are being provisioned from reservation after reservations are listed
+	pvc := &coreV1.PersistentVolumeClaim{ObjectMeta: metaV1.ObjectMeta{Name: "low-data", Namespace: testNs,
+		Annotations: map[string]string{selectedNodeAnnotation: "node-1"}}}
+	applyObjs(t, e.k8sClient, pvc)
+	assert.False(t, e.isPreemptable(testCtx, listed, victim))
+	assert.Nil(t, e.k8sClient.DeleteCR(testCtx, pvc))
+
+	// reservation isn't confirmed anymore
+	assert.Nil(t, e.k8sClient.ReadCR(testCtx, acr.Name, "", current))
+	current.Spec.Status = v1.ReservationRejected
+	assert.Nil(t, e.k8sClient.UpdateCR(testCtx, current))
+	assert.False(t, e.isPreemptable(testCtx, listed, victim))
+}
+
+func TestExtender_PreemptHandler(t *testing.T) {
+	var (
+		e     = setup(t)
+		pod   = newPreemptionPod("high", 100)
+		nodes = []coreV1.Node{
+			{ObjectMeta: metaV1.ObjectMeta{UID: "node-1-uid", Name: "node-1"}},
+			{ObjectMeta: metaV1.ObjectMeta{UID: "node-2-uid", Name: "node-2"}},
+		}
+		args = schedulerapi.ExtenderPreemptionArgs{
+			Pod: pod,
+			NodeNameToVictims: map[string]*schedulerapi.Victims{
+				"node-1": {Pods: []*coreV1.Pod{{ObjectMeta: metaV1.ObjectMeta{UID: "victim-1"}}}},
+				"node-2": {Pods: []*coreV1.Pod{{ObjectMeta: metaV1.ObjectMeta{UID: "victim-2"}}}, NumPDBViolations: 1},
+			},
+		}
+		preempt = func() map[string]*schedulerapi.MetaVictims {
+			body, err := json.Marshal(args)
+			assert.Nil(t, err)
+			w := httptest.NewRecorder()
+			e.PreemptHandler(w, httptest.NewRequest(http.MethodPost, "/preempt", bytes.NewReader(body)))
+			res := &schedulerapi.ExtenderPreemptionResult{}
+			assert.Nil(t, json.NewDecoder(w.Body).Decode(res))
+			return res.NodeNameToMetaVictims
+		}
+	)
+	applyObjs(t, e.k8sClient, &nodes[0], &nodes[1], testSC1.DeepCopy())
+
+	// reservation isn't confirmed, eviction of running pods doesn't help
+	assert.Empty(t, preempt())
+
+	reservation := newPreemptionACR(e, pod, v1.ReservationConfirmed, 100, "node-1-uid")
+	applyObjs(t, e.k8sClient, reservation)
+	assert.Equal(t, map[string]*schedulerapi.MetaVictims{
+		"node-1": {Pods: []*schedulerapi.MetaPod{{UID: "victim-1"}}},
+	}, preempt())
+
+	// pod doesn't have volumes provisioned by CSI Baremetal
+	args.Pod = &coreV1.Pod{ObjectMeta: metaV1.ObjectMeta{Name: "no-volumes", Namespace: testNs}}
+	assert.Equal(t, getMetaVictims(&args), preempt())
+	assert.Len(t, preempt(), 2)
+}
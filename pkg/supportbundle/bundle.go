@@ -0,0 +1,300 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package supportbundle contains collector of support bundle: archive with logs of CSI pods, CR dumps and
+// state of drives, LVM and mounts on nodes, which is attached to support tickets
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	acrcrd "github.com/dell/csi-baremetal/api/v1/acreservationcrd"
+	accrd "github.com/dell/csi-baremetal/api/v1/availablecapacitycrd"
+	"github.com/dell/csi-baremetal/api/v1/drivebatchcrd"
+	"github.com/dell/csi-baremetal/api/v1/drivecrd"
+	"github.com/dell/csi-baremetal/api/v1/historycrd"
+	"github.com/dell/csi-baremetal/api/v1/lvgcrd"
+	"github.com/dell/csi-baremetal/api/v1/nodecrd"
+	"github.com/dell/csi-baremetal/api/v1/poolcrd"
+	"github.com/dell/csi-baremetal/api/v1/volumecrd"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+const (
+	// DefaultNodeContainer is the name of node service container in node pods
+	DefaultNodeContainer = "node"
+	// DefaultLogTailLines is the default number of the latest log lines collected per container
+	DefaultLogTailLines = 10000
+	// errorsFile lists errors of collection, bundle is collected even if some parts fail
+	errorsFile = "errors.txt"
+)
+
+// nodeCommand is a command which output is collected from node service container
+type nodeCommand struct {
+	name string
+	cmd  []string
+}
+
+// nodeCommands are collected on every selected node
+var nodeCommands = []nodeCommand{
+	{name: "lsblk", cmd: []string{"lsblk", "--all", "--bytes", "--paths", "--output-all"}},
+	{name: "parted", cmd: []string{"parted", "--script", "--list"}},
+	{name: "lvm-pvs", cmd: []string{"lvm", "pvs", "--all", "--units", "b"}},
+	{name: "lvm-vgs", cmd: []string{"lvm", "vgs", "--units", "b"}},
+	{name: "lvm-lvs", cmd: []string{"lvm", "lvs", "--all", "--units", "b", "--options", "+devices"}},
+	{name: "mountinfo", cmd: []string{"cat", "/proc/self/mountinfo"}},
+}
+
+// diskListCmd lists disks of the node, partition table of each disk is collected with sgdisk
+var diskListCmd = []string{"lsblk", "--nodeps", "--noheadings", "--paths", "--output", "NAME"}
+
+// crLists are lists of CRs which are dumped to the bundle, key - file name
+var crLists = map[string]func() k8sCl.ObjectList{
+	"drives":                        func() k8sCl.ObjectList { return &drivecrd.DriveList{} },
+	"volumes":                       func() k8sCl.ObjectList { return &volumecrd.VolumeList{} },
+	"availablecapacities":           func() k8sCl.ObjectList { return &accrd.AvailableCapacityList{} },
+	"availablecapacityreservations": func() k8sCl.ObjectList { return &acrcrd.AvailableCapacityReservationList{} },
+	"logicalvolumegroups":           func() k8sCl.ObjectList { return &lvgcrd.LogicalVolumeGroupList{} },
+	"nodes":                         func() k8sCl.ObjectList { return &nodecrd.NodeList{} },
+	"pools":                         func() k8sCl.ObjectList { return &poolcrd.PoolList{} },
+	"drivebatches":                  func() k8sCl.ObjectList { return &drivebatchcrd.DriveBatchList{} },
+	"histories":                     func() k8sCl.ObjectList { return &historycrd.HistoryList{} },
+}
+
+// Collector collects support bundle
+type Collector struct {
+	client    *k8s.KubeClient
+	clientset kubernetes.Interface
+	executor  Executor
+	// names of k8s nodes which logs and state are collected, all nodes if empty
+	nodes         map[string]bool
+	nodeContainer string
+	execPrefix    []string
+	logTailLines  int64
+	log           *logrus.Entry
+}
+
+// NewCollector creates Collector
+// Receives KubeClient of CSI namespace, clientset to read logs, executor of commands in node pods and logger
+func NewCollector(client *k8s.KubeClient, clientset kubernetes.Interface, executor Executor,
+	logger *logrus.Logger) *Collector {
+	return &Collector{
+		client:        client,
+		clientset:     clientset,
+		executor:      executor,
+		nodes:         make(map[string]bool),
+		nodeContainer: DefaultNodeContainer,
+		logTailLines:  DefaultLogTailLines,
+		log:           logger.WithField("component", "SupportBundleCollector"),
+	}
+}
+
+// SetNodes limits collection of logs and node state to provided k8s nodes
+func (c *Collector) SetNodes(nodes []string) {
+	for _, node := range nodes {
+		if node = strings.TrimSpace(node); node != "" {
+			c.nodes[node] = true
+		}
+	}
+}
+
+// SetNodeContainer sets name of node service container and prefix of exec mode of node service,
+// commands are run with the same prefix as node service runs utilities
+func (c *Collector) SetNodeContainer(container string, execPrefix []string) {
+	c.nodeContainer = container
+	c.execPrefix = execPrefix
+}
+
+// SetLogTailLines sets the number of the latest log lines collected per container
+func (c *Collector) SetLogTailLines(lines int64) {
+	c.logTailLines = lines
+}
+
+// Collect writes gzipped tar archive with sanitized CR dumps, logs and node state to w
+// Errors of particular parts are written to errors.txt in the archive, error is returned if archive can't be written
+func (c *Collector) Collect(ctx context.Context, w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	b := &bundle{tar: tar.NewWriter(gz), created: time.Now()}
+
+	c.collectCRs(ctx, b)
+	pods, err := c.client.GetPods(ctx, "")
+	if err != nil {
+		b.failed("pods", err)
+	}
+	for _, pod := range pods {
+		if !c.isSelected(pod) {
+			continue
+		}
+		c.collectLogs(ctx, b, pod)
+		if hasContainer(pod, c.nodeContainer) {
+			c.collectNodeState(ctx, b, pod)
+		}
+	}
+	if len(b.errors) > 0 {
+		b.add(errorsFile, []byte(strings.Join(b.errors, "\n")+"\n"))
+	}
+
+	if b.err != nil {
+		return b.err
+	}
+	if err = b.tar.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// collectCRs dumps lists of CSI CRs in JSON
+func (c *Collector) collectCRs(ctx context.Context, b *bundle) {
+	for name, newList := range crLists {
+		list := newList()
+		if err := c.client.ReadList(ctx, list); err != nil {
+			b.failed("crs/"+name, err)
+			continue
+		}
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			b.failed("crs/"+name, err)
+			continue
+		}
+		b.add(path.Join("crs", name+".json"), data)
+	}
+}
+
+// collectLogs collects the latest logs of all containers of the pod
+func (c *Collector) collectLogs(ctx context.Context, b *bundle, pod *coreV1.Pod) {
+	for _, container := range pod.Spec.Containers {
+		name := path.Join("logs", pod.Name, container.Name+".log")
+		stream, err := c.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &coreV1.PodLogOptions{
+			Container: container.Name,
+			TailLines: &c.logTailLines,
+		}).Stream(ctx)
+		if err != nil {
+			b.failed(name, err)
+			continue
+		}
+		data, err := ioutil.ReadAll(stream)
+		_ = stream.Close()
+		if err != nil {
+			b.failed(name, err)
+			continue
+		}
+		b.add(name, data)
+	}
+}
+
+// collectNodeState collects output of node commands and partition tables of disks from node service container
+func (c *Collector) collectNodeState(ctx context.Context, b *bundle, pod *coreV1.Pod) {
+	dir := path.Join("nodes", pod.Spec.NodeName)
+	c.log.WithField("method", "collectNodeState").Infof("Collecting state of node %s", pod.Spec.NodeName)
+	for _, command := range nodeCommands {
+		name := path.Join(dir, command.name+".txt")
+		out, err := c.exec(ctx, pod, command.cmd)
+		if err != nil {
+			b.failed(name, err)
+		}
+		b.add(name, out)
+	}
+
+	name := path.Join(dir, "sgdisk.txt")
+	disks, err := c.exec(ctx, pod, diskListCmd)
+	if err != nil {
+		b.failed(name, err)
+		return
+	}
+	var sgdisk bytes.Buffer
+	for _, disk := range strings.Fields(string(disks)) {
+		out, err := c.exec(ctx, pod, []string{"sgdisk", "--print", disk})
+		if err != nil {
+			b.failed(name+" "+disk, err)
+		}
+		fmt.Fprintf(&sgdisk, "### %s\n%s\n", disk, out)
+	}
+	b.add(name, sgdisk.Bytes())
+}
+
+// exec runs command in node service container with prefix of exec mode
+func (c *Collector) exec(ctx context.Context, pod *coreV1.Pod, cmd []string) ([]byte, error) {
+	return c.executor.Exec(ctx, pod.Namespace, pod.Name, c.nodeContainer, append(append([]string{}, c.execPrefix...), cmd...))
+}
+
+// isSelected checks whether logs and state of the pod are collected: pods of DaemonSets are collected on selected
+// nodes only, other pods (e.g. controller) are always collected
+func (c *Collector) isSelected(pod *coreV1.Pod) bool {
+	if len(c.nodes) == 0 || c.nodes[pod.Spec.NodeName] {
+		return true
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasContainer checks whether pod has container with provided name
+func hasContainer(pod *coreV1.Pod, name string) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bundle is tar archive being written, the first write error is kept and the rest of writes are skipped
+type bundle struct {
+	tar     *tar.Writer
+	created time.Time
+	errors  []string
+	err     error
+}
+
+// add writes sanitized file to archive
+func (b *bundle) add(name string, data []byte) {
+	if b.err != nil {
+		return
+	}
+	data = Sanitize(data)
+	if b.err = b.tar.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: b.created,
+	}); b.err != nil {
+		return
+	}
+	_, b.err = b.tar.Write(data)
+}
+
+// failed records error of collection of the part of bundle
+func (b *bundle) failed(part string, err error) {
+	b.errors = append(b.errors, fmt.Sprintf("%s: %v", part, err))
+}
@@ -0,0 +1,163 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	api "github.com/dell/csi-baremetal/api/generated/v1"
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+)
+
+var (
+	testNs     = "default"
+	testLogger = logrus.New()
+	testCtx    = context.Background()
+)
+
+// fakeExecutor returns output by command line and records executed commands
+type fakeExecutor struct {
+	outputs  map[string]string
+	executed []string
+}
+
+func (e *fakeExecutor) Exec(_ context.Context, _, pod, container string, cmd []string) ([]byte, error) {
+	line := strings.Join(cmd, " ")
+	e.executed = append(e.executed, pod+"/"+container+": "+line)
+	if out, ok := e.outputs[line]; ok {
+		return []byte(out), nil
+	}
+	return nil, errors.New("command not found")
+}
+
+func newPod(name, node string, daemonSet bool, containers ...string) *coreV1.Pod {
+	pod := &coreV1.Pod{
+		ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: testNs},
+		Spec:       coreV1.PodSpec{NodeName: node},
+	}
+	if daemonSet {
+		pod.OwnerReferences = []metaV1.OwnerReference{{Kind: "DaemonSet", Name: "csi-baremetal-node"}}
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, coreV1.Container{Name: container})
+	}
+	return pod
+}
+
+// readBundle returns files of archive, key - file name
+func readBundle(t *testing.T, data []byte) map[string]string {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	assert.Nil(t, err)
+	reader := tar.NewReader(gz)
+	files := make(map[string]string)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return files
+		}
+		assert.Nil(t, err)
+		content, err := ioutil.ReadAll(reader)
+		assert.Nil(t, err)
+		files[header.Name] = string(content)
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	drive := kubeClient.ConstructDriveCR("drive-1", api.Drive{UUID: "drive-1", NodeId: "node-1", SerialNumber: "SN1"})
+	assert.Nil(t, kubeClient.CreateCR(testCtx, drive.Name, drive))
+
+	pods := []*coreV1.Pod{
+		newPod("node-1-pod", "node-1", true, "node", "drivemgr"),
+		newPod("node-2-pod", "node-2", true, "node", "drivemgr"),
+		newPod("controller-pod", "node-2", false, "controller"),
+	}
+	clientset := fake.NewSimpleClientset()
+	for _, pod := range pods {
+		assert.Nil(t, kubeClient.Create(testCtx, pod.DeepCopy()))
+		_, err = clientset.CoreV1().Pods(testNs).Create(testCtx, pod.DeepCopy(), metaV1.CreateOptions{})
+		assert.Nil(t, err)
+	}
+
+	executor := &fakeExecutor{outputs: map[string]string{
+		"nsenter lsblk --all --bytes --paths --output-all":          "NAME /dev/sda",
+		"nsenter lsblk --nodeps --noheadings --paths --output NAME": "/dev/sda\n/dev/sdb\n",
+		"nsenter sgdisk --print /dev/sda":                           "Disk /dev/sda",
+		"nsenter sgdisk --print /dev/sdb":                           "Disk /dev/sdb",
+		"nsenter cat /proc/self/mountinfo":                          "/dev/sda1 /mnt",
+		"nsenter lvm lvs --all --units b --options +devices":        "lv passphrase=secret-value",
+		"nsenter lvm pvs --all --units b":                           "",
+		"nsenter lvm vgs --units b":                                 "",
+	}}
+
+	collector := NewCollector(kubeClient, clientset, executor, testLogger)
+	collector.SetNodes([]string{"node-1", " "})
+	collector.SetNodeContainer("node", []string{"nsenter"})
+
+	var out bytes.Buffer
+	assert.Nil(t, collector.Collect(testCtx, &out))
+	files := readBundle(t, out.Bytes())
+
+	// CRs
+	assert.Contains(t, files["crs/drives.json"], "SN1")
+	assert.Contains(t, files, "crs/volumes.json")
+	// logs of selected node and not DaemonSet pods
+	assert.Contains(t, files, "logs/node-1-pod/node.log")
+	assert.Contains(t, files, "logs/node-1-pod/drivemgr.log")
+	assert.Contains(t, files, "logs/controller-pod/controller.log")
+	assert.NotContains(t, files, "logs/node-2-pod/node.log")
+	// node state
+	assert.Equal(t, "NAME /dev/sda", files["nodes/node-1/lsblk.txt"])
+	assert.Equal(t, "/dev/sda1 /mnt", files["nodes/node-1/mountinfo.txt"])
+	assert.Equal(t, "lv passphrase=<redacted>", files["nodes/node-1/lvm-lvs.txt"])
+	assert.Equal(t, "### /dev/sda\nDisk /dev/sda\n### /dev/sdb\nDisk /dev/sdb\n", files["nodes/node-1/sgdisk.txt"])
+	assert.NotContains(t, files, "nodes/node-2/lsblk.txt")
+	// parted isn't available
+	assert.Contains(t, files[errorsFile], "nodes/node-1/parted.txt: command not found")
+	for _, executed := range executor.executed {
+		assert.True(t, strings.HasPrefix(executed, "node-1-pod/node: nsenter "), executed)
+	}
+}
+
+func TestCollector_isSelected(t *testing.T) {
+	kubeClient, err := k8s.GetFakeKubeClient(testNs, testLogger)
+	assert.Nil(t, err)
+	collector := NewCollector(kubeClient, fake.NewSimpleClientset(), &fakeExecutor{}, testLogger)
+
+	// all nodes
+	assert.True(t, collector.isSelected(newPod("pod", "node-2", true)))
+
+	collector.SetNodes([]string{"node-1"})
+	assert.True(t, collector.isSelected(newPod("pod", "node-1", true)))
+	assert.False(t, collector.isSelected(newPod("pod", "node-2", true)))
+	assert.True(t, collector.isSelected(newPod("pod", "node-2", false)))
+}
@@ -0,0 +1,41 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supportbundle
+
+import "regexp"
+
+// redacted replaces sensitive values
+const redacted = "<redacted>"
+
+// sensitivePatterns match sensitive values in logs, command outputs and CR dumps,
+// the first group is kept and the rest of the match is replaced
+var sensitivePatterns = []*regexp.Regexp{
+	// key=value, key: value and "key":"value" pairs
+	regexp.MustCompile(`(?i)((?:password|passwd|passphrase|secret|token|credentials?)["']?\s*[:=]\s*["']?)[^\s"',}]+`),
+	// CSI secrets logged in protobuf text format, e.g. secrets:<key:"luks" value:"...">
+	regexp.MustCompile(`(secrets:<key:"[^"]*" value:")[^"]*`),
+	// HTTP authorization headers
+	regexp.MustCompile(`(?i)(authorization:\s*(?:bearer|basic)\s+)\S+`),
+}
+
+// Sanitize removes sensitive values from data
+func Sanitize(data []byte) []byte {
+	for _, pattern := range sensitivePatterns {
+		data = pattern.ReplaceAll(data, []byte("${1}"+redacted))
+	}
+	return data
+}
@@ -0,0 +1,44 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supportbundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"key=value", "password=qwerty uuid=1", "password=<redacted> uuid=1"},
+		{"key: value", "Token: abc.def", "Token: <redacted>"},
+		{"json", `{"passphrase":"p@ss","name":"vol"}`, `{"passphrase":"<redacted>","name":"vol"}`},
+		{"csi secrets", `secrets:<key:"luks" value:"p@ss word" >`, `secrets:<key:"luks" value:"<redacted>" >`},
+		{"authorization", "Authorization: Bearer abc", "Authorization: Bearer <redacted>"},
+		{"not sensitive", "lsblk --paths /dev/sda", "lsblk --paths /dev/sda"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(Sanitize([]byte(tc.data))))
+		})
+	}
+}
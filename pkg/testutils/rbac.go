@@ -0,0 +1,246 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutils
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sCl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/dell/csi-baremetal/pkg/base/k8s"
+	"github.com/dell/csi-baremetal/pkg/base/logger/objects"
+)
+
+const rbacMarkerPrefix = "// +kubebuilder:rbac:"
+
+// RBACRule is a rule of kubebuilder RBAC marker
+type RBACRule struct {
+	Groups    []string
+	Resources []string
+	Verbs     []string
+	Namespace string
+}
+
+// APICall is a request of k8s client, Resource is plural lowercase name of resource or its subresource
+type APICall struct {
+	Group    string
+	Resource string
+	Verb     string
+}
+
+// String returns call in form of RBAC marker
+func (c APICall) String() string {
+	return fmt.Sprintf("groups=%q,resources=%s,verbs=%s", c.Group, c.Resource, c.Verb)
+}
+
+// ParseRBACMarkers reads kubebuilder RBAC markers from Go file
+// Returns rules of markers or error if file can't be read or marker is malformed
+func ParseRBACMarkers(path string) ([]RBACRule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var rules []RBACRule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, rbacMarkerPrefix) {
+			continue
+		}
+		rule := RBACRule{}
+		for _, field := range strings.Split(strings.TrimPrefix(line, rbacMarkerPrefix), ",") {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("malformed RBAC marker %q", line)
+			}
+			values := strings.Split(strings.Trim(kv[1], `"`), ";")
+			switch kv[0] {
+			case "groups":
+				rule.Groups = values
+			case "resources":
+				rule.Resources = values
+			case "verbs":
+				rule.Verbs = values
+			case "namespace":
+				rule.Namespace = kv[1]
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// RBACAllows returns true if one of rules allows call, namespace of rule isn't checked
+func RBACAllows(rules []RBACRule, call APICall) bool {
+	for _, rule := range rules {
+		if contains(rule.Groups, call.Group) && contains(rule.Resources, call.Resource) &&
+			contains(rule.Verbs, call.Verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordingClient is k8s client which records requests, it is used to check RBAC rules against real client calls
+type RecordingClient struct {
+	k8sCl.Client
+	scheme *runtime.Scheme
+
+	mu    sync.Mutex
+	calls map[APICall]bool
+}
+
+// NewRecordingKubeClient returns KubeClient over fake k8s client which records requests
+func NewRecordingKubeClient(namespace string, logger *logrus.Logger) (*k8s.KubeClient, *RecordingClient, error) {
+	scheme, err := k8s.PrepareScheme()
+	if err != nil {
+		return nil, nil, err
+	}
+	recorder := &RecordingClient{
+		Client: k8s.NewFakeClientWrapper(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme),
+		scheme: scheme,
+		calls:  map[APICall]bool{},
+	}
+	return k8s.NewKubeClient(recorder, logger, objects.NewObjectLogger(), namespace), recorder, nil
+}
+
+// Calls returns recorded requests
+func (r *RecordingClient) Calls() []APICall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := make([]APICall, 0, len(r.calls))
+	for call := range r.calls {
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// Reset removes recorded requests, it is called after test objects are created
+func (r *RecordingClient) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = map[APICall]bool{}
+}
+
+func (r *RecordingClient) record(obj runtime.Object, subresource, verb string) {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return
+	}
+	kind := strings.TrimSuffix(gvk.Kind, "List")
+	resource := strings.ToLower(kind)
+	switch {
+	case strings.HasSuffix(resource, "y"):
+		resource = strings.TrimSuffix(resource, "y") + "ies"
+	case strings.HasSuffix(resource, "s"):
+		resource += "es"
+	default:
+		resource += "s"
+	}
+	if subresource != "" {
+		resource += "/" + subresource
+	}
+	r.mu.Lock()
+	r.calls[APICall{Group: gvk.Group, Resource: resource, Verb: verb}] = true
+	r.mu.Unlock()
+}
+
+// Get records get request
+func (r *RecordingClient) Get(ctx context.Context, key k8sCl.ObjectKey, obj k8sCl.Object) error {
+	r.record(obj, "", "get")
+	return r.Client.Get(ctx, key, obj)
+}
+
+// List records list request
+func (r *RecordingClient) List(ctx context.Context, list k8sCl.ObjectList, opts ...k8sCl.ListOption) error {
+	r.record(list, "", "list")
+	return r.Client.List(ctx, list, opts...)
+}
+
+// Create records create request
+func (r *RecordingClient) Create(ctx context.Context, obj k8sCl.Object, opts ...k8sCl.CreateOption) error {
+	r.record(obj, "", "create")
+	return r.Client.Create(ctx, obj, opts...)
+}
+
+// Update records update request
+func (r *RecordingClient) Update(ctx context.Context, obj k8sCl.Object, opts ...k8sCl.UpdateOption) error {
+	r.record(obj, "", "update")
+	return r.Client.Update(ctx, obj, opts...)
+}
+
+// Patch records patch request
+func (r *RecordingClient) Patch(ctx context.Context, obj k8sCl.Object, patch k8sCl.Patch,
+	opts ...k8sCl.PatchOption) error {
+	r.record(obj, "", "patch")
+	return r.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// Delete records delete request
+func (r *RecordingClient) Delete(ctx context.Context, obj k8sCl.Object, opts ...k8sCl.DeleteOption) error {
+	r.record(obj, "", "delete")
+	return r.Client.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf records deletecollection request
+func (r *RecordingClient) DeleteAllOf(ctx context.Context, obj k8sCl.Object, opts ...k8sCl.DeleteAllOfOption) error {
+	r.record(obj, "", "deletecollection")
+	return r.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Status returns writer which records requests to status subresource
+func (r *RecordingClient) Status() k8sCl.StatusWriter {
+	return &recordingStatusWriter{StatusWriter: r.Client.Status(), recorder: r}
+}
+
+type recordingStatusWriter struct {
+	k8sCl.StatusWriter
+	recorder *RecordingClient
+}
+
+// Update records update request of status subresource
+func (w *recordingStatusWriter) Update(ctx context.Context, obj k8sCl.Object, opts ...k8sCl.UpdateOption) error {
+	w.recorder.record(obj, "status", "update")
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+// Patch records patch request of status subresource
+func (w *recordingStatusWriter) Patch(ctx context.Context, obj k8sCl.Object, patch k8sCl.Patch,
+	opts ...k8sCl.PatchOption) error {
+	w.recorder.record(obj, "status", "patch")
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
@@ -26,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2elog "k8s.io/kubernetes/test/e2e/framework/log"
@@ -153,3 +154,36 @@ func WaitForStatefulSetReplicasReady(ctx context.Context, statefulSetName, ns st
 	}
 	return fmt.Errorf("StatefulSet %s still has unready pods within %v", statefulSetName, timeout)
 }
+
+// GetTotalACSize returns sum of sizes of all AvailableCapacity CRs in the cluster
+func GetTotalACSize(ctx context.Context, f *framework.Framework) (int64, error) {
+	acList, err := f.DynamicClient.Resource(ACGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, ac := range acList.Items {
+		size, _, err := unstructured.NestedInt64(ac.Object, "spec", "Size")
+		if err != nil {
+			return 0, fmt.Errorf("failed to read size of AC %s: %v", ac.GetName(), err)
+		}
+		total += size
+	}
+	return total, nil
+}
+
+// WaitForVolumeCRDeleted waits until Volume CR with the given name is removed or until timeout occurs
+func WaitForVolumeCRDeleted(ctx context.Context, f *framework.Framework, volumeName string, Poll, timeout time.Duration) error {
+	e2elog.Logf("Waiting up to %v for Volume CR %s to be deleted", timeout, volumeName)
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(Poll) {
+		_, err := f.DynamicClient.Resource(VolumeGVR).Namespace(f.Namespace.Name).Get(ctx, volumeName, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			e2elog.Logf("Volume CR %s was deleted (%v)", volumeName, time.Since(start))
+			return nil
+		}
+		if err != nil {
+			e2elog.Logf("Get Volume CR %s failed, ignoring for %v: %v", volumeName, Poll, err)
+		}
+	}
+	return fmt.Errorf("Volume CR %s still exists within %v", volumeName, timeout)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenarios
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2elog "k8s.io/kubernetes/test/e2e/framework/log"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2epv "k8s.io/kubernetes/test/e2e/framework/pv"
+	storageframework "k8s.io/kubernetes/test/e2e/storage/framework"
+	"k8s.io/kubernetes/test/e2e/storage/testsuites"
+
+	"github.com/dell/csi-baremetal/test/e2e/common"
+)
+
+const (
+	lifecycleTag = "lifecycle"
+	// lifecycle volume is created on LVG to be expandable
+	lifecycleStorageType = "HDDLVG"
+	lifecycleExpandSize  = "200Mi"
+	lifecycleDataFile    = "/mnt/volume1/lifecycle-data"
+	lifecycleTimeout     = 2 * time.Minute
+	lifecyclePoll        = 5 * time.Second
+)
+
+// DefineLifecycleTestSuite defines full volume lifecycle test which is able to run on kind with loop devices only
+func DefineLifecycleTestSuite(driver *baremetalDriver) {
+	ginkgo.Context("Baremetal-csi volume lifecycle [Lifecycle]", func() {
+		// Test scenario:
+		// Provision PVC and mount it into the Pod, write data on the volume and check it,
+		// expand PVC and check that data is still on the volume,
+		// delete Pod and PVC and check that Volume CR is removed and capacity is returned
+		volumeLifecycleTest(driver)
+	})
+}
+
+// volumeLifecycleTest checks provision -> mount -> write -> expand -> delete sequence for one volume
+func volumeLifecycleTest(driver *baremetalDriver) {
+	ginkgo.BeforeEach(skipIfNotAllTests)
+
+	var (
+		pod           *corev1.Pod
+		pvc           *corev1.PersistentVolumeClaim
+		k8sSC         *storagev1.StorageClass
+		driverCleanup func()
+		f             = framework.NewDefaultFramework(lifecycleTag)
+	)
+
+	init := func() {
+		var (
+			perTestConf *storageframework.PerTestConfig
+			err         error
+		)
+		perTestConf, driverCleanup = PrepareCSI(driver, f, true)
+
+		k8sSC = driver.GetStorageClassWithStorageType(perTestConf, lifecycleStorageType)
+		allowExpansion := true
+		k8sSC.AllowVolumeExpansion = &allowExpansion
+		k8sSC, err = f.ClientSet.StorageV1().StorageClasses().Create(context.TODO(), k8sSC, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+	}
+
+	cleanup := func() {
+		e2elog.Logf("Starting cleanup for test Lifecycle")
+		var (
+			pods []*corev1.Pod
+			pvcs []*corev1.PersistentVolumeClaim
+		)
+		if pod != nil {
+			pods = append(pods, pod)
+		}
+		if pvc != nil {
+			pvcs = append(pvcs, pvc)
+		}
+		common.CleanupAfterCustomTest(f, driverCleanup, pods, pvcs)
+	}
+
+	ginkgo.It("should provision, mount, write, expand and delete volume", func() {
+		init()
+		defer cleanup()
+		ns := f.Namespace.Name
+
+		capacityBefore, err := common.GetTotalACSize(context.TODO(), f)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("provisioning volume and mounting it into the pod")
+		pvc, err = f.ClientSet.CoreV1().PersistentVolumeClaims(ns).Create(context.TODO(),
+			constructPVC(ns, driver.GetClaimSize(), k8sSC.Name, pvcName), metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		pod = startAndWaitForPodWithPVCRunning(f, ns, []*corev1.PersistentVolumeClaim{pvc})
+		pv, err := common.GetBoundPV(context.TODO(), f.ClientSet, pvc)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("writing data on the volume")
+		data := "csi-baremetal-" + ns
+		execInLifecyclePod(f, pod, "echo "+data+" > "+lifecycleDataFile+" && sync")
+		Expect(execInLifecyclePod(f, pod, "cat "+lifecycleDataFile)).To(Equal(data))
+
+		ginkgo.By("expanding volume")
+		newSize := resource.MustParse(lifecycleExpandSize)
+		pvc, err = testsuites.ExpandPVCSize(pvc, newSize, f.ClientSet)
+		framework.ExpectNoError(err)
+		err = testsuites.WaitForControllerVolumeResize(pvc, f.ClientSet, lifecycleTimeout)
+		framework.ExpectNoError(err)
+		pvc, err = testsuites.WaitForFSResize(pvc, f.ClientSet)
+		framework.ExpectNoError(err)
+		actualSize := pvc.Status.Capacity[corev1.ResourceStorage]
+		Expect(actualSize.Cmp(newSize)).To(BeNumerically(">=", 0))
+		Expect(execInLifecyclePod(f, pod, "cat "+lifecycleDataFile)).To(Equal(data))
+
+		ginkgo.By("deleting pod and volume")
+		framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+		pod = nil
+		framework.ExpectNoError(e2epv.DeletePersistentVolumeClaim(f.ClientSet, pvc.Name, ns))
+		pvc = nil
+		framework.ExpectNoError(e2epv.WaitForPersistentVolumeDeleted(f.ClientSet, pv.Name, lifecyclePoll, lifecycleTimeout))
+		framework.ExpectNoError(common.WaitForVolumeCRDeleted(context.TODO(), f, pv.Name, lifecyclePoll, lifecycleTimeout))
+
+		ginkgo.By("checking that capacity is returned")
+		Eventually(func() int64 {
+			capacityAfter, err := common.GetTotalACSize(context.TODO(), f)
+			if err != nil {
+				e2elog.Logf("Failed to get AC size: %v", err)
+				return 0
+			}
+			return capacityAfter
+		}, lifecycleTimeout, lifecyclePoll).Should(Equal(capacityBefore))
+	})
+}
+
+// execInLifecyclePod executes shell command in the test pod and returns its trimmed stdout
+func execInLifecyclePod(f *framework.Framework, pod *corev1.Pod, cmd string) string {
+	stdout, stderr, err := f.ExecCommandInContainerWithFullOutput(pod.Name, pod.Spec.Containers[0].Name, "/bin/sh", "-c", cmd)
+	framework.ExpectNoError(err, "command %q failed, stderr: %s", cmd, stderr)
+	return strings.TrimSpace(stdout)
+}
@@ -60,6 +60,7 @@ var _ = utils.SIGDescribe("CSI Volumes", func() {
 		DefineDifferentSCTestSuite(curDriver)
 		DefineSchedulerTestSuite(curDriver)
 		DefineLabeledDeployTestSuite()
+		DefineLifecycleTestSuite(curDriver)
 	})
 })
 
@@ -0,0 +1,60 @@
+#!/bin/bash
+#
+# Copyright © 2021 Dell Inc. or its subsidiaries. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+# Pre-creates loop device nodes for kind cluster.
+# kind workers bind-mount /dev of the host (see kind.yaml), so Loopback DriveManager inside of them is able to use
+# only loop devices which nodes exist on the host. Docker doesn't create them on demand, so create enough nodes
+# before CSI deployment: every simulated drive takes one loop device and one more if latency is set for it.
+#
+# Usage: loop-devices.sh [count]
+#   count - number of loop devices to create, LOOP_DEVICE_COUNT or 64 by default
+
+set -euo pipefail
+
+COUNT=${1:-${LOOP_DEVICE_COUNT:-64}}
+LOOP_MAJOR=7
+
+if [[ $(id -u) -ne 0 ]]; then
+  SUDO=sudo
+else
+  SUDO=
+fi
+
+# loop module might be not loaded on fresh hosts
+if [[ ! -e /dev/loop-control ]]; then
+  ${SUDO} modprobe loop
+fi
+
+for ((i = 0; i < COUNT; i++)); do
+  if [[ ! -b /dev/loop${i} ]]; then
+    ${SUDO} mknod -m 0660 /dev/loop${i} b ${LOOP_MAJOR} ${i}
+    ${SUDO} chown root:disk /dev/loop${i}
+  fi
+done
+
+echo "Loop devices available on host: $(ls /dev/loop[0-9]* | wc -l)"
+
+# check that kind workers see loop devices of the host, control plane doesn't mount /dev
+if command -v "${KIND:-kind}" >/dev/null 2>&1; then
+  for node in $(${KIND:-kind} get nodes 2>/dev/null | grep -v control-plane); do
+    if docker exec "${node}" test -b /dev/loop$((COUNT - 1)); then
+      echo "Node ${node}: loop devices are available"
+    else
+      echo "Node ${node}: /dev/loop$((COUNT - 1)) is not visible, check extraMounts of the node" >&2
+      exit 1
+    fi
+  done
+fi
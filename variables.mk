@@ -4,6 +4,7 @@ PROJECT          := csi-baremetal
 ### common path
 CSI_OPERATOR_PATH=../csi-baremetal-operator
 CSI_CHART_CRDS_PATH=$(CSI_OPERATOR_PATH)/charts/csi-baremetal-operator/crds
+CSI_OPERATOR_RBAC_PATH=$(CSI_OPERATOR_PATH)/config/rbac/components
 CONTROLLER_GEN_BIN=./bin/controller-gen
 CRD_OPTIONS ?= "crd:trivialVersions=true"
 
@@ -45,6 +46,8 @@ EXTENDER_PATCHER := scheduler-patcher
 NODE_CONTROLLER  := ${NODE_CONTROLLER_PKG}-${CONTROLLER}
 PLUGIN           := plugin
 OPERATOR         := operator
+SUPPORT_BUNDLE   := support-bundle
+RENDER_COMMANDS  := render-commands
 
 BASE_DRIVE_MGR     := basemgr
 LOOPBACK_DRIVE_MGR := loopbackmgr
@@ -57,8 +60,12 @@ CSI_RESIZER     := csi-resizer
 LIVENESS_PROBE  := livenessprobe
 BUSYBOX         := busybox
 
+### target architecture of binaries and images: amd64 or arm64
+ARCH            ?= amd64
+DOCKER_PLATFORM := --platform linux/${ARCH}
+
 HEALTH_PROBE    	 := health_probe
-HEALTH_PROBE_BIN_URL := https://github.com/grpc-ecosystem/grpc-health-probe/releases/download/v0.3.1/grpc_health_probe-linux-amd64
+HEALTH_PROBE_BIN_URL := https://github.com/grpc-ecosystem/grpc-health-probe/releases/download/v0.3.1/grpc_health_probe-linux-${ARCH}
 
 ### go env vars
 GO_ENV_VARS     := GO111MODULE=on ${GOPRIVATE_PART} ${GOPROXY_PART}
@@ -84,6 +91,7 @@ KIND_VER := 0.11.1
 KIND_CONFIG := kind.yaml
 KIND_IMAGE_VERSION := v1.19.11
 KIND_WAIT := 30s 
+LOOP_DEVICE_COUNT := 64
 
 ### ci vars
 # timeout for short test suite, must be parsable as Go time.Duration (60m, 2h)